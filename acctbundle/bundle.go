@@ -0,0 +1,248 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package acctbundle
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/btcutil/hdkeychain"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/txscript"
+)
+
+// ScriptType is the type of the scripts paid to by the addresses of an
+// account.
+type ScriptType string
+
+// The script types of the addresses of an account.
+const (
+	ScriptP2PKH      ScriptType = "p2pkh"
+	ScriptP2SHP2WPKH ScriptType = "p2sh-p2wpkh"
+	ScriptP2WPKH     ScriptType = "p2wpkh"
+)
+
+// Branches of an account.
+const (
+	BranchReceive uint32 = 0
+	BranchChange  uint32 = 1
+)
+
+// accountDepth is the depth of an account key below the master key:
+// purpose, coin type and account.
+const accountDepth = 3
+
+var (
+	// ErrUnknownScriptType is returned for script types other than the
+	// ones defined by the package.
+	ErrUnknownScriptType = errors.New("unknown script type")
+
+	// ErrNotAccountKey is returned when an extended key is not the public
+	// key of an account.
+	ErrNotAccountKey = errors.New("not an account extended public key")
+
+	// ErrWrongNetwork is returned when a bundle is for another network.
+	ErrWrongNetwork = errors.New("extended key is for another network")
+)
+
+// purpose returns the BIP 0043 purpose of the accounts of the script type.
+func (t ScriptType) purpose() (uint32, error) {
+	switch t {
+	case ScriptP2PKH:
+		return 44, nil
+	case ScriptP2SHP2WPKH:
+		return 49, nil
+	case ScriptP2WPKH:
+		return 84, nil
+	}
+	return 0, ErrUnknownScriptType
+}
+
+// Bundle describes an account so a watch-only wallet can track it.
+type Bundle struct {
+	// Account is the name of the account.
+	Account string `json:"account"`
+
+	// AccountNumber is the index of the account key.
+	AccountNumber uint32 `json:"accountnumber"`
+
+	// ScriptType is the type of the scripts of the addresses.
+	ScriptType ScriptType `json:"scripttype"`
+
+	// MasterFingerprint is the hex encoded fingerprint of the master key,
+	// used by signers to recognize their keys.
+	MasterFingerprint string `json:"masterfingerprint"`
+
+	// XPub is the extended public key of the account.
+	XPub string `json:"xpub"`
+
+	// BirthHeight is the height of the first block which may pay to the
+	// account, from which a watch-only wallet rescans.
+	BirthHeight int32 `json:"birthheight"`
+}
+
+// Fingerprint returns the fingerprint of the passed extended key, the first
+// four bytes of the hash160 of its public key.
+func Fingerprint(key *hdkeychain.ExtendedKey) (uint32, error) {
+	pubKey, err := key.ECPubKey()
+	if err != nil {
+		return 0, err
+	}
+	hash := btcutil.Hash160(pubKey.SerializeCompressed())
+	return binary.BigEndian.Uint32(hash[:4]), nil
+}
+
+// New returns the bundle of the passed account key, derived from the master
+// key with the passed fingerprint.  Only the public part of the account key is
+// exported.
+func New(account string, accountNumber uint32, scriptType ScriptType,
+	masterFingerprint uint32, accountKey *hdkeychain.ExtendedKey,
+	birthHeight int32) (*Bundle, error) {
+
+	if _, err := scriptType.purpose(); err != nil {
+		return nil, err
+	}
+	if accountKey.Depth() != accountDepth {
+		return nil, ErrNotAccountKey
+	}
+	pubKey, err := accountKey.Neuter()
+	if err != nil {
+		return nil, err
+	}
+	var fp [4]byte
+	binary.BigEndian.PutUint32(fp[:], masterFingerprint)
+	return &Bundle{
+		Account:           account,
+		AccountNumber:     accountNumber,
+		ScriptType:        scriptType,
+		MasterFingerprint: hex.EncodeToString(fp[:]),
+		XPub:              pubKey.String(),
+		BirthHeight:       birthHeight,
+	}, nil
+}
+
+// AccountKey returns the extended public key of the account after checking
+// it is one for the passed network.
+func (b *Bundle) AccountKey(params *chaincfg.Params) (*hdkeychain.ExtendedKey, error) {
+	if _, err := b.KeyOrigin(params); err != nil {
+		return nil, err
+	}
+	key, err := hdkeychain.NewKeyFromString(b.XPub)
+	if err != nil {
+		return nil, err
+	}
+	if key.IsPrivate() || key.Depth() != accountDepth {
+		return nil, ErrNotAccountKey
+	}
+	if !key.IsForNet(params) {
+		return nil, ErrWrongNetwork
+	}
+	return key, nil
+}
+
+// Validate returns an error when the bundle is not valid for the passed
+// network.
+func (b *Bundle) Validate(params *chaincfg.Params) error {
+	_, err := b.AccountKey(params)
+	return err
+}
+
+// KeyOrigin returns the origin of the account key.  The origin of the key of
+// an address is the one of the account followed by the branch and the index
+// of the address.
+func (b *Bundle) KeyOrigin(params *chaincfg.Params) (*KeyOrigin, error) {
+	purpose, err := b.ScriptType.purpose()
+	if err != nil {
+		return nil, err
+	}
+	fp, err := hex.DecodeString(b.MasterFingerprint)
+	if err != nil || len(fp) != 4 {
+		return nil, fmt.Errorf("invalid master fingerprint %q",
+			b.MasterFingerprint)
+	}
+	return &KeyOrigin{
+		Fingerprint: binary.BigEndian.Uint32(fp),
+		Path: []uint32{
+			hdkeychain.HardenedKeyStart + purpose,
+			hdkeychain.HardenedKeyStart + params.HDCoinType,
+			hdkeychain.HardenedKeyStart + b.AccountNumber,
+		},
+	}, nil
+}
+
+// KeyPath returns the derivation path of the account key from the master key,
+// with hardened indexes marked by an apostrophe.
+func (b *Bundle) KeyPath(params *chaincfg.Params) (string, error) {
+	origin, err := b.KeyOrigin(params)
+	if err != nil {
+		return "", err
+	}
+	return FormatPath(origin.Path), nil
+}
+
+// Descriptor returns the output script descriptor of the addresses of the
+// passed branch of the account, with its checksum.
+func (b *Bundle) Descriptor(branch uint32, params *chaincfg.Params) (string, error) {
+	if err := b.Validate(params); err != nil {
+		return "", err
+	}
+	origin, err := b.KeyOrigin(params)
+	if err != nil {
+		return "", err
+	}
+	key := fmt.Sprintf("%s%s/%d/*", origin, b.XPub, branch)
+
+	var desc string
+	switch b.ScriptType {
+	case ScriptP2PKH:
+		desc = "pkh(" + key + ")"
+	case ScriptP2SHP2WPKH:
+		desc = "sh(wpkh(" + key + "))"
+	case ScriptP2WPKH:
+		desc = "wpkh(" + key + ")"
+	}
+	return AddDescriptorChecksum(desc)
+}
+
+// Address returns the address at the passed index of the passed branch of the
+// account.
+func (b *Bundle) Address(branch, index uint32, params *chaincfg.Params) (btcutil.Address, error) {
+	accountKey, err := b.AccountKey(params)
+	if err != nil {
+		return nil, err
+	}
+	branchKey, err := accountKey.Child(branch)
+	if err != nil {
+		return nil, err
+	}
+	key, err := branchKey.Child(index)
+	if err != nil {
+		return nil, err
+	}
+	pubKey, err := key.ECPubKey()
+	if err != nil {
+		return nil, err
+	}
+	pkHash := btcutil.Hash160(pubKey.SerializeCompressed())
+
+	switch b.ScriptType {
+	case ScriptP2PKH:
+		return btcutil.NewAddressPubKeyHash(pkHash, params)
+	case ScriptP2WPKH:
+		return btcutil.NewAddressWitnessPubKeyHash(pkHash, params)
+	}
+	witnessAddr, err := btcutil.NewAddressWitnessPubKeyHash(pkHash, params)
+	if err != nil {
+		return nil, err
+	}
+	redeemScript, err := txscript.PayToAddrScript(witnessAddr)
+	if err != nil {
+		return nil, err
+	}
+	return btcutil.NewAddressScriptHash(redeemScript, params)
+}
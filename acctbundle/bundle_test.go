@@ -0,0 +1,211 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package acctbundle
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pkt-cash/btcutil/hdkeychain"
+	"github.com/pkt-cash/pktd/chaincfg"
+)
+
+// TestDescriptorChecksum ensures descriptor checksums match BIP 0380.
+func TestDescriptorChecksum(t *testing.T) {
+	desc, err := AddDescriptorChecksum("raw(deadbeef)")
+	if err != nil {
+		t.Fatalf("AddDescriptorChecksum: %v", err)
+	}
+	if desc != "raw(deadbeef)#89f8spxm" {
+		t.Fatalf("unexpected descriptor %q", desc)
+	}
+	if d, err := CheckDescriptor(desc); err != nil || d != "raw(deadbeef)" {
+		t.Fatalf("CheckDescriptor: %q, %v", d, err)
+	}
+	if _, err := CheckDescriptor("raw(deadbeef)#89f8spxn"); err != ErrBadChecksum {
+		t.Fatalf("CheckDescriptor with bad checksum: %v", err)
+	}
+	if _, err := DescriptorChecksum("raw(é)"); err != ErrInvalidDescriptor {
+		t.Fatalf("DescriptorChecksum with invalid character: %v", err)
+	}
+}
+
+// testAccountKey returns a master key and the key of its first account of the
+// passed purpose on the passed network.
+func testAccountKey(t *testing.T, purpose uint32,
+	params *chaincfg.Params) (*hdkeychain.ExtendedKey, *hdkeychain.ExtendedKey) {
+
+	master, err := hdkeychain.NewMaster(bytes.Repeat([]byte{1}, 32), params)
+	if err != nil {
+		t.Fatalf("NewMaster: %v", err)
+	}
+	key := master
+	for _, i := range []uint32{purpose, params.HDCoinType, 0} {
+		key, err = key.Child(hdkeychain.HardenedKeyStart + i)
+		if err != nil {
+			t.Fatalf("Child: %v", err)
+		}
+	}
+	return master, key
+}
+
+// TestBundle ensures a bundle only exports the public account key and derives
+// the addresses of the signing wallet.
+func TestBundle(t *testing.T) {
+	params := &chaincfg.PktMainNetParams
+	master, accountKey := testAccountKey(t, 84, params)
+	fp, err := Fingerprint(master)
+	if err != nil {
+		t.Fatalf("Fingerprint: %v", err)
+	}
+
+	b, err := New("default", 0, ScriptP2WPKH, fp, accountKey, 1000)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := b.Validate(params); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	key, err := b.AccountKey(params)
+	if err != nil {
+		t.Fatalf("AccountKey: %v", err)
+	}
+	if key.IsPrivate() {
+		t.Fatalf("bundle exports a private key")
+	}
+
+	// The addresses of the bundle are the ones of the private keys.
+	for _, branch := range []uint32{BranchReceive, BranchChange} {
+		branchKey, err := accountKey.Child(branch)
+		if err != nil {
+			t.Fatalf("Child: %v", err)
+		}
+		privKey, err := branchKey.Child(3)
+		if err != nil {
+			t.Fatalf("Child: %v", err)
+		}
+		want, err := privKey.Address(params)
+		if err != nil {
+			t.Fatalf("Address: %v", err)
+		}
+		addr, err := b.Address(branch, 3, params)
+		if err != nil {
+			t.Fatalf("Address: %v", err)
+		}
+		if !bytes.Equal(addr.ScriptAddress(), want.ScriptAddress()) {
+			t.Fatalf("branch %d: address %v does not match key",
+				branch, addr)
+		}
+	}
+
+	desc, err := b.Descriptor(BranchChange, params)
+	if err != nil {
+		t.Fatalf("Descriptor: %v", err)
+	}
+	wantPrefix := "wpkh([" + b.MasterFingerprint + "/84'/390'/0']" +
+		b.XPub + "/1/*)#"
+	if !strings.HasPrefix(desc, wantPrefix) {
+		t.Fatalf("unexpected descriptor %q", desc)
+	}
+	if _, err := CheckDescriptor(desc); err != nil {
+		t.Fatalf("CheckDescriptor: %v", err)
+	}
+
+	// Bundles are rejected on other networks.
+	if err := b.Validate(&chaincfg.TestNet3Params); err != ErrWrongNetwork {
+		t.Fatalf("Validate on another network: %v", err)
+	}
+
+	// Only account keys may be exported and imported.
+	if _, err := New("default", 0, ScriptP2WPKH, fp, master, 0); err != ErrNotAccountKey {
+		t.Fatalf("New with master key: %v", err)
+	}
+	private := *b
+	private.XPub = accountKey.String()
+	if err := private.Validate(params); err != ErrNotAccountKey {
+		t.Fatalf("Validate with private key: %v", err)
+	}
+	unknown := *b
+	unknown.ScriptType = "p2tr"
+	if err := unknown.Validate(params); err != ErrUnknownScriptType {
+		t.Fatalf("Validate with unknown script type: %v", err)
+	}
+}
+
+// TestBundleNestedAddress ensures nested witness addresses pay to the script
+// hash of the witness program of the key.
+func TestBundleNestedAddress(t *testing.T) {
+	params := &chaincfg.PktMainNetParams
+	_, accountKey := testAccountKey(t, 49, params)
+	b, err := New("nested", 0, ScriptP2SHP2WPKH, 0, accountKey, 0)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	addr, err := b.Address(BranchReceive, 0, params)
+	if err != nil {
+		t.Fatalf("Address: %v", err)
+	}
+	if !addr.IsForNet(params) || len(addr.ScriptAddress()) != 20 {
+		t.Fatalf("unexpected address %v", addr)
+	}
+	keyPath, err := b.KeyPath(params)
+	if err != nil || keyPath != "m/49'/390'/0'" {
+		t.Fatalf("KeyPath: %q, %v", keyPath, err)
+	}
+	desc, err := b.Descriptor(BranchReceive, params)
+	if err != nil || !strings.HasPrefix(desc, "sh(wpkh([00000000/49'") {
+		t.Fatalf("Descriptor: %q, %v", desc, err)
+	}
+}
+
+// TestKeyOrigin ensures key origins round trip through their descriptor and
+// PSBT forms.
+func TestKeyOrigin(t *testing.T) {
+	o, err := ParseKeyOrigin("[d34db33f/84h/390'/0'/1/7]")
+	if err != nil {
+		t.Fatalf("ParseKeyOrigin: %v", err)
+	}
+	want := []uint32{
+		hdkeychain.HardenedKeyStart + 84,
+		hdkeychain.HardenedKeyStart + 390,
+		hdkeychain.HardenedKeyStart, 1, 7,
+	}
+	if o.Fingerprint != 0xd34db33f || !reflect.DeepEqual(o.Path, want) {
+		t.Fatalf("unexpected key origin %+v", o)
+	}
+	if s := o.String(); s != "[d34db33f/84'/390'/0'/1/7]" {
+		t.Fatalf("unexpected string %q", s)
+	}
+
+	serialized := o.SerializePSBT()
+	if len(serialized) != 24 || serialized[0] != 0xd3 || serialized[4] != 84 ||
+		serialized[7] != 0x80 {
+
+		t.Fatalf("unexpected PSBT serialization %x", serialized)
+	}
+	parsed, err := ParsePSBTKeyOrigin(serialized)
+	if err != nil || !reflect.DeepEqual(parsed, o) {
+		t.Fatalf("ParsePSBTKeyOrigin: %+v, %v", parsed, err)
+	}
+	if _, err := ParsePSBTKeyOrigin(serialized[:5]); err == nil {
+		t.Fatalf("ParsePSBTKeyOrigin accepted a truncated origin")
+	}
+
+	child := o.Child(9)
+	if len(o.Path) != 5 || FormatPath(child.Path) != "m/84'/390'/0'/1/7/9" {
+		t.Fatalf("unexpected child %v of %v", child, o)
+	}
+
+	for _, s := range []string{"d34db33f/x", "d34d/0", "d34db33f/2147483648"} {
+		if _, err := ParseKeyOrigin(s); err != ErrInvalidKeyOrigin {
+			t.Errorf("ParseKeyOrigin(%q): %v", s, err)
+		}
+	}
+	if o, err := ParseKeyOrigin("d34db33f"); err != nil || len(o.Path) != 0 {
+		t.Fatalf("ParseKeyOrigin of a master key: %+v, %v", o, err)
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package acctbundle
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	// descriptorCharset lists the characters allowed in a descriptor,
+	// ordered so that the most common ones fall in the first group.
+	descriptorCharset = "0123456789()[],'/*abcdefgh@:$%{}" +
+		"IJKLMNOPQRSTUVWXYZ&+-.;<=>?!^_|~" +
+		"ijklmnopqrstuvwxyzABCDEFGH`#\"\\ "
+
+	// checksumCharset is the bech32 character set the checksum is
+	// written with.
+	checksumCharset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+	// checksumLength is the number of characters of a checksum.
+	checksumLength = 8
+)
+
+var (
+	// ErrInvalidDescriptor is returned when a descriptor contains a
+	// character which is not allowed.
+	ErrInvalidDescriptor = errors.New("invalid character in descriptor")
+
+	// ErrBadChecksum is returned when the checksum of a descriptor does
+	// not match it.
+	ErrBadChecksum = errors.New("descriptor checksum mismatch")
+)
+
+// polyMod adds the passed 5 bit value to the checksum c.
+func polyMod(c uint64, val int) uint64 {
+	c0 := c >> 35
+	c = (c&0x7ffffffff)<<5 ^ uint64(val)
+	if c0&1 != 0 {
+		c ^= 0xf5dee51989
+	}
+	if c0&2 != 0 {
+		c ^= 0xa9fdca3312
+	}
+	if c0&4 != 0 {
+		c ^= 0x1bab10e32d
+	}
+	if c0&8 != 0 {
+		c ^= 0x3706b1677a
+	}
+	if c0&16 != 0 {
+		c ^= 0x644d626ffd
+	}
+	return c
+}
+
+// DescriptorChecksum returns the BIP 0380 checksum of the passed descriptor,
+// which must not already have one.
+func DescriptorChecksum(desc string) (string, error) {
+	c := uint64(1)
+	cls, clsCount := 0, 0
+	for _, ch := range desc {
+		pos := strings.IndexRune(descriptorCharset, ch)
+		if pos < 0 {
+			return "", ErrInvalidDescriptor
+		}
+		c = polyMod(c, pos&31)
+		cls = cls*3 + pos>>5
+		if clsCount++; clsCount == 3 {
+			c = polyMod(c, cls)
+			cls, clsCount = 0, 0
+		}
+	}
+	if clsCount > 0 {
+		c = polyMod(c, cls)
+	}
+	for i := 0; i < checksumLength; i++ {
+		c = polyMod(c, 0)
+	}
+	c ^= 1
+
+	checksum := make([]byte, checksumLength)
+	for i := range checksum {
+		checksum[i] = checksumCharset[(c>>(5*uint(7-i)))&31]
+	}
+	return string(checksum), nil
+}
+
+// AddDescriptorChecksum returns the passed descriptor followed by its
+// checksum.
+func AddDescriptorChecksum(desc string) (string, error) {
+	checksum, err := DescriptorChecksum(desc)
+	if err != nil {
+		return "", err
+	}
+	return desc + "#" + checksum, nil
+}
+
+// CheckDescriptor returns the passed descriptor without its checksum after
+// verifying it.  Descriptors without a checksum are returned unchanged.
+func CheckDescriptor(desc string) (string, error) {
+	i := strings.LastIndexByte(desc, '#')
+	if i < 0 {
+		if _, err := DescriptorChecksum(desc); err != nil {
+			return "", err
+		}
+		return desc, nil
+	}
+	checksum, err := DescriptorChecksum(desc[:i])
+	if err != nil {
+		return "", err
+	}
+	if checksum != desc[i+1:] {
+		return "", ErrBadChecksum
+	}
+	return desc[:i], nil
+}
@@ -0,0 +1,26 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package acctbundle exports the public keys of a wallet account so another
+wallet can track the account without being able to spend from it.
+
+A Bundle holds the extended public key of a BIP 0044 style account along with
+the fingerprint of the master key, the script type of its addresses and the
+height the account was created at.  A watch-only wallet importing the bundle
+derives the same receive and change addresses as the signing wallet, which may
+then stay offline while the watch-only wallet reports the balance and history
+of the account.
+
+The bundle is also described by a pair of output script descriptors, for the
+receive and change branches, with the checksum defined by BIP 0380, so it can
+be imported by any wallet supporting descriptors.
+
+The origin of a key, the fingerprint of its master key and its derivation
+path, is described by a KeyOrigin, which converts between the forms used by
+descriptors, wallets and PSBTs.  Wallets record it for every key, including
+imported ones, so signers can recognize their keys and audits can rebuild the
+key tree.
+*/
+package acctbundle
@@ -0,0 +1,144 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package acctbundle
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkt-cash/btcutil/hdkeychain"
+)
+
+// ErrInvalidKeyOrigin is returned when a key origin or a derivation path can
+// not be parsed.
+var ErrInvalidKeyOrigin = errors.New("invalid key origin")
+
+// KeyOrigin is where a key comes from: the fingerprint of the master key it
+// is derived from and the derivation path from the master key.  It is the
+// information a PSBT carries for each key so signers can tell whether they
+// hold it.
+type KeyOrigin struct {
+	// Fingerprint is the fingerprint of the master key.
+	Fingerprint uint32
+
+	// Path is the derivation path from the master key, with hardened
+	// indexes offset by hdkeychain.HardenedKeyStart.
+	Path []uint32
+}
+
+// FormatPath returns the passed derivation path in the m/44'/0'/0'/0/1 form.
+func FormatPath(path []uint32) string {
+	var b strings.Builder
+	b.WriteString("m")
+	for _, i := range path {
+		if i >= hdkeychain.HardenedKeyStart {
+			fmt.Fprintf(&b, "/%d'", i-hdkeychain.HardenedKeyStart)
+		} else {
+			fmt.Fprintf(&b, "/%d", i)
+		}
+	}
+	return b.String()
+}
+
+// ParsePath parses a derivation path in the m/44'/0'/0'/0/1 form.  Hardened
+// indexes may be marked by h instead of an apostrophe, and the leading m is
+// optional.
+func ParsePath(s string) ([]uint32, error) {
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "m"), "/")
+	if s == "" {
+		return nil, nil
+	}
+	elems := strings.Split(s, "/")
+	path := make([]uint32, len(elems))
+	for i, elem := range elems {
+		hardened := strings.HasSuffix(elem, "'") ||
+			strings.HasSuffix(elem, "h")
+		if hardened {
+			elem = elem[:len(elem)-1]
+		}
+		index, err := strconv.ParseUint(elem, 10, 32)
+		if err != nil || index >= hdkeychain.HardenedKeyStart {
+			return nil, ErrInvalidKeyOrigin
+		}
+		path[i] = uint32(index)
+		if hardened {
+			path[i] += hdkeychain.HardenedKeyStart
+		}
+	}
+	return path, nil
+}
+
+// String returns the key origin in the [d34db33f/44'/0'/0'] form of
+// descriptors.
+func (o *KeyOrigin) String() string {
+	var fp [4]byte
+	binary.BigEndian.PutUint32(fp[:], o.Fingerprint)
+	return "[" + hex.EncodeToString(fp[:]) + FormatPath(o.Path)[1:] + "]"
+}
+
+// ParseKeyOrigin parses a key origin in the [d34db33f/44'/0'/0'] form of
+// descriptors.  The brackets are optional.
+func ParseKeyOrigin(s string) (*KeyOrigin, error) {
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+	fpHex := s
+	if i := strings.IndexByte(s, '/'); i >= 0 {
+		fpHex = s[:i]
+		s = s[i:]
+	} else {
+		s = ""
+	}
+	fp, err := hex.DecodeString(fpHex)
+	if err != nil || len(fp) != 4 {
+		return nil, ErrInvalidKeyOrigin
+	}
+	path, err := ParsePath(s)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyOrigin{
+		Fingerprint: binary.BigEndian.Uint32(fp),
+		Path:        path,
+	}, nil
+}
+
+// Child returns the origin of the child key at the passed index.
+func (o *KeyOrigin) Child(index uint32) *KeyOrigin {
+	path := make([]uint32, len(o.Path)+1)
+	copy(path, o.Path)
+	path[len(o.Path)] = index
+	return &KeyOrigin{Fingerprint: o.Fingerprint, Path: path}
+}
+
+// SerializePSBT returns the key origin in the form of the value of the BIP32
+// derivation fields of a PSBT: the fingerprint followed by the path, as little
+// endian 32 bit integers.
+func (o *KeyOrigin) SerializePSBT() []byte {
+	b := make([]byte, 4*(len(o.Path)+1))
+	binary.BigEndian.PutUint32(b, o.Fingerprint)
+	for i, index := range o.Path {
+		binary.LittleEndian.PutUint32(b[4*(i+1):], index)
+	}
+	return b
+}
+
+// ParsePSBTKeyOrigin parses the value of a BIP32 derivation field of a PSBT.
+func ParsePSBTKeyOrigin(b []byte) (*KeyOrigin, error) {
+	if len(b) < 4 || len(b)%4 != 0 {
+		return nil, fmt.Errorf("%v: length %d", ErrInvalidKeyOrigin,
+			len(b))
+	}
+	o := &KeyOrigin{
+		Fingerprint: binary.BigEndian.Uint32(b),
+		Path:        make([]uint32, len(b)/4-1),
+	}
+	for i := range o.Path {
+		o.Path[i] = binary.LittleEndian.Uint32(b[4*(i+1):])
+	}
+	return o, nil
+}
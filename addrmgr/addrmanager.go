@@ -17,6 +17,7 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -701,6 +702,108 @@ func (a *AddrManager) getAddresses() []*wire.NetAddress {
 	return addrs
 }
 
+// GoodAddresses returns up to count randomly chosen addresses which are not
+// considered bad, or all of them when count is zero.
+func (a *AddrManager) GoodAddresses(count int) []*wire.NetAddress {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	addrs := make([]*wire.NetAddress, 0, len(a.addrIndex))
+	for _, ka := range a.addrIndex {
+		if !ka.isBad() {
+			addrs = append(addrs, ka.na)
+		}
+	}
+	if count <= 0 || count > len(addrs) {
+		count = len(addrs)
+	}
+
+	// Fisher-Yates shuffle the first count addresses.
+	for i := 0; i < count; i++ {
+		j := a.rand.Intn(len(addrs)-i) + i
+		addrs[i], addrs[j] = addrs[j], addrs[i]
+	}
+	return addrs[:count]
+}
+
+// Stats describes the contents of the new and tried buckets of the address
+// manager.
+type Stats struct {
+	// New and Tried are the number of addresses in the new and tried
+	// buckets.
+	New   int
+	Tried int
+
+	// Bad is the number of addresses which are considered bad and are
+	// about to be evicted.
+	Bad int
+
+	// IPv4, IPv6 and Onion are the number of addresses by network.
+	IPv4  int
+	IPv6  int
+	Onion int
+
+	// NewBuckets and TriedBuckets are the number of buckets, of which
+	// NewBucketsUsed and TriedBucketsUsed hold at least one address.
+	NewBuckets       int
+	NewBucketsUsed   int
+	TriedBuckets     int
+	TriedBucketsUsed int
+
+	// NewBucketSize and TriedBucketSize are the capacity of a bucket, and
+	// NewBucketMax and TriedBucketMax the number of addresses in the
+	// fullest one.
+	NewBucketSize   int
+	NewBucketMax    int
+	TriedBucketSize int
+	TriedBucketMax  int
+}
+
+// Stats returns statistics about the addresses known to the address manager.
+func (a *AddrManager) Stats() *Stats {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	stats := &Stats{
+		New:             a.nNew,
+		Tried:           a.nTried,
+		NewBuckets:      newBucketCount,
+		TriedBuckets:    triedBucketCount,
+		NewBucketSize:   newBucketSize,
+		TriedBucketSize: triedBucketSize,
+	}
+	for _, ka := range a.addrIndex {
+		if ka.isBad() {
+			stats.Bad++
+		}
+		switch {
+		case IsIPv4(ka.na):
+			stats.IPv4++
+		case IsOnionCatTor(ka.na):
+			stats.Onion++
+		default:
+			stats.IPv6++
+		}
+	}
+	for _, bucket := range a.addrNew {
+		if len(bucket) > 0 {
+			stats.NewBucketsUsed++
+		}
+		if len(bucket) > stats.NewBucketMax {
+			stats.NewBucketMax = len(bucket)
+		}
+	}
+	for _, bucket := range a.addrTried {
+		if bucket.Len() > 0 {
+			stats.TriedBucketsUsed++
+		}
+		if bucket.Len() > stats.TriedBucketMax {
+			stats.TriedBucketMax = bucket.Len()
+		}
+	}
+	return stats
+}
+
 // reset resets the address manager by reinitialising the random source
 // and allocating fresh empty bucket storage.
 func (a *AddrManager) reset() {
@@ -1017,6 +1120,48 @@ func (a *AddrManager) AddLocalAddress(na *wire.NetAddress, priority AddressPrior
 	return nil
 }
 
+// RemoveLocalAddress stops advertising na, and returns whether it was a known
+// local address.
+func (a *AddrManager) RemoveLocalAddress(na *wire.NetAddress) bool {
+	a.lamtx.Lock()
+	defer a.lamtx.Unlock()
+
+	key := NetAddressKey(na)
+	if _, ok := a.localAddresses[key]; !ok {
+		return false
+	}
+	delete(a.localAddresses, key)
+	return true
+}
+
+// LocalAddress is a known local address along with its score, which is the
+// priority of the method it was discovered with, increased every time it is
+// discovered again.
+type LocalAddress struct {
+	NetAddress *wire.NetAddress
+	Score      AddressPriority
+}
+
+// LocalAddresses returns the known local addresses to advertise, sorted by
+// address.
+func (a *AddrManager) LocalAddresses() []LocalAddress {
+	a.lamtx.Lock()
+	defer a.lamtx.Unlock()
+
+	keys := make([]string, 0, len(a.localAddresses))
+	for key := range a.localAddresses {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	addrs := make([]LocalAddress, 0, len(keys))
+	for _, key := range keys {
+		la := a.localAddresses[key]
+		addrs = append(addrs, LocalAddress{NetAddress: la.na, Score: la.score})
+	}
+	return addrs
+}
+
 // getReachabilityFrom returns the relative reachability of the provided local
 // address to the provided remote address.
 func getReachabilityFrom(localAddr, remoteAddr *wire.NetAddress) int {
@@ -208,6 +208,46 @@ func TestAddLocalAddress(t *testing.T) {
 	}
 }
 
+func TestLocalAddresses(t *testing.T) {
+	amgr := addrmgr.New("testlocaladdresses", nil)
+	ipv4 := &wire.NetAddress{IP: net.ParseIP("204.124.1.1")}
+	ipv6 := &wire.NetAddress{IP: net.ParseIP("2620:100::1")}
+	amgr.AddLocalAddress(ipv6, addrmgr.InterfacePrio)
+	amgr.AddLocalAddress(ipv4, addrmgr.InterfacePrio)
+	amgr.AddLocalAddress(&wire.NetAddress{IP: net.ParseIP("192.168.0.100")},
+		addrmgr.InterfacePrio)
+
+	// Adding an address again with a higher priority increases its score.
+	amgr.AddLocalAddress(ipv4, addrmgr.BoundPrio)
+
+	localAddrs := amgr.LocalAddresses()
+	if len(localAddrs) != 2 {
+		t.Fatalf("LocalAddresses: got %d addresses, want 2", len(localAddrs))
+	}
+	if localAddrs[0].NetAddress != ipv4 ||
+		localAddrs[0].Score != addrmgr.BoundPrio+1 {
+		t.Errorf("LocalAddresses: unexpected address %s with score %d",
+			localAddrs[0].NetAddress.IP, localAddrs[0].Score)
+	}
+	if localAddrs[1].NetAddress != ipv6 ||
+		localAddrs[1].Score != addrmgr.InterfacePrio {
+		t.Errorf("LocalAddresses: unexpected address %s with score %d",
+			localAddrs[1].NetAddress.IP, localAddrs[1].Score)
+	}
+
+	// Removed addresses are no longer advertised.
+	if !amgr.RemoveLocalAddress(&wire.NetAddress{IP: ipv6.IP}) {
+		t.Fatal("RemoveLocalAddress: address not found")
+	}
+	if amgr.RemoveLocalAddress(ipv6) {
+		t.Fatal("RemoveLocalAddress: removed address found")
+	}
+	localAddrs = amgr.LocalAddresses()
+	if len(localAddrs) != 1 || localAddrs[0].NetAddress != ipv4 {
+		t.Fatalf("LocalAddresses: unexpected addresses %v", localAddrs)
+	}
+}
+
 func TestAttempt(t *testing.T) {
 	n := addrmgr.New("testattempt", lookupFunc)
 
@@ -314,6 +354,61 @@ func TestGood(t *testing.T) {
 	}
 }
 
+func TestGoodAddressesAndStats(t *testing.T) {
+	n := addrmgr.New("testgoodaddresses", lookupFunc)
+	if addrs := n.GoodAddresses(0); len(addrs) != 0 {
+		t.Fatalf("Expected no addresses, got %d", len(addrs))
+	}
+
+	const addrsToAdd = 10
+	addrs := make([]*wire.NetAddress, addrsToAdd)
+	for i := range addrs {
+		s := fmt.Sprintf("173.194.115.%d:8333", i+1)
+		var err error
+		addrs[i], err = n.DeserializeNetAddress(s, wire.SFNodeNetwork)
+		if err != nil {
+			t.Fatalf("Failed to turn %s into an address: %v", s, err)
+		}
+	}
+	srcAddr := wire.NewNetAddressIPPort(net.IPv4(173, 144, 173, 111), 8333, 0)
+	n.AddAddresses(addrs, srcAddr)
+	n.Good(addrs[0])
+
+	if got := len(n.GoodAddresses(0)); got != addrsToAdd {
+		t.Errorf("GoodAddresses(0): got %d addresses, want %d", got,
+			addrsToAdd)
+	}
+	if got := len(n.GoodAddresses(3)); got != 3 {
+		t.Errorf("GoodAddresses(3): got %d addresses, want 3", got)
+	}
+	if got := len(n.GoodAddresses(addrsToAdd * 2)); got != addrsToAdd {
+		t.Errorf("GoodAddresses(%d): got %d addresses, want %d",
+			addrsToAdd*2, got, addrsToAdd)
+	}
+
+	stats := n.Stats()
+	if stats.New != addrsToAdd-1 || stats.Tried != 1 {
+		t.Errorf("Unexpected new/tried counts %d/%d", stats.New,
+			stats.Tried)
+	}
+	if stats.IPv4 != addrsToAdd || stats.IPv6 != 0 || stats.Onion != 0 {
+		t.Errorf("Unexpected network counts %d/%d/%d", stats.IPv4,
+			stats.IPv6, stats.Onion)
+	}
+	if stats.Bad != 0 {
+		t.Errorf("Unexpected bad count %d", stats.Bad)
+	}
+	if stats.TriedBucketsUsed != 1 || stats.TriedBucketMax != 1 {
+		t.Errorf("Unexpected tried bucket stats %d/%d",
+			stats.TriedBucketsUsed, stats.TriedBucketMax)
+	}
+	if stats.NewBucketsUsed == 0 || stats.NewBucketsUsed > stats.NewBuckets ||
+		stats.NewBucketMax > stats.NewBucketSize {
+
+		t.Errorf("Unexpected new bucket stats %+v", stats)
+	}
+}
+
 func TestGetAddress(t *testing.T) {
 	n := addrmgr.New("testgetaddress", lookupFunc)
 
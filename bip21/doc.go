@@ -0,0 +1,17 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package bip21 implements creating and parsing PKT payment URIs.
+
+Payment URIs follow BIP 0021 using the pkt: scheme, for example:
+
+	pkt:pkt1qpvc9275lcn5suv6c0k3v0mq3xedcpfw2dvye33?amount=1.5&label=Alice
+
+The address is the only required part.  The amount is expressed in PKT and the
+label and message are free form text meant to be shown to the payer.  Unknown
+parameters are kept so they can be inspected by the caller, unless they are
+prefixed with req- in which case BIP 0021 requires the URI to be rejected.
+*/
+package bip21
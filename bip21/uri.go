@@ -0,0 +1,184 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bip21
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/btcutil/bech32"
+)
+
+// Scheme is the URI scheme of PKT payment URIs.
+const Scheme = "pkt"
+
+// reqPrefix is the prefix of parameters which must be understood in order to
+// make the payment.
+const reqPrefix = "req-"
+
+var (
+	// ErrBadScheme is returned when parsing a URI which does not use the
+	// pkt: scheme.
+	ErrBadScheme = errors.New("payment URI does not use the " + Scheme +
+		": scheme")
+
+	// ErrMissingAddress is returned when parsing a URI without an address.
+	ErrMissingAddress = errors.New("payment URI is missing the address")
+)
+
+// URI is a payment request which can be encoded as a PKT payment URI.  An
+// Amount of zero means the payer chooses the amount.
+type URI struct {
+	Address string
+	Amount  btcutil.Amount
+	Label   string
+	Message string
+
+	// Params holds any other parameters of the URI.
+	Params map[string]string
+}
+
+// String returns the URI encoded as a pkt: URI.
+func (u *URI) String() string {
+	return Scheme + ":" + u.Address + u.query()
+}
+
+// QRString returns the URI encoded in a form which can use the denser
+// alphanumeric mode of QR codes.  This is only the case for bech32 addresses,
+// which may be uppercased, and only when there are no other parameters since
+// their values are case sensitive.
+func (u *URI) QRString() string {
+	if u.Label != "" || u.Message != "" || len(u.Params) != 0 {
+		return u.String()
+	}
+	if _, _, err := bech32.Decode(u.Address); err != nil {
+		return u.String()
+	}
+	return strings.ToUpper(Scheme+":"+u.Address) + u.query()
+}
+
+// query returns the encoded query part of the URI, including the leading
+// question mark, or an empty string when there are no parameters.
+func (u *URI) query() string {
+	var params []string
+	if u.Amount != 0 {
+		amount := strconv.FormatFloat(u.Amount.ToBTC(), 'f', -1, 64)
+		params = append(params, "amount="+amount)
+	}
+	if u.Label != "" {
+		params = append(params, "label="+escape(u.Label))
+	}
+	if u.Message != "" {
+		params = append(params, "message="+escape(u.Message))
+	}
+
+	// Sort the remaining parameters so the encoding is deterministic.
+	keys := make([]string, 0, len(u.Params))
+	for k := range u.Params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		params = append(params, escape(k)+"="+escape(u.Params[k]))
+	}
+
+	if len(params) == 0 {
+		return ""
+	}
+	return "?" + strings.Join(params, "&")
+}
+
+// escape percent-encodes s for use in a URI query.  Spaces are encoded as %20
+// rather than + since BIP 0021 does not treat + as a space.
+func escape(s string) string {
+	return strings.Replace(url.QueryEscape(s), "+", "%20", -1)
+}
+
+// Parse parses a pkt: payment URI.  The address is not validated since that
+// requires knowing which network it is for, use btcutil.DecodeAddress with the
+// returned address for that.
+func Parse(uri string) (*URI, error) {
+	sep := strings.IndexByte(uri, ':')
+	if sep < 0 || !strings.EqualFold(uri[:sep], Scheme) {
+		return nil, ErrBadScheme
+	}
+	rest := uri[sep+1:]
+
+	u := URI{Address: rest}
+	var rawQuery string
+	if q := strings.IndexByte(rest, '?'); q >= 0 {
+		u.Address = rest[:q]
+		rawQuery = rest[q+1:]
+	}
+	if u.Address == "" {
+		return nil, ErrMissingAddress
+	}
+
+	// Uppercase URIs are only valid with bech32 addresses, which are
+	// lowercased since that is their canonical form.  Base58 addresses are
+	// case sensitive so they are kept as they are.
+	if strings.ToUpper(u.Address) == u.Address {
+		if _, _, err := bech32.Decode(u.Address); err == nil {
+			u.Address = strings.ToLower(u.Address)
+		}
+	}
+
+	if rawQuery == "" {
+		return &u, nil
+	}
+	for _, param := range strings.Split(rawQuery, "&") {
+		if param == "" {
+			continue
+		}
+		kv := strings.SplitN(param, "=", 2)
+		key, err := url.PathUnescape(kv[0])
+		if err != nil {
+			return nil, fmt.Errorf("malformed payment URI parameter "+
+				"%q: %v", kv[0], err)
+		}
+		var value string
+		if len(kv) == 2 {
+			value, err = url.PathUnescape(kv[1])
+			if err != nil {
+				return nil, fmt.Errorf("malformed value for payment "+
+					"URI parameter %q: %v", key, err)
+			}
+		}
+
+		switch strings.ToLower(key) {
+		case "amount":
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil || f < 0 {
+				return nil, fmt.Errorf("invalid payment URI "+
+					"amount %q", value)
+			}
+			u.Amount, err = btcutil.NewAmount(f)
+			if err != nil {
+				return nil, fmt.Errorf("invalid payment URI "+
+					"amount %q: %v", value, err)
+			}
+		case "label":
+			u.Label = value
+		case "message":
+			u.Message = value
+		default:
+			if strings.HasPrefix(strings.ToLower(key), reqPrefix) {
+				return nil, fmt.Errorf("payment URI requires "+
+					"unsupported parameter %q", key)
+			}
+			if u.Params == nil {
+				u.Params = make(map[string]string)
+			}
+			u.Params[key] = value
+		}
+	}
+
+	return &u, nil
+}
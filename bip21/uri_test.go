@@ -0,0 +1,112 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bip21
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pkt-cash/btcutil"
+)
+
+// TestURIRoundTrip ensures payment URIs are encoded as expected and parse back
+// into the same request.
+func TestURIRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		uri     URI
+		encoded string
+	}{
+		{
+			name:    "address only",
+			uri:     URI{Address: "pGzCSv5tPuPJrCpZ8XhRdW6x4pv9BzgWfN"},
+			encoded: "pkt:pGzCSv5tPuPJrCpZ8XhRdW6x4pv9BzgWfN",
+		},
+		{
+			name: "amount label and message",
+			uri: URI{
+				Address: "pGzCSv5tPuPJrCpZ8XhRdW6x4pv9BzgWfN",
+				Amount:  btcutil.Amount(3) * btcutil.SatoshiPerBitcoin / 2,
+				Label:   "Alice & Bob",
+				Message: "Order 1+1",
+			},
+			encoded: "pkt:pGzCSv5tPuPJrCpZ8XhRdW6x4pv9BzgWfN?amount=1.5" +
+				"&label=Alice%20%26%20Bob&message=Order%201%2B1",
+		},
+		{
+			name: "extra params",
+			uri: URI{
+				Address: "pGzCSv5tPuPJrCpZ8XhRdW6x4pv9BzgWfN",
+				Params:  map[string]string{"z": "1", "a": "2"},
+			},
+			encoded: "pkt:pGzCSv5tPuPJrCpZ8XhRdW6x4pv9BzgWfN?a=2&z=1",
+		},
+		{
+			name:    "uppercase base58 address",
+			uri:     URI{Address: "PGZCSV5TPUPJRCPZ8XHRDW6X4PV9BZGWFN"},
+			encoded: "pkt:PGZCSV5TPUPJRCPZ8XHRDW6X4PV9BZGWFN",
+		},
+	}
+
+	for _, test := range tests {
+		encoded := test.uri.String()
+		if encoded != test.encoded {
+			t.Errorf("%s: unexpected encoding - got %s, want %s",
+				test.name, encoded, test.encoded)
+			continue
+		}
+		parsed, err := Parse(encoded)
+		if err != nil {
+			t.Errorf("%s: unexpected parse error: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(parsed, &test.uri) {
+			t.Errorf("%s: mismatched parse - got %+v, want %+v",
+				test.name, parsed, test.uri)
+		}
+	}
+}
+
+// TestQRString ensures only URIs with bech32 addresses and no text are
+// uppercased for QR codes, and that the uppercased form parses.
+func TestQRString(t *testing.T) {
+	const addr = "pkt1qpvc9275lcn5suv6c0k3v0mq3xedcpfw2dvye33"
+	u := URI{Address: addr, Amount: btcutil.SatoshiPerBitcoin}
+	want := "PKT:PKT1QPVC9275LCN5SUV6C0K3V0MQ3XEDCPFW2DVYE33?amount=1"
+	if got := u.QRString(); got != want {
+		t.Fatalf("unexpected QR string - got %s, want %s", got, want)
+	}
+	parsed, err := Parse(want)
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	if !reflect.DeepEqual(parsed, &u) {
+		t.Fatalf("mismatched parse - got %+v, want %+v", parsed, u)
+	}
+
+	u.Label = "Alice"
+	if got := u.QRString(); got != u.String() {
+		t.Fatalf("URI with label was uppercased: %s", got)
+	}
+}
+
+// TestParseErrors ensures malformed payment URIs are rejected.
+func TestParseErrors(t *testing.T) {
+	tests := []string{
+		"bitcoin:pGzCSv5tPuPJrCpZ8XhRdW6x4pv9BzgWfN",
+		"pGzCSv5tPuPJrCpZ8XhRdW6x4pv9BzgWfN",
+		"pkt:",
+		"pkt:?amount=1",
+		"pkt:pGzCSv5tPuPJrCpZ8XhRdW6x4pv9BzgWfN?amount=abc",
+		"pkt:pGzCSv5tPuPJrCpZ8XhRdW6x4pv9BzgWfN?amount=-1",
+		"pkt:pGzCSv5tPuPJrCpZ8XhRdW6x4pv9BzgWfN?label=%zz",
+		"pkt:pGzCSv5tPuPJrCpZ8XhRdW6x4pv9BzgWfN?req-somethingnew=1",
+	}
+	for _, uri := range tests {
+		if _, err := Parse(uri); err == nil {
+			t.Errorf("Parse(%q) unexpectedly succeeded", uri)
+		}
+	}
+}
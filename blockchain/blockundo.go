@@ -0,0 +1,109 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"fmt"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/database"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// BlockUndo is the undo data of a block of the main chain, which is everything
+// needed to restore the UTXO set to its state before the block was connected:
+// the outputs created by the block are removed and the outputs it spent are
+// restored.
+type BlockUndo struct {
+	Hash     chainhash.Hash
+	PrevHash chainhash.Hash
+	Height   int32
+
+	// Created are the outputs created by the block, in the order of the
+	// transactions and outputs.  Provably unspendable outputs are not part
+	// of the UTXO set, so they are skipped.
+	Created []UtxoDelta
+
+	// Spent are the outputs spent by the block, one per input of every
+	// transaction but the coinbase, in the order of the transactions and
+	// inputs.
+	Spent []UtxoDelta
+}
+
+// NewBlockUndo returns the undo data of the passed block at the passed height,
+// the spent outputs being described by the passed spend journal entries of the
+// block, as returned by FetchSpendJournal.
+func NewBlockUndo(block *btcutil.Block, height int32, stxos []SpentTxOut) *BlockUndo {
+	undo := &BlockUndo{
+		Hash:     *block.Hash(),
+		PrevHash: block.MsgBlock().Header.PrevBlock,
+		Height:   height,
+	}
+	undo.Created, undo.Spent = BlockUtxoDeltas(block, height, stxos)
+
+	// The outputs of the genesis block are not part of the UTXO set.
+	if height == 0 {
+		undo.Created = nil
+	}
+	return undo
+}
+
+// FetchBlockUndo returns the undo data of the main chain block with the passed
+// hash.  The spend journal of a block is removed once it is disconnected, so
+// the undo data of a block which is not in the main chain is not available;
+// it is sent with the NTBlockDisconnected notification instead.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) FetchBlockUndo(hash *chainhash.Hash) (*BlockUndo, error) {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	node := b.index.LookupNode(hash)
+	if node == nil || !b.bestChain.Contains(node) {
+		str := fmt.Sprintf("block %s is not in the main chain", hash)
+		return nil, errNotInMainChain(str)
+	}
+
+	var undo *BlockUndo
+	err := b.db.View(func(dbTx database.Tx) error {
+		block, err := dbFetchBlockByNode(dbTx, node)
+		if err != nil {
+			return err
+		}
+		stxos, err := dbFetchSpendJournalEntry(dbTx, block)
+		if err != nil {
+			return err
+		}
+		undo = NewBlockUndo(block, node.height, stxos)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return undo, nil
+}
+
+// ResurrectTxns returns the transactions of the passed block, which must be
+// the block of the undo data, to return to the mempool once the block is
+// disconnected, and the outputs created by the block which no longer exist
+// afterwards, so their spenders in the mempool must be removed along with
+// their descendants.
+//
+// Every transaction but the coinbase is resurrected, so the outputs it created
+// exist again once it is accepted to the mempool.  The coinbase is only valid
+// in its block, so its outputs are gone for good.  They can only be spent in
+// the mempool when a reorg deeper than the coinbase maturity resurrected the
+// transactions of the later blocks spending them.
+func (u *BlockUndo) ResurrectTxns(block *btcutil.Block) (resurrect []*btcutil.Tx,
+	removed []wire.OutPoint) {
+
+	for i := range u.Created {
+		if u.Created[i].IsCoinBase {
+			removed = append(removed, u.Created[i].OutPoint)
+		}
+	}
+	return block.Transactions()[1:], removed
+}
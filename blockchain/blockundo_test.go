@@ -0,0 +1,79 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// TestBlockUndo ensures the undo data of a block matches the spent outputs to
+// the inputs spending them, and that every transaction but the coinbase is
+// resurrected while the coinbase outputs are removed.
+func TestBlockUndo(t *testing.T) {
+	newTx := func(prevOuts ...wire.OutPoint) *wire.MsgTx {
+		tx := wire.NewMsgTx(wire.TxVersion)
+		for i := range prevOuts {
+			tx.AddTxIn(wire.NewTxIn(&prevOuts[i], nil, nil))
+		}
+		tx.AddTxOut(wire.NewTxOut(1e8, []byte{0x51}))
+		tx.AddTxOut(wire.NewTxOut(0, []byte{0x6a}))
+		return tx
+	}
+
+	// The second transaction spends an output of the first one and a
+	// coinbase output from height 50.
+	coinbase := newTx(wire.OutPoint{Index: wire.MaxPrevOutIndex})
+	parent := newTx(wire.OutPoint{Index: 1})
+	child := newTx(wire.OutPoint{Hash: parent.TxHash()},
+		wire.OutPoint{Index: 2})
+	msgBlock := wire.NewMsgBlock(wire.NewBlockHeader(1, &chainhash.Hash{},
+		&chainhash.Hash{}, 0, 0))
+	for _, tx := range []*wire.MsgTx{coinbase, parent, child} {
+		msgBlock.AddTransaction(tx)
+	}
+	block := btcutil.NewBlock(msgBlock)
+	stxos := []SpentTxOut{
+		{Amount: 2e8, Height: 100},
+		{Amount: 1e8, Height: 200},
+		{Amount: 3e8, Height: 50, IsCoinBase: true},
+	}
+
+	undo := NewBlockUndo(block, 200, stxos)
+	if undo.Hash != *block.Hash() || undo.Height != 200 {
+		t.Fatalf("unexpected undo data %+v", undo)
+	}
+	if len(undo.Spent) != 3 ||
+		undo.Spent[1].OutPoint.Hash != parent.TxHash() ||
+		undo.Spent[2].Amount != 3e8 || !undo.Spent[2].IsCoinBase {
+
+		t.Fatalf("unexpected spent outputs %+v", undo.Spent)
+	}
+
+	// The unspendable outputs are not part of the UTXO set.
+	if len(undo.Created) != 3 {
+		t.Fatalf("got %d created outputs, want 3", len(undo.Created))
+	}
+
+	resurrect, removed := undo.ResurrectTxns(block)
+	if len(resurrect) != 2 || *resurrect[0].Hash() != parent.TxHash() ||
+		*resurrect[1].Hash() != child.TxHash() {
+
+		t.Fatalf("unexpected resurrected transactions %v", resurrect)
+	}
+	want := wire.OutPoint{Hash: coinbase.TxHash()}
+	if len(removed) != 1 || removed[0] != want {
+		t.Fatalf("got removed outputs %v, want %v", removed, want)
+	}
+
+	// The outputs of the genesis block are not part of the UTXO set.
+	if undo := NewBlockUndo(block, 0, stxos); len(undo.Created) != 0 {
+		t.Fatalf("unexpected created outputs %+v at height 0",
+			undo.Created)
+	}
+}
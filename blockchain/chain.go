@@ -9,6 +9,7 @@ package blockchain
 import (
 	"container/list"
 	"fmt"
+	"math/big"
 	"sync"
 	"time"
 
@@ -104,6 +105,8 @@ type BlockChain struct {
 	sigCache            *txscript.SigCache
 	indexManager        IndexManager
 	hashCache           *txscript.HashCache
+	blockRecorder       BlockRecorder
+	readOnly            bool
 
 	// The following fields are calculated based upon the provided chain
 	// parameters.  They are also set when the instance is created and
@@ -187,6 +190,25 @@ type BlockChain struct {
 	// certain blockchain events.
 	notificationsLock sync.RWMutex
 	notifications     []NotificationCallback
+
+	// events delivers the changes of the main chain to the subscriptions
+	// made with SubscribeChainEvents.
+	events chainEventBus
+
+	// validationStats holds the time spent checking the proofs and the
+	// scripts of the processed blocks.
+	validationStats validationStats
+
+	// recovery holds the repairs made to the chain state at startup.
+	recovery chainRecovery
+
+	// safeMode is set atomically while blocks are refused, see
+	// SetSafeMode.
+	safeMode int32
+
+	// staleBlocks records the recently seen blocks left out of the main
+	// chain.  It is protected by the chain lock.
+	staleBlocks staleBlocks
 }
 
 // HaveBlock returns whether or not the chain instance has the block represented
@@ -222,6 +244,36 @@ func (b *BlockChain) IsKnownOrphan(hash *chainhash.Hash) bool {
 	return exists
 }
 
+// BlockValidity returns whether the block with the passed hash is known to be
+// valid and whether it is known to be invalid, because it failed validation or
+// one of its ancestors did.  Blocks which are not in the block index, such as
+// orphans, and blocks which have not been fully validated yet, such as side
+// chain blocks, are neither.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) BlockValidity(hash *chainhash.Hash) (knownValid, knownInvalid bool) {
+	node := b.index.LookupNode(hash)
+	if node == nil {
+		return false, false
+	}
+	status := b.index.NodeStatus(node)
+	return status.KnownValid(), status.KnownInvalid()
+}
+
+// BlockWork returns the height of the block with the passed hash and the total
+// work of the chain ending with it.  Any block of the block index is known,
+// including side chain and invalid blocks, while orphans are not.  The last
+// return value is false when the block is unknown.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) BlockWork(hash *chainhash.Hash) (int32, *big.Int, bool) {
+	node := b.index.LookupNode(hash)
+	if node == nil {
+		return 0, nil, false
+	}
+	return node.height, new(big.Int).Set(node.workSum), true
+}
+
 // GetOrphanRoot returns the head of the chain for the provided hash from the
 // map of orphan blocks.
 //
@@ -684,6 +736,7 @@ func (b *BlockChain) connectBlock(node *blockNode, block *btcutil.Block,
 	b.chainLock.Unlock()
 	b.sendNotification(NTBlockConnected, block)
 	b.chainLock.Lock()
+	b.publishChainEvent(ChainEventConnected, node, block, stxos)
 
 	return nil
 }
@@ -735,6 +788,7 @@ func (b *BlockChain) disconnectBlock(node *blockNode, block *btcutil.Block, view
 	state := newBestState(prevNode, blockSize, blockWeight, numTxns,
 		newTotalTxns, prevNode.CalcPastMedianTime(), prevEs)
 
+	var stxos []SpentTxOut
 	err = b.db.Update(func(dbTx database.Tx) error {
 		// Update best block state.
 		err := dbPutBestState(dbTx, state, node.workSum)
@@ -759,7 +813,7 @@ func (b *BlockChain) disconnectBlock(node *blockNode, block *btcutil.Block, view
 
 		// Before we delete the spend journal entry for this back,
 		// we'll fetch it as is so the indexers can utilize if needed.
-		stxos, err := dbFetchSpendJournalEntry(dbTx, block)
+		stxos, err = dbFetchSpendJournalEntry(dbTx, block)
 		if err != nil {
 			return err
 		}
@@ -807,8 +861,13 @@ func (b *BlockChain) disconnectBlock(node *blockNode, block *btcutil.Block, view
 	// chain.  The caller would typically want to react with actions such as
 	// updating wallets.
 	b.chainLock.Unlock()
-	b.sendNotification(NTBlockDisconnected, block)
+	b.notify(&Notification{
+		Type: NTBlockDisconnected,
+		Data: block,
+		Undo: NewBlockUndo(block, node.height, stxos),
+	})
 	b.chainLock.Lock()
+	b.publishChainEvent(ChainEventDisconnected, node, block, stxos)
 
 	return nil
 }
@@ -1038,6 +1097,7 @@ func (b *BlockChain) reorganizeChain(detachNodes, attachNodes *list.List) error
 		if err != nil {
 			return err
 		}
+		b.addStaleNode(n, StaleReorganized)
 	}
 
 	// Connect the new best chain blocks.
@@ -1205,6 +1265,8 @@ func (b *BlockChain) connectBestChain(node *blockNode, block *btcutil.Block, fla
 	// We're extending (or creating) a side chain, but the cumulative
 	// work for this new side chain is not enough to make it the new chain.
 	if node.workSum.Cmp(b.bestChain.Tip().workSum) <= 0 {
+		b.addStaleNode(node, StaleSideChain)
+
 		// Log information about how the block is forking the chain.
 		fork := b.bestChain.FindFork(node)
 		if fork.hash.IsEqual(parentHash) {
@@ -1268,6 +1330,17 @@ func (b *BlockChain) isCurrent() bool {
 	return b.bestChain.Tip().timestamp >= minus24Hours
 }
 
+// updateBulkLoad puts the database in bulk loading mode while the chain is not
+// current, so the metadata of the blocks downloaded during the initial block
+// download is flushed in groups, when the database supports it.
+//
+// This function MUST be called with the chain state lock held (for reads).
+func (b *BlockChain) updateBulkLoad() {
+	if loader, ok := b.db.(database.BulkLoader); ok {
+		loader.SetBulkLoad(!b.isCurrent())
+	}
+}
+
 // IsCurrent returns whether or not the chain believes it is current.  Several
 // factors are used to guess, but the key factors that allow the chain to
 // believe it is current are:
@@ -1307,6 +1380,20 @@ func (b *BlockChain) HeaderByHash(hash *chainhash.Hash) (wire.BlockHeader, error
 	return node.Header(), nil
 }
 
+// PastMedianTimeByHash returns the median time of the block with the given
+// hash and the blocks before it, which is the time a block built on top of it
+// must exceed.  Note that the block may be part of a side chain.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) PastMedianTimeByHash(hash *chainhash.Hash) (time.Time, error) {
+	node := b.index.LookupNode(hash)
+	if node == nil {
+		return time.Time{}, fmt.Errorf("block %s is not known", hash)
+	}
+
+	return node.CalcPastMedianTime(), nil
+}
+
 // MainChainHasBlock returns whether or not the block with the given hash is in
 // the main chain.
 //
@@ -1420,6 +1507,45 @@ func (b *BlockChain) HeightRange(startHeight, endHeight int32) ([]chainhash.Hash
 	return hashes, nil
 }
 
+// HeadersByHeight returns the headers of the main chain blocks from the start
+// height up to, but not including, the end height.  The end height will be
+// limited to the current main chain height, and the headers all belong to the
+// same main chain even when it is reorganized concurrently.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) HeadersByHeight(startHeight, endHeight int32) ([]wire.BlockHeader, error) {
+	// Ensure requested heights are sane.
+	if startHeight < 0 {
+		return nil, fmt.Errorf("start height of fetch range must not "+
+			"be less than zero - got %d", startHeight)
+	}
+	if endHeight < startHeight {
+		return nil, fmt.Errorf("end height of fetch range must not "+
+			"be less than the start height - got start %d, end %d",
+			startHeight, endHeight)
+	}
+
+	// Grab a lock on the chain view to prevent it from changing due to a
+	// reorg while collecting the headers.
+	b.bestChain.mtx.Lock()
+	defer b.bestChain.mtx.Unlock()
+
+	// Limit the ending height to the latest height of the chain.
+	latestHeight := b.bestChain.tip().height
+	if endHeight > latestHeight+1 {
+		endHeight = latestHeight + 1
+	}
+	if startHeight >= endHeight {
+		return nil, nil
+	}
+
+	headers := make([]wire.BlockHeader, 0, endHeight-startHeight)
+	for i := startHeight; i < endHeight; i++ {
+		headers = append(headers, b.bestChain.nodeByHeight(i).Header())
+	}
+	return headers, nil
+}
+
 // HeightToHashRange returns a range of block hashes for the given start height
 // and end hash, inclusive on both ends.  The hashes are for all blocks that are
 // ancestors of endHash with height greater than or equal to startHeight.  The
@@ -1740,6 +1866,19 @@ type Config struct {
 	// This field can be nil if the caller is not interested in using a
 	// signature cache.
 	HashCache *txscript.HashCache
+
+	// BlockRecorder is notified of every block passed to ProcessBlock
+	// along with the adjusted time it was processed at and the outcome.
+	// It is used to record the consensus inputs of the chain so they can
+	// be replayed deterministically.
+	//
+	// This field can be nil if the caller does not wish to record blocks.
+	BlockRecorder BlockRecorder
+
+	// ReadOnly specifies the database is open read-only.  The chain state
+	// is then loaded as it is stored: New fails when it needs repairs
+	// instead of making them.
+	ReadOnly bool
 }
 
 // New returns a BlockChain instance using the provided configuration details.
@@ -1790,6 +1929,8 @@ func New(config *Config) (*BlockChain, error) {
 		blocksPerRetarget:   int32(targetTimespan / targetTimePerBlock),
 		index:               newBlockIndex(config.DB, params),
 		hashCache:           config.HashCache,
+		blockRecorder:       config.BlockRecorder,
+		readOnly:            config.ReadOnly,
 		bestChain:           newChainView(nil),
 		orphans:             make(map[chainhash.Hash]*orphanBlock),
 		prevOrphans:         make(map[chainhash.Hash][]*orphanBlock),
@@ -1809,6 +1950,13 @@ func New(config *Config) (*BlockChain, error) {
 		return nil, err
 	}
 
+	// Make sure the best block is consistent with the utxo set and the
+	// main chain index, which may not be the case when the node stopped
+	// in the middle of a write.
+	if err := b.recoverChainState(); err != nil {
+		return nil, err
+	}
+
 	// Initialize and catch up all of the currently active optional indexes
 	// as needed.
 	if config.IndexManager != nil {
@@ -1823,7 +1971,22 @@ func New(config *Config) (*BlockChain, error) {
 		return nil, err
 	}
 
+	// Connect the stored blocks which were left out of the best chain by
+	// an interrupted write, including those rolled back above.
+	if err := b.rollForward(); err != nil {
+		return nil, err
+	}
+
 	bestNode := b.bestChain.Tip()
+	if report := &b.recovery.report; len(report.Repairs) > 0 {
+		report.NewTip, report.NewHeight = bestNode.hash, bestNode.height
+		log.Warnf("Repaired the chain state with %d repairs: best "+
+			"block moved from %v (height %d) to %v (height %d), %d "+
+			"blocks rolled back and %d rolled forward",
+			len(report.Repairs), report.OldTip, report.OldHeight,
+			report.NewTip, report.NewHeight, report.RolledBack,
+			report.RolledForward)
+	}
 	log.Infof("Chain state (height %d, hash %v, totaltx %d, work %v)",
 		bestNode.height, bestNode.hash, b.stateSnapshot.TotalTxns,
 		bestNode.workSum)
@@ -0,0 +1,262 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// DefaultChainEventsBuffer is the default number of events buffered for a
+// subscription to the chain events.
+const DefaultChainEventsBuffer = 1000
+
+// ErrChainEventsOverflow is the error of a subscription to the chain events
+// ended because its buffer was full.  The subscriber should subscribe again
+// and catch up from the last event it processed.
+var ErrChainEventsOverflow = errors.New("chain events subscription buffer overflow")
+
+// ChainEventType is the type of a chain event.
+type ChainEventType int
+
+const (
+	// ChainEventConnected is the event of a block connected to the main
+	// chain.
+	ChainEventConnected ChainEventType = iota
+
+	// ChainEventDisconnected is the event of a block disconnected from the
+	// main chain.
+	ChainEventDisconnected
+)
+
+// String returns the ChainEventType in human-readable form.
+func (t ChainEventType) String() string {
+	switch t {
+	case ChainEventConnected:
+		return "connected"
+	case ChainEventDisconnected:
+		return "disconnected"
+	}
+	return fmt.Sprintf("Unknown ChainEventType (%d)", int(t))
+}
+
+// UtxoDelta is an unspent transaction output created or spent by a block.
+type UtxoDelta struct {
+	OutPoint   wire.OutPoint
+	Amount     int64
+	PkScript   []byte
+	Height     int32
+	IsCoinBase bool
+}
+
+// ChainEvent is a change of the main chain.  The created and spent outputs are
+// those of the block, so the changes are undone when it is disconnected: the
+// created outputs are removed and the spent ones are unspent again.
+//
+// The blocks buried under FinalityDepth blocks, up to FinalHeight, are
+// considered final by the chain: they can't be disconnected without also
+// invalidating the coinbase outputs spent since.
+type ChainEvent struct {
+	// Sequence is the number of the event, incremented for every event
+	// so subscribers can tell whether they missed any.
+	Sequence uint64
+
+	Type      ChainEventType
+	Block     *btcutil.Block
+	Hash      chainhash.Hash
+	PrevHash  chainhash.Hash
+	Height    int32
+	Timestamp time.Time
+	Bits      uint32
+	ChainWork *big.Int
+
+	Created []UtxoDelta
+	Spent   []UtxoDelta
+
+	FinalityDepth int32
+	FinalHeight   int32
+}
+
+// ChainEventSubscription is a subscription to the chain events.  The events are
+// received from C in order.  C is closed when the subscription ends, after
+// which Err returns why.
+type ChainEventSubscription struct {
+	C <-chan *ChainEvent
+
+	c   chan *ChainEvent
+	bus *chainEventBus
+	err error
+}
+
+// Err returns ErrChainEventsOverflow when the subscription ended because its
+// buffer was full and nil otherwise.  It must only be called once C is closed.
+func (s *ChainEventSubscription) Err() error {
+	return s.err
+}
+
+// Unsubscribe ends the subscription, closing C.  It is safe to call more than
+// once.
+func (s *ChainEventSubscription) Unsubscribe() {
+	s.bus.mtx.Lock()
+	s.bus.remove(s, nil)
+	s.bus.mtx.Unlock()
+}
+
+// chainEventBus delivers the chain events to the subscriptions.  Publishing
+// never blocks, a subscription whose buffer is full is ended instead, so a slow
+// subscriber can't stall the chain.
+type chainEventBus struct {
+	mtx      sync.Mutex
+	subs     map[*ChainEventSubscription]struct{}
+	sequence uint64
+}
+
+// subscribe adds a subscription with the passed buffer size.
+func (bus *chainEventBus) subscribe(buffer int) *ChainEventSubscription {
+	c := make(chan *ChainEvent, buffer)
+	s := &ChainEventSubscription{C: c, c: c, bus: bus}
+	bus.mtx.Lock()
+	if bus.subs == nil {
+		bus.subs = make(map[*ChainEventSubscription]struct{})
+	}
+	bus.subs[s] = struct{}{}
+	bus.mtx.Unlock()
+	return s
+}
+
+// remove ends the passed subscription with the passed error.  It must be called
+// with the bus lock held.
+func (bus *chainEventBus) remove(s *ChainEventSubscription, err error) {
+	if _, ok := bus.subs[s]; !ok {
+		return
+	}
+	delete(bus.subs, s)
+	s.err = err
+	close(s.c)
+}
+
+// hasSubscribers returns whether there is any subscription to the events.
+func (bus *chainEventBus) hasSubscribers() bool {
+	bus.mtx.Lock()
+	defer bus.mtx.Unlock()
+	return len(bus.subs) > 0
+}
+
+// publish numbers the passed event and delivers it to the subscriptions.
+func (bus *chainEventBus) publish(event *ChainEvent) {
+	bus.mtx.Lock()
+	defer bus.mtx.Unlock()
+
+	bus.sequence++
+	event.Sequence = bus.sequence
+	for s := range bus.subs {
+		select {
+		case s.c <- event:
+		default:
+			log.Warnf("Ending chain events subscription which fell "+
+				"%d events behind", cap(s.c))
+			bus.remove(s, ErrChainEventsOverflow)
+		}
+	}
+}
+
+// SubscribeChainEvents subscribes to the blocks connected to and disconnected
+// from the main chain, along with the outputs they create and spend.  Up to
+// buffer events are buffered for the subscriber, the subscription ending when
+// it falls further behind.  This is the integration point for the services
+// following the main chain, such as wallets and indexes.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) SubscribeChainEvents(buffer int) *ChainEventSubscription {
+	return b.events.subscribe(buffer)
+}
+
+// publishChainEvent publishes the event of the passed block, with the passed
+// spent outputs, being connected to or disconnected from the main chain.
+func (b *BlockChain) publishChainEvent(typ ChainEventType, node *blockNode,
+	block *btcutil.Block, stxos []SpentTxOut) {
+
+	if !b.events.hasSubscribers() {
+		return
+	}
+
+	header := &block.MsgBlock().Header
+	depth := int32(b.chainParams.CoinbaseMaturity)
+	tipHeight := node.height
+	if typ == ChainEventDisconnected {
+		tipHeight--
+	}
+	event := &ChainEvent{
+		Type:          typ,
+		Block:         block,
+		Hash:          *block.Hash(),
+		PrevHash:      header.PrevBlock,
+		Height:        node.height,
+		Timestamp:     header.Timestamp,
+		Bits:          header.Bits,
+		ChainWork:     new(big.Int).Set(node.workSum),
+		FinalityDepth: depth,
+		FinalHeight:   tipHeight - depth,
+	}
+	if event.FinalHeight < 0 {
+		event.FinalHeight = 0
+	}
+
+	event.Created, event.Spent = BlockUtxoDeltas(block, node.height, stxos)
+	b.events.publish(event)
+}
+
+// BlockUtxoDeltas returns the outputs created and spent by the passed block at
+// the passed height, the spent outputs being described by the passed spend
+// journal entries of the block, as returned by FetchSpendJournal.  Provably
+// unspendable outputs are not part of the UTXO set, so they are skipped.
+func BlockUtxoDeltas(block *btcutil.Block, height int32,
+	stxos []SpentTxOut) (created, spent []UtxoDelta) {
+
+	stxoIdx := 0
+	for txIdx, tx := range block.Transactions() {
+		isCoinBase := txIdx == 0
+		if !isCoinBase {
+			for _, txIn := range tx.MsgTx().TxIn {
+				if stxoIdx >= len(stxos) {
+					break
+				}
+				stxo := &stxos[stxoIdx]
+				stxoIdx++
+				spent = append(spent, UtxoDelta{
+					OutPoint:   txIn.PreviousOutPoint,
+					Amount:     stxo.Amount,
+					PkScript:   stxo.PkScript,
+					Height:     stxo.Height,
+					IsCoinBase: stxo.IsCoinBase,
+				})
+			}
+		}
+		for txOutIdx, txOut := range tx.MsgTx().TxOut {
+			if txscript.IsUnspendable(txOut.PkScript) {
+				continue
+			}
+			created = append(created, UtxoDelta{
+				OutPoint: wire.OutPoint{
+					Hash:  *tx.Hash(),
+					Index: uint32(txOutIdx),
+				},
+				Amount:     txOut.Value,
+				PkScript:   txOut.PkScript,
+				Height:     height,
+				IsCoinBase: isCoinBase,
+			})
+		}
+	}
+	return created, spent
+}
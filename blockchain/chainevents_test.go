@@ -0,0 +1,79 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/chaincfg"
+)
+
+// TestChainEvents ensures the chain events are delivered in order to the
+// subscriptions and that a subscriber falling behind is unsubscribed.
+func TestChainEvents(t *testing.T) {
+	params := &chaincfg.MainNetParams
+	chain := newFakeChain(params)
+	node := chain.bestChain.Tip()
+	block := btcutil.NewBlock(params.GenesisBlock)
+
+	// Nothing is published without subscribers.
+	chain.publishChainEvent(ChainEventConnected, node, block, nil)
+	if chain.events.sequence != 0 {
+		t.Fatal("event published without subscribers")
+	}
+
+	slow := chain.SubscribeChainEvents(1)
+	sub := chain.SubscribeChainEvents(DefaultChainEventsBuffer)
+	chain.publishChainEvent(ChainEventConnected, node, block, nil)
+	chain.publishChainEvent(ChainEventDisconnected, node, block, nil)
+
+	coinbase := block.Transactions()[0].MsgTx()
+	for i, typ := range []ChainEventType{ChainEventConnected, ChainEventDisconnected} {
+		event := <-sub.C
+		if event.Sequence != uint64(i+1) || event.Type != typ ||
+			event.Hash != *params.GenesisHash || event.Height != 0 ||
+			event.ChainWork.Cmp(node.workSum) != 0 {
+
+			t.Fatalf("unexpected event %d: %+v", i, event)
+		}
+		if event.FinalityDepth != int32(params.CoinbaseMaturity) ||
+			event.FinalHeight != 0 {
+
+			t.Fatalf("unexpected finality of event %d: %+v", i, event)
+		}
+		if len(event.Spent) != 0 || len(event.Created) != len(coinbase.TxOut) {
+			t.Fatalf("got %d created and %d spent outputs",
+				len(event.Created), len(event.Spent))
+		}
+		for j, created := range event.Created {
+			if created.OutPoint.Hash != coinbase.TxHash() ||
+				created.OutPoint.Index != uint32(j) ||
+				created.Amount != coinbase.TxOut[j].Value ||
+				!created.IsCoinBase {
+
+				t.Fatalf("unexpected created output %d: %+v", j,
+					created)
+			}
+		}
+	}
+
+	// The subscription which did not keep up was ended.
+	if event, ok := <-slow.C; !ok || event.Sequence != 1 {
+		t.Fatalf("unexpected first event %+v", event)
+	}
+	if _, ok := <-slow.C; ok || slow.Err() != ErrChainEventsOverflow {
+		t.Fatalf("slow subscription not ended (%v)", slow.Err())
+	}
+
+	sub.Unsubscribe()
+	sub.Unsubscribe()
+	if _, ok := <-sub.C; ok || sub.Err() != nil {
+		t.Fatalf("subscription not ended (%v)", sub.Err())
+	}
+	if chain.events.hasSubscribers() {
+		t.Fatal("subscriptions left")
+	}
+}
@@ -741,31 +741,15 @@ func deserializeUtxoEntry(serialized []byte) (*UtxoEntry, error) {
 }
 
 // dbFetchUtxoEntryByHash attempts to find and fetch a utxo for the given hash.
-// It uses a cursor and seek to try and do this as efficiently as possible.
+// It uses a prefix cursor to try and do this as efficiently as possible.
 //
 // When there are no entries for the provided hash, nil will be returned for the
 // both the entry and the error.
 func dbFetchUtxoEntryByHash(dbTx database.Tx, hash *chainhash.Hash) (*UtxoEntry, error) {
-	// Attempt to find an entry by seeking for the hash along with a zero
-	// index.  Due to the fact the keys are serialized as <hash><index>,
-	// where the index uses an MSB encoding, if there are any entries for
-	// the hash at all, one will be found.
-	cursor := dbTx.Metadata().Bucket(utxoSetBucketName).Cursor()
-	key := outpointKey(wire.OutPoint{Hash: *hash, Index: 0})
-	ok := cursor.Seek(*key)
-	recycleOutpointKey(key)
-	if !ok {
-		return nil, nil
-	}
-
-	// An entry was found, but it could just be an entry with the next
-	// highest hash after the requested one, so make sure the hashes
-	// actually match.
-	cursorKey := cursor.Key()
-	if len(cursorKey) < chainhash.HashSize {
-		return nil, nil
-	}
-	if !bytes.Equal(hash[:], cursorKey[:chainhash.HashSize]) {
+	// Due to the fact the keys are serialized as <hash><index>, all of the
+	// entries for the hash, if any, share the hash as a prefix.
+	cursor := dbTx.Metadata().Bucket(utxoSetBucketName).PrefixCursor(hash[:])
+	if !cursor.First() {
 		return nil, nil
 	}
 
@@ -1179,6 +1163,13 @@ func (b *BlockChain) initChainState() error {
 	}
 
 	if !initialized {
+		// A read-only database can only be used once it was
+		// initialized.
+		if b.readOnly {
+			return fmt.Errorf("the read-only database does not " +
+				"hold a chain state")
+		}
+
 		// At this point the database has not already been initialized, so
 		// initialize both it and the chain state to the genesis block.
 		return b.createChainState()
@@ -1266,23 +1257,27 @@ func (b *BlockChain) initChainState() error {
 			i++
 		}
 
-		// Set the best chain view to the stored best state.
+		// Set the best chain view to the stored best state.  When the
+		// block index was not written along with it, the missing nodes
+		// are restored from the stored blocks.
 		tip := b.index.LookupNode(&state.hash)
 		if tip == nil {
-			return AssertError(fmt.Sprintf("initChainState: cannot find "+
-				"chain tip %s in block index", state.hash))
+			tip, err = b.recoverIndexNodes(dbTx, &state.hash)
+			if err != nil {
+				return err
+			}
 		}
 		b.bestChain.SetTip(tip)
 
 		// Load the raw block bytes for the best block.
 		blockBytes, err := dbTx.FetchBlock(&state.hash)
 		if err != nil {
-			return err
+			return unrecoverableError(tip, "unable to load the data", err)
 		}
 		var block wire.MsgBlock
 		err = block.Deserialize(bytes.NewReader(blockBytes))
 		if err != nil {
-			return err
+			return unrecoverableError(tip, "unable to load the data", err)
 		}
 
 		// As a final consistency check, we'll run through all the
@@ -1290,7 +1285,22 @@ func (b *BlockChain) initChainState() error {
 		// them as valid if they aren't already marked as such.  This
 		// is a safe assumption as all the block before the current tip
 		// are valid by definition.
+		var invalid int
 		for iterNode := tip; iterNode != nil; iterNode = iterNode.parent {
+			// A block of the best chain can't be invalid, so clear
+			// the flags left by an interrupted write.
+			if iterNode.status.KnownInvalid() {
+				if b.readOnly {
+					return readOnlyError("block %v (height "+
+						"%d) of the best chain is flagged "+
+						"invalid", iterNode.hash,
+						iterNode.height)
+				}
+				b.index.UnsetStatusFlags(iterNode,
+					statusValidateFailed|statusInvalidAncestor)
+				invalid++
+			}
+
 			// If this isn't already marked as valid in the index, then
 			// we'll mark it as valid now to ensure consistency once
 			// we're up and running.
@@ -1304,9 +1314,19 @@ func (b *BlockChain) initChainState() error {
 			}
 		}
 
+		if invalid > 0 {
+			b.repaired("cleared the invalid status of %d blocks of "+
+				"the best chain", invalid)
+		}
+
+		// The best block is rolled back by recoverChainState when its
+		// election state is missing.
 		esState, err := dbFetchElectionStateByNode(dbTx, tip)
 		if err != nil {
-			return err
+			log.Warnf("Unable to load the election state of the "+
+				"best block %v: %v", tip.hash, err)
+			b.recovery.badElectionState = true
+			esState = &ElectionState{}
 		}
 
 		// Initialize the state related to the best block.
@@ -1324,7 +1344,12 @@ func (b *BlockChain) initChainState() error {
 
 	// As we might have updated the index after it was loaded, we'll
 	// attempt to flush the index to the DB. This will only result in a
-	// write if the elements are dirty, so it'll usually be a noop.
+	// write if the elements are dirty, so it'll usually be a noop.  The
+	// nodes marked valid above are only updated in memory when the
+	// database is read-only.
+	if b.readOnly {
+		return nil
+	}
 	return b.index.flushToDB()
 }
 
@@ -1399,17 +1424,9 @@ func dbFetchBlockByNode(dbTx database.Tx, node *blockNode) (*btcutil.Block, erro
 // index bucket. This overwrites the current entry if there exists one.
 func dbStoreBlockNode(dbTx database.Tx, node *blockNode) error {
 	// Serialize block data to be stored.
-	w := bytes.NewBuffer(make([]byte, 0, blockHdrSize+1))
 	header := node.Header()
-	err := header.Serialize(w)
-	if err != nil {
-		return err
-	}
-	err = w.WriteByte(byte(node.status))
-	if err != nil {
-		return err
-	}
-	value := w.Bytes()
+	value := header.AppendTo(make([]byte, 0, blockHdrSize+1))
+	value = append(value, byte(node.status))
 
 	// Write block header data to block index bucket.
 	blockIndexBucket := dbTx.Metadata().Bucket(blockIndexBucketName)
@@ -0,0 +1,349 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package consensusvectors
+
+import (
+	"bytes"
+	"encoding/binary"
+	"flag"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt"
+	"github.com/pkt-cash/pktd/btcec"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/chaincfg/globalcfg"
+	"github.com/pkt-cash/pktd/database"
+	_ "github.com/pkt-cash/pktd/database/ffldb"
+	"github.com/pkt-cash/pktd/mining"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// update regenerates the corpus in testdata rather than comparing with it.
+var update = flag.Bool("update", false, "update the corpus in testdata")
+
+// corpusFile is the corpus checked by the tests.
+const corpusFile = "testdata/corpus.json"
+
+// generateCorpus generates a corpus from headers, proofs and scripts built for
+// the test.
+func generateCorpus(t *testing.T) *Corpus {
+	params := &chaincfg.MainNetParams
+	g := NewGenerator(params)
+
+	// Headers following the genesis block.
+	genesis := &params.GenesisBlock.Header
+	prevHash := *params.GenesisHash
+	var header wire.BlockHeader
+	for height := int32(1); height <= 2; height++ {
+		header = wire.BlockHeader{
+			Version:    1,
+			PrevBlock:  prevHash,
+			MerkleRoot: chainhash.Hash{byte(height)},
+			Timestamp: genesis.Timestamp.Add(time.Duration(height) *
+				params.TargetTimePerBlock),
+			Bits:  genesis.Bits,
+			Nonce: uint32(height),
+		}
+		g.AddHeader(&header, height, &prevHash, genesis.Bits)
+		prevHash = header.BlockHash()
+	}
+
+	// Difficulty changes within and beyond the retarget adjustment.
+	retarget := int32(params.TargetTimespan / params.TargetTimePerBlock)
+	g.AddTransition(retarget, 0x1b0404cb, 0x1b038dee)
+	g.AddTransition(retarget, 0x1b0404cb, 0x1a00ffff)
+	g.AddTransition(retarget+1, 0x1b0404cb, 0x1b038dee)
+	for _, bits := range []uint32{0x1b0404cb, 0x04923456, 0x01003456,
+		0x05009234, 0x20123456} {
+
+		g.AddCompact(bits)
+	}
+
+	// A block with a PacketCrypt proof which is not valid.
+	coinbase := wire.NewMsgTx(1)
+	coinbase.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Index: math.MaxUint32},
+		SignatureScript:  []byte{0x01, 0x03},
+		Sequence:         wire.MaxTxInSequenceNum,
+	})
+	cbc := wire.NewPcCoinbaseCommit()
+	binary.LittleEndian.PutUint32(cbc.Bytes[4:8], 0x2000ffff)
+	binary.LittleEndian.PutUint64(cbc.Bytes[40:], 1024)
+	packetcrypt.InsertCoinbaseCommit(coinbase, cbc)
+	block := wire.MsgBlock{
+		Header:       header,
+		Transactions: []*wire.MsgTx{coinbase},
+		Pcp:          &wire.PacketCryptProof{AnnProof: make([]byte, 32)},
+	}
+	annParentHashes := []*chainhash.Hash{params.GenesisHash,
+		params.GenesisHash, params.GenesisHash, params.GenesisHash}
+	if err := g.AddProof(&block, 3, annParentHashes); err != nil {
+		t.Fatalf("AddProof: %v", err)
+	}
+
+	// A transaction spending a pay-to-pubkey-hash and a pay-to-witness-
+	// pubkey-hash output.
+	privKey, _ := btcec.PrivKeyFromBytes(btcec.S256(),
+		bytes.Repeat([]byte{0x01}, 32))
+	pkHash := btcutil.Hash160(privKey.PubKey().SerializeCompressed())
+	p2pkh, err := txscript.NewScriptBuilder().AddOp(txscript.OP_DUP).
+		AddOp(txscript.OP_HASH160).AddData(pkHash).
+		AddOp(txscript.OP_EQUALVERIFY).AddOp(txscript.OP_CHECKSIG).Script()
+	if err != nil {
+		t.Fatalf("Script: %v", err)
+	}
+	p2wpkh, err := txscript.NewScriptBuilder().AddOp(txscript.OP_0).
+		AddData(pkHash).Script()
+	if err != nil {
+		t.Fatalf("Script: %v", err)
+	}
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: chainhash.Hash{1}}, nil, nil))
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: chainhash.Hash{2}}, nil, nil))
+	tx.AddTxOut(wire.NewTxOut(2500, p2pkh))
+	tx.TxIn[0].SignatureScript, err = txscript.SignatureScript(tx, 0,
+		p2pkh, txscript.SigHashAll, privKey, true)
+	if err != nil {
+		t.Fatalf("SignatureScript: %v", err)
+	}
+	tx.TxIn[1].Witness, err = txscript.WitnessSignature(tx,
+		txscript.NewTxSigHashes(tx), 1, 2000, p2wpkh, txscript.SigHashAll,
+		privKey, true)
+	if err != nil {
+		t.Fatalf("WitnessSignature: %v", err)
+	}
+	g.AddScript(tx, 0, p2pkh, 1000, txscript.ConsensusVerifyFlags)
+	g.AddScript(tx, 1, p2wpkh, 2000, txscript.ConsensusVerifyFlags)
+
+	return g.Corpus()
+}
+
+// TestGenerate ensures the generated vectors have the expected results and
+// match the corpus in testdata.
+func TestGenerate(t *testing.T) {
+	c := generateCorpus(t)
+
+	// The headers and their variants with a wrong previous block and a
+	// difficulty above the proof of work limit.
+	valid := []bool{true, false, false, true, false, false}
+	if len(c.Headers) != len(valid) {
+		t.Fatalf("got %d headers, want %d", len(c.Headers), len(valid))
+	}
+	for i, v := range c.Headers {
+		if v.Valid != valid[i] {
+			t.Errorf("header %d (%s): got valid %v, want %v", i,
+				v.Comment, v.Valid, valid[i])
+		}
+	}
+
+	// The transitions of the headers, then those at and after a retarget.
+	permitted := []bool{true, true, true, false, false}
+	if len(c.Transitions) != len(permitted) {
+		t.Fatalf("got %d transitions, want %d", len(c.Transitions),
+			len(permitted))
+	}
+	for i, v := range c.Transitions {
+		if v.Permitted != permitted[i] {
+			t.Errorf("transition %d: got permitted %v, want %v", i,
+				v.Permitted, permitted[i])
+		}
+	}
+
+	if len(c.Compact) != 6 {
+		t.Errorf("got %d compact vectors, want 6", len(c.Compact))
+	}
+	if len(c.Proofs) != 2 || c.Proofs[0].Valid || c.Proofs[1].Valid {
+		t.Errorf("got proofs %+v, want 2 invalid proofs", c.Proofs)
+	}
+	if len(c.EffectiveTargets) != 1 {
+		t.Errorf("got %d effective targets, want 1",
+			len(c.EffectiveTargets))
+	}
+
+	// The valid inputs, with a tampered signature and, for the witness
+	// input, spending a different amount.
+	results := []string{ScriptOK, "ErrEvalFalse", ScriptOK,
+		"ErrEvalFalse", "ErrEvalFalse"}
+	if len(c.Scripts) != len(results) {
+		t.Fatalf("got %d scripts, want %d", len(c.Scripts), len(results))
+	}
+	for i, v := range c.Scripts {
+		if v.Result != results[i] {
+			t.Errorf("script %d (%s): got result %s, want %s", i,
+				v.Comment, v.Result, results[i])
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := c.Write(&buf); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if *update {
+		if err := ioutil.WriteFile(corpusFile, buf.Bytes(), 0644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+		return
+	}
+	want, err := ioutil.ReadFile(corpusFile)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("the generated corpus differs from %s, run the tests "+
+			"with -update if the change is intended", corpusFile)
+	}
+}
+
+// TestRun ensures the corpus in testdata passes and vectors with a different
+// result are reported.
+func TestRun(t *testing.T) {
+	serialized, err := ioutil.ReadFile(corpusFile)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	c, err := Read(bytes.NewReader(serialized))
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	params := &chaincfg.MainNetParams
+	failures, err := Run(params, c)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	for _, f := range failures {
+		t.Errorf("unexpected failure: %v", &f)
+	}
+
+	if _, err := Run(&chaincfg.PktMainNetParams, c); err == nil {
+		t.Errorf("Run: no error for a corpus of another network")
+	}
+
+	c.Headers[0].Valid = false
+	c.Headers[1].Hash = c.Headers[0].Hash
+	c.Compact[0].Work = "1"
+	c.Transitions[3].Permitted = true
+	c.EffectiveTargets[0].Effective++
+	c.Proofs[1].Valid = true
+	c.Scripts[2].Result = "ErrEvalFalse"
+	c.Scripts[3].Tx = "00"
+	failures, err = Run(params, c)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	want := []struct {
+		kind  string
+		index int
+	}{
+		{"headers", 0}, {"headers", 1}, {"compact", 0},
+		{"transitions", 3}, {"effectivetargets", 0}, {"proofs", 1},
+		{"scripts", 2}, {"scripts", 3},
+	}
+	if len(failures) != len(want) {
+		t.Fatalf("got %d failures, want %d: %v", len(failures), len(want),
+			failures)
+	}
+	for i, f := range failures {
+		if f.Kind != want[i].kind || f.Index != want[i].index {
+			t.Errorf("failure %d: got %s %d, want %s %d", i, f.Kind,
+				f.Index, want[i].kind, want[i].index)
+		}
+	}
+}
+
+// TestAddChain ensures the vectors of the blocks of a chain pass.
+func TestAddChain(t *testing.T) {
+	params := &chaincfg.RegressionNetParams
+	if !globalcfg.SelectConfig(params.GlobalConf) {
+		t.Fatal("globalcfg.SelectConfig() called twice")
+	}
+	defer globalcfg.RemoveConfig()
+
+	dir, err := ioutil.TempDir("", "pktd-consensusvectors")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	db, err := database.Create("ffldb", filepath.Join(dir, "db"), params.Net)
+	if err != nil {
+		t.Fatalf("database.Create: %v", err)
+	}
+	defer db.Close()
+	chain, err := blockchain.New(&blockchain.Config{
+		DB:          db,
+		ChainParams: params,
+		TimeSource:  blockchain.NewMedianTime(),
+	})
+	if err != nil {
+		t.Fatalf("blockchain.New: %v", err)
+	}
+
+	// Extend the chain with blocks holding a coinbase transaction.
+	const numBlocks = 3
+	timestamp := params.GenesisBlock.Header.Timestamp
+	for height := int32(1); height <= numBlocks; height++ {
+		best := chain.BestSnapshot()
+		coinbase, err := mining.CreateCoinbaseTx(params, height, 0, nil,
+			best.Elect.NetworkSteward)
+		if err != nil {
+			t.Fatalf("CreateCoinbaseTx: %v", err)
+		}
+		timestamp = timestamp.Add(params.TargetTimePerBlock)
+		block := &wire.MsgBlock{
+			Header: wire.BlockHeader{
+				Version:    0x20000000,
+				PrevBlock:  best.Hash,
+				MerkleRoot: coinbase.MsgTx().TxHash(),
+				Timestamp:  timestamp,
+				Bits:       params.PowLimitBits,
+			},
+			Transactions: []*wire.MsgTx{coinbase.MsgTx()},
+		}
+		target := blockchain.CompactToBig(block.Header.Bits)
+		for {
+			hash := block.Header.BlockHash()
+			if blockchain.HashToBig(&hash).Cmp(target) <= 0 {
+				break
+			}
+			block.Header.Nonce++
+		}
+		_, isOrphan, err := chain.ProcessBlock(btcutil.NewBlock(block),
+			blockchain.BFNone)
+		if err != nil || isOrphan {
+			t.Fatalf("ProcessBlock: %v, orphan %v", err, isOrphan)
+		}
+	}
+
+	g := NewGenerator(params)
+	if err := g.AddChain(chain, 0, numBlocks, 10); err != nil {
+		t.Fatalf("AddChain: %v", err)
+	}
+	c := g.Corpus()
+	if len(c.Headers) != numBlocks*3 || len(c.Transitions) != numBlocks {
+		t.Fatalf("got %d headers and %d transitions, want %d and %d",
+			len(c.Headers), len(c.Transitions), numBlocks*3, numBlocks)
+	}
+	for i := 0; i < len(c.Headers); i += 3 {
+		if !c.Headers[i].Valid {
+			t.Errorf("header %d (%s) is not valid", i,
+				c.Headers[i].Comment)
+		}
+	}
+	failures, err := Run(params, c)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	for _, f := range failures {
+		t.Errorf("unexpected failure: %v", &f)
+	}
+}
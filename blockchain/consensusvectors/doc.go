@@ -0,0 +1,25 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package consensusvectors exports consensus test vectors along with their
+expected results, as computed by pktd, and checks corpora of such vectors
+against the consensus code.
+
+A corpus holds block headers, difficulty conversions and transitions,
+PacketCrypt effective targets and proofs, and transaction scripts.  Its JSON
+format, described in the README, only uses hex strings and numbers so that
+alternative implementations can check they reach the same results, and so that
+refactors of pktd can be checked against a corpus generated before them.
+
+A Generator computes the vectors, either from blocks passed one at a time or
+from the main chain of a node.  Along with each vector taken from the chain,
+it adds variants which were tampered with, so a corpus generated from valid
+blocks covers invalid cases as well.  Run checks a corpus and reports the
+vectors whose result differs.
+
+The consensusvectors command generates and checks corpora from the command
+line.  The corpus in testdata is checked by the tests of this package.
+*/
+package consensusvectors
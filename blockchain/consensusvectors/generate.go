@@ -0,0 +1,316 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package consensusvectors
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/spv"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// Generator builds a corpus, computing the expected result of every vector
+// with the consensus code of the node.  Along with each vector added, it adds
+// variants which were tampered with so the corpus covers invalid cases too.
+type Generator struct {
+	params *chaincfg.Params
+	corpus Corpus
+	bits   map[uint32]struct{}
+}
+
+// NewGenerator returns a generator of a corpus of the passed network.
+func NewGenerator(params *chaincfg.Params) *Generator {
+	return &Generator{
+		params: params,
+		corpus: Corpus{Version: Version, Network: params.Name},
+		bits:   make(map[uint32]struct{}),
+	}
+}
+
+// Corpus returns the corpus built so far.
+func (g *Generator) Corpus() *Corpus {
+	c := g.corpus
+	return &c
+}
+
+// AddHeader adds the passed header of the block at the passed height following
+// the header with the passed hash and difficulty bits, along with a variant
+// which does not connect to it and one with a different difficulty.  The
+// difficulty transition and the difficulty of the header are added as well.
+func (g *Generator) AddHeader(header *wire.BlockHeader, height int32,
+	prevHash *chainhash.Hash, prevBits uint32) {
+
+	comment := fmt.Sprintf("block at height %d", height)
+	g.addHeader(comment, header, height, prevHash, prevBits)
+
+	tampered := *header
+	tampered.PrevBlock[0] ^= 1
+	g.addHeader(comment+" with a wrong previous block", &tampered, height,
+		prevHash, prevBits)
+
+	tampered = *header
+	tampered.Bits++
+	g.addHeader(comment+" with a different difficulty", &tampered, height,
+		prevHash, prevBits)
+
+	g.AddTransition(height, prevBits, header.Bits)
+	g.AddCompact(header.Bits)
+}
+
+// addHeader adds a header vector.
+func (g *Generator) addHeader(comment string, header *wire.BlockHeader,
+	height int32, prevHash *chainhash.Hash, prevBits uint32) {
+
+	hash, err := spv.CheckHeader(g.params, header, height, prevHash, prevBits)
+	g.corpus.Headers = append(g.corpus.Headers, HeaderVector{
+		Comment:  comment,
+		Height:   height,
+		Header:   hex.EncodeToString(header.AppendTo(nil)),
+		PrevHash: prevHash.String(),
+		PrevBits: prevBits,
+		Hash:     hash.String(),
+		Valid:    err == nil,
+	})
+}
+
+// AddTransition adds the change of difficulty from oldBits to newBits at the
+// passed height.
+func (g *Generator) AddTransition(height int32, oldBits, newBits uint32) {
+	g.corpus.Transitions = append(g.corpus.Transitions, TransitionVector{
+		Comment:   fmt.Sprintf("block at height %d", height),
+		Height:    height,
+		OldBits:   oldBits,
+		NewBits:   newBits,
+		Permitted: spv.PermittedDifficultyTransition(g.params, height, oldBits, newBits),
+	})
+}
+
+// AddCompact adds the passed difficulty bits unless they were already added.
+func (g *Generator) AddCompact(bits uint32) {
+	if _, ok := g.bits[bits]; ok {
+		return
+	}
+	g.bits[bits] = struct{}{}
+
+	target := spv.CompactToBig(bits)
+	g.corpus.Compact = append(g.corpus.Compact, CompactVector{
+		Bits:    bits,
+		Target:  fmt.Sprintf("%064x", target),
+		Work:    spv.CalcWork(bits).String(),
+		Compact: spv.BigToCompact(target),
+	})
+}
+
+// AddProof adds the PacketCrypt proof of the passed block at the passed
+// height, whose announcements were mined on top of the blocks with the passed
+// hashes, along with a variant with a different nonce.  The effective target
+// of the block is added as well.  Nothing is added for a block without a
+// proof.
+func (g *Generator) AddProof(block *wire.MsgBlock, height int32,
+	annParentHashes []*chainhash.Hash) error {
+
+	if block.Pcp == nil || len(block.Transactions) == 0 {
+		return nil
+	}
+	comment := fmt.Sprintf("block at height %d", height)
+	err := g.addProof(comment, block, height, annParentHashes)
+	if err != nil {
+		return err
+	}
+
+	pcp := *block.Pcp
+	pcp.Nonce++
+	tampered := *block
+	tampered.Pcp = &pcp
+	err = g.addProof(comment+" with a different nonce", &tampered, height,
+		annParentHashes)
+	if err != nil {
+		return err
+	}
+
+	cbc := packetcrypt.ExtractCoinbaseCommit(block.Transactions[0])
+	if cbc != nil {
+		g.corpus.EffectiveTargets = append(g.corpus.EffectiveTargets,
+			EffectiveTargetVector{
+				Bits:       block.Header.Bits,
+				MinAnnBits: cbc.AnnMinDifficulty(),
+				AnnCount:   cbc.AnnCount(),
+				Effective: spv.GetEffectiveTarget(block.Header.Bits,
+					cbc.AnnMinDifficulty(), cbc.AnnCount()),
+			})
+	}
+	return nil
+}
+
+// addProof adds a proof vector.  Only the coinbase transaction of the block is
+// part of it since the proof does not commit to the other transactions.
+func (g *Generator) addProof(comment string, block *wire.MsgBlock,
+	height int32, annParentHashes []*chainhash.Hash) error {
+
+	var proof bytes.Buffer
+	if err := block.Pcp.Serialize(&proof); err != nil {
+		return err
+	}
+	hashes := make([]string, len(annParentHashes))
+	for i, hash := range annParentHashes {
+		hashes[i] = hash.String()
+	}
+
+	mb := wire.MsgBlock{
+		Header:       block.Header,
+		Transactions: block.Transactions[:1],
+		Pcp:          block.Pcp,
+	}
+	_, err := packetcrypt.ValidatePcBlock(&mb, height, 0, annParentHashes)
+	g.corpus.Proofs = append(g.corpus.Proofs, ProofVector{
+		Comment:         comment,
+		Height:          height,
+		Header:          hex.EncodeToString(block.Header.AppendTo(nil)),
+		Coinbase:        hex.EncodeToString(block.Transactions[0].AppendTo(nil)),
+		Proof:           hex.EncodeToString(proof.Bytes()),
+		AnnParentHashes: hashes,
+		Valid:           err == nil,
+	})
+	return nil
+}
+
+// AddScript adds the input at index idx of the passed transaction, which
+// spends an output with the passed script and amount, checked with the passed
+// flags.  A variant with a tampered signature is added along with, for inputs
+// with a witness, one spending a different amount.
+func (g *Generator) AddScript(tx *wire.MsgTx, idx int, pkScript []byte,
+	amount int64, flags txscript.ScriptFlags) {
+
+	comment := fmt.Sprintf("input %d of %v", idx, tx.TxHash())
+	g.addScript(comment, tx, idx, pkScript, amount, flags)
+
+	tampered := tx.Copy()
+	txIn := tampered.TxIn[idx]
+	switch {
+	case len(txIn.Witness) > 0 && len(txIn.Witness[0]) > 0:
+		txIn.Witness[0][len(txIn.Witness[0])/2] ^= 1
+	case len(txIn.SignatureScript) > 0:
+		txIn.SignatureScript[len(txIn.SignatureScript)/2] ^= 1
+	default:
+		return
+	}
+	g.addScript(comment+" with a tampered signature", tampered, idx,
+		pkScript, amount, flags)
+
+	if len(tx.TxIn[idx].Witness) > 0 {
+		g.addScript(comment+" spending a different amount", tx, idx,
+			pkScript, amount+1, flags)
+	}
+}
+
+// addScript adds a script vector.
+func (g *Generator) addScript(comment string, tx *wire.MsgTx, idx int,
+	pkScript []byte, amount int64, flags txscript.ScriptFlags) {
+
+	err := txscript.VerifyScript(pkScript, tx, idx, amount, flags, nil, nil)
+	g.corpus.Scripts = append(g.corpus.Scripts, ScriptVector{
+		Comment:  comment,
+		Tx:       hex.EncodeToString(tx.AppendTo(nil)),
+		Input:    idx,
+		PkScript: hex.EncodeToString(pkScript),
+		Amount:   amount,
+		Flags:    flags.String(),
+		Result:   scriptResult(err),
+	})
+}
+
+// AddChain adds the vectors of the main chain blocks of the passed chain from
+// height start through end: their headers and proofs, and the scripts of the
+// first maxScripts inputs of each block checked with the flags the block was
+// validated with.  The genesis block is skipped since it has no previous
+// header.
+func (g *Generator) AddChain(chain *blockchain.BlockChain, start, end int32,
+	maxScripts int) error {
+
+	if start < 1 {
+		start = 1
+	}
+	prev, err := chain.BlockByHeight(start - 1)
+	if err != nil {
+		return err
+	}
+	for height := start; height <= end; height++ {
+		block, err := chain.BlockByHeight(height)
+		if err != nil {
+			return err
+		}
+		msgBlock := block.MsgBlock()
+		g.AddHeader(&msgBlock.Header, height, prev.Hash(),
+			prev.MsgBlock().Header.Bits)
+
+		if msgBlock.Pcp != nil {
+			anns := msgBlock.Pcp.Announcements
+			hashes := make([]*chainhash.Hash, len(anns))
+			for i := range anns {
+				ph := anns[i].GetParentBlockHeight()
+				if ph > 0x7fffffff {
+					return fmt.Errorf("block %v has an "+
+						"announcement with a parent block "+
+						"height of %d", block.Hash(), ph)
+				}
+				hashes[i], err = chain.BlockHashByHeight(int32(ph))
+				if err != nil {
+					return err
+				}
+			}
+			err := g.AddProof(msgBlock, height, hashes)
+			if err != nil {
+				return err
+			}
+		}
+
+		if maxScripts > 0 {
+			err := g.addBlockScripts(chain, block.Hash(), msgBlock,
+				maxScripts)
+			if err != nil {
+				return err
+			}
+		}
+		prev = block
+	}
+	return nil
+}
+
+// addBlockScripts adds the scripts of the first maxScripts inputs of the
+// passed main chain block.
+func (g *Generator) addBlockScripts(chain *blockchain.BlockChain,
+	hash *chainhash.Hash, block *wire.MsgBlock, maxScripts int) error {
+
+	undo, err := chain.FetchBlockUndo(hash)
+	if err != nil {
+		return err
+	}
+	flags, err := chain.BlockScriptFlags(hash)
+	if err != nil {
+		return err
+	}
+
+	// The spent outputs are in the order of the inputs of the
+	// transactions following the coinbase.
+	n := 0
+	for _, tx := range block.Transactions[1:] {
+		for i := range tx.TxIn {
+			if n == maxScripts || n == len(undo.Spent) {
+				return nil
+			}
+			spent := &undo.Spent[n]
+			g.AddScript(tx, i, spent.PkScript, spent.Amount, flags)
+			n++
+		}
+	}
+	return nil
+}
@@ -0,0 +1,254 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package consensusvectors
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/spv"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// ScriptOK is the result of a script vector whose script succeeds.
+const ScriptOK = "OK"
+
+// scriptResult returns the result of a script check which returned err.
+func scriptResult(err error) string {
+	if err == nil {
+		return ScriptOK
+	}
+	if serr, ok := err.(txscript.Error); ok {
+		return serr.ErrorCode.String()
+	}
+	return "ERROR"
+}
+
+// Failure is a vector of a corpus whose result differs from the expected one
+// or which could not be decoded.
+type Failure struct {
+	// Kind is the name of the list of the vector in the corpus, such as
+	// headers, and Index its index in the list.
+	Kind    string
+	Index   int
+	Comment string
+	Err     error
+}
+
+// Error returns a description of the failure.
+func (f *Failure) Error() string {
+	if f.Comment == "" {
+		return fmt.Sprintf("%s %d: %v", f.Kind, f.Index, f.Err)
+	}
+	return fmt.Sprintf("%s %d (%s): %v", f.Kind, f.Index, f.Comment, f.Err)
+}
+
+// Run checks every vector of the passed corpus against the consensus code of
+// the node, for the passed network, and returns the vectors whose result
+// differs from the expected one.
+func Run(params *chaincfg.Params, c *Corpus) ([]Failure, error) {
+	if c.Network != params.Name {
+		return nil, fmt.Errorf("the corpus is for the %s network, not %s",
+			c.Network, params.Name)
+	}
+
+	var failures []Failure
+	fail := func(kind string, i int, comment string, err error) {
+		if err != nil {
+			failures = append(failures, Failure{kind, i, comment, err})
+		}
+	}
+	for i := range c.Headers {
+		v := &c.Headers[i]
+		fail("headers", i, v.Comment, runHeader(params, v))
+	}
+	for i := range c.Compact {
+		fail("compact", i, "", runCompact(&c.Compact[i]))
+	}
+	for i := range c.Transitions {
+		v := &c.Transitions[i]
+		fail("transitions", i, v.Comment, runTransition(params, v))
+	}
+	for i := range c.EffectiveTargets {
+		fail("effectivetargets", i, "",
+			runEffectiveTarget(&c.EffectiveTargets[i]))
+	}
+	for i := range c.Proofs {
+		v := &c.Proofs[i]
+		fail("proofs", i, v.Comment, runProof(v))
+	}
+	for i := range c.Scripts {
+		v := &c.Scripts[i]
+		fail("scripts", i, v.Comment, runScript(v))
+	}
+	return failures, nil
+}
+
+// checkValid returns an error when the validity of a check which returned err
+// differs from the expected one.
+func checkValid(valid bool, err error) error {
+	switch {
+	case valid && err != nil:
+		return fmt.Errorf("expected valid, got %v", err)
+	case !valid && err == nil:
+		return errors.New("expected invalid, got valid")
+	}
+	return nil
+}
+
+// decodeHeader decodes a hex-encoded block header.
+func decodeHeader(s string) (*wire.BlockHeader, error) {
+	serialized, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(serialized) != wire.MaxBlockHeaderPayload {
+		return nil, fmt.Errorf("header of %d bytes", len(serialized))
+	}
+	var header wire.BlockHeader
+	if err := header.Deserialize(bytes.NewReader(serialized)); err != nil {
+		return nil, err
+	}
+	return &header, nil
+}
+
+// decodeTx decodes a hex-encoded transaction.
+func decodeTx(s string) (*wire.MsgTx, error) {
+	serialized, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	var tx wire.MsgTx
+	r := bytes.NewReader(serialized)
+	if err := tx.Deserialize(r); err != nil {
+		return nil, err
+	}
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("%d trailing bytes after the transaction",
+			r.Len())
+	}
+	return &tx, nil
+}
+
+// runHeader checks a header vector.
+func runHeader(params *chaincfg.Params, v *HeaderVector) error {
+	header, err := decodeHeader(v.Header)
+	if err != nil {
+		return err
+	}
+	prevHash, err := chainhash.NewHashFromStr(v.PrevHash)
+	if err != nil {
+		return err
+	}
+	hash, err := spv.CheckHeader(params, header, v.Height, prevHash,
+		v.PrevBits)
+	if hash.String() != v.Hash {
+		return fmt.Errorf("expected hash %s, got %v", v.Hash, hash)
+	}
+	return checkValid(v.Valid, err)
+}
+
+// runCompact checks a compact difficulty vector.
+func runCompact(v *CompactVector) error {
+	target := spv.CompactToBig(v.Bits)
+	if s := fmt.Sprintf("%064x", target); s != v.Target {
+		return fmt.Errorf("expected target %s, got %s", v.Target, s)
+	}
+	if work := spv.CalcWork(v.Bits).String(); work != v.Work {
+		return fmt.Errorf("expected work %s, got %s", v.Work, work)
+	}
+	if compact := spv.BigToCompact(target); compact != v.Compact {
+		return fmt.Errorf("expected compact %08x, got %08x", v.Compact,
+			compact)
+	}
+	return nil
+}
+
+// runTransition checks a difficulty transition vector.
+func runTransition(params *chaincfg.Params, v *TransitionVector) error {
+	permitted := spv.PermittedDifficultyTransition(params, v.Height,
+		v.OldBits, v.NewBits)
+	if permitted != v.Permitted {
+		return fmt.Errorf("expected permitted %v, got %v", v.Permitted,
+			permitted)
+	}
+	return nil
+}
+
+// runEffectiveTarget checks an effective target vector.
+func runEffectiveTarget(v *EffectiveTargetVector) error {
+	effective := spv.GetEffectiveTarget(v.Bits, v.MinAnnBits, v.AnnCount)
+	if effective != v.Effective {
+		return fmt.Errorf("expected effective target %08x, got %08x",
+			v.Effective, effective)
+	}
+	return nil
+}
+
+// runProof checks a PacketCrypt proof vector.
+func runProof(v *ProofVector) error {
+	header, err := decodeHeader(v.Header)
+	if err != nil {
+		return err
+	}
+	coinbase, err := decodeTx(v.Coinbase)
+	if err != nil {
+		return err
+	}
+	serialized, err := hex.DecodeString(v.Proof)
+	if err != nil {
+		return err
+	}
+	var pcp wire.PacketCryptProof
+	if err := pcp.Deserialize(bytes.NewReader(serialized)); err != nil {
+		return err
+	}
+	hashes := make([]*chainhash.Hash, len(v.AnnParentHashes))
+	for i, s := range v.AnnParentHashes {
+		hashes[i], err = chainhash.NewHashFromStr(s)
+		if err != nil {
+			return err
+		}
+	}
+
+	block := wire.MsgBlock{
+		Header:       *header,
+		Transactions: []*wire.MsgTx{coinbase},
+		Pcp:          &pcp,
+	}
+	_, err = packetcrypt.ValidatePcBlock(&block, v.Height, 0, hashes)
+	return checkValid(v.Valid, err)
+}
+
+// runScript checks a script vector.
+func runScript(v *ScriptVector) error {
+	tx, err := decodeTx(v.Tx)
+	if err != nil {
+		return err
+	}
+	if v.Input < 0 || v.Input >= len(tx.TxIn) {
+		return fmt.Errorf("input %d out of range", v.Input)
+	}
+	pkScript, err := hex.DecodeString(v.PkScript)
+	if err != nil {
+		return err
+	}
+	flags, err := txscript.ParseScriptFlags(v.Flags)
+	if err != nil {
+		return err
+	}
+	err = txscript.VerifyScript(pkScript, tx, v.Input, v.Amount, flags,
+		nil, nil)
+	if result := scriptResult(err); result != v.Result {
+		return fmt.Errorf("expected %s, got %s", v.Result, result)
+	}
+	return nil
+}
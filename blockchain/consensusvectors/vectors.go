@@ -0,0 +1,115 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package consensusvectors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Version is the version of the corpus format.
+const Version = 1
+
+// Corpus is a set of consensus test vectors of a network along with their
+// expected results.
+type Corpus struct {
+	Version          int                     `json:"version"`
+	Network          string                  `json:"network"`
+	Headers          []HeaderVector          `json:"headers"`
+	Compact          []CompactVector         `json:"compact"`
+	Transitions      []TransitionVector      `json:"transitions"`
+	EffectiveTargets []EffectiveTargetVector `json:"effectivetargets"`
+	Proofs           []ProofVector           `json:"proofs"`
+	Scripts          []ScriptVector          `json:"scripts"`
+}
+
+// HeaderVector is a block header along with whether it is valid following the
+// previous header of the chain.  The proof of work is not part of the check.
+type HeaderVector struct {
+	Comment  string `json:"comment,omitempty"`
+	Height   int32  `json:"height"`
+	Header   string `json:"header"`
+	PrevHash string `json:"prevhash"`
+	PrevBits uint32 `json:"prevbits"`
+	Hash     string `json:"hash"`
+	Valid    bool   `json:"valid"`
+}
+
+// CompactVector is a difficulty in its compact representation along with the
+// target it encodes, the work of a block of that difficulty and the compact
+// representation of the target.
+type CompactVector struct {
+	Bits    uint32 `json:"bits"`
+	Target  string `json:"target"`
+	Work    string `json:"work"`
+	Compact uint32 `json:"compact"`
+}
+
+// TransitionVector is a change of difficulty between two blocks along with
+// whether the network permits it.
+type TransitionVector struct {
+	Comment   string `json:"comment,omitempty"`
+	Height    int32  `json:"height"`
+	OldBits   uint32 `json:"oldbits"`
+	NewBits   uint32 `json:"newbits"`
+	Permitted bool   `json:"permitted"`
+}
+
+// EffectiveTargetVector is the difficulty of a block header, the lowest
+// difficulty of the announcements it was mined with and their number, along
+// with the difficulty its PacketCrypt proof must meet.
+type EffectiveTargetVector struct {
+	Bits       uint32 `json:"bits"`
+	MinAnnBits uint32 `json:"minannbits"`
+	AnnCount   uint64 `json:"anncount"`
+	Effective  uint32 `json:"effective"`
+}
+
+// ProofVector is the PacketCrypt proof of a block, along with its header,
+// coinbase transaction and the hashes of the parent blocks of its
+// announcements, and whether the proof is valid.
+type ProofVector struct {
+	Comment         string   `json:"comment,omitempty"`
+	Height          int32    `json:"height"`
+	Header          string   `json:"header"`
+	Coinbase        string   `json:"coinbase"`
+	Proof           string   `json:"proof"`
+	AnnParentHashes []string `json:"annparenthashes"`
+	Valid           bool     `json:"valid"`
+}
+
+// ScriptVector is an input of a transaction along with the output it spends,
+// the script flags it is checked with and the result of the check: OK or the
+// name of the script error code.
+type ScriptVector struct {
+	Comment  string `json:"comment,omitempty"`
+	Tx       string `json:"tx"`
+	Input    int    `json:"input"`
+	PkScript string `json:"pkscript"`
+	Amount   int64  `json:"amount"`
+	Flags    string `json:"flags"`
+	Result   string `json:"result"`
+}
+
+// Read decodes a corpus from r.
+func Read(r io.Reader) (*Corpus, error) {
+	var c Corpus
+	if err := json.NewDecoder(r).Decode(&c); err != nil {
+		return nil, err
+	}
+	if c.Version != Version {
+		return nil, fmt.Errorf("unsupported corpus version %d", c.Version)
+	}
+	return &c, nil
+}
+
+// Write encodes the corpus to w.  The encoding is indented so changes to a
+// corpus under version control are easy to review.
+func (c *Corpus) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(c)
+}
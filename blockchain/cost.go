@@ -0,0 +1,216 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"fmt"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/database"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// TxCost houses the consensus accounting of a transaction, the sizes and the
+// signature operations which count towards the limits of the block holding it.
+type TxCost struct {
+	// Size is the serialized size of the transaction including its
+	// witness data and BaseSize is the size without it.
+	Size        int
+	BaseSize    int
+	WitnessSize int
+
+	// Weight is the weight of the transaction as defined in BIP0141.
+	Weight int64
+
+	// LegacySigOps is the number of signature operations in the input and
+	// output scripts, P2SHSigOps the precise number in the redeem scripts
+	// of the pay-to-script-hash inputs and WitnessSigOps the number in the
+	// witness programs of the inputs.  The pay-to-script-hash and witness
+	// signature operations are only counted once the rules introducing
+	// them are enforced.
+	LegacySigOps  int
+	P2SHSigOps    int
+	WitnessSigOps int
+
+	// SigOpCost is the signature operation cost of the transaction, the
+	// legacy and pay-to-script-hash signature operations scaled by the
+	// WitnessScaleFactor plus the witness signature operations.
+	SigOpCost int
+}
+
+// BlockCost houses the consensus accounting of a block, the totals checked
+// against MaxBlockWeight, MaxBlockBaseSize and MaxBlockSigOpsCost and the
+// parts of the block they are made of.
+type BlockCost struct {
+	// HeaderSize is the size of the block header, TxCountSize the size of
+	// the number of transactions and ProofSize the size of the
+	// PacketCrypt proof, if any.  They have no witness data.
+	HeaderSize  int
+	TxCountSize int
+	ProofSize   int
+
+	// Size is the serialized size of the block including the witness data
+	// and BaseSize is the size without it.
+	Size        int
+	BaseSize    int
+	WitnessSize int
+
+	// Weight is the weight of the block as defined in BIP0141.
+	Weight int64
+
+	// SigOpCost is the total signature operation cost of the
+	// transactions.
+	SigOpCost int
+
+	// Txs holds the accounting of each transaction of the block, in order.
+	Txs []TxCost
+}
+
+// GetTxCost returns the consensus accounting of the passed transaction.  The
+// utxo view must hold the outputs spent by the transaction unless it is a
+// coinbase or neither bip16 nor segWit is enforced.
+func GetTxCost(tx *btcutil.Tx, isCoinBaseTx bool, utxoView *UtxoViewpoint,
+	bip16, segWit bool) (*TxCost, error) {
+
+	msgTx := tx.MsgTx()
+	cost := &TxCost{
+		Size:         msgTx.SerializeSize(),
+		BaseSize:     msgTx.SerializeSizeStripped(),
+		Weight:       GetTransactionWeight(tx),
+		LegacySigOps: CountSigOps(tx),
+	}
+	cost.WitnessSize = cost.Size - cost.BaseSize
+
+	if bip16 {
+		numSigOps, err := CountP2SHSigOps(tx, isCoinBaseTx, utxoView)
+		if err != nil {
+			return nil, err
+		}
+		cost.P2SHSigOps = numSigOps
+	}
+
+	if segWit && !isCoinBaseTx {
+		for txInIndex, txIn := range msgTx.TxIn {
+			utxo := utxoView.LookupEntry(txIn.PreviousOutPoint)
+			if utxo == nil || utxo.IsSpent() {
+				str := fmt.Sprintf("output %v referenced from "+
+					"transaction %s:%d either does not "+
+					"exist or has already been spent",
+					txIn.PreviousOutPoint, tx.Hash(),
+					txInIndex)
+				return nil, ruleError(ErrMissingTxOut, str)
+			}
+			cost.WitnessSigOps += txscript.GetWitnessSigOpCount(
+				txIn.SignatureScript, utxo.PkScript(), txIn.Witness)
+		}
+	}
+
+	cost.SigOpCost = (cost.LegacySigOps+cost.P2SHSigOps)*WitnessScaleFactor +
+		cost.WitnessSigOps
+	return cost, nil
+}
+
+// GetBlockCost returns the consensus accounting of the passed block.  The utxo
+// view must hold the outputs spent by the transactions of the block when bip16
+// or segWit is enforced.
+func GetBlockCost(block *btcutil.Block, utxoView *UtxoViewpoint,
+	bip16, segWit bool) (*BlockCost, error) {
+
+	msgBlock := block.MsgBlock()
+	transactions := block.Transactions()
+	cost := &BlockCost{
+		HeaderSize:  wire.MaxBlockHeaderPayload,
+		TxCountSize: wire.VarIntSerializeSize(uint64(len(transactions))),
+		Size:        msgBlock.SerializeSize(),
+		BaseSize:    msgBlock.SerializeSizeStripped(),
+		Weight:      GetBlockWeight(block),
+		Txs:         make([]TxCost, 0, len(transactions)),
+	}
+	cost.WitnessSize = cost.Size - cost.BaseSize
+
+	txsBaseSize := 0
+	for i, tx := range transactions {
+		txCost, err := GetTxCost(tx, i == 0, utxoView, bip16, segWit)
+		if err != nil {
+			return nil, err
+		}
+		cost.SigOpCost += txCost.SigOpCost
+		txsBaseSize += txCost.BaseSize
+		cost.Txs = append(cost.Txs, *txCost)
+	}
+
+	// Whatever is left of the base size once the header, the number of
+	// transactions and the transactions are accounted for is the proof.
+	cost.ProofSize = cost.BaseSize - cost.HeaderSize - cost.TxCountSize -
+		txsBaseSize
+	return cost, nil
+}
+
+// BlockCost returns the consensus accounting of the passed block, which must
+// either be in the main chain or extend its tip, as a block template does.  The
+// height of the block is set accordingly.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) BlockCost(block *btcutil.Block) (*BlockCost, error) {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	// The outputs spent by a block of the main chain are in its spend
+	// journal while the ones spent by a block extending the tip are still
+	// unspent, in the utxo set or earlier in the block.
+	view := NewUtxoViewpoint()
+	var prevNode *blockNode
+	node := b.index.LookupNode(block.Hash())
+	if node != nil && b.bestChain.Contains(node) {
+		var stxos []SpentTxOut
+		err := b.db.View(func(dbTx database.Tx) error {
+			var err error
+			stxos, err = dbFetchSpendJournalEntry(dbTx, block)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		stxoIndex := 0
+		for _, tx := range block.Transactions()[1:] {
+			for _, txIn := range tx.MsgTx().TxIn {
+				stxo := &stxos[stxoIndex]
+				stxoIndex++
+
+				entry := &UtxoEntry{
+					amount:      stxo.Amount,
+					pkScript:    stxo.PkScript,
+					blockHeight: stxo.Height,
+				}
+				if stxo.IsCoinBase {
+					entry.packedFlags |= tfCoinBase
+				}
+				view.entries[txIn.PreviousOutPoint] = entry
+			}
+		}
+		prevNode = node.parent
+		block.SetHeight(node.height)
+	} else {
+		tip := b.bestChain.Tip()
+		if block.MsgBlock().Header.PrevBlock != tip.hash {
+			return nil, fmt.Errorf("block %s is neither in the main "+
+				"chain nor extending its tip", block.Hash())
+		}
+		if err := view.fetchInputUtxos(b.db, block); err != nil {
+			return nil, err
+		}
+		prevNode = tip
+		block.SetHeight(tip.height + 1)
+	}
+
+	// The rules in force are the ones used to validate the block.
+	flags, err := b.consensusScriptFlags(prevNode, &block.MsgBlock().Header)
+	if err != nil {
+		return nil, err
+	}
+	return GetBlockCost(block, view, flags&txscript.ScriptBip16 != 0,
+		flags&txscript.ScriptVerifyWitness != 0)
+}
@@ -7,6 +7,8 @@ package blockchain
 import (
 	"math/big"
 	"testing"
+
+	"github.com/pkt-cash/pktd/chaincfg"
 )
 
 // TestBigToCompact ensures BigToCompact converts big integers to the expected
@@ -73,3 +75,35 @@ func TestCalcWork(t *testing.T) {
 		}
 	}
 }
+
+// TestPermittedDifficultyTransition ensures difficulty changes are only
+// permitted at retarget heights and within the retarget adjustment limits.
+func TestPermittedDifficultyTransition(t *testing.T) {
+	tests := []struct {
+		params  *chaincfg.Params
+		height  int32
+		oldBits uint32
+		newBits uint32
+		want    bool
+	}{
+		{&chaincfg.MainNetParams, 1, 0x1b0404cb, 0x1b0404cb, true},
+		{&chaincfg.MainNetParams, 1, 0x1b0404cb, 0x1b0404cc, false},
+		{&chaincfg.MainNetParams, 2016, 0x1b0404cb, 0x1b0404cb, true},
+		{&chaincfg.MainNetParams, 2016, 0x1b0404cb, 0x1b10132c, true},
+		{&chaincfg.MainNetParams, 2016, 0x1b0404cb, 0x1b10132d, false},
+		{&chaincfg.MainNetParams, 2016, 0x1b0404cb, 0x1b010132, true},
+		{&chaincfg.MainNetParams, 2016, 0x1b0404cb, 0x1b010131, false},
+		{&chaincfg.MainNetParams, 2016, 0x1d00ffff, 0x1d00ffff, true},
+		{&chaincfg.MainNetParams, 2016, 0x1d00ffff, 0x1d01ffff, false},
+		{&chaincfg.PktMainNetParams, 1, 0x1c0404cb, 0x1f0fffff, true},
+	}
+
+	for x, test := range tests {
+		got := PermittedDifficultyTransition(test.params, test.height,
+			test.oldBits, test.newBits)
+		if got != test.want {
+			t.Errorf("TestPermittedDifficultyTransition test #%d "+
+				"failed: got %v want %v", x, got, test.want)
+		}
+	}
+}
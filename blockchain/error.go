@@ -308,8 +308,9 @@ func (e ErrorCode) String() string {
 // specifically due to a rule violation and access the ErrorCode field to
 // ascertain the specific reason for the rule violation.
 type RuleError struct {
-	ErrorCode   ErrorCode // Describes the kind of error
-	Description string    // Human readable description of the issue
+	ErrorCode   ErrorCode         // Describes the kind of error
+	Description string            // Human readable description of the issue
+	Details     *RuleErrorDetails // Part of the block at issue, if known
 }
 
 // Error satisfies the error interface and prints human-readable errors.
@@ -317,7 +318,78 @@ func (e RuleError) Error() string {
 	return e.Description
 }
 
+// RuleErrorDetails identifies the part of a block which violates a rule and
+// the value the rule expects, so callers such as miners can act on the error
+// without parsing its description.  The indexes which do not apply are -1 and
+// the values are empty when they do not apply.
+type RuleErrorDetails struct {
+	// TxIndex is the index of the transaction within the block and
+	// InputIndex the index of the input of the transaction.
+	TxIndex    int
+	InputIndex int
+
+	// AnnIndex is the index of the announcement within the PacketCrypt
+	// proof of the block.
+	AnnIndex int
+
+	// Expected is the value, or the limit, the rule requires and Actual
+	// the value of the block.
+	Expected string
+	Actual   string
+}
+
 // ruleError creates an RuleError given a set of arguments.
 func ruleError(c ErrorCode, desc string) RuleError {
 	return RuleError{ErrorCode: c, Description: desc}
 }
+
+// newRuleErrorDetails returns rule error details which are about no part of the
+// block in particular.
+func newRuleErrorDetails() *RuleErrorDetails {
+	return &RuleErrorDetails{TxIndex: -1, InputIndex: -1, AnnIndex: -1}
+}
+
+// valueRuleError creates a RuleError given a set of arguments and the value the
+// rule expects along with the actual one.
+func valueRuleError(c ErrorCode, desc string, expected, actual interface{}) RuleError {
+	details := newRuleErrorDetails()
+	details.Expected = fmt.Sprint(expected)
+	details.Actual = fmt.Sprint(actual)
+	return RuleError{ErrorCode: c, Description: desc, Details: details}
+}
+
+// inputRuleError creates a RuleError given a set of arguments and the input it
+// is about.  The transaction index is the one within the block, if known.
+func inputRuleError(c ErrorCode, desc string, txIndex, inputIndex int) RuleError {
+	details := newRuleErrorDetails()
+	details.TxIndex = txIndex
+	details.InputIndex = inputIndex
+	return RuleError{ErrorCode: c, Description: desc, Details: details}
+}
+
+// annRuleError creates a RuleError given a set of arguments and the
+// PacketCrypt announcement it is about.
+func annRuleError(c ErrorCode, desc string, annIndex int) RuleError {
+	details := newRuleErrorDetails()
+	details.AnnIndex = annIndex
+	return RuleError{ErrorCode: c, Description: desc, Details: details}
+}
+
+// withTxIndex returns the passed error along with the index of the transaction
+// within the block it is about, when it is a RuleError which does not identify
+// the transaction yet.  Other errors are returned unchanged.
+func withTxIndex(err error, txIndex int) error {
+	ruleErr, ok := err.(RuleError)
+	if !ok {
+		return err
+	}
+	details := newRuleErrorDetails()
+	if ruleErr.Details != nil {
+		*details = *ruleErr.Details
+	}
+	if details.TxIndex < 0 {
+		details.TxIndex = txIndex
+	}
+	ruleErr.Details = details
+	return ruleErr
+}
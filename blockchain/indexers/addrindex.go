@@ -77,6 +77,10 @@ var (
 	// unsupported address type has been used.
 	errUnsupportedAddressType = errors.New("address type is not supported " +
 		"by the address index")
+
+	// errLevelsFetched is used to stop iterating over the levels of an
+	// address once the needed ones have been fetched.
+	errLevelsFetched = errors.New("address index levels fetched")
 )
 
 // -----------------------------------------------------------------------------
@@ -259,14 +263,18 @@ func dbFetchAddrIndexEntries(bucket internalBucket, addrKey [addrKeySize]byte, n
 	// transactions (highest level) and thus the total count is needed.
 	// However, when the reverse flag is set, only enough records to satisfy
 	// the requested amount are needed.
+	//
+	// The levels of the address are fetched in order with a single prefix
+	// iteration since their keys only differ by the trailing level.
 	var level uint8
 	var serialized []byte
-	for !reverse || len(serialized) < int(numToSkip+numRequested)*txEntrySize {
-		curLevelKey := keyForLevel(addrKey, level)
-		levelData := bucket.Get(curLevelKey[:])
-		if levelData == nil {
-			// Stop when there are no more levels.
-			break
+	err := bucket.ForEachWithPrefix(addrKey[:], func(k, levelData []byte) error {
+		// Stop when there are no more levels.
+		if len(k) != levelKeySize || k[levelOffset] != level {
+			return errLevelsFetched
+		}
+		if reverse && len(serialized) >= int(numToSkip+numRequested)*txEntrySize {
+			return errLevelsFetched
 		}
 
 		// Higher levels contain older transactions, so prepend them.
@@ -275,6 +283,10 @@ func dbFetchAddrIndexEntries(bucket internalBucket, addrKey [addrKeySize]byte, n
 		copy(prepended[len(levelData):], serialized)
 		serialized = prepended
 		level++
+		return nil
+	})
+	if err != nil && err != errLevelsFetched {
+		return nil, 0, err
 	}
 
 	// When the requested number of entries to skip is larger than the
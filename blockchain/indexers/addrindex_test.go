@@ -7,8 +7,10 @@ package indexers
 import (
 	"bytes"
 	"fmt"
+	"sort"
 	"testing"
 
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
 	"github.com/pkt-cash/pktd/wire"
 )
 
@@ -59,6 +61,30 @@ func (b *addrIndexBucket) Delete(key []byte) error {
 	return nil
 }
 
+// ForEachWithPrefix invokes the passed function with every key/value pair of
+// the mock address index bucket with a key starting with the passed prefix, in
+// key order.
+//
+// This is part of the internalBucket interface.
+func (b *addrIndexBucket) ForEachWithPrefix(prefix []byte, fn func(k, v []byte) error) error {
+	var keys [][levelKeySize]byte
+	for k := range b.levels {
+		if bytes.HasPrefix(k[:], prefix) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return bytes.Compare(keys[i][:], keys[j][:]) < 0
+	})
+	for _, k := range keys {
+		k := k
+		if err := fn(k[:], b.levels[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // printLevels returns a string with a visual representation of the provided
 // address key taking into account the max size of each level.  It is useful
 // when creating and debugging test cases.
@@ -163,6 +189,12 @@ func (b *addrIndexBucket) sanityCheck(addrKey [addrKeySize]byte, expectedTotal i
 	return nil
 }
 
+// fetchTestBlockHash is a block hash fetching function which returns an empty
+// hash for every block ID.
+func fetchTestBlockHash(serializedID []byte) (*chainhash.Hash, error) {
+	return &chainhash.Hash{}, nil
+}
+
 // TestAddrIndexLevels ensures that adding and deleting entries to the address
 // index creates multiple levels as described by the address index
 // documentation.
@@ -234,6 +266,50 @@ nextTest:
 			t.Log(populatedBucket.printLevels(test.key))
 		}
 
+		// Ensure all of the entries are fetched in order, oldest first
+		// unless reversed, and are not mixed up with the entries of
+		// another address.
+		otherKey := test.key
+		otherKey[0] ^= 0xff
+		err := dbPutAddrIndexEntry(populatedBucket, otherKey, 0,
+			wire.TxLoc{TxStart: 1})
+		if err != nil {
+			t.Errorf("dbPutAddrIndexEntry #%d (%s) - unexpected "+
+				"error: %v", testNum, test.name, err)
+			continue nextTest
+		}
+		for _, reverse := range []bool{false, true} {
+			regions, skipped, err := dbFetchAddrIndexEntries(
+				populatedBucket, test.key, 1, uint32(test.numInsert),
+				reverse, fetchTestBlockHash)
+			if err != nil {
+				t.Errorf("dbFetchAddrIndexEntries #%d (%s) - "+
+					"unexpected error: %v", testNum,
+					test.name, err)
+				continue nextTest
+			}
+			if skipped != 1 || len(regions) != test.numInsert-1 {
+				t.Errorf("dbFetchAddrIndexEntries #%d (%s) - "+
+					"fetched %d entries, skipped %d",
+					testNum, test.name, len(regions), skipped)
+				continue nextTest
+			}
+			for i, region := range regions {
+				want := uint32((i + 1) * 2)
+				if reverse {
+					want = uint32((test.numInsert - i - 2) * 2)
+				}
+				if region.Offset != want {
+					t.Errorf("dbFetchAddrIndexEntries #%d "+
+						"(%s) reverse %v - entry %d has "+
+						"offset %d, want %d", testNum,
+						test.name, reverse, i,
+						region.Offset, want)
+					continue nextTest
+				}
+			}
+		}
+
 		// Delete entries from the populated bucket until all entries
 		// have been deleted.  The bucket is reset to the fully
 		// populated bucket on each iteration so every combination is
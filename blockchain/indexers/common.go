@@ -32,6 +32,22 @@ type NeedsInputser interface {
 	NeedsInputs() bool
 }
 
+// StartHeighter provides a generic interface for an indexer to specify it
+// only indexes the blocks from a height onwards.  The blocks below the height
+// only move the tip of the index.
+type StartHeighter interface {
+	StartHeight() int32
+}
+
+// indexStartHeight returns the height of the first block indexed by the
+// passed index.
+func indexStartHeight(index Indexer) int32 {
+	if sh, ok := index.(StartHeighter); ok {
+		return sh.StartHeight()
+	}
+	return 0
+}
+
 // Indexer provides a generic interface for an indexer that is managed by an
 // index manager such as the Manager type provided by this package.
 type Indexer interface {
@@ -96,6 +112,7 @@ type internalBucket interface {
 	Get(key []byte) []byte
 	Put(key []byte, value []byte) error
 	Delete(key []byte) error
+	ForEachWithPrefix(prefix []byte, fn func(k, v []byte) error) error
 }
 
 // interruptRequested returns true when the provided channel has been closed.
@@ -0,0 +1,399 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package indexers
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/database"
+)
+
+const (
+	// feeStatsIndexName is the human-readable name for the index.
+	feeStatsIndexName = "fee statistics index"
+
+	// feeStatsSize is the size of the serialized fee statistics of a
+	// block.
+	feeStatsSize = 8 + 4 + 8 + 8 + 8 + 8 + 8*len(FeeStatsPercentiles)
+)
+
+var (
+	// feeStatsIndexKey is the key of the fee statistics index and the db
+	// bucket used to house it.
+	feeStatsIndexKey = []byte("feestatsidx")
+
+	// feeStatsHeightBucketName is the name of the bucket holding the
+	// statistics of the blocks by height, nested in the index bucket.
+	feeStatsHeightBucketName = []byte("height")
+
+	// feeStatsTimeBucketName is the name of the bucket ordering the blocks
+	// by timestamp, nested in the index bucket.
+	feeStatsTimeBucketName = []byte("time")
+)
+
+// FeeStatsPercentiles are the percentiles of the fee rates of the transactions
+// of each block kept by the fee statistics index.  They are weighted by the
+// virtual size of the transactions, so the median is the fee rate paid for the
+// median virtual byte of the block.
+var FeeStatsPercentiles = [...]int{10, 25, 50, 75, 90}
+
+// -----------------------------------------------------------------------------
+// The fee statistics index keeps the fees paid by the transactions of every
+// main chain block so the history of the fee market can be followed without
+// loading the blocks and their spend journals.
+//
+// The statistics of the blocks are stored in the nested "height" bucket:
+//
+//   <block height> = <statistics>
+//
+//   Field           Type              Size
+//   block height    uint32 (BE)       4 bytes
+//
+// The blocks are also ordered by timestamp, which is not monotonic, in the
+// nested "time" bucket, whose values are empty:
+//
+//   <block time><block height> = <>
+//
+//   Field           Type              Size
+//   block time      uint64 (BE)       8 bytes
+//   block height    uint32 (BE)       4 bytes
+//
+// The serialized format of the statistics of a block is as follows, the fee
+// rates being in units per 1000 virtual bytes:
+//
+//   Field           Type              Size
+//   block time      int64             8 bytes
+//   tx count        uint32            4 bytes
+//   virtual size    uint64            8 bytes
+//   fees            uint64            8 bytes
+//   min fee rate    uint64            8 bytes
+//   max fee rate    uint64            8 bytes
+//   percentiles     [5]uint64         40 bytes
+// -----------------------------------------------------------------------------
+
+// FeeStats are the fees paid by the transactions of the block at Height,
+// leaving out the coinbase.  The fee rates are in units per 1000 virtual bytes
+// and are zero when the block only holds a coinbase.
+type FeeStats struct {
+	Height  int32
+	Time    int64
+	TxCount uint32
+	VSize   uint64
+	Fees    uint64
+
+	MinFeeRate uint64
+	MaxFeeRate uint64
+
+	// FeeRatePercentiles are the fee rates at the percentiles listed by
+	// FeeStatsPercentiles.
+	FeeRatePercentiles [len(FeeStatsPercentiles)]uint64
+}
+
+// txFeeRate is the fee rate and virtual size of a transaction.
+type txFeeRate struct {
+	feeRate uint64
+	vsize   uint64
+}
+
+// blockFeeStats returns the fee statistics of the passed block, given the
+// outputs spent by its transactions in order.
+func blockFeeStats(block *btcutil.Block, stxos []blockchain.SpentTxOut) (*FeeStats, error) {
+	stats := &FeeStats{
+		Height: block.Height(),
+		Time:   block.MsgBlock().Header.Timestamp.Unix(),
+	}
+	txs := block.Transactions()
+	if len(txs) <= 1 {
+		return stats, nil
+	}
+
+	rates := make([]txFeeRate, 0, len(txs)-1)
+	offset := 0
+	for _, tx := range txs[1:] {
+		numIns := len(tx.MsgTx().TxIn)
+		if offset+numIns > len(stxos) {
+			return nil, fmt.Errorf("spend journal of block %v is "+
+				"missing entries", block.Hash())
+		}
+		var fee int64
+		for _, stxo := range stxos[offset : offset+numIns] {
+			fee += stxo.Amount
+		}
+		offset += numIns
+		for _, txOut := range tx.MsgTx().TxOut {
+			fee -= txOut.Value
+		}
+
+		vsize := uint64(blockchain.GetTransactionWeight(tx)+
+			blockchain.WitnessScaleFactor-1) / blockchain.WitnessScaleFactor
+		rates = append(rates, txFeeRate{
+			feeRate: uint64(fee) * 1000 / vsize,
+			vsize:   vsize,
+		})
+		stats.VSize += vsize
+		stats.Fees += uint64(fee)
+	}
+	stats.TxCount = uint32(len(rates))
+
+	sort.Slice(rates, func(i, j int) bool {
+		return rates[i].feeRate < rates[j].feeRate
+	})
+	stats.MinFeeRate = rates[0].feeRate
+	stats.MaxFeeRate = rates[len(rates)-1].feeRate
+
+	// The fee rate at a percentile is the one of the transaction holding
+	// the virtual byte at that fraction of the size of the block.
+	var cumulative uint64
+	p := 0
+	for _, rate := range rates {
+		cumulative += rate.vsize
+		for ; p < len(FeeStatsPercentiles) &&
+			cumulative*100 >= stats.VSize*uint64(FeeStatsPercentiles[p]); p++ {
+
+			stats.FeeRatePercentiles[p] = rate.feeRate
+		}
+	}
+	return stats, nil
+}
+
+// serializeFeeStats returns the serialized fee statistics.
+func serializeFeeStats(s *FeeStats) []byte {
+	serialized := make([]byte, feeStatsSize)
+	byteOrder.PutUint64(serialized[0:], uint64(s.Time))
+	byteOrder.PutUint32(serialized[8:], s.TxCount)
+	byteOrder.PutUint64(serialized[12:], s.VSize)
+	byteOrder.PutUint64(serialized[20:], s.Fees)
+	byteOrder.PutUint64(serialized[28:], s.MinFeeRate)
+	byteOrder.PutUint64(serialized[36:], s.MaxFeeRate)
+	for i, rate := range s.FeeRatePercentiles {
+		byteOrder.PutUint64(serialized[44+8*i:], rate)
+	}
+	return serialized
+}
+
+// deserializeFeeStats returns the fee statistics of the block at the passed
+// height from their serialized form.
+func deserializeFeeStats(serialized []byte, height int32) (*FeeStats, error) {
+	if len(serialized) != feeStatsSize {
+		return nil, errDeserialize(fmt.Sprintf("unexpected length %d "+
+			"of fee statistics", len(serialized)))
+	}
+	s := &FeeStats{
+		Height:     height,
+		Time:       int64(byteOrder.Uint64(serialized[0:])),
+		TxCount:    byteOrder.Uint32(serialized[8:]),
+		VSize:      byteOrder.Uint64(serialized[12:]),
+		Fees:       byteOrder.Uint64(serialized[20:]),
+		MinFeeRate: byteOrder.Uint64(serialized[28:]),
+		MaxFeeRate: byteOrder.Uint64(serialized[36:]),
+	}
+	for i := range s.FeeRatePercentiles {
+		s.FeeRatePercentiles[i] = byteOrder.Uint64(serialized[44+8*i:])
+	}
+	return s, nil
+}
+
+// feeStatsHeightKey returns the key of the statistics of the block at the
+// passed height.
+func feeStatsHeightKey(height int32) []byte {
+	var key [4]byte
+	binary.BigEndian.PutUint32(key[:], uint32(height))
+	return key[:]
+}
+
+// feeStatsTimeKey returns the key ordering the block at the passed height by
+// its timestamp.  The timestamps before 1970 are clamped to zero.
+func feeStatsTimeKey(timestamp int64, height int32) []byte {
+	if timestamp < 0 {
+		timestamp = 0
+	}
+	var key [12]byte
+	binary.BigEndian.PutUint64(key[:], uint64(timestamp))
+	binary.BigEndian.PutUint32(key[8:], uint32(height))
+	return key[:]
+}
+
+// FeeStatsIndex implements an index of the fees paid by the transactions of
+// every block.
+type FeeStatsIndex struct {
+	db database.DB
+}
+
+// Ensure the FeeStatsIndex type implements the Indexer and NeedsInputser
+// interfaces.
+var _ Indexer = (*FeeStatsIndex)(nil)
+var _ NeedsInputser = (*FeeStatsIndex)(nil)
+
+// NeedsInputs signals that the index requires the referenced inputs in order
+// to compute the fees of the transactions.
+//
+// This implements the NeedsInputser interface.
+func (idx *FeeStatsIndex) NeedsInputs() bool {
+	return true
+}
+
+// Init is only provided to satisfy the Indexer interface as there is nothing
+// to initialize for this index.
+//
+// This is part of the Indexer interface.
+func (idx *FeeStatsIndex) Init() error {
+	return nil
+}
+
+// Key returns the database key to use for the index as a byte slice.
+//
+// This is part of the Indexer interface.
+func (idx *FeeStatsIndex) Key() []byte {
+	return feeStatsIndexKey
+}
+
+// Name returns the human-readable name of the index.
+//
+// This is part of the Indexer interface.
+func (idx *FeeStatsIndex) Name() string {
+	return feeStatsIndexName
+}
+
+// Create is invoked when the indexer manager determines the index needs to be
+// created for the first time.  It creates the bucket for the index and the
+// nested buckets of the blocks by height and by time.
+//
+// This is part of the Indexer interface.
+func (idx *FeeStatsIndex) Create(dbTx database.Tx) error {
+	bucket, err := dbTx.Metadata().CreateBucket(feeStatsIndexKey)
+	if err != nil {
+		return err
+	}
+	if _, err := bucket.CreateBucket(feeStatsHeightBucketName); err != nil {
+		return err
+	}
+	_, err = bucket.CreateBucket(feeStatsTimeBucketName)
+	return err
+}
+
+// ConnectBlock is invoked by the index manager when a new block has been
+// connected to the main chain.  This indexer stores the fee statistics of the
+// block.
+//
+// This is part of the Indexer interface.
+func (idx *FeeStatsIndex) ConnectBlock(dbTx database.Tx, block *btcutil.Block,
+	stxos []blockchain.SpentTxOut) error {
+
+	stats, err := blockFeeStats(block, stxos)
+	if err != nil {
+		return err
+	}
+	bucket := dbTx.Metadata().Bucket(feeStatsIndexKey)
+	err = bucket.Bucket(feeStatsHeightBucketName).Put(
+		feeStatsHeightKey(stats.Height), serializeFeeStats(stats))
+	if err != nil {
+		return err
+	}
+	return bucket.Bucket(feeStatsTimeBucketName).Put(
+		feeStatsTimeKey(stats.Time, stats.Height), nil)
+}
+
+// DisconnectBlock is invoked by the index manager when a block has been
+// disconnected from the main chain.  This indexer removes the fee statistics
+// of the block.
+//
+// This is part of the Indexer interface.
+func (idx *FeeStatsIndex) DisconnectBlock(dbTx database.Tx, block *btcutil.Block,
+	stxos []blockchain.SpentTxOut) error {
+
+	bucket := dbTx.Metadata().Bucket(feeStatsIndexKey)
+	err := bucket.Bucket(feeStatsHeightBucketName).Delete(
+		feeStatsHeightKey(block.Height()))
+	if err != nil {
+		return err
+	}
+	timestamp := block.MsgBlock().Header.Timestamp.Unix()
+	return bucket.Bucket(feeStatsTimeBucketName).Delete(
+		feeStatsTimeKey(timestamp, block.Height()))
+}
+
+// FeeStatsByHeight returns the fee statistics of the blocks from the start
+// height to the end height, both included, in order of height.  At most max
+// blocks are returned.
+//
+// This function is safe for concurrent access.
+func (idx *FeeStatsIndex) FeeStatsByHeight(start, end int32, max int) ([]*FeeStats, error) {
+	var stats []*FeeStats
+	if start < 0 {
+		start = 0
+	}
+	err := idx.db.View(func(dbTx database.Tx) error {
+		heights := dbTx.Metadata().Bucket(feeStatsIndexKey).
+			Bucket(feeStatsHeightBucketName)
+		cursor := heights.Cursor()
+		for ok := cursor.Seek(feeStatsHeightKey(start)); ok &&
+			len(stats) < max; ok = cursor.Next() {
+
+			height := int32(binary.BigEndian.Uint32(cursor.Key()))
+			if height > end {
+				break
+			}
+			s, err := deserializeFeeStats(cursor.Value(), height)
+			if err != nil {
+				return err
+			}
+			stats = append(stats, s)
+		}
+		return nil
+	})
+	return stats, err
+}
+
+// FeeStatsByTime returns the fee statistics of the blocks whose timestamp is
+// from the start time to the end time, both included, in order of timestamp.
+// The times are in seconds since 1 Jan 1970 GMT.  At most max blocks are
+// returned.
+//
+// This function is safe for concurrent access.
+func (idx *FeeStatsIndex) FeeStatsByTime(start, end int64, max int) ([]*FeeStats, error) {
+	var stats []*FeeStats
+	err := idx.db.View(func(dbTx database.Tx) error {
+		bucket := dbTx.Metadata().Bucket(feeStatsIndexKey)
+		heights := bucket.Bucket(feeStatsHeightBucketName)
+		cursor := bucket.Bucket(feeStatsTimeBucketName).Cursor()
+		for ok := cursor.Seek(feeStatsTimeKey(start, 0)); ok &&
+			len(stats) < max; ok = cursor.Next() {
+
+			key := cursor.Key()
+			if int64(binary.BigEndian.Uint64(key)) > end {
+				break
+			}
+			height := int32(binary.BigEndian.Uint32(key[8:]))
+			s, err := deserializeFeeStats(
+				heights.Get(feeStatsHeightKey(height)), height)
+			if err != nil {
+				return err
+			}
+			stats = append(stats, s)
+		}
+		return nil
+	})
+	return stats, err
+}
+
+// NewFeeStatsIndex returns a new instance of an indexer that is used to keep
+// the fee statistics of every block.
+//
+// It implements the Indexer interface which plugs into the IndexManager that
+// in turn is used by the blockchain package.  This allows the index to be
+// seamlessly maintained along with the chain.
+func NewFeeStatsIndex(db database.DB) *FeeStatsIndex {
+	return &FeeStatsIndex{db: db}
+}
+
+// DropFeeStatsIndex drops the fee statistics index from the provided database
+// if it exists.
+func DropFeeStatsIndex(db database.DB, interrupt <-chan struct{}) error {
+	return dropIndex(db, feeStatsIndexKey, feeStatsIndexName, interrupt)
+}
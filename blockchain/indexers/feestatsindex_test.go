@@ -0,0 +1,86 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package indexers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// TestBlockFeeStats ensures the fee statistics of a block leave out the
+// coinbase, weight the percentiles by virtual size and survive serialization.
+func TestBlockFeeStats(t *testing.T) {
+	t.Parallel()
+
+	msgBlock := &wire.MsgBlock{
+		Header: wire.BlockHeader{Timestamp: time.Unix(1600000000, 0)},
+	}
+	coinbase := wire.NewMsgTx(1)
+	coinbase.AddTxIn(&wire.TxIn{})
+	coinbase.AddTxOut(wire.NewTxOut(5000000000, []byte{0x51}))
+	msgBlock.AddTransaction(coinbase)
+
+	// The transactions have the same size, so each holds a third of the
+	// virtual bytes of the block.
+	fees := []int64{30000, 1000, 8000}
+	var stxos []blockchain.SpentTxOut
+	for i, fee := range fees {
+		tx := wire.NewMsgTx(1)
+		tx.AddTxIn(&wire.TxIn{
+			PreviousOutPoint: wire.OutPoint{Index: uint32(i)},
+		})
+		tx.AddTxOut(wire.NewTxOut(1000000, []byte{0x51}))
+		msgBlock.AddTransaction(tx)
+		stxos = append(stxos, blockchain.SpentTxOut{
+			Amount:   1000000 + fee,
+			PkScript: []byte{0x51},
+		})
+	}
+	block := btcutil.NewBlock(msgBlock)
+	block.SetHeight(7)
+
+	stats, err := blockFeeStats(block, stxos)
+	if err != nil {
+		t.Fatalf("blockFeeStats: %v", err)
+	}
+	vsize := uint64(msgBlock.Transactions[1].SerializeSize())
+	rate := func(fee int64) uint64 {
+		return uint64(fee) * 1000 / vsize
+	}
+	want := &FeeStats{
+		Height:     7,
+		Time:       1600000000,
+		TxCount:    3,
+		VSize:      3 * vsize,
+		Fees:       39000,
+		MinFeeRate: rate(1000),
+		MaxFeeRate: rate(30000),
+		FeeRatePercentiles: [len(FeeStatsPercentiles)]uint64{
+			rate(1000), rate(1000), rate(8000), rate(30000),
+			rate(30000),
+		},
+	}
+	if !reflect.DeepEqual(stats, want) {
+		t.Fatalf("got statistics %+v, want %+v", stats, want)
+	}
+
+	got, err := deserializeFeeStats(serializeFeeStats(stats), 7)
+	if err != nil {
+		t.Fatalf("deserializeFeeStats: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got deserialized statistics %+v, want %+v", got, want)
+	}
+
+	if _, err := blockFeeStats(block, stxos[:2]); err == nil {
+		t.Fatalf("blockFeeStats succeeded with missing spend journal " +
+			"entries")
+	}
+}
@@ -32,6 +32,15 @@ var (
 //   Field           Type             Size
 //   block hash      chainhash.Hash   chainhash.HashSize
 //   block height    uint32           4 bytes
+//
+// Indexes which only index the blocks from a start height onwards also have
+// an entry holding the height in the same bucket, keyed by the index key
+// prefixed with 's'.  The serialized format for a start height is:
+//
+//   <block height>
+//
+//   Field           Type             Size
+//   block height    uint32           4 bytes
 // -----------------------------------------------------------------------------
 
 // dbPutIndexerTip uses an existing database transaction to update or add the
@@ -64,6 +73,42 @@ func dbFetchIndexerTip(dbTx database.Tx, idxKey []byte) (*chainhash.Hash, int32,
 	return &hash, height, nil
 }
 
+// indexStartKey returns the key of the start height of an index.
+func indexStartKey(idxKey []byte) []byte {
+	startKey := make([]byte, len(idxKey)+1)
+	startKey[0] = 's'
+	copy(startKey[1:], idxKey)
+	return startKey
+}
+
+// dbPutIndexStartHeight uses an existing database transaction to store the
+// height of the first block indexed by the given index.
+func dbPutIndexStartHeight(dbTx database.Tx, idxKey []byte, height int32) error {
+	var serialized [4]byte
+	byteOrder.PutUint32(serialized[:], uint32(height))
+	indexesBucket := dbTx.Metadata().Bucket(indexTipsBucketName)
+	return indexesBucket.Put(indexStartKey(idxKey), serialized[:])
+}
+
+// dbFetchIndexStartHeight uses an existing database transaction to retrieve
+// the height of the first block indexed by the given index.  Indexes without
+// a stored start height index every block, so zero is returned for them.
+func dbFetchIndexStartHeight(dbTx database.Tx, idxKey []byte) (int32, error) {
+	indexesBucket := dbTx.Metadata().Bucket(indexTipsBucketName)
+	serialized := indexesBucket.Get(indexStartKey(idxKey))
+	if serialized == nil {
+		return 0, nil
+	}
+	if len(serialized) < 4 {
+		return 0, database.Error{
+			ErrorCode: database.ErrCorruption,
+			Description: fmt.Sprintf("unexpected end of data for "+
+				"index %q start height", string(idxKey)),
+		}
+	}
+	return int32(byteOrder.Uint32(serialized)), nil
+}
+
 // dbIndexConnectBlock adds all of the index entries associated with the
 // given block using the provided indexer and updates the tip of the indexer
 // accordingly.  An error will be returned if the current tip for the indexer is
@@ -85,9 +130,12 @@ func dbIndexConnectBlock(dbTx database.Tx, indexer Indexer, block *btcutil.Block
 			curTipHash, block.Hash()))
 	}
 
-	// Notify the indexer with the connected block so it can index it.
-	if err := indexer.ConnectBlock(dbTx, block, stxo); err != nil {
-		return err
+	// Notify the indexer with the connected block so it can index it
+	// unless the block is below the start height of the index.
+	if block.Height() >= indexStartHeight(indexer) {
+		if err := indexer.ConnectBlock(dbTx, block, stxo); err != nil {
+			return err
+		}
 	}
 
 	// Update the current index tip.
@@ -116,9 +164,12 @@ func dbIndexDisconnectBlock(dbTx database.Tx, indexer Indexer, block *btcutil.Bl
 	}
 
 	// Notify the indexer with the disconnected block so it can remove all
-	// of the appropriate entries.
-	if err := indexer.DisconnectBlock(dbTx, block, stxo); err != nil {
-		return err
+	// of the appropriate entries.  Blocks below the start height of the
+	// index have no entries.
+	if block.Height() >= indexStartHeight(indexer) {
+		if err := indexer.DisconnectBlock(dbTx, block, stxo); err != nil {
+			return err
+		}
 	}
 
 	// Update the current index tip.
@@ -132,6 +183,7 @@ func dbIndexDisconnectBlock(dbTx database.Tx, indexer Indexer, block *btcutil.Bl
 type Manager struct {
 	db             database.DB
 	enabledIndexes []Indexer
+	readOnly       bool
 }
 
 // Ensure the Manager type implements the blockchain.IndexManager interface.
@@ -220,11 +272,71 @@ func (m *Manager) maybeCreateIndexes(dbTx database.Tx) error {
 		if err != nil {
 			return err
 		}
+
+		// Remember where partial indexes start so a later change of
+		// the start height is detected.
+		if startHeight := indexStartHeight(indexer); startHeight > 0 {
+			err := dbPutIndexStartHeight(dbTx, idxKey, startHeight)
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
 }
 
+// checkReadOnly ensures each of the enabled indexes exists and is caught up to
+// the best block of the passed chain, since the indexes of a read-only
+// database can't be created, rolled back or caught up.
+func (m *Manager) checkReadOnly(chain *blockchain.BlockChain) error {
+	best := chain.BestSnapshot()
+	return m.db.View(func(dbTx database.Tx) error {
+		indexesBucket := dbTx.Metadata().Bucket(indexTipsBucketName)
+		for _, indexer := range m.enabledIndexes {
+			idxKey := indexer.Key()
+			if indexesBucket == nil || indexesBucket.Get(idxKey) == nil ||
+				indexesBucket.Get(indexDropKey(idxKey)) != nil {
+
+				return fmt.Errorf("the %s is not in the read-only "+
+					"database -- enable it on the node the "+
+					"database is copied from", indexer.Name())
+			}
+			hash, height, err := dbFetchIndexerTip(dbTx, idxKey)
+			if err != nil {
+				return err
+			}
+			if *hash != best.Hash {
+				return fmt.Errorf("the %s of the read-only database "+
+					"is at height %d instead of the best block "+
+					"height %d -- it can only be caught up in a "+
+					"writable database", indexer.Name(), height,
+					best.Height)
+			}
+		}
+		return m.checkStartHeights(dbTx)
+	})
+}
+
+// checkStartHeights ensures the enabled indexes were built from the start
+// heights they are configured with, since changing the start height of an
+// existing index would leave it with missing or unexpected entries.
+func (m *Manager) checkStartHeights(dbTx database.Tx) error {
+	for _, indexer := range m.enabledIndexes {
+		startHeight, err := dbFetchIndexStartHeight(dbTx, indexer.Key())
+		if err != nil {
+			return err
+		}
+		if want := indexStartHeight(indexer); startHeight != want {
+			return fmt.Errorf("the %s was built from height %d "+
+				"instead of height %d -- drop it to rebuild it "+
+				"from the new height", indexer.Name(),
+				startHeight, want)
+		}
+	}
+	return nil
+}
+
 // Init initializes the enabled indexes.  This is called during chain
 // initialization and primarily consists of catching up all indexes to the
 // current best chain tip.  This is necessary since each index can be disabled
@@ -243,6 +355,19 @@ func (m *Manager) Init(chain *blockchain.BlockChain, interrupt <-chan struct{})
 		return errInterruptRequested
 	}
 
+	// The indexes of a read-only database are used as they are.
+	if m.readOnly {
+		if err := m.checkReadOnly(chain); err != nil {
+			return err
+		}
+		for _, indexer := range m.enabledIndexes {
+			if err := indexer.Init(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
 	// Finish and drops that were previously interrupted.
 	if err := m.maybeFinishDrops(interrupt); err != nil {
 		return err
@@ -257,7 +382,10 @@ func (m *Manager) Init(chain *blockchain.BlockChain, interrupt <-chan struct{})
 			return err
 		}
 
-		return m.maybeCreateIndexes(dbTx)
+		if err := m.maybeCreateIndexes(dbTx); err != nil {
+			return err
+		}
+		return m.checkStartHeights(dbTx)
 	})
 	if err != nil {
 		return err
@@ -368,7 +496,7 @@ func (m *Manager) Init(chain *blockchain.BlockChain, interrupt <-chan struct{})
 	bestHeight := chain.BestSnapshot().Height
 	lowestHeight := bestHeight
 	indexerHeights := make([]int32, len(m.enabledIndexes))
-	err = m.db.View(func(dbTx database.Tx) error {
+	err = m.db.Update(func(dbTx database.Tx) error {
 		for i, indexer := range m.enabledIndexes {
 			idxKey := indexer.Key()
 			hash, height, err := dbFetchIndexerTip(dbTx, idxKey)
@@ -378,6 +506,27 @@ func (m *Manager) Init(chain *blockchain.BlockChain, interrupt <-chan struct{})
 
 			log.Debugf("Current %s tip (height %d, hash %v)",
 				indexer.Name(), height, hash)
+
+			// Move the tip of indexes which start above it
+			// directly to the block before their start height
+			// since the blocks below it are not indexed.
+			skipHeight := indexStartHeight(indexer) - 1
+			if skipHeight > bestHeight {
+				skipHeight = bestHeight
+			}
+			if height < skipHeight {
+				hash, err = chain.BlockHashByHeight(skipHeight)
+				if err != nil {
+					return err
+				}
+				err = dbPutIndexerTip(dbTx, idxKey, hash, skipHeight)
+				if err != nil {
+					return err
+				}
+				log.Infof("Skipped %s to height %d", indexer.Name(),
+					skipHeight)
+				height = skipHeight
+			}
 			indexerHeights[i] = height
 			if height < lowestHeight {
 				lowestHeight = height
@@ -544,6 +693,18 @@ func NewManager(db database.DB, enabledIndexes []Indexer) *Manager {
 	}
 }
 
+// NewReadOnlyManager returns a new index manager with the provided indexes
+// enabled for a database open read-only.  Its Init only checks the indexes
+// exist and are caught up to the best block, instead of creating and catching
+// them up.
+func NewReadOnlyManager(db database.DB, enabledIndexes []Indexer) *Manager {
+	return &Manager{
+		db:             db,
+		enabledIndexes: enabledIndexes,
+		readOnly:       true,
+	}
+}
+
 // dropIndex drops the passed index from the database.  Since indexes can be
 // massive, it deletes the index in multiple database transactions in order to
 // keep memory usage to reasonable levels.  It also marks the drop in progress
@@ -685,6 +846,9 @@ func dropIndex(db database.DB, idxKey []byte, idxName string, interrupt <-chan s
 		if err := indexesBucket.Delete(idxKey); err != nil {
 			return err
 		}
+		if err := indexesBucket.Delete(indexStartKey(idxKey)); err != nil {
+			return err
+		}
 
 		return indexesBucket.Delete(indexDropKey(idxKey))
 	})
@@ -282,12 +282,15 @@ func dbRemoveTxIndexEntries(dbTx database.Tx, block *btcutil.Block) error {
 // TxIndex implements a transaction by hash index.  That is to say, it supports
 // querying all transactions by their hash.
 type TxIndex struct {
-	db         database.DB
-	curBlockID uint32
+	db          database.DB
+	curBlockID  uint32
+	startHeight int32
 }
 
-// Ensure the TxIndex type implements the Indexer interface.
+// Ensure the TxIndex type implements the Indexer and StartHeighter
+// interfaces.
 var _ Indexer = (*TxIndex)(nil)
+var _ StartHeighter = (*TxIndex)(nil)
 
 // Init initializes the hash-based transaction index.  In particular, it finds
 // the highest used block ID and stores it for later use when connecting or
@@ -366,6 +369,14 @@ func (idx *TxIndex) Name() string {
 	return txIndexName
 }
 
+// StartHeight returns the height of the first block whose transactions are
+// indexed.  The transactions of earlier blocks are never found in the index.
+//
+// This is part of the StartHeighter interface.
+func (idx *TxIndex) StartHeight() int32 {
+	return idx.startHeight
+}
+
 // Create is invoked when the indexer manager determines the index needs
 // to be created for the first time.  It creates the buckets for the hash-based
 // transaction index and the internal block ID indexes.
@@ -457,6 +468,19 @@ func NewTxIndex(db database.DB) *TxIndex {
 	return &TxIndex{db: db}
 }
 
+// NewTxIndexFromHeight returns a new instance of a transaction indexer like
+// NewTxIndex, except only the transactions of the blocks from the passed
+// height onwards are indexed.  This saves building and storing the index for
+// the older blocks when only recent transactions need to be looked up.
+//
+// The start height is recorded when the index is created and the index
+// manager refuses to load the index with a different start height.  The
+// address index depends on every block being in the transaction index, so it
+// must not be used along with a start height above zero.
+func NewTxIndexFromHeight(db database.DB, startHeight int32) *TxIndex {
+	return &TxIndex{db: db, startHeight: startHeight}
+}
+
 // dropBlockIDIndex drops the internal block id index.
 func dropBlockIDIndex(db database.DB) error {
 	return db.Update(func(dbTx database.Tx) error {
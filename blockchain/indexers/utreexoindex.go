@@ -0,0 +1,434 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package indexers
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/blockchain/utreexo"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/database"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+const (
+	// utreexoIndexName is the human-readable name for the index.
+	utreexoIndexName = "utreexo accumulator"
+
+	// utreexoOpSize is the size of a serialized accumulator operation.
+	utreexoOpSize = 1 + chainhash.HashSize + 8
+)
+
+var (
+	// utreexoIndexKey is the key of the utreexo accumulator index and the
+	// db bucket used to house it.
+	utreexoIndexKey = []byte("utreexoidx")
+
+	// utreexoLeavesBucketName is the name of the bucket holding the leaves
+	// of the accumulator, nested in the index bucket.
+	utreexoLeavesBucketName = []byte("leaves")
+
+	// utreexoUndoBucketName is the name of the bucket holding the changes
+	// made to the accumulator by every block, nested in the index bucket.
+	utreexoUndoBucketName = []byte("undo")
+)
+
+// -----------------------------------------------------------------------------
+// The utreexo accumulator index keeps a utreexo.Forest of the unspent outputs
+// of the main chain in memory so proofs of the outputs can be served to peers.
+//
+// The leaves of the forest are stored by position in the nested "leaves"
+// bucket, which is enough to rebuild the forest on start up:
+//
+//   <position> = <leaf hash>
+//
+//   Field           Type              Size
+//   position        uint64 (BE)       8 bytes
+//   leaf hash       chainhash.Hash    32 bytes
+//
+// The changes every block made to the forest are stored in the nested "undo"
+// bucket so they can be undone exactly when the block is disconnected:
+//
+//   <block height> = <operation>...
+//
+//   Field           Type              Size
+//   block height    uint32 (BE)       4 bytes
+//   deletion        bool              1 byte
+//   leaf hash       chainhash.Hash    32 bytes
+//   position        uint64 (BE)       8 bytes
+//
+// The position of an operation is only meaningful for deletions.
+// -----------------------------------------------------------------------------
+
+// utreexoOp is a change made to the accumulator by a block.
+type utreexoOp struct {
+	deletion bool
+	leaf     chainhash.Hash
+	pos      uint64
+}
+
+// serializeUtreexoOps returns the passed operations serialized for the undo
+// bucket.
+func serializeUtreexoOps(ops []utreexoOp) []byte {
+	serialized := make([]byte, len(ops)*utreexoOpSize)
+	for i, op := range ops {
+		buf := serialized[i*utreexoOpSize:]
+		if op.deletion {
+			buf[0] = 1
+		}
+		copy(buf[1:], op.leaf[:])
+		binary.BigEndian.PutUint64(buf[1+chainhash.HashSize:], op.pos)
+	}
+	return serialized
+}
+
+// deserializeUtreexoOps decodes operations serialized for the undo bucket.
+func deserializeUtreexoOps(serialized []byte) ([]utreexoOp, error) {
+	if len(serialized)%utreexoOpSize != 0 {
+		return nil, database.Error{
+			ErrorCode:   database.ErrCorruption,
+			Description: "corrupt utreexo undo entry",
+		}
+	}
+	ops := make([]utreexoOp, len(serialized)/utreexoOpSize)
+	for i := range ops {
+		buf := serialized[i*utreexoOpSize:]
+		ops[i].deletion = buf[0] != 0
+		copy(ops[i].leaf[:], buf[1:])
+		ops[i].pos = binary.BigEndian.Uint64(buf[1+chainhash.HashSize:])
+	}
+	return ops, nil
+}
+
+// utreexoPositionKey returns the key of the leaf at the passed position.
+func utreexoPositionKey(pos uint64) []byte {
+	var key [8]byte
+	binary.BigEndian.PutUint64(key[:], pos)
+	return key[:]
+}
+
+// utreexoUndoKey returns the key of the undo data of the block at the passed
+// height.
+func utreexoUndoKey(height int32) []byte {
+	var key [4]byte
+	binary.BigEndian.PutUint32(key[:], uint32(height))
+	return key[:]
+}
+
+// UtreexoIndex implements an index which maintains a utreexo accumulator of
+// the unspent outputs alongside the utxo set.
+type UtreexoIndex struct {
+	db database.DB
+
+	// mtx protects the forest and the tip of the index, which are changed
+	// by the index manager while proofs are served to peers.
+	mtx     sync.RWMutex
+	forest  *utreexo.Forest
+	tipHash chainhash.Hash
+}
+
+// Ensure the UtreexoIndex type implements the Indexer and NeedsInputser
+// interfaces.
+var _ Indexer = (*UtreexoIndex)(nil)
+var _ NeedsInputser = (*UtreexoIndex)(nil)
+
+// NeedsInputs signals that the index requires the referenced inputs in order
+// to delete the spent outputs from the accumulator.
+//
+// This implements the NeedsInputser interface.
+func (idx *UtreexoIndex) NeedsInputs() bool {
+	return true
+}
+
+// Init rebuilds the forest from the leaves stored in the database.
+//
+// This is part of the Indexer interface.
+func (idx *UtreexoIndex) Init() error {
+	idx.mtx.Lock()
+	defer idx.mtx.Unlock()
+
+	forest := utreexo.NewForest()
+	err := idx.db.View(func(dbTx database.Tx) error {
+		tipHash, _, err := dbFetchIndexerTip(dbTx, utreexoIndexKey)
+		if err != nil {
+			return err
+		}
+		idx.tipHash = *tipHash
+
+		leaves := dbTx.Metadata().Bucket(utreexoIndexKey).
+			Bucket(utreexoLeavesBucketName)
+		cursor := leaves.Cursor()
+		for ok := cursor.First(); ok; ok = cursor.Next() {
+			pos := binary.BigEndian.Uint64(cursor.Key())
+			if pos != forest.NumLeaves() {
+				return database.Error{
+					ErrorCode: database.ErrCorruption,
+					Description: fmt.Sprintf("utreexo leaf "+
+						"%d is missing", forest.NumLeaves()),
+				}
+			}
+			var leaf chainhash.Hash
+			copy(leaf[:], cursor.Value())
+			if err := forest.Add(leaf); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	idx.forest = forest
+	log.Infof("Loaded %d leaves of the utreexo accumulator",
+		forest.NumLeaves())
+	return nil
+}
+
+// Key returns the database key to use for the index as a byte slice.
+//
+// This is part of the Indexer interface.
+func (idx *UtreexoIndex) Key() []byte {
+	return utreexoIndexKey
+}
+
+// Name returns the human-readable name of the index.
+//
+// This is part of the Indexer interface.
+func (idx *UtreexoIndex) Name() string {
+	return utreexoIndexName
+}
+
+// Create is invoked when the indexer manager determines the index needs to be
+// created for the first time.  It creates the bucket for the index and the
+// nested buckets of the leaves and the undo data.
+//
+// This is part of the Indexer interface.
+func (idx *UtreexoIndex) Create(dbTx database.Tx) error {
+	bucket, err := dbTx.Metadata().CreateBucket(utreexoIndexKey)
+	if err != nil {
+		return err
+	}
+	if _, err := bucket.CreateBucket(utreexoLeavesBucketName); err != nil {
+		return err
+	}
+	_, err = bucket.CreateBucket(utreexoUndoBucketName)
+	return err
+}
+
+// undo reverts the passed operations in reverse order, recording the positions
+// of the leaves it changes.
+func (idx *UtreexoIndex) undo(ops []utreexoOp, dirty map[uint64]struct{}) error {
+	for i := len(ops) - 1; i >= 0; i-- {
+		op := &ops[i]
+		n := idx.forest.NumLeaves()
+		var err error
+		if op.deletion {
+			err = idx.forest.UndoDelete(op.leaf, op.pos)
+			dirty[op.pos] = struct{}{}
+			dirty[n] = struct{}{}
+		} else {
+			err = idx.forest.UndoAdd(op.leaf)
+			dirty[n-1] = struct{}{}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// putLeaves writes the leaves at the passed positions to the database and
+// removes the positions beyond the last leaf.
+func (idx *UtreexoIndex) putLeaves(dbTx database.Tx, dirty map[uint64]struct{}) error {
+	leaves := dbTx.Metadata().Bucket(utreexoIndexKey).
+		Bucket(utreexoLeavesBucketName)
+	n := idx.forest.NumLeaves()
+	for pos := range dirty {
+		var err error
+		if pos < n {
+			leaf := idx.forest.Leaf(pos)
+			err = leaves.Put(utreexoPositionKey(pos), leaf[:])
+		} else {
+			err = leaves.Delete(utreexoPositionKey(pos))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConnectBlock is invoked by the index manager when a new block has been
+// connected to the main chain.  This indexer deletes the outputs spent by the
+// block from the accumulator and adds the outputs it creates.
+//
+// This is part of the Indexer interface.
+func (idx *UtreexoIndex) ConnectBlock(dbTx database.Tx, block *btcutil.Block,
+	stxos []blockchain.SpentTxOut) error {
+
+	idx.mtx.Lock()
+	defer idx.mtx.Unlock()
+
+	var ops []utreexoOp
+	dirty := make(map[uint64]struct{})
+	apply := func() error {
+		stxoIndex := 0
+		for txIdx, tx := range block.Transactions() {
+			// Coinbases do not reference any inputs.
+			if txIdx != 0 {
+				for _, txIn := range tx.MsgTx().TxIn {
+					stxo := &stxos[stxoIndex]
+					stxoIndex++
+					leaf := (&utreexo.LeafData{
+						OutPoint:   txIn.PreviousOutPoint,
+						Height:     stxo.Height,
+						IsCoinBase: stxo.IsCoinBase,
+						Amount:     stxo.Amount,
+						PkScript:   stxo.PkScript,
+					}).Hash()
+					n := idx.forest.NumLeaves()
+					pos, err := idx.forest.Delete(leaf)
+					if err != nil {
+						return err
+					}
+					ops = append(ops, utreexoOp{
+						deletion: true,
+						leaf:     leaf,
+						pos:      pos,
+					})
+					dirty[pos] = struct{}{}
+					dirty[n-1] = struct{}{}
+				}
+			}
+
+			// The outputs of the genesis block are not spendable
+			// and never enter the utxo set, and neither do the
+			// provably unspendable ones.
+			if block.Height() == 0 {
+				continue
+			}
+			for i, txOut := range tx.MsgTx().TxOut {
+				if txscript.IsUnspendable(txOut.PkScript) {
+					continue
+				}
+				leaf := (&utreexo.LeafData{
+					OutPoint: wire.OutPoint{
+						Hash:  *tx.Hash(),
+						Index: uint32(i),
+					},
+					Height:     block.Height(),
+					IsCoinBase: txIdx == 0,
+					Amount:     txOut.Value,
+					PkScript:   txOut.PkScript,
+				}).Hash()
+				dirty[idx.forest.NumLeaves()] = struct{}{}
+				if err := idx.forest.Add(leaf); err != nil {
+					return err
+				}
+				ops = append(ops, utreexoOp{leaf: leaf})
+			}
+		}
+		return nil
+	}
+	if err := apply(); err != nil {
+		// Leave the forest as it was so it keeps matching the
+		// database.
+		if undoErr := idx.undo(ops, dirty); undoErr != nil {
+			log.Errorf("Unable to revert the utreexo "+
+				"accumulator: %v", undoErr)
+		}
+		return err
+	}
+
+	if err := idx.putLeaves(dbTx, dirty); err != nil {
+		return err
+	}
+	undoBucket := dbTx.Metadata().Bucket(utreexoIndexKey).
+		Bucket(utreexoUndoBucketName)
+	err := undoBucket.Put(utreexoUndoKey(block.Height()),
+		serializeUtreexoOps(ops))
+	if err != nil {
+		return err
+	}
+	idx.tipHash = *block.Hash()
+	return nil
+}
+
+// DisconnectBlock is invoked by the index manager when a block has been
+// disconnected from the main chain.  This indexer undoes the changes the block
+// made to the accumulator.
+//
+// This is part of the Indexer interface.
+func (idx *UtreexoIndex) DisconnectBlock(dbTx database.Tx, block *btcutil.Block,
+	stxos []blockchain.SpentTxOut) error {
+
+	idx.mtx.Lock()
+	defer idx.mtx.Unlock()
+
+	undoBucket := dbTx.Metadata().Bucket(utreexoIndexKey).
+		Bucket(utreexoUndoBucketName)
+	key := utreexoUndoKey(block.Height())
+	serialized := undoBucket.Get(key)
+	if serialized == nil {
+		return fmt.Errorf("missing utreexo undo data for block %v",
+			block.Hash())
+	}
+	ops, err := deserializeUtreexoOps(serialized)
+	if err != nil {
+		return err
+	}
+	dirty := make(map[uint64]struct{})
+	if err := idx.undo(ops, dirty); err != nil {
+		return err
+	}
+	if err := idx.putLeaves(dbTx, dirty); err != nil {
+		return err
+	}
+	if err := undoBucket.Delete(key); err != nil {
+		return err
+	}
+	idx.tipHash = block.MsgBlock().Header.PrevBlock
+	return nil
+}
+
+// Prove returns the hash of the tip of the index, the roots of the accumulator
+// at the tip and the proofs of the passed outputs.  The proof of an output is
+// nil when it is not in the accumulator.
+//
+// This function is safe for concurrent access.
+func (idx *UtreexoIndex) Prove(leaves []utreexo.LeafData) (*chainhash.Hash,
+	*utreexo.Stump, []*utreexo.Proof) {
+
+	idx.mtx.RLock()
+	defer idx.mtx.RUnlock()
+
+	proofs := make([]*utreexo.Proof, len(leaves))
+	for i := range leaves {
+		proofs[i], _ = idx.forest.Prove(leaves[i].Hash())
+	}
+	tipHash := idx.tipHash
+	return &tipHash, idx.forest.Stump(), proofs
+}
+
+// NewUtreexoIndex returns a new instance of an indexer that is used to
+// maintain a utreexo accumulator of the unspent outputs.
+//
+// It implements the Indexer interface which plugs into the IndexManager that
+// in turn is used by the blockchain package.  This allows the index to be
+// seamlessly maintained along with the chain.
+func NewUtreexoIndex(db database.DB) *UtreexoIndex {
+	return &UtreexoIndex{db: db}
+}
+
+// DropUtreexoIndex drops the utreexo accumulator index from the provided
+// database if it exists.
+func DropUtreexoIndex(db database.DB, interrupt <-chan struct{}) error {
+	return dropIndex(db, utreexoIndexKey, utreexoIndexName, interrupt)
+}
@@ -0,0 +1,416 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package indexers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/database"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+const (
+	// utxoStatsIndexName is the human-readable name for the index.
+	utxoStatsIndexName = "utxo statistics index"
+
+	// UtxoStatsBuckets is the number of buckets of the value histograms.
+	// Bucket i counts the outputs whose value has i decimal digits, so the
+	// first bucket counts the outputs without value and the last one the
+	// outputs of at least 10^18 units.
+	UtxoStatsBuckets = 20
+
+	// UtxoStatsDustRelayFee is the minimum transaction relay fee, in units
+	// per kilobyte, the index uses to tell whether an output is dust.  It
+	// matches the default policy of the memory pool.
+	UtxoStatsDustRelayFee = btcutil.Amount(1000)
+
+	// DefaultUtxoStatsInterval is the default number of blocks between
+	// two snapshots of the statistics.
+	DefaultUtxoStatsInterval = 1000
+
+	// utxoClassStatsSize is the size of the serialized statistics of a
+	// script class.
+	utxoClassStatsSize = 1 + 8*(4+UtxoStatsBuckets)
+)
+
+var (
+	// utxoStatsIndexKey is the key of the utxo statistics index and the
+	// db bucket used to house it.
+	utxoStatsIndexKey = []byte("utxostatsidx")
+
+	// utxoStatsCurrentKey is the key of the statistics of the unspent
+	// outputs at the tip of the index.
+	utxoStatsCurrentKey = []byte("current")
+
+	// utxoStatsSnapshotsBucketName is the name of the bucket holding the
+	// snapshots of the statistics, nested in the index bucket.
+	utxoStatsSnapshotsBucketName = []byte("snapshots")
+)
+
+// -----------------------------------------------------------------------------
+// The utxo statistics index keeps the number and value of the unspent outputs
+// of every script class along with the outputs considered dust and a histogram
+// of the values.  The statistics at the tip of the index are updated with
+// every block and a copy of them is kept every configured number of blocks so
+// the evolution of the utxo set can be followed.
+//
+// The statistics at the tip are stored in the index bucket under the key
+// "current" and the snapshots in the nested "snapshots" bucket:
+//
+//   <block height> = <statistics>
+//
+//   Field           Type              Size
+//   block height    uint32 (BE)       4 bytes
+//
+// The serialized format of statistics is a sequence of the statistics of
+// every script class with unspent outputs:
+//
+//   Field           Type              Size
+//   script class    uint8             1 byte
+//   count           uint64            8 bytes
+//   value           uint64            8 bytes
+//   dust count      uint64            8 bytes
+//   dust value      uint64            8 bytes
+//   histogram       [20]uint64        160 bytes
+// -----------------------------------------------------------------------------
+
+// UtxoClassStats are the statistics of the unspent outputs of a script class.
+type UtxoClassStats struct {
+	Count     uint64
+	Value     uint64
+	DustCount uint64
+	DustValue uint64
+
+	// Histogram counts the outputs by the number of decimal digits of
+	// their value.
+	Histogram [UtxoStatsBuckets]uint64
+}
+
+// UtxoStats are the statistics of the unspent outputs after the block at
+// Height was connected.
+type UtxoStats struct {
+	Height  int32
+	Classes map[txscript.ScriptClass]*UtxoClassStats
+}
+
+// utxoStatsBucket returns the histogram bucket of the passed value.
+func utxoStatsBucket(value int64) int {
+	bucket := 0
+	for ; value > 0 && bucket < UtxoStatsBuckets-1; value /= 10 {
+		bucket++
+	}
+	return bucket
+}
+
+// isDustOutput returns whether the passed output is dust at the relay fee of
+// the index.  It mirrors the dust policy of the memory pool: an output is
+// dust when spending it costs more than a third of its value.
+func isDustOutput(txOut *wire.TxOut) bool {
+	totalSize := txOut.SerializeSize() + 41
+	if txscript.IsWitnessProgram(txOut.PkScript) {
+		totalSize += 107 / blockchain.WitnessScaleFactor
+	} else {
+		totalSize += 107
+	}
+	return txOut.Value*1000/(3*int64(totalSize)) < int64(UtxoStatsDustRelayFee)
+}
+
+// add adds the passed output to the statistics, or removes it when sign is
+// negative.
+func (s *UtxoStats) add(txOut *wire.TxOut, sign int) {
+	// Unspendable outputs never enter the utxo set.
+	if txscript.IsUnspendable(txOut.PkScript) {
+		return
+	}
+
+	class := txscript.GetScriptClass(txOut.PkScript)
+	stats := s.Classes[class]
+	if stats == nil {
+		stats = new(UtxoClassStats)
+		s.Classes[class] = stats
+	}
+	value := uint64(txOut.Value)
+	bucket := utxoStatsBucket(txOut.Value)
+	dust := isDustOutput(txOut)
+	if sign < 0 {
+		stats.Count--
+		stats.Value -= value
+		stats.Histogram[bucket]--
+		if dust {
+			stats.DustCount--
+			stats.DustValue -= value
+		}
+		if stats.Count == 0 {
+			delete(s.Classes, class)
+		}
+		return
+	}
+	stats.Count++
+	stats.Value += value
+	stats.Histogram[bucket]++
+	if dust {
+		stats.DustCount++
+		stats.DustValue += value
+	}
+}
+
+// serializeUtxoStats returns the serialized statistics.
+func serializeUtxoStats(s *UtxoStats) []byte {
+	serialized := make([]byte, 0, len(s.Classes)*utxoClassStatsSize)
+	var buf [8]byte
+	putUint64 := func(v uint64) {
+		byteOrder.PutUint64(buf[:], v)
+		serialized = append(serialized, buf[:]...)
+	}
+	for class := txscript.NonStandardTy; class <= txscript.NullDataTy; class++ {
+		stats := s.Classes[class]
+		if stats == nil {
+			continue
+		}
+		serialized = append(serialized, byte(class))
+		putUint64(stats.Count)
+		putUint64(stats.Value)
+		putUint64(stats.DustCount)
+		putUint64(stats.DustValue)
+		for _, count := range stats.Histogram {
+			putUint64(count)
+		}
+	}
+	return serialized
+}
+
+// deserializeUtxoStats returns the statistics at the passed height from their
+// serialized form.
+func deserializeUtxoStats(serialized []byte, height int32) (*UtxoStats, error) {
+	if len(serialized)%utxoClassStatsSize != 0 {
+		return nil, errDeserialize(fmt.Sprintf("unexpected length %d "+
+			"of utxo statistics", len(serialized)))
+	}
+	s := &UtxoStats{
+		Height:  height,
+		Classes: make(map[txscript.ScriptClass]*UtxoClassStats),
+	}
+	for ; len(serialized) > 0; serialized = serialized[utxoClassStatsSize:] {
+		stats := new(UtxoClassStats)
+		offset := 1
+		getUint64 := func() uint64 {
+			v := byteOrder.Uint64(serialized[offset:])
+			offset += 8
+			return v
+		}
+		stats.Count = getUint64()
+		stats.Value = getUint64()
+		stats.DustCount = getUint64()
+		stats.DustValue = getUint64()
+		for i := range stats.Histogram {
+			stats.Histogram[i] = getUint64()
+		}
+		s.Classes[txscript.ScriptClass(serialized[0])] = stats
+	}
+	return s, nil
+}
+
+// utxoStatsSnapshotKey returns the key of the snapshot at the passed height.
+func utxoStatsSnapshotKey(height int32) []byte {
+	var key [4]byte
+	binary.BigEndian.PutUint32(key[:], uint32(height))
+	return key[:]
+}
+
+// UtxoStatsIndex implements an index of statistics of the unspent outputs by
+// script class.
+type UtxoStatsIndex struct {
+	db       database.DB
+	interval int32
+}
+
+// Ensure the UtxoStatsIndex type implements the Indexer and NeedsInputser
+// interfaces.
+var _ Indexer = (*UtxoStatsIndex)(nil)
+var _ NeedsInputser = (*UtxoStatsIndex)(nil)
+
+// NeedsInputs signals that the index requires the referenced inputs in order
+// to remove the spent outputs from the statistics.
+//
+// This implements the NeedsInputser interface.
+func (idx *UtxoStatsIndex) NeedsInputs() bool {
+	return true
+}
+
+// Init is only provided to satisfy the Indexer interface as there is nothing
+// to initialize for this index.
+//
+// This is part of the Indexer interface.
+func (idx *UtxoStatsIndex) Init() error {
+	return nil
+}
+
+// Key returns the database key to use for the index as a byte slice.
+//
+// This is part of the Indexer interface.
+func (idx *UtxoStatsIndex) Key() []byte {
+	return utxoStatsIndexKey
+}
+
+// Name returns the human-readable name of the index.
+//
+// This is part of the Indexer interface.
+func (idx *UtxoStatsIndex) Name() string {
+	return utxoStatsIndexName
+}
+
+// Create is invoked when the indexer manager determines the index needs to be
+// created for the first time.  It creates the bucket for the index and the
+// nested bucket of the snapshots.
+//
+// This is part of the Indexer interface.
+func (idx *UtxoStatsIndex) Create(dbTx database.Tx) error {
+	bucket, err := dbTx.Metadata().CreateBucket(utxoStatsIndexKey)
+	if err != nil {
+		return err
+	}
+	_, err = bucket.CreateBucket(utxoStatsSnapshotsBucketName)
+	return err
+}
+
+// update applies the outputs created and spent by the passed block to the
+// statistics at the tip of the index.  The created outputs are added and the
+// spent ones removed when sign is positive, and the reverse is done when it
+// is negative.
+func (idx *UtxoStatsIndex) update(dbTx database.Tx, block *btcutil.Block,
+	stxos []blockchain.SpentTxOut, sign int) (*UtxoStats, error) {
+
+	bucket := dbTx.Metadata().Bucket(utxoStatsIndexKey)
+	stats, err := deserializeUtxoStats(bucket.Get(utxoStatsCurrentKey),
+		block.Height())
+	if err != nil {
+		return nil, err
+	}
+
+	// The outputs of the genesis block are not spendable and never enter
+	// the utxo set.
+	if block.Height() != 0 {
+		for _, tx := range block.Transactions() {
+			for _, txOut := range tx.MsgTx().TxOut {
+				stats.add(txOut, sign)
+			}
+		}
+	}
+	for i := range stxos {
+		stats.add(&wire.TxOut{
+			Value:    stxos[i].Amount,
+			PkScript: stxos[i].PkScript,
+		}, -sign)
+	}
+
+	err = bucket.Put(utxoStatsCurrentKey, serializeUtxoStats(stats))
+	return stats, err
+}
+
+// ConnectBlock is invoked by the index manager when a new block has been
+// connected to the main chain.  This indexer adds the outputs created by the
+// block to the statistics, removes the outputs it spends, and keeps a
+// snapshot of the statistics when the height of the block is a multiple of
+// the snapshot interval.
+//
+// This is part of the Indexer interface.
+func (idx *UtxoStatsIndex) ConnectBlock(dbTx database.Tx, block *btcutil.Block,
+	stxos []blockchain.SpentTxOut) error {
+
+	stats, err := idx.update(dbTx, block, stxos, 1)
+	if err != nil {
+		return err
+	}
+	if block.Height()%idx.interval != 0 {
+		return nil
+	}
+	snapshots := dbTx.Metadata().Bucket(utxoStatsIndexKey).
+		Bucket(utxoStatsSnapshotsBucketName)
+	return snapshots.Put(utxoStatsSnapshotKey(block.Height()),
+		serializeUtxoStats(stats))
+}
+
+// DisconnectBlock is invoked by the index manager when a block has been
+// disconnected from the main chain.  This indexer restores the statistics to
+// their state before the block and removes the snapshot taken at the block.
+//
+// This is part of the Indexer interface.
+func (idx *UtxoStatsIndex) DisconnectBlock(dbTx database.Tx, block *btcutil.Block,
+	stxos []blockchain.SpentTxOut) error {
+
+	if _, err := idx.update(dbTx, block, stxos, -1); err != nil {
+		return err
+	}
+	snapshots := dbTx.Metadata().Bucket(utxoStatsIndexKey).
+		Bucket(utxoStatsSnapshotsBucketName)
+	return snapshots.Delete(utxoStatsSnapshotKey(block.Height()))
+}
+
+// UtxoStats returns the statistics of the unspent outputs at the passed
+// height.  The statistics at the tip of the index are returned when the height
+// is negative or not below the tip.  Otherwise the latest snapshot at or below
+// the height is returned, whose height may therefore be lower than the one
+// requested.  Nil is returned when there is no such snapshot.
+//
+// This function is safe for concurrent access.
+func (idx *UtxoStatsIndex) UtxoStats(height int32) (*UtxoStats, error) {
+	var stats *UtxoStats
+	err := idx.db.View(func(dbTx database.Tx) error {
+		_, tipHeight, err := dbFetchIndexerTip(dbTx, utxoStatsIndexKey)
+		if err != nil {
+			return err
+		}
+		bucket := dbTx.Metadata().Bucket(utxoStatsIndexKey)
+		if height < 0 || height >= tipHeight {
+			stats, err = deserializeUtxoStats(
+				bucket.Get(utxoStatsCurrentKey), tipHeight)
+			return err
+		}
+
+		// Find the latest snapshot at or below the height.
+		key := utxoStatsSnapshotKey(height)
+		cursor := bucket.Bucket(utxoStatsSnapshotsBucketName).Cursor()
+		ok := cursor.Seek(key)
+		switch {
+		case ok && bytes.Equal(cursor.Key(), key):
+		case ok:
+			ok = cursor.Prev()
+		default:
+			ok = cursor.Last()
+		}
+		if !ok {
+			return nil
+		}
+		snapshotHeight := int32(binary.BigEndian.Uint32(cursor.Key()))
+		stats, err = deserializeUtxoStats(cursor.Value(), snapshotHeight)
+		return err
+	})
+	return stats, err
+}
+
+// NewUtxoStatsIndex returns a new instance of an indexer that is used to keep
+// statistics of the unspent outputs by script class, with a snapshot of them
+// every interval blocks.  An interval of zero selects the default.
+//
+// It implements the Indexer interface which plugs into the IndexManager that
+// in turn is used by the blockchain package.  This allows the index to be
+// seamlessly maintained along with the chain.
+func NewUtxoStatsIndex(db database.DB, interval int32) *UtxoStatsIndex {
+	if interval <= 0 {
+		interval = DefaultUtxoStatsInterval
+	}
+	return &UtxoStatsIndex{db: db, interval: interval}
+}
+
+// DropUtxoStatsIndex drops the utxo statistics index from the provided
+// database if it exists.
+func DropUtxoStatsIndex(db database.DB, interrupt <-chan struct{}) error {
+	return dropIndex(db, utxoStatsIndexKey, utxoStatsIndexName, interrupt)
+}
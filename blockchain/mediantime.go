@@ -5,22 +5,35 @@
 package blockchain
 
 import (
-	"math"
 	"sort"
 	"sync"
 	"time"
 )
 
 const (
-	// maxAllowedOffsetSeconds is the maximum number of seconds in either
-	// direction that local clock will be adjusted.  When the median time
-	// of the network is outside of this range, no offset will be applied.
-	maxAllowedOffsetSecs = 70 * 60 // 1 hour 10 minutes
+	// DefaultMaxTimeAdjustment is the default maximum adjustment in either
+	// direction of the local clock.  The applied offset is clamped to this
+	// range when the median time of the network is outside of it.
+	DefaultMaxTimeAdjustment = 70 * time.Minute // 1 hour 10 minutes
 
 	// similarTimeSecs is the number of seconds in either direction from the
 	// local clock that is used to determine that it is likely wrong and
 	// hence to show a warning.
 	similarTimeSecs = 5 * 60 // 5 minutes
+
+	// minTimeSamples is the number of time samples needed before the local
+	// clock is adjusted or deemed to be skewed.
+	minTimeSamples = 5
+
+	// outlierDeviations is the number of median absolute deviations from the
+	// median beyond which a time sample is rejected as an outlier.
+	outlierDeviations = 3
+
+	// minOutlierSecs is the minimum distance in seconds from the median
+	// beyond which a time sample is rejected as an outlier, so a set of
+	// samples which mostly agree doesn't reject samples which are off by a
+	// few seconds.
+	minOutlierSecs = 60
 )
 
 var (
@@ -45,6 +58,36 @@ type MedianTimeSource interface {
 	// Offset returns the number of seconds to adjust the local clock based
 	// upon the median of the time samples added by AddTimeData.
 	Offset() time.Duration
+
+	// Status returns how the local clock compares with the time samples
+	// added by AddTimeSample.
+	Status() MedianTimeStatus
+}
+
+// MedianTimeStatus describes how the local clock compares with the time samples
+// of a MedianTimeSource.
+type MedianTimeStatus struct {
+	// Samples is the number of time samples considered and Outliers the
+	// number of them which were rejected for being too far from the others.
+	Samples  int
+	Outliers int
+
+	// NetworkOffset is the median offset of the samples which are not
+	// outliers, and Offset the adjustment applied to the local clock, which
+	// differs from it when it exceeds MaxOffset.
+	NetworkOffset time.Duration
+	Offset        time.Duration
+	MaxOffset     time.Duration
+
+	// Skewed is set when the local clock deviates significantly from the
+	// network median time.
+	Skewed bool
+}
+
+// Clamped returns whether the network offset exceeds the maximum adjustment of
+// the local clock.
+func (s *MedianTimeStatus) Clamped() bool {
+	return s.Offset != s.NetworkOffset
 }
 
 // int64Sorter implements sort.Interface to allow a slice of 64-bit integers to
@@ -70,16 +113,35 @@ func (s int64Sorter) Less(i, j int) bool {
 	return s[i] < s[j]
 }
 
+// medianInt64 returns the median of the passed sorted offsets, the mean of the
+// two middle values when their number is even.
+func medianInt64(sorted []int64) int64 {
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// absInt64 returns the absolute value of the passed integer.
+func absInt64(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
 // medianTime provides an implementation of the MedianTimeSource interface.
-// It is limited to maxMedianTimeEntries includes the same buggy behavior as
-// the time offset mechanism in Bitcoin Core.  This is necessary because it is
-// used in the consensus code.
+// It keeps the offsets of the latest maxMedianTimeEntries time samples, rejects
+// the ones far from the others and adjusts the local clock by the median of the
+// rest, clamped to the maximum adjustment.
 type medianTime struct {
-	mtx                sync.Mutex
-	knownIDs           map[string]struct{}
-	offsets            []int64
-	offsetSecs         int64
-	invalidTimeChecked bool
+	mtx        sync.Mutex
+	maxOffset  int64
+	knownIDs   map[string]struct{}
+	offsets    []int64
+	offsetSecs int64
+	status     MedianTimeStatus
 }
 
 // Ensure the medianTime type implements the MedianTimeSource interface.
@@ -128,69 +190,71 @@ func (m *medianTime) AddTimeSample(sourceID string, timeVal time.Time) {
 	m.offsets = append(m.offsets, offsetSecs)
 	numOffsets++
 
-	// Sort the offsets so the median can be obtained as needed later.
-	sortedOffsets := make([]int64, numOffsets)
-	copy(sortedOffsets, m.offsets)
-	sort.Sort(int64Sorter(sortedOffsets))
-
 	offsetDuration := time.Duration(offsetSecs) * time.Second
 	log.Debugf("Added time sample of %v (total: %v)", offsetDuration,
 		numOffsets)
 
-	// NOTE: The following code intentionally has a bug to mirror the
-	// buggy behavior in Bitcoin Core since the median time is used in the
-	// consensus rules.
-	//
-	// In particular, the offset is only updated when the number of entries
-	// is odd, but the max number of entries is 200, an even number.  Thus,
-	// the offset will never be updated again once the max number of entries
-	// is reached.
-
-	// The median offset is only updated when there are enough offsets and
-	// the number of offsets is odd so the middle value is the true median.
-	// Thus, there is nothing to do when those conditions are not met.
-	if numOffsets < 5 || numOffsets&0x01 != 1 {
+	m.status.Samples = numOffsets
+	if numOffsets < minTimeSamples {
 		return
 	}
 
-	// At this point the number of offsets in the list is odd, so the
-	// middle value of the sorted offsets is the median.
-	median := sortedOffsets[numOffsets/2]
-
-	// Set the new offset when the median offset is within the allowed
-	// offset range.
-	if math.Abs(float64(median)) < maxAllowedOffsetSecs {
-		m.offsetSecs = median
-	} else {
-		// The median offset of all added time data is larger than the
-		// maximum allowed offset, so don't use an offset.  This
-		// effectively limits how far the local clock can be skewed.
-		m.offsetSecs = 0
-
-		if !m.invalidTimeChecked {
-			m.invalidTimeChecked = true
-
-			// Find if any time samples have a time that is close
-			// to the local time.
-			var remoteHasCloseTime bool
-			for _, offset := range sortedOffsets {
-				if math.Abs(float64(offset)) < similarTimeSecs {
-					remoteHasCloseTime = true
-					break
-				}
-			}
-
-			// Warn if none of the time samples are close.
-			if !remoteHasCloseTime {
-				log.Warnf("Please check your date and time " +
-					"are correct!  pktd will not work " +
-					"properly with an invalid time")
-			}
+	// Sort the offsets so the median can be obtained.
+	sortedOffsets := make([]int64, numOffsets)
+	copy(sortedOffsets, m.offsets)
+	sort.Sort(int64Sorter(sortedOffsets))
+	median := medianInt64(sortedOffsets)
+
+	// Reject the offsets further from the median than a multiple of their
+	// median absolute deviation, so a few peers with wrong clocks, or lying
+	// about their time, can't pull the adjustment.
+	deviations := make([]int64, numOffsets)
+	for i, offset := range sortedOffsets {
+		deviations[i] = absInt64(offset - median)
+	}
+	sort.Sort(int64Sorter(deviations))
+	maxDeviation := outlierDeviations * medianInt64(deviations)
+	if maxDeviation < minOutlierSecs {
+		maxDeviation = minOutlierSecs
+	}
+	inliers := sortedOffsets[:0:0]
+	for _, offset := range sortedOffsets {
+		if absInt64(offset-median) <= maxDeviation {
+			inliers = append(inliers, offset)
 		}
 	}
+	networkOffset := medianInt64(inliers)
+
+	// Clamp the adjustment of the local clock to the allowed range.
+	m.offsetSecs = networkOffset
+	if m.offsetSecs > m.maxOffset {
+		m.offsetSecs = m.maxOffset
+	} else if m.offsetSecs < -m.maxOffset {
+		m.offsetSecs = -m.maxOffset
+	}
+
+	skewed := absInt64(networkOffset) >= similarTimeSecs
+	if skewed && !m.status.Skewed {
+		log.Warnf("Please check your date and time are correct!  The "+
+			"local clock is %v off the median time of %d peers, "+
+			"pktd will not work properly with an invalid time",
+			-time.Duration(networkOffset)*time.Second, len(inliers))
+	} else if !skewed && m.status.Skewed {
+		log.Infof("The local clock is back within %v of the median time "+
+			"of the peers", time.Duration(similarTimeSecs)*time.Second)
+	}
+	m.status = MedianTimeStatus{
+		Samples:       numOffsets,
+		Outliers:      numOffsets - len(inliers),
+		NetworkOffset: time.Duration(networkOffset) * time.Second,
+		Offset:        time.Duration(m.offsetSecs) * time.Second,
+		MaxOffset:     time.Duration(m.maxOffset) * time.Second,
+		Skewed:        skewed,
+	}
 
 	medianDuration := time.Duration(m.offsetSecs) * time.Second
-	log.Debugf("New time offset: %v", medianDuration)
+	log.Debugf("New time offset: %v (%d outliers)", medianDuration,
+		m.status.Outliers)
 }
 
 // Offset returns the number of seconds to adjust the local clock based upon the
@@ -205,14 +269,39 @@ func (m *medianTime) Offset() time.Duration {
 	return time.Duration(m.offsetSecs) * time.Second
 }
 
+// Status returns how the local clock compares with the time samples added by
+// AddTimeSample.
+//
+// This function is safe for concurrent access and is part of the
+// MedianTimeSource interface implementation.
+func (m *medianTime) Status() MedianTimeStatus {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	return m.status
+}
+
 // NewMedianTime returns a new instance of concurrency-safe implementation of
 // the MedianTimeSource interface.  The returned implementation contains the
 // rules necessary for proper time handling in the chain consensus rules and
 // expects the time samples to be added from the timestamp field of the version
 // message received from remote peers that successfully connect and negotiate.
 func NewMedianTime() MedianTimeSource {
+	return NewMedianTimeWithMaxAdjustment(DefaultMaxTimeAdjustment)
+}
+
+// NewMedianTimeWithMaxAdjustment returns a new MedianTimeSource like
+// NewMedianTime which adjusts the local clock by at most the passed duration in
+// either direction.  A maximum adjustment of zero never adjusts the local clock
+// but still detects when it is skewed.
+func NewMedianTimeWithMaxAdjustment(maxAdjustment time.Duration) MedianTimeSource {
+	maxOffset := int64(maxAdjustment / time.Second)
 	return &medianTime{
-		knownIDs: make(map[string]struct{}),
-		offsets:  make([]int64, 0, maxMedianTimeEntries),
+		maxOffset: maxOffset,
+		knownIDs:  make(map[string]struct{}),
+		offsets:   make([]int64, 0, maxMedianTimeEntries),
+		status: MedianTimeStatus{
+			MaxOffset: time.Duration(maxOffset) * time.Second,
+		},
 	}
 }
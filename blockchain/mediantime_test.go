@@ -13,9 +13,12 @@ import (
 // TestMedianTime tests the medianTime implementation.
 func TestMedianTime(t *testing.T) {
 	tests := []struct {
-		in         []int64
-		wantOffset int64
-		useDupID   bool
+		in           []int64
+		noAdjustment bool
+		wantOffset   int64
+		wantOutliers int
+		wantSkewed   bool
+		useDupID     bool
 	}{
 		// Not enough samples must result in an offset of 0.
 		{in: []int64{1}, wantOffset: 0},
@@ -23,33 +26,41 @@ func TestMedianTime(t *testing.T) {
 		{in: []int64{1, 2, 3}, wantOffset: 0},
 		{in: []int64{1, 2, 3, 4}, wantOffset: 0},
 
-		// Various number of entries.  The expected offset is only
-		// updated on odd number of elements.
-		{in: []int64{-13, 57, -4, -23, -12}, wantOffset: -12},
-		{in: []int64{55, -13, 61, -52, 39, 55}, wantOffset: 39},
+		// Various number of entries.  The offset is the median of the
+		// entries which are not outliers, the mean of the two middle
+		// ones when their number is even.
+		{in: []int64{-13, 57, -4, -23, -12}, wantOffset: -12, wantOutliers: 1},
+		{in: []int64{55, -13, 61, -52, 39, 55}, wantOffset: 55, wantOutliers: 1},
 		{in: []int64{-62, -58, -30, -62, 51, -30, 15}, wantOffset: -30},
-		{in: []int64{29, -47, 39, 54, 42, 41, 8, -33}, wantOffset: 39},
+		{in: []int64{29, -47, 39, 54, 42, 41, 8, -33}, wantOffset: 40, wantOutliers: 2},
 		{in: []int64{37, 54, 9, -21, -56, -36, 5, -11, -39}, wantOffset: -11},
-		{in: []int64{57, -28, 25, -39, 9, 63, -16, 19, -60, 25}, wantOffset: 9},
+		{in: []int64{57, -28, 25, -39, 9, 63, -16, 19, -60, 25}, wantOffset: 14},
 		{in: []int64{-5, -4, -3, -2, -1}, wantOffset: -3, useDupID: true},
 
-		// The offset stops being updated once the max number of entries
-		// has been reached.  This is actually a bug from Bitcoin Core,
-		// but since the time is ultimately used as a part of the
-		// consensus rules, it must be mirrored.
-		{in: []int64{-67, 67, -50, 24, 63, 17, 58, -14, 5, -32, -52}, wantOffset: 17},
-		{in: []int64{-67, 67, -50, 24, 63, 17, 58, -14, 5, -32, -52, 45}, wantOffset: 17},
-		{in: []int64{-67, 67, -50, 24, 63, 17, 58, -14, 5, -32, -52, 45, 4}, wantOffset: 17},
+		// The oldest entries are replaced once the max number of
+		// entries has been reached.
+		{in: []int64{-67, 67, -50, 24, 63, 17, 58, -14, 5, -32, -52}, wantOffset: 11},
+		{in: []int64{-67, 67, -50, 24, 63, 17, 58, -14, 5, -32, -52, 45}, wantOffset: 11},
+		{in: []int64{-67, 67, -50, 24, 63, 17, 58, -14, 5, -32, -52, 45, 4}, wantOffset: 11},
 
-		// Offsets that are too far away from the local time should
-		// be ignored.
-		{in: []int64{-4201, 4202, -4203, 4204, -4205}, wantOffset: 0},
+		// A few samples far from the others are ignored.
+		{in: []int64{10, 12, 11, 9, 3600, -7200}, wantOffset: 10, wantOutliers: 2},
+		{in: []int64{-400, -390, -410, -405, -395, 20}, wantOffset: -400,
+			wantOutliers: 1, wantSkewed: true},
 
-		// Exercise the condition where the median offset is greater
-		// than the max allowed adjustment, but there is at least one
-		// sample that is close enough to the current time to avoid
-		// triggering a warning about an invalid local clock.
-		{in: []int64{4201, 4202, 4203, 4204, -299}, wantOffset: 0},
+		// Offsets that are too far away from the local time are
+		// clamped to the max allowed adjustment.
+		{in: []int64{-4201, 4202, -4203, 4204, -4205}, wantOffset: -4200,
+			wantOutliers: 2, wantSkewed: true},
+		{in: []int64{4201, 4202, 4203, 4204, -299}, wantOffset: 4200,
+			wantOutliers: 1, wantSkewed: true},
+		{in: []int64{4800, 4810, 4790, 4805, 4795}, wantOffset: 4200,
+			wantSkewed: true},
+
+		// A max adjustment of zero never adjusts the local clock but
+		// still detects a skewed one.
+		{in: []int64{-400, -390, -410, -405, -395}, noAdjustment: true,
+			wantOffset: 0, wantSkewed: true},
 	}
 
 	// Modify the max number of allowed median time entries for these tests.
@@ -57,7 +68,11 @@ func TestMedianTime(t *testing.T) {
 	defer func() { maxMedianTimeEntries = 200 }()
 
 	for i, test := range tests {
-		filter := NewMedianTime()
+		maxAdjustment := DefaultMaxTimeAdjustment
+		if test.noAdjustment {
+			maxAdjustment = 0
+		}
+		filter := NewMedianTimeWithMaxAdjustment(maxAdjustment)
 		for j, offset := range test.in {
 			id := strconv.Itoa(j)
 			now := time.Unix(time.Now().Unix(), 0)
@@ -87,6 +102,21 @@ func TestMedianTime(t *testing.T) {
 			continue
 		}
 
+		status := filter.Status()
+		wantSamples := len(test.in)
+		if wantSamples > maxMedianTimeEntries {
+			wantSamples = maxMedianTimeEntries
+		}
+		if status.Samples != wantSamples ||
+			status.Outliers != test.wantOutliers ||
+			status.Skewed != test.wantSkewed ||
+			status.Offset != gotOffset ||
+			status.Clamped() != (status.NetworkOffset != gotOffset) {
+
+			t.Errorf("Status #%d: unexpected status %+v", i, status)
+			continue
+		}
+
 		// Since it is possible that the time.Now call in AdjustedTime
 		// and the time.Now call here in the tests will be off by one
 		// second, allow a fudge factor to compensate.
@@ -7,12 +7,16 @@ package blockchain
 
 import (
 	"bytes"
+	"encoding/hex"
 	"fmt"
 	"math"
 	"math/bits"
+	"runtime"
+	"sync"
 
 	"github.com/pkt-cash/btcutil"
 	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/spv"
 	"github.com/pkt-cash/pktd/txscript"
 )
 
@@ -27,6 +31,11 @@ const (
 	// commitment itself. In order to be a valid candidate for the output
 	// containing the witness commitment
 	CoinbaseWitnessPkScriptLength = 38
+
+	// minHashesPerWorker is the minimum number of hashes given to each
+	// goroutine when the hashing of a merkle tree is spread across the
+	// CPUs.  Below it, the overhead of the goroutines outweighs the gain.
+	minHashesPerWorker = 256
 )
 
 var (
@@ -61,13 +70,7 @@ func nextPowerOfTwo(n int) int {
 // nodes, and returns the hash of their concatenation.  This is a helper
 // function used to aid in the generation of a merkle tree.
 func HashMerkleBranches(left *chainhash.Hash, right *chainhash.Hash) *chainhash.Hash {
-	// Concatenate the left and right nodes.
-	var hash [chainhash.HashSize * 2]byte
-	copy(hash[:chainhash.HashSize], left[:])
-	copy(hash[chainhash.HashSize:], right[:])
-
-	newHash := chainhash.DoubleHashH(hash[:])
-	return &newHash
+	return spv.HashMerkleBranches(left, right)
 }
 
 // BuildMerkleTreeStore creates a merkle tree from a slice of transactions,
@@ -103,6 +106,8 @@ func HashMerkleBranches(left *chainhash.Hash, right *chainhash.Hash) *chainhash.
 // using witness transaction id's rather than regular transaction id's. This
 // also presents an additional case wherein the wtxid of the coinbase transaction
 // is the zeroHash.
+//
+// The hashing of large trees is spread across the CPUs.
 func BuildMerkleTreeStore(transactions []*btcutil.Tx, witness bool) []*chainhash.Hash {
 	// Calculate how many entries are required to hold the binary merkle
 	// tree as a linear array and create an array of that size.
@@ -111,51 +116,99 @@ func BuildMerkleTreeStore(transactions []*btcutil.Tx, witness bool) []*chainhash
 	merkles := make([]*chainhash.Hash, arraySize)
 
 	// Create the base transaction hashes and populate the array with them.
-	for i, tx := range transactions {
-		// If we're computing a witness merkle root, instead of the
-		// regular txid, we use the modified wtxid which includes a
-		// transaction's witness data within the digest. Additionally,
-		// the coinbase's wtxid is all zeroes.
-		switch {
-		case witness && i == 0:
-			var zeroHash chainhash.Hash
-			merkles[i] = &zeroHash
-		case witness:
-			wSha := tx.MsgTx().WitnessHash()
-			merkles[i] = &wSha
-		default:
-			merkles[i] = tx.Hash()
+	parallelizeHashing(len(transactions), func(start, end int) {
+		for i := start; i < end; i++ {
+			merkles[i] = merkleLeaf(transactions[i], i, witness)
 		}
+	})
 
-	}
-
-	// Start the array offset after the last transaction and adjusted to the
-	// next power of two.
+	// Hash each level of the tree from the bottom up, starting the array
+	// offset of the parents after the last transaction and adjusted to the
+	// next power of two.  The parents of a level only depend on the level
+	// below, so they can be hashed concurrently.
 	offset := nextPoT
-	for i := 0; i < arraySize-1; i += 2 {
-		switch {
-		// When there is no left child node, the parent is nil too.
-		case merkles[i] == nil:
-			merkles[offset] = nil
-
-		// When there is no right child, the parent is generated by
-		// hashing the concatenation of the left child with itself.
-		case merkles[i+1] == nil:
-			newHash := HashMerkleBranches(merkles[i], merkles[i])
-			merkles[offset] = newHash
-
-		// The normal case sets the parent node to the double sha256
-		// of the concatentation of the left and right children.
-		default:
-			newHash := HashMerkleBranches(merkles[i], merkles[i+1])
-			merkles[offset] = newHash
-		}
-		offset++
+	for levelSize := nextPoT; levelSize > 1; levelSize /= 2 {
+		level := merkles[offset-levelSize : offset]
+		parents := merkles[offset : offset+levelSize/2]
+		parallelizeHashing(len(parents), func(start, end int) {
+			for i := start; i < end; i++ {
+				parents[i] = hashMerkleNode(level[i*2], level[i*2+1])
+			}
+		})
+		offset += levelSize / 2
 	}
 
 	return merkles
 }
 
+// merkleLeaf returns the leaf of the merkle tree for the transaction at index i
+// of a block.  If we're computing a witness merkle root, instead of the regular
+// txid, we use the modified wtxid which includes a transaction's witness data
+// within the digest.  Additionally, the coinbase's wtxid is all zeroes.
+func merkleLeaf(tx *btcutil.Tx, i int, witness bool) *chainhash.Hash {
+	switch {
+	case witness && i == 0:
+		var zeroHash chainhash.Hash
+		return &zeroHash
+
+	// The wtxid of a transaction without witness data is its txid, which
+	// is cached.
+	case witness && tx.HasWitness():
+		wSha := tx.MsgTx().WitnessHash()
+		return &wSha
+	default:
+		return tx.Hash()
+	}
+}
+
+// hashMerkleNode returns the parent of the passed children in a merkle tree.
+func hashMerkleNode(left, right *chainhash.Hash) *chainhash.Hash {
+	switch {
+	// When there is no left child node, the parent is nil too.
+	case left == nil:
+		return nil
+
+	// When there is no right child, the parent is generated by hashing the
+	// concatenation of the left child with itself.
+	case right == nil:
+		return HashMerkleBranches(left, left)
+
+	// The normal case sets the parent node to the double sha256 of the
+	// concatentation of the left and right children.
+	default:
+		return HashMerkleBranches(left, right)
+	}
+}
+
+// parallelizeHashing calls hashRange over consecutive ranges covering [0, n),
+// spreading them across the CPUs when there are enough hashes for the
+// goroutines to pay off.  hashRange must only touch the items of its range.
+func parallelizeHashing(n int, hashRange func(start, end int)) {
+	numWorkers := runtime.NumCPU()
+	if maxWorkers := n / minHashesPerWorker; maxWorkers < numWorkers {
+		numWorkers = maxWorkers
+	}
+	if numWorkers <= 1 {
+		hashRange(0, n)
+		return
+	}
+
+	var wg sync.WaitGroup
+	chunkSize := (n + numWorkers - 1) / numWorkers
+	for start := 0; start < n; start += chunkSize {
+		end := start + chunkSize
+		if end > n {
+			end = n
+		}
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			hashRange(start, end)
+		}(start, end)
+	}
+	wg.Wait()
+}
+
 // GetMerkleBranch takes a transaction index and a merkle store and outputs a
 // hash branch needed to prove a particular transaction.
 func GetMerkleBranch(txIndex int, tree []*chainhash.Hash) []*chainhash.Hash {
@@ -278,7 +331,9 @@ func ValidateWitnessCommitment(blk *btcutil.Block) error {
 		str := fmt.Sprintf("witness commitment does not match: "+
 			"computed %v, coinbase includes %v", computedCommitment,
 			witnessCommitment)
-		return ruleError(ErrWitnessCommitmentMismatch, str)
+		return valueRuleError(ErrWitnessCommitmentMismatch, str,
+			hex.EncodeToString(computedCommitment),
+			hex.EncodeToString(witnessCommitment))
 	}
 
 	return nil
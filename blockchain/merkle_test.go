@@ -8,6 +8,8 @@ import (
 	"testing"
 
 	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/wire"
 )
 
 // TestMerkle tests the BuildMerkleTreeStore API.
@@ -21,3 +23,89 @@ func TestMerkle(t *testing.T) {
 			"got %v, want %v", calculatedMerkleRoot, wantMerkle)
 	}
 }
+
+// merkleTestTxns returns numTxns distinct transactions, every third one with
+// witness data.
+func merkleTestTxns(numTxns int) []*btcutil.Tx {
+	txns := make([]*btcutil.Tx, 0, numTxns)
+	for i := 0; i < numTxns; i++ {
+		msgTx := wire.NewMsgTx(wire.TxVersion)
+		msgTx.AddTxIn(&wire.TxIn{
+			PreviousOutPoint: wire.OutPoint{Index: uint32(i)},
+		})
+		if i%3 == 1 {
+			msgTx.TxIn[0].Witness = wire.TxWitness{{byte(i)}}
+		}
+		msgTx.AddTxOut(wire.NewTxOut(int64(i), nil))
+		txns = append(txns, btcutil.NewTx(msgTx))
+	}
+	return txns
+}
+
+// TestMerkleTreeBuilder ensures the merkle tree builder computes the same roots
+// as BuildMerkleTreeStore as transactions are added and replaced.
+func TestMerkleTreeBuilder(t *testing.T) {
+	txns := merkleTestTxns(1100)
+	for _, witness := range []bool{false, true} {
+		builder := NewMerkleTreeBuilder(witness)
+		if root := builder.Root(); *root != (chainhash.Hash{}) {
+			t.Fatalf("empty tree has root %v", root)
+		}
+		for i, tx := range txns {
+			builder.AddTx(tx)
+			if builder.Len() != i+1 {
+				t.Fatalf("got %d leaves, want %d", builder.Len(), i+1)
+			}
+			if i > 70 && i%100 != 0 && i != len(txns)-1 {
+				continue
+			}
+			merkles := BuildMerkleTreeStore(txns[:i+1], witness)
+			want := merkles[len(merkles)-1]
+			if root := builder.Root(); !root.IsEqual(want) {
+				t.Fatalf("witness %v, %d transactions: got root "+
+					"%v, want %v", witness, i+1, root, want)
+			}
+		}
+
+		// Replacing a leaf changes the root until it is restored.
+		before := *builder.Root()
+		builder.Set(0, txns[1].Hash())
+		if got := *builder.Root(); got == before {
+			t.Fatalf("witness %v: replacing the coinbase left the "+
+				"root at %v", witness, got)
+		}
+		builder.Set(0, merkleLeaf(txns[0], 0, witness))
+		if got := *builder.Root(); got != before {
+			t.Fatalf("witness %v: got root %v after restoring the "+
+				"coinbase, want %v", witness, got, before)
+		}
+	}
+}
+
+// TestParallelizeHashing ensures the hashing ranges cover every item once.
+func TestParallelizeHashing(t *testing.T) {
+	for _, n := range []int{0, 1, minHashesPerWorker - 1,
+		minHashesPerWorker*2 + 1, minHashesPerWorker*64 + 7} {
+
+		visits := make([]int, n)
+		parallelizeHashing(n, func(start, end int) {
+			for i := start; i < end; i++ {
+				visits[i]++
+			}
+		})
+		for i, v := range visits {
+			if v != 1 {
+				t.Fatalf("n %d: item %d visited %d times", n, i, v)
+			}
+		}
+	}
+}
+
+// BenchmarkBuildMerkleTreeStore benchmarks the merkle tree of a large block.
+func BenchmarkBuildMerkleTreeStore(b *testing.B) {
+	txns := merkleTestTxns(4000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		BuildMerkleTreeStore(txns, true)
+	}
+}
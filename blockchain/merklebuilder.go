@@ -0,0 +1,94 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+)
+
+// MerkleTreeBuilder incrementally computes the merkle root of the transactions
+// of a block as they are added, the way a block template is put together.
+// Adding or replacing a transaction only hashes the nodes between its leaf and
+// the root rather than the entire tree, so the root is always at hand.
+//
+// It keeps every level of the tree, from the leaves up to the root, without the
+// padding of BuildMerkleTreeStore.  The last node of a level with an odd number
+// of nodes is paired with itself.
+//
+// A MerkleTreeBuilder is not safe for concurrent access.
+type MerkleTreeBuilder struct {
+	witness bool
+	levels  [][]chainhash.Hash
+}
+
+// NewMerkleTreeBuilder returns an empty merkle tree builder.  The witness flag
+// indicates if the tree commits to the witness transaction ids, in which case
+// the leaf of the coinbase transaction is the zeroHash, as in
+// BuildMerkleTreeStore.
+func NewMerkleTreeBuilder(witness bool) *MerkleTreeBuilder {
+	return &MerkleTreeBuilder{
+		witness: witness,
+		levels:  make([][]chainhash.Hash, 1),
+	}
+}
+
+// Len returns the number of leaves of the tree.
+func (m *MerkleTreeBuilder) Len() int {
+	return len(m.levels[0])
+}
+
+// Add appends the passed leaf to the tree.
+func (m *MerkleTreeBuilder) Add(leaf *chainhash.Hash) {
+	m.levels[0] = append(m.levels[0], *leaf)
+	m.update(len(m.levels[0]) - 1)
+}
+
+// AddTx appends the leaf of the passed transaction to the tree.  The first
+// transaction added is the coinbase.
+func (m *MerkleTreeBuilder) AddTx(tx *btcutil.Tx) {
+	m.Add(merkleLeaf(tx, m.Len(), m.witness))
+}
+
+// Set replaces the leaf at index i of the tree with the passed one.
+func (m *MerkleTreeBuilder) Set(i int, leaf *chainhash.Hash) {
+	m.levels[0][i] = *leaf
+	m.update(i)
+}
+
+// Root returns the merkle root of the tree, or the zeroHash when the tree is
+// empty.
+func (m *MerkleTreeBuilder) Root() *chainhash.Hash {
+	top := m.levels[len(m.levels)-1]
+	if len(top) == 0 {
+		return &chainhash.Hash{}
+	}
+	root := top[0]
+	return &root
+}
+
+// update hashes again the nodes between the leaf at index i and the root,
+// growing the tree by one level when it no longer fits.
+func (m *MerkleTreeBuilder) update(i int) {
+	for height := 0; len(m.levels[height]) > 1; height++ {
+		if height+1 == len(m.levels) {
+			m.levels = append(m.levels, nil)
+		}
+		level := m.levels[height]
+		left := &level[i&^1]
+		right := left
+		if i|1 < len(level) {
+			right = &level[i|1]
+		}
+		parent := HashMerkleBranches(left, right)
+
+		i /= 2
+		if i == len(m.levels[height+1]) {
+			m.levels[height+1] = append(m.levels[height+1], *parent)
+		} else {
+			m.levels[height+1][i] = *parent
+		}
+	}
+}
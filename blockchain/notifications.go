@@ -53,9 +53,17 @@ func (n NotificationType) String() string {
 // 	- NTBlockAccepted:     *btcutil.Block
 // 	- NTBlockConnected:    *btcutil.Block
 // 	- NTBlockDisconnected: *btcutil.Block
+//
+// The undo data of a disconnected block is also passed with the
+// NTBlockDisconnected notification, since it is no longer available from the
+// chain once the block is disconnected.
 type Notification struct {
 	Type NotificationType
 	Data interface{}
+
+	// Undo is the undo data of the block for NTBlockDisconnected, and nil
+	// otherwise.
+	Undo *BlockUndo
 }
 
 // Subscribe to block chain notifications. Registers a callback to be executed
@@ -71,11 +79,15 @@ func (b *BlockChain) Subscribe(callback NotificationCallback) {
 // caller requested notifications by providing a callback function in the call
 // to New.
 func (b *BlockChain) sendNotification(typ NotificationType, data interface{}) {
-	// Generate and send the notification.
-	n := Notification{Type: typ, Data: data}
+	b.notify(&Notification{Type: typ, Data: data})
+}
+
+// notify sends the passed notification to the callbacks registered with
+// Subscribe.
+func (b *BlockChain) notify(n *Notification) {
 	b.notificationsLock.RLock()
 	for _, callback := range b.notifications {
-		callback(&n)
+		callback(n)
 	}
 	b.notificationsLock.RUnlock()
 }
@@ -10,8 +10,6 @@ import (
 	"errors"
 	"fmt"
 
-	"github.com/pkt-cash/pktd/blockchain/packetcrypt/randhash/util"
-
 	"github.com/pkt-cash/pktd/blockchain/packetcrypt/announce"
 	"github.com/pkt-cash/pktd/blockchain/packetcrypt/block/proof"
 
@@ -32,22 +30,20 @@ func isWorkOk(ccState *cryptocycle.State, cb *wire.PcCoinbaseCommit, target uint
 	return difficulty.IsOk(ccState.Bytes[:32], effectiveTarget)
 }
 
-func isPcHashOk(
+// runCryptoCycle runs the hash cycle of the passed PacketCrypt proof over the
+// block header and the announcements, storing the item numbers selecting the
+// announcements in indexesOut, and returns the final state.
+func runCryptoCycle(
 	indexesOut *[4]uint64,
 	blockHeader *wire.BlockHeader,
 	proof *wire.PacketCryptProof,
-	cb *wire.PcCoinbaseCommit,
-	shareTarget uint32,
 	contentProofs [][]byte,
-) (bool, bool) {
+) *cryptocycle.State {
 	ccState := new(cryptocycle.State)
 
-	buf := bytes.NewBuffer(make([]byte, 0, wire.MaxBlockHeaderPayload))
-	if blockHeader.Serialize(buf) != nil {
-		panic("failed to serialize block header")
-	}
+	var buf [wire.MaxBlockHeaderPayload]byte
 	var hdrHash [32]byte
-	pcutil.HashCompress(hdrHash[:], buf.Bytes())
+	pcutil.HashCompress(hdrHash[:], blockHeader.AppendTo(buf[:0]))
 
 	cryptocycle.Init(ccState, hdrHash[:], uint64(proof.Nonce))
 	for j := 0; j < 4; j++ {
@@ -65,7 +61,31 @@ func isPcHashOk(
 	}
 	cryptocycle.Smul(ccState)
 	cryptocycle.Final(ccState)
+	return ccState
+}
 
+// AnnIndexes returns the item numbers selecting the announcements of the passed
+// PacketCrypt proof, which give their positions in the announcement tree
+// committed to by the coinbase once reduced modulo the announcement count.
+func AnnIndexes(
+	blockHeader *wire.BlockHeader,
+	proof *wire.PacketCryptProof,
+	contentProofs [][]byte,
+) [4]uint64 {
+	var indexes [4]uint64
+	runCryptoCycle(&indexes, blockHeader, proof, contentProofs)
+	return indexes
+}
+
+func isPcHashOk(
+	indexesOut *[4]uint64,
+	blockHeader *wire.BlockHeader,
+	proof *wire.PacketCryptProof,
+	cb *wire.PcCoinbaseCommit,
+	shareTarget uint32,
+	contentProofs [][]byte,
+) (bool, bool) {
+	ccState := runCryptoCycle(indexesOut, blockHeader, proof, contentProofs)
 	if isWorkOk(ccState, cb, blockHeader.Bits) {
 		return true, true
 	}
@@ -110,13 +130,8 @@ func ValidatePcProof(
 		if _, err := announce.CheckAnn(ann, blockHashes[i]); err != nil {
 			return false, err
 		}
-		effectiveAnnTarget := uint32(0xffffffff)
-		if blockHeight < util.Conf_PacketCrypt_ANN_WAIT_PERIOD {
-			effectiveAnnTarget = ann.GetWorkTarget()
-		} else {
-			age := uint32(blockHeight) - ann.GetParentBlockHeight()
-			effectiveAnnTarget = difficulty.GetAgedAnnTarget(ann.GetWorkTarget(), age)
-		}
+		effectiveAnnTarget := difficulty.GetBlockAnnTarget(ann.GetWorkTarget(),
+			ann.GetParentBlockHeight(), blockHeight)
 		if effectiveAnnTarget > cb.AnnMinDifficulty() {
 			return false, errors.New("Validate_checkBlock_ANN_INSUF_POW")
 		}
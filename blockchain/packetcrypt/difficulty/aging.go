@@ -0,0 +1,48 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package difficulty
+
+import (
+	"math"
+	"math/big"
+
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/randhash/util"
+)
+
+// MaxAgedAnnTarget is the highest (least work) aged target of an announcement
+// which can still be mined in a block.  GetAgedAnnTarget returns 0xffffffff
+// past it.
+const MaxAgedAnnTarget = 0x207fffff
+
+// AnnWaitPeriod is the age in blocks at which an announcement can first be
+// mined in a block, with its target unaged.
+const AnnWaitPeriod = util.Conf_PacketCrypt_ANN_WAIT_PERIOD
+
+// MaxAnnAge returns the greatest age in blocks at which an announcement mined
+// at target has an aged target of at most maxTarget, as computed by
+// GetAgedAnnTarget.  It returns false when the announcement never meets
+// maxTarget.  Passing MaxAgedAnnTarget returns the last age at which the
+// announcement can be mined at all.
+func MaxAnnAge(target, maxTarget uint32) (uint32, bool) {
+	if GetAgedAnnTarget(target, AnnWaitPeriod) > maxTarget {
+		return 0, false
+	}
+
+	// The aged work is the work of the target divided by the age past the
+	// wait period, so the age is estimated from the ratio of the works and
+	// then adjusted for the rounding of the compact targets.
+	ratio := new(big.Int).Div(Work(target), Work(maxTarget))
+	age := uint64(math.MaxUint32)
+	if ratio.IsUint64() && ratio.Uint64() < math.MaxUint32-AnnWaitPeriod {
+		age = ratio.Uint64() + AnnWaitPeriod
+	}
+	for age > AnnWaitPeriod && GetAgedAnnTarget(target, uint32(age)) > maxTarget {
+		age--
+	}
+	for age < math.MaxUint32 && GetAgedAnnTarget(target, uint32(age+1)) <= maxTarget {
+		age++
+	}
+	return uint32(age), true
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package difficulty
+
+import "testing"
+
+// TestMaxAnnAge ensures MaxAnnAge returns the last age at which the aged
+// target of an announcement meets the maximum target.
+func TestMaxAnnAge(t *testing.T) {
+	tests := []struct {
+		target    uint32
+		maxTarget uint32
+	}{
+		{0x2000ffff, 0x2000ffff},
+		{0x1f00ffff, 0x2000ffff},
+		{0x1f00ffff, MaxAgedAnnTarget},
+		{0x2000ffff, 0x20007fff},
+		{0x2007ffff, 0x2000ffff},
+	}
+	for _, test := range tests {
+		age, ok := MaxAnnAge(test.target, test.maxTarget)
+		if !ok {
+			if GetAgedAnnTarget(test.target, AnnWaitPeriod) <= test.maxTarget {
+				t.Errorf("MaxAnnAge(%08x, %08x): unexpected failure",
+					test.target, test.maxTarget)
+			}
+			continue
+		}
+		if age < AnnWaitPeriod ||
+			GetAgedAnnTarget(test.target, age) > test.maxTarget ||
+			GetAgedAnnTarget(test.target, age+1) <= test.maxTarget {
+
+			t.Errorf("MaxAnnAge(%08x, %08x): got %d", test.target,
+				test.maxTarget, age)
+		}
+	}
+
+	// An announcement with less work than the maximum never meets it.
+	if _, ok := MaxAnnAge(0x2007ffff, 0x2000ffff); ok {
+		t.Error("MaxAnnAge: unexpected success for an easier target")
+	}
+
+	// Announcements of the same work are only accepted unaged, or at the
+	// age dividing their work by one.
+	if age, _ := MaxAnnAge(0x2000ffff, 0x2000ffff); age != AnnWaitPeriod+1 {
+		t.Errorf("MaxAnnAge: got %d, want %d", age, AnnWaitPeriod+1)
+	}
+}
@@ -114,6 +114,18 @@ func GetAgedAnnTarget(target, annAgeBlocks uint32) uint32 {
 	return out
 }
 
+// GetBlockAnnTarget returns the aged target of an announcement with the passed
+// work target and parent block height when it is mined in a block at the passed
+// height.  The aging only applies once the chain is longer than the waiting
+// period, the announcements of the first blocks being valued at their work
+// target.
+func GetBlockAnnTarget(target, parentBlockHeight uint32, blockHeight int32) uint32 {
+	if blockHeight < util.Conf_PacketCrypt_ANN_WAIT_PERIOD {
+		return target
+	}
+	return GetAgedAnnTarget(target, uint32(blockHeight)-parentBlockHeight)
+}
+
 // IsAnnMinDiffOk is kind of a sanity check to make sure that the miner doesn't provide
 // "silly" results which might trigger wrong behavior from the diff computation
 func IsAnnMinDiffOk(target uint32) bool {
@@ -0,0 +1,127 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package difficulty
+
+import (
+	"math/big"
+	"time"
+)
+
+// Work returns the expected number of hashes needed to find a hash below the
+// compact target.
+func Work(target uint32) *big.Int {
+	return workForTarget(CompactToBig(target))
+}
+
+// AnnouncementWork returns the work of the announcements a block was mined
+// with: annCount announcements, each valued at the work of minAnnTarget, the
+// least work of any of them.
+func AnnouncementWork(minAnnTarget uint32, annCount uint64) *big.Int {
+	work := Work(minAnnTarget)
+	return work.Mul(work, new(big.Int).SetUint64(annCount))
+}
+
+// EffectiveWork returns the work of mining a block with the header target
+// blockTarget with annCount announcements of at least the work of
+// minAnnTarget.  More announcements make the effective target easier to beat.
+func EffectiveWork(blockTarget, minAnnTarget uint32, annCount uint64) *big.Int {
+	return Work(GetEffectiveTarget(blockTarget, minAnnTarget, annCount))
+}
+
+// BlockWork is the PacketCrypt work of a block, from its header target and
+// the announcement commitment of its coinbase.  AnnCount is zero for blocks
+// without a valid commitment, whose work is the work of their header target.
+type BlockWork struct {
+	Timestamp    time.Time
+	Target       uint32
+	AnnMinTarget uint32
+	AnnCount     uint64
+}
+
+// EffectiveTarget returns the target the block was mined at given its
+// announcements.
+func (b *BlockWork) EffectiveTarget() uint32 {
+	if b.AnnCount == 0 {
+		return b.Target
+	}
+	return GetEffectiveTarget(b.Target, b.AnnMinTarget, b.AnnCount)
+}
+
+// Effective returns the work of mining the block.
+func (b *BlockWork) Effective() *big.Int {
+	return Work(b.EffectiveTarget())
+}
+
+// Announcements returns the work of the announcements the block was mined
+// with.
+func (b *BlockWork) Announcements() *big.Int {
+	if b.AnnCount == 0 {
+		return new(big.Int)
+	}
+	return AnnouncementWork(b.AnnMinTarget, b.AnnCount)
+}
+
+// Hashrate is an estimate of the hash rates of the network over a window of
+// blocks.
+type Hashrate struct {
+	// Seconds is the time between the earliest and the latest timestamps
+	// of the blocks.
+	Seconds int64
+
+	// BlockWork and AnnWork are the total block mining and announcement
+	// work of the blocks.
+	BlockWork *big.Int
+	AnnWork   *big.Int
+
+	// AnnCount is the total number of announcements of the blocks.
+	AnnCount uint64
+
+	// BlockRate and AnnRate are the block mining and announcement
+	// hashes per second.  They are zero when the blocks span no time.
+	BlockRate float64
+	AnnRate   float64
+}
+
+// EstimateHashrate estimates the hash rates of the network from the work of
+// consecutive blocks.  The work of the first block is not counted as it was
+// done before its timestamp, where the window starts.
+func EstimateHashrate(blocks []BlockWork) *Hashrate {
+	h := &Hashrate{
+		BlockWork: new(big.Int),
+		AnnWork:   new(big.Int),
+	}
+	if len(blocks) == 0 {
+		return h
+	}
+	minTime := blocks[0].Timestamp
+	maxTime := minTime
+	for i := 1; i < len(blocks); i++ {
+		b := &blocks[i]
+		h.BlockWork.Add(h.BlockWork, b.Effective())
+		h.AnnWork.Add(h.AnnWork, b.Announcements())
+		h.AnnCount += b.AnnCount
+		if b.Timestamp.Before(minTime) {
+			minTime = b.Timestamp
+		}
+		if b.Timestamp.After(maxTime) {
+			maxTime = b.Timestamp
+		}
+	}
+	h.Seconds = int64(maxTime.Sub(minTime) / time.Second)
+	if h.Seconds > 0 {
+		h.BlockRate = rate(h.BlockWork, h.Seconds)
+		h.AnnRate = rate(h.AnnWork, h.Seconds)
+	}
+	return h
+}
+
+// rate returns work divided by seconds as a float, as the work may not fit in
+// 64 bits.
+func rate(work *big.Int, seconds int64) float64 {
+	r := new(big.Float).SetInt(work)
+	r.Quo(r, new(big.Float).SetInt64(seconds))
+	f, _ := r.Float64()
+	return f
+}
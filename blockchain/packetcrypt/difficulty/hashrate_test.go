@@ -0,0 +1,64 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package difficulty
+
+import (
+	"math/big"
+	"testing"
+	"time"
+)
+
+// TestEstimateHashrate ensures the block and announcement work of blocks is
+// valued from their targets and announcements, and divided by the time they
+// span.
+func TestEstimateHashrate(t *testing.T) {
+	const (
+		blockTarget  = 0x1d00ffff
+		minAnnTarget = 0x2000ffff
+	)
+	start := time.Unix(1600000000, 0)
+
+	// 2^256 / (0xffff * 2^224 + 1), rounded down.
+	if work := Work(0x1f00ffff); work.Cmp(big.NewInt(0x10001)) != 0 {
+		t.Fatalf("Work: got %v, want %v", work, 0x10001)
+	}
+
+	// More announcements make blocks easier to mine.
+	few := EffectiveWork(blockTarget, minAnnTarget, 10)
+	many := EffectiveWork(blockTarget, minAnnTarget, 1000)
+	if many.Cmp(few) >= 0 {
+		t.Fatalf("effective work %v with more announcements is not "+
+			"below %v", many, few)
+	}
+
+	blocks := []BlockWork{
+		{Timestamp: start, Target: blockTarget},
+		{Timestamp: start.Add(60 * time.Second), Target: blockTarget,
+			AnnMinTarget: minAnnTarget, AnnCount: 1000},
+		{Timestamp: start.Add(120 * time.Second), Target: blockTarget},
+	}
+	h := EstimateHashrate(blocks)
+
+	wantBlockWork := new(big.Int).Add(many, Work(blockTarget))
+	wantAnnWork := new(big.Int).Mul(Work(minAnnTarget), big.NewInt(1000))
+	if h.Seconds != 120 || h.AnnCount != 1000 ||
+		h.BlockWork.Cmp(wantBlockWork) != 0 ||
+		h.AnnWork.Cmp(wantAnnWork) != 0 {
+
+		t.Fatalf("unexpected estimate %+v", h)
+	}
+	wantRate, _ := new(big.Float).Quo(new(big.Float).SetInt(wantAnnWork),
+		big.NewFloat(120)).Float64()
+	if h.AnnRate != wantRate || h.BlockRate <= 0 {
+		t.Fatalf("unexpected rates %v and %v", h.BlockRate, h.AnnRate)
+	}
+
+	// Blocks spanning no time have no rate.
+	if h := EstimateHashrate(blocks[:1]); h.BlockRate != 0 ||
+		h.BlockWork.Sign() != 0 {
+
+		t.Fatalf("unexpected estimate %+v", h)
+	}
+}
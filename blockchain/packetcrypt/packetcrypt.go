@@ -12,15 +12,28 @@ import (
 	"fmt"
 	"io"
 
-	"github.com/dchest/blake2b"
 	"github.com/pkt-cash/pktd/blockchain/packetcrypt/announce"
 	"github.com/pkt-cash/pktd/blockchain/packetcrypt/block"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/difficulty"
 	"github.com/pkt-cash/pktd/blockchain/packetcrypt/pcutil"
 	"github.com/pkt-cash/pktd/chaincfg/chainhash"
 	"github.com/pkt-cash/pktd/wire"
 	"golang.org/x/crypto/ed25519"
 )
 
+// AnnouncementError is returned by ValidatePcBlock when one of the
+// announcements of the PacketCrypt proof is invalid.  Index is the index of
+// the announcement within the proof.
+type AnnouncementError struct {
+	Index int
+	Err   error
+}
+
+// Error returns the error of the announcement.
+func (e AnnouncementError) Error() string {
+	return e.Err.Error()
+}
+
 func ValidatePcAnn(p *wire.PacketCryptAnn, parentBlockHash *chainhash.Hash) (*chainhash.Hash, error) {
 	return announce.CheckAnn(p, parentBlockHash)
 }
@@ -53,10 +66,7 @@ func checkContentProof(ann *wire.PacketCryptAnn, proofIdx uint32, cpb io.Reader)
 		}
 		blockToProve >>= 1
 		blockSize <<= 1
-		b2 := blake2b.New256()
-		b2.Write(buf[:])
-		x := b2.Sum(nil)
-		copy(hash[:], x)
+		pcutil.HashCompress(hash[:], buf[:])
 	}
 	if bytes.Compare(hash[:], ann.GetContentHash()) != 0 {
 		return errors.New("announcement content proof hash mismatch")
@@ -65,12 +75,26 @@ func checkContentProof(ann *wire.PacketCryptAnn, proofIdx uint32, cpb io.Reader)
 }
 
 func contentProofIdx2(mb *wire.MsgBlock) uint32 {
-	b2 := blake2b.New256()
+	b2 := pcutil.NewHashCompress()
 	mb.Header.Serialize(b2)
 	buf := b2.Sum(nil)
 	return binary.LittleEndian.Uint32(buf) ^ mb.Pcp.Nonce
 }
 
+// AnnIndexes returns the numbers selecting the announcements of the
+// PacketCrypt proof of the passed block, which give their positions in the
+// announcement tree committed to by its coinbase.
+func AnnIndexes(mb *wire.MsgBlock) ([4]uint64, error) {
+	if mb.Pcp == nil {
+		return [4]uint64{}, errors.New("missing packetcrypt proof")
+	}
+	contentProofs, err := mb.Pcp.SplitContentProof(contentProofIdx2(mb))
+	if err != nil {
+		return [4]uint64{}, err
+	}
+	return block.AnnIndexes(&mb.Header, mb.Pcp, contentProofs), nil
+}
+
 func ValidatePcBlock(mb *wire.MsgBlock, height int32, shareTarget uint32, annParentHashes []*chainhash.Hash) (bool, error) {
 	if len(annParentHashes) != 4 {
 		return false, errors.New("wrong number of annParentHashes")
@@ -83,10 +107,10 @@ func ValidatePcBlock(mb *wire.MsgBlock, height int32, shareTarget uint32, annPar
 	for i, ann := range mb.Pcp.Announcements {
 		if !ann.HasSigningKey() {
 		} else if mb.Pcp.Signatures[i] == nil {
-			return false, fmt.Errorf("missing announcement signature for key [%s]",
-				hex.EncodeToString(ann.GetSigningKey()))
+			return false, AnnouncementError{i, fmt.Errorf("missing announcement signature for key [%s]",
+				hex.EncodeToString(ann.GetSigningKey()))}
 		} else if !ed25519.Verify(ann.GetSigningKey(), ann.Header[:], mb.Pcp.Signatures[i]) {
-			return false, errors.New("invalid announcement signature")
+			return false, AnnouncementError{i, errors.New("invalid announcement signature")}
 		}
 	}
 
@@ -101,11 +125,11 @@ func ValidatePcBlock(mb *wire.MsgBlock, height int32, shareTarget uint32, annPar
 			continue
 		}
 		if contentProofs[i] == nil {
-			return false, errors.New("missing announcement content proof")
+			return false, AnnouncementError{i, errors.New("missing announcement content proof")}
 		}
 		contentBuf := bytes.NewBuffer(contentProofs[i])
 		if err := checkContentProof(&ann, proofIdx, contentBuf); err != nil {
-			return false, err
+			return false, AnnouncementError{i, err}
 		}
 	}
 
@@ -134,6 +158,28 @@ func ExtractCoinbaseCommit(coinbaseTx *wire.MsgTx) *wire.PcCoinbaseCommit {
 	return nil
 }
 
+// ExtractBlockWork returns the PacketCrypt work of a block from its header
+// target and the announcement commitment of its coinbase.  Blocks without a
+// valid commitment are counted without announcements.
+func ExtractBlockWork(mb *wire.MsgBlock) difficulty.BlockWork {
+	work := difficulty.BlockWork{
+		Timestamp: mb.Header.Timestamp,
+		Target:    mb.Header.Bits,
+	}
+	if len(mb.Transactions) == 0 {
+		return work
+	}
+	cbc := ExtractCoinbaseCommit(mb.Transactions[0])
+	if cbc == nil || cbc.Magic() != wire.PcCoinbaseCommitMagic ||
+		!difficulty.IsAnnMinDiffOk(cbc.AnnMinDifficulty()) {
+
+		return work
+	}
+	work.AnnMinTarget = cbc.AnnMinDifficulty()
+	work.AnnCount = cbc.AnnCount()
+	return work
+}
+
 func InsertCoinbaseCommit(coinbaseTx *wire.MsgTx, cbc *wire.PcCoinbaseCommit) {
 	buf := make([]byte, len(cbc.Bytes)+2)
 	buf[0] = 0x6a
@@ -0,0 +1,62 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package pcutil
+
+import (
+	"runtime"
+
+	"golang.org/x/sys/cpu"
+)
+
+// cpuFeature is an instruction set extension and whether the CPU supports it.
+type cpuFeature struct {
+	has  bool
+	name string
+}
+
+// HashAcceleration returns the assembly implementations the hash functions use
+// on this CPU, as detected at runtime, each one named after the hash function
+// and the instruction set extension it relies on, such as "blake2b:avx2".
+// pcutil has no assembly of its own, these are the choices made by the
+// packages behind it: golang.org/x/crypto/blake2b behind HashCompress on
+// amd64, github.com/aead/chacha20 behind HashExpand on amd64 and 386, and
+// crypto/sha256 on arm64.  The hash functions which are not listed use generic
+// Go code.
+func HashAcceleration() []string {
+	var names []string
+	add := func(hash string, features []cpuFeature) {
+		for _, f := range features {
+			if f.has {
+				names = append(names, hash+":"+f.name)
+				return
+			}
+		}
+	}
+
+	switch runtime.GOARCH {
+	case "amd64":
+		add("blake2b", []cpuFeature{
+			{cpu.X86.HasAVX2, "avx2"},
+			{cpu.X86.HasAVX, "avx"},
+			{cpu.X86.HasSSE41, "sse4.1"},
+		})
+		add("chacha20", []cpuFeature{
+			{cpu.X86.HasAVX2, "avx2"},
+			{cpu.X86.HasAVX, "avx"},
+			{cpu.X86.HasSSSE3, "ssse3"},
+			{cpu.X86.HasSSE2, "sse2"},
+		})
+	case "386":
+		add("chacha20", []cpuFeature{
+			{cpu.X86.HasSSSE3, "ssse3"},
+			{cpu.X86.HasSSE2, "sse2"},
+		})
+	case "arm64":
+		add("sha256", []cpuFeature{
+			{cpu.ARM64.HasSHA2, "sha2"},
+		})
+	}
+	return names
+}
@@ -6,9 +6,10 @@ package pcutil
 
 import (
 	"encoding/binary"
+	"hash"
 
 	"github.com/aead/chacha20"
-	"github.com/dchest/blake2b"
+	"golang.org/x/crypto/blake2b"
 )
 
 func HashExpand(out, key []byte, counter uint32) {
@@ -28,24 +29,25 @@ func HashCompress(out, in []byte) {
 	if len(out) < 32 {
 		panic("need 32 byte output to place hash in")
 	}
-	b2 := blake2b.New256()
-	_, err := b2.Write(in)
-	if err != nil {
-		panic("failed b2.Write()")
-	}
-	// blake2 wants to *append* the hash
-	b2.Sum(out[:0])
+	sum := blake2b.Sum256(in)
+	copy(out, sum[:])
 }
 
 func HashCompress64(out, in []byte) {
 	if len(out) < 64 {
 		panic("need 64 byte output to place hash in")
 	}
-	b2 := blake2b.New512()
-	_, err := b2.Write(in)
+	sum := blake2b.Sum512(in)
+	copy(out, sum[:])
+}
+
+// NewHashCompress returns a hash.Hash computing the same digest as
+// HashCompress, for data which is written in parts.
+func NewHashCompress() hash.Hash {
+	b2, err := blake2b.New256(nil)
 	if err != nil {
-		panic("failed b2.Write()")
+		// Only keys longer than 64 bytes are rejected.
+		panic("failed blake2b.New256()")
 	}
-	// blake2 wants to *append* the hash
-	b2.Sum(out[:0])
+	return b2
 }
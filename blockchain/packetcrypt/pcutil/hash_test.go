@@ -0,0 +1,98 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package pcutil
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// TestHashCompress ensures the hash functions return the blake2b digests of
+// their input whether it is hashed at once or written in parts.
+func TestHashCompress(t *testing.T) {
+	long := make([]byte, 1280)
+	for i := range long {
+		long[i] = byte(i)
+	}
+	tests := []struct {
+		in     []byte
+		want   string
+		want64 string
+	}{
+		{
+			in:   []byte("abc"),
+			want: "bddd813c634239723171ef3fee98579b94964e3bb1cb3e427262c8c068d52319",
+			want64: "ba80a53f981c4d0d6a2797b69f12f6e94c212f14685ac4b74b12b" +
+				"b6fdbffa2d17d87c5392aab792dc252d5de4533cc9518d38aa8dbf19" +
+				"25ab92386edd4009923",
+		},
+		{
+			in:   long,
+			want: "82628cbfc9689e234b0923a531f4578fe2e7138a03e2f81ed6cde97517336650",
+		},
+	}
+	for _, test := range tests {
+		var out [64]byte
+		HashCompress(out[:], test.in)
+		if got := hex.EncodeToString(out[:32]); got != test.want {
+			t.Errorf("HashCompress: got %s, want %s", got, test.want)
+		}
+
+		b2 := NewHashCompress()
+		b2.Write(test.in[:len(test.in)/2])
+		b2.Write(test.in[len(test.in)/2:])
+		if got := hex.EncodeToString(b2.Sum(nil)); got != test.want {
+			t.Errorf("NewHashCompress: got %s, want %s", got,
+				test.want)
+		}
+
+		if test.want64 == "" {
+			continue
+		}
+		HashCompress64(out[:], test.in)
+		if got := hex.EncodeToString(out[:]); got != test.want64 {
+			t.Errorf("HashCompress64: got %s, want %s", got,
+				test.want64)
+		}
+	}
+}
+
+// BenchmarkHashCompress benchmarks hashing the two children of a node of a
+// PacketCrypt merkle tree.
+func BenchmarkHashCompress(b *testing.B) {
+	var in [64]byte
+	var out [32]byte
+	b.SetBytes(int64(len(in)))
+	for i := 0; i < b.N; i++ {
+		HashCompress(out[:], in[:])
+	}
+}
+
+// BenchmarkHashExpand benchmarks expanding a key into an announcement item.
+func BenchmarkHashExpand(b *testing.B) {
+	var key [32]byte
+	var out [1024]byte
+	b.SetBytes(int64(len(out)))
+	for i := 0; i < b.N; i++ {
+		HashExpand(out[:], key[:], uint32(i))
+	}
+}
+
+// TestHashAcceleration ensures each hash function is reported at most once,
+// along with the instruction set extension its implementation uses.
+func TestHashAcceleration(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, name := range HashAcceleration() {
+		parts := strings.Split(name, ":")
+		if len(parts) != 2 || parts[1] == "" {
+			t.Fatalf("malformed hash acceleration %q", name)
+		}
+		if seen[parts[0]] {
+			t.Fatalf("hash function %s reported twice", parts[0])
+		}
+		seen[parts[0]] = true
+	}
+}
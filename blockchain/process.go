@@ -138,13 +138,44 @@ func (b *BlockChain) processOrphans(hash *chainhash.Hash, flags BehaviorFlags) e
 //
 // When no errors occurred during processing, the first return value indicates
 // whether or not the block is on the main chain and the second indicates
-// whether or not the block is an orphan.
+// whether or not the block is an orphan.  ErrSafeMode is returned without
+// checking the block while the chain is in safe mode.
 //
 // This function is safe for concurrent access.
 func (b *BlockChain) ProcessBlock(block *btcutil.Block, flags BehaviorFlags) (bool, bool, error) {
 	b.chainLock.Lock()
 	defer b.chainLock.Unlock()
 
+	if b.SafeMode() {
+		return false, false, ErrSafeMode
+	}
+
+	b.updateBulkLoad()
+	if b.blockRecorder == nil {
+		return b.processBlock(block, flags)
+	}
+
+	// Capture the adjusted time before processing the block so replaying
+	// the block at the recorded time yields the same outcome.
+	adjustedTime := b.timeSource.AdjustedTime()
+	isMainChain, isOrphan, err := b.processBlock(block, flags)
+	b.blockRecorder.RecordBlock(&ProcessedBlock{
+		Block:        block,
+		Flags:        flags,
+		AdjustedTime: adjustedTime,
+		IsMainChain:  isMainChain,
+		IsOrphan:     isOrphan,
+		Err:          err,
+		Best:         b.BestSnapshot(),
+	})
+	return isMainChain, isOrphan, err
+}
+
+// processBlock handles insertion of a new block into the block chain.  See
+// ProcessBlock.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) processBlock(block *btcutil.Block, flags BehaviorFlags) (bool, bool, error) {
 	fastAdd := flags&BFFastAdd == BFFastAdd
 
 	blockHash := block.Hash()
@@ -176,9 +207,14 @@ func (b *BlockChain) ProcessBlock(block *btcutil.Block, flags BehaviorFlags) (bo
 
 	if globalcfg.GetProofOfWorkAlgorithm() != globalcfg.PowPacketCrypt {
 	} else if flags&BFNoPoWCheck == BFNoPoWCheck {
-	} else if err = b.pcCheckProofOfWork(block); err != nil {
-		prevHashExists, _ := b.blockExists(&blockHeader.PrevBlock)
-		return false, !prevHashExists, err
+	} else {
+		start := time.Now()
+		err = b.pcCheckProofOfWork(block)
+		b.validationStats.add(&b.validationStats.stats.Proofs, start)
+		if err != nil {
+			prevHashExists, _ := b.blockExists(&blockHeader.PrevBlock)
+			return false, !prevHashExists, err
+		}
 	}
 
 	// Find the previous checkpoint and perform some additional checks based
@@ -229,6 +265,7 @@ func (b *BlockChain) ProcessBlock(block *btcutil.Block, flags BehaviorFlags) (bo
 	if !prevHashExists {
 		log.Infof("Adding orphan block %v with parent %v", blockHash, prevHash)
 		b.addOrphanBlock(block)
+		b.addStaleOrphan(block)
 
 		return false, true, nil
 	}
@@ -0,0 +1,43 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"time"
+
+	"github.com/pkt-cash/btcutil"
+)
+
+// ProcessedBlock describes a block passed to ProcessBlock along with the
+// inputs it was processed with and the outcome.
+type ProcessedBlock struct {
+	// Block is the processed block.
+	Block *btcutil.Block
+
+	// Flags are the behavior flags the block was processed with.
+	Flags BehaviorFlags
+
+	// AdjustedTime is the adjusted time of the time source when the block
+	// was processed.
+	AdjustedTime time.Time
+
+	// IsMainChain, IsOrphan and Err are the values returned by
+	// ProcessBlock.
+	IsMainChain bool
+	IsOrphan    bool
+	Err         error
+
+	// Best is the best chain state once the block was processed.
+	Best *BestState
+}
+
+// BlockRecorder is the interface notified of every block passed to
+// ProcessBlock.
+type BlockRecorder interface {
+	// RecordBlock is invoked once a block has been processed.  It is
+	// called with the chain lock held, so it must not call back into the
+	// chain other than through BestSnapshot.
+	RecordBlock(block *ProcessedBlock)
+}
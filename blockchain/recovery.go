@@ -0,0 +1,512 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/database"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+const (
+	// mainChainCheckDepth is the number of blocks up to the best block
+	// whose entries in the main chain height index are checked at startup.
+	mainChainCheckDepth = 288
+
+	// maxRecoveredNodes is the maximum number of blocks missing from the
+	// block index below the best block which are restored at startup.
+	maxRecoveredNodes = 2016
+)
+
+// RecoveryReport describes the repairs made at startup to bring the chain
+// state back to a consistent best block after an interrupted write.
+type RecoveryReport struct {
+	// OldTip and OldHeight are the best block the stored chain state
+	// pointed to, and NewTip and NewHeight the best block once repaired.
+	OldTip    chainhash.Hash
+	OldHeight int32
+	NewTip    chainhash.Hash
+	NewHeight int32
+
+	// RolledBack is the number of blocks disconnected from the best chain
+	// and RolledForward the number of stored blocks connected to it.
+	RolledBack    int
+	RolledForward int
+
+	// Repairs describes each repair, in the order they were made.
+	Repairs []string
+}
+
+// chainRecovery holds the state of the recovery of the chain state at
+// startup.
+type chainRecovery struct {
+	report RecoveryReport
+
+	// badElectionState is set when the election state of the stored best
+	// block could not be loaded, so the block must be rolled back.
+	badElectionState bool
+}
+
+// repaired logs a repair of the chain state and adds it to the report.
+func (b *BlockChain) repaired(format string, args ...interface{}) {
+	repair := fmt.Sprintf(format, args...)
+	log.Warnf("Repaired the chain state: %s", repair)
+	b.recovery.report.Repairs = append(b.recovery.report.Repairs, repair)
+}
+
+// unrecoverableError returns the error reported when the chain state is
+// inconsistent in a way which can't be repaired automatically.
+func unrecoverableError(node *blockNode, what string, err error) error {
+	return fmt.Errorf("%s of block %v (height %d): %v -- the chain state "+
+		"can't be recovered automatically, remove the block database to "+
+		"resync", what, node.hash, node.height, err)
+}
+
+// readOnlyError returns the error reported when the chain state of a database
+// open read-only needs the passed repair.
+func readOnlyError(format string, args ...interface{}) error {
+	return fmt.Errorf("%s -- the chain state can't be repaired in a "+
+		"read-only database, open it writable once to repair it",
+		fmt.Sprintf(format, args...))
+}
+
+// RecoveryReport returns the repairs made to the chain state at startup, or
+// nil when it was consistent.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) RecoveryReport() *RecoveryReport {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	if len(b.recovery.report.Repairs) == 0 {
+		return nil
+	}
+	report := b.recovery.report
+	report.Repairs = append([]string(nil), report.Repairs...)
+	return &report
+}
+
+// recoverIndexNodes restores the nodes of the block index missing up to the
+// passed best block from the headers of the stored blocks.  This happens when
+// the chain state was written but the block index was not.
+//
+// This function MUST be called while loading the block index.
+func (b *BlockChain) recoverIndexNodes(dbTx database.Tx, tipHash *chainhash.Hash) (*blockNode, error) {
+	var headers []*wire.BlockHeader
+	hash := tipHash
+	for b.index.LookupNode(hash) == nil {
+		if len(headers) >= maxRecoveredNodes {
+			return nil, fmt.Errorf("more than %d blocks below the "+
+				"best block %v are missing from the block index -- "+
+				"the chain state can't be recovered automatically, "+
+				"remove the block database to resync",
+				maxRecoveredNodes, tipHash)
+		}
+		blockBytes, err := dbTx.FetchBlock(hash)
+		if err != nil {
+			return nil, fmt.Errorf("block %v is missing from the "+
+				"block index and its data can't be loaded: %v -- "+
+				"the chain state can't be recovered automatically, "+
+				"remove the block database to resync", hash, err)
+		}
+		var header wire.BlockHeader
+		err = header.Deserialize(bytes.NewReader(blockBytes))
+		if err != nil {
+			return nil, err
+		}
+		headers = append(headers, &header)
+		hash = &header.PrevBlock
+	}
+
+	if b.readOnly {
+		return nil, readOnlyError("%d blocks up to the best block %v "+
+			"are missing from the block index", len(headers), tipHash)
+	}
+
+	// The blocks were connected to the best chain, so they are valid.
+	node := b.index.LookupNode(hash)
+	for i := len(headers) - 1; i >= 0; i-- {
+		node = newBlockNode(headers[i], node)
+		node.status = statusDataStored | statusValid
+		b.index.AddNode(node)
+	}
+	b.repaired("restored %d blocks missing from the block index up to "+
+		"the best block %v (height %d)", len(headers), node.hash,
+		node.height)
+	return node, nil
+}
+
+// utxoHasBlock returns whether the outputs created by the coinbase of the
+// passed block are in the utxo set.  Since a coinbase can't be spent before it
+// matures, this tells whether the utxo set includes a block near the best one.
+// The second value is false when the block created no output the utxo set
+// would hold, as for the genesis block.
+func (b *BlockChain) utxoHasBlock(node *blockNode) (bool, bool, error) {
+	if node.parent == nil {
+		return false, false, nil
+	}
+
+	var has, known bool
+	err := b.db.View(func(dbTx database.Tx) error {
+		block, err := dbFetchBlockByNode(dbTx, node)
+		if err != nil {
+			return unrecoverableError(node, "unable to load the data",
+				err)
+		}
+		coinbase := block.Transactions()[0]
+		prevOut := wire.OutPoint{Hash: *coinbase.Hash()}
+		for i, txOut := range coinbase.MsgTx().TxOut {
+			if txscript.IsUnspendable(txOut.PkScript) {
+				continue
+			}
+			prevOut.Index = uint32(i)
+			entry, err := dbFetchUtxoEntry(dbTx, prevOut)
+			if err != nil {
+				return err
+			}
+			has, known = entry != nil, true
+			return nil
+		}
+		return nil
+	})
+	return has, known, err
+}
+
+// undoBlock returns a view which removes the outputs created by the passed
+// block from the utxo set and restores the ones it spent, using its undo data
+// in the spend journal.
+func (b *BlockChain) undoBlock(node *blockNode) (*UtxoViewpoint, error) {
+	var stxos []SpentTxOut
+	var block *btcutil.Block
+	err := b.db.View(func(dbTx database.Tx) error {
+		var err error
+		block, err = dbFetchBlockByNode(dbTx, node)
+		if err != nil {
+			return err
+		}
+		stxos, err = dbFetchSpendJournalEntry(dbTx, block)
+		return err
+	})
+	if err != nil {
+		return nil, unrecoverableError(node, "unable to load the undo data",
+			err)
+	}
+
+	view := NewUtxoViewpoint()
+	view.SetBestHash(&node.hash)
+	if err := view.fetchInputUtxos(b.db, block); err != nil {
+		return nil, err
+	}
+	if err := view.disconnectTransactions(b.db, block, stxos); err != nil {
+		return nil, unrecoverableError(node, "unable to apply the undo data",
+			err)
+	}
+	return view, nil
+}
+
+// rollBackTip moves the best chain back to the parent of the best block.  The
+// outputs of the best block are removed from the utxo set using its undo data
+// when undo is set, otherwise the utxo set is expected to match the parent
+// already.  The spend journal entry of the block is kept, so the optional
+// indexes can still roll it back.
+func (b *BlockChain) rollBackTip(undo bool) error {
+	tip := b.bestChain.Tip()
+	parent := tip.parent
+	if parent == nil {
+		return unrecoverableError(tip, "unable to roll back",
+			fmt.Errorf("the genesis block can't be rolled back"))
+	}
+
+	var view *UtxoViewpoint
+	if undo {
+		var err error
+		view, err = b.undoBlock(tip)
+		if err != nil {
+			return err
+		}
+	}
+
+	var state *BestState
+	err := b.db.View(func(dbTx database.Tx) error {
+		block, err := dbFetchBlockByNode(dbTx, parent)
+		if err != nil {
+			return err
+		}
+		es, err := dbFetchElectionStateByNode(dbTx, parent)
+		if err != nil {
+			return err
+		}
+		state = newBestState(parent, uint64(block.MsgBlock().SerializeSize()),
+			uint64(GetBlockWeight(block)),
+			uint64(len(block.MsgBlock().Transactions)),
+			b.stateSnapshot.TotalTxns-b.stateSnapshot.NumTxns,
+			parent.CalcPastMedianTime(), es)
+		return nil
+	})
+	if err != nil {
+		return unrecoverableError(parent, "unable to load the chain state",
+			err)
+	}
+
+	err = b.db.Update(func(dbTx database.Tx) error {
+		err := dbPutBestState(dbTx, state, parent.workSum)
+		if err != nil {
+			return err
+		}
+		err = dbRemoveBlockIndex(dbTx, &tip.hash, tip.height)
+		if err != nil {
+			return err
+		}
+		if view != nil {
+			return dbPutUtxoView(dbTx, view)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	b.bestChain.SetTip(parent)
+	b.stateLock.Lock()
+	b.stateSnapshot = state
+	b.stateLock.Unlock()
+	b.recovery.report.RolledBack++
+	return nil
+}
+
+// utxoDescendants returns the chain of stored blocks after the best block
+// whose outputs are in the utxo set, which happens when the utxo set was
+// written ahead of the chain state.
+func (b *BlockChain) utxoDescendants() ([]*blockNode, error) {
+	var chain []*blockNode
+	for n := b.bestChain.Tip(); ; {
+		var children []*blockNode
+		b.index.RLock()
+		for _, node := range b.index.index {
+			if node.parent == n && node.status.HaveData() {
+				children = append(children, node)
+			}
+		}
+		b.index.RUnlock()
+
+		var next *blockNode
+		for _, child := range children {
+			has, _, err := b.utxoHasBlock(child)
+			if err != nil {
+				return nil, err
+			}
+			if has {
+				next = child
+				break
+			}
+		}
+		if next == nil {
+			return chain, nil
+		}
+		chain = append(chain, next)
+		n = next
+	}
+}
+
+// repairMainChainIndex makes the main chain height index match the best chain
+// for the blocks near the best block, and removes the entries after it.
+func (b *BlockChain) repairMainChainIndex() error {
+	tip := b.bestChain.Tip()
+	var fixed, removed int
+	err := b.db.Update(func(dbTx database.Tx) error {
+		for n := tip; n != nil && tip.height-n.height < mainChainCheckDepth; n = n.parent {
+			hash, err := dbFetchHashByHeight(dbTx, n.height)
+			if err == nil && *hash == n.hash {
+				continue
+			}
+			if b.readOnly {
+				return readOnlyError("the main chain index does "+
+					"not match the best chain at height %d",
+					n.height)
+			}
+			if err == nil {
+				err := dbRemoveBlockIndex(dbTx, hash, n.height)
+				if err != nil {
+					return err
+				}
+			}
+			if err := dbPutBlockIndex(dbTx, &n.hash, n.height); err != nil {
+				return err
+			}
+			fixed++
+		}
+		for height := tip.height + 1; ; height++ {
+			hash, err := dbFetchHashByHeight(dbTx, height)
+			if err != nil {
+				return nil
+			}
+			if b.readOnly {
+				return readOnlyError("the main chain index has " +
+					"entries after the best block")
+			}
+			if err := dbRemoveBlockIndex(dbTx, hash, height); err != nil {
+				return err
+			}
+			removed++
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if fixed > 0 {
+		b.repaired("fixed %d entries of the main chain index below the "+
+			"best block", fixed)
+	}
+	if removed > 0 {
+		b.repaired("removed %d entries of the main chain index after "+
+			"the best block", removed)
+	}
+	return nil
+}
+
+// recoverChainState checks the stored best block is consistent with the utxo
+// set and the main chain index, rolling the best chain back and the utxo set
+// back using the undo data as needed.  The blocks which were rolled back are
+// connected again by rollForward once the chain is initialized.
+//
+// This function MUST be called once the chain state is loaded.
+func (b *BlockChain) recoverChainState() error {
+	tip := b.bestChain.Tip()
+	report := &b.recovery.report
+	report.OldTip, report.OldHeight = tip.hash, tip.height
+
+	has, known, err := b.utxoHasBlock(tip)
+	if err != nil {
+		return err
+	}
+	switch {
+	case known && !has:
+		// The utxo set was not written with the best block, so it
+		// must match its parent.
+		parentHas, parentKnown, err := b.utxoHasBlock(tip.parent)
+		if err != nil {
+			return err
+		}
+		if parentKnown && !parentHas {
+			return unrecoverableError(tip, "unable to match the utxo set",
+				fmt.Errorf("it contains neither the block nor its "+
+					"parent"))
+		}
+		if b.readOnly {
+			return readOnlyError("the utxo set does not contain the "+
+				"best block %v (height %d)", tip.hash, tip.height)
+		}
+		if err := b.rollBackTip(false); err != nil {
+			return err
+		}
+		b.repaired("rolled back the best block %v (height %d) which "+
+			"the utxo set does not contain", tip.hash, tip.height)
+
+	case b.recovery.badElectionState:
+		if b.readOnly {
+			return readOnlyError("the election state of the best "+
+				"block %v (height %d) is missing", tip.hash,
+				tip.height)
+		}
+		if err := b.rollBackTip(known); err != nil {
+			return err
+		}
+		b.repaired("rolled back the best block %v (height %d) whose "+
+			"election state is missing", tip.hash, tip.height)
+	}
+
+	// Undo the blocks after the best block which the utxo set contains,
+	// the last one first.
+	descendants, err := b.utxoDescendants()
+	if err != nil {
+		return err
+	}
+	if len(descendants) > 0 && b.readOnly {
+		return readOnlyError("the utxo set contains the outputs of %d "+
+			"blocks after the best block", len(descendants))
+	}
+	for i := len(descendants) - 1; i >= 0; i-- {
+		n := descendants[i]
+		view, err := b.undoBlock(n)
+		if err != nil {
+			return err
+		}
+		err = b.db.Update(func(dbTx database.Tx) error {
+			return dbPutUtxoView(dbTx, view)
+		})
+		if err != nil {
+			return err
+		}
+		b.repaired("removed the outputs of block %v (height %d) after "+
+			"the best block from the utxo set", n.hash, n.height)
+	}
+
+	return b.repairMainChainIndex()
+}
+
+// rollForward connects the stored blocks with more work than the best block,
+// which are left when a block was stored but the chain state was not updated
+// before the node stopped.  Blocks which turn out to be invalid are marked as
+// such and left out of the best chain.
+//
+// This function MUST be called once the chain is initialized.
+func (b *BlockChain) rollForward() error {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	tip := b.bestChain.Tip()
+	var best *blockNode
+	b.index.RLock()
+	for _, n := range b.index.index {
+		if !n.status.HaveData() || n.status.KnownInvalid() ||
+			n.workSum.Cmp(tip.workSum) <= 0 {
+
+			continue
+		}
+		if best == nil || n.workSum.Cmp(best.workSum) > 0 {
+			best = n
+		}
+	}
+	b.index.RUnlock()
+	if best == nil {
+		return nil
+	}
+	if b.readOnly {
+		return readOnlyError("the stored block %v (height %d) has more "+
+			"work than the best block but is not part of the best "+
+			"chain", best.hash, best.height)
+	}
+
+	detachNodes, attachNodes := b.getReorganizeNodes(best)
+	if attachNodes.Len() == 0 {
+		return b.index.flushToDB()
+	}
+	err := b.reorganizeChain(detachNodes, attachNodes)
+	if flushErr := b.index.flushToDB(); err == nil {
+		err = flushErr
+	}
+	if _, ok := err.(RuleError); ok {
+		log.Warnf("Unable to connect the stored block %v (height %d) "+
+			"with more work than the best block: %v", best.hash,
+			best.height, err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	newTip := b.bestChain.Tip()
+	b.recovery.report.RolledBack += detachNodes.Len()
+	b.recovery.report.RolledForward += attachNodes.Len()
+	b.repaired("connected %d stored blocks up to %v (height %d) which "+
+		"were not part of the best chain", attachNodes.Len(), newTip.hash,
+		newTip.height)
+	return nil
+}
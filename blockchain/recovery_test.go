@@ -0,0 +1,117 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/chaincfg/globalcfg"
+	"github.com/pkt-cash/pktd/database"
+	"github.com/pkt-cash/pktd/txscript"
+)
+
+// TestRecoverChainState ensures inconsistencies left by interrupted writes are
+// repaired and reported when the chain is loaded, and that the ones which
+// can't be repaired are reported with a clear error.
+func TestRecoverChainState(t *testing.T) {
+	params := &chaincfg.MainNetParams
+	if !globalcfg.SelectConfig(params.GlobalConf) {
+		t.Fatal("globalcfg.SelectConfig() called twice")
+	}
+	defer globalcfg.RemoveConfig()
+
+	chain, teardownFunc, err := chainSetup("recoverchainstate", params)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+
+	reopenWith := func(readOnly bool) (*BlockChain, error) {
+		return New(&Config{
+			DB:          chain.db,
+			ChainParams: chain.chainParams,
+			TimeSource:  NewMedianTime(),
+			SigCache:    txscript.NewSigCache(1000),
+			ReadOnly:    readOnly,
+		})
+	}
+	reopen := func() (*BlockChain, error) {
+		return reopenWith(false)
+	}
+	if report := chain.RecoveryReport(); report != nil {
+		t.Fatalf("unexpected repairs of a new chain: %+v", report)
+	}
+
+	// Leave a main chain index entry after the best block and flag the
+	// best block as invalid.
+	genesis := chain.bestChain.Tip()
+	err = chain.db.Update(func(dbTx database.Tx) error {
+		return dbPutBlockIndex(dbTx, &chainhash.Hash{1}, 1)
+	})
+	if err != nil {
+		t.Fatalf("dbPutBlockIndex: %v", err)
+	}
+	chain.index.SetStatusFlags(genesis, statusValidateFailed)
+	if err := chain.index.flushToDB(); err != nil {
+		t.Fatalf("flushToDB: %v", err)
+	}
+
+	// A read-only chain reports the repairs it needs instead of making
+	// them.
+	_, err = reopenWith(true)
+	if err == nil || !strings.Contains(err.Error(), "read-only database") {
+		t.Fatalf("New read-only: unexpected error %v", err)
+	}
+
+	repaired, err := reopen()
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	report := repaired.RecoveryReport()
+	if report == nil || len(report.Repairs) != 2 ||
+		report.NewTip != genesis.hash || report.RolledBack != 0 {
+
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if repaired.index.NodeStatus(repaired.bestChain.Tip()).KnownInvalid() {
+		t.Fatalf("best block still flagged as invalid")
+	}
+	err = chain.db.View(func(dbTx database.Tx) error {
+		_, err := dbFetchHashByHeight(dbTx, 1)
+		return err
+	})
+	if err == nil {
+		t.Fatalf("main chain index entry after the best block not removed")
+	}
+
+	// Once repaired, the chain loads without repairs.
+	again, err := reopen()
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if report := again.RecoveryReport(); report != nil {
+		t.Fatalf("unexpected repairs once repaired: %+v", report)
+	}
+	if _, err := reopenWith(true); err != nil {
+		t.Fatalf("New read-only once repaired: %v", err)
+	}
+
+	// The genesis block can't be rolled back when its election state is
+	// missing.
+	err = chain.db.Update(func(dbTx database.Tx) error {
+		bucket := dbTx.Metadata().Bucket(electionStateBucketName)
+		return bucket.Delete(genesis.hash[:])
+	})
+	if err != nil {
+		t.Fatalf("unable to delete the election state: %v", err)
+	}
+	_, err = reopen()
+	if err == nil || !strings.Contains(err.Error(), "remove the block database") {
+		t.Fatalf("New: unexpected error %v", err)
+	}
+}
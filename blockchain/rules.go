@@ -0,0 +1,188 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/difficulty"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/globalcfg"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// SubsidyRules describes the block subsidy at a height.
+type SubsidyRules struct {
+	// Epoch is the number of times the subsidy changed before the height:
+	// the number of halvings, or the payout period on chains with a
+	// network steward.
+	Epoch int32
+
+	// NextEpochHeight is the height at which the subsidy next changes, or
+	// -1 when it never does.
+	NextEpochHeight int32
+
+	// Subsidy is the new money created by the block at the height.
+	Subsidy int64
+
+	// NetworkStewardPayout is the part of the subsidy which is paid to the
+	// network steward, zero on chains without one.
+	NetworkStewardPayout int64
+}
+
+// PacketCryptRules describes the PacketCrypt rules at a height.
+type PacketCryptRules struct {
+	// Enabled is whether blocks must carry a PacketCrypt proof.
+	Enabled bool
+
+	// AnnAging is whether the targets of the announcements of a block are
+	// aged by the number of blocks since their parent block.  Blocks
+	// below the announcement wait period have no announcements old
+	// enough to age.
+	AnnAging bool
+
+	// AnnWaitPeriod is the age in blocks at which an announcement can
+	// first be mined in a block.
+	AnnWaitPeriod int32
+}
+
+// Rules describes the consensus rules in effect for the block at a height of
+// the main chain, which are otherwise spread across the validation code as
+// comparisons with activation heights and deployment states.
+type Rules struct {
+	// Height is the height of the block the rules apply to.
+	Height int32
+
+	// MinBlockVersion is the lowest block version accepted at the height,
+	// as raised by BIP0034, BIP0066 and BIP0065.
+	MinBlockVersion int32
+
+	// SerializedHeight is whether the coinbase must start with the height
+	// of the block.  This is part of BIP0034.
+	SerializedHeight bool
+
+	// ScriptFlags are the script flags enforced for the transactions of
+	// every block which is valid at the height.  Blocks with a version
+	// above MinBlockVersion may have more flags enforced before the
+	// activation heights of BIP0066 and BIP0065.
+	ScriptFlags txscript.ScriptFlags
+
+	// Deployments is the state of each version bits deployment for the
+	// block, indexed by deployment ID.
+	Deployments [chaincfg.DefinedDeployments]ThresholdState
+
+	// Subsidy describes the block subsidy.
+	Subsidy SubsidyRules
+
+	// PacketCrypt describes the PacketCrypt proof of work rules.
+	PacketCrypt PacketCryptRules
+}
+
+// minBlockVersion returns the lowest version of a block at the passed height
+// once a majority of the network has upgraded.  These were originally voted on
+// by BIP0034, BIP0065, and BIP0066.
+func minBlockVersion(height int32, params *chaincfg.Params) int32 {
+	version := int32(1)
+	if height >= params.BIP0034Height && version < 2 {
+		version = 2
+	}
+	if height >= params.BIP0066Height && version < 3 {
+		version = 3
+	}
+	if height >= params.BIP0065Height && version < 4 {
+		version = 4
+	}
+	return version
+}
+
+// CalcSubsidyRules returns the block subsidy rules at the passed height.
+func CalcSubsidyRules(height int32, params *chaincfg.Params) SubsidyRules {
+	rules := SubsidyRules{
+		NextEpochHeight: -1,
+		Subsidy:         CalcBlockSubsidy(height, params),
+	}
+	interval := params.SubsidyReductionInterval
+	if params.GlobalConf.HasNetworkSteward {
+		interval = pktBlocksPerPeriod
+		rules.NetworkStewardPayout = PktCalcNetworkStewardPayout(rules.Subsidy)
+	}
+	if interval > 0 {
+		rules.Epoch = height / interval
+		rules.NextEpochHeight = (rules.Epoch + 1) * interval
+	}
+	return rules
+}
+
+// CalcPacketCryptRules returns the PacketCrypt rules at the passed height.
+func CalcPacketCryptRules(height int32, params *chaincfg.Params) PacketCryptRules {
+	return PacketCryptRules{
+		Enabled: params.GlobalConf.ProofOfWorkAlgorithm ==
+			globalcfg.PowPacketCrypt,
+		AnnAging:      height >= difficulty.AnnWaitPeriod,
+		AnnWaitPeriod: difficulty.AnnWaitPeriod,
+	}
+}
+
+// rules returns the consensus rules for the block after prevNode, or for the
+// genesis block when prevNode is nil.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) rules(prevNode *blockNode) (*Rules, error) {
+	params := b.chainParams
+	var height int32
+	header := params.GenesisBlock.Header
+	if prevNode != nil {
+		// A block is timestamped after the median time of the blocks
+		// before it.
+		height = prevNode.height + 1
+		header = wire.BlockHeader{
+			Version:   minBlockVersion(height, params),
+			Timestamp: prevNode.CalcPastMedianTime().Add(time.Second),
+		}
+	}
+
+	rules := &Rules{
+		Height:           height,
+		MinBlockVersion:  minBlockVersion(height, params),
+		SerializedHeight: height >= params.BIP0034Height,
+		Subsidy:          CalcSubsidyRules(height, params),
+		PacketCrypt:      CalcPacketCryptRules(height, params),
+	}
+	for id := range rules.Deployments {
+		state, err := b.deploymentState(prevNode, uint32(id))
+		if err != nil {
+			return nil, err
+		}
+		rules.Deployments[id] = state
+	}
+	flags, err := b.consensusScriptFlags(prevNode, &header)
+	if err != nil {
+		return nil, err
+	}
+	rules.ScriptFlags = flags
+	return rules, nil
+}
+
+// RulesAtHeight returns the consensus rules in effect for the block at the
+// passed height of the main chain.  The height may be one past the end of the
+// main chain for the rules of the next block.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) RulesAtHeight(height int32) (*Rules, error) {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	var prevNode *blockNode
+	if height != 0 {
+		prevNode = b.bestChain.NodeByHeight(height - 1)
+		if prevNode == nil {
+			str := fmt.Sprintf("no block at height %d exists", height-1)
+			return nil, errNotInMainChain(str)
+		}
+	}
+	return b.rules(prevNode)
+}
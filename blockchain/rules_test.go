@@ -0,0 +1,132 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/globalcfg"
+	"github.com/pkt-cash/pktd/txscript"
+)
+
+// TestRulesAtHeight ensures the rules reported for a height follow the
+// activation heights, the timestamps of the blocks and the chain parameters.
+func TestRulesAtHeight(t *testing.T) {
+	params := chaincfg.RegressionNetParams
+	params.BIP0034Height = 1
+	params.BIP0066Height = 2
+	params.BIP0065Height = 3
+	if !globalcfg.SelectConfig(params.GlobalConf) {
+		t.Fatal("globalcfg.SelectConfig() called twice")
+	}
+	defer globalcfg.RemoveConfig()
+
+	// The regression test genesis block predates BIP0016 while the blocks
+	// on top of it are timestamped after its activation, so it is enforced
+	// once the median time of the blocks is past it.
+	chain := newFakeChain(&params)
+	node := chain.bestChain.Tip()
+	for i := 0; i < 3; i++ {
+		node = newFakeNode(node, 4, 0, txscript.Bip16Activation.Add(
+			time.Duration(node.height+1)*time.Second))
+		chain.index.AddNode(node)
+		chain.bestChain.SetTip(node)
+	}
+
+	if _, err := chain.RulesAtHeight(5); err == nil {
+		t.Fatal("RulesAtHeight: unexpected success past the next block")
+	}
+
+	tests := []struct {
+		height  int32
+		version int32
+		flags   txscript.ScriptFlags
+	}{
+		{0, 1, 0},
+		{1, 2, 0},
+		{2, 3, txscript.ScriptBip16 | txscript.ScriptVerifyDERSignatures},
+		{3, 4, txscript.ScriptBip16 | txscript.ScriptVerifyDERSignatures |
+			txscript.ScriptVerifyCheckLockTimeVerify},
+		{4, 4, txscript.ScriptBip16 | txscript.ScriptVerifyDERSignatures |
+			txscript.ScriptVerifyCheckLockTimeVerify},
+	}
+	for _, test := range tests {
+		rules, err := chain.RulesAtHeight(test.height)
+		if err != nil {
+			t.Fatalf("RulesAtHeight(%d): %v", test.height, err)
+		}
+		if rules.Height != test.height ||
+			rules.MinBlockVersion != test.version ||
+			rules.SerializedHeight != (test.height >= 1) {
+
+			t.Errorf("height %d: unexpected rules %+v", test.height,
+				rules)
+		}
+		if rules.ScriptFlags != test.flags {
+			t.Errorf("height %d: got flags %v, want %v", test.height,
+				rules.ScriptFlags, test.flags)
+		}
+		for id, state := range rules.Deployments {
+			if state != ThresholdDefined {
+				t.Errorf("height %d: deployment %d is %v",
+					test.height, id, state)
+			}
+		}
+		if rules.Subsidy.Epoch != 0 ||
+			rules.Subsidy.NextEpochHeight != params.SubsidyReductionInterval ||
+			rules.Subsidy.Subsidy != CalcBlockSubsidy(test.height, &params) ||
+			rules.Subsidy.NetworkStewardPayout != 0 {
+
+			t.Errorf("height %d: unexpected subsidy %+v", test.height,
+				rules.Subsidy)
+		}
+		if rules.PacketCrypt.Enabled ||
+			rules.PacketCrypt.AnnAging != (test.height >= 3) {
+
+			t.Errorf("height %d: unexpected PacketCrypt rules %+v",
+				test.height, rules.PacketCrypt)
+		}
+	}
+}
+
+// TestCalcSubsidyRules ensures the subsidy epochs follow the halvings and the
+// payout periods of chains with a network steward.
+func TestCalcSubsidyRules(t *testing.T) {
+	tests := []struct {
+		name      string
+		params    *chaincfg.Params
+		height    int32
+		epoch     int32
+		nextEpoch int32
+	}{
+		{"mainnet genesis", &chaincfg.MainNetParams, 0, 0, 210000},
+		{"mainnet halving", &chaincfg.MainNetParams, 420000, 2, 630000},
+		{"pkt genesis", &chaincfg.PktMainNetParams, 0, 0, 144000},
+		{"pkt period", &chaincfg.PktMainNetParams, 300000, 2, 432000},
+	}
+	for _, test := range tests {
+		rules := CalcSubsidyRules(test.height, test.params)
+		subsidy := CalcBlockSubsidy(test.height, test.params)
+		var payout int64
+		if test.params.GlobalConf.HasNetworkSteward {
+			payout = PktCalcNetworkStewardPayout(subsidy)
+		}
+		if rules.Epoch != test.epoch ||
+			rules.NextEpochHeight != test.nextEpoch ||
+			rules.Subsidy != subsidy ||
+			rules.NetworkStewardPayout != payout {
+
+			t.Errorf("%s: unexpected rules %+v", test.name, rules)
+		}
+	}
+
+	params := chaincfg.RegressionNetParams
+	params.SubsidyReductionInterval = 0
+	if rules := CalcSubsidyRules(1000, &params); rules.NextEpochHeight != -1 {
+		t.Errorf("unexpected rules %+v without subsidy reduction", rules)
+	}
+}
@@ -0,0 +1,36 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// ErrSafeMode is the error returned by ProcessBlock while the chain is in safe
+// mode.  It is not a rule error: the block was not checked at all and may be
+// processed again once the safe mode is left.
+var ErrSafeMode = errors.New("blocks are not accepted in safe mode")
+
+// SetSafeMode enters or leaves the safe mode.  In safe mode every block passed
+// to ProcessBlock is refused with ErrSafeMode, so nothing is written to the
+// database, while the queries keep being served.  This protects the database
+// when writing to it is not safe, such as when the disk is about to be full.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) SetSafeMode(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&b.safeMode, v)
+}
+
+// SafeMode returns whether the chain is in safe mode.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) SafeMode() bool {
+	return atomic.LoadInt32(&b.safeMode) != 0
+}
@@ -0,0 +1,40 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/chaincfg"
+)
+
+// TestSafeMode ensures blocks are refused without being checked while the
+// chain is in safe mode.
+func TestSafeMode(t *testing.T) {
+	params := &chaincfg.RegressionNetParams
+	chain, teardown, err := chainSetup("safemode", params)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardown()
+
+	genesis := btcutil.NewBlock(params.GenesisBlock)
+	chain.SetSafeMode(true)
+	if !chain.SafeMode() {
+		t.Fatalf("SafeMode: not in safe mode")
+	}
+	if _, _, err := chain.ProcessBlock(genesis, BFNone); err != ErrSafeMode {
+		t.Fatalf("ProcessBlock: got %v in safe mode, want %v", err,
+			ErrSafeMode)
+	}
+
+	// The genesis block is a duplicate once the safe mode is left.
+	chain.SetSafeMode(false)
+	_, _, err = chain.ProcessBlock(genesis, BFNone)
+	if _, ok := err.(RuleError); !ok {
+		t.Fatalf("ProcessBlock: got %v, want a rule error", err)
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"fmt"
+
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// consensusScriptFlags returns the script flags the consensus rules enforce
+// for the transactions of a block with the passed header on top of prevNode.
+// They are derived from the chain parameters and the state of the deployments
+// at prevNode and are always a subset of txscript.ConsensusVerifyFlags.
+//
+// This function MUST be called with the chain state lock held (for reads).
+func (b *BlockChain) consensusScriptFlags(prevNode *blockNode,
+	header *wire.BlockHeader) (txscript.ScriptFlags, error) {
+
+	// The genesis block has no previous node.
+	var height int32
+	if prevNode != nil {
+		height = prevNode.height + 1
+	}
+	var flags txscript.ScriptFlags
+
+	// Blocks created after the BIP0016 activation time need to have the
+	// pay-to-script-hash checks enabled.
+	if header.Timestamp.Unix() >= txscript.Bip16Activation.Unix() {
+		flags |= txscript.ScriptBip16
+	}
+
+	// Enforce DER signatures for block versions 3+ once the historical
+	// activation threshold has been reached.  This is part of BIP0066.
+	if header.Version >= 3 && height >= b.chainParams.BIP0066Height {
+		flags |= txscript.ScriptVerifyDERSignatures
+	}
+
+	// Enforce CHECKLOCKTIMEVERIFY for block versions 4+ once the historical
+	// activation threshold has been reached.  This is part of BIP0065.
+	if header.Version >= 4 && height >= b.chainParams.BIP0065Height {
+		flags |= txscript.ScriptVerifyCheckLockTimeVerify
+	}
+
+	// Enforce CHECKSEQUENCEVERIFY once the soft-fork deployment is fully
+	// active.
+	csvState, err := b.deploymentState(prevNode, chaincfg.DeploymentCSV)
+	if err != nil {
+		return 0, err
+	}
+	if csvState == ThresholdActive {
+		flags |= txscript.ScriptVerifyCheckSequenceVerify
+	}
+
+	// Enforce the segwit soft-fork package once the soft-fork has shifted
+	// into the "active" version bits state.
+	segwitState, err := b.deploymentState(prevNode, chaincfg.DeploymentSegwit)
+	if err != nil {
+		return 0, err
+	}
+	if segwitState == ThresholdActive {
+		flags |= txscript.ScriptVerifyWitness
+		flags |= txscript.ScriptStrictMultiSig
+	}
+
+	return flags, nil
+}
+
+// ConsensusScriptFlags returns the script flags the consensus rules enforce
+// for the transactions of a block with the passed header extending the end of
+// the main chain.  Transactions which must also be standard are checked with
+// these flags along with txscript.PolicyVerifyFlags.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) ConsensusScriptFlags(header *wire.BlockHeader) (txscript.ScriptFlags, error) {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	return b.consensusScriptFlags(b.bestChain.Tip(), header)
+}
+
+// BlockScriptFlags returns the script flags the consensus rules enforce for
+// the transactions of the known block with the passed hash, as of its parent,
+// so the flags of blocks below the end of the main chain can be found.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) BlockScriptFlags(hash *chainhash.Hash) (txscript.ScriptFlags, error) {
+	node := b.index.LookupNode(hash)
+	if node == nil {
+		return 0, fmt.Errorf("block %s is not known", hash)
+	}
+
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	header := node.Header()
+	return b.consensusScriptFlags(node.parent, &header)
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/chaincfg/globalcfg"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// TestConsensusScriptFlags ensures the script flags enforced for a block follow
+// its version, its height and the state of the deployments.
+func TestConsensusScriptFlags(t *testing.T) {
+	params := chaincfg.RegressionNetParams
+	params.BIP0065Height = 3
+	params.BIP0066Height = 2
+	if !globalcfg.SelectConfig(params.GlobalConf) {
+		t.Fatal("globalcfg.SelectConfig() called twice")
+	}
+	defer globalcfg.RemoveConfig()
+
+	chain := newFakeChain(&params)
+
+	// The blocks are timestamped after the activation of BIP0016.
+	timestamp := func(height int32) time.Time {
+		return txscript.Bip16Activation.Add(time.Duration(height) *
+			time.Second)
+	}
+	check := func(version int32, want txscript.ScriptFlags) {
+		t.Helper()
+		tip := chain.bestChain.Tip()
+		header := &wire.BlockHeader{
+			Version:   version,
+			PrevBlock: tip.hash,
+			Timestamp: timestamp(tip.height + 1),
+		}
+		flags, err := chain.ConsensusScriptFlags(header)
+		if err != nil {
+			t.Fatalf("ConsensusScriptFlags: %v", err)
+		}
+		if flags != want {
+			t.Fatalf("version %d at height %d: got flags %v, want %v",
+				version, tip.height+1, flags, want)
+		}
+		if flags&^txscript.ConsensusVerifyFlags != 0 {
+			t.Fatalf("flags %v are not consensus flags", flags)
+		}
+	}
+	extend := func(version int32, numBlocks uint32) {
+		node := chain.bestChain.Tip()
+		for i := uint32(0); i < numBlocks; i++ {
+			node = newFakeNode(node, version, 0,
+				timestamp(node.height+1))
+			chain.index.AddNode(node)
+			chain.bestChain.SetTip(node)
+		}
+	}
+
+	// The version bits deployments start defined and BIP0066 is not yet
+	// active at the height of the first block.  BIP0066 and BIP0065 only
+	// apply to blocks of the versions introducing them.
+	check(4, txscript.ScriptBip16)
+	extend(1, 1)
+	check(2, txscript.ScriptBip16)
+	check(3, txscript.ScriptBip16|txscript.ScriptVerifyDERSignatures)
+	extend(1, 1)
+	check(4, txscript.ScriptBip16|txscript.ScriptVerifyDERSignatures|
+		txscript.ScriptVerifyCheckLockTimeVerify)
+
+	// Signal both CSV and segwit until they are active.
+	csvBit := params.Deployments[chaincfg.DeploymentCSV].BitNumber
+	segwitBit := params.Deployments[chaincfg.DeploymentSegwit].BitNumber
+	version := int32(vbTopBits | 1<<csvBit | 1<<segwitBit)
+	extend(version, params.MinerConfirmationWindow*3)
+	check(2, txscript.ScriptBip16|txscript.ScriptVerifyCheckSequenceVerify|
+		txscript.ScriptVerifyWitness|txscript.ScriptStrictMultiSig)
+	check(4, txscript.ConsensusVerifyFlags)
+
+	// The flags of a block below the tip are those as of its parent, so
+	// the first signalling block does not have the deployments active.
+	node := chain.bestChain.Tip().RelativeAncestor(
+		int32(params.MinerConfirmationWindow*3 - 1))
+	flags, err := chain.BlockScriptFlags(&node.hash)
+	if err != nil {
+		t.Fatalf("BlockScriptFlags: %v", err)
+	}
+	want := txscript.ScriptBip16 | txscript.ScriptVerifyDERSignatures |
+		txscript.ScriptVerifyCheckLockTimeVerify
+	if flags != want {
+		t.Fatalf("BlockScriptFlags: got flags %v, want %v", flags, want)
+	}
+	if _, err := chain.BlockScriptFlags(&chainhash.Hash{1}); err == nil {
+		t.Fatal("BlockScriptFlags: no error for an unknown block")
+	}
+}
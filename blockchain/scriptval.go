@@ -64,7 +64,8 @@ out:
 					"transaction %s:%d",
 					txIn.PreviousOutPoint, txVI.tx.Hash(),
 					txVI.txInIndex)
-				err := ruleError(ErrMissingTxOut, str)
+				err := inputRuleError(ErrMissingTxOut, str,
+					txVI.tx.Index(), txVI.txInIndex)
 				v.sendResult(err)
 				break out
 			}
@@ -85,7 +86,8 @@ out:
 					txVI.tx.Hash(), txVI.txInIndex,
 					txIn.PreviousOutPoint, err, witness,
 					sigScript, pkScript)
-				err := ruleError(ErrScriptMalformed, str)
+				err := inputRuleError(ErrScriptMalformed, str,
+					txVI.tx.Index(), txVI.txInIndex)
 				v.sendResult(err)
 				break out
 			}
@@ -99,7 +101,8 @@ out:
 					txVI.tx.Hash(), txVI.txInIndex,
 					txIn.PreviousOutPoint, err, witness,
 					sigScript, pkScript)
-				err := ruleError(ErrScriptValidation, str)
+				err := inputRuleError(ErrScriptValidation, str,
+					txVI.tx.Index(), txVI.txInIndex)
 				v.sendResult(err)
 				break out
 			}
@@ -199,7 +202,7 @@ func ValidateTransactionScripts(tx *btcutil.Tx, utxoView *UtxoViewpoint,
 	// If the hashcache doesn't yet has the sighash midstate for this
 	// transaction, then we'll compute them now so we can re-use them
 	// amongst all worker validation goroutines.
-	if segwitActive && tx.MsgTx().HasWitness() &&
+	if segwitActive && tx.MsgTx().HasWitness() && hashCache != nil &&
 		!hashCache.ContainsHashes(tx.Hash()) {
 		hashCache.AddSigHashes(tx.MsgTx())
 	}
@@ -209,9 +212,13 @@ func ValidateTransactionScripts(tx *btcutil.Tx, utxoView *UtxoViewpoint,
 		// The same pointer to the transaction's sighash midstate will
 		// be re-used amongst all validation goroutines. By
 		// pre-computing the sighash here instead of during validation,
-		// we ensure the sighashes
-		// are only computed once.
-		cachedHashes, _ = hashCache.GetSigHashes(tx.Hash())
+		// we ensure the sighashes are only computed once, even without
+		// a hash cache.
+		if hashCache != nil {
+			cachedHashes, _ = hashCache.GetSigHashes(tx.Hash())
+		} else {
+			cachedHashes = txscript.NewTxSigHashes(tx.MsgTx())
+		}
 	}
 
 	// Collect all of the transaction inputs and required information for
@@ -0,0 +1,54 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"sync"
+	"time"
+)
+
+// StageStats is the number of blocks which went through a stage of the block
+// validation and the total time spent in it.
+type StageStats struct {
+	Count    uint64
+	Duration time.Duration
+}
+
+// ValidationStats holds the time spent in the most expensive stages of the
+// validation of the blocks processed since the chain instance was created.
+type ValidationStats struct {
+	// Proofs is the time spent checking the PacketCrypt proofs.
+	Proofs StageStats
+
+	// Scripts is the time spent running the scripts of the transactions.
+	Scripts StageStats
+}
+
+// validationStats accumulates the validation stats.  Its zero value is ready
+// to use.
+type validationStats struct {
+	mtx   sync.Mutex
+	stats ValidationStats
+}
+
+// add adds a block to the passed stage which started at the passed time.
+func (v *validationStats) add(stage *StageStats, start time.Time) {
+	elapsed := time.Since(start)
+	v.mtx.Lock()
+	stage.Count++
+	stage.Duration += elapsed
+	v.mtx.Unlock()
+}
+
+// ValidationStats returns the time spent in the most expensive stages of the
+// block validation since the chain instance was created.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) ValidationStats() ValidationStats {
+	b.validationStats.mtx.Lock()
+	defer b.validationStats.mtx.Unlock()
+
+	return b.validationStats.stats
+}
@@ -0,0 +1,267 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// maxStaleBlocks is the number of recently seen blocks outside of the main
+// chain which are remembered.
+const maxStaleBlocks = 500
+
+// StaleReason is the reason a block was left out of the main chain.
+type StaleReason uint8
+
+const (
+	// StaleOrphan is used for a block whose parent was unknown when it was
+	// received.
+	StaleOrphan StaleReason = iota
+
+	// StaleSideChain is used for a block extending a chain with less work
+	// than the main chain.
+	StaleSideChain
+
+	// StaleReorganized is used for a block disconnected from the main chain
+	// by a reorganization.
+	StaleReorganized
+)
+
+// staleReasonStrings maps the stale reasons to their names.
+var staleReasonStrings = map[StaleReason]string{
+	StaleOrphan:      "orphan",
+	StaleSideChain:   "sidechain",
+	StaleReorganized: "reorganized",
+}
+
+// String returns the name of the stale reason.
+func (r StaleReason) String() string {
+	if s, ok := staleReasonStrings[r]; ok {
+		return s
+	}
+	return fmt.Sprintf("Unknown StaleReason (%d)", uint8(r))
+}
+
+// StaleStatus is the current state of a block outside of the main chain.
+type StaleStatus uint8
+
+const (
+	// StaleStatusOrphan is used for a block held in the orphan pool until
+	// its parent is received.
+	StaleStatusOrphan StaleStatus = iota
+
+	// StaleStatusSideChain is used for a block of the block index which is
+	// not known to be invalid.
+	StaleStatusSideChain
+
+	// StaleStatusInvalid is used for a block of the block index which
+	// failed validation, or descends from such a block.
+	StaleStatusInvalid
+
+	// StaleStatusDropped is used for an orphan removed from the orphan pool
+	// without its parent being received.
+	StaleStatusDropped
+)
+
+// staleStatusStrings maps the stale statuses to their names.
+var staleStatusStrings = map[StaleStatus]string{
+	StaleStatusOrphan:    "orphan",
+	StaleStatusSideChain: "sidechain",
+	StaleStatusInvalid:   "invalid",
+	StaleStatusDropped:   "dropped",
+}
+
+// String returns the name of the stale status.
+func (s StaleStatus) String() string {
+	if str, ok := staleStatusStrings[s]; ok {
+		return str
+	}
+	return fmt.Sprintf("Unknown StaleStatus (%d)", uint8(s))
+}
+
+// StaleBlock describes a recently seen block with a valid proof of work which
+// is not part of the main chain.
+type StaleBlock struct {
+	Hash   chainhash.Hash
+	Header wire.BlockHeader
+
+	// Height is the height of the block, taken from its coinbase when its
+	// parent is unknown, or -1 when it cannot be determined.
+	Height int32
+
+	// Seen is the time the block was first left out of the main chain.
+	Seen time.Time
+
+	// Reason is why the block was last left out of the main chain and
+	// Status is its current state.
+	Reason StaleReason
+	Status StaleStatus
+
+	// ForkHash and ForkHeight identify the last main chain block the block
+	// descends from.  ForkHeight is -1 when the block does not connect to
+	// the block index.
+	ForkHash   chainhash.Hash
+	ForkHeight int32
+
+	// MainChainHash is the hash of the main chain block at the height of
+	// the block, which is zero when there is none.
+	MainChainHash chainhash.Hash
+
+	// Children are the hashes of the known blocks outside of the main chain
+	// building on the block.
+	Children []chainhash.Hash
+}
+
+// staleBlock is a block recorded in the stale block pool.
+type staleBlock struct {
+	header wire.BlockHeader
+	height int32
+	seen   time.Time
+	reason StaleReason
+}
+
+// staleBlocks remembers the recently seen blocks outside of the main chain,
+// evicting the oldest ones first.  Its zero value is ready to use.  It is
+// protected by the chain lock.
+type staleBlocks struct {
+	blocks map[chainhash.Hash]*staleBlock
+	order  []chainhash.Hash
+}
+
+// add records that the block with the passed header and height was left out
+// of the main chain for the passed reason.  The time the block was first seen
+// is kept when it was already recorded.
+func (s *staleBlocks) add(header *wire.BlockHeader, height int32, reason StaleReason) {
+	hash := header.BlockHash()
+	if sb, ok := s.blocks[hash]; ok {
+		sb.height = height
+		sb.reason = reason
+		return
+	}
+
+	if s.blocks == nil {
+		s.blocks = make(map[chainhash.Hash]*staleBlock)
+	}
+	if len(s.order) >= maxStaleBlocks {
+		delete(s.blocks, s.order[0])
+		s.order = s.order[1:]
+	}
+	s.blocks[hash] = &staleBlock{
+		header: *header,
+		height: height,
+		seen:   time.Now(),
+		reason: reason,
+	}
+	s.order = append(s.order, hash)
+}
+
+// addStaleOrphan records the passed block added to the orphan pool.  Its
+// height is taken from its coinbase since its ancestors are unknown.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) addStaleOrphan(block *btcutil.Block) {
+	height := int32(-1)
+	if txns := block.Transactions(); len(txns) > 0 {
+		if h, err := ExtractCoinbaseHeight(txns[0]); err == nil {
+			height = h
+		}
+	}
+	b.staleBlocks.add(&block.MsgBlock().Header, height, StaleOrphan)
+}
+
+// addStaleNode records that the passed block node was left out of the main
+// chain for the passed reason.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) addStaleNode(node *blockNode, reason StaleReason) {
+	header := node.Header()
+	b.staleBlocks.add(&header, node.height, reason)
+}
+
+// StaleBlocks returns the recently seen blocks with a valid proof of work
+// which are not part of the main chain, newest first, along with the fork
+// points and the blocks building on them.  It covers the orphans waiting for
+// their parents, the blocks of side chains with less work than the main chain
+// and the blocks disconnected by reorganizations.  The blocks which joined the
+// main chain since are left out.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) StaleBlocks() []StaleBlock {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+	b.orphanLock.RLock()
+	defer b.orphanLock.RUnlock()
+
+	// Collect the children of the blocks among the recorded blocks and the
+	// orphans, which may have been received after their parents were
+	// evicted from the record.
+	children := make(map[chainhash.Hash][]chainhash.Hash)
+	addChild := func(parent, child chainhash.Hash) {
+		for _, hash := range children[parent] {
+			if hash == child {
+				return
+			}
+		}
+		children[parent] = append(children[parent], child)
+	}
+	for _, hash := range b.staleBlocks.order {
+		sb := b.staleBlocks.blocks[hash]
+		addChild(sb.header.PrevBlock, hash)
+	}
+	for hash, orphan := range b.orphans {
+		addChild(orphan.block.MsgBlock().Header.PrevBlock, hash)
+	}
+
+	result := make([]StaleBlock, 0, len(b.staleBlocks.order))
+	for i := len(b.staleBlocks.order) - 1; i >= 0; i-- {
+		hash := b.staleBlocks.order[i]
+		sb := b.staleBlocks.blocks[hash]
+		stale := StaleBlock{
+			Hash:       hash,
+			Header:     sb.header,
+			Height:     sb.height,
+			Seen:       sb.seen,
+			Reason:     sb.reason,
+			ForkHeight: -1,
+			Children:   children[hash],
+		}
+
+		node := b.index.LookupNode(&hash)
+		switch {
+		case node != nil && b.bestChain.Contains(node):
+			continue
+
+		case node != nil:
+			stale.Status = StaleStatusSideChain
+			if b.index.NodeStatus(node).KnownInvalid() {
+				stale.Status = StaleStatusInvalid
+			}
+			if fork := b.bestChain.FindFork(node); fork != nil {
+				stale.ForkHash = fork.hash
+				stale.ForkHeight = fork.height
+			}
+
+		default:
+			stale.Status = StaleStatusDropped
+			if _, ok := b.orphans[hash]; ok {
+				stale.Status = StaleStatusOrphan
+			}
+		}
+
+		if stale.Height >= 0 {
+			mainNode := b.bestChain.NodeByHeight(stale.Height)
+			if mainNode != nil {
+				stale.MainChainHash = mainNode.hash
+			}
+		}
+		result = append(result, stale)
+	}
+	return result
+}
@@ -0,0 +1,24 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package utreexo implements an experimental Utreexo-style hash accumulator of
+the unspent transaction outputs.
+
+The accumulator commits to a set of leaves, the hashes of the unspent outputs,
+with a forest of perfect binary merkle trees, one for each bit set in the number
+of leaves.  The roots of the trees are enough to verify that an output is
+unspent given a proof made of the hashes of the siblings on its path to a root,
+so a validating node only needs to keep a few hundred bytes instead of the
+whole utxo set and can ask peers holding the full forest for the proofs of the
+outputs it has to check.
+
+A Forest holds every node and creates proofs while a Stump only holds the roots
+and verifies them.
+
+This is a prototype.  Leaves are deleted by moving the last leaf into the
+position of the deleted one rather than with the batched deletion of the
+Utreexo paper, so the roots are not compatible with other implementations.
+*/
+package utreexo
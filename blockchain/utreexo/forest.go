@@ -0,0 +1,270 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package utreexo
+
+import (
+	"errors"
+	"math/bits"
+
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+)
+
+var (
+	// ErrLeafNotFound is returned when a leaf is not in the forest.
+	ErrLeafNotFound = errors.New("leaf not found in the accumulator")
+
+	// ErrDuplicateLeaf is returned when a leaf is added to a forest which
+	// already holds it.
+	ErrDuplicateLeaf = errors.New("leaf already in the accumulator")
+
+	// ErrBadPosition is returned when a deletion is undone at a position
+	// the forest can not hold.
+	ErrBadPosition = errors.New("position out of the accumulator")
+
+	// ErrInvalidProof is returned when a proof does not lead from its leaf
+	// to a root of the accumulator.
+	ErrInvalidProof = errors.New("invalid accumulator proof")
+)
+
+// parentHash returns the hash of the parent of the passed nodes.
+func parentHash(left, right *chainhash.Hash) chainhash.Hash {
+	var buf [chainhash.HashSize * 2]byte
+	copy(buf[:chainhash.HashSize], left[:])
+	copy(buf[chainhash.HashSize:], right[:])
+	return chainhash.DoubleHashH(buf[:])
+}
+
+// Proof proves a leaf is in the accumulator.  It holds the position of the
+// leaf and the hashes of the siblings on its path to the root of its tree,
+// from the bottom up.
+type Proof struct {
+	Position uint64
+	Siblings []chainhash.Hash
+}
+
+// Stump is the part of the accumulator needed to verify proofs: the number of
+// leaves and the roots of the trees, from the largest tree to the smallest.
+type Stump struct {
+	NumLeaves uint64
+	Roots     []chainhash.Hash
+}
+
+// Verify returns nil when the passed proof shows the leaf is in the
+// accumulator and ErrInvalidProof otherwise.
+func (s *Stump) Verify(leaf chainhash.Hash, proof *Proof) error {
+	if proof.Position >= s.NumLeaves ||
+		len(s.Roots) != bits.OnesCount64(s.NumLeaves) {
+
+		return ErrInvalidProof
+	}
+
+	// Find the tree holding the position.  The trees are laid out from
+	// the largest to the smallest and each one is aligned to its size.
+	var start uint64
+	root := 0
+	height := 63
+	for ; height >= 0; height-- {
+		size := uint64(1) << uint(height)
+		if s.NumLeaves&size == 0 {
+			continue
+		}
+		if proof.Position < start+size {
+			break
+		}
+		start += size
+		root++
+	}
+	if len(proof.Siblings) != height {
+		return ErrInvalidProof
+	}
+
+	node := leaf
+	pos := proof.Position
+	for i := range proof.Siblings {
+		if pos&1 == 0 {
+			node = parentHash(&node, &proof.Siblings[i])
+		} else {
+			node = parentHash(&proof.Siblings[i], &node)
+		}
+		pos >>= 1
+	}
+	if node != s.Roots[root] {
+		return ErrInvalidProof
+	}
+	return nil
+}
+
+// Forest is an accumulator holding every node of its trees, so it can create
+// the proofs of its leaves.
+//
+// Deleting a leaf moves the last leaf into its position, so the positions of
+// the leaves, and therefore the roots, depend on the order of the additions
+// and deletions.  The deletions and additions are undone exactly with
+// UndoDelete and UndoAdd.
+//
+// A Forest is not safe for concurrent access.
+type Forest struct {
+	// levels holds the nodes of the trees by height, the leaves first.
+	// The node at index i of level h is the root of the subtree of the
+	// leaves i<<h to (i+1)<<h - 1, so level h holds NumLeaves()>>h nodes.
+	levels [][]chainhash.Hash
+
+	// positions maps every leaf to its position.
+	positions map[chainhash.Hash]uint64
+}
+
+// NewForest returns an empty forest.
+func NewForest() *Forest {
+	return &Forest{
+		positions: make(map[chainhash.Hash]uint64),
+	}
+}
+
+// NumLeaves returns the number of leaves in the forest.
+func (f *Forest) NumLeaves() uint64 {
+	if len(f.levels) == 0 {
+		return 0
+	}
+	return uint64(len(f.levels[0]))
+}
+
+// Leaf returns the leaf at the passed position, which must be lower than the
+// number of leaves.
+func (f *Forest) Leaf(pos uint64) chainhash.Hash {
+	return f.levels[0][pos]
+}
+
+// push appends the leaf to the forest and hashes the subtrees it completes.
+func (f *Forest) push(leaf chainhash.Hash) {
+	node := leaf
+	for h := 0; ; h++ {
+		if h == len(f.levels) {
+			f.levels = append(f.levels, nil)
+		}
+		f.levels[h] = append(f.levels[h], node)
+		n := len(f.levels[h])
+		if n%2 != 0 {
+			return
+		}
+		node = parentHash(&f.levels[h][n-2], &f.levels[h][n-1])
+	}
+}
+
+// pop removes the last leaf of the forest, splitting the smallest tree into
+// the subtrees which do not hold it, and returns the leaf.
+func (f *Forest) pop() chainhash.Hash {
+	n := f.NumLeaves() - 1
+	leaf := f.levels[0][n]
+	for h := range f.levels {
+		f.levels[h] = f.levels[h][:n>>uint(h)]
+	}
+	return leaf
+}
+
+// set replaces the leaf at the passed position and hashes its path to the
+// root again.
+func (f *Forest) set(pos uint64, leaf chainhash.Hash) {
+	f.levels[0][pos] = leaf
+	for h := 0; h+1 < len(f.levels); h++ {
+		parent := pos >> 1
+		if parent >= uint64(len(f.levels[h+1])) {
+			return
+		}
+		f.levels[h+1][parent] = parentHash(&f.levels[h][parent*2],
+			&f.levels[h][parent*2+1])
+		pos = parent
+	}
+}
+
+// Add adds the leaf to the forest.
+func (f *Forest) Add(leaf chainhash.Hash) error {
+	if _, ok := f.positions[leaf]; ok {
+		return ErrDuplicateLeaf
+	}
+	f.positions[leaf] = f.NumLeaves()
+	f.push(leaf)
+	return nil
+}
+
+// UndoAdd removes the leaf, which must be the last one added to the forest.
+func (f *Forest) UndoAdd(leaf chainhash.Hash) error {
+	n := f.NumLeaves()
+	if n == 0 || f.levels[0][n-1] != leaf {
+		return ErrLeafNotFound
+	}
+	f.pop()
+	delete(f.positions, leaf)
+	return nil
+}
+
+// Delete removes the leaf from the forest and returns the position it was
+// at, which is needed to undo the deletion.
+func (f *Forest) Delete(leaf chainhash.Hash) (uint64, error) {
+	pos, ok := f.positions[leaf]
+	if !ok {
+		return 0, ErrLeafNotFound
+	}
+	delete(f.positions, leaf)
+	last := f.pop()
+	if pos < f.NumLeaves() {
+		f.set(pos, last)
+		f.positions[last] = pos
+	}
+	return pos, nil
+}
+
+// UndoDelete restores the leaf deleted from the passed position.  It must be
+// called in the reverse order of the deletions and additions made since.
+func (f *Forest) UndoDelete(leaf chainhash.Hash, pos uint64) error {
+	if _, ok := f.positions[leaf]; ok {
+		return ErrDuplicateLeaf
+	}
+	n := f.NumLeaves()
+	if pos > n {
+		return ErrBadPosition
+	}
+	if pos < n {
+		moved := f.levels[0][pos]
+		f.positions[moved] = n
+		f.push(moved)
+		f.set(pos, leaf)
+	} else {
+		f.push(leaf)
+	}
+	f.positions[leaf] = pos
+	return nil
+}
+
+// Stump returns the roots of the forest.
+func (f *Forest) Stump() *Stump {
+	n := f.NumLeaves()
+	s := &Stump{
+		NumLeaves: n,
+		Roots:     make([]chainhash.Hash, 0, bits.OnesCount64(n)),
+	}
+	for h := len(f.levels) - 1; h >= 0; h-- {
+		if n&(1<<uint(h)) != 0 {
+			s.Roots = append(s.Roots, f.levels[h][n>>uint(h)-1])
+		}
+	}
+	return s
+}
+
+// Prove returns the proof of the leaf.
+func (f *Forest) Prove(leaf chainhash.Hash) (*Proof, error) {
+	pos, ok := f.positions[leaf]
+	if !ok {
+		return nil, ErrLeafNotFound
+	}
+	proof := &Proof{Position: pos}
+	for h := 0; h+1 < len(f.levels); h++ {
+		if pos>>1 >= uint64(len(f.levels[h+1])) {
+			break
+		}
+		proof.Siblings = append(proof.Siblings, f.levels[h][pos^1])
+		pos >>= 1
+	}
+	return proof, nil
+}
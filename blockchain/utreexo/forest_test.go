@@ -0,0 +1,118 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package utreexo
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+)
+
+// checkForest ensures every leaf of the forest can be proven against its
+// roots and that the roots match the ones of a forest the leaves are added to
+// in order, so the incremental updates hashed the same trees.
+func checkForest(t *testing.T, f *Forest) {
+	t.Helper()
+
+	stump := f.Stump()
+	fresh := NewForest()
+	for pos := uint64(0); pos < f.NumLeaves(); pos++ {
+		leaf := f.Leaf(pos)
+		proof, err := f.Prove(leaf)
+		if err != nil {
+			t.Fatalf("Prove: %v", err)
+		}
+		if proof.Position != pos {
+			t.Fatalf("leaf at %d proven at %d", pos, proof.Position)
+		}
+		if err := stump.Verify(leaf, proof); err != nil {
+			t.Fatalf("leaf %d of %d: %v", pos, f.NumLeaves(), err)
+		}
+		if err := fresh.Add(leaf); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+	if !reflect.DeepEqual(fresh.Stump(), stump) {
+		t.Fatalf("roots %v, want %v", stump.Roots, fresh.Stump().Roots)
+	}
+}
+
+// TestForest ensures proofs of a forest verify while leaves are added and
+// deleted, and that undoing the changes restores the forest.
+func TestForest(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	randomLeaf := func() chainhash.Hash {
+		var leaf chainhash.Hash
+		rng.Read(leaf[:])
+		return leaf
+	}
+
+	type op struct {
+		leaf    chainhash.Hash
+		deleted bool
+		pos     uint64
+	}
+	f := NewForest()
+	var ops []op
+	var stumps []*Stump
+	for i := 0; i < 300; i++ {
+		stumps = append(stumps, f.Stump())
+		if f.NumLeaves() == 0 || rng.Intn(3) != 0 {
+			leaf := randomLeaf()
+			if err := f.Add(leaf); err != nil {
+				t.Fatalf("Add: %v", err)
+			}
+			ops = append(ops, op{leaf: leaf})
+		} else {
+			leaf := f.Leaf(uint64(rng.Int63n(int64(f.NumLeaves()))))
+			pos, err := f.Delete(leaf)
+			if err != nil {
+				t.Fatalf("Delete: %v", err)
+			}
+			ops = append(ops, op{leaf: leaf, deleted: true, pos: pos})
+			if _, err := f.Prove(leaf); err != ErrLeafNotFound {
+				t.Fatalf("deleted leaf proven: %v", err)
+			}
+		}
+		checkForest(t, f)
+	}
+
+	// Proofs fail against other roots and for other leaves.
+	stump := f.Stump()
+	leaf := f.Leaf(f.NumLeaves() / 2)
+	proof, err := f.Prove(leaf)
+	if err != nil {
+		t.Fatalf("Prove: %v", err)
+	}
+	if err := stump.Verify(randomLeaf(), proof); err != ErrInvalidProof {
+		t.Fatalf("proof of another leaf: unexpected error %v", err)
+	}
+	if err := stumps[len(stumps)/2].Verify(leaf, proof); err != ErrInvalidProof {
+		t.Fatalf("proof against old roots: unexpected error %v", err)
+	}
+	if err := f.Add(leaf); err != ErrDuplicateLeaf {
+		t.Fatalf("duplicate leaf: unexpected error %v", err)
+	}
+
+	// Undoing the operations in reverse order restores every state.
+	for i := len(ops) - 1; i >= 0; i-- {
+		var err error
+		if ops[i].deleted {
+			err = f.UndoDelete(ops[i].leaf, ops[i].pos)
+		} else {
+			err = f.UndoAdd(ops[i].leaf)
+		}
+		if err != nil {
+			t.Fatalf("undo %d: %v", i, err)
+		}
+		if !reflect.DeepEqual(f.Stump(), stumps[i]) {
+			t.Fatalf("undo %d: roots %v, want %v", i, f.Stump().Roots,
+				stumps[i].Roots)
+		}
+		checkForest(t, f)
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package utreexo
+
+import (
+	"encoding/binary"
+
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// LeafData is the data of an unspent output committed to by its leaf.
+type LeafData struct {
+	OutPoint   wire.OutPoint
+	Height     int32
+	IsCoinBase bool
+	Amount     int64
+	PkScript   []byte
+}
+
+// Hash returns the leaf of the output, the double sha256 of the outpoint, the
+// height of its block shifted left by one with the lowest bit set for coinbase
+// outputs, the amount and the public key script, with the integers encoded in
+// little endian.
+func (l *LeafData) Hash() chainhash.Hash {
+	buf := make([]byte, 0, chainhash.HashSize+16+len(l.PkScript))
+	buf = l.OutPoint.AppendTo(buf)[:chainhash.HashSize+16]
+	heightCode := uint32(l.Height) << 1
+	if l.IsCoinBase {
+		heightCode |= 1
+	}
+	binary.LittleEndian.PutUint32(buf[chainhash.HashSize+4:], heightCode)
+	binary.LittleEndian.PutUint64(buf[chainhash.HashSize+8:],
+		uint64(l.Amount))
+	buf = append(buf, l.PkScript...)
+	return chainhash.DoubleHashH(buf)
+}
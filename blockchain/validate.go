@@ -398,17 +398,20 @@ func (b *BlockChain) pcCheckProofOfWork(block *btcutil.Block) error {
 	for i := 0; i < len(pcp.Announcements); i++ {
 		ph := pcp.Announcements[i].GetParentBlockHeight()
 		if ph > 0x7fffffff {
-			return ruleError(ErrBadPow, "ann parent block height is negative")
+			return annRuleError(ErrBadPow, "ann parent block height is negative", i)
 		}
 		hash, err := b.BlockHashByHeight(int32(ph))
 		if err != nil {
-			return ruleError(ErrPowCannotVerify,
-				fmt.Sprintf("Cannot verify pow, missing block at height [%d]", ph))
+			return annRuleError(ErrPowCannotVerify,
+				fmt.Sprintf("Cannot verify pow, missing block at height [%d]", ph), i)
 		}
 		hashes[i] = hash
 	}
 	if _, err := packetcrypt.ValidatePcBlock(block.MsgBlock(), height, 0, hashes); err != nil {
 		str := fmt.Sprintf("Error validating PacketCrypt proof [%v]", err)
+		if annErr, ok := err.(packetcrypt.AnnouncementError); ok {
+			return annRuleError(ErrBadPow, str, annErr.Index)
+		}
 		return ruleError(ErrBadPow, str)
 	}
 	return nil
@@ -461,7 +464,8 @@ func CountP2SHSigOps(tx *btcutil.Tx, isCoinBaseTx bool, utxoView *UtxoViewpoint)
 				"transaction %s:%d either does not exist or "+
 				"has already been spent", txIn.PreviousOutPoint,
 				tx.Hash(), txInIndex)
-			return 0, ruleError(ErrMissingTxOut, str)
+			return 0, inputRuleError(ErrMissingTxOut, str,
+				tx.Index(), txInIndex)
 		}
 
 		// We're only interested in pay-to-script-hash types, so skip
@@ -589,10 +593,10 @@ func checkBlockSanity(block *btcutil.Block, powLimit *big.Int, timeSource Median
 
 	// Do some preliminary checks on each transaction to ensure they are
 	// sane before continuing.
-	for _, tx := range transactions {
+	for i, tx := range transactions {
 		err := CheckTransactionSanity(tx)
 		if err != nil {
-			return err
+			return withTxIndex(err, i)
 		}
 	}
 
@@ -608,7 +612,8 @@ func checkBlockSanity(block *btcutil.Block, powLimit *big.Int, timeSource Median
 		str := fmt.Sprintf("block merkle root is invalid - block "+
 			"header indicates %v, but calculated value is %v",
 			header.MerkleRoot, calculatedMerkleRoot)
-		return ruleError(ErrBadMerkleRoot, str)
+		return valueRuleError(ErrBadMerkleRoot, str,
+			calculatedMerkleRoot, header.MerkleRoot)
 	}
 
 	// Check for duplicate transactions.  This check will be fairly quick
@@ -637,7 +642,8 @@ func checkBlockSanity(block *btcutil.Block, powLimit *big.Int, timeSource Median
 			str := fmt.Sprintf("block contains too many signature "+
 				"operations - got %v, max %v", totalSigOps,
 				MaxBlockSigOpsCost)
-			return ruleError(ErrTooManySigOps, str)
+			return valueRuleError(ErrTooManySigOps, str,
+				MaxBlockSigOpsCost, totalSigOps)
 		}
 	}
 
@@ -703,7 +709,8 @@ func checkSerializedHeight(coinbaseTx *btcutil.Tx, wantHeight int32) error {
 		str := fmt.Sprintf("the coinbase signature script serialized "+
 			"block height is %d when %d was expected",
 			serializedHeight, wantHeight)
-		return ruleError(ErrBadCoinbaseHeight, str)
+		return valueRuleError(ErrBadCoinbaseHeight, str, wantHeight,
+			serializedHeight)
 	}
 	return nil
 }
@@ -731,7 +738,8 @@ func (b *BlockChain) checkBlockHeaderContext(header *wire.BlockHeader, prevNode
 		if blockDifficulty != expectedDifficulty {
 			str := "block difficulty of %d is not the expected value of %d"
 			str = fmt.Sprintf(str, blockDifficulty, expectedDifficulty)
-			return ruleError(ErrUnexpectedDifficulty, str)
+			return valueRuleError(ErrUnexpectedDifficulty, str,
+				expectedDifficulty, blockDifficulty)
 		}
 
 		// Ensure the timestamp for the block header is after the
@@ -764,7 +772,11 @@ func (b *BlockChain) checkBlockHeaderContext(header *wire.BlockHeader, prevNode
 	if err != nil {
 		return err
 	}
-	if checkpointNode != nil && blockHeight < checkpointNode.height {
+	// Blocks which are already part of the main chain are exempt since
+	// they can only be getting re-validated.
+	if checkpointNode != nil && blockHeight < checkpointNode.height &&
+		!b.inMainChain(&blockHash) {
+
 		str := fmt.Sprintf("block at height %d forks the main chain "+
 			"before the previous checkpoint at height %d",
 			blockHeight, checkpointNode.height)
@@ -774,11 +786,7 @@ func (b *BlockChain) checkBlockHeaderContext(header *wire.BlockHeader, prevNode
 	// Reject outdated block versions once a majority of the network
 	// has upgraded.  These were originally voted on by BIP0034,
 	// BIP0065, and BIP0066.
-	params := b.chainParams
-	if header.Version < 2 && blockHeight >= params.BIP0034Height ||
-		header.Version < 3 && blockHeight >= params.BIP0066Height ||
-		header.Version < 4 && blockHeight >= params.BIP0065Height {
-
+	if header.Version < minBlockVersion(blockHeight, b.chainParams) {
 		str := "new blocks with version %d are no longer valid"
 		str = fmt.Sprintf(str, header.Version)
 		return ruleError(ErrBlockVersionTooOld, str)
@@ -787,6 +795,13 @@ func (b *BlockChain) checkBlockHeaderContext(header *wire.BlockHeader, prevNode
 	return nil
 }
 
+// inMainChain returns whether or not the block with the given hash is present
+// in the block index and part of the main chain.
+func (b *BlockChain) inMainChain(hash *chainhash.Hash) bool {
+	node := b.index.LookupNode(hash)
+	return node != nil && b.bestChain.Contains(node)
+}
+
 // checkBlockContext peforms several validation checks on the block which depend
 // on its position within the block chain.
 //
@@ -885,7 +900,8 @@ func (b *BlockChain) checkBlockContext(block *btcutil.Block, prevNode *blockNode
 				str := fmt.Sprintf("block's weight metric is "+
 					"too high - got %v, max %v",
 					blockWeight, MaxBlockWeight)
-				return ruleError(ErrBlockWeightTooHigh, str)
+				return valueRuleError(ErrBlockWeightTooHigh, str,
+					MaxBlockWeight, blockWeight)
 			}
 		}
 	}
@@ -893,6 +909,41 @@ func (b *BlockChain) checkBlockContext(block *btcutil.Block, prevNode *blockNode
 	return nil
 }
 
+// CheckBlockContext re-runs the contextual validation checks for a block which
+// is already part of the main chain against its parent.  This is primarily
+// useful for auditing the integrity of the stored chain, such as is done by the
+// verifychain RPC.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) CheckBlockContext(block *btcutil.Block) error {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	node := b.index.LookupNode(block.Hash())
+	if node == nil || !b.bestChain.Contains(node) {
+		return fmt.Errorf("block %v is not in the main chain",
+			block.Hash())
+	}
+
+	// The genesis block has no context to check against.
+	if node.parent == nil {
+		return nil
+	}
+	return b.checkBlockContext(block, node.parent, BFNone)
+}
+
+// CheckPacketCryptProof validates the PacketCrypt proof of a block which is
+// already part of the main chain.  Nothing is checked when PacketCrypt is not
+// the active proof of work algorithm.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) CheckPacketCryptProof(block *btcutil.Block) error {
+	if globalcfg.GetProofOfWorkAlgorithm() != globalcfg.PowPacketCrypt {
+		return nil
+	}
+	return b.pcCheckProofOfWork(block)
+}
+
 // checkBIP0030 ensures blocks do not contain duplicate transactions which
 // 'overwrite' older transactions that are not fully spent.  This prevents an
 // attack where a coinbase and all of its dependent transactions could be
@@ -978,7 +1029,8 @@ func CheckTransactionInputs(tx *btcutil.Tx, txHeight int32, utxoView *UtxoViewpo
 					"of %v blocks", txIn.PreviousOutPoint,
 					originHeight, txHeight,
 					coinbaseMaturity)
-				return 0, ruleError(ErrImmatureSpend, str)
+				return 0, inputRuleError(ErrImmatureSpend, str,
+					tx.Index(), txInIndex)
 			}
 
 			// Verify that no network steward money is paid out if it is older
@@ -1129,20 +1181,17 @@ func (b *BlockChain) checkConnectBlock(node *blockNode, block *btcutil.Block,
 		return nil, err
 	}
 
-	// BIP0016 describes a pay-to-script-hash type that is considered a
-	// "standard" type.  The rules for this BIP only apply to transactions
-	// after the timestamp defined by txscript.Bip16Activation.  See
-	// https://en.bitcoin.it/wiki/BIP_0016 for more details.
-	enforceBIP0016 := node.timestamp >= txscript.Bip16Activation.Unix()
-
-	// Query for the Version Bits state for the segwit soft-fork
-	// deployment. If segwit is active, we'll switch over to enforcing all
-	// the new rules.
-	segwitState, err := b.deploymentState(node.parent, chaincfg.DeploymentSegwit)
+	// The script flags enforced for the block depend on its header and the
+	// state of the deployments.  The pay-to-script-hash rules of BIP0016
+	// and the segwit rules also change how signature operations are
+	// counted.
+	scriptFlags, err := b.consensusScriptFlags(node.parent,
+		&block.MsgBlock().Header)
 	if err != nil {
 		return nil, err
 	}
-	enforceSegWit := segwitState == ThresholdActive
+	enforceBIP0016 := scriptFlags&txscript.ScriptBip16 != 0
+	enforceSegWit := scriptFlags&txscript.ScriptVerifyWitness != 0
 
 	// The number of signature operations must be less than the maximum
 	// allowed per block.  Note that the preliminary sanity checks on a
@@ -1162,7 +1211,7 @@ func (b *BlockChain) checkConnectBlock(node *blockNode, block *btcutil.Block,
 		sigOpCost, err := GetSigOpCost(tx, i == 0, view, enforceBIP0016,
 			enforceSegWit)
 		if err != nil {
-			return nil, err
+			return nil, withTxIndex(err, i)
 		}
 
 		// Check for overflow or going over the limits.  We have to do
@@ -1173,7 +1222,8 @@ func (b *BlockChain) checkConnectBlock(node *blockNode, block *btcutil.Block,
 			str := fmt.Sprintf("block contains too many "+
 				"signature operations - got %v, max %v",
 				totalSigOpCost, MaxBlockSigOpsCost)
-			return nil, ruleError(ErrTooManySigOps, str)
+			return nil, valueRuleError(ErrTooManySigOps, str,
+				MaxBlockSigOpsCost, totalSigOpCost)
 		}
 	}
 
@@ -1185,11 +1235,11 @@ func (b *BlockChain) checkConnectBlock(node *blockNode, block *btcutil.Block,
 	// against all the inputs when the signature operations are out of
 	// bounds.
 	var totalFees int64
-	for _, tx := range transactions {
+	for i, tx := range transactions {
 		txFee, err := CheckTransactionInputs(tx, node.height, view,
 			b.chainParams)
 		if err != nil {
-			return nil, err
+			return nil, withTxIndex(err, i)
 		}
 
 		// Sum the total fees and ensure we don't overflow the
@@ -1236,7 +1286,8 @@ func (b *BlockChain) checkConnectBlock(node *blockNode, block *btcutil.Block,
 		str := fmt.Sprintf("coinbase transaction for block pays %v "+
 			"which is more than expected value of %v",
 			totalSatoshiOut, expectedSatoshiOut)
-		return nil, ruleError(ErrBadCoinbaseValue, str)
+		return nil, valueRuleError(ErrBadCoinbaseValue, str,
+			expectedSatoshiOut, totalSatoshiOut)
 	}
 
 	// Check that the network steward is being paid 51/256 of the block subsidy
@@ -1271,38 +1322,10 @@ func (b *BlockChain) checkConnectBlock(node *blockNode, block *btcutil.Block,
 		runScripts = false
 	}
 
-	// Blocks created after the BIP0016 activation time need to have the
-	// pay-to-script-hash checks enabled.
-	var scriptFlags txscript.ScriptFlags
-	if enforceBIP0016 {
-		scriptFlags |= txscript.ScriptBip16
-	}
-
-	// Enforce DER signatures for block versions 3+ once the historical
-	// activation threshold has been reached.  This is part of BIP0066.
-	blockHeader := &block.MsgBlock().Header
-	if blockHeader.Version >= 3 && node.height >= b.chainParams.BIP0066Height {
-		scriptFlags |= txscript.ScriptVerifyDERSignatures
-	}
-
-	// Enforce CHECKLOCKTIMEVERIFY for block versions 4+ once the historical
-	// activation threshold has been reached.  This is part of BIP0065.
-	if blockHeader.Version >= 4 && node.height >= b.chainParams.BIP0065Height {
-		scriptFlags |= txscript.ScriptVerifyCheckLockTimeVerify
-	}
-
-	// Enforce CHECKSEQUENCEVERIFY during all block validation checks once
-	// the soft-fork deployment is fully active.
-	csvState, err := b.deploymentState(node.parent, chaincfg.DeploymentCSV)
-	if err != nil {
-		return nil, err
-	}
-	if csvState == ThresholdActive {
-		// If the CSV soft-fork is now active, then modify the
-		// scriptFlags to ensure that the CSV op code is properly
-		// validated during the script checks bleow.
-		scriptFlags |= txscript.ScriptVerifyCheckSequenceVerify
-
+	// Enforce the relative lock-times once the CSV soft-fork deployment is
+	// fully active, which is when the CSV op code is enforced by the script
+	// flags.
+	if scriptFlags&txscript.ScriptVerifyCheckSequenceVerify != 0 {
 		// We obtain the MTP of the *previous* block in order to
 		// determine if transactions in the current block are final.
 		medianTime := node.parent.CalcPastMedianTime()
@@ -1330,20 +1353,15 @@ func (b *BlockChain) checkConnectBlock(node *blockNode, block *btcutil.Block,
 		}
 	}
 
-	// Enforce the segwit soft-fork package once the soft-fork has shifted
-	// into the "active" version bits state.
-	if enforceSegWit {
-		scriptFlags |= txscript.ScriptVerifyWitness
-		scriptFlags |= txscript.ScriptStrictMultiSig
-	}
-
 	// Now that the inexpensive checks are done and have passed, verify the
 	// transactions are actually allowed to spend the coins by running the
 	// expensive ECDSA signature check scripts.  Doing this last helps
 	// prevent CPU exhaustion attacks.
 	if runScripts {
+		start := time.Now()
 		err := checkBlockScripts(block, view, scriptFlags, b.sigCache,
 			b.hashCache)
+		b.validationStats.add(&b.validationStats.stats.Scripts, start)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,127 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/btcjson"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+)
+
+// TestGetBlockCost ensures getblockcost accounts for main chain blocks and for
+// blocks extending the best block and rejects other blocks.
+func TestGetBlockCost(t *testing.T) {
+	tc, teardown := newTestChain(t, "blockcost", nil)
+	defer teardown()
+	params, chain, g := tc.params, tc.chain, tc.gen
+	s := &rpcServer{cfg: rpcserverConfig{Chain: chain}}
+
+	if _, err := g.generate(params.GenesisHash, 3, nil); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+
+	// check ensures the accounting of the block adds up.
+	check := func(block *btcutil.Block, arg string, wantHeight int32) {
+		result, err := handleGetBlockCost(s,
+			btcjson.NewGetBlockCostCmd(&arg), nil)
+		if err != nil {
+			t.Fatalf("getblockcost: %v", err)
+		}
+		cost := result.(*btcjson.GetBlockCostResult)
+		if cost.Hash != block.Hash().String() ||
+			cost.Height != wantHeight {
+
+			t.Fatalf("got block %s at height %d, want %v at %d",
+				cost.Hash, cost.Height, block.Hash(), wantHeight)
+		}
+		msgBlock := block.MsgBlock()
+		if cost.Size != msgBlock.SerializeSize() ||
+			cost.StrippedSize != msgBlock.SerializeSizeStripped() ||
+			cost.Weight != blockchain.GetBlockWeight(block) {
+
+			t.Fatalf("got size %d, stripped size %d and weight %d",
+				cost.Size, cost.StrippedSize, cost.Weight)
+		}
+		if len(cost.Transactions) != len(msgBlock.Transactions) {
+			t.Fatalf("got %d transactions, want %d",
+				len(cost.Transactions), len(msgBlock.Transactions))
+		}
+
+		// The parts of the block add up to its sizes and weight.
+		size := cost.HeaderSize + cost.TxCountSize + cost.ProofSize
+		strippedSize := size
+		weight := int64(size * blockchain.WitnessScaleFactor)
+		sigOpCost := 0
+		for _, tx := range cost.Transactions {
+			size += tx.Size
+			strippedSize += tx.StrippedSize
+			weight += tx.Weight
+			sigOpCost += tx.SigOpCost
+		}
+		if size != cost.Size || strippedSize != cost.StrippedSize ||
+			weight != cost.Weight || sigOpCost != cost.SigOpCost {
+
+			t.Fatalf("parts add up to size %d, stripped size %d, "+
+				"weight %d and sigop cost %d, got %+v", size,
+				strippedSize, weight, sigOpCost, cost)
+		}
+	}
+
+	// Main chain blocks are given by hash.
+	for height := int32(0); height <= 3; height++ {
+		block, err := chain.BlockByHeight(height)
+		if err != nil {
+			t.Fatalf("BlockByHeight: %v", err)
+		}
+		check(block, block.Hash().String(), height)
+	}
+
+	// Blocks extending the best block are given serialized.
+	serialize := func(block *btcutil.Block) string {
+		var buf bytes.Buffer
+		if err := block.MsgBlock().Serialize(&buf); err != nil {
+			t.Fatalf("Serialize: %v", err)
+		}
+		return hex.EncodeToString(buf.Bytes())
+	}
+	best := chain.BestSnapshot()
+	next, err := g.newBlock(&best.Hash, best.Height+1, nil)
+	if err != nil {
+		t.Fatalf("newBlock: %v", err)
+	}
+	check(next, serialize(next), best.Height+1)
+
+	// Other blocks are rejected.
+	stale, err := g.newBlock(params.GenesisHash, 1, nil)
+	if err != nil {
+		t.Fatalf("newBlock: %v", err)
+	}
+	staleHex := serialize(stale)
+	unknownHash := chainhash.Hash{0x01}.String()
+	tests := []struct {
+		arg  string
+		code btcjson.RPCErrorCode
+	}{
+		{staleHex, btcjson.ErrRPCVerify},
+		{unknownHash, btcjson.ErrRPCBlockNotFound},
+		{"zz", btcjson.ErrRPCDecodeHexString},
+		{"00", btcjson.ErrRPCDeserialization},
+	}
+	for _, test := range tests {
+		arg := test.arg
+		_, err := handleGetBlockCost(s, btcjson.NewGetBlockCostCmd(&arg), nil)
+		if rpcErr, ok := err.(*btcjson.RPCError); !ok ||
+			rpcErr.Code != test.code {
+
+			t.Fatalf("getblockcost %.16s: got error %v, want code %d",
+				arg, err, test.code)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/btcjson"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/mining"
+)
+
+// TestSetBlockLimits ensures setblocklimits only changes the limits passed,
+// keeps the data carrier share of the block weight and rejects inconsistent
+// limits without changing the mining policy.
+func TestSetBlockLimits(t *testing.T) {
+	// The log rotator is not initialized in tests.
+	setLogLevels("off")
+	defer setLogLevels(defaultLogLevel)
+
+	defer func(c *config) { cfg = c }(cfg)
+	cfg = &config{DataCarrierShare: 10}
+
+	policy := mining.Policy{
+		BlockMaxWeight:            3000000,
+		BlockMaxSigOpCost:         blockchain.MaxBlockSigOpsCost,
+		BlockPrioritySize:         50000,
+		BlockMaxDataCarrierWeight: 300000,
+	}
+	generator := mining.NewBlkTmplGenerator(&policy,
+		&chaincfg.RegressionNetParams, nil, nil, nil, nil, nil)
+	s := &rpcServer{cfg: rpcserverConfig{Generator: generator}}
+
+	setBlockLimits := func(maxWeight *uint32, maxSigOpCost *int64,
+		reservedWeight, prioritySize *uint32) (*btcjson.BlockLimitsResult, error) {
+
+		cmd := btcjson.NewSetBlockLimitsCmd(&btcjson.BlockLimits{
+			MaxWeight:      maxWeight,
+			MaxSigOpCost:   maxSigOpCost,
+			ReservedWeight: reservedWeight,
+			PrioritySize:   prioritySize,
+		})
+		result, err := handleSetBlockLimits(s, cmd, nil)
+		if err != nil {
+			return nil, err
+		}
+		return result.(*btcjson.BlockLimitsResult), nil
+	}
+
+	want := btcjson.BlockLimitsResult{
+		MaxWeight:            2000000,
+		MaxSigOpCost:         blockchain.MaxBlockSigOpsCost,
+		ReservedWeight:       100000,
+		PrioritySize:         50000,
+		MaxDataCarrierWeight: 200000,
+	}
+	result, err := setBlockLimits(btcjson.Uint32(2000000), nil,
+		btcjson.Uint32(100000), nil)
+	if err != nil {
+		t.Fatalf("setblocklimits: %v", err)
+	}
+	if *result != want {
+		t.Fatalf("got limits %+v, want %+v", result, want)
+	}
+
+	// Invalid limits leave the policy unchanged.
+	invalid := []struct {
+		name           string
+		maxWeight      *uint32
+		maxSigOpCost   *int64
+		reservedWeight *uint32
+		prioritySize   *uint32
+	}{
+		{name: "max weight", maxWeight: btcjson.Uint32(1000)},
+		{name: "sigop cost", maxSigOpCost: btcjson.Int64(0)},
+		{name: "reserved weight", reservedWeight: btcjson.Uint32(2000001)},
+		{
+			name:      "max weight below reserved weight",
+			maxWeight: btcjson.Uint32(50000),
+		},
+		{name: "priority size", prioritySize: btcjson.Uint32(3000000)},
+	}
+	for _, test := range invalid {
+		_, err := setBlockLimits(test.maxWeight, test.maxSigOpCost,
+			test.reservedWeight, test.prioritySize)
+		if rpcErr, ok := err.(*btcjson.RPCError); !ok ||
+			rpcErr.Code != btcjson.ErrRPCInvalidParameter {
+
+			t.Errorf("%s: unexpected error %v", test.name, err)
+		}
+	}
+	if got := *blockLimitsResult(&policy); got != want {
+		t.Fatalf("got limits %+v after invalid changes, want %+v", got,
+			want)
+	}
+}
@@ -14,9 +14,13 @@ import (
 	"runtime"
 	"runtime/debug"
 	"runtime/pprof"
+	"strings"
 
 	"github.com/pkt-cash/pktd/blockchain/indexers"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/pcutil"
+	"github.com/pkt-cash/pktd/chaincfg"
 	"github.com/pkt-cash/pktd/database"
+	"github.com/pkt-cash/pktd/database/ffldb"
 	"github.com/pkt-cash/pktd/limits"
 )
 
@@ -62,6 +66,28 @@ func pktdMain(serverChan chan<- *server) error {
 
 	// Show version at startup.
 	pktdLog.Infof("Version %s", version())
+	build := readBuildInfo()
+	if build.commit != "" {
+		pktdLog.Infof("Built from commit %s with %s", build.commit,
+			build.goVersion)
+	}
+	for _, m := range build.modules {
+		pktdLog.Debugf("Consensus module %s %s %s", m.path, m.version,
+			m.sum)
+	}
+
+	// Refuse to run consensus code other than the intended one.
+	if cfg.BuildManifest != "" {
+		if err := verifyBuildManifest(build, cfg.BuildManifest); err != nil {
+			pktdLog.Errorf("Build verification failed: %v", err)
+			return err
+		}
+		pktdLog.Infof("Consensus modules verified against %s",
+			cfg.BuildManifest)
+	}
+	if accel := pcutil.HashAcceleration(); len(accel) > 0 {
+		pktdLog.Debugf("Hash acceleration: %s", strings.Join(accel, " "))
+	}
 
 	// Enable http profiling server if requested.
 	if cfg.Profile != "" {
@@ -98,6 +124,31 @@ func pktdMain(serverChan chan<- *server) error {
 		return nil
 	}
 
+	// Replay a consensus recording and exit if requested.  The replay uses
+	// a temporary database, so the block database is not loaded.
+	if cfg.ReplayConsensus != "" {
+		var checkpoints []chaincfg.Checkpoint
+		if !cfg.DisableCheckpoints {
+			checkpoints = mergeCheckpoints(
+				activeNetParams.Params.Checkpoints,
+				cfg.addCheckpoints)
+		}
+		err := replayConsensus(&consensusReplayConfig{
+			Path:            cfg.ReplayConsensus,
+			ChainParams:     activeNetParams.Params,
+			DbType:          cfg.DbType,
+			Checkpoints:     checkpoints,
+			SigCacheMaxSize: cfg.SigCacheMaxSize,
+			Interrupt:       interrupt,
+		})
+		if err != nil {
+			pktdLog.Errorf("%v", err)
+			return err
+		}
+
+		return nil
+	}
+
 	// Load the block database.
 	db, err := loadBlockDB()
 	if err != nil {
@@ -143,6 +194,30 @@ func pktdMain(serverChan chan<- *server) error {
 
 		return nil
 	}
+	if cfg.DropUtxoStatsIndex {
+		if err := indexers.DropUtxoStatsIndex(db, interrupt); err != nil {
+			pktdLog.Errorf("%v", err)
+			return err
+		}
+
+		return nil
+	}
+	if cfg.DropFeeStatsIndex {
+		if err := indexers.DropFeeStatsIndex(db, interrupt); err != nil {
+			pktdLog.Errorf("%v", err)
+			return err
+		}
+
+		return nil
+	}
+	if cfg.DropUtreexo {
+		if err := indexers.DropUtreexoIndex(db, interrupt); err != nil {
+			pktdLog.Errorf("%v", err)
+			return err
+		}
+
+		return nil
+	}
 
 	// Create server and start it.
 	server, err := newServer(cfg.Listeners, cfg.AgentBlacklist,
@@ -248,6 +323,21 @@ func warnMultipleDBs() {
 // contains additional logic such warning the user if there are multiple
 // databases which consume space on the file system and ensuring the regression
 // test database is clean when in regression test mode.
+// blockDbOptions returns the tuning options of the block database from the
+// configuration.
+func blockDbOptions() *ffldb.Options {
+	const mib = 1024 * 1024
+	return &ffldb.Options{
+		CacheSize:          uint64(cfg.DbCache) * mib,
+		FlushInterval:      cfg.DbFlushInterval,
+		FlushBlocks:        int(cfg.DbFlushBlocks),
+		WriteBuffer:        int(cfg.DbWriteBuffer) * mib,
+		BlockCacheCapacity: int(cfg.DbBlockCache) * mib,
+		MmapReads:          cfg.DbMmap,
+		Readahead:          int(cfg.DbReadahead) * 1024,
+	}
+}
+
 func loadBlockDB() (database.DB, error) {
 	// The memdb backend does not have a file path associated with it, so
 	// handle it uniquely.  We also don't want to worry about the multiple
@@ -266,12 +356,28 @@ func loadBlockDB() (database.DB, error) {
 	// The database name is based on the database type.
 	dbPath := blockDbPath(cfg.DbType)
 
+	// A replica only ever opens an existing database and never modifies
+	// it, so this is done before the regression test database is removed.
+	if cfg.Replica {
+		pktdLog.Infof("Loading read-only block database from '%s'", dbPath)
+		db, err := database.Open(cfg.DbType, dbPath, activeNetParams.Net,
+			true, blockDbOptions())
+		if err != nil {
+			return nil, fmt.Errorf("unable to open the block database "+
+				"read-only, it must exist and not be in use by "+
+				"another process: %v", err)
+		}
+		pktdLog.Info("Block database loaded")
+		return db, nil
+	}
+
 	// The regression test is special in that it needs a clean database for
 	// each run, so remove it now if it already exists.
 	removeRegressionDB(dbPath)
 
 	pktdLog.Infof("Loading block database from '%s'", dbPath)
-	db, err := database.Open(cfg.DbType, dbPath, activeNetParams.Net)
+	db, err := database.Open(cfg.DbType, dbPath, activeNetParams.Net,
+		blockDbOptions())
 	if err != nil {
 		// Return the error if it's not because the database doesn't
 		// exist.
@@ -286,7 +392,8 @@ func loadBlockDB() (database.DB, error) {
 		if err != nil {
 			return nil, err
 		}
-		db, err = database.Create(cfg.DbType, dbPath, activeNetParams.Net)
+		db, err = database.Create(cfg.DbType, dbPath, activeNetParams.Net,
+			blockDbOptions())
 		if err != nil {
 			return nil, err
 		}
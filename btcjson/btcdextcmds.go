@@ -44,6 +44,97 @@ func NewNodeCmd(subCmd NodeSubCmd, target string, connectSubCmd *string) *NodeCm
 	}
 }
 
+// CaptureMessagesSubCmd defines the type used in the capturemessages JSON-RPC
+// command for the sub command field.
+type CaptureMessagesSubCmd string
+
+const (
+	// CaptureStart starts capturing the messages exchanged with the peers,
+	// or restarts it with the given redaction options.
+	CaptureStart CaptureMessagesSubCmd = "start"
+
+	// CaptureStop stops capturing the messages and closes the capture
+	// files.
+	CaptureStop CaptureMessagesSubCmd = "stop"
+
+	// CaptureStatus only reports the state of the message capture.
+	CaptureStatus CaptureMessagesSubCmd = "status"
+)
+
+// CaptureMessagesCmd defines the capturemessages JSON-RPC command.  This
+// command is not a standard Bitcoin command.  It is an extension for pktd.
+type CaptureMessagesCmd struct {
+	SubCmd CaptureMessagesSubCmd `jsonrpcusage:"\"start|stop|status\""`
+	Redact *[]string
+}
+
+// NewCaptureMessagesCmd returns a new instance which can be used to issue a
+// capturemessages JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewCaptureMessagesCmd(subCmd CaptureMessagesSubCmd, redact *[]string) *CaptureMessagesCmd {
+	return &CaptureMessagesCmd{
+		SubCmd: subCmd,
+		Redact: redact,
+	}
+}
+
+// ListenersSubCmd defines the type used in the listeners JSON-RPC command for
+// the sub command field.
+type ListenersSubCmd string
+
+const (
+	// ListenerAdd starts listening for peer connections at an address.
+	ListenerAdd ListenersSubCmd = "add"
+
+	// ListenerRemove stops listening for peer connections at an address.
+	ListenerRemove ListenersSubCmd = "remove"
+
+	// ListenerList only lists the listeners.
+	ListenerList ListenersSubCmd = "list"
+)
+
+// ListenersCmd defines the listeners JSON-RPC command.  This command is not a
+// standard Bitcoin command.  It is an extension for pktd.
+type ListenersCmd struct {
+	SubCmd ListenersSubCmd `jsonrpcusage:"\"add|remove|list\""`
+	Addr   *string
+}
+
+// NewListenersCmd returns a new instance which can be used to issue a
+// listeners JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewListenersCmd(subCmd ListenersSubCmd, addr *string) *ListenersCmd {
+	return &ListenersCmd{
+		SubCmd: subCmd,
+		Addr:   addr,
+	}
+}
+
+// CaptureProfileCmd defines the captureprofile JSON-RPC command.  This command
+// is not a standard Bitcoin command.  It is an extension for pktd.
+type CaptureProfileCmd struct {
+	Profile string
+	Seconds *int32 `jsonrpcdefault:"30"`
+	File    *string
+}
+
+// NewCaptureProfileCmd returns a new instance which can be used to issue a
+// captureprofile JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewCaptureProfileCmd(profile string, seconds *int32, file *string) *CaptureProfileCmd {
+	return &CaptureProfileCmd{
+		Profile: profile,
+		Seconds: seconds,
+		File:    file,
+	}
+}
+
 // DebugLevelCmd defines the debuglevel JSON-RPC command.  This command is not a
 // standard Bitcoin command.  It is an extension for pktd.
 type DebugLevelCmd struct {
@@ -72,6 +163,44 @@ func NewGenerateCmd(numBlocks uint32) *GenerateCmd {
 	}
 }
 
+// GenerateForkCmd defines the generatefork JSON-RPC command.  This command is
+// not a standard Bitcoin command.  It is an extension for pktd.
+type GenerateForkCmd struct {
+	ForkHash  string
+	NumBlocks uint32
+	Address   *string
+}
+
+// NewGenerateForkCmd returns a new instance which can be used to issue a
+// generatefork JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGenerateForkCmd(forkHash string, numBlocks uint32, address *string) *GenerateForkCmd {
+	return &GenerateForkCmd{
+		ForkHash:  forkHash,
+		NumBlocks: numBlocks,
+		Address:   address,
+	}
+}
+
+// GetAuditLogCmd defines the getauditlog JSON-RPC command.  This command is
+// not a standard Bitcoin command.  It is an extension for pktd.
+type GetAuditLogCmd struct {
+	Count *int32 `jsonrpcdefault:"100"`
+}
+
+// NewGetAuditLogCmd returns a new instance which can be used to issue a
+// getauditlog JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetAuditLogCmd(count *int32) *GetAuditLogCmd {
+	return &GetAuditLogCmd{
+		Count: count,
+	}
+}
+
 // GetBestBlockCmd defines the getbestblock JSON-RPC command.
 type GetBestBlockCmd struct{}
 
@@ -81,6 +210,26 @@ func NewGetBestBlockCmd() *GetBestBlockCmd {
 	return &GetBestBlockCmd{}
 }
 
+// GetColdSpendsCmd defines the getcoldspends JSON-RPC command.  It returns the
+// transactions seen spending from the cold storage addresses watched by the
+// node and whether the alarm they raised is still up, which is cleared when
+// Acknowledge is true.  This command is not a standard Bitcoin command.  It is
+// an extension for pktd.
+type GetColdSpendsCmd struct {
+	Acknowledge *bool `jsonrpcdefault:"false"`
+}
+
+// NewGetColdSpendsCmd returns a new instance which can be used to issue a
+// getcoldspends JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetColdSpendsCmd(acknowledge *bool) *GetColdSpendsCmd {
+	return &GetColdSpendsCmd{
+		Acknowledge: acknowledge,
+	}
+}
+
 // GetCurrentNetCmd defines the getcurrentnet JSON-RPC command.
 type GetCurrentNetCmd struct{}
 
@@ -90,6 +239,492 @@ func NewGetCurrentNetCmd() *GetCurrentNetCmd {
 	return &GetCurrentNetCmd{}
 }
 
+// GetDataCarrierInfoCmd defines the getdatacarrierinfo JSON-RPC command.
+type GetDataCarrierInfoCmd struct{}
+
+// NewGetDataCarrierInfoCmd returns a new instance which can be used to issue a
+// getdatacarrierinfo JSON-RPC command.
+func NewGetDataCarrierInfoCmd() *GetDataCarrierInfoCmd {
+	return &GetDataCarrierInfoCmd{}
+}
+
+// GetMemoryInfoCmd defines the getmemoryinfo JSON-RPC command.
+type GetMemoryInfoCmd struct{}
+
+// NewGetMemoryInfoCmd returns a new instance which can be used to issue a
+// getmemoryinfo JSON-RPC command.
+func NewGetMemoryInfoCmd() *GetMemoryInfoCmd {
+	return &GetMemoryInfoCmd{}
+}
+
+// GetClockSkewCmd defines the getclockskew JSON-RPC command.  This command is
+// not a standard Bitcoin command.  It is an extension for pktd.
+type GetClockSkewCmd struct{}
+
+// NewGetClockSkewCmd returns a new instance which can be used to issue a
+// getclockskew JSON-RPC command.
+func NewGetClockSkewCmd() *GetClockSkewCmd {
+	return &GetClockSkewCmd{}
+}
+
+// GetPartitionStatusCmd defines the getpartitionstatus JSON-RPC command.  This
+// command is not a standard Bitcoin command.  It is an extension for pktd.
+type GetPartitionStatusCmd struct{}
+
+// NewGetPartitionStatusCmd returns a new instance which can be used to issue a
+// getpartitionstatus JSON-RPC command.
+func NewGetPartitionStatusCmd() *GetPartitionStatusCmd {
+	return &GetPartitionStatusCmd{}
+}
+
+// GetDiskStatusCmd defines the getdiskstatus JSON-RPC command.  It returns the
+// free space on the data directory and whether the node is in safe mode
+// because it runs out of it.  This command is not a standard Bitcoin command.
+// It is an extension for pktd.
+type GetDiskStatusCmd struct{}
+
+// NewGetDiskStatusCmd returns a new instance which can be used to issue a
+// getdiskstatus JSON-RPC command.
+func NewGetDiskStatusCmd() *GetDiskStatusCmd {
+	return &GetDiskStatusCmd{}
+}
+
+// GetOrphanBlocksCmd defines the getorphanblocks JSON-RPC command.  It returns
+// the recently seen blocks which are not part of the main chain, such as the
+// orphans and the blocks of losing side chains.  This command is not a standard
+// Bitcoin command.  It is an extension for pktd.
+type GetOrphanBlocksCmd struct{}
+
+// NewGetOrphanBlocksCmd returns a new instance which can be used to issue a
+// getorphanblocks JSON-RPC command.
+func NewGetOrphanBlocksCmd() *GetOrphanBlocksCmd {
+	return &GetOrphanBlocksCmd{}
+}
+
+// GetOrphanTxsCmd defines the getorphantxs JSON-RPC command.  It returns the
+// transactions of the orphan pool, as transaction hashes with a Verbosity of
+// 0, as objects describing them with 1 and with the serialized transactions
+// added with 2.  This command is not a standard Bitcoin command.  It is an
+// extension for pktd.
+type GetOrphanTxsCmd struct {
+	Verbosity *int `jsonrpcdefault:"0"`
+}
+
+// NewGetOrphanTxsCmd returns a new instance which can be used to issue a
+// getorphantxs JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetOrphanTxsCmd(verbosity *int) *GetOrphanTxsCmd {
+	return &GetOrphanTxsCmd{
+		Verbosity: verbosity,
+	}
+}
+
+// GetRejectedTxsCmd defines the getrejectedtxs JSON-RPC command.  It returns
+// the transactions relayed by peers which were recently rejected, with the
+// reasons they were rejected.  This command is not a standard Bitcoin command.
+// It is an extension for pktd.
+type GetRejectedTxsCmd struct{}
+
+// NewGetRejectedTxsCmd returns a new instance which can be used to issue a
+// getrejectedtxs JSON-RPC command.
+func NewGetRejectedTxsCmd() *GetRejectedTxsCmd {
+	return &GetRejectedTxsCmd{}
+}
+
+// GetMempoolDeltaCmd defines the getmempooldelta JSON-RPC command.  It returns
+// the transactions added to and removed from the mempool since the state with
+// the passed sequence number, or the whole mempool when Sequence is zero.
+// This command is not a standard Bitcoin command.  It is an extension for
+// pktd.
+type GetMempoolDeltaCmd struct {
+	Sequence *uint64 `jsonrpcdefault:"0"`
+}
+
+// NewGetMempoolDeltaCmd returns a new instance which can be used to issue a
+// getmempooldelta JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetMempoolDeltaCmd(sequence *uint64) *GetMempoolDeltaCmd {
+	return &GetMempoolDeltaCmd{
+		Sequence: sequence,
+	}
+}
+
+// GetSyncStatusCmd defines the getsyncstatus JSON-RPC command.  It returns the
+// progress of the sync with the chain of the network, with an estimate of the
+// time remaining and the time spent in each stage.  This command is not a
+// standard Bitcoin command.  It is an extension for pktd.
+type GetSyncStatusCmd struct{}
+
+// NewGetSyncStatusCmd returns a new instance which can be used to issue a
+// getsyncstatus JSON-RPC command.
+func NewGetSyncStatusCmd() *GetSyncStatusCmd {
+	return &GetSyncStatusCmd{}
+}
+
+// CreateDepositAddressesCmd defines the createdepositaddresses JSON-RPC
+// command.  It returns the deposit addresses bound to the reference IDs, in
+// the same order, binding the next unused addresses of the deposit account to
+// the new ones.  This command is not a standard Bitcoin command.  It is an
+// extension for pktd.
+type CreateDepositAddressesCmd struct {
+	Refs []string
+}
+
+// NewCreateDepositAddressesCmd returns a new instance which can be used to
+// issue a createdepositaddresses JSON-RPC command.
+func NewCreateDepositAddressesCmd(refs []string) *CreateDepositAddressesCmd {
+	return &CreateDepositAddressesCmd{
+		Refs: refs,
+	}
+}
+
+// ListDepositsCmd defines the listdeposits JSON-RPC command.  It returns the
+// deposits to the address bound to Ref, or to every deposit address when it is
+// not set, in the order they were mined.  This command is not a standard
+// Bitcoin command.  It is an extension for pktd.
+type ListDepositsCmd struct {
+	Ref *string
+}
+
+// NewListDepositsCmd returns a new instance which can be used to issue a
+// listdeposits JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewListDepositsCmd(ref *string) *ListDepositsCmd {
+	return &ListDepositsCmd{
+		Ref: ref,
+	}
+}
+
+// MintTokenCmd defines the minttoken JSON-RPC command.  It mints a capability
+// token authenticating RPC clients with the passed scope, one of readonly, send
+// or admin.  Expiry is the lifetime of the token in seconds, which never
+// expires when it is 0, MaxSend limits the total output value in PKT of the
+// transactions relayed with it and Methods restricts it to the listed
+// commands.  This command is not a standard Bitcoin command.  It is an
+// extension for pktd.
+type MintTokenCmd struct {
+	Scope   string
+	Expiry  *int64 `jsonrpcdefault:"0"`
+	MaxSend *float64
+	Methods *[]string
+}
+
+// NewMintTokenCmd returns a new instance which can be used to issue a
+// minttoken JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewMintTokenCmd(scope string, expiry *int64, maxSend *float64,
+	methods *[]string) *MintTokenCmd {
+
+	return &MintTokenCmd{
+		Scope:   scope,
+		Expiry:  expiry,
+		MaxSend: maxSend,
+		Methods: methods,
+	}
+}
+
+// GetBlockCostCmd defines the getblockcost JSON-RPC command.  It returns the
+// consensus accounting of a block, the sizes, weight and signature operations
+// checked against the limits of the chain, for each transaction of the block.
+// Block is either the hash of a main chain block or a hex-encoded serialized
+// block extending the best block, and the current block template is accounted
+// for when it is not set.  This command is not a standard Bitcoin command.  It
+// is an extension for pktd.
+type GetBlockCostCmd struct {
+	Block *string
+}
+
+// NewGetBlockCostCmd returns a new instance which can be used to issue a
+// getblockcost JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetBlockCostCmd(block *string) *GetBlockCostCmd {
+	return &GetBlockCostCmd{
+		Block: block,
+	}
+}
+
+// GetConsensusRulesCmd defines the getconsensusrules JSON-RPC command.  It
+// returns the consensus rules in effect for the main chain block at Height, or
+// for the block following the best block when it is -1.  This command is not a
+// standard Bitcoin command.  It is an extension for pktd.
+type GetConsensusRulesCmd struct {
+	Height *int32 `jsonrpcdefault:"-1"`
+}
+
+// NewGetConsensusRulesCmd returns a new instance which can be used to issue a
+// getconsensusrules JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetConsensusRulesCmd(height *int32) *GetConsensusRulesCmd {
+	return &GetConsensusRulesCmd{
+		Height: height,
+	}
+}
+
+// GetBlockTemplateLightCmd defines the getblocktemplatelight JSON-RPC command.
+// It returns the block template getblocktemplate would return with a coinbase
+// value, without the data of the transactions the caller already knows.  When
+// LongPollID is set, the reply waits for the block template it identifies to
+// become stale, as a long poll request of getblocktemplate does, and only
+// holds the transactions which were not in it.  This command is not a standard
+// Bitcoin command.  It is an extension for pktd.
+type GetBlockTemplateLightCmd struct {
+	LongPollID *string
+}
+
+// NewGetBlockTemplateLightCmd returns a new instance which can be used to
+// issue a getblocktemplatelight JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetBlockTemplateLightCmd(longPollID *string) *GetBlockTemplateLightCmd {
+	return &GetBlockTemplateLightCmd{
+		LongPollID: longPollID,
+	}
+}
+
+// GetTxFeeCmd defines the gettxfee JSON-RPC command.  It returns the exact fee
+// paid by a mempool or confirmed transaction, computed from the outputs it
+// spends even when they are not known to the caller.  Confirmed transactions
+// require the transaction index.  This command is not a standard Bitcoin
+// command.  It is an extension for pktd.
+type GetTxFeeCmd struct {
+	TxID string
+}
+
+// NewGetTxFeeCmd returns a new instance which can be used to issue a gettxfee
+// JSON-RPC command.
+func NewGetTxFeeCmd(txID string) *GetTxFeeCmd {
+	return &GetTxFeeCmd{
+		TxID: txID,
+	}
+}
+
+// GetUtxoStatsCmd defines the getutxostats JSON-RPC command.  It returns the
+// statistics of the unspent outputs by script class at the best block, or at
+// the latest snapshot at or below Height when it is set.  This command is not
+// a standard Bitcoin command.  It is an extension for pktd.
+type GetUtxoStatsCmd struct {
+	Height *int32
+}
+
+// NewGetUtxoStatsCmd returns a new instance which can be used to issue a
+// getutxostats JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetUtxoStatsCmd(height *int32) *GetUtxoStatsCmd {
+	return &GetUtxoStatsCmd{
+		Height: height,
+	}
+}
+
+// GetNetworkHashrateCmd defines the getnetworkhashrate JSON-RPC command.  It
+// estimates the PacketCrypt block mining and announcement hash rates of the
+// network over the Blocks blocks ending at Height, the best block when it is
+// -1.  This command is not a standard Bitcoin command.  It is an extension for
+// pktd.
+type GetNetworkHashrateCmd struct {
+	Blocks *int32 `jsonrpcdefault:"120"`
+	Height *int32 `jsonrpcdefault:"-1"`
+}
+
+// NewGetNetworkHashrateCmd returns a new instance which can be used to issue a
+// getnetworkhashrate JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetNetworkHashrateCmd(blocks, height *int32) *GetNetworkHashrateCmd {
+	return &GetNetworkHashrateCmd{
+		Blocks: blocks,
+		Height: height,
+	}
+}
+
+// GetAnnProofCmd defines the getannproof JSON-RPC command.  It returns a proof
+// that the announcement at Position, from 0 to 3, among the announcements of
+// the PacketCrypt proof of the main chain block BlockHash is included in the
+// announcement tree committed to by the block.  This command is not a standard
+// Bitcoin command.  It is an extension for pktd.
+type GetAnnProofCmd struct {
+	BlockHash string
+	Position  int32
+}
+
+// NewGetAnnProofCmd returns a new instance which can be used to issue a
+// getannproof JSON-RPC command.
+func NewGetAnnProofCmd(blockHash string, position int32) *GetAnnProofCmd {
+	return &GetAnnProofCmd{
+		BlockHash: blockHash,
+		Position:  position,
+	}
+}
+
+// GetAnnAgingScheduleCmd defines the getannagingschedule JSON-RPC command.  It
+// returns the aged targets of an announcement mined at Target, a compact target
+// in hex, for each age up to MaxAge when mined in the block following the best
+// block.  Target defaults to the minimum announcement target of the best block.
+// This command is not a standard Bitcoin command.  It is an extension for
+// pktd.
+type GetAnnAgingScheduleCmd struct {
+	Target *string
+	MaxAge *int32 `jsonrpcdefault:"20"`
+}
+
+// NewGetAnnAgingScheduleCmd returns a new instance which can be used to issue
+// a getannagingschedule JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetAnnAgingScheduleCmd(target *string, maxAge *int32) *GetAnnAgingScheduleCmd {
+	return &GetAnnAgingScheduleCmd{
+		Target: target,
+		MaxAge: maxAge,
+	}
+}
+
+// GetMiningAnalyticsCmd defines the getmininganalytics JSON-RPC command.  It
+// summarizes how the announcement and block mining work composed the effective
+// targets of the Blocks blocks ending at Height, the best block when it is -1.
+// This command is not a standard Bitcoin command.  It is an extension for
+// pktd.
+type GetMiningAnalyticsCmd struct {
+	Blocks *int32 `jsonrpcdefault:"120"`
+	Height *int32 `jsonrpcdefault:"-1"`
+}
+
+// NewGetMiningAnalyticsCmd returns a new instance which can be used to issue a
+// getmininganalytics JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetMiningAnalyticsCmd(blocks, height *int32) *GetMiningAnalyticsCmd {
+	return &GetMiningAnalyticsCmd{
+		Blocks: blocks,
+		Height: height,
+	}
+}
+
+// GetDifficultyHistoryCmd defines the getdifficultyhistory JSON-RPC command.
+// It returns the block and announcement difficulties of Count blocks, every
+// Interval blocks back from Height, the best block when it is -1.  This
+// command is not a standard Bitcoin command.  It is an extension for pktd.
+type GetDifficultyHistoryCmd struct {
+	Count    *int32 `jsonrpcdefault:"100"`
+	Height   *int32 `jsonrpcdefault:"-1"`
+	Interval *int32 `jsonrpcdefault:"1"`
+}
+
+// NewGetDifficultyHistoryCmd returns a new instance which can be used to issue
+// a getdifficultyhistory JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetDifficultyHistoryCmd(count, height, interval *int32) *GetDifficultyHistoryCmd {
+	return &GetDifficultyHistoryCmd{
+		Count:    count,
+		Height:   height,
+		Interval: interval,
+	}
+}
+
+// GetUtxoDeltasCmd defines the getutxodeltas JSON-RPC command.  It returns the
+// outputs created and spent by up to Count blocks of the main chain from
+// StartHeight, so indexers can catch up with the UTXO set changes.  This
+// command is not a standard Bitcoin command.  It is an extension for pktd.
+type GetUtxoDeltasCmd struct {
+	StartHeight int32
+	Count       *int32 `jsonrpcdefault:"100"`
+}
+
+// NewGetUtxoDeltasCmd returns a new instance which can be used to issue a
+// getutxodeltas JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetUtxoDeltasCmd(startHeight int32, count *int32) *GetUtxoDeltasCmd {
+	return &GetUtxoDeltasCmd{
+		StartHeight: startHeight,
+		Count:       count,
+	}
+}
+
+// GetBlockHeadersCmd defines the getblockheaders JSON-RPC command.  It returns
+// up to Count contiguous headers of the main chain from StartHeight, either
+// serialized as a single hex string or, when Verbose is set, as JSON objects.
+// This command is not a standard Bitcoin command.  It is an extension for
+// pktd.
+type GetBlockHeadersCmd struct {
+	StartHeight int32
+	Count       *int32 `jsonrpcdefault:"2000"`
+	Verbose     *bool  `jsonrpcdefault:"false"`
+}
+
+// NewGetBlockHeadersCmd returns a new instance which can be used to issue a
+// getblockheaders JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetBlockHeadersCmd(startHeight int32, count *int32, verbose *bool) *GetBlockHeadersCmd {
+	return &GetBlockHeadersCmd{
+		StartHeight: startHeight,
+		Count:       count,
+		Verbose:     verbose,
+	}
+}
+
+// GetBlockUndoCmd defines the getblockundo JSON-RPC command.  It returns the
+// undo data of a block of the main chain, the outputs which must be removed
+// from and restored to the UTXO set to bring it back to its state before the
+// block was connected.  This command is not a standard Bitcoin command.  It is
+// an extension for pktd.
+type GetBlockUndoCmd struct {
+	Hash string
+}
+
+// NewGetBlockUndoCmd returns a new instance which can be used to issue a
+// getblockundo JSON-RPC command.
+func NewGetBlockUndoCmd(hash string) *GetBlockUndoCmd {
+	return &GetBlockUndoCmd{
+		Hash: hash,
+	}
+}
+
+// GetFeeHistoryCmd defines the getfeehistory JSON-RPC command.  It returns
+// the fee statistics of the main chain blocks from Start to End, which are
+// heights, or timestamps in seconds since 1 Jan 1970 GMT when ByTime is set.
+// End defaults to the best block.  This command is not a standard Bitcoin
+// command.  It is an extension for pktd.
+type GetFeeHistoryCmd struct {
+	Start  int64
+	End    *int64
+	ByTime *bool `jsonrpcdefault:"false"`
+}
+
+// NewGetFeeHistoryCmd returns a new instance which can be used to issue a
+// getfeehistory JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetFeeHistoryCmd(start int64, end *int64, byTime *bool) *GetFeeHistoryCmd {
+	return &GetFeeHistoryCmd{
+		Start:  start,
+		End:    end,
+		ByTime: byTime,
+	}
+}
+
 // GetHeadersCmd defines the getheaders JSON-RPC command.
 //
 // NOTE: This is a btcsuite extension ported from
@@ -111,6 +746,99 @@ func NewGetHeadersCmd(blockLocators []string, hashStop string) *GetHeadersCmd {
 	}
 }
 
+// VerifyAddressOwnershipCmd defines the verifyaddressownership JSON-RPC
+// command.  It verifies a proof created by the proveaddressownership wallet
+// command against the outputs which were unspent at Height, the best block
+// height when it is not set.  This command is not a standard Bitcoin command.
+// It is an extension for pktd.
+type VerifyAddressOwnershipCmd struct {
+	Proof   string
+	Message string
+	Height  *int32
+}
+
+// NewVerifyAddressOwnershipCmd returns a new instance which can be used to
+// issue a verifyaddressownership JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewVerifyAddressOwnershipCmd(proof, message string, height *int32) *VerifyAddressOwnershipCmd {
+	return &VerifyAddressOwnershipCmd{
+		Proof:   proof,
+		Message: message,
+		Height:  height,
+	}
+}
+
+// BlockLimits holds the limits used to assemble block templates changed by
+// the setblocklimits command.  The limits left nil are not changed.
+// ReservedWeight is the part of MaxWeight only used by the transactions
+// submitted to the node.
+type BlockLimits struct {
+	MaxWeight      *uint32 `json:"maxweight,omitempty"`
+	MaxSigOpCost   *int64  `json:"maxsigopcost,omitempty"`
+	ReservedWeight *uint32 `json:"reservedweight,omitempty"`
+	PrioritySize   *uint32 `json:"prioritysize,omitempty"`
+}
+
+// SetBlockLimitsCmd defines the setblocklimits JSON-RPC command.  It changes
+// the limits used to assemble block templates, leaving the ones not passed
+// unchanged.  The limits are passed as an object rather than as positional
+// parameters so any of them may be omitted.  This command is not a standard
+// Bitcoin command.  It is an extension for pktd.
+type SetBlockLimitsCmd struct {
+	Limits *BlockLimits `jsonrpcusage:"{\"maxweight\":n,\"maxsigopcost\":n,\"reservedweight\":n,\"prioritysize\":n}"`
+}
+
+// NewSetBlockLimitsCmd returns a new instance which can be used to issue a
+// setblocklimits JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will leave all the limits unchanged.
+func NewSetBlockLimitsCmd(limits *BlockLimits) *SetBlockLimitsCmd {
+	return &SetBlockLimitsCmd{
+		Limits: limits,
+	}
+}
+
+// SimulateReorgCmd defines the simulatereorg JSON-RPC command.  This command is
+// not a standard Bitcoin command.  It is an extension for pktd.
+type SimulateReorgCmd struct {
+	Depth     uint32
+	NumBlocks *uint32
+	Address   *string
+}
+
+// NewSimulateReorgCmd returns a new instance which can be used to issue a
+// simulatereorg JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewSimulateReorgCmd(depth uint32, numBlocks *uint32, address *string) *SimulateReorgCmd {
+	return &SimulateReorgCmd{
+		Depth:     depth,
+		NumBlocks: numBlocks,
+		Address:   address,
+	}
+}
+
+// TriggerGCCmd defines the triggergc JSON-RPC command.  This command is not a
+// standard Bitcoin command.  It is an extension for pktd.
+type TriggerGCCmd struct {
+	FreeOSMemory *bool `jsonrpcdefault:"false"`
+}
+
+// NewTriggerGCCmd returns a new instance which can be used to issue a triggergc
+// JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewTriggerGCCmd(freeOSMemory *bool) *TriggerGCCmd {
+	return &TriggerGCCmd{
+		FreeOSMemory: freeOSMemory,
+	}
+}
+
 // VersionCmd defines the version JSON-RPC command.
 //
 // NOTE: This is a btcsuite extension ported from
@@ -128,11 +856,48 @@ func init() {
 	// No special flags for commands in this file.
 	flags := UsageFlag(0)
 
+	MustRegisterCmd("capturemessages", (*CaptureMessagesCmd)(nil), flags)
+	MustRegisterCmd("captureprofile", (*CaptureProfileCmd)(nil), flags)
+	MustRegisterCmd("createdepositaddresses", (*CreateDepositAddressesCmd)(nil), flags)
 	MustRegisterCmd("debuglevel", (*DebugLevelCmd)(nil), flags)
+	MustRegisterCmd("listeners", (*ListenersCmd)(nil), flags)
 	MustRegisterCmd("node", (*NodeCmd)(nil), flags)
 	MustRegisterCmd("generate", (*GenerateCmd)(nil), flags)
+	MustRegisterCmd("generatefork", (*GenerateForkCmd)(nil), flags)
+	MustRegisterCmd("getannagingschedule", (*GetAnnAgingScheduleCmd)(nil), flags)
+	MustRegisterCmd("getannproof", (*GetAnnProofCmd)(nil), flags)
+	MustRegisterCmd("getauditlog", (*GetAuditLogCmd)(nil), flags)
 	MustRegisterCmd("getbestblock", (*GetBestBlockCmd)(nil), flags)
+	MustRegisterCmd("getblockcost", (*GetBlockCostCmd)(nil), flags)
+	MustRegisterCmd("getblockheaders", (*GetBlockHeadersCmd)(nil), flags)
+	MustRegisterCmd("getblockundo", (*GetBlockUndoCmd)(nil), flags)
+	MustRegisterCmd("getblocktemplatelight", (*GetBlockTemplateLightCmd)(nil), flags)
+	MustRegisterCmd("getclockskew", (*GetClockSkewCmd)(nil), flags)
+	MustRegisterCmd("getcoldspends", (*GetColdSpendsCmd)(nil), flags)
+	MustRegisterCmd("getconsensusrules", (*GetConsensusRulesCmd)(nil), flags)
 	MustRegisterCmd("getcurrentnet", (*GetCurrentNetCmd)(nil), flags)
+	MustRegisterCmd("getdatacarrierinfo", (*GetDataCarrierInfoCmd)(nil), flags)
+	MustRegisterCmd("getdifficultyhistory", (*GetDifficultyHistoryCmd)(nil), flags)
+	MustRegisterCmd("getdiskstatus", (*GetDiskStatusCmd)(nil), flags)
+	MustRegisterCmd("getfeehistory", (*GetFeeHistoryCmd)(nil), flags)
 	MustRegisterCmd("getheaders", (*GetHeadersCmd)(nil), flags)
+	MustRegisterCmd("getmemoryinfo", (*GetMemoryInfoCmd)(nil), flags)
+	MustRegisterCmd("getmininganalytics", (*GetMiningAnalyticsCmd)(nil), flags)
+	MustRegisterCmd("getnetworkhashrate", (*GetNetworkHashrateCmd)(nil), flags)
+	MustRegisterCmd("getorphanblocks", (*GetOrphanBlocksCmd)(nil), flags)
+	MustRegisterCmd("getorphantxs", (*GetOrphanTxsCmd)(nil), flags)
+	MustRegisterCmd("getpartitionstatus", (*GetPartitionStatusCmd)(nil), flags)
+	MustRegisterCmd("getrejectedtxs", (*GetRejectedTxsCmd)(nil), flags)
+	MustRegisterCmd("getmempooldelta", (*GetMempoolDeltaCmd)(nil), flags)
+	MustRegisterCmd("getsyncstatus", (*GetSyncStatusCmd)(nil), flags)
+	MustRegisterCmd("gettxfee", (*GetTxFeeCmd)(nil), flags)
+	MustRegisterCmd("getutxodeltas", (*GetUtxoDeltasCmd)(nil), flags)
+	MustRegisterCmd("getutxostats", (*GetUtxoStatsCmd)(nil), flags)
+	MustRegisterCmd("listdeposits", (*ListDepositsCmd)(nil), flags)
+	MustRegisterCmd("minttoken", (*MintTokenCmd)(nil), flags)
+	MustRegisterCmd("setblocklimits", (*SetBlockLimitsCmd)(nil), flags)
+	MustRegisterCmd("simulatereorg", (*SimulateReorgCmd)(nil), flags)
+	MustRegisterCmd("triggergc", (*TriggerGCCmd)(nil), flags)
+	MustRegisterCmd("verifyaddressownership", (*VerifyAddressOwnershipCmd)(nil), flags)
 	MustRegisterCmd("version", (*VersionCmd)(nil), flags)
 }
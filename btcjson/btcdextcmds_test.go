@@ -30,6 +30,65 @@ func TestBtcdExtCmds(t *testing.T) {
 		marshalled   string
 		unmarshalled interface{}
 	}{
+		{
+			name: "capturemessages",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("capturemessages", "status")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewCaptureMessagesCmd(btcjson.CaptureStatus, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"capturemessages","params":["status"],"id":1}`,
+			unmarshalled: &btcjson.CaptureMessagesCmd{
+				SubCmd: btcjson.CaptureStatus,
+			},
+		},
+		{
+			name: "capturemessages redact",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("capturemessages", "start",
+					[]string{"peers", "tx"})
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewCaptureMessagesCmd(btcjson.CaptureStart,
+					&[]string{"peers", "tx"})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"capturemessages","params":["start",["peers","tx"]],"id":1}`,
+			unmarshalled: &btcjson.CaptureMessagesCmd{
+				SubCmd: btcjson.CaptureStart,
+				Redact: &[]string{"peers", "tx"},
+			},
+		},
+		{
+			name: "captureprofile",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("captureprofile", "heap")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewCaptureProfileCmd("heap", nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"captureprofile","params":["heap"],"id":1}`,
+			unmarshalled: &btcjson.CaptureProfileCmd{
+				Profile: "heap",
+				Seconds: btcjson.Int32(30),
+			},
+		},
+		{
+			name: "captureprofile optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("captureprofile", "cpu", 10, "cpu.pprof")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewCaptureProfileCmd("cpu", btcjson.Int32(10),
+					btcjson.String("cpu.pprof"))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"captureprofile","params":["cpu",10,"cpu.pprof"],"id":1}`,
+			unmarshalled: &btcjson.CaptureProfileCmd{
+				Profile: "cpu",
+				Seconds: btcjson.Int32(10),
+				File:    btcjson.String("cpu.pprof"),
+			},
+		},
 		{
 			name: "debuglevel",
 			newCmd: func() (interface{}, error) {
@@ -114,6 +173,62 @@ func TestBtcdExtCmds(t *testing.T) {
 				NumBlocks: 1,
 			},
 		},
+		{
+			name: "generatefork",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("generatefork", "123", 2)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGenerateForkCmd("123", 2, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"generatefork","params":["123",2],"id":1}`,
+			unmarshalled: &btcjson.GenerateForkCmd{
+				ForkHash:  "123",
+				NumBlocks: 2,
+			},
+		},
+		{
+			name: "generatefork optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("generatefork", "123", 2, "1Address")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGenerateForkCmd("123", 2,
+					btcjson.String("1Address"))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"generatefork","params":["123",2,"1Address"],"id":1}`,
+			unmarshalled: &btcjson.GenerateForkCmd{
+				ForkHash:  "123",
+				NumBlocks: 2,
+				Address:   btcjson.String("1Address"),
+			},
+		},
+		{
+			name: "getauditlog",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getauditlog")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetAuditLogCmd(nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getauditlog","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetAuditLogCmd{
+				Count: btcjson.Int32(100),
+			},
+		},
+		{
+			name: "getauditlog optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getauditlog", 5)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetAuditLogCmd(btcjson.Int32(5))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getauditlog","params":[5],"id":1}`,
+			unmarshalled: &btcjson.GetAuditLogCmd{
+				Count: btcjson.Int32(5),
+			},
+		},
 		{
 			name: "getbestblock",
 			newCmd: func() (interface{}, error) {
@@ -136,6 +251,614 @@ func TestBtcdExtCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"getcurrentnet","params":[],"id":1}`,
 			unmarshalled: &btcjson.GetCurrentNetCmd{},
 		},
+		{
+			name: "getdatacarrierinfo",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getdatacarrierinfo")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetDataCarrierInfoCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getdatacarrierinfo","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetDataCarrierInfoCmd{},
+		},
+		{
+			name: "getmemoryinfo",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getmemoryinfo")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetMemoryInfoCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getmemoryinfo","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetMemoryInfoCmd{},
+		},
+		{
+			name: "getblockcost",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getblockcost")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetBlockCostCmd(nil)
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getblockcost","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetBlockCostCmd{},
+		},
+		{
+			name: "getblockcost block",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getblockcost", "123")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetBlockCostCmd(btcjson.String("123"))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getblockcost","params":["123"],"id":1}`,
+			unmarshalled: &btcjson.GetBlockCostCmd{
+				Block: btcjson.String("123"),
+			},
+		},
+		{
+			name: "getconsensusrules",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getconsensusrules")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetConsensusRulesCmd(nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getconsensusrules","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetConsensusRulesCmd{
+				Height: btcjson.Int32(-1),
+			},
+		},
+		{
+			name: "getconsensusrules height",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getconsensusrules", 144000)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetConsensusRulesCmd(btcjson.Int32(144000))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getconsensusrules","params":[144000],"id":1}`,
+			unmarshalled: &btcjson.GetConsensusRulesCmd{
+				Height: btcjson.Int32(144000),
+			},
+		},
+		{
+			name: "getblocktemplatelight",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getblocktemplatelight")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetBlockTemplateLightCmd(nil)
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getblocktemplatelight","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetBlockTemplateLightCmd{},
+		},
+		{
+			name: "getblocktemplatelight longpollid",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getblocktemplatelight", "123")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetBlockTemplateLightCmd(btcjson.String("123"))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getblocktemplatelight","params":["123"],"id":1}`,
+			unmarshalled: &btcjson.GetBlockTemplateLightCmd{
+				LongPollID: btcjson.String("123"),
+			},
+		},
+		{
+			name: "gettxfee",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("gettxfee", "123")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetTxFeeCmd("123")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"gettxfee","params":["123"],"id":1}`,
+			unmarshalled: &btcjson.GetTxFeeCmd{
+				TxID: "123",
+			},
+		},
+		{
+			name: "getutxodeltas",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getutxodeltas", 1000)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetUtxoDeltasCmd(1000, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getutxodeltas","params":[1000],"id":1}`,
+			unmarshalled: &btcjson.GetUtxoDeltasCmd{
+				StartHeight: 1000,
+				Count:       btcjson.Int32(100),
+			},
+		},
+		{
+			name: "getutxodeltas count",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getutxodeltas", 1000, 10)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetUtxoDeltasCmd(1000, btcjson.Int32(10))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getutxodeltas","params":[1000,10],"id":1}`,
+			unmarshalled: &btcjson.GetUtxoDeltasCmd{
+				StartHeight: 1000,
+				Count:       btcjson.Int32(10),
+			},
+		},
+		{
+			name: "getutxostats",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getutxostats")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetUtxoStatsCmd(nil)
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getutxostats","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetUtxoStatsCmd{},
+		},
+		{
+			name: "getutxostats height",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getutxostats", 1000)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetUtxoStatsCmd(btcjson.Int32(1000))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getutxostats","params":[1000],"id":1}`,
+			unmarshalled: &btcjson.GetUtxoStatsCmd{
+				Height: btcjson.Int32(1000),
+			},
+		},
+		{
+			name: "listeners",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("listeners", "list")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewListenersCmd(btcjson.ListenerList, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"listeners","params":["list"],"id":1}`,
+			unmarshalled: &btcjson.ListenersCmd{
+				SubCmd: btcjson.ListenerList,
+			},
+		},
+		{
+			name: "listeners add",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("listeners", "add", "eth0:8333")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewListenersCmd(btcjson.ListenerAdd,
+					btcjson.String("eth0:8333"))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"listeners","params":["add","eth0:8333"],"id":1}`,
+			unmarshalled: &btcjson.ListenersCmd{
+				SubCmd: btcjson.ListenerAdd,
+				Addr:   btcjson.String("eth0:8333"),
+			},
+		},
+		{
+			name: "getclockskew",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getclockskew")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetClockSkewCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getclockskew","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetClockSkewCmd{},
+		},
+		{
+			name: "getpartitionstatus",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getpartitionstatus")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetPartitionStatusCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getpartitionstatus","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetPartitionStatusCmd{},
+		},
+		{
+			name: "getorphanblocks",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getorphanblocks")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetOrphanBlocksCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getorphanblocks","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetOrphanBlocksCmd{},
+		},
+		{
+			name: "getorphantxs",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getorphantxs")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetOrphanTxsCmd(nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getorphantxs","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetOrphanTxsCmd{
+				Verbosity: btcjson.Int(0),
+			},
+		},
+		{
+			name: "getorphantxs optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getorphantxs", 2)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetOrphanTxsCmd(btcjson.Int(2))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getorphantxs","params":[2],"id":1}`,
+			unmarshalled: &btcjson.GetOrphanTxsCmd{
+				Verbosity: btcjson.Int(2),
+			},
+		},
+		{
+			name: "getrejectedtxs",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getrejectedtxs")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetRejectedTxsCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getrejectedtxs","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetRejectedTxsCmd{},
+		},
+		{
+			name: "getmempooldelta",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getmempooldelta")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetMempoolDeltaCmd(nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getmempooldelta","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetMempoolDeltaCmd{
+				Sequence: btcjson.Uint64(0),
+			},
+		},
+		{
+			name: "getmempooldelta optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getmempooldelta", uint64(1602806400000001))
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetMempoolDeltaCmd(btcjson.Uint64(1602806400000001))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getmempooldelta","params":[1602806400000001],"id":1}`,
+			unmarshalled: &btcjson.GetMempoolDeltaCmd{
+				Sequence: btcjson.Uint64(1602806400000001),
+			},
+		},
+		{
+			name: "createdepositaddresses",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("createdepositaddresses", []string{"a", "b"})
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewCreateDepositAddressesCmd([]string{"a", "b"})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"createdepositaddresses","params":[["a","b"]],"id":1}`,
+			unmarshalled: &btcjson.CreateDepositAddressesCmd{
+				Refs: []string{"a", "b"},
+			},
+		},
+		{
+			name: "listdeposits",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("listdeposits")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewListDepositsCmd(nil)
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"listdeposits","params":[],"id":1}`,
+			unmarshalled: &btcjson.ListDepositsCmd{},
+		},
+		{
+			name: "listdeposits optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("listdeposits", "a")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewListDepositsCmd(btcjson.String("a"))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"listdeposits","params":["a"],"id":1}`,
+			unmarshalled: &btcjson.ListDepositsCmd{
+				Ref: btcjson.String("a"),
+			},
+		},
+		{
+			name: "minttoken",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("minttoken", "readonly")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewMintTokenCmd("readonly", nil, nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"minttoken","params":["readonly"],"id":1}`,
+			unmarshalled: &btcjson.MintTokenCmd{
+				Scope:  "readonly",
+				Expiry: btcjson.Int64(0),
+			},
+		},
+		{
+			name: "minttoken optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("minttoken", "send", 3600, 1.5,
+					[]string{"getinfo", "sendrawtransaction"})
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewMintTokenCmd("send", btcjson.Int64(3600),
+					btcjson.Float64(1.5), &[]string{"getinfo",
+						"sendrawtransaction"})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"minttoken","params":["send",3600,1.5,["getinfo","sendrawtransaction"]],"id":1}`,
+			unmarshalled: &btcjson.MintTokenCmd{
+				Scope:   "send",
+				Expiry:  btcjson.Int64(3600),
+				MaxSend: btcjson.Float64(1.5),
+				Methods: &[]string{"getinfo", "sendrawtransaction"},
+			},
+		},
+		{
+			name: "getcoldspends",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getcoldspends")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetColdSpendsCmd(nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getcoldspends","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetColdSpendsCmd{
+				Acknowledge: btcjson.Bool(false),
+			},
+		},
+		{
+			name: "getcoldspends optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getcoldspends", true)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetColdSpendsCmd(btcjson.Bool(true))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getcoldspends","params":[true],"id":1}`,
+			unmarshalled: &btcjson.GetColdSpendsCmd{
+				Acknowledge: btcjson.Bool(true),
+			},
+		},
+		{
+			name: "getsyncstatus",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getsyncstatus")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetSyncStatusCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getsyncstatus","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetSyncStatusCmd{},
+		},
+		{
+			name: "getblockheaders",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getblockheaders", 100)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetBlockHeadersCmd(100, nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getblockheaders","params":[100],"id":1}`,
+			unmarshalled: &btcjson.GetBlockHeadersCmd{
+				StartHeight: 100,
+				Count:       btcjson.Int32(2000),
+				Verbose:     btcjson.Bool(false),
+			},
+		},
+		{
+			name: "getblockheaders optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getblockheaders", 100, 10, true)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetBlockHeadersCmd(100, btcjson.Int32(10),
+					btcjson.Bool(true))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getblockheaders","params":[100,10,true],"id":1}`,
+			unmarshalled: &btcjson.GetBlockHeadersCmd{
+				StartHeight: 100,
+				Count:       btcjson.Int32(10),
+				Verbose:     btcjson.Bool(true),
+			},
+		},
+		{
+			name: "getblockundo",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getblockundo", "123")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetBlockUndoCmd("123")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getblockundo","params":["123"],"id":1}`,
+			unmarshalled: &btcjson.GetBlockUndoCmd{
+				Hash: "123",
+			},
+		},
+		{
+			name: "getdiskstatus",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getdiskstatus")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetDiskStatusCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getdiskstatus","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetDiskStatusCmd{},
+		},
+		{
+			name: "getnetworkhashrate",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getnetworkhashrate")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetNetworkHashrateCmd(nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getnetworkhashrate","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetNetworkHashrateCmd{
+				Blocks: btcjson.Int32(120),
+				Height: btcjson.Int32(-1),
+			},
+		},
+		{
+			name: "getnetworkhashrate optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getnetworkhashrate", 1440, 5000)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetNetworkHashrateCmd(btcjson.Int32(1440),
+					btcjson.Int32(5000))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getnetworkhashrate","params":[1440,5000],"id":1}`,
+			unmarshalled: &btcjson.GetNetworkHashrateCmd{
+				Blocks: btcjson.Int32(1440),
+				Height: btcjson.Int32(5000),
+			},
+		},
+		{
+			name: "getannagingschedule",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getannagingschedule")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetAnnAgingScheduleCmd(nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getannagingschedule","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetAnnAgingScheduleCmd{
+				MaxAge: btcjson.Int32(20),
+			},
+		},
+		{
+			name: "getannagingschedule optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getannagingschedule", "2000ffff", 50)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetAnnAgingScheduleCmd(
+					btcjson.String("2000ffff"), btcjson.Int32(50))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getannagingschedule","params":["2000ffff",50],"id":1}`,
+			unmarshalled: &btcjson.GetAnnAgingScheduleCmd{
+				Target: btcjson.String("2000ffff"),
+				MaxAge: btcjson.Int32(50),
+			},
+		},
+		{
+			name: "getannproof",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getannproof", "123", 2)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetAnnProofCmd("123", 2)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getannproof","params":["123",2],"id":1}`,
+			unmarshalled: &btcjson.GetAnnProofCmd{
+				BlockHash: "123",
+				Position:  2,
+			},
+		},
+		{
+			name: "getmininganalytics",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getmininganalytics")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetMiningAnalyticsCmd(nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getmininganalytics","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetMiningAnalyticsCmd{
+				Blocks: btcjson.Int32(120),
+				Height: btcjson.Int32(-1),
+			},
+		},
+		{
+			name: "getmininganalytics optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getmininganalytics", 1440, 5000)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetMiningAnalyticsCmd(btcjson.Int32(1440),
+					btcjson.Int32(5000))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getmininganalytics","params":[1440,5000],"id":1}`,
+			unmarshalled: &btcjson.GetMiningAnalyticsCmd{
+				Blocks: btcjson.Int32(1440),
+				Height: btcjson.Int32(5000),
+			},
+		},
+		{
+			name: "getdifficultyhistory",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getdifficultyhistory")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetDifficultyHistoryCmd(nil, nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getdifficultyhistory","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetDifficultyHistoryCmd{
+				Count:    btcjson.Int32(100),
+				Height:   btcjson.Int32(-1),
+				Interval: btcjson.Int32(1),
+			},
+		},
+		{
+			name: "getdifficultyhistory optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getdifficultyhistory", 30, -1, 1440)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetDifficultyHistoryCmd(btcjson.Int32(30),
+					btcjson.Int32(-1), btcjson.Int32(1440))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getdifficultyhistory","params":[30,-1,1440],"id":1}`,
+			unmarshalled: &btcjson.GetDifficultyHistoryCmd{
+				Count:    btcjson.Int32(30),
+				Height:   btcjson.Int32(-1),
+				Interval: btcjson.Int32(1440),
+			},
+		},
+		{
+			name: "getfeehistory",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getfeehistory", 1000)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetFeeHistoryCmd(1000, nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getfeehistory","params":[1000],"id":1}`,
+			unmarshalled: &btcjson.GetFeeHistoryCmd{
+				Start:  1000,
+				ByTime: btcjson.Bool(false),
+			},
+		},
+		{
+			name: "getfeehistory by time",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getfeehistory", 1600000000, 1600086400, true)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetFeeHistoryCmd(1600000000,
+					btcjson.Int64(1600086400), btcjson.Bool(true))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getfeehistory","params":[1600000000,1600086400,true],"id":1}`,
+			unmarshalled: &btcjson.GetFeeHistoryCmd{
+				Start:  1600000000,
+				End:    btcjson.Int64(1600086400),
+				ByTime: btcjson.Bool(true),
+			},
+		},
 		{
 			name: "getheaders",
 			newCmd: func() (interface{}, error) {
@@ -176,6 +899,144 @@ func TestBtcdExtCmds(t *testing.T) {
 				HashStop: "000000000000000000ba33b33e1fad70b69e234fc24414dd47113bff38f523f7",
 			},
 		},
+		{
+			name: "setblocklimits",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("setblocklimits")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewSetBlockLimitsCmd(nil)
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"setblocklimits","params":[],"id":1}`,
+			unmarshalled: &btcjson.SetBlockLimitsCmd{},
+		},
+		{
+			name: "setblocklimits optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("setblocklimits",
+					`{"maxweight":2000000,"maxsigopcost":40000,"reservedweight":100000,"prioritysize":0}`)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewSetBlockLimitsCmd(&btcjson.BlockLimits{
+					MaxWeight:      btcjson.Uint32(2000000),
+					MaxSigOpCost:   btcjson.Int64(40000),
+					ReservedWeight: btcjson.Uint32(100000),
+					PrioritySize:   btcjson.Uint32(0),
+				})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"setblocklimits","params":[{"maxweight":2000000,"maxsigopcost":40000,"reservedweight":100000,"prioritysize":0}],"id":1}`,
+			unmarshalled: &btcjson.SetBlockLimitsCmd{
+				Limits: &btcjson.BlockLimits{
+					MaxWeight:      btcjson.Uint32(2000000),
+					MaxSigOpCost:   btcjson.Int64(40000),
+					ReservedWeight: btcjson.Uint32(100000),
+					PrioritySize:   btcjson.Uint32(0),
+				},
+			},
+		},
+		{
+			name: "setblocklimits without max weight",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("setblocklimits",
+					`{"prioritysize":0}`)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewSetBlockLimitsCmd(&btcjson.BlockLimits{
+					PrioritySize: btcjson.Uint32(0),
+				})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"setblocklimits","params":[{"prioritysize":0}],"id":1}`,
+			unmarshalled: &btcjson.SetBlockLimitsCmd{
+				Limits: &btcjson.BlockLimits{
+					PrioritySize: btcjson.Uint32(0),
+				},
+			},
+		},
+		{
+			name: "simulatereorg",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("simulatereorg", 3)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewSimulateReorgCmd(3, nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"simulatereorg","params":[3],"id":1}`,
+			unmarshalled: &btcjson.SimulateReorgCmd{
+				Depth: 3,
+			},
+		},
+		{
+			name: "simulatereorg optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("simulatereorg", 3, 5, "1Address")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewSimulateReorgCmd(3, btcjson.Uint32(5),
+					btcjson.String("1Address"))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"simulatereorg","params":[3,5,"1Address"],"id":1}`,
+			unmarshalled: &btcjson.SimulateReorgCmd{
+				Depth:     3,
+				NumBlocks: btcjson.Uint32(5),
+				Address:   btcjson.String("1Address"),
+			},
+		},
+		{
+			name: "verifyaddressownership",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("verifyaddressownership", "0100", "reserves")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewVerifyAddressOwnershipCmd("0100", "reserves", nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"verifyaddressownership","params":["0100","reserves"],"id":1}`,
+			unmarshalled: &btcjson.VerifyAddressOwnershipCmd{
+				Proof:   "0100",
+				Message: "reserves",
+			},
+		},
+		{
+			name: "verifyaddressownership optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("verifyaddressownership", "0100", "reserves", 1000)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewVerifyAddressOwnershipCmd("0100", "reserves",
+					btcjson.Int32(1000))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"verifyaddressownership","params":["0100","reserves",1000],"id":1}`,
+			unmarshalled: &btcjson.VerifyAddressOwnershipCmd{
+				Proof:   "0100",
+				Message: "reserves",
+				Height:  btcjson.Int32(1000),
+			},
+		},
+		{
+			name: "triggergc",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("triggergc")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewTriggerGCCmd(nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"triggergc","params":[],"id":1}`,
+			unmarshalled: &btcjson.TriggerGCCmd{
+				FreeOSMemory: btcjson.Bool(false),
+			},
+		},
+		{
+			name: "triggergc optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("triggergc", true)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewTriggerGCCmd(btcjson.Bool(true))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"triggergc","params":[true],"id":1}`,
+			unmarshalled: &btcjson.TriggerGCCmd{
+				FreeOSMemory: btcjson.Bool(true),
+			},
+		},
 		{
 			name: "version",
 			newCmd: func() (interface{}, error) {
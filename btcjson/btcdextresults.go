@@ -18,3 +18,783 @@ type VersionResult struct {
 	Prerelease    string `json:"prerelease"`
 	BuildMetadata string `json:"buildmetadata"`
 }
+
+// VerifyAddressOwnershipResult models the data returned by the
+// verifyaddressownership command.  Amount and Addresses describe the outputs
+// spent by the proof and are only set when it is valid, otherwise Error holds
+// the reason it was rejected.
+type VerifyAddressOwnershipResult struct {
+	Valid     bool     `json:"valid"`
+	Height    int32    `json:"height"`
+	Amount    float64  `json:"amount,omitempty"`
+	Addresses []string `json:"addresses,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// AuditLogEntry models a single entry of the RPC audit log as returned by the
+// getauditlog command.  Each entry commits to the hash of the entry before it.
+type AuditLogEntry struct {
+	Seq      int64  `json:"seq"`
+	Time     int64  `json:"time"`
+	User     string `json:"user"`
+	Remote   string `json:"remote"`
+	Method   string `json:"method"`
+	Params   string `json:"params"`
+	Error    string `json:"error,omitempty"`
+	PrevHash string `json:"prevhash"`
+	Hash     string `json:"hash,omitempty"`
+}
+
+// SimulateReorgResult models the data returned by the simulatereorg command.
+// Disconnected holds the hashes of the blocks removed from the main chain and
+// Connected the hashes of the generated blocks, both in chain order.
+type SimulateReorgResult struct {
+	ForkHash     string   `json:"forkhash"`
+	ForkHeight   int32    `json:"forkheight"`
+	OldTip       string   `json:"oldtip"`
+	NewTip       string   `json:"newtip"`
+	NewHeight    int32    `json:"newheight"`
+	Disconnected []string `json:"disconnected"`
+	Connected    []string `json:"connected"`
+}
+
+// CaptureMessagesResult models the data returned by the capturemessages
+// command.  Files lists the capture files which are currently open, one per
+// connection, and Messages and Bytes count what was captured since the
+// capture was started.
+type CaptureMessagesResult struct {
+	Enabled  bool     `json:"enabled"`
+	Dir      string   `json:"dir"`
+	Redact   []string `json:"redact"`
+	Files    []string `json:"files"`
+	Messages uint64   `json:"messages"`
+	Bytes    uint64   `json:"bytes"`
+}
+
+// PartitionConditionResult models a condition watched by the network partition
+// detector, as returned by the getpartitionstatus command.  Since is the Unix
+// time the condition started to hold and Alert is set once it held for the
+// configured alert time.
+type PartitionConditionResult struct {
+	Holding bool  `json:"holding"`
+	Since   int64 `json:"since,omitempty"`
+	Alert   bool  `json:"alert"`
+}
+
+// GetPartitionStatusResult models the data returned by the getpartitionstatus
+// command.  The works are hex encoded.  The work of the best peer is estimated
+// from the announced height when its chain is unknown.  Peers whose chain
+// can't be placed relative to the main chain are counted in Peers only.
+type GetPartitionStatusResult struct {
+	Enabled           bool                     `json:"enabled"`
+	AlertTime         int64                    `json:"alerttime"`
+	TipHash           string                   `json:"tiphash"`
+	TipHeight         int32                    `json:"tipheight"`
+	TipWork           string                   `json:"tipwork"`
+	BestPeer          string                   `json:"bestpeer,omitempty"`
+	BestPeerHeight    int32                    `json:"bestpeerheight,omitempty"`
+	BestPeerWork      string                   `json:"bestpeerwork,omitempty"`
+	BestPeerEstimated bool                     `json:"bestpeerestimated,omitempty"`
+	Peers             int                      `json:"peers"`
+	PeersSameChain    int                      `json:"peerssamechain"`
+	PeersOtherChain   int                      `json:"peersotherchain"`
+	Behind            PartitionConditionResult `json:"behind"`
+	Fork              PartitionConditionResult `json:"fork"`
+}
+
+// ListenerResult models a listener accepting peer connections, as returned by
+// the listeners command.  Listen is the listen address it was created for, which
+// may be a wildcard or name a network interface, and Address the address it is
+// bound to.  Runtime is set when it was added with the listeners command.
+type ListenerResult struct {
+	Listen      string   `json:"listen"`
+	Address     string   `json:"address"`
+	Network     string   `json:"network"`
+	Runtime     bool     `json:"runtime"`
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+// GetClockSkewResult models the data returned by the getclockskew command.  The
+// offsets are in seconds.  NetworkOffset is the median offset of the peer time
+// samples which are not outliers and Offset the adjustment applied to the
+// local clock, clamped to MaxOffset.
+type GetClockSkewResult struct {
+	LocalTime     int64  `json:"localtime"`
+	AdjustedTime  int64  `json:"adjustedtime"`
+	Offset        int64  `json:"offset"`
+	NetworkOffset int64  `json:"networkoffset"`
+	MaxOffset     int64  `json:"maxoffset"`
+	Samples       int    `json:"samples"`
+	Outliers      int    `json:"outliers"`
+	Clamped       bool   `json:"clamped"`
+	Skewed        bool   `json:"skewed"`
+	Warning       string `json:"warning,omitempty"`
+}
+
+// CaptureProfileResult models the data returned by the captureprofile command.
+// The profile is in the format read by go tool pprof.  It is written to File
+// when a file was requested and returned base64 encoded in Data otherwise.
+type CaptureProfileResult struct {
+	Profile string `json:"profile"`
+	Bytes   int64  `json:"bytes"`
+	File    string `json:"file,omitempty"`
+	Data    string `json:"data,omitempty"`
+}
+
+// RuntimeMemoryStats models the Go runtime memory statistics returned by the
+// getmemoryinfo command.  The sizes are in bytes and LastGC is a Unix time.
+type RuntimeMemoryStats struct {
+	Alloc        uint64 `json:"alloc"`
+	TotalAlloc   uint64 `json:"totalalloc"`
+	Sys          uint64 `json:"sys"`
+	Mallocs      uint64 `json:"mallocs"`
+	Frees        uint64 `json:"frees"`
+	HeapAlloc    uint64 `json:"heapalloc"`
+	HeapSys      uint64 `json:"heapsys"`
+	HeapIdle     uint64 `json:"heapidle"`
+	HeapInuse    uint64 `json:"heapinuse"`
+	HeapReleased uint64 `json:"heapreleased"`
+	HeapObjects  uint64 `json:"heapobjects"`
+	StackSys     uint64 `json:"stacksys"`
+	NumGC        uint32 `json:"numgc"`
+	LastGC       int64  `json:"lastgc"`
+	PauseTotalNs uint64 `json:"pausetotalns"`
+	Goroutines   int    `json:"goroutines"`
+}
+
+// SubsystemMemoryUsage models the estimated memory used by a subsystem as
+// returned by the getmemoryinfo command.
+type SubsystemMemoryUsage struct {
+	Name    string `json:"name"`
+	Entries int64  `json:"entries"`
+	Bytes   int64  `json:"bytes"`
+}
+
+// DataCarrierClassResult models the counts of the transactions carrying data in
+// OP_RETURN outputs of a payload size class returned by the getdatacarrierinfo
+// command.
+type DataCarrierClassResult struct {
+	Size     string `json:"size"`
+	Accepted uint64 `json:"accepted"`
+	Mempool  int    `json:"mempool"`
+}
+
+// GetDataCarrierInfoResult models the data returned by the getdatacarrierinfo
+// command.
+type GetDataCarrierInfoResult struct {
+	Relay          bool                     `json:"relay"`
+	MaxSize        int                      `json:"maxsize"`
+	BlockMaxWeight uint32                   `json:"blockmaxweight"`
+	Rejected       uint64                   `json:"rejected"`
+	MempoolBytes   int64                    `json:"mempoolbytes"`
+	Classes        []DataCarrierClassResult `json:"classes"`
+}
+
+// GetMemoryInfoResult models the data returned by the getmemoryinfo command.
+type GetMemoryInfoResult struct {
+	Runtime    RuntimeMemoryStats     `json:"runtime"`
+	Subsystems []SubsystemMemoryUsage `json:"subsystems"`
+}
+
+// TriggerGCResult models the data returned by the triggergc command.
+type TriggerGCResult struct {
+	HeapAllocBefore uint64 `json:"heapallocbefore"`
+	HeapAllocAfter  uint64 `json:"heapallocafter"`
+	HeapReleased    uint64 `json:"heapreleased"`
+	DurationMs      int64  `json:"durationms"`
+}
+
+// UtxoHistogramBucket models a bucket of the value histograms returned by the
+// getutxostats command.  It counts the unspent outputs whose value, in atomic
+// units, is between MinValue and MaxValue.
+type UtxoHistogramBucket struct {
+	MinValue int64  `json:"minvalue"`
+	MaxValue int64  `json:"maxvalue"`
+	Count    uint64 `json:"count"`
+}
+
+// UtxoClassStatsResult models the statistics of the unspent outputs of a
+// script class as returned by the getutxostats command.
+type UtxoClassStatsResult struct {
+	Class      string                `json:"class"`
+	Count      uint64                `json:"count"`
+	Amount     float64               `json:"amount"`
+	DustCount  uint64                `json:"dustcount"`
+	DustAmount float64               `json:"dustamount"`
+	Histogram  []UtxoHistogramBucket `json:"histogram"`
+}
+
+// GetUtxoStatsResult models the data returned by the getutxostats command.
+type GetUtxoStatsResult struct {
+	Height       int32                  `json:"height"`
+	Hash         string                 `json:"hash"`
+	DustRelayFee float64                `json:"dustrelayfee"`
+	Total        UtxoClassStatsResult   `json:"total"`
+	Classes      []UtxoClassStatsResult `json:"classes"`
+}
+
+// FeeHistoryBlock models the fee statistics of a block returned by the
+// getfeehistory command.  The fee rates are in BTC/KB of virtual size and the
+// percentiles are weighted by virtual size.
+type FeeHistoryBlock struct {
+	Height             int32     `json:"height"`
+	Time               int64     `json:"time"`
+	TxCount            uint32    `json:"txcount"`
+	VSize              uint64    `json:"vsize"`
+	TotalFee           float64   `json:"totalfee"`
+	MinFeeRate         float64   `json:"minfeerate"`
+	MaxFeeRate         float64   `json:"maxfeerate"`
+	FeeRatePercentiles []float64 `json:"feeratepercentiles"`
+}
+
+// GetFeeHistoryResult models the data returned by the getfeehistory command.
+type GetFeeHistoryResult struct {
+	Percentiles []int             `json:"percentiles"`
+	Truncated   bool              `json:"truncated"`
+	Blocks      []FeeHistoryBlock `json:"blocks"`
+}
+
+// BlockLimitsResult models the limits used to assemble block templates which
+// are returned by the setblocklimits and getmininginfo commands.
+type BlockLimitsResult struct {
+	MaxWeight            uint32 `json:"maxweight"`
+	MaxSigOpCost         int64  `json:"maxsigopcost"`
+	ReservedWeight       uint32 `json:"reservedweight"`
+	PrioritySize         uint32 `json:"prioritysize"`
+	MaxDataCarrierWeight uint32 `json:"maxdatacarrierweight"`
+}
+
+// TxCostResult models the accounting of a transaction returned by the
+// getblockcost command.
+type TxCostResult struct {
+	TxID          string `json:"txid"`
+	Hash          string `json:"hash"`
+	Size          int    `json:"size"`
+	StrippedSize  int    `json:"strippedsize"`
+	WitnessSize   int    `json:"witnesssize"`
+	Weight        int64  `json:"weight"`
+	LegacySigOps  int    `json:"legacysigops"`
+	P2SHSigOps    int    `json:"p2shsigops"`
+	WitnessSigOps int    `json:"witnesssigops"`
+	SigOpCost     int    `json:"sigopcost"`
+}
+
+// GetBlockCostResult models the data returned by the getblockcost command.
+type GetBlockCostResult struct {
+	Hash            string         `json:"hash"`
+	Height          int32          `json:"height"`
+	Size            int            `json:"size"`
+	StrippedSize    int            `json:"strippedsize"`
+	WitnessSize     int            `json:"witnesssize"`
+	Weight          int64          `json:"weight"`
+	SigOpCost       int            `json:"sigopcost"`
+	MaxStrippedSize int            `json:"maxstrippedsize"`
+	MaxWeight       int64          `json:"maxweight"`
+	MaxSigOpCost    int            `json:"maxsigopcost"`
+	HeaderSize      int            `json:"headersize"`
+	TxCountSize     int            `json:"txcountsize"`
+	ProofSize       int            `json:"proofsize"`
+	Transactions    []TxCostResult `json:"transactions"`
+}
+
+// GetBlockTemplateLightResult models the data returned by the
+// getblocktemplatelight command.  Transactions holds the transactions of the
+// block template which were not in the one identified by BaseLongPollID, or
+// all of them when it is not set, and Removed the hashes of the transactions
+// of the base template which are no longer in it.
+type GetBlockTemplateLightResult struct {
+	Bits                     string                     `json:"bits"`
+	CurTime                  int64                      `json:"curtime"`
+	Height                   int64                      `json:"height"`
+	PreviousHash             string                     `json:"previousblockhash"`
+	Version                  int32                      `json:"version"`
+	Target                   string                     `json:"target"`
+	MinTime                  int64                      `json:"mintime"`
+	MaxTime                  int64                      `json:"maxtime"`
+	LongPollID               string                     `json:"longpollid"`
+	SubmitOld                *bool                      `json:"submitold,omitempty"`
+	CoinbaseValue            int64                      `json:"coinbasevalue"`
+	DefaultWitnessCommitment string                     `json:"default_witness_commitment,omitempty"`
+	Merkle                   []string                   `json:"merkle"`
+	TxHashes                 []string                   `json:"txhashes"`
+	BaseLongPollID           string                     `json:"baselongpollid,omitempty"`
+	Transactions             []GetBlockTemplateResultTx `json:"transactions"`
+	Removed                  []string                   `json:"removed"`
+}
+
+// SubmitBlockResult models the data returned by the submitblock command when
+// the verbose option is set.  The status is one of the SubmitBlockStatus
+// values.  The details of a rejection are only set when they are known and the
+// indexes are only set when the rejection is about a transaction, an input or
+// a PacketCrypt announcement.
+type SubmitBlockResult struct {
+	Hash       string `json:"hash"`
+	Status     string `json:"status"`
+	Reason     string `json:"reason,omitempty"`
+	Rule       string `json:"rule,omitempty"`
+	Message    string `json:"message,omitempty"`
+	TxIndex    *int   `json:"txindex,omitempty"`
+	InputIndex *int   `json:"inputindex,omitempty"`
+	AnnIndex   *int   `json:"annindex,omitempty"`
+	Expected   string `json:"expected,omitempty"`
+	Actual     string `json:"actual,omitempty"`
+}
+
+// The statuses of a block returned by the submitblock command.  Besides
+// SubmitBlockAccepted and SubmitBlockRejected, they are the BIP0022 reasons
+// for the outcomes which are not a rejection of an invalid block.
+const (
+	// SubmitBlockAccepted is the status of a block which was accepted and
+	// extends the main chain.
+	SubmitBlockAccepted = "accepted"
+
+	// SubmitBlockRejected is the status of a block which was rejected.
+	SubmitBlockRejected = "rejected"
+
+	// SubmitBlockInconclusive is the status of a block which was accepted
+	// but could not be fully validated, either because it is an orphan or
+	// because it is on a side chain.
+	SubmitBlockInconclusive = "inconclusive"
+
+	// SubmitBlockDuplicate is the status of a block which was already
+	// known to be valid.
+	SubmitBlockDuplicate = "duplicate"
+
+	// SubmitBlockDuplicateInvalid is the status of a block which was
+	// already known to be invalid.
+	SubmitBlockDuplicateInvalid = "duplicate-invalid"
+
+	// SubmitBlockDuplicateInconclusive is the status of a block which was
+	// already known but not fully validated.
+	SubmitBlockDuplicateInconclusive = "duplicate-inconclusive"
+)
+
+// GetTxFeeResult models the data returned by the gettxfee command.  The fee
+// rate is in coins per kilo virtual byte and the block hash is only set for
+// confirmed transactions.
+type GetTxFeeResult struct {
+	TxID      string  `json:"txid"`
+	Fee       float64 `json:"fee"`
+	VSize     int32   `json:"vsize"`
+	FeeRate   float64 `json:"feerate"`
+	BlockHash string  `json:"blockhash,omitempty"`
+}
+
+// ChainEventUtxo models an unspent transaction output created or spent by a
+// block in a chain event.
+type ChainEventUtxo struct {
+	TxID         string  `json:"txid"`
+	Vout         uint32  `json:"vout"`
+	Amount       float64 `json:"amount"`
+	ScriptPubKey string  `json:"scriptpubkey"`
+	Address      string  `json:"address,omitempty"`
+	Height       int32   `json:"height"`
+	Coinbase     bool    `json:"coinbase"`
+}
+
+// UtxoDeltasResult models the outputs created and spent by a block of the main
+// chain, returned by the getutxodeltas command and sent by the utxodeltas
+// notification.  The type is "disconnected" when the block was disconnected
+// from the main chain, undoing the changes, and "connected" otherwise.
+type UtxoDeltasResult struct {
+	Type         string           `json:"type"`
+	Hash         string           `json:"hash"`
+	PreviousHash string           `json:"previoushash"`
+	Height       int32            `json:"height"`
+	Created      []ChainEventUtxo `json:"created"`
+	Spent        []ChainEventUtxo `json:"spent"`
+}
+
+// ChainEventResult models the data of the events streamed by the chain events
+// endpoint of the RPC server.
+type ChainEventResult struct {
+	Sequence      uint64           `json:"sequence"`
+	Type          string           `json:"type"`
+	Hash          string           `json:"hash"`
+	PreviousHash  string           `json:"previoushash"`
+	Height        int32            `json:"height"`
+	Time          int64            `json:"time"`
+	Bits          string           `json:"bits"`
+	ChainWork     string           `json:"chainwork"`
+	Created       []ChainEventUtxo `json:"created"`
+	Spent         []ChainEventUtxo `json:"spent"`
+	FinalityDepth int32            `json:"finalitydepth"`
+	FinalHeight   int32            `json:"finalheight"`
+}
+
+// GetNetworkHashrateResult models the data returned by the getnetworkhashrate
+// command.  The hash rates are in hashes per second, the block hash rate from
+// the effective targets the blocks were mined at given their announcements, and
+// the announcement hash rate from the work of the announcements of the blocks.
+// The work of the first block of the window is not counted as it was done
+// before the window starts.
+type GetNetworkHashrateResult struct {
+	StartHeight   int32   `json:"startheight"`
+	EndHeight     int32   `json:"endheight"`
+	TimeSpan      int64   `json:"timespan"`
+	Announcements uint64  `json:"announcements"`
+	BlockWork     string  `json:"blockwork"`
+	AnnWork       string  `json:"annwork"`
+	BlockHashrate float64 `json:"blockhashrate"`
+	AnnHashrate   float64 `json:"annhashrate"`
+}
+
+// DifficultyHistoryResult models a block of the series returned by the
+// getdifficultyhistory command.  The announcement fields are empty for blocks
+// without an announcement commitment, whose effective target is their header
+// target.
+type DifficultyHistoryResult struct {
+	Height              int32   `json:"height"`
+	Hash                string  `json:"hash"`
+	Time                int64   `json:"time"`
+	Bits                string  `json:"bits"`
+	Difficulty          float64 `json:"difficulty"`
+	AnnCount            uint64  `json:"anncount"`
+	AnnMinBits          string  `json:"annminbits,omitempty"`
+	AnnDifficulty       float64 `json:"anndifficulty,omitempty"`
+	EffectiveBits       string  `json:"effectivebits"`
+	EffectiveDifficulty float64 `json:"effectivedifficulty"`
+}
+
+// GetMiningAnalyticsResult models the data returned by the getmininganalytics
+// command.  The announcement statistics only cover the blocks with an
+// announcement commitment, counted in AnnBlocks.  AnnWorkShare is the share of
+// the announcement work in the total announcement and block mining work of the
+// window, and WorkReduction the average ratio of the header work to the
+// effective work of the blocks mined with announcements.  The works are hex
+// encoded.
+type GetMiningAnalyticsResult struct {
+	StartHeight            int32   `json:"startheight"`
+	EndHeight              int32   `json:"endheight"`
+	Blocks                 int32   `json:"blocks"`
+	AnnBlocks              int32   `json:"annblocks"`
+	AnnCount               uint64  `json:"anncount"`
+	MinAnnCount            uint64  `json:"minanncount"`
+	MaxAnnCount            uint64  `json:"maxanncount"`
+	AvgAnnCount            float64 `json:"avganncount"`
+	AvgDifficulty          float64 `json:"avgdifficulty"`
+	AvgAnnDifficulty       float64 `json:"avganndifficulty"`
+	AvgEffectiveDifficulty float64 `json:"avgeffectivedifficulty"`
+	HeaderWork             string  `json:"headerwork"`
+	AnnWork                string  `json:"annwork"`
+	EffectiveWork          string  `json:"effectivework"`
+	AnnWorkShare           float64 `json:"annworkshare"`
+	WorkReduction          float64 `json:"workreduction"`
+}
+
+// AnnAgeResult models the aged target of an announcement at an age, returned
+// by the getannagingschedule command.  The status is "waiting" before the
+// announcement can be mined, "valid" while it can and "expired" once its aged
+// target exceeds the maximum.  Bits and Difficulty are only set for valid
+// ages.  Acceptable reports whether the aged target meets the minimum
+// announcement target of the best block.
+type AnnAgeResult struct {
+	Age          int32   `json:"age"`
+	ParentHeight int32   `json:"parentheight"`
+	Status       string  `json:"status"`
+	Bits         string  `json:"bits,omitempty"`
+	Difficulty   float64 `json:"difficulty,omitempty"`
+	Acceptable   bool    `json:"acceptable"`
+}
+
+// GetAnnAgingScheduleResult models the data returned by the
+// getannagingschedule command.  Height is the height of the block following
+// the best block, which the ages are relative to.  ExpiryAge is the last age at
+// which the announcement can be mined, and AcceptableAge the last age at which
+// it meets the minimum announcement target of the best block, or -1 when it
+// never does.
+type GetAnnAgingScheduleResult struct {
+	Height        int32          `json:"height"`
+	Target        string         `json:"target"`
+	TipAnnMinBits string         `json:"tipannminbits,omitempty"`
+	MaxAgedBits   string         `json:"maxagedbits"`
+	WaitPeriod    int32          `json:"waitperiod"`
+	ExpiryAge     int64          `json:"expiryage"`
+	AcceptableAge int64          `json:"acceptableage"`
+	Schedule      []AnnAgeResult `json:"schedule"`
+}
+
+// GetAnnProofResult models the data returned by the getannproof command.  Hex
+// is the serialized proof and the other fields are what it proves: the
+// announcement with hash AnnHash, mined at the compact target WorkBits on the
+// block at ParentHeight, is included in the block at Height, where its aged
+// target AgedBits meets the minimum announcement target AnnMinBits of the
+// block.  SigningKey is only set for signed announcements.
+type GetAnnProofResult struct {
+	Hex          string `json:"hex"`
+	BlockHash    string `json:"blockhash"`
+	Height       int32  `json:"height"`
+	Position     int32  `json:"position"`
+	AnnHash      string `json:"annhash"`
+	ParentHeight uint32 `json:"parentheight"`
+	WorkBits     string `json:"workbits"`
+	AgedBits     string `json:"agedbits"`
+	AnnMinBits   string `json:"annminbits"`
+	AnnCount     uint64 `json:"anncount"`
+	SigningKey   string `json:"signingkey,omitempty"`
+}
+
+// GetConsensusRulesResult models the data returned by the getconsensusrules
+// command.  Deployments maps the name of each version bits deployment to its
+// state for the block.  The subsidy amounts are in the smallest unit of the
+// chain, and NextSubsidyHeight is -1 when the subsidy never changes.
+type GetConsensusRulesResult struct {
+	Height               int32             `json:"height"`
+	MinBlockVersion      int32             `json:"minblockversion"`
+	SerializedHeight     bool              `json:"serializedheight"`
+	ScriptFlags          string            `json:"scriptflags"`
+	Deployments          map[string]string `json:"deployments"`
+	SubsidyEpoch         int32             `json:"subsidyepoch"`
+	NextSubsidyHeight    int32             `json:"nextsubsidyheight"`
+	Subsidy              int64             `json:"subsidy"`
+	NetworkStewardPayout int64             `json:"networkstewardpayout"`
+	PacketCrypt          bool              `json:"packetcrypt"`
+	AnnAging             bool              `json:"annaging"`
+	AnnWaitPeriod        int32             `json:"annwaitperiod"`
+}
+
+// BuildModuleResult models a consensus-critical module pktd was built with,
+// as returned in the build information of the getinfo and getnetworkinfo
+// commands.  Sum is the go.sum hash of the module, empty when it was replaced
+// with a local directory.
+type BuildModuleResult struct {
+	Path     string `json:"path"`
+	Version  string `json:"version"`
+	Sum      string `json:"sum,omitempty"`
+	Replaced bool   `json:"replaced,omitempty"`
+}
+
+// BuildInfoResult models the build information of pktd returned by the
+// getinfo and getnetworkinfo commands.  ManifestVerified reports whether the
+// consensus modules were checked against a build manifest at startup.
+type BuildInfoResult struct {
+	Commit           string              `json:"commit,omitempty"`
+	GoVersion        string              `json:"goversion"`
+	Flags            string              `json:"flags,omitempty"`
+	ConsensusModules []BuildModuleResult `json:"consensusmodules"`
+	ManifestVerified bool                `json:"manifestverified"`
+}
+
+// OrphanBlockResult models a block which is not part of the main chain returned
+// by the getorphanblocks command.  Height is -1 when it is unknown, as is
+// ForkHeight when the block does not connect to the known blocks.  Seen is the
+// time in seconds since 1 Jan 1970 GMT the block was first left out of the main
+// chain and MainChainHash the hash of the main chain block at its height.
+type OrphanBlockResult struct {
+	Hash              string   `json:"hash"`
+	Height            int32    `json:"height"`
+	Header            string   `json:"header"`
+	PreviousBlockHash string   `json:"previousblockhash"`
+	Time              int64    `json:"time"`
+	Seen              int64    `json:"seen"`
+	Reason            string   `json:"reason"`
+	Status            string   `json:"status"`
+	ForkHeight        int32    `json:"forkheight"`
+	ForkHash          string   `json:"forkhash,omitempty"`
+	MainChainHash     string   `json:"mainchainhash,omitempty"`
+	Children          []string `json:"children"`
+}
+
+// OrphanTxResult models a transaction of the orphan pool returned by the
+// getorphantxs command with a verbosity of 1 or more.  From is the ID of the
+// peer which relayed the orphan, 0 when it was submitted locally, and
+// Expiration the time in seconds since 1 Jan 1970 GMT when it is removed from
+// the pool if its missing parents are still unknown.  Hex is only set with a
+// verbosity of 2.
+type OrphanTxResult struct {
+	TxID       string `json:"txid"`
+	WTxID      string `json:"wtxid"`
+	Bytes      int64  `json:"bytes"`
+	VSize      int64  `json:"vsize"`
+	Weight     int64  `json:"weight"`
+	From       uint64 `json:"from"`
+	Expiration int64  `json:"expiration"`
+	Hex        string `json:"hex,omitempty"`
+}
+
+// RejectedTxResult models a transaction relayed by a peer which was rejected,
+// returned by the getrejectedtxs command.  Time is in seconds since 1 Jan 1970
+// GMT and Height is the height of the best block at that time.  Filtered
+// reports whether the transaction was rejected since the last block was
+// connected, so it is not requested again when it is announced.
+type RejectedTxResult struct {
+	TxID     string `json:"txid"`
+	PeerID   int32  `json:"peerid"`
+	Peer     string `json:"peer"`
+	Time     int64  `json:"time"`
+	Height   int32  `json:"height"`
+	Code     string `json:"code"`
+	Reason   string `json:"reason"`
+	Filtered bool   `json:"filtered"`
+}
+
+// GetMempoolDeltaResult models the data returned by the getmempooldelta
+// command.  Sequence is passed to the next call to get the following changes.
+// When Reset is set, the changes since the requested sequence number are not
+// known and Added lists every transaction of the mempool.
+type GetMempoolDeltaResult struct {
+	Sequence uint64                 `json:"sequence"`
+	Reset    bool                   `json:"reset"`
+	Added    []string               `json:"added"`
+	Removed  []MempoolRemovedResult `json:"removed"`
+}
+
+// MempoolRemovedResult models a transaction removed from the mempool returned
+// by the getmempooldelta command.  Reason is one of mined, conflict, replaced,
+// reorg or evicted.
+type MempoolRemovedResult struct {
+	TxID   string `json:"txid"`
+	Reason string `json:"reason"`
+}
+
+// DepositAddressResult models an address bound to a reference ID returned by
+// the createdepositaddresses command.  Index is the index of the address on
+// the receive branch of the deposit account.
+type DepositAddressResult struct {
+	Ref     string `json:"ref"`
+	Address string `json:"address"`
+	Index   uint32 `json:"index"`
+}
+
+// DepositResult models a deposit returned by the listdeposits command.
+// Reported is the highest confirmation count the deposit was sent to the
+// webhooks at, 0 before the first.
+type DepositResult struct {
+	Ref           string  `json:"ref"`
+	Address       string  `json:"address"`
+	TxID          string  `json:"txid"`
+	Vout          uint32  `json:"vout"`
+	Amount        float64 `json:"amount"`
+	BlockHash     string  `json:"blockhash"`
+	Height        int32   `json:"height"`
+	Confirmations int32   `json:"confirmations"`
+	Reported      int32   `json:"reported"`
+}
+
+// ColdSpendResult models a transaction spending from cold storage addresses
+// returned by the getcoldspends command.  Amount is the total spent from the
+// cold storage addresses and FirstSeen the time in seconds since 1 Jan 1970
+// GMT the transaction was first seen.  BlockHash and Height are only set once
+// the transaction is mined.
+type ColdSpendResult struct {
+	TxID      string   `json:"txid"`
+	Addresses []string `json:"addresses"`
+	Amount    float64  `json:"amount"`
+	FirstSeen int64    `json:"firstseen"`
+	BlockHash string   `json:"blockhash,omitempty"`
+	Height    int32    `json:"height,omitempty"`
+}
+
+// GetColdSpendsResult models the data returned by the getcoldspends command.
+// Alarm reports whether a spend was seen since the alarm was last
+// acknowledged, and Addresses is the number of cold storage addresses watched.
+type GetColdSpendsResult struct {
+	Alarm     bool              `json:"alarm"`
+	Addresses int               `json:"addresses"`
+	Spends    []ColdSpendResult `json:"spends"`
+}
+
+// SyncStageResult models the time spent in a stage of the sync returned by the
+// getsyncstatus command.  Count is the number of headers or blocks which went
+// through the stage.
+type SyncStageResult struct {
+	Count   uint64  `json:"count"`
+	Seconds float64 `json:"seconds"`
+}
+
+// SyncStagesResult models the per-stage breakdown of the sync returned by the
+// getsyncstatus command.  Blocks covers the whole processing of the blocks,
+// which includes the checks of their proofs and scripts.
+type SyncStagesResult struct {
+	Headers SyncStageResult `json:"headers"`
+	Blocks  SyncStageResult `json:"blocks"`
+	Proofs  SyncStageResult `json:"proofs"`
+	Scripts SyncStageResult `json:"scripts"`
+}
+
+// SyncBandwidthResult models the bandwidth usage returned by the getsyncstatus
+// command.  The rates are in bytes per second averaged since the node started.
+type SyncBandwidthResult struct {
+	TotalBytesRecv uint64  `json:"totalbytesrecv"`
+	TotalBytesSent uint64  `json:"totalbytessent"`
+	RecvRate       float64 `json:"recvrate"`
+	SentRate       float64 `json:"sentrate"`
+}
+
+// GetSyncStatusResult models the data returned by the getsyncstatus command.
+// EstimatedHeight is the estimated height of the chain of the network and
+// EstimatedTimeRemaining the estimated number of seconds left to reach it, or
+// -1 when it can't be estimated because no block is being connected.
+type GetSyncStatusResult struct {
+	Blocks                 int32               `json:"blocks"`
+	Headers                int32               `json:"headers"`
+	BestPeerHeight         int32               `json:"bestpeerheight"`
+	EstimatedHeight        int32               `json:"estimatedheight"`
+	SyncPeer               int32               `json:"syncpeer"`
+	HeadersFirst           bool                `json:"headersfirst"`
+	PresyncPhase           string              `json:"presyncphase,omitempty"`
+	InitialBlockDownload   bool                `json:"initialblockdownload"`
+	VerificationProgress   float64             `json:"verificationprogress"`
+	BlocksPerSecond        float64             `json:"blockspersecond"`
+	EstimatedTimeRemaining int64               `json:"estimatedtimeremaining"`
+	Stages                 SyncStagesResult    `json:"stages"`
+	Bandwidth              SyncBandwidthResult `json:"bandwidth"`
+}
+
+// BlockUndoResult models the data returned by the getblockundo command.
+// Disconnecting the block removes the created outputs from the UTXO set and
+// restores the spent outputs, listed in the order of the inputs spending them.
+type BlockUndoResult struct {
+	Hash         string           `json:"hash"`
+	PreviousHash string           `json:"previoushash"`
+	Height       int32            `json:"height"`
+	Created      []ChainEventUtxo `json:"created"`
+	Spent        []ChainEventUtxo `json:"spent"`
+}
+
+// GetDiskStatusResult models the data returned by the getdiskstatus command.
+// The sizes are in bytes and the times in seconds since 1 Jan 1970 GMT.  Since
+// is the time the status last changed and Checked the time of the last check.
+type GetDiskStatusResult struct {
+	Enabled       bool   `json:"enabled"`
+	DataDir       string `json:"datadir,omitempty"`
+	Status        string `json:"status"`
+	SafeMode      bool   `json:"safemode"`
+	Free          uint64 `json:"free"`
+	Total         uint64 `json:"total"`
+	WarnSpace     uint64 `json:"warnspace"`
+	SafeModeSpace uint64 `json:"safemodespace"`
+	Since         int64  `json:"since,omitempty"`
+	Checked       int64  `json:"checked,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+// MintTokenResult models the data returned by the minttoken command.  Token is
+// sent by the clients as a bearer token in the Authorization header, and ID
+// names it in the logs and revocation lists.  Expires is the time the token
+// expires in seconds since 1 Jan 1970 GMT, omitted when it never expires.
+type MintTokenResult struct {
+	Token   string `json:"token"`
+	ID      string `json:"id"`
+	Expires int64  `json:"expires,omitempty"`
+}
+
+// BlockHeadersResult models the contiguous main chain headers returned by the
+// getblockheaders command and the blockheaders notification.  The headers are
+// either concatenated in their serialized form in Hex, or described in Headers
+// when verbose output is requested.
+type BlockHeadersResult struct {
+	StartHeight int32                         `json:"startheight"`
+	Count       int32                         `json:"count"`
+	Hex         string                        `json:"hex,omitempty"`
+	Headers     []GetBlockHeaderVerboseResult `json:"headers,omitempty"`
+}
+
+// StreamBlockHeadersResult models the data returned by the streamblockheaders
+// command once every header was sent, the hash and height of the last header
+// along with the number of headers sent.
+type StreamBlockHeadersResult struct {
+	Hash   string `json:"hash"`
+	Height int32  `json:"height"`
+	Count  int32  `json:"count"`
+}
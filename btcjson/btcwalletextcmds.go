@@ -7,9 +7,202 @@
 
 package btcjson
 
-// CreateNewAccountCmd defines the createnewaccount JSON-RPC command.
+// Signing package formats supported by createsigningpackage.
+const (
+	// SigningPackageFormatPSBT encodes the package as a base64 partially
+	// signed transaction as described by BIP 174.
+	SigningPackageFormatPSBT = "psbt"
+
+	// SigningPackageFormatJSON encodes the package as a SigningPackage
+	// serialized to JSON.
+	SigningPackageFormatJSON = "json"
+)
+
+// Wallet export formats of the reference client supported by
+// importcorewallet, see package coreimport.
+const (
+	// CoreWalletFormatDump is the text dump written by dumpwallet.
+	CoreWalletFormatDump = "dump"
+
+	// CoreWalletFormatDescriptors is the JSON written by listdescriptors.
+	CoreWalletFormatDescriptors = "descriptors"
+)
+
+// SigningPackageInput describes a previous output spent by the transaction of
+// a signing package, providing everything an offline wallet needs in order to
+// sign for it without access to the chain.
+type SigningPackageInput struct {
+	TxID              string  `json:"txid"`
+	Vout              uint32  `json:"vout"`
+	Amount            float64 `json:"amount"`
+	ScriptPubKey      string  `json:"scriptpubkey"`
+	RedeemScript      string  `json:"redeemscript,omitempty"`
+	MasterFingerprint string  `json:"masterfingerprint,omitempty"`
+	DerivationPath    string  `json:"derivationpath,omitempty"`
+	PubKey            string  `json:"pubkey,omitempty"`
+	Signature         string  `json:"signature,omitempty"`
+}
+
+// SigningPackage is the compact JSON form of a signing package.  Tx is the
+// hex-encoded unsigned transaction and Inputs is in the order of its inputs.
+type SigningPackage struct {
+	Tx     string                `json:"tx"`
+	Inputs []SigningPackageInput `json:"inputs"`
+}
+
+// AccountPolicy holds the spending policy of a wallet account.  MaxFeeRate,
+// in BTC/kB, and MaxFee cap the fee of every transaction spending from the
+// account.  WithdrawalLimit caps the total sent from the account within each
+// LimitPeriod, in seconds, which defaults to a day.  A sub-account is also
+// bound by the policies of every account above it.  Nil fields are not
+// limited.
+type AccountPolicy struct {
+	MaxFeeRate      *float64 `json:"maxfeerate,omitempty"`
+	MaxFee          *float64 `json:"maxfee,omitempty"`
+	WithdrawalLimit *float64 `json:"withdrawallimit,omitempty"`
+	LimitPeriod     *int64   `json:"limitperiod,omitempty"`
+}
+
+// AccountXPubBundle describes a wallet account so a watch-only wallet can
+// track it.  XPub is the extended public key of the account, derived from the
+// master key with fingerprint MasterFingerprint, and ScriptType is one of
+// p2pkh, p2sh-p2wpkh or p2wpkh.  BirthHeight is the height a watch-only
+// wallet rescans from.
+type AccountXPubBundle struct {
+	Account           string `json:"account"`
+	AccountNumber     uint32 `json:"accountnumber"`
+	ScriptType        string `json:"scripttype"`
+	MasterFingerprint string `json:"masterfingerprint"`
+	XPub              string `json:"xpub"`
+	BirthHeight       int32  `json:"birthheight"`
+}
+
+// Modes of the sends scheduled by schedulesend, see package txschedule.
+const (
+	// ScheduleModeHold keeps the send and creates, signs and broadcasts
+	// its transaction once it is due.
+	ScheduleModeHold = "hold"
+
+	// ScheduleModeLockTime signs the transaction of the send right away
+	// with the time of the send as its lock time, and broadcasts it once
+	// it can be mined.
+	ScheduleModeLockTime = "locktime"
+)
+
+// Actions of the rules of a signer policy, see package signpolicy.
+const (
+	// SignerPolicyDeny refuses the sends a rule applies to.
+	SignerPolicyDeny = "deny"
+
+	// SignerPolicySecondFactor holds the sends a rule applies to until
+	// they are approved with a one-time password.
+	SignerPolicySecondFactor = "secondfactor"
+
+	// SignerPolicyDelay holds the sends a rule applies to for its delay,
+	// during which they can be cancelled.
+	SignerPolicyDelay = "delay"
+)
+
+// SignerPolicyRule is a rule of the signer policy of a wallet, applying to
+// the sends above Threshold, in BTC.  When Window, in seconds, is set the rule
+// is a spending limit and the threshold is compared to the total sent within
+// the window instead.  Action is one of deny, secondfactor or delay, and Delay
+// is the time in seconds the sends are held for by a delay rule.
+type SignerPolicyRule struct {
+	Name      string  `json:"name"`
+	Threshold float64 `json:"threshold"`
+	Window    int64   `json:"window,omitempty"`
+	Action    string  `json:"action"`
+	Delay     int64   `json:"delay,omitempty"`
+}
+
+// PaymentRecipient is a recipient of the payments of a payment template.
+// Amount is an expression in coins evaluated at each run, such as "250",
+// "5% * balance" or "(balance - 1000) / 4", where balance is the spendable
+// balance of the account paying.
+type PaymentRecipient struct {
+	Address string `json:"address"`
+	Amount  string `json:"amount"`
+	Label   string `json:"label,omitempty"`
+}
+
+// PaymentTemplate describes payments made by a wallet to a list of recipients
+// with a single transaction every Interval seconds, starting at Start, in
+// seconds since 1 Jan 1970 GMT, or right away when it is zero.  The fee is
+// chosen like the fee of sendmanybatch.  MaxFee, in BTC, fails a run whose fee
+// would exceed it, and SubtractFee subtracts the fee from the amounts paid.
+// A failed run is attempted again MaxRetries times, every RetryDelay seconds,
+// before it is given up until the next interval.  See package txschedule.
+type PaymentTemplate struct {
+	Name        string             `json:"name"`
+	Account     string             `json:"account"`
+	Recipients  []PaymentRecipient `json:"recipients"`
+	Interval    int64              `json:"interval"`
+	Start       int64              `json:"start,omitempty"`
+	MinConf     int32              `json:"minconf,omitempty"`
+	Fee         *BatchFeeOptions   `json:"fee,omitempty"`
+	MaxFee      *float64           `json:"maxfee,omitempty"`
+	SubtractFee bool               `json:"subtractfee,omitempty"`
+	MaxRetries  int                `json:"maxretries,omitempty"`
+	RetryDelay  int64              `json:"retrydelay,omitempty"`
+}
+
+// ApprovePendingSendCmd defines the approvependingsend JSON-RPC command.  It
+// approves a send held by the signer policy with a one-time password of the
+// second factor.  The send is signed and broadcast once its delay, if any, has
+// elapsed.
+type ApprovePendingSendCmd struct {
+	ID   uint64
+	Code string
+}
+
+// NewApprovePendingSendCmd returns a new instance which can be used to issue
+// an approvependingsend JSON-RPC command.
+func NewApprovePendingSendCmd(id uint64, code string) *ApprovePendingSendCmd {
+	return &ApprovePendingSendCmd{
+		ID:   id,
+		Code: code,
+	}
+}
+
+// CancelPendingSendCmd defines the cancelpendingsend JSON-RPC command.  It
+// drops a send held by the signer policy, which is never signed.
+type CancelPendingSendCmd struct {
+	ID uint64
+}
+
+// NewCancelPendingSendCmd returns a new instance which can be used to issue a
+// cancelpendingsend JSON-RPC command.
+func NewCancelPendingSendCmd(id uint64) *CancelPendingSendCmd {
+	return &CancelPendingSendCmd{
+		ID: id,
+	}
+}
+
+// CancelScheduledCmd defines the cancelscheduled JSON-RPC command.  It
+// unschedules a send scheduled by schedulesend and releases the inputs of its
+// signed transaction, if any.  A signed transaction which was already handed
+// out can still be broadcast by others.
+type CancelScheduledCmd struct {
+	ID uint64
+}
+
+// NewCancelScheduledCmd returns a new instance which can be used to issue a
+// cancelscheduled JSON-RPC command.
+func NewCancelScheduledCmd(id uint64) *CancelScheduledCmd {
+	return &CancelScheduledCmd{
+		ID: id,
+	}
+}
+
+// CreateNewAccountCmd defines the createnewaccount JSON-RPC command.  When
+// Parent is set, the account is created as a sub-account of it and is named
+// "parent/account".  Label is a free-form description of the account, such as
+// the customer it holds funds for.
 type CreateNewAccountCmd struct {
 	Account string
+	Parent  *string
+	Label   *string
 }
 
 // NewCreateNewAccountCmd returns a new instance which can be used to issue a
@@ -20,6 +213,106 @@ func NewCreateNewAccountCmd(account string) *CreateNewAccountCmd {
 	}
 }
 
+// NewCreateSubAccountCmd returns a new instance which can be used to issue a
+// createnewaccount JSON-RPC command creating a sub-account of parent.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewCreateSubAccountCmd(account, parent string, label *string) *CreateNewAccountCmd {
+	return &CreateNewAccountCmd{
+		Account: account,
+		Parent:  &parent,
+		Label:   label,
+	}
+}
+
+// WalletUnlockScope describes what a wallet unlocked with walletpassphrase may
+// be used for.
+type WalletUnlockScope string
+
+const (
+	// WalletUnlockScopeAll unlocks the wallet for every operation which
+	// requires the private keys, including dumping them.
+	WalletUnlockScopeAll WalletUnlockScope = "all"
+
+	// WalletUnlockScopeSigning unlocks the wallet for creating and signing
+	// transactions and messages only.  Private keys may not be exported.
+	WalletUnlockScopeSigning WalletUnlockScope = "signing"
+
+	// WalletUnlockScopeStaking unlocks the wallet only for signing which is
+	// done on behalf of the node, such as network steward votes, and never
+	// for spending to arbitrary outputs.
+	WalletUnlockScopeStaking WalletUnlockScope = "staking"
+)
+
+// CreatePaymentURICmd defines the createpaymenturi JSON-RPC command.  A new
+// receiving address of the default account is used when Address is omitted.
+// A zero Amount leaves the amount to the payer, like an omitted one.
+//
+// When Payjoin is true, the URI advertises the payjoin (BIP 0078) endpoint of
+// the wallet so the sender may add an input of the wallet to its payment.
+type CreatePaymentURICmd struct {
+	Amount  *float64
+	Label   *string
+	Message *string
+	Address *string
+	Payjoin *bool
+}
+
+// NewCreatePaymentURICmd returns a new instance which can be used to issue a
+// createpaymenturi JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewCreatePaymentURICmd(amount *float64, label, message, address *string) *CreatePaymentURICmd {
+	return &CreatePaymentURICmd{
+		Amount:  amount,
+		Label:   label,
+		Message: message,
+		Address: address,
+	}
+}
+
+// CreateSigningPackageCmd defines the createsigningpackage JSON-RPC command.
+type CreateSigningPackageCmd struct {
+	HexTx  string
+	Format *string `jsonrpcdefault:"\"psbt\""`
+}
+
+// NewCreateSigningPackageCmd returns a new instance which can be used to issue
+// a createsigningpackage JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewCreateSigningPackageCmd(hexTx string, format *string) *CreateSigningPackageCmd {
+	return &CreateSigningPackageCmd{
+		HexTx:  hexTx,
+		Format: format,
+	}
+}
+
+// CreateTimeLockAddressCmd defines the createtimelockaddress JSON-RPC command.
+// It creates an address of the account whose outputs can only be spent once
+// LockTime is reached, see txscript.TimeLockScript.
+type CreateTimeLockAddressCmd struct {
+	LockTime int64
+	Relative *bool `jsonrpcdefault:"false"`
+	Account  *string
+}
+
+// NewCreateTimeLockAddressCmd returns a new instance which can be used to
+// issue a createtimelockaddress JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewCreateTimeLockAddressCmd(lockTime int64, relative *bool, account *string) *CreateTimeLockAddressCmd {
+	return &CreateTimeLockAddressCmd{
+		LockTime: lockTime,
+		Relative: relative,
+		Account:  account,
+	}
+}
+
 // DumpWalletCmd defines the dumpwallet JSON-RPC command.
 type DumpWalletCmd struct {
 	Filename string
@@ -33,6 +326,146 @@ func NewDumpWalletCmd(filename string) *DumpWalletCmd {
 	}
 }
 
+// EnumerateSignersCmd defines the enumeratesigners JSON-RPC command.
+type EnumerateSignersCmd struct{}
+
+// NewEnumerateSignersCmd returns a new instance which can be used to issue an
+// enumeratesigners JSON-RPC command.
+func NewEnumerateSignersCmd() *EnumerateSignersCmd {
+	return &EnumerateSignersCmd{}
+}
+
+// ExportAccountXPubCmd defines the exportaccountxpub JSON-RPC command.  It
+// returns the extended public key and descriptors of an account, to be
+// imported by importaccountxpub into a watch-only wallet.
+type ExportAccountXPubCmd struct {
+	Account string
+}
+
+// NewExportAccountXPubCmd returns a new instance which can be used to issue an
+// exportaccountxpub JSON-RPC command.
+func NewExportAccountXPubCmd(account string) *ExportAccountXPubCmd {
+	return &ExportAccountXPubCmd{
+		Account: account,
+	}
+}
+
+// ExportTxMemosCmd defines the exporttxmemos JSON-RPC command.  It returns the
+// memos of every transaction of the wallet, base64 encoded and encrypted with
+// the passphrase, to be imported by importtxmemos.
+type ExportTxMemosCmd struct {
+	Passphrase string
+}
+
+// NewExportTxMemosCmd returns a new instance which can be used to issue an
+// exporttxmemos JSON-RPC command.
+func NewExportTxMemosCmd(passphrase string) *ExportTxMemosCmd {
+	return &ExportTxMemosCmd{
+		Passphrase: passphrase,
+	}
+}
+
+// GetAccountPolicyCmd defines the getaccountpolicy JSON-RPC command.
+type GetAccountPolicyCmd struct {
+	Account string
+}
+
+// NewGetAccountPolicyCmd returns a new instance which can be used to issue a
+// getaccountpolicy JSON-RPC command.
+func NewGetAccountPolicyCmd(account string) *GetAccountPolicyCmd {
+	return &GetAccountPolicyCmd{
+		Account: account,
+	}
+}
+
+// GetAddressInfoCmd defines the getaddressinfo JSON-RPC command.  It reports
+// everything the wallet knows about an address, which validateaddress only
+// partially covers.
+type GetAddressInfoCmd struct {
+	Address string
+}
+
+// NewGetAddressInfoCmd returns a new instance which can be used to issue a
+// getaddressinfo JSON-RPC command.
+func NewGetAddressInfoCmd(address string) *GetAddressInfoCmd {
+	return &GetAddressInfoCmd{
+		Address: address,
+	}
+}
+
+// GetPaymentReportCmd defines the getpaymentreport JSON-RPC command.  It
+// reports the last Count completed runs of the payment template named Name, or
+// of every template when it is nil, most recent first.
+type GetPaymentReportCmd struct {
+	Name  *string
+	Count *int `jsonrpcdefault:"50"`
+}
+
+// NewGetPaymentReportCmd returns a new instance which can be used to issue a
+// getpaymentreport JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetPaymentReportCmd(name *string, count *int) *GetPaymentReportCmd {
+	return &GetPaymentReportCmd{
+		Name:  name,
+		Count: count,
+	}
+}
+
+// GetRecoveryInfoCmd defines the getrecoveryinfo JSON-RPC command.
+type GetRecoveryInfoCmd struct{}
+
+// NewGetRecoveryInfoCmd returns a new instance which can be used to issue a
+// getrecoveryinfo JSON-RPC command.
+func NewGetRecoveryInfoCmd() *GetRecoveryInfoCmd {
+	return &GetRecoveryInfoCmd{}
+}
+
+// GetSignerPolicyCmd defines the getsignerpolicy JSON-RPC command.
+type GetSignerPolicyCmd struct{}
+
+// NewGetSignerPolicyCmd returns a new instance which can be used to issue a
+// getsignerpolicy JSON-RPC command.
+func NewGetSignerPolicyCmd() *GetSignerPolicyCmd {
+	return &GetSignerPolicyCmd{}
+}
+
+// GetWalletLockStateCmd defines the getwalletlockstate JSON-RPC command.
+type GetWalletLockStateCmd struct{}
+
+// NewGetWalletLockStateCmd returns a new instance which can be used to issue
+// a getwalletlockstate JSON-RPC command.
+func NewGetWalletLockStateCmd() *GetWalletLockStateCmd {
+	return &GetWalletLockStateCmd{}
+}
+
+// ImportAccountXPubCmd defines the importaccountxpub JSON-RPC command.  It
+// pairs a watch-only wallet with a signing wallet by creating a watch-only
+// account tracking the addresses of the exported account.  The account is
+// named after the exported one unless Account is set, and the chain is
+// rescanned from the birth height of the bundle when Rescan is true.
+type ImportAccountXPubCmd struct {
+	Bundle  AccountXPubBundle `jsonrpcusage:"{\"account\":\"name\",\"accountnumber\":n,\"scripttype\":\"type\",\"masterfingerprint\":\"hex\",\"xpub\":\"xpub\",\"birthheight\":n}"`
+	Account *string
+	Rescan  *bool `jsonrpcdefault:"true"`
+}
+
+// NewImportAccountXPubCmd returns a new instance which can be used to issue an
+// importaccountxpub JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewImportAccountXPubCmd(bundle AccountXPubBundle, account *string,
+	rescan *bool) *ImportAccountXPubCmd {
+
+	return &ImportAccountXPubCmd{
+		Bundle:  bundle,
+		Account: account,
+		Rescan:  rescan,
+	}
+}
+
 // ImportAddressCmd defines the importaddress JSON-RPC command.
 type ImportAddressCmd struct {
 	Address string
@@ -50,10 +483,41 @@ func NewImportAddressCmd(address string, account string, rescan *bool) *ImportAd
 	}
 }
 
-// ImportPubKeyCmd defines the importpubkey JSON-RPC command.
+// ImportCoreWalletCmd defines the importcorewallet JSON-RPC command.  It
+// imports the keys, scripts and labels of a wallet export of the reference
+// client read from Filename, in one of the CoreWalletFormat formats, into the
+// account.  The material which does not map to pktwallet is skipped and
+// reported in the result.
+type ImportCoreWalletCmd struct {
+	Filename string
+	Format   *string `jsonrpcdefault:"\"dump\""`
+	Account  *string `jsonrpcdefault:"\"imported\""`
+	Rescan   *bool   `jsonrpcdefault:"true"`
+}
+
+// NewImportCoreWalletCmd returns a new instance which can be used to issue an
+// importcorewallet JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewImportCoreWalletCmd(filename string, format, account *string,
+	rescan *bool) *ImportCoreWalletCmd {
+
+	return &ImportCoreWalletCmd{
+		Filename: filename,
+		Format:   format,
+		Account:  account,
+		Rescan:   rescan,
+	}
+}
+
+// ImportPubKeyCmd defines the importpubkey JSON-RPC command.  KeyOrigin is the
+// origin of the key in the [fingerprint/path] form of descriptors, which the
+// wallet records so signers can match the key.
 type ImportPubKeyCmd struct {
-	PubKey string
-	Rescan *bool `jsonrpcdefault:"true"`
+	PubKey    string
+	Rescan    *bool `jsonrpcdefault:"true"`
+	KeyOrigin *string
 }
 
 // NewImportPubKeyCmd returns a new instance which can be used to issue an
@@ -65,6 +529,45 @@ func NewImportPubKeyCmd(pubKey string, rescan *bool) *ImportPubKeyCmd {
 	}
 }
 
+// ImportSignaturesCmd defines the importsignatures JSON-RPC command.  It
+// merges the signatures of a signed package into the transaction and returns
+// it, finalized when every input is signed.
+type ImportSignaturesCmd struct {
+	Package string
+}
+
+// NewImportSignaturesCmd returns a new instance which can be used to issue an
+// importsignatures JSON-RPC command.
+func NewImportSignaturesCmd(pkg string) *ImportSignaturesCmd {
+	return &ImportSignaturesCmd{
+		Package: pkg,
+	}
+}
+
+// ImportTxMemosCmd defines the importtxmemos JSON-RPC command.  It imports
+// the memos of an export made by exporttxmemos.  The memos of transactions
+// which already have one are skipped unless Overwrite is true.  Memos of
+// transactions unknown to the wallet are kept so they are shown once the
+// transactions are found by a rescan.
+type ImportTxMemosCmd struct {
+	Data       string
+	Passphrase string
+	Overwrite  *bool `jsonrpcdefault:"false"`
+}
+
+// NewImportTxMemosCmd returns a new instance which can be used to issue an
+// importtxmemos JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewImportTxMemosCmd(data, passphrase string, overwrite *bool) *ImportTxMemosCmd {
+	return &ImportTxMemosCmd{
+		Data:       data,
+		Passphrase: passphrase,
+		Overwrite:  overwrite,
+	}
+}
+
 // ImportWalletCmd defines the importwallet JSON-RPC command.
 type ImportWalletCmd struct {
 	Filename string
@@ -78,6 +581,93 @@ func NewImportWalletCmd(filename string) *ImportWalletCmd {
 	}
 }
 
+// ListKeyOriginsCmd defines the listkeyorigins JSON-RPC command.  It lists the
+// origin of every key of the wallet, or of the account when Account is set,
+// so the key tree can be rebuilt by an audit.
+type ListKeyOriginsCmd struct {
+	Account *string
+}
+
+// NewListKeyOriginsCmd returns a new instance which can be used to issue a
+// listkeyorigins JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewListKeyOriginsCmd(account *string) *ListKeyOriginsCmd {
+	return &ListKeyOriginsCmd{
+		Account: account,
+	}
+}
+
+// ListPaymentTemplatesCmd defines the listpaymenttemplates JSON-RPC command.  It
+// lists the payment templates and the state of their current runs.
+type ListPaymentTemplatesCmd struct{}
+
+// NewListPaymentTemplatesCmd returns a new instance which can be used to issue
+// a listpaymenttemplates JSON-RPC command.
+func NewListPaymentTemplatesCmd() *ListPaymentTemplatesCmd {
+	return &ListPaymentTemplatesCmd{}
+}
+
+// ListPendingSendsCmd defines the listpendingsends JSON-RPC command.  It lists
+// the sends held by the signer policy.
+type ListPendingSendsCmd struct{}
+
+// NewListPendingSendsCmd returns a new instance which can be used to issue a
+// listpendingsends JSON-RPC command.
+func NewListPendingSendsCmd() *ListPendingSendsCmd {
+	return &ListPendingSendsCmd{}
+}
+
+// ListScheduledCmd defines the listscheduled JSON-RPC command.  It lists the
+// sends scheduled by schedulesend.
+type ListScheduledCmd struct{}
+
+// NewListScheduledCmd returns a new instance which can be used to issue a
+// listscheduled JSON-RPC command.
+func NewListScheduledCmd() *ListScheduledCmd {
+	return &ListScheduledCmd{}
+}
+
+// ProveAddressOwnershipCmd defines the proveaddressownership JSON-RPC command.
+// It creates a proof, see package reserveproof, that the wallet controls every
+// unspent output with at least MinConf confirmations paying to the passed
+// addresses, without spending them.  The proof commits to Message and can be
+// checked by anyone with the verifyaddressownership command of a node.
+type ProveAddressOwnershipCmd struct {
+	Addresses []string
+	Message   string
+	MinConf   *int `jsonrpcdefault:"1"`
+}
+
+// NewProveAddressOwnershipCmd returns a new instance which can be used to
+// issue a proveaddressownership JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewProveAddressOwnershipCmd(addresses []string, message string, minConf *int) *ProveAddressOwnershipCmd {
+	return &ProveAddressOwnershipCmd{
+		Addresses: addresses,
+		Message:   message,
+		MinConf:   minConf,
+	}
+}
+
+// RemovePaymentTemplateCmd defines the removepaymenttemplate JSON-RPC command.
+// It stops the runs of a payment template.  Its completed runs are still
+// reported.
+type RemovePaymentTemplateCmd struct {
+	Name string
+}
+
+// NewRemovePaymentTemplateCmd returns a new instance which can be used to
+// issue a removepaymenttemplate JSON-RPC command.
+func NewRemovePaymentTemplateCmd(name string) *RemovePaymentTemplateCmd {
+	return &RemovePaymentTemplateCmd{
+		Name: name,
+	}
+}
+
 // RenameAccountCmd defines the renameaccount JSON-RPC command.
 type RenameAccountCmd struct {
 	OldAccount string
@@ -93,14 +683,402 @@ func NewRenameAccountCmd(oldAccount, newAccount string) *RenameAccountCmd {
 	}
 }
 
+// ScheduleSendCmd defines the schedulesend JSON-RPC command.  It schedules
+// paying Amounts from FromAccount at At, a block height below 500000000 and a
+// Unix time otherwise, like a transaction lock time.  Mode is one of hold or
+// locktime, see package txschedule.  The send is repeated every Every blocks
+// or seconds, the same unit as At, for Count payments in total, or until it is
+// cancelled when Count is 0.
+type ScheduleSendCmd struct {
+	FromAccount string
+	Amounts     map[string]float64 `jsonrpcusage:"{\"address\":amount,...}"` // In BTC
+	At          int64
+	Mode        *string `jsonrpcdefault:"\"hold\""`
+	Every       *int64  `jsonrpcdefault:"0"`
+	Count       *int    `jsonrpcdefault:"1"`
+	MinConf     *int    `jsonrpcdefault:"1"`
+	Comment     *string
+}
+
+// NewScheduleSendCmd returns a new instance which can be used to issue a
+// schedulesend JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.  Since the optional
+// parameters are positional, the ones preceding a passed interval or count are
+// given their defaults.
+func NewScheduleSendCmd(fromAccount string, amounts map[string]float64, at int64,
+	mode *string, every *int64, count *int) *ScheduleSendCmd {
+
+	cmd := &ScheduleSendCmd{
+		FromAccount: fromAccount,
+		Amounts:     amounts,
+		At:          at,
+		Mode:        mode,
+		Every:       every,
+		Count:       count,
+	}
+	if count != nil && cmd.Every == nil {
+		cmd.Every = Int64(0)
+	}
+	if cmd.Every != nil && cmd.Mode == nil {
+		cmd.Mode = String(ScheduleModeHold)
+	}
+	return cmd
+}
+
+// SetAccountCredentialsCmd defines the setaccountcredentials JSON-RPC command.
+// It sets RPC credentials which only give access to the account and its
+// sub-accounts, so a service holding them can not touch the funds of any
+// other account.  A nil Password removes the credentials of the account.
+type SetAccountCredentialsCmd struct {
+	Account  string
+	Username string
+	Password *string
+}
+
+// NewSetAccountCredentialsCmd returns a new instance which can be used to
+// issue a setaccountcredentials JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewSetAccountCredentialsCmd(account, username string, password *string) *SetAccountCredentialsCmd {
+	return &SetAccountCredentialsCmd{
+		Account:  account,
+		Username: username,
+		Password: password,
+	}
+}
+
+// SetAccountPolicyCmd defines the setaccountpolicy JSON-RPC command.  The
+// passed policy replaces the current policy of the account.
+type SetAccountPolicyCmd struct {
+	Account string
+	Policy  AccountPolicy `jsonrpcusage:"{\"maxfeerate\":n,\"maxfee\":n,\"withdrawallimit\":n,\"limitperiod\":n}"`
+}
+
+// NewSetAccountPolicyCmd returns a new instance which can be used to issue a
+// setaccountpolicy JSON-RPC command.
+func NewSetAccountPolicyCmd(account string, policy AccountPolicy) *SetAccountPolicyCmd {
+	return &SetAccountPolicyCmd{
+		Account: account,
+		Policy:  policy,
+	}
+}
+
+// SetPaymentTemplateCmd defines the setpaymenttemplate JSON-RPC command.  It
+// adds a payment template, or replaces the template with the same name, whose
+// current run is then rescheduled.  The amount expressions are checked when
+// the template is set.
+type SetPaymentTemplateCmd struct {
+	Template PaymentTemplate `jsonrpcusage:"{\"name\":\"name\",\"account\":\"account\",\"recipients\":[{\"address\":\"addr\",\"amount\":\"expr\"},...],\"interval\":n,...}"`
+}
+
+// NewSetPaymentTemplateCmd returns a new instance which can be used to issue a
+// setpaymenttemplate JSON-RPC command.
+func NewSetPaymentTemplateCmd(template PaymentTemplate) *SetPaymentTemplateCmd {
+	return &SetPaymentTemplateCmd{
+		Template: template,
+	}
+}
+
+// SetSignerPolicyCmd defines the setsignerpolicy JSON-RPC command.  The passed
+// rules replace the signer policy of the wallet, which every send must satisfy
+// before it is signed.  SecondFactorSecret is the base32 secret of the
+// one-time passwords required by the secondfactor rules: nil keeps the current
+// one and an empty secret removes it.  Once a second factor is configured,
+// Code must be one of its one-time passwords.
+type SetSignerPolicyCmd struct {
+	Rules              []SignerPolicyRule `jsonrpcusage:"[{\"name\":\"str\",\"threshold\":n,\"window\":n,\"action\":\"deny|secondfactor|delay\",\"delay\":n},...]"`
+	SecondFactorSecret *string
+	Code               *string
+}
+
+// NewSetSignerPolicyCmd returns a new instance which can be used to issue a
+// setsignerpolicy JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewSetSignerPolicyCmd(rules []SignerPolicyRule, secondFactorSecret,
+	code *string) *SetSignerPolicyCmd {
+
+	return &SetSignerPolicyCmd{
+		Rules:              rules,
+		SecondFactorSecret: secondFactorSecret,
+		Code:               code,
+	}
+}
+
+// SetTxMemoCmd defines the settxmemo JSON-RPC command.  It replaces the memo
+// and payment metadata of a wallet transaction, which are reported by
+// gettransaction and listtransactions.  An empty memo without metadata
+// removes the memo.
+type SetTxMemoCmd struct {
+	TxID     string
+	Memo     string
+	Metadata *map[string]string `jsonrpcusage:"{\"key\":\"value\",...}"`
+}
+
+// NewSetTxMemoCmd returns a new instance which can be used to issue a
+// settxmemo JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewSetTxMemoCmd(txID, memo string, metadata *map[string]string) *SetTxMemoCmd {
+	return &SetTxMemoCmd{
+		TxID:     txID,
+		Memo:     memo,
+		Metadata: metadata,
+	}
+}
+
+// MaxBatchOutputs is the maximum number of outputs which may be paid by a
+// single sendmanybatch command.
+const MaxBatchOutputs = 2500
+
+// BatchOutput is a single payment of a sendmanybatch command.  Label is an
+// optional caller reference, such as a withdrawal id, which is echoed back in
+// the result so payments can be matched to their outputs.
+type BatchOutput struct {
+	Address string  `json:"address"`
+	Amount  float64 `json:"amount"`
+	Label   string  `json:"label,omitempty"`
+}
+
+// BatchFeeOptions selects the fee of a sendmanybatch transaction.  The fee is
+// chosen to confirm within ConfTarget blocks unless FeeRate, in BTC/kB, is
+// given explicitly.  At most one of the two may be set.
+type BatchFeeOptions struct {
+	ConfTarget *int32   `json:"conftarget,omitempty"`
+	FeeRate    *float64 `json:"feerate,omitempty"`
+}
+
+// SendManyBatchCmd defines the sendmanybatch JSON-RPC command.  It is a
+// variant of sendmany for batch payout systems which takes an ordered list of
+// up to MaxBatchOutputs payments.
+//
+// Every address is validated before any coins are selected and the command
+// fails naming the first bad one.  Payments to the same address are merged
+// into a single output unless MergeDuplicates is false, in which case they
+// are rejected.  When Fee is nil the wallet's default fee rate is used.
+type SendManyBatchCmd struct {
+	FromAccount     string
+	Outputs         []BatchOutput `jsonrpcusage:"[{\"address\":\"addr\",\"amount\":n,\"label\":\"str\"},...]"`
+	MinConf         *int          `jsonrpcdefault:"1"`
+	MergeDuplicates *bool         `jsonrpcdefault:"true"`
+	Fee             *BatchFeeOptions
+	Change          *ChangeOptions
+	Comment         *string
+}
+
+// NewSendManyBatchCmd returns a new instance which can be used to issue a
+// sendmanybatch JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.  Since the optional
+// parameters are positional, the ones preceding a passed fee or change are
+// given their defaults.
+func NewSendManyBatchCmd(fromAccount string, outputs []BatchOutput, minConf *int,
+	fee *BatchFeeOptions, change *ChangeOptions) *SendManyBatchCmd {
+
+	cmd := &SendManyBatchCmd{
+		FromAccount: fromAccount,
+		Outputs:     outputs,
+		MinConf:     minConf,
+		Fee:         fee,
+		Change:      change,
+	}
+	if change != nil && cmd.Fee == nil {
+		cmd.Fee = &BatchFeeOptions{}
+	}
+	if cmd.Fee != nil {
+		if cmd.MinConf == nil {
+			cmd.MinConf = Int(1)
+		}
+		cmd.MergeDuplicates = Bool(true)
+	}
+	return cmd
+}
+
+// SendPayjoinCmd defines the sendpayjoin JSON-RPC command.  It pays a payment
+// URI and, when the URI advertises a payjoin (BIP 0078) endpoint, lets the
+// receiver add one of its inputs to the transaction.  Amounts are additional
+// payments made by the same transaction.
+//
+// MaxFeeContribution is the most the change output may be decreased by to pay
+// for the fee of the inputs of the receiver.  The original transaction is
+// broadcast instead when the receiver fails or its proposal is invalid.
+type SendPayjoinCmd struct {
+	URI                       string
+	Amounts                   *map[string]float64 `jsonrpcusage:"{\"address\":amount,...}"`
+	FromAccount               *string             `jsonrpcdefault:"\"default\""`
+	MinConf                   *int                `jsonrpcdefault:"1"`
+	MaxFeeContribution        *float64
+	DisableOutputSubstitution *bool `jsonrpcdefault:"false"`
+}
+
+// NewSendPayjoinCmd returns a new instance which can be used to issue a
+// sendpayjoin JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewSendPayjoinCmd(uri string, amounts *map[string]float64,
+	fromAccount *string, minConf *int, maxFeeContribution *float64,
+	disableOutputSubstitution *bool) *SendPayjoinCmd {
+
+	return &SendPayjoinCmd{
+		URI:                       uri,
+		Amounts:                   amounts,
+		FromAccount:               fromAccount,
+		MinConf:                   minConf,
+		MaxFeeContribution:        maxFeeContribution,
+		DisableOutputSubstitution: disableOutputSubstitution,
+	}
+}
+
+// WalletFlagAvoidReuse is the setwalletflag flag which keeps coin selection
+// from spending outputs to reused addresses together with fresh outputs.
+const WalletFlagAvoidReuse = "avoid_reuse"
+
+// SetWalletFlagCmd defines the setwalletflag JSON-RPC command.
+type SetWalletFlagCmd struct {
+	Flag  string
+	Value *bool `jsonrpcdefault:"true"`
+}
+
+// NewSetWalletFlagCmd returns a new instance which can be used to issue a
+// setwalletflag JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewSetWalletFlagCmd(flag string, value *bool) *SetWalletFlagCmd {
+	return &SetWalletFlagCmd{
+		Flag:  flag,
+		Value: value,
+	}
+}
+
+// SignSigningPackageCmd defines the signsigningpackage JSON-RPC command.  It
+// adds signatures for every input the wallet has keys for, using only the
+// metadata in the package, so it works on a wallet without chain access.
+type SignSigningPackageCmd struct {
+	Package string
+}
+
+// NewSignSigningPackageCmd returns a new instance which can be used to issue a
+// signsigningpackage JSON-RPC command.
+func NewSignSigningPackageCmd(pkg string) *SignSigningPackageCmd {
+	return &SignSigningPackageCmd{
+		Package: pkg,
+	}
+}
+
+// StartRecoveryCmd defines the startrecovery JSON-RPC command.  It rescans
+// the chain from StartHeight for every account and script type, deriving
+// addresses up to GapLimit past the last one used.  Whenever an address is
+// found in the chain the lookahead window is extended past it, so addresses
+// beyond the normal gap limit of a busy wallet are still recovered.
+type StartRecoveryCmd struct {
+	GapLimit    *int32 `jsonrpcdefault:"250"`
+	StartHeight *int32 `jsonrpcdefault:"0"`
+}
+
+// NewStartRecoveryCmd returns a new instance which can be used to issue a
+// startrecovery JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewStartRecoveryCmd(gapLimit, startHeight *int32) *StartRecoveryCmd {
+	return &StartRecoveryCmd{
+		GapLimit:    gapLimit,
+		StartHeight: startHeight,
+	}
+}
+
+// VerifyWalletBackupCmd defines the verifywalletbackup JSON-RPC command.  It
+// checks that a backup can be decrypted with the current passphrases.  When
+// Path is omitted the most recent automatic backup is checked.
+type VerifyWalletBackupCmd struct {
+	Path *string
+}
+
+// NewVerifyWalletBackupCmd returns a new instance which can be used to issue a
+// verifywalletbackup JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewVerifyWalletBackupCmd(path *string) *VerifyWalletBackupCmd {
+	return &VerifyWalletBackupCmd{
+		Path: path,
+	}
+}
+
+// WalletPubPassphraseChangeCmd defines the walletpubpassphrasechange JSON-RPC
+// command.  It changes the viewing passphrase which protects the public
+// metadata of the wallet (addresses, transactions and balances) without
+// affecting the spending passphrase which protects the private keys.  Only
+// the master key encrypting the metadata is re-wrapped so the wallet database
+// is not rewritten.
+type WalletPubPassphraseChangeCmd struct {
+	OldPassphrase string
+	NewPassphrase string
+}
+
+// NewWalletPubPassphraseChangeCmd returns a new instance which can be used to
+// issue a walletpubpassphrasechange JSON-RPC command.
+func NewWalletPubPassphraseChangeCmd(oldPassphrase, newPassphrase string) *WalletPubPassphraseChangeCmd {
+	return &WalletPubPassphraseChangeCmd{
+		OldPassphrase: oldPassphrase,
+		NewPassphrase: newPassphrase,
+	}
+}
+
 func init() {
 	// The commands in this file are only usable with a wallet server.
 	flags := UFWalletOnly
 
+	MustRegisterCmd("approvependingsend", (*ApprovePendingSendCmd)(nil), flags)
+	MustRegisterCmd("cancelpendingsend", (*CancelPendingSendCmd)(nil), flags)
+	MustRegisterCmd("cancelscheduled", (*CancelScheduledCmd)(nil), flags)
 	MustRegisterCmd("createnewaccount", (*CreateNewAccountCmd)(nil), flags)
+	MustRegisterCmd("createpaymenturi", (*CreatePaymentURICmd)(nil), flags)
+	MustRegisterCmd("createsigningpackage", (*CreateSigningPackageCmd)(nil), flags)
+	MustRegisterCmd("createtimelockaddress", (*CreateTimeLockAddressCmd)(nil), flags)
 	MustRegisterCmd("dumpwallet", (*DumpWalletCmd)(nil), flags)
+	MustRegisterCmd("enumeratesigners", (*EnumerateSignersCmd)(nil), flags)
+	MustRegisterCmd("exportaccountxpub", (*ExportAccountXPubCmd)(nil), flags)
+	MustRegisterCmd("exporttxmemos", (*ExportTxMemosCmd)(nil), flags)
+	MustRegisterCmd("getaccountpolicy", (*GetAccountPolicyCmd)(nil), flags)
+	MustRegisterCmd("getaddressinfo", (*GetAddressInfoCmd)(nil), flags)
+	MustRegisterCmd("getpaymentreport", (*GetPaymentReportCmd)(nil), flags)
+	MustRegisterCmd("getrecoveryinfo", (*GetRecoveryInfoCmd)(nil), flags)
+	MustRegisterCmd("getsignerpolicy", (*GetSignerPolicyCmd)(nil), flags)
+	MustRegisterCmd("getwalletlockstate", (*GetWalletLockStateCmd)(nil), flags)
+	MustRegisterCmd("importaccountxpub", (*ImportAccountXPubCmd)(nil), flags)
 	MustRegisterCmd("importaddress", (*ImportAddressCmd)(nil), flags)
+	MustRegisterCmd("importcorewallet", (*ImportCoreWalletCmd)(nil), flags)
 	MustRegisterCmd("importpubkey", (*ImportPubKeyCmd)(nil), flags)
+	MustRegisterCmd("importsignatures", (*ImportSignaturesCmd)(nil), flags)
+	MustRegisterCmd("importtxmemos", (*ImportTxMemosCmd)(nil), flags)
 	MustRegisterCmd("importwallet", (*ImportWalletCmd)(nil), flags)
+	MustRegisterCmd("listkeyorigins", (*ListKeyOriginsCmd)(nil), flags)
+	MustRegisterCmd("listpaymenttemplates", (*ListPaymentTemplatesCmd)(nil), flags)
+	MustRegisterCmd("listpendingsends", (*ListPendingSendsCmd)(nil), flags)
+	MustRegisterCmd("listscheduled", (*ListScheduledCmd)(nil), flags)
+	MustRegisterCmd("proveaddressownership", (*ProveAddressOwnershipCmd)(nil), flags)
+	MustRegisterCmd("removepaymenttemplate", (*RemovePaymentTemplateCmd)(nil), flags)
 	MustRegisterCmd("renameaccount", (*RenameAccountCmd)(nil), flags)
+	MustRegisterCmd("schedulesend", (*ScheduleSendCmd)(nil), flags)
+	MustRegisterCmd("setaccountcredentials", (*SetAccountCredentialsCmd)(nil), flags)
+	MustRegisterCmd("setaccountpolicy", (*SetAccountPolicyCmd)(nil), flags)
+	MustRegisterCmd("setpaymenttemplate", (*SetPaymentTemplateCmd)(nil), flags)
+	MustRegisterCmd("sendmanybatch", (*SendManyBatchCmd)(nil), flags)
+	MustRegisterCmd("sendpayjoin", (*SendPayjoinCmd)(nil), flags)
+	MustRegisterCmd("setsignerpolicy", (*SetSignerPolicyCmd)(nil), flags)
+	MustRegisterCmd("settxmemo", (*SetTxMemoCmd)(nil), flags)
+	MustRegisterCmd("setwalletflag", (*SetWalletFlagCmd)(nil), flags)
+	MustRegisterCmd("signsigningpackage", (*SignSigningPackageCmd)(nil), flags)
+	MustRegisterCmd("startrecovery", (*StartRecoveryCmd)(nil), flags)
+	MustRegisterCmd("verifywalletbackup", (*VerifyWalletBackupCmd)(nil), flags)
+	MustRegisterCmd("walletpubpassphrasechange", (*WalletPubPassphraseChangeCmd)(nil), flags)
 }
@@ -29,6 +29,46 @@ func TestBtcWalletExtCmds(t *testing.T) {
 		marshalled   string
 		unmarshalled interface{}
 	}{
+		{
+			name: "approvependingsend",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("approvependingsend", 3, "287082")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewApprovePendingSendCmd(3, "287082")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"approvependingsend","params":[3,"287082"],"id":1}`,
+			unmarshalled: &btcjson.ApprovePendingSendCmd{
+				ID:   3,
+				Code: "287082",
+			},
+		},
+		{
+			name: "cancelpendingsend",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("cancelpendingsend", 3)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewCancelPendingSendCmd(3)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"cancelpendingsend","params":[3],"id":1}`,
+			unmarshalled: &btcjson.CancelPendingSendCmd{
+				ID: 3,
+			},
+		},
+		{
+			name: "cancelscheduled",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("cancelscheduled", 4)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewCancelScheduledCmd(4)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"cancelscheduled","params":[4],"id":1}`,
+			unmarshalled: &btcjson.CancelScheduledCmd{
+				ID: 4,
+			},
+		},
 		{
 			name: "createnewaccount",
 			newCmd: func() (interface{}, error) {
@@ -42,6 +82,132 @@ func TestBtcWalletExtCmds(t *testing.T) {
 				Account: "acct",
 			},
 		},
+		{
+			name: "createnewaccount sub-account",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("createnewaccount", "alice", "customers",
+					"Alice Ltd")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewCreateSubAccountCmd("alice", "customers",
+					btcjson.String("Alice Ltd"))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"createnewaccount","params":["alice","customers","Alice Ltd"],"id":1}`,
+			unmarshalled: &btcjson.CreateNewAccountCmd{
+				Account: "alice",
+				Parent:  btcjson.String("customers"),
+				Label:   btcjson.String("Alice Ltd"),
+			},
+		},
+		{
+			name: "createpaymenturi",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("createpaymenturi")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewCreatePaymentURICmd(nil, nil, nil, nil)
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"createpaymenturi","params":[],"id":1}`,
+			unmarshalled: &btcjson.CreatePaymentURICmd{},
+		},
+		{
+			name: "createpaymenturi optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("createpaymenturi", 1.5, "label", "message", "1Address")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewCreatePaymentURICmd(btcjson.Float64(1.5),
+					btcjson.String("label"), btcjson.String("message"),
+					btcjson.String("1Address"))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"createpaymenturi","params":[1.5,"label","message","1Address"],"id":1}`,
+			unmarshalled: &btcjson.CreatePaymentURICmd{
+				Amount:  btcjson.Float64(1.5),
+				Label:   btcjson.String("label"),
+				Message: btcjson.String("message"),
+				Address: btcjson.String("1Address"),
+			},
+		},
+		{
+			name: "createpaymenturi payjoin",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("createpaymenturi", 1.5, "label", "message", "1Address", true)
+			},
+			staticCmd: func() interface{} {
+				cmd := btcjson.NewCreatePaymentURICmd(btcjson.Float64(1.5),
+					btcjson.String("label"), btcjson.String("message"),
+					btcjson.String("1Address"))
+				cmd.Payjoin = btcjson.Bool(true)
+				return cmd
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"createpaymenturi","params":[1.5,"label","message","1Address",true],"id":1}`,
+			unmarshalled: &btcjson.CreatePaymentURICmd{
+				Amount:  btcjson.Float64(1.5),
+				Label:   btcjson.String("label"),
+				Message: btcjson.String("message"),
+				Address: btcjson.String("1Address"),
+				Payjoin: btcjson.Bool(true),
+			},
+		},
+		{
+			name: "createsigningpackage",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("createsigningpackage", "001122")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewCreateSigningPackageCmd("001122", nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"createsigningpackage","params":["001122"],"id":1}`,
+			unmarshalled: &btcjson.CreateSigningPackageCmd{
+				HexTx:  "001122",
+				Format: btcjson.String("psbt"),
+			},
+		},
+		{
+			name: "createsigningpackage optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("createsigningpackage", "001122", "json")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewCreateSigningPackageCmd("001122",
+					btcjson.String(btcjson.SigningPackageFormatJSON))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"createsigningpackage","params":["001122","json"],"id":1}`,
+			unmarshalled: &btcjson.CreateSigningPackageCmd{
+				HexTx:  "001122",
+				Format: btcjson.String("json"),
+			},
+		},
+		{
+			name: "createtimelockaddress",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("createtimelockaddress", 500000)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewCreateTimeLockAddressCmd(500000, nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"createtimelockaddress","params":[500000],"id":1}`,
+			unmarshalled: &btcjson.CreateTimeLockAddressCmd{
+				LockTime: 500000,
+				Relative: btcjson.Bool(false),
+			},
+		},
+		{
+			name: "createtimelockaddress optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("createtimelockaddress", 144, true, "savings")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewCreateTimeLockAddressCmd(144, btcjson.Bool(true),
+					btcjson.String("savings"))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"createtimelockaddress","params":[144,true,"savings"],"id":1}`,
+			unmarshalled: &btcjson.CreateTimeLockAddressCmd{
+				LockTime: 144,
+				Relative: btcjson.Bool(true),
+				Account:  btcjson.String("savings"),
+			},
+		},
 		{
 			name: "dumpwallet",
 			newCmd: func() (interface{}, error) {
@@ -55,6 +221,181 @@ func TestBtcWalletExtCmds(t *testing.T) {
 				Filename: "filename",
 			},
 		},
+		{
+			name: "enumeratesigners",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("enumeratesigners")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewEnumerateSignersCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"enumeratesigners","params":[],"id":1}`,
+			unmarshalled: &btcjson.EnumerateSignersCmd{},
+		},
+		{
+			name: "exportaccountxpub",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("exportaccountxpub", "default")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewExportAccountXPubCmd("default")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"exportaccountxpub","params":["default"],"id":1}`,
+			unmarshalled: &btcjson.ExportAccountXPubCmd{
+				Account: "default",
+			},
+		},
+		{
+			name: "exporttxmemos",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("exporttxmemos", "pass")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewExportTxMemosCmd("pass")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"exporttxmemos","params":["pass"],"id":1}`,
+			unmarshalled: &btcjson.ExportTxMemosCmd{
+				Passphrase: "pass",
+			},
+		},
+		{
+			name: "getaccountpolicy",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getaccountpolicy", "customers/alice")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetAccountPolicyCmd("customers/alice")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getaccountpolicy","params":["customers/alice"],"id":1}`,
+			unmarshalled: &btcjson.GetAccountPolicyCmd{
+				Account: "customers/alice",
+			},
+		},
+		{
+			name: "getaddressinfo",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getaddressinfo", "1Address")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetAddressInfoCmd("1Address")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getaddressinfo","params":["1Address"],"id":1}`,
+			unmarshalled: &btcjson.GetAddressInfoCmd{
+				Address: "1Address",
+			},
+		},
+		{
+			name: "getpaymentreport",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getpaymentreport")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetPaymentReportCmd(nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getpaymentreport","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetPaymentReportCmd{
+				Count: btcjson.Int(50),
+			},
+		},
+		{
+			name: "getpaymentreport optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getpaymentreport", "payroll", 10)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetPaymentReportCmd(btcjson.String("payroll"),
+					btcjson.Int(10))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getpaymentreport","params":["payroll",10],"id":1}`,
+			unmarshalled: &btcjson.GetPaymentReportCmd{
+				Name:  btcjson.String("payroll"),
+				Count: btcjson.Int(10),
+			},
+		},
+		{
+			name: "getrecoveryinfo",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getrecoveryinfo")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetRecoveryInfoCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getrecoveryinfo","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetRecoveryInfoCmd{},
+		},
+		{
+			name: "getsignerpolicy",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getsignerpolicy")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetSignerPolicyCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getsignerpolicy","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetSignerPolicyCmd{},
+		},
+		{
+			name: "getwalletlockstate",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getwalletlockstate")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetWalletLockStateCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getwalletlockstate","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetWalletLockStateCmd{},
+		},
+		{
+			name: "importaccountxpub",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("importaccountxpub",
+					`{"account":"default","scripttype":"p2wpkh","xpub":"xpub"}`)
+			},
+			staticCmd: func() interface{} {
+				bundle := btcjson.AccountXPubBundle{
+					Account:    "default",
+					ScriptType: "p2wpkh",
+					XPub:       "xpub",
+				}
+				return btcjson.NewImportAccountXPubCmd(bundle, nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"importaccountxpub","params":[{"account":"default","accountnumber":0,"scripttype":"p2wpkh","masterfingerprint":"","xpub":"xpub","birthheight":0}],"id":1}`,
+			unmarshalled: &btcjson.ImportAccountXPubCmd{
+				Bundle: btcjson.AccountXPubBundle{
+					Account:    "default",
+					ScriptType: "p2wpkh",
+					XPub:       "xpub",
+				},
+				Rescan: btcjson.Bool(true),
+			},
+		},
+		{
+			name: "importaccountxpub optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("importaccountxpub",
+					`{"account":"default","scripttype":"p2wpkh","xpub":"xpub"}`,
+					"cold", false)
+			},
+			staticCmd: func() interface{} {
+				bundle := btcjson.AccountXPubBundle{
+					Account:    "default",
+					ScriptType: "p2wpkh",
+					XPub:       "xpub",
+				}
+				return btcjson.NewImportAccountXPubCmd(bundle,
+					btcjson.String("cold"), btcjson.Bool(false))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"importaccountxpub","params":[{"account":"default","accountnumber":0,"scripttype":"p2wpkh","masterfingerprint":"","xpub":"xpub","birthheight":0},"cold",false],"id":1}`,
+			unmarshalled: &btcjson.ImportAccountXPubCmd{
+				Bundle: btcjson.AccountXPubBundle{
+					Account:    "default",
+					ScriptType: "p2wpkh",
+					XPub:       "xpub",
+				},
+				Account: btcjson.String("cold"),
+				Rescan:  btcjson.Bool(false),
+			},
+		},
 		{
 			name: "importaddress",
 			newCmd: func() (interface{}, error) {
@@ -84,6 +425,41 @@ func TestBtcWalletExtCmds(t *testing.T) {
 				Rescan:  btcjson.Bool(false),
 			},
 		},
+		{
+			name: "importcorewallet",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("importcorewallet", "wallet.txt")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewImportCoreWalletCmd("wallet.txt", nil, nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"importcorewallet","params":["wallet.txt"],"id":1}`,
+			unmarshalled: &btcjson.ImportCoreWalletCmd{
+				Filename: "wallet.txt",
+				Format:   btcjson.String(btcjson.CoreWalletFormatDump),
+				Account:  btcjson.String("imported"),
+				Rescan:   btcjson.Bool(true),
+			},
+		},
+		{
+			name: "importcorewallet optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("importcorewallet", "descriptors.json",
+					"descriptors", "core", false)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewImportCoreWalletCmd("descriptors.json",
+					btcjson.String(btcjson.CoreWalletFormatDescriptors),
+					btcjson.String("core"), btcjson.Bool(false))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"importcorewallet","params":["descriptors.json","descriptors","core",false],"id":1}`,
+			unmarshalled: &btcjson.ImportCoreWalletCmd{
+				Filename: "descriptors.json",
+				Format:   btcjson.String(btcjson.CoreWalletFormatDescriptors),
+				Account:  btcjson.String("core"),
+				Rescan:   btcjson.Bool(false),
+			},
+		},
 		{
 			name: "importpubkey",
 			newCmd: func() (interface{}, error) {
@@ -112,6 +488,68 @@ func TestBtcWalletExtCmds(t *testing.T) {
 				Rescan: btcjson.Bool(false),
 			},
 		},
+		{
+			name: "importpubkey key origin",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("importpubkey", "031234", false,
+					"[d34db33f/84'/390'/0'/0/1]")
+			},
+			staticCmd: func() interface{} {
+				cmd := btcjson.NewImportPubKeyCmd("031234", btcjson.Bool(false))
+				cmd.KeyOrigin = btcjson.String("[d34db33f/84'/390'/0'/0/1]")
+				return cmd
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"importpubkey","params":["031234",false,"[d34db33f/84'/390'/0'/0/1]"],"id":1}`,
+			unmarshalled: &btcjson.ImportPubKeyCmd{
+				PubKey:    "031234",
+				Rescan:    btcjson.Bool(false),
+				KeyOrigin: btcjson.String("[d34db33f/84'/390'/0'/0/1]"),
+			},
+		},
+		{
+			name: "importsignatures",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("importsignatures", "cHNidP8B")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewImportSignaturesCmd("cHNidP8B")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"importsignatures","params":["cHNidP8B"],"id":1}`,
+			unmarshalled: &btcjson.ImportSignaturesCmd{
+				Package: "cHNidP8B",
+			},
+		},
+		{
+			name: "importtxmemos",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("importtxmemos", "cGt0bWVtbw==", "pass")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewImportTxMemosCmd("cGt0bWVtbw==", "pass", nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"importtxmemos","params":["cGt0bWVtbw==","pass"],"id":1}`,
+			unmarshalled: &btcjson.ImportTxMemosCmd{
+				Data:       "cGt0bWVtbw==",
+				Passphrase: "pass",
+				Overwrite:  btcjson.Bool(false),
+			},
+		},
+		{
+			name: "importtxmemos optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("importtxmemos", "cGt0bWVtbw==", "pass", true)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewImportTxMemosCmd("cGt0bWVtbw==", "pass",
+					btcjson.Bool(true))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"importtxmemos","params":["cGt0bWVtbw==","pass",true],"id":1}`,
+			unmarshalled: &btcjson.ImportTxMemosCmd{
+				Data:       "cGt0bWVtbw==",
+				Passphrase: "pass",
+				Overwrite:  btcjson.Bool(true),
+			},
+		},
 		{
 			name: "importwallet",
 			newCmd: func() (interface{}, error) {
@@ -125,6 +563,110 @@ func TestBtcWalletExtCmds(t *testing.T) {
 				Filename: "filename",
 			},
 		},
+		{
+			name: "listkeyorigins",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("listkeyorigins")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewListKeyOriginsCmd(nil)
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"listkeyorigins","params":[],"id":1}`,
+			unmarshalled: &btcjson.ListKeyOriginsCmd{},
+		},
+		{
+			name: "listkeyorigins optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("listkeyorigins", "default")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewListKeyOriginsCmd(btcjson.String("default"))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"listkeyorigins","params":["default"],"id":1}`,
+			unmarshalled: &btcjson.ListKeyOriginsCmd{
+				Account: btcjson.String("default"),
+			},
+		},
+		{
+			name: "listpaymenttemplates",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("listpaymenttemplates")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewListPaymentTemplatesCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"listpaymenttemplates","params":[],"id":1}`,
+			unmarshalled: &btcjson.ListPaymentTemplatesCmd{},
+		},
+		{
+			name: "listpendingsends",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("listpendingsends")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewListPendingSendsCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"listpendingsends","params":[],"id":1}`,
+			unmarshalled: &btcjson.ListPendingSendsCmd{},
+		},
+		{
+			name: "listscheduled",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("listscheduled")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewListScheduledCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"listscheduled","params":[],"id":1}`,
+			unmarshalled: &btcjson.ListScheduledCmd{},
+		},
+		{
+			name: "proveaddressownership",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("proveaddressownership", []string{"1Address"},
+					"reserves")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewProveAddressOwnershipCmd([]string{"1Address"},
+					"reserves", nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"proveaddressownership","params":[["1Address"],"reserves"],"id":1}`,
+			unmarshalled: &btcjson.ProveAddressOwnershipCmd{
+				Addresses: []string{"1Address"},
+				Message:   "reserves",
+				MinConf:   btcjson.Int(1),
+			},
+		},
+		{
+			name: "proveaddressownership optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("proveaddressownership", []string{"1Address"},
+					"reserves", 6)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewProveAddressOwnershipCmd([]string{"1Address"},
+					"reserves", btcjson.Int(6))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"proveaddressownership","params":[["1Address"],"reserves",6],"id":1}`,
+			unmarshalled: &btcjson.ProveAddressOwnershipCmd{
+				Addresses: []string{"1Address"},
+				Message:   "reserves",
+				MinConf:   btcjson.Int(6),
+			},
+		},
+		{
+			name: "removepaymenttemplate",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("removepaymenttemplate", "payroll")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewRemovePaymentTemplateCmd("payroll")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"removepaymenttemplate","params":["payroll"],"id":1}`,
+			unmarshalled: &btcjson.RemovePaymentTemplateCmd{
+				Name: "payroll",
+			},
+		},
 		{
 			name: "renameaccount",
 			newCmd: func() (interface{}, error) {
@@ -139,6 +681,523 @@ func TestBtcWalletExtCmds(t *testing.T) {
 				NewAccount: "newacct",
 			},
 		},
+		{
+			name: "schedulesend",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("schedulesend", "payouts",
+					`{"1Address":0.5}`, 700000)
+			},
+			staticCmd: func() interface{} {
+				amounts := map[string]float64{"1Address": 0.5}
+				return btcjson.NewScheduleSendCmd("payouts", amounts, 700000,
+					nil, nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"schedulesend","params":["payouts",{"1Address":0.5},700000],"id":1}`,
+			unmarshalled: &btcjson.ScheduleSendCmd{
+				FromAccount: "payouts",
+				Amounts:     map[string]float64{"1Address": 0.5},
+				At:          700000,
+				Mode:        btcjson.String(btcjson.ScheduleModeHold),
+				Every:       btcjson.Int64(0),
+				Count:       btcjson.Int(1),
+				MinConf:     btcjson.Int(1),
+			},
+		},
+		{
+			name: "schedulesend optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("schedulesend", "payouts",
+					`{"1Address":0.5}`, 1600000000, "locktime", 604800, 0, 6,
+					"weekly")
+			},
+			staticCmd: func() interface{} {
+				amounts := map[string]float64{"1Address": 0.5}
+				cmd := btcjson.NewScheduleSendCmd("payouts", amounts,
+					1600000000, btcjson.String(btcjson.ScheduleModeLockTime),
+					btcjson.Int64(604800), btcjson.Int(0))
+				cmd.MinConf = btcjson.Int(6)
+				cmd.Comment = btcjson.String("weekly")
+				return cmd
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"schedulesend","params":["payouts",{"1Address":0.5},1600000000,"locktime",604800,0,6,"weekly"],"id":1}`,
+			unmarshalled: &btcjson.ScheduleSendCmd{
+				FromAccount: "payouts",
+				Amounts:     map[string]float64{"1Address": 0.5},
+				At:          1600000000,
+				Mode:        btcjson.String(btcjson.ScheduleModeLockTime),
+				Every:       btcjson.Int64(604800),
+				Count:       btcjson.Int(0),
+				MinConf:     btcjson.Int(6),
+				Comment:     btcjson.String("weekly"),
+			},
+		},
+		{
+			name: "schedulesend recurring without mode",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("schedulesend", "payouts",
+					`{"1Address":0.5}`, 700000, "hold", 1008, 4)
+			},
+			staticCmd: func() interface{} {
+				amounts := map[string]float64{"1Address": 0.5}
+				return btcjson.NewScheduleSendCmd("payouts", amounts, 700000,
+					nil, btcjson.Int64(1008), btcjson.Int(4))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"schedulesend","params":["payouts",{"1Address":0.5},700000,"hold",1008,4],"id":1}`,
+			unmarshalled: &btcjson.ScheduleSendCmd{
+				FromAccount: "payouts",
+				Amounts:     map[string]float64{"1Address": 0.5},
+				At:          700000,
+				Mode:        btcjson.String(btcjson.ScheduleModeHold),
+				Every:       btcjson.Int64(1008),
+				Count:       btcjson.Int(4),
+				MinConf:     btcjson.Int(1),
+			},
+		},
+		{
+			name: "schedulesend count only",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("schedulesend", "payouts",
+					`{"1Address":0.5}`, 700000, "hold", 0, 0)
+			},
+			staticCmd: func() interface{} {
+				amounts := map[string]float64{"1Address": 0.5}
+				return btcjson.NewScheduleSendCmd("payouts", amounts, 700000,
+					nil, nil, btcjson.Int(0))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"schedulesend","params":["payouts",{"1Address":0.5},700000,"hold",0,0],"id":1}`,
+			unmarshalled: &btcjson.ScheduleSendCmd{
+				FromAccount: "payouts",
+				Amounts:     map[string]float64{"1Address": 0.5},
+				At:          700000,
+				Mode:        btcjson.String(btcjson.ScheduleModeHold),
+				Every:       btcjson.Int64(0),
+				Count:       btcjson.Int(0),
+				MinConf:     btcjson.Int(1),
+			},
+		},
+		{
+			name: "setaccountcredentials",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("setaccountcredentials", "customers/alice",
+					"alice", "secret")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewSetAccountCredentialsCmd("customers/alice",
+					"alice", btcjson.String("secret"))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"setaccountcredentials","params":["customers/alice","alice","secret"],"id":1}`,
+			unmarshalled: &btcjson.SetAccountCredentialsCmd{
+				Account:  "customers/alice",
+				Username: "alice",
+				Password: btcjson.String("secret"),
+			},
+		},
+		{
+			name: "setaccountpolicy",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("setaccountpolicy", "customers/alice",
+					`{"maxfeerate":0.001,"withdrawallimit":100}`)
+			},
+			staticCmd: func() interface{} {
+				policy := btcjson.AccountPolicy{
+					MaxFeeRate:      btcjson.Float64(0.001),
+					WithdrawalLimit: btcjson.Float64(100),
+				}
+				return btcjson.NewSetAccountPolicyCmd("customers/alice", policy)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"setaccountpolicy","params":["customers/alice",{"maxfeerate":0.001,"withdrawallimit":100}],"id":1}`,
+			unmarshalled: &btcjson.SetAccountPolicyCmd{
+				Account: "customers/alice",
+				Policy: btcjson.AccountPolicy{
+					MaxFeeRate:      btcjson.Float64(0.001),
+					WithdrawalLimit: btcjson.Float64(100),
+				},
+			},
+		},
+		{
+			name: "sendmanybatch",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("sendmanybatch", "from",
+					`[{"address":"1Address","amount":0.5,"label":"w1"}]`)
+			},
+			staticCmd: func() interface{} {
+				outputs := []btcjson.BatchOutput{
+					{Address: "1Address", Amount: 0.5, Label: "w1"},
+				}
+				return btcjson.NewSendManyBatchCmd("from", outputs, nil, nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"sendmanybatch","params":["from",[{"address":"1Address","amount":0.5,"label":"w1"}]],"id":1}`,
+			unmarshalled: &btcjson.SendManyBatchCmd{
+				FromAccount: "from",
+				Outputs: []btcjson.BatchOutput{
+					{Address: "1Address", Amount: 0.5, Label: "w1"},
+				},
+				MinConf:         btcjson.Int(1),
+				MergeDuplicates: btcjson.Bool(true),
+			},
+		},
+		{
+			name: "sendmanybatch optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("sendmanybatch", "from",
+					`[{"address":"1Address","amount":0.5},{"address":"1Address2","amount":1}]`,
+					6, false, `{"feerate":0.0002}`, `{"subtractfeefrom":["1Address2"]}`,
+					"payouts")
+			},
+			staticCmd: func() interface{} {
+				outputs := []btcjson.BatchOutput{
+					{Address: "1Address", Amount: 0.5},
+					{Address: "1Address2", Amount: 1},
+				}
+				fee := &btcjson.BatchFeeOptions{
+					FeeRate: btcjson.Float64(0.0002),
+				}
+				change := &btcjson.ChangeOptions{
+					SubtractFeeFrom: []string{"1Address2"},
+				}
+				cmd := btcjson.NewSendManyBatchCmd("from", outputs,
+					btcjson.Int(6), fee, change)
+				cmd.MergeDuplicates = btcjson.Bool(false)
+				cmd.Comment = btcjson.String("payouts")
+				return cmd
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"sendmanybatch","params":["from",[{"address":"1Address","amount":0.5},{"address":"1Address2","amount":1}],6,false,{"feerate":0.0002},{"subtractfeefrom":["1Address2"]},"payouts"],"id":1}`,
+			unmarshalled: &btcjson.SendManyBatchCmd{
+				FromAccount: "from",
+				Outputs: []btcjson.BatchOutput{
+					{Address: "1Address", Amount: 0.5},
+					{Address: "1Address2", Amount: 1},
+				},
+				MinConf:         btcjson.Int(6),
+				MergeDuplicates: btcjson.Bool(false),
+				Fee: &btcjson.BatchFeeOptions{
+					FeeRate: btcjson.Float64(0.0002),
+				},
+				Change: &btcjson.ChangeOptions{
+					SubtractFeeFrom: []string{"1Address2"},
+				},
+				Comment: btcjson.String("payouts"),
+			},
+		},
+		{
+			name: "sendmanybatch fee",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("sendmanybatch", "from",
+					`[{"address":"1Address","amount":0.5}]`, 1, true,
+					`{"conftarget":6}`)
+			},
+			staticCmd: func() interface{} {
+				outputs := []btcjson.BatchOutput{
+					{Address: "1Address", Amount: 0.5},
+				}
+				fee := &btcjson.BatchFeeOptions{
+					ConfTarget: btcjson.Int32(6),
+				}
+				return btcjson.NewSendManyBatchCmd("from", outputs, nil,
+					fee, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"sendmanybatch","params":["from",[{"address":"1Address","amount":0.5}],1,true,{"conftarget":6}],"id":1}`,
+			unmarshalled: &btcjson.SendManyBatchCmd{
+				FromAccount: "from",
+				Outputs: []btcjson.BatchOutput{
+					{Address: "1Address", Amount: 0.5},
+				},
+				MinConf:         btcjson.Int(1),
+				MergeDuplicates: btcjson.Bool(true),
+				Fee: &btcjson.BatchFeeOptions{
+					ConfTarget: btcjson.Int32(6),
+				},
+			},
+		},
+		{
+			name: "sendmanybatch change",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("sendmanybatch", "from",
+					`[{"address":"1Address","amount":0.5}]`, 3, true,
+					`{}`, `{"address":"1Change"}`)
+			},
+			staticCmd: func() interface{} {
+				outputs := []btcjson.BatchOutput{
+					{Address: "1Address", Amount: 0.5},
+				}
+				change := &btcjson.ChangeOptions{
+					Address: btcjson.String("1Change"),
+				}
+				return btcjson.NewSendManyBatchCmd("from", outputs,
+					btcjson.Int(3), nil, change)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"sendmanybatch","params":["from",[{"address":"1Address","amount":0.5}],3,true,{},{"address":"1Change"}],"id":1}`,
+			unmarshalled: &btcjson.SendManyBatchCmd{
+				FromAccount: "from",
+				Outputs: []btcjson.BatchOutput{
+					{Address: "1Address", Amount: 0.5},
+				},
+				MinConf:         btcjson.Int(3),
+				MergeDuplicates: btcjson.Bool(true),
+				Fee:             &btcjson.BatchFeeOptions{},
+				Change: &btcjson.ChangeOptions{
+					Address: btcjson.String("1Change"),
+				},
+			},
+		},
+		{
+			name: "sendpayjoin",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("sendpayjoin", "pkt:1Address?amount=1&pj=https://example.com/pj")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewSendPayjoinCmd("pkt:1Address?amount=1&pj=https://example.com/pj",
+					nil, nil, nil, nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"sendpayjoin","params":["pkt:1Address?amount=1\u0026pj=https://example.com/pj"],"id":1}`,
+			unmarshalled: &btcjson.SendPayjoinCmd{
+				URI:                       "pkt:1Address?amount=1&pj=https://example.com/pj",
+				FromAccount:               btcjson.String("default"),
+				MinConf:                   btcjson.Int(1),
+				DisableOutputSubstitution: btcjson.Bool(false),
+			},
+		},
+		{
+			name: "sendpayjoin optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("sendpayjoin", "pkt:1Address?amount=1",
+					`{"1Address2":0.5}`, "from", 6, 0.0001, true)
+			},
+			staticCmd: func() interface{} {
+				amounts := map[string]float64{"1Address2": 0.5}
+				return btcjson.NewSendPayjoinCmd("pkt:1Address?amount=1",
+					&amounts, btcjson.String("from"), btcjson.Int(6),
+					btcjson.Float64(0.0001), btcjson.Bool(true))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"sendpayjoin","params":["pkt:1Address?amount=1",{"1Address2":0.5},"from",6,0.0001,true],"id":1}`,
+			unmarshalled: &btcjson.SendPayjoinCmd{
+				URI:                       "pkt:1Address?amount=1",
+				Amounts:                   &map[string]float64{"1Address2": 0.5},
+				FromAccount:               btcjson.String("from"),
+				MinConf:                   btcjson.Int(6),
+				MaxFeeContribution:        btcjson.Float64(0.0001),
+				DisableOutputSubstitution: btcjson.Bool(true),
+			},
+		},
+		{
+			name: "setpaymenttemplate",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("setpaymenttemplate",
+					`{"name":"payroll","account":"team","recipients":[{"address":"1Address","amount":"5% * balance"}],"interval":86400,"fee":{"conftarget":6},"maxretries":3,"retrydelay":600}`)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewSetPaymentTemplateCmd(btcjson.PaymentTemplate{
+					Name:    "payroll",
+					Account: "team",
+					Recipients: []btcjson.PaymentRecipient{
+						{Address: "1Address", Amount: "5% * balance"},
+					},
+					Interval:   86400,
+					Fee:        &btcjson.BatchFeeOptions{ConfTarget: btcjson.Int32(6)},
+					MaxRetries: 3,
+					RetryDelay: 600,
+				})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"setpaymenttemplate","params":[{"name":"payroll","account":"team","recipients":[{"address":"1Address","amount":"5% * balance"}],"interval":86400,"fee":{"conftarget":6},"maxretries":3,"retrydelay":600}],"id":1}`,
+			unmarshalled: &btcjson.SetPaymentTemplateCmd{
+				Template: btcjson.PaymentTemplate{
+					Name:    "payroll",
+					Account: "team",
+					Recipients: []btcjson.PaymentRecipient{
+						{Address: "1Address", Amount: "5% * balance"},
+					},
+					Interval:   86400,
+					Fee:        &btcjson.BatchFeeOptions{ConfTarget: btcjson.Int32(6)},
+					MaxRetries: 3,
+					RetryDelay: 600,
+				},
+			},
+		},
+		{
+			name: "setsignerpolicy",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("setsignerpolicy",
+					`[{"name":"max","threshold":1000,"action":"deny"}]`)
+			},
+			staticCmd: func() interface{} {
+				rules := []btcjson.SignerPolicyRule{
+					{Name: "max", Threshold: 1000, Action: "deny"},
+				}
+				return btcjson.NewSetSignerPolicyCmd(rules, nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"setsignerpolicy","params":[[{"name":"max","threshold":1000,"action":"deny"}]],"id":1}`,
+			unmarshalled: &btcjson.SetSignerPolicyCmd{
+				Rules: []btcjson.SignerPolicyRule{
+					{Name: "max", Threshold: 1000, Action: "deny"},
+				},
+			},
+		},
+		{
+			name: "setsignerpolicy optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("setsignerpolicy",
+					`[{"name":"daily","threshold":100,"window":86400,"action":"delay","delay":3600}]`,
+					"GEZDGNBVGY3TQOJQ", "287082")
+			},
+			staticCmd: func() interface{} {
+				rules := []btcjson.SignerPolicyRule{
+					{Name: "daily", Threshold: 100, Window: 86400,
+						Action: "delay", Delay: 3600},
+				}
+				return btcjson.NewSetSignerPolicyCmd(rules,
+					btcjson.String("GEZDGNBVGY3TQOJQ"),
+					btcjson.String("287082"))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"setsignerpolicy","params":[[{"name":"daily","threshold":100,"window":86400,"action":"delay","delay":3600}],"GEZDGNBVGY3TQOJQ","287082"],"id":1}`,
+			unmarshalled: &btcjson.SetSignerPolicyCmd{
+				Rules: []btcjson.SignerPolicyRule{
+					{Name: "daily", Threshold: 100, Window: 86400,
+						Action: "delay", Delay: 3600},
+				},
+				SecondFactorSecret: btcjson.String("GEZDGNBVGY3TQOJQ"),
+				Code:               btcjson.String("287082"),
+			},
+		},
+		{
+			name: "settxmemo",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("settxmemo", "123", "rent")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewSetTxMemoCmd("123", "rent", nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"settxmemo","params":["123","rent"],"id":1}`,
+			unmarshalled: &btcjson.SetTxMemoCmd{
+				TxID: "123",
+				Memo: "rent",
+			},
+		},
+		{
+			name: "settxmemo optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("settxmemo", "123", "rent",
+					`{"invoice":"42"}`)
+			},
+			staticCmd: func() interface{} {
+				metadata := map[string]string{"invoice": "42"}
+				return btcjson.NewSetTxMemoCmd("123", "rent", &metadata)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"settxmemo","params":["123","rent",{"invoice":"42"}],"id":1}`,
+			unmarshalled: &btcjson.SetTxMemoCmd{
+				TxID:     "123",
+				Memo:     "rent",
+				Metadata: &map[string]string{"invoice": "42"},
+			},
+		},
+		{
+			name: "setwalletflag",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("setwalletflag", "avoid_reuse")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewSetWalletFlagCmd(btcjson.WalletFlagAvoidReuse, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"setwalletflag","params":["avoid_reuse"],"id":1}`,
+			unmarshalled: &btcjson.SetWalletFlagCmd{
+				Flag:  "avoid_reuse",
+				Value: btcjson.Bool(true),
+			},
+		},
+		{
+			name: "setwalletflag optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("setwalletflag", "avoid_reuse", false)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewSetWalletFlagCmd(btcjson.WalletFlagAvoidReuse,
+					btcjson.Bool(false))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"setwalletflag","params":["avoid_reuse",false],"id":1}`,
+			unmarshalled: &btcjson.SetWalletFlagCmd{
+				Flag:  "avoid_reuse",
+				Value: btcjson.Bool(false),
+			},
+		},
+		{
+			name: "signsigningpackage",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("signsigningpackage", "cHNidP8B")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewSignSigningPackageCmd("cHNidP8B")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"signsigningpackage","params":["cHNidP8B"],"id":1}`,
+			unmarshalled: &btcjson.SignSigningPackageCmd{
+				Package: "cHNidP8B",
+			},
+		},
+		{
+			name: "startrecovery",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("startrecovery")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewStartRecoveryCmd(nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"startrecovery","params":[],"id":1}`,
+			unmarshalled: &btcjson.StartRecoveryCmd{
+				GapLimit:    btcjson.Int32(250),
+				StartHeight: btcjson.Int32(0),
+			},
+		},
+		{
+			name: "startrecovery optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("startrecovery", 1000, 50000)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewStartRecoveryCmd(btcjson.Int32(1000),
+					btcjson.Int32(50000))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"startrecovery","params":[1000,50000],"id":1}`,
+			unmarshalled: &btcjson.StartRecoveryCmd{
+				GapLimit:    btcjson.Int32(1000),
+				StartHeight: btcjson.Int32(50000),
+			},
+		},
+		{
+			name: "verifywalletbackup",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("verifywalletbackup")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewVerifyWalletBackupCmd(nil)
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"verifywalletbackup","params":[],"id":1}`,
+			unmarshalled: &btcjson.VerifyWalletBackupCmd{},
+		},
+		{
+			name: "verifywalletbackup optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("verifywalletbackup", "/backups/wallet.db")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewVerifyWalletBackupCmd(btcjson.String("/backups/wallet.db"))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"verifywalletbackup","params":["/backups/wallet.db"],"id":1}`,
+			unmarshalled: &btcjson.VerifyWalletBackupCmd{
+				Path: btcjson.String("/backups/wallet.db"),
+			},
+		},
+		{
+			name: "walletpubpassphrasechange",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("walletpubpassphrasechange", "old", "new")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewWalletPubPassphraseChangeCmd("old", "new")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"walletpubpassphrasechange","params":["old","new"],"id":1}`,
+			unmarshalled: &btcjson.WalletPubPassphraseChangeCmd{
+				OldPassphrase: "old",
+				NewPassphrase: "new",
+			},
+		},
 	}
 
 	t.Logf("Running %d tests", len(tests))
@@ -41,6 +41,36 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"addnode","params":["127.0.0.1","remove"],"id":1}`,
 			unmarshalled: &btcjson.AddNodeCmd{Addr: "127.0.0.1", SubCmd: btcjson.ANRemove},
 		},
+		{
+			name: "addpeeraddress",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("addpeeraddress", "1.2.3.4", 64764)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewAddPeerAddressCmd("1.2.3.4", 64764, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"addpeeraddress","params":["1.2.3.4",64764],"id":1}`,
+			unmarshalled: &btcjson.AddPeerAddressCmd{
+				Address: "1.2.3.4",
+				Port:    64764,
+				Tried:   btcjson.Bool(false),
+			},
+		},
+		{
+			name: "addpeeraddress optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("addpeeraddress", "1.2.3.4", 64764, true)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewAddPeerAddressCmd("1.2.3.4", 64764, btcjson.Bool(true))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"addpeeraddress","params":["1.2.3.4",64764,true],"id":1}`,
+			unmarshalled: &btcjson.AddPeerAddressCmd{
+				Address: "1.2.3.4",
+				Port:    64764,
+				Tried:   btcjson.Bool(true),
+			},
+		},
 		{
 			name: "createrawtransaction",
 			newCmd: func() (interface{}, error) {
@@ -128,6 +158,17 @@ func TestChainSvrCmds(t *testing.T) {
 				Node: btcjson.String("127.0.0.1"),
 			},
 		},
+		{
+			name: "getaddrmaninfo",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getaddrmaninfo")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetAddrManInfoCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getaddrmaninfo","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetAddrManInfoCmd{},
+		},
 		{
 			name: "getbestblockhash",
 			newCmd: func() (interface{}, error) {
@@ -473,6 +514,17 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"getnettotals","params":[],"id":1}`,
 			unmarshalled: &btcjson.GetNetTotalsCmd{},
 		},
+		{
+			name: "getrpcinfo",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getrpcinfo")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetRPCInfoCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getrpcinfo","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetRPCInfoCmd{},
+		},
 		{
 			name: "getnetworkhashps",
 			newCmd: func() (interface{}, error) {
@@ -515,6 +567,32 @@ func TestChainSvrCmds(t *testing.T) {
 				Height: btcjson.Int(123),
 			},
 		},
+		{
+			name: "getnodeaddresses",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getnodeaddresses")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetNodeAddressesCmd(nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getnodeaddresses","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetNodeAddressesCmd{
+				Count: btcjson.Int32(1),
+			},
+		},
+		{
+			name: "getnodeaddresses optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getnodeaddresses", 10)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetNodeAddressesCmd(btcjson.Int32(10))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getnodeaddresses","params":[10],"id":1}`,
+			unmarshalled: &btcjson.GetNodeAddressesCmd{
+				Count: btcjson.Int32(10),
+			},
+		},
 		{
 			name: "getpeerinfo",
 			newCmd: func() (interface{}, error) {
@@ -992,6 +1070,25 @@ func TestChainSvrCmds(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "submitblock verbose",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("submitblock", "112233", `{"verbose":true}`)
+			},
+			staticCmd: func() interface{} {
+				options := btcjson.SubmitBlockOptions{
+					Verbose: true,
+				}
+				return btcjson.NewSubmitBlockCmd("112233", &options)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"submitblock","params":["112233",{"verbose":true}],"id":1}`,
+			unmarshalled: &btcjson.SubmitBlockCmd{
+				HexBlock: "112233",
+				Options: &btcjson.SubmitBlockOptions{
+					Verbose: true,
+				},
+			},
+		},
 		{
 			name: "uptime",
 			newCmd: func() (interface{}, error) {
@@ -1086,6 +1183,60 @@ func TestChainSvrCmds(t *testing.T) {
 				Proof: "test",
 			},
 		},
+		{
+			name: "waitforblockheight",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("waitforblockheight", 100)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewWaitForBlockHeightCmd(100, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"waitforblockheight","params":[100],"id":1}`,
+			unmarshalled: &btcjson.WaitForBlockHeightCmd{
+				Height:  100,
+				Timeout: btcjson.Int64(0),
+			},
+		},
+		{
+			name: "waitforblockheight optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("waitforblockheight", 100, 5000)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewWaitForBlockHeightCmd(100, btcjson.Int64(5000))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"waitforblockheight","params":[100,5000],"id":1}`,
+			unmarshalled: &btcjson.WaitForBlockHeightCmd{
+				Height:  100,
+				Timeout: btcjson.Int64(5000),
+			},
+		},
+		{
+			name: "waitfornewblock",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("waitfornewblock")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewWaitForNewBlockCmd(nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"waitfornewblock","params":[],"id":1}`,
+			unmarshalled: &btcjson.WaitForNewBlockCmd{
+				Timeout: btcjson.Int64(0),
+			},
+		},
+		{
+			name: "waitfornewblock optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("waitfornewblock", 5000)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewWaitForNewBlockCmd(btcjson.Int64(5000))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"waitfornewblock","params":[5000],"id":1}`,
+			unmarshalled: &btcjson.WaitForNewBlockCmd{
+				Timeout: btcjson.Int64(5000),
+			},
+		},
 	}
 
 	t.Logf("Running %d tests", len(tests))
@@ -46,6 +46,29 @@ type GetBlockVerboseResult struct {
 	Difficulty    float64       `json:"difficulty"`
 	PreviousHash  string        `json:"previousblockhash"`
 	NextHash      string        `json:"nextblockhash,omitempty"`
+
+	// PacketCrypt is the composition of the effective target the block
+	// was mined at.  It is a pktd extension, omitted for blocks without an
+	// announcement commitment.
+	PacketCrypt *PacketCryptWorkResult `json:"packetcrypt,omitempty"`
+}
+
+// PacketCryptWorkResult models the composition of the effective target a
+// PacketCrypt block was mined at: the work of its header target, the work of
+// the announcement with the least work and the number of announcements it was
+// mined with.  The effective work is the cube of the header work divided by
+// the minimum announcement work and the announcement count.  The works are hex
+// encoded.
+type PacketCryptWorkResult struct {
+	AnnCount            uint64  `json:"anncount"`
+	AnnMinBits          string  `json:"annminbits"`
+	AnnDifficulty       float64 `json:"anndifficulty"`
+	HeaderWork          string  `json:"headerwork"`
+	AnnMinWork          string  `json:"annminwork"`
+	AnnWork             string  `json:"annwork"`
+	EffectiveBits       string  `json:"effectivebits"`
+	EffectiveDifficulty float64 `json:"effectivedifficulty"`
+	EffectiveWork       string  `json:"effectivework"`
 }
 
 // CreateMultiSigResult models the data returned from the createmultisig
@@ -113,6 +136,12 @@ type GetBlockChainInfoResult struct {
 	ChainWork            string                              `json:"chainwork,omitempty"`
 	SoftForks            []*SoftForkDescription              `json:"softforks"`
 	Bip9SoftForks        map[string]*Bip9SoftForkDescription `json:"bip9_softforks"`
+
+	// The following fields are pktd extensions.  EstimatedTimeRemaining
+	// is the estimated number of seconds left to sync, or -1 when it
+	// can't be estimated.
+	InitialBlockDownload   bool  `json:"initialblockdownload"`
+	EstimatedTimeRemaining int64 `json:"estimatedtimeremaining,omitempty"`
 }
 
 // GetBlockTemplateResultTx models the transactions field of the
@@ -177,20 +206,33 @@ type GetBlockTemplateResult struct {
 // GetMempoolEntryResult models the data returned from the getmempoolentry
 // command.
 type GetMempoolEntryResult struct {
-	Size             int32    `json:"size"`
-	Fee              float64  `json:"fee"`
-	ModifiedFee      float64  `json:"modifiedfee"`
-	Time             int64    `json:"time"`
-	Height           int64    `json:"height"`
-	StartingPriority float64  `json:"startingpriority"`
-	CurrentPriority  float64  `json:"currentpriority"`
-	DescendantCount  int64    `json:"descendantcount"`
-	DescendantSize   int64    `json:"descendantsize"`
-	DescendantFees   float64  `json:"descendantfees"`
-	AncestorCount    int64    `json:"ancestorcount"`
-	AncestorSize     int64    `json:"ancestorsize"`
-	AncestorFees     float64  `json:"ancestorfees"`
-	Depends          []string `json:"depends"`
+	Size             int32               `json:"size"`
+	Fee              float64             `json:"fee"`
+	ModifiedFee      float64             `json:"modifiedfee"`
+	Time             int64               `json:"time"`
+	Height           int64               `json:"height"`
+	StartingPriority float64             `json:"startingpriority"`
+	CurrentPriority  float64             `json:"currentpriority"`
+	DescendantCount  int64               `json:"descendantcount"`
+	DescendantSize   int64               `json:"descendantsize"`
+	DescendantFees   float64             `json:"descendantfees"`
+	AncestorCount    int64               `json:"ancestorcount"`
+	AncestorSize     int64               `json:"ancestorsize"`
+	AncestorFees     float64             `json:"ancestorfees"`
+	Depends          []string            `json:"depends"`
+	Provenance       *TxProvenanceResult `json:"provenance,omitempty"`
+}
+
+// TxProvenanceResult models when and how a transaction reached the node,
+// returned by the getmempoolentry command and the txacceptedverbose
+// notification.  FirstSeen is in seconds since 1 Jan 1970 GMT, Origin is the
+// kind of peer the transaction was first received from (local, inbound,
+// outbound or whitelisted) and Announcers the number of peers which announced
+// or relayed it.
+type TxProvenanceResult struct {
+	FirstSeen  int64  `json:"firstseen"`
+	Origin     string `json:"origin"`
+	Announcers int32  `json:"announcers"`
 }
 
 // GetMempoolInfoResult models the data returned from the getmempoolinfo
@@ -233,6 +275,20 @@ type GetNetworkInfoResult struct {
 	IncrementalFee  float64                `json:"incrementalfee"`
 	LocalAddresses  []LocalAddressesResult `json:"localaddresses"`
 	Warnings        string                 `json:"warnings"`
+	Build           *BuildInfoResult       `json:"build,omitempty"`
+}
+
+// RPCCommandInfo models the data of a command which is being executed from
+// the getrpcinfo command.
+type RPCCommandInfo struct {
+	Method   string `json:"method"`
+	Duration int64  `json:"duration"`
+}
+
+// GetRPCInfoResult models the data returned from the getrpcinfo command.
+type GetRPCInfoResult struct {
+	ActiveCommands []RPCCommandInfo `json:"active_commands"`
+	LogPath        string           `json:"logpath"`
 }
 
 // GetNetworkStewardResult models the data returned from the getnetworksteward command.
@@ -244,27 +300,30 @@ type GetNetworkStewardResult struct {
 
 // GetPeerInfoResult models the data returned from the getpeerinfo command.
 type GetPeerInfoResult struct {
-	ID             int32   `json:"id"`
-	Addr           string  `json:"addr"`
-	AddrLocal      string  `json:"addrlocal,omitempty"`
-	Services       string  `json:"services"`
-	RelayTxes      bool    `json:"relaytxes"`
-	LastSend       int64   `json:"lastsend"`
-	LastRecv       int64   `json:"lastrecv"`
-	BytesSent      uint64  `json:"bytessent"`
-	BytesRecv      uint64  `json:"bytesrecv"`
-	ConnTime       int64   `json:"conntime"`
-	TimeOffset     int64   `json:"timeoffset"`
-	PingTime       float64 `json:"pingtime"`
-	PingWait       float64 `json:"pingwait,omitempty"`
-	Version        uint32  `json:"version"`
-	SubVer         string  `json:"subver"`
-	Inbound        bool    `json:"inbound"`
-	StartingHeight int32   `json:"startingheight"`
-	CurrentHeight  int32   `json:"currentheight,omitempty"`
-	BanScore       int32   `json:"banscore"`
-	FeeFilter      int64   `json:"feefilter"`
-	SyncNode       bool    `json:"syncnode"`
+	ID             int32    `json:"id"`
+	Addr           string   `json:"addr"`
+	AddrLocal      string   `json:"addrlocal,omitempty"`
+	Services       string   `json:"services"`
+	RelayTxes      bool     `json:"relaytxes"`
+	LastSend       int64    `json:"lastsend"`
+	LastRecv       int64    `json:"lastrecv"`
+	BytesSent      uint64   `json:"bytessent"`
+	BytesRecv      uint64   `json:"bytesrecv"`
+	ConnTime       int64    `json:"conntime"`
+	TimeOffset     int64    `json:"timeoffset"`
+	PingTime       float64  `json:"pingtime"`
+	PingWait       float64  `json:"pingwait,omitempty"`
+	Version        uint32   `json:"version"`
+	SubVer         string   `json:"subver"`
+	Inbound        bool     `json:"inbound"`
+	StartingHeight int32    `json:"startingheight"`
+	CurrentHeight  int32    `json:"currentheight,omitempty"`
+	BanScore       int32    `json:"banscore"`
+	FeeFilter      int64    `json:"feefilter"`
+	SyncNode       bool     `json:"syncnode"`
+	Permissions    []string `json:"permissions,omitempty"`
+	InvQueued      int      `json:"invqueued"`
+	InvAnnounced   uint64   `json:"invannounced"`
 }
 
 type GetRawBlockTemplateResult struct {
@@ -315,6 +374,43 @@ type GetNetTotalsResult struct {
 	TimeMillis     int64  `json:"timemillis"`
 }
 
+// GetNodeAddressesResult models an address returned by the getnodeaddresses
+// command.
+type GetNodeAddressesResult struct {
+	Time     int64  `json:"time"`
+	Services uint64 `json:"services"`
+	Address  string `json:"address"`
+	Port     uint16 `json:"port"`
+}
+
+// AddrManBucketsResult models statistics about the new or tried buckets of the
+// address manager returned by the getaddrmaninfo command.
+type AddrManBucketsResult struct {
+	Addresses  int `json:"addresses"`
+	Buckets    int `json:"buckets"`
+	Used       int `json:"used"`
+	BucketSize int `json:"bucketsize"`
+	MaxBucket  int `json:"maxbucket"`
+}
+
+// GetAddrManInfoResult models the data returned from the getaddrmaninfo
+// command.
+type GetAddrManInfoResult struct {
+	Total int                  `json:"total"`
+	New   AddrManBucketsResult `json:"new"`
+	Tried AddrManBucketsResult `json:"tried"`
+	Bad   int                  `json:"bad"`
+	IPv4  int                  `json:"ipv4"`
+	IPv6  int                  `json:"ipv6"`
+	Onion int                  `json:"onion"`
+}
+
+// AddPeerAddressResult models the data returned from the addpeeraddress
+// command.
+type AddPeerAddressResult struct {
+	Success bool `json:"success"`
+}
+
 // ScriptSig models a signature script.  It is defined separately since it only
 // applies to non-coinbase.  Therefore the field in the Vin structure needs
 // to be a pointer.
@@ -490,6 +586,10 @@ type GetMiningInfoResult struct {
 	NetworkHashPS      int64   `json:"networkhashps"`
 	PooledTx           uint64  `json:"pooledtx"`
 	TestNet            bool    `json:"testnet"`
+	Chain              string  `json:"chain"`
+	Warnings           string  `json:"warnings"`
+
+	BlockLimits *BlockLimitsResult `json:"blocklimits,omitempty"`
 }
 
 // GetWorkResult models the data from the getwork command.
@@ -502,33 +602,38 @@ type GetWorkResult struct {
 
 // InfoChainResult models the data returned by the chain server getinfo command.
 type InfoChainResult struct {
-	Version         int32   `json:"version"`
-	ProtocolVersion int32   `json:"protocolversion"`
-	Blocks          int32   `json:"blocks"`
-	TimeOffset      int64   `json:"timeoffset"`
-	Connections     int32   `json:"connections"`
-	Proxy           string  `json:"proxy"`
-	Difficulty      float64 `json:"difficulty"`
-	TestNet         bool    `json:"testnet"`
-	RelayFee        float64 `json:"relayfee"`
-	Errors          string  `json:"errors"`
-}
-
-// TxRawResult models the data from the getrawtransaction command.
+	Version         int32            `json:"version"`
+	ProtocolVersion int32            `json:"protocolversion"`
+	Blocks          int32            `json:"blocks"`
+	TimeOffset      int64            `json:"timeoffset"`
+	Connections     int32            `json:"connections"`
+	Proxy           string           `json:"proxy"`
+	Difficulty      float64          `json:"difficulty"`
+	TestNet         bool             `json:"testnet"`
+	RelayFee        float64          `json:"relayfee"`
+	Errors          string           `json:"errors"`
+	Build           *BuildInfoResult `json:"build,omitempty"`
+}
+
+// TxRawResult models the data from the getrawtransaction command.  The fee is
+// only set when the transaction is requested with a verbosity of 2, and the
+// provenance only in the txacceptedverbose notification.
 type TxRawResult struct {
-	Hex           string `json:"hex"`
-	Txid          string `json:"txid"`
-	Hash          string `json:"hash,omitempty"`
-	Size          int32  `json:"size,omitempty"`
-	Vsize         int32  `json:"vsize,omitempty"`
-	Version       int32  `json:"version"`
-	LockTime      uint32 `json:"locktime"`
-	Vin           []Vin  `json:"vin"`
-	Vout          []Vout `json:"vout"`
-	BlockHash     string `json:"blockhash,omitempty"`
-	Confirmations uint64 `json:"confirmations,omitempty"`
-	Time          int64  `json:"time,omitempty"`
-	Blocktime     int64  `json:"blocktime,omitempty"`
+	Hex           string              `json:"hex"`
+	Txid          string              `json:"txid"`
+	Hash          string              `json:"hash,omitempty"`
+	Size          int32               `json:"size,omitempty"`
+	Vsize         int32               `json:"vsize,omitempty"`
+	Version       int32               `json:"version"`
+	LockTime      uint32              `json:"locktime"`
+	Vin           []Vin               `json:"vin"`
+	Vout          []Vout              `json:"vout"`
+	Fee           *float64            `json:"fee,omitempty"`
+	BlockHash     string              `json:"blockhash,omitempty"`
+	Confirmations uint64              `json:"confirmations,omitempty"`
+	Time          int64               `json:"time,omitempty"`
+	Blocktime     int64               `json:"blocktime,omitempty"`
+	Provenance    *TxProvenanceResult `json:"provenance,omitempty"`
 }
 
 // SearchRawTransactionsResult models the data from the searchrawtransaction
@@ -564,3 +669,16 @@ type ValidateAddressChainResult struct {
 	IsValid bool   `json:"isvalid"`
 	Address string `json:"address,omitempty"`
 }
+
+// WaitForBlockResult models the data returned from the waitfornewblock and
+// waitforblockheight commands.  Hash and Height describe the best block when
+// the command returns and PreviousHash and PreviousHeight the best block when
+// it was received.  Reorg is set when the previous best block is no longer
+// part of the main chain.
+type WaitForBlockResult struct {
+	Hash           string `json:"hash"`
+	Height         int32  `json:"height"`
+	PreviousHash   string `json:"previoushash"`
+	PreviousHeight int32  `json:"previousheight"`
+	Reorg          bool   `json:"reorg"`
+}
@@ -41,6 +41,32 @@ func NewStopNotifyBlocksCmd() *StopNotifyBlocksCmd {
 	return &StopNotifyBlocksCmd{}
 }
 
+// NotifyUtxoDeltasCmd defines the notifyutxodeltas JSON-RPC command.
+//
+// NOTE: This is a pktd extension and requires a websocket connection.
+type NotifyUtxoDeltasCmd struct{}
+
+// NewNotifyUtxoDeltasCmd returns a new instance which can be used to issue a
+// notifyutxodeltas JSON-RPC command.
+//
+// NOTE: This is a pktd extension and requires a websocket connection.
+func NewNotifyUtxoDeltasCmd() *NotifyUtxoDeltasCmd {
+	return &NotifyUtxoDeltasCmd{}
+}
+
+// StopNotifyUtxoDeltasCmd defines the stopnotifyutxodeltas JSON-RPC command.
+//
+// NOTE: This is a pktd extension and requires a websocket connection.
+type StopNotifyUtxoDeltasCmd struct{}
+
+// NewStopNotifyUtxoDeltasCmd returns a new instance which can be used to issue
+// a stopnotifyutxodeltas JSON-RPC command.
+//
+// NOTE: This is a pktd extension and requires a websocket connection.
+func NewStopNotifyUtxoDeltasCmd() *StopNotifyUtxoDeltasCmd {
+	return &StopNotifyUtxoDeltasCmd{}
+}
+
 // NotifyNewTransactionsCmd defines the notifynewtransactions JSON-RPC command.
 type NotifyNewTransactionsCmd struct {
 	Verbose *bool `jsonrpcdefault:"false"`
@@ -126,6 +152,56 @@ func NewLoadTxFilterCmd(reload bool, addresses []string, outPoints []OutPoint) *
 	}
 }
 
+// LoadWatchListCmd defines the loadwatchlist JSON-RPC command which adds
+// addresses and raw output scripts to the websocket client's watch list, or
+// replaces it when Reload is set.
+//
+// NOTE: This is a pktd extension and requires a websocket connection.
+type LoadWatchListCmd struct {
+	Reload    bool
+	Addresses []string
+	Scripts   *[]string
+}
+
+// NewLoadWatchListCmd returns a new instance which can be used to issue a
+// loadwatchlist JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+//
+// NOTE: This is a pktd extension and requires a websocket connection.
+func NewLoadWatchListCmd(reload bool, addresses []string, scripts *[]string) *LoadWatchListCmd {
+	return &LoadWatchListCmd{
+		Reload:    reload,
+		Addresses: addresses,
+		Scripts:   scripts,
+	}
+}
+
+// RemoveWatchListCmd defines the removewatchlist JSON-RPC command which
+// removes addresses and raw output scripts from the websocket client's watch
+// list.
+//
+// NOTE: This is a pktd extension and requires a websocket connection.
+type RemoveWatchListCmd struct {
+	Addresses []string
+	Scripts   *[]string
+}
+
+// NewRemoveWatchListCmd returns a new instance which can be used to issue a
+// removewatchlist JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+//
+// NOTE: This is a pktd extension and requires a websocket connection.
+func NewRemoveWatchListCmd(addresses []string, scripts *[]string) *RemoveWatchListCmd {
+	return &RemoveWatchListCmd{
+		Addresses: addresses,
+		Scripts:   scripts,
+	}
+}
+
 // NotifySpentCmd defines the notifyspent JSON-RPC command.
 //
 // NOTE: Deprecated. Use LoadTxFilterCmd instead.
@@ -221,21 +297,51 @@ func NewRescanBlocksCmd(blockHashes []string) *RescanBlocksCmd {
 	return &RescanBlocksCmd{BlockHashes: blockHashes}
 }
 
+// StreamBlockHeadersCmd defines the streamblockheaders JSON-RPC command.  It
+// sends the headers of the main chain from StartHeight to EndHeight, the best
+// height when it is not set, in blockheaders notifications.
+//
+// NOTE: This is a pktd extension and requires a websocket connection.
+type StreamBlockHeadersCmd struct {
+	StartHeight int32
+	EndHeight   *int32
+	Verbose     *bool `jsonrpcdefault:"false"`
+}
+
+// NewStreamBlockHeadersCmd returns a new instance which can be used to issue a
+// streamblockheaders JSON-RPC command.
+//
+// NOTE: This is a pktd extension and requires a websocket connection.
+func NewStreamBlockHeadersCmd(startHeight int32, endHeight *int32,
+	verbose *bool) *StreamBlockHeadersCmd {
+
+	return &StreamBlockHeadersCmd{
+		StartHeight: startHeight,
+		EndHeight:   endHeight,
+		Verbose:     verbose,
+	}
+}
+
 func init() {
 	// The commands in this file are only usable by websockets.
 	flags := UFWebsocketOnly
 
 	MustRegisterCmd("authenticate", (*AuthenticateCmd)(nil), flags)
 	MustRegisterCmd("loadtxfilter", (*LoadTxFilterCmd)(nil), flags)
+	MustRegisterCmd("loadwatchlist", (*LoadWatchListCmd)(nil), flags)
 	MustRegisterCmd("notifyblocks", (*NotifyBlocksCmd)(nil), flags)
 	MustRegisterCmd("notifynewtransactions", (*NotifyNewTransactionsCmd)(nil), flags)
 	MustRegisterCmd("notifyreceived", (*NotifyReceivedCmd)(nil), flags)
 	MustRegisterCmd("notifyspent", (*NotifySpentCmd)(nil), flags)
+	MustRegisterCmd("notifyutxodeltas", (*NotifyUtxoDeltasCmd)(nil), flags)
+	MustRegisterCmd("removewatchlist", (*RemoveWatchListCmd)(nil), flags)
 	MustRegisterCmd("session", (*SessionCmd)(nil), flags)
 	MustRegisterCmd("stopnotifyblocks", (*StopNotifyBlocksCmd)(nil), flags)
+	MustRegisterCmd("streamblockheaders", (*StreamBlockHeadersCmd)(nil), flags)
 	MustRegisterCmd("stopnotifynewtransactions", (*StopNotifyNewTransactionsCmd)(nil), flags)
 	MustRegisterCmd("stopnotifyspent", (*StopNotifySpentCmd)(nil), flags)
 	MustRegisterCmd("stopnotifyreceived", (*StopNotifyReceivedCmd)(nil), flags)
+	MustRegisterCmd("stopnotifyutxodeltas", (*StopNotifyUtxoDeltasCmd)(nil), flags)
 	MustRegisterCmd("rescan", (*RescanCmd)(nil), flags)
 	MustRegisterCmd("rescanblocks", (*RescanBlocksCmd)(nil), flags)
 }
@@ -63,6 +63,58 @@ func TestChainSvrWsCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"stopnotifyblocks","params":[],"id":1}`,
 			unmarshalled: &btcjson.StopNotifyBlocksCmd{},
 		},
+		{
+			name: "notifyutxodeltas",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("notifyutxodeltas")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewNotifyUtxoDeltasCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"notifyutxodeltas","params":[],"id":1}`,
+			unmarshalled: &btcjson.NotifyUtxoDeltasCmd{},
+		},
+		{
+			name: "stopnotifyutxodeltas",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("stopnotifyutxodeltas")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewStopNotifyUtxoDeltasCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"stopnotifyutxodeltas","params":[],"id":1}`,
+			unmarshalled: &btcjson.StopNotifyUtxoDeltasCmd{},
+		},
+		{
+			name: "streamblockheaders",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("streamblockheaders", 0)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewStreamBlockHeadersCmd(0, nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"streamblockheaders","params":[0],"id":1}`,
+			unmarshalled: &btcjson.StreamBlockHeadersCmd{
+				StartHeight: 0,
+				Verbose:     btcjson.Bool(false),
+			},
+		},
+		{
+			name: "streamblockheaders optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("streamblockheaders", 0, 5000, true)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewStreamBlockHeadersCmd(0, btcjson.Int32(5000),
+					btcjson.Bool(true))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"streamblockheaders","params":[0,5000,true],"id":1}`,
+			unmarshalled: &btcjson.StreamBlockHeadersCmd{
+				StartHeight: 0,
+				EndHeight:   btcjson.Int32(5000),
+				Verbose:     btcjson.Bool(true),
+			},
+		},
 		{
 			name: "notifynewtransactions",
 			newCmd: func() (interface{}, error) {
@@ -213,6 +265,51 @@ func TestChainSvrWsCmds(t *testing.T) {
 				OutPoints: []btcjson.OutPoint{{Hash: "0000000000000000000000000000000000000000000000000000000000000123", Index: 0}},
 			},
 		},
+		{
+			name: "loadwatchlist",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("loadwatchlist", true, `["1Address"]`)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewLoadWatchListCmd(true, []string{"1Address"}, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"loadwatchlist","params":[true,["1Address"]],"id":1}`,
+			unmarshalled: &btcjson.LoadWatchListCmd{
+				Reload:    true,
+				Addresses: []string{"1Address"},
+			},
+		},
+		{
+			name: "loadwatchlist scripts",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("loadwatchlist", false, `[]`, `["6a"]`)
+			},
+			staticCmd: func() interface{} {
+				scripts := []string{"6a"}
+				return btcjson.NewLoadWatchListCmd(false, []string{}, &scripts)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"loadwatchlist","params":[false,[],["6a"]],"id":1}`,
+			unmarshalled: &btcjson.LoadWatchListCmd{
+				Reload:    false,
+				Addresses: []string{},
+				Scripts:   &[]string{"6a"},
+			},
+		},
+		{
+			name: "removewatchlist",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("removewatchlist", `["1Address"]`, `["6a"]`)
+			},
+			staticCmd: func() interface{} {
+				scripts := []string{"6a"}
+				return btcjson.NewRemoveWatchListCmd([]string{"1Address"}, &scripts)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"removewatchlist","params":[["1Address"],["6a"]],"id":1}`,
+			unmarshalled: &btcjson.RemoveWatchListCmd{
+				Addresses: []string{"1Address"},
+				Scripts:   &[]string{"6a"},
+			},
+		},
 		{
 			name: "rescanblocks",
 			newCmd: func() (interface{}, error) {
@@ -75,6 +75,21 @@ const (
 	// from the chain server that inform a client that a transaction that
 	// matches the loaded filter was accepted by the mempool.
 	RelevantTxAcceptedNtfnMethod = "relevanttxaccepted"
+
+	// WatchedTxNtfnMethod is the method used for notifications from the
+	// chain server that a mempool or newly mined transaction pays to or
+	// spends from a script on the client's watch list.
+	WatchedTxNtfnMethod = "watchedtx"
+
+	// UtxoDeltasNtfnMethod is the method used for notifications from the
+	// chain server that a block was connected to or disconnected from the
+	// main chain, along with the outputs it creates and spends.
+	UtxoDeltasNtfnMethod = "utxodeltas"
+
+	// BlockHeadersNtfnMethod is the method used for notifications from the
+	// chain server of a batch of the main chain headers requested with the
+	// streamblockheaders command.
+	BlockHeadersNtfnMethod = "blockheaders"
 )
 
 // BlockConnectedNtfn defines the blockconnected JSON-RPC notification.
@@ -285,6 +300,67 @@ func NewRelevantTxAcceptedNtfn(txHex string) *RelevantTxAcceptedNtfn {
 	return &RelevantTxAcceptedNtfn{Transaction: txHex}
 }
 
+// WatchedTxMatch describes an input or output of a transaction which matches
+// a watched script.
+type WatchedTxMatch struct {
+	Script  string `json:"script"`
+	Address string `json:"address,omitempty"`
+	Input   bool   `json:"input"`
+	Index   uint32 `json:"index"`
+}
+
+// WatchedTxNtfn defines the watchedtx JSON-RPC notification.
+//
+// NOTE: This is a pktd extension.
+type WatchedTxNtfn struct {
+	Transaction string
+	Matches     []WatchedTxMatch
+	Block       *BlockDetails
+}
+
+// NewWatchedTxNtfn returns a new instance which can be used to issue a
+// watchedtx JSON-RPC notification.  The block is nil for a mempool
+// transaction.
+//
+// NOTE: This is a pktd extension.
+func NewWatchedTxNtfn(txHex string, matches []WatchedTxMatch, block *BlockDetails) *WatchedTxNtfn {
+	return &WatchedTxNtfn{
+		Transaction: txHex,
+		Matches:     matches,
+		Block:       block,
+	}
+}
+
+// UtxoDeltasNtfn defines the utxodeltas JSON-RPC notification.
+//
+// NOTE: This is a pktd extension.
+type UtxoDeltasNtfn struct {
+	Deltas UtxoDeltasResult
+}
+
+// NewUtxoDeltasNtfn returns a new instance which can be used to issue a
+// utxodeltas JSON-RPC notification.
+//
+// NOTE: This is a pktd extension.
+func NewUtxoDeltasNtfn(deltas UtxoDeltasResult) *UtxoDeltasNtfn {
+	return &UtxoDeltasNtfn{Deltas: deltas}
+}
+
+// BlockHeadersNtfn defines the blockheaders JSON-RPC notification.
+//
+// NOTE: This is a pktd extension.
+type BlockHeadersNtfn struct {
+	Headers BlockHeadersResult
+}
+
+// NewBlockHeadersNtfn returns a new instance which can be used to issue a
+// blockheaders JSON-RPC notification.
+//
+// NOTE: This is a pktd extension.
+func NewBlockHeadersNtfn(headers BlockHeadersResult) *BlockHeadersNtfn {
+	return &BlockHeadersNtfn{Headers: headers}
+}
+
 func init() {
 	// The commands in this file are only usable by websockets and are
 	// notifications.
@@ -301,4 +377,7 @@ func init() {
 	MustRegisterCmd(TxAcceptedNtfnMethod, (*TxAcceptedNtfn)(nil), flags)
 	MustRegisterCmd(TxAcceptedVerboseNtfnMethod, (*TxAcceptedVerboseNtfn)(nil), flags)
 	MustRegisterCmd(RelevantTxAcceptedNtfnMethod, (*RelevantTxAcceptedNtfn)(nil), flags)
+	MustRegisterCmd(WatchedTxNtfnMethod, (*WatchedTxNtfn)(nil), flags)
+	MustRegisterCmd(UtxoDeltasNtfnMethod, (*UtxoDeltasNtfn)(nil), flags)
+	MustRegisterCmd(BlockHeadersNtfnMethod, (*BlockHeadersNtfn)(nil), flags)
 }
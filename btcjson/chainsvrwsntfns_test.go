@@ -225,6 +225,98 @@ func TestChainSvrWsNtfns(t *testing.T) {
 				Transaction: "001122",
 			},
 		},
+		{
+			name: "watchedtx",
+			newNtfn: func() (interface{}, error) {
+				return btcjson.NewCmd("watchedtx", "001122", `[{"script":"6a","input":true,"index":1}]`, `{"height":100000,"hash":"123","index":0,"time":12345678}`)
+			},
+			staticNtfn: func() interface{} {
+				matches := []btcjson.WatchedTxMatch{{Script: "6a", Input: true, Index: 1}}
+				blockDetails := &btcjson.BlockDetails{
+					Height: 100000,
+					Hash:   "123",
+					Index:  0,
+					Time:   12345678,
+				}
+				return btcjson.NewWatchedTxNtfn("001122", matches, blockDetails)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"watchedtx","params":["001122",[{"script":"6a","input":true,"index":1}],{"height":100000,"hash":"123","index":0,"time":12345678}],"id":null}`,
+			unmarshalled: &btcjson.WatchedTxNtfn{
+				Transaction: "001122",
+				Matches:     []btcjson.WatchedTxMatch{{Script: "6a", Input: true, Index: 1}},
+				Block: &btcjson.BlockDetails{
+					Height: 100000,
+					Hash:   "123",
+					Index:  0,
+					Time:   12345678,
+				},
+			},
+		},
+		{
+			name: "watchedtx mempool",
+			newNtfn: func() (interface{}, error) {
+				return btcjson.NewCmd("watchedtx", "001122", `[{"script":"76a914","address":"1Address","input":false,"index":0}]`)
+			},
+			staticNtfn: func() interface{} {
+				matches := []btcjson.WatchedTxMatch{{Script: "76a914", Address: "1Address"}}
+				return btcjson.NewWatchedTxNtfn("001122", matches, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"watchedtx","params":["001122",[{"script":"76a914","address":"1Address","input":false,"index":0}]],"id":null}`,
+			unmarshalled: &btcjson.WatchedTxNtfn{
+				Transaction: "001122",
+				Matches:     []btcjson.WatchedTxMatch{{Script: "76a914", Address: "1Address"}},
+			},
+		},
+		{
+			name: "utxodeltas",
+			newNtfn: func() (interface{}, error) {
+				return btcjson.NewCmd("utxodeltas", `{"type":"connected","hash":"123","previoushash":"122","height":100000,"created":[{"txid":"456","vout":0,"amount":1,"scriptpubkey":"6a","height":100000,"coinbase":true}],"spent":[]}`)
+			},
+			staticNtfn: func() interface{} {
+				return btcjson.NewUtxoDeltasNtfn(btcjson.UtxoDeltasResult{
+					Type:         "connected",
+					Hash:         "123",
+					PreviousHash: "122",
+					Height:       100000,
+					Created: []btcjson.ChainEventUtxo{{TxID: "456", Amount: 1,
+						ScriptPubKey: "6a", Height: 100000, Coinbase: true}},
+					Spent: []btcjson.ChainEventUtxo{},
+				})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"utxodeltas","params":[{"type":"connected","hash":"123","previoushash":"122","height":100000,"created":[{"txid":"456","vout":0,"amount":1,"scriptpubkey":"6a","height":100000,"coinbase":true}],"spent":[]}],"id":null}`,
+			unmarshalled: &btcjson.UtxoDeltasNtfn{
+				Deltas: btcjson.UtxoDeltasResult{
+					Type:         "connected",
+					Hash:         "123",
+					PreviousHash: "122",
+					Height:       100000,
+					Created: []btcjson.ChainEventUtxo{{TxID: "456", Amount: 1,
+						ScriptPubKey: "6a", Height: 100000, Coinbase: true}},
+					Spent: []btcjson.ChainEventUtxo{},
+				},
+			},
+		},
+		{
+			name: "blockheaders",
+			newNtfn: func() (interface{}, error) {
+				return btcjson.NewCmd("blockheaders", `{"startheight":100,"count":1,"hex":"0011"}`)
+			},
+			staticNtfn: func() interface{} {
+				return btcjson.NewBlockHeadersNtfn(btcjson.BlockHeadersResult{
+					StartHeight: 100,
+					Count:       1,
+					Hex:         "0011",
+				})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"blockheaders","params":[{"startheight":100,"count":1,"hex":"0011"}],"id":null}`,
+			unmarshalled: &btcjson.BlockHeadersNtfn{
+				Headers: btcjson.BlockHeadersResult{
+					StartHeight: 100,
+					Count:       1,
+					Hex:         "0011",
+				},
+			},
+		},
 	}
 
 	t.Logf("Running %d tests", len(tests))
@@ -59,6 +59,11 @@ const (
 	ErrRPCWalletWrongEncState       RPCErrorCode = -15
 	ErrRPCWalletEncryptionFailed    RPCErrorCode = -16
 	ErrRPCWalletAlreadyUnlocked     RPCErrorCode = -17
+
+	// ErrRPCWalletSendHeld is returned by the commands sending coins when
+	// the signer policy holds the send, with its ID in the message, or
+	// denies it.
+	ErrRPCWalletSendHeld RPCErrorCode = -100
 )
 
 // Specific Errors related to commands.  These are the ones a user of the RPC
@@ -72,6 +77,9 @@ const (
 	ErrRPCOutOfRange        RPCErrorCode = -1
 	ErrRPCNoTxInfo          RPCErrorCode = -5
 	ErrRPCNoCFIndex         RPCErrorCode = -5
+	ErrRPCNoUtxoStatsIndex  RPCErrorCode = -5
+	ErrRPCNoFeeStatsIndex   RPCErrorCode = -5
+	ErrRPCNoDepositTracker  RPCErrorCode = -5
 	ErrRPCNoNewestBlockInfo RPCErrorCode = -5
 	ErrRPCInvalidTxVout     RPCErrorCode = -5
 	ErrRPCRawTxString       RPCErrorCode = -32602
@@ -47,6 +47,19 @@ func NewAddWitnessAddressCmd(address string) *AddWitnessAddressCmd {
 	}
 }
 
+// BackupWalletCmd defines the backupwallet JSON-RPC command.
+type BackupWalletCmd struct {
+	Destination string
+}
+
+// NewBackupWalletCmd returns a new instance which can be used to issue a
+// backupwallet JSON-RPC command.
+func NewBackupWalletCmd(destination string) *BackupWalletCmd {
+	return &BackupWalletCmd{
+		Destination: destination,
+	}
+}
+
 // CreateMultisigCmd defines the createmultisig JSON-RPC command.
 type CreateMultisigCmd struct {
 	NRequired int
@@ -114,6 +127,28 @@ func NewEstimatePriorityCmd(numBlocks int64) *EstimatePriorityCmd {
 	}
 }
 
+// FundRawTransactionCmd defines the fundrawtransaction JSON-RPC command.
+// Inputs which are already present in the transaction are always kept, which
+// allows pinning specific outputs, and more are only added when needed.
+type FundRawTransactionCmd struct {
+	HexTx   string
+	MinConf *int `jsonrpcdefault:"1"`
+	Change  *ChangeOptions
+}
+
+// NewFundRawTransactionCmd returns a new instance which can be used to issue
+// a fundrawtransaction JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewFundRawTransactionCmd(hexTx string, minConf *int, change *ChangeOptions) *FundRawTransactionCmd {
+	return &FundRawTransactionCmd{
+		HexTx:   hexTx,
+		MinConf: minConf,
+		Change:  change,
+	}
+}
+
 // GetAccountCmd defines the getaccount JSON-RPC command.
 type GetAccountCmd struct {
 	Address string
@@ -266,11 +301,14 @@ func NewGetWalletInfoCmd() *GetWalletInfoCmd {
 	return &GetWalletInfoCmd{}
 }
 
-// ImportPrivKeyCmd defines the importprivkey JSON-RPC command.
+// ImportPrivKeyCmd defines the importprivkey JSON-RPC command.  KeyOrigin is
+// the origin of the key in the [fingerprint/path] form of descriptors, which
+// the wallet records so signers can match the key.
 type ImportPrivKeyCmd struct {
-	PrivKey string
-	Label   *string
-	Rescan  *bool `jsonrpcdefault:"true"`
+	PrivKey   string
+	Label     *string
+	Rescan    *bool `jsonrpcdefault:"true"`
+	KeyOrigin *string
 }
 
 // NewImportPrivKeyCmd returns a new instance which can be used to issue a
@@ -377,10 +415,21 @@ func NewListReceivedByAddressCmd(minConf *int, includeEmpty, includeWatchOnly *b
 }
 
 // ListSinceBlockCmd defines the listsinceblock JSON-RPC command.
+//
+// When IncludeRemoved is set, transactions which were in blocks since
+// BlockHash that have since been disconnected by a reorganization are listed
+// separately so they can be reverted.  Results can be paged by setting Count
+// and passing the returned cursor back in Cursor with the same BlockHash.  An
+// empty BlockHash lists all transactions like an omitted one, and an empty
+// Cursor requests the first page.
 type ListSinceBlockCmd struct {
 	BlockHash           *string
 	TargetConfirmations *int  `jsonrpcdefault:"1"`
 	IncludeWatchOnly    *bool `jsonrpcdefault:"false"`
+	IncludeRemoved      *bool `jsonrpcdefault:"true"`
+	IncludeChange       *bool `jsonrpcdefault:"false"`
+	Cursor              *string
+	Count               *int
 }
 
 // NewListSinceBlockCmd returns a new instance which can be used to issue a
@@ -439,9 +488,13 @@ func NewListUnspentCmd(minConf, maxConf *int, addresses *[]string) *ListUnspentC
 }
 
 // LockUnspentCmd defines the lockunspent JSON-RPC command.
+//
+// Locks are only kept in memory unless Persistent is set, in which case they
+// are written to the wallet database and survive a restart.
 type LockUnspentCmd struct {
 	Unlock       bool
 	Transactions []TransactionInput
+	Persistent   *bool
 }
 
 // NewLockUnspentCmd returns a new instance which can be used to issue a
@@ -477,6 +530,43 @@ func NewMoveCmd(fromAccount, toAccount string, amount float64, minConf *int, com
 	}
 }
 
+// RestoreWalletCmd defines the restorewallet JSON-RPC command.  The backup is
+// verified to decrypt before it replaces the current wallet, which is itself
+// backed up first.
+type RestoreWalletCmd struct {
+	BackupFile string
+}
+
+// NewRestoreWalletCmd returns a new instance which can be used to issue a
+// restorewallet JSON-RPC command.
+func NewRestoreWalletCmd(backupFile string) *RestoreWalletCmd {
+	return &RestoreWalletCmd{
+		BackupFile: backupFile,
+	}
+}
+
+// Change address types which may be requested with ChangeOptions.
+const (
+	ChangeTypeP2PKH  = "p2pkh"
+	ChangeTypeP2WPKH = "p2wpkh"
+)
+
+// ChangeOptions controls how the wallet handles change for the commands which
+// fund a transaction.
+//
+// When Address is set, change is paid to it rather than to a newly derived
+// address, otherwise Type selects the kind of address derived from the
+// internal branch of the account.  When NoChange is set the command fails
+// instead of adding a change output, unless the excess is below the dust
+// limit in which case it is added to the fee.  SubtractFeeFrom lists the
+// output addresses which pay the fee, split evenly, rather than the inputs.
+type ChangeOptions struct {
+	Address         *string  `json:"address,omitempty"`
+	Type            *string  `json:"type,omitempty"`
+	NoChange        *bool    `json:"nochange,omitempty"`
+	SubtractFeeFrom []string `json:"subtractfeefrom,omitempty"`
+}
+
 // SendFromCmd defines the sendfrom JSON-RPC command.
 type SendFromCmd struct {
 	FromAccount string
@@ -485,6 +575,7 @@ type SendFromCmd struct {
 	MinConf     *int    `jsonrpcdefault:"1"`
 	Comment     *string
 	CommentTo   *string
+	Change      *ChangeOptions
 }
 
 // NewSendFromCmd returns a new instance which can be used to issue a sendfrom
@@ -514,11 +605,16 @@ type CreateTransactionCmd struct {
 }
 
 // SendManyCmd defines the sendmany JSON-RPC command.
+//
+// When Inputs is set, exactly those outputs are spent, even if they are
+// locked, and no other coins are selected.
 type SendManyCmd struct {
 	FromAccount string
 	Amounts     map[string]float64 `jsonrpcusage:"{\"address\":amount,...}"` // In BTC
 	MinConf     *int               `jsonrpcdefault:"1"`
 	Comment     *string
+	Change      *ChangeOptions
+	Inputs      *[]TransactionInput
 }
 
 // NewSendManyCmd returns a new instance which can be used to issue a sendmany
@@ -536,11 +632,16 @@ func NewSendManyCmd(fromAccount string, amounts map[string]float64, minConf *int
 }
 
 // SendToAddressCmd defines the sendtoaddress JSON-RPC command.
+//
+// When Inputs is set, exactly those outputs are spent, even if they are
+// locked, and no other coins are selected.
 type SendToAddressCmd struct {
 	Address   string
 	Amount    float64
 	Comment   *string
 	CommentTo *string
+	Change    *ChangeOptions
+	Inputs    *[]TransactionInput
 }
 
 // NewSendToAddressCmd returns a new instance which can be used to issue a
@@ -641,9 +742,13 @@ func NewWalletLockCmd() *WalletLockCmd {
 }
 
 // WalletPassphraseCmd defines the walletpassphrase JSON-RPC command.
+//
+// The optional Scope restricts what the unlocked wallet may be used for, see
+// WalletUnlockScope.  When it is omitted the wallet is fully unlocked.
 type WalletPassphraseCmd struct {
 	Passphrase string
 	Timeout    int64
+	Scope      *WalletUnlockScope `jsonrpcusage:"\"all|signing|staking\""`
 }
 
 // NewWalletPassphraseCmd returns a new instance which can be used to issue a
@@ -655,6 +760,17 @@ func NewWalletPassphraseCmd(passphrase string, timeout int64) *WalletPassphraseC
 	}
 }
 
+// NewWalletPassphraseScopedCmd returns a new instance which can be used to
+// issue a walletpassphrase JSON-RPC command which only unlocks the wallet for
+// the passed scope.
+func NewWalletPassphraseScopedCmd(passphrase string, timeout int64, scope WalletUnlockScope) *WalletPassphraseCmd {
+	return &WalletPassphraseCmd{
+		Passphrase: passphrase,
+		Timeout:    timeout,
+		Scope:      &scope,
+	}
+}
+
 // WalletPassphraseChangeCmd defines the walletpassphrase JSON-RPC command.
 type WalletPassphraseChangeCmd struct {
 	OldPassphrase string
@@ -699,12 +815,14 @@ func init() {
 	MustRegisterCmd("addmultisigaddress", (*AddMultisigAddressCmd)(nil), flags)
 	MustRegisterCmd("addp2shscript", (*AddP2shScriptCmd)(nil), flags)
 	MustRegisterCmd("addwitnessaddress", (*AddWitnessAddressCmd)(nil), flags)
+	MustRegisterCmd("backupwallet", (*BackupWalletCmd)(nil), flags)
 	MustRegisterCmd("createmultisig", (*CreateMultisigCmd)(nil), flags)
 	MustRegisterCmd("createtransaction", (*CreateTransactionCmd)(nil), flags)
 	MustRegisterCmd("dumpprivkey", (*DumpPrivKeyCmd)(nil), flags)
 	MustRegisterCmd("encryptwallet", (*EncryptWalletCmd)(nil), flags)
 	MustRegisterCmd("estimatefee", (*EstimateFeeCmd)(nil), flags)
 	MustRegisterCmd("estimatepriority", (*EstimatePriorityCmd)(nil), flags)
+	MustRegisterCmd("fundrawtransaction", (*FundRawTransactionCmd)(nil), flags)
 	MustRegisterCmd("getaccount", (*GetAccountCmd)(nil), flags)
 	MustRegisterCmd("getaccountaddress", (*GetAccountAddressCmd)(nil), flags)
 	MustRegisterCmd("getaddressesbyaccount", (*GetAddressesByAccountCmd)(nil), flags)
@@ -728,6 +846,7 @@ func init() {
 	MustRegisterCmd("listunspent", (*ListUnspentCmd)(nil), flags)
 	MustRegisterCmd("lockunspent", (*LockUnspentCmd)(nil), flags)
 	MustRegisterCmd("move", (*MoveCmd)(nil), flags)
+	MustRegisterCmd("restorewallet", (*RestoreWalletCmd)(nil), flags)
 	MustRegisterCmd("sendfrom", (*SendFromCmd)(nil), flags)
 	MustRegisterCmd("sendmany", (*SendManyCmd)(nil), flags)
 	MustRegisterCmd("sendtoaddress", (*SendToAddressCmd)(nil), flags)
@@ -74,6 +74,19 @@ func TestWalletSvrCmds(t *testing.T) {
 				Address: "1address",
 			},
 		},
+		{
+			name: "backupwallet",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("backupwallet", "/backups/wallet.db")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewBackupWalletCmd("/backups/wallet.db")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"backupwallet","params":["/backups/wallet.db"],"id":1}`,
+			unmarshalled: &btcjson.BackupWalletCmd{
+				Destination: "/backups/wallet.db",
+			},
+		},
 		{
 			name: "createmultisig",
 			newCmd: func() (interface{}, error) {
@@ -141,6 +154,43 @@ func TestWalletSvrCmds(t *testing.T) {
 				NumBlocks: 6,
 			},
 		},
+		{
+			name: "fundrawtransaction",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("fundrawtransaction", "001122")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewFundRawTransactionCmd("001122", nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"fundrawtransaction","params":["001122"],"id":1}`,
+			unmarshalled: &btcjson.FundRawTransactionCmd{
+				HexTx:   "001122",
+				MinConf: btcjson.Int(1),
+			},
+		},
+		{
+			name: "fundrawtransaction optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("fundrawtransaction", "001122", 6,
+					`{"type":"p2wpkh","nochange":true}`)
+			},
+			staticCmd: func() interface{} {
+				change := btcjson.ChangeOptions{
+					Type:     btcjson.String(btcjson.ChangeTypeP2WPKH),
+					NoChange: btcjson.Bool(true),
+				}
+				return btcjson.NewFundRawTransactionCmd("001122", btcjson.Int(6), &change)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"fundrawtransaction","params":["001122",6,{"type":"p2wpkh","nochange":true}],"id":1}`,
+			unmarshalled: &btcjson.FundRawTransactionCmd{
+				HexTx:   "001122",
+				MinConf: btcjson.Int(6),
+				Change: &btcjson.ChangeOptions{
+					Type:     btcjson.String(btcjson.ChangeTypeP2WPKH),
+					NoChange: btcjson.Bool(true),
+				},
+			},
+		},
 		{
 			name: "getaccount",
 			newCmd: func() (interface{}, error) {
@@ -414,6 +464,25 @@ func TestWalletSvrCmds(t *testing.T) {
 				Rescan:  btcjson.Bool(false),
 			},
 		},
+		{
+			name: "importprivkey key origin",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("importprivkey", "abc", "label", false,
+					"[d34db33f/44'/0'/0'/0/1]")
+			},
+			staticCmd: func() interface{} {
+				cmd := btcjson.NewImportPrivKeyCmd("abc", btcjson.String("label"), btcjson.Bool(false))
+				cmd.KeyOrigin = btcjson.String("[d34db33f/44'/0'/0'/0/1]")
+				return cmd
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"importprivkey","params":["abc","label",false,"[d34db33f/44'/0'/0'/0/1]"],"id":1}`,
+			unmarshalled: &btcjson.ImportPrivKeyCmd{
+				PrivKey:   "abc",
+				Label:     btcjson.String("label"),
+				Rescan:    btcjson.Bool(false),
+				KeyOrigin: btcjson.String("[d34db33f/44'/0'/0'/0/1]"),
+			},
+		},
 		{
 			name: "keypoolrefill",
 			newCmd: func() (interface{}, error) {
@@ -621,6 +690,8 @@ func TestWalletSvrCmds(t *testing.T) {
 				BlockHash:           nil,
 				TargetConfirmations: btcjson.Int(1),
 				IncludeWatchOnly:    btcjson.Bool(false),
+				IncludeRemoved:      btcjson.Bool(true),
+				IncludeChange:       btcjson.Bool(false),
 			},
 		},
 		{
@@ -636,6 +707,8 @@ func TestWalletSvrCmds(t *testing.T) {
 				BlockHash:           btcjson.String("123"),
 				TargetConfirmations: btcjson.Int(1),
 				IncludeWatchOnly:    btcjson.Bool(false),
+				IncludeRemoved:      btcjson.Bool(true),
+				IncludeChange:       btcjson.Bool(false),
 			},
 		},
 		{
@@ -651,6 +724,8 @@ func TestWalletSvrCmds(t *testing.T) {
 				BlockHash:           btcjson.String("123"),
 				TargetConfirmations: btcjson.Int(6),
 				IncludeWatchOnly:    btcjson.Bool(false),
+				IncludeRemoved:      btcjson.Bool(true),
+				IncludeChange:       btcjson.Bool(false),
 			},
 		},
 		{
@@ -666,6 +741,32 @@ func TestWalletSvrCmds(t *testing.T) {
 				BlockHash:           btcjson.String("123"),
 				TargetConfirmations: btcjson.Int(6),
 				IncludeWatchOnly:    btcjson.Bool(true),
+				IncludeRemoved:      btcjson.Bool(true),
+				IncludeChange:       btcjson.Bool(false),
+			},
+		},
+		{
+			name: "listsinceblock optional4",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("listsinceblock", "123", 6, true, false, true, "cursor", 100)
+			},
+			staticCmd: func() interface{} {
+				cmd := btcjson.NewListSinceBlockCmd(btcjson.String("123"), btcjson.Int(6), btcjson.Bool(true))
+				cmd.IncludeRemoved = btcjson.Bool(false)
+				cmd.IncludeChange = btcjson.Bool(true)
+				cmd.Cursor = btcjson.String("cursor")
+				cmd.Count = btcjson.Int(100)
+				return cmd
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"listsinceblock","params":["123",6,true,false,true,"cursor",100],"id":1}`,
+			unmarshalled: &btcjson.ListSinceBlockCmd{
+				BlockHash:           btcjson.String("123"),
+				TargetConfirmations: btcjson.Int(6),
+				IncludeWatchOnly:    btcjson.Bool(true),
+				IncludeRemoved:      btcjson.Bool(false),
+				IncludeChange:       btcjson.Bool(true),
+				Cursor:              btcjson.String("cursor"),
+				Count:               btcjson.Int(100),
 			},
 		},
 		{
@@ -830,6 +931,28 @@ func TestWalletSvrCmds(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "lockunspent persistent",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("lockunspent", false, `[{"txid":"123","vout":1}]`, true)
+			},
+			staticCmd: func() interface{} {
+				txInputs := []btcjson.TransactionInput{
+					{Txid: "123", Vout: 1},
+				}
+				cmd := btcjson.NewLockUnspentCmd(false, txInputs)
+				cmd.Persistent = btcjson.Bool(true)
+				return cmd
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"lockunspent","params":[false,[{"txid":"123","vout":1}],true],"id":1}`,
+			unmarshalled: &btcjson.LockUnspentCmd{
+				Unlock: false,
+				Transactions: []btcjson.TransactionInput{
+					{Txid: "123", Vout: 1},
+				},
+				Persistent: btcjson.Bool(true),
+			},
+		},
 		{
 			name: "move",
 			newCmd: func() (interface{}, error) {
@@ -881,6 +1004,19 @@ func TestWalletSvrCmds(t *testing.T) {
 				Comment:     btcjson.String("comment"),
 			},
 		},
+		{
+			name: "restorewallet",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("restorewallet", "/backups/wallet.db")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewRestoreWalletCmd("/backups/wallet.db")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"restorewallet","params":["/backups/wallet.db"],"id":1}`,
+			unmarshalled: &btcjson.RestoreWalletCmd{
+				BackupFile: "/backups/wallet.db",
+			},
+		},
 		{
 			name: "sendfrom",
 			newCmd: func() (interface{}, error) {
@@ -1006,6 +1142,33 @@ func TestWalletSvrCmds(t *testing.T) {
 				Comment:     btcjson.String("comment"),
 			},
 		},
+		{
+			name: "sendmany optional3",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("sendmany", "from", `{"1Address":0.5}`, 6, "comment",
+					`{"address":"1Change","subtractfeefrom":["1Address"]}`)
+			},
+			staticCmd: func() interface{} {
+				amounts := map[string]float64{"1Address": 0.5}
+				cmd := btcjson.NewSendManyCmd("from", amounts, btcjson.Int(6), btcjson.String("comment"))
+				cmd.Change = &btcjson.ChangeOptions{
+					Address:         btcjson.String("1Change"),
+					SubtractFeeFrom: []string{"1Address"},
+				}
+				return cmd
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"sendmany","params":["from",{"1Address":0.5},6,"comment",{"address":"1Change","subtractfeefrom":["1Address"]}],"id":1}`,
+			unmarshalled: &btcjson.SendManyCmd{
+				FromAccount: "from",
+				Amounts:     map[string]float64{"1Address": 0.5},
+				MinConf:     btcjson.Int(6),
+				Comment:     btcjson.String("comment"),
+				Change: &btcjson.ChangeOptions{
+					Address:         btcjson.String("1Change"),
+					SubtractFeeFrom: []string{"1Address"},
+				},
+			},
+		},
 		{
 			name: "sendtoaddress",
 			newCmd: func() (interface{}, error) {
@@ -1039,6 +1202,33 @@ func TestWalletSvrCmds(t *testing.T) {
 				CommentTo: btcjson.String("commentto"),
 			},
 		},
+		{
+			name: "sendtoaddress optional2",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("sendtoaddress", "1Address", 0.5, "comment",
+					"commentto", `{}`, `[{"txid":"123","vout":1}]`)
+			},
+			staticCmd: func() interface{} {
+				cmd := btcjson.NewSendToAddressCmd("1Address", 0.5, btcjson.String("comment"),
+					btcjson.String("commentto"))
+				cmd.Change = &btcjson.ChangeOptions{}
+				cmd.Inputs = &[]btcjson.TransactionInput{
+					{Txid: "123", Vout: 1},
+				}
+				return cmd
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"sendtoaddress","params":["1Address",0.5,"comment","commentto",{},[{"txid":"123","vout":1}]],"id":1}`,
+			unmarshalled: &btcjson.SendToAddressCmd{
+				Address:   "1Address",
+				Amount:    0.5,
+				Comment:   btcjson.String("comment"),
+				CommentTo: btcjson.String("commentto"),
+				Change:    &btcjson.ChangeOptions{},
+				Inputs: &[]btcjson.TransactionInput{
+					{Txid: "123", Vout: 1},
+				},
+			},
+		},
 		{
 			name: "setaccount",
 			newCmd: func() (interface{}, error) {
@@ -1190,6 +1380,25 @@ func TestWalletSvrCmds(t *testing.T) {
 				Timeout:    60,
 			},
 		},
+		{
+			name: "walletpassphrase scoped",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("walletpassphrase", "pass", 60, "signing")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewWalletPassphraseScopedCmd("pass", 60,
+					btcjson.WalletUnlockScopeSigning)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"walletpassphrase","params":["pass",60,"signing"],"id":1}`,
+			unmarshalled: &btcjson.WalletPassphraseCmd{
+				Passphrase: "pass",
+				Timeout:    60,
+				Scope: func() *btcjson.WalletUnlockScope {
+					scope := btcjson.WalletUnlockScopeSigning
+					return &scope
+				}(),
+			},
+		},
 		{
 			name: "walletpassphrasechange",
 			newCmd: func() (interface{}, error) {
@@ -34,6 +34,8 @@ type GetTransactionResult struct {
 	TimeReceived    int64                         `json:"timereceived"`
 	Details         []GetTransactionDetailsResult `json:"details"`
 	Hex             string                        `json:"hex"`
+	Memo            string                        `json:"memo,omitempty"`
+	Metadata        map[string]string             `json:"metadata,omitempty"`
 }
 
 // InfoWalletResult models the data returned by the wallet server getinfo
@@ -59,27 +61,29 @@ type InfoWalletResult struct {
 
 // ListTransactionsResult models the data from the listtransactions command.
 type ListTransactionsResult struct {
-	Abandoned         bool     `json:"abandoned"`
-	Account           string   `json:"account"`
-	Address           string   `json:"address,omitempty"`
-	Amount            float64  `json:"amount"`
-	BIP125Replaceable string   `json:"bip125-replaceable,omitempty"`
-	BlockHash         string   `json:"blockhash,omitempty"`
-	BlockIndex        *int64   `json:"blockindex,omitempty"`
-	BlockTime         int64    `json:"blocktime,omitempty"`
-	Category          string   `json:"category"`
-	Confirmations     int64    `json:"confirmations"`
-	Fee               *float64 `json:"fee,omitempty"`
-	Generated         bool     `json:"generated,omitempty"`
-	InvolvesWatchOnly bool     `json:"involveswatchonly,omitempty"`
-	Time              int64    `json:"time"`
-	TimeReceived      int64    `json:"timereceived"`
-	Trusted           bool     `json:"trusted"`
-	TxID              string   `json:"txid"`
-	Vout              uint32   `json:"vout"`
-	WalletConflicts   []string `json:"walletconflicts"`
-	Comment           string   `json:"comment,omitempty"`
-	OtherAccount      string   `json:"otheraccount,omitempty"`
+	Abandoned         bool              `json:"abandoned"`
+	Account           string            `json:"account"`
+	Address           string            `json:"address,omitempty"`
+	Amount            float64           `json:"amount"`
+	BIP125Replaceable string            `json:"bip125-replaceable,omitempty"`
+	BlockHash         string            `json:"blockhash,omitempty"`
+	BlockIndex        *int64            `json:"blockindex,omitempty"`
+	BlockTime         int64             `json:"blocktime,omitempty"`
+	Category          string            `json:"category"`
+	Confirmations     int64             `json:"confirmations"`
+	Fee               *float64          `json:"fee,omitempty"`
+	Generated         bool              `json:"generated,omitempty"`
+	InvolvesWatchOnly bool              `json:"involveswatchonly,omitempty"`
+	Time              int64             `json:"time"`
+	TimeReceived      int64             `json:"timereceived"`
+	Trusted           bool              `json:"trusted"`
+	TxID              string            `json:"txid"`
+	Vout              uint32            `json:"vout"`
+	WalletConflicts   []string          `json:"walletconflicts"`
+	Comment           string            `json:"comment,omitempty"`
+	OtherAccount      string            `json:"otheraccount,omitempty"`
+	Memo              string            `json:"memo,omitempty"`
+	Metadata          map[string]string `json:"metadata,omitempty"`
 }
 
 // ListReceivedByAccountResult models the data from the listreceivedbyaccount
@@ -102,12 +106,276 @@ type ListReceivedByAddressResult struct {
 }
 
 // ListSinceBlockResult models the data from the listsinceblock command.
+//
+// Removed lists the transactions from blocks which were disconnected by a
+// reorganization.  NextCursor is only set when more results remain and
+// LastBlock is the same for every page of a listing.
 type ListSinceBlockResult struct {
 	Transactions []ListTransactionsResult `json:"transactions"`
+	Removed      []ListTransactionsResult `json:"removed,omitempty"`
 	LastBlock    string                   `json:"lastblock"`
+	NextCursor   string                   `json:"nextcursor,omitempty"`
+}
+
+// AccountPolicyResult models the data returned by the getaccountpolicy
+// command.  Withdrawn is the amount sent from the account since PeriodStart,
+// which counts towards the withdrawal limit of the policy, and RPCUser is the
+// username of the credentials of the account, if any.
+type AccountPolicyResult struct {
+	Account     string        `json:"account"`
+	Parent      string        `json:"parent,omitempty"`
+	Label       string        `json:"label,omitempty"`
+	SubAccounts []string      `json:"subaccounts"`
+	Policy      AccountPolicy `json:"policy"`
+	Withdrawn   float64       `json:"withdrawn"`
+	PeriodStart int64         `json:"periodstart"`
+	RPCUser     string        `json:"rpcuser,omitempty"`
+}
+
+// AddressOwnershipProofResult models the data returned by the
+// proveaddressownership command.  Proof is the hex-encoded proof transaction
+// and Height and BlockHash identify the best block when it was created.
+type AddressOwnershipProofResult struct {
+	Proof     string   `json:"proof"`
+	Message   string   `json:"message"`
+	Height    int32    `json:"height"`
+	BlockHash string   `json:"blockhash"`
+	Amount    float64  `json:"amount"`
+	Addresses []string `json:"addresses"`
+}
+
+// PaymentURIResult models the data returned by the createpaymenturi command.
+// QRPayload is the same request encoded so that it fits in a smaller QR code
+// where possible.  PayjoinEndpoint is the payjoin endpoint advertised by the
+// URI, if any.
+type PaymentURIResult struct {
+	Address         string `json:"address"`
+	URI             string `json:"uri"`
+	QRPayload       string `json:"qrpayload"`
+	PayjoinEndpoint string `json:"payjoinendpoint,omitempty"`
+}
+
+// ExternalSigner describes a device available through the external signer
+// command of a wallet.
+type ExternalSigner struct {
+	Fingerprint string `json:"fingerprint"`
+	Name        string `json:"name,omitempty"`
+}
+
+// AccountXPubResult models the data returned by the exportaccountxpub and
+// importaccountxpub commands.  The descriptors of the receive and change
+// addresses include their checksum.  WatchOnly is set for accounts imported
+// by importaccountxpub, which can not sign.
+type AccountXPubResult struct {
+	Bundle            AccountXPubBundle `json:"bundle"`
+	KeyPath           string            `json:"keypath"`
+	ReceiveDescriptor string            `json:"receivedescriptor"`
+	ChangeDescriptor  string            `json:"changedescriptor"`
+	WatchOnly         bool              `json:"watchonly"`
+}
+
+// EnumerateSignersResult models the data returned by the enumeratesigners
+// command.
+type EnumerateSignersResult struct {
+	Signers []ExternalSigner `json:"signers"`
+}
+
+// FundRawTransactionResult models the data returned by the fundrawtransaction
+// command.  ChangePos is the index of the change output, or -1 when no change
+// output was added.
+type FundRawTransactionResult struct {
+	Hex       string  `json:"hex"`
+	Fee       float64 `json:"fee"`
+	ChangePos int     `json:"changepos"`
+}
+
+// GetAddressInfoResult models the data returned by the getaddressinfo
+// command.
+//
+// Script is the class of the script paid to, or of the redeem script of a
+// pay-to-script-hash address known to the wallet, in which case Hex is the
+// redeem script and PubKeys and SigsRequired describe a multisig script.
+// HDKeyPath and HDMasterFP are the derivation path of the key and the
+// fingerprint of its master key, recorded for imported keys when their origin
+// was given.  Used is set once the address has received a payment.
+type GetAddressInfoResult struct {
+	Address        string   `json:"address"`
+	ScriptPubKey   string   `json:"scriptPubKey"`
+	IsMine         bool     `json:"ismine"`
+	IsWatchOnly    bool     `json:"iswatchonly"`
+	Solvable       bool     `json:"solvable"`
+	IsScript       bool     `json:"isscript"`
+	IsChange       bool     `json:"ischange"`
+	IsWitness      bool     `json:"iswitness"`
+	WitnessVersion *int32   `json:"witness_version,omitempty"`
+	WitnessProgram string   `json:"witness_program,omitempty"`
+	Script         string   `json:"script,omitempty"`
+	Hex            string   `json:"hex,omitempty"`
+	PubKeys        []string `json:"pubkeys,omitempty"`
+	SigsRequired   int32    `json:"sigsrequired,omitempty"`
+	PubKey         string   `json:"pubkey,omitempty"`
+	IsCompressed   bool     `json:"iscompressed,omitempty"`
+	Account        string   `json:"account,omitempty"`
+	Labels         []string `json:"labels"`
+	HDKeyPath      string   `json:"hdkeypath,omitempty"`
+	HDMasterFP     string   `json:"hdmasterfingerprint,omitempty"`
+	Timestamp      int64    `json:"timestamp,omitempty"`
+	Used           bool     `json:"used"`
+}
+
+// KeyOriginResult models an entry of the data returned by the listkeyorigins
+// command.  KeyOrigin is empty for imported keys whose origin is unknown.
+type KeyOriginResult struct {
+	Address   string `json:"address"`
+	PubKey    string `json:"pubkey"`
+	Account   string `json:"account"`
+	KeyOrigin string `json:"keyorigin,omitempty"`
+	Imported  bool   `json:"imported"`
+}
+
+// ImportCoreWalletResult models the data returned by the importcorewallet
+// command.  Skipped describes each entry of the export which was not
+// imported, such as unsupported descriptors.
+type ImportCoreWalletResult struct {
+	Keys        int      `json:"keys"`
+	Scripts     int      `json:"scripts"`
+	Descriptors int      `json:"descriptors"`
+	Labels      int      `json:"labels"`
+	Skipped     []string `json:"skipped,omitempty"`
+}
+
+// ImportTxMemosResult models the data returned by the importtxmemos command.
+// Skipped counts the memos of transactions which already had one.
+type ImportTxMemosResult struct {
+	Imported int `json:"imported"`
+	Skipped  int `json:"skipped"`
+}
+
+// PendingSendResult models a send held by the signer policy, returned by the
+// listpendingsends and approvependingsend commands.  NotBefore is the time its
+// delay ends, zero when it has none, and Rules are the names of the rules
+// holding it.  TxID is set once the send was signed and broadcast.
+type PendingSendResult struct {
+	ID           uint64             `json:"id"`
+	Account      string             `json:"account"`
+	Amount       float64            `json:"amount"`
+	Outputs      map[string]float64 `json:"outputs"`
+	Created      int64              `json:"created"`
+	NotBefore    int64              `json:"notbefore,omitempty"`
+	SecondFactor bool               `json:"secondfactor"`
+	Approved     bool               `json:"approved"`
+	Rules        []string           `json:"rules"`
+	TxID         string             `json:"txid,omitempty"`
+}
+
+// ScheduledSendResult models a send scheduled by schedulesend, returned by the
+// schedulesend and listscheduled commands.  At is the block height or Unix
+// time of the next payment and Remaining the number of payments left, 0 when
+// the send repeats until cancelled.  Hex is the signed transaction of the next
+// payment of a send in locktime mode, once signed.
+type ScheduledSendResult struct {
+	ID        uint64             `json:"id"`
+	Account   string             `json:"account"`
+	Outputs   map[string]float64 `json:"outputs"`
+	Mode      string             `json:"mode"`
+	At        int64              `json:"at"`
+	Every     int64              `json:"every,omitempty"`
+	Remaining int                `json:"remaining"`
+	Created   int64              `json:"created"`
+	Sent      int                `json:"sent"`
+	Hex       string             `json:"hex,omitempty"`
+	LastTxID  string             `json:"lasttxid,omitempty"`
+	LastError string             `json:"lasterror,omitempty"`
+}
+
+// PaymentTemplateResult models a payment template and the state of its current
+// run, returned by the listpaymenttemplates command.  Scheduled is the time of
+// the current run, NextAttempt the time it is next attempted and Attempts the
+// number of times it failed.
+type PaymentTemplateResult struct {
+	PaymentTemplate
+	Scheduled   int64  `json:"scheduled"`
+	NextAttempt int64  `json:"nextattempt"`
+	Attempts    int    `json:"attempts"`
+	LastError   string `json:"lasterror,omitempty"`
+}
+
+// PaymentRunResult models a completed run of a payment template, returned by
+// the getpaymentreport command.  TxID and Outputs, the amounts paid by
+// address, are only set when the run made its payments, and Error when it was
+// given up.
+type PaymentRunResult struct {
+	Template  string             `json:"template"`
+	Scheduled int64              `json:"scheduled"`
+	Time      int64              `json:"time"`
+	Attempts  int                `json:"attempts"`
+	TxID      string             `json:"txid,omitempty"`
+	Outputs   map[string]float64 `json:"outputs,omitempty"`
+	Fee       float64            `json:"fee,omitempty"`
+	Error     string             `json:"error,omitempty"`
+}
+
+// RecoveryInfoResult models the data returned by the getrecoveryinfo command.
+// Lookahead is the number of addresses currently derived past the last used
+// one in each branch, which grows past GapLimit as used addresses are found.
+type RecoveryInfoResult struct {
+	Active           bool    `json:"active"`
+	GapLimit         int32   `json:"gaplimit"`
+	Lookahead        int32   `json:"lookahead"`
+	StartHeight      int32   `json:"startheight"`
+	ScannedHeight    int32   `json:"scannedheight"`
+	TargetHeight     int32   `json:"targetheight"`
+	Progress         float64 `json:"progress"`
+	AddressesDerived int64   `json:"addressesderived"`
+	AddressesFound   int64   `json:"addressesfound"`
+}
+
+// SignerPolicyResult models the data returned by the getsignerpolicy command.
+// SecondFactor reports whether a second factor is configured.
+type SignerPolicyResult struct {
+	Rules        []SignerPolicyRule `json:"rules"`
+	SecondFactor bool               `json:"secondfactor"`
+}
+
+// TimeLockAddressResult models the data returned by the createtimelockaddress
+// command.
+type TimeLockAddressResult struct {
+	Address      string `json:"address"`
+	RedeemScript string `json:"redeemscript"`
+	LockTime     int64  `json:"locktime"`
+	Relative     bool   `json:"relative"`
+}
+
+// VerifyWalletBackupResult models the data returned by the verifywalletbackup
+// command.  Time is the unix time the backup was taken and Error describes why
+// it could not be decrypted when Valid is false.
+type VerifyWalletBackupResult struct {
+	Path  string `json:"path"`
+	Time  int64  `json:"time"`
+	Valid bool   `json:"valid"`
+	Error string `json:"error,omitempty"`
+}
+
+// WalletLockStateResult models the data returned by the getwalletlockstate
+// command.  UnlockedUntil is the unix time at which the wallet will lock
+// itself again and is zero while the wallet is locked.
+type WalletLockStateResult struct {
+	Locked        bool   `json:"locked"`
+	Scope         string `json:"scope,omitempty"`
+	UnlockedUntil int64  `json:"unlockeduntil,omitempty"`
+	PubEncrypted  bool   `json:"pubencrypted"`
 }
 
 // ListUnspentResult models a successful response from the listunspent request.
+//
+// Reused is set for dirty outputs, which pay to an address that has received
+// more than once or received after already being spent from.  When the
+// avoid_reuse wallet flag is set these are not mixed with fresh outputs during
+// coin selection.
+//
+// UnlockAt is set for time locked outputs and is the block height, or the unix
+// time from txscript.LockTimeThreshold on, from which the output may be spent.
+// Such outputs are not spendable before then.
 type ListUnspentResult struct {
 	TxID          string  `json:"txid"`
 	Vout          uint32  `json:"vout"`
@@ -118,6 +386,60 @@ type ListUnspentResult struct {
 	Amount        float64 `json:"amount"`
 	Confirmations int64   `json:"confirmations"`
 	Spendable     bool    `json:"spendable"`
+	Reused        bool    `json:"reused,omitempty"`
+	UnlockAt      int64   `json:"unlockat,omitempty"`
+}
+
+// SendManyBatchInput describes an output spent by a sendmanybatch transaction.
+type SendManyBatchInput struct {
+	TxID   string  `json:"txid"`
+	Vout   uint32  `json:"vout"`
+	Amount float64 `json:"amount"`
+}
+
+// SendManyBatchOutput describes a payment made by a sendmanybatch transaction.
+// Amount is what the output pays after any share of the fee was subtracted
+// from it and Merged is the number of requested payments it combines.
+type SendManyBatchOutput struct {
+	Address string   `json:"address"`
+	Amount  float64  `json:"amount"`
+	Vout    uint32   `json:"vout"`
+	Merged  int      `json:"merged"`
+	Labels  []string `json:"labels,omitempty"`
+}
+
+// SendManyBatchResult models the data returned by the sendmanybatch command.
+// ChangePos is the index of the change output, or -1 when no change output
+// was added.
+type SendManyBatchResult struct {
+	TxID         string                `json:"txid"`
+	Size         int32                 `json:"size"`
+	Fee          float64               `json:"fee"`
+	FeeRate      float64               `json:"feerate"`
+	Inputs       []SendManyBatchInput  `json:"inputs"`
+	Outputs      []SendManyBatchOutput `json:"outputs"`
+	ChangeAmount float64               `json:"changeamount"`
+	ChangeAddr   string                `json:"changeaddress,omitempty"`
+	ChangePos    int                   `json:"changepos"`
+}
+
+// SendPayjoinResult models the data returned by the sendpayjoin command.
+// Payjoin is false when the original transaction was broadcast, in which case
+// Error tells why the payjoin failed, if the URI had a payjoin endpoint.
+// FeeContribution is the part of the fee paid for the inputs of the receiver.
+type SendPayjoinResult struct {
+	TxID            string  `json:"txid"`
+	Payjoin         bool    `json:"payjoin"`
+	Fee             float64 `json:"fee"`
+	FeeContribution float64 `json:"feecontribution"`
+	Error           string  `json:"error,omitempty"`
+}
+
+// SetWalletFlagResult models the data returned by the setwalletflag command.
+type SetWalletFlagResult struct {
+	FlagName  string `json:"flag_name"`
+	FlagState bool   `json:"flag_state"`
+	Warnings  string `json:"warnings,omitempty"`
 }
 
 // SignRawTransactionError models the data that contains script verification
@@ -138,6 +460,15 @@ type SignRawTransactionResult struct {
 	Errors   []SignRawTransactionError `json:"errors,omitempty"`
 }
 
+// SigningPackageResult models the data returned by the createsigningpackage
+// and signsigningpackage commands.  Complete is set once every input of the
+// package has been signed.
+type SigningPackageResult struct {
+	Format   string `json:"format"`
+	Package  string `json:"package"`
+	Complete bool   `json:"complete"`
+}
+
 // ValidateAddressWalletResult models the data returned by the wallet server
 // validateaddress command.
 type ValidateAddressWalletResult struct {
@@ -89,6 +89,21 @@ func NewListAllTransactionsCmd(account *string) *ListAllTransactionsCmd {
 	}
 }
 
+// NotifyConfirmationsCmd defines the notifyconfirmations JSON-RPC command.
+// Once registered, a txconfirmed notification is sent each time a wallet
+// transaction reaches one of the passed numbers of confirmations.
+type NotifyConfirmationsCmd struct {
+	Thresholds []int32 `jsonrpcusage:"[n,...]"`
+}
+
+// NewNotifyConfirmationsCmd returns a new instance which can be used to issue
+// a notifyconfirmations JSON-RPC command.
+func NewNotifyConfirmationsCmd(thresholds []int32) *NotifyConfirmationsCmd {
+	return &NotifyConfirmationsCmd{
+		Thresholds: thresholds,
+	}
+}
+
 // RecoverAddressesCmd defines the recoveraddresses JSON-RPC command.
 type RecoverAddressesCmd struct {
 	Account string
@@ -123,6 +138,7 @@ func init() {
 	MustRegisterCmd("getunconfirmedbalance", (*GetUnconfirmedBalanceCmd)(nil), flags)
 	MustRegisterCmd("listaddresstransactions", (*ListAddressTransactionsCmd)(nil), flags)
 	MustRegisterCmd("listalltransactions", (*ListAllTransactionsCmd)(nil), flags)
+	MustRegisterCmd("notifyconfirmations", (*NotifyConfirmationsCmd)(nil), flags)
 	MustRegisterCmd("recoveraddresses", (*RecoverAddressesCmd)(nil), flags)
 	MustRegisterCmd("walletislocked", (*WalletIsLockedCmd)(nil), flags)
 }
@@ -178,6 +178,19 @@ func TestWalletSvrWsCmds(t *testing.T) {
 				N:       10,
 			},
 		},
+		{
+			name: "notifyconfirmations",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("notifyconfirmations", `[1,6]`)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewNotifyConfirmationsCmd([]int32{1, 6})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"notifyconfirmations","params":[[1,6]],"id":1}`,
+			unmarshalled: &btcjson.NotifyConfirmationsCmd{
+				Thresholds: []int32{1, 6},
+			},
+		},
 		{
 			name: "walletislocked",
 			newCmd: func() (interface{}, error) {
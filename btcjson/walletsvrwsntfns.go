@@ -23,6 +23,19 @@ const (
 	// NewTxNtfnMethod is the method used to notify that a wallet server has
 	// added a new transaction to the transaction store.
 	NewTxNtfnMethod = "newtx"
+
+	// TxConfirmedNtfnMethod is the method used to notify that a wallet
+	// transaction reached one of the numbers of confirmations registered
+	// with notifyconfirmations.
+	TxConfirmedNtfnMethod = "txconfirmed"
+
+	// WalletRescanProgressNtfnMethod is the method used to notify the
+	// progress of a wallet rescan or recovery.
+	WalletRescanProgressNtfnMethod = "walletrescanprogress"
+
+	// FeeBumpNtfnMethod is the method used to notify that a wallet
+	// transaction was replaced by one paying a higher fee.
+	FeeBumpNtfnMethod = "feebump"
 )
 
 // AccountBalanceNtfn defines the accountbalance JSON-RPC notification.
@@ -83,6 +96,56 @@ func NewNewTxNtfn(account string, details ListTransactionsResult) *NewTxNtfn {
 	}
 }
 
+// TxConfirmedNtfn defines the txconfirmed JSON-RPC notification.
+type TxConfirmedNtfn struct {
+	TxID          string
+	BlockHash     string
+	Confirmations int32
+}
+
+// NewTxConfirmedNtfn returns a new instance which can be used to issue a
+// txconfirmed JSON-RPC notification.
+func NewTxConfirmedNtfn(txID, blockHash string, confirmations int32) *TxConfirmedNtfn {
+	return &TxConfirmedNtfn{
+		TxID:          txID,
+		BlockHash:     blockHash,
+		Confirmations: confirmations,
+	}
+}
+
+// WalletRescanProgressNtfn defines the walletrescanprogress JSON-RPC
+// notification.
+type WalletRescanProgressNtfn struct {
+	Height       int32
+	TargetHeight int32
+}
+
+// NewWalletRescanProgressNtfn returns a new instance which can be used to
+// issue a walletrescanprogress JSON-RPC notification.
+func NewWalletRescanProgressNtfn(height, targetHeight int32) *WalletRescanProgressNtfn {
+	return &WalletRescanProgressNtfn{
+		Height:       height,
+		TargetHeight: targetHeight,
+	}
+}
+
+// FeeBumpNtfn defines the feebump JSON-RPC notification.
+type FeeBumpNtfn struct {
+	OrigTxID string
+	TxID     string
+	Fee      float64 // In BTC
+}
+
+// NewFeeBumpNtfn returns a new instance which can be used to issue a feebump
+// JSON-RPC notification.
+func NewFeeBumpNtfn(origTxID, txID string, fee float64) *FeeBumpNtfn {
+	return &FeeBumpNtfn{
+		OrigTxID: origTxID,
+		TxID:     txID,
+		Fee:      fee,
+	}
+}
+
 func init() {
 	// The commands in this file are only usable with a wallet server via
 	// websockets and are notifications.
@@ -92,4 +155,7 @@ func init() {
 	MustRegisterCmd(BtcdConnectedNtfnMethod, (*BtcdConnectedNtfn)(nil), flags)
 	MustRegisterCmd(WalletLockStateNtfnMethod, (*WalletLockStateNtfn)(nil), flags)
 	MustRegisterCmd(NewTxNtfnMethod, (*NewTxNtfn)(nil), flags)
+	MustRegisterCmd(TxConfirmedNtfnMethod, (*TxConfirmedNtfn)(nil), flags)
+	MustRegisterCmd(WalletRescanProgressNtfnMethod, (*WalletRescanProgressNtfn)(nil), flags)
+	MustRegisterCmd(FeeBumpNtfnMethod, (*FeeBumpNtfn)(nil), flags)
 }
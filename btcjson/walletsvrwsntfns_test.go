@@ -116,6 +116,50 @@ func TestWalletSvrWsNtfns(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "txconfirmed",
+			newNtfn: func() (interface{}, error) {
+				return btcjson.NewCmd("txconfirmed", "123", "456", 6)
+			},
+			staticNtfn: func() interface{} {
+				return btcjson.NewTxConfirmedNtfn("123", "456", 6)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"txconfirmed","params":["123","456",6],"id":null}`,
+			unmarshalled: &btcjson.TxConfirmedNtfn{
+				TxID:          "123",
+				BlockHash:     "456",
+				Confirmations: 6,
+			},
+		},
+		{
+			name: "walletrescanprogress",
+			newNtfn: func() (interface{}, error) {
+				return btcjson.NewCmd("walletrescanprogress", 100, 200)
+			},
+			staticNtfn: func() interface{} {
+				return btcjson.NewWalletRescanProgressNtfn(100, 200)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"walletrescanprogress","params":[100,200],"id":null}`,
+			unmarshalled: &btcjson.WalletRescanProgressNtfn{
+				Height:       100,
+				TargetHeight: 200,
+			},
+		},
+		{
+			name: "feebump",
+			newNtfn: func() (interface{}, error) {
+				return btcjson.NewCmd("feebump", "123", "456", 0.0002)
+			},
+			staticNtfn: func() interface{} {
+				return btcjson.NewFeeBumpNtfn("123", "456", 0.0002)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"feebump","params":["123","456",0.0002],"id":null}`,
+			unmarshalled: &btcjson.FeeBumpNtfn{
+				OrigTxID: "123",
+				TxID:     "456",
+				Fee:      0.0002,
+			},
+		},
 	}
 
 	t.Logf("Running %d tests", len(tests))
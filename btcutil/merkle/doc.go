@@ -0,0 +1,23 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package merkle creates and verifies compact proofs that transactions are
+included in a block.
+
+A proof is a merkle block as defined by BIP 37: the block header, the number
+of transactions in the block and a partial merkle tree holding just enough
+hashes to recompute the merkle root committed to by the header from the hashes
+of the proven transactions.  Its size grows with the logarithm of the number
+of transactions in the block rather than with the block itself.
+
+Proofs are serialized in the same format as the merkleblock wire message,
+which is also the format of the gettxoutproof and verifytxoutproof RPCs, so
+they can be exchanged with other implementations.
+
+Verifying a proof only ensures the proven transactions are committed to by the
+header.  The caller remains responsible for checking the header belongs to the
+best chain, for instance by asking a trusted node or following the headers.
+*/
+package merkle
@@ -0,0 +1,160 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package merkle
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/spv"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+var (
+	// ErrNoTransactions is returned when a proof is requested without any
+	// transaction to prove.
+	ErrNoTransactions = errors.New("no transactions to prove")
+
+	// ErrBadProof is returned when a proof is malformed, so the partial
+	// merkle tree can not be traversed.
+	ErrBadProof = spv.ErrBadProof
+
+	// ErrMerkleRootMismatch is returned when the merkle root computed from
+	// a proof differs from the one committed to by its header.
+	ErrMerkleRootMismatch = spv.ErrMerkleRootMismatch
+)
+
+// partialTree is used to house the intermediate state of building a partial
+// merkle tree.
+type partialTree struct {
+	numTx   uint32
+	leaves  []*chainhash.Hash
+	matched []bool
+	hashes  []*chainhash.Hash
+	bits    []bool
+}
+
+// calcHash returns the hash of the node at the passed height and position.
+func (t *partialTree) calcHash(height uint, pos uint32) *chainhash.Hash {
+	if height == 0 {
+		return t.leaves[pos]
+	}
+
+	left := t.calcHash(height-1, pos*2)
+	right := left
+	if pos*2+1 < spv.TreeWidth(t.numTx, height-1) {
+		right = t.calcHash(height-1, pos*2+1)
+	}
+	return spv.HashMerkleBranches(left, right)
+}
+
+// build traverses the tree depth-first from the node at the passed height and
+// position.  It records for every node visited whether it is the parent of a
+// matched transaction, and the hashes of the nodes whose subtree is not
+// descended into along with the matched transactions.
+func (t *partialTree) build(height uint, pos uint32) {
+	var isParent bool
+	for i := pos << height; i < (pos+1)<<height && i < t.numTx; i++ {
+		isParent = isParent || t.matched[i]
+	}
+	t.bits = append(t.bits, isParent)
+
+	if height == 0 || !isParent {
+		t.hashes = append(t.hashes, t.calcHash(height, pos))
+		return
+	}
+	t.build(height-1, pos*2)
+	if pos*2+1 < spv.TreeWidth(t.numTx, height-1) {
+		t.build(height-1, pos*2+1)
+	}
+}
+
+// NewMerkleBlock returns a proof that the transactions with the passed hashes
+// are included in the block.  An error is returned when any of them is not in
+// the block.
+func NewMerkleBlock(block *btcutil.Block, txHashes []*chainhash.Hash) (*wire.MsgMerkleBlock, error) {
+	if len(txHashes) == 0 {
+		return nil, ErrNoTransactions
+	}
+	wanted := make(map[chainhash.Hash]struct{}, len(txHashes))
+	for _, hash := range txHashes {
+		wanted[*hash] = struct{}{}
+	}
+
+	txns := block.Transactions()
+	t := partialTree{
+		numTx:   uint32(len(txns)),
+		leaves:  make([]*chainhash.Hash, len(txns)),
+		matched: make([]bool, len(txns)),
+	}
+	for i, tx := range txns {
+		t.leaves[i] = tx.Hash()
+		if _, ok := wanted[*tx.Hash()]; ok {
+			t.matched[i] = true
+			delete(wanted, *tx.Hash())
+		}
+	}
+	for hash := range wanted {
+		return nil, fmt.Errorf("transaction %v is not in block %v",
+			hash, block.Hash())
+	}
+	t.build(spv.TreeHeight(t.numTx), 0)
+
+	msg := &wire.MsgMerkleBlock{
+		Header:       block.MsgBlock().Header,
+		Transactions: t.numTx,
+		Hashes:       make([]*chainhash.Hash, 0, len(t.hashes)),
+		Flags:        make([]byte, (len(t.bits)+7)/8),
+	}
+	for _, hash := range t.hashes {
+		if err := msg.AddTxHash(hash); err != nil {
+			return nil, err
+		}
+	}
+	for i, bit := range t.bits {
+		if bit {
+			msg.Flags[i/8] |= 1 << uint(i%8)
+		}
+	}
+	return msg, nil
+}
+
+// ExtractMatches verifies the passed proof against the merkle root of its
+// header and returns the hashes of the proven transactions along with their
+// indices in the block.  ErrBadProof is returned when the proof is malformed
+// and ErrMerkleRootMismatch when it does not match its header.
+func ExtractMatches(msg *wire.MsgMerkleBlock) ([]*chainhash.Hash, []uint32, error) {
+	return spv.ExtractMatches(msg)
+}
+
+// Serialize returns the passed proof serialized in the format of the
+// merkleblock wire message.
+func Serialize(msg *wire.MsgMerkleBlock) ([]byte, error) {
+	var buf bytes.Buffer
+	err := msg.BtcEncode(&buf, wire.ProtocolVersion, wire.BaseEncoding)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Deserialize parses a proof serialized in the format of the merkleblock wire
+// message.  The proof is not verified, which is done by ExtractMatches.
+func Deserialize(serialized []byte) (*wire.MsgMerkleBlock, error) {
+	var msg wire.MsgMerkleBlock
+	r := bytes.NewReader(serialized)
+	err := msg.BtcDecode(r, wire.ProtocolVersion, wire.BaseEncoding)
+	if err != nil {
+		return nil, err
+	}
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("%d trailing bytes after the merkle proof",
+			r.Len())
+	}
+	return &msg, nil
+}
@@ -0,0 +1,208 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package merkle
+
+import (
+	"testing"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// testBlock returns a block holding numTx distinct transactions with a valid
+// merkle root.
+func testBlock(numTx int) *btcutil.Block {
+	var msgBlock wire.MsgBlock
+	for i := 0; i < numTx; i++ {
+		tx := wire.NewMsgTx(wire.TxVersion)
+		tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Index: uint32(i)}, nil, nil))
+		tx.AddTxOut(wire.NewTxOut(int64(i), nil))
+		msgBlock.AddTransaction(tx)
+	}
+	block := btcutil.NewBlock(&msgBlock)
+	merkles := blockchain.BuildMerkleTreeStore(block.Transactions(), false)
+	msgBlock.Header.MerkleRoot = *merkles[len(merkles)-1]
+	return block
+}
+
+// TestMerkleBlock ensures proofs of any set of transactions of blocks of
+// various sizes survive serialization and yield the proven transactions.
+func TestMerkleBlock(t *testing.T) {
+	for _, numTx := range []int{1, 2, 3, 4, 5, 7, 16, 17, 33} {
+		block := testBlock(numTx)
+		txns := block.Transactions()
+
+		// Prove every single transaction, the first and last ones
+		// together, and all of them.
+		sets := [][]int{{0, numTx - 1}}
+		all := make([]int, numTx)
+		for i := range txns {
+			sets = append(sets, []int{i})
+			all[i] = i
+		}
+		sets = append(sets, all)
+
+		for _, set := range sets {
+			var hashes []*chainhash.Hash
+			seen := make(map[int]bool)
+			for _, i := range set {
+				if !seen[i] {
+					seen[i] = true
+					hashes = append(hashes, txns[i].Hash())
+				}
+			}
+			msg, err := NewMerkleBlock(block, hashes)
+			if err != nil {
+				t.Fatalf("%d txns %v: NewMerkleBlock: %v", numTx,
+					set, err)
+			}
+			serialized, err := Serialize(msg)
+			if err != nil {
+				t.Fatalf("%d txns %v: Serialize: %v", numTx, set,
+					err)
+			}
+			msg, err = Deserialize(serialized)
+			if err != nil {
+				t.Fatalf("%d txns %v: Deserialize: %v", numTx,
+					set, err)
+			}
+			matches, indices, err := ExtractMatches(msg)
+			if err != nil {
+				t.Fatalf("%d txns %v: ExtractMatches: %v", numTx,
+					set, err)
+			}
+
+			// Matches are returned in block order.
+			var want []int
+			for i := range txns {
+				if seen[i] {
+					want = append(want, i)
+				}
+			}
+			if len(matches) != len(want) {
+				t.Fatalf("%d txns %v: got %d matches, want %d",
+					numTx, set, len(matches), len(want))
+			}
+			for j, i := range want {
+				if !matches[j].IsEqual(txns[i].Hash()) ||
+					indices[j] != uint32(i) {
+
+					t.Fatalf("%d txns %v: match %d is %v at "+
+						"%d, want %v at %d", numTx, set, j,
+						matches[j], indices[j],
+						txns[i].Hash(), i)
+				}
+			}
+		}
+	}
+}
+
+// TestMerkleBlockErrors ensures transactions missing from the block can not
+// be proven and that tampered proofs are rejected.
+func TestMerkleBlockErrors(t *testing.T) {
+	block := testBlock(7)
+	if _, err := NewMerkleBlock(block, nil); err != ErrNoTransactions {
+		t.Fatalf("empty proof: unexpected error %v", err)
+	}
+	other := testBlock(8).Transactions()[7].Hash()
+	if _, err := NewMerkleBlock(block, []*chainhash.Hash{other}); err == nil {
+		t.Fatal("proof of a transaction missing from the block created")
+	}
+
+	newProof := func() *wire.MsgMerkleBlock {
+		msg, err := NewMerkleBlock(block,
+			[]*chainhash.Hash{block.Transactions()[2].Hash()})
+		if err != nil {
+			t.Fatalf("NewMerkleBlock: %v", err)
+		}
+		return msg
+	}
+	tests := []struct {
+		name   string
+		tamper func(msg *wire.MsgMerkleBlock)
+		err    error
+	}{
+		{
+			name: "changed hash",
+			tamper: func(msg *wire.MsgMerkleBlock) {
+				msg.Hashes[0] = other
+			},
+			err: ErrMerkleRootMismatch,
+		},
+		{
+			name: "changed header",
+			tamper: func(msg *wire.MsgMerkleBlock) {
+				msg.Header.MerkleRoot = *other
+			},
+			err: ErrMerkleRootMismatch,
+		},
+		{
+			name: "extra hash",
+			tamper: func(msg *wire.MsgMerkleBlock) {
+				msg.Hashes = append(msg.Hashes, other)
+			},
+			err: ErrBadProof,
+		},
+		{
+			name: "missing hash",
+			tamper: func(msg *wire.MsgMerkleBlock) {
+				msg.Hashes = msg.Hashes[:len(msg.Hashes)-1]
+			},
+			err: ErrBadProof,
+		},
+		{
+			name: "extra flags",
+			tamper: func(msg *wire.MsgMerkleBlock) {
+				msg.Flags = append(msg.Flags, 0)
+			},
+			err: ErrBadProof,
+		},
+		{
+			name: "no flags",
+			tamper: func(msg *wire.MsgMerkleBlock) {
+				msg.Flags = nil
+			},
+			err: ErrBadProof,
+		},
+		{
+			name: "no transactions",
+			tamper: func(msg *wire.MsgMerkleBlock) {
+				msg.Transactions = 0
+			},
+			err: ErrBadProof,
+		},
+	}
+	for _, test := range tests {
+		msg := newProof()
+		test.tamper(msg)
+		if _, _, err := ExtractMatches(msg); err != test.err {
+			t.Errorf("%s: got error %v, want %v", test.name, err,
+				test.err)
+		}
+	}
+
+	// A duplicated last transaction must not be accepted in place of the
+	// real one (CVE-2012-2459).
+	block = testBlock(3)
+	txns := block.Transactions()
+	dup := wire.MsgMerkleBlock{
+		Header:       block.MsgBlock().Header,
+		Transactions: 4,
+		Hashes: []*chainhash.Hash{
+			blockchain.HashMerkleBranches(txns[0].Hash(), txns[1].Hash()),
+			txns[2].Hash(), txns[2].Hash(),
+		},
+		Flags: []byte{0x1d},
+	}
+	if _, _, err := ExtractMatches(&dup); err != ErrBadProof {
+		t.Fatalf("duplicated transaction: unexpected error %v", err)
+	}
+
+	if _, err := Deserialize(append(make([]byte, 0), 0x01)); err == nil {
+		t.Fatal("truncated proof deserialized")
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	"github.com/pkt-cash/pktd/btcjson"
+)
+
+// appCommit and appBuildFlags are defined as variables so they can be set
+// during the build process with
+// '-ldflags "-X main.appCommit=<commit> -X main.appBuildFlags=<flags>"', as
+// release/build.sh does.  appBuildFlags MUST NOT contain spaces.
+var (
+	appCommit     string
+	appBuildFlags string
+)
+
+// consensusModules are the modules outside of pktd whose code takes part in
+// the consensus rules: the hashes of scripts and PacketCrypt proofs, the
+// PacketCrypt announcement encryption and the block database.
+var consensusModules = []string{
+	"github.com/aead/chacha20",
+	"github.com/btcsuite/golangcrypto",
+	"github.com/btcsuite/goleveldb",
+	"github.com/pkt-cash/btcutil",
+	"golang.org/x/crypto",
+}
+
+// moduleInfo is the version and the go.sum hash of a module the binary was
+// built with.  The hash is empty for a module replaced with a local directory.
+type moduleInfo struct {
+	path     string
+	version  string
+	sum      string
+	replaced bool
+}
+
+// buildInfo describes how the binary was built.
+type buildInfo struct {
+	commit    string
+	goVersion string
+	flags     string
+
+	// modules are the consensus modules linked into the binary, in the
+	// order of consensusModules.
+	modules []moduleInfo
+
+	// available is whether the module information of the binary could be
+	// read.  It is not for binaries built outside of module mode.
+	available bool
+}
+
+// readBuildInfo returns the build information of the running binary.
+func readBuildInfo() *buildInfo {
+	b := &buildInfo{
+		commit:    appCommit,
+		goVersion: runtime.Version(),
+		flags:     appBuildFlags,
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return b
+	}
+	b.available = true
+
+	deps := make(map[string]*debug.Module, len(info.Deps))
+	for _, dep := range info.Deps {
+		deps[dep.Path] = dep
+	}
+	for _, path := range consensusModules {
+		dep, ok := deps[path]
+		if !ok {
+			continue
+		}
+		m := moduleInfo{path: path, version: dep.Version, sum: dep.Sum}
+		if dep.Replace != nil {
+			m.version = dep.Replace.Version
+			m.sum = dep.Replace.Sum
+			m.replaced = true
+		}
+		b.modules = append(b.modules, m)
+	}
+	return b
+}
+
+// buildManifest maps the paths of modules to the version and hash they are
+// known to be good at.
+type buildManifest map[string]moduleInfo
+
+// readBuildManifest reads a manifest in the go.sum format, which the go.sum
+// of a release is.  The hashes of go.mod files are ignored.
+func readBuildManifest(r io.Reader) (buildManifest, error) {
+	manifest := make(buildManifest)
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("malformed line %d of the build "+
+				"manifest", line)
+		}
+		if strings.HasSuffix(fields[1], "/go.mod") {
+			continue
+		}
+		manifest[fields[0]] = moduleInfo{
+			path:    fields[0],
+			version: fields[1],
+			sum:     fields[2],
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// verify returns an error unless every consensus module the binary was built
+// with has the version and the hash of the manifest.
+func (b *buildInfo) verify(manifest buildManifest) error {
+	if !b.available {
+		return fmt.Errorf("the binary has no module information to " +
+			"verify")
+	}
+	for _, m := range b.modules {
+		want, ok := manifest[m.path]
+		switch {
+		case !ok:
+			return fmt.Errorf("consensus module %s is not in the "+
+				"build manifest", m.path)
+		case m.sum == "":
+			return fmt.Errorf("consensus module %s was replaced "+
+				"with a local directory", m.path)
+		case m.version != want.version || m.sum != want.sum:
+			return fmt.Errorf("consensus module %s is %s %s, the "+
+				"build manifest expects %s %s", m.path, m.version,
+				m.sum, want.version, want.sum)
+		}
+	}
+	return nil
+}
+
+// verifyBuildManifest checks the build of the binary against the manifest in
+// the passed file.
+func verifyBuildManifest(b *buildInfo, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	manifest, err := readBuildManifest(f)
+	if err != nil {
+		return err
+	}
+	return b.verify(manifest)
+}
+
+// result returns the build information as reported by getinfo and
+// getnetworkinfo.
+func (b *buildInfo) result(verified bool) *btcjson.BuildInfoResult {
+	modules := make([]btcjson.BuildModuleResult, 0, len(b.modules))
+	for _, m := range b.modules {
+		modules = append(modules, btcjson.BuildModuleResult{
+			Path:     m.path,
+			Version:  m.version,
+			Sum:      m.sum,
+			Replaced: m.replaced,
+		})
+	}
+	return &btcjson.BuildInfoResult{
+		Commit:           b.commit,
+		GoVersion:        b.goVersion,
+		Flags:            b.flags,
+		ConsensusModules: modules,
+		ManifestVerified: verified,
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestBuildManifest ensures build manifests are read from the go.sum format
+// and the consensus modules of a build are verified against them.
+func TestBuildManifest(t *testing.T) {
+	const goSum = `
+github.com/pkt-cash/btcutil v1.0.0 h1:btcutil=
+github.com/pkt-cash/btcutil v1.0.0/go.mod h1:btcutilmod=
+golang.org/x/crypto v0.1.0 h1:crypto=
+`
+	manifest, err := readBuildManifest(strings.NewReader(goSum))
+	if err != nil {
+		t.Fatalf("readBuildManifest: %v", err)
+	}
+	if len(manifest) != 2 ||
+		manifest["github.com/pkt-cash/btcutil"].sum != "h1:btcutil=" {
+
+		t.Fatalf("unexpected manifest %+v", manifest)
+	}
+	if _, err := readBuildManifest(strings.NewReader("a b\n")); err == nil {
+		t.Fatal("readBuildManifest: unexpected success")
+	}
+
+	btcutil := moduleInfo{
+		path:    "github.com/pkt-cash/btcutil",
+		version: "v1.0.0",
+		sum:     "h1:btcutil=",
+	}
+	tests := []struct {
+		name    string
+		build   buildInfo
+		wantErr bool
+	}{
+		{
+			name:  "matching",
+			build: buildInfo{available: true, modules: []moduleInfo{btcutil}},
+		},
+		{
+			name:    "no module information",
+			build:   buildInfo{},
+			wantErr: true,
+		},
+		{
+			name: "other hash",
+			build: buildInfo{available: true, modules: []moduleInfo{{
+				path:    btcutil.path,
+				version: btcutil.version,
+				sum:     "h1:other=",
+			}}},
+			wantErr: true,
+		},
+		{
+			name: "other version",
+			build: buildInfo{available: true, modules: []moduleInfo{{
+				path:    btcutil.path,
+				version: "v1.0.1",
+				sum:     btcutil.sum,
+			}}},
+			wantErr: true,
+		},
+		{
+			name: "replaced with a directory",
+			build: buildInfo{available: true, modules: []moduleInfo{{
+				path:     btcutil.path,
+				replaced: true,
+			}}},
+			wantErr: true,
+		},
+		{
+			name: "not in manifest",
+			build: buildInfo{available: true, modules: []moduleInfo{{
+				path:    "github.com/btcsuite/goleveldb",
+				version: "v1.0.0",
+				sum:     "h1:goleveldb=",
+			}}},
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		err := test.build.verify(manifest)
+		if (err != nil) != test.wantErr {
+			t.Errorf("%s: got error %v, want error %v", test.name, err,
+				test.wantErr)
+		}
+	}
+
+	b := &buildInfo{commit: "abc", goVersion: "go1.12",
+		modules: []moduleInfo{btcutil}}
+	result := b.result(true)
+	if result.Commit != "abc" || result.GoVersion != "go1.12" ||
+		!result.ManifestVerified || len(result.ConsensusModules) != 1 ||
+		result.ConsensusModules[0].Sum != btcutil.sum {
+
+		t.Fatalf("unexpected result %+v", result)
+	}
+}
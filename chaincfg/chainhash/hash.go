@@ -33,6 +33,23 @@ func (hash Hash) String() string {
 	return hex.EncodeToString(hash[:])
 }
 
+// AppendTo appends the bytes which represent the hash to b and returns the
+// extended slice.  Unlike CloneBytes, it does not allocate when b has enough
+// capacity.
+func (hash *Hash) AppendTo(b []byte) []byte {
+	return append(b, hash[:]...)
+}
+
+// AppendString appends the hash, as the hexadecimal string of the
+// byte-reversed hash returned by String, to b and returns the extended slice.
+func (hash *Hash) AppendString(b []byte) []byte {
+	const hexDigits = "0123456789abcdef"
+	for i := HashSize - 1; i >= 0; i-- {
+		b = append(b, hexDigits[hash[i]>>4], hexDigits[hash[i]&0x0f])
+	}
+	return b
+}
+
 // CloneBytes returns a copy of the bytes which represent the hash as a byte
 // slice.
 //
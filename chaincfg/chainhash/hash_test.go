@@ -109,6 +109,23 @@ func TestHashString(t *testing.T) {
 	}
 }
 
+// TestHashAppend tests appending hashes and their strings to byte slices.
+func TestHashAppend(t *testing.T) {
+	prefix := []byte("prefix")
+
+	b := mainNetGenesisHash.AppendTo(append([]byte(nil), prefix...))
+	want := append(append([]byte(nil), prefix...), mainNetGenesisHash[:]...)
+	if !bytes.Equal(b, want) {
+		t.Errorf("AppendTo: got %x, want %x", b, want)
+	}
+
+	b = mainNetGenesisHash.AppendString(append([]byte(nil), prefix...))
+	wantStr := string(prefix) + mainNetGenesisHash.String()
+	if string(b) != wantStr {
+		t.Errorf("AppendString: got %s, want %s", b, wantStr)
+	}
+}
+
 // TestNewHashFromStr executes tests against the NewHashFromStr function.
 func TestNewHashFromStr(t *testing.T) {
 	tests := []struct {
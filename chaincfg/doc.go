@@ -58,4 +58,10 @@
 // non-standard network.  As a general rule of thumb, all network parameters
 // should be unique to the network, but parameter collisions can still occur
 // (unfortunately, this is the case with regtest and testnet3 sharing magics).
+//
+// Register and the lookup functions of this package share a default Registry
+// of every network known to the process.  A process which runs isolated stacks
+// for several networks at once may create a Registry for each with NewRegistry,
+// so that the address and extended key magics accepted by one stack are only
+// those of its own networks.
 package chaincfg
@@ -9,7 +9,6 @@ import (
 	"errors"
 	"math"
 	"math/big"
-	"strings"
 	"time"
 
 	"github.com/pkt-cash/pktd/chaincfg/chainhash"
@@ -799,41 +798,22 @@ var (
 	ErrUnknownHDKeyID = errors.New("unknown hd private extended key bytes")
 )
 
-var (
-	registeredNets       = make(map[wire.BitcoinNet]struct{})
-	pubKeyHashAddrIDs    = make(map[byte]struct{})
-	scriptHashAddrIDs    = make(map[byte]struct{})
-	bech32SegwitPrefixes = make(map[string]struct{})
-	hdPrivToPubKeyIDs    = make(map[[4]byte][]byte)
-)
-
 // String returns the hostname of the DNS seed in human-readable form.
 func (d DNSSeed) String() string {
 	return d.Host
 }
 
-// Register registers the network parameters for a Bitcoin network.  This may
-// error with ErrDuplicateNet if the network is already registered (either
-// due to a previous Register call, or the network being one of the default
-// networks).
+// Register registers the network parameters for a Bitcoin network with the
+// default registry.  This may error with ErrDuplicateNet if the network is
+// already registered (either due to a previous Register call, or the network
+// being one of the default networks).
 //
 // Network parameters should be registered into this package by a main package
 // as early as possible.  Then, library packages may lookup networks or network
 // parameters based on inputs and work regardless of the network being standard
 // or not.
 func Register(params *Params) error {
-	if _, ok := registeredNets[params.Net]; ok {
-		return ErrDuplicateNet
-	}
-	registeredNets[params.Net] = struct{}{}
-	pubKeyHashAddrIDs[params.PubKeyHashAddrID] = struct{}{}
-	scriptHashAddrIDs[params.ScriptHashAddrID] = struct{}{}
-	hdPrivToPubKeyIDs[params.HDPrivateKeyID] = params.HDPublicKeyID[:]
-
-	// A valid Bech32 encoded segwit address always has as prefix the
-	// human-readable part for the given net followed by '1'.
-	bech32SegwitPrefixes[params.Bech32HRPSegwit+"1"] = struct{}{}
-	return nil
+	return defaultRegistry.Register(params)
 }
 
 // mustRegister performs the same function as Register except it panics if there
@@ -844,6 +824,13 @@ func mustRegister(params *Params) {
 	}
 }
 
+// ParamsForNet returns the parameters of the default or registered network
+// identified by the passed wire.BitcoinNet, or false when it is not
+// registered.
+func ParamsForNet(net wire.BitcoinNet) (*Params, bool) {
+	return defaultRegistry.ParamsForNet(net)
+}
+
 // IsPubKeyHashAddrID returns whether the id is an identifier known to prefix a
 // pay-to-pubkey-hash address on any default or registered network.  This is
 // used when decoding an address string into a specific address type.  It is up
@@ -851,8 +838,7 @@ func mustRegister(params *Params) {
 // address is a pubkey hash address, script hash address, neither, or
 // undeterminable (if both return true).
 func IsPubKeyHashAddrID(id byte) bool {
-	_, ok := pubKeyHashAddrIDs[id]
-	return ok
+	return defaultRegistry.IsPubKeyHashAddrID(id)
 }
 
 // IsScriptHashAddrID returns whether the id is an identifier known to prefix a
@@ -862,35 +848,21 @@ func IsPubKeyHashAddrID(id byte) bool {
 // address is a pubkey hash address, script hash address, neither, or
 // undeterminable (if both return true).
 func IsScriptHashAddrID(id byte) bool {
-	_, ok := scriptHashAddrIDs[id]
-	return ok
+	return defaultRegistry.IsScriptHashAddrID(id)
 }
 
 // IsBech32SegwitPrefix returns whether the prefix is a known prefix for segwit
 // addresses on any default or registered network.  This is used when decoding
 // an address string into a specific address type.
 func IsBech32SegwitPrefix(prefix string) bool {
-	prefix = strings.ToLower(prefix)
-	_, ok := bech32SegwitPrefixes[prefix]
-	return ok
+	return defaultRegistry.IsBech32SegwitPrefix(prefix)
 }
 
 // HDPrivateKeyToPublicKeyID accepts a private hierarchical deterministic
 // extended key id and returns the associated public key id.  When the provided
 // id is not registered, the ErrUnknownHDKeyID error will be returned.
 func HDPrivateKeyToPublicKeyID(id []byte) ([]byte, error) {
-	if len(id) != 4 {
-		return nil, ErrUnknownHDKeyID
-	}
-
-	var key [4]byte
-	copy(key[:], id)
-	pubBytes, ok := hdPrivToPubKeyIDs[key]
-	if !ok {
-		return nil, ErrUnknownHDKeyID
-	}
-
-	return pubBytes, nil
+	return defaultRegistry.HDPrivateKeyToPublicKeyID(id)
 }
 
 // newHashFromStr converts the passed big-endian hex string into a
@@ -0,0 +1,140 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// Registry holds a set of registered networks along with the encoding magics
+// of their addresses and hierarchical deterministic extended keys.
+//
+// The package level Register and lookup functions use a default registry which
+// all the default networks are registered with.  A process running isolated
+// stacks for several networks at once, such as tests or explorers serving more
+// than one chain, may create a registry per stack with NewRegistry so that
+// each only accepts the magics of its own networks, and so that networks
+// reusing the magic of another network do not collide.
+//
+// A Registry is safe for concurrent access.
+type Registry struct {
+	mtx                  sync.RWMutex
+	nets                 map[wire.BitcoinNet]*Params
+	pubKeyHashAddrIDs    map[byte]struct{}
+	scriptHashAddrIDs    map[byte]struct{}
+	bech32SegwitPrefixes map[string]struct{}
+	hdPrivToPubKeyIDs    map[[4]byte][]byte
+}
+
+// defaultRegistry is the registry used by the package level functions.
+var defaultRegistry = NewRegistry()
+
+// NewRegistry returns a new registry with the passed networks registered.  It
+// panics if the same network is passed twice as that can only be a programming
+// error.
+func NewRegistry(params ...*Params) *Registry {
+	r := &Registry{
+		nets:                 make(map[wire.BitcoinNet]*Params),
+		pubKeyHashAddrIDs:    make(map[byte]struct{}),
+		scriptHashAddrIDs:    make(map[byte]struct{}),
+		bech32SegwitPrefixes: make(map[string]struct{}),
+		hdPrivToPubKeyIDs:    make(map[[4]byte][]byte),
+	}
+	for _, p := range params {
+		if err := r.Register(p); err != nil {
+			panic("failed to register network: " + err.Error())
+		}
+	}
+	return r
+}
+
+// Register registers the network parameters with the registry.  It returns
+// ErrDuplicateNet if a network with the same wire.BitcoinNet is already
+// registered.
+func (r *Registry) Register(params *Params) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if _, ok := r.nets[params.Net]; ok {
+		return ErrDuplicateNet
+	}
+	r.nets[params.Net] = params
+	r.pubKeyHashAddrIDs[params.PubKeyHashAddrID] = struct{}{}
+	r.scriptHashAddrIDs[params.ScriptHashAddrID] = struct{}{}
+	r.hdPrivToPubKeyIDs[params.HDPrivateKeyID] = params.HDPublicKeyID[:]
+
+	// A valid Bech32 encoded segwit address always has as prefix the
+	// human-readable part for the given net followed by '1'.
+	r.bech32SegwitPrefixes[params.Bech32HRPSegwit+"1"] = struct{}{}
+	return nil
+}
+
+// ParamsForNet returns the parameters of the registered network identified by
+// the passed wire.BitcoinNet, or false when it is not registered.
+func (r *Registry) ParamsForNet(net wire.BitcoinNet) (*Params, bool) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	params, ok := r.nets[net]
+	return params, ok
+}
+
+// IsPubKeyHashAddrID returns whether the id is an identifier known to prefix a
+// pay-to-pubkey-hash address on any network of the registry.
+func (r *Registry) IsPubKeyHashAddrID(id byte) bool {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	_, ok := r.pubKeyHashAddrIDs[id]
+	return ok
+}
+
+// IsScriptHashAddrID returns whether the id is an identifier known to prefix a
+// pay-to-script-hash address on any network of the registry.
+func (r *Registry) IsScriptHashAddrID(id byte) bool {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	_, ok := r.scriptHashAddrIDs[id]
+	return ok
+}
+
+// IsBech32SegwitPrefix returns whether the prefix is a known prefix for segwit
+// addresses on any network of the registry.
+func (r *Registry) IsBech32SegwitPrefix(prefix string) bool {
+	prefix = strings.ToLower(prefix)
+
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	_, ok := r.bech32SegwitPrefixes[prefix]
+	return ok
+}
+
+// HDPrivateKeyToPublicKeyID accepts a private hierarchical deterministic
+// extended key id and returns the associated public key id of the network of
+// the registry it identifies.  When the provided id is not registered, the
+// ErrUnknownHDKeyID error will be returned.
+func (r *Registry) HDPrivateKeyToPublicKeyID(id []byte) ([]byte, error) {
+	if len(id) != 4 {
+		return nil, ErrUnknownHDKeyID
+	}
+
+	var key [4]byte
+	copy(key[:], id)
+
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	pubBytes, ok := r.hdPrivToPubKeyIDs[key]
+	if !ok {
+		return nil, ErrUnknownHDKeyID
+	}
+
+	return pubBytes, nil
+}
@@ -0,0 +1,95 @@
+package chaincfg_test
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	. "github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// TestRegistry ensures a registry only knows the magics of the networks
+// registered with it, independently of the default registry.
+func TestRegistry(t *testing.T) {
+	r := NewRegistry(&PktMainNetParams)
+
+	if err := r.Register(&PktMainNetParams); err != ErrDuplicateNet {
+		t.Fatalf("Register: got %v, want %v", err, ErrDuplicateNet)
+	}
+	if params, ok := r.ParamsForNet(PktMainNetParams.Net); !ok ||
+		params != &PktMainNetParams {
+
+		t.Fatalf("ParamsForNet: got %v %v", params, ok)
+	}
+	if _, ok := r.ParamsForNet(MainNetParams.Net); ok {
+		t.Fatal("ParamsForNet: unexpected mainnet")
+	}
+
+	// The magics of the default networks which are not registered are
+	// unknown to the registry.
+	if !r.IsPubKeyHashAddrID(PktMainNetParams.PubKeyHashAddrID) ||
+		r.IsPubKeyHashAddrID(MainNetParams.PubKeyHashAddrID) {
+
+		t.Fatal("IsPubKeyHashAddrID: unexpected result")
+	}
+	if !r.IsScriptHashAddrID(PktMainNetParams.ScriptHashAddrID) ||
+		r.IsScriptHashAddrID(MainNetParams.ScriptHashAddrID) {
+
+		t.Fatal("IsScriptHashAddrID: unexpected result")
+	}
+	if !r.IsBech32SegwitPrefix("PKT1") || r.IsBech32SegwitPrefix("bc1") {
+		t.Fatal("IsBech32SegwitPrefix: unexpected result")
+	}
+	pub, err := r.HDPrivateKeyToPublicKeyID(PktMainNetParams.HDPrivateKeyID[:])
+	if err != nil || !bytes.Equal(pub, PktMainNetParams.HDPublicKeyID[:]) {
+		t.Fatalf("HDPrivateKeyToPublicKeyID: got %x %v", pub, err)
+	}
+	_, err = r.HDPrivateKeyToPublicKeyID(MainNetParams.HDPrivateKeyID[:])
+	if err != ErrUnknownHDKeyID {
+		t.Fatalf("HDPrivateKeyToPublicKeyID: got %v, want %v", err,
+			ErrUnknownHDKeyID)
+	}
+
+	// A network registered with an isolated registry is not known to the
+	// default registry.
+	params := mockNetParams
+	params.Net = 1<<32 - 2
+	params.PubKeyHashAddrID = 0x9e
+	if err := r.Register(&params); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if !r.IsPubKeyHashAddrID(0x9e) || IsPubKeyHashAddrID(0x9e) {
+		t.Fatal("IsPubKeyHashAddrID: network leaked into the default registry")
+	}
+	if _, ok := ParamsForNet(params.Net); ok {
+		t.Fatal("ParamsForNet: network leaked into the default registry")
+	}
+}
+
+// TestRegistryConcurrent ensures networks can be registered while the
+// registry is used.
+func TestRegistryConcurrent(t *testing.T) {
+	r := NewRegistry()
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		params := mockNetParams
+		params.Net -= wire.BitcoinNet(i)
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if err := r.Register(&params); err != nil {
+				t.Errorf("Register: %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			r.IsPubKeyHashAddrID(mockNetParams.PubKeyHashAddrID)
+			r.IsBech32SegwitPrefix("tc1")
+		}()
+	}
+	wg.Wait()
+	if !r.IsPubKeyHashAddrID(mockNetParams.PubKeyHashAddrID) {
+		t.Fatal("IsPubKeyHashAddrID: network not registered")
+	}
+}
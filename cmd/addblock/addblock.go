@@ -84,17 +84,50 @@ func realMain() error {
 	}
 	defer db.Close()
 
-	fi, err := os.Open(cfg.InFile)
-	if err != nil {
-		log.Errorf("Failed to open file %v: %v", cfg.InFile, err)
-		return err
+	// Open the block source.  The blocks of other implementations are
+	// fully validated, and the block files of bitcoind also hold blocks
+	// out of order and outside of the main chain.
+	var src blockSource
+	flags := blockchain.BFFastAdd
+	unordered := false
+	switch {
+	case cfg.BtcdDb != "":
+		fdb, err := newFfldbSource(cfg.BtcdDb)
+		if err != nil {
+			log.Errorf("Failed to open block database %v: %v",
+				cfg.BtcdDb, err)
+			return err
+		}
+		defer fdb.Close()
+		src = fdb
+		flags = blockchain.BFNone
+
+	case cfg.BitcoindDir != "":
+		bitcoind, err := newBitcoindSource(cfg.BitcoindDir)
+		if err != nil {
+			log.Errorf("Failed to open block files in %v: %v",
+				cfg.BitcoindDir, err)
+			return err
+		}
+		defer bitcoind.Close()
+		src = bitcoind
+		flags = blockchain.BFNone
+		unordered = true
+
+	default:
+		fi, err := os.Open(cfg.InFile)
+		if err != nil {
+			log.Errorf("Failed to open file %v: %v", cfg.InFile, err)
+			return err
+		}
+		defer fi.Close()
+		src = &bootstrapSource{r: fi}
 	}
-	defer fi.Close()
 
-	// Create a block importer for the database and input file and start it.
-	// The done channel returned from start will contain an error if
+	// Create a block importer for the database and block source and start
+	// it.  The done channel returned from start will contain an error if
 	// anything went wrong.
-	importer, err := newBlockImporter(db, fi)
+	importer, err := newBlockImporter(db, src, flags, unordered)
 	if err != nil {
 		log.Errorf("Failed create block importer: %v", err)
 		return err
@@ -115,6 +148,10 @@ func realMain() error {
 	log.Infof("Processed a total of %d blocks (%d imported, %d already "+
 		"known)", results.blocksProcessed, results.blocksImported,
 		results.blocksProcessed-results.blocksImported)
+	if results.blocksSkipped > 0 {
+		log.Warnf("Skipped %d blocks which do not link to the block "+
+			"chain", results.blocksSkipped)
+	}
 	return nil
 }
 
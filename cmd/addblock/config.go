@@ -40,6 +40,8 @@ type config struct {
 	RegressionTest bool   `long:"regtest" description:"Use the regression test network"`
 	SimNet         bool   `long:"simnet" description:"Use the simulation test network"`
 	InFile         string `short:"i" long:"infile" description:"File containing the block(s)"`
+	BtcdDb         string `long:"btcddb" description:"Import the main chain of the btcd or pktd ffldb block database at this path instead of a block file"`
+	BitcoindDir    string `long:"bitcoinddir" description:"Import the blk*.dat files of the bitcoind blocks directory at this path instead of a block file"`
 	TxIndex        bool   `long:"txindex" description:"Build a full hash-based transaction index which makes all transactions available via the getrawtransaction RPC"`
 	AddrIndex      bool   `long:"addrindex" description:"Build a full address-based transaction index which makes the searchrawtransactions RPC available"`
 	Progress       int    `short:"p" long:"progress" description:"Show a progress message each time this number of seconds have passed -- Use 0 to disable progress announcements"`
@@ -148,10 +150,42 @@ func loadConfig() (*config, []string, error) {
 	// worry about changing names per network and such.
 	cfg.DataDir = filepath.Join(cfg.DataDir, netName(activeNetParams))
 
-	// Ensure the specified block file exists.
-	if !fileExists(cfg.InFile) {
-		str := "%s: The specified block file [%v] does not exist"
-		err := fmt.Errorf(str, "loadConfig", cfg.InFile)
+	// Only one legacy source can be imported at a time.
+	if cfg.BtcdDb != "" && cfg.BitcoindDir != "" {
+		str := "%s: The btcddb and bitcoinddir options can't be used " +
+			"together -- choose one of the two"
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		parser.WriteHelp(os.Stderr)
+		return nil, nil, err
+	}
+
+	// The block database being imported can't be the one imported to.
+	if cfg.BtcdDb != "" {
+		srcPath, err1 := filepath.Abs(cfg.BtcdDb)
+		dstPath, err2 := filepath.Abs(filepath.Join(cfg.DataDir,
+			blockDbNamePrefix+"_"+cfg.DbType))
+		if err1 == nil && err2 == nil && srcPath == dstPath {
+			str := "%s: The btcddb option must not point to the " +
+				"block database of the data directory"
+			err := fmt.Errorf(str, funcName)
+			fmt.Fprintln(os.Stderr, err)
+			parser.WriteHelp(os.Stderr)
+			return nil, nil, err
+		}
+	}
+
+	// Ensure the specified block source exists.
+	source := cfg.InFile
+	switch {
+	case cfg.BtcdDb != "":
+		source = cfg.BtcdDb
+	case cfg.BitcoindDir != "":
+		source = cfg.BitcoindDir
+	}
+	if !fileExists(source) {
+		str := "%s: The specified block source [%v] does not exist"
+		err := fmt.Errorf(str, "loadConfig", source)
 		fmt.Fprintln(os.Stderr, err)
 		parser.WriteHelp(os.Stderr)
 		return nil, nil, err
@@ -21,19 +21,45 @@ import (
 
 var zeroHash = chainhash.Hash{}
 
+// maxPendingBlocks is the maximum number of blocks of an unordered source held
+// until their parents are imported.
+const maxPendingBlocks = 10000
+
 // importResults houses the stats and result as an import operation.
 type importResults struct {
 	blocksProcessed int64
 	blocksImported  int64
+	blocksSkipped   int64
 	err             error
 }
 
+// blockSource is a source of serialized blocks to import.
+type blockSource interface {
+	// readBlock returns the next serialized block, or nil when there are no
+	// more blocks to read.
+	readBlock() ([]byte, error)
+}
+
+// bootstrapSource reads the blocks of a bootstrap file.
+type bootstrapSource struct {
+	r io.Reader
+}
+
+// readBlock reads the next block from the bootstrap file.
+func (s *bootstrapSource) readBlock() ([]byte, error) {
+	return readBlockRecord(s.r, false)
+}
+
 // blockImporter houses information about an ongoing import from a block data
 // file to the block database.
 type blockImporter struct {
 	db                database.DB
 	chain             *blockchain.BlockChain
-	r                 io.ReadSeeker
+	src               blockSource
+	flags             blockchain.BehaviorFlags
+	unordered         bool
+	pending           map[chainhash.Hash][]*btcutil.Block
+	numPending        int
 	processQueue      chan []byte
 	doneChan          chan bool
 	errChan           chan error
@@ -48,12 +74,15 @@ type blockImporter struct {
 	lastLogTime       time.Time
 }
 
-// readBlock reads the next block from the input file.
-func (bi *blockImporter) readBlock() ([]byte, error) {
+// readBlockRecord reads the next block from r, which holds blocks in the
+// bootstrap file format also used by the block files of bitcoind.  When padded
+// is set, a record starting with a zero network is taken as the padding ending
+// the blocks of a preallocated file.
+func readBlockRecord(r io.Reader, padded bool) ([]byte, error) {
 	// The block file format is:
 	//  <network> <block length> <serialized block>
 	var net uint32
-	err := binary.Read(bi.r, binary.LittleEndian, &net)
+	err := binary.Read(r, binary.LittleEndian, &net)
 	if err != nil {
 		if err != io.EOF {
 			return nil, err
@@ -62,6 +91,9 @@ func (bi *blockImporter) readBlock() ([]byte, error) {
 		// No block and no error means there are no more blocks to read.
 		return nil, nil
 	}
+	if padded && net == 0 {
+		return nil, nil
+	}
 	if net != uint32(activeNetParams.Net) {
 		return nil, fmt.Errorf("network mismatch -- got %x, want %x",
 			net, uint32(activeNetParams.Net))
@@ -69,7 +101,7 @@ func (bi *blockImporter) readBlock() ([]byte, error) {
 
 	// Read the block length and ensure it is sane.
 	var blockLen uint32
-	if err := binary.Read(bi.r, binary.LittleEndian, &blockLen); err != nil {
+	if err := binary.Read(r, binary.LittleEndian, &blockLen); err != nil {
 		return nil, err
 	}
 	if blockLen > wire.MaxBlockPayload {
@@ -79,7 +111,7 @@ func (bi *blockImporter) readBlock() ([]byte, error) {
 	}
 
 	serializedBlock := make([]byte, blockLen)
-	if _, err := io.ReadFull(bi.r, serializedBlock); err != nil {
+	if _, err := io.ReadFull(r, serializedBlock); err != nil {
 		return nil, err
 	}
 
@@ -88,15 +120,17 @@ func (bi *blockImporter) readBlock() ([]byte, error) {
 
 // processBlock potentially imports the block into the database.  It first
 // deserializes the raw block while checking for errors.  Already known blocks
-// are skipped and orphan blocks are considered errors.  Finally, it runs the
-// block through the chain rules to ensure it follows all rules and matches
-// up to the known checkpoint.  Returns whether the block was imported along
-// with any potential errors.
-func (bi *blockImporter) processBlock(serializedBlock []byte) (bool, error) {
+// are skipped and orphan blocks are considered errors, unless the blocks are
+// read out of order, in which case they are held until their parents are
+// imported.  Finally, it runs the block through the chain rules to ensure it
+// follows all rules and matches up to the known checkpoint.  Returns the number
+// of blocks imported, which includes the held blocks building on it, along with
+// any potential errors.
+func (bi *blockImporter) processBlock(serializedBlock []byte) (int64, error) {
 	// Deserialize the block which includes checks for malformed blocks.
 	block, err := btcutil.NewBlockFromBytes(serializedBlock)
 	if err != nil {
-		return false, err
+		return 0, err
 	}
 
 	// update progress statistics
@@ -107,43 +141,88 @@ func (bi *blockImporter) processBlock(serializedBlock []byte) (bool, error) {
 	blockHash := block.Hash()
 	exists, err := bi.chain.HaveBlock(blockHash)
 	if err != nil {
-		return false, err
+		return 0, err
 	}
 	if exists {
-		return false, nil
+		return 0, nil
 	}
 
-	// Don't bother trying to process orphans.
+	// Don't bother trying to process orphans.  The blocks of an unordered
+	// source are stored in the order they were downloaded, so their parents
+	// may follow them.
 	prevHash := &block.MsgBlock().Header.PrevBlock
 	if !prevHash.IsEqual(&zeroHash) {
 		exists, err := bi.chain.HaveBlock(prevHash)
 		if err != nil {
-			return false, err
+			return 0, err
 		}
-		if !exists {
-			return false, fmt.Errorf("import file contains block "+
+		if !exists && !bi.unordered {
+			return 0, fmt.Errorf("import file contains block "+
 				"%v which does not link to the available "+
 				"block chain", prevHash)
 		}
+		if !exists {
+			if bi.numPending >= maxPendingBlocks {
+				return 0, fmt.Errorf("more than %d blocks do "+
+					"not link to the available block chain",
+					maxPendingBlocks)
+			}
+			bi.pending[*prevHash] = append(bi.pending[*prevHash], block)
+			bi.numPending++
+			return 0, nil
+		}
+	}
+
+	if err := bi.importBlock(block); err != nil {
+		return 0, err
+	}
+	imported := int64(1)
+
+	// Import the held blocks building on the imported ones.
+	parents := []*chainhash.Hash{blockHash}
+	for len(parents) > 0 {
+		children := bi.pending[*parents[0]]
+		delete(bi.pending, *parents[0])
+		parents = parents[1:]
+		for _, child := range children {
+			bi.numPending--
+			exists, err := bi.chain.HaveBlock(child.Hash())
+			if err != nil {
+				return imported, err
+			}
+			if exists {
+				continue
+			}
+			if err := bi.importBlock(child); err != nil {
+				return imported, err
+			}
+			imported++
+			parents = append(parents, child.Hash())
+		}
 	}
 
-	// Ensure the blocks follows all of the chain rules and match up to the
-	// known checkpoints.
-	isMainChain, isOrphan, err := bi.chain.ProcessBlock(block,
-		blockchain.BFFastAdd)
+	return imported, nil
+}
+
+// importBlock ensures the passed block follows all of the chain rules and
+// matches up to the known checkpoints, adding it to the block chain.  The
+// blocks of an unordered source may belong to stale branches, so they are not
+// required to extend the main chain.
+func (bi *blockImporter) importBlock(block *btcutil.Block) error {
+	isMainChain, isOrphan, err := bi.chain.ProcessBlock(block, bi.flags)
 	if err != nil {
-		return false, err
+		return err
 	}
-	if !isMainChain {
-		return false, fmt.Errorf("import file contains an block that "+
-			"does not extend the main chain: %v", blockHash)
+	if !isMainChain && !bi.unordered {
+		return fmt.Errorf("import file contains an block that "+
+			"does not extend the main chain: %v", block.Hash())
 	}
 	if isOrphan {
-		return false, fmt.Errorf("import file contains an orphan "+
-			"block: %v", blockHash)
+		return fmt.Errorf("import file contains an orphan "+
+			"block: %v", block.Hash())
 	}
 
-	return true, nil
+	return nil
 }
 
 // readHandler is the main handler for reading blocks from the import file.
@@ -154,7 +233,7 @@ out:
 	for {
 		// Read the next block from the file and if anything goes wrong
 		// notify the status handler with the error and bail.
-		serializedBlock, err := bi.readBlock()
+		serializedBlock, err := bi.src.readBlock()
 		if err != nil {
 			bi.errChan <- fmt.Errorf("Error reading from input "+
 				"file: %v", err.Error())
@@ -235,9 +314,7 @@ out:
 				break out
 			}
 
-			if imported {
-				bi.blocksImported++
-			}
+			bi.blocksImported += imported
 
 			bi.logProgress()
 
@@ -268,6 +345,7 @@ func (bi *blockImporter) statusHandler(resultsChan chan *importResults) {
 		resultsChan <- &importResults{
 			blocksProcessed: bi.blocksProcessed,
 			blocksImported:  bi.blocksImported,
+			blocksSkipped:   int64(bi.numPending),
 			err:             nil,
 		}
 	}
@@ -297,9 +375,12 @@ func (bi *blockImporter) Import() chan *importResults {
 	return resultChan
 }
 
-// newBlockImporter returns a new importer for the provided file reader seeker
-// and database.
-func newBlockImporter(db database.DB, r io.ReadSeeker) (*blockImporter, error) {
+// newBlockImporter returns a new importer for the provided block source and
+// database.  The blocks are processed with the passed behavior flags.  When
+// unordered is set, the source may return blocks before their parents and
+// blocks outside of the main chain.
+func newBlockImporter(db database.DB, src blockSource, flags blockchain.BehaviorFlags,
+	unordered bool) (*blockImporter, error) {
 	// Create the transaction and address indexes if needed.
 	//
 	// CAUTION: the txindex needs to be first in the indexes array because
@@ -342,7 +423,10 @@ func newBlockImporter(db database.DB, r io.ReadSeeker) (*blockImporter, error) {
 
 	return &blockImporter{
 		db:           db,
-		r:            r,
+		src:          src,
+		flags:        flags,
+		unordered:    unordered,
+		pending:      make(map[chainhash.Hash][]*btcutil.Block),
 		processQueue: make(chan []byte, 2),
 		doneChan:     make(chan bool),
 		errChan:      make(chan error),
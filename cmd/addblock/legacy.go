@@ -0,0 +1,161 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/database"
+)
+
+// heightIndexBucketName is the name of the bucket of the block height to block
+// hash index of the main chain, which btcd and pktd keep in the metadata of
+// their block databases.
+var heightIndexBucketName = []byte("heightidx")
+
+// ffldbSource reads the main chain blocks of a btcd or pktd ffldb database in
+// height order.
+type ffldbSource struct {
+	db     database.DB
+	height uint32
+}
+
+// newFfldbSource opens the ffldb database at the passed path for reading and
+// ensures its chain starts with the genesis block of the active network.
+func newFfldbSource(dbPath string) (*ffldbSource, error) {
+	db, err := database.Open("ffldb", dbPath, activeNetParams.Net, true)
+	if err != nil {
+		return nil, err
+	}
+	s := &ffldbSource{db: db}
+	genesisHash, err := s.hashByHeight(0)
+	if err == nil && genesisHash == nil {
+		err = fmt.Errorf("database %s holds no block index", dbPath)
+	}
+	if err == nil && !genesisHash.IsEqual(activeNetParams.GenesisHash) {
+		err = fmt.Errorf("database %s holds the chain of genesis block "+
+			"%v, not the one of %s", dbPath, genesisHash,
+			activeNetParams.Name)
+	}
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// hashByHeight returns the hash of the main chain block at the passed height,
+// or nil when the chain is shorter.
+func (s *ffldbSource) hashByHeight(height uint32) (*chainhash.Hash, error) {
+	var hash *chainhash.Hash
+	err := s.db.View(func(dbTx database.Tx) error {
+		heightIndex := dbTx.Metadata().Bucket(heightIndexBucketName)
+		if heightIndex == nil {
+			return nil
+		}
+		var serializedHeight [4]byte
+		binary.LittleEndian.PutUint32(serializedHeight[:], height)
+		hashBytes := heightIndex.Get(serializedHeight[:])
+		if hashBytes == nil {
+			return nil
+		}
+		var err error
+		hash, err = chainhash.NewHash(hashBytes)
+		return err
+	})
+	return hash, err
+}
+
+// readBlock reads the main chain block following the last one read.
+func (s *ffldbSource) readBlock() ([]byte, error) {
+	hash, err := s.hashByHeight(s.height)
+	if err != nil || hash == nil {
+		return nil, err
+	}
+	var serializedBlock []byte
+	err = s.db.View(func(dbTx database.Tx) error {
+		block, err := dbTx.FetchBlock(hash)
+		if err != nil {
+			return err
+		}
+
+		// The returned bytes are only valid during the transaction.
+		serializedBlock = append([]byte(nil), block...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.height++
+	return serializedBlock, nil
+}
+
+// Close closes the source database.
+func (s *ffldbSource) Close() error {
+	return s.db.Close()
+}
+
+// bitcoindSource reads the blocks of the blk*.dat files of a bitcoind blocks
+// directory in file order, which is the order they were downloaded in.
+type bitcoindSource struct {
+	dir     string
+	fileNum int
+	file    *os.File
+}
+
+// newBitcoindSource returns a source reading the block files of the passed
+// bitcoind blocks directory, ensuring it holds at least the first one.
+func newBitcoindSource(dir string) (*bitcoindSource, error) {
+	s := &bitcoindSource{dir: dir}
+	file, err := os.Open(s.filePath())
+	if err != nil {
+		return nil, err
+	}
+	s.file = file
+	return s, nil
+}
+
+// filePath returns the path of the current block file.
+func (s *bitcoindSource) filePath() string {
+	return filepath.Join(s.dir, fmt.Sprintf("blk%05d.dat", s.fileNum))
+}
+
+// readBlock reads the next block of the block files, moving to the next file
+// at the end of the blocks of the current one.
+func (s *bitcoindSource) readBlock() ([]byte, error) {
+	for s.file != nil {
+		serializedBlock, err := readBlockRecord(s.file, true)
+		if err != nil || serializedBlock != nil {
+			return serializedBlock, err
+		}
+
+		// The files are numbered from zero, so the first missing one
+		// ends the blocks.
+		s.file.Close()
+		s.file = nil
+		s.fileNum++
+		file, err := os.Open(s.filePath())
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		s.file = file
+	}
+	return nil, nil
+}
+
+// Close closes the current block file.
+func (s *bitcoindSource) Close() error {
+	if s.file == nil {
+		return nil
+	}
+	return s.file.Close()
+}
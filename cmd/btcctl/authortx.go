@@ -0,0 +1,219 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/chaincfg/globalcfg"
+	"github.com/pkt-cash/pktd/txauthor"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+const (
+	// authorTxMethod is the offline command building an unsigned
+	// transaction without connecting to a server.
+	authorTxMethod = "authortransaction"
+
+	// authorTxUsage is the one-line usage of the authortransaction
+	// command.
+	authorTxUsage = authorTxMethod + ` [{"txid":"id","vout":n,"amount":n.nnn,"scriptPubKey":"hex","redeemScript":"hex"},...] {"address":amount,...} feerate "changeaddress" (sortbip69=false) (locktime=0) (replaceable=false)`
+)
+
+// authorTxInput is an input of the authortransaction command.
+type authorTxInput struct {
+	TxID         string  `json:"txid"`
+	Vout         uint32  `json:"vout"`
+	Amount       float64 `json:"amount"`
+	ScriptPubKey string  `json:"scriptPubKey"`
+	RedeemScript string  `json:"redeemScript"`
+}
+
+// authorTxResult is the result of the authortransaction command.
+type authorTxResult struct {
+	Hex         string  `json:"hex"`
+	Fee         float64 `json:"fee"`
+	VirtualSize int     `json:"vsize"`
+	ChangeIndex int     `json:"changeindex"`
+}
+
+// activeNetParams returns the parameters of the network selected by the
+// configuration.
+func activeNetParams(cfg *config) *chaincfg.Params {
+	switch {
+	case cfg.TestNet3:
+		return &chaincfg.TestNet3Params
+	case cfg.SimNet:
+		return &chaincfg.SimNetParams
+	case cfg.BtcMainNet:
+		return &chaincfg.MainNetParams
+	case cfg.PktTest:
+		return &chaincfg.PktTestNetParams
+	}
+	return &chaincfg.PktMainNetParams
+}
+
+// parseAmount returns the atomic units of the passed amount of coins.
+func parseAmount(f float64) (btcutil.Amount, error) {
+	amount, err := globalcfg.NewAmount(f)
+	if err != nil {
+		return 0, err
+	}
+	if amount < 0 {
+		return 0, fmt.Errorf("negative amount %v", f)
+	}
+	return btcutil.Amount(amount), nil
+}
+
+// decodeOutputs decodes the outputs of the authortransaction command, keeping
+// the order they are given in.
+func decodeOutputs(param string, params *chaincfg.Params) ([]*wire.TxOut, error) {
+	dec := json.NewDecoder(bytes.NewReader([]byte(param)))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, errors.New("outputs must be a JSON object")
+	}
+	var outputs []*wire.TxOut
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		var value float64
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		addr, err := btcutil.DecodeAddress(tok.(string), params)
+		if err != nil {
+			return nil, err
+		}
+		pkScript, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			return nil, err
+		}
+		amount, err := parseAmount(value)
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, wire.NewTxOut(int64(amount), pkScript))
+	}
+	return outputs, nil
+}
+
+// decodeInputs decodes the inputs of the authortransaction command.
+func decodeInputs(param string) ([]*txauthor.Input, error) {
+	var txInputs []authorTxInput
+	if err := json.Unmarshal([]byte(param), &txInputs); err != nil {
+		return nil, err
+	}
+	inputs := make([]*txauthor.Input, 0, len(txInputs))
+	for _, txInput := range txInputs {
+		hash, err := chainhash.NewHashFromStr(txInput.TxID)
+		if err != nil {
+			return nil, err
+		}
+		amount, err := parseAmount(txInput.Amount)
+		if err != nil {
+			return nil, err
+		}
+		pkScript, err := hex.DecodeString(txInput.ScriptPubKey)
+		if err != nil {
+			return nil, err
+		}
+		redeemScript, err := hex.DecodeString(txInput.RedeemScript)
+		if err != nil {
+			return nil, err
+		}
+		if len(redeemScript) == 0 {
+			redeemScript = nil
+		}
+		inputs = append(inputs, &txauthor.Input{
+			OutPoint:     *wire.NewOutPoint(hash, txInput.Vout),
+			Amount:       amount,
+			PkScript:     pkScript,
+			RedeemScript: redeemScript,
+		})
+	}
+	return inputs, nil
+}
+
+// authorTransaction builds an unsigned transaction spending the passed inputs,
+// in order and as needed, to pay the passed outputs and the fee at the passed
+// rate in coins per kilobyte, with the change paid to the passed address.  It
+// does not connect to a server, so the inputs must be given with their amount
+// and script.  The lock time and replaceability may be set by the optional
+// parameters.
+func authorTransaction(cfg *config, params []string) (*authorTxResult, error) {
+	if len(params) < 4 || len(params) > 7 {
+		return nil, fmt.Errorf("wrong number of params (expected 4 to "+
+			"7, received %d)", len(params))
+	}
+	netParams := activeNetParams(cfg)
+	globalcfg.SelectConfig(netParams.GlobalConf)
+
+	inputs, err := decodeInputs(params[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid inputs: %v", err)
+	}
+	outputs, err := decodeOutputs(params[1], netParams)
+	if err != nil {
+		return nil, fmt.Errorf("invalid outputs: %v", err)
+	}
+	feeRate, err := strconv.ParseFloat(params[2], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid fee rate: %v", err)
+	}
+	b := &txauthor.Builder{Outputs: outputs}
+	if b.FeeRate, err = parseAmount(feeRate); err != nil {
+		return nil, fmt.Errorf("invalid fee rate: %v", err)
+	}
+	changeAddr, err := btcutil.DecodeAddress(params[3], netParams)
+	if err != nil {
+		return nil, fmt.Errorf("invalid change address: %v", err)
+	}
+	if b.ChangeScript, err = txscript.PayToAddrScript(changeAddr); err != nil {
+		return nil, fmt.Errorf("invalid change address: %v", err)
+	}
+	if len(params) > 4 {
+		if b.SortBIP69, err = strconv.ParseBool(params[4]); err != nil {
+			return nil, fmt.Errorf("invalid sortbip69: %v", err)
+		}
+	}
+	if len(params) > 5 {
+		lockTime, err := strconv.ParseUint(params[5], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid locktime: %v", err)
+		}
+		b.LockTime = uint32(lockTime)
+	}
+	if len(params) > 6 {
+		if b.Replaceable, err = strconv.ParseBool(params[6]); err != nil {
+			return nil, fmt.Errorf("invalid replaceable: %v", err)
+		}
+	}
+
+	authored, err := b.Build(txauthor.SelectInputs(inputs))
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := authored.Tx.Serialize(&buf); err != nil {
+		return nil, err
+	}
+	return &authorTxResult{
+		Hex:         hex.EncodeToString(buf.Bytes()),
+		Fee:         authored.Fee.ToBTC(),
+		VirtualSize: authored.VirtualSize,
+		ChangeIndex: authored.ChangeIndex,
+	}, nil
+}
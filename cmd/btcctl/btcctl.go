@@ -8,8 +8,10 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
+	"github.com/pkt-cash/pktd/bip21"
 	"github.com/pkt-cash/pktd/btcjson"
 )
 
@@ -46,6 +48,34 @@ func usage(errorMessage string) {
 	fmt.Fprintln(os.Stderr, listCmdMessage)
 }
 
+// expandPaymentURI replaces a pkt: payment URI passed as the first parameter
+// of sendtoaddress with the address and amount it requests.  When the URI has
+// an amount, the remaining parameters are the comments, otherwise the amount
+// must follow the URI.  The message of the URI is used as the comment when no
+// comment is given.  Parameters which do not start with a URI are returned
+// unchanged.
+func expandPaymentURI(params []interface{}) ([]interface{}, error) {
+	first, ok := params[0].(string)
+	if !ok || !strings.HasPrefix(strings.ToLower(first), bip21.Scheme+":") {
+		return params, nil
+	}
+	uri, err := bip21.Parse(first)
+	if err != nil {
+		return nil, err
+	}
+
+	expanded := []interface{}{uri.Address}
+	if uri.Amount != 0 {
+		amount := strconv.FormatFloat(uri.Amount.ToBTC(), 'f', -1, 64)
+		expanded = append(expanded, amount)
+	}
+	expanded = append(expanded, params[1:]...)
+	if uri.Message != "" && len(expanded) == 2 {
+		expanded = append(expanded, uri.Message)
+	}
+	return expanded, nil
+}
+
 func main() {
 	cfg, args, err := loadConfig()
 	if err != nil {
@@ -56,6 +86,25 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Offline commands are run locally without connecting to a server.
+	if args[0] == authorTxMethod {
+		result, err := authorTransaction(cfg, args[1:])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s command: %v\n", authorTxMethod,
+				err)
+			fmt.Fprintln(os.Stderr, "Usage:")
+			fmt.Fprintf(os.Stderr, "  %s\n", authorTxUsage)
+			os.Exit(1)
+		}
+		marshalled, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Println(string(marshalled))
+		return
+	}
+
 	// Ensure the specified method identifies a valid registered command and
 	// is one of the usable types.
 	method := args[0]
@@ -102,6 +151,16 @@ func main() {
 		params = append(params, arg)
 	}
 
+	// Allow paying a payment URI by passing it to sendtoaddress in place
+	// of the address.
+	if method == "sendtoaddress" && len(params) > 0 {
+		params, err = expandPaymentURI(params)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid payment URI: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
 	// Attempt to create the appropriate command using the arguments
 	// provided by the user.
 	cmd, err := btcjson.NewCmd(method, params...)
@@ -86,6 +86,9 @@ func listCommands() {
 		}
 		fmt.Println()
 	}
+	fmt.Println("Offline Commands:")
+	fmt.Println(authorTxUsage)
+	fmt.Println()
 }
 
 // config defines the configuration options for btcctl.
@@ -97,6 +100,7 @@ type config struct {
 	ConfigFile    string `short:"C" long:"configfile" description:"Path to configuration file"`
 	RPCUser       string `short:"u" long:"rpcuser" description:"RPC username"`
 	RPCPassword   string `short:"P" long:"rpcpass" default-mask:"-" description:"RPC password"`
+	RPCToken      string `long:"rpctoken" default-mask:"-" description:"RPC capability token minted with the minttoken command, used instead of the username and password"`
 	RPCServer     string `short:"s" long:"rpcserver" description:"RPC server to connect to"`
 	RPCCert       string `short:"c" long:"rpccert" description:"RPC server certificate chain for validation"`
 	NoTLS         bool   `long:"notls" description:"Disable TLS"`
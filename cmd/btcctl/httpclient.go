@@ -80,8 +80,13 @@ func sendPostRequest(marshalledJSON []byte, cfg *config) ([]byte, error) {
 	httpRequest.Close = true
 	httpRequest.Header.Set("Content-Type", "application/json")
 
-	// Configure basic access authorization.
-	httpRequest.SetBasicAuth(cfg.RPCUser, cfg.RPCPassword)
+	// Configure basic access authorization, or bearer authorization with
+	// a token.
+	if cfg.RPCToken != "" {
+		httpRequest.Header.Set("Authorization", "Bearer "+cfg.RPCToken)
+	} else {
+		httpRequest.SetBasicAuth(cfg.RPCUser, cfg.RPCPassword)
+	}
 
 	// Create the new HTTP client that is configured according to the user-
 	// specified options and submit the request.
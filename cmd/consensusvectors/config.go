@@ -0,0 +1,156 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	flags "github.com/jessevdk/go-flags"
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/database"
+	_ "github.com/pkt-cash/pktd/database/ffldb"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+const (
+	defaultDbType     = "ffldb"
+	defaultMaxScripts = 10
+)
+
+var (
+	pktdHomeDir     = btcutil.AppDataDir("pktd", false)
+	defaultDataDir  = filepath.Join(pktdHomeDir, "data")
+	knownDbTypes    = database.SupportedDrivers()
+	activeNetParams = chaincfg.PktMainNetParams
+)
+
+// config defines the configuration options for consensusvectors.
+//
+// See loadConfig for details on the configuration load process.
+type config struct {
+	DataDir        string `short:"b" long:"datadir" description:"Location of the pktd data directory"`
+	DbType         string `long:"dbtype" description:"Database backend to use for the Block Chain"`
+	TestNet3       bool   `long:"testnet" description:"Use the test network"`
+	PktTest        bool   `long:"pkttest" description:"Use the pkt.cash test network"`
+	BtcMainNet     bool   `long:"btc" description:"Use the bitcoin main network"`
+	RegressionTest bool   `long:"regtest" description:"Use the regression test network"`
+	SimNet         bool   `long:"simnet" description:"Use the simulation test network"`
+	Start          int32  `long:"start" description:"Height of the first block to generate vectors from"`
+	End            int32  `long:"end" description:"Height of the last block to generate vectors from -- default: the best block"`
+	MaxScripts     int    `long:"maxscripts" description:"Maximum number of inputs of each block whose scripts are exported"`
+	Out            string `short:"o" long:"out" description:"File to write the generated corpus to -- default: standard output"`
+	Check          string `long:"check" description:"Check the corpus in the specified file against the consensus code rather than generating one"`
+}
+
+// validDbType returns whether or not dbType is a supported database type.
+func validDbType(dbType string) bool {
+	for _, knownType := range knownDbTypes {
+		if dbType == knownType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// netName returns the name of the data directory of a network, which is
+// "testnet" for testnet version 3 rather than the Name field of its chaincfg
+// parameters.
+func netName(chainParams *chaincfg.Params) string {
+	switch chainParams.Net {
+	case wire.TestNet3:
+		return "testnet"
+	default:
+		return chainParams.Name
+	}
+}
+
+// loadConfig initializes and parses the config using command line options.
+func loadConfig() (*config, []string, error) {
+	// Default config.
+	cfg := config{
+		DataDir:    defaultDataDir,
+		DbType:     defaultDbType,
+		End:        -1,
+		MaxScripts: defaultMaxScripts,
+	}
+
+	// Parse command line options.
+	parser := flags.NewParser(&cfg, flags.Default)
+	remainingArgs, err := parser.Parse()
+	if err != nil {
+		if e, ok := err.(*flags.Error); !ok || e.Type != flags.ErrHelp {
+			parser.WriteHelp(os.Stderr)
+		}
+		return nil, nil, err
+	}
+
+	// Multiple networks can't be selected simultaneously.
+	funcName := "loadConfig"
+	numNets := 0
+	if cfg.TestNet3 {
+		numNets++
+		activeNetParams = chaincfg.TestNet3Params
+	}
+	if cfg.PktTest {
+		numNets++
+		activeNetParams = chaincfg.PktTestNetParams
+	}
+	if cfg.BtcMainNet {
+		numNets++
+		activeNetParams = chaincfg.MainNetParams
+	}
+	if cfg.RegressionTest {
+		numNets++
+		activeNetParams = chaincfg.RegressionNetParams
+	}
+	if cfg.SimNet {
+		numNets++
+		activeNetParams = chaincfg.SimNetParams
+	}
+	if numNets > 1 {
+		str := "%s: The testnet, pkttest, btc, regtest, and simnet " +
+			"params can't be used together -- choose one of the five"
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		parser.WriteHelp(os.Stderr)
+		return nil, nil, err
+	}
+
+	// Validate database type.
+	if !validDbType(cfg.DbType) {
+		str := "%s: The specified database type [%v] is invalid -- " +
+			"supported types %v"
+		err := fmt.Errorf(str, funcName, cfg.DbType, knownDbTypes)
+		fmt.Fprintln(os.Stderr, err)
+		parser.WriteHelp(os.Stderr)
+		return nil, nil, err
+	}
+
+	// Validate the range of blocks.
+	if cfg.Start < 0 || (cfg.End >= 0 && cfg.End < cfg.Start) {
+		str := "%s: The range of blocks [%d, %d] is invalid"
+		err := fmt.Errorf(str, funcName, cfg.Start, cfg.End)
+		fmt.Fprintln(os.Stderr, err)
+		parser.WriteHelp(os.Stderr)
+		return nil, nil, err
+	}
+	if cfg.MaxScripts < 0 {
+		str := "%s: The maximum number of scripts may not be negative"
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		parser.WriteHelp(os.Stderr)
+		return nil, nil, err
+	}
+
+	// Append the network type to the data directory so it is "namespaced"
+	// per network.
+	cfg.DataDir = filepath.Join(cfg.DataDir, netName(&activeNetParams))
+
+	return &cfg, remainingArgs, nil
+}
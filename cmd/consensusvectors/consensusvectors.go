@@ -0,0 +1,138 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/blockchain/consensusvectors"
+	"github.com/pkt-cash/pktd/chaincfg/globalcfg"
+	"github.com/pkt-cash/pktd/database"
+)
+
+const blockDbNamePrefix = "blocks"
+
+var (
+	cfg *config
+)
+
+// loadBlockDB opens the block database and returns a handle to it.
+func loadBlockDB() (database.DB, error) {
+	// The database name is based on the database type.
+	dbName := blockDbNamePrefix + "_" + cfg.DbType
+	dbPath := filepath.Join(cfg.DataDir, dbName)
+	fmt.Fprintf(os.Stderr, "Loading block database from '%s'\n", dbPath)
+	db, err := database.Open(cfg.DbType, dbPath, activeNetParams.Net)
+	if err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// check checks the corpus in the configured file and returns the number of
+// vectors whose result differs from the expected one.
+func check() (int, error) {
+	f, err := os.Open(cfg.Check)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	c, err := consensusvectors.Read(f)
+	if err != nil {
+		return 0, err
+	}
+
+	failures, err := consensusvectors.Run(&activeNetParams, c)
+	if err != nil {
+		return 0, err
+	}
+	for i := range failures {
+		fmt.Println(failures[i].Error())
+	}
+	fmt.Printf("Checked %d headers, %d compact difficulties, %d "+
+		"transitions, %d effective targets, %d proofs and %d scripts: "+
+		"%d failures\n", len(c.Headers), len(c.Compact),
+		len(c.Transitions), len(c.EffectiveTargets), len(c.Proofs),
+		len(c.Scripts), len(failures))
+	return len(failures), nil
+}
+
+// generate generates a corpus from the main chain of the block database and
+// writes it to the configured output.
+func generate() error {
+	db, err := loadBlockDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	chain, err := blockchain.New(&blockchain.Config{
+		DB:          db,
+		ChainParams: &activeNetParams,
+		TimeSource:  blockchain.NewMedianTime(),
+	})
+	if err != nil {
+		return err
+	}
+	end := cfg.End
+	if best := chain.BestSnapshot(); end < 0 || end > best.Height {
+		end = best.Height
+	}
+
+	g := consensusvectors.NewGenerator(&activeNetParams)
+	err = g.AddChain(chain, cfg.Start, end, cfg.MaxScripts)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if cfg.Out != "" {
+		f, err := os.Create(cfg.Out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return g.Corpus().Write(w)
+}
+
+func main() {
+	// Load configuration and parse command line.
+	tcfg, _, err := loadConfig()
+	if err != nil {
+		os.Exit(1)
+	}
+	cfg = tcfg
+
+	// Blocks are decoded according to the proof of work of the network,
+	// and the chain needs the proof of work limit as a big integer.
+	globalcfg.SelectConfig(activeNetParams.GlobalConf)
+	if activeNetParams.PowLimit == nil {
+		activeNetParams.PowLimit = blockchain.CompactToBig(
+			activeNetParams.PowLimitBits)
+	}
+
+	if cfg.Check != "" {
+		numFailures, err := check()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "failed to check the corpus:", err)
+			os.Exit(1)
+		}
+		if numFailures > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := generate(); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to generate the corpus:", err)
+		os.Exit(1)
+	}
+}
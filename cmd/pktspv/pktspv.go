@@ -0,0 +1,121 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// +build js,wasm
+
+// Command pktspv exposes the light client verification code of pktd to
+// JavaScript.  Once the WebAssembly module is instantiated, the functions are
+// available as the methods of the global pktspv object:
+//
+//	pktspv.target(bits)
+//	pktspv.work(bits)
+//	pktspv.effectiveTarget(bits, minAnnBits, annCount)
+//	pktspv.verifyTxOutProof(hex)
+//	pktspv.verifyAnnProof(hex)
+//	pktspv.newHeaderChain(network, hash, height, bits)
+//
+// The functions which can fail return an object with an error property
+// instead of their result.  The header chain returned by newHeaderChain has
+// the addHeader(hex), tipHash(), tipHeight() and work() methods, addHeader
+// returning the error or null.
+package main
+
+import (
+	"syscall/js"
+
+	"github.com/pkt-cash/pktd/spv/pktspv"
+)
+
+// jsError returns the object returned to JavaScript for the passed error.
+func jsError(err error) interface{} {
+	return map[string]interface{}{"error": err.Error()}
+}
+
+// headerChain returns the JavaScript object wrapping the passed header chain.
+func headerChain(c *pktspv.HeaderChain) interface{} {
+	return map[string]interface{}{
+		"addHeader": js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			if err := c.AddHeader(args[0].String()); err != nil {
+				return err.Error()
+			}
+			return nil
+		}),
+		"tipHash": js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			return c.TipHash()
+		}),
+		"tipHeight": js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			return c.TipHeight()
+		}),
+		"work": js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			return c.Work()
+		}),
+	}
+}
+
+func main() {
+	js.Global().Set("pktspv", map[string]interface{}{
+		"target": js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			return pktspv.Target(int64(args[0].Int()))
+		}),
+		"work": js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			return pktspv.Work(int64(args[0].Int()))
+		}),
+		"effectiveTarget": js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			return pktspv.EffectiveTarget(int64(args[0].Int()),
+				int64(args[1].Int()), int64(args[2].Int()))
+		}),
+		"verifyTxOutProof": js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			proof, err := pktspv.VerifyTxOutProof(args[0].String())
+			if err != nil {
+				return jsError(err)
+			}
+			txns := make([]interface{}, 0, proof.NumTx())
+			for i := 0; i < proof.NumTx(); i++ {
+				txns = append(txns, map[string]interface{}{
+					"txid":  proof.TxID(i),
+					"index": proof.TxIndex(i),
+				})
+			}
+			return map[string]interface{}{
+				"blockHash":         proof.BlockHash,
+				"previousBlockHash": proof.PrevBlockHash,
+				"merkleRoot":        proof.MerkleRoot,
+				"time":              proof.Time,
+				"bits":              proof.Bits,
+				"tx":                txns,
+			}
+		}),
+		"verifyAnnProof": js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			proof, err := pktspv.VerifyAnnProof(args[0].String())
+			if err != nil {
+				return jsError(err)
+			}
+			return map[string]interface{}{
+				"blockHash":    proof.BlockHash,
+				"blockHeight":  proof.BlockHeight,
+				"ann":          proof.Ann,
+				"annHash":      proof.AnnHash,
+				"parentHeight": proof.ParentHeight,
+				"workBits":     proof.WorkBits,
+				"signingKey":   proof.SigningKey,
+				"agedBits":     proof.AgedBits,
+				"annMinBits":   proof.AnnMinBits,
+				"annCount":     proof.AnnCount,
+			}
+		}),
+		"newHeaderChain": js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			c, err := pktspv.NewHeaderChain(args[0].String(),
+				args[1].String(), int32(args[2].Int()),
+				int64(args[3].Int()))
+			if err != nil {
+				return jsError(err)
+			}
+			return headerChain(c)
+		}),
+	})
+
+	// The functions are called from JavaScript as long as the program
+	// runs.
+	select {}
+}
@@ -0,0 +1,17 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// +build !js !wasm
+
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func main() {
+	fmt.Fprintln(os.Stderr, "pktspv must be built with GOOS=js GOARCH=wasm")
+	os.Exit(1)
+}
@@ -0,0 +1,212 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package coinjoin
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+	"sync"
+
+	"github.com/pkt-cash/pktd/btcec"
+)
+
+// blindTag is hashed with the nonce and the message of a blind signature so
+// the challenges of this scheme can't be confused with any other hash.
+const blindTag = "pktd/coinjoin/blind"
+
+// BlindSignatureSize is the size of a serialized blind signature.
+const BlindSignatureSize = 33 + 32
+
+var (
+	// ErrNonceUsed is returned when a signer nonce is used twice.  Signing
+	// two challenges with the same nonce would reveal the private key.
+	ErrNonceUsed = errors.New("signer nonce already used")
+
+	// ErrBadBlindSignature is returned when a blind signature is not
+	// valid.
+	ErrBadBlindSignature = errors.New("invalid blind signature")
+)
+
+var curve = btcec.S256()
+
+// randScalar returns a random scalar in [1, N).
+func randScalar() (*big.Int, error) {
+	for {
+		k, err := rand.Int(rand.Reader, curve.N)
+		if err != nil {
+			return nil, err
+		}
+		if k.Sign() != 0 {
+			return k, nil
+		}
+	}
+}
+
+// challenge returns the challenge of the message signed with the passed
+// nonce point.
+func challenge(r *btcec.PublicKey, msg []byte) *big.Int {
+	h := sha256.New()
+	h.Write([]byte(blindTag))
+	h.Write(r.SerializeCompressed())
+	h.Write(msg)
+	c := new(big.Int).SetBytes(h.Sum(nil))
+	return c.Mod(c, curve.N)
+}
+
+// Signer makes the blind signatures of a coordinator.  The scheme is a blind
+// Schnorr signature over secp256k1: the signer never learns the message it
+// signs nor can it link the signature to the request it answered.
+type Signer struct {
+	key *btcec.PrivateKey
+}
+
+// NewSigner returns a signer using the passed private key.
+func NewSigner(key *btcec.PrivateKey) *Signer {
+	return &Signer{key: key}
+}
+
+// PubKey returns the public key the signatures are verified with.
+func (s *Signer) PubKey() *btcec.PublicKey {
+	return s.key.PubKey()
+}
+
+// Nonce is a single use nonce of a signer.  The point R is sent to the
+// requester, which needs it to blind its message.
+type Nonce struct {
+	R *btcec.PublicKey
+
+	mtx sync.Mutex
+	k   *big.Int
+}
+
+// NewNonce returns a new nonce of the signer.
+func (s *Signer) NewNonce() (*Nonce, error) {
+	k, err := randScalar()
+	if err != nil {
+		return nil, err
+	}
+	x, y := curve.ScalarBaseMult(k.Bytes())
+	return &Nonce{R: &btcec.PublicKey{Curve: curve, X: x, Y: y}, k: k}, nil
+}
+
+// Sign signs the blinded challenge of a requester with the passed nonce,
+// which can't be used again.
+func (s *Signer) Sign(nonce *Nonce, c *big.Int) (*big.Int, error) {
+	nonce.mtx.Lock()
+	k := nonce.k
+	nonce.k = nil
+	nonce.mtx.Unlock()
+	if k == nil {
+		return nil, ErrNonceUsed
+	}
+
+	// s = k - c*x
+	sig := new(big.Int).Mul(c, s.key.D)
+	sig.Sub(k, sig)
+	return sig.Mod(sig, curve.N), nil
+}
+
+// BlindSignature is the signature of a message, unblinded by the requester.
+type BlindSignature struct {
+	R *btcec.PublicKey
+	S *big.Int
+}
+
+// Serialize returns the compressed nonce point followed by the scalar.
+func (sig *BlindSignature) Serialize() []byte {
+	b := make([]byte, BlindSignatureSize)
+	copy(b, sig.R.SerializeCompressed())
+	s := sig.S.Bytes()
+	copy(b[BlindSignatureSize-len(s):], s)
+	return b
+}
+
+// ParseBlindSignature parses a serialized blind signature.
+func ParseBlindSignature(b []byte) (*BlindSignature, error) {
+	if len(b) != BlindSignatureSize {
+		return nil, ErrBadBlindSignature
+	}
+	r, err := btcec.ParsePubKey(b[:33], curve)
+	if err != nil {
+		return nil, ErrBadBlindSignature
+	}
+	s := new(big.Int).SetBytes(b[33:])
+	if s.Cmp(curve.N) >= 0 {
+		return nil, ErrBadBlindSignature
+	}
+	return &BlindSignature{R: r, S: s}, nil
+}
+
+// Verify returns whether sig is a signature of msg by the passed public key.
+func (sig *BlindSignature) Verify(pub *btcec.PublicKey, msg []byte) bool {
+	if sig.R == nil || sig.S == nil || sig.S.Sign() < 0 ||
+		sig.S.Cmp(curve.N) >= 0 {
+
+		return false
+	}
+
+	// s*G + c*P == R
+	c := challenge(sig.R, msg)
+	x1, y1 := curve.ScalarBaseMult(sig.S.Bytes())
+	x2, y2 := curve.ScalarMult(pub.X, pub.Y, c.Bytes())
+	x, y := curve.Add(x1, y1, x2, y2)
+	return x.Cmp(sig.R.X) == 0 && y.Cmp(sig.R.Y) == 0
+}
+
+// Blinder holds the secret factors a requester blinds its message with, to
+// unblind the signature of the signer.
+type Blinder struct {
+	pub *btcec.PublicKey
+	r   *btcec.PublicKey
+	a   *big.Int
+	msg []byte
+}
+
+// Blind blinds msg for the signer with the passed public key and nonce point.
+// It returns the blinder and the blinded challenge to have signed.
+func Blind(pub, nonce *btcec.PublicKey, msg []byte) (*Blinder, *big.Int, error) {
+	for {
+		a, err := randScalar()
+		if err != nil {
+			return nil, nil, err
+		}
+		b, err := randScalar()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// R' = R + a*G - b*P
+		ax, ay := curve.ScalarBaseMult(a.Bytes())
+		bx, by := curve.ScalarMult(pub.X, pub.Y, b.Bytes())
+		by.Sub(curve.P, by)
+		x, y := curve.Add(nonce.X, nonce.Y, ax, ay)
+		x, y = curve.Add(x, y, bx, by)
+		if x.Sign() == 0 && y.Sign() == 0 {
+			continue
+		}
+		r := &btcec.PublicKey{Curve: curve, X: x, Y: y}
+
+		// c = c' + b
+		c := challenge(r, msg)
+		blinded := new(big.Int).Add(c, b)
+		blinded.Mod(blinded, curve.N)
+		return &Blinder{pub: pub, r: r, a: a, msg: msg}, blinded, nil
+	}
+}
+
+// Unblind returns the signature of the message from the signature of the
+// blinded challenge.
+func (b *Blinder) Unblind(s *big.Int) (*BlindSignature, error) {
+	// s' = s + a
+	unblinded := new(big.Int).Add(s, b.a)
+	unblinded.Mod(unblinded, curve.N)
+	sig := &BlindSignature{R: b.r, S: unblinded}
+	if !sig.Verify(b.pub, b.msg) {
+		return nil, ErrBadBlindSignature
+	}
+	return sig, nil
+}
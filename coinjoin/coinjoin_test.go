@@ -0,0 +1,314 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package coinjoin
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/btcec"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/txauthor"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// testWallet signs pay-to-witness-pubkey-hash inputs with its keys.
+type testWallet struct {
+	keys map[string]*btcec.PrivateKey
+}
+
+// newScript returns a new pay-to-witness-pubkey-hash script of the wallet.
+func (w *testWallet) newScript(t *testing.T) []byte {
+	key, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatalf("NewPrivateKey: %v", err)
+	}
+	hash := btcutil.Hash160(key.PubKey().SerializeCompressed())
+	pkScript, err := txscript.NewScriptBuilder().AddOp(txscript.OP_0).
+		AddData(hash).Script()
+	if err != nil {
+		t.Fatalf("Script: %v", err)
+	}
+	w.keys[string(pkScript)] = key
+	return pkScript
+}
+
+func (w *testWallet) SignInput(tx *wire.MsgTx, index int, prevOut *wire.TxOut) error {
+	key, ok := w.keys[string(prevOut.PkScript)]
+	if !ok {
+		return errors.New("unknown script")
+	}
+	p2pkh, err := txscript.NewScriptBuilder().AddOp(txscript.OP_DUP).
+		AddOp(txscript.OP_HASH160).AddData(prevOut.PkScript[2:]).
+		AddOp(txscript.OP_EQUALVERIFY).AddOp(txscript.OP_CHECKSIG).
+		Script()
+	if err != nil {
+		return err
+	}
+	tx.TxIn[index].Witness, err = txscript.WitnessSignature(tx,
+		txscript.NewTxSigHashes(tx), index, prevOut.Value, p2pkh,
+		txscript.SigHashAll, key, true)
+	return err
+}
+
+// TestBlindSignature ensures unblinded signatures verify for their message
+// only and nonces can't be reused.
+func TestBlindSignature(t *testing.T) {
+	key, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatalf("NewPrivateKey: %v", err)
+	}
+	signer := NewSigner(key)
+	nonce, err := signer.NewNonce()
+	if err != nil {
+		t.Fatalf("NewNonce: %v", err)
+	}
+	msg := []byte("output script")
+	blinder, blinded, err := Blind(signer.PubKey(), nonce.R, msg)
+	if err != nil {
+		t.Fatalf("Blind: %v", err)
+	}
+	s, err := signer.Sign(nonce, blinded)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	if _, err := signer.Sign(nonce, blinded); err != ErrNonceUsed {
+		t.Fatalf("Sign: got %v, want %v", err, ErrNonceUsed)
+	}
+	sig, err := blinder.Unblind(s)
+	if err != nil {
+		t.Fatalf("Unblind: %v", err)
+	}
+
+	// The signature does not reveal the nonce the signer used.
+	if sig.R.IsEqual(nonce.R) {
+		t.Fatal("signature nonce is not blinded")
+	}
+	parsed, err := ParseBlindSignature(sig.Serialize())
+	if err != nil {
+		t.Fatalf("ParseBlindSignature: %v", err)
+	}
+	if !parsed.Verify(signer.PubKey(), msg) {
+		t.Fatal("signature does not verify")
+	}
+	if parsed.Verify(signer.PubKey(), []byte("other script")) {
+		t.Fatal("signature verifies for another message")
+	}
+	other, _ := btcec.NewPrivateKey(btcec.S256())
+	if parsed.Verify(other.PubKey(), msg) {
+		t.Fatal("signature verifies for another key")
+	}
+	if _, err := blinder.Unblind(new(big.Int).Add(s, big.NewInt(1))); err !=
+		ErrBadBlindSignature {
+
+		t.Fatalf("Unblind: got %v, want %v", err, ErrBadBlindSignature)
+	}
+}
+
+// TestRound runs a round between three participants and ensures the
+// transaction is completed, pays every participant its mixed output and
+// change, and that the coordinator rejects invalid requests.
+func TestRound(t *testing.T) {
+	key, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatalf("NewPrivateKey: %v", err)
+	}
+	wallet := &testWallet{keys: make(map[string]*btcec.PrivateKey)}
+	params := &Params{
+		Denomination:      1e9,
+		MinParticipants:   3,
+		MaxParticipants:   3,
+		FeeRate:           1000,
+		DustLimit:         1000,
+		CoordinatorFee:    5000,
+		CoordinatorScript: wallet.newScript(t),
+	}
+	utxos := make(map[wire.OutPoint]*wire.TxOut)
+	fetch := func(op wire.OutPoint) (*wire.TxOut, error) {
+		if txOut, ok := utxos[op]; ok {
+			return txOut, nil
+		}
+		return nil, fmt.Errorf("%v is not unspent", op)
+	}
+	round, err := NewRound(params, NewSigner(key), fetch)
+	if err != nil {
+		t.Fatalf("NewRound: %v", err)
+	}
+
+	var participants []*Participant
+	for i, amounts := range [][]btcutil.Amount{
+		{1.5e9}, {0.6e9, 0.6e9}, {1e9 + 6000},
+	} {
+		var inputs []*txauthor.Input
+		for j, amount := range amounts {
+			input := &txauthor.Input{
+				OutPoint: wire.OutPoint{
+					Hash:  chainhash.Hash{byte(i + 1)},
+					Index: uint32(j),
+				},
+				Amount:   amount,
+				PkScript: wallet.newScript(t),
+			}
+			utxos[input.OutPoint] = wire.NewTxOut(int64(amount),
+				input.PkScript)
+			inputs = append(inputs, input)
+		}
+		participants = append(participants, NewParticipant(round.ID(),
+			params, round.SignerKey(), wallet, inputs,
+			wallet.newScript(t), wallet.newScript(t)))
+	}
+
+	for _, p := range participants {
+		nonce, err := round.Nonce()
+		if err != nil {
+			t.Fatalf("Nonce: %v", err)
+		}
+		reg, err := p.InputRegistration(nonce)
+		if err != nil {
+			t.Fatalf("InputRegistration: %v", err)
+		}
+		id, blindSig, err := round.RegisterInputs(reg)
+		if err != nil {
+			t.Fatalf("RegisterInputs: %v", err)
+		}
+		if err := p.Registered(id, blindSig); err != nil {
+			t.Fatalf("Registered: %v", err)
+		}
+
+		// The registration can't be replayed.
+		if _, _, err := round.RegisterInputs(reg); err != ErrRoundFull &&
+			err != ErrUnknownNonce {
+
+			t.Fatalf("RegisterInputs replay: got %v", err)
+		}
+	}
+	if _, err := round.Nonce(); err != nil {
+		t.Fatalf("Nonce: %v", err)
+	}
+	if err := round.EndInputRegistration(); err != nil {
+		t.Fatalf("EndInputRegistration: %v", err)
+	}
+
+	// Outputs need a signature of the coordinator.
+	script, sig := participants[0].OutputRegistration()
+	if err := round.RegisterOutput(wallet.newScript(t), sig); err !=
+		ErrBadBlindSignature {
+
+		t.Fatalf("RegisterOutput: got %v, want %v", err,
+			ErrBadBlindSignature)
+	}
+	for _, p := range participants {
+		if err := round.RegisterOutput(p.OutputRegistration()); err != nil {
+			t.Fatalf("RegisterOutput: %v", err)
+		}
+	}
+	if err := round.RegisterOutput(script, sig); err != ErrOutputRegistered {
+		t.Fatalf("RegisterOutput: got %v, want %v", err,
+			ErrOutputRegistered)
+	}
+	tx, err := round.EndOutputRegistration()
+	if err != nil {
+		t.Fatalf("EndOutputRegistration: %v", err)
+	}
+
+	// Four inputs, three mixed outputs, two change outputs as the change
+	// of the last participant is dust, and the coordinator fee.
+	if len(tx.TxIn) != 4 || len(tx.TxOut) != 6 {
+		t.Fatalf("unexpected transaction with %d inputs and %d outputs",
+			len(tx.TxIn), len(tx.TxOut))
+	}
+	var in, out int64
+	for _, txIn := range tx.TxIn {
+		in += utxos[txIn.PreviousOutPoint].Value
+	}
+	for _, txOut := range tx.TxOut {
+		out += txOut.Value
+	}
+	sizes := make([]txauthor.InputSize, len(tx.TxIn))
+	for i := range sizes {
+		sizes[i] = txauthor.InputSize{Witness: 108}
+	}
+	vsize := txauthor.EstimateVirtualSize(tx, sizes)
+	if fee := in - out; fee < int64(vsize) {
+		t.Fatalf("fee %d is below the fee rate for %d vbytes", fee,
+			vsize)
+	}
+
+	// A participant signing only after another one has still signed its
+	// own inputs only, and the round completes with the last one.
+	if err := round.Sign(participants[0].ID(), tx); err == nil {
+		t.Fatal("Sign: unexpected success without signatures")
+	}
+	for i, p := range participants {
+		signed := round.Transaction()
+		if err := p.SignTransaction(signed); err != nil {
+			t.Fatalf("SignTransaction: %v", err)
+		}
+		if err := round.Sign(p.ID(), signed); err != nil {
+			t.Fatalf("Sign: %v", err)
+		}
+		if phase := round.Phase(); (phase == PhaseCompleted) !=
+			(i == len(participants)-1) {
+
+			t.Fatalf("unexpected phase %v after %d signers", phase, i+1)
+		}
+	}
+	signed := round.Transaction()
+	for i, txIn := range signed.TxIn {
+		prevOut := utxos[txIn.PreviousOutPoint]
+		err := txscript.VerifyScript(prevOut.PkScript, signed, i,
+			prevOut.Value, txscript.StandardVerifyFlags, nil,
+			txscript.NewTxSigHashes(signed))
+		if err != nil {
+			t.Fatalf("input %d: %v", i, err)
+		}
+	}
+
+	// A participant refuses a transaction not paying its mixed output.
+	for _, txOut := range tx.TxOut {
+		if string(txOut.PkScript) == string(script) {
+			txOut.Value--
+		}
+	}
+	if err := participants[0].SignTransaction(tx); err != ErrBadTransaction {
+		t.Fatalf("SignTransaction: got %v, want %v", err,
+			ErrBadTransaction)
+	}
+}
+
+// TestRoundFailure ensures a round without enough participants fails.
+func TestRoundFailure(t *testing.T) {
+	key, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatalf("NewPrivateKey: %v", err)
+	}
+	params := &Params{Denomination: 1e9, MinParticipants: 2,
+		MaxParticipants: 5}
+	round, err := NewRound(params, NewSigner(key), nil)
+	if err != nil {
+		t.Fatalf("NewRound: %v", err)
+	}
+	if err := round.RegisterOutput(nil, &BlindSignature{}); err !=
+		ErrWrongPhase {
+
+		t.Fatalf("RegisterOutput: got %v, want %v", err, ErrWrongPhase)
+	}
+	if err := round.EndInputRegistration(); err != ErrNotEnoughParticipants {
+		t.Fatalf("EndInputRegistration: got %v, want %v", err,
+			ErrNotEnoughParticipants)
+	}
+	if phase := round.Phase(); phase != PhaseFailed {
+		t.Fatalf("unexpected phase %v", phase)
+	}
+	if _, err := NewRound(&Params{Denomination: 1e9, MinParticipants: 1,
+		MaxParticipants: 1}, NewSigner(key), nil); err == nil {
+
+		t.Fatal("NewRound: unexpected success with a single participant")
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package coinjoin implements the coordination of coinjoin rounds, where several
+participants spend their outputs in a single transaction paying the same
+amount to a fresh output of each of them, so the mixed outputs can't be linked
+to the inputs which funded them.
+
+A round goes through three phases.  During input registration, a participant
+proves it owns the inputs it spends with a reserveproof proof and has its
+mixed output script blindly signed by the coordinator.  During output
+registration, it registers the unblinded output and signature over a
+connection which can't be linked to the first one, such as a new Tor circuit.
+The coordinator only learns that the output belongs to some participant.
+Finally, the coordinator assembles the transaction, sorted as described by BIP
+0069, and every participant signs its inputs once it has checked the
+transaction pays it.  The signatures are verified with the script engine so
+the completed transaction is valid under the consensus rules.
+
+The blind signatures are Schnorr signatures over secp256k1 with the nonce and
+challenge blinded by the requester.  Each nonce of the coordinator is only
+used once.
+
+Every participant pays the denomination, the fee of its inputs and outputs at
+the fee rate of the round, and the coordinator fee.  The rest is paid back to
+its change script, unless it is below the dust limit.
+
+Round is the coordinator side and Participant the side of a wallet, which
+signs through the Wallet interface.
+*/
+package coinjoin
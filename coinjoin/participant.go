@@ -0,0 +1,174 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package coinjoin
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/btcec"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/reserveproof"
+	"github.com/pkt-cash/pktd/txauthor"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// ErrBadTransaction is returned by a participant asked to sign a round
+// transaction which does not spend its inputs or pay its outputs.
+var ErrBadTransaction = errors.New("round transaction does not pay the " +
+	"participant")
+
+// Wallet is the wallet of a participant.
+type Wallet interface {
+	// SignInput signs the input at the passed index of tx, which spends
+	// prevOut, with SIGHASH_ALL by setting its signature script and
+	// witness.
+	SignInput(tx *wire.MsgTx, index int, prevOut *wire.TxOut) error
+}
+
+// Participant runs the client side of a round for a wallet: it blinds its
+// mixed output, proves the ownership of its inputs and only signs a round
+// transaction paying it what it is owed.
+type Participant struct {
+	wallet       Wallet
+	roundID      chainhash.Hash
+	params       Params
+	signerKey    *btcec.PublicKey
+	inputs       []*txauthor.Input
+	changeScript []byte
+	outputScript []byte
+
+	blinder *Blinder
+	change  *wire.TxOut
+	id      chainhash.Hash
+	sig     *BlindSignature
+}
+
+// NewParticipant returns a participant of the round with the passed
+// identifier, parameters and signer key, spending inputs to pay the
+// denomination to outputScript and the change to changeScript.
+func NewParticipant(roundID chainhash.Hash, params *Params, signerKey *btcec.PublicKey,
+	wallet Wallet, inputs []*txauthor.Input, changeScript, outputScript []byte) *Participant {
+
+	return &Participant{
+		wallet:       wallet,
+		roundID:      roundID,
+		params:       *params,
+		signerKey:    signerKey,
+		inputs:       inputs,
+		changeScript: changeScript,
+		outputScript: outputScript,
+	}
+}
+
+// InputRegistration returns the registration of the inputs of the participant
+// with its output blinded with the passed nonce point of the coordinator.
+func (p *Participant) InputRegistration(nonce *btcec.PublicKey) (*InputRegistration, error) {
+	blinder, blinded, err := Blind(p.signerKey, nonce, p.outputScript)
+	if err != nil {
+		return nil, err
+	}
+
+	outPoints := make([]wire.OutPoint, len(p.inputs))
+	var total btcutil.Amount
+	for i, input := range p.inputs {
+		outPoints[i] = input.OutPoint
+		total += input.Amount
+	}
+	proof := reserveproof.New(ProofMessage(p.roundID, blinded), outPoints,
+		total)
+	for i, input := range p.inputs {
+		prevOut := wire.NewTxOut(int64(input.Amount), input.PkScript)
+		if err := p.wallet.SignInput(proof, i+1, prevOut); err != nil {
+			return nil, err
+		}
+	}
+
+	// The change is computed the way the coordinator does, to check the
+	// round transaction pays it.
+	p.change, err = changeOutput(&p.params, total, proofSizes(proof),
+		p.changeScript)
+	if err != nil {
+		return nil, err
+	}
+	p.blinder = blinder
+	return &InputRegistration{
+		Proof:        proof,
+		ChangeScript: p.changeScript,
+		Nonce:        nonce,
+		Blinded:      blinded,
+	}, nil
+}
+
+// Registered records the reply of the coordinator to the input registration:
+// the identifier of the participant and the blind signature of its output.
+func (p *Participant) Registered(id chainhash.Hash, blindSig *big.Int) error {
+	if p.blinder == nil {
+		return errors.New("inputs not registered")
+	}
+	sig, err := p.blinder.Unblind(blindSig)
+	if err != nil {
+		return err
+	}
+	p.id = id
+	p.sig = sig
+	return nil
+}
+
+// ID returns the identifier of the participant in the round.
+func (p *Participant) ID() chainhash.Hash {
+	return p.id
+}
+
+// OutputRegistration returns the mixed output script and its signature by the
+// coordinator.  They must be registered over a connection which can't be
+// linked to the one the inputs were registered with.
+func (p *Participant) OutputRegistration() ([]byte, *BlindSignature) {
+	return p.outputScript, p.sig
+}
+
+// SignTransaction signs the inputs of the participant in the round
+// transaction, after checking the transaction spends all of them and pays the
+// mixed output and the change.
+func (p *Participant) SignTransaction(tx *wire.MsgTx) error {
+	hasOutput := false
+	hasChange := false
+	for _, txOut := range tx.TxOut {
+		switch {
+		case txOut.Value == int64(p.params.Denomination) &&
+			bytes.Equal(txOut.PkScript, p.outputScript):
+
+			hasOutput = true
+
+		case p.change != nil && txOut.Value >= p.change.Value &&
+			bytes.Equal(txOut.PkScript, p.change.PkScript):
+
+			hasChange = true
+		}
+	}
+	if !hasOutput || (p.change != nil && !hasChange) {
+		return ErrBadTransaction
+	}
+
+	indexes := make(map[wire.OutPoint]int, len(tx.TxIn))
+	for i, txIn := range tx.TxIn {
+		indexes[txIn.PreviousOutPoint] = i
+	}
+	for _, input := range p.inputs {
+		if _, ok := indexes[input.OutPoint]; !ok {
+			return ErrBadTransaction
+		}
+	}
+	for _, input := range p.inputs {
+		prevOut := wire.NewTxOut(int64(input.Amount), input.PkScript)
+		err := p.wallet.SignInput(tx, indexes[input.OutPoint], prevOut)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
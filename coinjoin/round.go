@@ -0,0 +1,549 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package coinjoin
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/btcec"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/reserveproof"
+	"github.com/pkt-cash/pktd/txauthor"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// Phase is the phase of a round.
+type Phase int
+
+const (
+	// PhaseInputRegistration is the phase where the participants register
+	// their inputs and have their blinded output signed.
+	PhaseInputRegistration Phase = iota
+
+	// PhaseOutputRegistration is the phase where the participants register
+	// their unblinded output, over a connection unlinkable to the one
+	// they registered their inputs with.
+	PhaseOutputRegistration
+
+	// PhaseSigning is the phase where the participants sign their inputs
+	// of the transaction.
+	PhaseSigning
+
+	// PhaseCompleted is the phase of a round whose transaction is fully
+	// signed.
+	PhaseCompleted
+
+	// PhaseFailed is the phase of a round which was aborted.
+	PhaseFailed
+)
+
+// String returns the phase as a human-readable string.
+func (p Phase) String() string {
+	switch p {
+	case PhaseInputRegistration:
+		return "inputregistration"
+	case PhaseOutputRegistration:
+		return "outputregistration"
+	case PhaseSigning:
+		return "signing"
+	case PhaseCompleted:
+		return "completed"
+	case PhaseFailed:
+		return "failed"
+	}
+	return fmt.Sprintf("Phase(%d)", int(p))
+}
+
+var (
+	// ErrWrongPhase is returned when a request is not valid in the current
+	// phase of the round.
+	ErrWrongPhase = errors.New("request not valid in this phase of the " +
+		"round")
+
+	// ErrRoundFull is returned when an input registration would exceed
+	// the maximum number of participants.
+	ErrRoundFull = errors.New("round is full")
+
+	// ErrUnknownNonce is returned when a registration uses a nonce the
+	// coordinator did not hand out or already used.
+	ErrUnknownNonce = errors.New("unknown or used nonce")
+
+	// ErrInputRegistered is returned when an input is already registered
+	// in the round.
+	ErrInputRegistered = errors.New("input already registered")
+
+	// ErrInsufficientFunds is returned when the inputs of a participant do
+	// not cover the denomination and the fees.
+	ErrInsufficientFunds = errors.New("inputs do not cover the " +
+		"denomination and fees")
+
+	// ErrNonStandardScript is returned when an output script is not
+	// standard.
+	ErrNonStandardScript = errors.New("non-standard output script")
+
+	// ErrOutputRegistered is returned when an output is registered twice.
+	ErrOutputRegistered = errors.New("output already registered")
+
+	// ErrNotEnoughParticipants is returned when input registration ends
+	// with fewer participants than the minimum.
+	ErrNotEnoughParticipants = errors.New("not enough participants")
+
+	// ErrMissingOutputs is returned when output registration ends with
+	// fewer outputs than participants.
+	ErrMissingOutputs = errors.New("outputs missing")
+
+	// ErrUnknownParticipant is returned for requests naming a participant
+	// which is not registered in the round.
+	ErrUnknownParticipant = errors.New("unknown participant")
+
+	// ErrBadInputSignature is returned when a participant signs its inputs
+	// invalidly.
+	ErrBadInputSignature = errors.New("invalid input signature")
+)
+
+// Params are the parameters of a round.
+type Params struct {
+	// Denomination is the value of the mixed outputs.
+	Denomination btcutil.Amount
+
+	// MinParticipants and MaxParticipants bound the number of participants
+	// of the round.
+	MinParticipants int
+	MaxParticipants int
+
+	// MaxInputs is the maximum number of inputs of a participant, no limit
+	// when zero.
+	MaxInputs int
+
+	// FeeRate is the fee paid per kilobyte of virtual size.  Each
+	// participant pays for its inputs and outputs.
+	FeeRate btcutil.Amount
+
+	// DustLimit is the smallest change output.  Smaller change is left to
+	// the miners.
+	DustLimit btcutil.Amount
+
+	// CoordinatorFee is paid by every participant to CoordinatorScript.
+	CoordinatorFee    btcutil.Amount
+	CoordinatorScript []byte
+}
+
+// ProofMessage returns the message of the ownership proof of the inputs a
+// participant registers with the passed blinded challenge, so the proof can't
+// be replayed in another registration.
+func ProofMessage(roundID chainhash.Hash, blinded *big.Int) string {
+	return fmt.Sprintf("coinjoin %v %064x", roundID, blinded)
+}
+
+// InputRegistration is the registration of the inputs of a participant.
+type InputRegistration struct {
+	// Proof is a reserveproof proof, with ProofMessage as its message,
+	// that the participant owns the inputs it spends.
+	Proof *wire.MsgTx
+
+	// ChangeScript is the script the change of the participant is paid
+	// to.
+	ChangeScript []byte
+
+	// Nonce is the nonce point handed out by the coordinator and Blinded
+	// the challenge blinded with it.
+	Nonce   *btcec.PublicKey
+	Blinded *big.Int
+}
+
+// participant is a participant registered in a round.
+type participant struct {
+	inputs   []wire.OutPoint
+	prevOuts []*wire.TxOut
+	change   *wire.TxOut
+	signed   bool
+}
+
+// Round is a round of a coordinator, which assembles the transaction mixing
+// the outputs of its participants.  All of its methods are safe for
+// concurrent access.
+type Round struct {
+	id     chainhash.Hash
+	params Params
+	signer *Signer
+	fetch  reserveproof.FetchFunc
+
+	mtx          sync.Mutex
+	phase        Phase
+	nonces       map[[33]byte]*Nonce
+	participants map[chainhash.Hash]*participant
+	inputs       map[wire.OutPoint]chainhash.Hash
+	outputs      [][]byte
+	outputSet    map[string]struct{}
+	tx           *wire.MsgTx
+}
+
+// NewRound returns a round with a random identifier in its input
+// registration phase.  Its blind signatures are made by signer and the
+// registered inputs are looked up with fetch, which must fail for outputs
+// which are spent or otherwise not acceptable.
+func NewRound(params *Params, signer *Signer, fetch reserveproof.FetchFunc) (*Round, error) {
+	if params.Denomination <= 0 || params.MinParticipants < 2 ||
+		params.MaxParticipants < params.MinParticipants {
+
+		return nil, errors.New("invalid round parameters")
+	}
+	r := &Round{
+		params:       *params,
+		signer:       signer,
+		fetch:        fetch,
+		nonces:       make(map[[33]byte]*Nonce),
+		participants: make(map[chainhash.Hash]*participant),
+		inputs:       make(map[wire.OutPoint]chainhash.Hash),
+		outputSet:    make(map[string]struct{}),
+	}
+	if _, err := rand.Read(r.id[:]); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// ID returns the identifier of the round.
+func (r *Round) ID() chainhash.Hash {
+	return r.id
+}
+
+// Params returns the parameters of the round.
+func (r *Round) Params() Params {
+	return r.params
+}
+
+// SignerKey returns the public key of the blind signatures of the round.
+func (r *Round) SignerKey() *btcec.PublicKey {
+	return r.signer.PubKey()
+}
+
+// Phase returns the current phase of the round.
+func (r *Round) Phase() Phase {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return r.phase
+}
+
+// Participants returns the number of participants registered in the round.
+func (r *Round) Participants() int {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return len(r.participants)
+}
+
+// Nonce returns a new nonce point for a participant to blind its output
+// with.
+func (r *Round) Nonce() (*btcec.PublicKey, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if r.phase != PhaseInputRegistration {
+		return nil, ErrWrongPhase
+	}
+	nonce, err := r.signer.NewNonce()
+	if err != nil {
+		return nil, err
+	}
+	var key [33]byte
+	copy(key[:], nonce.R.SerializeCompressed())
+	r.nonces[key] = nonce
+	return nonce.R, nil
+}
+
+// vsize returns the virtual size of a transaction input or output of the
+// passed sizes.
+func vsize(baseSize, witnessSize int) int {
+	return (baseSize*4 + witnessSize + 3) / 4
+}
+
+// inputBaseSize returns the size of an input, without its witness.
+func inputBaseSize(size txauthor.InputSize) int {
+	return 32 + 4 + 4 + wire.VarIntSerializeSize(uint64(size.SigScript)) +
+		size.SigScript
+}
+
+// outputSize returns the size of an output paying to a script of the passed
+// length.
+func outputSize(scriptLen int) int {
+	return 8 + wire.VarIntSerializeSize(uint64(scriptLen)) + scriptLen
+}
+
+// participantFee returns the fee a participant pays for the passed inputs, its
+// mixed output and its change output, both assumed to be as large as the
+// change script, along with the coordinator fee.
+func participantFee(params *Params, sizes []txauthor.InputSize, scriptLen int) btcutil.Amount {
+	size := 2 * outputSize(scriptLen)
+	for _, s := range sizes {
+		size += vsize(inputBaseSize(s), s.Witness)
+	}
+	return (params.FeeRate*btcutil.Amount(size)+999)/1000 +
+		params.CoordinatorFee
+}
+
+// proofSizes returns the sizes of the signatures of the inputs of an
+// ownership proof, but the commitment input.
+func proofSizes(proof *wire.MsgTx) []txauthor.InputSize {
+	sizes := make([]txauthor.InputSize, 0, len(proof.TxIn)-1)
+	for _, txIn := range proof.TxIn[1:] {
+		sizes = append(sizes, txauthor.InputSize{
+			SigScript: len(txIn.SignatureScript),
+			Witness:   txIn.Witness.SerializeSize(),
+		})
+	}
+	return sizes
+}
+
+// changeOutput returns the change output of a participant with the passed
+// inputs, or nil when the change is left to the miners.  It fails when the
+// inputs don't cover the denomination and fees.
+func changeOutput(params *Params, total btcutil.Amount, sizes []txauthor.InputSize,
+	changeScript []byte) (*wire.TxOut, error) {
+
+	change := total - params.Denomination -
+		participantFee(params, sizes, len(changeScript))
+	if change < 0 {
+		return nil, ErrInsufficientFunds
+	}
+	if change == 0 || change < params.DustLimit {
+		return nil, nil
+	}
+	return wire.NewTxOut(int64(change), changeScript), nil
+}
+
+// RegisterInputs registers the inputs of a participant and returns its
+// identifier, to sign its inputs with, and the blind signature of its output.
+// The inputs are owned by the participant as proven by the signatures of the
+// proof, whose sizes are used to compute the fee of the participant.
+func (r *Round) RegisterInputs(reg *InputRegistration) (chainhash.Hash, *big.Int, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	var id chainhash.Hash
+	if r.phase != PhaseInputRegistration {
+		return id, nil, ErrWrongPhase
+	}
+	if len(r.participants) >= r.params.MaxParticipants {
+		return id, nil, ErrRoundFull
+	}
+	var key [33]byte
+	copy(key[:], reg.Nonce.SerializeCompressed())
+	nonce, ok := r.nonces[key]
+	if !ok {
+		return id, nil, ErrUnknownNonce
+	}
+	if txscript.GetScriptClass(reg.ChangeScript) == txscript.NonStandardTy {
+		return id, nil, ErrNonStandardScript
+	}
+
+	message := ProofMessage(r.id, reg.Blinded)
+	prevOuts, total, err := reserveproof.Verify(reg.Proof, message, r.fetch)
+	if err != nil {
+		return id, nil, err
+	}
+	p := &participant{prevOuts: prevOuts}
+	for _, txIn := range reg.Proof.TxIn[1:] {
+		if _, ok := r.inputs[txIn.PreviousOutPoint]; ok {
+			return id, nil, ErrInputRegistered
+		}
+		p.inputs = append(p.inputs, txIn.PreviousOutPoint)
+	}
+	if r.params.MaxInputs > 0 && len(p.inputs) > r.params.MaxInputs {
+		return id, nil, fmt.Errorf("at most %d inputs per participant",
+			r.params.MaxInputs)
+	}
+	p.change, err = changeOutput(&r.params, total, proofSizes(reg.Proof),
+		reg.ChangeScript)
+	if err != nil {
+		return id, nil, err
+	}
+
+	blindSig, err := r.signer.Sign(nonce, reg.Blinded)
+	delete(r.nonces, key)
+	if err != nil {
+		return id, nil, err
+	}
+	if _, err := rand.Read(id[:]); err != nil {
+		return id, nil, err
+	}
+	r.participants[id] = p
+	for _, op := range p.inputs {
+		r.inputs[op] = id
+	}
+	return id, blindSig, nil
+}
+
+// EndInputRegistration moves the round to its output registration phase, or
+// fails it when there are not enough participants.
+func (r *Round) EndInputRegistration() error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if r.phase != PhaseInputRegistration {
+		return ErrWrongPhase
+	}
+	r.nonces = nil
+	if len(r.participants) < r.params.MinParticipants {
+		r.phase = PhaseFailed
+		return ErrNotEnoughParticipants
+	}
+	r.phase = PhaseOutputRegistration
+	return nil
+}
+
+// RegisterOutput registers a mixed output, paying the denomination to the
+// passed script which is signed by the coordinator.
+func (r *Round) RegisterOutput(pkScript []byte, sig *BlindSignature) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if r.phase != PhaseOutputRegistration {
+		return ErrWrongPhase
+	}
+	if !sig.Verify(r.signer.PubKey(), pkScript) {
+		return ErrBadBlindSignature
+	}
+	if _, ok := r.outputSet[string(pkScript)]; ok {
+		return ErrOutputRegistered
+	}
+	if txscript.GetScriptClass(pkScript) == txscript.NonStandardTy {
+		return ErrNonStandardScript
+	}
+	r.outputSet[string(pkScript)] = struct{}{}
+	r.outputs = append(r.outputs, pkScript)
+	return nil
+}
+
+// EndOutputRegistration assembles the transaction of the round and moves it
+// to its signing phase.  The round fails when an output is missing, as its
+// value would otherwise be lost to the miners.
+func (r *Round) EndOutputRegistration() (*wire.MsgTx, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if r.phase != PhaseOutputRegistration {
+		return nil, ErrWrongPhase
+	}
+	if len(r.outputs) != len(r.participants) {
+		r.phase = PhaseFailed
+		return nil, ErrMissingOutputs
+	}
+
+	tx := wire.NewMsgTx(wire.TxVersion)
+	for _, p := range r.participants {
+		for i := range p.inputs {
+			tx.AddTxIn(wire.NewTxIn(&p.inputs[i], nil, nil))
+		}
+		if p.change != nil {
+			tx.AddTxOut(wire.NewTxOut(p.change.Value,
+				p.change.PkScript))
+		}
+	}
+	for _, pkScript := range r.outputs {
+		tx.AddTxOut(wire.NewTxOut(int64(r.params.Denomination), pkScript))
+	}
+	coordinatorFee := r.params.CoordinatorFee *
+		btcutil.Amount(len(r.participants))
+	if coordinatorFee > 0 {
+		tx.AddTxOut(wire.NewTxOut(int64(coordinatorFee),
+			r.params.CoordinatorScript))
+	}
+	txauthor.SortBIP69(tx)
+
+	r.tx = tx
+	r.phase = PhaseSigning
+	return tx.Copy(), nil
+}
+
+// Transaction returns the transaction of the round, with the signatures
+// registered so far.  It is nil before the signing phase.
+func (r *Round) Transaction() *wire.MsgTx {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if r.tx == nil {
+		return nil
+	}
+	return r.tx.Copy()
+}
+
+// unsignedHash returns the hash of the passed transaction without its
+// signature scripts.
+func unsignedHash(tx *wire.MsgTx) chainhash.Hash {
+	tx = tx.Copy()
+	for _, txIn := range tx.TxIn {
+		txIn.SignatureScript = nil
+	}
+	return tx.TxHash()
+}
+
+// Sign registers the signed inputs of a participant.  signed is the
+// transaction of the round with at least the inputs of the participant
+// signed.  The round is completed once all the participants signed.
+func (r *Round) Sign(id chainhash.Hash, signed *wire.MsgTx) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if r.phase != PhaseSigning {
+		return ErrWrongPhase
+	}
+	p, ok := r.participants[id]
+	if !ok {
+		return ErrUnknownParticipant
+	}
+	if unsignedHash(signed) != unsignedHash(r.tx) {
+		return errors.New("signed transaction differs from the round " +
+			"transaction")
+	}
+
+	// Verify the signatures on a copy so a bad signature leaves the
+	// transaction untouched.
+	tx := r.tx.Copy()
+	prevOuts := make(map[wire.OutPoint]*wire.TxOut, len(p.inputs))
+	for i, op := range p.inputs {
+		prevOuts[op] = p.prevOuts[i]
+	}
+	var indexes []int
+	for i, txIn := range tx.TxIn {
+		if _, ok := prevOuts[txIn.PreviousOutPoint]; ok {
+			txIn.SignatureScript = signed.TxIn[i].SignatureScript
+			txIn.Witness = signed.TxIn[i].Witness
+			indexes = append(indexes, i)
+		}
+	}
+	hashCache := txscript.NewTxSigHashes(tx)
+	for _, i := range indexes {
+		prevOut := prevOuts[tx.TxIn[i].PreviousOutPoint]
+		err := txscript.VerifyScript(prevOut.PkScript, tx, i,
+			prevOut.Value, txscript.StandardVerifyFlags, nil, hashCache)
+		if err != nil {
+			return fmt.Errorf("%v: input %d: %v", ErrBadInputSignature,
+				i, err)
+		}
+	}
+
+	r.tx = tx
+	p.signed = true
+	for _, p := range r.participants {
+		if !p.signed {
+			return nil
+		}
+	}
+	r.phase = PhaseCompleted
+	return nil
+}
+
+// Fail aborts the round.
+func (r *Round) Fail() {
+	r.mtx.Lock()
+	r.phase = PhaseFailed
+	r.mtx.Unlock()
+}
@@ -0,0 +1,355 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/btcjson"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/mempool"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// Where a spend from a cold storage address was seen.
+const (
+	coldSpendMempool = "mempool"
+	coldSpendMined   = "mined"
+)
+
+// coldSpendWarning is the warning reported by getinfo and getnetworkinfo while
+// the cold storage alarm is raised.
+const coldSpendWarning = "A spend from a cold storage address was seen, " +
+	"check getcoldspends and acknowledge it with getcoldspends true"
+
+// maxColdSpends is the number of spends from cold storage addresses kept for
+// the getcoldspends RPC.  The oldest are forgotten first.
+const maxColdSpends = 1000
+
+// coldWatchConfig holds the configuration of the cold storage watcher.
+type coldWatchConfig struct {
+	// Addresses are the cold storage addresses watched for spends.
+	Addresses []string
+
+	ChainParams *chaincfg.Params
+
+	// FetchSpendJournal returns the outputs spent by a connected block and
+	// FetchPrevOut returns the output spent by a mempool transaction, or
+	// nil when it is unknown.
+	FetchSpendJournal func(*btcutil.Block) ([]blockchain.SpentTxOut, error)
+	FetchPrevOut      func(wire.OutPoint) *wire.TxOut
+
+	// Notify, when not nil, is called whenever a spend is seen in the
+	// mempool or mined.
+	Notify func(*webhookColdSpendInfo)
+}
+
+// coldSpend is a transaction spending from cold storage addresses.
+type coldSpend struct {
+	txID      chainhash.Hash
+	addresses []string
+	amount    btcutil.Amount
+	firstSeen time.Time
+	blockHash *chainhash.Hash
+	height    int32
+}
+
+// coldWatcher raises an alarm on any transaction spending from a set of cold
+// storage addresses, as soon as it is seen in the mempool and again when it is
+// mined.  A spend which is not expected is a sign the keys of the treasury
+// were compromised, so the alarm stays raised until it is acknowledged.
+type coldWatcher struct {
+	cfg     coldWatchConfig
+	scripts map[string]string // Watched scripts to their addresses.
+
+	mtx    sync.Mutex
+	spends []*coldSpend
+	alarm  bool
+
+	// Notifications are passed through an unbounded queue so the chain
+	// and mempool callbacks never block.
+	queueNotification chan interface{}
+	notifications     chan interface{}
+
+	wg   sync.WaitGroup
+	quit chan struct{}
+}
+
+// newColdWatcher returns a cold storage watcher for the passed configuration.
+func newColdWatcher(cfg *coldWatchConfig) (*coldWatcher, error) {
+	w := &coldWatcher{
+		cfg:               *cfg,
+		scripts:           make(map[string]string, len(cfg.Addresses)),
+		queueNotification: make(chan interface{}),
+		notifications:     make(chan interface{}),
+		quit:              make(chan struct{}),
+	}
+	for _, s := range cfg.Addresses {
+		addr, err := btcutil.DecodeAddress(s, cfg.ChainParams)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cold storage address "+
+				"%q: %v", s, err)
+		}
+		pkScript, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cold storage address "+
+				"%q: %v", s, err)
+		}
+		w.scripts[string(pkScript)] = addr.EncodeAddress()
+	}
+	return w, nil
+}
+
+// Start starts watching the chain and mempool notifications.
+func (w *coldWatcher) Start() {
+	w.wg.Add(2)
+	go func() {
+		queueHandler(w.queueNotification, w.notifications, w.quit)
+		w.wg.Done()
+	}()
+	go w.notificationHandler()
+}
+
+// Stop stops the cold storage watcher and waits for it to finish.
+func (w *coldWatcher) Stop() {
+	close(w.quit)
+	w.wg.Wait()
+}
+
+// handleBlockchainNotification queues block connected and disconnected
+// notifications from the chain.
+func (w *coldWatcher) handleBlockchainNotification(notification *blockchain.Notification) {
+	switch notification.Type {
+	case blockchain.NTBlockConnected, blockchain.NTBlockDisconnected:
+	default:
+		return
+	}
+	select {
+	case w.queueNotification <- notification:
+	case <-w.quit:
+	}
+}
+
+// NotifyNewTransactions queues transactions newly accepted to the mempool.
+func (w *coldWatcher) NotifyNewTransactions(txns []*mempool.TxDesc) {
+	select {
+	case w.queueNotification <- txns:
+	case <-w.quit:
+	}
+}
+
+// notificationHandler checks the queued notifications for spends.  It must be
+// run as a goroutine.
+func (w *coldWatcher) notificationHandler() {
+out:
+	for {
+		select {
+		case n, ok := <-w.notifications:
+			if !ok {
+				break out
+			}
+			switch n := n.(type) {
+			case *blockchain.Notification:
+				block := n.Data.(*btcutil.Block)
+				if n.Type == blockchain.NTBlockConnected {
+					w.blockConnected(block)
+				} else {
+					w.blockDisconnected(block)
+				}
+			case []*mempool.TxDesc:
+				for _, txD := range n {
+					w.txAccepted(txD.Tx)
+				}
+			}
+
+		case <-w.quit:
+			break out
+		}
+	}
+	w.wg.Done()
+}
+
+// spentFrom returns the watched addresses spent from by the passed previous
+// outputs of the inputs of a transaction, and the total amount spent from
+// them.  Unknown previous outputs are nil.
+func (w *coldWatcher) spentFrom(prevOuts []*wire.TxOut) ([]string, btcutil.Amount) {
+	var addrs []string
+	var amount btcutil.Amount
+	seen := make(map[string]struct{})
+	for _, prevOut := range prevOuts {
+		if prevOut == nil {
+			continue
+		}
+		addr, ok := w.scripts[string(prevOut.PkScript)]
+		if !ok {
+			continue
+		}
+		amount += btcutil.Amount(prevOut.Value)
+		if _, ok := seen[addr]; ok {
+			continue
+		}
+		seen[addr] = struct{}{}
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+	return addrs, amount
+}
+
+// txAccepted raises the alarm when a transaction newly accepted to the mempool
+// spends from a watched address.
+func (w *coldWatcher) txAccepted(tx *btcutil.Tx) {
+	prevOuts := make([]*wire.TxOut, 0, len(tx.MsgTx().TxIn))
+	for _, txIn := range tx.MsgTx().TxIn {
+		prevOuts = append(prevOuts, w.cfg.FetchPrevOut(txIn.PreviousOutPoint))
+	}
+	addrs, amount := w.spentFrom(prevOuts)
+	if len(addrs) == 0 {
+		return
+	}
+	w.seen(tx.Hash(), addrs, amount, nil, 0)
+}
+
+// blockConnected raises the alarm for every transaction of the block spending
+// from a watched address.
+func (w *coldWatcher) blockConnected(block *btcutil.Block) {
+	// The spend journal holds the outputs spent by the block in the order
+	// of the inputs of every transaction but the coinbase.
+	stxos, err := w.cfg.FetchSpendJournal(block)
+	if err != nil {
+		srvrLog.Errorf("Unable to fetch the outputs spent by block %v "+
+			"to check for cold storage spends: %v", block.Hash(), err)
+		return
+	}
+	for _, tx := range block.Transactions()[1:] {
+		prevOuts := make([]*wire.TxOut, 0, len(tx.MsgTx().TxIn))
+		for range tx.MsgTx().TxIn {
+			if len(stxos) == 0 {
+				break
+			}
+			prevOuts = append(prevOuts, wire.NewTxOut(stxos[0].Amount,
+				stxos[0].PkScript))
+			stxos = stxos[1:]
+		}
+		addrs, amount := w.spentFrom(prevOuts)
+		if len(addrs) == 0 {
+			continue
+		}
+		w.seen(tx.Hash(), addrs, amount, block.Hash(), block.Height())
+	}
+}
+
+// blockDisconnected marks the spends mined in the block as back in the
+// mempool.  They are not reported again until they are mined again.
+func (w *coldWatcher) blockDisconnected(block *btcutil.Block) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	for _, s := range w.spends {
+		if s.blockHash != nil && *s.blockHash == *block.Hash() {
+			s.blockHash = nil
+			s.height = 0
+		}
+	}
+}
+
+// seen records a spend from watched addresses seen in the mempool, or mined
+// when the block hash is not nil, raises the alarm and notifies it.
+func (w *coldWatcher) seen(txID *chainhash.Hash, addrs []string,
+	amount btcutil.Amount, blockHash *chainhash.Hash, height int32) {
+
+	w.mtx.Lock()
+	var spend *coldSpend
+	for _, s := range w.spends {
+		if s.txID == *txID {
+			spend = s
+			break
+		}
+	}
+	if spend == nil {
+		if len(w.spends) >= maxColdSpends {
+			w.spends = append(w.spends[:0], w.spends[1:]...)
+		}
+		spend = &coldSpend{
+			txID:      *txID,
+			addresses: addrs,
+			amount:    amount,
+			firstSeen: time.Now(),
+		}
+		w.spends = append(w.spends, spend)
+	}
+	status := coldSpendMempool
+	if blockHash != nil {
+		hash := *blockHash
+		spend.blockHash = &hash
+		spend.height = height
+		status = coldSpendMined
+	}
+	w.alarm = true
+	w.mtx.Unlock()
+
+	srvrLog.Errorf("COLD STORAGE SPEND %s: transaction %v spends %v from "+
+		"%v", status, txID, amount, addrs)
+	if w.cfg.Notify == nil {
+		return
+	}
+	event := &webhookColdSpendInfo{
+		TxID:      txID.String(),
+		Addresses: addrs,
+		Amount:    amount.ToBTC(),
+		Status:    status,
+	}
+	if blockHash != nil {
+		event.BlockHash = blockHash.String()
+		event.Height = height
+	}
+	w.cfg.Notify(event)
+}
+
+// Alarm returns whether a spend from a watched address was seen since the
+// alarm was last acknowledged.
+func (w *coldWatcher) Alarm() bool {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	return w.alarm
+}
+
+// Status returns the result of the getcoldspends RPC, with the spends most
+// recent first, and acknowledges the alarm when requested.
+func (w *coldWatcher) Status(acknowledge bool) *btcjson.GetColdSpendsResult {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	result := &btcjson.GetColdSpendsResult{
+		Alarm:     w.alarm,
+		Addresses: len(w.scripts),
+		Spends:    make([]btcjson.ColdSpendResult, 0, len(w.spends)),
+	}
+	for i := len(w.spends) - 1; i >= 0; i-- {
+		s := w.spends[i]
+		r := btcjson.ColdSpendResult{
+			TxID:      s.txID.String(),
+			Addresses: s.addresses,
+			Amount:    s.amount.ToBTC(),
+			FirstSeen: s.firstSeen.Unix(),
+		}
+		if s.blockHash != nil {
+			r.BlockHash = s.blockHash.String()
+			r.Height = s.height
+		}
+		result.Spends = append(result.Spends, r)
+	}
+	if acknowledge {
+		w.alarm = false
+	}
+	return result
+}
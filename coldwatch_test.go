@@ -0,0 +1,128 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// TestColdWatcher ensures spends from cold storage addresses raise the alarm
+// when seen in the mempool and when mined, and that the alarm stays up until
+// it is acknowledged.
+func TestColdWatcher(t *testing.T) {
+	// The log rotator is not initialized in tests.
+	setLogLevels("off")
+	defer setLogLevels(defaultLogLevel)
+
+	params := &chaincfg.RegressionNetParams
+	cold, err := btcutil.NewAddressPubKeyHash(make([]byte, 20), params)
+	if err != nil {
+		t.Fatalf("NewAddressPubKeyHash: %v", err)
+	}
+	coldScript, err := txscript.PayToAddrScript(cold)
+	if err != nil {
+		t.Fatalf("PayToAddrScript: %v", err)
+	}
+	otherScript := []byte{txscript.OP_TRUE}
+
+	// Outputs with index 0 pay to the cold storage address.
+	prevOut := func(op wire.OutPoint) *wire.TxOut {
+		if op.Index == 0 {
+			return wire.NewTxOut(2e8, coldScript)
+		}
+		return wire.NewTxOut(1e8, otherScript)
+	}
+	var events []*webhookColdSpendInfo
+	w, err := newColdWatcher(&coldWatchConfig{
+		Addresses:    []string{cold.EncodeAddress()},
+		ChainParams:  params,
+		FetchPrevOut: prevOut,
+		FetchSpendJournal: func(block *btcutil.Block) ([]blockchain.SpentTxOut, error) {
+			var stxos []blockchain.SpentTxOut
+			for _, tx := range block.Transactions()[1:] {
+				for _, txIn := range tx.MsgTx().TxIn {
+					out := prevOut(txIn.PreviousOutPoint)
+					stxos = append(stxos, blockchain.SpentTxOut{
+						Amount:   out.Value,
+						PkScript: out.PkScript,
+					})
+				}
+			}
+			return stxos, nil
+		},
+		Notify: func(e *webhookColdSpendInfo) { events = append(events, e) },
+	})
+	if err != nil {
+		t.Fatalf("newColdWatcher: %v", err)
+	}
+	if _, err := newColdWatcher(&coldWatchConfig{
+		Addresses:   []string{"notanaddress"},
+		ChainParams: params,
+	}); err == nil {
+		t.Fatalf("newColdWatcher: unexpected success with invalid address")
+	}
+
+	newTx := func(indexes ...uint32) *btcutil.Tx {
+		tx := wire.NewMsgTx(wire.TxVersion)
+		for _, i := range indexes {
+			tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Index: i}, nil, nil))
+		}
+		tx.AddTxOut(wire.NewTxOut(1e8, otherScript))
+		return btcutil.NewTx(tx)
+	}
+
+	// A transaction which doesn't spend from the cold storage address
+	// leaves the alarm down.
+	w.txAccepted(newTx(1))
+	if w.Alarm() || len(events) != 0 {
+		t.Fatalf("alarm raised by an unrelated transaction")
+	}
+
+	spend := newTx(0, 1)
+	w.txAccepted(spend)
+	if !w.Alarm() || len(events) != 1 || events[0].Status != coldSpendMempool ||
+		events[0].Amount != 2 || events[0].TxID != spend.Hash().String() {
+
+		t.Fatalf("unexpected events %+v after the mempool spend", events)
+	}
+
+	// Mining the spend notifies it again with its block.
+	coinbase := newTx()
+	msgBlock := wire.NewMsgBlock(wire.NewBlockHeader(1, &chainhash.Hash{},
+		&chainhash.Hash{}, 0, 0))
+	msgBlock.AddTransaction(coinbase.MsgTx())
+	msgBlock.AddTransaction(spend.MsgTx())
+	block := btcutil.NewBlock(msgBlock)
+	block.SetHeight(10)
+	w.blockConnected(block)
+	if len(events) != 2 || events[1].Status != coldSpendMined ||
+		events[1].Height != 10 || events[1].BlockHash != block.Hash().String() {
+
+		t.Fatalf("unexpected events %+v after the spend was mined", events)
+	}
+
+	// Acknowledging clears the alarm but keeps the spend, which is back in
+	// the mempool once its block is disconnected.
+	status := w.Status(true)
+	if !status.Alarm || status.Addresses != 1 || len(status.Spends) != 1 ||
+		status.Spends[0].Height != 10 {
+
+		t.Fatalf("unexpected status %+v", status)
+	}
+	w.blockDisconnected(block)
+	status = w.Status(false)
+	if status.Alarm || len(status.Spends) != 1 ||
+		status.Spends[0].BlockHash != "" {
+
+		t.Fatalf("unexpected status %+v after acknowledging", status)
+	}
+}
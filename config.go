@@ -9,6 +9,7 @@ import (
 	"bufio"
 	"crypto/rand"
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -32,8 +33,13 @@ import (
 	"github.com/pkt-cash/pktd/connmgr"
 	"github.com/pkt-cash/pktd/database"
 	_ "github.com/pkt-cash/pktd/database/ffldb"
+	"github.com/pkt-cash/pktd/hooks"
 	"github.com/pkt-cash/pktd/mempool"
+	"github.com/pkt-cash/pktd/netsync"
 	"github.com/pkt-cash/pktd/peer"
+	"github.com/pkt-cash/pktd/rpctoken"
+	"github.com/pkt-cash/pktd/txscript"
+	"golang.org/x/crypto/acme"
 )
 
 const (
@@ -52,10 +58,12 @@ const (
 	defaultDbType                = "ffldb"
 	defaultFreeTxRelayLimit      = 15.0
 	defaultTrickleInterval       = peer.DefaultTrickleInterval
+	defaultTrickleBatchSize      = peer.DefaultTrickleBatchSize
 	defaultBlockMinSize          = 0
 	defaultBlockMaxSize          = 750000
 	defaultBlockMinWeight        = 0
 	defaultBlockMaxWeight        = 3000000
+	defaultBlockDataCarrierShare = 100.0
 	blockMaxSizeMin              = 1000
 	blockMaxSizeMax              = blockchain.MaxBlockBaseSize - 1000
 	blockMaxWeightMin            = 4000
@@ -64,6 +72,8 @@ const (
 	defaultMaxOrphanTransactions = 100
 	defaultMaxOrphanTxSize       = 100000
 	defaultSigCacheMaxSize       = 100000
+	defaultServedBlockCache      = 32
+	defaultExplorerPort          = "8080"
 	sampleConfigFilename         = "sample-pktd.conf"
 	defaultTxIndex               = false
 	defaultAddrIndex             = false
@@ -103,29 +113,61 @@ type config struct {
 	AddPeers             []string      `short:"a" long:"addpeer" description:"Add a peer to connect with at startup"`
 	ConnectPeers         []string      `long:"connect" description:"Connect only to the specified peers at startup"`
 	DisableListen        bool          `long:"nolisten" description:"Disable listening for incoming connections -- NOTE: Listening is automatically disabled if the --connect or --proxy options are used without also specifying listen interfaces via --listen"`
-	Listeners            []string      `long:"listen" description:"Add an interface/port to listen for connections (default all interfaces port: 8333, testnet: 18333)"`
+	Listeners            []string      `long:"listen" description:"Add an interface/port to listen for connections, the interface being an IP address or the name of a network interface such as eth0 to listen on all its addresses (default all interfaces port: 8333, testnet: 18333)"`
+	NoListenIPv4         bool          `long:"nolistenipv4" description:"Do not listen on the IPv4 addresses of the wildcard and network interface --listen addresses, for IPv6-only nodes"`
+	NoListenIPv6         bool          `long:"nolistenipv6" description:"Do not listen on the IPv6 addresses of the wildcard and network interface --listen addresses"`
 	MaxPeers             int           `long:"maxpeers" description:"Max number of inbound and outbound peers"`
 	DisableBanning       bool          `long:"nobanning" description:"Disable banning of misbehaving peers"`
 	BanDuration          time.Duration `long:"banduration" description:"How long to ban misbehaving peers.  Valid time units are {s, m, h}.  Minimum 1 second"`
 	BanThreshold         uint32        `long:"banthreshold" description:"Maximum allowed ban score before disconnecting and banning misbehaving peers."`
-	Whitelists           []string      `long:"whitelist" description:"Add an IP network or IP that will not be banned. (eg. 192.168.1.0/24 or ::1)"`
+	Whitelists           []string      `long:"whitelist" description:"Grant permissions to the peers of an IP network or IP, in the form [perm,...@]IP[/CIDR]. The permissions are noban, relay, forcerelay, addr, download and all, noban when none is given (eg. 192.168.1.0/24, ::1 or relay,noban@10.0.0.1)"`
+	WhiteBinds           []string      `long:"whitebind" description:"Add an interface/port to listen for connections and grant permissions to the peers connecting to it, in the form [perm,...@]interface:port, noban when none is given (eg. forcerelay@127.0.0.1:8335)"`
 	AgentBlacklist       []string      `long:"agentblacklist" description:"A comma separated list of user-agent substrings which will cause pktd to reject any peers whose user-agent contains any of the blacklisted substrings."`
 	AgentWhitelist       []string      `long:"agentwhitelist" description:"A comma separated list of user-agent substrings which will cause pktd to require all peers' user-agents to contain one of the whitelisted substrings. The blacklist is applied before the blacklist, and an empty whitelist will allow all agents that do not fail the blacklist."`
+	MsgCapture           bool          `long:"msgcapture" description:"Capture the P2P messages exchanged with peers to pcapng files readable by Wireshark, one per connection -- The capture can also be started and stopped with the capturemessages RPC"`
+	MsgCaptureDir        string        `long:"msgcapturedir" description:"Directory to write the P2P message captures to (default: msgcapture in the data directory)"`
+	MsgCaptureRedact     []string      `long:"msgcaptureredact" description:"Leave something out of the P2P message captures: peers to replace the peer addresses with placeholders, or a message command such as tx or addr to record those messages with an empty payload"`
+	PartitionAlertTime   time.Duration `long:"partitionalerttime" description:"Raise a network partition alert once the node has been behind the most work announced by its peers, or many of its peers have been on another chain, for this long -- 0 disables the detector"`
+	PartitionForkRatio   float64       `long:"partitionforkratio" description:"Share of the peers on another chain, between 0 and 1, which raises a network partition alert"`
+	MaxTimeAdjustment    time.Duration `long:"maxtimeadjustment" description:"Maximum adjustment of the local clock, in either direction, to the median time of the peers -- 0 never adjusts the local clock"`
+	HeaderProbePeers     int           `long:"headerprobepeers" description:"Number of peers whose header chain is downloaded in parallel during the initial block download to learn the best chain tip and detect peers serving diverging chains -- 0 disables it"`
 	RPCUser              string        `short:"u" long:"rpcuser" description:"Username for RPC connections"`
 	RPCPass              string        `short:"P" long:"rpcpass" default-mask:"-" description:"Password for RPC connections"`
 	RPCLimitUser         string        `long:"rpclimituser" description:"Username for limited RPC connections"`
 	RPCLimitPass         string        `long:"rpclimitpass" default-mask:"-" description:"Password for limited RPC connections"`
 	RPCListeners         []string      `long:"rpclisten" description:"Add an interface/port to listen for RPC connections (default port: 8334, testnet: 18334)"`
+	RPCLocal             []string      `long:"rpclocal" description:"Add a Unix domain socket, or a named pipe such as \\\\.\\pipe\\pktd on Windows, to listen for RPC connections authenticated as the admin user by the permissions of the socket"`
+	RPCLimitLocal        []string      `long:"rpclimitlocal" description:"Add a Unix domain socket, or a named pipe on Windows, to listen for RPC connections authenticated as the limited user by the permissions of the socket"`
+	RPCLocalMode         uint32        `long:"rpclocalmode" base:"8" description:"Permissions of the RPC Unix domain sockets, in octal"`
 	RPCCert              string        `long:"rpccert" description:"File containing the certificate file"`
 	RPCKey               string        `long:"rpckey" description:"File containing the certificate key"`
+	RPCClientCA          string        `long:"rpcclientca" description:"File containing the certificate authorities whose client certificates authenticate RPC connections as the admin user -- A valid client certificate replaces the username and password"`
+	RPCLimitClientCA     string        `long:"rpclimitclientca" description:"File containing the certificate authorities whose client certificates authenticate RPC connections as the limited user"`
+	RPCRequireClientCert bool          `long:"rpcrequireclientcert" description:"Refuse RPC connections without a valid client certificate -- NOTE: Requires --rpcclientca or --rpclimitclientca"`
+	RPCACMEDomains       []string      `long:"rpcacmedomain" description:"Add a public domain of the RPC server to obtain and renew its certificate for from an ACME certificate authority, accepting the terms of service of the authority -- NOTE: The RPC server must be reachable on port 443 of the domain"`
+	RPCACMEEmail         string        `long:"rpcacmeemail" description:"Contact email address of the ACME account, for expiration notices"`
+	RPCACMEDirectory     string        `long:"rpcacmedirectory" description:"Directory URL of the ACME certificate authority"`
+	RPCTokens            bool          `long:"rpctokens" description:"Accept the capability tokens minted with the minttoken RPC, sent as bearer tokens in the Authorization header, as RPC credentials -- Deleting rpctoken.key in the data directory revokes every token"`
+	RPCRevokeTokens      []string      `long:"rpcrevoketoken" description:"Refuse the RPC token with this identifier, as returned by minttoken, along with the tokens attenuated from it"`
 	RPCMaxClients        int           `long:"rpcmaxclients" description:"Max number of RPC clients for standard connections"`
 	RPCMaxWebsockets     int           `long:"rpcmaxwebsockets" description:"Max number of RPC websocket connections"`
 	RPCMaxConcurrentReqs int           `long:"rpcmaxconcurrentreqs" description:"Max number of concurrent RPC requests that may be processed concurrently"`
+	RPCAuditLog          bool          `long:"rpcauditlog" description:"Record state-changing RPC commands in a hash-chained audit log in the data directory"`
+	RPCAuditSyslog       bool          `long:"rpcauditsyslog" description:"Also export RPC audit log entries to the local syslog daemon -- NOTE: Requires --rpcauditlog"`
 	RPCQuirks            bool          `long:"rpcquirks" description:"Mirror some JSON-RPC quirks of Bitcoin Core -- NOTE: Discouraged unless interoperability issues need to be worked around"`
+	Webhooks             []string      `long:"webhook" description:"Add a URL to POST block and transaction events to, optionally followed by #event,event to select the events (blockconnected, blockdisconnected, addressactivity, txconfirmed, partitionalert, deposit, coldspend, diskspace)"`
+	WebhookSecret        string        `long:"webhooksecret" default-mask:"-" description:"Secret used to sign webhook payloads with HMAC-SHA256"`
+	WebhookWatch         []string      `long:"webhookwatch" description:"Add an address whose activity is sent to webhooks"`
+	WebhookConfs         []int32       `long:"webhookconfirmations" description:"Add a confirmation count at which transactions of watched addresses are sent to webhooks (default: 1 and 6)"`
+	DepositBundle        string        `long:"depositbundle" description:"Track the deposits to the addresses of the account bundle in the passed JSON file, bound to reference IDs with createdepositaddresses"`
+	DepositConfs         []int32       `long:"depositconfirmations" description:"Add a confirmation count at which deposits are sent to webhooks as deposit events (default: 1 and 6)"`
+	ColdAddresses        []string      `long:"coldaddress" description:"Add a cold storage address whose spends raise an alarm as soon as they are seen in the mempool or mined, logged, reported by getcoldspends and sent to webhooks as coldspend events"`
+	ExplorerListeners    []string      `long:"explorerlisten" description:"Add an interface/port to serve the read-only block explorer on (default port: 8080, disabled by default)"`
+	ExplorerPeers        bool          `long:"explorerpeers" description:"List the address, direction and user agent of the connected peers in the block explorer rather than only their number per network"`
 	DisableRPC           bool          `long:"norpc" description:"Disable built-in RPC server -- NOTE: The RPC server is disabled by default if no rpcuser/rpcpass or rpclimituser/rpclimitpass is specified"`
 	DisableTLS           bool          `long:"notls" description:"Disable TLS for the RPC server -- NOTE: This is only allowed if the RPC server is bound to localhost"`
 	DisableDNSSeed       bool          `long:"nodnsseed" description:"Disable DNS seeding for peers"`
-	ExternalIPs          []string      `long:"externalip" description:"Add an ip to the list of local addresses we claim to listen on to peers"`
+	ExternalIPs          []string      `long:"externalip" description:"Add an ip to the list of local addresses we claim to listen on to peers -- The bound addresses of the listeners are still advertised in the networks, IPv4, IPv6 or Tor, without an external ip"`
 	Proxy                string        `long:"proxy" description:"Connect via SOCKS5 proxy (eg. 127.0.0.1:9050)"`
 	ProxyUser            string        `long:"proxyuser" description:"Username for proxy server"`
 	ProxyPass            string        `long:"proxypass" default-mask:"-" description:"Password for proxy server"`
@@ -143,15 +185,32 @@ type config struct {
 	AddCheckpoints       []string      `long:"addcheckpoint" description:"Add a custom checkpoint.  Format: '<height>:<hash>'"`
 	DisableCheckpoints   bool          `long:"nocheckpoints" description:"Disable built-in checkpoints.  Don't do this unless you know what you're doing."`
 	DbType               string        `long:"dbtype" description:"Database backend to use for the Block Chain"`
+	DbCache              uint          `long:"dbcache" description:"Maximum size in MiB of the block database metadata cached before it is flushed to disk -- 0 selects the default of 100 MiB"`
+	DbFlushInterval      time.Duration `long:"dbflushinterval" description:"Maximum time between two flushes of the block database metadata cache -- 0 selects the default of 5 minutes"`
+	DbFlushBlocks        uint          `long:"dbflushblocks" description:"Number of blocks after which the block database metadata cache is flushed during the initial block download, when the flush interval has not elapsed before -- Blocks stored after the last flush are downloaded again after an unexpected shutdown -- 0 selects the default of 2000"`
+	DbWriteBuffer        uint          `long:"dbwritebuffer" description:"Size in MiB of the leveldb write buffer of the block database -- 0 selects the leveldb default"`
+	DbBlockCache         uint          `long:"dbblockcache" description:"Size in MiB of the leveldb block cache of the block database -- 0 selects the leveldb default"`
+	DbMmap               bool          `long:"dbmmap" description:"Read blocks from memory mapped block files -- Reduces the cost of serving many syncing peers on platforms supporting it"`
+	DbReadahead          uint          `long:"dbreadahead" description:"Size in KiB of the data following every block read from a memory mapped block file which the operating system is advised to load in the background -- Only used on Linux with --dbmmap"`
+	DiskWarnSpace        uint          `long:"diskwarnspace" description:"Free space in MiB on the data directory below which a low disk space warning is logged and reported by getinfo, getnetworkinfo and getdiskstatus -- 0 disables the warning"`
+	DiskSafeModeSpace    uint          `long:"disksafemodespace" description:"Free space in MiB on the data directory below which the node enters a safe mode, where blocks are no longer accepted while queries keep being served, so the database isn't corrupted when the disk gets full -- The node leaves the safe mode once space is freed -- 0 disables the safe mode"`
+	ServedBlockCache     uint          `long:"servedblockcache" description:"Number of blocks recently served to peers to keep in memory for serving them again -- 0 disables the cache"`
+	Replica              bool          `long:"replica" description:"Serve query RPCs from a read-only copy of the block database of another pktd without connecting to the network -- NOTE: The database must not be in use by another process"`
 	Profile              string        `long:"profile" description:"Enable HTTP profiling on given port -- NOTE port must be between 1024 and 65536"`
 	CPUProfile           string        `long:"cpuprofile" description:"Write CPU profile to the specified file"`
+	RecordConsensus      string        `long:"recordconsensus" description:"Record every block processed by the chain, in arrival order along with the adjusted time, to the specified file so it can be replayed with --replayconsensus -- NOTE: Start from an empty block database since the replay starts from the genesis block"`
+	ReplayConsensus      string        `long:"replayconsensus" description:"Replay the blocks recorded with --recordconsensus in the specified file against a temporary chain, report the first block whose resulting chain state differs from the recording and then exit"`
+	BuildManifest        string        `long:"buildmanifest" description:"Refuse to start unless the consensus-critical modules the binary was built with match the versions and hashes of the specified manifest, in the go.sum format of a release"`
 	DebugLevel           string        `short:"d" long:"debuglevel" description:"Logging level for all subsystems {trace, debug, info, warn, error, critical} -- You may also specify <subsystem>=<level>,<subsystem2>=<level>,... to set the log level for individual subsystems -- Use show to list available subsystems"`
 	Upnp                 bool          `long:"upnp" description:"Use UPnP to map our listening port outside of NAT"`
 	MinRelayTxFee        float64       `long:"minrelaytxfee" description:"The minimum transaction fee in BTC/kB to be considered a non-zero fee."`
 	FreeTxRelayLimit     float64       `long:"limitfreerelay" description:"Limit relay of transactions with no transaction fee to the given amount in thousands of bytes per minute"`
 	NoRelayPriority      bool          `long:"norelaypriority" description:"Do not require free or low-fee transactions to have high priority for relaying"`
-	TrickleInterval      time.Duration `long:"trickleinterval" description:"Minimum time between attempts to send new inventory to a connected peer"`
+	TrickleInterval      time.Duration `long:"trickleinterval" description:"Average time between attempts to send new inventory to a connected peer, randomized per attempt between half and one and a half times the interval"`
+	TrickleBatchSize     int           `long:"tricklebatchsize" description:"Maximum number of inventory items to send to a connected peer per attempt, highest fee rate first -- Transactions submitted through the RPC server are the first to fit"`
 	MaxOrphanTxs         int           `long:"maxorphantx" description:"Max number of orphan transactions to keep in memory"`
+	MaxRejectedTxs       int           `long:"maxrejectedtx" description:"Max number of transactions rejected from peers to remember, which are not requested again until the next block and can be listed with getrejectedtxs"`
+	MempoolEvents        int           `long:"mempoolevents" description:"Number of transactions added to and removed from the mempool to remember for getmempooldelta"`
 	Generate             bool          `long:"generate" description:"Generate (mine) bitcoins using the CPU"`
 	MiningAddrs          []string      `long:"miningaddr" description:"Add the specified payment address to the list of addresses to use for generated blocks -- At least one address is required if the generate option is set"`
 	BlockMinSize         uint32        `long:"blockminsize" description:"Mininum block size in bytes to be used when creating a block"`
@@ -159,6 +218,9 @@ type config struct {
 	BlockMinWeight       uint32        `long:"blockminweight" description:"Mininum block weight to be used when creating a block"`
 	BlockMaxWeight       uint32        `long:"blockmaxweight" description:"Maximum block weight to be used when creating a block"`
 	BlockPrioritySize    uint32        `long:"blockprioritysize" description:"Size in bytes for high-priority/low-fee transactions when creating a block"`
+	DataCarrierShare     float64       `long:"blockdatacarriershare" description:"Maximum percentage of the block weight used by transactions carrying data in OP_RETURN outputs when creating a block"`
+	BlockMaxSigOpCost    int64         `long:"blockmaxsigopcost" description:"Maximum signature operation cost to be used when creating a block"`
+	BlockReservedWeight  uint32        `long:"blockreservedweight" description:"Part of the maximum block weight reserved for transactions submitted to this node with sendrawtransaction when creating a block"`
 	UserAgentComments    []string      `long:"uacomment" description:"Comment to add to the user agent -- See BIP 14 for more information."`
 	NoPeerBloomFilters   bool          `long:"nopeerbloomfilters" description:"Disable bloom filtering support"`
 	NoCFilters           bool          `long:"nocfilters" description:"Disable committed filtering (CF) support"`
@@ -166,19 +228,33 @@ type config struct {
 	SigCacheMaxSize      uint          `long:"sigcachemaxsize" description:"The maximum number of entries in the signature verification cache"`
 	BlocksOnly           bool          `long:"blocksonly" description:"Do not accept transactions from remote peers."`
 	TxIndex              bool          `long:"txindex" description:"Maintain a full hash-based transaction index which makes all transactions available via the getrawtransaction RPC"`
+	TxIndexStart         int32         `long:"txindexstart" description:"Only index the transactions of the blocks from this height onwards -- NOTE: Changing it requires dropping the index with --droptxindex and it cannot be used with --addrindex"`
 	DropTxIndex          bool          `long:"droptxindex" description:"Deletes the hash-based transaction index from the database on start up and then exits."`
 	AddrIndex            bool          `long:"addrindex" description:"Maintain a full address-based transaction index which makes the searchrawtransactions RPC available"`
 	DropAddrIndex        bool          `long:"dropaddrindex" description:"Deletes the address-based transaction index from the database on start up and then exits."`
+	UtxoStatsIndex       bool          `long:"utxostatsindex" description:"Maintain an index of unspent output statistics by script class which makes the getutxostats RPC available"`
+	UtxoStatsInterval    int32         `long:"utxostatsinterval" description:"Number of blocks between the snapshots of the unspent output statistics kept by the utxo statistics index -- NOTE: Changing it only affects blocks connected afterwards"`
+	DropUtxoStatsIndex   bool          `long:"droputxostatsindex" description:"Deletes the unspent output statistics index from the database on start up and then exits."`
+	FeeStatsIndex        bool          `long:"feestatsindex" description:"Maintain an index of the fees paid in every block which makes the getfeehistory RPC available"`
+	DropFeeStatsIndex    bool          `long:"dropfeestatsindex" description:"Deletes the fee statistics index from the database on start up and then exits."`
+	Utreexo              bool          `long:"utreexo" description:"EXPERIMENTAL: Maintain a utreexo accumulator of the unspent outputs alongside the utxo set and serve proofs of them to peers"`
+	DropUtreexo          bool          `long:"droputreexo" description:"Deletes the utreexo accumulator from the database on start up and then exits."`
 	RelayNonStd          bool          `long:"relaynonstd" description:"Relay non-standard transactions regardless of the default settings for the active network."`
 	RejectNonStd         bool          `long:"rejectnonstd" description:"Reject non-standard transactions regardless of the default settings for the active network."`
 	RejectReplacement    bool          `long:"rejectreplacement" description:"Reject transactions that attempt to replace existing transactions within the mempool through the Replace-By-Fee (RBF) signaling policy."`
+	NoDataCarrier        bool          `long:"nodatacarrier" description:"Do not relay transactions carrying data in OP_RETURN outputs"`
+	DataCarrierSize      int           `long:"datacarriersize" description:"Maximum number of bytes of data a transaction may carry in its OP_RETURN outputs to be relayed"`
+	Hooks                []string      `long:"hook" description:"Add a hook notified of the blocks connected and disconnected and able to reject transactions from the mempool: the name of a compiled-in hook, optionally followed by :args, or exec: followed by the command line of an external process"`
+	HookTimeout          time.Duration `long:"hooktimeout" description:"Time an external hook process is given to answer whether a transaction is accepted before it is accepted anyway"`
 	lookup               func(string) ([]net.IP, error)
 	oniondial            func(string, string, time.Duration) (net.Conn, error)
 	dial                 func(string, string, time.Duration) (net.Conn, error)
 	addCheckpoints       []chaincfg.Checkpoint
 	miningAddrs          map[btcutil.Address]float64
 	minRelayTxFee        btcutil.Amount
-	whitelists           []*net.IPNet
+	whitelists           []*whitelistEntry
+	whitebinds           []*whitebind
+	msgCaptureRedact     *msgCaptureRedaction
 }
 
 // serviceOptions defines the configuration options for the daemon as a service on
@@ -425,16 +501,32 @@ func loadConfig() (*config, []string, error) {
 		DbType:               defaultDbType,
 		RPCKey:               defaultRPCKeyFile,
 		RPCCert:              defaultRPCCertFile,
+		RPCACMEDirectory:     acme.LetsEncryptURL,
+		RPCLocalMode:         defaultRPCLocalMode,
 		MinRelayTxFee:        mempool.DefaultMinRelayTxFee.ToBTC(),
 		FreeTxRelayLimit:     defaultFreeTxRelayLimit,
 		TrickleInterval:      defaultTrickleInterval,
+		TrickleBatchSize:     defaultTrickleBatchSize,
 		BlockMinSize:         defaultBlockMinSize,
 		BlockMaxSize:         defaultBlockMaxSize,
 		BlockMinWeight:       defaultBlockMinWeight,
 		BlockMaxWeight:       defaultBlockMaxWeight,
 		BlockPrioritySize:    mempool.DefaultBlockPrioritySize,
+		DataCarrierShare:     defaultBlockDataCarrierShare,
+		BlockMaxSigOpCost:    blockchain.MaxBlockSigOpsCost,
+		DataCarrierSize:      mempool.DefaultMaxDataCarrierSize,
+		HookTimeout:          hooks.DefaultTimeout,
 		MaxOrphanTxs:         defaultMaxOrphanTransactions,
+		MaxRejectedTxs:       netsync.DefaultMaxRejectedTxns,
+		MempoolEvents:        mempool.DefaultMaxTxEvents,
 		SigCacheMaxSize:      defaultSigCacheMaxSize,
+		ServedBlockCache:     defaultServedBlockCache,
+		DiskWarnSpace:        defaultDiskWarnSpace,
+		DiskSafeModeSpace:    defaultDiskSafeModeSpace,
+		PartitionAlertTime:   defaultPartitionAlertTime,
+		PartitionForkRatio:   defaultPartitionForkRatio,
+		MaxTimeAdjustment:    blockchain.DefaultMaxTimeAdjustment,
+		HeaderProbePeers:     netsync.DefaultHeaderProbePeers,
 		Generate:             defaultGenerate,
 		TxIndex:              defaultTxIndex,
 		AddrIndex:            defaultAddrIndex,
@@ -616,6 +708,13 @@ func loadConfig() (*config, []string, error) {
 	cfg.DataDir = cleanAndExpandPath(cfg.DataDir)
 	cfg.DataDir = filepath.Join(cfg.DataDir, netName(activeNetParams))
 
+	// The message captures go to the data directory unless specified.
+	if cfg.MsgCaptureDir == "" {
+		cfg.MsgCaptureDir = filepath.Join(cfg.DataDir, msgCaptureDirname)
+	} else {
+		cfg.MsgCaptureDir = cleanAndExpandPath(cfg.MsgCaptureDir)
+	}
+
 	// Append the network type to the log directory so it is "namespaced"
 	// per network in the same fashion as the data directory.
 	cfg.LogDir = cleanAndExpandPath(cfg.LogDir)
@@ -649,6 +748,16 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// The flush interval of the block database may not be negative.
+	if cfg.DbFlushInterval < 0 {
+		str := "%s: The dbflushinterval option may not be negative " +
+			"-- parsed [%v]"
+		err := fmt.Errorf(str, funcName, cfg.DbFlushInterval)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
 	// Validate profile port number
 	if cfg.Profile != "" {
 		profilePort, err := strconv.Atoi(cfg.Profile)
@@ -670,35 +779,21 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
-	// Validate any given whitelisted IP addresses and networks.
+	// Validate any given whitelisted IP addresses and networks along with
+	// the permissions they grant.
 	if len(cfg.Whitelists) > 0 {
-		var ip net.IP
-		cfg.whitelists = make([]*net.IPNet, 0, len(cfg.Whitelists))
+		cfg.whitelists = make([]*whitelistEntry, 0, len(cfg.Whitelists))
 
 		for _, addr := range cfg.Whitelists {
-			_, ipnet, err := net.ParseCIDR(addr)
+			entry, err := parseWhitelist(addr)
 			if err != nil {
-				ip = net.ParseIP(addr)
-				if ip == nil {
-					str := "%s: The whitelist value of '%s' is invalid"
-					err = fmt.Errorf(str, funcName, addr)
-					fmt.Fprintln(os.Stderr, err)
-					fmt.Fprintln(os.Stderr, usageMessage)
-					return nil, nil, err
-				}
-				var bits int
-				if ip.To4() == nil {
-					// IPv6
-					bits = 128
-				} else {
-					bits = 32
-				}
-				ipnet = &net.IPNet{
-					IP:   ip,
-					Mask: net.CIDRMask(bits, bits),
-				}
+				str := "%s: The whitelist value of '%s' is invalid: %v"
+				err = fmt.Errorf(str, funcName, addr, err)
+				fmt.Fprintln(os.Stderr, err)
+				fmt.Fprintln(os.Stderr, usageMessage)
+				return nil, nil, err
 			}
-			cfg.whitelists = append(cfg.whitelists, ipnet)
+			cfg.whitelists = append(cfg.whitelists, entry)
 		}
 	}
 
@@ -712,9 +807,61 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
-	// --proxy or --connect without --listen disables listening.
+	// A read-only replica never talks to the network and can not write to
+	// the database, so refuse the options which require either.
+	if cfg.Replica {
+		var bad string
+		switch {
+		case cfg.DbType == "memdb":
+			bad = "--dbtype=memdb"
+		case len(cfg.AddPeers) > 0:
+			bad = "--addpeer"
+		case len(cfg.ConnectPeers) > 0:
+			bad = "--connect"
+		case cfg.Generate:
+			bad = "--generate"
+		case cfg.DropTxIndex || cfg.DropAddrIndex || cfg.DropCfIndex ||
+			cfg.DropUtxoStatsIndex || cfg.DropFeeStatsIndex ||
+			cfg.DropUtreexo:
+			bad = "dropping indexes"
+		}
+		if bad != "" {
+			str := "%s: the --replica option can not be used with %s"
+			err := fmt.Errorf(str, funcName, bad)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+		cfg.DisableListen = true
+		cfg.DisableDNSSeed = true
+		cfg.Upnp = false
+		cfg.MaxPeers = 0
+	}
+
+	// Replaying a consensus recording exits once done, so it can not be
+	// recorded, and a replica never processes blocks to record.
+	if cfg.RecordConsensus != "" && (cfg.ReplayConsensus != "" || cfg.Replica) {
+		str := "%s: the --recordconsensus option can not be used with " +
+			"--replayconsensus or --replica"
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+	if cfg.RecordConsensus != "" {
+		cfg.RecordConsensus = cleanAndExpandPath(cfg.RecordConsensus)
+	}
+	if cfg.ReplayConsensus != "" {
+		cfg.ReplayConsensus = cleanAndExpandPath(cfg.ReplayConsensus)
+	}
+	if cfg.BuildManifest != "" {
+		cfg.BuildManifest = cleanAndExpandPath(cfg.BuildManifest)
+	}
+
+	// --proxy or --connect without --listen or --whitebind disables
+	// listening.
 	if (cfg.Proxy != "" || len(cfg.ConnectPeers) > 0) &&
-		len(cfg.Listeners) == 0 {
+		len(cfg.Listeners) == 0 && len(cfg.WhiteBinds) == 0 {
 		cfg.DisableListen = true
 	}
 
@@ -725,8 +872,8 @@ func loadConfig() (*config, []string, error) {
 
 	// Add the default listener if none were specified. The default
 	// listener is all addresses on the listen port for the network
-	// we are to connect to.
-	if len(cfg.Listeners) == 0 {
+	// we are to connect to.  Whitebind listeners count as specified.
+	if len(cfg.Listeners) == 0 && len(cfg.WhiteBinds) == 0 {
 		cfg.Listeners = []string{
 			net.JoinHostPort("", activeNetParams.DefaultPort),
 		}
@@ -752,9 +899,59 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
-	// The RPC server is disabled if no username or password is provided.
-	if (cfg.RPCUser == "" || cfg.RPCPass == "") &&
-		(cfg.RPCLimitUser == "" || cfg.RPCLimitPass == "") {
+	// Client certificates require certificate authorities to be verified
+	// with.
+	if cfg.RPCRequireClientCert && cfg.RPCClientCA == "" &&
+		cfg.RPCLimitClientCA == "" {
+
+		str := "%s: the --rpcrequireclientcert option requires " +
+			"--rpcclientca or --rpclimitclientca"
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+	if cfg.RPCClientCA != "" {
+		cfg.RPCClientCA = cleanAndExpandPath(cfg.RPCClientCA)
+	}
+	if cfg.RPCLimitClientCA != "" {
+		cfg.RPCLimitClientCA = cleanAndExpandPath(cfg.RPCLimitClientCA)
+	}
+
+	// Revoked RPC tokens are named by their hex encoded identifier.
+	for _, id := range cfg.RPCRevokeTokens {
+		b, err := hex.DecodeString(id)
+		if err != nil || len(b) != rpctoken.IDSize {
+			str := "%s: the --rpcrevoketoken option must be the " +
+				"identifier of a token -- parsed [%s]"
+			err := fmt.Errorf(str, funcName, id)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+	}
+
+	// Expand the paths of the RPC Unix domain sockets.  Named pipes are
+	// not paths of the filesystem.
+	expandSocketPaths := func(paths []string) {
+		for i, path := range paths {
+			if !strings.HasPrefix(path, `\\`) {
+				paths[i] = cleanAndExpandPath(path)
+			}
+		}
+	}
+	expandSocketPaths(cfg.RPCLocal)
+	expandSocketPaths(cfg.RPCLimitLocal)
+
+	// The RPC server is disabled if no username or password is provided,
+	// unless client certificates, RPC tokens or the permissions of local
+	// sockets authenticate the connections.
+	passwordAuth := (cfg.RPCUser != "" && cfg.RPCPass != "") ||
+		(cfg.RPCLimitUser != "" && cfg.RPCLimitPass != "")
+	certAuth := cfg.RPCClientCA != "" || cfg.RPCLimitClientCA != ""
+	localAuth := len(cfg.RPCLocal) != 0 ||
+		len(cfg.RPCLimitLocal) != 0
+	if !passwordAuth && !certAuth && !cfg.RPCTokens && !localAuth {
 		cfg.DisableRPC = true
 	}
 
@@ -762,8 +959,12 @@ func loadConfig() (*config, []string, error) {
 		pktdLog.Infof("RPC service is disabled")
 	}
 
-	// Default RPC to listen on localhost only.
-	if !cfg.DisableRPC && len(cfg.RPCListeners) == 0 {
+	// Default RPC to listen on localhost only, unless the connections are
+	// only authenticated by local sockets, in which case no TCP port is
+	// opened.
+	if !cfg.DisableRPC && len(cfg.RPCListeners) == 0 &&
+		(passwordAuth || certAuth || cfg.RPCTokens) {
+
 		addrs, err := net.LookupHost("localhost")
 		if err != nil {
 			return nil, nil, err
@@ -784,6 +985,42 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// Exporting the RPC audit log to syslog requires the audit log.
+	if cfg.RPCAuditSyslog && !cfg.RPCAuditLog {
+		str := "%s: the --rpcauditsyslog option requires --rpcauditlog"
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Webhook confirmation counts must be positive.
+	for _, confs := range cfg.WebhookConfs {
+		if confs < 1 {
+			str := "%s: the --webhookconfirmations option must be " +
+				"at least 1 -- parsed [%d]"
+			err := fmt.Errorf(str, funcName, confs)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+	}
+
+	// Deposit confirmation counts must be positive.
+	for _, confs := range cfg.DepositConfs {
+		if confs < 1 {
+			str := "%s: the --depositconfirmations option must be " +
+				"at least 1 -- parsed [%d]"
+			err := fmt.Errorf(str, funcName, confs)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+	}
+	if cfg.DepositBundle != "" {
+		cfg.DepositBundle = cleanAndExpandPath(cfg.DepositBundle)
+	}
+
 	// Validate the the minrelaytxfee.
 	mrf, err := globalcfg.NewAmount(cfg.MinRelayTxFee)
 	if err != nil {
@@ -821,6 +1058,50 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// Limit the max block signature operation cost to the consensus limit.
+	if cfg.BlockMaxSigOpCost < 1 ||
+		cfg.BlockMaxSigOpCost > blockchain.MaxBlockSigOpsCost {
+
+		str := "%s: The blockmaxsigopcost option must be in between 1 " +
+			"and %d -- parsed [%d]"
+		err := fmt.Errorf(str, funcName, blockchain.MaxBlockSigOpsCost,
+			cfg.BlockMaxSigOpCost)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Limit the data carrier share of the block weight to a percentage.
+	if cfg.DataCarrierShare < 0 || cfg.DataCarrierShare > 100 {
+		str := "%s: The blockdatacarriershare option must be in " +
+			"between 0 and 100 -- parsed [%v]"
+		err := fmt.Errorf(str, funcName, cfg.DataCarrierShare)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Limit the data carrier size to what fits in an output script.
+	if cfg.DataCarrierSize < 0 || cfg.DataCarrierSize > txscript.MaxScriptSize {
+		str := "%s: The datacarriersize option must be in between 0 " +
+			"and %d -- parsed [%d]"
+		err := fmt.Errorf(str, funcName, txscript.MaxScriptSize,
+			cfg.DataCarrierSize)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// External hook processes must be given time to answer.
+	if cfg.HookTimeout <= 0 {
+		str := "%s: The hooktimeout option must be greater than 0 " +
+			"-- parsed [%v]"
+		err := fmt.Errorf(str, funcName, cfg.HookTimeout)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
 	// Limit the max orphan count to a sane vlue.
 	if cfg.MaxOrphanTxs < 0 {
 		str := "%s: The maxorphantx option may not be less than 0 " +
@@ -831,6 +1112,26 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// At least one rejected transaction must be remembered.
+	if cfg.MaxRejectedTxs < 1 {
+		str := "%s: The maxrejectedtx option may not be less than 1 " +
+			"-- parsed [%d]"
+		err := fmt.Errorf(str, funcName, cfg.MaxRejectedTxs)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// At least one mempool event must be remembered.
+	if cfg.MempoolEvents < 1 {
+		str := "%s: The mempoolevents option may not be less than 1 " +
+			"-- parsed [%d]"
+		err := fmt.Errorf(str, funcName, cfg.MempoolEvents)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
 	// Limit the block priority and minimum block sizes to max block size.
 	cfg.BlockPrioritySize = minUint32(cfg.BlockPrioritySize, cfg.BlockMaxSize)
 	cfg.BlockMinSize = minUint32(cfg.BlockMinSize, cfg.BlockMaxSize)
@@ -853,6 +1154,17 @@ func loadConfig() (*config, []string, error) {
 		cfg.BlockMaxWeight = cfg.BlockMaxSize * blockchain.WitnessScaleFactor
 	}
 
+	// The reserved block weight is part of the max block weight.
+	if cfg.BlockReservedWeight > cfg.BlockMaxWeight {
+		str := "%s: The blockreservedweight option may not be more " +
+			"than the max block weight %d -- parsed [%d]"
+		err := fmt.Errorf(str, funcName, cfg.BlockMaxWeight,
+			cfg.BlockReservedWeight)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
 	// Look for illegal characters in the user agent comments.
 	for _, uaComment := range cfg.UserAgentComments {
 		if strings.ContainsAny(uaComment, "/:()") {
@@ -875,6 +1187,25 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// --txindexstart must not be negative and the address index needs
+	// every block in the transaction index.
+	if cfg.TxIndexStart < 0 {
+		str := "%s: the --txindexstart option may not be negative: %d"
+		err := fmt.Errorf(str, funcName, cfg.TxIndexStart)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+	if cfg.TxIndexStart > 0 && cfg.AddrIndex {
+		err := fmt.Errorf("%s: the --addrindex and --txindexstart "+
+			"options may not be activated at the same time "+
+			"because the address index relies on a transaction "+
+			"index of every block", funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
 	// --addrindex and --dropaddrindex do not mix.
 	if cfg.AddrIndex && cfg.DropAddrIndex {
 		err := fmt.Errorf("%s: the --addrindex and --dropaddrindex "+
@@ -885,6 +1216,45 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// --utxostatsindex and --droputxostatsindex do not mix.
+	if cfg.UtxoStatsIndex && cfg.DropUtxoStatsIndex {
+		err := fmt.Errorf("%s: the --utxostatsindex and "+
+			"--droputxostatsindex options may not be activated at "+
+			"the same time", funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// --feestatsindex and --dropfeestatsindex do not mix.
+	if cfg.FeeStatsIndex && cfg.DropFeeStatsIndex {
+		err := fmt.Errorf("%s: the --feestatsindex and "+
+			"--dropfeestatsindex options may not be activated at "+
+			"the same time", funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// --utreexo and --droputreexo do not mix.
+	if cfg.Utreexo && cfg.DropUtreexo {
+		err := fmt.Errorf("%s: the --utreexo and --droputreexo options "+
+			"may not be activated at the same time", funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// --utxostatsinterval must not be negative.
+	if cfg.UtxoStatsInterval < 0 {
+		str := "%s: the --utxostatsinterval option may not be " +
+			"negative: %d"
+		err := fmt.Errorf(str, funcName, cfg.UtxoStatsInterval)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
 	// --addrindex and --droptxindex do not mix.
 	if cfg.AddrIndex && cfg.DropTxIndex {
 		err := fmt.Errorf("%s: the --addrindex and --droptxindex "+
@@ -934,11 +1304,36 @@ func loadConfig() (*config, []string, error) {
 	cfg.Listeners = normalizeAddresses(cfg.Listeners,
 		activeNetParams.DefaultPort)
 
+	// Validate the whitebind listeners and the permissions they grant, and
+	// add the default port to their addresses if needed.
+	cfg.whitebinds = make([]*whitebind, 0, len(cfg.WhiteBinds))
+	for _, addr := range cfg.WhiteBinds {
+		perms, listenAddr, err := parsePeerPermissions(addr,
+			defaultWhitelistPermissions)
+		if err != nil {
+			str := "%s: The whitebind value of '%s' is invalid: %v"
+			err := fmt.Errorf(str, funcName, addr, err)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+		cfg.whitebinds = append(cfg.whitebinds, &whitebind{
+			listenAddr: normalizeAddress(listenAddr,
+				activeNetParams.DefaultPort),
+			perms: perms,
+		})
+	}
+
 	// Add default port to all rpc listener addresses if needed and remove
 	// duplicate addresses.
 	cfg.RPCListeners = normalizeAddresses(cfg.RPCListeners,
 		activeNetParams.rpcPort)
 
+	// Add default port to all block explorer listener addresses if needed
+	// and remove duplicate addresses.
+	cfg.ExplorerListeners = normalizeAddresses(cfg.ExplorerListeners,
+		defaultExplorerPort)
+
 	// Only allow TLS to be disabled if the RPC is bound to localhost
 	// addresses.
 	if !cfg.DisableRPC && cfg.DisableTLS {
@@ -969,6 +1364,18 @@ func loadConfig() (*config, []string, error) {
 		}
 	}
 
+	// Client certificates and ACME certificates are only used with TLS.
+	if !cfg.DisableRPC && cfg.DisableTLS && (cfg.RPCClientCA != "" ||
+		cfg.RPCLimitClientCA != "" || len(cfg.RPCACMEDomains) > 0) {
+
+		str := "%s: the --notls option may not be used with client " +
+			"certificates or ACME certificates"
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
 	// Add default port to all added peer addresses if needed and remove
 	// duplicate addresses.
 	cfg.AddPeers = normalizeAddresses(cfg.AddPeers,
@@ -995,6 +1402,69 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// The partition detector needs a meaningful share of the peers.
+	if cfg.PartitionForkRatio <= 0 || cfg.PartitionForkRatio > 1 {
+		str := "%s: the partitionforkratio option must be greater " +
+			"than 0 and at most 1 -- parsed [%v]"
+		err := fmt.Errorf(str, funcName, cfg.PartitionForkRatio)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// The low disk space warning must be raised before the safe mode is
+	// entered.
+	if cfg.DiskWarnSpace != 0 && cfg.DiskWarnSpace <= cfg.DiskSafeModeSpace {
+		str := "%s: the diskwarnspace option must be greater than " +
+			"disksafemodespace -- parsed [%d] and [%d]"
+		err := fmt.Errorf(str, funcName, cfg.DiskWarnSpace,
+			cfg.DiskSafeModeSpace)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Listening on the wildcard and interface addresses needs an address
+	// family.
+	if cfg.NoListenIPv4 && cfg.NoListenIPv6 {
+		str := "%s: the --nolistenipv4 and --nolistenipv6 options can " +
+			"not be used together, use --nolisten instead"
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// The local clock can't be adjusted by a negative duration.
+	if cfg.MaxTimeAdjustment < 0 {
+		str := "%s: the maxtimeadjustment option may not be less " +
+			"than 0 -- parsed [%v]"
+		err := fmt.Errorf(str, funcName, cfg.MaxTimeAdjustment)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// The number of header probe peers can't be negative.
+	if cfg.HeaderProbePeers < 0 {
+		str := "%s: the headerprobepeers option may not be less " +
+			"than 0 -- parsed [%d]"
+		err := fmt.Errorf(str, funcName, cfg.HeaderProbePeers)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Check the message capture redaction options.
+	cfg.msgCaptureRedact, err = parseMsgCaptureRedaction(cfg.MsgCaptureRedact)
+	if err != nil {
+		str := "%s: %v"
+		err := fmt.Errorf(str, funcName, err)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
 	// Tor stream isolation requires either proxy or onion proxy to be set.
 	if cfg.TorIsolation && cfg.Proxy == "" && cfg.OnionProxy == "" {
 		str := "%s: Tor stream isolation requires either proxy or " +
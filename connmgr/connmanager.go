@@ -22,6 +22,18 @@ var (
 	//ErrDialNil is used to indicate that Dial cannot be nil in the configuration.
 	ErrDialNil = errors.New("Config: Dial cannot be nil")
 
+	// ErrNoAccept is used to indicate that a listener can't be added since
+	// no OnAccept callback was configured.
+	ErrNoAccept = errors.New("Config: OnAccept is nil, can't accept connections")
+
+	// ErrStopped is used to indicate that a listener can't be added since
+	// the connection manager was stopped.
+	ErrStopped = errors.New("connection manager stopped")
+
+	// ErrListenerNotFound is used to indicate that no listener is bound to
+	// the address of a listener to remove.
+	ErrListenerNotFound = errors.New("listener not found")
+
 	// maxRetryDuration is the max duration of time retrying of a persistent
 	// connection is allowed to grow to.  This is necessary since the retry
 	// logic uses a backoff mechanism which increases the interval base times
@@ -183,6 +195,13 @@ type ConnManager struct {
 	failedAttempts uint64
 	requests       chan interface{}
 	quit           chan struct{}
+
+	// listeners are the listeners accepting connections, those of the
+	// configuration and the ones added since.  listening is set once their
+	// handlers were started.
+	listenerMtx sync.Mutex
+	listeners   []net.Listener
+	listening   bool
 }
 
 // handleFailedConn handles a connection failed due to a disconnect or any
@@ -493,8 +512,13 @@ func (cm *ConnManager) listenHandler(listener net.Listener) {
 	for atomic.LoadInt32(&cm.stop) == 0 {
 		conn, err := listener.Accept()
 		if err != nil {
-			// Only log the error if not forcibly shutting down.
+			// Only log the error if not forcibly shutting down, and
+			// stop accepting connections once the listener was
+			// removed.
 			if atomic.LoadInt32(&cm.stop) == 0 {
+				if !cm.hasListener(listener) {
+					break
+				}
 				log.Errorf("Can't accept connection: %v", err)
 			}
 			continue
@@ -506,6 +530,73 @@ func (cm *ConnManager) listenHandler(listener net.Listener) {
 	log.Tracef("Listener handler done for %s", listener.Addr())
 }
 
+// hasListener returns whether the passed listener still accepts connections for
+// the connection manager.
+func (cm *ConnManager) hasListener(listener net.Listener) bool {
+	cm.listenerMtx.Lock()
+	defer cm.listenerMtx.Unlock()
+
+	for _, l := range cm.listeners {
+		if l == listener {
+			return true
+		}
+	}
+	return false
+}
+
+// AddListener adds a listener to accept connections on.  The connection manager
+// takes ownership of it like of the listeners of the configuration, and starts
+// accepting connections on it right away when it is running.
+func (cm *ConnManager) AddListener(listener net.Listener) error {
+	if cm.cfg.OnAccept == nil {
+		return ErrNoAccept
+	}
+
+	cm.listenerMtx.Lock()
+	defer cm.listenerMtx.Unlock()
+
+	if atomic.LoadInt32(&cm.stop) != 0 {
+		return ErrStopped
+	}
+	cm.listeners = append(cm.listeners, listener)
+	if cm.listening {
+		cm.wg.Add(1)
+		go cm.listenHandler(listener)
+	}
+	return nil
+}
+
+// RemoveListener stops accepting connections on the listener bound to the
+// passed address and closes it.  Connections accepted before are left alone.
+func (cm *ConnManager) RemoveListener(addr string) error {
+	cm.listenerMtx.Lock()
+	var listener net.Listener
+	for i, l := range cm.listeners {
+		if l.Addr().String() == addr {
+			listener = l
+			cm.listeners = append(cm.listeners[:i:i],
+				cm.listeners[i+1:]...)
+			break
+		}
+	}
+	cm.listenerMtx.Unlock()
+
+	if listener == nil {
+		return ErrListenerNotFound
+	}
+	return listener.Close()
+}
+
+// Listeners returns the listeners accepting connections.
+func (cm *ConnManager) Listeners() []net.Listener {
+	cm.listenerMtx.Lock()
+	defer cm.listenerMtx.Unlock()
+
+	listeners := make([]net.Listener, len(cm.listeners))
+	copy(listeners, cm.listeners)
+	return listeners
+}
+
 // Start launches the connection manager and begins connecting to the network.
 func (cm *ConnManager) Start() {
 	// Already started?
@@ -520,10 +611,13 @@ func (cm *ConnManager) Start() {
 	// Start all the listeners so long as the caller requested them and
 	// provided a callback to be invoked when connections are accepted.
 	if cm.cfg.OnAccept != nil {
-		for _, listner := range cm.cfg.Listeners {
+		cm.listenerMtx.Lock()
+		for _, listner := range cm.listeners {
 			cm.wg.Add(1)
 			go cm.listenHandler(listner)
 		}
+		cm.listening = true
+		cm.listenerMtx.Unlock()
 	}
 
 	for i := atomic.LoadUint64(&cm.connReqCount); i < uint64(cm.cfg.TargetOutbound); i++ {
@@ -545,11 +639,13 @@ func (cm *ConnManager) Stop() {
 
 	// Stop all the listeners.  There will not be any listeners if
 	// listening is disabled.
-	for _, listener := range cm.cfg.Listeners {
+	cm.listenerMtx.Lock()
+	for _, listener := range cm.listeners {
 		// Ignore the error since this is shutdown and there is no way
 		// to recover anyways.
 		_ = listener.Close()
 	}
+	cm.listenerMtx.Unlock()
 
 	close(cm.quit)
 	log.Trace("Connection manager stopped")
@@ -569,9 +665,10 @@ func New(cfg *Config) (*ConnManager, error) {
 		cfg.TargetOutbound = defaultTargetOutbound
 	}
 	cm := ConnManager{
-		cfg:      *cfg, // Copy so caller can't mutate
-		requests: make(chan interface{}),
-		quit:     make(chan struct{}),
+		cfg:       *cfg, // Copy so caller can't mutate
+		requests:  make(chan interface{}),
+		quit:      make(chan struct{}),
+		listeners: append([]net.Listener(nil), cfg.Listeners...),
 	}
 	return &cm, nil
 }
@@ -664,3 +664,57 @@ out:
 	cmgr.Stop()
 	cmgr.Wait()
 }
+
+// TestAddRemoveListener ensures listeners can be added to and removed from a
+// running connection manager.
+func TestAddRemoveListener(t *testing.T) {
+	receivedConns := make(chan net.Conn)
+	listener1 := newMockListener("127.0.0.1:8333")
+	cmgr, err := New(&Config{
+		Listeners: []net.Listener{listener1},
+		OnAccept: func(conn net.Conn) {
+			receivedConns <- conn
+		},
+		Dial: mockDialer,
+	})
+	if err != nil {
+		t.Fatalf("New error: %v", err)
+	}
+	cmgr.Start()
+
+	// Connections are accepted on a listener added while running.
+	listener2 := newMockListener("[::1]:8333")
+	if err := cmgr.AddListener(listener2); err != nil {
+		t.Fatalf("AddListener error: %v", err)
+	}
+	go listener2.Connect("::1", 10000)
+	select {
+	case conn := <-receivedConns:
+		if conn.LocalAddr().String() != "[::1]:8333" {
+			t.Fatalf("connection accepted on %v", conn.LocalAddr())
+		}
+	case <-time.After(time.Millisecond * 50):
+		t.Fatal("Timeout waiting for the connection")
+	}
+	if got := cmgr.Listeners(); len(got) != 2 || got[1] != listener2 {
+		t.Fatalf("unexpected listeners %v", got)
+	}
+
+	// A removed listener is closed and forgotten.
+	if err := cmgr.RemoveListener("[::1]:8333"); err != nil {
+		t.Fatalf("RemoveListener error: %v", err)
+	}
+	if err := cmgr.RemoveListener("[::1]:8333"); err != ErrListenerNotFound {
+		t.Fatalf("RemoveListener: got %v, want %v", err,
+			ErrListenerNotFound)
+	}
+	if got := cmgr.Listeners(); len(got) != 1 || got[0] != listener1 {
+		t.Fatalf("unexpected listeners %v", got)
+	}
+
+	cmgr.Stop()
+	cmgr.Wait()
+	if err := cmgr.AddListener(listener2); err != ErrStopped {
+		t.Fatalf("AddListener: got %v, want %v", err, ErrStopped)
+	}
+}
@@ -0,0 +1,476 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/database"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+const (
+	// consensusRecordingVersion is the version of the consensus recording
+	// file format.
+	consensusRecordingVersion = 1
+
+	// consensusReplayLogInterval is the number of replayed blocks between
+	// progress messages.
+	consensusReplayLogInterval = 10000
+)
+
+// consensusRecordingMagic starts every consensus recording file.
+var consensusRecordingMagic = [8]byte{'p', 'k', 't', 'd', 'r', 'p', 'l', 'y'}
+
+// Outcome bits of a consensus record.
+const (
+	consensusMainChain uint8 = 1 << iota
+	consensusOrphan
+	consensusFailed
+)
+
+// consensusRecord is a block processed by the chain along with the inputs it
+// was processed with and the resulting chain state.
+//
+// The digest commits to the digest of the previous record, the block, whether
+// it was accepted and the resulting best chain state, so the digests of two
+// runs processing the same blocks are equal for as long as they agree on
+// consensus.  The error message is recorded to help debugging, but it is not
+// part of the digest since it does not affect consensus.
+type consensusRecord struct {
+	adjustedTime time.Time
+	flags        blockchain.BehaviorFlags
+	block        []byte
+	outcome      uint8
+	errMsg       string
+	bestHash     chainhash.Hash
+	bestHeight   int32
+	totalTxns    uint64
+	digest       chainhash.Hash
+}
+
+// newConsensusRecord returns the record of a processed block, chained to the
+// record with the passed digest.
+func newConsensusRecord(pb *blockchain.ProcessedBlock, prevDigest *chainhash.Hash) (*consensusRecord, error) {
+	block, err := pb.Block.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	rec := &consensusRecord{
+		adjustedTime: pb.AdjustedTime,
+		flags:        pb.Flags,
+		block:        block,
+		bestHash:     pb.Best.Hash,
+		bestHeight:   pb.Best.Height,
+		totalTxns:    pb.Best.TotalTxns,
+	}
+	if pb.IsMainChain {
+		rec.outcome |= consensusMainChain
+	}
+	if pb.IsOrphan {
+		rec.outcome |= consensusOrphan
+	}
+	if pb.Err != nil {
+		rec.outcome |= consensusFailed
+		rec.errMsg = pb.Err.Error()
+	}
+
+	var buf [4 + 8]byte
+	h := sha256.New()
+	h.Write(prevDigest[:])
+	h.Write(pb.Block.Hash()[:])
+	h.Write([]byte{rec.outcome})
+	h.Write(rec.bestHash[:])
+	binary.LittleEndian.PutUint32(buf[0:4], uint32(rec.bestHeight))
+	binary.LittleEndian.PutUint64(buf[4:12], rec.totalTxns)
+	h.Write(buf[:])
+	copy(rec.digest[:], h.Sum(nil))
+	return rec, nil
+}
+
+// String returns a human-readable description of the outcome of the record.
+func (rec *consensusRecord) String() string {
+	var result string
+	switch {
+	case rec.outcome&consensusFailed != 0:
+		result = "rejected (" + rec.errMsg + ")"
+	case rec.outcome&consensusOrphan != 0:
+		result = "orphan"
+	case rec.outcome&consensusMainChain != 0:
+		result = "main chain"
+	default:
+		result = "side chain"
+	}
+	return fmt.Sprintf("%s, best chain %v at height %d with %d "+
+		"transactions", result, rec.bestHash, rec.bestHeight,
+		rec.totalTxns)
+}
+
+// writeConsensusHeader writes the header of a consensus recording.
+func writeConsensusHeader(w io.Writer, net wire.BitcoinNet) error {
+	var buf [len(consensusRecordingMagic) + 8]byte
+	copy(buf[:], consensusRecordingMagic[:])
+	binary.LittleEndian.PutUint32(buf[8:12], consensusRecordingVersion)
+	binary.LittleEndian.PutUint32(buf[12:16], uint32(net))
+	_, err := w.Write(buf[:])
+	return err
+}
+
+// readConsensusHeader reads the header of a consensus recording and returns
+// the network it was recorded on.
+func readConsensusHeader(r io.Reader) (wire.BitcoinNet, error) {
+	var buf [len(consensusRecordingMagic) + 8]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return 0, err
+	}
+	if !bytes.Equal(buf[:8], consensusRecordingMagic[:]) {
+		return 0, errors.New("not a consensus recording")
+	}
+	version := binary.LittleEndian.Uint32(buf[8:12])
+	if version != consensusRecordingVersion {
+		return 0, fmt.Errorf("unsupported consensus recording version %d",
+			version)
+	}
+	return wire.BitcoinNet(binary.LittleEndian.Uint32(buf[12:16])), nil
+}
+
+// write serializes the record.
+func (rec *consensusRecord) write(w io.Writer) error {
+	var buf [8 + 4 + 1]byte
+	binary.LittleEndian.PutUint64(buf[0:8], uint64(rec.adjustedTime.UnixNano()))
+	binary.LittleEndian.PutUint32(buf[8:12], uint32(rec.flags))
+	buf[12] = rec.outcome
+	if _, err := w.Write(buf[:]); err != nil {
+		return err
+	}
+	if err := wire.WriteVarBytes(w, 0, rec.block); err != nil {
+		return err
+	}
+	if err := wire.WriteVarString(w, 0, rec.errMsg); err != nil {
+		return err
+	}
+	var state [32 + 4 + 8 + 32]byte
+	copy(state[0:32], rec.bestHash[:])
+	binary.LittleEndian.PutUint32(state[32:36], uint32(rec.bestHeight))
+	binary.LittleEndian.PutUint64(state[36:44], rec.totalTxns)
+	copy(state[44:76], rec.digest[:])
+	_, err := w.Write(state[:])
+	return err
+}
+
+// readConsensusRecord deserializes a record.  It returns io.EOF at the end of
+// the recording.
+func readConsensusRecord(r io.Reader) (*consensusRecord, error) {
+	var buf [8 + 4 + 1]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return nil, err
+	}
+	rec := &consensusRecord{
+		adjustedTime: time.Unix(0, int64(binary.LittleEndian.Uint64(buf[0:8]))),
+		flags:        blockchain.BehaviorFlags(binary.LittleEndian.Uint32(buf[8:12])),
+		outcome:      buf[12],
+	}
+	var err error
+	rec.block, err = wire.ReadVarBytes(r, 0, wire.MaxBlockPayload, "block")
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	rec.errMsg, err = wire.ReadVarString(r, 0)
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	var state [32 + 4 + 8 + 32]byte
+	if _, err := io.ReadFull(r, state[:]); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	copy(rec.bestHash[:], state[0:32])
+	rec.bestHeight = int32(binary.LittleEndian.Uint32(state[32:36]))
+	rec.totalTxns = binary.LittleEndian.Uint64(state[36:44])
+	copy(rec.digest[:], state[44:76])
+	return rec, nil
+}
+
+// unexpectedEOF converts io.EOF to io.ErrUnexpectedEOF since a record which
+// was started must be complete.
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// consensusRecorder records every block processed by the chain to a file so
+// that it can be replayed with --replayconsensus.  It implements the
+// blockchain.BlockRecorder interface.
+type consensusRecorder struct {
+	mtx    sync.Mutex
+	file   *os.File
+	w      *bufio.Writer
+	digest chainhash.Hash
+	failed bool
+}
+
+// Ensure consensusRecorder implements the blockchain.BlockRecorder interface.
+var _ blockchain.BlockRecorder = (*consensusRecorder)(nil)
+
+// newConsensusRecorder creates the recording file, overwriting any existing
+// one, and returns a recorder writing to it.
+func newConsensusRecorder(path string, net wire.BitcoinNet) (*consensusRecorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w := bufio.NewWriter(file)
+	if err := writeConsensusHeader(w, net); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &consensusRecorder{file: file, w: w}, nil
+}
+
+// RecordBlock appends the processed block to the recording.  Recording stops
+// at the first write error since the recording could not be replayed past it.
+//
+// This is part of the blockchain.BlockRecorder interface.
+func (r *consensusRecorder) RecordBlock(pb *blockchain.ProcessedBlock) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if r.failed || r.file == nil {
+		return
+	}
+	rec, err := newConsensusRecord(pb, &r.digest)
+	if err == nil {
+		err = rec.write(r.w)
+	}
+	if err == nil {
+		err = r.w.Flush()
+	}
+	if err != nil {
+		r.failed = true
+		srvrLog.Errorf("Unable to record block %v, consensus recording "+
+			"stopped: %v", pb.Block.Hash(), err)
+		return
+	}
+	r.digest = rec.digest
+}
+
+// Close closes the recording file.
+func (r *consensusRecorder) Close() error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+// replayTimeSource is a median time source returning the adjusted time of the
+// record being replayed.
+type replayTimeSource struct {
+	mtx sync.Mutex
+	now time.Time
+}
+
+// AdjustedTime returns the adjusted time of the record being replayed.
+//
+// This is part of the blockchain.MedianTimeSource interface.
+func (s *replayTimeSource) AdjustedTime() time.Time {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.now
+}
+
+// AddTimeSample does nothing since the adjusted time is recorded.
+//
+// This is part of the blockchain.MedianTimeSource interface.
+func (s *replayTimeSource) AddTimeSample(string, time.Time) {}
+
+// Offset returns zero since the adjusted time is recorded.
+//
+// This is part of the blockchain.MedianTimeSource interface.
+func (s *replayTimeSource) Offset() time.Duration {
+	return 0
+}
+
+// Status returns an empty status since the adjusted time is recorded.
+//
+// This is part of the blockchain.MedianTimeSource interface.
+func (s *replayTimeSource) Status() blockchain.MedianTimeStatus {
+	return blockchain.MedianTimeStatus{}
+}
+
+// set sets the adjusted time returned by the time source.
+func (s *replayTimeSource) set(now time.Time) {
+	s.mtx.Lock()
+	s.now = now
+	s.mtx.Unlock()
+}
+
+// consensusReplayConfig is the configuration of a consensus replay.
+type consensusReplayConfig struct {
+	// Path is the path of the consensus recording to replay.
+	Path string
+
+	// ChainParams are the parameters of the network the recording is
+	// expected to be for.
+	ChainParams *chaincfg.Params
+
+	// DbType is the database backend of the temporary chain.
+	DbType string
+
+	// Checkpoints are the checkpoints of the temporary chain.
+	Checkpoints []chaincfg.Checkpoint
+
+	// SigCacheMaxSize is the size of the signature cache.
+	SigCacheMaxSize uint
+
+	// Interrupt is closed to stop the replay.
+	Interrupt <-chan struct{}
+}
+
+// replayConsensus processes the blocks of a consensus recording, in their
+// recorded order and at their recorded adjusted time, against a new chain in a
+// temporary database.  It returns an error describing the first block whose
+// resulting chain state differs from the recording.
+func replayConsensus(rcfg *consensusReplayConfig) error {
+	path, params := rcfg.Path, rcfg.ChainParams
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	r := bufio.NewReader(file)
+
+	net, err := readConsensusHeader(r)
+	if err != nil {
+		return fmt.Errorf("unable to read consensus recording %s: %v",
+			path, err)
+	}
+	if net != params.Net {
+		return fmt.Errorf("consensus recording %s is for network %v, "+
+			"not %v", path, net, params.Net)
+	}
+
+	// Replay against a chain in a temporary database so the block database
+	// of the node is left untouched.
+	var db database.DB
+	if rcfg.DbType == "memdb" {
+		db, err = database.Create(rcfg.DbType)
+	} else {
+		var tmpDir string
+		tmpDir, err = ioutil.TempDir("", "pktd-replay")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(tmpDir)
+		dbPath := filepath.Join(tmpDir, blockDbNamePrefix+"_"+rcfg.DbType)
+		db, err = database.Create(rcfg.DbType, dbPath, params.Net)
+	}
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	timeSource := &replayTimeSource{}
+	chain, err := blockchain.New(&blockchain.Config{
+		DB:          db,
+		Interrupt:   rcfg.Interrupt,
+		ChainParams: params,
+		Checkpoints: rcfg.Checkpoints,
+		TimeSource:  timeSource,
+		SigCache:    txscript.NewSigCache(rcfg.SigCacheMaxSize),
+		HashCache:   txscript.NewHashCache(rcfg.SigCacheMaxSize),
+	})
+	if err != nil {
+		return err
+	}
+
+	pktdLog.Infof("Replaying consensus recording %s", path)
+	var digest chainhash.Hash
+	var count int
+	for {
+		if interruptRequested(rcfg.Interrupt) {
+			return errors.New("consensus replay interrupted")
+		}
+
+		recorded, err := readConsensusRecord(r)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("unable to read record %d of consensus "+
+				"recording %s: %v", count, path, err)
+		}
+		block, err := btcutil.NewBlockFromBytes(recorded.block)
+		if err != nil {
+			return fmt.Errorf("unable to decode block of record %d: %v",
+				count, err)
+		}
+
+		timeSource.set(recorded.adjustedTime)
+		isMainChain, isOrphan, processErr := chain.ProcessBlock(block,
+			recorded.flags)
+		replayed, err := newConsensusRecord(&blockchain.ProcessedBlock{
+			Block:        block,
+			Flags:        recorded.flags,
+			AdjustedTime: recorded.adjustedTime,
+			IsMainChain:  isMainChain,
+			IsOrphan:     isOrphan,
+			Err:          processErr,
+			Best:         chain.BestSnapshot(),
+		}, &digest)
+		if err != nil {
+			return err
+		}
+		if replayed.digest != recorded.digest {
+			return fmt.Errorf("consensus diverged at record %d, block "+
+				"%v processed at %v: recorded %v, replayed %v",
+				count, block.Hash(), recorded.adjustedTime,
+				recorded, replayed)
+		}
+		if recorded.errMsg != replayed.errMsg {
+			pktdLog.Warnf("Block %v of record %d was rejected with a "+
+				"different error: recorded %q, replayed %q",
+				block.Hash(), count, recorded.errMsg,
+				replayed.errMsg)
+		}
+		digest = replayed.digest
+		count++
+
+		if count%consensusReplayLogInterval == 0 {
+			pktdLog.Infof("Replayed %d blocks, height %d", count,
+				replayed.bestHeight)
+		}
+	}
+
+	best := chain.BestSnapshot()
+	pktdLog.Infof("Replayed %d blocks without divergence, best chain %v "+
+		"at height %d", count, best.Hash, best.Height)
+	return nil
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+)
+
+// TestConsensusReplay ensures the blocks recorded while processing them are
+// replayed with the same outcome, and that a diverging chain state is
+// reported.
+func TestConsensusReplay(t *testing.T) {
+	params := &chaincfg.RegressionNetParams
+	dir, err := ioutil.TempDir("", "pktd-consensusreplay")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "consensus.rec")
+
+	recorder, err := newConsensusRecorder(path, params.Net)
+	if err != nil {
+		t.Fatalf("newConsensusRecorder: %v", err)
+	}
+	tc, teardown := newTestChain(t, "consensusreplay",
+		func(config *blockchain.Config) {
+			config.BlockRecorder = recorder
+		})
+	defer teardown()
+	chain := tc.chain
+
+	// Process the genesis block again, which is rejected as a duplicate,
+	// and an orphan block.
+	genesis := btcutil.NewBlock(params.GenesisBlock)
+	if _, _, err := chain.ProcessBlock(genesis, blockchain.BFNone); err == nil {
+		t.Fatal("duplicate genesis block accepted")
+	}
+	orphanMsg := *params.GenesisBlock
+	orphanMsg.Header.PrevBlock = chainhash.Hash{1}
+	chain.ProcessBlock(btcutil.NewBlock(&orphanMsg), blockchain.BFNone)
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	rcfg := &consensusReplayConfig{
+		Path:            path,
+		ChainParams:     params,
+		DbType:          "ffldb",
+		SigCacheMaxSize: 100,
+	}
+	if err := replayConsensus(rcfg); err != nil {
+		t.Fatalf("replayConsensus: %v", err)
+	}
+
+	// Alter the chain state digest of the last record.
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	data[len(data)-1] ^= 0xff
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	err = replayConsensus(rcfg)
+	if err == nil || !strings.Contains(err.Error(), "diverged at record 1") {
+		t.Fatalf("unexpected replay error: %v", err)
+	}
+
+	// Truncated recordings and recordings of another network are
+	// rejected.
+	if err := ioutil.WriteFile(path, data[:len(data)-10], 0600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if err := replayConsensus(rcfg); err == nil {
+		t.Fatal("truncated recording replayed")
+	}
+	rcfg.ChainParams = &chaincfg.SimNetParams
+	if err := replayConsensus(rcfg); err == nil {
+		t.Fatal("recording replayed on another network")
+	}
+}
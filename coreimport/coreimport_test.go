@@ -0,0 +1,160 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package coreimport
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/btcutil/hdkeychain"
+	"github.com/pkt-cash/pktd/acctbundle"
+	"github.com/pkt-cash/pktd/btcec"
+	"github.com/pkt-cash/pktd/chaincfg"
+)
+
+// testWIF returns a private key made of the passed byte in wallet import
+// format.
+func testWIF(t *testing.T, b byte) string {
+	privKey, _ := btcec.PrivKeyFromBytes(btcec.S256(),
+		bytes.Repeat([]byte{b}, 32))
+	wif, err := btcutil.NewWIF(privKey, &chaincfg.MainNetParams, true)
+	if err != nil {
+		t.Fatalf("NewWIF: %v", err)
+	}
+	return wif.String()
+}
+
+// TestParseDump ensures the keys, scripts and labels of a wallet dump are read.
+func TestParseDump(t *testing.T) {
+	dump := `# Wallet dump created by Bitcoin v0.21.0
+# * Created on 2021-01-01T00:00:00Z
+
+# extended private masterkey: xprv9s21ZrQH143K
+
+` + testWIF(t, 1) + ` 2020-05-01T10:00:00Z hdseed=1 # addr=1Seed hdkeypath=s
+` + testWIF(t, 2) + ` 2020-05-01T10:00:01Z label=my%20savings # addr=bc1qa,1A hdkeypath=m/0'/0'/0'
+` + testWIF(t, 3) + ` 2020-05-01T10:00:02Z change=1 # addr=bc1qb hdkeypath=m/0'/1'/0'
+` + testWIF(t, 4) + ` 2020-05-01T10:00:03Z reserve=1 # addr=bc1qc
+0014abcd 0 script=1 # addr=3Script
+
+# End of dump
+`
+	d, err := ParseDump(strings.NewReader(dump))
+	if err != nil {
+		t.Fatalf("ParseDump: %v", err)
+	}
+	if d.MasterKey != "xprv9s21ZrQH143K" {
+		t.Fatalf("unexpected master key %q", d.MasterKey)
+	}
+	if len(d.Keys) != 4 || len(d.Scripts) != 1 {
+		t.Fatalf("unexpected dump %+v", d)
+	}
+	if !d.Keys[0].HDSeed || d.Keys[1].Label != "my savings" ||
+		!d.Keys[2].Change || !d.Keys[3].Reserve {
+
+		t.Fatalf("unexpected key flags %+v", d.Keys)
+	}
+	key := d.Keys[1]
+	if key.WIF.String() != testWIF(t, 2) ||
+		!reflect.DeepEqual(key.Addresses, []string{"bc1qa", "1A"}) ||
+		key.KeyPath != "m/0'/0'/0'" ||
+		!key.Time.Equal(time.Date(2020, 5, 1, 10, 0, 1, 0, time.UTC)) {
+
+		t.Fatalf("unexpected key %+v", key)
+	}
+	script := d.Scripts[0]
+	if !bytes.Equal(script.Script, []byte{0x00, 0x14, 0xab, 0xcd}) ||
+		!script.Time.IsZero() || script.Addresses[0] != "3Script" {
+
+		t.Fatalf("unexpected script %+v", script)
+	}
+
+	for _, bad := range []string{
+		"notakey 2020-05-01T10:00:00Z label= # addr=1A",
+		testWIF(t, 1) + " yesterday label=",
+		"zz 0 script=1",
+		testWIF(t, 1),
+	} {
+		if _, err := ParseDump(strings.NewReader(bad)); err == nil {
+			t.Errorf("ParseDump accepted %q", bad)
+		}
+	}
+}
+
+// TestParseDescriptor ensures single key descriptors are broken down and the
+// other ones are rejected.
+func TestParseDescriptor(t *testing.T) {
+	master, err := hdkeychain.NewMaster(bytes.Repeat([]byte{1}, 32),
+		&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("NewMaster: %v", err)
+	}
+	xpub, err := master.Neuter()
+	if err != nil {
+		t.Fatalf("Neuter: %v", err)
+	}
+	desc, err := acctbundle.AddDescriptorChecksum("sh(wpkh([d34db33f/49h/0h/0h]" +
+		xpub.String() + "/1/*))")
+	if err != nil {
+		t.Fatalf("AddDescriptorChecksum: %v", err)
+	}
+	export := `{"wallet_name":"w","descriptors":[{"desc":"` + desc +
+		`","timestamp":1600000000,"active":true,"internal":true,"range":[0,999],"next":12}]}`
+	e, err := ParseDescriptorExport([]byte(export))
+	if err != nil {
+		t.Fatalf("ParseDescriptorExport: %v", err)
+	}
+	if e.WalletName != "w" || len(e.Descriptors) != 1 ||
+		!e.Descriptors[0].Internal || e.Descriptors[0].Next != 12 {
+
+		t.Fatalf("unexpected export %+v", e)
+	}
+
+	d, err := ParseDescriptor(e.Descriptors[0].Desc)
+	if err != nil {
+		t.Fatalf("ParseDescriptor: %v", err)
+	}
+	if d.ScriptType != acctbundle.ScriptP2SHP2WPKH || d.Key != xpub.String() ||
+		!d.IsExtended() || !d.Ranged || d.HardenedRange ||
+		!reflect.DeepEqual(d.Path, []uint32{1}) ||
+		d.Origin.String() != "[d34db33f/49'/0'/0']" {
+
+		t.Fatalf("unexpected descriptor %+v", d)
+	}
+
+	d, err = ParseDescriptor("wpkh(" + xpub.String() + "/0h/*h)")
+	if err != nil {
+		t.Fatalf("ParseDescriptor: %v", err)
+	}
+	if d.ScriptType != acctbundle.ScriptP2WPKH || d.Origin != nil ||
+		!d.HardenedRange || len(d.Path) != 1 {
+
+		t.Fatalf("unexpected descriptor %+v", d)
+	}
+
+	d, err = ParseDescriptor("pkh(" + testWIF(t, 5) + ")")
+	if err != nil {
+		t.Fatalf("ParseDescriptor: %v", err)
+	}
+	if d.ScriptType != acctbundle.ScriptP2PKH || d.IsExtended() || d.Ranged {
+		t.Fatalf("unexpected descriptor %+v", d)
+	}
+
+	for _, bad := range []string{
+		"multi(1,02aa,02bb)",
+		"tr(" + xpub.String() + ")",
+		"sh(multi(1,02aa,02bb))",
+		"pkh(02aa/0/*)",
+		desc[:len(desc)-1] + "x",
+	} {
+		if _, err := ParseDescriptor(bad); err == nil {
+			t.Errorf("ParseDescriptor accepted %q", bad)
+		}
+	}
+}
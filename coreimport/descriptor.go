@@ -0,0 +1,166 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package coreimport
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/pkt-cash/btcutil/hdkeychain"
+	"github.com/pkt-cash/pktd/acctbundle"
+)
+
+// ErrUnsupportedDescriptor is returned for descriptors which do not map to a
+// pktwallet account or key.
+var ErrUnsupportedDescriptor = errors.New("unsupported descriptor")
+
+// ExportedDescriptor is a descriptor of the export of a descriptor wallet.
+type ExportedDescriptor struct {
+	// Desc is the descriptor, with its checksum.
+	Desc string `json:"desc"`
+
+	// Timestamp is the creation time of the descriptor in seconds since
+	// the epoch.
+	Timestamp int64 `json:"timestamp"`
+
+	// Active is set for the descriptors the wallet derives new addresses
+	// from.
+	Active bool `json:"active"`
+
+	// Internal is set for the descriptors of change addresses.
+	Internal bool `json:"internal,omitempty"`
+
+	// Range is the range of indexes derived from a ranged descriptor.
+	Range []int64 `json:"range,omitempty"`
+
+	// Next is the index of the next address derived from a ranged
+	// descriptor.
+	Next int64 `json:"next,omitempty"`
+
+	// Label is the label of a descriptor which is not ranged.
+	Label string `json:"label,omitempty"`
+}
+
+// DescriptorExport is the export of a descriptor wallet written by the
+// listdescriptors command of the reference client.
+type DescriptorExport struct {
+	// WalletName is the name of the exported wallet.
+	WalletName string `json:"wallet_name"`
+
+	// Descriptors are the descriptors of the wallet.
+	Descriptors []ExportedDescriptor `json:"descriptors"`
+}
+
+// ParseDescriptorExport parses the export of a descriptor wallet.
+func ParseDescriptorExport(data []byte) (*DescriptorExport, error) {
+	var export DescriptorExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// Descriptor is a single key output script descriptor.
+type Descriptor struct {
+	// ScriptType is the type of the scripts described.
+	ScriptType acctbundle.ScriptType
+
+	// Origin is the origin of the key, nil when the descriptor does not
+	// give it.
+	Origin *acctbundle.KeyOrigin
+
+	// Key is the key as written in the descriptor: a hex encoded public
+	// key, a private key in wallet import format or an extended key.
+	Key string
+
+	// Path is the derivation path below an extended key, without the
+	// final wildcard of ranged descriptors.
+	Path []uint32
+
+	// Ranged is set when the descriptor describes a range of keys derived
+	// at the last index of the path.
+	Ranged bool
+
+	// HardenedRange is set when the keys of a ranged descriptor are
+	// hardened, so they can only be derived from a private key.
+	HardenedRange bool
+}
+
+// IsExtended returns whether the key of the descriptor is an extended key.
+func (d *Descriptor) IsExtended() bool {
+	_, err := hdkeychain.NewKeyFromString(d.Key)
+	return err == nil
+}
+
+// ParseDescriptor parses a pkh, wpkh or sh(wpkh) descriptor, verifying its
+// checksum when it has one.
+func ParseDescriptor(desc string) (*Descriptor, error) {
+	desc, err := acctbundle.CheckDescriptor(desc)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Descriptor{}
+	var key string
+	switch {
+	case strings.HasPrefix(desc, "sh(wpkh(") && strings.HasSuffix(desc, "))"):
+		d.ScriptType = acctbundle.ScriptP2SHP2WPKH
+		key = desc[len("sh(wpkh(") : len(desc)-2]
+	case strings.HasPrefix(desc, "wpkh(") && strings.HasSuffix(desc, ")"):
+		d.ScriptType = acctbundle.ScriptP2WPKH
+		key = desc[len("wpkh(") : len(desc)-1]
+	case strings.HasPrefix(desc, "pkh(") && strings.HasSuffix(desc, ")"):
+		d.ScriptType = acctbundle.ScriptP2PKH
+		key = desc[len("pkh(") : len(desc)-1]
+	default:
+		return nil, ErrUnsupportedDescriptor
+	}
+	if strings.ContainsAny(key, "(),") {
+		return nil, ErrUnsupportedDescriptor
+	}
+
+	if strings.HasPrefix(key, "[") {
+		end := strings.IndexByte(key, ']')
+		if end < 0 {
+			return nil, acctbundle.ErrInvalidKeyOrigin
+		}
+		d.Origin, err = acctbundle.ParseKeyOrigin(key[:end+1])
+		if err != nil {
+			return nil, err
+		}
+		key = key[end+1:]
+	}
+
+	// Only extended keys may be followed by a derivation path.
+	elems := strings.SplitN(key, "/", 2)
+	d.Key = elems[0]
+	if len(elems) == 1 {
+		return d, nil
+	}
+	if !d.IsExtended() {
+		return nil, ErrUnsupportedDescriptor
+	}
+	path := elems[1]
+	switch {
+	case strings.HasSuffix(path, "/*'") || strings.HasSuffix(path, "/*h"):
+		d.Ranged, d.HardenedRange = true, true
+		path = path[:len(path)-3]
+	case strings.HasSuffix(path, "/*"):
+		d.Ranged = true
+		path = path[:len(path)-2]
+	case path == "*'" || path == "*h":
+		d.Ranged, d.HardenedRange = true, true
+		path = ""
+	case path == "*":
+		d.Ranged = true
+		path = ""
+	}
+	d.Path, err = acctbundle.ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
@@ -0,0 +1,28 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package coreimport reads the wallet exports of the bitcoin reference client so
+their keys, scripts and labels can be imported into a pktwallet.
+
+Two formats are supported.  The dump written by the dumpwallet command of a
+legacy wallet lists one private key or script per line, with its creation
+time, its label or role and the addresses it was used with:
+
+	# extended private masterkey: xprv...
+	<wif> <time> label=<label> # addr=<address> hdkeypath=m/0'/0'/1'
+	<wif> <time> change=1 # addr=<address> hdkeypath=m/0'/1'/0'
+	<hex script> 0 script=1 # addr=<address>
+
+The JSON written by the listdescriptors command of a descriptor wallet lists
+output script descriptors, which ParseDescriptor breaks down into their script
+type, key origin, key and derivation path.  Only the single key descriptors
+pkh, wpkh and sh(wpkh) map to pktwallet accounts; the others are reported as
+unsupported.
+
+The keys and addresses of the reference client are for the bitcoin network, so
+the importer derives the pktwallet addresses from the keys rather than using
+the addresses of the export.
+*/
+package coreimport
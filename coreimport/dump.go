@@ -0,0 +1,187 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package coreimport
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkt-cash/btcutil"
+)
+
+// masterKeyPrefix starts the comment holding the extended master key of a
+// dump.
+const masterKeyPrefix = "# extended private masterkey: "
+
+// DumpKey is a private key of a wallet dump.
+type DumpKey struct {
+	// WIF is the private key.
+	WIF *btcutil.WIF
+
+	// Time is the creation time of the key, zero when unknown.
+	Time time.Time
+
+	// Label is the label of the addresses of the key.
+	Label string
+
+	// Change is set for the keys of change addresses.
+	Change bool
+
+	// Reserve is set for the keys of the key pool which were never used.
+	Reserve bool
+
+	// HDSeed is set for the keys of the current or previous HD seeds.
+	HDSeed bool
+
+	// Addresses are the addresses of the key in the dumping wallet.
+	Addresses []string
+
+	// KeyPath is the derivation path of the key, empty for imported keys.
+	KeyPath string
+}
+
+// DumpScript is a script of a wallet dump.
+type DumpScript struct {
+	// Script is the script, usually a redeem script.
+	Script []byte
+
+	// Time is the creation time of the script, zero when unknown.
+	Time time.Time
+
+	// Addresses are the addresses of the script in the dumping wallet.
+	Addresses []string
+}
+
+// Dump is the content of a wallet dump.
+type Dump struct {
+	// MasterKey is the extended private master key of the wallet, empty
+	// for wallets without an HD seed.
+	MasterKey string
+
+	// Keys are the private keys of the wallet.
+	Keys []DumpKey
+
+	// Scripts are the scripts of the wallet.
+	Scripts []DumpScript
+}
+
+// parseDumpTime parses the creation time of an entry of a dump.
+func parseDumpTime(s string) (time.Time, error) {
+	if s == "0" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// parseDumpComment parses the comment following an entry of a dump, returning
+// the addresses and the key path it lists.
+func parseDumpComment(comment string) ([]string, string) {
+	var addrs []string
+	var keyPath string
+	for _, field := range strings.Fields(comment) {
+		switch {
+		case strings.HasPrefix(field, "addr="):
+			addrs = strings.Split(field[len("addr="):], ",")
+		case strings.HasPrefix(field, "hdkeypath="):
+			keyPath = field[len("hdkeypath="):]
+		}
+	}
+	return addrs, keyPath
+}
+
+// ParseDump parses a wallet dump written by the dumpwallet command of the
+// reference client.
+func ParseDump(r io.Reader) (*Dump, error) {
+	dump := &Dump{}
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, masterKeyPrefix) {
+			dump.MasterKey = strings.TrimSpace(
+				line[len(masterKeyPrefix):])
+			continue
+		}
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		var comment string
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line, comment = line[:i], line[i+1:]
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("line %d: missing fields", lineNum)
+		}
+		t, err := parseDumpTime(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid time: %v",
+				lineNum, err)
+		}
+		addrs, keyPath := parseDumpComment(comment)
+
+		// Scripts are flagged by script=1 and start with their hex
+		// encoding in place of a key.
+		isScript := false
+		for _, field := range fields[2:] {
+			if field == "script=1" {
+				isScript = true
+			}
+		}
+		if isScript {
+			script, err := hex.DecodeString(fields[0])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid script: %v",
+					lineNum, err)
+			}
+			dump.Scripts = append(dump.Scripts, DumpScript{
+				Script:    script,
+				Time:      t,
+				Addresses: addrs,
+			})
+			continue
+		}
+
+		wif, err := btcutil.DecodeWIF(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid key: %v",
+				lineNum, err)
+		}
+		key := DumpKey{
+			WIF:       wif,
+			Time:      t,
+			Addresses: addrs,
+			KeyPath:   keyPath,
+		}
+		for _, field := range fields[2:] {
+			switch {
+			case strings.HasPrefix(field, "label="):
+				// Labels are percent-encoded.
+				key.Label, err = url.PathUnescape(
+					field[len("label="):])
+				if err != nil {
+					return nil, fmt.Errorf("line %d: invalid "+
+						"label: %v", lineNum, err)
+				}
+			case field == "change=1":
+				key.Change = true
+			case field == "reserve=1":
+				key.Reserve = true
+			case field == "hdseed=1" || field == "inactivehdseed=1":
+				key.HDSeed = true
+			}
+		}
+		dump.Keys = append(dump.Keys, key)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return dump, nil
+}
@@ -116,6 +116,12 @@ type blockStore struct {
 	// override the value.
 	maxBlockFileSize uint32
 
+	// mmapReads is whether the block files opened read-only are mapped in
+	// memory, in which case readahead is the number of bytes following
+	// every read the operating system is advised to load.  See mmapFile.
+	mmapReads bool
+	readahead int
+
 	// The following fields are related to the flat files which hold the
 	// actual blocks.   The number of open files is limited by maxOpenFiles.
 	//
@@ -259,6 +265,19 @@ func (s *blockStore) openFile(fileNum uint32) (*lockableFile, error) {
 	}
 	blockFile := &lockableFile{file: file}
 
+	// Map the file in memory when enabled.  The file is still usable
+	// when it can't be mapped, such as when the address space is
+	// exhausted, so it is read directly then.
+	if s.mmapReads {
+		mf, err := newMmapFile(file, s.readahead)
+		if err != nil {
+			log.Warnf("Unable to map block file %d in memory: %v",
+				fileNum, err)
+		} else {
+			blockFile.file = mf
+		}
+	}
+
 	// Close the least recently used file if the file exceeds the max
 	// allowed open files.  This is not done until after the file open in
 	// case the file fails to open, there is no need to close any files.
@@ -22,9 +22,7 @@ import (
 	"github.com/btcsuite/goleveldb/leveldb"
 	"github.com/btcsuite/goleveldb/leveldb/comparer"
 	ldberrors "github.com/btcsuite/goleveldb/leveldb/errors"
-	"github.com/btcsuite/goleveldb/leveldb/filter"
 	"github.com/btcsuite/goleveldb/leveldb/iterator"
-	"github.com/btcsuite/goleveldb/leveldb/opt"
 	"github.com/btcsuite/goleveldb/leveldb/util"
 )
 
@@ -174,6 +172,10 @@ type cursor struct {
 	dbIter      iterator.Iterator
 	pendingIter iterator.Iterator
 	currentIter iterator.Iterator
+
+	// seekStart is the first serialized key of range cursors.  Seeking
+	// before it positions the cursor at the first key of the range.
+	seekStart []byte
 }
 
 // Enforce cursor implements the database.Cursor interface.
@@ -379,6 +381,9 @@ func (c *cursor) Seek(seek []byte) bool {
 	// Seek to the provided key in both the database and pending iterators
 	// then choose the iterator that is both valid and has the larger key.
 	seekKey := bucketizedKey(c.bucket.id, seek)
+	if c.seekStart != nil && bytes.Compare(seekKey, c.seekStart) < 0 {
+		seekKey = c.seekStart
+	}
 	c.dbIter.Seek(seekKey)
 	c.pendingIter.Seek(seekKey)
 	return c.chooseIterator(true)
@@ -549,6 +554,18 @@ func newCursor(b *bucket, bucketID []byte, cursorTyp cursorType) *cursor {
 	return &cursor{bucket: b, dbIter: dbIter, pendingIter: pendingIter}
 }
 
+// newRangeCursor returns a new cursor for the given bucket which only iterates
+// through the keys in the passed range of serialized keys.
+//
+// NOTE: The caller is responsible for calling the cursorFinalizer function on
+// the returned cursor.
+func newRangeCursor(b *bucket, keyRange *util.Range) *cursor {
+	dbIter := b.tx.snapshot.NewIterator(keyRange)
+	pendingIter := newLdbTreapIter(b.tx, keyRange)
+	return &cursor{bucket: b, dbIter: dbIter, pendingIter: pendingIter,
+		seekStart: keyRange.Start}
+}
+
 // bucket is an internal type used to represent a collection of key/value pairs
 // and implements the database.Bucket interface.
 type bucket struct {
@@ -850,6 +867,172 @@ func (b *bucket) ForEachBucket(fn func(k []byte) error) error {
 	return nil
 }
 
+// keyRange returns the range of the serialized keys of the bucket which are
+// greater than or equal to start and less than limit.  A nil start or limit
+// leaves the range open on that side, within the bucket.
+func (b *bucket) keyRange(start, limit []byte) *util.Range {
+	keyRange := util.BytesPrefix(b.id[:])
+	if start != nil {
+		keyRange.Start = bucketizedKey(b.id, start)
+	}
+	if limit != nil {
+		keyRange.Limit = bucketizedKey(b.id, limit)
+	}
+	return keyRange
+}
+
+// prefixRange returns the range of the serialized keys of the bucket which
+// start with the passed prefix.
+func (b *bucket) prefixRange(prefix []byte) *util.Range {
+	keyRange := util.BytesPrefix(bucketizedKey(b.id, prefix))
+
+	// A prefix made of 0xff bytes has no upper bound, so stop at the end
+	// of the bucket instead.
+	if keyRange.Limit == nil {
+		keyRange.Limit = util.BytesPrefix(b.id[:]).Limit
+	}
+	return keyRange
+}
+
+// RangeCursor returns a new cursor, allowing for iteration over the bucket's
+// key/value pairs with a key greater than or equal to start and less than limit
+// in forward or backward order.  A nil start begins at the first key of the
+// bucket and a nil limit ends after its last key.  Nested buckets are not
+// included.
+//
+// This function is part of the database.Bucket interface implementation.
+func (b *bucket) RangeCursor(start, limit []byte) database.Cursor {
+	// Ensure transaction state is valid.
+	if err := b.tx.checkClosed(); err != nil {
+		return &cursor{bucket: b}
+	}
+
+	// Create the cursor and setup a runtime finalizer to ensure the
+	// iterators are released when the cursor is garbage collected.
+	c := newRangeCursor(b, b.keyRange(start, limit))
+	runtime.SetFinalizer(c, cursorFinalizer)
+	return c
+}
+
+// PrefixCursor returns a new cursor, allowing for iteration over the bucket's
+// key/value pairs with a key starting with the passed prefix in forward or
+// backward order.  Nested buckets are not included.
+//
+// This function is part of the database.Bucket interface implementation.
+func (b *bucket) PrefixCursor(prefix []byte) database.Cursor {
+	// Ensure transaction state is valid.
+	if err := b.tx.checkClosed(); err != nil {
+		return &cursor{bucket: b}
+	}
+
+	c := newRangeCursor(b, b.prefixRange(prefix))
+	runtime.SetFinalizer(c, cursorFinalizer)
+	return c
+}
+
+// forEachInKeyRange invokes the passed function with every key/value pair of
+// the bucket in the passed range of serialized keys.
+func (b *bucket) forEachInKeyRange(keyRange *util.Range, fn func(k, v []byte) error) error {
+	// Ensure transaction state is valid.
+	if err := b.tx.checkClosed(); err != nil {
+		return err
+	}
+
+	// Invoke the callback for each cursor item.  Return the error returned
+	// from the callback when it is non-nil.
+	c := newRangeCursor(b, keyRange)
+	defer cursorFinalizer(c)
+	for ok := c.First(); ok; ok = c.Next() {
+		err := fn(c.Key(), c.Value())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ForEachInRange invokes the passed function with every key/value pair in the
+// bucket with a key greater than or equal to start and less than limit, in key
+// order.  A nil start begins at the first key of the bucket and a nil limit
+// ends after its last key.  This does not include nested buckets or the
+// key/value pairs within those nested buckets.
+//
+// Returns the following errors as required by the interface contract:
+//   - ErrTxClosed if the transaction has already been closed
+//
+// This function is part of the database.Bucket interface implementation.
+func (b *bucket) ForEachInRange(start, limit []byte, fn func(k, v []byte) error) error {
+	return b.forEachInKeyRange(b.keyRange(start, limit), fn)
+}
+
+// ForEachWithPrefix invokes the passed function with every key/value pair in
+// the bucket with a key starting with the passed prefix, in key order.  This
+// does not include nested buckets or the key/value pairs within those nested
+// buckets.
+//
+// Returns the following errors as required by the interface contract:
+//   - ErrTxClosed if the transaction has already been closed
+//
+// This function is part of the database.Bucket interface implementation.
+func (b *bucket) ForEachWithPrefix(prefix []byte, fn func(k, v []byte) error) error {
+	return b.forEachInKeyRange(b.prefixRange(prefix), fn)
+}
+
+// ApproximateSize returns the approximate number of bytes the key/value pairs
+// of the bucket with a key greater than or equal to start and less than limit
+// use in the underlying storage.  A nil start begins at the first key of the
+// bucket and a nil limit ends after its last key.
+//
+// The size is estimated by leveldb from the tables holding the range, so it
+// does not account for the keys which are only held by the database cache or
+// the current transaction.
+//
+// Returns the following errors as required by the interface contract:
+//   - ErrTxClosed if the transaction has already been closed
+//
+// This function is part of the database.Bucket interface implementation.
+func (b *bucket) ApproximateSize(start, limit []byte) (int64, error) {
+	// Ensure transaction state is valid.
+	if err := b.tx.checkClosed(); err != nil {
+		return 0, err
+	}
+
+	sizes, err := b.tx.db.cache.ldb.SizeOf([]util.Range{*b.keyRange(start, limit)})
+	if err != nil {
+		return 0, convertErr("failed to estimate size", err)
+	}
+	return sizes.Sum(), nil
+}
+
+// Stats returns statistics about the key/value pairs and nested buckets
+// directly held by the bucket.
+//
+// Returns the following errors as required by the interface contract:
+//   - ErrTxClosed if the transaction has already been closed
+//
+// This function is part of the database.Bucket interface implementation.
+func (b *bucket) Stats() (*database.BucketStats, error) {
+	var stats database.BucketStats
+	err := b.ForEach(func(k, v []byte) error {
+		stats.Keys++
+		stats.KeyBytes += int64(len(k))
+		stats.ValueBytes += int64(len(v))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = b.ForEachBucket(func(k []byte) error {
+		stats.Buckets++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
 // Writable returns whether or not the bucket is writable.
 //
 // This function is part of the database.Bucket interface implementation.
@@ -1699,6 +1882,19 @@ func (tx *transaction) Commit() error {
 		return makeDbErr(database.ErrTxNotWritable, str, nil)
 	}
 
+	// A database which is open read-only can not be modified, however
+	// transactions which turn out to not change anything, such as those
+	// only creating state which already exists, are allowed to commit.
+	if tx.db.readOnly {
+		if tx.pendingKeys.Len() != 0 || tx.pendingRemove.Len() != 0 ||
+			len(tx.pendingBlockData) != 0 {
+
+			str := "Commit is not allowed on a read-only database"
+			return makeDbErr(database.ErrTxNotWritable, str, nil)
+		}
+		return nil
+	}
+
 	// Write pending data.  The function will rollback if any errors occur.
 	return tx.writePendingAndCommit()
 }
@@ -1730,6 +1926,7 @@ type db struct {
 	writeLock sync.Mutex   // Limit to one write transaction at a time.
 	closeLock sync.RWMutex // Make database close block while txns active.
 	closed    bool         // Is the database closed?
+	readOnly  bool         // Is the database open read-only?
 	store     *blockStore  // Handles read/writing blocks to flat files.
 	cache     *dbCache     // Cache layer which wraps underlying leveldb DB.
 }
@@ -1898,6 +2095,23 @@ func (db *db) Update(fn func(database.Tx) error) error {
 	return tx.Commit()
 }
 
+// CacheSize returns the number of metadata updates cached in memory and their
+// size in bytes.
+//
+// This function is part of the database.CacheSizer interface implementation.
+func (db *db) CacheSize() (int, uint64) {
+	return db.cache.size()
+}
+
+// SetBulkLoad enables or disables the bulk loading mode, during which the
+// cached metadata is flushed after a configured number of blocks rather than
+// after a time interval.
+//
+// This function is part of the database.BulkLoader interface implementation.
+func (db *db) SetBulkLoad(enabled bool) {
+	db.cache.setBulkLoad(enabled)
+}
+
 // Close cleanly shuts down the database and syncs all data.  It will block
 // until all database transactions have been finalized (rolled back or
 // committed).
@@ -1982,7 +2196,11 @@ func initDB(ldb *leveldb.DB) error {
 
 // openDB opens the database at the provided path.  database.ErrDbDoesNotExist
 // is returned if the database doesn't exist and the create flag is not set.
-func openDB(dbPath string, network wire.BitcoinNet, create bool) (database.DB, error) {
+// When the readOnly flag is set, no transaction which modifies the database
+// may be committed.  Nil options select the default tuning.
+func openDB(dbPath string, network wire.BitcoinNet, create, readOnly bool,
+	dbOpts *Options) (database.DB, error) {
+
 	// Error if the database doesn't exist and the create flag is not set.
 	metadataDbPath := filepath.Join(dbPath, metadataDbName)
 	dbExists := fileExists(metadataDbPath)
@@ -2000,13 +2218,9 @@ func openDB(dbPath string, network wire.BitcoinNet, create bool) (database.DB, e
 	}
 
 	// Open the metadata database (will create it if needed).
-	opts := opt.Options{
-		ErrorIfExist: create,
-		Strict:       opt.DefaultStrict,
-		Compression:  opt.NoCompression,
-		Filter:       filter.NewBloomFilter(10),
-	}
-	ldb, err := leveldb.OpenFile(metadataDbPath, &opts)
+	dbOpts = dbOpts.withDefaults()
+	ldb, err := leveldb.OpenFile(metadataDbPath,
+		dbOpts.ldbOptions(create, readOnly))
 	if err != nil {
 		return nil, convertErr(err.Error(), err)
 	}
@@ -2017,8 +2231,14 @@ func openDB(dbPath string, network wire.BitcoinNet, create bool) (database.DB, e
 	// database cache which wraps the underlying leveldb database to provide
 	// write caching.
 	store := newBlockStore(dbPath, network)
-	cache := newDbCache(ldb, store, defaultCacheSize, defaultFlushSecs)
-	pdb := &db{store: store, cache: cache}
+	if dbOpts.MmapReads && !mmapSupported {
+		log.Warnf("Memory mapped block files are not supported on " +
+			"this platform")
+	}
+	store.mmapReads = dbOpts.MmapReads && mmapSupported
+	store.readahead = dbOpts.Readahead
+	cache := newDbCache(ldb, store, dbOpts)
+	pdb := &db{store: store, cache: cache, readOnly: readOnly}
 
 	// Perform any reconciliation needed between the block and metadata as
 	// well as database initialization, if needed.
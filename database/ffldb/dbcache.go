@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pkt-cash/pktd/database/internal/treap"
@@ -374,11 +375,24 @@ type dbCache struct {
 	// lastFlush is the time the cache was last flushed.  It is used in
 	// conjunction with the current time and the flush interval.
 	//
+	// flushBlocks is the number of blocks stored during a bulk load after
+	// which the cache is flushed, even when the flush interval has not
+	// elapsed yet.
+	//
+	// blocksSinceFlush is the number of blocks stored since the cache was
+	// last flushed.
+	//
 	// NOTE: These flush related fields are protected by the database write
 	// lock.
-	maxSize       uint64
-	flushInterval time.Duration
-	lastFlush     time.Time
+	maxSize          uint64
+	flushInterval    time.Duration
+	lastFlush        time.Time
+	flushBlocks      int
+	blocksSinceFlush int
+
+	// bulkLoad is set to 1 while blocks are bulk loaded.  It is accessed
+	// atomically.
+	bulkLoad int32
 
 	// The following fields hold the keys that need to be stored or deleted
 	// from the underlying database once the cache is full, enough time has
@@ -487,6 +501,7 @@ func (c *dbCache) commitTreaps(pendingKeys, pendingRemove TreapForEacher) error
 // This function MUST be called with the database write lock held.
 func (c *dbCache) flush() error {
 	c.lastFlush = time.Now()
+	c.blocksSinceFlush = 0
 
 	// Sync the current write file associated with the block store.  This is
 	// necessary before writing the metadata to prevent the case where the
@@ -527,7 +542,8 @@ func (c *dbCache) flush() error {
 // persistent storage based on its current size, whether or not adding all of
 // the entries in the passed database transaction would cause it to exceed the
 // configured limit, and how much time has elapsed since the last time the cache
-// was flushed.
+// was flushed.  During bulk loads, the number of blocks stored since the last
+// flush also triggers a flush.
 //
 // This function MUST be called with the database write lock held.
 func (c *dbCache) needsFlush(tx *transaction) bool {
@@ -537,6 +553,17 @@ func (c *dbCache) needsFlush(tx *transaction) bool {
 		return true
 	}
 
+	// During bulk loads, a flush is also needed when the configured number
+	// of blocks has been stored since the last flush.  Blocks stored in the
+	// flat files but not yet in the metadata are removed when the database
+	// is opened after an unexpected shutdown, so they are downloaded again.
+	if atomic.LoadInt32(&c.bulkLoad) == 1 {
+		numBlocks := c.blocksSinceFlush + len(tx.pendingBlockData)
+		if numBlocks >= c.flushBlocks {
+			return true
+		}
+	}
+
 	// A flush is needed when the size of the database cache exceeds the
 	// specified max cache size.  The total calculated size is multiplied by
 	// 1.5 here to account for additional memory consumption that will be
@@ -586,6 +613,7 @@ func (c *dbCache) commitTx(tx *transaction) error {
 
 	// At this point a database flush is not needed, so atomically commit
 	// the transaction to the cache.
+	c.blocksSinceFlush += len(tx.pendingBlockData)
 
 	// Since the cached keys to be added and removed use an immutable treap,
 	// a snapshot is simply obtaining the root of the tree under the lock
@@ -643,17 +671,42 @@ func (c *dbCache) Close() error {
 	return nil
 }
 
+// size returns the number of cached keys to add and remove and their size.
+//
+// This function is safe for concurrent access.
+func (c *dbCache) size() (int, uint64) {
+	c.cacheLock.RLock()
+	cachedKeys := c.cachedKeys
+	cachedRemove := c.cachedRemove
+	c.cacheLock.RUnlock()
+
+	return cachedKeys.Len() + cachedRemove.Len(),
+		cachedKeys.Size() + cachedRemove.Size()
+}
+
+// setBulkLoad enables or disables the flushing of the cache by number of
+// blocks rather than by time.
+func (c *dbCache) setBulkLoad(enabled bool) {
+	var bulkLoad int32
+	if enabled {
+		bulkLoad = 1
+	}
+	atomic.StoreInt32(&c.bulkLoad, bulkLoad)
+}
+
 // newDbCache returns a new database cache instance backed by the provided
 // leveldb instance.  The cache will be flushed to leveldb when the max size
-// exceeds the provided value or it has been longer than the provided interval
-// since the last flush.
-func newDbCache(ldb *leveldb.DB, store *blockStore, maxSize uint64, flushIntervalSecs uint32) *dbCache {
+// exceeds the cache size of the provided options or it has been longer than
+// their flush interval since the last flush.  The options must have their
+// defaults set.
+func newDbCache(ldb *leveldb.DB, store *blockStore, opts *Options) *dbCache {
 	return &dbCache{
 		ldb:           ldb,
 		store:         store,
-		maxSize:       maxSize,
-		flushInterval: time.Second * time.Duration(flushIntervalSecs),
+		maxSize:       opts.CacheSize,
+		flushInterval: opts.FlushInterval,
 		lastFlush:     time.Now(),
+		flushBlocks:   opts.FlushBlocks,
 		cachedKeys:    treap.NewImmutable(),
 		cachedRemove:  treap.NewImmutable(),
 	}
@@ -25,5 +25,31 @@ database path as a string and the block network:
 	if err != nil {
 		// Handle error
 	}
+
+Open also takes an optional third boolean parameter which opens the database
+read-only.  Any transaction which would modify a read-only database fails to
+commit with database.ErrTxNotWritable.  Since leveldb locks the database, it
+may not be opened read-only while another process has it open.  A copy of the
+database, such as a filesystem snapshot, must be used instead:
+
+	db, err := database.Open("ffldb", "path/to/database", wire.MainNet, true)
+	if err != nil {
+		// Handle error
+	}
+
+Both functions also take an optional last *Options parameter tuning the sizes
+of the caches and buffers and how often the cached metadata is flushed:
+
+	opts := &ffldb.Options{CacheSize: 500 * 1024 * 1024}
+	db, err := database.Open("ffldb", "path/to/database", wire.MainNet, opts)
+	if err != nil {
+		// Handle error
+	}
+
+The database implements database.BulkLoader.  While bulk loading, the metadata
+is also flushed after Options.FlushBlocks blocks, on top of the flush interval,
+which bounds the number of blocks downloaded again after an unexpected shutdown
+during the initial block download.  The blocks stored after the last flush are
+removed from the flat files when the database is opened.
 */
 package ffldb
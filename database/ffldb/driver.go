@@ -41,26 +41,48 @@ func parseArgs(funcName string, args ...interface{}) (string, wire.BitcoinNet, e
 	return dbPath, network, nil
 }
 
+// parseOptions removes the optional trailing *Options argument from the
+// arguments of the database Open/Create methods.
+func parseOptions(args []interface{}) ([]interface{}, *Options) {
+	if len(args) > 2 {
+		if opts, ok := args[len(args)-1].(*Options); ok {
+			return args[:len(args)-1], opts
+		}
+	}
+	return args, nil
+}
+
 // openDBDriver is the callback provided during driver registration that opens
-// an existing database for use.
+// an existing database for use.  An optional third boolean argument opens the
+// database read-only, and an optional last *Options argument tunes it.
 func openDBDriver(args ...interface{}) (database.DB, error) {
+	args, opts := parseOptions(args)
+	var readOnly bool
+	if len(args) == 3 {
+		if ro, ok := args[2].(bool); ok {
+			readOnly = ro
+			args = args[:2]
+		}
+	}
 	dbPath, network, err := parseArgs("Open", args...)
 	if err != nil {
 		return nil, err
 	}
 
-	return openDB(dbPath, network, false)
+	return openDB(dbPath, network, false, readOnly, opts)
 }
 
 // createDBDriver is the callback provided during driver registration that
-// creates, initializes, and opens a database for use.
+// creates, initializes, and opens a database for use.  An optional last
+// *Options argument tunes it.
 func createDBDriver(args ...interface{}) (database.DB, error) {
+	args, opts := parseOptions(args)
 	dbPath, network, err := parseArgs("Create", args...)
 	if err != nil {
 		return nil, err
 	}
 
-	return openDB(dbPath, network, true)
+	return openDB(dbPath, network, true, false, opts)
 }
 
 // useLogger is the callback provided during driver registration that sets the
@@ -254,6 +254,96 @@ func TestPersistence(t *testing.T) {
 	}
 }
 
+// TestReadOnly ensures a database opened read-only can be read, allows
+// transactions which do not change anything and rejects any modification.
+func TestReadOnly(t *testing.T) {
+	t.Parallel()
+
+	// Create a new database with a block and a value in it.
+	dbPath := filepath.Join(os.TempDir(), "ffldb-readonlytest")
+	_ = os.RemoveAll(dbPath)
+	db, err := database.Create(dbType, dbPath, blockDataNet)
+	if err != nil {
+		t.Errorf("Failed to create test database (%s) %v", dbType, err)
+		return
+	}
+	defer os.RemoveAll(dbPath)
+
+	bucketKey := []byte("bucket")
+	genesisBlock := btcutil.NewBlock(chaincfg.MainNetParams.GenesisBlock)
+	genesisHash := chaincfg.MainNetParams.GenesisHash
+	err = db.Update(func(tx database.Tx) error {
+		bucket, err := tx.Metadata().CreateBucket(bucketKey)
+		if err != nil {
+			return err
+		}
+		if err := bucket.Put([]byte("key"), []byte("value")); err != nil {
+			return err
+		}
+		return tx.StoreBlock(genesisBlock)
+	})
+	if err != nil {
+		t.Errorf("Update: unexpected error: %v", err)
+		return
+	}
+	db.Close()
+
+	db, err = database.Open(dbType, dbPath, blockDataNet, true)
+	if err != nil {
+		t.Errorf("Failed to open test database read-only (%s) %v",
+			dbType, err)
+		return
+	}
+	defer db.Close()
+
+	err = db.View(func(tx database.Tx) error {
+		bucket := tx.Metadata().Bucket(bucketKey)
+		if bucket == nil {
+			return fmt.Errorf("Bucket: unexpected nil bucket")
+		}
+		if got := bucket.Get([]byte("key")); string(got) != "value" {
+			return fmt.Errorf("Get: got %q, want %q", got, "value")
+		}
+		_, err := tx.FetchBlock(genesisHash)
+		return err
+	})
+	if err != nil {
+		t.Errorf("View: unexpected error: %v", err)
+		return
+	}
+
+	// Creating a bucket which already exists does not change anything, so
+	// the transaction must commit.
+	err = db.Update(func(tx database.Tx) error {
+		_, err := tx.Metadata().CreateBucketIfNotExists(bucketKey)
+		return err
+	})
+	if err != nil {
+		t.Errorf("Update without changes: unexpected error: %v", err)
+		return
+	}
+
+	wantErrCode := database.ErrTxNotWritable
+	err = db.Update(func(tx database.Tx) error {
+		return tx.Metadata().Bucket(bucketKey).Put([]byte("key"),
+			[]byte("changed"))
+	})
+	if !checkDbError(t, "Update", err, wantErrCode) {
+		return
+	}
+	err = db.View(func(tx database.Tx) error {
+		got := tx.Metadata().Bucket(bucketKey).Get([]byte("key"))
+		if string(got) != "value" {
+			return fmt.Errorf("Get after rejected update: got %q, "+
+				"want %q", got, "value")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("View: unexpected error: %v", err)
+	}
+}
+
 // TestInterface performs all interfaces tests for this database driver.
 func TestInterface(t *testing.T) {
 	t.Parallel()
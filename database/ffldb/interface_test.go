@@ -383,6 +383,172 @@ func testCursorInterface(tc *testContext, bucket database.Bucket) bool {
 	return true
 }
 
+// testIterated checks that the keys iterated by the passed cursor from the
+// first one, and from the last one backwards, match the expected keypairs.
+func testIterated(tc *testContext, name string, cursor database.Cursor, values []keyPair) bool {
+	curIdx := 0
+	for ok := cursor.First(); ok; ok = cursor.Next() {
+		k, v := cursor.Key(), cursor.Value()
+		if !testCursorKeyPair(tc, k, v, curIdx, values) {
+			return false
+		}
+		curIdx++
+	}
+	if curIdx != len(values) {
+		tc.t.Errorf("%s: expected to iterate %d values, but only "+
+			"iterated %d", name, len(values), curIdx)
+		return false
+	}
+
+	curIdx = len(values) - 1
+	for ok := cursor.Last(); ok; ok = cursor.Prev() {
+		k, v := cursor.Key(), cursor.Value()
+		if !testCursorKeyPair(tc, k, v, curIdx, values) {
+			return false
+		}
+		curIdx--
+	}
+	if curIdx > -1 {
+		tc.t.Errorf("%s reverse: expected to iterate %d values, but "+
+			"only iterated %d", name, len(values),
+			len(values)-(curIdx+1))
+		return false
+	}
+
+	return true
+}
+
+// testRangeInterface ensures the range and prefix cursors, iteration and
+// statistics are working properly by exercising them on a nested bucket of the
+// passed writable bucket.
+func testRangeInterface(tc *testContext, bucket database.Bucket) bool {
+	rangeBucketName := []byte("rangetest")
+	rangeBucket, err := bucket.CreateBucket(rangeBucketName)
+	if err != nil {
+		tc.t.Errorf("CreateBucket: unexpected error: %v", err)
+		return false
+	}
+	defer bucket.DeleteBucket(rangeBucketName)
+
+	values := []keyPair{
+		{[]byte("aa"), []byte("val1")},
+		{[]byte("ab"), []byte("val2")},
+		{[]byte("abc"), []byte("val3")},
+		{[]byte("b"), []byte("val4")},
+		{[]byte("c"), []byte("val5")},
+		{[]byte{0xff, 0xff}, []byte("val6")},
+	}
+	if !testPutValues(tc, rangeBucket, values) {
+		return false
+	}
+	if _, err := rangeBucket.CreateBucket([]byte("abnested")); err != nil {
+		tc.t.Errorf("CreateBucket: unexpected error: %v", err)
+		return false
+	}
+
+	// Ensure range cursors only iterate the keys in the range and never
+	// the nested buckets.
+	tests := []struct {
+		name   string
+		cursor database.Cursor
+		want   []keyPair
+	}{
+		{"RangeCursor", rangeBucket.RangeCursor([]byte("ab"), []byte("c")),
+			values[1:4]},
+		{"RangeCursor nil start", rangeBucket.RangeCursor(nil, []byte("ab")),
+			values[:1]},
+		{"RangeCursor nil limit", rangeBucket.RangeCursor([]byte("b"), nil),
+			values[3:]},
+		{"RangeCursor empty", rangeBucket.RangeCursor([]byte("c"), []byte("b")),
+			nil},
+		{"PrefixCursor", rangeBucket.PrefixCursor([]byte("ab")),
+			values[1:3]},
+		{"PrefixCursor 0xff", rangeBucket.PrefixCursor([]byte{0xff}),
+			values[5:]},
+		{"PrefixCursor missing", rangeBucket.PrefixCursor([]byte("d")),
+			nil},
+	}
+	for _, test := range tests {
+		if test.cursor.Bucket() != rangeBucket {
+			tc.t.Errorf("%s: does not match the bucket it was "+
+				"created for", test.name)
+			return false
+		}
+		if !testIterated(tc, test.name, test.cursor, test.want) {
+			return false
+		}
+	}
+
+	// Ensure seeking outside of the range positions the cursor at the
+	// nearest key inside of it.
+	cursor := rangeBucket.PrefixCursor([]byte("ab"))
+	if !cursor.Seek([]byte("a")) || !bytes.Equal(cursor.Key(), values[1].key) {
+		tc.t.Errorf("PrefixCursor.Seek: unexpected key %q", cursor.Key())
+		return false
+	}
+	if cursor.Seek([]byte("b")) {
+		tc.t.Errorf("PrefixCursor.Seek: unexpected key %q after the "+
+			"range", cursor.Key())
+		return false
+	}
+
+	// Ensure the iteration functions visit the same keys as the cursors
+	// and stop on errors returned from the user-supplied function.
+	var iterated []keyPair
+	collect := func(k, v []byte) error {
+		iterated = append(iterated, keyPair{k, v})
+		return nil
+	}
+	if err := rangeBucket.ForEachInRange([]byte("ab"), []byte("c"), collect); err != nil {
+		tc.t.Errorf("ForEachInRange: unexpected error: %v", err)
+		return false
+	}
+	if !reflect.DeepEqual(iterated, values[1:4]) {
+		tc.t.Errorf("ForEachInRange: unexpected values %q", iterated)
+		return false
+	}
+	iterated = nil
+	if err := rangeBucket.ForEachWithPrefix([]byte("ab"), collect); err != nil {
+		tc.t.Errorf("ForEachWithPrefix: unexpected error: %v", err)
+		return false
+	}
+	if !reflect.DeepEqual(iterated, values[1:3]) {
+		tc.t.Errorf("ForEachWithPrefix: unexpected values %q", iterated)
+		return false
+	}
+	forEachErr := fmt.Errorf("inner function error")
+	err = rangeBucket.ForEachWithPrefix(nil, func(k, v []byte) error {
+		return forEachErr
+	})
+	if err != forEachErr {
+		tc.t.Errorf("ForEachWithPrefix: inner function error not "+
+			"returned - got %v, want %v", err, forEachErr)
+		return false
+	}
+
+	// Ensure the statistics account for every key and nested bucket.
+	stats, err := rangeBucket.Stats()
+	if err != nil {
+		tc.t.Errorf("Stats: unexpected error: %v", err)
+		return false
+	}
+	wantStats := database.BucketStats{Keys: 6, KeyBytes: 11,
+		ValueBytes: 24, Buckets: 1}
+	if *stats != wantStats {
+		tc.t.Errorf("Stats: unexpected statistics %+v, want %+v",
+			*stats, wantStats)
+		return false
+	}
+
+	// The size is only approximate, so just make sure it can be obtained.
+	if _, err := rangeBucket.ApproximateSize(nil, nil); err != nil {
+		tc.t.Errorf("ApproximateSize: unexpected error: %v", err)
+		return false
+	}
+
+	return true
+}
+
 // testNestedBucket reruns the testBucketInterface against a nested bucket along
 // with a counter to only test a couple of level deep.
 func testNestedBucket(tc *testContext, testBucket database.Bucket) bool {
@@ -561,6 +727,11 @@ func testBucketInterface(tc *testContext, bucket database.Bucket) bool {
 			return false
 		}
 
+		// Ensure the range and prefix iteration works as expected.
+		if !testRangeInterface(tc, testBucket) {
+			return false
+		}
+
 		// Delete the test bucket to avoid leaving it around for future
 		// calls.
 		if err := bucket.DeleteBucket(testBucketName); err != nil {
@@ -1742,6 +1913,44 @@ func testClosedTxInterface(tc *testContext, tx database.Tx) bool {
 		return false
 	}
 
+	// Ensure ForEachInRange returns expected error.
+	testName = "ForEachInRange on closed tx"
+	err = bucket.ForEachInRange(nil, nil, nil)
+	if !checkDbError(tc.t, testName, err, wantErrCode) {
+		return false
+	}
+
+	// Ensure ForEachWithPrefix returns expected error.
+	testName = "ForEachWithPrefix on closed tx"
+	err = bucket.ForEachWithPrefix(nil, nil)
+	if !checkDbError(tc.t, testName, err, wantErrCode) {
+		return false
+	}
+
+	// Ensure ApproximateSize returns expected error.
+	testName = "ApproximateSize on closed tx"
+	_, err = bucket.ApproximateSize(nil, nil)
+	if !checkDbError(tc.t, testName, err, wantErrCode) {
+		return false
+	}
+
+	// Ensure Stats returns expected error.
+	testName = "Stats on closed tx"
+	_, err = bucket.Stats()
+	if !checkDbError(tc.t, testName, err, wantErrCode) {
+		return false
+	}
+
+	// Ensure range cursors are exhausted.
+	if bucket.RangeCursor(nil, nil).First() {
+		tc.t.Errorf("RangeCursor: positioned on closed tx")
+		return false
+	}
+	if bucket.PrefixCursor(nil).First() {
+		tc.t.Errorf("PrefixCursor: positioned on closed tx")
+		return false
+	}
+
 	// Ensure Get returns expected error.
 	testName = "Get on closed tx"
 	if k := bucket.Get(keyName); k != nil {
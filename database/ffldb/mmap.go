@@ -0,0 +1,76 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package ffldb
+
+import (
+	"os"
+)
+
+// mmapFile is a read-only block file which serves reads from a memory mapping
+// of the file, so reading a block neither needs a system call nor competes for
+// the file offset with other readers.  The mapping covers the file as it was
+// when it was opened.  Reads past its end, which only happen for the current
+// write file, are served by the file itself.
+type mmapFile struct {
+	*os.File
+	data []byte
+
+	// readahead is the number of bytes following every read which the
+	// operating system is advised to load, since peers downloading the
+	// chain request the blocks in the order they are stored.
+	readahead int
+}
+
+// newMmapFile maps the passed read-only file in memory.
+func newMmapFile(file *os.File, readahead int) (*mmapFile, error) {
+	fi, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	f := &mmapFile{File: file, readahead: readahead}
+	if fi.Size() > 0 {
+		f.data, err = mmap(file, int(fi.Size()))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// ReadAt reads len(b) bytes at the passed offset of the file.
+//
+// This is part of the filer interface.
+func (f *mmapFile) ReadAt(b []byte, off int64) (int, error) {
+	end := off + int64(len(b))
+	if off < 0 || end > int64(len(f.data)) {
+		return f.File.ReadAt(b, off)
+	}
+	n := copy(b, f.data[off:end])
+
+	if f.readahead > 0 && end < int64(len(f.data)) {
+		// Advice must start on a page boundary.
+		start := end &^ int64(os.Getpagesize()-1)
+		stop := end + int64(f.readahead)
+		if stop > int64(len(f.data)) {
+			stop = int64(len(f.data))
+		}
+		adviseWillNeed(f.data[start:stop])
+	}
+	return n, nil
+}
+
+// Close unmaps the file and closes it.
+//
+// This is part of the filer interface.
+func (f *mmapFile) Close() error {
+	if f.data != nil {
+		if err := munmap(f.data); err != nil {
+			_ = f.File.Close()
+			return err
+		}
+		f.data = nil
+	}
+	return f.File.Close()
+}
@@ -0,0 +1,26 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package ffldb
+
+import (
+	"errors"
+	"os"
+)
+
+// mmapSupported is whether block files can be mapped in memory.
+const mmapSupported = false
+
+// mmap returns an error since memory mapping is not supported on this
+// platform.
+func mmap(file *os.File, size int) ([]byte, error) {
+	return nil, errors.New("memory mapped files are not supported")
+}
+
+// munmap does nothing since memory mapping is not supported on this platform.
+func munmap(data []byte) error {
+	return nil
+}
@@ -0,0 +1,26 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package ffldb
+
+import (
+	"os"
+	"syscall"
+)
+
+// mmapSupported is whether block files can be mapped in memory.
+const mmapSupported = true
+
+// mmap maps the first size bytes of the passed file in memory read-only.
+func mmap(file *os.File, size int) ([]byte, error) {
+	return syscall.Mmap(int(file.Fd()), 0, size, syscall.PROT_READ,
+		syscall.MAP_SHARED)
+}
+
+// munmap unmaps memory mapped by mmap.
+func munmap(data []byte) error {
+	return syscall.Munmap(data)
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package ffldb
+
+import (
+	"time"
+
+	"github.com/btcsuite/goleveldb/leveldb/filter"
+	"github.com/btcsuite/goleveldb/leveldb/opt"
+)
+
+// defaultFlushBlocks is the default number of blocks stored during a bulk load
+// after which the database cache is flushed.
+const defaultFlushBlocks = 2000
+
+// Options are the tuning options of a database.  They can be passed as the
+// last argument when opening or creating a database with database.Open and
+// database.Create.  The zero value of every field selects its default.
+type Options struct {
+	// CacheSize is the maximum size, in bytes, the cache of metadata
+	// updates grows to before it is flushed to leveldb.  It defaults to
+	// 100 MB.
+	CacheSize uint64
+
+	// FlushInterval is the maximum time between two flushes of the cache.
+	// It defaults to 5 minutes.
+	FlushInterval time.Duration
+
+	// FlushBlocks is the number of blocks stored during a bulk load after
+	// which the cache is flushed, unless it is full or the flush interval
+	// elapses before.  It defaults to 2000.
+	FlushBlocks int
+
+	// WriteBuffer is the size, in bytes, of the leveldb write buffer.
+	// Larger buffers mean fewer and larger tables to compact.  It
+	// defaults to the leveldb default of 4 MiB.
+	WriteBuffer int
+
+	// BlockCacheCapacity is the capacity, in bytes, of the leveldb cache
+	// of table blocks.  It defaults to the leveldb default of 8 MiB.
+	BlockCacheCapacity int
+
+	// CompactionTableSize is the size, in bytes, of the tables written by
+	// leveldb compactions.  It defaults to the leveldb default of 2 MiB.
+	CompactionTableSize int
+
+	// MmapReads maps the block files in memory to read blocks from them
+	// instead of reading the files.  It is ignored on platforms which do
+	// not support memory mapped files.
+	MmapReads bool
+
+	// Readahead is the number of bytes following a block read from a
+	// memory mapped block file which the operating system is advised to
+	// load in the background.  It is only used on Linux and defaults to
+	// no readahead.
+	Readahead int
+}
+
+// ldbOptions returns the leveldb options to open the metadata database with.
+func (o *Options) ldbOptions(create, readOnly bool) *opt.Options {
+	return &opt.Options{
+		ErrorIfExist:        create,
+		ReadOnly:            readOnly,
+		Strict:              opt.DefaultStrict,
+		Compression:         opt.NoCompression,
+		Filter:              filter.NewBloomFilter(10),
+		WriteBuffer:         o.WriteBuffer,
+		BlockCacheCapacity:  o.BlockCacheCapacity,
+		CompactionTableSize: o.CompactionTableSize,
+	}
+}
+
+// withDefaults returns a copy of the options with the defaults of the database
+// cache set.  Nil options select every default.
+func (o *Options) withDefaults() *Options {
+	var opts Options
+	if o != nil {
+		opts = *o
+	}
+	if opts.CacheSize == 0 {
+		opts.CacheSize = defaultCacheSize
+	}
+	if opts.FlushInterval == 0 {
+		opts.FlushInterval = defaultFlushSecs * time.Second
+	}
+	if opts.FlushBlocks == 0 {
+		opts.FlushBlocks = defaultFlushBlocks
+	}
+	return &opts
+}
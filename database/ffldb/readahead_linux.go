@@ -0,0 +1,16 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package ffldb
+
+import (
+	"syscall"
+)
+
+// adviseWillNeed advises the kernel to load the passed page aligned memory
+// mapped data in the background.  Failures are ignored since the advice only
+// affects performance.
+func adviseWillNeed(data []byte) {
+	_ = syscall.Madvise(data, syscall.MADV_WILLNEED)
+}
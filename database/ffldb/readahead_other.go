@@ -0,0 +1,10 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// +build !linux
+
+package ffldb
+
+// adviseWillNeed does nothing since readahead advice is only given on Linux.
+func adviseWillNeed(data []byte) {}
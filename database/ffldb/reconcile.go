@@ -82,9 +82,14 @@ func reconcileDB(pdb *db, create bool) (database.DB, error) {
 	// the middle of being written.  Since the metadata isn't updated until
 	// after the block data is written, this is effectively just a rollback
 	// to the known good point before the unclean shutdown.
+	//
+	// A read-only database is left alone since the block data past the
+	// position in the metadata is never referenced by it anyways.  This is
+	// also the case when the files are a copy of a database which is in
+	// use.
 	wc := pdb.store.writeCursor
-	if wc.curFileNum > curFileNum || (wc.curFileNum == curFileNum &&
-		wc.curOffset > curOffset) {
+	if !pdb.readOnly && (wc.curFileNum > curFileNum ||
+		(wc.curFileNum == curFileNum && wc.curOffset > curOffset)) {
 
 		log.Info("Detected unclean shutdown - Repairing...")
 		log.Debugf("Metadata claims file %d, offset %d. Block data is "+
@@ -8,6 +8,7 @@
 package ffldb
 
 import (
+	"bytes"
 	"compress/bzip2"
 	"encoding/binary"
 	"fmt"
@@ -16,6 +17,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/pkt-cash/pktd/chaincfg"
 	"github.com/pkt-cash/pktd/database"
@@ -179,7 +181,7 @@ func TestCornerCases(t *testing.T) {
 	// directory is needed.
 	testName := "openDB: fail due to file at target location"
 	wantErrCode := database.ErrDriverSpecific
-	idb, err := openDB(dbPath, blockDataNet, true)
+	idb, err := openDB(dbPath, blockDataNet, true, false, nil)
 	if !checkDbError(t, testName, err, wantErrCode) {
 		if err == nil {
 			idb.Close()
@@ -191,7 +193,7 @@ func TestCornerCases(t *testing.T) {
 	// Remove the file and create the database to run tests against.  It
 	// should be successful this time.
 	_ = os.RemoveAll(dbPath)
-	idb, err = openDB(dbPath, blockDataNet, true)
+	idb, err = openDB(dbPath, blockDataNet, true, false, nil)
 	if err != nil {
 		t.Errorf("openDB: unexpected error: %v", err)
 		return
@@ -704,3 +706,182 @@ func TestFailureScenarios(t *testing.T) {
 	// Test various corruption scenarios.
 	testCorruption(tc)
 }
+
+// TestBulkLoad ensures the metadata of the blocks stored during a bulk load is
+// flushed after the configured number of blocks or the flush interval.
+func TestBulkLoad(t *testing.T) {
+	t.Parallel()
+
+	blocks, err := loadBlocks(t, blockDataFile, blockDataNet)
+	if err != nil {
+		t.Errorf("loadBlocks: Unexpected error: %v", err)
+		return
+	}
+
+	dbPath := filepath.Join(os.TempDir(), "ffldb-bulkload")
+	_ = os.RemoveAll(dbPath)
+	opts := &Options{FlushBlocks: 3, WriteBuffer: 1024 * 1024}
+	idb, err := database.Create(dbType, dbPath, blockDataNet, opts)
+	if err != nil {
+		t.Errorf("Failed to create test database (%s) %v", dbType, err)
+		return
+	}
+	defer os.RemoveAll(dbPath)
+	defer idb.Close()
+
+	pdb := idb.(*db)
+	if pdb.cache.flushBlocks != 3 || pdb.cache.maxSize != defaultCacheSize {
+		t.Errorf("unexpected cache options: flush blocks %d, max size %d",
+			pdb.cache.flushBlocks, pdb.cache.maxSize)
+		return
+	}
+
+	storeBlock := func(block *btcutil.Block) bool {
+		err := idb.Update(func(tx database.Tx) error {
+			return tx.StoreBlock(block)
+		})
+		if err != nil {
+			t.Errorf("StoreBlock: unexpected error: %v", err)
+			return false
+		}
+		return true
+	}
+
+	idb.(database.BulkLoader).SetBulkLoad(true)
+	for i, block := range blocks[:6] {
+		if !storeBlock(block) {
+			return
+		}
+		flushed := pdb.cache.cachedKeys.Len() == 0
+		if wantFlushed := (i+1)%3 == 0; flushed != wantFlushed {
+			t.Errorf("block %d: flushed %v, want %v", i, flushed,
+				wantFlushed)
+			return
+		}
+	}
+
+	// The cache is still flushed after the flush interval during bulk
+	// loads, before the configured number of blocks is reached.
+	pdb.cache.lastFlush = time.Now().Add(-2 * pdb.cache.flushInterval)
+	if !storeBlock(blocks[6]) {
+		return
+	}
+	if pdb.cache.cachedKeys.Len() != 0 {
+		t.Errorf("block 6: cache not flushed after the flush interval")
+		return
+	}
+
+	// Outside of bulk loads, the cache is only flushed after the flush
+	// interval.
+	idb.(database.BulkLoader).SetBulkLoad(false)
+	for i, block := range blocks[7:11] {
+		if !storeBlock(block) {
+			return
+		}
+		if pdb.cache.cachedKeys.Len() == 0 {
+			t.Errorf("block %d: unexpected flush", i+7)
+			return
+		}
+	}
+}
+
+// TestMmapReads ensures blocks and block regions read from memory mapped block
+// files match the stored blocks, including the blocks appended to a mapped file
+// after it was mapped.
+func TestMmapReads(t *testing.T) {
+	t.Parallel()
+
+	blocks, err := loadBlocks(t, blockDataFile, blockDataNet)
+	if err != nil {
+		t.Errorf("loadBlocks: Unexpected error: %v", err)
+		return
+	}
+	blocks = blocks[:20]
+
+	dbPath := filepath.Join(os.TempDir(), "ffldb-mmapreads")
+	_ = os.RemoveAll(dbPath)
+	opts := &Options{MmapReads: true, Readahead: 64 * 1024}
+	idb, err := database.Create(dbType, dbPath, blockDataNet, opts)
+	if err != nil {
+		t.Errorf("Failed to create test database (%s) %v", dbType, err)
+		return
+	}
+	defer os.RemoveAll(dbPath)
+	storeBlocks := func(blocks []*btcutil.Block) error {
+		return idb.Update(func(tx database.Tx) error {
+			for _, block := range blocks {
+				if err := tx.StoreBlock(block); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	if err := storeBlocks(blocks[:10]); err != nil {
+		t.Errorf("StoreBlock: unexpected error: %v", err)
+		idb.Close()
+		return
+	}
+	idb.Close()
+
+	// Reopen the database so the block file is opened read-only, which
+	// maps it, then append to it.
+	idb, err = database.Open(dbType, dbPath, blockDataNet, opts)
+	if err != nil {
+		t.Errorf("Failed to open test database (%s) %v", dbType, err)
+		return
+	}
+	defer idb.Close()
+	checkBlocks := func(blocks []*btcutil.Block) error {
+		return idb.View(func(tx database.Tx) error {
+			return checkFetchedBlocks(tx, blocks)
+		})
+	}
+	if err := checkBlocks(blocks[:10]); err != nil {
+		t.Errorf("mapped blocks: %v", err)
+		return
+	}
+	store := idb.(*db).store
+	if _, ok := store.openBlockFiles[0].file.(*mmapFile); ok != mmapSupported {
+		t.Errorf("block file mapped: got %v, want %v", ok, mmapSupported)
+		return
+	}
+	if err := storeBlocks(blocks[10:]); err != nil {
+		t.Errorf("StoreBlock: unexpected error: %v", err)
+		return
+	}
+	if err := checkBlocks(blocks); err != nil {
+		t.Errorf("appended blocks: %v", err)
+	}
+}
+
+// checkFetchedBlocks returns an error when the passed blocks or one of their
+// regions fetched from the database do not match them.
+func checkFetchedBlocks(tx database.Tx, blocks []*btcutil.Block) error {
+	for i, block := range blocks {
+		wantBytes, err := block.Bytes()
+		if err != nil {
+			return err
+		}
+		gotBytes, err := tx.FetchBlock(block.Hash())
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(gotBytes, wantBytes) {
+			return fmt.Errorf("block %d: bytes mismatch", i)
+		}
+		region := &database.BlockRegion{
+			Hash:   block.Hash(),
+			Offset: 4,
+			Len:    32,
+		}
+		gotRegion, err := tx.FetchBlockRegion(region)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(gotRegion, wantBytes[4:36]) {
+			return fmt.Errorf("block %d: region mismatch", i)
+		}
+	}
+	return nil
+}
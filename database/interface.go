@@ -63,6 +63,20 @@ type Cursor interface {
 	Value() []byte
 }
 
+// BucketStats holds statistics about the key/value pairs and nested buckets
+// directly held by a bucket, as returned by Bucket.Stats.
+type BucketStats struct {
+	// Keys is the number of key/value pairs.
+	Keys int
+
+	// KeyBytes and ValueBytes are the total sizes of the keys and values.
+	KeyBytes   int64
+	ValueBytes int64
+
+	// Buckets is the number of nested buckets.
+	Buckets int
+}
+
 // Bucket represents a collection of key/value pairs.
 type Bucket interface {
 	// Bucket retrieves a nested bucket with the given key.  Returns nil if
@@ -153,6 +167,63 @@ type Bucket interface {
 	// Value functions.
 	Cursor() Cursor
 
+	// RangeCursor returns a new cursor, allowing for iteration over the
+	// bucket's key/value pairs with a key greater than or equal to start
+	// and less than limit in forward or backward order.  A nil start
+	// begins at the first key of the bucket and a nil limit ends after its
+	// last key.  Nested buckets are not included.
+	//
+	// The same positioning rules as Cursor apply, and seeking outside of
+	// the range positions the cursor at the nearest key inside of it.
+	RangeCursor(start, limit []byte) Cursor
+
+	// PrefixCursor returns a new cursor, allowing for iteration over the
+	// bucket's key/value pairs with a key starting with the passed prefix
+	// in forward or backward order.  Nested buckets are not included.
+	//
+	// The same positioning rules as RangeCursor apply.
+	PrefixCursor(prefix []byte) Cursor
+
+	// ForEachInRange invokes the passed function with every key/value pair
+	// in the bucket with a key greater than or equal to start and less
+	// than limit, in key order.  A nil start begins at the first key of
+	// the bucket and a nil limit ends after its last key.  This does not
+	// include nested buckets or the key/value pairs within those nested
+	// buckets.
+	//
+	// The same warnings, errors and constraints as ForEach apply.
+	ForEachInRange(start, limit []byte, fn func(k, v []byte) error) error
+
+	// ForEachWithPrefix invokes the passed function with every key/value
+	// pair in the bucket with a key starting with the passed prefix, in
+	// key order.  This does not include nested buckets or the key/value
+	// pairs within those nested buckets.
+	//
+	// The same warnings, errors and constraints as ForEach apply.
+	ForEachWithPrefix(prefix []byte, fn func(k, v []byte) error) error
+
+	// ApproximateSize returns the approximate number of bytes the
+	// key/value pairs of the bucket with a key greater than or equal to
+	// start and less than limit use in the underlying storage.  A nil
+	// start begins at the first key of the bucket and a nil limit ends
+	// after its last key.  It does not iterate over the keys, so the
+	// result may not account for recent changes, including the ones made
+	// by the current transaction.
+	//
+	// The interface contract guarantees at least the following errors will
+	// be returned (other implementation-specific errors are possible):
+	//   - ErrTxClosed if the transaction has already been closed
+	ApproximateSize(start, limit []byte) (int64, error)
+
+	// Stats returns statistics about the key/value pairs and nested
+	// buckets directly held by the bucket.  Unlike ApproximateSize, it
+	// visits every key of the bucket.
+	//
+	// The interface contract guarantees at least the following errors will
+	// be returned (other implementation-specific errors are possible):
+	//   - ErrTxClosed if the transaction has already been closed
+	Stats() (*BucketStats, error)
+
 	// Writable returns whether or not the bucket is writable.
 	Writable() bool
 
@@ -464,3 +535,21 @@ type DB interface {
 	// back or committed).
 	Close() error
 }
+
+// BulkLoader is implemented by databases which can trade durability of recent
+// updates for throughput while many blocks are stored in a row, such as during
+// the initial block download.  Updates committed during a bulk load may be lost
+// on an unexpected shutdown, but the database remains consistent.
+type BulkLoader interface {
+	// SetBulkLoad enables or disables the bulk loading mode.
+	SetBulkLoad(enabled bool)
+}
+
+// CacheSizer is implemented by databases which keep updates in memory before
+// writing them to disk, such as the changes to the unspent transaction output
+// set of the latest blocks.
+type CacheSizer interface {
+	// CacheSize returns the number of cached updates and their estimated
+	// size in bytes.
+	CacheSize() (int, uint64)
+}
@@ -0,0 +1,28 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package deposits tracks the deposits made to addresses bound to external
+reference IDs, such as the customer accounts of an exchange, and reports them
+as they reach configured confirmation counts.
+
+The addresses are derived from the external branch of an account described by
+an account bundle, so only the extended public key of the account is needed
+and the coins are spent by the wallet holding its private key.  Each reference
+ID is bound to the next unused address the first time it is requested, and to
+the same address afterwards.
+
+The Tracker is told about every block connected to and disconnected from the
+main chain.  It finds the outputs paying to the tracked addresses and returns
+an event each time a deposit reaches one of the confirmation counts.  When the
+block of a deposit is disconnected by a reorganization, the deposit is dropped
+and, when it had already been reported, a reorged event is returned so the
+receiver can revoke the credit.  A deposit whose transaction is mined again is
+reported again from its first confirmation count.
+
+The state of the Tracker, including the last block it processed, is saved with
+Save and restored with Load, so the blocks connected while the state was not
+saved can be processed again.
+*/
+package deposits
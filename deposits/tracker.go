@@ -0,0 +1,451 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package deposits
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/btcutil/hdkeychain"
+	"github.com/pkt-cash/pktd/acctbundle"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/txscript"
+)
+
+// MaxRefLen is the maximum length of a reference ID.
+const MaxRefLen = 128
+
+var (
+	// ErrInvalidRef is returned for an empty or too long reference ID.
+	ErrInvalidRef = errors.New("invalid reference ID")
+
+	// ErrBundleMismatch is returned when loading the state saved by a
+	// tracker of another account.
+	ErrBundleMismatch = errors.New("saved deposits are of another account")
+)
+
+// DefaultConfirmations are the confirmation counts at which deposits are
+// reported when none are configured.
+var DefaultConfirmations = []int32{1, 6}
+
+// Status is the status of a deposit reported by an event.
+type Status string
+
+const (
+	// StatusConfirmed reports that a deposit reached a confirmation
+	// count.
+	StatusConfirmed Status = "confirmed"
+
+	// StatusReorged reports that the block of a deposit which was already
+	// reported was disconnected.
+	StatusReorged Status = "reorged"
+)
+
+// Config is the configuration of a tracker.
+type Config struct {
+	// Bundle is the account the addresses are derived from.
+	Bundle *acctbundle.Bundle
+
+	// Confirmations are the confirmation counts at which deposits are
+	// reported, DefaultConfirmations when empty.
+	Confirmations []int32
+
+	ChainParams *chaincfg.Params
+}
+
+// Address is an address bound to a reference ID.
+type Address struct {
+	// Ref is the reference ID.
+	Ref string `json:"ref"`
+
+	// Address is the encoded address.
+	Address string `json:"address"`
+
+	// Index is the index of the address on the external branch of the
+	// account.
+	Index uint32 `json:"index"`
+}
+
+// Deposit is an output paying to a tracked address.
+type Deposit struct {
+	// Ref is the reference ID of the address paid.
+	Ref string
+
+	// Address is the address paid.
+	Address string
+
+	// TxID and Vout are the outpoint of the output.
+	TxID chainhash.Hash
+	Vout uint32
+
+	// Amount is the amount paid.
+	Amount btcutil.Amount
+
+	// BlockHash and Height are the block the deposit was mined in.
+	BlockHash chainhash.Hash
+	Height    int32
+
+	// Confirmations is the number of confirmations of the deposit when
+	// it is returned by Deposits, and the confirmation count reported by
+	// an event.
+	Confirmations int32
+
+	// Reported is the highest confirmation count reported for the
+	// deposit, zero before the first.
+	Reported int32
+}
+
+// Event reports that a deposit reached a confirmation count, or that the
+// block of a reported deposit was disconnected.
+type Event struct {
+	Deposit
+
+	Status Status
+}
+
+// Tracker binds reference IDs to the addresses of an account and tracks the
+// deposits made to them.
+//
+// It is safe for concurrent access.
+type Tracker struct {
+	mtx       sync.Mutex
+	cfg       Config
+	nextIndex uint32
+	addresses []*Address
+	refs      map[string]*Address
+	scripts   map[string]*Address
+	deposits  []*Deposit
+	tipHash   chainhash.Hash
+	tipHeight int32
+}
+
+// New returns a tracker without addresses for the passed configuration, which
+// processes blocks from the one with the passed hash and height.
+func New(cfg *Config, tipHash *chainhash.Hash, tipHeight int32) (*Tracker, error) {
+	if err := cfg.Bundle.Validate(cfg.ChainParams); err != nil {
+		return nil, err
+	}
+
+	confs := cfg.Confirmations
+	if len(confs) == 0 {
+		confs = DefaultConfirmations
+	}
+	seen := make(map[int32]struct{}, len(confs))
+	sorted := make([]int32, 0, len(confs))
+	for _, c := range confs {
+		if c < 1 {
+			return nil, fmt.Errorf("invalid confirmation count %d", c)
+		}
+		if _, ok := seen[c]; ok {
+			continue
+		}
+		seen[c] = struct{}{}
+		sorted = append(sorted, c)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	t := &Tracker{
+		cfg:       *cfg,
+		refs:      make(map[string]*Address),
+		scripts:   make(map[string]*Address),
+		tipHash:   *tipHash,
+		tipHeight: tipHeight,
+	}
+	t.cfg.Confirmations = sorted
+	return t, nil
+}
+
+// Confirmations returns the confirmation counts at which deposits are
+// reported, in increasing order.
+func (t *Tracker) Confirmations() []int32 {
+	return append([]int32(nil), t.cfg.Confirmations...)
+}
+
+// Tip returns the hash and height of the last block processed.
+func (t *Tracker) Tip() (chainhash.Hash, int32) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	return t.tipHash, t.tipHeight
+}
+
+// track adds an address to the tracked addresses.
+//
+// This function MUST be called with the tracker lock held.
+func (t *Tracker) track(a *Address) error {
+	addr, err := btcutil.DecodeAddress(a.Address, t.cfg.ChainParams)
+	if err != nil {
+		return err
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return err
+	}
+	t.addresses = append(t.addresses, a)
+	t.refs[a.Ref] = a
+	t.scripts[string(pkScript)] = a
+	return nil
+}
+
+// CheckRef returns an error when the passed reference ID is empty or longer
+// than MaxRefLen.
+func CheckRef(ref string) error {
+	if ref == "" || len(ref) > MaxRefLen {
+		return fmt.Errorf("%v: %q", ErrInvalidRef, ref)
+	}
+	return nil
+}
+
+// NewAddresses returns the addresses bound to the passed reference IDs, in the
+// same order.  A reference ID without an address is bound to the next unused
+// address of the account.
+func (t *Tracker) NewAddresses(refs []string) ([]Address, error) {
+	for _, ref := range refs {
+		if err := CheckRef(ref); err != nil {
+			return nil, err
+		}
+	}
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	addrs := make([]Address, 0, len(refs))
+	for _, ref := range refs {
+		if a, ok := t.refs[ref]; ok {
+			addrs = append(addrs, *a)
+			continue
+		}
+
+		// Skip the rare indexes which do not derive a valid key.
+		addr, err := t.cfg.Bundle.Address(acctbundle.BranchReceive, t.nextIndex,
+			t.cfg.ChainParams)
+		for err == hdkeychain.ErrInvalidChild {
+			t.nextIndex++
+			addr, err = t.cfg.Bundle.Address(acctbundle.BranchReceive,
+				t.nextIndex, t.cfg.ChainParams)
+		}
+		if err != nil {
+			return nil, err
+		}
+		a := &Address{
+			Ref:     ref,
+			Address: addr.EncodeAddress(),
+			Index:   t.nextIndex,
+		}
+		if err := t.track(a); err != nil {
+			return nil, err
+		}
+		t.nextIndex++
+		addrs = append(addrs, *a)
+	}
+	return addrs, nil
+}
+
+// Deposits returns the deposits to the address of the passed reference ID, or
+// to every address when it is empty, in the order they were mined.
+func (t *Tracker) Deposits(ref string) []Deposit {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	var deposits []Deposit
+	for _, d := range t.deposits {
+		if ref != "" && d.Ref != ref {
+			continue
+		}
+		dc := *d
+		dc.Confirmations = t.tipHeight - d.Height + 1
+		deposits = append(deposits, dc)
+	}
+	return deposits
+}
+
+// BlockConnected finds the deposits in the passed block, which must extend
+// the last block processed, and returns the events of the deposits reaching a
+// confirmation count with it.
+func (t *Tracker) BlockConnected(block *btcutil.Block) []Event {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	for _, tx := range block.Transactions() {
+		for vout, txOut := range tx.MsgTx().TxOut {
+			a, ok := t.scripts[string(txOut.PkScript)]
+			if !ok {
+				continue
+			}
+			t.deposits = append(t.deposits, &Deposit{
+				Ref:       a.Ref,
+				Address:   a.Address,
+				TxID:      *tx.Hash(),
+				Vout:      uint32(vout),
+				Amount:    btcutil.Amount(txOut.Value),
+				BlockHash: *block.Hash(),
+				Height:    block.Height(),
+			})
+		}
+	}
+	t.tipHash = *block.Hash()
+	t.tipHeight = block.Height()
+
+	confs := t.cfg.Confirmations
+	maxConfs := confs[len(confs)-1]
+	var events []Event
+	for _, d := range t.deposits {
+		if d.Reported >= maxConfs {
+			continue
+		}
+		depth := t.tipHeight - d.Height + 1
+		for _, c := range confs {
+			if c <= d.Reported || c > depth {
+				continue
+			}
+			d.Reported = c
+			event := Event{Deposit: *d, Status: StatusConfirmed}
+			event.Confirmations = c
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// BlockDisconnected drops the deposits in the passed block, which must be the
+// last block processed, and returns the reorged events of the ones which were
+// already reported.
+func (t *Tracker) BlockDisconnected(block *btcutil.Block) []Event {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	var events []Event
+	kept := t.deposits[:0]
+	for _, d := range t.deposits {
+		if d.BlockHash != *block.Hash() {
+			kept = append(kept, d)
+			continue
+		}
+		if d.Reported > 0 {
+			event := Event{Deposit: *d, Status: StatusReorged}
+			event.Confirmations = d.Reported
+			events = append(events, event)
+		}
+	}
+	for i := len(kept); i < len(t.deposits); i++ {
+		t.deposits[i] = nil
+	}
+	t.deposits = kept
+	t.tipHash = block.MsgBlock().Header.PrevBlock
+	t.tipHeight = block.Height() - 1
+	return events
+}
+
+// savedDeposit is the saved form of a deposit.
+type savedDeposit struct {
+	Ref       string `json:"ref"`
+	Address   string `json:"address"`
+	TxID      string `json:"txid"`
+	Vout      uint32 `json:"vout"`
+	Amount    int64  `json:"amount"`
+	BlockHash string `json:"blockhash"`
+	Height    int32  `json:"height"`
+	Reported  int32  `json:"reported"`
+}
+
+// savedState is the saved form of a tracker.
+type savedState struct {
+	XPub      string         `json:"xpub"`
+	NextIndex uint32         `json:"nextindex"`
+	TipHash   string         `json:"tiphash"`
+	TipHeight int32          `json:"tipheight"`
+	Addresses []Address      `json:"addresses"`
+	Deposits  []savedDeposit `json:"deposits"`
+}
+
+// Save writes the state of the tracker as JSON to the passed writer.
+func (t *Tracker) Save(w io.Writer) error {
+	t.mtx.Lock()
+	state := savedState{
+		XPub:      t.cfg.Bundle.XPub,
+		NextIndex: t.nextIndex,
+		TipHash:   t.tipHash.String(),
+		TipHeight: t.tipHeight,
+		Addresses: make([]Address, 0, len(t.addresses)),
+		Deposits:  make([]savedDeposit, 0, len(t.deposits)),
+	}
+	for _, a := range t.addresses {
+		state.Addresses = append(state.Addresses, *a)
+	}
+	for _, d := range t.deposits {
+		state.Deposits = append(state.Deposits, savedDeposit{
+			Ref:       d.Ref,
+			Address:   d.Address,
+			TxID:      d.TxID.String(),
+			Vout:      d.Vout,
+			Amount:    int64(d.Amount),
+			BlockHash: d.BlockHash.String(),
+			Height:    d.Height,
+			Reported:  d.Reported,
+		})
+	}
+	t.mtx.Unlock()
+
+	return json.NewEncoder(w).Encode(&state)
+}
+
+// Load returns a tracker for the passed configuration with the state read from
+// the passed reader, which must have been saved by a tracker of the same
+// account.
+func Load(cfg *Config, r io.Reader) (*Tracker, error) {
+	var state savedState
+	if err := json.NewDecoder(r).Decode(&state); err != nil {
+		return nil, err
+	}
+	if state.XPub != cfg.Bundle.XPub {
+		return nil, ErrBundleMismatch
+	}
+	tipHash, err := chainhash.NewHashFromStr(state.TipHash)
+	if err != nil {
+		return nil, err
+	}
+	t, err := New(cfg, tipHash, state.TipHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	t.nextIndex = state.NextIndex
+	for i := range state.Addresses {
+		a := state.Addresses[i]
+		if _, ok := t.refs[a.Ref]; ok || a.Ref == "" {
+			return nil, fmt.Errorf("%v: %q", ErrInvalidRef, a.Ref)
+		}
+		if err := t.track(&a); err != nil {
+			return nil, err
+		}
+	}
+	for _, sd := range state.Deposits {
+		txID, err := chainhash.NewHashFromStr(sd.TxID)
+		if err != nil {
+			return nil, err
+		}
+		blockHash, err := chainhash.NewHashFromStr(sd.BlockHash)
+		if err != nil {
+			return nil, err
+		}
+		t.deposits = append(t.deposits, &Deposit{
+			Ref:       sd.Ref,
+			Address:   sd.Address,
+			TxID:      *txID,
+			Vout:      sd.Vout,
+			Amount:    btcutil.Amount(sd.Amount),
+			BlockHash: *blockHash,
+			Height:    sd.Height,
+			Reported:  sd.Reported,
+		})
+	}
+	return t, nil
+}
@@ -0,0 +1,198 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package deposits
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/btcutil/hdkeychain"
+	"github.com/pkt-cash/pktd/acctbundle"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// testTracker returns a tracker of a test account reporting deposits at the
+// passed confirmation counts.
+func testTracker(t *testing.T, confs ...int32) (*Tracker, *Config) {
+	params := &chaincfg.PktMainNetParams
+	key, err := hdkeychain.NewMaster(bytes.Repeat([]byte{1}, 32), params)
+	if err != nil {
+		t.Fatalf("NewMaster: %v", err)
+	}
+	for _, i := range []uint32{84, params.HDCoinType, 0} {
+		key, err = key.Child(hdkeychain.HardenedKeyStart + i)
+		if err != nil {
+			t.Fatalf("Child: %v", err)
+		}
+	}
+	b, err := acctbundle.New("deposits", 0, acctbundle.ScriptP2WPKH, 0,
+		key, 0)
+	if err != nil {
+		t.Fatalf("New bundle: %v", err)
+	}
+	cfg := &Config{
+		Bundle:        b,
+		Confirmations: confs,
+		ChainParams:   params,
+	}
+	tr, err := New(cfg, &chainhash.Hash{}, 0)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return tr, cfg
+}
+
+// testBlock returns a block at the passed height on top of the passed block
+// with a transaction paying the passed amounts to the passed addresses.
+func testBlock(t *testing.T, prev *chainhash.Hash, height int32,
+	addrs []string, amounts []btcutil.Amount) *btcutil.Block {
+
+	tx := wire.NewMsgTx(wire.TxVersion)
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Index: uint32(height)}, nil, nil))
+	for i, a := range addrs {
+		addr, err := btcutil.DecodeAddress(a, &chaincfg.PktMainNetParams)
+		if err != nil {
+			t.Fatalf("DecodeAddress: %v", err)
+		}
+		pkScript, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			t.Fatalf("PayToAddrScript: %v", err)
+		}
+		tx.AddTxOut(wire.NewTxOut(int64(amounts[i]), pkScript))
+	}
+	msgBlock := wire.NewMsgBlock(wire.NewBlockHeader(1, prev,
+		&chainhash.Hash{}, 0, 0))
+	msgBlock.Header.Timestamp = time.Unix(int64(height), 0)
+	msgBlock.AddTransaction(tx)
+	block := btcutil.NewBlock(msgBlock)
+	block.SetHeight(height)
+	return block
+}
+
+// TestNewAddresses ensures reference IDs are bound to consecutive addresses of
+// the account, once.
+func TestNewAddresses(t *testing.T) {
+	tr, cfg := testTracker(t)
+	addrs, err := tr.NewAddresses([]string{"alice", "bob", "alice"})
+	if err != nil {
+		t.Fatalf("NewAddresses: %v", err)
+	}
+	if len(addrs) != 3 || addrs[0] != addrs[2] || addrs[1].Index != 1 {
+		t.Fatalf("NewAddresses: got %+v", addrs)
+	}
+	want, err := cfg.Bundle.Address(acctbundle.BranchReceive, 1,
+		cfg.ChainParams)
+	if err != nil {
+		t.Fatalf("Address: %v", err)
+	}
+	if addrs[1].Address != want.EncodeAddress() {
+		t.Fatalf("NewAddresses: got address %s, want %s",
+			addrs[1].Address, want)
+	}
+
+	// An invalid reference ID fails the whole request.
+	if _, err := tr.NewAddresses([]string{"carol", ""}); err == nil {
+		t.Fatalf("NewAddresses: unexpected success with empty reference")
+	}
+	more, err := tr.NewAddresses([]string{"bob", "carol"})
+	if err != nil {
+		t.Fatalf("NewAddresses: %v", err)
+	}
+	if more[0] != addrs[1] || more[1].Index != 2 {
+		t.Fatalf("NewAddresses: got %+v", more)
+	}
+}
+
+// TestDepositConfirmations ensures deposits are reported at each confirmation
+// count, revoked when their block is disconnected, and reported again once
+// mined in another block.
+func TestDepositConfirmations(t *testing.T) {
+	tr, cfg := testTracker(t, 3, 1, 3)
+	addrs, err := tr.NewAddresses([]string{"alice", "bob"})
+	if err != nil {
+		t.Fatalf("NewAddresses: %v", err)
+	}
+
+	genesis := chainhash.Hash{}
+	b1 := testBlock(t, &genesis, 1, []string{addrs[0].Address},
+		[]btcutil.Amount{5e8})
+	events := tr.BlockConnected(b1)
+	if len(events) != 1 || events[0].Ref != "alice" ||
+		events[0].Status != StatusConfirmed ||
+		events[0].Confirmations != 1 || events[0].Amount != 5e8 {
+
+		t.Fatalf("BlockConnected: got %+v", events)
+	}
+	b2 := testBlock(t, b1.Hash(), 2, []string{addrs[1].Address},
+		[]btcutil.Amount{1e8})
+	if events := tr.BlockConnected(b2); len(events) != 1 ||
+		events[0].Ref != "bob" {
+
+		t.Fatalf("BlockConnected: got %+v", events)
+	}
+	b3 := testBlock(t, b2.Hash(), 3, nil, nil)
+	events = tr.BlockConnected(b3)
+	if len(events) != 1 || events[0].Ref != "alice" ||
+		events[0].Confirmations != 3 {
+
+		t.Fatalf("BlockConnected: got %+v", events)
+	}
+	if deposits := tr.Deposits("bob"); len(deposits) != 1 ||
+		deposits[0].Confirmations != 2 || deposits[0].Reported != 1 {
+
+		t.Fatalf("Deposits: got %+v", deposits)
+	}
+
+	// The state is saved and restored.
+	var buf bytes.Buffer
+	if err := tr.Save(&buf); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	tr, err = Load(cfg, &buf)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if hash, height := tr.Tip(); hash != *b3.Hash() || height != 3 {
+		t.Fatalf("Tip: got %v %d after Load", hash, height)
+	}
+	if len(tr.Deposits("")) != 2 {
+		t.Fatalf("Deposits: got %+v after Load", tr.Deposits(""))
+	}
+
+	// Disconnecting the blocks down to the deposit of bob revokes it,
+	// and it is reported again when mined in another block.
+	if events := tr.BlockDisconnected(b3); len(events) != 0 {
+		t.Fatalf("BlockDisconnected: got %+v", events)
+	}
+	events = tr.BlockDisconnected(b2)
+	if len(events) != 1 || events[0].Ref != "bob" ||
+		events[0].Status != StatusReorged || events[0].Confirmations != 1 {
+
+		t.Fatalf("BlockDisconnected: got %+v", events)
+	}
+	if deposits := tr.Deposits(""); len(deposits) != 1 ||
+		deposits[0].Ref != "alice" || deposits[0].Confirmations != 1 {
+
+		t.Fatalf("Deposits: got %+v after reorg", deposits)
+	}
+	unbound, err := cfg.Bundle.Address(acctbundle.BranchReceive, 99,
+		cfg.ChainParams)
+	if err != nil {
+		t.Fatalf("Address: %v", err)
+	}
+	b2b := testBlock(t, b1.Hash(), 2, []string{addrs[1].Address,
+		unbound.EncodeAddress()}, []btcutil.Amount{1e8, 1e8})
+	events = tr.BlockConnected(b2b)
+	if len(events) != 1 || events[0].Ref != "bob" ||
+		events[0].BlockHash != *b2b.Hash() {
+
+		t.Fatalf("BlockConnected: got %+v after reorg", events)
+	}
+}
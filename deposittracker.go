@@ -0,0 +1,215 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/acctbundle"
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/deposits"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// depositsFilename is the name of the file in the data directory holding the
+// state of the deposit tracker.
+const depositsFilename = "deposits.json"
+
+// depositTrackerConfig holds the configuration of the deposit tracker.
+type depositTrackerConfig struct {
+	// BundleFile is the file holding the account bundle the deposit
+	// addresses are derived from.
+	BundleFile string
+
+	// StateFile is the file the state of the tracker is saved to.
+	StateFile string
+
+	// Confirmations are the confirmation counts at which deposits are
+	// sent to the webhooks.
+	Confirmations []int32
+
+	Chain       *blockchain.BlockChain
+	ChainParams *chaincfg.Params
+
+	// Webhooks receives the deposit events.  It may be nil.
+	Webhooks *webhookManager
+}
+
+// depositTracker runs a deposit tracker on the main chain for exchanges, which
+// bind deposit addresses to their own reference IDs and receive the deposits
+// to them as webhook events.  Its state is saved after every block so it
+// survives restarts.
+type depositTracker struct {
+	cfg     depositTrackerConfig
+	tracker *deposits.Tracker
+
+	// saveMtx serializes the writes of the state file.
+	saveMtx sync.Mutex
+}
+
+// newDepositTracker returns a deposit tracker for the passed configuration.
+// The saved state is restored and brought up to date with the blocks which
+// were connected and disconnected since it was saved.
+func newDepositTracker(cfg *depositTrackerConfig) (*depositTracker, error) {
+	data, err := ioutil.ReadFile(cfg.BundleFile)
+	if err != nil {
+		return nil, err
+	}
+	var bundle acctbundle.Bundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, fmt.Errorf("invalid account bundle %s: %v",
+			cfg.BundleFile, err)
+	}
+	trackerCfg := &deposits.Config{
+		Bundle:        &bundle,
+		Confirmations: cfg.Confirmations,
+		ChainParams:   cfg.ChainParams,
+	}
+
+	d := &depositTracker{cfg: *cfg}
+	f, err := os.Open(cfg.StateFile)
+	switch {
+	case os.IsNotExist(err):
+		best := cfg.Chain.BestSnapshot()
+		d.tracker, err = deposits.New(trackerCfg, &best.Hash, best.Height)
+	case err == nil:
+		d.tracker, err = deposits.Load(trackerCfg, f)
+		f.Close()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to load deposits from %s: %v",
+			cfg.StateFile, err)
+	}
+	if err := d.catchUp(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// catchUp disconnects the blocks processed by the tracker which are no longer
+// in the main chain and connects the main chain blocks after them.
+func (d *depositTracker) catchUp() error {
+	chain := d.cfg.Chain
+	tipHash, tipHeight := d.tracker.Tip()
+	for !chain.MainChainHasBlock(&tipHash) {
+		// Only the header of a disconnected block is needed to drop
+		// its deposits.
+		header, err := chain.HeaderByHash(&tipHash)
+		if err != nil {
+			return err
+		}
+		block := btcutil.NewBlock(&wire.MsgBlock{Header: header})
+		block.SetHeight(tipHeight)
+		d.notify(d.tracker.BlockDisconnected(block))
+		tipHash, tipHeight = d.tracker.Tip()
+	}
+
+	best := chain.BestSnapshot()
+	if tipHeight < best.Height {
+		srvrLog.Infof("Catching up the deposit tracker from height %d "+
+			"to %d", tipHeight, best.Height)
+	}
+	for height := tipHeight + 1; height <= best.Height; height++ {
+		block, err := chain.BlockByHeight(height)
+		if err != nil {
+			return err
+		}
+		d.notify(d.tracker.BlockConnected(block))
+	}
+	return d.save()
+}
+
+// save writes the state of the tracker to the state file, replacing it
+// atomically.
+func (d *depositTracker) save() error {
+	d.saveMtx.Lock()
+	defer d.saveMtx.Unlock()
+
+	tmpFile := d.cfg.StateFile + ".tmp"
+	f, err := os.OpenFile(tmpFile, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if err := d.tracker.Save(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, d.cfg.StateFile)
+}
+
+// notify logs the passed events and sends them to the webhooks.
+func (d *depositTracker) notify(events []deposits.Event) {
+	for i := range events {
+		e := &events[i]
+		srvrLog.Infof("Deposit %v:%d of %v to %s (ref %s) %s at %d "+
+			"confirmations", e.TxID, e.Vout, e.Amount, e.Address,
+			e.Ref, e.Status, e.Confirmations)
+		if d.cfg.Webhooks == nil {
+			continue
+		}
+		d.cfg.Webhooks.NotifyDeposit(&webhookDepositInfo{
+			Ref:           e.Ref,
+			Address:       e.Address,
+			TxID:          e.TxID.String(),
+			Vout:          e.Vout,
+			Amount:        e.Amount.ToBTC(),
+			BlockHash:     e.BlockHash.String(),
+			Height:        e.Height,
+			Confirmations: e.Confirmations,
+			Status:        string(e.Status),
+		})
+	}
+}
+
+// handleBlockchainNotification passes the blocks connected to and
+// disconnected from the main chain to the tracker, and saves its state.
+func (d *depositTracker) handleBlockchainNotification(notification *blockchain.Notification) {
+	var events []deposits.Event
+	switch notification.Type {
+	case blockchain.NTBlockConnected:
+		events = d.tracker.BlockConnected(notification.Data.(*btcutil.Block))
+	case blockchain.NTBlockDisconnected:
+		events = d.tracker.BlockDisconnected(notification.Data.(*btcutil.Block))
+	default:
+		return
+	}
+	d.notify(events)
+	if err := d.save(); err != nil {
+		srvrLog.Errorf("Unable to save deposits to %s: %v",
+			d.cfg.StateFile, err)
+	}
+}
+
+// NewAddresses binds deposit addresses to the passed reference IDs and saves
+// them before they are returned, so no address is handed out twice.
+func (d *depositTracker) NewAddresses(refs []string) ([]deposits.Address, error) {
+	addrs, err := d.tracker.NewAddresses(refs)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.save(); err != nil {
+		return nil, err
+	}
+	return addrs, nil
+}
+
+// Deposits returns the deposits to the address bound to the passed reference
+// ID, or to every deposit address when it is empty.
+func (d *depositTracker) Deposits(ref string) []deposits.Deposit {
+	return d.tracker.Deposits(ref)
+}
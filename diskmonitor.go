@@ -0,0 +1,250 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkt-cash/pktd/btcjson"
+)
+
+const (
+	// defaultDiskWarnSpace is the free space in MiB on the data directory
+	// below which a low disk space warning is raised.
+	defaultDiskWarnSpace = 2048
+
+	// defaultDiskSafeModeSpace is the free space in MiB on the data
+	// directory below which the node enters the safe mode.
+	defaultDiskSafeModeSpace = 512
+
+	// diskCheckInterval is the time between two checks of the free space.
+	diskCheckInterval = 30 * time.Second
+
+	// diskSpaceHysteresis is the free space in bytes which must be
+	// available above a threshold before the warning or the safe mode it
+	// raised is cleared, so the state doesn't flap around the threshold.
+	diskSpaceHysteresis = 64 << 20
+)
+
+// The states of the free space of the data directory.
+const (
+	diskSpaceOK       = "ok"
+	diskSpaceLow      = "low"
+	diskSpaceSafeMode = "safemode"
+)
+
+// diskMonitorConfig holds the configuration of the disk space monitor.
+type diskMonitorConfig struct {
+	// Dir is the data directory whose filesystem is watched.
+	Dir string
+
+	// WarnSpace is the free space in bytes below which a warning is
+	// raised and SafeModeSpace the free space below which the safe mode is
+	// entered.  Either is disabled when 0.
+	WarnSpace     uint64
+	SafeModeSpace uint64
+
+	// DiskSpace returns the free and the total space of the filesystem
+	// holding a directory.
+	DiskSpace func(dir string) (free, total uint64, err error)
+
+	// SetSafeMode enters or leaves the safe mode.
+	SetSafeMode func(enabled bool)
+
+	// Notify, when not nil, is called whenever the state changes.
+	Notify func(*webhookDiskSpaceInfo)
+}
+
+// diskMonitor watches the free space on the data directory.  It warns when it
+// runs low and enters the safe mode before the disk is full, since the
+// database can be corrupted by a write failing halfway.  In safe mode blocks
+// are no longer accepted while the queries keep being served, until enough
+// space is freed.
+type diskMonitor struct {
+	cfg diskMonitorConfig
+
+	mtx     sync.Mutex
+	state   string
+	since   time.Time
+	free    uint64
+	total   uint64
+	checked time.Time
+	err     error
+
+	wg   sync.WaitGroup
+	quit chan struct{}
+}
+
+// newDiskMonitor returns a disk space monitor with the passed configuration.
+func newDiskMonitor(cfg *diskMonitorConfig) *diskMonitor {
+	return &diskMonitor{
+		cfg:   *cfg,
+		state: diskSpaceOK,
+		quit:  make(chan struct{}),
+	}
+}
+
+// Start checks the free space and keeps checking it periodically.
+func (m *diskMonitor) Start() {
+	m.check(time.Now())
+	m.wg.Add(1)
+	go m.handler()
+}
+
+// Stop stops the disk space monitor and waits for it to finish.
+func (m *diskMonitor) Stop() {
+	close(m.quit)
+	m.wg.Wait()
+}
+
+// handler checks the free space every diskCheckInterval.  It must be run as a
+// goroutine.
+func (m *diskMonitor) handler() {
+	ticker := time.NewTicker(diskCheckInterval)
+	defer ticker.Stop()
+out:
+	for {
+		select {
+		case <-ticker.C:
+			m.check(time.Now())
+		case <-m.quit:
+			break out
+		}
+	}
+	m.wg.Done()
+}
+
+// nextState returns the state of the free space given the passed free space
+// and current state.  A state is only left for a better one once the free
+// space is diskSpaceHysteresis above its threshold.
+func (m *diskMonitor) nextState(free uint64, state string) string {
+	below := func(threshold uint64, current bool) bool {
+		if threshold == 0 {
+			return false
+		}
+		if current {
+			threshold += diskSpaceHysteresis
+		}
+		return free < threshold
+	}
+	switch {
+	case below(m.cfg.SafeModeSpace, state == diskSpaceSafeMode):
+		return diskSpaceSafeMode
+	case below(m.cfg.WarnSpace, state != diskSpaceOK):
+		return diskSpaceLow
+	default:
+		return diskSpaceOK
+	}
+}
+
+// check queries the free space at the passed time, entering or leaving the
+// safe mode and raising or clearing the warning accordingly.
+func (m *diskMonitor) check(now time.Time) {
+	free, total, err := m.cfg.DiskSpace(m.cfg.Dir)
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.checked = now
+	if err != nil {
+		if m.err == nil || m.err.Error() != err.Error() {
+			srvrLog.Errorf("Unable to check the free space on %s: %v",
+				m.cfg.Dir, err)
+		}
+		m.err = err
+		return
+	}
+	m.err = nil
+	m.free, m.total = free, total
+
+	state := m.nextState(free, m.state)
+	if state == m.state {
+		return
+	}
+	previous := m.state
+	m.state = state
+	m.since = now
+
+	switch state {
+	case diskSpaceSafeMode:
+		srvrLog.Errorf("Only %s free on %s, entering safe mode: blocks "+
+			"are not accepted until space is freed", formatMiB(free),
+			m.cfg.Dir)
+	case diskSpaceLow:
+		srvrLog.Warnf("Low disk space: only %s free on %s",
+			formatMiB(free), m.cfg.Dir)
+	default:
+		srvrLog.Infof("Disk space back to normal: %s free on %s",
+			formatMiB(free), m.cfg.Dir)
+	}
+	if previous == diskSpaceSafeMode {
+		srvrLog.Infof("Leaving safe mode, accepting blocks again")
+	}
+	if (state == diskSpaceSafeMode) != (previous == diskSpaceSafeMode) {
+		m.cfg.SetSafeMode(state == diskSpaceSafeMode)
+	}
+
+	if m.cfg.Notify != nil {
+		m.cfg.Notify(&webhookDiskSpaceInfo{
+			Status:   state,
+			Previous: previous,
+			Free:     free,
+			Total:    total,
+		})
+	}
+}
+
+// formatMiB formats the passed number of bytes in MiB for the log.
+func formatMiB(n uint64) string {
+	return fmt.Sprintf("%d MiB", n>>20)
+}
+
+// Warning returns the warning reported by getinfo and getnetworkinfo while the
+// free space is low, or an empty string.
+func (m *diskMonitor) Warning() string {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	switch m.state {
+	case diskSpaceSafeMode:
+		return fmt.Sprintf("The disk of the data directory is almost "+
+			"full (%s free), blocks are not accepted until space "+
+			"is freed", formatMiB(m.free))
+	case diskSpaceLow:
+		return fmt.Sprintf("Low disk space on the data directory "+
+			"(%s free)", formatMiB(m.free))
+	}
+	return ""
+}
+
+// Status returns the state of the free space as of the last check for the
+// getdiskstatus RPC.
+func (m *diskMonitor) Status() *btcjson.GetDiskStatusResult {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	status := &btcjson.GetDiskStatusResult{
+		Enabled:       true,
+		DataDir:       m.cfg.Dir,
+		Status:        m.state,
+		SafeMode:      m.state == diskSpaceSafeMode,
+		Free:          m.free,
+		Total:         m.total,
+		WarnSpace:     m.cfg.WarnSpace,
+		SafeModeSpace: m.cfg.SafeModeSpace,
+	}
+	if !m.since.IsZero() {
+		status.Since = m.since.Unix()
+	}
+	if !m.checked.IsZero() {
+		status.Checked = m.checked.Unix()
+	}
+	if m.err != nil {
+		status.Error = m.err.Error()
+	}
+	return status
+}
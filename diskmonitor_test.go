@@ -0,0 +1,98 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestDiskMonitor ensures the disk space monitor raises the warning and enters
+// the safe mode at their thresholds, and only leaves them once enough space is
+// freed.
+func TestDiskMonitor(t *testing.T) {
+	// The log rotator is not initialized in tests.
+	setLogLevels("off")
+	defer setLogLevels(defaultLogLevel)
+
+	const mib = 1 << 20
+	var (
+		free     uint64
+		spaceErr error
+		safeMode bool
+		events   []*webhookDiskSpaceInfo
+	)
+	m := newDiskMonitor(&diskMonitorConfig{
+		Dir:           "data",
+		WarnSpace:     1000 * mib,
+		SafeModeSpace: 200 * mib,
+		DiskSpace: func(dir string) (uint64, uint64, error) {
+			return free, 10000 * mib, spaceErr
+		},
+		SetSafeMode: func(enabled bool) { safeMode = enabled },
+		Notify:      func(e *webhookDiskSpaceInfo) { events = append(events, e) },
+	})
+
+	now := time.Unix(1600000000, 0)
+	tests := []struct {
+		free     uint64
+		status   string
+		previous string // Empty when the status doesn't change.
+	}{
+		{5000, diskSpaceOK, ""},
+		{999, diskSpaceLow, diskSpaceOK},
+		{1050, diskSpaceLow, ""},
+		{199, diskSpaceSafeMode, diskSpaceLow},
+		{250, diskSpaceSafeMode, ""},
+		{300, diskSpaceLow, diskSpaceSafeMode},
+		{1100, diskSpaceOK, diskSpaceLow},
+		{100, diskSpaceSafeMode, diskSpaceOK},
+	}
+	for i, test := range tests {
+		free = test.free * mib
+		numEvents := len(events)
+		now = now.Add(diskCheckInterval)
+		m.check(now)
+
+		status := m.Status()
+		if status.Status != test.status || status.Free != free ||
+			status.SafeMode != safeMode ||
+			safeMode != (test.status == diskSpaceSafeMode) {
+
+			t.Fatalf("test %d: unexpected status %+v, safe mode %v",
+				i, status, safeMode)
+		}
+		if (m.Warning() == "") != (test.status == diskSpaceOK) {
+			t.Fatalf("test %d: unexpected warning %q", i, m.Warning())
+		}
+		if test.previous == "" {
+			if len(events) != numEvents {
+				t.Fatalf("test %d: unexpected event %+v", i,
+					events[len(events)-1])
+			}
+			continue
+		}
+		if len(events) != numEvents+1 || status.Since != now.Unix() {
+			t.Fatalf("test %d: no event for the status change", i)
+		}
+		e := events[numEvents]
+		if e.Status != test.status || e.Previous != test.previous ||
+			e.Free != free {
+
+			t.Fatalf("test %d: unexpected event %+v", i, e)
+		}
+	}
+
+	// A failed check keeps the status and is reported.
+	spaceErr = errors.New("statfs failed")
+	m.check(now.Add(diskCheckInterval))
+	status := m.Status()
+	if status.Status != diskSpaceSafeMode || status.Error != spaceErr.Error() ||
+		!safeMode {
+
+		t.Fatalf("unexpected status %+v after a failed check", status)
+	}
+}
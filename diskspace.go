@@ -0,0 +1,20 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// +build darwin dragonfly freebsd linux
+
+package main
+
+import "syscall"
+
+// diskSpace returns the space available to unprivileged users and the total
+// size, in bytes, of the filesystem holding the passed directory.
+func diskSpace(dir string) (free, total uint64, err error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(dir, &st); err != nil {
+		return 0, 0, err
+	}
+	return uint64(st.Bavail) * uint64(st.Bsize),
+		uint64(st.Blocks) * uint64(st.Bsize), nil
+}
@@ -0,0 +1,16 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// +build !darwin,!dragonfly,!freebsd,!linux
+
+package main
+
+import "errors"
+
+// diskSpace returns an error since the free disk space can't be queried on
+// this platform.
+func diskSpace(dir string) (free, total uint64, err error) {
+	return 0, 0, errors.New("disk space monitoring is not supported on " +
+		"this platform")
+}
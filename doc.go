@@ -39,16 +39,60 @@ Application Options:
                             are {s, m, h}.  Minimum 1 second (24h0m0s)
       --banthreshold=       Maximum allowed ban score before disconnecting and
                             banning misbehaving peers.
-      --whitelist=          Add an IP network or IP that will not be banned.
-                            (eg. 192.168.1.0/24 or ::1)
+      --whitelist=          Grant permissions to the peers of an IP network or
+                            IP, in the form [perm,...@]IP[/CIDR]. The
+                            permissions are noban, relay, forcerelay, addr,
+                            download and all, noban when none is given (eg.
+                            192.168.1.0/24, ::1 or relay,noban@10.0.0.1)
+      --whitebind=          Add an interface/port to listen for connections and
+                            grant permissions to the peers connecting to it, in
+                            the form [perm,...@]interface:port, noban when none
+                            is given (eg. forcerelay@127.0.0.1:8335)
   -u, --rpcuser=            Username for RPC connections
   -P, --rpcpass=            Password for RPC connections
       --rpclimituser=       Username for limited RPC connections
       --rpclimitpass=       Password for limited RPC connections
       --rpclisten=          Add an interface/port to listen for RPC connections
                             (default port: 8334, testnet: 18334)
+      --rpclocal=           Add a Unix domain socket, or a named pipe such as
+                            \\.\pipe\pktd on Windows, to listen for RPC
+                            connections authenticated as the admin user by the
+                            permissions of the socket
+      --rpclimitlocal=      Add a Unix domain socket, or a named pipe on
+                            Windows, to listen for RPC connections authenticated
+                            as the limited user by the permissions of the socket
+      --rpclocalmode=       Permissions of the RPC Unix domain sockets, in octal
+                            (600)
       --rpccert=            File containing the certificate file
       --rpckey=             File containing the certificate key
+      --rpcclientca=        File containing the certificate authorities whose
+                            client certificates authenticate RPC connections as
+                            the admin user -- A valid client certificate
+                            replaces the username and password
+      --rpclimitclientca=   File containing the certificate authorities whose
+                            client certificates authenticate RPC connections as
+                            the limited user
+      --rpcrequireclientcert
+                            Refuse RPC connections without a valid client
+                            certificate -- NOTE: Requires --rpcclientca or
+                            --rpclimitclientca
+      --rpcacmedomain=      Add a public domain of the RPC server to obtain and
+                            renew its certificate for from an ACME certificate
+                            authority, accepting the terms of service of the
+                            authority -- NOTE: The RPC server must be reachable
+                            on port 443 of the domain
+      --rpcacmeemail=       Contact email address of the ACME account, for
+                            expiration notices
+      --rpcacmedirectory=   Directory URL of the ACME certificate authority
+                            (https://acme-v02.api.letsencrypt.org/directory)
+      --rpctokens           Accept the capability tokens minted with the
+                            minttoken RPC, sent as bearer tokens in the
+                            Authorization header, as RPC credentials --
+                            Deleting rpctoken.key in the data directory revokes
+                            every token
+      --rpcrevoketoken=     Refuse the RPC token with this identifier, as
+                            returned by minttoken, along with the tokens
+                            attenuated from it
       --rpcmaxclients=      Max number of RPC clients for standard connections
                             (10)
       --rpcmaxwebsockets=   Max number of RPC websocket connections (25)
@@ -0,0 +1,650 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/mempool"
+	"github.com/pkt-cash/pktd/peer"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+const (
+	// explorerRecentBlocks is the number of blocks listed on the front
+	// page of the explorer.
+	explorerRecentBlocks = 20
+
+	// explorerMaxMempool is the number of mempool transactions listed by
+	// the explorer, the ones paying the highest fee rate first.
+	explorerMaxMempool = 500
+
+	// explorerTimeout bounds the time allowed to read a request and write
+	// its response.
+	explorerTimeout = 30 * time.Second
+)
+
+// errExplorerNotFound is returned for blocks and transactions the explorer
+// can't find.
+var errExplorerNotFound = errors.New("not found")
+
+// explorerConfig is the configuration of the block explorer.  The lookups are
+// functions so the explorer can be tested without a full server.
+type explorerConfig struct {
+	// Listeners are the listeners the explorer is served on.
+	Listeners []net.Listener
+
+	// ChainParams are the parameters of the chain, used to encode the
+	// addresses.
+	ChainParams *chaincfg.Params
+
+	// BestSnapshot returns the best chain state.
+	BestSnapshot func() *blockchain.BestState
+
+	// BlockByHeight and BlockByHash return a block of the main chain
+	// with its height set.
+	BlockByHeight func(height int32) (*btcutil.Block, error)
+	BlockByHash   func(hash *chainhash.Hash) (*btcutil.Block, error)
+
+	// FetchTx returns a confirmed transaction and the hash of its block,
+	// or errExplorerNotFound.  It is nil without the transaction index.
+	FetchTx func(hash *chainhash.Hash) (*wire.MsgTx, *chainhash.Hash, error)
+
+	// MempoolTxs returns the transactions of the mempool.
+	MempoolTxs func() []*mempool.TxDesc
+
+	// Peers returns the connected peers.
+	Peers func() []*peer.Peer
+
+	// ListPeers lists the connected peers with their address, direction
+	// and user agent.  Only their number per network is shown otherwise,
+	// since the explorer has no authentication.
+	ListPeers bool
+}
+
+// explorer is a read-only block explorer served over HTTP, as HTML pages for
+// browsers and as JSON under /api/.
+type explorer struct {
+	cfg    explorerConfig
+	server *http.Server
+	wg     sync.WaitGroup
+}
+
+// newExplorer returns a block explorer using the passed configuration.
+func newExplorer(cfg *explorerConfig) *explorer {
+	e := &explorer{cfg: *cfg}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", e.handleIndex)
+	mux.HandleFunc("/block/", e.handleBlock)
+	mux.HandleFunc("/tx/", e.handleTx)
+	mux.HandleFunc("/mempool", e.handleMempool)
+	mux.HandleFunc("/peers", e.handlePeers)
+	mux.HandleFunc("/api/", e.handleAPI)
+	e.server = &http.Server{
+		Handler:      mux,
+		ReadTimeout:  explorerTimeout,
+		WriteTimeout: explorerTimeout,
+	}
+	return e
+}
+
+// Start serves the explorer on its listeners.
+func (e *explorer) Start() {
+	for _, listener := range e.cfg.Listeners {
+		e.wg.Add(1)
+		go func(listener net.Listener) {
+			defer e.wg.Done()
+			explLog.Infof("Block explorer listening on %s",
+				listener.Addr())
+			err := e.server.Serve(listener)
+			if err != http.ErrServerClosed {
+				explLog.Errorf("Block explorer stopped serving %s: %v",
+					listener.Addr(), err)
+			}
+		}(listener)
+	}
+}
+
+// Stop closes the listeners of the explorer and waits for it to stop.
+func (e *explorer) Stop() {
+	e.server.Close()
+	e.wg.Wait()
+}
+
+// explorerBlockSummary describes a block in the lists of the explorer.
+type explorerBlockSummary struct {
+	Hash   string    `json:"hash"`
+	Height int32     `json:"height"`
+	Time   time.Time `json:"time"`
+	NumTx  int       `json:"ntx"`
+	Size   int       `json:"size"`
+}
+
+// explorerIndex is the data of the front page.
+type explorerIndex struct {
+	Network      string                 `json:"network"`
+	BestHeight   int32                  `json:"bestheight"`
+	BestHash     string                 `json:"besthash"`
+	TotalTxns    uint64                 `json:"totaltxns"`
+	MempoolTxs   int                    `json:"mempooltxs"`
+	Peers        int                    `json:"peers"`
+	RecentBlocks []explorerBlockSummary `json:"recentblocks"`
+}
+
+// explorerTxSummary describes a transaction in the lists of the explorer.
+type explorerTxSummary struct {
+	TxID    string  `json:"txid"`
+	Size    int     `json:"vsize"`
+	Outputs float64 `json:"outputs"`
+	Fee     float64 `json:"fee,omitempty"`
+	FeeRate float64 `json:"feerate,omitempty"`
+}
+
+// explorerBlock is the data of a block page.
+type explorerBlock struct {
+	explorerBlockSummary
+	Confirmations int32               `json:"confirmations"`
+	Version       int32               `json:"version"`
+	PrevHash      string              `json:"previousblockhash"`
+	NextHash      string              `json:"nextblockhash,omitempty"`
+	MerkleRoot    string              `json:"merkleroot"`
+	Bits          string              `json:"bits"`
+	Nonce         uint32              `json:"nonce"`
+	Txs           []explorerTxSummary `json:"tx"`
+}
+
+// explorerInput is an input of a transaction page.
+type explorerInput struct {
+	Coinbase bool   `json:"coinbase,omitempty"`
+	TxID     string `json:"txid,omitempty"`
+	Vout     uint32 `json:"vout"`
+	Sequence uint32 `json:"sequence"`
+}
+
+// explorerOutput is an output of a transaction page.
+type explorerOutput struct {
+	Value     float64  `json:"value"`
+	Type      string   `json:"type"`
+	Addresses []string `json:"addresses,omitempty"`
+	Script    string   `json:"script"`
+}
+
+// explorerTx is the data of a transaction page.  BlockHash is empty for the
+// transactions of the mempool.
+type explorerTx struct {
+	TxID          string           `json:"txid"`
+	Hash          string           `json:"hash"`
+	Version       int32            `json:"version"`
+	LockTime      uint32           `json:"locktime"`
+	Size          int              `json:"size"`
+	VSize         int              `json:"vsize"`
+	BlockHash     string           `json:"blockhash,omitempty"`
+	BlockHeight   int32            `json:"blockheight,omitempty"`
+	Confirmations int32            `json:"confirmations"`
+	Fee           *float64         `json:"fee,omitempty"`
+	Inputs        []explorerInput  `json:"vin"`
+	Outputs       []explorerOutput `json:"vout"`
+}
+
+// explorerMempool is the data of the mempool page.
+type explorerMempool struct {
+	Count int                 `json:"count"`
+	VSize int                 `json:"vsize"`
+	Fees  float64             `json:"fees"`
+	Txs   []explorerTxSummary `json:"tx"`
+}
+
+// explorerPeer is a peer of the peers page.
+type explorerPeer struct {
+	ID        int32   `json:"id"`
+	Addr      string  `json:"addr"`
+	Network   string  `json:"network"`
+	Inbound   bool    `json:"inbound"`
+	UserAgent string  `json:"subver"`
+	Height    int32   `json:"height"`
+	PingMs    float64 `json:"pingms"`
+	ConnTime  int64   `json:"conntime"`
+}
+
+// explorerPeers is the data of the peers page, with the number of peers of
+// each network and, when they are listed, the peers.
+type explorerPeers struct {
+	Networks map[string]int `json:"networks"`
+	Peers    []explorerPeer `json:"peers,omitempty"`
+}
+
+// summarizeBlock returns the summary of a block.
+func summarizeBlock(block *btcutil.Block) explorerBlockSummary {
+	msgBlock := block.MsgBlock()
+	return explorerBlockSummary{
+		Hash:   block.Hash().String(),
+		Height: block.Height(),
+		Time:   msgBlock.Header.Timestamp,
+		NumTx:  len(msgBlock.Transactions),
+		Size:   msgBlock.SerializeSize(),
+	}
+}
+
+// txVirtualSize returns the virtual size of a transaction.
+func txVirtualSize(tx *wire.MsgTx) int {
+	return int(mempool.GetTxVirtualSize(btcutil.NewTx(tx)))
+}
+
+// outputTotal returns the total paid by the outputs of a transaction.
+func outputTotal(tx *wire.MsgTx) float64 {
+	var total btcutil.Amount
+	for _, txOut := range tx.TxOut {
+		total += btcutil.Amount(txOut.Value)
+	}
+	return total.ToBTC()
+}
+
+func (e *explorer) index() *explorerIndex {
+	best := e.cfg.BestSnapshot()
+	index := &explorerIndex{
+		Network:    e.cfg.ChainParams.Name,
+		BestHeight: best.Height,
+		BestHash:   best.Hash.String(),
+		TotalTxns:  best.TotalTxns,
+		MempoolTxs: len(e.cfg.MempoolTxs()),
+		Peers:      len(e.cfg.Peers()),
+	}
+	for h := best.Height; h >= 0 && h > best.Height-explorerRecentBlocks; h-- {
+		block, err := e.cfg.BlockByHeight(h)
+		if err != nil {
+			break
+		}
+		index.RecentBlocks = append(index.RecentBlocks, summarizeBlock(block))
+	}
+	return index
+}
+
+// block returns the block with the passed height or hash.
+func (e *explorer) block(id string) (*explorerBlock, error) {
+	var block *btcutil.Block
+	var err error
+	if height, perr := strconv.ParseInt(id, 10, 32); perr == nil {
+		block, err = e.cfg.BlockByHeight(int32(height))
+	} else {
+		hash, herr := chainhash.NewHashFromStr(id)
+		if herr != nil {
+			return nil, errExplorerNotFound
+		}
+		block, err = e.cfg.BlockByHash(hash)
+	}
+	if err != nil {
+		return nil, errExplorerNotFound
+	}
+
+	best := e.cfg.BestSnapshot()
+	header := &block.MsgBlock().Header
+	result := &explorerBlock{
+		explorerBlockSummary: summarizeBlock(block),
+		Confirmations:        best.Height - block.Height() + 1,
+		Version:              header.Version,
+		PrevHash:             header.PrevBlock.String(),
+		MerkleRoot:           header.MerkleRoot.String(),
+		Bits:                 strconv.FormatUint(uint64(header.Bits), 16),
+		Nonce:                header.Nonce,
+	}
+	if next, err := e.cfg.BlockByHeight(block.Height() + 1); err == nil {
+		result.NextHash = next.Hash().String()
+	}
+	for _, tx := range block.MsgBlock().Transactions {
+		result.Txs = append(result.Txs, explorerTxSummary{
+			TxID:    tx.TxHash().String(),
+			Size:    txVirtualSize(tx),
+			Outputs: outputTotal(tx),
+		})
+	}
+	return result, nil
+}
+
+// tx returns the transaction with the passed hash, from the mempool or the
+// transaction index.
+func (e *explorer) tx(id string) (*explorerTx, error) {
+	hash, err := chainhash.NewHashFromStr(id)
+	if err != nil {
+		return nil, errExplorerNotFound
+	}
+
+	var msgTx *wire.MsgTx
+	var fee *float64
+	var blockHash *chainhash.Hash
+	for _, desc := range e.cfg.MempoolTxs() {
+		if *desc.Tx.Hash() == *hash {
+			msgTx = desc.Tx.MsgTx()
+			f := btcutil.Amount(desc.Fee).ToBTC()
+			fee = &f
+			break
+		}
+	}
+	if msgTx == nil {
+		if e.cfg.FetchTx == nil {
+			return nil, errExplorerNotFound
+		}
+		msgTx, blockHash, err = e.cfg.FetchTx(hash)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := &explorerTx{
+		TxID:     msgTx.TxHash().String(),
+		Hash:     msgTx.WitnessHash().String(),
+		Version:  msgTx.Version,
+		LockTime: msgTx.LockTime,
+		Size:     msgTx.SerializeSize(),
+		VSize:    txVirtualSize(msgTx),
+		Fee:      fee,
+	}
+	if blockHash != nil {
+		result.BlockHash = blockHash.String()
+		if block, err := e.cfg.BlockByHash(blockHash); err == nil {
+			result.BlockHeight = block.Height()
+			result.Confirmations = e.cfg.BestSnapshot().Height -
+				block.Height() + 1
+		}
+	}
+	isCoinbase := blockchain.IsCoinBaseTx(msgTx)
+	for _, txIn := range msgTx.TxIn {
+		input := explorerInput{
+			Coinbase: isCoinbase,
+			Sequence: txIn.Sequence,
+		}
+		if !isCoinbase {
+			input.TxID = txIn.PreviousOutPoint.Hash.String()
+			input.Vout = txIn.PreviousOutPoint.Index
+		}
+		result.Inputs = append(result.Inputs, input)
+	}
+	for _, txOut := range msgTx.TxOut {
+		class, addrs, _, _ := txscript.ExtractPkScriptAddrs(
+			txOut.PkScript, e.cfg.ChainParams)
+		output := explorerOutput{
+			Value:  btcutil.Amount(txOut.Value).ToBTC(),
+			Type:   class.String(),
+			Script: hex.EncodeToString(txOut.PkScript),
+		}
+		for _, addr := range addrs {
+			output.Addresses = append(output.Addresses,
+				addr.EncodeAddress())
+		}
+		result.Outputs = append(result.Outputs, output)
+	}
+	return result, nil
+}
+
+// mempool returns the transactions of the mempool paying the highest fee
+// rates.
+func (e *explorer) mempool() *explorerMempool {
+	descs := e.cfg.MempoolTxs()
+	result := &explorerMempool{Count: len(descs)}
+	txs := make([]explorerTxSummary, 0, len(descs))
+	var fees btcutil.Amount
+	for _, desc := range descs {
+		msgTx := desc.Tx.MsgTx()
+		vsize := txVirtualSize(msgTx)
+		result.VSize += vsize
+		fees += btcutil.Amount(desc.Fee)
+		txs = append(txs, explorerTxSummary{
+			TxID:    desc.Tx.Hash().String(),
+			Size:    vsize,
+			Outputs: outputTotal(msgTx),
+			Fee:     btcutil.Amount(desc.Fee).ToBTC(),
+			FeeRate: float64(desc.Fee) / float64(vsize),
+		})
+	}
+	sort.Slice(txs, func(i, j int) bool {
+		if txs[i].FeeRate != txs[j].FeeRate {
+			return txs[i].FeeRate > txs[j].FeeRate
+		}
+		return txs[i].TxID < txs[j].TxID
+	})
+	if len(txs) > explorerMaxMempool {
+		txs = txs[:explorerMaxMempool]
+	}
+	result.Fees = fees.ToBTC()
+	result.Txs = txs
+	return result
+}
+
+// peerNetwork returns the network of the passed peer address.
+func peerNetwork(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	if strings.HasSuffix(host, ".onion") {
+		return "onion"
+	}
+	ip := net.ParseIP(host)
+	switch {
+	case ip == nil:
+		return "unknown"
+	case ip.To4() != nil:
+		return "ipv4"
+	}
+	return "ipv6"
+}
+
+// peers returns the number of connected peers of each network, along with the
+// peers when they are listed.
+func (e *explorer) peers() *explorerPeers {
+	result := &explorerPeers{Networks: make(map[string]int)}
+	for _, p := range e.cfg.Peers() {
+		network := peerNetwork(p.Addr())
+		result.Networks[network]++
+		if !e.cfg.ListPeers {
+			continue
+		}
+		result.Peers = append(result.Peers, explorerPeer{
+			ID:        p.ID(),
+			Addr:      p.Addr(),
+			Network:   network,
+			Inbound:   p.Inbound(),
+			UserAgent: p.UserAgent(),
+			Height:    p.LastBlock(),
+			PingMs:    float64(p.LastPingMicros()) / 1000,
+			ConnTime:  p.TimeConnected().Unix(),
+		})
+	}
+	sort.Slice(result.Peers, func(i, j int) bool {
+		return result.Peers[i].ID < result.Peers[j].ID
+	})
+	return result
+}
+
+// writeJSON writes v as the JSON response.
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		explLog.Debugf("Failed to write response: %v", err)
+	}
+}
+
+// render writes the named page of the explorer.
+func (e *explorer) render(w http.ResponseWriter, name string, data interface{}) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	err := explorerTemplates.ExecuteTemplate(w, name, data)
+	if err != nil {
+		explLog.Debugf("Failed to render %s: %v", name, err)
+	}
+}
+
+// notFound writes the not found page, or the error of a failed lookup.
+func notFound(w http.ResponseWriter, err error) {
+	if err == errExplorerNotFound {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
+func (e *explorer) handleIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	e.render(w, "index", e.index())
+}
+
+func (e *explorer) handleBlock(w http.ResponseWriter, r *http.Request) {
+	block, err := e.block(strings.TrimPrefix(r.URL.Path, "/block/"))
+	if err != nil {
+		notFound(w, err)
+		return
+	}
+	e.render(w, "block", block)
+}
+
+func (e *explorer) handleTx(w http.ResponseWriter, r *http.Request) {
+	tx, err := e.tx(strings.TrimPrefix(r.URL.Path, "/tx/"))
+	if err != nil {
+		notFound(w, err)
+		return
+	}
+	e.render(w, "tx", tx)
+}
+
+func (e *explorer) handleMempool(w http.ResponseWriter, r *http.Request) {
+	e.render(w, "mempool", e.mempool())
+}
+
+func (e *explorer) handlePeers(w http.ResponseWriter, r *http.Request) {
+	e.render(w, "peers", e.peers())
+}
+
+// handleAPI serves the JSON form of the pages: /api/status, /api/block/<id>,
+// /api/tx/<txid>, /api/mempool and /api/peers.
+func (e *explorer) handleAPI(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/")
+	switch {
+	case path == "status":
+		writeJSON(w, e.index())
+	case strings.HasPrefix(path, "block/"):
+		block, err := e.block(strings.TrimPrefix(path, "block/"))
+		if err != nil {
+			notFound(w, err)
+			return
+		}
+		writeJSON(w, block)
+	case strings.HasPrefix(path, "tx/"):
+		tx, err := e.tx(strings.TrimPrefix(path, "tx/"))
+		if err != nil {
+			notFound(w, err)
+			return
+		}
+		writeJSON(w, tx)
+	case path == "mempool":
+		writeJSON(w, e.mempool())
+	case path == "peers":
+		writeJSON(w, e.peers())
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// explorerTemplates are the HTML pages of the explorer.
+var explorerTemplates = template.Must(template.New("explorer").Funcs(
+	template.FuncMap{
+		"time": func(t time.Time) string {
+			return t.UTC().Format("2006-01-02 15:04:05")
+		},
+	}).Parse(`
+{{define "header"}}<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>pktd explorer</title>
+<style>
+body{font-family:sans-serif;margin:2em;color:#222}
+table{border-collapse:collapse}
+td,th{padding:.2em .8em;text-align:left;border-bottom:1px solid #ddd}
+.mono{font-family:monospace}
+</style></head><body>
+<p><a href="/">Blocks</a> | <a href="/mempool">Mempool</a> | <a href="/peers">Peers</a></p>
+{{end}}
+{{define "footer"}}</body></html>{{end}}
+
+{{define "index"}}{{template "header"}}
+<h1>{{.Network}}</h1>
+<p>Height {{.BestHeight}}, {{.TotalTxns}} transactions, {{.MempoolTxs}} in the mempool, {{.Peers}} peers</p>
+<table><tr><th>Height</th><th>Hash</th><th>Time (UTC)</th><th>Transactions</th><th>Size</th></tr>
+{{range .RecentBlocks}}<tr><td>{{.Height}}</td><td class="mono"><a href="/block/{{.Hash}}">{{.Hash}}</a></td><td>{{time .Time}}</td><td>{{.NumTx}}</td><td>{{.Size}}</td></tr>
+{{end}}</table>
+{{template "footer"}}{{end}}
+
+{{define "block"}}{{template "header"}}
+<h1>Block {{.Height}}</h1>
+<table>
+<tr><th>Hash</th><td class="mono">{{.Hash}}</td></tr>
+<tr><th>Confirmations</th><td>{{.Confirmations}}</td></tr>
+<tr><th>Time (UTC)</th><td>{{time .Time}}</td></tr>
+<tr><th>Previous</th><td class="mono"><a href="/block/{{.PrevHash}}">{{.PrevHash}}</a></td></tr>
+{{if .NextHash}}<tr><th>Next</th><td class="mono"><a href="/block/{{.NextHash}}">{{.NextHash}}</a></td></tr>{{end}}
+<tr><th>Merkle root</th><td class="mono">{{.MerkleRoot}}</td></tr>
+<tr><th>Version</th><td>{{.Version}}</td></tr>
+<tr><th>Bits</th><td>{{.Bits}}</td></tr>
+<tr><th>Nonce</th><td>{{.Nonce}}</td></tr>
+<tr><th>Size</th><td>{{.Size}}</td></tr>
+</table>
+<h2>{{.NumTx}} transactions</h2>
+<table><tr><th>Transaction</th><th>Virtual size</th><th>Output total</th></tr>
+{{range .Txs}}<tr><td class="mono"><a href="/tx/{{.TxID}}">{{.TxID}}</a></td><td>{{.Size}}</td><td>{{.Outputs}}</td></tr>
+{{end}}</table>
+{{template "footer"}}{{end}}
+
+{{define "tx"}}{{template "header"}}
+<h1>Transaction</h1>
+<table>
+<tr><th>Transaction ID</th><td class="mono">{{.TxID}}</td></tr>
+<tr><th>Witness hash</th><td class="mono">{{.Hash}}</td></tr>
+{{if .BlockHash}}<tr><th>Block</th><td class="mono"><a href="/block/{{.BlockHash}}">{{.BlockHeight}}</a></td></tr>
+<tr><th>Confirmations</th><td>{{.Confirmations}}</td></tr>{{else}}<tr><th>Block</th><td>Unconfirmed</td></tr>{{end}}
+{{if .Fee}}<tr><th>Fee</th><td>{{.Fee}}</td></tr>{{end}}
+<tr><th>Size</th><td>{{.Size}} ({{.VSize}} virtual)</td></tr>
+<tr><th>Version</th><td>{{.Version}}</td></tr>
+<tr><th>Lock time</th><td>{{.LockTime}}</td></tr>
+</table>
+<h2>Inputs</h2>
+<table>{{range .Inputs}}<tr>{{if .Coinbase}}<td>Coinbase</td>{{else}}<td class="mono"><a href="/tx/{{.TxID}}">{{.TxID}}</a>:{{.Vout}}</td>{{end}}</tr>
+{{end}}</table>
+<h2>Outputs</h2>
+<table><tr><th>Value</th><th>Type</th><th>Addresses</th></tr>
+{{range .Outputs}}<tr><td>{{.Value}}</td><td>{{.Type}}</td><td class="mono">{{range .Addresses}}{{.}} {{end}}</td></tr>
+{{end}}</table>
+{{template "footer"}}{{end}}
+
+{{define "mempool"}}{{template "header"}}
+<h1>Mempool</h1>
+<p>{{.Count}} transactions, {{.VSize}} virtual bytes, {{.Fees}} in fees</p>
+<table><tr><th>Transaction</th><th>Virtual size</th><th>Fee</th><th>Fee rate</th></tr>
+{{range .Txs}}<tr><td class="mono"><a href="/tx/{{.TxID}}">{{.TxID}}</a></td><td>{{.Size}}</td><td>{{.Fee}}</td><td>{{printf "%.2f" .FeeRate}}</td></tr>
+{{end}}</table>
+{{template "footer"}}{{end}}
+
+{{define "peers"}}{{template "header"}}
+<h1>Peers</h1>
+<p>{{range $network, $count := .Networks}}{{$network}}: {{$count}} {{end}}</p>
+{{if .Peers}}<table><tr><th>ID</th><th>Address</th><th>Network</th><th>Direction</th><th>User agent</th><th>Height</th><th>Ping (ms)</th></tr>
+{{range .Peers}}<tr><td>{{.ID}}</td><td class="mono">{{.Addr}}</td><td>{{.Network}}</td><td>{{if .Inbound}}inbound{{else}}outbound{{end}}</td><td>{{.UserAgent}}</td><td>{{.Height}}</td><td>{{printf "%.1f" .PingMs}}</td></tr>
+{{end}}</table>
+{{end}}{{template "footer"}}{{end}}
+`))
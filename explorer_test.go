@@ -0,0 +1,194 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/chaincfg/globalcfg"
+	"github.com/pkt-cash/pktd/mempool"
+	"github.com/pkt-cash/pktd/mining"
+	"github.com/pkt-cash/pktd/peer"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// TestExplorer ensures the explorer serves blocks, transactions of the chain
+// and the mempool, and the mempool summary as HTML and JSON.
+func TestExplorer(t *testing.T) {
+	// The log rotator is not initialized in tests.
+	setLogLevels("off")
+	defer setLogLevels(defaultLogLevel)
+
+	params := &chaincfg.RegressionNetParams
+	if !globalcfg.SelectConfig(params.GlobalConf) {
+		t.Fatal("globalcfg.SelectConfig() called twice")
+	}
+	defer globalcfg.RemoveConfig()
+
+	genesis := btcutil.NewBlock(params.GenesisBlock)
+	genesis.SetHeight(0)
+	coinbase := params.GenesisBlock.Transactions[0]
+	coinbaseHash := coinbase.TxHash()
+
+	spend := wire.NewMsgTx(wire.TxVersion)
+	spend.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&coinbaseHash, 0), nil, nil))
+	spend.AddTxOut(wire.NewTxOut(1e9, coinbase.TxOut[0].PkScript))
+	pool := []*mempool.TxDesc{{TxDesc: mining.TxDesc{
+		Tx:  btcutil.NewTx(spend),
+		Fee: 2000,
+	}}}
+
+	e := newExplorer(&explorerConfig{
+		ChainParams: params,
+		BestSnapshot: func() *blockchain.BestState {
+			return &blockchain.BestState{Hash: *genesis.Hash()}
+		},
+		BlockByHeight: func(height int32) (*btcutil.Block, error) {
+			if height != 0 {
+				return nil, errExplorerNotFound
+			}
+			return genesis, nil
+		},
+		BlockByHash: func(hash *chainhash.Hash) (*btcutil.Block, error) {
+			if *hash != *genesis.Hash() {
+				return nil, errExplorerNotFound
+			}
+			return genesis, nil
+		},
+		FetchTx: func(hash *chainhash.Hash) (*wire.MsgTx, *chainhash.Hash, error) {
+			if *hash != coinbaseHash {
+				return nil, nil, errExplorerNotFound
+			}
+			return coinbase, genesis.Hash(), nil
+		},
+		MempoolTxs: func() []*mempool.TxDesc { return pool },
+		Peers:      func() []*peer.Peer { return nil },
+	})
+	server := httptest.NewServer(e.server.Handler)
+	defer server.Close()
+
+	get := func(path string, wantStatus int) string {
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		if resp.StatusCode != wantStatus {
+			t.Fatalf("GET %s: got status %d, want %d", path,
+				resp.StatusCode, wantStatus)
+		}
+		return string(body)
+	}
+
+	// The HTML pages link the blocks and transactions they show.
+	pages := []struct {
+		path string
+		want string
+	}{
+		{"/", "/block/" + genesis.Hash().String()},
+		{"/block/0", "/tx/" + coinbaseHash.String()},
+		{"/block/" + genesis.Hash().String(), coinbaseHash.String()},
+		{"/tx/" + coinbaseHash.String(), "Coinbase"},
+		{"/tx/" + spend.TxHash().String(), "Unconfirmed"},
+		{"/mempool", "/tx/" + spend.TxHash().String()},
+		{"/peers", "Peers"},
+	}
+	for _, page := range pages {
+		if body := get(page.path, http.StatusOK); !strings.Contains(body,
+			page.want) {
+
+			t.Errorf("GET %s: body does not contain %q", page.path,
+				page.want)
+		}
+	}
+	get("/block/1", http.StatusNotFound)
+	get("/tx/"+strings.Repeat("00", 32), http.StatusNotFound)
+	get("/unknown", http.StatusNotFound)
+
+	var tx explorerTx
+	body := get("/api/tx/"+coinbaseHash.String(), http.StatusOK)
+	if err := json.Unmarshal([]byte(body), &tx); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if tx.BlockHash != genesis.Hash().String() || tx.Confirmations != 1 ||
+		len(tx.Inputs) != 1 || !tx.Inputs[0].Coinbase ||
+		len(tx.Outputs) != len(coinbase.TxOut) {
+
+		t.Errorf("unexpected coinbase transaction %+v", tx)
+	}
+
+	var mp explorerMempool
+	body = get("/api/mempool", http.StatusOK)
+	if err := json.Unmarshal([]byte(body), &mp); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if mp.Count != 1 || len(mp.Txs) != 1 ||
+		mp.Txs[0].TxID != spend.TxHash().String() ||
+		mp.Fees != btcutil.Amount(2000).ToBTC() {
+
+		t.Errorf("unexpected mempool %+v", mp)
+	}
+}
+
+// TestExplorerPeers ensures the addresses of the peers are only published when
+// they are listed, and that their number per network always is.
+func TestExplorerPeers(t *testing.T) {
+	p, err := peer.NewOutboundPeer(&peer.Config{}, "203.0.113.5:64764")
+	if err != nil {
+		t.Fatalf("NewOutboundPeer: %v", err)
+	}
+	for _, listPeers := range []bool{false, true} {
+		e := newExplorer(&explorerConfig{
+			Peers:     func() []*peer.Peer { return []*peer.Peer{p} },
+			ListPeers: listPeers,
+		})
+		for _, path := range []string{"/peers", "/api/peers"} {
+			w := httptest.NewRecorder()
+			e.server.Handler.ServeHTTP(w,
+				httptest.NewRequest("GET", path, nil))
+			body := w.Body.String()
+			if w.Code != http.StatusOK || !strings.Contains(body, "ipv4") {
+				t.Errorf("GET %s: unexpected response %d %s", path,
+					w.Code, body)
+			}
+			if listed := strings.Contains(body, p.Addr()); listed != listPeers {
+				t.Errorf("GET %s with listed peers %v: address "+
+					"published %v", path, listPeers, listed)
+			}
+		}
+	}
+}
+
+// TestPeerNetwork ensures peer addresses are sorted into their networks.
+func TestPeerNetwork(t *testing.T) {
+	tests := []struct {
+		addr string
+		want string
+	}{
+		{"203.0.113.5:64764", "ipv4"},
+		{"[2001:db8::1]:64764", "ipv6"},
+		{"expyuzz4wqqyqhjn.onion:64764", "onion"},
+		{"bogus", "unknown"},
+	}
+	for _, test := range tests {
+		if got := peerNetwork(test.addr); got != test.want {
+			t.Errorf("peerNetwork(%q): got %s, want %s", test.addr,
+				got, test.want)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package extsigner runs external signer commands on behalf of a wallet.
+
+An external signer is a program, typically a bridge to a hardware wallet or an
+HSM, which holds private keys the wallet does not have.  The protocol is the
+one used by the bitcoin reference client's -signer option so existing signer
+programs such as HWI can be used unchanged:
+
+	<command> enumerate
+		Prints a JSON array of {"fingerprint": "..."} objects, one for
+		each connected device.
+
+	<command> --fingerprint=<fingerprint> --chain <chain> signtx
+		Reads a base64 PSBT on stdin and prints {"psbt": "..."} with the
+		signatures added.
+
+A signer may print {"error": "..."} instead to report a failure.
+
+The command is not run through a shell.  It is started with an empty
+environment apart from PATH and HOME, in the temporary directory, with its
+output size limited and killed when it runs longer than the configured timeout.
+*/
+package extsigner
@@ -0,0 +1,188 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package extsigner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/pkt-cash/pktd/btcjson"
+)
+
+const (
+	// DefaultTimeout is the time a signer command is allowed to run when
+	// no timeout is configured.  Signing usually needs confirmation on the
+	// device so this is generous.
+	DefaultTimeout = 2 * time.Minute
+
+	// maxOutputSize is the maximum number of bytes read from the output of
+	// a signer command.
+	maxOutputSize = 4 * 1024 * 1024
+)
+
+var (
+	// ErrNoCommand is returned by New when the signer command is empty.
+	ErrNoCommand = errors.New("no external signer command configured")
+
+	// ErrOutputTooLarge is returned when a signer command writes more than
+	// maxOutputSize bytes.
+	ErrOutputTooLarge = errors.New("external signer output is too large")
+)
+
+// Signer runs an external signer command.
+type Signer struct {
+	path    string
+	args    []string
+	chain   string
+	timeout time.Duration
+}
+
+// New returns a Signer which runs the passed command, split into the program
+// and its arguments on white space.  The chain is the name passed to the
+// signer with --chain, and a timeout of zero means DefaultTimeout.
+func New(command, chain string, timeout time.Duration) (*Signer, error) {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return nil, ErrNoCommand
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Signer{
+		path:    fields[0],
+		args:    fields[1:],
+		chain:   chain,
+		timeout: timeout,
+	}, nil
+}
+
+// limitedBuffer is a bytes.Buffer which refuses to grow past maxOutputSize.
+type limitedBuffer struct {
+	bytes.Buffer
+}
+
+// Write appends p to the buffer, failing once the buffer would exceed
+// maxOutputSize.
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.Len()+len(p) > maxOutputSize {
+		return 0, ErrOutputTooLarge
+	}
+	return b.Buffer.Write(p)
+}
+
+// sandboxEnv returns the environment signer commands are run with.
+func sandboxEnv() []string {
+	var env []string
+	for _, name := range []string{"PATH", "HOME"} {
+		if value, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+value)
+		}
+	}
+	return env
+}
+
+// run runs the signer command with the passed arguments and stdin and
+// returns what it printed, which is checked to be JSON without an error
+// member.
+func (s *Signer) run(stdin string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	cmdArgs := make([]string, 0, len(s.args)+len(args))
+	cmdArgs = append(cmdArgs, s.args...)
+	cmdArgs = append(cmdArgs, args...)
+
+	var stdout, stderr limitedBuffer
+	cmd := exec.CommandContext(ctx, s.path, cmdArgs...)
+	cmd.Env = sandboxEnv()
+	cmd.Dir = os.TempDir()
+	cmd.Stdin = strings.NewReader(stdin)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("unable to start external signer: %v", err)
+	}
+
+	// The signer is killed when the context expires, but waiting for it
+	// may still block for as long as any process it started keeps its
+	// output open, so stop waiting at that point too.
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("external signer did not finish within %v",
+			s.timeout)
+	}
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			return nil, fmt.Errorf("external signer failed: %v", err)
+		}
+		return nil, fmt.Errorf("external signer failed: %v: %s", err, msg)
+	}
+
+	out := bytes.TrimSpace(stdout.Bytes())
+	var reply struct {
+		Error string `json:"error"`
+	}
+	if len(out) > 0 && out[0] == '{' {
+		if err := json.Unmarshal(out, &reply); err != nil {
+			return nil, fmt.Errorf("malformed external signer "+
+				"output: %v", err)
+		}
+		if reply.Error != "" {
+			return nil, fmt.Errorf("external signer: %s", reply.Error)
+		}
+	}
+	return out, nil
+}
+
+// Enumerate returns the devices available through the signer.
+func (s *Signer) Enumerate() ([]btcjson.ExternalSigner, error) {
+	out, err := s.run("", "enumerate")
+	if err != nil {
+		return nil, err
+	}
+	var signers []btcjson.ExternalSigner
+	if err := json.Unmarshal(out, &signers); err != nil {
+		return nil, fmt.Errorf("malformed external signer output: %v",
+			err)
+	}
+	return signers, nil
+}
+
+// SignPSBT asks the device with the passed fingerprint to sign the base64
+// encoded PSBT and returns the PSBT with the signatures added.
+func (s *Signer) SignPSBT(fingerprint, psbt string) (string, error) {
+	out, err := s.run(psbt, "--fingerprint="+fingerprint, "--chain",
+		s.chain, "signtx")
+	if err != nil {
+		return "", err
+	}
+	var reply struct {
+		PSBT string `json:"psbt"`
+	}
+	if err := json.Unmarshal(out, &reply); err != nil {
+		return "", fmt.Errorf("malformed external signer output: %v",
+			err)
+	}
+	if reply.PSBT == "" {
+		return "", errors.New("external signer did not return a PSBT")
+	}
+	return reply.PSBT, nil
+}
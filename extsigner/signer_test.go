@@ -0,0 +1,106 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package extsigner
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testSignerScript is a signer which reports a single device and signs by
+// appending to the PSBT it reads, failing for any other fingerprint.  It also
+// reports whether it could see a variable which must not be passed through.
+const testSignerScript = `#!/bin/sh
+case "$1" in
+enumerate)
+	echo '[{"fingerprint":"00000001","name":"'"${EXTSIGNER_SECRET:-clean}"'"}]'
+	;;
+--fingerprint=00000001)
+	read psbt
+	echo '{"psbt":"'"$psbt"'signed"}'
+	;;
+--fingerprint=*)
+	echo '{"error":"device not found"}'
+	;;
+sleep)
+	sleep 5
+	;;
+esac
+`
+
+// writeSigner writes the test signer script to a temporary directory and
+// returns its path along with a function to remove it.
+func writeSigner(t *testing.T) (string, func()) {
+	if runtime.GOOS == "windows" {
+		t.Skip("external signer test requires a unix shell")
+	}
+	dir, err := ioutil.TempDir("", "extsigner")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	path := filepath.Join(dir, "signer")
+	err = ioutil.WriteFile(path, []byte(testSignerScript), 0700)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("unable to write signer: %v", err)
+	}
+	return path, func() { os.RemoveAll(dir) }
+}
+
+// TestSigner ensures the signer protocol is followed and that signer errors,
+// timeouts and the sandboxed environment are handled.
+func TestSigner(t *testing.T) {
+	path, cleanup := writeSigner(t)
+	defer cleanup()
+
+	os.Setenv("EXTSIGNER_SECRET", "leaked")
+	defer os.Unsetenv("EXTSIGNER_SECRET")
+
+	s, err := New(path, "pkt", 0)
+	if err != nil {
+		t.Fatalf("New: unexpected error: %v", err)
+	}
+	signers, err := s.Enumerate()
+	if err != nil {
+		t.Fatalf("Enumerate: unexpected error: %v", err)
+	}
+	if len(signers) != 1 || signers[0].Fingerprint != "00000001" {
+		t.Fatalf("Enumerate: unexpected signers %+v", signers)
+	}
+	if signers[0].Name != "clean" {
+		t.Fatalf("signer was run with the caller's environment")
+	}
+
+	psbt, err := s.SignPSBT("00000001", "cHNidP8B")
+	if err != nil {
+		t.Fatalf("SignPSBT: unexpected error: %v", err)
+	}
+	if psbt != "cHNidP8Bsigned" {
+		t.Fatalf("SignPSBT: unexpected psbt %q", psbt)
+	}
+
+	_, err = s.SignPSBT("00000002", "cHNidP8B")
+	if err == nil || !strings.Contains(err.Error(), "device not found") {
+		t.Fatalf("SignPSBT: unexpected error for unknown device: %v",
+			err)
+	}
+
+	s, err = New(path+" sleep", "pkt", 100*time.Millisecond)
+	if err != nil {
+		t.Fatalf("New: unexpected error: %v", err)
+	}
+	if _, err := s.Enumerate(); err == nil {
+		t.Fatalf("Enumerate: expected timeout error")
+	}
+
+	if _, err := New(" ", "pkt", 0); err != ErrNoCommand {
+		t.Fatalf("New: unexpected error for empty command: %v", err)
+	}
+}
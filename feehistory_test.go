@@ -0,0 +1,111 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/blockchain/indexers"
+	"github.com/pkt-cash/pktd/btcjson"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// TestFeeStatsIndex ensures the fee statistics index follows the blocks of
+// the main chain across reorganizations and that getfeehistory returns them by
+// height and by timestamp.
+func TestFeeStatsIndex(t *testing.T) {
+	var feeIndex *indexers.FeeStatsIndex
+	tc, teardown := newTestChain(t, "feestats",
+		func(config *blockchain.Config) {
+			feeIndex = indexers.NewFeeStatsIndex(config.DB)
+			config.IndexManager = indexers.NewManager(config.DB,
+				[]indexers.Indexer{feeIndex})
+		})
+	defer teardown()
+	params, chain, g := tc.params, tc.chain, tc.gen
+	s := &rpcServer{cfg: rpcserverConfig{Chain: chain}}
+
+	// The command needs the index.
+	cmd := btcjson.NewGetFeeHistoryCmd(0, nil, nil)
+	_, err := handleGetFeeHistory(s, cmd, nil)
+	if rpcErr, ok := err.(*btcjson.RPCError); !ok ||
+		rpcErr.Code != btcjson.ErrRPCNoFeeStatsIndex {
+		t.Fatalf("getfeehistory without index: unexpected error %v", err)
+	}
+	s.cfg.FeeStatsIndex = feeIndex
+
+	getFeeHistory := func(start int64, end *int64,
+		byTime bool) *btcjson.GetFeeHistoryResult {
+
+		cmd := btcjson.NewGetFeeHistoryCmd(start, end, &byTime)
+		result, err := handleGetFeeHistory(s, cmd, nil)
+		if err != nil {
+			t.Fatalf("getfeehistory: %v", err)
+		}
+		return result.(*btcjson.GetFeeHistoryResult)
+	}
+
+	header := func(height int32) *wire.BlockHeader {
+		headers, err := chain.HeadersByHeight(height, height+1)
+		if err != nil || len(headers) != 1 {
+			t.Fatalf("HeadersByHeight: %v", err)
+		}
+		return &headers[0]
+	}
+
+	// check ensures the blocks returned are the main chain blocks at the
+	// passed heights, which only hold a coinbase.
+	check := func(result *btcjson.GetFeeHistoryResult, heights ...int32) {
+		if result.Truncated || len(result.Blocks) != len(heights) {
+			t.Fatalf("got %d blocks, want %d", len(result.Blocks),
+				len(heights))
+		}
+		for i, block := range result.Blocks {
+			if block.Height != heights[i] ||
+				block.Time != header(heights[i]).Timestamp.Unix() ||
+				block.TxCount != 0 || block.TotalFee != 0 ||
+				len(block.FeeRatePercentiles) !=
+					len(result.Percentiles) {
+
+				t.Fatalf("unexpected block %d %+v", i, block)
+			}
+		}
+	}
+
+	if _, err := g.generate(params.GenesisHash, 5, nil); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	check(getFeeHistory(0, nil, false), 0, 1, 2, 3, 4, 5)
+	check(getFeeHistory(2, btcjson.Int64(3), false), 2, 3)
+	check(getFeeHistory(6, nil, false))
+
+	// The generated blocks may share a timestamp, but the timestamps do
+	// not decrease, so the blocks in a range of time are ordered by height.
+	start, end := header(2).Timestamp.Unix(), header(4).Timestamp.Unix()
+	var inRange []int32
+	for height := int32(0); height <= 5; height++ {
+		timestamp := header(height).Timestamp.Unix()
+		if timestamp >= start && timestamp <= end {
+			inRange = append(inRange, height)
+		}
+	}
+	check(getFeeHistory(start, btcjson.Int64(end), true), inRange...)
+	check(getFeeHistory(0, btcjson.Int64(header(0).Timestamp.Unix()), true), 0)
+
+	// Invalid ranges are rejected.
+	_, err = handleGetFeeHistory(s, btcjson.NewGetFeeHistoryCmd(3,
+		btcjson.Int64(2), nil), nil)
+	if err == nil {
+		t.Fatalf("getfeehistory of an empty range succeeded")
+	}
+
+	// Disconnected blocks are removed by height and by time.
+	if _, err := g.simulateReorg(2, 3, nil); err != nil {
+		t.Fatalf("simulateReorg: %v", err)
+	}
+	check(getFeeHistory(0, nil, false), 0, 1, 2, 3, 4, 5, 6)
+	check(getFeeHistory(0, nil, true), 0, 1, 2, 3, 4, 5, 6)
+}
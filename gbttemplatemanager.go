@@ -0,0 +1,100 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"time"
+)
+
+const (
+	// gbtRefreshInterval is how often the block template manager checks
+	// whether the block template of the getblocktemplate work state is
+	// stale.
+	gbtRefreshInterval = time.Second
+
+	// gbtIdleTimeout is how long the block template manager keeps the block
+	// template up to date after the last block template request, when no
+	// long poll is waiting.
+	gbtIdleTimeout = 2 * time.Minute
+)
+
+// gbtTemplateManager keeps the block template of the getblocktemplate work
+// state up to date in the background while miners ask for them.  A new block
+// template is generated as soon as the best block changes, or the memory pool
+// has changed and gbtRegenerateSeconds have passed, rather than when the next
+// request comes in.  It stops once no block template was requested for
+// gbtIdleTimeout and no long poll is waiting, until the next request.  The long poll clients are notified of the new template
+// by the work state, so they get new work without generating it themselves.
+type gbtTemplateManager struct {
+	s              *rpcServer
+	blockConnected chan struct{}
+}
+
+// newGbtTemplateManager returns a new block template manager for the work
+// state of the passed RPC server.  Use Start to begin processing.
+func newGbtTemplateManager(s *rpcServer) *gbtTemplateManager {
+	return &gbtTemplateManager{
+		s:              s,
+		blockConnected: make(chan struct{}, 1),
+	}
+}
+
+// NotifyBlockConnected lets the manager know the best block has changed, so the
+// block template is stale.  It never blocks.
+func (m *gbtTemplateManager) NotifyBlockConnected() {
+	select {
+	case m.blockConnected <- struct{}{}:
+	default:
+	}
+}
+
+// Start begins refreshing the block template until the RPC server is stopped.
+func (m *gbtTemplateManager) Start() {
+	m.s.wg.Add(1)
+	go m.handler()
+}
+
+// handler refreshes the block template whenever a block is connected and every
+// gbtRefreshInterval.  It must be run as a goroutine.
+func (m *gbtTemplateManager) handler() {
+	ticker := time.NewTicker(gbtRefreshInterval)
+	defer ticker.Stop()
+out:
+	for {
+		select {
+		case <-m.blockConnected:
+		case <-ticker.C:
+		case <-m.s.quit:
+			break out
+		}
+		m.refresh()
+	}
+	m.s.wg.Done()
+}
+
+// refresh generates a new block template for the work state when it is stale,
+// keeping the kind of coinbase of the current one.
+func (m *gbtTemplateManager) refresh() {
+	s := m.s
+	state := s.gbtWorkState
+	state.Lock()
+	defer state.Unlock()
+
+	// There is nothing to keep up to date until a block template was
+	// requested, nor once the miners are gone, and no point in generating
+	// work before the chain is synced.
+	if state.template == nil || state.isIdle(time.Now()) ||
+		!s.cfg.SyncMgr.IsCurrent() {
+
+		return
+	}
+	useCoinbaseValue := !state.withPayAddresses
+	if !state.isStale(s, useCoinbaseValue) {
+		return
+	}
+	if err := state.updateBlockTemplate(s, useCoinbaseValue); err != nil {
+		rpcsLog.Debugf("Unable to refresh the block template: %v", err)
+	}
+}
@@ -0,0 +1,37 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkt-cash/pktd/blockchain"
+)
+
+// TestGbtWorkStateIdle ensures the block template is only considered unused
+// once no block template was requested for gbtIdleTimeout and no long poll is
+// waiting.
+func TestGbtWorkStateIdle(t *testing.T) {
+	state := newGbtWorkState(blockchain.NewMedianTime())
+	now := time.Now()
+	if !state.isIdle(now) {
+		t.Fatal("work state without requests is not idle")
+	}
+
+	state.noteRequest()
+	if state.isIdle(now) {
+		t.Fatal("work state idle right after a request")
+	}
+	later := time.Now().Add(gbtIdleTimeout + time.Second)
+	if !state.isIdle(later) {
+		t.Fatal("work state not idle after the timeout")
+	}
+
+	state.longPolls++
+	if state.isIdle(later) {
+		t.Fatal("work state idle with a waiting long poll")
+	}
+}
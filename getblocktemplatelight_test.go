@@ -0,0 +1,155 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/hex"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/chaincfg/globalcfg"
+	"github.com/pkt-cash/pktd/mining"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// TestBlockTemplateLightResult ensures getblocktemplatelight replies hold the
+// differences from the remembered block templates.
+func TestBlockTemplateLightResult(t *testing.T) {
+	params := &chaincfg.RegressionNetParams
+	if !globalcfg.SelectConfig(params.GlobalConf) {
+		t.Fatal("globalcfg.SelectConfig() called twice")
+	}
+	defer globalcfg.RemoveConfig()
+
+	// newTx returns a distinct transaction for each index.
+	newTx := func(index uint32) *wire.MsgTx {
+		msgTx := wire.NewMsgTx(wire.TxVersion)
+		msgTx.AddTxIn(&wire.TxIn{
+			PreviousOutPoint: wire.OutPoint{Index: index},
+		})
+		msgTx.AddTxOut(wire.NewTxOut(int64(index), nil))
+		return msgTx
+	}
+	txs := []*wire.MsgTx{newTx(0), newTx(1), newTx(2), newTx(3)}
+	txHash := func(i int) string {
+		return txs[i].TxHash().String()
+	}
+
+	// generate makes the work state generate a block template holding the
+	// passed transactions after the coinbase.
+	state := newGbtWorkState(blockchain.NewMedianTime())
+	prevHash := params.GenesisHash
+	lastGenerated := time.Now().Add(-time.Hour)
+	generate := func(indexes ...int) string {
+		msgBlock := &wire.MsgBlock{
+			Header: wire.BlockHeader{
+				PrevBlock: *prevHash,
+				Timestamp: time.Now(),
+			},
+		}
+		coinbase := newTx(100)
+		coinbase.TxIn[0].PreviousOutPoint.Index = wire.MaxPrevOutIndex
+		msgBlock.AddTransaction(coinbase)
+		for _, i := range indexes {
+			msgBlock.AddTransaction(txs[i])
+		}
+		numTxs := len(msgBlock.Transactions)
+		state.template = &mining.BlockTemplate{
+			Block:      msgBlock,
+			Fees:       make([]int64, numTxs),
+			SigOpCosts: make([]int64, numTxs),
+			Height:     1,
+		}
+		lastGenerated = lastGenerated.Add(time.Second)
+		state.prevHash = prevHash
+		state.lastGenerated = lastGenerated
+		state.recordTemplate()
+		return encodeTemplateID(prevHash, lastGenerated)
+	}
+	check := func(baseID string, wantBaseID string, wantTxs []int,
+		wantRemoved []int) {
+
+		t.Helper()
+		result, err := state.blockTemplateLightResult(baseID, nil)
+		if err != nil {
+			t.Fatalf("blockTemplateLightResult: %v", err)
+		}
+		if result.BaseLongPollID != wantBaseID {
+			t.Fatalf("got base %q, want %q", result.BaseLongPollID,
+				wantBaseID)
+		}
+		gotTxs := make([]string, 0, len(result.Transactions))
+		for _, tx := range result.Transactions {
+			gotTxs = append(gotTxs, tx.Hash)
+		}
+		wantTxHashes := make([]string, 0, len(wantTxs))
+		for _, i := range wantTxs {
+			wantTxHashes = append(wantTxHashes, txHash(i))
+		}
+		wantRemovedHashes := make([]string, 0, len(wantRemoved))
+		for _, i := range wantRemoved {
+			wantRemovedHashes = append(wantRemovedHashes, txHash(i))
+		}
+		if !reflect.DeepEqual(gotTxs, wantTxHashes) ||
+			!reflect.DeepEqual(result.Removed, wantRemovedHashes) {
+
+			t.Fatalf("got transactions %v and removed %v, want %v "+
+				"and %v", gotTxs, result.Removed, wantTxHashes,
+				wantRemovedHashes)
+		}
+
+		// The hashes of the transactions and the merkle branch of the
+		// coinbase lead to the merkle root of the block template.
+		msgBlock := state.template.Block
+		if len(result.TxHashes) != len(msgBlock.Transactions)-1 {
+			t.Fatalf("got %d transaction hashes, want %d",
+				len(result.TxHashes), len(msgBlock.Transactions)-1)
+		}
+		for i, hash := range result.TxHashes {
+			if hash != msgBlock.Transactions[i+1].TxHash().String() {
+				t.Fatalf("got transaction hash %s at %d", hash, i)
+			}
+		}
+		root := msgBlock.Transactions[0].TxHash()
+		for _, hashStr := range result.Merkle {
+			b, err := hex.DecodeString(hashStr)
+			if err != nil {
+				t.Fatalf("DecodeString: %v", err)
+			}
+			var branch chainhash.Hash
+			copy(branch[:], b)
+			root = *blockchain.HashMerkleBranches(&root, &branch)
+		}
+		merkles := blockchain.BuildMerkleTreeStore(
+			btcutil.NewBlock(msgBlock).Transactions(), false)
+		if root != *merkles[len(merkles)-1] {
+			t.Fatalf("merkle branch leads to %v, want %v", root,
+				merkles[len(merkles)-1])
+		}
+	}
+
+	first := generate(0, 1, 2)
+	check("", "", []int{0, 1, 2}, nil)
+	second := generate(0, 2, 3)
+	check("", "", []int{0, 2, 3}, nil)
+	check(first, first, []int{3}, []int{1})
+	check(second, second, nil, nil)
+	check("unknown", "", []int{0, 2, 3}, nil)
+
+	// Only the most recent block templates are remembered.
+	for i := 0; i < gbtTemplateHistorySize; i++ {
+		generate(i % len(txs))
+	}
+	if len(state.recentTemplates) != gbtTemplateHistorySize {
+		t.Fatalf("%d block templates remembered, want %d",
+			len(state.recentTemplates), gbtTemplateHistorySize)
+	}
+	check(first, "", []int{(gbtTemplateHistorySize - 1) % len(txs)}, nil)
+}
@@ -0,0 +1,34 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package hooks lets operators plug their own code into the node, to follow the
+blocks connected to and disconnected from the main chain and to decide which
+transactions are accepted to the mempool, and so relayed, without forking it.
+
+A hook implements the Hook interface.  Hooks are either compiled into the node,
+by a package calling Register from its init function, or run as an external
+process.  The Manager invokes the hooks selected in the configuration in
+order.  Blocks are only notified to the hooks: they are valid by consensus,
+which no hook may override.  A transaction is rejected as soon as a hook
+returns an error for it, which is a matter of policy only.
+
+An external process is given the events as JSON objects, one per line, on its
+standard input:
+
+	{"method":"blockconnected","params":{"hash":"...","height":1000,"previousblockhash":"...","time":1588888888,"tx":["..."]}}
+	{"method":"blockdisconnected","params":{...}}
+	{"id":1,"method":"accepttx","params":{"txid":"...","hex":"...","fee":1000,"size":250}}
+
+Requests with an id must be answered on its standard output by a JSON object
+on a line with the same id, and a reject reason to refuse the transaction:
+
+	{"id":1}
+	{"id":1,"reject":"fee too low"}
+
+A transaction is accepted when the process does not answer in time, or is not
+running, so a failing hook never stops the node.  What the process writes on
+its standard error is logged, and it is restarted when it exits.
+*/
+package hooks
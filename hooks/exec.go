@@ -0,0 +1,356 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package hooks
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/pkt-cash/btcutil"
+)
+
+const (
+	// execRestartDelay is the minimum time between two starts of the
+	// external process of a hook.
+	execRestartDelay = 10 * time.Second
+
+	// execQueueSize is the number of messages queued for an external
+	// process which doesn't read them fast enough.  Further messages are
+	// dropped.
+	execQueueSize = 1000
+
+	// execStopTimeout is the time an external process is given to exit
+	// once its standard input is closed before it is killed.
+	execStopTimeout = 5 * time.Second
+
+	// maxExecLineLen is the maximum length of a line written by an
+	// external process.
+	maxExecLineLen = 1 << 20
+)
+
+// execRequest is a message sent to an external process.  Requests with an ID
+// must be answered, the others are notifications.
+type execRequest struct {
+	ID     uint64      `json:"id,omitempty"`
+	Method string      `json:"method"`
+	Params interface{} `json:"params"`
+}
+
+// execResponse is the answer of an external process to a request.
+type execResponse struct {
+	ID     uint64 `json:"id"`
+	Reject string `json:"reject,omitempty"`
+}
+
+// execBlock describes a block connected or disconnected to an external process.
+type execBlock struct {
+	Hash          string   `json:"hash"`
+	Height        int32    `json:"height"`
+	PrevBlockHash string   `json:"previousblockhash"`
+	Time          int64    `json:"time"`
+	Tx            []string `json:"tx"`
+}
+
+// execTx describes a transaction about to be accepted to an external process.
+type execTx struct {
+	TxID string `json:"txid"`
+	Hex  string `json:"hex"`
+	Fee  int64  `json:"fee"`
+	Size int    `json:"size"`
+}
+
+// execProcess is a running external process.
+type execProcess struct {
+	cmd       *exec.Cmd
+	stdin     io.WriteCloser
+	queue     chan []byte
+	responses chan *execResponse
+	done      chan struct{} // Closed once the process exited.
+	quit      chan struct{} // Closed to close the standard input.
+}
+
+// execHook is a hook run as an external process.
+type execHook struct {
+	path    string
+	args    []string
+	timeout time.Duration
+
+	mtx       sync.Mutex
+	process   *execProcess
+	startedAt time.Time
+	nextID    uint64
+	closed    bool
+}
+
+// newExecHook returns a hook running the passed command, which is started
+// right away.
+func newExecHook(path string, args []string, timeout time.Duration) (*execHook, error) {
+	h := &execHook{
+		path:    path,
+		args:    args,
+		timeout: timeout,
+	}
+	if err := h.start(); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// start starts the external process.  It must be called with the mutex held.
+func (h *execHook) start() error {
+	cmd := exec.Command(h.path, h.args...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	cmd.Stderr = &stderrLogger{name: h.path}
+	h.startedAt = time.Now()
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	p := &execProcess{
+		cmd:       cmd,
+		stdin:     stdin,
+		queue:     make(chan []byte, execQueueSize),
+		responses: make(chan *execResponse, 16),
+		done:      make(chan struct{}),
+		quit:      make(chan struct{}),
+	}
+	h.process = p
+	go h.writeHandler(p)
+	go h.readHandler(p, stdout)
+	return nil
+}
+
+// writeHandler writes the queued messages to the standard input of the
+// process.  It must be run as a goroutine.
+func (h *execHook) writeHandler(p *execProcess) {
+	defer p.stdin.Close()
+	for {
+		select {
+		case msg := <-p.queue:
+			if _, err := p.stdin.Write(msg); err != nil {
+				log.Warnf("Unable to write to hook %s: %v",
+					h.path, err)
+				return
+			}
+		case <-p.quit:
+			return
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// readHandler passes the responses read from the standard output of the
+// process on until it exits.  It must be run as a goroutine.
+func (h *execHook) readHandler(p *execProcess, stdout io.Reader) {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(nil, maxExecLineLen)
+	for scanner.Scan() {
+		var r execResponse
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			log.Warnf("Invalid response from hook %s: %v", h.path,
+				err)
+			continue
+		}
+		select {
+		case p.responses <- &r:
+		default:
+		}
+	}
+	err := p.cmd.Wait()
+	if err == nil {
+		err = errors.New("exited")
+	}
+	log.Warnf("Hook %s stopped: %v", h.path, err)
+	close(p.done)
+}
+
+// running returns the external process when it is running, restarting it when
+// it exited more than execRestartDelay after it was last started, or nil.  It
+// must be called with the mutex held.
+func (h *execHook) running() *execProcess {
+	if h.closed {
+		return nil
+	}
+	select {
+	case <-h.process.done:
+	default:
+		return h.process
+	}
+	if time.Since(h.startedAt) < execRestartDelay {
+		return nil
+	}
+	if err := h.start(); err != nil {
+		log.Errorf("Unable to restart hook %s: %v", h.path, err)
+		return nil
+	}
+	log.Infof("Hook %s restarted", h.path)
+	return h.process
+}
+
+// send queues the passed request for the process, returning false when it was
+// dropped.
+func (h *execHook) send(p *execProcess, req *execRequest) bool {
+	msg, err := json.Marshal(req)
+	if err != nil {
+		log.Errorf("Unable to serialize %s request for hook %s: %v",
+			req.Method, h.path, err)
+		return false
+	}
+	select {
+	case p.queue <- append(msg, '\n'):
+		return true
+	default:
+		log.Warnf("Dropping %s request for hook %s: too many requests "+
+			"queued", req.Method, h.path)
+		return false
+	}
+}
+
+// notifyBlock sends the passed block notification to the process.
+func (h *execHook) notifyBlock(method string, block *btcutil.Block) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	p := h.running()
+	if p == nil {
+		return
+	}
+	header := &block.MsgBlock().Header
+	params := &execBlock{
+		Hash:          block.Hash().String(),
+		Height:        block.Height(),
+		PrevBlockHash: header.PrevBlock.String(),
+		Time:          header.Timestamp.Unix(),
+		Tx:            make([]string, 0, len(block.Transactions())),
+	}
+	for _, tx := range block.Transactions() {
+		params.Tx = append(params.Tx, tx.Hash().String())
+	}
+	h.send(p, &execRequest{Method: method, Params: params})
+}
+
+// BlockConnected sends a blockconnected notification to the process.
+func (h *execHook) BlockConnected(block *btcutil.Block) {
+	h.notifyBlock("blockconnected", block)
+}
+
+// BlockDisconnected sends a blockdisconnected notification to the process.
+func (h *execHook) BlockDisconnected(block *btcutil.Block) {
+	h.notifyBlock("blockdisconnected", block)
+}
+
+// AcceptTx asks the process whether the passed transaction is accepted.  It is
+// accepted when the process does not answer within the timeout.
+func (h *execHook) AcceptTx(tx *btcutil.Tx, fee int64) error {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	p := h.running()
+	if p == nil {
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := tx.MsgTx().Serialize(&buf); err != nil {
+		return nil
+	}
+	h.nextID++
+	id := h.nextID
+	if !h.send(p, &execRequest{
+		ID:     id,
+		Method: "accepttx",
+		Params: &execTx{
+			TxID: tx.Hash().String(),
+			Hex:  hex.EncodeToString(buf.Bytes()),
+			Fee:  fee,
+			Size: buf.Len(),
+		},
+	}) {
+		return nil
+	}
+
+	timer := time.NewTimer(h.timeout)
+	defer timer.Stop()
+	for {
+		select {
+		case r := <-p.responses:
+			// Skip the late answers to requests which timed out.
+			if r.ID != id {
+				continue
+			}
+			if r.Reject != "" {
+				return errors.New(r.Reject)
+			}
+			return nil
+
+		case <-p.done:
+			return nil
+
+		case <-timer.C:
+			log.Warnf("Hook %s did not answer within %v, accepting "+
+				"transaction %v", h.path, h.timeout, tx.Hash())
+			return nil
+		}
+	}
+}
+
+// Close closes the standard input of the process and waits for it to exit,
+// killing it when it takes too long.
+func (h *execHook) Close() {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	if h.closed {
+		return
+	}
+	h.closed = true
+	p := h.process
+	close(p.quit)
+	select {
+	case <-p.done:
+	case <-time.After(execStopTimeout):
+		log.Warnf("Hook %s did not exit, killing it", h.path)
+		p.cmd.Process.Kill()
+		<-p.done
+	}
+}
+
+// stderrLogger logs the lines written to the standard error of a process.
+type stderrLogger struct {
+	name string
+	buf  []byte
+}
+
+// Write logs the complete lines written so far.
+func (w *stderrLogger) Write(b []byte) (int, error) {
+	w.buf = append(w.buf, b...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		log.Infof("%s: %s", w.name, w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+	if len(w.buf) > maxExecLineLen {
+		log.Infof("%s: %s", w.name, w.buf)
+		w.buf = nil
+	}
+	return len(b), nil
+}
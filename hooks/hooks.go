@@ -0,0 +1,191 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package hooks
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/blockchain"
+)
+
+// ExecPrefix prefixes the command line of an external process in the
+// configuration of a hook.
+const ExecPrefix = "exec:"
+
+// DefaultTimeout is the time an external process is given to answer whether a
+// transaction is accepted when none is configured.
+const DefaultTimeout = 500 * time.Millisecond
+
+// Hook is implemented by the hooks.  The methods are called from the
+// goroutines of the chain and of the mempool while they are locked, so they
+// must return quickly.
+type Hook interface {
+	// BlockConnected and BlockDisconnected are called with every block
+	// connected to and disconnected from the main chain.
+	BlockConnected(block *btcutil.Block)
+	BlockDisconnected(block *btcutil.Block)
+
+	// AcceptTx is called with every transaction about to be accepted to
+	// the mempool, once it passed all the other checks, and its fee in
+	// satoshis.  Returning an error rejects the transaction.
+	AcceptTx(tx *btcutil.Tx, fee int64) error
+
+	// Close is called when the node shuts down.
+	Close()
+}
+
+// Factory returns a new hook given the arguments following its name in the
+// configuration, which are empty when there are none.
+type Factory func(args string) (Hook, error)
+
+var (
+	registryMtx sync.Mutex
+	registry    = make(map[string]Factory)
+)
+
+// Register makes a compiled-in hook available under the passed name.  It is
+// meant to be called from the init function of the package implementing the
+// hook, and panics when the name is invalid or already registered.
+func Register(name string, factory Factory) {
+	registryMtx.Lock()
+	defer registryMtx.Unlock()
+
+	if name == "" || strings.ContainsRune(name, ':') {
+		panic(fmt.Sprintf("hooks: invalid hook name %q", name))
+	}
+	if _, ok := registry[name]; ok {
+		panic(fmt.Sprintf("hooks: hook %q registered twice", name))
+	}
+	registry[name] = factory
+}
+
+// Names returns the sorted names of the registered hooks.
+func Names() []string {
+	registryMtx.Lock()
+	defer registryMtx.Unlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Config holds the configuration of the hooks.
+type Config struct {
+	// Hooks are the hooks invoked, in order.  Each is either the name of a
+	// registered hook, optionally followed by a colon and its arguments,
+	// or ExecPrefix followed by the command line of an external process.
+	Hooks []string
+
+	// Timeout is the time an external process is given to answer whether
+	// a transaction is accepted.  DefaultTimeout is used when 0.
+	Timeout time.Duration
+}
+
+// namedHook is a hook with the name it is logged with.
+type namedHook struct {
+	name string
+	Hook
+}
+
+// Manager invokes the configured hooks.
+type Manager struct {
+	hooks []namedHook
+}
+
+// New returns a manager of the hooks of the passed configuration.  The
+// external processes are started right away.
+func New(cfg *Config) (*Manager, error) {
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	m := &Manager{}
+	for _, spec := range cfg.Hooks {
+		var name string
+		var hook Hook
+		var err error
+		if strings.HasPrefix(spec, ExecPrefix) {
+			args := strings.Fields(spec[len(ExecPrefix):])
+			if len(args) == 0 {
+				m.Close()
+				return nil, fmt.Errorf("missing command line of "+
+					"hook %q", spec)
+			}
+			name = args[0]
+			hook, err = newExecHook(args[0], args[1:], timeout)
+		} else {
+			var args string
+			name = spec
+			if i := strings.IndexByte(spec, ':'); i >= 0 {
+				name, args = spec[:i], spec[i+1:]
+			}
+			registryMtx.Lock()
+			factory, ok := registry[name]
+			registryMtx.Unlock()
+			if !ok {
+				m.Close()
+				return nil, fmt.Errorf("unknown hook %q -- the "+
+					"compiled-in hooks are %v", name, Names())
+			}
+			hook, err = factory(args)
+		}
+		if err != nil {
+			m.Close()
+			return nil, fmt.Errorf("unable to start hook %s: %v",
+				name, err)
+		}
+		m.hooks = append(m.hooks, namedHook{name: name, Hook: hook})
+		log.Infof("Hook %s enabled", name)
+	}
+	return m, nil
+}
+
+// HandleBlockchainNotification passes the blocks connected to and disconnected
+// from the main chain to the hooks.  It is meant to be subscribed to the chain
+// notifications.
+func (m *Manager) HandleBlockchainNotification(n *blockchain.Notification) {
+	switch n.Type {
+	case blockchain.NTBlockConnected:
+		block := n.Data.(*btcutil.Block)
+		for _, h := range m.hooks {
+			h.BlockConnected(block)
+		}
+
+	case blockchain.NTBlockDisconnected:
+		block := n.Data.(*btcutil.Block)
+		for _, h := range m.hooks {
+			h.BlockDisconnected(block)
+		}
+	}
+}
+
+// AcceptTx returns an error when a hook rejects the passed transaction about
+// to be accepted to the mempool.  It is meant to be the policy hook of the
+// mempool.
+func (m *Manager) AcceptTx(tx *btcutil.Tx, fee int64) error {
+	for _, h := range m.hooks {
+		if err := h.AcceptTx(tx, fee); err != nil {
+			log.Debugf("Hook %s rejected transaction %v: %v",
+				h.name, tx.Hash(), err)
+			return fmt.Errorf("%s: %v", h.name, err)
+		}
+	}
+	return nil
+}
+
+// Close closes the hooks, waiting for the external processes to exit.
+func (m *Manager) Close() {
+	for _, h := range m.hooks {
+		h.Close()
+	}
+}
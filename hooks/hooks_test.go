@@ -0,0 +1,236 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package hooks
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// testHook records the blocks it is notified and rejects the transactions
+// paying less than a minimum fee.
+type testHook struct {
+	minFee int64
+	blocks []string
+	closed bool
+}
+
+func (h *testHook) BlockConnected(block *btcutil.Block) {
+	h.blocks = append(h.blocks, "+"+block.Hash().String())
+}
+
+func (h *testHook) BlockDisconnected(block *btcutil.Block) {
+	h.blocks = append(h.blocks, "-"+block.Hash().String())
+}
+
+func (h *testHook) AcceptTx(tx *btcutil.Tx, fee int64) error {
+	if fee < h.minFee {
+		return errors.New("fee too low")
+	}
+	return nil
+}
+
+func (h *testHook) Close() {
+	h.closed = true
+}
+
+// testBlock returns a block with a single transaction at the passed height.
+func testBlock(height int32) *btcutil.Block {
+	msgBlock := wire.NewMsgBlock(wire.NewBlockHeader(1, &chainhash.Hash{},
+		&chainhash.Hash{}, 0, uint32(height)))
+	msgBlock.AddTransaction(testTx().MsgTx())
+	block := btcutil.NewBlock(msgBlock)
+	block.SetHeight(height)
+	return block
+}
+
+// testTx returns a transaction spending a null outpoint.
+func testTx() *btcutil.Tx {
+	tx := wire.NewMsgTx(wire.TxVersion)
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{}, nil, nil))
+	tx.AddTxOut(wire.NewTxOut(1e8, []byte{0x51}))
+	return btcutil.NewTx(tx)
+}
+
+// TestManager ensures compiled-in hooks are looked up by name, given their
+// arguments, notified of the blocks and allowed to reject transactions.
+func TestManager(t *testing.T) {
+	var hook *testHook
+	Register("test", func(args string) (Hook, error) {
+		minFee, err := strconv.ParseInt(args, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		hook = &testHook{minFee: minFee}
+		return hook, nil
+	})
+	defer func() {
+		registryMtx.Lock()
+		delete(registry, "test")
+		registryMtx.Unlock()
+	}()
+	if names := Names(); len(names) != 1 || names[0] != "test" {
+		t.Fatalf("Names: got %v", names)
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatalf("Register: no panic registering a hook twice")
+			}
+		}()
+		Register("test", nil)
+	}()
+
+	for _, spec := range []string{"unknown", "test:notanumber", "exec:"} {
+		if _, err := New(&Config{Hooks: []string{spec}}); err == nil {
+			t.Fatalf("New: unexpected success with hook %q", spec)
+		}
+	}
+
+	m, err := New(&Config{Hooks: []string{"test:1000"}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	connected, disconnected := testBlock(1), testBlock(2)
+	m.HandleBlockchainNotification(&blockchain.Notification{
+		Type: blockchain.NTBlockConnected,
+		Data: connected,
+	})
+	m.HandleBlockchainNotification(&blockchain.Notification{
+		Type: blockchain.NTBlockDisconnected,
+		Data: disconnected,
+	})
+	want := []string{"+" + connected.Hash().String(),
+		"-" + disconnected.Hash().String()}
+	if fmt.Sprint(hook.blocks) != fmt.Sprint(want) {
+		t.Fatalf("notified blocks %v, want %v", hook.blocks, want)
+	}
+
+	if err := m.AcceptTx(testTx(), 1000); err != nil {
+		t.Fatalf("AcceptTx: unexpected rejection: %v", err)
+	}
+	err = m.AcceptTx(testTx(), 999)
+	if err == nil || err.Error() != "test: fee too low" {
+		t.Fatalf("AcceptTx: unexpected error %v", err)
+	}
+
+	m.Close()
+	if !hook.closed {
+		t.Fatalf("Close: hook not closed")
+	}
+}
+
+// TestExecHook ensures an external process is notified of the blocks, that its
+// answers reject transactions and that transactions are accepted when it
+// doesn't answer in time or exits.
+func TestExecHook(t *testing.T) {
+	os.Setenv("PKTD_TEST_HOOK_PROCESS", "1")
+	defer os.Unsetenv("PKTD_TEST_HOOK_PROCESS")
+
+	m, err := New(&Config{
+		Hooks: []string{ExecPrefix + os.Args[0] +
+			" -test.run=TestHookProcess"},
+		Timeout: time.Second,
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer m.Close()
+	h := m.hooks[0].Hook.(*execHook)
+
+	// The process rejects transactions paying less than 1000 satoshis,
+	// giving the number of blocks it was notified.
+	m.HandleBlockchainNotification(&blockchain.Notification{
+		Type: blockchain.NTBlockConnected,
+		Data: testBlock(1),
+	})
+	if err := m.AcceptTx(testTx(), 1000); err != nil {
+		t.Fatalf("AcceptTx: unexpected rejection: %v", err)
+	}
+	err = m.AcceptTx(testTx(), 999)
+	if err == nil || !strings.HasSuffix(err.Error(), "fee too low after 1 blocks") {
+		t.Fatalf("AcceptTx: unexpected error %v", err)
+	}
+
+	// A fee of 1 satoshi is never answered and the late answer of a fee of
+	// 2 is ignored.
+	h.timeout = 100 * time.Millisecond
+	if err := m.AcceptTx(testTx(), 1); err != nil {
+		t.Fatalf("AcceptTx: unexpected rejection without answer: %v",
+			err)
+	}
+	if err := m.AcceptTx(testTx(), 2); err != nil {
+		t.Fatalf("AcceptTx: unexpected rejection with late answer: %v",
+			err)
+	}
+	h.timeout = time.Second
+	if err := m.AcceptTx(testTx(), 999); err == nil {
+		t.Fatalf("AcceptTx: unexpected acceptance after a late answer")
+	}
+
+	// A fee of 3 satoshis makes the process exit, so transactions are
+	// accepted until it is restarted.
+	if err := m.AcceptTx(testTx(), 3); err != nil {
+		t.Fatalf("AcceptTx: unexpected rejection on exit: %v", err)
+	}
+	if err := m.AcceptTx(testTx(), 999); err != nil {
+		t.Fatalf("AcceptTx: unexpected rejection while stopped: %v", err)
+	}
+	h.startedAt = time.Now().Add(-execRestartDelay)
+	if err := m.AcceptTx(testTx(), 999); err == nil {
+		t.Fatalf("AcceptTx: unexpected acceptance after a restart")
+	}
+}
+
+// TestHookProcess is the external process of TestExecHook.
+func TestHookProcess(t *testing.T) {
+	if os.Getenv("PKTD_TEST_HOOK_PROCESS") != "1" {
+		return
+	}
+	blocks := 0
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var req struct {
+			ID     uint64
+			Method string
+			Params struct{ Fee int64 }
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			os.Exit(1)
+		}
+		switch {
+		case req.Method == "blockconnected":
+			blocks++
+			continue
+		case req.Method != "accepttx" || req.Params.Fee == 1:
+			continue
+		case req.Params.Fee == 2:
+			time.Sleep(200 * time.Millisecond)
+		case req.Params.Fee == 3:
+			os.Exit(0)
+		}
+		resp := map[string]interface{}{"id": req.ID}
+		if req.Params.Fee < 1000 && req.Params.Fee != 2 {
+			resp["reject"] = fmt.Sprintf("fee too low after %d "+
+				"blocks", blocks)
+		}
+		if err := json.NewEncoder(os.Stdout).Encode(resp); err != nil {
+			os.Exit(1)
+		}
+	}
+	os.Exit(0)
+}
@@ -0,0 +1,200 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// This file is ignored during the regular tests due to the following build tag.
+// +build rpctest
+
+package integration
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/integration/rpctest"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// lossyLink are the conditions of a slow and unreliable link.
+var lossyLink = rpctest.LinkConditions{
+	Latency: 30 * time.Millisecond,
+	Jitter:  20 * time.Millisecond,
+	Loss:    0.05,
+}
+
+// newTestNetwork creates and sets up a simulated network, failing the test
+// on error.
+func newTestNetwork(t *testing.T, cfg *rpctest.NetworkConfig) *rpctest.Network {
+	n, err := rpctest.NewNetwork(&chaincfg.SimNetParams, cfg)
+	if err != nil {
+		t.Fatalf("unable to create network: %v", err)
+	}
+	if err := n.SetUp(); err != nil {
+		n.TearDown()
+		t.Fatalf("unable to set up network: %v", err)
+	}
+	return n
+}
+
+// sendTestTx sends a transaction paying to a new address of the harness.
+func sendTestTx(h *rpctest.Harness) (*chainhash.Hash, error) {
+	addr, err := h.NewAddress()
+	if err != nil {
+		return nil, err
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return nil, err
+	}
+	output := wire.NewTxOut(btcutil.SatoshiPerBitcoin, pkScript)
+	return h.SendOutputs([]*wire.TxOut{output}, 10)
+}
+
+// assertInMempool ensures the transaction is in the mempool of the nodes.
+func assertInMempool(n *rpctest.Network, txHash *chainhash.Hash, nodes ...int) error {
+	for _, node := range nodes {
+		pool, err := n.Nodes[node].Node.GetRawMempool()
+		if err != nil {
+			return err
+		}
+		var found bool
+		for _, hash := range pool {
+			if *hash == *txHash {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("transaction %v not in the mempool of "+
+				"node %d", txHash, node)
+		}
+	}
+	return nil
+}
+
+// TestNetworkBlockPropagation ensures blocks propagate along a line of nodes
+// connected over slow and lossy links, in both directions.
+func TestNetworkBlockPropagation(t *testing.T) {
+	n := newTestNetwork(t, &rpctest.NetworkConfig{
+		Nodes:      4,
+		Topology:   rpctest.LineTopology,
+		Conditions: lossyLink,
+	})
+	defer n.TearDown()
+
+	err := n.Run(
+		rpctest.Mine(0, 3),
+		rpctest.Sync(),
+		rpctest.Mine(3, 2),
+		rpctest.Sync(),
+		rpctest.Check("height", func(n *rpctest.Network) error {
+			count, err := n.Nodes[1].Node.GetBlockCount()
+			if err != nil {
+				return err
+			}
+			if count != 5 {
+				return fmt.Errorf("unexpected height %d", count)
+			}
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestNetworkMempoolRelay ensures transactions are relayed to every mempool
+// of a star network over lossy links.
+func TestNetworkMempoolRelay(t *testing.T) {
+	n := newTestNetwork(t, &rpctest.NetworkConfig{
+		Nodes:            4,
+		Topology:         rpctest.StarTopology,
+		Conditions:       lossyLink,
+		NumMatureOutputs: 1,
+	})
+	defer n.TearDown()
+
+	txHash, err := sendTestTx(n.Nodes[0])
+	if err != nil {
+		t.Fatalf("unable to send transaction: %v", err)
+	}
+	err = n.Run(
+		rpctest.SyncMempools(),
+		rpctest.Check("relayed", func(n *rpctest.Network) error {
+			return assertInMempool(n, txHash, 1, 2, 3)
+		}),
+
+		// Mining the transaction on a leaf removes it from every
+		// mempool.
+		rpctest.Mine(3, 1),
+		rpctest.Sync(),
+		rpctest.SyncMempools(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool, err := n.Nodes[0].Node.GetRawMempool()
+	if err != nil {
+		t.Fatalf("unable to get mempool: %v", err)
+	}
+	if len(pool) != 0 {
+		t.Fatalf("mempool not empty after mining: %v", pool)
+	}
+}
+
+// TestNetworkPartitionReorg ensures the nodes of a partitioned network
+// converge on the longest chain once the partition heals, and that the
+// transactions of the disconnected blocks return to the mempool.
+func TestNetworkPartitionReorg(t *testing.T) {
+	n := newTestNetwork(t, &rpctest.NetworkConfig{
+		Nodes:            4,
+		Topology:         rpctest.LineTopology,
+		NumMatureOutputs: 1,
+	})
+	defer n.TearDown()
+
+	var txHash *chainhash.Hash
+	var longestTip []*chainhash.Hash
+	err := n.Run(
+		rpctest.Partition([]int{0, 1}, []int{2, 3}),
+
+		// The first side mines a transaction in a short chain.
+		rpctest.Check("send transaction", func(n *rpctest.Network) error {
+			var err error
+			txHash, err = sendTestTx(n.Nodes[0])
+			return err
+		}),
+		rpctest.SyncMempools(0, 1),
+		rpctest.Mine(0, 1),
+		rpctest.Sync(0, 1),
+
+		// The second side mines a longer chain.
+		rpctest.Check("mine longest chain", func(n *rpctest.Network) error {
+			var err error
+			longestTip, err = n.Generate(3, 3)
+			return err
+		}),
+		rpctest.Sync(2, 3),
+
+		rpctest.Heal(),
+		rpctest.Sync(),
+		rpctest.Check("reorg", func(n *rpctest.Network) error {
+			tip, _, err := n.Nodes[0].Node.GetBestBlock()
+			if err != nil {
+				return err
+			}
+			if *tip != *longestTip[len(longestTip)-1] {
+				return fmt.Errorf("unexpected tip %v", tip)
+			}
+			return assertInMempool(n, txHash, 0, 1)
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,291 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rpctest
+
+import (
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultRetransmitDelay is the delay added to lost data when the link
+	// conditions do not specify one.
+	defaultRetransmitDelay = 200 * time.Millisecond
+
+	// linkDialTimeout is the time allowed to connect to the target of a
+	// link.
+	linkDialTimeout = 5 * time.Second
+
+	// linkQueueSize is the number of chunks of data in flight in each
+	// direction of a proxied connection.
+	linkQueueSize = 1024
+)
+
+// LinkConditions describes the network conditions simulated by a link.
+type LinkConditions struct {
+	// Latency is the delay added to all data sent over the link.
+	Latency time.Duration
+
+	// Jitter is the maximum random delay added to the latency.  Data is
+	// never reordered.
+	Jitter time.Duration
+
+	// Loss is the probability, between 0 and 1, that a chunk of data sent
+	// over the link is lost.  Since nodes talk over TCP, lost data is
+	// delayed by RetransmitDelay instead of being dropped, which is what a
+	// TCP peer observes.
+	Loss float64
+
+	// RetransmitDelay is the delay added to lost data.  It defaults to
+	// 200ms.
+	RetransmitDelay time.Duration
+}
+
+// Link is a TCP proxy simulating the network between two nodes.  Nodes connect
+// to the address of the link, which forwards the data to the target address
+// under the configured conditions.  A link can be cut to simulate a network
+// partition and restored later on.
+//
+// NOTE: All methods are safe for concurrent access.
+type Link struct {
+	listener net.Listener
+	target   string
+
+	mtx   sync.Mutex
+	cond  LinkConditions
+	cut   bool
+	conns map[net.Conn]struct{}
+	rand  *rand.Rand
+
+	wg sync.WaitGroup
+}
+
+// NewLink returns a link listening on a random local port and forwarding
+// connections to the target address.
+func NewLink(target string, cond LinkConditions) (*Link, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	l := &Link{
+		listener: listener,
+		target:   target,
+		cond:     cond,
+		conns:    make(map[net.Conn]struct{}),
+		rand:     rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	l.wg.Add(1)
+	go l.acceptHandler()
+	return l, nil
+}
+
+// Addr returns the address nodes connect to in order to use the link.
+func (l *Link) Addr() string {
+	return l.listener.Addr().String()
+}
+
+// Target returns the address the link forwards connections to.
+func (l *Link) Target() string {
+	return l.target
+}
+
+// Conditions returns the network conditions simulated by the link.
+func (l *Link) Conditions() LinkConditions {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	return l.cond
+}
+
+// SetConditions changes the network conditions simulated by the link.  The
+// new conditions apply to data sent from now on.
+func (l *Link) SetConditions(cond LinkConditions) {
+	l.mtx.Lock()
+	l.cond = cond
+	l.mtx.Unlock()
+}
+
+// Cut closes every connection over the link and refuses new ones until the
+// link is restored.
+func (l *Link) Cut() {
+	l.mtx.Lock()
+	l.cut = true
+	l.closeConns()
+	l.mtx.Unlock()
+}
+
+// Restore accepts connections over a cut link again.  Nodes connected with a
+// persistent connection reconnect on their own.
+func (l *Link) Restore() {
+	l.mtx.Lock()
+	l.cut = false
+	l.mtx.Unlock()
+}
+
+// IsCut returns whether the link is cut.
+func (l *Link) IsCut() bool {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	return l.cut
+}
+
+// Close stops the link and closes every connection over it.
+func (l *Link) Close() error {
+	err := l.listener.Close()
+	l.mtx.Lock()
+	l.closeConns()
+	l.mtx.Unlock()
+	l.wg.Wait()
+	return err
+}
+
+// closeConns closes every connection over the link.
+//
+// This function MUST be called with the link mutex held.
+func (l *Link) closeConns() {
+	for conn := range l.conns {
+		conn.Close()
+	}
+}
+
+// track adds the connection to the ones closed when the link is cut.  It
+// returns false when the link is cut, in which case the connection is closed.
+func (l *Link) track(conn net.Conn) bool {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if l.cut {
+		conn.Close()
+		return false
+	}
+	l.conns[conn] = struct{}{}
+	return true
+}
+
+// untrack closes the connection and removes it from the tracked ones.
+func (l *Link) untrack(conn net.Conn) {
+	conn.Close()
+	l.mtx.Lock()
+	delete(l.conns, conn)
+	l.mtx.Unlock()
+}
+
+// delay returns the delay of the next chunk of data sent over the link.
+func (l *Link) delay() time.Duration {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	d := l.cond.Latency
+	if l.cond.Jitter > 0 {
+		d += time.Duration(l.rand.Int63n(int64(l.cond.Jitter)))
+	}
+	if l.cond.Loss > 0 && l.rand.Float64() < l.cond.Loss {
+		retransmit := l.cond.RetransmitDelay
+		if retransmit == 0 {
+			retransmit = defaultRetransmitDelay
+		}
+		d += retransmit
+	}
+	return d
+}
+
+// acceptHandler accepts connections until the link is closed.  It must be run
+// as a goroutine.
+func (l *Link) acceptHandler() {
+	defer l.wg.Done()
+	for {
+		conn, err := l.listener.Accept()
+		if err != nil {
+			return
+		}
+		if !l.track(conn) {
+			continue
+		}
+		l.wg.Add(1)
+		go l.proxy(conn)
+	}
+}
+
+// proxy forwards data between the accepted connection and the target of the
+// link until either side closes.  It must be run as a goroutine.
+func (l *Link) proxy(in net.Conn) {
+	defer l.wg.Done()
+	defer l.untrack(in)
+
+	out, err := net.DialTimeout("tcp", l.target, linkDialTimeout)
+	if err != nil {
+		return
+	}
+	if !l.track(out) {
+		return
+	}
+	defer l.untrack(out)
+
+	// Close both connections as soon as either direction ends so the
+	// other one ends as well.
+	done := make(chan struct{}, 2)
+	go func() {
+		l.forward(in, out)
+		done <- struct{}{}
+	}()
+	go func() {
+		l.forward(out, in)
+		done <- struct{}{}
+	}()
+	<-done
+	in.Close()
+	out.Close()
+	<-done
+}
+
+// linkChunk is a chunk of data in flight over a link.
+type linkChunk struct {
+	data []byte
+	due  time.Time
+}
+
+// forward reads data from src and writes it to dst once its delay elapsed,
+// preserving the order of the data.
+func (l *Link) forward(src, dst net.Conn) {
+	queue := make(chan linkChunk, linkQueueSize)
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+		var failed bool
+		for c := range queue {
+			if failed {
+				continue
+			}
+			time.Sleep(time.Until(c.due))
+			if _, err := dst.Write(c.data); err != nil {
+				// Keep draining the queue so the reader is not
+				// blocked.
+				failed = true
+				src.Close()
+			}
+		}
+	}()
+
+	var last time.Time
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := src.Read(buf)
+		if n > 0 {
+			due := time.Now().Add(l.delay())
+			if due.Before(last) {
+				due = last
+			}
+			last = due
+			data := make([]byte, n)
+			copy(data, buf[:n])
+			queue <- linkChunk{data: data, due: due}
+		}
+		if err != nil {
+			break
+		}
+	}
+	close(queue)
+	<-written
+}
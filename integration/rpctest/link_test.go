@@ -0,0 +1,146 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rpctest
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// startEchoServer starts a TCP server echoing back everything it receives.
+func startEchoServer(t *testing.T) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				io.Copy(conn, conn)
+				conn.Close()
+			}()
+		}
+	}()
+	return listener
+}
+
+// TestLink ensures data sent over a link is delayed by the configured latency
+// without being reordered, and that cut links refuse connections until they
+// are restored.
+func TestLink(t *testing.T) {
+	echo := startEchoServer(t)
+	defer echo.Close()
+
+	const latency = 50 * time.Millisecond
+	l, err := NewLink(echo.Addr().String(), LinkConditions{
+		Latency:         latency,
+		Loss:            0.5,
+		RetransmitDelay: 10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("NewLink: %v", err)
+	}
+	defer l.Close()
+
+	conn, err := net.Dial("tcp", l.Addr())
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer conn.Close()
+
+	// Data crosses the link twice, so the round trip takes at least twice
+	// the latency, and arrives in order despite the losses.
+	var sent bytes.Buffer
+	start := time.Now()
+	for i := 0; i < 20; i++ {
+		chunk := bytes.Repeat([]byte{byte(i)}, 100)
+		sent.Write(chunk)
+		if _, err := conn.Write(chunk); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	received := make([]byte, sent.Len())
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := io.ReadFull(conn, received); err != nil {
+		t.Fatalf("ReadFull: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 2*latency {
+		t.Fatalf("round trip took %v, less than twice the latency", elapsed)
+	}
+	if !bytes.Equal(received, sent.Bytes()) {
+		t.Fatal("data reordered or corrupted over the link")
+	}
+
+	// Cutting the link closes the connection and refuses new ones.
+	l.Cut()
+	if !l.IsCut() {
+		t.Fatal("link not cut")
+	}
+	if _, err := conn.Read(received); err == nil {
+		t.Fatal("connection still open after cutting the link")
+	}
+	cutConn, err := net.Dial("tcp", l.Addr())
+	if err == nil {
+		cutConn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		if _, err := cutConn.Read(received); err == nil {
+			t.Fatal("connection accepted over a cut link")
+		}
+		cutConn.Close()
+	}
+
+	// Restored links accept connections again.
+	l.Restore()
+	l.SetConditions(LinkConditions{})
+	conn, err = net.Dial("tcp", l.Addr())
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte("ping")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := io.ReadFull(conn, received[:4]); err != nil {
+		t.Fatalf("ReadFull after restore: %v", err)
+	}
+	if string(received[:4]) != "ping" {
+		t.Fatalf("unexpected echo %q", received[:4])
+	}
+}
+
+// TestTopologies ensures the topologies connect the expected nodes.
+func TestTopologies(t *testing.T) {
+	tests := []struct {
+		name     string
+		topology Topology
+		want     [][2]int
+	}{
+		{"line", LineTopology, [][2]int{{0, 1}, {1, 2}, {2, 3}}},
+		{"ring", RingTopology, [][2]int{{0, 1}, {1, 2}, {2, 3}, {3, 0}}},
+		{"star", StarTopology, [][2]int{{1, 0}, {2, 0}, {3, 0}}},
+		{"mesh", MeshTopology, [][2]int{{0, 1}, {0, 2}, {0, 3},
+			{1, 2}, {1, 3}, {2, 3}}},
+	}
+	for _, test := range tests {
+		got := test.topology(4)
+		if len(got) != len(test.want) {
+			t.Errorf("%s: unexpected edges %v", test.name, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != test.want[i] {
+				t.Errorf("%s: unexpected edges %v", test.name, got)
+				break
+			}
+		}
+	}
+}
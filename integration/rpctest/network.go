@@ -0,0 +1,348 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rpctest
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+)
+
+// DefaultSyncTimeout is the time allowed for the nodes of a network to sync
+// when the network configuration does not specify one.
+const DefaultSyncTimeout = time.Minute
+
+// Topology returns the connections to make between the nodes of a network of
+// the passed size.  Each connection is a pair of node indexes, the first node
+// connecting to the second one.
+type Topology func(size int) [][2]int
+
+// LineTopology connects every node to the next one.
+func LineTopology(size int) [][2]int {
+	var edges [][2]int
+	for i := 0; i+1 < size; i++ {
+		edges = append(edges, [2]int{i, i + 1})
+	}
+	return edges
+}
+
+// RingTopology connects every node to the next one and the last node to the
+// first one.
+func RingTopology(size int) [][2]int {
+	edges := LineTopology(size)
+	if size > 2 {
+		edges = append(edges, [2]int{size - 1, 0})
+	}
+	return edges
+}
+
+// StarTopology connects every node to the first one.
+func StarTopology(size int) [][2]int {
+	var edges [][2]int
+	for i := 1; i < size; i++ {
+		edges = append(edges, [2]int{i, 0})
+	}
+	return edges
+}
+
+// MeshTopology connects every node to every other node.
+func MeshTopology(size int) [][2]int {
+	var edges [][2]int
+	for i := 0; i < size; i++ {
+		for j := i + 1; j < size; j++ {
+			edges = append(edges, [2]int{i, j})
+		}
+	}
+	return edges
+}
+
+// NetworkConfig is the configuration of a simulated network.
+type NetworkConfig struct {
+	// Nodes is the number of nodes in the network.
+	Nodes int
+
+	// Topology selects the connections between the nodes.  It defaults to
+	// LineTopology.
+	Topology Topology
+
+	// Conditions are the network conditions of every link.  They can be
+	// changed per link once the network is set up.
+	Conditions LinkConditions
+
+	// ExtraArgs are passed to every node.
+	ExtraArgs []string
+
+	// NumMatureOutputs is the number of mature coinbase outputs generated
+	// for the wallet of the first node during set up.  The chain is synced
+	// to the other nodes.
+	NumMatureOutputs uint32
+
+	// SyncTimeout is the time allowed for the nodes to sync.  It defaults
+	// to DefaultSyncTimeout.
+	SyncTimeout time.Duration
+}
+
+// Network is a set of harnesses connected with each other through links
+// simulating the network conditions between them.  It is used to test
+// behaviors involving several nodes, such as block and transaction relay,
+// under latency and packet loss, as well as chain reorganizations caused by
+// network partitions.
+//
+// NOTE: The methods of a network should be called from the same goroutine.
+type Network struct {
+	// Nodes are the harnesses of the network nodes.
+	Nodes []*Harness
+
+	cfg   NetworkConfig
+	links map[[2]int]*Link
+}
+
+// NewNetwork creates the harnesses of a simulated network.  The nodes are
+// started and connected by SetUp.
+func NewNetwork(activeNet *chaincfg.Params, cfg *NetworkConfig) (*Network, error) {
+	if cfg.Nodes < 1 {
+		return nil, fmt.Errorf("a network needs at least one node")
+	}
+	n := &Network{
+		cfg:   *cfg,
+		links: make(map[[2]int]*Link),
+	}
+	if n.cfg.Topology == nil {
+		n.cfg.Topology = LineTopology
+	}
+	if n.cfg.SyncTimeout == 0 {
+		n.cfg.SyncTimeout = DefaultSyncTimeout
+	}
+	for i := 0; i < cfg.Nodes; i++ {
+		h, err := New(activeNet, nil, cfg.ExtraArgs)
+		if err != nil {
+			n.TearDown()
+			return nil, err
+		}
+		n.Nodes = append(n.Nodes, h)
+	}
+	return n, nil
+}
+
+// SetUp starts every node, connects them according to the topology and waits
+// for them to sync.
+//
+// NOTE: This method and TearDown should always be called from the same
+// goroutine as they are not concurrent safe.
+func (n *Network) SetUp() error {
+	for i, h := range n.Nodes {
+		if err := h.SetUp(i == 0, n.cfg.NumMatureOutputs); err != nil {
+			return err
+		}
+	}
+	for _, edge := range n.cfg.Topology(len(n.Nodes)) {
+		_, err := n.Connect(edge[0], edge[1], n.cfg.Conditions)
+		if err != nil {
+			return err
+		}
+	}
+	return n.Sync()
+}
+
+// TearDown closes every link and tears down every node of the network.
+func (n *Network) TearDown() error {
+	for _, l := range n.links {
+		l.Close()
+	}
+	n.links = make(map[[2]int]*Link)
+	for _, h := range n.Nodes {
+		if err := h.TearDown(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Connect connects the from node to the to node over a new link with the
+// passed conditions and waits for the connection to be established.
+func (n *Network) Connect(from, to int, cond LinkConditions) (*Link, error) {
+	if n.Link(from, to) != nil {
+		return nil, fmt.Errorf("nodes %d and %d are already linked",
+			from, to)
+	}
+	l, err := NewLink(n.Nodes[to].P2PAddress(), cond)
+	if err != nil {
+		return nil, err
+	}
+	if err := connectNodeAddr(n.Nodes[from], l.Addr()); err != nil {
+		l.Close()
+		return nil, err
+	}
+	n.links[[2]int{from, to}] = l
+	return l, nil
+}
+
+// Link returns the link between the two nodes in either direction, or nil
+// when they are not linked.
+func (n *Network) Link(a, b int) *Link {
+	if l, ok := n.links[[2]int{a, b}]; ok {
+		return l
+	}
+	return n.links[[2]int{b, a}]
+}
+
+// SetConditions changes the network conditions of every link.
+func (n *Network) SetConditions(cond LinkConditions) {
+	for _, l := range n.links {
+		l.SetConditions(cond)
+	}
+}
+
+// Partition cuts every link between nodes of different groups.  Nodes which
+// are not part of any group form a group of their own.
+func (n *Network) Partition(groups ...[]int) {
+	group := make(map[int]int)
+	for i, g := range groups {
+		for _, node := range g {
+			group[node] = i + 1
+		}
+	}
+	for edge, l := range n.links {
+		if group[edge[0]] != group[edge[1]] {
+			l.Cut()
+		}
+	}
+}
+
+// Heal restores every cut link.  The nodes reconnect on their own since their
+// connections are persistent.
+func (n *Network) Heal() {
+	for _, l := range n.links {
+		l.Restore()
+	}
+}
+
+// Generate mines blocks on the passed node.
+func (n *Network) Generate(node int, blocks uint32) ([]*chainhash.Hash, error) {
+	return n.Nodes[node].Node.Generate(blocks)
+}
+
+// harnesses returns the harnesses of the passed nodes, or every harness when
+// no node is passed.
+func (n *Network) harnesses(nodes []int) []*Harness {
+	if len(nodes) == 0 {
+		return n.Nodes
+	}
+	harnesses := make([]*Harness, 0, len(nodes))
+	for _, node := range nodes {
+		harnesses = append(harnesses, n.Nodes[node])
+	}
+	return harnesses
+}
+
+// waitFor polls the condition until it holds or the sync timeout expires.
+func (n *Network) waitFor(what string, cond func() (bool, error)) error {
+	deadline := time.Now().Add(n.cfg.SyncTimeout)
+	for {
+		ok, err := cond()
+		if err != nil || ok {
+			return err
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%s not synced after %v", what,
+				n.cfg.SyncTimeout)
+		}
+		time.Sleep(time.Millisecond * 100)
+	}
+}
+
+// Sync waits until the passed nodes, or every node when none is passed, share
+// the same best chain.
+func (n *Network) Sync(nodes ...int) error {
+	harnesses := n.harnesses(nodes)
+	return n.waitFor("blocks", func() (bool, error) {
+		return blocksMatch(harnesses)
+	})
+}
+
+// SyncMempools waits until the passed nodes, or every node when none is
+// passed, have identical mempools.
+func (n *Network) SyncMempools(nodes ...int) error {
+	harnesses := n.harnesses(nodes)
+	return n.waitFor("mempools", func() (bool, error) {
+		return mempoolsMatch(harnesses)
+	})
+}
+
+// Step is a single step of a scenario run on a network.
+type Step struct {
+	// Name describes the step in errors.
+	Name string
+
+	// Do performs the step.
+	Do func(n *Network) error
+}
+
+// Run runs the steps of a scenario in order and returns the error of the
+// first failing step.
+func (n *Network) Run(steps ...Step) error {
+	for i, step := range steps {
+		if err := step.Do(n); err != nil {
+			return fmt.Errorf("step %d (%s): %v", i, step.Name, err)
+		}
+	}
+	return nil
+}
+
+// Mine returns a step mining blocks on the passed node.
+func Mine(node int, blocks uint32) Step {
+	name := fmt.Sprintf("mine %d blocks on node %d", blocks, node)
+	return Step{name, func(n *Network) error {
+		_, err := n.Generate(node, blocks)
+		return err
+	}}
+}
+
+// Partition returns a step partitioning the network.  See Network.Partition.
+func Partition(groups ...[]int) Step {
+	return Step{fmt.Sprintf("partition %v", groups), func(n *Network) error {
+		n.Partition(groups...)
+		return nil
+	}}
+}
+
+// Heal returns a step restoring every cut link.
+func Heal() Step {
+	return Step{"heal", func(n *Network) error {
+		n.Heal()
+		return nil
+	}}
+}
+
+// Conditions returns a step changing the conditions of every link.
+func Conditions(cond LinkConditions) Step {
+	return Step{"set link conditions", func(n *Network) error {
+		n.SetConditions(cond)
+		return nil
+	}}
+}
+
+// Sync returns a step waiting for the passed nodes, or every node when none
+// is passed, to share the same best chain.
+func Sync(nodes ...int) Step {
+	return Step{fmt.Sprintf("sync blocks of %v", nodes), func(n *Network) error {
+		return n.Sync(nodes...)
+	}}
+}
+
+// SyncMempools returns a step waiting for the passed nodes, or every node
+// when none is passed, to have identical mempools.
+func SyncMempools(nodes ...int) Step {
+	return Step{fmt.Sprintf("sync mempools of %v", nodes), func(n *Network) error {
+		return n.SyncMempools(nodes...)
+	}}
+}
+
+// Check returns a step running an arbitrary check on the network.
+func Check(name string, check func(n *Network) error) Step {
+	return Step{name, check}
+}
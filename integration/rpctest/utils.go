@@ -45,62 +45,67 @@ func JoinNodes(nodes []*Harness, joinType JoinType) error {
 
 // syncMempools blocks until all nodes have identical mempools.
 func syncMempools(nodes []*Harness) error {
-	poolsMatch := false
-
-retry:
-	for !poolsMatch {
-		firstPool, err := nodes[0].Node.GetRawMempool()
-		if err != nil {
+	for {
+		match, err := mempoolsMatch(nodes)
+		if err != nil || match {
 			return err
 		}
+		time.Sleep(time.Millisecond * 100)
+	}
+}
+
+// mempoolsMatch returns whether all nodes have an identical mempool.
+func mempoolsMatch(nodes []*Harness) (bool, error) {
+	firstPool, err := nodes[0].Node.GetRawMempool()
+	if err != nil {
+		return false, err
+	}
 
-		// If all nodes have an identical mempool with respect to the
-		// first node, then we're done. Otherwise, drop back to the top
-		// of the loop and retry after a short wait period.
-		for _, node := range nodes[1:] {
-			nodePool, err := node.Node.GetRawMempool()
-			if err != nil {
-				return err
-			}
-
-			if !reflect.DeepEqual(firstPool, nodePool) {
-				time.Sleep(time.Millisecond * 100)
-				continue retry
-			}
+	// If all nodes have an identical mempool with respect to the first
+	// node, then we're done.
+	for _, node := range nodes[1:] {
+		nodePool, err := node.Node.GetRawMempool()
+		if err != nil {
+			return false, err
 		}
 
-		poolsMatch = true
+		if !reflect.DeepEqual(firstPool, nodePool) {
+			return false, nil
+		}
 	}
 
-	return nil
+	return true, nil
 }
 
 // syncBlocks blocks until all nodes report the same best chain.
 func syncBlocks(nodes []*Harness) error {
-	blocksMatch := false
-
-retry:
-	for !blocksMatch {
-		var prevHash *chainhash.Hash
-		var prevHeight int32
-		for _, node := range nodes {
-			blockHash, blockHeight, err := node.Node.GetBestBlock()
-			if err != nil {
-				return err
-			}
-			if prevHash != nil && (*blockHash != *prevHash ||
-				blockHeight != prevHeight) {
-
-				time.Sleep(time.Millisecond * 100)
-				continue retry
-			}
-			prevHash, prevHeight = blockHash, blockHeight
+	for {
+		match, err := blocksMatch(nodes)
+		if err != nil || match {
+			return err
+		}
+		time.Sleep(time.Millisecond * 100)
+	}
+}
+
+// blocksMatch returns whether all nodes report the same best chain.
+func blocksMatch(nodes []*Harness) (bool, error) {
+	var prevHash *chainhash.Hash
+	var prevHeight int32
+	for _, node := range nodes {
+		blockHash, blockHeight, err := node.Node.GetBestBlock()
+		if err != nil {
+			return false, err
 		}
+		if prevHash != nil && (*blockHash != *prevHash ||
+			blockHeight != prevHeight) {
 
-		blocksMatch = true
+			return false, nil
+		}
+		prevHash, prevHeight = blockHash, blockHeight
 	}
 
-	return nil
+	return true, nil
 }
 
 // ConnectNode establishes a new peer-to-peer connection between the "from"
@@ -108,13 +113,18 @@ retry:
 // therefore in the case of disconnects, "from" will attempt to reestablish a
 // connection to the "to" harness.
 func ConnectNode(from *Harness, to *Harness) error {
+	return connectNodeAddr(from, to.node.config.listen)
+}
+
+// connectNodeAddr establishes a new persistent peer-to-peer connection from
+// the harness to the passed address and blocks until it is established.
+func connectNodeAddr(from *Harness, targetAddr string) error {
 	peerInfo, err := from.Node.GetPeerInfo()
 	if err != nil {
 		return err
 	}
 	numPeers := len(peerInfo)
 
-	targetAddr := to.node.config.listen
 	if err := from.Node.AddNode(targetAddr, rpcclient.ANAdd); err != nil {
 		return err
 	}
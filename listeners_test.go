@@ -0,0 +1,140 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/pkt-cash/pktd/addrmgr"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/connmgr"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// loopbackInterface returns the name of a loopback network interface with an
+// IPv4 address, or an empty string when there is none.
+func loopbackInterface() string {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return ""
+	}
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagLoopback == 0 {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.To4() != nil {
+				return iface.Name
+			}
+		}
+	}
+	return ""
+}
+
+// TestRuntimeListeners ensures listeners can be added at IP addresses and
+// network interfaces, and removed, while the server runs.
+func TestRuntimeListeners(t *testing.T) {
+	// The log rotator is not initialized in tests.
+	setLogLevels("off")
+	defer setLogLevels(defaultLogLevel)
+	defer func(c *config) { cfg = c }(cfg)
+	cfg = &config{NoListenIPv6: true}
+
+	dir, err := ioutil.TempDir("", "pktd-listeners")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	accepted := make(chan net.Conn, 1)
+	cm, err := connmgr.New(&connmgr.Config{
+		OnAccept: func(conn net.Conn) { accepted <- conn },
+		Dial: func(net.Addr) (net.Conn, error) {
+			return nil, errors.New("no outbound connections")
+		},
+		TargetOutbound: 1,
+	})
+	if err != nil {
+		t.Fatalf("connmgr.New: %v", err)
+	}
+	cm.Start()
+	defer cm.Stop()
+	s := &server{
+		chainParams:  &chaincfg.RegressionNetParams,
+		addrManager:  addrmgr.New(dir, nil),
+		connManager:  cm,
+		services:     defaultServices,
+		externalNets: make(map[string]struct{}),
+	}
+
+	// Connections are accepted on an added listener.
+	listeners, err := s.AddListener("127.0.0.1:0")
+	if err != nil || len(listeners) != 1 {
+		t.Fatalf("AddListener: got %v (%v)", listeners, err)
+	}
+	bound := listeners[0].Addr().String()
+	conn, err := net.Dial("tcp", bound)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	conn.Close()
+	select {
+	case conn := <-accepted:
+		conn.Close()
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for the connection")
+	}
+	if _, err := s.AddListener("127.0.0.1:0"); err == nil {
+		t.Fatal("AddListener accepted a duplicate listen address")
+	}
+
+	// The listeners of a network interface only use the enabled families.
+	if name := loopbackInterface(); name != "" {
+		listeners, err := s.AddListener(name + ":0")
+		if err != nil || len(listeners) == 0 {
+			t.Fatalf("AddListener: got %v (%v)", listeners, err)
+		}
+		for _, l := range listeners {
+			if l.Addr().(*net.TCPAddr).IP.To4() == nil {
+				t.Fatalf("listening on IPv6 address %v", l.Addr())
+			}
+		}
+		if _, err := s.RemoveListener(name + ":0"); err != nil {
+			t.Fatalf("RemoveListener: %v", err)
+		}
+	}
+	if _, err := s.AddListener("nosuchinterface0"); err == nil {
+		t.Fatal("AddListener accepted an unknown interface")
+	}
+
+	// A listener can be removed by its bound address.
+	na := wire.NewNetAddressIPPort(net.ParseIP("127.0.0.1"),
+		uint16(listeners[0].Addr().(*net.TCPAddr).Port), 0)
+	if !listenerBinds(listeners[0], na) {
+		t.Fatalf("listener not bound to %v", na.IP)
+	}
+	removed, err := s.RemoveListener(bound)
+	if err != nil || len(removed) != 1 {
+		t.Fatalf("RemoveListener: got %v (%v)", removed, err)
+	}
+	if got := cm.Listeners(); len(got) != 0 {
+		t.Fatalf("unexpected listeners %v", got)
+	}
+	if _, err := s.RemoveListener(bound); err == nil {
+		t.Fatal("RemoveListener removed a removed listener")
+	}
+	if _, err := net.Dial("tcp", bound); err == nil {
+		t.Fatal("connected to a removed listener")
+	}
+}
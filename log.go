@@ -19,6 +19,7 @@ import (
 	"github.com/pkt-cash/pktd/blockchain/packetcrypt/block/proof"
 	"github.com/pkt-cash/pktd/connmgr"
 	"github.com/pkt-cash/pktd/database"
+	"github.com/pkt-cash/pktd/hooks"
 	"github.com/pkt-cash/pktd/mempool"
 	"github.com/pkt-cash/pktd/mining"
 	"github.com/pkt-cash/pktd/mining/cpuminer"
@@ -65,6 +66,8 @@ var (
 	pktdLog = backendLog.Logger("BTCD")
 	chanLog = backendLog.Logger("CHAN")
 	discLog = backendLog.Logger("DISC")
+	explLog = backendLog.Logger("EXPL")
+	hookLog = backendLog.Logger("HOOK")
 	indxLog = backendLog.Logger("INDX")
 	minrLog = backendLog.Logger("MINR")
 	peerLog = backendLog.Logger("PEER")
@@ -89,6 +92,7 @@ func init() {
 	txscript.UseLogger(scrpLog)
 	netsync.UseLogger(syncLog)
 	mempool.UseLogger(txmpLog)
+	hooks.UseLogger(hookLog)
 
 	packetcrypt.UseLogger(pcptLog)
 	block.UseLogger(pcptLog)
@@ -104,6 +108,8 @@ var subsystemLoggers = map[string]btclog.Logger{
 	"BTCD": pktdLog,
 	"CHAN": chanLog,
 	"DISC": discLog,
+	"EXPL": explLog,
+	"HOOK": hookLog,
 	"INDX": indxLog,
 	"MINR": minrLog,
 	"PEER": peerLog,
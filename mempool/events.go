@@ -0,0 +1,195 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mempool
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+)
+
+// DefaultMaxTxEvents is the default number of transaction additions and
+// removals remembered by the mempool.
+const DefaultMaxTxEvents = 10000
+
+// RemovalReason describes why a transaction was removed from the mempool.
+type RemovalReason uint8
+
+const (
+	// RemovalMined is used for a transaction included in a block connected
+	// to the main chain.
+	RemovalMined RemovalReason = iota
+
+	// RemovalConflict is used for a transaction spending an output also
+	// spent by a transaction of a block connected to the main chain, or
+	// depending on such a transaction.
+	RemovalConflict
+
+	// RemovalReplaced is used for a transaction replaced by another one
+	// paying a higher fee, or depending on such a transaction.
+	RemovalReplaced
+
+	// RemovalReorg is used for a transaction no longer valid after a block
+	// was disconnected from the main chain.
+	RemovalReorg
+
+	// RemovalEvicted is used for a transaction removed for any other
+	// reason.
+	RemovalEvicted
+)
+
+// removalReasonStrings maps the removal reasons to their names.
+var removalReasonStrings = map[RemovalReason]string{
+	RemovalMined:    "mined",
+	RemovalConflict: "conflict",
+	RemovalReplaced: "replaced",
+	RemovalReorg:    "reorg",
+	RemovalEvicted:  "evicted",
+}
+
+// String returns the name of the removal reason.
+func (r RemovalReason) String() string {
+	if s, ok := removalReasonStrings[r]; ok {
+		return s
+	}
+	return fmt.Sprintf("Unknown RemovalReason (%d)", uint8(r))
+}
+
+// txEvent is the addition of a transaction to the mempool or its removal.
+type txEvent struct {
+	hash    chainhash.Hash
+	removed bool
+	reason  RemovalReason
+}
+
+// txEventLog is a ring buffer of the most recent transaction events of the
+// mempool, numbered by increasing sequence numbers.  The sequence numbers
+// start from the time the log was created in microseconds, so the ones held
+// by clients are not mistaken for recent ones after a restart.  It is not safe
+// for concurrent access, the mempool lock protects it.
+type txEventLog struct {
+	events []txEvent
+
+	// first is the sequence number preceding the first event recorded,
+	// and last the sequence number of the last one.
+	first uint64
+	last  uint64
+}
+
+// newTxEventLog returns an event log which remembers up to max events.
+func newTxEventLog(max int) *txEventLog {
+	if max <= 0 {
+		max = DefaultMaxTxEvents
+	}
+	seq := uint64(time.Now().UnixNano() / int64(time.Microsecond))
+	return &txEventLog{
+		events: make([]txEvent, max),
+		first:  seq,
+		last:   seq,
+	}
+}
+
+// add records the passed event.
+func (l *txEventLog) add(e txEvent) {
+	l.last++
+	l.events[l.last%uint64(len(l.events))] = e
+}
+
+// since returns the events which followed the passed sequence number, oldest
+// first.  It returns false when the events were evicted from the log, or the
+// sequence number was not issued by it.
+func (l *txEventLog) since(seq uint64) ([]txEvent, bool) {
+	if seq < l.first || seq > l.last ||
+		l.last-seq > uint64(len(l.events)) {
+
+		return nil, false
+	}
+	events := make([]txEvent, 0, l.last-seq)
+	for s := seq + 1; s <= l.last; s++ {
+		events = append(events, l.events[s%uint64(len(l.events))])
+	}
+	return events, true
+}
+
+// RemovedTx describes a transaction removed from the mempool.
+type RemovedTx struct {
+	Hash   chainhash.Hash
+	Reason RemovalReason
+}
+
+// TxPoolDelta describes how the mempool changed since a sequence number.
+type TxPoolDelta struct {
+	// Sequence is the sequence number of the current state of the
+	// mempool, to pass to Delta to get the next changes.
+	Sequence uint64
+
+	// Reset is whether the changes since the requested sequence number
+	// are not known, in which case Added holds every transaction of the
+	// mempool.
+	Reset bool
+
+	// Added are the transactions which were added to the mempool and are
+	// still in it.
+	Added []chainhash.Hash
+
+	// Removed are the transactions which were in the mempool and were
+	// removed from it, with the reason of their last removal.
+	Removed []RemovedTx
+}
+
+// Delta returns how the mempool changed since the state with the passed
+// sequence number, as returned by a previous call.  The changes are netted
+// out: a transaction both added and removed since then is not reported, so
+// applying the removals then the additions to the earlier state gives the
+// current one.  When the sequence number is zero, or when too many events
+// occurred since it to be remembered, the whole mempool is returned instead.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) Delta(since uint64) *TxPoolDelta {
+	mp.mtx.RLock()
+	defer mp.mtx.RUnlock()
+
+	delta := &TxPoolDelta{Sequence: mp.txEvents.last}
+	var events []txEvent
+	var ok bool
+	if since != 0 {
+		events, ok = mp.txEvents.since(since)
+	}
+	if !ok {
+		delta.Reset = true
+		delta.Added = make([]chainhash.Hash, 0, len(mp.pool))
+		for hash := range mp.pool {
+			delta.Added = append(delta.Added, hash)
+		}
+		return delta
+	}
+
+	// A transaction was in the mempool before the events when its first
+	// event is a removal, and is in it after them when its last event is
+	// an addition.
+	firstRemoved := make(map[chainhash.Hash]bool)
+	lastEvent := make(map[chainhash.Hash]int)
+	for i, e := range events {
+		if _, ok := firstRemoved[e.hash]; !ok {
+			firstRemoved[e.hash] = e.removed
+		}
+		lastEvent[e.hash] = i
+	}
+	for i, e := range events {
+		if lastEvent[e.hash] != i || firstRemoved[e.hash] != e.removed {
+			continue
+		}
+		if e.removed {
+			delta.Removed = append(delta.Removed, RemovedTx{
+				Hash:   e.hash,
+				Reason: e.reason,
+			})
+		} else {
+			delta.Added = append(delta.Added, e.hash)
+		}
+	}
+	return delta
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mempool
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+)
+
+// TestDelta ensures the changes of the mempool since a sequence number are
+// netted out, and that the whole mempool is returned when they are unknown.
+func TestDelta(t *testing.T) {
+	t.Parallel()
+
+	harness, outputs, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	txPool := harness.txPool
+	chainedTxns, err := harness.CreateTxChain(outputs[0], 3)
+	if err != nil {
+		t.Fatalf("unable to create transaction chain: %v", err)
+	}
+	hashes := make([]chainhash.Hash, len(chainedTxns))
+	for i, tx := range chainedTxns {
+		hashes[i] = *tx.Hash()
+	}
+
+	start := txPool.Delta(0)
+	if !start.Reset || len(start.Added) != 0 || len(start.Removed) != 0 {
+		t.Fatalf("unexpected delta of the empty mempool %+v", start)
+	}
+	for _, tx := range chainedTxns {
+		_, err := txPool.ProcessTransaction(tx, false, false, 0)
+		if err != nil {
+			t.Fatalf("ProcessTransaction: %v", err)
+		}
+	}
+
+	added := txPool.Delta(start.Sequence)
+	if added.Reset || !reflect.DeepEqual(added.Added, hashes) ||
+		len(added.Removed) != 0 {
+
+		t.Fatalf("unexpected delta after adding %+v", added)
+	}
+	if added.Sequence != start.Sequence+3 {
+		t.Fatalf("sequence %d, want %d", added.Sequence,
+			start.Sequence+3)
+	}
+	if all := txPool.Delta(0); !all.Reset || len(all.Added) != 3 {
+		t.Fatalf("unexpected delta of the whole mempool %+v", all)
+	}
+
+	// The redeemers are removed before the transactions they spend.
+	txPool.RemoveTransaction(chainedTxns[0], false, RemovalMined)
+	txPool.RemoveTransaction(chainedTxns[1], true, RemovalConflict)
+	removed := txPool.Delta(added.Sequence)
+	wantRemoved := []RemovedTx{
+		{Hash: hashes[0], Reason: RemovalMined},
+		{Hash: hashes[2], Reason: RemovalConflict},
+		{Hash: hashes[1], Reason: RemovalConflict},
+	}
+	if removed.Reset || len(removed.Added) != 0 ||
+		!reflect.DeepEqual(removed.Removed, wantRemoved) {
+
+		t.Fatalf("unexpected delta after removing %+v", removed)
+	}
+
+	// The transactions both added and removed are left out.
+	netted := txPool.Delta(start.Sequence)
+	if netted.Reset || len(netted.Added) != 0 || len(netted.Removed) != 0 {
+		t.Fatalf("unexpected netted delta %+v", netted)
+	}
+
+	// Sequence numbers which were not issued reset the client.
+	for _, seq := range []uint64{start.Sequence - 1, removed.Sequence + 1} {
+		if d := txPool.Delta(seq); !d.Reset {
+			t.Errorf("Delta(%d) did not reset: %+v", seq, d)
+		}
+	}
+
+	// The events evicted from the log reset the client too.
+	eventLog := newTxEventLog(2)
+	for _, hash := range hashes {
+		eventLog.add(txEvent{hash: hash})
+	}
+	if _, ok := eventLog.since(eventLog.first); ok {
+		t.Errorf("since returned evicted events")
+	}
+	events, ok := eventLog.since(eventLog.first + 1)
+	if !ok || len(events) != 2 || events[0].hash != hashes[1] ||
+		events[1].hash != hashes[2] {
+
+		t.Errorf("unexpected events %+v", events)
+	}
+}
@@ -89,6 +89,11 @@ type Config struct {
 	// into the mempool or not.
 	IsDeploymentActive func(deploymentID uint32) (bool, error)
 
+	// ScriptFlags returns the script flags the consensus rules enforce for
+	// the transactions of the next block.  The scripts of the transactions
+	// are checked with them along with txscript.PolicyVerifyFlags.
+	ScriptFlags func() (txscript.ScriptFlags, error)
+
 	// SigCache defines a signature cache to use.
 	SigCache *txscript.SigCache
 
@@ -103,6 +108,18 @@ type Config struct {
 	// FeeEstimatator provides a feeEstimator. If it is not nil, the mempool
 	// records all new transactions it observes into the feeEstimator.
 	FeeEstimator *FeeEstimator
+
+	// PolicyHook, when not nil, is called with every transaction about to
+	// be accepted, once it passed all the other checks, and its fee.
+	// Returning an error rejects the transaction as non-standard, which
+	// lets operators implement their own relay policy.  It is called with
+	// the mempool locked.
+	PolicyHook func(tx *btcutil.Tx, fee int64) error
+
+	// MaxTxEvents is the number of transaction additions and removals
+	// remembered to describe how the mempool changed, see Delta.
+	// DefaultMaxTxEvents is used when it is zero.
+	MaxTxEvents int
 }
 
 // Policy houses the policy (configuration parameters) which is used to
@@ -148,6 +165,33 @@ type Policy struct {
 	// transactions using the Replace-By-Fee (RBF) signaling policy into
 	// the mempool.
 	RejectReplacement bool
+
+	// RejectDataCarrier, if true, rejects transactions carrying data in
+	// OP_RETURN outputs as non-standard.
+	RejectDataCarrier bool
+
+	// MaxDataCarrierSize is the maximum number of bytes of data a standard
+	// transaction may carry in its OP_RETURN outputs.
+	MaxDataCarrierSize int
+}
+
+// DataCarrierStats describes the transactions carrying data in OP_RETURN
+// outputs processed by the mempool, counted by the size class of their
+// payload.  See DataCarrierSizeClasses.
+type DataCarrierStats struct {
+	// Accepted is the number of transactions accepted into the mempool.
+	Accepted [NumDataCarrierSizeClasses]uint64
+
+	// Pooled is the number of transactions currently in the mempool.
+	Pooled [NumDataCarrierSizeClasses]int
+
+	// PooledBytes is the total payload of the transactions currently in
+	// the mempool.
+	PooledBytes int64
+
+	// Rejected is the number of transactions rejected by the data carrier
+	// policy.
+	Rejected uint64
 }
 
 // TxDesc is a descriptor containing a transaction in the mempool along with
@@ -158,6 +202,10 @@ type TxDesc struct {
 	// StartingPriority is the priority of the transaction when it was added
 	// to the pool.
 	StartingPriority float64
+
+	// sighting records when the transaction was first seen and the peers
+	// which announced it.  It is protected by the mempool lock.
+	sighting *txSighting
 }
 
 // orphanTx is normal transaction that references an ancestor transaction
@@ -185,6 +233,20 @@ type TxPool struct {
 	pennyTotal    float64 // exponentially decaying total for penny spends.
 	lastPennyUnix int64   // unix time of last ``penny spend''
 
+	// dataCarriersAccepted and dataCarriersRejected count the transactions
+	// carrying data accepted by size class and rejected by the policy.
+	dataCarriersAccepted [NumDataCarrierSizeClasses]uint64
+	dataCarriersRejected uint64
+
+	// txEvents records the transactions added to and removed from the
+	// pool.
+	txEvents *txEventLog
+
+	// sightings records the announcements of the transactions which are
+	// not in the pool, and sightingsOrder their hashes oldest first.
+	sightings      map[chainhash.Hash]*txSighting
+	sightingsOrder []chainhash.Hash
+
 	// nextExpireScan is the time after which the orphan pool will be
 	// scanned in order to evict orphans.  This is NOT a hard deadline as
 	// the scan will only run when an orphan is added to the pool as opposed
@@ -466,14 +528,16 @@ func (mp *TxPool) HaveTransaction(hash *chainhash.Hash) bool {
 // RemoveTransaction.  See the comment for RemoveTransaction for more details.
 //
 // This function MUST be called with the mempool lock held (for writes).
-func (mp *TxPool) removeTransaction(tx *btcutil.Tx, removeRedeemers bool) {
+func (mp *TxPool) removeTransaction(tx *btcutil.Tx, removeRedeemers bool,
+	reason RemovalReason) {
+
 	txHash := tx.Hash()
 	if removeRedeemers {
 		// Remove any transactions which rely on this one.
 		for i := uint32(0); i < uint32(len(tx.MsgTx().TxOut)); i++ {
 			prevOut := wire.OutPoint{Hash: *txHash, Index: i}
 			if txRedeemer, exists := mp.outpoints[prevOut]; exists {
-				mp.removeTransaction(txRedeemer, true)
+				mp.removeTransaction(txRedeemer, true, reason)
 			}
 		}
 	}
@@ -491,6 +555,8 @@ func (mp *TxPool) removeTransaction(tx *btcutil.Tx, removeRedeemers bool) {
 			delete(mp.outpoints, txIn.PreviousOutPoint)
 		}
 		delete(mp.pool, *txHash)
+		mp.txEvents.add(txEvent{hash: *txHash, removed: true,
+			reason: reason})
 		atomic.StoreInt64(&mp.lastUpdated, time.Now().Unix())
 	}
 }
@@ -498,13 +564,16 @@ func (mp *TxPool) removeTransaction(tx *btcutil.Tx, removeRedeemers bool) {
 // RemoveTransaction removes the passed transaction from the mempool. When the
 // removeRedeemers flag is set, any transactions that redeem outputs from the
 // removed transaction will also be removed recursively from the mempool, as
-// they would otherwise become orphans.
+// they would otherwise become orphans.  The removals are reported by Delta
+// with the passed reason.
 //
 // This function is safe for concurrent access.
-func (mp *TxPool) RemoveTransaction(tx *btcutil.Tx, removeRedeemers bool) {
+func (mp *TxPool) RemoveTransaction(tx *btcutil.Tx, removeRedeemers bool,
+	reason RemovalReason) {
+
 	// Protect concurrent access.
 	mp.mtx.Lock()
-	mp.removeTransaction(tx, removeRedeemers)
+	mp.removeTransaction(tx, removeRedeemers, reason)
 	mp.mtx.Unlock()
 }
 
@@ -521,7 +590,8 @@ func (mp *TxPool) RemoveDoubleSpends(tx *btcutil.Tx) {
 	for _, txIn := range tx.MsgTx().TxIn {
 		if txRedeemer, ok := mp.outpoints[txIn.PreviousOutPoint]; ok {
 			if !txRedeemer.Hash().IsEqual(tx.Hash()) {
-				mp.removeTransaction(txRedeemer, true)
+				mp.removeTransaction(txRedeemer, true,
+					RemovalConflict)
 			}
 		}
 	}
@@ -536,6 +606,7 @@ func (mp *TxPool) RemoveDoubleSpends(tx *btcutil.Tx) {
 func (mp *TxPool) addTransaction(utxoView *blockchain.UtxoViewpoint, tx *btcutil.Tx, height int32, fee int64) *TxDesc {
 	// Add the transaction to the pool and mark the referenced outpoints
 	// as spent by the pool.
+	sighting := mp.takeSighting(tx.Hash())
 	txD := &TxDesc{
 		TxDesc: mining.TxDesc{
 			Tx:       tx,
@@ -543,14 +614,20 @@ func (mp *TxPool) addTransaction(utxoView *blockchain.UtxoViewpoint, tx *btcutil
 			Height:   height,
 			Fee:      fee,
 			FeePerKB: fee * 1000 / GetTxVirtualSize(tx),
+			Local:    sighting.submitted,
 		},
 		StartingPriority: mining.CalcPriority(tx.MsgTx(), utxoView, height),
+		sighting:         sighting,
 	}
 
 	mp.pool[*tx.Hash()] = txD
+	mp.txEvents.add(txEvent{hash: *tx.Hash()})
 	for _, txIn := range tx.MsgTx().TxIn {
 		mp.outpoints[txIn.PreviousOutPoint] = tx
 	}
+	if size, ok := dataCarrierPayload(tx.MsgTx()); ok {
+		mp.dataCarriersAccepted[dataCarrierSizeClass(size)]++
+	}
 	atomic.StoreInt64(&mp.lastUpdated, time.Now().Unix())
 
 	// Add unconfirmed address index entries associated with the transaction
@@ -825,6 +902,24 @@ func (mp *TxPool) FetchTransaction(txHash *chainhash.Hash) (*btcutil.Tx, error)
 	return nil, fmt.Errorf("transaction is not in the pool")
 }
 
+// FetchTxDesc returns the descriptor of the requested transaction from the
+// transaction pool.  This only fetches from the main transaction pool and does
+// not include orphans.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) FetchTxDesc(txHash *chainhash.Hash) (*TxDesc, error) {
+	// Protect concurrent access.
+	mp.mtx.RLock()
+	txDesc, exists := mp.pool[*txHash]
+	mp.mtx.RUnlock()
+
+	if exists {
+		return txDesc, nil
+	}
+
+	return nil, fmt.Errorf("transaction is not in the pool")
+}
+
 // validateReplacement determines whether a transaction is deemed as a valid
 // replacement of all of its conflicts according to the RBF policy. If it is
 // valid, no error is returned. Otherwise, an error is returned indicating what
@@ -924,7 +1019,7 @@ func (mp *TxPool) validateReplacement(tx *btcutil.Tx,
 // more details.
 //
 // This function MUST be called with the mempool lock held (for writes).
-func (mp *TxPool) maybeAcceptTransaction(tx *btcutil.Tx, isNew, rateLimit, rejectDupOrphans bool) ([]*chainhash.Hash, *TxDesc, error) {
+func (mp *TxPool) maybeAcceptTransaction(tx *btcutil.Tx, isNew, rateLimit, rejectDupOrphans, acceptNonStd bool) ([]*chainhash.Hash, *TxDesc, error) {
 	txHash := tx.Hash()
 
 	// If a transaction has iwtness data, and segwit isn't active yet, If
@@ -981,8 +1076,8 @@ func (mp *TxPool) maybeAcceptTransaction(tx *btcutil.Tx, isNew, rateLimit, rejec
 	medianTimePast := mp.cfg.MedianTimePast()
 
 	// Don't allow non-standard transactions if the network parameters
-	// forbid their acceptance.
-	if !mp.cfg.Policy.AcceptNonStd {
+	// forbid their acceptance, unless the caller allows them.
+	if !mp.cfg.Policy.AcceptNonStd && !acceptNonStd {
 		err = checkTransactionStandard(tx, nextBlockHeight,
 			medianTimePast, mp.cfg.Policy.MinRelayTxFee,
 			mp.cfg.Policy.MaxTxVersion)
@@ -998,6 +1093,12 @@ func (mp *TxPool) maybeAcceptTransaction(tx *btcutil.Tx, isNew, rateLimit, rejec
 				txHash, err)
 			return nil, nil, txRuleError(rejectCode, str)
 		}
+		if err := checkDataCarrier(tx, &mp.cfg.Policy); err != nil {
+			mp.dataCarriersRejected++
+			str := fmt.Sprintf("transaction %v is not standard: %v",
+				txHash, err)
+			return nil, nil, txRuleError(wire.RejectNonstandard, str)
+		}
 	}
 
 	// The transaction may not use any of the same outputs as other
@@ -1088,8 +1189,8 @@ func (mp *TxPool) maybeAcceptTransaction(tx *btcutil.Tx, isNew, rateLimit, rejec
 	}
 
 	// Don't allow transactions with non-standard inputs if the network
-	// parameters forbid their acceptance.
-	if !mp.cfg.Policy.AcceptNonStd {
+	// parameters forbid their acceptance, unless the caller allows them.
+	if !mp.cfg.Policy.AcceptNonStd && !acceptNonStd {
 		err := checkInputsStandard(tx, utxoView)
 		if err != nil {
 			// Attempt to extract a reject code from the error so
@@ -1199,9 +1300,14 @@ func (mp *TxPool) maybeAcceptTransaction(tx *btcutil.Tx, isNew, rateLimit, rejec
 	}
 
 	// Verify crypto signatures for each input and reject the transaction if
-	// any don't verify.
+	// any don't verify.  The rules of the deployments are only enforced
+	// once they are active, as in the blocks.
+	scriptFlags, err := mp.cfg.ScriptFlags()
+	if err != nil {
+		return nil, nil, err
+	}
 	err = blockchain.ValidateTransactionScripts(tx, utxoView,
-		txscript.StandardVerifyFlags, mp.cfg.SigCache,
+		scriptFlags|txscript.PolicyVerifyFlags, mp.cfg.SigCache,
 		mp.cfg.HashCache)
 	if err != nil {
 		if cerr, ok := err.(blockchain.RuleError); ok {
@@ -1210,6 +1316,16 @@ func (mp *TxPool) maybeAcceptTransaction(tx *btcutil.Tx, isNew, rateLimit, rejec
 		return nil, nil, err
 	}
 
+	// Give the policy hook the last word on whether the transaction is
+	// accepted.
+	if mp.cfg.PolicyHook != nil {
+		if err := mp.cfg.PolicyHook(tx, txFee); err != nil {
+			str := fmt.Sprintf("transaction %v rejected by policy "+
+				"hook: %v", txHash, err)
+			return nil, nil, txRuleError(wire.RejectNonstandard, str)
+		}
+	}
+
 	// Now that we've deemed the transaction as valid, we can add it to the
 	// mempool. If it ended up replacing any transactions, we'll remove them
 	// first.
@@ -1222,7 +1338,7 @@ func (mp *TxPool) maybeAcceptTransaction(tx *btcutil.Tx, isNew, rateLimit, rejec
 		// The conflict set should already include the descendants for
 		// each one, so we don't need to remove the redeemers within
 		// this call as they'll be removed eventually.
-		mp.removeTransaction(conflict, false)
+		mp.removeTransaction(conflict, false, RemovalReplaced)
 	}
 	txD := mp.addTransaction(utxoView, tx, bestHeight, txFee)
 
@@ -1246,7 +1362,8 @@ func (mp *TxPool) maybeAcceptTransaction(tx *btcutil.Tx, isNew, rateLimit, rejec
 func (mp *TxPool) MaybeAcceptTransaction(tx *btcutil.Tx, isNew, rateLimit bool) ([]*chainhash.Hash, *TxDesc, error) {
 	// Protect concurrent access.
 	mp.mtx.Lock()
-	hashes, txD, err := mp.maybeAcceptTransaction(tx, isNew, rateLimit, true)
+	hashes, txD, err := mp.maybeAcceptTransaction(tx, isNew, rateLimit, true,
+		false)
 	mp.mtx.Unlock()
 
 	return hashes, txD, err
@@ -1289,7 +1406,7 @@ func (mp *TxPool) processOrphans(acceptedTx *btcutil.Tx) []*TxDesc {
 			// Potentially accept an orphan into the tx pool.
 			for _, tx := range orphans {
 				missing, txD, err := mp.maybeAcceptTransaction(
-					tx, true, true, false)
+					tx, true, true, false, false)
 				if err != nil {
 					// The orphan is now invalid, so there
 					// is no way any other orphans which
@@ -1365,6 +1482,25 @@ func (mp *TxPool) ProcessOrphans(acceptedTx *btcutil.Tx) []*TxDesc {
 //
 // This function is safe for concurrent access.
 func (mp *TxPool) ProcessTransaction(tx *btcutil.Tx, allowOrphan, rateLimit bool, tag Tag) ([]*TxDesc, error) {
+	return mp.processTransaction(tx, allowOrphan, rateLimit, false, tag)
+}
+
+// ProcessNonStdTransaction is like ProcessTransaction but accepts the passed
+// transaction even when it is not standard and the policy forbids non-standard
+// transactions.  It is meant for the transactions relayed by trusted peers.
+// The orphans the transaction makes acceptable must still be standard.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) ProcessNonStdTransaction(tx *btcutil.Tx, allowOrphan, rateLimit bool, tag Tag) ([]*TxDesc, error) {
+	return mp.processTransaction(tx, allowOrphan, rateLimit, true, tag)
+}
+
+// processTransaction is the internal function which implements the public
+// ProcessTransaction and ProcessNonStdTransaction.  See the comment for
+// ProcessTransaction for more details.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) processTransaction(tx *btcutil.Tx, allowOrphan, rateLimit, acceptNonStd bool, tag Tag) ([]*TxDesc, error) {
 	log.Tracef("Processing transaction %v", tx.Hash())
 
 	// Protect concurrent access.
@@ -1373,7 +1509,7 @@ func (mp *TxPool) ProcessTransaction(tx *btcutil.Tx, allowOrphan, rateLimit bool
 
 	// Potentially accept the transaction to the memory pool.
 	missingParents, txD, err := mp.maybeAcceptTransaction(tx, true, rateLimit,
-		true)
+		true, acceptNonStd)
 	if err != nil {
 		return nil, err
 	}
@@ -1464,6 +1600,38 @@ func (mp *TxPool) TxDescs() []*TxDesc {
 	return descs
 }
 
+// OrphanTxDesc is a descriptor of a transaction in the orphan pool.
+type OrphanTxDesc struct {
+	Tx *btcutil.Tx
+
+	// Tag is the tag the orphan was added with, which is the ID of the
+	// peer that relayed it when it was relayed by a peer.
+	Tag Tag
+
+	// Expiration is when the orphan is removed from the pool if its
+	// missing parents are still unknown.
+	Expiration time.Time
+}
+
+// OrphanTxDescs returns a slice of descriptors for all the transactions in the
+// orphan pool.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) OrphanTxDescs() []*OrphanTxDesc {
+	mp.mtx.RLock()
+	descs := make([]*OrphanTxDesc, 0, len(mp.orphans))
+	for _, otx := range mp.orphans {
+		descs = append(descs, &OrphanTxDesc{
+			Tx:         otx.tx,
+			Tag:        otx.tag,
+			Expiration: otx.expiration,
+		})
+	}
+	mp.mtx.RUnlock()
+
+	return descs
+}
+
 // MiningDescs returns a slice of mining descriptors for all the transactions
 // in the pool.
 //
@@ -1482,6 +1650,29 @@ func (mp *TxPool) MiningDescs() []*mining.TxDesc {
 	return descs
 }
 
+// DataCarrierStats returns the statistics of the transactions carrying data in
+// OP_RETURN outputs processed by the mempool.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) DataCarrierStats() *DataCarrierStats {
+	mp.mtx.RLock()
+	defer mp.mtx.RUnlock()
+
+	stats := &DataCarrierStats{
+		Accepted: mp.dataCarriersAccepted,
+		Rejected: mp.dataCarriersRejected,
+	}
+	for _, desc := range mp.pool {
+		size, ok := dataCarrierPayload(desc.Tx.MsgTx())
+		if !ok {
+			continue
+		}
+		stats.Pooled[dataCarrierSizeClass(size)]++
+		stats.PooledBytes += int64(size)
+	}
+	return stats
+}
+
 // RawMempoolVerbose returns all of the entries in the mempool as a fully
 // populated btcjson result.
 //
@@ -1530,6 +1721,77 @@ func (mp *TxPool) RawMempoolVerbose() map[string]*btcjson.GetRawMempoolVerboseRe
 	return result
 }
 
+// MempoolEntry returns a data structure describing the transaction with the
+// passed hash in the pool, along with its unconfirmed ancestors and
+// descendants, and how it reached the node.  It is used by the getmempoolentry
+// RPC.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) MempoolEntry(hash *chainhash.Hash) (*btcjson.GetMempoolEntryResult, error) {
+	mp.mtx.RLock()
+	defer mp.mtx.RUnlock()
+
+	desc, ok := mp.pool[*hash]
+	if !ok {
+		return nil, fmt.Errorf("transaction is not in the pool")
+	}
+
+	// Calculate the current priority based on the inputs to the
+	// transaction.  Use zero if one or more of the input transactions
+	// can't be found for some reason.
+	tx := desc.Tx
+	var currentPriority float64
+	utxos, err := mp.fetchInputUtxos(tx)
+	if err == nil {
+		currentPriority = mining.CalcPriority(tx.MsgTx(), utxos,
+			mp.cfg.BestHeight()+1)
+	}
+
+	// The descendant and ancestor figures include the transaction itself,
+	// and their fees are in atoms.
+	size := GetTxVirtualSize(tx)
+	fee := btcutil.Amount(desc.Fee).ToBTC()
+	p := desc.sighting.provenance()
+	entry := &btcjson.GetMempoolEntryResult{
+		Size:             int32(size),
+		Fee:              fee,
+		ModifiedFee:      fee,
+		Time:             desc.Added.Unix(),
+		Height:           int64(desc.Height),
+		StartingPriority: desc.StartingPriority,
+		CurrentPriority:  currentPriority,
+		DescendantCount:  1,
+		DescendantSize:   size,
+		DescendantFees:   float64(desc.Fee),
+		AncestorCount:    1,
+		AncestorSize:     size,
+		AncestorFees:     float64(desc.Fee),
+		Depends:          make([]string, 0),
+		Provenance: &btcjson.TxProvenanceResult{
+			FirstSeen:  p.FirstSeen.Unix(),
+			Origin:     p.Origin.String(),
+			Announcers: int32(p.Announcers),
+		},
+	}
+	for _, descendant := range mp.txDescendants(tx, nil) {
+		entry.DescendantCount++
+		entry.DescendantSize += GetTxVirtualSize(descendant)
+		entry.DescendantFees += float64(mp.pool[*descendant.Hash()].Fee)
+	}
+	for _, ancestor := range mp.txAncestors(tx, nil) {
+		entry.AncestorCount++
+		entry.AncestorSize += GetTxVirtualSize(ancestor)
+		entry.AncestorFees += float64(mp.pool[*ancestor.Hash()].Fee)
+	}
+	for _, txIn := range tx.MsgTx().TxIn {
+		hash := &txIn.PreviousOutPoint.Hash
+		if mp.haveTransaction(hash) {
+			entry.Depends = append(entry.Depends, hash.String())
+		}
+	}
+	return entry, nil
+}
+
 // LastUpdated returns the last time a transaction was added to or removed from
 // the main pool.  It does not include the orphan pool.
 //
@@ -1548,5 +1810,7 @@ func New(cfg *Config) *TxPool {
 		orphansByPrev:  make(map[wire.OutPoint]map[chainhash.Hash]*btcutil.Tx),
 		nextExpireScan: time.Now().Add(orphanExpireScanInterval),
 		outpoints:      make(map[wire.OutPoint]*btcutil.Tx),
+		txEvents:       newTxEventLog(cfg.MaxTxEvents),
+		sightings:      make(map[chainhash.Hash]*txSighting),
 	}
 }
@@ -6,6 +6,7 @@ package mempool
 
 import (
 	"encoding/hex"
+	"errors"
 	"reflect"
 	"strings"
 	"sync"
@@ -29,6 +30,7 @@ type fakeChain struct {
 	utxos          *blockchain.UtxoViewpoint
 	currentHeight  int32
 	medianTimePast time.Time
+	scriptFlags    txscript.ScriptFlags
 }
 
 // FetchUtxoView loads utxo details about the inputs referenced by the passed
@@ -95,6 +97,23 @@ func (s *fakeChain) SetMedianTimePast(mtp time.Time) {
 	s.Unlock()
 }
 
+// ScriptFlags returns the script flags the consensus rules enforce for the
+// next block of the fake chain instance.
+func (s *fakeChain) ScriptFlags() (txscript.ScriptFlags, error) {
+	s.RLock()
+	flags := s.scriptFlags
+	s.RUnlock()
+	return flags, nil
+}
+
+// SetScriptFlags sets the script flags the consensus rules enforce for the
+// next block of the fake chain instance.
+func (s *fakeChain) SetScriptFlags(flags txscript.ScriptFlags) {
+	s.Lock()
+	s.scriptFlags = flags
+	s.Unlock()
+}
+
 // CalcSequenceLock returns the current sequence lock for the passed
 // transaction associated with the fake chain instance.
 func (s *fakeChain) CalcSequenceLock(tx *btcutil.Tx,
@@ -307,7 +326,10 @@ func newPoolHarness(chainParams *chaincfg.Params) (*poolHarness, []spendableOutp
 	}
 
 	// Create a new fake chain and harness bound to it.
-	chain := &fakeChain{utxos: blockchain.NewUtxoViewpoint()}
+	chain := &fakeChain{
+		utxos:       blockchain.NewUtxoViewpoint(),
+		scriptFlags: txscript.ConsensusVerifyFlags,
+	}
 	harness := poolHarness{
 		signKey:     signKey,
 		payAddr:     payAddr,
@@ -324,12 +346,14 @@ func newPoolHarness(chainParams *chaincfg.Params) (*poolHarness, []spendableOutp
 				MaxSigOpCostPerTx:    blockchain.MaxBlockSigOpsCost / 4,
 				MinRelayTxFee:        1000, // 1 Satoshi per byte
 				MaxTxVersion:         1,
+				MaxDataCarrierSize:   DefaultMaxDataCarrierSize,
 			},
 			ChainParams:      chainParams,
 			FetchUtxoView:    chain.FetchUtxoView,
 			BestHeight:       chain.BestHeight,
 			MedianTimePast:   chain.MedianTimePast,
 			CalcSequenceLock: chain.CalcSequenceLock,
+			ScriptFlags:      chain.ScriptFlags,
 			SigCache:         nil,
 			AddrIndex:        nil,
 		}),
@@ -494,6 +518,19 @@ func TestSimpleOrphanChain(t *testing.T) {
 		testPoolMembership(tc, tx, true, false)
 	}
 
+	// Ensure the orphans are all described by the orphan pool.
+	orphanDescs := harness.txPool.OrphanTxDescs()
+	if len(orphanDescs) != int(maxOrphans) {
+		t.Fatalf("OrphanTxDescs: got %d descriptors, want %d",
+			len(orphanDescs), maxOrphans)
+	}
+	for _, desc := range orphanDescs {
+		if !harness.txPool.IsOrphanInPool(desc.Tx.Hash()) {
+			t.Fatalf("OrphanTxDescs: %v is not an orphan",
+				desc.Tx.Hash())
+		}
+	}
+
 	// Add the transaction which completes the orphan chain and ensure they
 	// all get accepted.  Notice the accept orphans flag is also false here
 	// to ensure it has no bearing on whether or not already existing
@@ -931,6 +968,266 @@ func TestCheckSpend(t *testing.T) {
 	}
 }
 
+// TestProcessNonStdTransaction ensures non-standard transactions are only
+// accepted by ProcessNonStdTransaction, and that FetchTxDesc returns the
+// descriptors of the accepted transactions.
+func TestProcessNonStdTransaction(t *testing.T) {
+	t.Parallel()
+
+	harness, outputs, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	tc := &testContext{t, harness}
+
+	// Paying all but a satoshi of the output as fee makes the only output
+	// of the transaction dust.
+	tx, err := harness.CreateSignedTx(outputs[0:1], 1,
+		outputs[0].amount-1, false)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+
+	_, err = harness.txPool.ProcessTransaction(tx, false, false, 0)
+	if err == nil {
+		t.Fatal("ProcessTransaction: accepted non-standard transaction")
+	}
+	code, extracted := extractRejectCode(err)
+	if !extracted || code != wire.RejectDust {
+		t.Fatalf("ProcessTransaction: unexpected error %v", err)
+	}
+	testPoolMembership(tc, tx, false, false)
+	if _, err := harness.txPool.FetchTxDesc(tx.Hash()); err == nil {
+		t.Fatal("FetchTxDesc: returned a rejected transaction")
+	}
+
+	acceptedTxns, err := harness.txPool.ProcessNonStdTransaction(tx, false,
+		false, 0)
+	if err != nil {
+		t.Fatalf("ProcessNonStdTransaction: failed to accept tx: %v",
+			err)
+	}
+	if len(acceptedTxns) != 1 || acceptedTxns[0].Tx != tx {
+		t.Fatalf("ProcessNonStdTransaction: reported %d accepted "+
+			"transactions", len(acceptedTxns))
+	}
+	testPoolMembership(tc, tx, false, true)
+	txD, err := harness.txPool.FetchTxDesc(tx.Hash())
+	if err != nil || txD.Tx != tx {
+		t.Fatalf("FetchTxDesc: got %v (%v)", txD, err)
+	}
+}
+
+// TestPolicyHook ensures the policy hook is given the transactions about to be
+// accepted with their fee and that its refusal rejects them as non-standard.
+func TestPolicyHook(t *testing.T) {
+	t.Parallel()
+
+	harness, outputs, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	tc := &testContext{t, harness}
+
+	tx, err := harness.CreateSignedTx(outputs[0:1], 1, 1000, false)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	var hookFee int64
+	var hookErr error
+	harness.txPool.cfg.PolicyHook = func(hookTx *btcutil.Tx, fee int64) error {
+		if hookTx != tx {
+			t.Fatalf("policy hook called with transaction %v, "+
+				"want %v", hookTx.Hash(), tx.Hash())
+		}
+		hookFee = fee
+		return hookErr
+	}
+
+	hookErr = errors.New("not today")
+	_, err = harness.txPool.ProcessTransaction(tx, false, false, 0)
+	code, extracted := extractRejectCode(err)
+	if !extracted || code != wire.RejectNonstandard {
+		t.Fatalf("ProcessTransaction: unexpected error %v", err)
+	}
+	if hookFee != 1000 {
+		t.Fatalf("policy hook given a fee of %d, want 1000", hookFee)
+	}
+	testPoolMembership(tc, tx, false, false)
+
+	hookErr = nil
+	if _, err := harness.txPool.ProcessTransaction(tx, false, false, 0); err != nil {
+		t.Fatalf("ProcessTransaction: failed to accept tx: %v", err)
+	}
+	testPoolMembership(tc, tx, false, true)
+}
+
+// TestScriptFlagsDeployment ensures the scripts of the transactions are only
+// checked against the rules of a deployment once it is active.
+func TestScriptFlagsDeployment(t *testing.T) {
+	t.Parallel()
+
+	harness, _, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	tc := &testContext{t, harness}
+
+	// Fund a pay-to-script-hash output whose redeem script checks the lock
+	// time of the transaction.  CHECKLOCKTIMEVERIFY is an upgradable NOP
+	// until BIP0065 is active, which the policy discourages.
+	redeemScript, err := txscript.NewScriptBuilder().AddOp(txscript.OP_0).
+		AddOp(txscript.OP_CHECKLOCKTIMEVERIFY).AddOp(txscript.OP_DROP).
+		AddOp(txscript.OP_TRUE).Script()
+	if err != nil {
+		t.Fatalf("unable to create redeem script: %v", err)
+	}
+	scriptAddr, err := btcutil.NewAddressScriptHash(redeemScript,
+		harness.chainParams)
+	if err != nil {
+		t.Fatalf("unable to create script address: %v", err)
+	}
+	p2shScript, err := txscript.PayToAddrScript(scriptAddr)
+	if err != nil {
+		t.Fatalf("unable to create pay-to-script-hash script: %v", err)
+	}
+	fundTx := wire.NewMsgTx(wire.TxVersion)
+	fundTx.AddTxIn(&wire.TxIn{PreviousOutPoint: wire.OutPoint{Index: 1}})
+	fundTx.AddTxOut(wire.NewTxOut(100000000, p2shScript))
+	harness.chain.utxos.AddTxOuts(btcutil.NewTx(fundTx),
+		harness.chain.BestHeight())
+
+	sigScript, err := txscript.NewScriptBuilder().AddData(redeemScript).
+		Script()
+	if err != nil {
+		t.Fatalf("unable to create signature script: %v", err)
+	}
+	msgTx := wire.NewMsgTx(1)
+	msgTx.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Hash: fundTx.TxHash()},
+		SignatureScript:  sigScript,
+		Sequence:         0,
+	})
+	msgTx.AddTxOut(wire.NewTxOut(100000000-10000, harness.payScript))
+	tx := btcutil.NewTx(msgTx)
+
+	harness.chain.SetScriptFlags(txscript.ConsensusVerifyFlags &^
+		txscript.ScriptVerifyCheckLockTimeVerify)
+	if _, err := harness.txPool.ProcessTransaction(tx, false, false, 0); err == nil {
+		t.Fatal("ProcessTransaction: accepted transaction using " +
+			"CHECKLOCKTIMEVERIFY before BIP0065")
+	}
+	testPoolMembership(tc, tx, false, false)
+
+	harness.chain.SetScriptFlags(txscript.ConsensusVerifyFlags)
+	if _, err := harness.txPool.ProcessTransaction(tx, false, false, 0); err != nil {
+		t.Fatalf("ProcessTransaction: failed to accept tx: %v", err)
+	}
+	testPoolMembership(tc, tx, false, true)
+}
+
+// TestDataCarrierPolicy ensures transactions carrying data in OP_RETURN outputs
+// are accepted or rejected according to the data carrier policy and counted by
+// the size class of their payload.
+func TestDataCarrierPolicy(t *testing.T) {
+	t.Parallel()
+
+	harness, outputs, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	tc := &testContext{t, harness}
+
+	// createDataTx returns a transaction spending the passed output which
+	// carries a payload of the passed size in an OP_RETURN output.
+	createDataTx := func(output spendableOutput, size int) *btcutil.Tx {
+		t.Helper()
+
+		dataScript, err := txscript.NewScriptBuilder().
+			AddOp(txscript.OP_RETURN).AddFullData(make([]byte, size)).
+			Script()
+		if err != nil {
+			t.Fatalf("unable to create data script: %v", err)
+		}
+		if size == 0 {
+			dataScript = []byte{txscript.OP_RETURN}
+		}
+		tx := wire.NewMsgTx(wire.TxVersion)
+		tx.AddTxIn(&wire.TxIn{
+			PreviousOutPoint: output.outPoint,
+			Sequence:         wire.MaxTxInSequenceNum,
+		})
+		tx.AddTxOut(&wire.TxOut{
+			PkScript: harness.payScript,
+			Value:    int64(output.amount) - 10000,
+		})
+		tx.AddTxOut(&wire.TxOut{PkScript: dataScript})
+		sigScript, err := txscript.SignatureScript(tx, 0,
+			harness.payScript, txscript.SigHashAll, harness.signKey,
+			true)
+		if err != nil {
+			t.Fatalf("unable to sign transaction: %v", err)
+		}
+		tx.TxIn[0].SignatureScript = sigScript
+		return btcutil.NewTx(tx)
+	}
+
+	// Split the spendable output so each transaction carrying data spends
+	// its own.
+	splitTx, err := harness.CreateSignedTx(outputs, 3, 10000, false)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	if _, err := harness.txPool.ProcessTransaction(splitTx, false, false, 0); err != nil {
+		t.Fatalf("ProcessTransaction: failed to accept tx: %v", err)
+	}
+	outputs = []spendableOutput{
+		txOutToSpendableOut(splitTx, 0),
+		txOutToSpendableOut(splitTx, 1),
+		txOutToSpendableOut(splitTx, 2),
+	}
+
+	// A payload up to the default maximum size is accepted.
+	tx := createDataTx(outputs[0], DefaultMaxDataCarrierSize)
+	if _, err := harness.txPool.ProcessTransaction(tx, false, false, 0); err != nil {
+		t.Fatalf("ProcessTransaction: failed to accept tx: %v", err)
+	}
+	testPoolMembership(tc, tx, false, true)
+
+	// A larger payload is rejected until the maximum size is raised.
+	tx = createDataTx(outputs[1], 100)
+	_, err = harness.txPool.ProcessTransaction(tx, false, false, 0)
+	code, extracted := extractRejectCode(err)
+	if err == nil || !extracted || code != wire.RejectNonstandard {
+		t.Fatalf("ProcessTransaction: unexpected error %v", err)
+	}
+	testPoolMembership(tc, tx, false, false)
+	harness.txPool.cfg.Policy.MaxDataCarrierSize = 200
+	if _, err := harness.txPool.ProcessTransaction(tx, false, false, 0); err != nil {
+		t.Fatalf("ProcessTransaction: failed to accept tx: %v", err)
+	}
+
+	// No transaction carrying data is accepted when they are rejected,
+	// even without a payload.
+	harness.txPool.cfg.Policy.RejectDataCarrier = true
+	tx = createDataTx(outputs[2], 0)
+	if _, err := harness.txPool.ProcessTransaction(tx, false, false, 0); err == nil {
+		t.Fatal("ProcessTransaction: accepted transaction carrying data")
+	}
+
+	// The transactions are counted by the size class of their payload.
+	stats := harness.txPool.DataCarrierStats()
+	want := DataCarrierStats{
+		Accepted:    [NumDataCarrierSizeClasses]uint64{0, 0, 1, 1, 0},
+		Pooled:      [NumDataCarrierSizeClasses]int{0, 0, 1, 1, 0},
+		PooledBytes: DefaultMaxDataCarrierSize + 100,
+		Rejected:    2,
+	}
+	if *stats != want {
+		t.Fatalf("DataCarrierStats: got %+v, want %+v", *stats, want)
+	}
+}
+
 // TestSignalsReplacement tests that transactions properly signal they can be
 // replaced using RBF.
 func TestSignalsReplacement(t *testing.T) {
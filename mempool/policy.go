@@ -54,8 +54,68 @@ const (
 	// in a multi-signature transaction output script for it to be
 	// considered standard.
 	maxStandardMultiSigKeys = 3
+
+	// DefaultMaxDataCarrierSize is the default maximum number of bytes of
+	// data a transaction may carry in its OP_RETURN outputs to be relayed.
+	DefaultMaxDataCarrierSize = txscript.MaxDataCarrierSize
 )
 
+// DataCarrierSizeClasses are the upper bounds, in bytes, of the classes the
+// transactions carrying data in OP_RETURN outputs are counted in by the size
+// of their payload.  Larger payloads are counted in a final unbounded class.
+var DataCarrierSizeClasses = [...]int{0, 32, 80, 256}
+
+// NumDataCarrierSizeClasses is the number of data carrier size classes,
+// including the final unbounded one.
+const NumDataCarrierSizeClasses = len(DataCarrierSizeClasses) + 1
+
+// dataCarrierSizeClass returns the index of the size class of the passed data
+// carrier payload size.
+func dataCarrierSizeClass(size int) int {
+	for i, max := range DataCarrierSizeClasses {
+		if size <= max {
+			return i
+		}
+	}
+	return len(DataCarrierSizeClasses)
+}
+
+// dataCarrierPayload returns the total number of bytes of data carried by the
+// OP_RETURN outputs of the passed transaction and whether it has any.
+func dataCarrierPayload(msgTx *wire.MsgTx) (int, bool) {
+	total := 0
+	isDataCarrier := false
+	for _, txOut := range msgTx.TxOut {
+		size, ok := txscript.NullDataPayloadSize(txOut.PkScript)
+		if ok {
+			total += size
+			isDataCarrier = true
+		}
+	}
+	return total, isDataCarrier
+}
+
+// checkDataCarrier returns an error when the passed transaction carries data in
+// OP_RETURN outputs and the policy rejects them or the payload is larger than
+// the maximum allowed size.
+func checkDataCarrier(tx *btcutil.Tx, policy *Policy) error {
+	size, isDataCarrier := dataCarrierPayload(tx.MsgTx())
+	if !isDataCarrier {
+		return nil
+	}
+	if policy.RejectDataCarrier {
+		return txRuleError(wire.RejectNonstandard,
+			"transactions carrying data are not relayed")
+	}
+	if size > policy.MaxDataCarrierSize {
+		str := fmt.Sprintf("data carrier payload of %d bytes is larger "+
+			"than max allowed size of %d bytes", size,
+			policy.MaxDataCarrierSize)
+		return txRuleError(wire.RejectNonstandard, str)
+	}
+	return nil
+}
+
 // calcMinRequiredTxRelayFee returns the minimum transaction fee required for a
 // transaction with the passed serialized size to be accepted into the memory
 // pool and relayed.
@@ -333,6 +393,14 @@ func checkTransactionStandard(tx *btcutil.Tx, height int32,
 	// be "dust" (except when the script is a null data script).
 	numNullDataOutputs := 0
 	for i, txOut := range msgTx.TxOut {
+		// Outputs which only carry data are standard regardless of
+		// the size of their payload, which is limited by the data
+		// carrier policy instead.  See checkDataCarrier.
+		if _, ok := txscript.NullDataPayloadSize(txOut.PkScript); ok {
+			numNullDataOutputs++
+			continue
+		}
+
 		scriptClass := txscript.GetScriptClass(txOut.PkScript)
 		err := checkPkScriptStandard(txOut.PkScript, scriptClass)
 		if err != nil {
@@ -347,12 +415,8 @@ func checkTransactionStandard(tx *btcutil.Tx, height int32,
 			return txRuleError(rejectCode, str)
 		}
 
-		// Accumulate the number of outputs which only carry data.  For
-		// all other script types, ensure the output value is not
-		// "dust".
-		if scriptClass == txscript.NullDataTy {
-			numNullDataOutputs++
-		} else if isDust(txOut, minRelayTxFee) {
+		// Ensure the output value is not "dust".
+		if isDust(txOut, minRelayTxFee) {
 			str := fmt.Sprintf("transaction output %d: payment "+
 				"of %d is dust", i, txOut.Value)
 			return txRuleError(wire.RejectDust, str)
@@ -464,6 +464,23 @@ func TestCheckTransactionStandard(t *testing.T) {
 			height:     300000,
 			isStandard: true,
 		},
+		{
+			name: "One nulldata output larger than MaxDataCarrierSize " +
+				"(limited by the data carrier policy)",
+			tx: wire.MsgTx{
+				Version: 1,
+				TxIn:    []*wire.TxIn{&dummyTxIn},
+				TxOut: []*wire.TxOut{{
+					Value: 0,
+					PkScript: append([]byte{txscript.OP_RETURN,
+						txscript.OP_PUSHDATA1, 200},
+						make([]byte, 200)...),
+				}},
+				LockTime: 0,
+			},
+			height:     300000,
+			isStandard: true,
+		},
 	}
 
 	pastMedianTime := time.Now()
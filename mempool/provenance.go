@@ -0,0 +1,198 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mempool
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+)
+
+// maxTxSightings is the number of transactions not in the pool whose
+// announcements are remembered, so the provenance of a transaction includes the
+// announcements made before it was accepted.
+const maxTxSightings = 5000
+
+// TxOrigin is the kind of peer a transaction was first received from.
+type TxOrigin uint8
+
+const (
+	// OriginLocal is used for a transaction which was not received from a
+	// peer, such as one submitted with sendrawtransaction or returned to
+	// the pool from a disconnected block.
+	OriginLocal TxOrigin = iota
+
+	// OriginInbound is used for a transaction received from a peer which
+	// connected to the node.
+	OriginInbound
+
+	// OriginOutbound is used for a transaction received from a peer the
+	// node connected to.
+	OriginOutbound
+
+	// OriginWhitelisted is used for a transaction received from a peer
+	// granted permissions by the whitelist or whitebind options.
+	OriginWhitelisted
+)
+
+// txOriginStrings maps the transaction origins to their names.
+var txOriginStrings = map[TxOrigin]string{
+	OriginLocal:       "local",
+	OriginInbound:     "inbound",
+	OriginOutbound:    "outbound",
+	OriginWhitelisted: "whitelisted",
+}
+
+// String returns the name of the transaction origin.
+func (o TxOrigin) String() string {
+	if s, ok := txOriginStrings[o]; ok {
+		return s
+	}
+	return fmt.Sprintf("Unknown TxOrigin (%d)", uint8(o))
+}
+
+// TxProvenance describes when and how a transaction reached the node.
+type TxProvenance struct {
+	// FirstSeen is the time the transaction was first announced to the
+	// node or received by it.
+	FirstSeen time.Time
+
+	// Origin is the kind of peer the transaction was first received from.
+	Origin TxOrigin
+
+	// Announcers is the number of distinct peers which announced the
+	// transaction or relayed it.
+	Announcers int
+}
+
+// txSighting records the announcements of a transaction.
+type txSighting struct {
+	firstSeen time.Time
+	origin    TxOrigin
+	received  bool
+	submitted bool
+	peers     map[Tag]struct{}
+}
+
+// newTxSighting returns the sighting of a transaction first seen now.
+func newTxSighting() *txSighting {
+	return &txSighting{
+		firstSeen: time.Now(),
+		peers:     make(map[Tag]struct{}),
+	}
+}
+
+// provenance returns the provenance described by the sighting.
+func (s *txSighting) provenance() TxProvenance {
+	return TxProvenance{
+		FirstSeen:  s.firstSeen,
+		Origin:     s.origin,
+		Announcers: len(s.peers),
+	}
+}
+
+// sighting returns the sighting of the transaction with the passed hash,
+// recording a new one when it was not seen yet.  The sightings of the
+// transactions in the pool are kept with them, while the others are evicted
+// oldest first.
+//
+// This function MUST be called with the mempool lock held (for writes).
+func (mp *TxPool) sighting(hash *chainhash.Hash) *txSighting {
+	if desc, ok := mp.pool[*hash]; ok {
+		return desc.sighting
+	}
+	if s, ok := mp.sightings[*hash]; ok {
+		return s
+	}
+
+	if len(mp.sightingsOrder) >= maxTxSightings {
+		oldest := mp.sightingsOrder[0]
+		mp.sightingsOrder = mp.sightingsOrder[1:]
+		delete(mp.sightings, oldest)
+	}
+	s := newTxSighting()
+	mp.sightings[*hash] = s
+	mp.sightingsOrder = append(mp.sightingsOrder, *hash)
+	return s
+}
+
+// takeSighting returns the sighting of the transaction with the passed hash
+// to keep along with it in the pool.  A new sighting is returned when the
+// transaction was not seen before.
+//
+// This function MUST be called with the mempool lock held (for writes).
+func (mp *TxPool) takeSighting(hash *chainhash.Hash) *txSighting {
+	s, ok := mp.sightings[*hash]
+	if !ok {
+		return newTxSighting()
+	}
+	delete(mp.sightings, *hash)
+	for i := range mp.sightingsOrder {
+		if mp.sightingsOrder[i] == *hash {
+			mp.sightingsOrder = append(mp.sightingsOrder[:i],
+				mp.sightingsOrder[i+1:]...)
+			break
+		}
+	}
+	return s
+}
+
+// NoteAnnouncement records that the peer with the passed tag announced the
+// transaction with the passed hash.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) NoteAnnouncement(hash *chainhash.Hash, tag Tag) {
+	mp.mtx.Lock()
+	mp.sighting(hash).peers[tag] = struct{}{}
+	mp.mtx.Unlock()
+}
+
+// NoteRelay records that the transaction with the passed hash was received
+// from the peer with the passed tag and origin, which is the origin of the
+// transaction unless it was received before.  It should be called before
+// processing the transaction.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) NoteRelay(hash *chainhash.Hash, tag Tag, origin TxOrigin) {
+	mp.mtx.Lock()
+	s := mp.sighting(hash)
+	s.peers[tag] = struct{}{}
+	if !s.received {
+		s.origin = origin
+		s.received = true
+	}
+	mp.mtx.Unlock()
+}
+
+// NoteSubmission records that the transaction with the passed hash was
+// submitted to the node, which lets it use the block weight reserved for such
+// transactions by the mining policy once it is accepted.  It should be called
+// before processing the transaction.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) NoteSubmission(hash *chainhash.Hash) {
+	mp.mtx.Lock()
+	s := mp.sighting(hash)
+	s.submitted = true
+	s.received = true
+	mp.mtx.Unlock()
+}
+
+// TxProvenance returns when and how the transaction with the passed hash
+// reached the node, or nil when it is not in the pool.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) TxProvenance(hash *chainhash.Hash) *TxProvenance {
+	mp.mtx.RLock()
+	defer mp.mtx.RUnlock()
+
+	desc, ok := mp.pool[*hash]
+	if !ok {
+		return nil
+	}
+	p := desc.sighting.provenance()
+	return &p
+}
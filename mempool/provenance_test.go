@@ -0,0 +1,110 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mempool
+
+import (
+	"testing"
+
+	"github.com/pkt-cash/pktd/chaincfg"
+)
+
+// TestTxProvenance ensures the announcements and the relay of a transaction
+// made before it is accepted are kept in its provenance, and that they are
+// reported along with its ancestors and descendants by MempoolEntry.
+func TestTxProvenance(t *testing.T) {
+	t.Parallel()
+
+	harness, outputs, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	txPool := harness.txPool
+	chainedTxns, err := harness.CreateTxChain(outputs[0], 2)
+	if err != nil {
+		t.Fatalf("unable to create transaction chain: %v", err)
+	}
+	parent, child := chainedTxns[0], chainedTxns[1]
+
+	// The origin is the one of the first peer relaying the transaction.
+	txPool.NoteAnnouncement(parent.Hash(), 1)
+	txPool.NoteAnnouncement(parent.Hash(), 2)
+	txPool.NoteRelay(parent.Hash(), 2, OriginInbound)
+	txPool.NoteRelay(parent.Hash(), 3, OriginOutbound)
+	if p := txPool.TxProvenance(parent.Hash()); p != nil {
+		t.Fatalf("provenance of a transaction not in the pool %+v", p)
+	}
+	_, err = txPool.ProcessTransaction(parent, false, false, 2)
+	if err != nil {
+		t.Fatalf("ProcessTransaction: %v", err)
+	}
+	txPool.NoteAnnouncement(parent.Hash(), 4)
+	p := txPool.TxProvenance(parent.Hash())
+	if p == nil || p.Origin != OriginInbound || p.Announcers != 4 ||
+		p.FirstSeen.IsZero() {
+
+		t.Fatalf("unexpected provenance %+v", p)
+	}
+	if len(txPool.sightings) != 0 || len(txPool.sightingsOrder) != 0 {
+		t.Fatalf("sighting of an accepted transaction kept in the cache")
+	}
+
+	// A transaction submitted without being announced is local, and only
+	// the submitted transactions may use the reserved block weight.
+	txPool.NoteSubmission(child.Hash())
+	_, err = txPool.ProcessTransaction(child, false, false, 0)
+	if err != nil {
+		t.Fatalf("ProcessTransaction: %v", err)
+	}
+	p = txPool.TxProvenance(child.Hash())
+	if p == nil || p.Origin != OriginLocal || p.Announcers != 0 {
+		t.Fatalf("unexpected provenance %+v", p)
+	}
+	if txPool.pool[*parent.Hash()].Local || !txPool.pool[*child.Hash()].Local {
+		t.Fatalf("only the submitted transaction should be local")
+	}
+
+	entry, err := txPool.MempoolEntry(parent.Hash())
+	if err != nil {
+		t.Fatalf("MempoolEntry: %v", err)
+	}
+	if entry.DescendantCount != 2 || entry.AncestorCount != 1 ||
+		len(entry.Depends) != 0 || entry.Provenance == nil ||
+		entry.Provenance.Origin != "inbound" ||
+		entry.Provenance.Announcers != 4 {
+
+		t.Fatalf("unexpected entry %+v", entry)
+	}
+	entry, err = txPool.MempoolEntry(child.Hash())
+	if err != nil {
+		t.Fatalf("MempoolEntry: %v", err)
+	}
+	if entry.DescendantCount != 1 || entry.AncestorCount != 2 ||
+		len(entry.Depends) != 1 ||
+		entry.Depends[0] != parent.Hash().String() ||
+		entry.AncestorSize != int64(entry.Size)+GetTxVirtualSize(parent) {
+
+		t.Fatalf("unexpected entry %+v", entry)
+	}
+
+	txPool.RemoveTransaction(child, false, RemovalMined)
+	if _, err := txPool.MempoolEntry(child.Hash()); err == nil {
+		t.Fatalf("MempoolEntry of a removed transaction succeeded")
+	}
+
+	// The announcements of the transactions not in the pool are evicted
+	// oldest first.
+	for i := 0; i < maxTxSightings+1; i++ {
+		hash := *parent.Hash()
+		hash[0] ^= byte(i + 1)
+		hash[1] ^= byte((i + 1) >> 8)
+		txPool.NoteAnnouncement(&hash, 1)
+	}
+	if len(txPool.sightings) != maxTxSightings ||
+		len(txPool.sightingsOrder) != maxTxSightings {
+
+		t.Fatalf("%d sightings kept, want %d", len(txPool.sightings),
+			maxTxSightings)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"bytes"
 	"container/heap"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/pkt-cash/btcutil"
@@ -54,6 +55,11 @@ type TxDesc struct {
 
 	// FeePerKB is the fee the transaction pays in Satoshi per 1000 bytes.
 	FeePerKB int64
+
+	// Local is whether the transaction was submitted to the node rather
+	// than relayed by a peer, which allows it to use the block weight
+	// reserved by the BlockReservedWeight policy setting.
+	Local bool
 }
 
 // TxSource represents a source of transactions to consider for inclusion in
@@ -83,6 +89,7 @@ type txPrioItem struct {
 	fee      int64
 	priority float64
 	feePerKB int64
+	local    bool
 
 	// dependsOn holds a map of transaction hashes which this one depends
 	// on.  It will only be set when the transaction references other
@@ -324,6 +331,25 @@ func createCoinbaseTx(params *chaincfg.Params, coinbaseScript []byte, nextBlockH
 	return btcutil.NewTx(tx), nil
 }
 
+// CreateCoinbaseTx returns a standard coinbase transaction for a block at the
+// passed height, paying the block subsidy less the network steward tax to the
+// provided address and the tax to taxScript.  When the address is nil, the
+// subsidy will instead be redeemable by anyone.  It is used to build blocks
+// outside of block templates, such as the alternative chains generated on test
+// networks.
+func CreateCoinbaseTx(params *chaincfg.Params, nextBlockHeight int32, extraNonce uint64, addr btcutil.Address, taxScript []byte) (*btcutil.Tx, error) {
+	coinbaseScript, err := standardCoinbaseScript(nextBlockHeight, extraNonce)
+	if err != nil {
+		return nil, err
+	}
+	var addrs map[btcutil.Address]float64
+	if addr != nil {
+		addrs = map[btcutil.Address]float64{addr: 1}
+	}
+	return createCoinbaseTx(params, coinbaseScript, nextBlockHeight, addrs,
+		taxScript)
+}
+
 // spendTransaction updates the passed view by marking the inputs to the passed
 // transaction as spent.  It also adds all outputs in the passed transaction
 // which are not provably unspendable as available unspent transaction outputs.
@@ -384,6 +410,7 @@ func medianAdjustedTime(chainState *blockchain.BestState, timeSource blockchain.
 // It also houses additional state required in order to ensure the templates
 // are built on top of the current best chain and adhere to the consensus rules.
 type BlkTmplGenerator struct {
+	policyMtx   sync.RWMutex
 	policy      *Policy
 	chainParams *chaincfg.Params
 	txSource    TxSource
@@ -453,8 +480,13 @@ func NewBlkTmplGenerator(policy *Policy, params *chaincfg.Params,
 // transactions until the block size reaches that minimum size.
 //
 // Any transactions which would cause the block to exceed the BlockMaxSize
-// policy setting, exceed the maximum allowed signature operations per block, or
-// otherwise cause the block to be invalid are skipped.
+// policy setting, exceed the BlockMaxSigOpCost policy setting, or otherwise
+// cause the block to be invalid are skipped.
+// Likewise, transactions carrying data in OP_RETURN outputs are skipped once
+// their total weight would exceed the BlockMaxDataCarrierWeight policy setting,
+// and transactions relayed by peers are skipped once they would use the weight
+// the BlockReservedWeight policy setting keeps for the transactions submitted
+// to the node.
 //
 // Given the above, a block generated by this function is of the following form:
 //
@@ -479,6 +511,10 @@ func NewBlkTmplGenerator(policy *Policy, params *chaincfg.Params,
 //  |  <= policy.BlockMinSize)          |   |
 //   -----------------------------------  --
 func (g *BlkTmplGenerator) NewBlockTemplate(payToAddresses map[btcutil.Address]float64, cbc *wire.PcCoinbaseCommit) (*BlockTemplate, error) {
+	// The policy may be changed while the template is generated, so the
+	// template follows the policy in effect when it was started.
+	policy := g.Policy()
+
 	// Extend the most recently known best block.
 	best := g.chain.BestSnapshot()
 	nextBlockHeight := best.Height + 1
@@ -508,6 +544,24 @@ func (g *BlkTmplGenerator) NewBlockTemplate(payToAddresses map[btcutil.Address]f
 
 	coinbaseSigOpCost := int64(blockchain.CountSigOps(coinbaseTx)) * blockchain.WitnessScaleFactor
 
+	// The scripts of the transactions are checked with the flags the
+	// consensus rules enforce for the block, which depend on its version
+	// and timestamp, along with the policy ones.
+	ts := medianAdjustedTime(best, g.timeSource)
+	nextBlockVersion, err := g.chain.CalcNextBlockVersion()
+	if err != nil {
+		return nil, err
+	}
+	scriptFlags, err := g.chain.ConsensusScriptFlags(&wire.BlockHeader{
+		Version:   nextBlockVersion,
+		PrevBlock: best.Hash,
+		Timestamp: ts,
+	})
+	if err != nil {
+		return nil, err
+	}
+	scriptFlags |= txscript.PolicyVerifyFlags
+
 	// Get the current source transactions and create a priority queue to
 	// hold the transactions which are ready for inclusion into a block
 	// along with some priority related and fee metadata.  Reserve the same
@@ -515,7 +569,7 @@ func (g *BlkTmplGenerator) NewBlockTemplate(payToAddresses map[btcutil.Address]f
 	// choose the initial sort order for the priority queue based on whether
 	// or not there is an area allocated for high-priority transactions.
 	sourceTxns := g.txSource.MiningDescs()
-	sortedByFee := g.policy.BlockPrioritySize == 0
+	sortedByFee := policy.BlockPrioritySize == 0
 	priorityQueue := newTxPriorityQueue(len(sourceTxns), sortedByFee)
 
 	// Create a slice to hold the transactions to be included in the
@@ -526,6 +580,14 @@ func (g *BlkTmplGenerator) NewBlockTemplate(payToAddresses map[btcutil.Address]f
 	blockTxns = append(blockTxns, coinbaseTx)
 	blockUtxos := blockchain.NewUtxoViewpoint()
 
+	// The merkle trees of the block are built as the transactions are
+	// added so only the path of each new leaf is hashed.  The coinbase
+	// changes once the fees are known, so its leaf is set at the end.
+	merkleTree := blockchain.NewMerkleTreeBuilder(false)
+	merkleTree.Add(&chainhash.Hash{})
+	witnessMerkleTree := blockchain.NewMerkleTreeBuilder(true)
+	witnessMerkleTree.AddTx(coinbaseTx)
+
 	// dependers is used to track transactions which depend on another
 	// transaction in the source pool.  This, in conjunction with the
 	// dependsOn map kept with each dependent transaction helps quickly
@@ -621,6 +683,7 @@ mempoolLoop:
 		// Calculate the fee in Satoshi/kB.
 		prioItem.feePerKB = txDesc.FeePerKB
 		prioItem.fee = txDesc.Fee
+		prioItem.local = txDesc.Local
 
 		// Add the transaction to the priority queue to mark it ready
 		// for inclusion in the block unless it has dependencies.
@@ -644,6 +707,7 @@ mempoolLoop:
 		blockchain.GetTransactionWeight(coinbaseTx))
 	blockSigOpCost := coinbaseSigOpCost
 	totalFees := int64(0)
+	dataCarrierWeight := uint32(0)
 
 	// Query the version bits state to see if segwit has been activated, if
 	// so then this means that we'll include any transactions with witness
@@ -706,11 +770,13 @@ mempoolLoop:
 		// Grab any transactions which depend on this one.
 		deps := dependers[*tx.Hash()]
 
-		// Enforce maximum block size.  Also check for overflow.
+		// Enforce maximum block size, leaving the reserved weight to
+		// the transactions submitted to the node.  Also check for
+		// overflow.
 		txWeight := uint32(blockchain.GetTransactionWeight(tx))
 		blockPlusTxWeight := blockWeight + txWeight
 		if blockPlusTxWeight < blockWeight ||
-			blockPlusTxWeight >= g.policy.BlockMaxWeight {
+			blockPlusTxWeight >= policy.maxBlockWeight(prioItem.local) {
 
 			log.Tracef("Skipping tx %s because it would exceed "+
 				"the max block weight", tx.Hash())
@@ -718,6 +784,17 @@ mempoolLoop:
 			continue
 		}
 
+		// Enforce maximum weight of the transactions carrying data.
+		dataCarrier := isDataCarrier(tx)
+		if dataCarrier && (dataCarrierWeight+txWeight < dataCarrierWeight ||
+			dataCarrierWeight+txWeight > policy.BlockMaxDataCarrierWeight) {
+
+			log.Tracef("Skipping tx %s because it would exceed "+
+				"the max data carrier weight", tx.Hash())
+			logSkippedDeps(tx, deps)
+			continue
+		}
+
 		// Enforce maximum signature operation cost per block.  Also
 		// check for overflow.
 		sigOpCost, err := blockchain.GetSigOpCost(tx, false,
@@ -729,7 +806,7 @@ mempoolLoop:
 			continue
 		}
 		if blockSigOpCost+int64(sigOpCost) < blockSigOpCost ||
-			blockSigOpCost+int64(sigOpCost) > blockchain.MaxBlockSigOpsCost {
+			blockSigOpCost+int64(sigOpCost) > policy.maxBlockSigOpCost() {
 			log.Tracef("Skipping tx %s because it would "+
 				"exceed the maximum sigops per block", tx.Hash())
 			logSkippedDeps(tx, deps)
@@ -739,14 +816,14 @@ mempoolLoop:
 		// Skip free transactions once the block is larger than the
 		// minimum block size.
 		if sortedByFee &&
-			prioItem.feePerKB < int64(g.policy.TxMinFreeFee) &&
-			blockPlusTxWeight >= g.policy.BlockMinWeight {
+			prioItem.feePerKB < int64(policy.TxMinFreeFee) &&
+			blockPlusTxWeight >= policy.BlockMinWeight {
 
 			log.Tracef("Skipping tx %s with feePerKB %d "+
 				"< TxMinFreeFee %d and block weight %d >= "+
 				"minBlockWeight %d", tx.Hash(), prioItem.feePerKB,
-				g.policy.TxMinFreeFee, blockPlusTxWeight,
-				g.policy.BlockMinWeight)
+				policy.TxMinFreeFee, blockPlusTxWeight,
+				policy.BlockMinWeight)
 			logSkippedDeps(tx, deps)
 			continue
 		}
@@ -754,13 +831,13 @@ mempoolLoop:
 		// Prioritize by fee per kilobyte once the block is larger than
 		// the priority size or there are no more high-priority
 		// transactions.
-		if !sortedByFee && (blockPlusTxWeight >= g.policy.BlockPrioritySize ||
+		if !sortedByFee && (blockPlusTxWeight >= policy.BlockPrioritySize ||
 			prioItem.priority <= MinHighPriority) {
 
 			log.Tracef("Switching to sort by fees per "+
 				"kilobyte blockSize %d >= BlockPrioritySize "+
 				"%d || priority %.2f <= minHighPriority %.2f",
-				blockPlusTxWeight, g.policy.BlockPrioritySize,
+				blockPlusTxWeight, policy.BlockPrioritySize,
 				prioItem.priority, MinHighPriority)
 
 			sortedByFee = true
@@ -772,7 +849,7 @@ mempoolLoop:
 			// is too low.  Otherwise this transaction will be the
 			// final one in the high-priority section, so just fall
 			// though to the code below so it is added now.
-			if blockPlusTxWeight > g.policy.BlockPrioritySize ||
+			if blockPlusTxWeight > policy.BlockPrioritySize ||
 				prioItem.priority < MinHighPriority {
 
 				heap.Push(priorityQueue, prioItem)
@@ -791,7 +868,7 @@ mempoolLoop:
 			continue
 		}
 		err = blockchain.ValidateTransactionScripts(tx, blockUtxos,
-			txscript.StandardVerifyFlags, g.sigCache,
+			scriptFlags, g.sigCache,
 			g.hashCache)
 		if err != nil {
 			log.Tracef("Skipping tx %s due to error in "+
@@ -810,7 +887,12 @@ mempoolLoop:
 		// save the fees and signature operation counts to the block
 		// template.
 		blockTxns = append(blockTxns, tx)
+		merkleTree.AddTx(tx)
+		witnessMerkleTree.AddTx(tx)
 		blockWeight += txWeight
+		if dataCarrier {
+			dataCarrierWeight += txWeight
+		}
 		blockSigOpCost += int64(sigOpCost)
 		totalFees += prioItem.fee
 		txFees = append(txFees, prioItem.fee)
@@ -854,9 +936,7 @@ mempoolLoop:
 		// Next, obtain the merkle root of a tree which consists of the
 		// wtxid of all transactions in the block. The coinbase
 		// transaction will have a special wtxid of all zeroes.
-		witnessMerkleTree := blockchain.BuildMerkleTreeStore(blockTxns,
-			true)
-		witnessMerkleRoot := witnessMerkleTree[len(witnessMerkleTree)-1]
+		witnessMerkleRoot := witnessMerkleTree.Root()
 
 		// The preimage to the witness commitment is:
 		// witnessRoot || coinbaseWitness
@@ -883,28 +963,22 @@ mempoolLoop:
 	}
 
 	// Calculate the required difficulty for the block.  The timestamp
-	// is potentially adjusted to ensure it comes after the median time of
+	// was potentially adjusted to ensure it comes after the median time of
 	// the last several blocks per the chain consensus rules.
-	ts := medianAdjustedTime(best, g.timeSource)
 	reqDifficulty, err := g.chain.CalcNextRequiredDifficulty(ts)
 	if err != nil {
 		return nil, err
 	}
 
-	// Calculate the next expected block version based on the state of the
-	// rule change deployments.
-	nextBlockVersion, err := g.chain.CalcNextBlockVersion()
-	if err != nil {
-		return nil, err
-	}
-
-	// Create a new block ready to be solved.
-	merkles := blockchain.BuildMerkleTreeStore(blockTxns, false)
+	// Create a new block ready to be solved.  The coinbase is final, so
+	// its leaf completes the merkle tree.
+	coinbaseHash := coinbaseTx.MsgTx().TxHash()
+	merkleTree.Set(0, &coinbaseHash)
 	var msgBlock wire.MsgBlock
 	msgBlock.Header = wire.BlockHeader{
 		Version:    nextBlockVersion,
 		PrevBlock:  best.Hash,
-		MerkleRoot: *merkles[len(merkles)-1],
+		MerkleRoot: *merkleTree.Root(),
 		Timestamp:  ts,
 		Bits:       reqDifficulty,
 	}
@@ -924,9 +998,10 @@ mempoolLoop:
 	}
 
 	log.Debugf("Created new block template (%d transactions, %d in "+
-		"fees, %d signature operations cost, %d weight, target difficulty "+
-		"%064x)", len(msgBlock.Transactions), totalFees, blockSigOpCost,
-		blockWeight, blockchain.CompactToBig(msgBlock.Header.Bits))
+		"fees, %d signature operations cost, %d weight, %d data carrier "+
+		"weight, target difficulty %064x)", len(msgBlock.Transactions),
+		totalFees, blockSigOpCost, blockWeight, dataCarrierWeight,
+		blockchain.CompactToBig(msgBlock.Header.Bits))
 
 	return &BlockTemplate{
 		Block:             &msgBlock,
@@ -1007,3 +1082,22 @@ func (g *BlkTmplGenerator) BestSnapshot() *blockchain.BestState {
 func (g *BlkTmplGenerator) TxSource() TxSource {
 	return g.txSource
 }
+
+// Policy returns a copy of the policy used to generate block templates.
+//
+// This function is safe for concurrent access.
+func (g *BlkTmplGenerator) Policy() Policy {
+	g.policyMtx.RLock()
+	defer g.policyMtx.RUnlock()
+	return *g.policy
+}
+
+// SetPolicy replaces the policy used to generate block templates.  It applies
+// to the templates generated afterwards.
+//
+// This function is safe for concurrent access.
+func (g *BlkTmplGenerator) SetPolicy(policy Policy) {
+	g.policyMtx.Lock()
+	*g.policy = policy
+	g.policyMtx.Unlock()
+}
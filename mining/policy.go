@@ -6,6 +6,7 @@ package mining
 
 import (
 	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/txscript"
 	"github.com/pkt-cash/pktd/wire"
 	"github.com/pkt-cash/btcutil"
 )
@@ -45,6 +46,56 @@ type Policy struct {
 	// required for a transaction to be treated as free for mining purposes
 	// (block template generation).
 	TxMinFreeFee btcutil.Amount
+
+	// BlockMaxDataCarrierWeight is the maximum total weight of the
+	// transactions carrying data in OP_RETURN outputs to be used when
+	// generating a block template.
+	BlockMaxDataCarrierWeight uint32
+
+	// BlockMaxSigOpCost is the maximum signature operation cost to be used
+	// when generating a block template.  Zero, or a value above the
+	// consensus limit, selects the consensus limit.
+	BlockMaxSigOpCost int64
+
+	// BlockReservedWeight is the part of BlockMaxWeight reserved for the
+	// transactions submitted to the node, as opposed to the ones relayed
+	// by peers, when generating a block template.
+	BlockReservedWeight uint32
+}
+
+// maxBlockWeight returns the weight a block template may reach when adding a
+// transaction, depending on whether the transaction was submitted to the node
+// and can therefore use the reserved weight.
+func (p *Policy) maxBlockWeight(local bool) uint32 {
+	switch {
+	case local:
+		return p.BlockMaxWeight
+	case p.BlockReservedWeight >= p.BlockMaxWeight:
+		return 0
+	}
+	return p.BlockMaxWeight - p.BlockReservedWeight
+}
+
+// maxBlockSigOpCost returns the signature operation cost a block template may
+// reach.
+func (p *Policy) maxBlockSigOpCost() int64 {
+	if p.BlockMaxSigOpCost <= 0 ||
+		p.BlockMaxSigOpCost > blockchain.MaxBlockSigOpsCost {
+
+		return blockchain.MaxBlockSigOpsCost
+	}
+	return p.BlockMaxSigOpCost
+}
+
+// isDataCarrier returns whether the passed transaction carries data in an
+// OP_RETURN output.
+func isDataCarrier(tx *btcutil.Tx) bool {
+	for _, txOut := range tx.MsgTx().TxOut {
+		if _, ok := txscript.NullDataPayloadSize(txOut.PkScript); ok {
+			return true
+		}
+	}
+	return false
 }
 
 // minInt is a helper function to return the minimum of two ints.  This avoids
@@ -166,3 +166,62 @@ func TestCalcPriority(t *testing.T) {
 		}
 	}
 }
+
+// TestPolicyBlockLimits ensures the weight reserved for the transactions
+// submitted to the node is only available to them and that the signature
+// operation cost falls back to the consensus limit.
+func TestPolicyBlockLimits(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      Policy
+		weight      uint32
+		localWeight uint32
+		sigOpCost   int64
+	}{
+		{
+			name:        "no reserved weight",
+			policy:      Policy{BlockMaxWeight: 3000000},
+			weight:      3000000,
+			localWeight: 3000000,
+			sigOpCost:   blockchain.MaxBlockSigOpsCost,
+		},
+		{
+			name: "reserved weight",
+			policy: Policy{
+				BlockMaxWeight:      3000000,
+				BlockReservedWeight: 100000,
+				BlockMaxSigOpCost:   40000,
+			},
+			weight:      2900000,
+			localWeight: 3000000,
+			sigOpCost:   40000,
+		},
+		{
+			name: "whole block reserved",
+			policy: Policy{
+				BlockMaxWeight:      3000000,
+				BlockReservedWeight: 4000000,
+				BlockMaxSigOpCost:   blockchain.MaxBlockSigOpsCost + 1,
+			},
+			weight:      0,
+			localWeight: 3000000,
+			sigOpCost:   blockchain.MaxBlockSigOpsCost,
+		},
+	}
+
+	for _, test := range tests {
+		if got := test.policy.maxBlockWeight(false); got != test.weight {
+			t.Errorf("%s: got max weight %d, want %d", test.name,
+				got, test.weight)
+		}
+		got := test.policy.maxBlockWeight(true)
+		if got != test.localWeight {
+			t.Errorf("%s: got max weight of local transactions %d, "+
+				"want %d", test.name, got, test.localWeight)
+		}
+		if got := test.policy.maxBlockSigOpCost(); got != test.sigOpCost {
+			t.Errorf("%s: got max sigop cost %d, want %d", test.name,
+				got, test.sigOpCost)
+		}
+	}
+}
@@ -0,0 +1,555 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkt-cash/pktd/btcjson"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+const (
+	// msgCaptureDirname is the name of the directory, relative to the data
+	// directory, which the message captures are written to by default.
+	msgCaptureDirname = "msgcapture"
+
+	// msgCaptureRedactPeers is the redaction option which replaces the
+	// addresses of the peers, and the local ones, with placeholders.
+	msgCaptureRedactPeers = "peers"
+
+	// msgCaptureMaxSegment is the largest TCP payload of the packets the
+	// messages are captured as.  Larger messages are split across several
+	// packets so the lengths fit in the IP headers.
+	msgCaptureMaxSegment = 32768
+
+	// msgCaptureMaxRetained is the largest payload buffer a connection
+	// keeps around for the next message once a message has been captured.
+	msgCaptureMaxRetained = 64 * 1024
+)
+
+// pcapng block types, the byte order magic of the section header block and the
+// link type of the captured packets, which are raw IP packets.
+const (
+	pcapngSectionHeader  = 0x0a0d0d0a
+	pcapngInterfaceDesc  = 0x00000001
+	pcapngEnhancedPacket = 0x00000006
+	pcapngByteOrderMagic = 0x1a2b3c4d
+	pcapngLinkTypeRaw    = 101
+)
+
+// The placeholder addresses used when the peer addresses are redacted.  The
+// remote ones are numbered from the benchmarking range.
+var (
+	msgCaptureLocalIP  = net.IPv4(192, 0, 2, 1).To4()
+	msgCaptureRemoteIP = net.IPv4(198, 18, 0, 0).To4()
+)
+
+// msgCaptureRedaction describes what is left out of the message captures.
+// Messages of the redacted commands are recorded with an empty payload, so the
+// capture still shows when they were exchanged.
+type msgCaptureRedaction struct {
+	peers    bool
+	commands map[string]struct{}
+}
+
+// parseMsgCaptureRedaction parses the redaction options, each of which is
+// either "peers" or the command of the messages whose payload is left out.
+func parseMsgCaptureRedaction(options []string) (*msgCaptureRedaction, error) {
+	r := &msgCaptureRedaction{commands: make(map[string]struct{})}
+	for _, option := range options {
+		switch {
+		case option == msgCaptureRedactPeers:
+			r.peers = true
+		case option == "" || len(option) > wire.CommandSize:
+			return nil, fmt.Errorf("invalid redaction option %q -- "+
+				"use %q or a message command", option,
+				msgCaptureRedactPeers)
+		default:
+			r.commands[option] = struct{}{}
+		}
+	}
+	return r, nil
+}
+
+// options returns the redaction options in a stable order.
+func (r *msgCaptureRedaction) options() []string {
+	options := make([]string, 0, len(r.commands)+1)
+	if r.peers {
+		options = append(options, msgCaptureRedactPeers)
+	}
+	commands := make([]string, 0, len(r.commands))
+	for command := range r.commands {
+		commands = append(commands, command)
+	}
+	sort.Strings(commands)
+	return append(options, commands...)
+}
+
+// msgCapture records the messages exchanged with the peers to pcapng files, one
+// per connection, which can be opened with Wireshark.  Every message is
+// captured as one or more TCP packets between the addresses of the connection,
+// so the Bitcoin dissector decodes them once the port is associated with it.
+//
+// The capture can be started and stopped at any time.  Only the messages whose
+// header is read or written while it is running are recorded.
+type msgCapture struct {
+	enabled int32 // atomic
+
+	mtx           sync.Mutex
+	dir           string
+	defaultRedact *msgCaptureRedaction
+	redact        *msgCaptureRedaction
+	files         map[*captureConn]*msgCaptureFile
+	nextPeer      uint32
+	messages      uint64
+	bytes         uint64
+}
+
+// newMsgCapture returns a message capture writing to the passed directory
+// with the passed default redaction.  It is not running until it is started.
+func newMsgCapture(dir string, redact *msgCaptureRedaction) *msgCapture {
+	if redact == nil {
+		redact = &msgCaptureRedaction{}
+	}
+	return &msgCapture{
+		dir:           dir,
+		defaultRedact: redact,
+		redact:        redact,
+		files:         make(map[*captureConn]*msgCaptureFile),
+	}
+}
+
+// Start starts capturing the messages with the passed redaction, or the
+// default one when it is nil.  When the capture is already running, the open
+// files are closed and the following messages are recorded to new ones.
+func (c *msgCapture) Start(redact *msgCaptureRedaction) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return err
+	}
+	c.closeFiles()
+	if redact == nil {
+		redact = c.defaultRedact
+	}
+	c.redact = redact
+	c.messages = 0
+	c.bytes = 0
+	atomic.StoreInt32(&c.enabled, 1)
+	peerLog.Infof("Capturing peer messages to %s", c.dir)
+	return nil
+}
+
+// Stop stops capturing the messages and closes the capture files.
+func (c *msgCapture) Stop() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if atomic.SwapInt32(&c.enabled, 0) == 0 {
+		return
+	}
+	c.closeFiles()
+	peerLog.Infof("Stopped capturing peer messages (%d messages, %d bytes)",
+		c.messages, c.bytes)
+}
+
+// Status returns the state of the capture.
+func (c *msgCapture) Status() *btcjson.CaptureMessagesResult {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	files := make([]string, 0, len(c.files))
+	for _, f := range c.files {
+		files = append(files, f.path)
+	}
+	sort.Strings(files)
+	return &btcjson.CaptureMessagesResult{
+		Enabled:  atomic.LoadInt32(&c.enabled) != 0,
+		Dir:      c.dir,
+		Redact:   c.redact.options(),
+		Files:    files,
+		Messages: c.messages,
+		Bytes:    c.bytes,
+	}
+}
+
+// closeFiles closes all the capture files.
+//
+// This function MUST be called with the capture mutex held.
+func (c *msgCapture) closeFiles() {
+	for conn, f := range c.files {
+		if err := f.file.Close(); err != nil {
+			peerLog.Warnf("Unable to close message capture %s: %v",
+				f.path, err)
+		}
+		delete(c.files, conn)
+	}
+}
+
+// WrapConn returns a connection which hands the messages exchanged over the
+// passed connection to the capture while it is running.
+func (c *msgCapture) WrapConn(conn net.Conn, inbound bool) net.Conn {
+	return &captureConn{Conn: conn, capture: c, inbound: inbound}
+}
+
+// record writes a message exchanged over the passed connection to its capture
+// file, opening it when needed.
+func (c *msgCapture) record(conn *captureConn, outgoing bool, hdr, payload []byte) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if atomic.LoadInt32(&c.enabled) == 0 {
+		return
+	}
+
+	// Record the messages of the redacted commands with an empty payload
+	// and a header matching it.
+	command := strings.TrimRight(string(hdr[4:4+wire.CommandSize]), "\x00")
+	if _, ok := c.redact.commands[command]; ok && len(payload) > 0 {
+		redacted := make([]byte, len(hdr))
+		copy(redacted, hdr)
+		binary.LittleEndian.PutUint32(redacted[16:20], 0)
+		copy(redacted[20:24], chainhash.DoubleHashB(nil)[:4])
+		hdr, payload = redacted, nil
+	}
+
+	f := c.files[conn]
+	if f == nil {
+		var err error
+		f, err = c.openFile(conn)
+		if err != nil {
+			peerLog.Errorf("Unable to capture messages of %s: %v",
+				conn.RemoteAddr(), err)
+			return
+		}
+		c.files[conn] = f
+	}
+	data := make([]byte, 0, len(hdr)+len(payload))
+	data = append(append(data, hdr...), payload...)
+	if err := f.writeMessage(outgoing, data, time.Now()); err != nil {
+		peerLog.Errorf("Unable to capture messages of %s: %v",
+			conn.RemoteAddr(), err)
+		f.file.Close()
+		delete(c.files, conn)
+		return
+	}
+	c.messages++
+	c.bytes += uint64(len(data))
+}
+
+// closeConn closes the capture file of the passed connection, if any.
+func (c *msgCapture) closeConn(conn *captureConn) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if f := c.files[conn]; f != nil {
+		f.file.Close()
+		delete(c.files, conn)
+	}
+}
+
+// openFile creates the capture file of the passed connection.  It is named
+// after the time, the direction and the address of the connection, or a
+// placeholder number when the peer addresses are redacted.
+//
+// This function MUST be called with the capture mutex held.
+func (c *msgCapture) openFile(conn *captureConn) (*msgCaptureFile, error) {
+	local, localPort := tcpAddrParts(conn.LocalAddr())
+	remote, remotePort := tcpAddrParts(conn.RemoteAddr())
+	c.nextPeer++
+	peerName := strings.NewReplacer(":", "_", "[", "", "]", "").Replace(
+		conn.RemoteAddr().String())
+	if c.redact.peers {
+		local = msgCaptureLocalIP
+		remote = make(net.IP, net.IPv4len)
+		binary.BigEndian.PutUint32(remote,
+			binary.BigEndian.Uint32(msgCaptureRemoteIP)+c.nextPeer)
+		peerName = fmt.Sprintf("peer%d", c.nextPeer)
+	}
+	direction := "out"
+	if conn.inbound {
+		direction = "in"
+	}
+	name := fmt.Sprintf("%s-%s-%s-%d.pcapng",
+		time.Now().UTC().Format("20060102-150405"), direction, peerName,
+		c.nextPeer)
+
+	path := filepath.Join(c.dir, name)
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, err
+	}
+	f := &msgCaptureFile{
+		path:       path,
+		file:       file,
+		localPort:  localPort,
+		remotePort: remotePort,
+		seq:        [2]uint32{1, 1},
+	}
+
+	// Use IPv4 packets when both addresses have an IPv4 form.
+	if local.To4() != nil && remote.To4() != nil {
+		f.local, f.remote = local.To4(), remote.To4()
+	} else {
+		f.local, f.remote = local.To16(), remote.To16()
+	}
+	if err := f.writeHeader(); err != nil {
+		file.Close()
+		os.Remove(path)
+		return nil, err
+	}
+	return f, nil
+}
+
+// tcpAddrParts returns the IP and the port of the passed address, or the
+// unspecified IPv4 address and port 0 when it is not a TCP address.
+func tcpAddrParts(addr net.Addr) (net.IP, uint16) {
+	if tcpAddr, ok := addr.(*net.TCPAddr); ok && tcpAddr.IP != nil {
+		return tcpAddr.IP, uint16(tcpAddr.Port)
+	}
+	return net.IPv4zero.To4(), 0
+}
+
+// msgCaptureFile is the pcapng capture file of a connection.  It tracks the TCP
+// sequence numbers of both directions of the connection.
+type msgCaptureFile struct {
+	path       string
+	file       *os.File
+	local      net.IP
+	remote     net.IP
+	localPort  uint16
+	remotePort uint16
+	seq        [2]uint32
+}
+
+// writeHeader writes the section header block and the description of the only
+// interface of the capture.
+func (f *msgCaptureFile) writeHeader() error {
+	shb := make([]byte, 28)
+	binary.LittleEndian.PutUint32(shb[0:4], pcapngSectionHeader)
+	binary.LittleEndian.PutUint32(shb[4:8], uint32(len(shb)))
+	binary.LittleEndian.PutUint32(shb[8:12], pcapngByteOrderMagic)
+	binary.LittleEndian.PutUint16(shb[12:14], 1)
+	binary.LittleEndian.PutUint16(shb[14:16], 0)
+	binary.LittleEndian.PutUint64(shb[16:24], ^uint64(0))
+	binary.LittleEndian.PutUint32(shb[24:28], uint32(len(shb)))
+
+	idb := make([]byte, 20)
+	binary.LittleEndian.PutUint32(idb[0:4], pcapngInterfaceDesc)
+	binary.LittleEndian.PutUint32(idb[4:8], uint32(len(idb)))
+	binary.LittleEndian.PutUint16(idb[8:10], pcapngLinkTypeRaw)
+	binary.LittleEndian.PutUint32(idb[12:16], 0)
+	binary.LittleEndian.PutUint32(idb[16:20], uint32(len(idb)))
+
+	_, err := f.file.Write(append(shb, idb...))
+	return err
+}
+
+// writeMessage writes the passed serialized message, sent to the peer when
+// outgoing is true, as enhanced packet blocks holding IP packets.
+func (f *msgCaptureFile) writeMessage(outgoing bool, data []byte, t time.Time) error {
+	ts := uint64(t.UnixNano() / int64(time.Microsecond))
+	for len(data) > 0 {
+		segment := data
+		if len(segment) > msgCaptureMaxSegment {
+			segment = segment[:msgCaptureMaxSegment]
+		}
+		data = data[len(segment):]
+
+		packet := f.packet(outgoing, segment)
+		padded := (len(packet) + 3) &^ 3
+		block := make([]byte, 28+padded+4)
+		binary.LittleEndian.PutUint32(block[0:4], pcapngEnhancedPacket)
+		binary.LittleEndian.PutUint32(block[4:8], uint32(len(block)))
+		binary.LittleEndian.PutUint32(block[8:12], 0)
+		binary.LittleEndian.PutUint32(block[12:16], uint32(ts>>32))
+		binary.LittleEndian.PutUint32(block[16:20], uint32(ts))
+		binary.LittleEndian.PutUint32(block[20:24], uint32(len(packet)))
+		binary.LittleEndian.PutUint32(block[24:28], uint32(len(packet)))
+		copy(block[28:], packet)
+		binary.LittleEndian.PutUint32(block[len(block)-4:], uint32(len(block)))
+		if _, err := f.file.Write(block); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// packet returns an IP packet carrying the passed TCP payload in the passed
+// direction of the connection and advances its sequence number.  The checksums
+// of the TCP headers are left out, which Wireshark does not verify by default.
+func (f *msgCaptureFile) packet(outgoing bool, payload []byte) []byte {
+	src, dst := f.remote, f.local
+	srcPort, dstPort := f.remotePort, f.localPort
+	dir := 1
+	if outgoing {
+		src, dst = f.local, f.remote
+		srcPort, dstPort = f.localPort, f.remotePort
+		dir = 0
+	}
+
+	const tcpHeaderLen = 20
+	ipHeaderLen := 20
+	if len(src) == net.IPv6len {
+		ipHeaderLen = 40
+	}
+	packet := make([]byte, ipHeaderLen+tcpHeaderLen+len(payload))
+	ip := packet[:ipHeaderLen]
+	if ipHeaderLen == 20 {
+		ip[0] = 0x45
+		binary.BigEndian.PutUint16(ip[2:4], uint16(len(packet)))
+		ip[8] = 64
+		ip[9] = 6
+		copy(ip[12:16], src)
+		copy(ip[16:20], dst)
+		binary.BigEndian.PutUint16(ip[10:12], ipv4Checksum(ip))
+	} else {
+		ip[0] = 0x60
+		binary.BigEndian.PutUint16(ip[4:6],
+			uint16(tcpHeaderLen+len(payload)))
+		ip[6] = 6
+		ip[7] = 64
+		copy(ip[8:24], src)
+		copy(ip[24:40], dst)
+	}
+
+	tcp := packet[ipHeaderLen : ipHeaderLen+tcpHeaderLen]
+	binary.BigEndian.PutUint16(tcp[0:2], srcPort)
+	binary.BigEndian.PutUint16(tcp[2:4], dstPort)
+	binary.BigEndian.PutUint32(tcp[4:8], f.seq[dir])
+	binary.BigEndian.PutUint32(tcp[8:12], f.seq[1-dir])
+	tcp[12] = tcpHeaderLen / 4 << 4
+	tcp[13] = 0x18 // PSH and ACK
+	binary.BigEndian.PutUint16(tcp[14:16], 0xffff)
+	copy(packet[ipHeaderLen+tcpHeaderLen:], payload)
+
+	f.seq[dir] += uint32(len(payload))
+	return packet
+}
+
+// ipv4Checksum returns the checksum of the passed IPv4 header, whose checksum
+// field is zero.
+func ipv4Checksum(hdr []byte) uint16 {
+	var sum uint32
+	for i := 0; i < len(hdr); i += 2 {
+		sum += uint32(binary.BigEndian.Uint16(hdr[i:]))
+	}
+	for sum>>16 != 0 {
+		sum = sum&0xffff + sum>>16
+	}
+	return ^uint16(sum)
+}
+
+// captureStream splits the bytes exchanged in one direction of a connection
+// into messages.  The payload of a message is only kept when the capture was
+// running as its header went through.
+type captureStream struct {
+	hdr       [wire.MessageHeaderSize]byte
+	hdrLen    int
+	remaining uint32
+	record    bool
+	payload   []byte
+}
+
+// feed passes the next bytes of the stream, calling emit with the header and
+// the payload of every recorded message they complete.
+func (s *captureStream) feed(data []byte, enabled bool, emit func(hdr, payload []byte)) {
+	for len(data) > 0 {
+		if s.hdrLen < len(s.hdr) {
+			n := copy(s.hdr[s.hdrLen:], data)
+			s.hdrLen += n
+			data = data[n:]
+			if s.hdrLen < len(s.hdr) {
+				return
+			}
+			s.remaining = binary.LittleEndian.Uint32(s.hdr[16:20])
+			s.record = enabled
+			if s.remaining == 0 {
+				s.done(emit)
+			}
+			continue
+		}
+
+		n := len(data)
+		if uint32(n) > s.remaining {
+			n = int(s.remaining)
+		}
+		if s.record {
+			s.payload = append(s.payload, data[:n]...)
+		}
+		data = data[n:]
+		s.remaining -= uint32(n)
+		if s.remaining == 0 {
+			s.done(emit)
+		}
+	}
+}
+
+// done completes the current message and prepares for the next one.
+func (s *captureStream) done(emit func(hdr, payload []byte)) {
+	if s.record {
+		emit(s.hdr[:], s.payload)
+	}
+	s.hdrLen = 0
+	s.record = false
+	if cap(s.payload) > msgCaptureMaxRetained {
+		s.payload = nil
+	} else {
+		s.payload = s.payload[:0]
+	}
+}
+
+// captureConn is a connection to a peer whose messages are handed to the
+// capture while it is running.  Reads and writes may happen concurrently
+// since each direction has its own stream.
+type captureConn struct {
+	net.Conn
+	capture *msgCapture
+	inbound bool
+	in      captureStream
+	out     captureStream
+}
+
+// Read reads from the connection and captures the messages read.
+func (c *captureConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if n > 0 {
+		enabled := atomic.LoadInt32(&c.capture.enabled) != 0
+		c.in.feed(b[:n], enabled, func(hdr, payload []byte) {
+			c.capture.record(c, false, hdr, payload)
+		})
+	}
+	return n, err
+}
+
+// Write writes to the connection and captures the messages written.
+func (c *captureConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	if n > 0 {
+		enabled := atomic.LoadInt32(&c.capture.enabled) != 0
+		c.out.feed(b[:n], enabled, func(hdr, payload []byte) {
+			c.capture.record(c, true, hdr, payload)
+		})
+	}
+	return n, err
+}
+
+// Close closes the connection along with its capture file.
+func (c *captureConn) Close() error {
+	c.capture.closeConn(c)
+	return c.Conn.Close()
+}
@@ -0,0 +1,225 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"net"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// readPcapng returns the packets of the enhanced packet blocks of the passed
+// pcapng capture after checking it holds raw IP packets.
+func readPcapng(t *testing.T, path string) [][]byte {
+	t.Helper()
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	var packets [][]byte
+	for len(data) > 0 {
+		if len(data) < 12 {
+			t.Fatalf("truncated block")
+		}
+		blockType := binary.LittleEndian.Uint32(data[0:4])
+		length := binary.LittleEndian.Uint32(data[4:8])
+		if int(length) > len(data) || length%4 != 0 ||
+			binary.LittleEndian.Uint32(data[length-4:]) != length {
+
+			t.Fatalf("malformed block of type %x", blockType)
+		}
+		block := data[:length]
+		data = data[length:]
+		switch blockType {
+		case pcapngSectionHeader:
+			if binary.LittleEndian.Uint32(block[8:12]) != pcapngByteOrderMagic {
+				t.Fatalf("bad byte order magic")
+			}
+		case pcapngInterfaceDesc:
+			if binary.LittleEndian.Uint16(block[8:10]) != pcapngLinkTypeRaw {
+				t.Fatalf("bad link type")
+			}
+		case pcapngEnhancedPacket:
+			capLen := binary.LittleEndian.Uint32(block[20:24])
+			packets = append(packets, block[28:28+capLen])
+		default:
+			t.Fatalf("unexpected block of type %x", blockType)
+		}
+	}
+	return packets
+}
+
+// TestMsgCapture ensures the messages exchanged over a connection are captured
+// as TCP packets while the capture is running, with the redactions applied.
+func TestMsgCapture(t *testing.T) {
+	// The log rotator is not initialized in tests.
+	setLogLevels("off")
+	defer setLogLevels(defaultLogLevel)
+
+	dir, err := ioutil.TempDir("", "pktd-msgcapture")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	defer listener.Close()
+	rawConn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	remote, err := listener.Accept()
+	if err != nil {
+		t.Fatalf("Accept: %v", err)
+	}
+	defer remote.Close()
+
+	capture := newMsgCapture(dir, nil)
+	conn := capture.WrapConn(rawConn, false)
+	defer conn.Close()
+
+	const pver = wire.ProtocolVersion
+	btcnet := wire.MainNet
+	send := func(msg wire.Message) {
+		t.Helper()
+		if err := wire.WriteMessage(conn, msg, pver, btcnet); err != nil {
+			t.Fatalf("WriteMessage: %v", err)
+		}
+		if _, _, err := wire.ReadMessage(remote, pver, btcnet); err != nil {
+			t.Fatalf("ReadMessage: %v", err)
+		}
+	}
+	receive := func(msg wire.Message) {
+		t.Helper()
+		if err := wire.WriteMessage(remote, msg, pver, btcnet); err != nil {
+			t.Fatalf("WriteMessage: %v", err)
+		}
+		if _, _, err := wire.ReadMessage(conn, pver, btcnet); err != nil {
+			t.Fatalf("ReadMessage: %v", err)
+		}
+	}
+
+	// Nothing is captured before the capture is started.
+	send(wire.NewMsgPing(1))
+	if status := capture.Status(); status.Enabled || len(status.Files) != 0 {
+		t.Fatalf("unexpected status %+v", status)
+	}
+
+	redact, err := parseMsgCaptureRedaction([]string{"tx"})
+	if err != nil {
+		t.Fatalf("parseMsgCaptureRedaction: %v", err)
+	}
+	if err := capture.Start(redact); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	tx := wire.NewMsgTx(wire.TxVersion)
+	tx.AddTxOut(wire.NewTxOut(1, []byte{0x51}))
+	inv := wire.NewMsgInv()
+	for i := 0; i < 2000; i++ {
+		hash := chainhash.Hash{byte(i), byte(i >> 8)}
+		inv.AddInvVect(wire.NewInvVect(wire.InvTypeTx, &hash))
+	}
+	send(wire.NewMsgPing(2))
+	send(tx)
+	send(inv)
+	receive(wire.NewMsgPong(2))
+
+	status := capture.Status()
+	if !status.Enabled || len(status.Files) != 1 || status.Messages != 4 ||
+		!reflect.DeepEqual(status.Redact, []string{"tx"}) {
+
+		t.Fatalf("unexpected status %+v", status)
+	}
+	path := status.Files[0]
+	capture.Stop()
+	if status := capture.Status(); status.Enabled || len(status.Files) != 0 {
+		t.Fatalf("unexpected status %+v", status)
+	}
+
+	// Reassemble both directions of the connection from the packets.
+	localAddr := rawConn.LocalAddr().(*net.TCPAddr)
+	remoteAddr := rawConn.RemoteAddr().(*net.TCPAddr)
+	var sent, received bytes.Buffer
+	for _, packet := range readPcapng(t, path) {
+		if packet[0] != 0x45 || int(binary.BigEndian.Uint16(packet[2:4])) != len(packet) ||
+			ipv4Checksum(packet[:20]) != 0 {
+
+			t.Fatalf("malformed IPv4 header %x", packet[:20])
+		}
+		src, srcPort := net.IP(packet[12:16]), binary.BigEndian.Uint16(packet[20:22])
+		payload := packet[40:]
+		switch {
+		case src.Equal(localAddr.IP) && int(srcPort) == localAddr.Port:
+			sent.Write(payload)
+		case src.Equal(remoteAddr.IP) && int(srcPort) == remoteAddr.Port:
+			received.Write(payload)
+		default:
+			t.Fatalf("unexpected source %v:%d", src, srcPort)
+		}
+		if len(payload) > msgCaptureMaxSegment {
+			t.Fatalf("got segment of %d bytes", len(payload))
+		}
+	}
+
+	// The payload of the transaction is left out.
+	msg, _, err := wire.ReadMessage(&sent, pver, btcnet)
+	if ping, ok := msg.(*wire.MsgPing); err != nil || !ok || ping.Nonce != 2 {
+		t.Fatalf("got message %v (%v), want ping 2", msg, err)
+	}
+	hdr := sent.Next(wire.MessageHeaderSize)
+	if !strings.HasPrefix(string(hdr[4:16]), "tx\x00") ||
+		binary.LittleEndian.Uint32(hdr[16:20]) != 0 ||
+		!bytes.Equal(hdr[20:24], chainhash.DoubleHashB(nil)[:4]) {
+
+		t.Fatalf("got redacted header %x", hdr)
+	}
+	msg, _, err = wire.ReadMessage(&sent, pver, btcnet)
+	if err != nil || !reflect.DeepEqual(msg, inv) {
+		t.Fatalf("got message %v (%v), want the inv", msg, err)
+	}
+	msg, _, err = wire.ReadMessage(&received, pver, btcnet)
+	if pong, ok := msg.(*wire.MsgPong); err != nil || !ok || pong.Nonce != 2 {
+		t.Fatalf("got message %v (%v), want pong 2", msg, err)
+	}
+	if sent.Len() != 0 || received.Len() != 0 {
+		t.Fatalf("got %d and %d extra bytes", sent.Len(), received.Len())
+	}
+
+	// Redacted peer addresses are replaced with placeholders.
+	redact, err = parseMsgCaptureRedaction([]string{msgCaptureRedactPeers})
+	if err != nil {
+		t.Fatalf("parseMsgCaptureRedaction: %v", err)
+	}
+	if err := capture.Start(redact); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	send(wire.NewMsgPing(3))
+	status = capture.Status()
+	if len(status.Files) != 1 || !strings.Contains(status.Files[0], "-peer") {
+		t.Fatalf("unexpected status %+v", status)
+	}
+	packets := readPcapng(t, status.Files[0])
+	if len(packets) != 1 || !net.IP(packets[0][12:16]).Equal(msgCaptureLocalIP) ||
+		!net.IP(packets[0][16:20]).Equal(net.IPv4(198, 18, 0, 2)) {
+
+		t.Fatalf("unexpected packets %x", packets)
+	}
+	capture.Stop()
+
+	if _, err := parseMsgCaptureRedaction([]string{"toolongcommand"}); err == nil {
+		t.Fatal("parseMsgCaptureRedaction accepted an invalid command")
+	}
+}
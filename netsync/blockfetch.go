@@ -0,0 +1,181 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package netsync
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+)
+
+const (
+	// blockFetchSampleInterval is the interval at which the pending block
+	// requests are checked for timeouts.
+	blockFetchSampleInterval = 2 * time.Second
+
+	// minBlockFetchTimeout is the minimum time after which a block not
+	// delivered by the peer it was requested from is requested from
+	// another peer.
+	minBlockFetchTimeout = 5 * time.Second
+
+	// maxBlockFetchTimeout is the maximum time after which a block not
+	// delivered by the peer it was requested from is requested from
+	// another peer.  Requests are forgotten after twice this time.
+	maxBlockFetchTimeout = 2 * time.Minute
+
+	// blockFetchTimeoutFactor is the number of times its usual delivery
+	// time a peer is given to deliver a block.
+	blockFetchTimeoutFactor = 4
+
+	// initialBlockDelay is the delivery time assumed for a peer which did
+	// not deliver any block yet.
+	initialBlockDelay = 2 * time.Second
+
+	// slowPeerFactor is the number of times the delivery time of the
+	// fastest peer after which a peer is considered slow.  The blocks near
+	// the tip are requested from faster peers when possible and a slow
+	// peer is not given more than this times the delivery time of the
+	// fastest peer to deliver one.
+	slowPeerFactor = 3
+
+	// maxBlockFetchTimeouts is the maximum number of timeouts penalizing
+	// the delivery time of a peer.
+	maxBlockFetchTimeouts = 8
+
+	// blockFetchAvgWeight is the weight of a new sample in the moving
+	// averages of the block delivery statistics.
+	blockFetchAvgWeight = 0.2
+)
+
+// blockRequest is a block requested from a peer.
+type blockRequest struct {
+	time time.Time
+
+	// timedOut is set once the block was requested from another peer
+	// after the peer did not deliver it in time and retry is set when
+	// the request is such a request to another peer.
+	timedOut bool
+	retry    bool
+}
+
+// blockFetchStats are the block delivery statistics of a peer, used to
+// prefer the fastest peers when following the tip of the chain and to time
+// out the block requests adaptively.
+type blockFetchStats struct {
+	requests map[chainhash.Hash]*blockRequest
+
+	blocks     uint64
+	delay      time.Duration
+	throughput float64
+	proofSize  float64
+	timeouts   uint32
+}
+
+// newBlockFetchStats returns the block delivery statistics of a new peer.
+func newBlockFetchStats() *blockFetchStats {
+	return &blockFetchStats{
+		requests: make(map[chainhash.Hash]*blockRequest),
+	}
+}
+
+// request records the passed block as requested from the peer at the passed
+// time, retry being set when it was requested from another peer before.
+func (s *blockFetchStats) request(hash *chainhash.Hash, now time.Time, retry bool) {
+	s.requests[*hash] = &blockRequest{time: now, retry: retry}
+}
+
+// deliver records the delivery at the passed time of the passed block, of the
+// passed size and PacketCrypt proof size, and returns whether the block was
+// also requested from another peer.
+func (s *blockFetchStats) deliver(hash *chainhash.Hash, size, proofSize int,
+	now time.Time) bool {
+
+	req, ok := s.requests[*hash]
+	if !ok {
+		return false
+	}
+	delete(s.requests, *hash)
+
+	elapsed := now.Sub(req.time)
+	if elapsed < time.Millisecond {
+		elapsed = time.Millisecond
+	}
+	throughput := float64(size) / elapsed.Seconds()
+	if s.blocks == 0 {
+		s.delay = elapsed
+		s.throughput = throughput
+		s.proofSize = float64(proofSize)
+	} else {
+		s.delay += time.Duration(blockFetchAvgWeight *
+			float64(elapsed-s.delay))
+		s.throughput += blockFetchAvgWeight * (throughput - s.throughput)
+		s.proofSize += blockFetchAvgWeight *
+			(float64(proofSize) - s.proofSize)
+	}
+	s.blocks++
+
+	// Blocks delivered in time make up for previous timeouts.
+	if !req.timedOut && s.timeouts > 0 {
+		s.timeouts--
+	}
+	return req.timedOut || req.retry
+}
+
+// expectedDelay returns the time the peer is expected to take to deliver a
+// block, penalized by its recent timeouts.
+func (s *blockFetchStats) expectedDelay() time.Duration {
+	delay := initialBlockDelay
+	if s.blocks > 0 {
+		delay = s.delay
+	}
+	return delay * time.Duration(1+s.timeouts)
+}
+
+// expired returns the blocks requested before the passed timeout which were not
+// requested from another peer yet, marking them as such.
+func (s *blockFetchStats) expired(now time.Time, timeout time.Duration) []chainhash.Hash {
+	var expired []chainhash.Hash
+	for hash, req := range s.requests {
+		elapsed := now.Sub(req.time)
+		if req.timedOut {
+			if elapsed > 2*maxBlockFetchTimeout {
+				delete(s.requests, hash)
+			}
+			continue
+		}
+		if elapsed > timeout {
+			req.timedOut = true
+			expired = append(expired, hash)
+		}
+	}
+	if len(expired) > 0 && s.timeouts < maxBlockFetchTimeouts {
+		s.timeouts++
+	}
+	return expired
+}
+
+// String returns the block delivery statistics as a human-readable string.
+func (s *blockFetchStats) String() string {
+	return fmt.Sprintf("%d blocks, delay %v, %.0f bytes/s, proofs of %.0f "+
+		"bytes, %d timeouts", s.blocks, s.expectedDelay(), s.throughput,
+		s.proofSize, s.timeouts)
+}
+
+// blockFetchTimeout returns the time a peer with the passed expected delivery
+// time is given to deliver a block when the fastest peer has the passed one.
+func blockFetchTimeout(delay, fastest time.Duration) time.Duration {
+	if fastest > 0 && delay > slowPeerFactor*fastest {
+		delay = slowPeerFactor * fastest
+	}
+	timeout := blockFetchTimeoutFactor * delay
+	if timeout < minBlockFetchTimeout {
+		timeout = minBlockFetchTimeout
+	}
+	if timeout > maxBlockFetchTimeout {
+		timeout = maxBlockFetchTimeout
+	}
+	return timeout
+}
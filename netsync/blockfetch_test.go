@@ -0,0 +1,81 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package netsync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+)
+
+// TestBlockFetchStats ensures the block delivery statistics of a peer track its
+// delivery time and that the block requests time out adaptively.
+func TestBlockFetchStats(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	hashes := make([]chainhash.Hash, 4)
+	for i := range hashes {
+		hashes[i][0] = byte(i + 1)
+	}
+
+	s := newBlockFetchStats()
+	if s.expectedDelay() != initialBlockDelay {
+		t.Fatalf("got expected delay %v for a new peer", s.expectedDelay())
+	}
+	s.request(&hashes[0], now, false)
+	if s.deliver(&hashes[0], 1000000, 5000, now.Add(time.Second)) {
+		t.Fatal("block delivered only by the peer reported as redundant")
+	}
+	s.request(&hashes[1], now, false)
+	s.deliver(&hashes[1], 1000000, 10000, now.Add(3*time.Second))
+	if s.blocks != 2 || s.expectedDelay() != 1400*time.Millisecond ||
+		s.proofSize != 6000 {
+
+		t.Fatalf("unexpected statistics: %v", s)
+	}
+	if s.deliver(&hashes[2], 1000, 0, now) || s.blocks != 2 {
+		t.Fatal("unrequested block delivery recorded")
+	}
+
+	// Expired requests are only returned once and penalize the peer.
+	s.request(&hashes[2], now, false)
+	s.request(&hashes[3], now, true)
+	if expired := s.expired(now.Add(5*time.Second), 5*time.Second); len(expired) != 0 {
+		t.Fatalf("unexpected expired requests %v", expired)
+	}
+	expired := s.expired(now.Add(6*time.Second), 5*time.Second)
+	if len(expired) != 2 || s.timeouts != 1 ||
+		s.expectedDelay() != 2800*time.Millisecond {
+
+		t.Fatalf("got expired requests %v with %v", expired, s)
+	}
+	if expired := s.expired(now.Add(7*time.Second), 5*time.Second); len(expired) != 0 {
+		t.Fatalf("requests expired twice: %v", expired)
+	}
+	if !s.deliver(&hashes[2], 1000000, 0, now.Add(7*time.Second)) ||
+		s.timeouts != 1 {
+
+		t.Fatal("late block not reported as redundant")
+	}
+	s.expired(now.Add(2*maxBlockFetchTimeout+time.Hour), time.Hour)
+	if len(s.requests) != 0 {
+		t.Fatalf("got %d stale requests", len(s.requests))
+	}
+
+	tests := []struct {
+		delay, fastest, want time.Duration
+	}{
+		{time.Second, 0, minBlockFetchTimeout},
+		{10 * time.Second, 0, 40 * time.Second},
+		{10 * time.Second, 2 * time.Second, 24 * time.Second},
+		{time.Hour, 0, maxBlockFetchTimeout},
+	}
+	for _, test := range tests {
+		if got := blockFetchTimeout(test.delay, test.fastest); got != test.want {
+			t.Errorf("blockFetchTimeout(%v, %v): got %v, want %v",
+				test.delay, test.fastest, got, test.want)
+		}
+	}
+}
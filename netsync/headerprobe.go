@@ -0,0 +1,314 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package netsync
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+const (
+	// DefaultHeaderProbePeers is the default number of peers whose chain
+	// is probed in parallel during the initial block download.
+	DefaultHeaderProbePeers = 4
+
+	// headerAnchorInterval is the number of heights between two anchors,
+	// the header hashes recorded to compare the chains of the probed
+	// peers.
+	headerAnchorInterval = 1000
+
+	// maxHeaderProbeOvershoot is the number of headers a peer may send
+	// past the height it announced while its chain is probed.
+	maxHeaderProbeOvershoot = wire.MaxBlockHeadersPerMsg
+
+	// headerProbeTimeout is the time after which a probed peer which did
+	// not send headers is no longer waited for.
+	headerProbeTimeout = 2 * time.Minute
+)
+
+// headerProbe is the state of the probe of the header chain of a peer.  Only
+// the last header is kept, along with the work claimed by the chain.
+type headerProbe struct {
+	id           int32
+	addr         string
+	maxHeight    int32
+	lastProgress time.Time
+
+	started    bool
+	lastHash   chainhash.Hash
+	lastHeight int32
+	lastBits   uint32
+	work       *big.Int
+	done       bool
+}
+
+// headerFork is a divergence between the header chains of two probed peers,
+// detected at the height of an anchor.  The chains forked at or below it.
+type headerFork struct {
+	height int32
+	addrs  [2]string
+}
+
+// headerBase looks up a block of the block index a probed chain connects to,
+// returning its height, difficulty bits and the work of the chain up to it.
+type headerBase func(hash *chainhash.Hash) (int32, uint32, *big.Int, bool)
+
+// headerProbeSet downloads the header chains of several peers in parallel from
+// the best block, without storing them, to learn the best chain tip and to
+// detect the peers serving diverging chains early in the initial block
+// download.  The chains are cross-checked at anchors, the header hashes at
+// every headerAnchorInterval heights.
+type headerProbeSet struct {
+	params *chaincfg.Params
+	base   headerBase
+
+	probes  map[int32]*headerProbe
+	anchors map[int32]map[chainhash.Hash][]int32
+	forks   map[[2]int32]int32
+}
+
+// newHeaderProbeSet returns an empty header probe set looking up the blocks
+// the chains connect to with the passed function.
+func newHeaderProbeSet(params *chaincfg.Params, base headerBase) *headerProbeSet {
+	return &headerProbeSet{
+		params:  params,
+		base:    base,
+		probes:  make(map[int32]*headerProbe),
+		anchors: make(map[int32]map[chainhash.Hash][]int32),
+		forks:   make(map[[2]int32]int32),
+	}
+}
+
+// add starts probing the chain of the passed peer, which announced the passed
+// height.
+func (s *headerProbeSet) add(id int32, addr string, height int32, now time.Time) {
+	s.probes[id] = &headerProbe{
+		id:           id,
+		addr:         addr,
+		maxHeight:    height + maxHeaderProbeOvershoot,
+		lastProgress: now,
+		work:         new(big.Int),
+	}
+}
+
+// has returns whether the chain of the passed peer is being probed.
+func (s *headerProbeSet) has(id int32) bool {
+	p, ok := s.probes[id]
+	return ok && !p.done
+}
+
+// remove stops probing the chain of the passed peer and forgets it.
+func (s *headerProbeSet) remove(id int32) {
+	if _, ok := s.probes[id]; !ok {
+		return
+	}
+	delete(s.probes, id)
+	for height, hashes := range s.anchors {
+		for hash, ids := range hashes {
+			for i, other := range ids {
+				if other == id {
+					ids = append(ids[:i], ids[i+1:]...)
+					break
+				}
+			}
+			if len(ids) == 0 {
+				delete(hashes, hash)
+			} else {
+				hashes[hash] = ids
+			}
+		}
+		if len(hashes) == 0 {
+			delete(s.anchors, height)
+		}
+	}
+	for pair := range s.forks {
+		if pair[0] == id || pair[1] == id {
+			delete(s.forks, pair)
+		}
+	}
+}
+
+// expire stops waiting for the probed peers which did not send headers since
+// the header probe timeout, returning their addresses.  Their chains are still
+// considered as probed so far.
+func (s *headerProbeSet) expire(now time.Time) []string {
+	var expired []string
+	for _, p := range s.probes {
+		if !p.done && now.Sub(p.lastProgress) > headerProbeTimeout {
+			p.done = true
+			expired = append(expired, p.addr)
+		}
+	}
+	return expired
+}
+
+// pending returns the number of peers whose chain is still being probed.
+func (s *headerProbeSet) pending() int {
+	n := 0
+	for _, p := range s.probes {
+		if !p.done {
+			n++
+		}
+	}
+	return n
+}
+
+// locator returns the block locator to request the next headers of the chain
+// of the passed peer with.
+func (s *headerProbeSet) locator(id int32, best blockchain.BlockLocator) blockchain.BlockLocator {
+	p := s.probes[id]
+	if p == nil || !p.started {
+		return best
+	}
+	hash := p.lastHash
+	return blockchain.BlockLocator([]*chainhash.Hash{&hash})
+}
+
+// process checks the passed headers sent by the passed peer extend its chain,
+// returning the forks with the chains of the other peers they reveal and
+// whether the probe of the chain of the peer is done.  An error is returned
+// when the headers are invalid, in which case the peer should be
+// disconnected.
+func (s *headerProbeSet) process(id int32, headers []*wire.BlockHeader,
+	now time.Time) ([]headerFork, bool, error) {
+
+	p := s.probes[id]
+	if p == nil || p.done {
+		return nil, true, nil
+	}
+	p.lastProgress = now
+
+	var forks []headerFork
+	for _, header := range headers {
+		hash := header.BlockHash()
+
+		// The chain starts at a block of the block index.
+		if !p.started {
+			height, bits, work, ok := s.base(&header.PrevBlock)
+			if !ok {
+				return nil, false, fmt.Errorf("header %v does "+
+					"not connect to a known block", hash)
+			}
+			p.started = true
+			p.lastHash = header.PrevBlock
+			p.lastHeight = height
+			p.lastBits = bits
+			p.work.Set(work)
+		}
+
+		height := p.lastHeight + 1
+		if header.PrevBlock != p.lastHash {
+			return nil, false, fmt.Errorf("header %v at height %d "+
+				"does not connect to the previous header", hash,
+				height)
+		}
+		if height > p.maxHeight {
+			return nil, false, fmt.Errorf("header %v at height %d "+
+				"is past the announced height of %d", hash,
+				height, p.maxHeight-maxHeaderProbeOvershoot)
+		}
+		target := blockchain.CompactToBig(header.Bits)
+		if target.Sign() <= 0 || target.Cmp(s.params.PowLimit) > 0 ||
+			!blockchain.PermittedDifficultyTransition(s.params,
+				height, p.lastBits, header.Bits) {
+
+			return nil, false, fmt.Errorf("header %v at height %d "+
+				"has an invalid difficulty of %08x", hash, height,
+				header.Bits)
+		}
+
+		p.lastHash = hash
+		p.lastHeight = height
+		p.lastBits = header.Bits
+		p.work.Add(p.work, blockchain.CalcWork(header.Bits))
+		if height%headerAnchorInterval == 0 {
+			forks = append(forks, s.anchor(p, height, &hash)...)
+		}
+	}
+
+	// The peer sent its last headers when it sent less than the maximum.
+	if len(headers) < wire.MaxBlockHeadersPerMsg {
+		p.done = true
+	}
+	return forks, p.done, nil
+}
+
+// anchor records the hash of the header of the chain of the passed probe at the
+// passed anchor height, returning the forks with the chains of the other peers
+// it reveals.
+func (s *headerProbeSet) anchor(p *headerProbe, height int32,
+	hash *chainhash.Hash) []headerFork {
+
+	hashes := s.anchors[height]
+	if hashes == nil {
+		hashes = make(map[chainhash.Hash][]int32)
+		s.anchors[height] = hashes
+	}
+	hashes[*hash] = append(hashes[*hash], p.id)
+
+	var forks []headerFork
+	for other, ids := range hashes {
+		if other == *hash {
+			continue
+		}
+		for _, id := range ids {
+			pair := [2]int32{p.id, id}
+			if id < p.id {
+				pair = [2]int32{id, p.id}
+			}
+			if _, ok := s.forks[pair]; ok {
+				continue
+			}
+			s.forks[pair] = height
+			addr := ""
+			if o := s.probes[id]; o != nil {
+				addr = o.addr
+			}
+			forks = append(forks, headerFork{
+				height: height,
+				addrs:  [2]string{p.addr, addr},
+			})
+		}
+	}
+	return forks
+}
+
+// best returns the probe of the chain with the most work, or nil when no chain
+// was probed.
+func (s *headerProbeSet) best() *headerProbe {
+	var best *headerProbe
+	for _, p := range s.probes {
+		if !p.started {
+			continue
+		}
+		if best == nil || p.work.Cmp(best.work) > 0 {
+			best = p
+		}
+	}
+	return best
+}
+
+// onBestChain returns whether the chain of the passed peer did not diverge
+// from the chain with the most work, or has as much work.  Peers whose chain
+// was not probed are considered on the best chain.
+func (s *headerProbeSet) onBestChain(id int32) bool {
+	best := s.best()
+	p := s.probes[id]
+	if best == nil || p == nil || !p.started || p.work.Cmp(best.work) >= 0 {
+		return true
+	}
+	pair := [2]int32{p.id, best.id}
+	if best.id < p.id {
+		pair = [2]int32{best.id, p.id}
+	}
+	_, forked := s.forks[pair]
+	return !forked
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package netsync
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// makeHeaders returns a chain of headers after the passed block, the nonce
+// making chains with the same parent differ.
+func makeHeaders(prev chainhash.Hash, bits uint32, n int, nonce uint32) []*wire.BlockHeader {
+	headers := make([]*wire.BlockHeader, 0, n)
+	for i := 0; i < n; i++ {
+		header := &wire.BlockHeader{
+			Version:   1,
+			PrevBlock: prev,
+			Timestamp: time.Unix(1600000000+int64(i)*600, 0),
+			Bits:      bits,
+			Nonce:     nonce,
+		}
+		headers = append(headers, header)
+		prev = header.BlockHash()
+	}
+	return headers
+}
+
+// TestHeaderProbes ensures the header chains of the probed peers are checked,
+// cross-checked at the anchors and that the chain with the most work is found.
+func TestHeaderProbes(t *testing.T) {
+	params := &chaincfg.MainNetParams
+	bits := params.PowLimitBits
+	genesis := *params.GenesisHash
+	base := func(hash *chainhash.Hash) (int32, uint32, *big.Int, bool) {
+		if *hash != genesis {
+			return 0, 0, nil, false
+		}
+		return 0, bits, blockchain.CalcWork(bits), true
+	}
+	chain := makeHeaders(genesis, bits, 2500, 0)
+	other := makeHeaders(genesis, bits, 1200, 1)
+
+	now := time.Unix(1600000000, 0)
+	s := newHeaderProbeSet(params, base)
+	s.add(1, "peerA", 2500, now)
+	s.add(2, "peerB", 2500, now)
+	s.add(3, "peerC", 1200, now)
+	s.add(4, "peerD", 2500, now)
+	s.add(5, "peerE", 100, now)
+	s.add(6, "peerF", 2500, now)
+
+	best := blockchain.BlockLocator([]*chainhash.Hash{&genesis})
+	if loc := s.locator(1, best); len(loc) != 1 || *loc[0] != genesis {
+		t.Fatalf("unexpected locator %v", loc)
+	}
+
+	process := func(id int32, headers []*wire.BlockHeader, wantForks int,
+		wantDone bool) {
+
+		t.Helper()
+		forks, done, err := s.process(id, headers, now)
+		if err != nil {
+			t.Fatalf("process: %v", err)
+		}
+		if len(forks) != wantForks || done != wantDone {
+			t.Fatalf("got forks %v, done %v", forks, done)
+		}
+	}
+	process(1, chain[:2000], 0, false)
+	if loc := s.locator(1, best); *loc[0] != chain[1999].BlockHash() {
+		t.Fatalf("unexpected locator %v", loc)
+	}
+	process(2, chain[:2000], 0, false)
+
+	// The chain of peer C diverges from those of peers A and B.
+	process(3, other, 2, true)
+	process(1, chain[2000:], 0, true)
+	process(2, chain[2000:], 0, true)
+
+	// Invalid chains are rejected.
+	if _, _, err := s.process(4, chain[1:10], now); err == nil {
+		t.Fatal("accepted a chain not connecting to a known block")
+	}
+	if _, _, err := s.process(5, chain[:2000], now); err != nil {
+		t.Fatalf("process: %v", err)
+	}
+	if _, _, err := s.process(5, chain[2000:], now); err == nil {
+		t.Fatal("accepted headers past the announced height")
+	}
+	s.remove(4)
+	s.remove(5)
+
+	// Peers which stall are no longer waited for.
+	if s.pending() != 1 || !s.has(6) {
+		t.Fatalf("got %d pending probes", s.pending())
+	}
+	if expired := s.expire(now.Add(headerProbeTimeout)); len(expired) != 0 {
+		t.Fatalf("unexpected expired probes %v", expired)
+	}
+	expired := s.expire(now.Add(headerProbeTimeout + time.Second))
+	if len(expired) != 1 || expired[0] != "peerF" || s.pending() != 0 {
+		t.Fatalf("unexpected expired probes %v", expired)
+	}
+
+	// The chain of peers A and B has the most work.
+	b := s.best()
+	wantWork := new(big.Int).Mul(blockchain.CalcWork(bits), big.NewInt(2501))
+	if b == nil || (b.id != 1 && b.id != 2) || b.lastHeight != 2500 ||
+		b.work.Cmp(wantWork) != 0 {
+
+		t.Fatalf("unexpected best probe %+v", b)
+	}
+	for id, want := range map[int32]bool{1: true, 2: true, 3: false, 6: true} {
+		if got := s.onBestChain(id); got != want {
+			t.Errorf("onBestChain(%d): got %v, want %v", id, got, want)
+		}
+	}
+	s.remove(3)
+	if !s.onBestChain(3) || len(s.forks) != 0 {
+		t.Fatal("the forks of a removed peer were kept")
+	}
+}
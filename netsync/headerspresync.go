@@ -0,0 +1,253 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package netsync
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/spv"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+const (
+	// headerCommitmentPeriod is the number of headers between two
+	// commitments made while pre-synchronizing headers.  Each commitment
+	// is a single bit, so the memory used by the pre-synchronization is a
+	// small fraction of the size of the headers.
+	headerCommitmentPeriod = 584
+
+	// redownloadBufferSize is the number of redownloaded headers which are
+	// held back until the commitments after them were checked.  A peer
+	// serving another chain than the pre-synchronized one during the
+	// redownload has a chance of 1 in 2^(redownloadBufferSize /
+	// headerCommitmentPeriod) of having any of its headers stored.
+	redownloadBufferSize = 14016
+)
+
+// headersPresyncPhase is the phase of a headers pre-synchronization.
+type headersPresyncPhase int
+
+const (
+	// presyncPhase is the first phase, during which the headers up to the
+	// target checkpoint are downloaded and checked, only committing to
+	// them.
+	presyncPhase headersPresyncPhase = iota
+
+	// redownloadPhase is the second phase, during which the headers are
+	// downloaded again and released for storage once checked against the
+	// commitments.
+	redownloadPhase
+)
+
+// String returns the phase as a human-readable string.
+func (p headersPresyncPhase) String() string {
+	if p == presyncPhase {
+		return "presync"
+	}
+	return "redownload"
+}
+
+// headersPresync downloads the headers from a block of the main chain up to a
+// checkpoint twice before storing any of them, so a peer feeding a chain which
+// never reaches the checkpoint can't make the node use memory for its headers.
+//
+// The first pass checks the headers connect, that their difficulty is valid
+// and that the chain reaches the checkpoint, accumulating the work the headers
+// claim and keeping a single bit salted commitment every headerCommitmentPeriod
+// headers.  The second pass downloads the headers again and checks them against
+// the commitments, releasing them for storage once enough of the commitments
+// after them were checked.
+type headersPresync struct {
+	params     *chaincfg.Params
+	checkpoint *chaincfg.Checkpoint
+	salt       [16]byte
+	offset     int32
+
+	startHash   chainhash.Hash
+	startHeight int32
+	startBits   uint32
+
+	phase       headersPresyncPhase
+	commitments []byte
+	committed   int
+	work        *big.Int
+
+	// The last header processed in the current phase, along with the
+	// next commitment to check and the headers held back in the
+	// redownload phase.
+	lastHash   chainhash.Hash
+	lastHeight int32
+	lastBits   uint32
+	checked    int
+	buffer     []*wire.BlockHeader
+}
+
+// newHeadersPresync returns a headers pre-synchronization of the headers after
+// the passed block of the main chain up to the passed checkpoint.
+func newHeadersPresync(params *chaincfg.Params, checkpoint *chaincfg.Checkpoint,
+	startHash *chainhash.Hash, startHeight int32, startBits uint32) *headersPresync {
+
+	p := &headersPresync{
+		params:      params,
+		checkpoint:  checkpoint,
+		startHash:   *startHash,
+		startHeight: startHeight,
+		startBits:   startBits,
+	}
+	if _, err := rand.Read(p.salt[:]); err != nil {
+		log.Warnf("Unable to generate the headers commitment salt: %v",
+			err)
+	}
+	p.offset = int32(binary.LittleEndian.Uint32(p.salt[:4]) %
+		headerCommitmentPeriod)
+	p.work = new(big.Int)
+	p.reset(presyncPhase)
+	return p
+}
+
+// reset starts the passed phase from the first block.
+func (p *headersPresync) reset(phase headersPresyncPhase) {
+	p.phase = phase
+	p.lastHash = p.startHash
+	p.lastHeight = p.startHeight
+	p.lastBits = p.startBits
+	p.checked = 0
+	p.buffer = nil
+}
+
+// locator returns the block locator to request the next headers with.
+func (p *headersPresync) locator() blockchain.BlockLocator {
+	hash := p.lastHash
+	return blockchain.BlockLocator([]*chainhash.Hash{&hash})
+}
+
+// commitment returns the salted commitment to the passed header hash.
+func (p *headersPresync) commitment(hash *chainhash.Hash) bool {
+	var buf [len(p.salt) + chainhash.HashSize]byte
+	copy(buf[:], p.salt[:])
+	copy(buf[len(p.salt):], hash[:])
+	return chainhash.HashB(buf[:])[0]&1 == 1
+}
+
+// isCommitmentHeight returns whether a commitment is made to the header at the
+// passed height.
+func (p *headersPresync) isCommitmentHeight(height int32) bool {
+	return height%headerCommitmentPeriod == p.offset
+}
+
+// checkHeader ensures the passed header connects to the last processed one and
+// has a valid difficulty, returning its hash.
+func (p *headersPresync) checkHeader(header *wire.BlockHeader) (chainhash.Hash, error) {
+	hash, err := spv.CheckHeader(p.params, header, p.lastHeight+1,
+		&p.lastHash, p.lastBits)
+	if err != nil {
+		return hash, err
+	}
+	if p.lastHeight+1 == p.checkpoint.Height &&
+		!hash.IsEqual(p.checkpoint.Hash) {
+
+		return hash, fmt.Errorf("header %v at height %d does not "+
+			"match the checkpoint hash of %v", hash,
+			p.lastHeight+1, p.checkpoint.Hash)
+	}
+	return hash, nil
+}
+
+// process processes the passed headers, which must follow the last processed
+// header, and returns the headers released for storage.  The headers after
+// the checkpoint are ignored.  An error is returned when the headers are
+// invalid or don't match the commitments, in which case the peer which sent
+// them should be disconnected.
+func (p *headersPresync) process(headers []*wire.BlockHeader) ([]*wire.BlockHeader, error) {
+	var released []*wire.BlockHeader
+	for _, header := range headers {
+		if p.lastHeight >= p.checkpoint.Height {
+			break
+		}
+		hash, err := p.checkHeader(header)
+		if err != nil {
+			return nil, err
+		}
+		height := p.lastHeight + 1
+
+		switch p.phase {
+		case presyncPhase:
+			p.work.Add(p.work, blockchain.CalcWork(header.Bits))
+			if p.isCommitmentHeight(height) {
+				if p.committed%8 == 0 {
+					p.commitments = append(p.commitments, 0)
+				}
+				if p.commitment(&hash) {
+					p.commitments[p.committed/8] |=
+						1 << uint(p.committed%8)
+				}
+				p.committed++
+			}
+
+		case redownloadPhase:
+			if p.isCommitmentHeight(height) {
+				want := p.commitments[p.checked/8]&
+					(1<<uint(p.checked%8)) != 0
+				if p.commitment(&hash) != want {
+					return nil, fmt.Errorf("redownloaded "+
+						"header %v at height %d does "+
+						"not match the commitment", hash,
+						height)
+				}
+				p.checked++
+			}
+			p.buffer = append(p.buffer, header)
+		}
+
+		p.lastHash = hash
+		p.lastHeight = height
+		p.lastBits = header.Bits
+	}
+
+	// Release the redownloaded headers the buffer holds too many of, or all
+	// of them once the checkpoint is reached.
+	if p.phase == redownloadPhase {
+		n := len(p.buffer) - redownloadBufferSize
+		if p.Done() {
+			n = len(p.buffer)
+		}
+		if n > 0 {
+			released = p.buffer[:n:n]
+			p.buffer = p.buffer[n:]
+		}
+	}
+
+	// Start the redownload once the checkpoint is reached.
+	if p.phase == presyncPhase && p.lastHeight >= p.checkpoint.Height {
+		p.reset(redownloadPhase)
+	}
+	return released, nil
+}
+
+// Phase returns the current phase of the pre-synchronization.
+func (p *headersPresync) Phase() headersPresyncPhase {
+	return p.phase
+}
+
+// Height returns the height of the last header processed in the current phase.
+func (p *headersPresync) Height() int32 {
+	return p.lastHeight
+}
+
+// Work returns the work claimed by the headers of the pre-synchronized chain.
+func (p *headersPresync) Work() *big.Int {
+	return new(big.Int).Set(p.work)
+}
+
+// Done returns whether all the headers up to the checkpoint were redownloaded.
+func (p *headersPresync) Done() bool {
+	return p.phase == redownloadPhase && p.lastHeight >= p.checkpoint.Height
+}
@@ -0,0 +1,233 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package netsync
+
+import (
+	"container/list"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	peerpkg "github.com/pkt-cash/pktd/peer"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// TestHeadersPresync ensures the headers are only released for storage once
+// they were downloaded twice, the redownloaded ones matching the commitments.
+func TestHeadersPresync(t *testing.T) {
+	params := &chaincfg.MainNetParams
+	bits := params.PowLimitBits
+	start := *params.GenesisHash
+	const numHeaders = redownloadBufferSize + 3000
+	headers := makeHeaders(start, bits, numHeaders, 0)
+	checkpoint := &chaincfg.Checkpoint{
+		Height: numHeaders,
+		Hash:   &chainhash.Hash{},
+	}
+	*checkpoint.Hash = headers[numHeaders-1].BlockHash()
+	newPresync := func() *headersPresync {
+		p := newHeadersPresync(params, checkpoint, &start, 0, bits)
+		p.salt = [16]byte{0x01}
+		p.offset = 7
+		return p
+	}
+
+	// The headers are checked while pre-synchronizing.
+	tests := []struct {
+		name    string
+		headers []*wire.BlockHeader
+	}{
+		{"not connecting", headers[1:2]},
+		{"invalid difficulty", makeHeaders(start, bits+1, 1, 0)},
+		{"changed difficulty", makeHeaders(start, bits-0x10000, 1, 0)},
+	}
+	for _, test := range tests {
+		if _, err := newPresync().process(test.headers); err == nil {
+			t.Errorf("%s: no error", test.name)
+		}
+	}
+	other := makeHeaders(start, bits, numHeaders, 1)
+	p := newPresync()
+	if _, err := p.process(other); err == nil {
+		t.Error("other chain reaching the checkpoint height: no error")
+	}
+
+	// Nothing is released before the redownload.
+	p = newPresync()
+	for i := 0; i < numHeaders; i += wire.MaxBlockHeadersPerMsg {
+		end := i + wire.MaxBlockHeadersPerMsg
+		if end > numHeaders {
+			end = numHeaders
+		}
+		if p.Phase() != presyncPhase || p.Height() != int32(i) {
+			t.Fatalf("got phase %v at height %d before header %d",
+				p.Phase(), p.Height(), i)
+		}
+		released, err := p.process(headers[i:end])
+		if err != nil || len(released) != 0 {
+			t.Fatalf("process: released %d headers (%v)",
+				len(released), err)
+		}
+	}
+	wantWork := new(big.Int).Mul(blockchain.CalcWork(bits),
+		big.NewInt(numHeaders))
+	if p.Phase() != redownloadPhase || p.Height() != 0 ||
+		p.Work().Cmp(wantWork) != 0 || p.Done() {
+
+		t.Fatalf("got phase %v at height %d with work %v after presync",
+			p.Phase(), p.Height(), p.Work())
+	}
+	if hash := p.locator()[0]; *hash != start {
+		t.Fatalf("got locator %v, want %v", hash, start)
+	}
+
+	// Another chain served during the redownload fails the commitments
+	// before any of its headers are released.
+	alt := *p
+	alt.commitments = append([]byte(nil), p.commitments...)
+	if released, err := alt.process(other[:redownloadBufferSize+
+		headerCommitmentPeriod]); err == nil || len(released) != 0 {
+
+		t.Fatalf("other chain redownloaded: released %d headers (%v)",
+			len(released), err)
+	}
+
+	// The redownloaded headers are released in order, holding back the
+	// buffer until the checkpoint.
+	var stored []*wire.BlockHeader
+	for i := 0; i < numHeaders; i += wire.MaxBlockHeadersPerMsg {
+		end := i + wire.MaxBlockHeadersPerMsg
+		if end > numHeaders {
+			end = numHeaders
+		}
+		released, err := p.process(headers[i:end])
+		if err != nil {
+			t.Fatalf("process: %v", err)
+		}
+		stored = append(stored, released...)
+		if want := end - redownloadBufferSize; !p.Done() && want > 0 &&
+			len(stored) != want {
+
+			t.Fatalf("got %d released headers, want %d",
+				len(stored), want)
+		}
+	}
+	if !p.Done() || len(stored) != numHeaders {
+		t.Fatalf("got %d released headers, done %v", len(stored),
+			p.Done())
+	}
+	for i := range stored {
+		if stored[i] != headers[i] {
+			t.Fatalf("released header %d out of order", i)
+		}
+	}
+}
+
+// TestHandleHeadersPresync ensures the sync manager only stores the headers of
+// the headers-first mode once they were pre-synchronized, and stores none of
+// the headers of a peer serving another chain.
+func TestHandleHeadersPresync(t *testing.T) {
+	DisableLog()
+	params := &chaincfg.MainNetParams
+	bits := params.PowLimitBits
+	start := *params.GenesisHash
+	const numHeaders = redownloadBufferSize + 3000
+	headers := makeHeaders(start, bits, numHeaders, 0)
+	checkpoint := &chaincfg.Checkpoint{
+		Height: numHeaders,
+		Hash:   &chainhash.Hash{},
+	}
+	*checkpoint.Hash = headers[numHeaders-1].BlockHash()
+
+	newPeer := func() *peerpkg.Peer {
+		peer, err := peerpkg.NewOutboundPeer(&peerpkg.Config{},
+			"127.0.0.1:8333")
+		if err != nil {
+			t.Fatalf("NewOutboundPeer: %v", err)
+		}
+		return peer
+	}
+	newManager := func(peer *peerpkg.Peer) *SyncManager {
+		sm := &SyncManager{
+			chainParams:    params,
+			peerStates:     map[*peerpkg.Peer]*peerSyncState{peer: {}},
+			headerList:     list.New(),
+			nextCheckpoint: checkpoint,
+		}
+		sm.resetHeaderState(&start, 0)
+		sm.headersFirstMode = true
+		sm.headersPresync = newHeadersPresync(params, checkpoint,
+			&start, 0, bits)
+		return sm
+	}
+	send := func(sm *SyncManager, peer *peerpkg.Peer,
+		headers []*wire.BlockHeader) {
+
+		for i := 0; i < len(headers); i += wire.MaxBlockHeadersPerMsg {
+			end := i + wire.MaxBlockHeadersPerMsg
+			if end > len(headers) {
+				end = len(headers)
+			}
+			msg := wire.NewMsgHeaders()
+			msg.Headers = headers[i:end]
+			sm.handleHeadersMsg(&headersMsg{headers: msg, peer: peer})
+		}
+	}
+	disconnected := func(peer *peerpkg.Peer) bool {
+		done := make(chan struct{})
+		go func() {
+			peer.WaitForDisconnect()
+			close(done)
+		}()
+		select {
+		case <-done:
+			return true
+		case <-time.After(100 * time.Millisecond):
+			return false
+		}
+	}
+
+	// A peer serving another chain is disconnected without any of its
+	// headers being stored.
+	peer := newPeer()
+	sm := newManager(peer)
+	send(sm, peer, makeHeaders(start, bits, numHeaders, 1))
+	if sm.headerList.Len() != 1 || !disconnected(peer) {
+		t.Fatalf("other chain: got %d stored headers, disconnected %v",
+			sm.headerList.Len()-1, disconnected(peer))
+	}
+
+	// The headers of the chain reaching the checkpoint are stored once
+	// redownloaded, holding back the buffer.
+	peer = newPeer()
+	sm = newManager(peer)
+	send(sm, peer, headers)
+	if sm.headerList.Len() != 1 ||
+		sm.headersPresync.Phase() != redownloadPhase {
+
+		t.Fatalf("got %d stored headers in phase %v after presync",
+			sm.headerList.Len()-1, sm.headersPresync.Phase())
+	}
+	send(sm, peer, headers[:numHeaders-1])
+	want := numHeaders - 1 - redownloadBufferSize
+	if sm.headerList.Len()-1 != want || disconnected(peer) {
+		t.Fatalf("got %d stored headers, want %d", sm.headerList.Len()-1,
+			want)
+	}
+	e := sm.headerList.Front()
+	for i := 0; i < want; i++ {
+		e = e.Next()
+		node := e.Value.(*headerNode)
+		if hash := headers[i].BlockHash(); !node.hash.IsEqual(&hash) ||
+			node.height != int32(i+1) {
+
+			t.Fatalf("stored header %d is %v at height %d", i,
+				node.hash, node.height)
+		}
+	}
+}
@@ -37,5 +37,14 @@ type Config struct {
 	DisableCheckpoints bool
 	MaxPeers           int
 
+	// HeaderProbePeers is the number of peers whose header chain is
+	// downloaded in parallel during the initial block download to learn
+	// the best chain tip and detect diverging chains.  Zero disables it.
+	HeaderProbePeers int
+
+	// MaxRejectedTxns is the number of rejected transactions remembered.
+	// DefaultMaxRejectedTxns is used when it is zero.
+	MaxRejectedTxns int
+
 	FeeEstimator *mempool.FeeEstimator
 }
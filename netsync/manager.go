@@ -6,6 +6,7 @@ package netsync
 
 import (
 	"container/list"
+	"math/big"
 	"math/rand"
 	"net"
 	"sync"
@@ -28,10 +29,6 @@ const (
 	// more.
 	minInFlightBlocks = 10
 
-	// maxRejectedTxns is the maximum number of rejected transactions
-	// hashes to store in memory.
-	maxRejectedTxns = 1000
-
 	// maxRequestedBlocks is the maximum number of requested block
 	// hashes to store in memory.
 	maxRequestedBlocks = wire.MaxInvPerMsg
@@ -84,20 +81,51 @@ type donePeerMsg struct {
 	peer *peerpkg.Peer
 }
 
+// TxRelayFlags are the permissions of the peer a transaction came from which
+// change how the transaction is handled.
+type TxRelayFlags uint8
+
+const (
+	// TxRelayNonStd accepts the transaction to the memory pool even when
+	// it is not standard.
+	TxRelayNonStd TxRelayFlags = 1 << iota
+
+	// TxRelayForce relays the transaction even when it was rejected
+	// before or is already in the memory pool.
+	TxRelayForce
+
+	// TxRelayWhitelisted records the transaction as received from a
+	// whitelisted peer in its provenance.
+	TxRelayWhitelisted
+)
+
 // txMsg packages a bitcoin tx message and the peer it came from together
 // so the block handler has access to that information.
 type txMsg struct {
 	tx    *btcutil.Tx
 	peer  *peerpkg.Peer
+	flags TxRelayFlags
 	reply chan struct{}
 }
 
+// getRejectedTxnsMsg is a message type to be sent across the message channel
+// for retrieving the recently rejected transactions.
+type getRejectedTxnsMsg struct {
+	reply chan []RejectedTx
+}
+
 // getSyncPeerMsg is a message type to be sent across the message channel for
 // retrieving the current sync peer.
 type getSyncPeerMsg struct {
 	reply chan int32
 }
 
+// getSyncStatusMsg is a message type to be sent across the message channel
+// for retrieving the status of the sync.
+type getSyncStatusMsg struct {
+	reply chan *SyncStatus
+}
+
 // processBlockResponse is a response sent to the reply channel of a
 // processBlockMsg.
 type processBlockResponse struct {
@@ -145,6 +173,7 @@ type peerSyncState struct {
 	requestQueue    []*wire.InvVect
 	requestedTxns   map[chainhash.Hash]struct{}
 	requestedBlocks map[chainhash.Hash]struct{}
+	fetch           *blockFetchStats
 }
 
 // SyncManager is used to communicate block related messages with peers. The
@@ -165,7 +194,7 @@ type SyncManager struct {
 	quit           chan struct{}
 
 	// These fields should only be accessed from the blockHandler thread
-	rejectedTxns     map[chainhash.Hash]struct{}
+	rejectedTxns     *rejectCache
 	requestedTxns    map[chainhash.Hash]struct{}
 	requestedBlocks  map[chainhash.Hash]struct{}
 	syncPeer         *peerpkg.Peer
@@ -177,6 +206,22 @@ type SyncManager struct {
 	headerList       *list.List
 	startHeader      *list.Element
 	nextCheckpoint   *chaincfg.Checkpoint
+	headersPresync   *headersPresync
+
+	// The following fields are used to probe the header chains of several
+	// peers in parallel during the initial block download.
+	headerProbePeers int
+	headerProbes     *headerProbeSet
+	headerProbesRun  bool
+
+	// The following fields measure the progress of the sync.
+	blockRate   blockRate
+	headerStats blockchain.StageStats
+	blockStats  blockchain.StageStats
+
+	// safeModeDropped is set when a block was dropped because the chain
+	// is in safe mode, so the sync is restarted once it is left.
+	safeModeDropped bool
 
 	// An optional fee estimator.
 	feeEstimator *mempool.FeeEstimator
@@ -188,6 +233,7 @@ func (sm *SyncManager) resetHeaderState(newestHash *chainhash.Hash, newestHeight
 	sm.headersFirstMode = false
 	sm.headerList.Init()
 	sm.startHeader = nil
+	sm.headersPresync = nil
 
 	// When there is a next checkpoint, add an entry for the latest known
 	// block into the header pool.  This allows the next downloaded header
@@ -337,13 +383,16 @@ func (sm *SyncManager) startSync() {
 
 			bestPeer.PushGetHeadersMsg(locator, sm.nextCheckpoint.Hash)
 			sm.headersFirstMode = true
-			log.Infof("Downloading headers for blocks %d to "+
+			sm.headersPresync = newHeadersPresync(sm.chainParams,
+				sm.nextCheckpoint, &best.Hash, best.Height, best.Bits)
+			log.Infof("Pre-synchronizing headers for blocks %d to "+
 				"%d from peer %s", best.Height+1,
 				sm.nextCheckpoint.Height, bestPeer.Addr())
 		} else {
 			bestPeer.PushGetBlocksMsg(locator, &zeroHash)
 		}
 		sm.syncPeer = bestPeer
+		sm.startHeaderProbes(locator, higherPeers)
 
 		// Reset the last progress time now that we have a non-nil
 		// syncPeer to avoid instantly detecting it as stalled in the
@@ -354,6 +403,133 @@ func (sm *SyncManager) startSync() {
 	}
 }
 
+// startHeaderProbes starts probing the header chains of up to the configured
+// number of the passed candidate peers during the initial block download,
+// requesting their headers after the passed block locator of the best chain.
+// Only one round of probes is run.
+func (sm *SyncManager) startHeaderProbes(locator blockchain.BlockLocator,
+	candidates []*peerpkg.Peer) {
+
+	if sm.headerProbesRun || sm.headerProbePeers <= 0 ||
+		sm.chain.IsCurrent() {
+
+		return
+	}
+
+	probes := newHeaderProbeSet(sm.chainParams, sm.headerProbeBase)
+	now := time.Now()
+	for _, peer := range candidates {
+		if probes.pending() >= sm.headerProbePeers {
+			break
+		}
+
+		// The headers of the sync peer are used by the headers-first
+		// mode.
+		if peer == sm.syncPeer && sm.headersFirstMode {
+			continue
+		}
+		err := peer.PushGetHeadersMsg(locator, &zeroHash)
+		if err != nil {
+			log.Warnf("Failed to send getheaders message to "+
+				"peer %s: %v", peer.Addr(), err)
+			continue
+		}
+		probes.add(peer.ID(), peer.Addr(), peer.LastBlock(), now)
+	}
+	if probes.pending() == 0 {
+		return
+	}
+	sm.headerProbes = probes
+	sm.headerProbesRun = true
+	log.Infof("Probing the header chains of %d peers", probes.pending())
+}
+
+// headerProbeBase returns the height, the difficulty bits and the work of the
+// chain of the block of the block index with the passed hash for the header
+// probes.
+func (sm *SyncManager) headerProbeBase(hash *chainhash.Hash) (int32, uint32, *big.Int, bool) {
+	height, work, ok := sm.chain.BlockWork(hash)
+	if !ok {
+		return 0, 0, nil, false
+	}
+	header, err := sm.chain.HeaderByHash(hash)
+	if err != nil {
+		return 0, 0, nil, false
+	}
+	return height, header.Bits, work, true
+}
+
+// handleProbeHeaders handles the headers sent by a peer whose header chain is
+// being probed, requesting the next ones until it sent them all.
+func (sm *SyncManager) handleProbeHeaders(peer *peerpkg.Peer, headers []*wire.BlockHeader) {
+	forks, done, err := sm.headerProbes.process(peer.ID(), headers,
+		time.Now())
+	if err != nil {
+		log.Warnf("Header chain of peer %s is invalid: %v -- "+
+			"disconnecting", peer.Addr(), err)
+		peer.Disconnect()
+		return
+	}
+	for _, fork := range forks {
+		log.Warnf("Peers %s and %s serve header chains which diverge "+
+			"at or below height %d", fork.addrs[0], fork.addrs[1],
+			fork.height)
+	}
+	if !done {
+		locator, err := sm.chain.LatestBlockLocator()
+		if err != nil {
+			log.Warnf("Failed to get block locator for the "+
+				"latest block: %v", err)
+			return
+		}
+		err = peer.PushGetHeadersMsg(sm.headerProbes.locator(peer.ID(),
+			locator), &zeroHash)
+		if err != nil {
+			log.Warnf("Failed to send getheaders message to "+
+				"peer %s: %v", peer.Addr(), err)
+		}
+		return
+	}
+	if sm.headerProbes.pending() == 0 {
+		sm.finishHeaderProbes()
+	}
+}
+
+// finishHeaderProbes ends the round of header probes once no chain is being
+// probed anymore.  The peers whose chain diverges from the chain with the most
+// work are no longer sync candidates, switching the sync peer when it is one
+// of them.
+func (sm *SyncManager) finishHeaderProbes() {
+	probes := sm.headerProbes
+	sm.headerProbes = nil
+
+	best := probes.best()
+	if best == nil {
+		return
+	}
+	log.Infof("Learned the best chain tip at height %d from the header "+
+		"chain of peer %s", best.lastHeight, best.addr)
+
+	switchSyncPeer := false
+	for peer, state := range sm.peerStates {
+		if !state.syncCandidate || probes.onBestChain(peer.ID()) {
+			continue
+		}
+		log.Warnf("Peer %s serves a header chain diverging from the "+
+			"one with the most work -- not syncing from it",
+			peer.Addr())
+		state.syncCandidate = false
+		if peer == sm.syncPeer {
+			switchSyncPeer = true
+		}
+	}
+	if switchSyncPeer {
+		state := sm.peerStates[sm.syncPeer]
+		sm.clearRequestedState(state)
+		sm.updateSyncPeer(false)
+	}
+}
+
 // isSyncCandidate returns whether or not the peer is a candidate to consider
 // syncing from.
 func (sm *SyncManager) isSyncCandidate(peer *peerpkg.Peer) bool {
@@ -408,6 +584,7 @@ func (sm *SyncManager) handleNewPeerMsg(peer *peerpkg.Peer) {
 		syncCandidate:   isSyncCandidate,
 		requestedTxns:   make(map[chainhash.Hash]struct{}),
 		requestedBlocks: make(map[chainhash.Hash]struct{}),
+		fetch:           newBlockFetchStats(),
 	}
 
 	// Start syncing by choosing the best candidate if needed.
@@ -425,6 +602,30 @@ func (sm *SyncManager) handleStallSample() {
 		return
 	}
 
+	// Stop waiting for the probed peers which stalled.
+	if sm.headerProbes != nil {
+		for _, addr := range sm.headerProbes.expire(time.Now()) {
+			log.Debugf("Header chain probe of peer %s timed out",
+				addr)
+		}
+		if sm.headerProbes.pending() == 0 {
+			sm.finishHeaderProbes()
+		}
+	}
+
+	// Blocks are dropped in safe mode, so the sync peer is not stalling.
+	// The sync is restarted once the safe mode is left to request the
+	// dropped blocks again.
+	if sm.chain.SafeMode() {
+		sm.lastProgressTime = time.Now()
+		return
+	}
+	if sm.safeModeDropped {
+		sm.safeModeDropped = false
+		sm.updateSyncPeer(false)
+		return
+	}
+
 	// If we don't have an active sync peer, exit early.
 	if sm.syncPeer == nil {
 		return
@@ -447,6 +648,102 @@ func (sm *SyncManager) handleStallSample() {
 	sm.updateSyncPeer(disconnectSyncPeer)
 }
 
+// handleBlockFetchSample requests the blocks the peers did not deliver in time
+// from the fastest other peers.  The time a peer is given adapts to its usual
+// delivery time, a slow peer not being given much more than the fastest one.
+// This is only done once the chain is current, so a slow peer can't delay
+// following the tip, while the stall sample handles the sync peer during the
+// initial block download.
+func (sm *SyncManager) handleBlockFetchSample() {
+	if atomic.LoadInt32(&sm.shutdown) != 0 || !sm.current() {
+		return
+	}
+
+	var fastest time.Duration
+	for _, state := range sm.peerStates {
+		if delay := state.fetch.expectedDelay(); fastest == 0 ||
+			delay < fastest {
+
+			fastest = delay
+		}
+	}
+
+	now := time.Now()
+	for peer, state := range sm.peerStates {
+		timeout := blockFetchTimeout(state.fetch.expectedDelay(), fastest)
+		expired := state.fetch.expired(now, timeout)
+		for i := range expired {
+			hash := &expired[i]
+			if have, _ := sm.chain.HaveBlock(hash); have {
+				continue
+			}
+			other, otherState := sm.fastestBlockPeer(peer, hash, false)
+			if other == nil {
+				continue
+			}
+			log.Debugf("Block %v not received from %s within %v "+
+				"(%v) -- requesting it from %s", hash, peer,
+				timeout, state.fetch, other)
+			sm.requestBlock(other, otherState, hash, true)
+		}
+	}
+}
+
+// fastestBlockPeer returns the peer other than the passed one expected to
+// deliver the passed block the fastest, along with its sync state.  Only the
+// peers which announced the block are considered when announced is set,
+// otherwise those at least as far as the best chain are too.  Nil is returned
+// when there is no such peer.
+func (sm *SyncManager) fastestBlockPeer(exclude *peerpkg.Peer,
+	hash *chainhash.Hash, announced bool) (*peerpkg.Peer, *peerSyncState) {
+
+	best := sm.chain.BestSnapshot()
+	iv := wire.NewInvVect(wire.InvTypeBlock, hash)
+	var fastest *peerpkg.Peer
+	var fastestState *peerSyncState
+	for peer, state := range sm.peerStates {
+		if peer == exclude || !peer.Connected() ||
+			!peer.IsWitnessEnabled() {
+
+			continue
+		}
+		if _, exists := state.requestedBlocks[*hash]; exists {
+			continue
+		}
+		if !peer.IsKnownInventory(iv) &&
+			(announced || peer.LastBlock() < best.Height) {
+
+			continue
+		}
+		if fastest == nil || state.fetch.expectedDelay() <
+			fastestState.fetch.expectedDelay() {
+
+			fastest = peer
+			fastestState = state
+		}
+	}
+	return fastest, fastestState
+}
+
+// requestBlock requests the passed block from the passed peer, retry being set
+// when it was requested from another peer before.
+func (sm *SyncManager) requestBlock(peer *peerpkg.Peer, state *peerSyncState,
+	hash *chainhash.Hash, retry bool) {
+
+	sm.requestedBlocks[*hash] = struct{}{}
+	sm.limitMap(sm.requestedBlocks, maxRequestedBlocks)
+	state.requestedBlocks[*hash] = struct{}{}
+	state.fetch.request(hash, time.Now(), retry)
+
+	iv := wire.NewInvVect(wire.InvTypeBlock, hash)
+	if peer.IsWitnessEnabled() {
+		iv.Type = wire.InvTypeWitnessBlock
+	}
+	gdmsg := wire.NewMsgGetData()
+	gdmsg.AddInvVect(iv)
+	peer.QueueMessage(gdmsg, nil)
+}
+
 // shouldDCStalledSyncPeer determines whether or not we should disconnect a
 // stalled sync peer. If the peer has stalled and its reported height is greater
 // than our own best height, we will disconnect it. Otherwise, we will keep the
@@ -487,6 +784,13 @@ func (sm *SyncManager) handleDonePeerMsg(peer *peerpkg.Peer) {
 
 	sm.clearRequestedState(state)
 
+	if sm.headerProbes != nil {
+		sm.headerProbes.remove(peer.ID())
+		if sm.headerProbes.pending() == 0 {
+			sm.finishHeaderProbes()
+		}
+	}
+
 	if peer == sm.syncPeer {
 		// Update the sync peer. The server has already disconnected the
 		// peer before signaling to the sync manager.
@@ -557,17 +861,39 @@ func (sm *SyncManager) handleTxMsg(tmsg *txMsg) {
 
 	// Ignore transactions that we have already rejected.  Do not
 	// send a reject message here because if the transaction was already
-	// rejected, the transaction was unsolicited.
-	if _, exists = sm.rejectedTxns[*txHash]; exists {
+	// rejected, the transaction was unsolicited.  Transactions of peers
+	// allowed to force their relay are processed again.
+	force := tmsg.flags&TxRelayForce != 0
+	if sm.rejectedTxns.filtered(txHash) && !force {
 		log.Debugf("Ignoring unsolicited previously rejected "+
 			"transaction %v from %s", txHash, peer)
 		return
 	}
 
+	// Record the kind of peer the transaction came from, which is kept as
+	// its origin when it is accepted to the memory pool.
+	origin := mempool.OriginOutbound
+	switch {
+	case tmsg.flags&TxRelayWhitelisted != 0:
+		origin = mempool.OriginWhitelisted
+	case peer.Inbound():
+		origin = mempool.OriginInbound
+	}
+	sm.txMemPool.NoteRelay(txHash, mempool.Tag(peer.ID()), origin)
+
 	// Process the transaction to include validation, insertion in the
-	// memory pool, orphan handling, etc.
-	acceptedTxs, err := sm.txMemPool.ProcessTransaction(tmsg.tx,
-		true, true, mempool.Tag(peer.ID()))
+	// memory pool, orphan handling, etc.  The transactions of peers allowed
+	// to relay non-standard transactions are neither required to be
+	// standard nor rate limited.
+	var acceptedTxs []*mempool.TxDesc
+	var err error
+	if tmsg.flags&TxRelayNonStd != 0 {
+		acceptedTxs, err = sm.txMemPool.ProcessNonStdTransaction(tmsg.tx,
+			true, false, mempool.Tag(peer.ID()))
+	} else {
+		acceptedTxs, err = sm.txMemPool.ProcessTransaction(tmsg.tx,
+			true, true, mempool.Tag(peer.ID()))
+	}
 
 	// Remove transaction from request maps. Either the mempool/chain
 	// already knows about it and as such we shouldn't have any more
@@ -577,10 +903,30 @@ func (sm *SyncManager) handleTxMsg(tmsg *txMsg) {
 	delete(sm.requestedTxns, *txHash)
 
 	if err != nil {
+		// A transaction whose relay is forced is relayed again when it
+		// is already in the memory pool.
+		if force {
+			if txD, err := sm.txMemPool.FetchTxDesc(txHash); err == nil {
+				log.Debugf("Force relaying transaction %v from %s",
+					txHash, peer)
+				iv := wire.NewInvVect(wire.InvTypeTx, txHash)
+				sm.peerNotifier.RelayInventory(iv, txD)
+				return
+			}
+		}
+
 		// Do not request this transaction again until a new block
-		// has been processed.
-		sm.rejectedTxns[*txHash] = struct{}{}
-		sm.limitMap(sm.rejectedTxns, maxRejectedTxns)
+		// has been processed, and remember why it was rejected.
+		code, reason := mempool.ErrToRejectErr(err)
+		sm.rejectedTxns.add(&RejectedTx{
+			Hash:     *txHash,
+			PeerID:   peer.ID(),
+			PeerAddr: peer.Addr(),
+			Time:     time.Now(),
+			Height:   sm.chain.BestSnapshot().Height,
+			Code:     code,
+			Reason:   reason,
+		})
 
 		// When the error is a rule error, it means the transaction was
 		// simply rejected as opposed to something actually going wrong,
@@ -594,9 +940,7 @@ func (sm *SyncManager) handleTxMsg(tmsg *txMsg) {
 				txHash, err)
 		}
 
-		// Convert the error into an appropriate reject message and
-		// send it.
-		code, reason := mempool.ErrToRejectErr(err)
+		// Send the reject message the error was converted into.
 		peer.PushRejectMsg(wire.CmdTx, code, reason, txHash, false)
 		return
 	}
@@ -650,6 +994,25 @@ func (sm *SyncManager) handleBlockMsg(bmsg *blockMsg) {
 		}
 	}
 
+	// Record the delivery of the block in the statistics of the peer.  A
+	// block which was also requested from another peer is ignored when it
+	// was already received from it.
+	var proofSize int
+	if pcp := bmsg.block.MsgBlock().Pcp; pcp != nil {
+		proofSize = pcp.SerializeSize()
+	}
+	redundant := state.fetch.deliver(blockHash,
+		bmsg.block.MsgBlock().SerializeSize(), proofSize, time.Now())
+	if redundant {
+		if have, _ := sm.chain.HaveBlock(blockHash); have {
+			log.Debugf("Ignoring block %v from %s which was "+
+				"already received", blockHash, peer)
+			delete(state.requestedBlocks, *blockHash)
+			delete(sm.requestedBlocks, *blockHash)
+			return
+		}
+	}
+
 	// When in headers-first mode, if the block matches the hash of the
 	// first header in the list of headers that are being fetched, it's
 	// eligible for less validation since the headers have already been
@@ -682,7 +1045,17 @@ func (sm *SyncManager) handleBlockMsg(bmsg *blockMsg) {
 
 	// Process the block to include validation, best chain selection, orphan
 	// handling, etc.
+	start := time.Now()
 	_, isOrphan, err := sm.chain.ProcessBlock(bmsg.block, behaviorFlags)
+	addStage(&sm.blockStats, 1, start)
+	sm.blockRate.add(time.Now(), sm.chain.BestSnapshot().Height)
+	if err == blockchain.ErrSafeMode {
+		// The block is requested again once the safe mode is left.
+		log.Debugf("Dropped block %v from %s in safe mode", blockHash,
+			peer)
+		sm.safeModeDropped = true
+		return
+	}
 	if err != nil {
 		if re, ok := err.(blockchain.RuleError); ok {
 			if re.ErrorCode == blockchain.ErrPowCannotVerify {
@@ -771,8 +1144,8 @@ func (sm *SyncManager) handleBlockMsg(bmsg *blockMsg) {
 		heightUpdate = best.Height
 		blkHashUpdate = &best.Hash
 
-		// Clear the rejected transactions.
-		sm.rejectedTxns = make(map[chainhash.Hash]struct{})
+		// Stop filtering the rejected transactions.
+		sm.rejectedTxns.blockConnected()
 	}
 
 	// Update the block height for this peer. But only send a message to
@@ -811,6 +1184,9 @@ func (sm *SyncManager) handleBlockMsg(bmsg *blockMsg) {
 	prevHash := sm.nextCheckpoint.Hash
 	sm.nextCheckpoint = sm.findNextHeaderCheckpoint(prevHeight)
 	if sm.nextCheckpoint != nil {
+		sm.headersPresync = newHeadersPresync(sm.chainParams,
+			sm.nextCheckpoint, prevHash, prevHeight,
+			bmsg.block.MsgBlock().Header.Bits)
 		locator := blockchain.BlockLocator([]*chainhash.Hash{prevHash})
 		err := peer.PushGetHeadersMsg(locator, sm.nextCheckpoint.Hash)
 		if err != nil {
@@ -818,8 +1194,8 @@ func (sm *SyncManager) handleBlockMsg(bmsg *blockMsg) {
 				"peer %s: %v", peer.Addr(), err)
 			return
 		}
-		log.Infof("Downloading headers for blocks %d to %d from "+
-			"peer %s", prevHeight+1, sm.nextCheckpoint.Height,
+		log.Infof("Pre-synchronizing headers for blocks %d to %d "+
+			"from peer %s", prevHeight+1, sm.nextCheckpoint.Height,
 			sm.syncPeer.Addr())
 		return
 	}
@@ -829,6 +1205,7 @@ func (sm *SyncManager) handleBlockMsg(bmsg *blockMsg) {
 	// from the block after this one up to the end of the chain (zero hash).
 	sm.headersFirstMode = false
 	sm.headerList.Init()
+	sm.headersPresync = nil
 	log.Infof("Reached the final checkpoint -- switching to normal mode")
 	locator := blockchain.BlockLocator([]*chainhash.Hash{blockHash})
 	err = peer.PushGetBlocksMsg(locator, &zeroHash)
@@ -872,6 +1249,7 @@ func (sm *SyncManager) fetchHeaderBlocks() {
 
 			sm.requestedBlocks[*node.hash] = struct{}{}
 			syncPeerState.requestedBlocks[*node.hash] = struct{}{}
+			syncPeerState.fetch.request(node.hash, time.Now(), false)
 
 			// If we're fetching from a witness enabled peer
 			// post-fork, then ensure that we receive all the
@@ -903,8 +1281,15 @@ func (sm *SyncManager) handleHeadersMsg(hmsg *headersMsg) {
 		return
 	}
 
-	// The remote peer is misbehaving if we didn't request headers.
+	// The headers requested to probe the header chain of the peer are
+	// handled apart.
 	msg := hmsg.headers
+	if sm.headerProbes != nil && sm.headerProbes.has(peer.ID()) {
+		sm.handleProbeHeaders(peer, msg.Headers)
+		return
+	}
+
+	// The remote peer is misbehaving if we didn't request headers.
 	numHeaders := len(msg.Headers)
 	if !sm.headersFirstMode {
 		log.Warnf("Got %d unrequested headers from %s -- "+
@@ -918,11 +1303,50 @@ func (sm *SyncManager) handleHeadersMsg(hmsg *headersMsg) {
 		return
 	}
 
+	// Pre-synchronize the headers up to the next checkpoint before storing
+	// any of them, so a peer feeding a chain which does not reach it can't
+	// exhaust the memory.  Only the redownloaded headers checked against
+	// the commitments of the pre-synchronization are stored.
+	headers := msg.Headers
+	if presync := sm.headersPresync; presync != nil {
+		phase := presync.Phase()
+		var err error
+		headers, err = presync.process(msg.Headers)
+		if err != nil {
+			log.Warnf("Headers pre-synchronization with peer %s "+
+				"failed: %v -- disconnecting", peer.Addr(), err)
+			peer.Disconnect()
+			return
+		}
+		if peer == sm.syncPeer {
+			sm.lastProgressTime = time.Now()
+		}
+		if phase == presyncPhase && presync.Phase() == redownloadPhase {
+			log.Infof("Pre-synchronized headers up to the checkpoint "+
+				"at height %d with a claimed work of %064x -- "+
+				"redownloading them from peer %s",
+				sm.nextCheckpoint.Height, presync.Work(),
+				peer.Addr())
+		}
+		if presync.Done() {
+			sm.headersPresync = nil
+		}
+		if len(headers) == 0 {
+			err := peer.PushGetHeadersMsg(presync.locator(),
+				sm.nextCheckpoint.Hash)
+			if err != nil {
+				log.Warnf("Failed to send getheaders message "+
+					"to peer %s: %v", peer.Addr(), err)
+			}
+			return
+		}
+	}
+
 	// Process all of the received headers ensuring each one connects to the
 	// previous and that checkpoints match.
 	receivedCheckpoint := false
 	var finalHash *chainhash.Hash
-	for _, blockHeader := range msg.Headers {
+	for _, blockHeader := range headers {
 		blockHash := blockHeader.BlockHash()
 		finalHash = &blockHash
 
@@ -990,9 +1414,13 @@ func (sm *SyncManager) handleHeadersMsg(hmsg *headersMsg) {
 	}
 
 	// This header is not a checkpoint, so request the next batch of
-	// headers starting from the latest known header and ending with the
-	// next checkpoint.
+	// headers starting from the latest known header, or the latest
+	// redownloaded one when pre-synchronizing, and ending with the next
+	// checkpoint.
 	locator := blockchain.BlockLocator([]*chainhash.Hash{finalHash})
+	if sm.headersPresync != nil {
+		locator = sm.headersPresync.locator()
+	}
 	err := peer.PushGetHeadersMsg(locator, sm.nextCheckpoint.Hash)
 	if err != nil {
 		log.Warnf("Failed to send getheaders message to "+
@@ -1121,6 +1549,13 @@ func (sm *SyncManager) handleInvMsg(imsg *invMsg) {
 			continue
 		}
 
+		// Count the peers announcing each transaction for its
+		// provenance.
+		if iv.Type == wire.InvTypeTx || iv.Type == wire.InvTypeWitnessTx {
+			sm.txMemPool.NoteAnnouncement(&iv.Hash,
+				mempool.Tag(peer.ID()))
+		}
+
 		// Request the inventory if we don't already have it.
 		haveInv, err := sm.haveInventory(iv)
 		if err != nil {
@@ -1133,7 +1568,7 @@ func (sm *SyncManager) handleInvMsg(imsg *invMsg) {
 			if iv.Type == wire.InvTypeTx {
 				// Skip the transaction if it has already been
 				// rejected.
-				if _, exists := sm.rejectedTxns[iv.Hash]; exists {
+				if sm.rejectedTxns.filtered(&iv.Hash) {
 					continue
 				}
 			}
@@ -1209,9 +1644,29 @@ func (sm *SyncManager) handleInvMsg(imsg *invMsg) {
 			// Request the block if there is not already a pending
 			// request.
 			if _, exists := sm.requestedBlocks[iv.Hash]; !exists {
+				// Near the tip, request the block from a much
+				// faster peer which announced it too.
+				if sm.current() {
+					other, otherState := sm.fastestBlockPeer(
+						peer, &iv.Hash, true)
+					if other != nil && slowPeerFactor*
+						otherState.fetch.expectedDelay() <
+						state.fetch.expectedDelay() {
+
+						log.Debugf("Requesting block %v "+
+							"from %s instead of slow "+
+							"peer %s (%v)", iv.Hash,
+							other, peer, state.fetch)
+						sm.requestBlock(other, otherState,
+							&iv.Hash, false)
+						continue
+					}
+				}
+
 				sm.requestedBlocks[iv.Hash] = struct{}{}
 				sm.limitMap(sm.requestedBlocks, maxRequestedBlocks)
 				state.requestedBlocks[iv.Hash] = struct{}{}
+				state.fetch.request(&iv.Hash, time.Now(), false)
 
 				if peer.IsWitnessEnabled() {
 					iv.Type = wire.InvTypeWitnessBlock
@@ -1279,6 +1734,8 @@ func (sm *SyncManager) limitMap(m map[chainhash.Hash]struct{}, limit int) {
 func (sm *SyncManager) blockHandler() {
 	stallTicker := time.NewTicker(stallSampleInterval)
 	defer stallTicker.Stop()
+	fetchTicker := time.NewTicker(blockFetchSampleInterval)
+	defer fetchTicker.Stop()
 
 out:
 	for {
@@ -1300,11 +1757,17 @@ out:
 				sm.handleInvMsg(msg)
 
 			case *headersMsg:
+				start := time.Now()
 				sm.handleHeadersMsg(msg)
+				addStage(&sm.headerStats, len(msg.headers.Headers),
+					start)
 
 			case *donePeerMsg:
 				sm.handleDonePeerMsg(msg.peer)
 
+			case getRejectedTxnsMsg:
+				msg.reply <- sm.rejectedTxns.list()
+
 			case getSyncPeerMsg:
 				var peerID int32
 				if sm.syncPeer != nil {
@@ -1312,6 +1775,9 @@ out:
 				}
 				msg.reply <- peerID
 
+			case getSyncStatusMsg:
+				msg.reply <- sm.syncStatus()
+
 			case processBlockMsg:
 				_, isOrphan, err := sm.chain.ProcessBlock(
 					msg.block, msg.flags)
@@ -1342,6 +1808,9 @@ out:
 		case <-stallTicker.C:
 			sm.handleStallSample()
 
+		case <-fetchTicker.C:
+			sm.handleBlockFetchSample()
+
 		case <-sm.quit:
 			break out
 		}
@@ -1391,7 +1860,8 @@ func (sm *SyncManager) handleBlockchainNotification(notification *blockchain.Not
 		// transaction are NOT removed recursively because they are still
 		// valid.
 		for _, tx := range block.Transactions()[1:] {
-			sm.txMemPool.RemoveTransaction(tx, false)
+			sm.txMemPool.RemoveTransaction(tx, false,
+				mempool.RemovalMined)
 			sm.txMemPool.RemoveDoubleSpends(tx)
 			sm.txMemPool.RemoveOrphan(tx)
 			sm.peerNotifier.TransactionConfirmed(tx)
@@ -1421,16 +1891,36 @@ func (sm *SyncManager) handleBlockchainNotification(notification *blockchain.Not
 			break
 		}
 
-		// Reinsert all of the transactions (except the coinbase) into
-		// the transaction pool.
-		for _, tx := range block.Transactions()[1:] {
+		// Reinsert the transactions (except the coinbase) into the
+		// transaction pool.  The outputs of the coinbase no longer
+		// exist, so the transactions spending them, which a deep reorg
+		// may have returned to the pool from later blocks, are removed
+		// along with the transactions depending on them.
+		resurrect := block.Transactions()[1:]
+		if notification.Undo != nil {
+			var removed []wire.OutPoint
+			resurrect, removed = notification.Undo.ResurrectTxns(block)
+			for _, op := range removed {
+				tx := sm.txMemPool.CheckSpend(op)
+				if tx == nil {
+					continue
+				}
+				log.Debugf("Removing transaction %v spending "+
+					"coinbase output %v of disconnected "+
+					"block %v", tx.Hash(), op, block.Hash())
+				sm.txMemPool.RemoveTransaction(tx, true,
+					mempool.RemovalReorg)
+			}
+		}
+		for _, tx := range resurrect {
 			_, _, err := sm.txMemPool.MaybeAcceptTransaction(tx,
 				false, false)
 			if err != nil {
 				// Remove the transaction and all transactions
 				// that depend on it if it wasn't accepted into
 				// the transaction pool.
-				sm.txMemPool.RemoveTransaction(tx, true)
+				sm.txMemPool.RemoveTransaction(tx, true,
+					mempool.RemovalReorg)
 			}
 		}
 
@@ -1451,16 +1941,16 @@ func (sm *SyncManager) NewPeer(peer *peerpkg.Peer) {
 }
 
 // QueueTx adds the passed transaction message and peer to the block handling
-// queue. Responds to the done channel argument after the tx message is
-// processed.
-func (sm *SyncManager) QueueTx(tx *btcutil.Tx, peer *peerpkg.Peer, done chan struct{}) {
+// queue along with the relay flags of the peer. Responds to the done channel
+// argument after the tx message is processed.
+func (sm *SyncManager) QueueTx(tx *btcutil.Tx, peer *peerpkg.Peer, flags TxRelayFlags, done chan struct{}) {
 	// Don't accept more transactions if we're shutting down.
 	if atomic.LoadInt32(&sm.shutdown) != 0 {
 		done <- struct{}{}
 		return
 	}
 
-	sm.msgChan <- &txMsg{tx: tx, peer: peer, reply: done}
+	sm.msgChan <- &txMsg{tx: tx, peer: peer, flags: flags, reply: done}
 }
 
 // QueueBlock adds the passed block message and peer to the block handling
@@ -1543,6 +2033,21 @@ func (sm *SyncManager) SyncPeerID() int32 {
 	return <-reply
 }
 
+// SyncStatus returns the status of the sync with the chain of the network.
+func (sm *SyncManager) SyncStatus() *SyncStatus {
+	reply := make(chan *SyncStatus)
+	sm.msgChan <- getSyncStatusMsg{reply: reply}
+	return <-reply
+}
+
+// RejectedTxns returns the transactions relayed by peers which were recently
+// rejected, most recent first.
+func (sm *SyncManager) RejectedTxns() []RejectedTx {
+	reply := make(chan []RejectedTx)
+	sm.msgChan <- getRejectedTxnsMsg{reply: reply}
+	return <-reply
+}
+
 // ProcessBlock makes use of ProcessBlock on an internal instance of a block
 // chain.
 func (sm *SyncManager) ProcessBlock(block *btcutil.Block, flags blockchain.BehaviorFlags) (bool, error) {
@@ -1578,7 +2083,7 @@ func New(config *Config) (*SyncManager, error) {
 		chain:           config.Chain,
 		txMemPool:       config.TxMemPool,
 		chainParams:     config.ChainParams,
-		rejectedTxns:    make(map[chainhash.Hash]struct{}),
+		rejectedTxns:    newRejectCache(config.MaxRejectedTxns),
 		requestedTxns:   make(map[chainhash.Hash]struct{}),
 		requestedBlocks: make(map[chainhash.Hash]struct{}),
 		peerStates:      make(map[*peerpkg.Peer]*peerSyncState),
@@ -1587,6 +2092,8 @@ func New(config *Config) (*SyncManager, error) {
 		headerList:      list.New(),
 		quit:            make(chan struct{}),
 		feeEstimator:    config.FeeEstimator,
+
+		headerProbePeers: config.HeaderProbePeers,
 	}
 
 	best := sm.chain.BestSnapshot()
@@ -0,0 +1,114 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package netsync
+
+import (
+	"time"
+
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// DefaultMaxRejectedTxns is the default number of rejected transactions the
+// sync manager remembers.
+const DefaultMaxRejectedTxns = 1000
+
+// RejectedTx describes a transaction relayed by a peer which the memory pool
+// rejected.
+type RejectedTx struct {
+	Hash     chainhash.Hash
+	PeerID   int32
+	PeerAddr string
+	Time     time.Time
+
+	// Height is the height of the best block when the transaction was
+	// rejected.
+	Height int32
+
+	Code   wire.RejectCode
+	Reason string
+
+	// Filtered is whether the transaction was rejected since the last
+	// block was connected, in which case it is not requested again when
+	// it is announced.
+	Filtered bool
+
+	// generation is the value of the block generation of the cache when
+	// the transaction was rejected.
+	generation uint64
+}
+
+// rejectCache is a rolling cache of the most recently rejected transactions.
+// When it is full, the oldest rejection is evicted.  Only the rejections made
+// since the last connected block filter transaction announcements, since a new
+// block can make a rejected transaction acceptable, but older ones are kept
+// for inspection until evicted.  It is only used by the block handler and
+// therefore is not safe for concurrent access.
+type rejectCache struct {
+	max        int
+	rejects    map[chainhash.Hash]*RejectedTx
+	order      []chainhash.Hash // oldest first
+	generation uint64
+}
+
+// newRejectCache returns a reject cache which remembers up to max rejected
+// transactions.
+func newRejectCache(max int) *rejectCache {
+	if max <= 0 {
+		max = DefaultMaxRejectedTxns
+	}
+	return &rejectCache{
+		max:     max,
+		rejects: make(map[chainhash.Hash]*RejectedTx),
+	}
+}
+
+// add records the passed rejection, replacing any previous rejection of the
+// same transaction.
+func (c *rejectCache) add(r *RejectedTx) {
+	r.generation = c.generation
+	if _, ok := c.rejects[r.Hash]; ok {
+		c.remove(r.Hash)
+	}
+	if len(c.order) >= c.max {
+		c.remove(c.order[0])
+	}
+	c.rejects[r.Hash] = r
+	c.order = append(c.order, r.Hash)
+}
+
+// remove forgets the rejection of the transaction with the passed hash.
+func (c *rejectCache) remove(hash chainhash.Hash) {
+	delete(c.rejects, hash)
+	for i := range c.order {
+		if c.order[i] == hash {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			return
+		}
+	}
+}
+
+// filtered returns whether the transaction with the passed hash was rejected
+// since the last connected block.
+func (c *rejectCache) filtered(hash *chainhash.Hash) bool {
+	r, ok := c.rejects[*hash]
+	return ok && r.generation == c.generation
+}
+
+// blockConnected stops the current rejections from filtering transactions.
+func (c *rejectCache) blockConnected() {
+	c.generation++
+}
+
+// list returns the remembered rejections, most recent first.
+func (c *rejectCache) list() []RejectedTx {
+	list := make([]RejectedTx, 0, len(c.order))
+	for i := len(c.order) - 1; i >= 0; i-- {
+		r := *c.rejects[c.order[i]]
+		r.Filtered = r.generation == c.generation
+		list = append(list, r)
+	}
+	return list
+}
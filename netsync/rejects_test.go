@@ -0,0 +1,65 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package netsync
+
+import (
+	"testing"
+
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// TestRejectCache ensures the reject cache evicts the oldest rejections, only
+// filters the rejections made since the last block and lists the rejections
+// most recent first.
+func TestRejectCache(t *testing.T) {
+	hashes := []chainhash.Hash{{1}, {2}, {3}, {4}}
+	c := newRejectCache(3)
+	for i := range hashes[:3] {
+		c.add(&RejectedTx{Hash: hashes[i], Code: wire.RejectInvalid})
+	}
+
+	// Rejecting a transaction again moves it to the front, so the oldest
+	// rejection evicted by a new one is the second.
+	c.add(&RejectedTx{Hash: hashes[0], Code: wire.RejectDuplicate})
+	c.blockConnected()
+	c.add(&RejectedTx{Hash: hashes[3], Code: wire.RejectNonstandard})
+
+	want := []struct {
+		hash     chainhash.Hash
+		code     wire.RejectCode
+		filtered bool
+	}{
+		{hashes[3], wire.RejectNonstandard, true},
+		{hashes[0], wire.RejectDuplicate, false},
+		{hashes[2], wire.RejectInvalid, false},
+	}
+	list := c.list()
+	if len(list) != len(want) {
+		t.Fatalf("list: got %d rejections, want %d", len(list), len(want))
+	}
+	for i, r := range list {
+		if r.Hash != want[i].hash || r.Code != want[i].code ||
+			r.Filtered != want[i].filtered {
+
+			t.Fatalf("list: rejection %d is %v %v %v, want %v %v %v", i,
+				r.Hash, r.Code, r.Filtered, want[i].hash,
+				want[i].code, want[i].filtered)
+		}
+		if c.filtered(&r.Hash) != want[i].filtered {
+			t.Fatalf("filtered(%v): got %v, want %v", r.Hash,
+				!want[i].filtered, want[i].filtered)
+		}
+	}
+	if c.filtered(&hashes[1]) {
+		t.Fatalf("filtered(%v): evicted rejection is filtered", hashes[1])
+	}
+
+	// A zero size uses the default.
+	if c := newRejectCache(0); c.max != DefaultMaxRejectedTxns {
+		t.Fatalf("newRejectCache: got max %d, want %d", c.max,
+			DefaultMaxRejectedTxns)
+	}
+}
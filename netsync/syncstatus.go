@@ -0,0 +1,141 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package netsync
+
+import (
+	"time"
+
+	"github.com/pkt-cash/pktd/blockchain"
+)
+
+const (
+	// blockRateWindow is the period over which the rate at which blocks
+	// are connected to the main chain is measured.
+	blockRateWindow = 5 * time.Minute
+
+	// blockRateSampleInterval is the minimum interval between two samples
+	// of the height of the main chain.
+	blockRateSampleInterval = 10 * time.Second
+)
+
+// SyncStatus describes the progress of the sync manager with the chain of the
+// network.
+type SyncStatus struct {
+	// SyncPeerID is the ID of the sync peer, or 0 if there is none, and
+	// BestPeerHeight is the highest latest block announced by a peer.
+	SyncPeerID     int32
+	BestPeerHeight int32
+
+	// HeaderHeight is the height of the latest header known to be part of
+	// the chain being synced, which is ahead of the best block in the
+	// headers-first mode.
+	HeaderHeight     int32
+	HeadersFirstMode bool
+
+	// PresyncPhase is the phase of the headers pre-synchronization, or
+	// empty when it is not running.
+	PresyncPhase string
+
+	// Current is whether the sync manager believes it is synced with the
+	// connected peers.
+	Current bool
+
+	// BlockRate is the number of blocks per second connected to the main
+	// chain over the last few minutes.
+	BlockRate float64
+
+	// Headers is the time spent processing the headers received in the
+	// headers-first mode, and Blocks the time spent processing the blocks
+	// received from peers, validation included.
+	Headers blockchain.StageStats
+	Blocks  blockchain.StageStats
+}
+
+// heightSample is the height of the main chain at a point in time.
+type heightSample struct {
+	time   time.Time
+	height int32
+}
+
+// blockRate measures the rate at which blocks are connected to the main chain
+// over a sliding window.  It is only used by the block handler and therefore
+// is not safe for concurrent access.
+type blockRate struct {
+	samples []heightSample
+}
+
+// add samples the height of the main chain, unless it was sampled less than
+// blockRateSampleInterval ago, and forgets the samples which left the window.
+func (r *blockRate) add(now time.Time, height int32) {
+	n := len(r.samples)
+	if n > 0 && now.Sub(r.samples[n-1].time) < blockRateSampleInterval {
+		return
+	}
+	r.samples = append(r.samples, heightSample{time: now, height: height})
+	for len(r.samples) > 1 && now.Sub(r.samples[0].time) > blockRateWindow {
+		r.samples = r.samples[1:]
+	}
+}
+
+// rate returns the number of blocks per second connected to the main chain
+// from the oldest sample within the window to the passed height.  It is zero
+// when no block was connected within the window.
+func (r *blockRate) rate(now time.Time, height int32) float64 {
+	for _, s := range r.samples {
+		if now.Sub(s.time) > blockRateWindow {
+			continue
+		}
+		elapsed := now.Sub(s.time).Seconds()
+		if elapsed <= 0 || height <= s.height {
+			return 0
+		}
+		return float64(height-s.height) / elapsed
+	}
+	return 0
+}
+
+// addStage adds the passed number of headers or blocks processed since the
+// passed time to a stage.
+func addStage(stage *blockchain.StageStats, count int, start time.Time) {
+	stage.Count += uint64(count)
+	stage.Duration += time.Since(start)
+}
+
+// syncStatus returns the status of the sync.  It must be called from the block
+// handler.
+func (sm *SyncManager) syncStatus() *SyncStatus {
+	best := sm.chain.BestSnapshot()
+	status := &SyncStatus{
+		HeaderHeight:     best.Height,
+		HeadersFirstMode: sm.headersFirstMode,
+		Current:          sm.current(),
+		BlockRate:        sm.blockRate.rate(time.Now(), best.Height),
+		Headers:          sm.headerStats,
+		Blocks:           sm.blockStats,
+	}
+	if sm.syncPeer != nil {
+		status.SyncPeerID = sm.syncPeer.ID()
+	}
+	for peer := range sm.peerStates {
+		if height := peer.LastBlock(); height > status.BestPeerHeight {
+			status.BestPeerHeight = height
+		}
+	}
+	if sm.headersFirstMode {
+		if e := sm.headerList.Back(); e != nil {
+			node := e.Value.(*headerNode)
+			if node.height > status.HeaderHeight {
+				status.HeaderHeight = node.height
+			}
+		}
+	}
+	if p := sm.headersPresync; p != nil {
+		status.PresyncPhase = p.Phase().String()
+		if p.lastHeight > status.HeaderHeight {
+			status.HeaderHeight = p.lastHeight
+		}
+	}
+	return status
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package netsync
+
+import (
+	"testing"
+	"time"
+)
+
+// TestBlockRate ensures the block rate is measured over the sliding window
+// only and drops to zero once no block was connected within it.
+func TestBlockRate(t *testing.T) {
+	var r blockRate
+	start := time.Unix(1600000000, 0)
+	if rate := r.rate(start, 100); rate != 0 {
+		t.Fatalf("rate without samples: got %v, want 0", rate)
+	}
+
+	// Samples taken closer than the sample interval are ignored.
+	r.add(start, 100)
+	r.add(start.Add(time.Second), 150)
+	if len(r.samples) != 1 {
+		t.Fatalf("got %d samples, want 1", len(r.samples))
+	}
+
+	// 10 blocks per second during the first minute.
+	now := start.Add(time.Minute)
+	r.add(now, 700)
+	if rate := r.rate(now, 700); rate != 10 {
+		t.Fatalf("rate: got %v, want 10", rate)
+	}
+
+	// The first sample leaves the window, so the rate is measured from the
+	// second one.
+	now = start.Add(blockRateWindow + 30*time.Second)
+	r.add(now, 1300)
+	if len(r.samples) != 2 {
+		t.Fatalf("got %d samples, want 2", len(r.samples))
+	}
+	if rate := r.rate(now, 1300); rate != 600/(blockRateWindow-30*time.Second).Seconds() {
+		t.Fatalf("rate: got %v", rate)
+	}
+
+	// Nothing was connected within the window.
+	now = now.Add(2 * blockRateWindow)
+	if rate := r.rate(now, 1300); rate != 0 {
+		t.Fatalf("rate after stalling: got %v, want 0", rate)
+	}
+}
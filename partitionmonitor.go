@@ -0,0 +1,381 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/btcjson"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+const (
+	// defaultPartitionAlertTime is the time the node must be behind the
+	// peers, or many of them on another chain, before an alert is raised.
+	defaultPartitionAlertTime = 20 * time.Minute
+
+	// defaultPartitionForkRatio is the share of the peers on another chain
+	// which raises an alert.
+	defaultPartitionForkRatio = 0.5
+
+	// partitionCheckInterval is the time between two evaluations of the
+	// partition conditions.
+	partitionCheckInterval = 30 * time.Second
+
+	// minPartitionForkPeers is the number of peers whose chain must be
+	// placed relative to the main chain for the share of them on another
+	// chain to be meaningful.
+	minPartitionForkPeers = 3
+)
+
+// The conditions watched by the partition monitor.
+const (
+	partitionBehind = "behind"
+	partitionFork   = "fork"
+)
+
+// partitionPeer is the tip announced by a connected peer, its height and, when
+// it announced one, the hash of its latest block.
+type partitionPeer struct {
+	ID     int32
+	Addr   string
+	Height int32
+	Hash   *chainhash.Hash
+}
+
+// partitionHeaderTip is the last header sent by a peer which connects to the
+// block index, possibly through headers sent before.  Base is the latest of its
+// ancestors in the block index.
+type partitionHeaderTip struct {
+	hash   chainhash.Hash
+	height int32
+	work   *big.Int
+	base   chainhash.Hash
+}
+
+// partitionCondition tracks since when a condition holds and whether an alert
+// was raised for it.
+type partitionCondition struct {
+	since time.Time
+	alert bool
+}
+
+// result returns the condition as returned by the getpartitionstatus RPC.
+func (c *partitionCondition) result() btcjson.PartitionConditionResult {
+	r := btcjson.PartitionConditionResult{
+		Holding: !c.since.IsZero(),
+		Alert:   c.alert,
+	}
+	if r.Holding {
+		r.Since = c.since.Unix()
+	}
+	return r
+}
+
+// partitionMonitorConfig holds the configuration of the partition monitor.
+type partitionMonitorConfig struct {
+	Chain *blockchain.BlockChain
+
+	// Peers returns the tips announced by the connected peers.
+	Peers func() []partitionPeer
+
+	// IsCurrent returns whether the node believes it is synced with the
+	// network.  The node is only considered behind once it was synced,
+	// so the initial block download doesn't raise alerts.
+	IsCurrent func() bool
+
+	// AlertTime is the time a condition must hold for an alert to be
+	// raised and ForkRatio the share of the peers on another chain which
+	// makes the fork condition hold.
+	AlertTime time.Duration
+	ForkRatio float64
+
+	// Notify, when not nil, is called whenever an alert is raised or
+	// cleared.
+	Notify func(*webhookPartition)
+}
+
+// partitionMonitor watches the chains announced by the peers as an early
+// warning of network partitions and eclipse attacks.  It raises an alert when
+// the node stays behind the most work announced by a peer, or when a large
+// share of the peers stays on another chain, for the configured alert time.
+type partitionMonitor struct {
+	cfg partitionMonitorConfig
+
+	mtx        sync.Mutex
+	headers    map[int32]*partitionHeaderTip
+	wasCurrent bool
+	behind     partitionCondition
+	fork       partitionCondition
+	status     *btcjson.GetPartitionStatusResult
+
+	wg   sync.WaitGroup
+	quit chan struct{}
+}
+
+// newPartitionMonitor returns a partition monitor with the passed
+// configuration.
+func newPartitionMonitor(cfg *partitionMonitorConfig) *partitionMonitor {
+	return &partitionMonitor{
+		cfg:     *cfg,
+		headers: make(map[int32]*partitionHeaderTip),
+		quit:    make(chan struct{}),
+	}
+}
+
+// Start starts evaluating the partition conditions periodically.
+func (m *partitionMonitor) Start() {
+	m.wg.Add(1)
+	go m.handler()
+}
+
+// Stop stops the partition monitor and waits for it to finish.
+func (m *partitionMonitor) Stop() {
+	close(m.quit)
+	m.wg.Wait()
+}
+
+// handler evaluates the partition conditions every partitionCheckInterval.  It
+// must be run as a goroutine.
+func (m *partitionMonitor) handler() {
+	ticker := time.NewTicker(partitionCheckInterval)
+	defer ticker.Stop()
+out:
+	for {
+		select {
+		case <-ticker.C:
+			m.check(time.Now())
+		case <-m.quit:
+			break out
+		}
+	}
+	m.wg.Done()
+}
+
+// ObserveHeaders records the last of the headers sent by the passed peer when
+// they connect to the block index, directly or through the headers it sent
+// before, so the work of chains the node does not have the blocks of is known.
+func (m *partitionMonitor) ObserveHeaders(peerID int32, headers []*wire.BlockHeader) {
+	if len(headers) == 0 {
+		return
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	tip := m.headers[peerID]
+	for _, header := range headers {
+		parent := tip
+		if height, work, ok := m.cfg.Chain.BlockWork(&header.PrevBlock); ok {
+			parent = &partitionHeaderTip{
+				hash:   header.PrevBlock,
+				height: height,
+				work:   work,
+				base:   header.PrevBlock,
+			}
+		} else if parent == nil || parent.hash != header.PrevBlock {
+			return
+		}
+		tip = &partitionHeaderTip{
+			hash:   header.BlockHash(),
+			height: parent.height + 1,
+			work: new(big.Int).Add(parent.work,
+				blockchain.CalcWork(header.Bits)),
+			base: parent.base,
+		}
+	}
+	m.headers[peerID] = tip
+}
+
+// peerChain returns the height and the work of the chain announced by the
+// passed peer, whether the work is estimated from the height and whether the
+// chain is known to be the main chain or another one.  Neither is set when the
+// chain can't be placed relative to the main chain.
+//
+// This function MUST be called with the monitor mutex held.
+func (m *partitionMonitor) peerChain(p *partitionPeer, best *blockchain.BestState,
+	tipWork *big.Int) (height int32, work *big.Int, estimated, same, other bool) {
+
+	chain := m.cfg.Chain
+	known := func(hash *chainhash.Hash) bool {
+		var ok bool
+		height, work, ok = chain.BlockWork(hash)
+		if !ok {
+			return false
+		}
+		same = chain.MainChainHasBlock(hash)
+		other = !same
+		return true
+	}
+
+	// The headers sent by the peer are the most precise, unless it has
+	// since announced a higher block.
+	if h := m.headers[p.ID]; h != nil && h.height >= p.Height {
+		if known(&h.hash) {
+			return
+		}
+		height, work = h.height, h.work
+		same = h.base == best.Hash
+		other = !same
+		return
+	}
+	if p.Hash != nil && known(p.Hash) {
+		return
+	}
+
+	// Estimate the work from the height difference with the best block
+	// otherwise.
+	height = p.Height
+	work = new(big.Int).Mul(blockchain.CalcWork(best.Bits),
+		big.NewInt(int64(p.Height-best.Height)))
+	work.Add(work, tipWork)
+	return height, work, true, false, false
+}
+
+// check evaluates the partition conditions at the passed time, raising and
+// clearing the alerts accordingly.
+func (m *partitionMonitor) check(now time.Time) {
+	chain := m.cfg.Chain
+	best := chain.BestSnapshot()
+	_, tipWork, _ := chain.BlockWork(&best.Hash)
+	peers := m.cfg.Peers()
+	isCurrent := m.cfg.IsCurrent()
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	status := &btcjson.GetPartitionStatusResult{
+		Enabled:   true,
+		AlertTime: int64(m.cfg.AlertTime / time.Second),
+		TipHash:   best.Hash.String(),
+		TipHeight: best.Height,
+		TipWork:   fmt.Sprintf("%064x", tipWork),
+		Peers:     len(peers),
+	}
+	connected := make(map[int32]struct{}, len(peers))
+	var bestWork *big.Int
+	for i := range peers {
+		p := &peers[i]
+		connected[p.ID] = struct{}{}
+
+		height, work, estimated, same, other := m.peerChain(p, best,
+			tipWork)
+		if same {
+			status.PeersSameChain++
+		}
+		if other {
+			status.PeersOtherChain++
+		}
+		if bestWork == nil || work.Cmp(bestWork) > 0 {
+			bestWork = work
+			status.BestPeer = p.Addr
+			status.BestPeerHeight = height
+			status.BestPeerWork = fmt.Sprintf("%064x", work)
+			status.BestPeerEstimated = estimated
+		}
+	}
+
+	// Forget the headers of the peers which disconnected.
+	for id := range m.headers {
+		if _, ok := connected[id]; !ok {
+			delete(m.headers, id)
+		}
+	}
+
+	if isCurrent {
+		m.wasCurrent = true
+	}
+	behind := m.wasCurrent && bestWork != nil && bestWork.Cmp(tipWork) > 0
+	placed := status.PeersSameChain + status.PeersOtherChain
+	fork := placed >= minPartitionForkPeers &&
+		float64(status.PeersOtherChain) >= m.cfg.ForkRatio*float64(placed)
+
+	m.update(&m.behind, partitionBehind, behind, now, status)
+	m.update(&m.fork, partitionFork, fork, now, status)
+	status.Behind = m.behind.result()
+	status.Fork = m.fork.result()
+	m.status = status
+}
+
+// update tracks whether the passed condition holds at the passed time and
+// raises or clears its alert.
+//
+// This function MUST be called with the monitor mutex held.
+func (m *partitionMonitor) update(c *partitionCondition, name string,
+	holding bool, now time.Time, status *btcjson.GetPartitionStatusResult) {
+
+	since := c.since
+	var alertStatus string
+	switch {
+	case !holding:
+		c.since = time.Time{}
+		if !c.alert {
+			return
+		}
+		c.alert = false
+		alertStatus = "cleared"
+		srvrLog.Infof("Network partition alert cleared: the node is no "+
+			"longer %s", partitionDescription(name))
+
+	case c.since.IsZero():
+		c.since = now
+		return
+
+	case !c.alert && now.Sub(c.since) >= m.cfg.AlertTime:
+		c.alert = true
+		alertStatus = "raised"
+		srvrLog.Warnf("Network partition alert: the node has been %s "+
+			"since %v (tip %v at height %d, best peer %s at height "+
+			"%d, %d of %d placed peers on another chain)",
+			partitionDescription(name), c.since, status.TipHash,
+			status.TipHeight, status.BestPeer, status.BestPeerHeight,
+			status.PeersOtherChain,
+			status.PeersSameChain+status.PeersOtherChain)
+
+	default:
+		return
+	}
+
+	if m.cfg.Notify != nil {
+		m.cfg.Notify(&webhookPartition{
+			Condition:       name,
+			Status:          alertStatus,
+			Since:           since.Unix(),
+			TipHash:         status.TipHash,
+			TipHeight:       status.TipHeight,
+			BestPeerHeight:  status.BestPeerHeight,
+			PeersSameChain:  status.PeersSameChain,
+			PeersOtherChain: status.PeersOtherChain,
+		})
+	}
+}
+
+// partitionDescription describes the passed condition for the log.
+func partitionDescription(name string) string {
+	if name == partitionBehind {
+		return "behind the most work announced by its peers"
+	}
+	return "on another chain than many of its peers"
+}
+
+// Status returns the state of the partition conditions as of the last check,
+// checking them first if they never were.
+func (m *partitionMonitor) Status() *btcjson.GetPartitionStatusResult {
+	m.mtx.Lock()
+	status := m.status
+	m.mtx.Unlock()
+	if status == nil {
+		m.check(time.Now())
+		m.mtx.Lock()
+		status = m.status
+		m.mtx.Unlock()
+	}
+	return status
+}
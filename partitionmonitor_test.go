@@ -0,0 +1,135 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// TestPartitionMonitor ensures the partition monitor raises and clears alerts
+// when the node stays behind its peers or many of them are on another chain.
+func TestPartitionMonitor(t *testing.T) {
+	tc, teardown := newTestChain(t, "partitionmonitor", nil)
+	defer teardown()
+	params, chain, g := tc.params, tc.chain, tc.gen
+	if _, err := g.generate(params.GenesisHash, 3, nil); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	side, err := g.generate(params.GenesisHash, 1, nil)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	best := chain.BestSnapshot()
+
+	var peers []partitionPeer
+	isCurrent := false
+	var alerts []*webhookPartition
+	const alertTime = 10 * time.Minute
+	m := newPartitionMonitor(&partitionMonitorConfig{
+		Chain:     chain,
+		Peers:     func() []partitionPeer { return peers },
+		IsCurrent: func() bool { return isCurrent },
+		AlertTime: alertTime,
+		ForkRatio: 0.5,
+		Notify:    func(a *webhookPartition) { alerts = append(alerts, a) },
+	})
+
+	now := time.Unix(1600000000, 0)
+	check := func(elapsed time.Duration, wantSame, wantOther int,
+		wantBehind, wantFork bool, wantAlerts ...string) {
+
+		t.Helper()
+		now = now.Add(elapsed)
+		alerts = nil
+		m.check(now)
+		status := m.Status()
+		if status.PeersSameChain != wantSame ||
+			status.PeersOtherChain != wantOther ||
+			status.Behind.Holding != wantBehind ||
+			status.Fork.Holding != wantFork {
+
+			t.Fatalf("unexpected status %+v", status)
+		}
+		if len(alerts) != len(wantAlerts) {
+			t.Fatalf("got %d alerts, want %v", len(alerts), wantAlerts)
+		}
+		for i, alert := range alerts {
+			if got := alert.Condition + " " + alert.Status; got != wantAlerts[i] {
+				t.Fatalf("got alert %q, want %q", got, wantAlerts[i])
+			}
+		}
+	}
+
+	// Peers on the main chain, including one behind.
+	prevHash, err := chain.BlockHashByHeight(best.Height - 1)
+	if err != nil {
+		t.Fatalf("BlockHashByHeight: %v", err)
+	}
+	peers = []partitionPeer{
+		{ID: 1, Addr: "peer1", Height: best.Height, Hash: &best.Hash},
+		{ID: 2, Addr: "peer2", Height: best.Height, Hash: &best.Hash},
+		{ID: 3, Addr: "peer3", Height: best.Height - 1, Hash: prevHash},
+	}
+	check(0, 3, 0, false, false)
+
+	// Headers extending the tip make the node behind, once it was current.
+	header := wire.BlockHeader{
+		Version:   1,
+		PrevBlock: best.Hash,
+		Timestamp: time.Unix(1600000000, 0),
+		Bits:      best.Bits,
+	}
+	header2 := header
+	header2.PrevBlock = header.BlockHash()
+	m.ObserveHeaders(1, []*wire.BlockHeader{&header})
+	m.ObserveHeaders(1, []*wire.BlockHeader{&header2})
+	check(0, 3, 0, false, false)
+	if status := m.Status(); status.BestPeer != "peer1" ||
+		status.BestPeerHeight != best.Height+2 || status.BestPeerEstimated {
+
+		t.Fatalf("unexpected best peer %+v", status)
+	}
+	isCurrent = true
+	check(0, 3, 0, true, false)
+	check(alertTime-time.Second, 3, 0, true, false)
+	check(time.Second, 3, 0, true, false, "behind raised")
+	check(time.Minute, 3, 0, true, false)
+
+	// Unconnected headers are ignored.
+	orphan := header
+	orphan.PrevBlock = chainhash.Hash{0x01}
+	m.ObserveHeaders(2, []*wire.BlockHeader{&orphan})
+
+	// The alert is cleared once the peer is gone, and its headers with it.
+	isCurrent = false
+	peers = peers[1:]
+	check(0, 2, 0, false, false, "behind cleared")
+
+	// A higher announced height of an unknown chain is estimated.
+	peers = append(peers, partitionPeer{ID: 4, Addr: "peer4",
+		Height: best.Height + 5, Hash: &chainhash.Hash{0x02}})
+	check(0, 2, 0, true, false)
+	if status := m.Status(); status.BestPeer != "peer4" ||
+		status.BestPeerHeight != best.Height+5 || !status.BestPeerEstimated {
+
+		t.Fatalf("unexpected best peer %+v", status)
+	}
+	peers = peers[:2]
+	check(0, 2, 0, false, false)
+
+	// Most peers on a side chain.
+	sideHash := side[0]
+	peers = append(peers,
+		partitionPeer{ID: 5, Addr: "peer5", Height: 1, Hash: sideHash},
+		partitionPeer{ID: 6, Addr: "peer6", Height: 1, Hash: sideHash})
+	check(0, 2, 2, false, true)
+	check(alertTime, 2, 2, false, true, "fork raised")
+	peers = peers[:3]
+	check(0, 2, 1, false, false, "fork cleared")
+}
@@ -0,0 +1,29 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package payjoin implements the payjoin protocol of BIP 0078, where the
+receiver of a payment adds one of its own inputs to the transaction of the
+sender.  The payment then no longer looks like one, as the usual assumption
+that all the inputs of a transaction belong to the same party is broken, and
+the receiver consolidates its outputs at no extra cost.
+
+A receiver advertises its endpoint with the pj parameter of its payment URIs.
+The sender builds, signs and posts the original transaction, as a partially
+signed transaction (BIP 0174), to that endpoint.  It may pay any number of
+destinations besides the receiver.  The receiver checks it could broadcast the
+original transaction, so it is paid even when the sender goes away, adds its
+input, increases its output by the value of the input and returns the proposal
+with its input signed.
+
+The sender must not trust the proposal: CheckProposal ensures it pays the same
+destinations as the original transaction and costs the sender at most the
+additional fee it allowed, before the sender signs and broadcasts it.  The
+sender broadcasts the original transaction when the receiver fails or the
+proposal is invalid.
+
+Receiver is an http.Handler serving the endpoint on top of the Wallet
+interface, which the wallet implements to check, fund and sign the proposals.
+*/
+package payjoin
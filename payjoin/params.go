@@ -0,0 +1,160 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package payjoin
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/bip21"
+)
+
+const (
+	// Version is the version of the payjoin protocol.
+	Version = 1
+
+	// URIParamEndpoint is the parameter of a payment URI holding the
+	// payjoin endpoint of the receiver.
+	URIParamEndpoint = "pj"
+
+	// URIParamOutputSubstitution is the parameter of a payment URI which
+	// disables output substitution when it is 0.
+	URIParamOutputSubstitution = "pjos"
+)
+
+// The error codes of BIP 0078 returned by a receiver.
+const (
+	// ErrCodeUnavailable means the payjoin endpoint is not available.
+	ErrCodeUnavailable = "unavailable"
+
+	// ErrCodeNotEnoughMoney means the receiver has no output to add.
+	ErrCodeNotEnoughMoney = "not-enough-money"
+
+	// ErrCodeVersionUnsupported means the receiver does not support the
+	// requested version.
+	ErrCodeVersionUnsupported = "version-unsupported"
+
+	// ErrCodeOriginalRejected means the original transaction was rejected.
+	ErrCodeOriginalRejected = "original-psbt-rejected"
+)
+
+// Error is an error returned by a payjoin receiver, in the JSON form of BIP
+// 0078.
+type Error struct {
+	Code    string `json:"errorCode"`
+	Message string `json:"message"`
+}
+
+// Error satisfies the error interface.
+func (e *Error) Error() string {
+	return fmt.Sprintf("payjoin receiver error %s: %s", e.Code, e.Message)
+}
+
+// Params are the parameters a sender passes to the payjoin endpoint of the
+// receiver along with the original transaction.
+type Params struct {
+	// Version is the version of the protocol, 1 when it is zero.
+	Version int
+
+	// AdditionalFeeOutputIndex is the index of the output of the sender
+	// the receiver may decrease to pay for the fee of its inputs, or -1
+	// when the receiver must not.
+	AdditionalFeeOutputIndex int
+
+	// MaxAdditionalFeeContribution is the maximum amount the output at
+	// AdditionalFeeOutputIndex can be decreased by.
+	MaxAdditionalFeeContribution btcutil.Amount
+
+	// DisableOutputSubstitution forbids the receiver to change its output.
+	DisableOutputSubstitution bool
+
+	// MinFeeRate is the minimum fee rate in satoshis per virtual byte of
+	// the payjoin transaction, zero when it is not constrained.
+	MinFeeRate float64
+}
+
+// Query returns the URL query encoding the parameters.
+func (p *Params) Query() url.Values {
+	q := url.Values{}
+	version := p.Version
+	if version == 0 {
+		version = Version
+	}
+	q.Set("v", strconv.Itoa(version))
+	if p.AdditionalFeeOutputIndex >= 0 {
+		q.Set("additionalfeeoutputindex",
+			strconv.Itoa(p.AdditionalFeeOutputIndex))
+		q.Set("maxadditionalfeecontribution",
+			strconv.FormatInt(int64(p.MaxAdditionalFeeContribution), 10))
+	}
+	if p.DisableOutputSubstitution {
+		q.Set("disableoutputsubstitution", "true")
+	}
+	if p.MinFeeRate > 0 {
+		q.Set("minfeerate", strconv.FormatFloat(p.MinFeeRate, 'f', -1, 64))
+	}
+	return q
+}
+
+// ParseParams parses the parameters of a payjoin request.  Unknown parameters
+// are ignored.
+func ParseParams(q url.Values) (*Params, error) {
+	p := &Params{Version: Version, AdditionalFeeOutputIndex: -1}
+	var err error
+	if v := q.Get("v"); v != "" {
+		if p.Version, err = strconv.Atoi(v); err != nil {
+			return nil, fmt.Errorf("invalid version %q", v)
+		}
+	}
+	index := q.Get("additionalfeeoutputindex")
+	contribution := q.Get("maxadditionalfeecontribution")
+	if index != "" && contribution != "" {
+		p.AdditionalFeeOutputIndex, err = strconv.Atoi(index)
+		if err != nil || p.AdditionalFeeOutputIndex < 0 {
+			return nil, fmt.Errorf("invalid additional fee output "+
+				"index %q", index)
+		}
+		amount, err := strconv.ParseInt(contribution, 10, 64)
+		if err != nil || amount < 0 {
+			return nil, fmt.Errorf("invalid maximum additional fee "+
+				"contribution %q", contribution)
+		}
+		p.MaxAdditionalFeeContribution = btcutil.Amount(amount)
+	}
+	if v := q.Get("disableoutputsubstitution"); v != "" {
+		if p.DisableOutputSubstitution, err = strconv.ParseBool(v); err != nil {
+			return nil, fmt.Errorf("invalid disableoutputsubstitution "+
+				"%q", v)
+		}
+	}
+	if v := q.Get("minfeerate"); v != "" {
+		p.MinFeeRate, err = strconv.ParseFloat(v, 64)
+		if err != nil || p.MinFeeRate < 0 {
+			return nil, fmt.Errorf("invalid minfeerate %q", v)
+		}
+	}
+	return p, nil
+}
+
+// FromURI returns the payjoin endpoint of a payment URI, or an empty string
+// when it has none, and whether the receiver allows output substitution.
+func FromURI(u *bip21.URI) (string, bool) {
+	return u.Params[URIParamEndpoint], u.Params[URIParamOutputSubstitution] != "0"
+}
+
+// SetURI adds the payjoin endpoint of the receiver to a payment URI.
+func SetURI(u *bip21.URI, endpoint string, outputSubstitution bool) {
+	if u.Params == nil {
+		u.Params = make(map[string]string)
+	}
+	u.Params[URIParamEndpoint] = endpoint
+	if !outputSubstitution {
+		u.Params[URIParamOutputSubstitution] = "0"
+	} else {
+		delete(u.Params, URIParamOutputSubstitution)
+	}
+}
@@ -0,0 +1,272 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package payjoin
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/txauthor"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// p2wpkh returns a pay-to-witness-pubkey-hash script filled with the passed
+// byte.
+func p2wpkh(b byte) []byte {
+	return append([]byte{txscript.OP_0, txscript.OP_DATA_20},
+		bytes.Repeat([]byte{b}, 20)...)
+}
+
+// fakeWitness is the witness of a signed pay-to-witness-pubkey-hash input.
+var fakeWitness = wire.TxWitness{make([]byte, 72), make([]byte, 33)}
+
+// testWallet is a receiver wallet with a single output to contribute, which
+// signs with a fake witness.
+type testWallet struct {
+	script    []byte
+	input     *Input
+	broadcast []*wire.MsgTx
+}
+
+func (w *testWallet) IsMine(pkScript []byte) bool {
+	return bytes.Equal(pkScript, w.script)
+}
+
+func (w *testWallet) CheckOriginal(tx *wire.MsgTx) error {
+	return nil
+}
+
+func (w *testWallet) ScheduleBroadcast(tx *wire.MsgTx) {
+	w.broadcast = append(w.broadcast, tx)
+}
+
+func (w *testWallet) SelectInput(class txscript.ScriptClass) (*Input, error) {
+	if w.input == nil {
+		return nil, ErrNoInput
+	}
+	return w.input, nil
+}
+
+func (w *testWallet) SignInput(proposal *Packet, index int) error {
+	proposal.SetFinalWitness(index, fakeWitness)
+	return nil
+}
+
+// originalPacket returns a signed original transaction spending an output of
+// 10 PKT to pay 1 PKT to the receiver script and 2 PKT to another
+// destination, with its change at index 2 and a fee of 1000 satoshis.
+func originalPacket(receiver []byte) *Packet {
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{
+		Hash: chainhash.Hash{1}}, nil, nil))
+	tx.AddTxOut(wire.NewTxOut(1e9, receiver))
+	tx.AddTxOut(wire.NewTxOut(2e9, p2wpkh(2)))
+	tx.AddTxOut(wire.NewTxOut(7e9-1000, p2wpkh(3)))
+	p := &Packet{
+		UnsignedTx: tx,
+		Inputs:     make([]Map, 1),
+		Outputs:    make([]Map, 3),
+	}
+	p.SetWitnessUtxo(0, wire.NewTxOut(10e9, p2wpkh(1)))
+	p.SetFinalWitness(0, fakeWitness)
+	return p
+}
+
+// TestPacket ensures packets are serialized back without loss and the signed
+// transaction is extracted from their final witnesses.
+func TestPacket(t *testing.T) {
+	p := originalPacket(p2wpkh(9))
+	p.Global.Set(GlobalXPub, []byte{1, 2, 3})
+	p.Outputs[1].Set(0xfc, []byte("proprietary"))
+
+	s, err := p.Base64()
+	if err != nil {
+		t.Fatalf("Base64: %v", err)
+	}
+	decoded, err := DecodeBase64(s)
+	if err != nil {
+		t.Fatalf("DecodeBase64: %v", err)
+	}
+	if !reflect.DeepEqual(decoded.Global, p.Global) ||
+		!reflect.DeepEqual(decoded.Inputs, p.Inputs) ||
+		!reflect.DeepEqual(decoded.Outputs, p.Outputs) ||
+		decoded.UnsignedTx.TxHash() != p.UnsignedTx.TxHash() {
+
+		t.Fatalf("round trip mismatch: got %+v, want %+v", decoded, p)
+	}
+	if s2, err := decoded.Base64(); err != nil || s2 != s {
+		t.Fatalf("Base64: got %s, %v, want %s", s2, err, s)
+	}
+	tx, err := decoded.Extract()
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	if !reflect.DeepEqual(tx.TxIn[0].Witness, fakeWitness) {
+		t.Fatalf("unexpected witness %x", tx.TxIn[0].Witness)
+	}
+	utxo, err := decoded.InputUtxo(0)
+	if err != nil || utxo.Value != 10e9 {
+		t.Fatalf("InputUtxo: got %v, %v", utxo, err)
+	}
+
+	if _, err := DecodeBase64("cHNidP8="); err == nil {
+		t.Fatal("DecodeBase64: unexpected success on truncated packet")
+	}
+}
+
+// TestParams ensures the parameters of a request survive their query
+// encoding.
+func TestParams(t *testing.T) {
+	params := &Params{
+		Version:                      Version,
+		AdditionalFeeOutputIndex:     2,
+		MaxAdditionalFeeContribution: 500,
+		DisableOutputSubstitution:    true,
+		MinFeeRate:                   1.5,
+	}
+	parsed, err := ParseParams(params.Query())
+	if err != nil {
+		t.Fatalf("ParseParams: %v", err)
+	}
+	if !reflect.DeepEqual(parsed, params) {
+		t.Fatalf("got %+v, want %+v", parsed, params)
+	}
+	if _, err := ParseParams(map[string][]string{
+		"v": {"1"}, "additionalfeeoutputindex": {"-1"},
+		"maxadditionalfeecontribution": {"1"},
+	}); err == nil {
+		t.Fatal("ParseParams: unexpected success on negative index")
+	}
+}
+
+// TestPayjoin ensures a receiver proposal to a multi-destination payment
+// passes the checks of the sender, which reject tampered proposals.
+func TestPayjoin(t *testing.T) {
+	receiver := p2wpkh(9)
+	wallet := &testWallet{
+		script: receiver,
+		input: &Input{
+			OutPoint: wire.OutPoint{Hash: chainhash.Hash{2}},
+			TxOut:    wire.NewTxOut(5e9, receiver),
+			Size:     txauthor.InputSize{Witness: 108},
+		},
+	}
+	server := httptest.NewServer(NewReceiver(wallet))
+	defer server.Close()
+
+	params := &Params{
+		AdditionalFeeOutputIndex:     2,
+		MaxAdditionalFeeContribution: 1000,
+	}
+	original := originalPacket(receiver)
+	proposal, err := Request(nil, server.URL, original, params)
+	if err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	if len(wallet.broadcast) != 1 {
+		t.Fatal("original transaction not scheduled for broadcast")
+	}
+	if err := CheckProposal(original, proposal.Copy(), params,
+		receiver); err != nil {
+
+		t.Fatalf("CheckProposal: %v", err)
+	}
+	if n := len(proposal.UnsignedTx.TxIn); n != 2 {
+		t.Fatalf("proposal has %d inputs", n)
+	}
+	var received int64
+	for _, txOut := range proposal.UnsignedTx.TxOut {
+		if bytes.Equal(txOut.PkScript, receiver) {
+			received = txOut.Value
+		}
+	}
+	if received != 6e9 {
+		t.Fatalf("receiver output is %v, want %v", btcutil.Amount(received),
+			btcutil.Amount(6e9))
+	}
+
+	// The same original transaction is only accepted once.
+	if _, err := Request(nil, server.URL, original, params); err == nil {
+		t.Fatal("Request: unexpected success on replayed original")
+	} else if e, ok := err.(*Error); !ok || e.Code != ErrCodeOriginalRejected {
+		t.Fatalf("Request: unexpected error %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		tamper func(p *Packet)
+	}{
+		{"removed output", func(p *Packet) {
+			for i, txOut := range p.UnsignedTx.TxOut {
+				if bytes.Equal(txOut.PkScript, p2wpkh(2)) {
+					p.UnsignedTx.TxOut = append(
+						p.UnsignedTx.TxOut[:i],
+						p.UnsignedTx.TxOut[i+1:]...)
+					p.Outputs = append(p.Outputs[:i],
+						p.Outputs[i+1:]...)
+					return
+				}
+			}
+		}},
+		{"excessive fee", func(p *Packet) {
+			for _, txOut := range p.UnsignedTx.TxOut {
+				if bytes.Equal(txOut.PkScript, p2wpkh(3)) {
+					txOut.Value -= 2000
+				}
+			}
+		}},
+		{"unsigned input", func(p *Packet) {
+			for i := range p.Inputs {
+				p.Inputs[i].Delete(InputFinalScriptWitness)
+			}
+		}},
+		{"changed lock time", func(p *Packet) {
+			p.UnsignedTx.LockTime++
+		}},
+	}
+	for _, test := range tests {
+		tampered := proposal.Copy()
+		test.tamper(tampered)
+		if err := CheckProposal(original, tampered, params,
+			receiver); err == nil {
+
+			t.Errorf("CheckProposal %s: unexpected success", test.name)
+		}
+	}
+
+	// Without output substitution, the payment must be kept as is.
+	strict := *params
+	strict.DisableOutputSubstitution = true
+	if err := CheckProposal(original, proposal.Copy(), &strict,
+		receiver); err != nil {
+
+		t.Fatalf("CheckProposal: %v", err)
+	}
+	substituted := proposal.Copy()
+	for _, txOut := range substituted.UnsignedTx.TxOut {
+		if bytes.Equal(txOut.PkScript, receiver) {
+			txOut.PkScript = p2wpkh(8)
+		}
+	}
+	if err := CheckProposal(original, substituted, &strict,
+		receiver); err == nil {
+
+		t.Fatal("CheckProposal: unexpected success on substituted output")
+	}
+
+	// A receiver without output to contribute reports it.
+	wallet.input = nil
+	original = originalPacket(receiver)
+	original.UnsignedTx.TxIn[0].PreviousOutPoint.Index = 1
+	_, err = Request(nil, server.URL, original, params)
+	if e, ok := err.(*Error); !ok || e.Code != ErrCodeNotEnoughMoney {
+		t.Fatalf("Request: got %v, want %s", err, ErrCodeNotEnoughMoney)
+	}
+}
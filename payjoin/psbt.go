@@ -0,0 +1,392 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package payjoin
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// psbtMagic starts every serialized partially signed transaction.
+var psbtMagic = []byte{0x70, 0x73, 0x62, 0x74, 0xff}
+
+// maxPSBTSize is the maximum size of a key or value of a partially signed
+// transaction, and of a request or response body.
+const maxPSBTSize = 4 * 1024 * 1024
+
+// The key types of BIP 0174 used by payjoin.
+const (
+	// GlobalUnsignedTx is the key type of the unsigned transaction.
+	GlobalUnsignedTx = 0x00
+
+	// GlobalXPub is the key type of the extended public keys.
+	GlobalXPub = 0x01
+
+	// InputNonWitnessUtxo is the key type of the transaction holding the
+	// output spent by an input.
+	InputNonWitnessUtxo = 0x00
+
+	// InputWitnessUtxo is the key type of the output spent by a witness
+	// input.
+	InputWitnessUtxo = 0x01
+
+	// InputPartialSig is the key type of the partial signatures.
+	InputPartialSig = 0x02
+
+	// InputBIP32Derivation is the key type of the key paths of an input.
+	InputBIP32Derivation = 0x06
+
+	// InputFinalScriptSig is the key type of the final signature script.
+	InputFinalScriptSig = 0x07
+
+	// InputFinalScriptWitness is the key type of the final witness.
+	InputFinalScriptWitness = 0x08
+
+	// OutputBIP32Derivation is the key type of the key paths of an output.
+	OutputBIP32Derivation = 0x02
+)
+
+// ErrInvalidPSBT is returned when decoding a malformed partially signed
+// transaction.
+var ErrInvalidPSBT = errors.New("invalid partially signed transaction")
+
+// KeyValue is an entry of a map of a partially signed transaction.  The first
+// byte of the key is its type.
+type KeyValue struct {
+	Key   []byte
+	Value []byte
+}
+
+// Map is a map of a partially signed transaction, with its entries in order.
+type Map []KeyValue
+
+// Get returns the value of the entry with the single byte key typ, or nil.
+func (m Map) Get(typ byte) []byte {
+	for _, kv := range m {
+		if len(kv.Key) == 1 && kv.Key[0] == typ {
+			return kv.Value
+		}
+	}
+	return nil
+}
+
+// Has returns whether the map has an entry of the passed type.
+func (m Map) Has(typ byte) bool {
+	for _, kv := range m {
+		if kv.Key[0] == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// Set sets the value of the entry with the single byte key typ.
+func (m *Map) Set(typ byte, value []byte) {
+	for i, kv := range *m {
+		if len(kv.Key) == 1 && kv.Key[0] == typ {
+			(*m)[i].Value = value
+			return
+		}
+	}
+	*m = append(*m, KeyValue{Key: []byte{typ}, Value: value})
+}
+
+// Delete removes the entries of the passed types.
+func (m *Map) Delete(types ...byte) {
+	kept := (*m)[:0]
+	for _, kv := range *m {
+		deleted := false
+		for _, typ := range types {
+			if kv.Key[0] == typ {
+				deleted = true
+				break
+			}
+		}
+		if !deleted {
+			kept = append(kept, kv)
+		}
+	}
+	*m = kept
+}
+
+// Packet is a partially signed transaction as described by BIP 0174.  The
+// entries of the maps are kept as they are, so the packets are serialized
+// back without loss.
+type Packet struct {
+	UnsignedTx *wire.MsgTx
+	Global     Map
+	Inputs     []Map
+	Outputs    []Map
+}
+
+// readMap reads a map up to its separator.
+func readMap(r io.Reader) (Map, error) {
+	var m Map
+	seen := make(map[string]struct{})
+	for {
+		key, err := wire.ReadVarBytes(r, 0, maxPSBTSize, "key")
+		if err != nil {
+			return nil, err
+		}
+		if len(key) == 0 {
+			return m, nil
+		}
+		if _, ok := seen[string(key)]; ok {
+			return nil, fmt.Errorf("%v: duplicate key %x",
+				ErrInvalidPSBT, key)
+		}
+		seen[string(key)] = struct{}{}
+		value, err := wire.ReadVarBytes(r, 0, maxPSBTSize, "value")
+		if err != nil {
+			return nil, err
+		}
+		m = append(m, KeyValue{Key: key, Value: value})
+	}
+}
+
+// writeMap writes a map followed by its separator.
+func writeMap(w io.Writer, m Map) error {
+	for _, kv := range m {
+		if err := wire.WriteVarBytes(w, 0, kv.Key); err != nil {
+			return err
+		}
+		if err := wire.WriteVarBytes(w, 0, kv.Value); err != nil {
+			return err
+		}
+	}
+	_, err := w.Write([]byte{0})
+	return err
+}
+
+// Decode reads a serialized partially signed transaction.
+func Decode(r io.Reader) (*Packet, error) {
+	magic := make([]byte, len(psbtMagic))
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(magic, psbtMagic) {
+		return nil, fmt.Errorf("%v: bad magic", ErrInvalidPSBT)
+	}
+
+	global, err := readMap(r)
+	if err != nil {
+		return nil, err
+	}
+	rawTx := global.Get(GlobalUnsignedTx)
+	if rawTx == nil {
+		return nil, fmt.Errorf("%v: missing unsigned transaction",
+			ErrInvalidPSBT)
+	}
+	var tx wire.MsgTx
+	if err := tx.DeserializeNoWitness(bytes.NewReader(rawTx)); err != nil {
+		return nil, fmt.Errorf("%v: %v", ErrInvalidPSBT, err)
+	}
+	for _, txIn := range tx.TxIn {
+		if len(txIn.SignatureScript) != 0 || len(txIn.Witness) != 0 {
+			return nil, fmt.Errorf("%v: signed unsigned transaction",
+				ErrInvalidPSBT)
+		}
+	}
+	global.Delete(GlobalUnsignedTx)
+
+	p := &Packet{
+		UnsignedTx: &tx,
+		Global:     global,
+		Inputs:     make([]Map, len(tx.TxIn)),
+		Outputs:    make([]Map, len(tx.TxOut)),
+	}
+	for i := range p.Inputs {
+		if p.Inputs[i], err = readMap(r); err != nil {
+			return nil, err
+		}
+	}
+	for i := range p.Outputs {
+		if p.Outputs[i], err = readMap(r); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// Encode writes the serialized partially signed transaction.
+func (p *Packet) Encode(w io.Writer) error {
+	if len(p.Inputs) != len(p.UnsignedTx.TxIn) ||
+		len(p.Outputs) != len(p.UnsignedTx.TxOut) {
+
+		return fmt.Errorf("%v: maps do not match the transaction",
+			ErrInvalidPSBT)
+	}
+
+	var rawTx bytes.Buffer
+	if err := p.UnsignedTx.SerializeNoWitness(&rawTx); err != nil {
+		return err
+	}
+	if _, err := w.Write(psbtMagic); err != nil {
+		return err
+	}
+	global := append(Map{{Key: []byte{GlobalUnsignedTx},
+		Value: rawTx.Bytes()}}, p.Global...)
+	if err := writeMap(w, global); err != nil {
+		return err
+	}
+	for _, m := range p.Inputs {
+		if err := writeMap(w, m); err != nil {
+			return err
+		}
+	}
+	for _, m := range p.Outputs {
+		if err := writeMap(w, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DecodeBase64 decodes a base64 partially signed transaction.
+func DecodeBase64(s string) (*Packet, error) {
+	raw, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", ErrInvalidPSBT, err)
+	}
+	return Decode(bytes.NewReader(raw))
+}
+
+// Base64 returns the base64 encoding of the partially signed transaction.
+func (p *Packet) Base64() (string, error) {
+	var buf bytes.Buffer
+	if err := p.Encode(&buf); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// Copy returns a deep copy of the packet.
+func (p *Packet) Copy() *Packet {
+	copyMap := func(m Map) Map {
+		c := make(Map, len(m))
+		for i, kv := range m {
+			c[i] = KeyValue{
+				Key:   append([]byte(nil), kv.Key...),
+				Value: append([]byte(nil), kv.Value...),
+			}
+		}
+		return c
+	}
+	c := &Packet{
+		UnsignedTx: p.UnsignedTx.Copy(),
+		Global:     copyMap(p.Global),
+		Inputs:     make([]Map, len(p.Inputs)),
+		Outputs:    make([]Map, len(p.Outputs)),
+	}
+	for i, m := range p.Inputs {
+		c.Inputs[i] = copyMap(m)
+	}
+	for i, m := range p.Outputs {
+		c.Outputs[i] = copyMap(m)
+	}
+	return c
+}
+
+// InputUtxo returns the output spent by the input with the passed index, from
+// its witness or non-witness UTXO entry.
+func (p *Packet) InputUtxo(i int) (*wire.TxOut, error) {
+	prevOut := &p.UnsignedTx.TxIn[i].PreviousOutPoint
+	if raw := p.Inputs[i].Get(InputWitnessUtxo); raw != nil {
+		r := bytes.NewReader(raw)
+		var value [8]byte
+		if _, err := io.ReadFull(r, value[:]); err != nil {
+			return nil, err
+		}
+		pkScript, err := wire.ReadVarBytes(r, 0, maxPSBTSize, "pkScript")
+		if err != nil {
+			return nil, err
+		}
+		amount := int64(binary.LittleEndian.Uint64(value[:]))
+		return wire.NewTxOut(amount, pkScript), nil
+	}
+	if raw := p.Inputs[i].Get(InputNonWitnessUtxo); raw != nil {
+		var prevTx wire.MsgTx
+		if err := prevTx.Deserialize(bytes.NewReader(raw)); err != nil {
+			return nil, err
+		}
+		if prevTx.TxHash() != prevOut.Hash ||
+			int(prevOut.Index) >= len(prevTx.TxOut) {
+
+			return nil, fmt.Errorf("%v: UTXO of input %d does not "+
+				"match its outpoint", ErrInvalidPSBT, i)
+		}
+		return prevTx.TxOut[prevOut.Index], nil
+	}
+	return nil, fmt.Errorf("%v: input %d has no UTXO", ErrInvalidPSBT, i)
+}
+
+// SetWitnessUtxo sets the witness UTXO entry of the input with the passed
+// index.
+func (p *Packet) SetWitnessUtxo(i int, txOut *wire.TxOut) {
+	var buf bytes.Buffer
+	var value [8]byte
+	binary.LittleEndian.PutUint64(value[:], uint64(txOut.Value))
+	buf.Write(value[:])
+	wire.WriteVarBytes(&buf, 0, txOut.PkScript)
+	p.Inputs[i].Set(InputWitnessUtxo, buf.Bytes())
+}
+
+// IsFinalized returns whether the input with the passed index has its final
+// signature script or witness.
+func (p *Packet) IsFinalized(i int) bool {
+	return p.Inputs[i].Has(InputFinalScriptSig) ||
+		p.Inputs[i].Has(InputFinalScriptWitness)
+}
+
+// SetFinalWitness sets the final witness of the input with the passed index.
+func (p *Packet) SetFinalWitness(i int, witness wire.TxWitness) {
+	var buf bytes.Buffer
+	wire.WriteVarInt(&buf, 0, uint64(len(witness)))
+	for _, item := range witness {
+		wire.WriteVarBytes(&buf, 0, item)
+	}
+	p.Inputs[i].Set(InputFinalScriptWitness, buf.Bytes())
+}
+
+// Extract returns the signed transaction of a packet whose inputs are all
+// finalized.
+func (p *Packet) Extract() (*wire.MsgTx, error) {
+	tx := p.UnsignedTx.Copy()
+	for i, txIn := range tx.TxIn {
+		if !p.IsFinalized(i) {
+			return nil, fmt.Errorf("%v: input %d is not finalized",
+				ErrInvalidPSBT, i)
+		}
+		txIn.SignatureScript = p.Inputs[i].Get(InputFinalScriptSig)
+		raw := p.Inputs[i].Get(InputFinalScriptWitness)
+		if raw == nil {
+			continue
+		}
+		r := bytes.NewReader(raw)
+		count, err := wire.ReadVarInt(r, 0)
+		if err != nil {
+			return nil, err
+		}
+		if count > uint64(len(raw)) {
+			return nil, fmt.Errorf("%v: bad witness of input %d",
+				ErrInvalidPSBT, i)
+		}
+		txIn.Witness = make(wire.TxWitness, count)
+		for j := range txIn.Witness {
+			txIn.Witness[j], err = wire.ReadVarBytes(r, 0,
+				maxPSBTSize, "witness item")
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return tx, nil
+}
@@ -0,0 +1,305 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package payjoin
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/txauthor"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// ErrNoInput is returned by Wallet.SelectInput when the wallet has no output
+// to contribute.
+var ErrNoInput = errors.New("no input to contribute")
+
+// Input is an unspent output of the receiver contributed to a payjoin.
+type Input struct {
+	OutPoint wire.OutPoint
+	TxOut    *wire.TxOut
+
+	// Size is the size of the signature of the input once it is signed.
+	Size txauthor.InputSize
+}
+
+// Wallet is the wallet of a receiver.
+type Wallet interface {
+	// IsMine returns whether the passed output script pays the wallet.
+	IsMine(pkScript []byte) bool
+
+	// CheckOriginal ensures the original transaction could be broadcast,
+	// typically by testing its acceptance to the mempool of the node.
+	CheckOriginal(tx *wire.MsgTx) error
+
+	// ScheduleBroadcast broadcasts the original transaction after a
+	// while unless the payjoin transaction is seen first, so the payment
+	// is received even when the sender does not complete the payjoin.
+	ScheduleBroadcast(tx *wire.MsgTx)
+
+	// SelectInput returns an unspent output of the wallet to contribute,
+	// preferably paying to a script of the passed class, or ErrNoInput.
+	SelectInput(class txscript.ScriptClass) (*Input, error)
+
+	// SignInput finalizes the input of the wallet at the passed index of
+	// the proposal, whose other fields must not be changed.
+	SignInput(proposal *Packet, index int) error
+}
+
+// Receiver is the payjoin endpoint of a wallet, serving the payjoin requests
+// of senders over HTTP as described by BIP 0078.
+type Receiver struct {
+	wallet Wallet
+
+	// seen holds the outpoints spent by the original transactions already
+	// processed, so a sender can't probe the outputs of the wallet by
+	// sending many original transactions spending the same outputs.
+	seenMtx sync.Mutex
+	seen    map[wire.OutPoint]struct{}
+}
+
+// NewReceiver returns a receiver contributing the outputs of the passed wallet.
+func NewReceiver(wallet Wallet) *Receiver {
+	return &Receiver{
+		wallet: wallet,
+		seen:   make(map[wire.OutPoint]struct{}),
+	}
+}
+
+// writeError replies with a BIP 0078 error.
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(&Error{Code: code, Message: message})
+}
+
+// ServeHTTP implements http.Handler.
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, ErrCodeUnavailable,
+			"payjoin requests must be posted")
+		return
+	}
+	params, err := ParseParams(req.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeOriginalRejected,
+			err.Error())
+		return
+	}
+	if params.Version != Version {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"errorCode": ErrCodeVersionUnsupported,
+			"supported": []int{Version},
+			"message":   "This version of payjoin is not supported.",
+		})
+		return
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(req.Body, maxPSBTSize))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeOriginalRejected,
+			err.Error())
+		return
+	}
+	original, err := DecodeBase64(strings.TrimSpace(string(body)))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeOriginalRejected,
+			err.Error())
+		return
+	}
+
+	proposal, err := r.Propose(original, params)
+	switch err := err.(type) {
+	case nil:
+	case *Error:
+		status := http.StatusBadRequest
+		if err.Code == ErrCodeUnavailable {
+			status = http.StatusServiceUnavailable
+		}
+		writeError(w, status, err.Code, err.Message)
+		return
+	default:
+		writeError(w, http.StatusInternalServerError,
+			ErrCodeUnavailable, err.Error())
+		return
+	}
+	reply, err := proposal.Base64()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError,
+			ErrCodeUnavailable, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	io.WriteString(w, reply)
+}
+
+// markSeen records the outpoints spent by an original transaction and
+// returns false when one of them was already spent by a previous one.
+func (r *Receiver) markSeen(tx *wire.MsgTx) bool {
+	r.seenMtx.Lock()
+	defer r.seenMtx.Unlock()
+
+	for _, txIn := range tx.TxIn {
+		if _, ok := r.seen[txIn.PreviousOutPoint]; ok {
+			return false
+		}
+	}
+	for _, txIn := range tx.TxIn {
+		r.seen[txIn.PreviousOutPoint] = struct{}{}
+	}
+	return true
+}
+
+// randIndex returns a random integer in [0, n].
+func randIndex(n int) int {
+	i, err := rand.Int(rand.Reader, big.NewInt(int64(n)+1))
+	if err != nil {
+		return n
+	}
+	return int(i.Int64())
+}
+
+// Propose checks the original transaction of a sender and returns the payjoin
+// proposal adding an input of the wallet.  The errors to report to the sender
+// are returned as *Error.
+func (r *Receiver) Propose(original *Packet, params *Params) (*Packet, error) {
+	reject := func(message string) error {
+		return &Error{Code: ErrCodeOriginalRejected, Message: message}
+	}
+
+	// The original transaction must be fully signed, spend no output of
+	// the wallet, use a single script type and pay the wallet.
+	tx, err := original.Extract()
+	if err != nil {
+		return nil, reject(err.Error())
+	}
+	class := txscript.NonStandardTy
+	sizes := make([]txauthor.InputSize, len(tx.TxIn))
+	for i := range tx.TxIn {
+		utxo, err := original.InputUtxo(i)
+		if err != nil {
+			return nil, reject(err.Error())
+		}
+		if r.wallet.IsMine(utxo.PkScript) {
+			return nil, reject("the original transaction spends " +
+				"outputs of the receiver")
+		}
+		c := scriptClass(utxo.PkScript, original.Inputs[i])
+		if i > 0 && c != class {
+			return nil, reject("the original transaction mixes " +
+				"input types")
+		}
+		class = c
+		sizes[i] = inputSize(original.Inputs[i])
+	}
+	payee := -1
+	for i, txOut := range tx.TxOut {
+		if r.wallet.IsMine(txOut.PkScript) {
+			payee = i
+			break
+		}
+	}
+	if payee < 0 {
+		return nil, reject("the original transaction does not pay " +
+			"the receiver")
+	}
+	if err := r.wallet.CheckOriginal(tx); err != nil {
+		return nil, reject(err.Error())
+	}
+	if !r.markSeen(tx) {
+		return nil, reject("the inputs of the original transaction " +
+			"were already used")
+	}
+	r.wallet.ScheduleBroadcast(tx)
+
+	origFee, err := packetFee(original)
+	if err != nil {
+		return nil, reject(err.Error())
+	}
+	origVSize := txauthor.EstimateVirtualSize(original.UnsignedTx, sizes)
+
+	input, err := r.wallet.SelectInput(class)
+	if err == ErrNoInput {
+		return nil, &Error{Code: ErrCodeNotEnoughMoney,
+			Message: "the receiver has no output to contribute"}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The proposal keeps the transaction of the sender without its
+	// signatures and key information, with the input of the receiver
+	// inserted at a random position.
+	proposal := original.Copy()
+	proposal.Global.Delete(GlobalXPub)
+	for i := range proposal.Inputs {
+		proposal.Inputs[i].Delete(InputNonWitnessUtxo, InputWitnessUtxo,
+			InputPartialSig, InputBIP32Derivation, InputFinalScriptSig,
+			InputFinalScriptWitness)
+	}
+	for i := range proposal.Outputs {
+		proposal.Outputs[i].Delete(OutputBIP32Derivation)
+	}
+	txIn := wire.NewTxIn(&input.OutPoint, nil, nil)
+	txIn.Sequence = tx.TxIn[0].Sequence
+	index := randIndex(len(proposal.UnsignedTx.TxIn))
+	propTx := proposal.UnsignedTx
+	propTx.TxIn = append(propTx.TxIn, nil)
+	copy(propTx.TxIn[index+1:], propTx.TxIn[index:])
+	propTx.TxIn[index] = txIn
+	proposal.Inputs = append(proposal.Inputs, nil)
+	copy(proposal.Inputs[index+1:], proposal.Inputs[index:])
+	proposal.Inputs[index] = Map{}
+	proposal.SetWitnessUtxo(index, input.TxOut)
+	sizes = append(sizes, txauthor.InputSize{})
+	copy(sizes[index+1:], sizes[index:])
+	sizes[index] = input.Size
+
+	// The input pays the fee of its size at the fee rate of the original
+	// transaction, or the minimum fee rate of the sender when it is
+	// higher.  The sender pays up to its maximum contribution from its
+	// fee output and the wallet the rest.
+	propVSize := txauthor.EstimateVirtualSize(propTx, sizes)
+	rate := float64(origFee) / float64(origVSize)
+	fee := btcutil.Amount(math.Ceil(rate * float64(propVSize-origVSize)))
+	if minFee := btcutil.Amount(math.Ceil(params.MinFeeRate *
+		float64(propVSize))); minFee-origFee > fee {
+
+		fee = minFee - origFee
+	}
+	senderFee := btcutil.Amount(0)
+	if i := params.AdditionalFeeOutputIndex; i >= 0 && i < len(propTx.TxOut) &&
+		i != payee {
+
+		senderFee = fee
+		if senderFee > params.MaxAdditionalFeeContribution {
+			senderFee = params.MaxAdditionalFeeContribution
+		}
+		if max := btcutil.Amount(propTx.TxOut[i].Value); senderFee > max {
+			senderFee = max
+		}
+		propTx.TxOut[i].Value -= int64(senderFee)
+	}
+	propTx.TxOut[payee].Value += input.TxOut.Value - int64(fee-senderFee)
+
+	if err := r.wallet.SignInput(proposal, index); err != nil {
+		return nil, err
+	}
+	if !proposal.IsFinalized(index) {
+		return nil, errors.New("the input of the receiver was not signed")
+	}
+	return proposal, nil
+}
@@ -0,0 +1,276 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package payjoin
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/txauthor"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// DefaultTimeout is the time a sender waits for the proposal of the receiver
+// before falling back to the original transaction.
+const DefaultTimeout = 30 * time.Second
+
+// ErrInvalidProposal is returned when the proposal of a receiver does not
+// satisfy the checks of the sender.  The sender broadcasts the original
+// transaction instead.
+var ErrInvalidProposal = errors.New("invalid payjoin proposal")
+
+// inputSize returns the size of the signature script and witness of a
+// finalized input.
+func inputSize(m Map) txauthor.InputSize {
+	return txauthor.InputSize{
+		SigScript: len(m.Get(InputFinalScriptSig)),
+		Witness:   len(m.Get(InputFinalScriptWitness)),
+	}
+}
+
+// packetFee returns the fee of a packet whose inputs all have their UTXO.
+func packetFee(p *Packet) (btcutil.Amount, error) {
+	var fee int64
+	for i := range p.Inputs {
+		utxo, err := p.InputUtxo(i)
+		if err != nil {
+			return 0, err
+		}
+		fee += utxo.Value
+	}
+	for _, txOut := range p.UnsignedTx.TxOut {
+		fee -= txOut.Value
+	}
+	return btcutil.Amount(fee), nil
+}
+
+// scriptClass returns the class of the script paying to the passed output,
+// looking through pay-to-script-hash outputs spent with a witness.
+func scriptClass(pkScript []byte, m Map) txscript.ScriptClass {
+	class := txscript.GetScriptClass(pkScript)
+	if class == txscript.ScriptHashTy && m.Has(InputFinalScriptWitness) {
+		return txscript.WitnessV0PubKeyHashTy
+	}
+	return class
+}
+
+// Request posts the original transaction, a packet whose inputs are all
+// finalized, to the payjoin endpoint of the receiver and returns its
+// proposal.  The proposal must be checked with CheckProposal before it is
+// signed.  A nil client uses one with DefaultTimeout.
+func Request(client *http.Client, endpoint string, original *Packet,
+	params *Params) (*Packet, error) {
+
+	if client == nil {
+		client = &http.Client{Timeout: DefaultTimeout}
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+	for k, v := range params.Query() {
+		q[k] = v
+	}
+	u.RawQuery = q.Encode()
+
+	body, err := original.Base64()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Post(u.String(), "text/plain",
+		strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	reply, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxPSBTSize))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var rerr Error
+		if err := json.Unmarshal(reply, &rerr); err != nil ||
+			rerr.Code == "" {
+
+			return nil, fmt.Errorf("payjoin receiver returned %s",
+				resp.Status)
+		}
+		return nil, &rerr
+	}
+	return DecodeBase64(string(bytes.TrimSpace(reply)))
+}
+
+// CheckProposal checks the proposal of a receiver against the original
+// transaction as described by BIP 0078, so the sender only signs a
+// transaction which pays what it intended, plus at most the additional fee it
+// allowed.  payeeScript is the output script of the payment to the receiver.
+// The UTXO entries of the inputs of the sender are restored in the proposal so
+// it can be signed.
+func CheckProposal(original, proposal *Packet, params *Params,
+	payeeScript []byte) error {
+
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%v: %s", ErrInvalidProposal,
+			fmt.Sprintf(format, args...))
+	}
+	origTx, propTx := original.UnsignedTx, proposal.UnsignedTx
+	if propTx.Version != origTx.Version || propTx.LockTime != origTx.LockTime {
+		return invalid("version or lock time changed")
+	}
+	if proposal.Global.Has(GlobalXPub) {
+		return invalid("extended public keys added")
+	}
+
+	// The inputs of the sender must all be present, unsigned and
+	// unchanged, and the inputs of the receiver must be finalized and of
+	// the same type as the ones of the sender.
+	origInputs := make(map[wire.OutPoint]int, len(origTx.TxIn))
+	senderClass := txscript.NonStandardTy
+	for i, txIn := range origTx.TxIn {
+		origInputs[txIn.PreviousOutPoint] = i
+		utxo, err := original.InputUtxo(i)
+		if err != nil {
+			return err
+		}
+		class := scriptClass(utxo.PkScript, original.Inputs[i])
+		if i == 0 {
+			senderClass = class
+		} else if class != senderClass {
+			senderClass = txscript.NonStandardTy
+		}
+	}
+	sizes := make([]txauthor.InputSize, len(propTx.TxIn))
+	found := 0
+	var receiverInputs []int
+	for i, txIn := range propTx.TxIn {
+		j, ok := origInputs[txIn.PreviousOutPoint]
+		if !ok {
+			if !proposal.IsFinalized(i) {
+				return invalid("input %d of the receiver is not "+
+					"finalized", i)
+			}
+			utxo, err := proposal.InputUtxo(i)
+			if err != nil {
+				return invalid("%v", err)
+			}
+			if txIn.Sequence != origTx.TxIn[0].Sequence {
+				return invalid("input %d has a different sequence", i)
+			}
+			if senderClass != txscript.NonStandardTy &&
+				scriptClass(utxo.PkScript, proposal.Inputs[i]) != senderClass {
+
+				return invalid("input %d has a different script type", i)
+			}
+			sizes[i] = inputSize(proposal.Inputs[i])
+			receiverInputs = append(receiverInputs, i)
+			continue
+		}
+
+		found++
+		if txIn.Sequence != origTx.TxIn[j].Sequence {
+			return invalid("sequence of input %d changed", i)
+		}
+		m := proposal.Inputs[i]
+		if m.Has(InputFinalScriptSig) || m.Has(InputFinalScriptWitness) ||
+			m.Has(InputPartialSig) || m.Has(InputBIP32Derivation) {
+
+			return invalid("input %d of the sender is signed", i)
+		}
+		for _, typ := range []byte{InputNonWitnessUtxo, InputWitnessUtxo} {
+			if v := original.Inputs[j].Get(typ); v != nil {
+				proposal.Inputs[i].Set(typ, v)
+			}
+		}
+		sizes[i] = inputSize(original.Inputs[j])
+	}
+	if found != len(origTx.TxIn) {
+		return invalid("inputs of the sender removed")
+	}
+	if len(receiverInputs) == 0 {
+		return invalid("no input added")
+	}
+
+	// The outputs of the sender other than the payment must all be
+	// present, and only the fee output may be decreased, by at most the
+	// allowed contribution.
+	var feeDecrease btcutil.Amount
+	for i, origOut := range origTx.TxOut {
+		isPayee := bytes.Equal(origOut.PkScript, payeeScript)
+		if isPayee && !params.DisableOutputSubstitution {
+			continue
+		}
+		k := -1
+		for j, propOut := range propTx.TxOut {
+			if bytes.Equal(propOut.PkScript, origOut.PkScript) {
+				k = j
+				break
+			}
+		}
+		if k < 0 {
+			return invalid("output %d removed", i)
+		}
+		if proposal.Outputs[k].Has(OutputBIP32Derivation) {
+			return invalid("key paths added to output %d", k)
+		}
+		value := propTx.TxOut[k].Value
+		switch {
+		case value >= origOut.Value:
+		case i == params.AdditionalFeeOutputIndex && !isPayee:
+			feeDecrease = btcutil.Amount(origOut.Value - value)
+			if feeDecrease > params.MaxAdditionalFeeContribution {
+				return invalid("fee contribution of %v exceeds "+
+					"%v", feeDecrease,
+					params.MaxAdditionalFeeContribution)
+			}
+		default:
+			return invalid("output %d decreased", i)
+		}
+	}
+
+	// The fee contribution of the sender must only pay for the fee of the
+	// inputs of the receiver, and the fee rate must not drop.
+	origFee, err := packetFee(original)
+	if err != nil {
+		return err
+	}
+	propFee, err := packetFee(proposal)
+	if err != nil {
+		return invalid("%v", err)
+	}
+	if feeDecrease > propFee-origFee {
+		return invalid("fee contribution exceeds the additional fee")
+	}
+	origSizes := make([]txauthor.InputSize, len(origTx.TxIn))
+	for i := range origSizes {
+		origSizes[i] = inputSize(original.Inputs[i])
+	}
+	origVSize := txauthor.EstimateVirtualSize(origTx, origSizes)
+	propVSize := txauthor.EstimateVirtualSize(propTx, sizes)
+	propRate := float64(propFee) / float64(propVSize)
+	if params.MinFeeRate > 0 && propRate < params.MinFeeRate {
+		return invalid("fee rate %.2f is below the minimum %.2f",
+			propRate, params.MinFeeRate)
+	}
+	if feeDecrease > 0 {
+		origRate := float64(origFee) / float64(origVSize)
+		maxDecrease := btcutil.Amount(origRate * float64(propVSize-origVSize))
+		if feeDecrease > maxDecrease+1 {
+			return invalid("fee contribution of %v exceeds the fee of "+
+				"the added inputs", feeDecrease)
+		}
+	}
+	return nil
+}
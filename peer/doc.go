@@ -104,7 +104,12 @@ Of special interest are inventory messages.  Rather than manually sending MsgInv
 messages via Queuemessage, the inventory vectors should be queued using the
 QueueInventory function.  It employs batching and trickling along with
 intelligent known remote peer inventory detection and avoidance through the use
-of a most-recently used algorithm.
+of a most-recently used algorithm.  Transaction inventory queued with
+QueueTxInventory is announced by descending fee rate, and transactions queued
+in the InvLaneLocal lane, such as those submitted by a local wallet, are the
+first to fit in the next trickle, among the relayed inventory.  The time
+between trickles is randomized around the TrickleInterval of the peer so the
+announcements of a transaction to different peers are not made in lockstep.
 
 Message Sending Helper Functions
 
@@ -0,0 +1,113 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package peer
+
+import (
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// InvLane identifies the lane of the inventory send queue a queued inventory
+// is trickled from.  Lanes are drained in order, so the inventory of a lane is
+// picked for a trickle before the inventory of the lanes after it.
+type InvLane int
+
+const (
+	// InvLaneLocal is the lane for transactions submitted locally, such as
+	// by a wallet through the RPC server.  They are announced with the next
+	// trickle to the peer with room for them, but not any sooner, so they
+	// are not told apart from relayed transactions by their timing.  Nor
+	// are they by their position, since each batch is announced by fee
+	// rate regardless of the lanes.
+	InvLaneLocal InvLane = iota
+
+	// InvLaneRelay is the lane for all other inventory.
+	InvLaneRelay
+
+	// numInvLanes is the number of inventory lanes.
+	numInvLanes
+)
+
+// queuedInv is an inventory waiting in the inventory send queue of a peer.
+type queuedInv struct {
+	iv       *wire.InvVect
+	lane     InvLane
+	feePerKB int64
+}
+
+// invSendQueue is the queue of inventory trickled to a peer.  It has a lane
+// per InvLane and the inventory of each lane is announced by descending fee
+// rate, so the most valuable transactions go out first when more inventory is
+// queued than fits in a batch.  It is only used by the queueHandler goroutine
+// and therefore is not safe for concurrent access.
+type invSendQueue struct {
+	lanes [numInvLanes][]queuedInv
+}
+
+// Len returns the number of queued inventory.
+func (q *invSendQueue) Len() int {
+	n := 0
+	for _, lane := range q.lanes {
+		n += len(lane)
+	}
+	return n
+}
+
+// Push adds the passed inventory to its lane of the queue.  Inventory with an
+// unknown lane is added to the relay lane.
+func (q *invSendQueue) Push(qi queuedInv) {
+	if qi.lane < 0 || qi.lane >= numInvLanes {
+		qi.lane = InvLaneRelay
+	}
+	q.lanes[qi.lane] = append(q.lanes[qi.lane], qi)
+}
+
+// NextBatch removes and returns up to maxSize items of inventory to announce
+// with the next trickle, in the order to announce it.  The items are taken
+// from the lanes in order and, within a lane, by descending fee rate and then
+// by the order they were queued in.  The batch is then ordered by descending
+// fee rate across the lanes, so the lane of an item is not given away by its
+// position.
+func (q *invSendQueue) NextBatch(maxSize int) []queuedInv {
+	var batch []queuedInv
+	for i := range q.lanes {
+		lane := q.lanes[i]
+		if maxSize <= 0 {
+			break
+		}
+		if len(lane) == 0 {
+			continue
+		}
+		sort.SliceStable(lane, func(a, b int) bool {
+			return lane[a].feePerKB > lane[b].feePerKB
+		})
+
+		n := len(lane)
+		if n > maxSize {
+			n = maxSize
+		}
+		maxSize -= n
+		batch = append(batch, lane[:n]...)
+
+		// Copy the remainder so the announced inventory does not stay
+		// reachable through the backing array of the lane.
+		q.lanes[i] = append([]queuedInv(nil), lane[n:]...)
+	}
+	sort.SliceStable(batch, func(a, b int) bool {
+		return batch[a].feePerKB > batch[b].feePerKB
+	})
+	return batch
+}
+
+// trickleDelay returns the time to wait before the next trickle to a peer.  It
+// is drawn uniformly between half and one and a half times the passed
+// interval, so trickles to different peers do not happen in lockstep, and
+// bursts of announcements cannot be matched across peers by their timing.
+func trickleDelay(interval time.Duration) time.Duration {
+	return interval/2 + time.Duration(rand.Int63n(int64(interval)+1))
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package peer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// TestInvSendQueue ensures the inventory send queue fills batches from the
+// local lane first, and announces each batch by descending fee rate.
+func TestInvSendQueue(t *testing.T) {
+	queued := []struct {
+		id       byte
+		lane     InvLane
+		feePerKB int64
+	}{
+		{1, InvLaneRelay, 1000},
+		{2, InvLaneRelay, 5000},
+		{3, InvLaneLocal, 10},
+		{4, InvLaneRelay, 1000},
+		{5, InvLaneLocal, 20},
+		{6, InvLaneLocal, 30},
+		{7, InvLane(-1), 2000},
+	}
+	var q invSendQueue
+	for _, inv := range queued {
+		hash := chainhash.Hash{inv.id}
+		q.Push(queuedInv{
+			iv:       wire.NewInvVect(wire.InvTypeTx, &hash),
+			lane:     inv.lane,
+			feePerKB: inv.feePerKB,
+		})
+	}
+	if q.Len() != len(queued) {
+		t.Fatalf("Len: got %d, want %d", q.Len(), len(queued))
+	}
+
+	// The local lane counts against the batch size, its inventory is mixed
+	// with the relayed one, and equal fee rates keep the order they were
+	// queued in.
+	tests := []struct {
+		maxSize int
+		want    []byte
+	}{
+		{2, []byte{6, 5}},
+		{3, []byte{2, 7, 3}},
+		{5, []byte{1, 4}},
+		{5, nil},
+	}
+	for i, test := range tests {
+		batch := q.NextBatch(test.maxSize)
+		if len(batch) != len(test.want) {
+			t.Fatalf("NextBatch #%d: got %d items, want %d", i,
+				len(batch), len(test.want))
+		}
+		for j, qi := range batch {
+			if qi.iv.Hash[0] != test.want[j] {
+				t.Fatalf("NextBatch #%d: item %d is %d, want %d",
+					i, j, qi.iv.Hash[0], test.want[j])
+			}
+		}
+	}
+	if q.Len() != 0 {
+		t.Fatalf("Len: got %d, want 0", q.Len())
+	}
+}
+
+// TestTrickleDelay ensures trickle delays stay within half and one and a half
+// times the interval.
+func TestTrickleDelay(t *testing.T) {
+	const interval = 10 * time.Second
+	for i := 0; i < 1000; i++ {
+		delay := trickleDelay(interval)
+		if delay < interval/2 || delay > interval*3/2 {
+			t.Fatalf("trickleDelay: %v is out of range", delay)
+		}
+	}
+}
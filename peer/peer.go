@@ -42,10 +42,14 @@ const (
 	// outputBufferSize is the number of elements the output channels use.
 	outputBufferSize = 50
 
-	// invTrickleSize is the maximum amount of inventory to send in a single
-	// message when trickling inventory to remote peers.
+	// maxInvTrickleSize is the maximum amount of inventory to send in a
+	// single message when trickling inventory to remote peers.
 	maxInvTrickleSize = 1000
 
+	// DefaultTrickleBatchSize is the default maximum amount of inventory
+	// to announce to a peer with each trickle.
+	DefaultTrickleBatchSize = maxInvTrickleSize
+
 	// maxKnownInventory is the maximum number of items to keep in the known
 	// inventory cache.
 	maxKnownInventory = 1000
@@ -169,6 +173,13 @@ type MessageListeners struct {
 	// bitcoin message.
 	OnGetCFCheckpt func(p *Peer, msg *wire.MsgGetCFCheckpt)
 
+	// OnGetUtreexoProof is invoked when a peer receives a getuproof
+	// message.
+	OnGetUtreexoProof func(p *Peer, msg *wire.MsgGetUtreexoProof)
+
+	// OnUtreexoProof is invoked when a peer receives a uproof message.
+	OnUtreexoProof func(p *Peer, msg *wire.MsgUtreexoProof)
+
 	// OnFeeFilter is invoked when a peer receives a feefilter bitcoin message.
 	OnFeeFilter func(p *Peer, msg *wire.MsgFeeFilter)
 
@@ -273,9 +284,15 @@ type Config struct {
 	// messages.
 	Listeners MessageListeners
 
-	// TrickleInterval is the duration of the ticker which trickles down the
-	// inventory to a peer.
+	// TrickleInterval is the average time between trickles of inventory to
+	// the peer.  The time until each trickle is randomized between half and
+	// one and a half times the interval.
 	TrickleInterval time.Duration
+
+	// TrickleBatchSize is the maximum amount of inventory announced to the
+	// peer with each trickle.  Inventory that does not fit waits for the
+	// next trickle.  Locally submitted transactions are the first to fit.
+	TrickleBatchSize int
 }
 
 // minUint32 is a helper function to return the minimum of two uint32s.
@@ -380,6 +397,8 @@ type StatsSnap struct {
 	LastPingNonce  uint64
 	LastPingTime   time.Time
 	LastPingMicros int64
+	InvQueued      int
+	InvAnnounced   uint64
 }
 
 // HashFunc is a function which returns a block hash, height and error
@@ -424,10 +443,12 @@ type Peer struct {
 	// The following variables must only be used atomically.
 	bytesReceived uint64
 	bytesSent     uint64
+	invAnnounced  uint64
 	lastRecv      int64
 	lastSend      int64
 	connected     int32
 	disconnect    int32
+	invQueued     int32
 
 	conn net.Conn
 
@@ -475,7 +496,7 @@ type Peer struct {
 	outputQueue   chan outMsg
 	sendQueue     chan outMsg
 	sendDoneQueue chan struct{}
-	outputInvChan chan *wire.InvVect
+	outputInvChan chan queuedInv
 	inQuit        chan struct{}
 	queueQuit     chan struct{}
 	outQuit       chan struct{}
@@ -521,6 +542,14 @@ func (p *Peer) AddKnownInventory(invVect *wire.InvVect) {
 	p.knownInventory.Add(invVect)
 }
 
+// IsKnownInventory returns whether the passed inventory is in the cache of
+// known inventory for the peer, such as when the peer announced it.
+//
+// This function is safe for concurrent access.
+func (p *Peer) IsKnownInventory(invVect *wire.InvVect) bool {
+	return p.knownInventory.Exists(invVect)
+}
+
 // StatsSnapshot returns a snapshot of the current peer flags and statistics.
 //
 // This function is safe for concurrent access.
@@ -554,6 +583,8 @@ func (p *Peer) StatsSnapshot() *StatsSnap {
 		LastPingNonce:  p.lastPingNonce,
 		LastPingMicros: p.lastPingMicros,
 		LastPingTime:   p.lastPingTime,
+		InvQueued:      int(atomic.LoadInt32(&p.invQueued)),
+		InvAnnounced:   atomic.LoadUint64(&p.invAnnounced),
 	}
 
 	p.statsMtx.RUnlock()
@@ -1496,6 +1527,16 @@ out:
 				p.cfg.Listeners.OnCFHeaders(p, msg)
 			}
 
+		case *wire.MsgGetUtreexoProof:
+			if p.cfg.Listeners.OnGetUtreexoProof != nil {
+				p.cfg.Listeners.OnGetUtreexoProof(p, msg)
+			}
+
+		case *wire.MsgUtreexoProof:
+			if p.cfg.Listeners.OnUtreexoProof != nil {
+				p.cfg.Listeners.OnUtreexoProof(p, msg)
+			}
+
 		case *wire.MsgFeeFilter:
 			if p.cfg.Listeners.OnFeeFilter != nil {
 				p.cfg.Listeners.OnFeeFilter(p, msg)
@@ -1561,9 +1602,9 @@ out:
 // to outHandler to be actually written.
 func (p *Peer) queueHandler() {
 	pendingMsgs := list.New()
-	invSendQueue := list.New()
-	trickleTicker := time.NewTicker(p.cfg.TrickleInterval)
-	defer trickleTicker.Stop()
+	var invQueue invSendQueue
+	trickleTimer := time.NewTimer(trickleDelay(p.cfg.TrickleInterval))
+	defer trickleTimer.Stop()
 
 	// We keep the waiting flag so that we know if we have a message queued
 	// to the outHandler or not.  We could use the presence of a head of
@@ -1606,56 +1647,61 @@ out:
 			val := pendingMsgs.Remove(next)
 			p.sendQueue <- val.(outMsg)
 
-		case iv := <-p.outputInvChan:
+		case qi := <-p.outputInvChan:
 			// No handshake?  They'll find out soon enough.
 			if p.VersionKnown() {
 				// If this is a new block, then we'll blast it
 				// out immediately, sipping the inv trickle
 				// queue.
-				if iv.Type == wire.InvTypeBlock ||
-					iv.Type == wire.InvTypeWitnessBlock {
+				if qi.iv.Type == wire.InvTypeBlock ||
+					qi.iv.Type == wire.InvTypeWitnessBlock {
 
 					invMsg := wire.NewMsgInvSizeHint(1)
-					invMsg.AddInvVect(iv)
+					invMsg.AddInvVect(qi.iv)
 					waiting = queuePacket(outMsg{msg: invMsg},
 						pendingMsgs, waiting)
 				} else {
-					invSendQueue.PushBack(iv)
+					invQueue.Push(qi)
+					atomic.StoreInt32(&p.invQueued,
+						int32(invQueue.Len()))
 				}
 			}
 
-		case <-trickleTicker.C:
+		case <-trickleTimer.C:
+			trickleTimer.Reset(trickleDelay(p.cfg.TrickleInterval))
+
 			// Don't send anything if we're disconnecting or there
 			// is no queued inventory.
 			// version is known if send queue has any entries.
 			if atomic.LoadInt32(&p.disconnect) != 0 ||
-				invSendQueue.Len() == 0 {
+				invQueue.Len() == 0 {
 				continue
 			}
 
 			// Create and send as many inv messages as needed to
-			// drain the inventory send queue.
-			invMsg := wire.NewMsgInvSizeHint(uint(invSendQueue.Len()))
-			for e := invSendQueue.Front(); e != nil; e = invSendQueue.Front() {
-				iv := invSendQueue.Remove(e).(*wire.InvVect)
-
+			// announce the next batch of the inventory send queue.
+			batch := invQueue.NextBatch(p.cfg.TrickleBatchSize)
+			atomic.StoreInt32(&p.invQueued, int32(invQueue.Len()))
+			invMsg := wire.NewMsgInvSizeHint(uint(len(batch)))
+			for _, qi := range batch {
 				// Don't send inventory that became known after
 				// the initial check.
-				if p.knownInventory.Exists(iv) {
+				if p.knownInventory.Exists(qi.iv) {
 					continue
 				}
 
-				invMsg.AddInvVect(iv)
+				invMsg.AddInvVect(qi.iv)
 				if len(invMsg.InvList) >= maxInvTrickleSize {
 					waiting = queuePacket(
 						outMsg{msg: invMsg},
 						pendingMsgs, waiting)
-					invMsg = wire.NewMsgInvSizeHint(uint(invSendQueue.Len()))
+					invMsg = wire.NewMsgInvSizeHint(uint(len(batch)))
 				}
 
 				// Add the inventory that is being relayed to
 				// the known inventory for the peer.
-				p.AddKnownInventory(iv)
+				p.AddKnownInventory(qi.iv)
+				atomic.AddUint64(&p.invAnnounced, 1)
 			}
 			if len(invMsg.InvList) > 0 {
 				waiting = queuePacket(outMsg{msg: invMsg},
@@ -1839,15 +1885,32 @@ func (p *Peer) QueueMessageWithEncoding(msg wire.Message, doneChan chan<- struct
 	p.outputQueue <- outMsg{msg: msg, encoding: encoding, doneChan: doneChan}
 }
 
-// QueueInventory adds the passed inventory to the inventory send queue which
-// might not be sent right away, rather it is trickled to the peer in batches.
-// Inventory that the peer is already known to have is ignored.
+// QueueInventory adds the passed inventory to the relay lane of the inventory
+// send queue which might not be sent right away, rather it is trickled to the
+// peer in batches.  Inventory that the peer is already known to have is
+// ignored.
 //
 // This function is safe for concurrent access.
 func (p *Peer) QueueInventory(invVect *wire.InvVect) {
+	p.queueInventory(queuedInv{iv: invVect, lane: InvLaneRelay})
+}
+
+// QueueTxInventory adds the passed transaction inventory to the given lane of
+// the inventory send queue in the same way as QueueInventory.  The queued
+// transactions of a lane are trickled to the peer by descending fee rate,
+// feePerKB being the fee rate of the transaction in satoshi per kilobyte.
+//
+// This function is safe for concurrent access.
+func (p *Peer) QueueTxInventory(invVect *wire.InvVect, feePerKB int64, lane InvLane) {
+	p.queueInventory(queuedInv{iv: invVect, lane: lane, feePerKB: feePerKB})
+}
+
+// queueInventory adds the passed inventory to the inventory send queue unless
+// the peer is already known to have it.
+func (p *Peer) queueInventory(qi queuedInv) {
 	// Don't add the inventory to the send queue if the peer is already
 	// known to have it.
-	if p.knownInventory.Exists(invVect) {
+	if p.knownInventory.Exists(qi.iv) {
 		return
 	}
 
@@ -1858,7 +1921,7 @@ func (p *Peer) QueueInventory(invVect *wire.InvVect) {
 		return
 	}
 
-	p.outputInvChan <- invVect
+	p.outputInvChan <- qi
 }
 
 // Connected returns whether or not the peer is currently connected.
@@ -2177,6 +2240,11 @@ func newPeerBase(origCfg *Config, inbound bool) *Peer {
 		cfg.TrickleInterval = DefaultTrickleInterval
 	}
 
+	// Set the trickle batch size if a non-positive value is specified.
+	if cfg.TrickleBatchSize <= 0 {
+		cfg.TrickleBatchSize = DefaultTrickleBatchSize
+	}
+
 	p := Peer{
 		inbound:         inbound,
 		wireEncoding:    wire.BaseEncoding,
@@ -2185,7 +2253,7 @@ func newPeerBase(origCfg *Config, inbound bool) *Peer {
 		outputQueue:     make(chan outMsg, outputBufferSize),
 		sendQueue:       make(chan outMsg, 1),   // nonblocking sync
 		sendDoneQueue:   make(chan struct{}, 1), // nonblocking sync
-		outputInvChan:   make(chan *wire.InvVect, outputBufferSize),
+		outputInvChan:   make(chan queuedInv, outputBufferSize),
 		inQuit:          make(chan struct{}),
 		queueQuit:       make(chan struct{}),
 		outQuit:         make(chan struct{}),
@@ -417,6 +417,12 @@ func TestPeerListeners(t *testing.T) {
 			OnCFHeaders: func(p *peer.Peer, msg *wire.MsgCFHeaders) {
 				ok <- msg
 			},
+			OnGetUtreexoProof: func(p *peer.Peer, msg *wire.MsgGetUtreexoProof) {
+				ok <- msg
+			},
+			OnUtreexoProof: func(p *peer.Peer, msg *wire.MsgUtreexoProof) {
+				ok <- msg
+			},
 			OnFeeFilter: func(p *peer.Peer, msg *wire.MsgFeeFilter) {
 				ok <- msg
 			},
@@ -563,6 +569,14 @@ func TestPeerListeners(t *testing.T) {
 			"OnCFHeaders",
 			wire.NewMsgCFHeaders(),
 		},
+		{
+			"OnGetUtreexoProof",
+			wire.NewMsgGetUtreexoProof(),
+		},
+		{
+			"OnUtreexoProof",
+			wire.NewMsgUtreexoProof(&chainhash.Hash{}, 0, nil),
+		},
 		{
 			"OnFeeFilter",
 			wire.NewMsgFeeFilter(15000),
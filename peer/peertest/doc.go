@@ -0,0 +1,58 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package peertest provides a scriptable fake peer for testing implementations
+of the PKT peer-to-peer protocol.
+
+Overview
+
+A FakePeer speaks the raw wire protocol over any net.Conn.  Unlike the peer
+package, it performs no protocol handling of its own, which makes it possible
+to drive the remote side through arbitrary sequences, including ones a well
+behaved peer would never produce:
+
+ - Send well formed wire messages or arbitrary byte sequences, for instance a
+   message header with a bad checksum built with Frame
+ - Send data in small chunks with delays in between to exercise partial reads
+ - Stall for a period of time to exercise timeouts and stall detection
+ - Assert the next message received, that no message is received within a
+   period of time, or that the remote peer disconnects
+
+The fake peer is usable from the pktd tests as well as from external tools
+validating other implementations of the protocol, since it only requires a
+connection or an address to dial.
+
+Scripts
+
+Interactions can be written as a sequence of steps which are run in order,
+stopping at the first failing step:
+
+	p, err := peertest.Dial("127.0.0.1:64764", &peertest.Config{
+		Net:    wire.PktMainNet,
+		Ignore: []string{wire.CmdPing, wire.CmdGetAddr},
+	})
+	if err != nil {
+		return err
+	}
+	defer p.Close()
+
+	// A ping with a corrupted checksum.
+	badChecksum := peertest.Frame(wire.PktMainNet, wire.CmdPing, make([]byte, 8))
+	badChecksum[20] ^= 0xff
+
+	err = p.Run(
+		peertest.Handshake(nil),
+		peertest.Send(wire.NewMsgPing(42)),
+		peertest.Expect(wire.CmdPong, nil),
+		peertest.SendBytes(badChecksum),
+		peertest.Expect(wire.CmdReject, nil),
+		peertest.ExpectDisconnect(5*time.Second),
+	)
+
+Every message received while a step waits for a message is checked, except
+for the commands listed in the Ignore field of the configuration, which are
+silently skipped.
+*/
+package peertest
@@ -0,0 +1,427 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package peertest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+const (
+	// DefaultTimeout is the time to wait for an expected message when no
+	// timeout is configured.
+	DefaultTimeout = 5 * time.Second
+
+	// readQueueSize is the number of received messages buffered before the
+	// fake peer stops reading from the connection.
+	readQueueSize = 100
+)
+
+// Config is the configuration of a fake peer.
+type Config struct {
+	// Net is the network magic used when framing and reading messages.
+	Net wire.BitcoinNet
+
+	// ProtocolVersion is the protocol version used to encode and decode
+	// messages.  It defaults to wire.ProtocolVersion.
+	ProtocolVersion uint32
+
+	// Timeout is the time to wait for an expected message.  It defaults to
+	// DefaultTimeout.
+	Timeout time.Duration
+
+	// Ignore lists the commands which are skipped while waiting for a
+	// message, such as pings sent by the remote peer at any time.
+	Ignore []string
+}
+
+// readResult is a message read from the connection, or the error which ended
+// reading.
+type readResult struct {
+	msg wire.Message
+	err error
+}
+
+// FakePeer is a scriptable peer speaking the raw wire protocol over a
+// connection.  It does not answer any message on its own.
+//
+// Messages are read from the connection in the background so that waiting for
+// a message with a timeout never leaves a partially read message behind.
+type FakePeer struct {
+	conn   net.Conn
+	cfg    Config
+	ignore map[string]struct{}
+	msgs   chan readResult
+	quit   chan struct{}
+	once   sync.Once
+	closed error
+}
+
+// New returns a fake peer speaking over the passed connection.  The fake peer
+// takes ownership of the connection, which is closed by Close.
+func New(conn net.Conn, cfg *Config) *FakePeer {
+	p := &FakePeer{
+		conn:   conn,
+		cfg:    *cfg,
+		ignore: make(map[string]struct{}, len(cfg.Ignore)),
+		msgs:   make(chan readResult, readQueueSize),
+		quit:   make(chan struct{}),
+	}
+	if p.cfg.ProtocolVersion == 0 {
+		p.cfg.ProtocolVersion = wire.ProtocolVersion
+	}
+	if p.cfg.Timeout == 0 {
+		p.cfg.Timeout = DefaultTimeout
+	}
+	for _, command := range cfg.Ignore {
+		p.ignore[command] = struct{}{}
+	}
+	go p.readHandler()
+	return p
+}
+
+// Dial connects to the peer at the passed address and returns a fake peer
+// speaking over the connection.
+func Dial(addr string, cfg *Config) (*FakePeer, error) {
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return New(conn, cfg), nil
+}
+
+// readHandler reads messages from the connection until it fails or the fake
+// peer is closed.  It must be run as a goroutine.
+func (p *FakePeer) readHandler() {
+	defer close(p.msgs)
+	for {
+		_, msg, _, err := wire.ReadMessageWithEncodingN(p.conn,
+			p.cfg.ProtocolVersion, p.cfg.Net, wire.LatestEncoding)
+		select {
+		case p.msgs <- readResult{msg: msg, err: err}:
+		case <-p.quit:
+			return
+		}
+
+		// Messages with an unknown command or a malformed payload are
+		// reported without ending the stream since their payload has
+		// been consumed.
+		if _, ok := err.(*wire.MessageError); err != nil && !ok {
+			return
+		}
+	}
+}
+
+// Conn returns the connection of the fake peer.
+func (p *FakePeer) Conn() net.Conn {
+	return p.conn
+}
+
+// Close closes the connection of the fake peer.
+func (p *FakePeer) Close() error {
+	p.once.Do(func() { close(p.quit) })
+	return p.conn.Close()
+}
+
+// SendBytes writes the raw bytes to the connection.
+func (p *FakePeer) SendBytes(b []byte) error {
+	_, err := p.conn.Write(b)
+	return err
+}
+
+// SendPartial writes the raw bytes to the connection in chunks of the passed
+// size, waiting for delay between each chunk.
+func (p *FakePeer) SendPartial(b []byte, chunk int, delay time.Duration) error {
+	if chunk <= 0 {
+		return errors.New("chunk size must be positive")
+	}
+	for len(b) > 0 {
+		n := chunk
+		if n > len(b) {
+			n = len(b)
+		}
+		if err := p.SendBytes(b[:n]); err != nil {
+			return err
+		}
+		b = b[n:]
+		if len(b) > 0 {
+			time.Sleep(delay)
+		}
+	}
+	return nil
+}
+
+// SendMessage writes the message to the connection.
+func (p *FakePeer) SendMessage(msg wire.Message) error {
+	b, err := EncodeMessage(p.cfg.Net, p.cfg.ProtocolVersion, msg)
+	if err != nil {
+		return err
+	}
+	return p.SendBytes(b)
+}
+
+// next returns the next message which is not ignored, waiting up to timeout
+// for it.  A nil message and error are returned on timeout.
+func (p *FakePeer) next(timeout time.Duration) (wire.Message, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		select {
+		case r, ok := <-p.msgs:
+			if !ok {
+				if p.closed == nil {
+					p.closed = errors.New("fake peer closed")
+				}
+				return nil, p.closed
+			}
+			if r.err != nil {
+				if _, ok := r.err.(*wire.MessageError); !ok {
+					p.closed = r.err
+				}
+				return nil, r.err
+			}
+			if _, ok := p.ignore[r.msg.Command()]; ok {
+				continue
+			}
+			return r.msg, nil
+		case <-timer.C:
+			return nil, nil
+		}
+	}
+}
+
+// ReadMessage returns the next message received which is not ignored.
+func (p *FakePeer) ReadMessage() (wire.Message, error) {
+	msg, err := p.next(p.cfg.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	if msg == nil {
+		return nil, fmt.Errorf("no message received within %v",
+			p.cfg.Timeout)
+	}
+	return msg, nil
+}
+
+// ExpectMessage returns the next message received which is not ignored and
+// fails when it does not have the passed command.
+func (p *FakePeer) ExpectMessage(command string) (wire.Message, error) {
+	msg, err := p.ReadMessage()
+	if err != nil {
+		return nil, fmt.Errorf("expected %s: %v", command, err)
+	}
+	if msg.Command() != command {
+		return nil, fmt.Errorf("expected %s, received %s", command,
+			msg.Command())
+	}
+	return msg, nil
+}
+
+// ExpectNoMessage fails when a message which is not ignored is received
+// within the passed duration.
+func (p *FakePeer) ExpectNoMessage(d time.Duration) error {
+	msg, err := p.next(d)
+	if err != nil {
+		return err
+	}
+	if msg != nil {
+		return fmt.Errorf("unexpected %s received", msg.Command())
+	}
+	return nil
+}
+
+// ExpectDisconnect waits up to the passed duration for the remote peer to
+// close the connection.  Messages received before the connection is closed
+// are skipped.
+func (p *FakePeer) ExpectDisconnect(d time.Duration) error {
+	deadline := time.Now().Add(d)
+	for {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return fmt.Errorf("still connected after %v", d)
+		}
+		msg, err := p.next(remaining)
+		if err != nil {
+			if _, ok := err.(*wire.MessageError); ok {
+				continue
+			}
+			return nil
+		}
+		if msg == nil {
+			return fmt.Errorf("still connected after %v", d)
+		}
+	}
+}
+
+// Handshake sends the passed version message, or a default one when it is
+// nil, then waits for the version and verack messages of the remote peer and
+// acknowledges its version.  It returns the version message of the remote
+// peer.
+//
+// Since the version is sent right away, the handshake works the same whether
+// the remote peer is expected to send its version first or not.
+func (p *FakePeer) Handshake(version *wire.MsgVersion) (*wire.MsgVersion, error) {
+	if version == nil {
+		version = wire.NewMsgVersion(&wire.NetAddress{},
+			&wire.NetAddress{}, uint64(rand.Int63()), 0)
+		version.ProtocolVersion = int32(p.cfg.ProtocolVersion)
+	}
+	if err := p.SendMessage(version); err != nil {
+		return nil, err
+	}
+
+	var remote *wire.MsgVersion
+	var verAck bool
+	for remote == nil || !verAck {
+		msg, err := p.ReadMessage()
+		if err != nil {
+			return nil, err
+		}
+		switch msg := msg.(type) {
+		case *wire.MsgVersion:
+			if remote != nil {
+				return nil, errors.New("duplicate version received")
+			}
+			remote = msg
+			if err := p.SendMessage(wire.NewMsgVerAck()); err != nil {
+				return nil, err
+			}
+		case *wire.MsgVerAck:
+			if verAck {
+				return nil, errors.New("duplicate verack received")
+			}
+			verAck = true
+		default:
+			return nil, fmt.Errorf("unexpected %s received during "+
+				"handshake", msg.Command())
+		}
+	}
+	return remote, nil
+}
+
+// Step is a single step of a script run by a fake peer.
+type Step struct {
+	// Name describes the step in errors.
+	Name string
+
+	// Do performs the step.
+	Do func(p *FakePeer) error
+}
+
+// Run runs the steps in order and returns the error of the first failing
+// step.
+func (p *FakePeer) Run(steps ...Step) error {
+	for i, step := range steps {
+		if err := step.Do(p); err != nil {
+			return fmt.Errorf("step %d (%s): %v", i, step.Name, err)
+		}
+	}
+	return nil
+}
+
+// Handshake returns a step performing the version handshake.  See
+// FakePeer.Handshake.
+func Handshake(version *wire.MsgVersion) Step {
+	return Step{"handshake", func(p *FakePeer) error {
+		_, err := p.Handshake(version)
+		return err
+	}}
+}
+
+// Send returns a step sending the message.
+func Send(msg wire.Message) Step {
+	return Step{"send " + msg.Command(), func(p *FakePeer) error {
+		return p.SendMessage(msg)
+	}}
+}
+
+// SendBytes returns a step sending the raw bytes.
+func SendBytes(b []byte) Step {
+	return Step{fmt.Sprintf("send %d bytes", len(b)), func(p *FakePeer) error {
+		return p.SendBytes(b)
+	}}
+}
+
+// SendPartial returns a step sending the raw bytes in chunks.  See
+// FakePeer.SendPartial.
+func SendPartial(b []byte, chunk int, delay time.Duration) Step {
+	name := fmt.Sprintf("send %d bytes in chunks of %d", len(b), chunk)
+	return Step{name, func(p *FakePeer) error {
+		return p.SendPartial(b, chunk, delay)
+	}}
+}
+
+// Stall returns a step which does nothing for the passed duration.  Received
+// messages are buffered meanwhile.
+func Stall(d time.Duration) Step {
+	return Step{fmt.Sprintf("stall %v", d), func(p *FakePeer) error {
+		time.Sleep(d)
+		return nil
+	}}
+}
+
+// Expect returns a step expecting a message with the passed command.  When
+// check is not nil, it is called with the message and its error fails the
+// step.
+func Expect(command string, check func(wire.Message) error) Step {
+	return Step{"expect " + command, func(p *FakePeer) error {
+		msg, err := p.ExpectMessage(command)
+		if err != nil || check == nil {
+			return err
+		}
+		return check(msg)
+	}}
+}
+
+// ExpectNone returns a step expecting no message for the passed duration.
+func ExpectNone(d time.Duration) Step {
+	return Step{fmt.Sprintf("expect no message for %v", d), func(p *FakePeer) error {
+		return p.ExpectNoMessage(d)
+	}}
+}
+
+// ExpectDisconnect returns a step expecting the remote peer to disconnect
+// within the passed duration.
+func ExpectDisconnect(d time.Duration) Step {
+	return Step{"expect disconnect", func(p *FakePeer) error {
+		return p.ExpectDisconnect(d)
+	}}
+}
+
+// EncodeMessage returns the message framed for the passed network.
+func EncodeMessage(btcnet wire.BitcoinNet, pver uint32, msg wire.Message) ([]byte, error) {
+	var buf bytes.Buffer
+	_, err := wire.WriteMessageWithEncodingN(&buf, msg, pver, btcnet,
+		wire.LatestEncoding)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Frame returns the passed command and payload framed for the passed network
+// with a valid header, without checking that the payload is valid for the
+// command.  The result can be altered to produce malformed headers.
+func Frame(btcnet wire.BitcoinNet, command string, payload []byte) []byte {
+	b := make([]byte, wire.MessageHeaderSize, wire.MessageHeaderSize+len(payload))
+	binary.LittleEndian.PutUint32(b[0:4], uint32(btcnet))
+	copy(b[4:4+wire.CommandSize], command)
+	binary.LittleEndian.PutUint32(b[16:20], uint32(len(payload)))
+	copy(b[20:24], chainhash.DoubleHashB(payload)[0:4])
+	return append(b, payload...)
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package peertest_test
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/peer"
+	"github.com/pkt-cash/pktd/peer/peertest"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// TestFrame ensures framed payloads match the encoding of the wire package.
+func TestFrame(t *testing.T) {
+	btcnet := chaincfg.MainNetParams.Net
+	msg := wire.NewMsgPing(0x0102030405060708)
+	want, err := peertest.EncodeMessage(btcnet, wire.ProtocolVersion, msg)
+	if err != nil {
+		t.Fatalf("EncodeMessage: %v", err)
+	}
+	var payload bytes.Buffer
+	if err := msg.BtcEncode(&payload, wire.ProtocolVersion, wire.LatestEncoding); err != nil {
+		t.Fatalf("BtcEncode: %v", err)
+	}
+	got := peertest.Frame(btcnet, wire.CmdPing, payload.Bytes())
+	if !bytes.Equal(got, want) {
+		t.Fatalf("unexpected frame: got %x, want %x", got, want)
+	}
+}
+
+// TestFakePeer runs a script against an inbound peer.
+func TestFakePeer(t *testing.T) {
+	params := &chaincfg.MainNetParams
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	defer listener.Close()
+
+	remote := make(chan *peer.Peer, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		p := peer.NewInboundPeer(&peer.Config{
+			UserAgentName:    "peertest",
+			UserAgentVersion: "1.0",
+			ChainParams:      params,
+		})
+		p.AssociateConnection(conn)
+		remote <- p
+	}()
+
+	fp, err := peertest.Dial(listener.Addr().String(), &peertest.Config{
+		Net:    params.Net,
+		Ignore: []string{wire.CmdPing},
+	})
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer fp.Close()
+
+	version, err := fp.Handshake(nil)
+	if err != nil {
+		t.Fatalf("Handshake: %v", err)
+	}
+	if !bytes.Contains([]byte(version.UserAgent), []byte("/peertest:1.0/")) {
+		t.Fatalf("unexpected user agent %q", version.UserAgent)
+	}
+
+	ping, err := peertest.EncodeMessage(params.Net, wire.ProtocolVersion,
+		wire.NewMsgPing(42))
+	if err != nil {
+		t.Fatalf("EncodeMessage: %v", err)
+	}
+	badPing := append([]byte(nil), ping...)
+	badPing[20] ^= 0xff
+	err = fp.Run(
+		// A ping received in small chunks is still answered.
+		peertest.SendPartial(ping, 5, 10*time.Millisecond),
+		peertest.Expect(wire.CmdPong, func(msg wire.Message) error {
+			if msg.(*wire.MsgPong).Nonce != 42 {
+				return errors.New("unexpected nonce")
+			}
+			return nil
+		}),
+		peertest.Stall(50*time.Millisecond),
+		peertest.ExpectNone(100*time.Millisecond),
+
+		// A message with a bad checksum is malformed and gets the fake
+		// peer disconnected.
+		peertest.SendBytes(badPing),
+		peertest.Expect(wire.CmdReject, nil),
+		peertest.ExpectDisconnect(5*time.Second),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := <-remote
+	p.WaitForDisconnect()
+
+	// Steps after the disconnect fail with the step which failed.
+	err = fp.Run(peertest.Expect(wire.CmdPong, nil))
+	if err == nil {
+		t.Fatal("expected error after disconnect")
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"sort"
+	"time"
+)
+
+const (
+	// evictProtectNetGroups is the number of inbound peers from distinct
+	// network groups which are protected from eviction, chosen by a keyed
+	// hash of the group so an attacker can not predict which groups are
+	// protected.
+	evictProtectNetGroups = 4
+
+	// evictProtectPing is the number of inbound peers with the lowest
+	// latency which are protected from eviction.
+	evictProtectPing = 8
+
+	// evictProtectTxs is the number of inbound peers which most recently
+	// relayed a new transaction which are protected from eviction.
+	evictProtectTxs = 4
+
+	// evictProtectBlocks is the number of inbound peers which most recently
+	// relayed a new block which are protected from eviction.
+	evictProtectBlocks = 4
+)
+
+// evictionCandidate describes an inbound peer which may be evicted to make
+// room for a new inbound peer.
+type evictionCandidate struct {
+	sp            *serverPeer
+	connected     time.Time
+	pingMicros    int64 // Zero when no ping has completed yet.
+	lastTx        int64 // Unix time of the last new transaction relayed.
+	lastBlock     int64 // Unix time of the last new block relayed.
+	netGroup      string
+	keyedNetGroup uint64
+}
+
+// keyedNetGroup returns the hash of the network group keyed with the passed
+// secret, which is used to order peers by network group in a way which can
+// not be predicted by remote peers.
+func keyedNetGroup(key []byte, netGroup string) uint64 {
+	h := sha256.New()
+	h.Write(key)
+	h.Write([]byte(netGroup))
+	return binary.LittleEndian.Uint64(h.Sum(nil))
+}
+
+// protectCandidates removes up to count candidates from the end of the list
+// after sorting it with the passed less function, which orders the
+// candidates most deserving of protection last.
+func protectCandidates(candidates []*evictionCandidate, count int,
+	less func(a, b *evictionCandidate) bool) []*evictionCandidate {
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return less(candidates[i], candidates[j])
+	})
+	if count > len(candidates) {
+		count = len(candidates)
+	}
+	return candidates[:len(candidates)-count]
+}
+
+// selectEvictionCandidate returns the inbound peer to evict in order to make
+// room for a new inbound peer, or nil when every candidate is protected.
+//
+// Peers are protected from eviction when they come from a diverse set of
+// network groups, have the lowest latency, most recently relayed new
+// transactions or blocks, or have been connected the longest, since an
+// attacker can not cheaply get an advantage on all of these at once.  The
+// youngest peer of the network group with the most remaining peers is then
+// evicted, so an attacker connecting many peers from a few network groups
+// evicts its own peers first.
+func selectEvictionCandidate(candidates []*evictionCandidate) *evictionCandidate {
+	candidates = append([]*evictionCandidate(nil), candidates...)
+
+	candidates = protectCandidates(candidates, evictProtectNetGroups,
+		func(a, b *evictionCandidate) bool {
+			return a.keyedNetGroup < b.keyedNetGroup
+		})
+	candidates = protectCandidates(candidates, evictProtectPing,
+		func(a, b *evictionCandidate) bool {
+			// Peers which have not answered a ping yet are the
+			// least deserving of protection.
+			if a.pingMicros == 0 || b.pingMicros == 0 {
+				return a.pingMicros == 0 && b.pingMicros != 0
+			}
+			return a.pingMicros > b.pingMicros
+		})
+	candidates = protectCandidates(candidates, evictProtectTxs,
+		func(a, b *evictionCandidate) bool {
+			return a.lastTx < b.lastTx
+		})
+	candidates = protectCandidates(candidates, evictProtectBlocks,
+		func(a, b *evictionCandidate) bool {
+			return a.lastBlock < b.lastBlock
+		})
+	candidates = protectCandidates(candidates, len(candidates)/2,
+		func(a, b *evictionCandidate) bool {
+			return a.connected.After(b.connected)
+		})
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	// Group the remaining candidates by network group, keeping track of
+	// the youngest peer of each group.
+	groups := make(map[string][]*evictionCandidate)
+	youngest := make(map[string]*evictionCandidate)
+	for _, c := range candidates {
+		groups[c.netGroup] = append(groups[c.netGroup], c)
+		if y, ok := youngest[c.netGroup]; !ok || c.connected.After(y.connected) {
+			youngest[c.netGroup] = c
+		}
+	}
+
+	// Evict the youngest peer of the group with the most peers, breaking
+	// ties in favor of the group with the youngest peer.
+	var evict *evictionCandidate
+	var evictGroupSize int
+	for netGroup, group := range groups {
+		y := youngest[netGroup]
+		if len(group) > evictGroupSize || (len(group) == evictGroupSize &&
+			y.connected.After(evict.connected)) {
+
+			evict = y
+			evictGroupSize = len(group)
+		}
+	}
+	return evict
+}
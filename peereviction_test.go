@@ -0,0 +1,76 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// TestSelectEvictionCandidate ensures inbound peers are protected by the
+// eviction heuristics and the youngest peer of the largest network group is
+// evicted.
+func TestSelectEvictionCandidate(t *testing.T) {
+	key := []byte("eviction test key")
+	now := time.Unix(1600000000, 0)
+	newCandidate := func(netGroup string, age time.Duration) *evictionCandidate {
+		return &evictionCandidate{
+			connected:     now.Add(-age),
+			netGroup:      netGroup,
+			keyedNetGroup: keyedNetGroup(key, netGroup),
+		}
+	}
+
+	// Too few candidates to evict any of them.
+	var candidates []*evictionCandidate
+	for i := 0; i < evictProtectNetGroups+evictProtectPing+
+		evictProtectTxs+evictProtectBlocks; i++ {
+
+		c := newCandidate(fmt.Sprintf("10.%d", i), time.Duration(i)*time.Minute)
+		candidates = append(candidates, c)
+	}
+	if evict := selectEvictionCandidate(candidates); evict != nil {
+		t.Fatalf("evicted %s while all candidates are protected",
+			evict.netGroup)
+	}
+
+	// Honest peers with a low latency which relayed transactions and
+	// blocks are kept, while an attacker connecting many peers from a
+	// single network group loses its youngest one.
+	candidates = candidates[:0]
+	for i := 0; i < 20; i++ {
+		c := newCandidate(fmt.Sprintf("10.%d", i), time.Hour)
+		c.pingMicros = int64(1000 + i)
+		c.lastTx = now.Unix() - int64(i)
+		c.lastBlock = now.Unix() - int64(i)
+		candidates = append(candidates, c)
+	}
+	var youngest *evictionCandidate
+	for i := 0; i < 30; i++ {
+		c := newCandidate("192.168", time.Duration(i+1)*time.Second)
+		if i == 0 {
+			youngest = c
+		}
+		candidates = append(candidates, c)
+	}
+	evict := selectEvictionCandidate(candidates)
+	if evict != youngest {
+		t.Fatalf("unexpected eviction candidate %+v", evict)
+	}
+
+	// The candidates passed in are not reordered.
+	if candidates[len(candidates)-30] != youngest {
+		t.Fatal("candidates were modified")
+	}
+
+	// The keyed network group depends on both the key and the group.
+	if keyedNetGroup(key, "a") == keyedNetGroup(key, "b") {
+		t.Fatal("keyed network groups collide")
+	}
+	if keyedNetGroup(key, "a") == keyedNetGroup([]byte("other key"), "a") {
+		t.Fatal("keyed network group does not depend on the key")
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/pkt-cash/pktd/netsync"
+)
+
+// peerPermissions is a set of elevated behaviors granted to a peer, either by
+// the whitelisted network its address is in or by the whitebind listener it
+// connected to.
+type peerPermissions uint32
+
+const (
+	// permNoBan keeps the peer from being banned, disconnected for its ban
+	// score or evicted to make room for other inbound peers.
+	permNoBan peerPermissions = 1 << iota
+
+	// permRelay accepts the non-standard transactions relayed by the peer
+	// without rate limiting them.
+	permRelay
+
+	// permForceRelay relays the transactions of the peer even when they
+	// were rejected before or are already in the memory pool.  It implies
+	// permRelay.
+	permForceRelay
+
+	// permAddr answers every getaddr request of the peer, including the
+	// repeated ones and those of outbound peers.
+	permAddr
+
+	// permDownload serves mempool requests of the peer without bloom
+	// filtering and large getdata requests without raising its ban score.
+	permDownload
+
+	// permAll is the set of all the permissions.
+	permAll = permNoBan | permRelay | permForceRelay | permAddr | permDownload
+)
+
+// defaultWhitelistPermissions are the permissions of the whitelisted networks
+// and whitebind listeners which don't name any.
+const defaultWhitelistPermissions = permNoBan
+
+// peerPermissionNames maps the names of the permissions used in the
+// configuration and by the RPC server to their flags.
+var peerPermissionNames = []struct {
+	name string
+	perm peerPermissions
+}{
+	{"noban", permNoBan},
+	{"relay", permRelay},
+	{"forcerelay", permForceRelay},
+	{"addr", permAddr},
+	{"download", permDownload},
+}
+
+// names returns the names of the permissions in the set.
+func (p peerPermissions) names() []string {
+	var names []string
+	for _, n := range peerPermissionNames {
+		if p&n.perm != 0 {
+			names = append(names, n.name)
+		}
+	}
+	return names
+}
+
+// String returns the permissions in the set as a comma separated list.
+func (p peerPermissions) String() string {
+	return strings.Join(p.names(), ",")
+}
+
+// txRelayFlags returns the flags the transactions of a peer with the
+// permissions in the set are handled with by the sync manager.
+func (p peerPermissions) txRelayFlags() netsync.TxRelayFlags {
+	var flags netsync.TxRelayFlags
+	if p != 0 {
+		flags |= netsync.TxRelayWhitelisted
+	}
+	if p&(permRelay|permForceRelay) != 0 {
+		flags |= netsync.TxRelayNonStd
+	}
+	if p&permForceRelay != 0 {
+		flags |= netsync.TxRelayForce
+	}
+	return flags
+}
+
+// parsePeerPermissions splits the permissions off a whitelist or whitebind
+// value of the form [perm,...@]value.  The passed default permissions are
+// returned when the value names none.
+func parsePeerPermissions(s string, defaults peerPermissions) (peerPermissions, string, error) {
+	i := strings.LastIndex(s, "@")
+	if i < 0 {
+		return defaults, s, nil
+	}
+	var perms peerPermissions
+	for _, name := range strings.Split(s[:i], ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "all" {
+			perms |= permAll
+			continue
+		}
+		found := false
+		for _, n := range peerPermissionNames {
+			if n.name == name {
+				perms |= n.perm
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, "", fmt.Errorf("unknown peer permission '%s'",
+				name)
+		}
+	}
+	return perms, s[i+1:], nil
+}
+
+// whitelistEntry is a whitelisted network and the permissions of the peers
+// whose address is in it.
+type whitelistEntry struct {
+	ipnet *net.IPNet
+	perms peerPermissions
+}
+
+// parseWhitelist parses a whitelist value of the form [perm,...@]IP or
+// [perm,...@]IP/CIDR.
+func parseWhitelist(s string) (*whitelistEntry, error) {
+	perms, addr, err := parsePeerPermissions(s, defaultWhitelistPermissions)
+	if err != nil {
+		return nil, err
+	}
+	_, ipnet, err := net.ParseCIDR(addr)
+	if err != nil {
+		ip := net.ParseIP(addr)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IP address or network "+
+				"'%s'", addr)
+		}
+		var bits int
+		if ip.To4() == nil {
+			// IPv6
+			bits = 128
+		} else {
+			bits = 32
+		}
+		ipnet = &net.IPNet{
+			IP:   ip,
+			Mask: net.CIDRMask(bits, bits),
+		}
+	}
+	return &whitelistEntry{ipnet: ipnet, perms: perms}, nil
+}
+
+// whitelistPermissions returns the permissions the whitelisted networks which
+// include the IP address grant.
+func whitelistPermissions(addr net.Addr) peerPermissions {
+	if len(cfg.whitelists) == 0 {
+		return 0
+	}
+
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		srvrLog.Warnf("Unable to SplitHostPort on '%s': %v", addr, err)
+		return 0
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		srvrLog.Warnf("Unable to parse IP '%s'", addr)
+		return 0
+	}
+
+	var perms peerPermissions
+	for _, entry := range cfg.whitelists {
+		if entry.ipnet.Contains(ip) {
+			perms |= entry.perms
+		}
+	}
+	return perms
+}
+
+// whitebind is a whitebind listen address and the permissions of the peers
+// connecting to it.
+type whitebind struct {
+	listenAddr string
+	perms      peerPermissions
+}
+
+// permissionedConn is a connection accepted by a whitebind listener along with
+// the permissions the listener grants.
+type permissionedConn struct {
+	net.Conn
+	perms peerPermissions
+}
+
+// connPermissions returns the permissions of the peer of the passed inbound
+// connection.
+func connPermissions(conn net.Conn) peerPermissions {
+	perms := whitelistPermissions(conn.RemoteAddr())
+	if pc, ok := conn.(*permissionedConn); ok {
+		perms |= pc.perms
+	}
+	return perms
+}
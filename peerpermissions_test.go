@@ -0,0 +1,132 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net"
+	"testing"
+
+	"github.com/pkt-cash/pktd/netsync"
+)
+
+// TestParsePeerPermissions ensures the permissions are split off whitelist
+// values and the whitelisted networks parsed.
+func TestParsePeerPermissions(t *testing.T) {
+	tests := []struct {
+		in      string
+		perms   peerPermissions
+		network string
+		err     bool
+	}{
+		{in: "127.0.0.1", perms: permNoBan, network: "127.0.0.1/32"},
+		{in: "::1", perms: permNoBan, network: "::1/128"},
+		{in: "192.168.0.0/24", perms: permNoBan, network: "192.168.0.0/24"},
+		{in: "relay@10.0.0.0/8", perms: permRelay, network: "10.0.0.0/8"},
+		{in: "noban, Addr,download@fd00::/16",
+			perms: permNoBan | permAddr | permDownload, network: "fd00::/16"},
+		{in: "all@10.1.2.3", perms: permAll, network: "10.1.2.3/32"},
+		{in: "@10.1.2.3", err: true},
+		{in: "bogus@10.1.2.3", err: true},
+		{in: "relay@notanip", err: true},
+	}
+	for _, test := range tests {
+		entry, err := parseWhitelist(test.in)
+		if test.err {
+			if err == nil {
+				t.Errorf("parseWhitelist(%q): no error", test.in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseWhitelist(%q): %v", test.in, err)
+			continue
+		}
+		if entry.perms != test.perms || entry.ipnet.String() != test.network {
+			t.Errorf("parseWhitelist(%q): got %v %v, want %v %v",
+				test.in, entry.perms, entry.ipnet, test.perms,
+				test.network)
+		}
+	}
+
+	perms, addr, err := parsePeerPermissions("eth0:8333", 0)
+	if err != nil || perms != 0 || addr != "eth0:8333" {
+		t.Errorf("parsePeerPermissions: got %v %q (%v)", perms, addr, err)
+	}
+	if got := (permForceRelay | permNoBan).String(); got != "noban,forcerelay" {
+		t.Errorf("String: got %q", got)
+	}
+}
+
+// TestPeerPermissions ensures peers are granted the permissions of the
+// whitelisted networks their address is in and of the whitebind listener they
+// connected to.
+func TestPeerPermissions(t *testing.T) {
+	// The log rotator is not initialized in tests.
+	setLogLevels("off")
+	defer setLogLevels(defaultLogLevel)
+	defer func(c *config) { cfg = c }(cfg)
+	cfg = &config{}
+	for _, s := range []string{"127.0.0.0/8", "relay@127.0.0.1", "addr@::1"} {
+		entry, err := parseWhitelist(s)
+		if err != nil {
+			t.Fatalf("parseWhitelist(%q): %v", s, err)
+		}
+		cfg.whitelists = append(cfg.whitelists, entry)
+	}
+
+	addrs := []struct {
+		addr  string
+		perms peerPermissions
+	}{
+		{"127.0.0.1:8333", permNoBan | permRelay},
+		{"127.0.0.2:8333", permNoBan},
+		{"[::1]:8333", permAddr},
+		{"10.0.0.1:8333", 0},
+	}
+	for _, test := range addrs {
+		addr, err := net.ResolveTCPAddr("tcp", test.addr)
+		if err != nil {
+			t.Fatalf("ResolveTCPAddr: %v", err)
+		}
+		if got := whitelistPermissions(addr); got != test.perms {
+			t.Errorf("whitelistPermissions(%v): got %v, want %v",
+				addr, got, test.perms)
+		}
+	}
+
+	// The connections accepted by a whitebind listener are granted its
+	// permissions on top of those of the whitelist.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	listener := &p2pListener{Listener: l, perms: permForceRelay}
+	defer listener.Close()
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer conn.Close()
+	accepted, err := listener.Accept()
+	if err != nil {
+		t.Fatalf("Accept: %v", err)
+	}
+	defer accepted.Close()
+	perms := connPermissions(accepted)
+	if perms != permNoBan|permRelay|permForceRelay {
+		t.Fatalf("connPermissions: got %v", perms)
+	}
+	if flags := perms.txRelayFlags(); flags != netsync.TxRelayNonStd|
+		netsync.TxRelayForce|netsync.TxRelayWhitelisted {
+
+		t.Fatalf("txRelayFlags: got %v", flags)
+	}
+	if flags := permNoBan.txRelayFlags(); flags != netsync.TxRelayWhitelisted {
+		t.Fatalf("txRelayFlags: got %v", flags)
+	}
+	if flags := peerPermissions(0).txRelayFlags(); flags != 0 {
+		t.Fatalf("txRelayFlags: got %v", flags)
+	}
+}
@@ -0,0 +1,48 @@
+#!/bin/sh
+#
+# Copyright (c) 2020 The pktd developers
+# Use of this source code is governed by an ISC
+# license that can be found in the LICENSE file.
+#
+# Builds pktd and btcctl reproducibly from a clean checkout:
+#   - Disables cgo so the binaries do not depend on the host C toolchain
+#   - Strips the paths of the build machine and the build ID
+#   - Refuses to update go.mod and go.sum during the build
+#   - Embeds the commit and the build flags, reported by getinfo and
+#     getnetworkinfo
+#
+# The go.sum of the commit is the manifest to start pktd with using
+# --buildmanifest so it verifies the consensus-critical modules it runs.
+#
+# Usage: release/build.sh [output-directory]
+
+set -e
+
+cd "$(dirname "$0")/.."
+OUT=${1:-build}
+
+if [ -n "$(git status --porcelain --untracked-files=no)" ]; then
+	echo "$(basename "$0"): error: the working tree has local changes" 1>&2
+	exit 1
+fi
+
+COMMIT=$(git rev-parse HEAD)
+ROOT=$(pwd)
+GOPATH=$(go env GOPATH)
+BUILDFLAGS="cgo=0,mod=readonly,trimpath,buildid="
+
+export CGO_ENABLED=0
+export GO111MODULE=on
+
+build() {
+	go build -mod=readonly -o "$OUT/$1" \
+		-gcflags="all=-trimpath=$ROOT;$GOPATH" \
+		-asmflags="all=-trimpath=$ROOT;$GOPATH" \
+		-ldflags="-buildid= -X main.appCommit=$COMMIT -X main.appBuildFlags=$BUILDFLAGS" \
+		"$2"
+}
+
+mkdir -p "$OUT"
+build pktd .
+build btcctl ./cmd/btcctl
+cp go.sum "$OUT/pktd-go.sum"
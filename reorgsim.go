@@ -0,0 +1,189 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/btcjson"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/mining"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// forkBlockVersion is the version of the blocks of generated forks.  It has the
+// top bits of version bits deployments set, with no deployment signalled, so
+// the blocks are valid regardless of which soft forks are active.
+const forkBlockVersion = 0x20000000
+
+// forkGenerator builds alternative chains from a block of the main chain and
+// submits them.  It is used on test networks to exercise the handling of chain
+// reorganizations by wallets and other clients.
+type forkGenerator struct {
+	chain  *blockchain.BlockChain
+	params *chaincfg.Params
+
+	// submit processes a generated block.  The next block is built on top
+	// of it, so it must be known to the chain once submit returns.
+	submit func(*btcutil.Block) error
+}
+
+// generate builds and submits numBlocks blocks on top of the passed main chain
+// block, paying their subsidy to addr, or to anyone when addr is nil.  The
+// blocks only hold a coinbase transaction and are solved against the required
+// difficulty, which is trivial on the networks allowing CPU mining.  The hashes
+// of the blocks are returned in order.
+func (g *forkGenerator) generate(forkHash *chainhash.Hash, numBlocks uint32,
+	addr btcutil.Address) ([]*chainhash.Hash, error) {
+
+	height, err := g.chain.BlockHeightByHash(forkHash)
+	if err != nil {
+		return nil, err
+	}
+
+	prevHash := *forkHash
+	hashes := make([]*chainhash.Hash, 0, numBlocks)
+	for i := uint32(0); i < numBlocks; i++ {
+		height++
+		block, err := g.newBlock(&prevHash, height, addr)
+		if err != nil {
+			return hashes, err
+		}
+		if err := g.submit(block); err != nil {
+			return hashes, fmt.Errorf("block %v at height %d "+
+				"rejected: %v", block.Hash(), height, err)
+		}
+		hashes = append(hashes, block.Hash())
+		prevHash = *block.Hash()
+	}
+	return hashes, nil
+}
+
+// newBlock returns a solved block at the passed height on top of the block with
+// the passed hash.
+func (g *forkGenerator) newBlock(prevHash *chainhash.Hash, height int32,
+	addr btcutil.Address) (*btcutil.Block, error) {
+
+	// The timestamp must be after the median time of the previous blocks.
+	medianTime, err := g.chain.PastMedianTimeByHash(prevHash)
+	if err != nil {
+		return nil, err
+	}
+	timestamp := time.Unix(time.Now().Unix(), 0)
+	if !timestamp.After(medianTime) {
+		timestamp = medianTime.Add(time.Second)
+	}
+	bits, err := g.chain.CalcNextRequiredDifficultyByHash(prevHash, timestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	// A random extra nonce keeps the coinbase distinct from the one of the
+	// main chain block at the same height.
+	extraNonce, err := wire.RandomUint64()
+	if err != nil {
+		return nil, err
+	}
+	taxScript := g.chain.BestSnapshot().Elect.NetworkSteward
+	coinbaseTx, err := mining.CreateCoinbaseTx(g.params, height, extraNonce,
+		addr, taxScript)
+	if err != nil {
+		return nil, err
+	}
+	merkles := blockchain.BuildMerkleTreeStore([]*btcutil.Tx{coinbaseTx}, false)
+
+	msgBlock := &wire.MsgBlock{
+		Header: wire.BlockHeader{
+			Version:    forkBlockVersion,
+			PrevBlock:  *prevHash,
+			MerkleRoot: *merkles[len(merkles)-1],
+			Timestamp:  timestamp,
+			Bits:       bits,
+		},
+		Transactions: []*wire.MsgTx{coinbaseTx.MsgTx()},
+	}
+	if !solveHeader(&msgBlock.Header) {
+		return nil, fmt.Errorf("unable to solve block at height %d with "+
+			"difficulty bits %08x", height, bits)
+	}
+	block := btcutil.NewBlock(msgBlock)
+	block.SetHeight(height)
+	return block, nil
+}
+
+// solveHeader searches the nonce of the passed header making its hash meet the
+// target difficulty.  It returns false when no nonce does.
+func solveHeader(header *wire.BlockHeader) bool {
+	target := blockchain.CompactToBig(header.Bits)
+	for nonce := uint32(0); ; nonce++ {
+		header.Nonce = nonce
+		hash := header.BlockHash()
+		if blockchain.HashToBig(&hash).Cmp(target) <= 0 {
+			return true
+		}
+		if nonce == math.MaxUint32 {
+			return false
+		}
+	}
+}
+
+// simulateReorg replaces the last depth blocks of the main chain with numBlocks
+// generated blocks, which must be more than depth for the chain to reorganize.
+func (g *forkGenerator) simulateReorg(depth, numBlocks uint32,
+	addr btcutil.Address) (*btcjson.SimulateReorgResult, error) {
+
+	if numBlocks <= depth {
+		return nil, fmt.Errorf("the number of blocks (%d) must exceed "+
+			"the depth (%d) to reorganize the chain", numBlocks, depth)
+	}
+	best := g.chain.BestSnapshot()
+	if int64(depth) > int64(best.Height) {
+		return nil, fmt.Errorf("depth %d exceeds the best chain height "+
+			"%d", depth, best.Height)
+	}
+	forkHeight := best.Height - int32(depth)
+	forkHash, err := g.chain.BlockHashByHeight(forkHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	disconnected := make([]string, 0, depth)
+	for height := forkHeight + 1; height <= best.Height; height++ {
+		hash, err := g.chain.BlockHashByHeight(height)
+		if err != nil {
+			return nil, err
+		}
+		disconnected = append(disconnected, hash.String())
+	}
+
+	hashes, err := g.generate(forkHash, numBlocks, addr)
+	if err != nil {
+		return nil, err
+	}
+	connected := make([]string, 0, len(hashes))
+	for _, hash := range hashes {
+		connected = append(connected, hash.String())
+	}
+
+	newBest := g.chain.BestSnapshot()
+	if newBest.Hash != *hashes[len(hashes)-1] {
+		return nil, fmt.Errorf("the chain did not reorganize to the "+
+			"generated blocks, best block is %v", newBest.Hash)
+	}
+	return &btcjson.SimulateReorgResult{
+		ForkHash:     forkHash.String(),
+		ForkHeight:   forkHeight,
+		OldTip:       best.Hash.String(),
+		NewTip:       newBest.Hash.String(),
+		NewHeight:    newBest.Height,
+		Disconnected: disconnected,
+		Connected:    connected,
+	}, nil
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import "testing"
+
+// TestForkGenerator ensures generated blocks extend the chain from the passed
+// fork point and that simulated reorganizations replace the expected blocks.
+func TestForkGenerator(t *testing.T) {
+	tc, teardown := newTestChain(t, "reorgsim", nil)
+	defer teardown()
+	params, chain, g := tc.params, tc.chain, tc.gen
+
+	// Extend the main chain.
+	hashes, err := g.generate(params.GenesisHash, 3, nil)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	best := chain.BestSnapshot()
+	if best.Height != 3 || best.Hash != *hashes[2] {
+		t.Fatalf("unexpected best block %v at height %d", best.Hash,
+			best.Height)
+	}
+
+	// A shorter fork stays on a side chain.
+	if _, err := g.generate(hashes[0], 1, nil); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if chain.BestSnapshot().Hash != *hashes[2] {
+		t.Fatal("best chain changed by a shorter fork")
+	}
+
+	// Replacing the last two blocks reorganizes the chain.
+	if _, err := g.simulateReorg(2, 2, nil); err == nil {
+		t.Fatal("reorganization with too few blocks accepted")
+	}
+	if _, err := g.simulateReorg(4, 5, nil); err == nil {
+		t.Fatal("reorganization deeper than the chain accepted")
+	}
+	result, err := g.simulateReorg(2, 3, nil)
+	if err != nil {
+		t.Fatalf("simulateReorg: %v", err)
+	}
+	if result.ForkHeight != 1 || result.ForkHash != hashes[0].String() {
+		t.Fatalf("unexpected fork block %s at height %d",
+			result.ForkHash, result.ForkHeight)
+	}
+	if len(result.Disconnected) != 2 ||
+		result.Disconnected[1] != hashes[2].String() {
+		t.Fatalf("unexpected disconnected blocks %v", result.Disconnected)
+	}
+	if result.OldTip != hashes[2].String() || result.NewHeight != 4 ||
+		len(result.Connected) != 3 {
+		t.Fatalf("unexpected reorganization %+v", result)
+	}
+	if chain.MainChainHasBlock(hashes[2]) {
+		t.Fatal("disconnected block still in the main chain")
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/blockchain/indexers"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/globalcfg"
+	"github.com/pkt-cash/pktd/database"
+)
+
+// TestReplicaTxIndex ensures a replica started with the transaction index uses
+// the index of its read-only database, and refuses to start when the index is
+// missing or behind rather than building or catching it up.
+func TestReplicaTxIndex(t *testing.T) {
+	// The log rotator is not initialized in tests.
+	setLogLevels("off")
+	defer setLogLevels(defaultLogLevel)
+
+	defer func(c *config, p *params) { cfg, activeNetParams = c, p }(cfg,
+		activeNetParams)
+	activeNetParams = &regressionNetParams
+
+	params := &chaincfg.RegressionNetParams
+	if !globalcfg.SelectConfig(params.GlobalConf) {
+		t.Fatal("globalcfg.SelectConfig() called twice")
+	}
+	defer globalcfg.RemoveConfig()
+
+	dir, err := ioutil.TempDir("", "pktd-replica")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	cfg = &config{DataDir: dir, DbType: "ffldb", TxIndex: true}
+
+	newChain := func(db database.DB, indexes ...indexers.Indexer) (*blockchain.BlockChain, error) {
+		var indexManager blockchain.IndexManager
+		if len(indexes) > 0 {
+			indexManager = newIndexManager(db, indexes)
+		}
+		return blockchain.New(&blockchain.Config{
+			DB:           db,
+			ChainParams:  params,
+			TimeSource:   blockchain.NewMedianTime(),
+			IndexManager: indexManager,
+			ReadOnly:     cfg.Replica,
+		})
+	}
+
+	// generate mines blocks on the database of the primary, with the passed
+	// indexes enabled.
+	generate := func(numBlocks uint32, indexes func(database.DB) []indexers.Indexer) {
+		cfg.Replica = false
+		db, err := database.Open(cfg.DbType, blockDbPath(cfg.DbType),
+			params.Net)
+		if err != nil {
+			t.Fatalf("database.Open: %v", err)
+		}
+		defer db.Close()
+		chain, err := newChain(db, indexes(db)...)
+		if err != nil {
+			t.Fatalf("blockchain.New: %v", err)
+		}
+		best := chain.BestSnapshot()
+		g := newTestGenerator(chain, params)
+		if _, err := g.generate(&best.Hash, numBlocks, nil); err != nil {
+			t.Fatalf("generate: %v", err)
+		}
+	}
+
+	// startReplica opens the database read-only the way the replica does
+	// and initializes the chain with the passed indexes.
+	startReplica := func(indexes func(database.DB) []indexers.Indexer) (*blockchain.BlockChain, database.DB, error) {
+		cfg.Replica = true
+		db, err := loadBlockDB()
+		if err != nil {
+			t.Fatalf("loadBlockDB: %v", err)
+		}
+		chain, err := newChain(db, indexes(db)...)
+		if err != nil {
+			db.Close()
+			return nil, nil, err
+		}
+		return chain, db, nil
+	}
+	txIndex := func(db database.DB) []indexers.Indexer {
+		return []indexers.Indexer{indexers.NewTxIndex(db)}
+	}
+	noIndex := func(db database.DB) []indexers.Indexer {
+		return nil
+	}
+
+	// The replica serves the transaction index built by the primary.
+	db, err := database.Create(cfg.DbType, blockDbPath(cfg.DbType), params.Net)
+	if err != nil {
+		t.Fatalf("database.Create: %v", err)
+	}
+	db.Close()
+	generate(3, txIndex)
+	chain, db, err := startReplica(txIndex)
+	if err != nil {
+		t.Fatalf("unable to start the replica: %v", err)
+	}
+	best := chain.BestSnapshot()
+	block, err := chain.BlockByHash(&best.Hash)
+	if err != nil {
+		t.Fatalf("BlockByHash: %v", err)
+	}
+	idx := indexers.NewTxIndex(db)
+	region, err := idx.TxBlockRegion(block.Transactions()[0].Hash())
+	if err != nil || region == nil || *region.Hash != best.Hash {
+		t.Errorf("coinbase of the best block not found in the "+
+			"transaction index: %v, %v", region, err)
+	}
+	db.Close()
+
+	// The address index was never built, so it can't be served.
+	_, _, err = startReplica(func(db database.DB) []indexers.Indexer {
+		return []indexers.Indexer{indexers.NewTxIndex(db),
+			indexers.NewAddrIndex(db, params)}
+	})
+	if err == nil || !strings.Contains(err.Error(), "not in the read-only") {
+		t.Errorf("replica with a missing index: got %v, want a "+
+			"missing index error", err)
+	}
+
+	// Nor is a transaction index behind the best block caught up.
+	generate(2, noIndex)
+	_, _, err = startReplica(txIndex)
+	if err == nil || !strings.Contains(err.Error(), "instead of the best") {
+		t.Errorf("replica with an index behind: got %v, want a "+
+			"behind index error", err)
+	}
+}
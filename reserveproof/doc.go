@@ -0,0 +1,19 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package reserveproof creates and verifies proofs that a wallet controls a set
+of unspent outputs, such as the proofs of reserves published by exchanges.
+
+A proof is a transaction, following BIP 127, which can never be mined.  Its
+first input spends a commitment outpoint whose hash is derived from a message
+chosen by the prover, typically naming the exchange and the date, and which
+therefore does not exist.  The remaining inputs spend the outputs being proven
+and are signed with SIGHASH_ALL, so every signature commits to the message.
+The single output pays the total of the proven outputs to an OP_RETURN script.
+
+Verifying a proof only needs the outputs it spends, which are looked up by the
+caller, so the verifier holds no state of its own.
+*/
+package reserveproof
@@ -0,0 +1,120 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package reserveproof
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// MessagePrefix is prepended to the message of a proof before it is hashed
+// into the commitment outpoint, so the outpoint can not be confused with the
+// hash of anything else.
+const MessagePrefix = "Proof-of-Reserves: "
+
+var (
+	// ErrNoInputs is returned when a proof does not spend any outputs
+	// besides the commitment.
+	ErrNoInputs = errors.New("proof does not spend any outputs")
+
+	// ErrBadCommitment is returned when the first input of a proof does
+	// not spend the commitment outpoint of the message.
+	ErrBadCommitment = errors.New("proof does not commit to the message")
+
+	// ErrBadOutput is returned when a proof does not have a single
+	// OP_RETURN output paying the total of its inputs.
+	ErrBadOutput = errors.New("proof must have a single OP_RETURN output " +
+		"paying the total of its inputs")
+
+	// ErrDuplicateInput is returned when a proof spends the same output
+	// more than once.
+	ErrDuplicateInput = errors.New("proof spends an output more than once")
+)
+
+// proofScript is the script of the single output of a proof.
+var proofScript = []byte{txscript.OP_RETURN}
+
+// CommitmentOutPoint returns the outpoint spent by the first input of a proof
+// for the passed message.
+func CommitmentOutPoint(message string) wire.OutPoint {
+	return wire.OutPoint{
+		Hash:  chainhash.HashH([]byte(MessagePrefix + message)),
+		Index: 0,
+	}
+}
+
+// New returns the unsigned proof transaction for the passed message, spending
+// the passed outputs whose amounts add up to total.  Every input but the first
+// must then be signed with SIGHASH_ALL by the owner of the output it spends.
+func New(message string, outPoints []wire.OutPoint, total btcutil.Amount) *wire.MsgTx {
+	tx := wire.NewMsgTx(wire.TxVersion)
+	commitment := CommitmentOutPoint(message)
+	tx.AddTxIn(wire.NewTxIn(&commitment, nil, nil))
+	for i := range outPoints {
+		tx.AddTxIn(wire.NewTxIn(&outPoints[i], nil, nil))
+	}
+	tx.AddTxOut(wire.NewTxOut(int64(total), proofScript))
+	return tx
+}
+
+// FetchFunc returns the output spent by the passed outpoint.  It returns an
+// error when the output does not exist or is not acceptable to the verifier,
+// for instance because it has been spent.
+type FetchFunc func(op wire.OutPoint) (*wire.TxOut, error)
+
+// Verify checks that the passed proof commits to message and that every
+// output it spends, as returned by fetch, is validly signed for.  It returns
+// the outputs spent by the proof, in the order of its inputs, and their total.
+func Verify(tx *wire.MsgTx, message string, fetch FetchFunc) ([]*wire.TxOut, btcutil.Amount, error) {
+	if len(tx.TxIn) < 2 {
+		return nil, 0, ErrNoInputs
+	}
+	if tx.TxIn[0].PreviousOutPoint != CommitmentOutPoint(message) {
+		return nil, 0, ErrBadCommitment
+	}
+	if len(tx.TxOut) != 1 || len(tx.TxOut[0].PkScript) != 1 ||
+		tx.TxOut[0].PkScript[0] != txscript.OP_RETURN {
+
+		return nil, 0, ErrBadOutput
+	}
+
+	seen := make(map[wire.OutPoint]struct{}, len(tx.TxIn))
+	prevOuts := make([]*wire.TxOut, 0, len(tx.TxIn)-1)
+	var total btcutil.Amount
+	for i, txIn := range tx.TxIn[1:] {
+		if _, ok := seen[txIn.PreviousOutPoint]; ok {
+			return nil, 0, ErrDuplicateInput
+		}
+		seen[txIn.PreviousOutPoint] = struct{}{}
+
+		prevOut, err := fetch(txIn.PreviousOutPoint)
+		if err != nil {
+			return nil, 0, fmt.Errorf("input %d: %v", i+1, err)
+		}
+		prevOuts = append(prevOuts, prevOut)
+		total += btcutil.Amount(prevOut.Value)
+	}
+	if tx.TxOut[0].Value != int64(total) {
+		return nil, 0, ErrBadOutput
+	}
+
+	hashCache := txscript.NewTxSigHashes(tx)
+	for i, prevOut := range prevOuts {
+		err := txscript.VerifyScript(prevOut.PkScript, tx, i+1,
+			prevOut.Value, txscript.StandardVerifyFlags, nil, hashCache)
+		if err != nil {
+			return nil, 0, fmt.Errorf("input %d is not signed by the "+
+				"owner of %v: %v", i+1, tx.TxIn[i+1].PreviousOutPoint,
+				err)
+		}
+	}
+
+	return prevOuts, total, nil
+}
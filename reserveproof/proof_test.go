@@ -0,0 +1,127 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package reserveproof
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/btcec"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// p2pkhScript returns the pay-to-pubkey-hash script of the passed key.
+func p2pkhScript(t *testing.T, key *btcec.PrivateKey) []byte {
+	pkh := btcutil.Hash160(key.PubKey().SerializeCompressed())
+	script, err := txscript.NewScriptBuilder().AddOp(txscript.OP_DUP).
+		AddOp(txscript.OP_HASH160).AddData(pkh).
+		AddOp(txscript.OP_EQUALVERIFY).AddOp(txscript.OP_CHECKSIG).
+		Script()
+	if err != nil {
+		t.Fatalf("unable to build script: %v", err)
+	}
+	return script
+}
+
+// TestProof ensures proofs created by New and signed by the owner of the
+// outputs verify, and that tampered proofs are rejected.
+func TestProof(t *testing.T) {
+	const message = "pkt exchange reserves 2020-06-01"
+
+	key, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatalf("unable to generate key: %v", err)
+	}
+	otherKey, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatalf("unable to generate key: %v", err)
+	}
+	pkScript := p2pkhScript(t, key)
+
+	utxos := map[wire.OutPoint]*wire.TxOut{
+		{Hash: chainhash.HashH([]byte("a")), Index: 0}: wire.NewTxOut(1e9, pkScript),
+		{Hash: chainhash.HashH([]byte("b")), Index: 3}: wire.NewTxOut(25e8, pkScript),
+	}
+	fetch := func(op wire.OutPoint) (*wire.TxOut, error) {
+		txOut, ok := utxos[op]
+		if !ok {
+			return nil, errors.New("output does not exist")
+		}
+		return txOut, nil
+	}
+	outPoints := []wire.OutPoint{
+		{Hash: chainhash.HashH([]byte("a")), Index: 0},
+		{Hash: chainhash.HashH([]byte("b")), Index: 3},
+	}
+
+	// sign returns a signed copy of the passed proof, signing with key for
+	// every input but the first.
+	sign := func(tx *wire.MsgTx, key *btcec.PrivateKey) *wire.MsgTx {
+		tx = tx.Copy()
+		for i := 1; i < len(tx.TxIn); i++ {
+			sigScript, err := txscript.SignatureScript(tx, i, pkScript,
+				txscript.SigHashAll, key, true)
+			if err != nil {
+				t.Fatalf("unable to sign input %d: %v", i, err)
+			}
+			tx.TxIn[i].SignatureScript = sigScript
+		}
+		return tx
+	}
+
+	unsigned := New(message, outPoints, 35e8)
+	proof := sign(unsigned, key)
+	prevOuts, total, err := Verify(proof, message, fetch)
+	if err != nil {
+		t.Fatalf("Verify: unexpected error: %v", err)
+	}
+	if total != 35e8 || len(prevOuts) != 2 {
+		t.Fatalf("Verify: got total %v of %d outputs, want %v of 2",
+			total, len(prevOuts), btcutil.Amount(35e8))
+	}
+
+	// Changing the message, which the signatures commit to through the
+	// commitment input, must invalidate the proof.
+	if _, _, err := Verify(proof, "another message", fetch); err != ErrBadCommitment {
+		t.Errorf("Verify with wrong message: got %v, want %v", err,
+			ErrBadCommitment)
+	}
+	resigned := proof.Copy()
+	resigned.TxIn[0].PreviousOutPoint = CommitmentOutPoint("another message")
+	if _, _, err := Verify(resigned, "another message", fetch); err == nil {
+		t.Errorf("Verify with swapped commitment: unexpected success")
+	}
+
+	// The output must pay exactly the total of the inputs.
+	wrongTotal := sign(New(message, outPoints, 36e8), key)
+	if _, _, err := Verify(wrongTotal, message, fetch); err != ErrBadOutput {
+		t.Errorf("Verify with wrong total: got %v, want %v", err,
+			ErrBadOutput)
+	}
+
+	dup := sign(New(message, []wire.OutPoint{outPoints[0], outPoints[0]},
+		2e9), key)
+	if _, _, err := Verify(dup, message, fetch); err != ErrDuplicateInput {
+		t.Errorf("Verify with duplicate input: got %v, want %v", err,
+			ErrDuplicateInput)
+	}
+
+	if _, _, err := Verify(New(message, nil, 0), message, fetch); err != ErrNoInputs {
+		t.Errorf("Verify without inputs: got %v, want %v", err,
+			ErrNoInputs)
+	}
+
+	missing := sign(New(message, []wire.OutPoint{{Index: 7}}, 0), key)
+	if _, _, err := Verify(missing, message, fetch); err == nil {
+		t.Errorf("Verify with missing output: unexpected success")
+	}
+
+	if _, _, err := Verify(sign(unsigned, otherKey), message, fetch); err == nil {
+		t.Errorf("Verify signed by another key: unexpected success")
+	}
+}
@@ -0,0 +1,357 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/acme"
+)
+
+const (
+	// acmeAccountKeyFilename is the name of the file holding the key of
+	// the ACME account, in the directory of the RPC key.
+	acmeAccountKeyFilename = "rpcacme.key"
+
+	// acmeCheckInterval is the time between two checks of the expiration
+	// of the RPC certificate.
+	acmeCheckInterval = 12 * time.Hour
+
+	// acmeRetryInterval is the time before obtaining a certificate is
+	// attempted again after a failure.
+	acmeRetryInterval = time.Hour
+
+	// acmeRenewBefore is the time before its expiration at which the RPC
+	// certificate is renewed.
+	acmeRenewBefore = 30 * 24 * time.Hour
+
+	// acmeTimeout is the time allowed to obtain a certificate.
+	acmeTimeout = 10 * time.Minute
+)
+
+// rpcACMEManager obtains the RPC certificate from an ACME certificate
+// authority such as Let's Encrypt and renews it before it expires.  The
+// certificate authority checks the node controls the domains with the
+// TLS-ALPN-01 challenge, which the RPC listeners answer, so the RPC server
+// must be reachable on port 443 of the domains.
+//
+// The certificate and its key are written to the RPC certificate and key
+// files and replace the certificate served by the listeners without a
+// restart.
+type rpcACMEManager struct {
+	client  *acme.Client
+	email   string
+	domains []string
+	keyPair *rpcKeyPair
+
+	mtx        sync.Mutex
+	challenges map[string]*tls.Certificate
+
+	wg   sync.WaitGroup
+	quit chan struct{}
+}
+
+// newRPCACMEManager returns a manager of the certificate of the passed key
+// pair for the passed domains, obtained from the ACME certificate authority
+// with the passed directory URL.  The account key is loaded from the passed
+// file and generated when the file doesn't exist.
+func newRPCACMEManager(directoryURL, email string, domains []string,
+	accountKeyFile string, keyPair *rpcKeyPair) (*rpcACMEManager, error) {
+
+	accountKey, err := loadACMEAccountKey(accountKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load the ACME account key: %v",
+			err)
+	}
+	m := &rpcACMEManager{
+		client: &acme.Client{
+			Key:          accountKey,
+			DirectoryURL: directoryURL,
+			UserAgent:    "pktd/" + version(),
+		},
+		email:      email,
+		domains:    domains,
+		keyPair:    keyPair,
+		challenges: make(map[string]*tls.Certificate),
+		quit:       make(chan struct{}),
+	}
+	keyPair.challenge = m.challengeCert
+	return m, nil
+}
+
+// loadACMEAccountKey returns the ECDSA key of the passed PEM file, generating
+// and writing it when the file doesn't exist.
+func loadACMEAccountKey(file string) (crypto.Signer, error) {
+	keyPEM, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		keyPEM, err := marshalECKey(key)
+		if err != nil {
+			return nil, err
+		}
+		if err := ioutil.WriteFile(file, keyPEM, 0600); err != nil {
+			return nil, err
+		}
+		return key, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(keyPEM)
+	if block == nil || block.Type != "EC PRIVATE KEY" {
+		return nil, fmt.Errorf("no EC private key found in %s", file)
+	}
+	return x509.ParseECPrivateKey(block.Bytes)
+}
+
+// marshalECKey returns the PEM encoding of the passed key.
+func marshalECKey(key *ecdsa.PrivateKey) ([]byte, error) {
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), nil
+}
+
+// Start checks the certificate and keeps it renewed.
+func (m *rpcACMEManager) Start() {
+	m.wg.Add(1)
+	go m.handler()
+}
+
+// Stop stops renewing the certificate, aborting a renewal in progress, and
+// waits for the manager to finish.
+func (m *rpcACMEManager) Stop() {
+	close(m.quit)
+	m.wg.Wait()
+}
+
+// handler obtains a certificate when the current one doesn't cover the domains
+// or expires soon, checking it every acmeCheckInterval.  It must be run as a
+// goroutine.
+func (m *rpcACMEManager) handler() {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+out:
+	for {
+		select {
+		case <-timer.C:
+		case <-m.quit:
+			break out
+		}
+
+		wait := acmeCheckInterval
+		cert := m.keyPair.Certificate(time.Now())
+		if certNeedsRenewal(cert.Leaf, m.domains, time.Now()) {
+			if err := m.obtain(); err != nil {
+				rpcsLog.Warnf("Unable to obtain the RPC certificate "+
+					"from the ACME certificate authority, "+
+					"retrying in %v: %v", acmeRetryInterval, err)
+				wait = acmeRetryInterval
+			}
+		}
+		timer.Reset(wait)
+	}
+	m.wg.Done()
+}
+
+// certNeedsRenewal returns whether the passed certificate must be replaced
+// since it doesn't cover all the passed domains or expires soon.
+func certNeedsRenewal(leaf *x509.Certificate, domains []string, now time.Time) bool {
+	if leaf == nil || now.Add(acmeRenewBefore).After(leaf.NotAfter) {
+		return true
+	}
+	for _, domain := range domains {
+		if leaf.VerifyHostname(domain) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// obtain orders a certificate for the domains, answers the challenges of the
+// certificate authority and installs the certificate.
+func (m *rpcACMEManager) obtain() error {
+	ctx, cancel := context.WithTimeout(context.Background(), acmeTimeout)
+	defer cancel()
+	go func() {
+		select {
+		case <-m.quit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	rpcsLog.Infof("Obtaining the RPC certificate for %v from %s", m.domains,
+		m.client.DirectoryURL)
+
+	// Registering an existing account is harmless, and registers the key
+	// again should the certificate authority have forgotten it.
+	account := &acme.Account{}
+	if m.email != "" {
+		account.Contact = []string{"mailto:" + m.email}
+	}
+	_, err := m.client.Register(ctx, account, acme.AcceptTOS)
+	if err != nil && err != acme.ErrAccountAlreadyExists {
+		return fmt.Errorf("unable to register the account: %v", err)
+	}
+
+	order, err := m.client.AuthorizeOrder(ctx, acme.DomainIDs(m.domains...))
+	if err != nil {
+		return err
+	}
+	for _, url := range order.AuthzURLs {
+		if err := m.authorize(ctx, url); err != nil {
+			return err
+		}
+	}
+	order, err = m.client.WaitOrder(ctx, order.URI)
+	if err != nil {
+		return err
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader,
+		&x509.CertificateRequest{
+			Subject:  pkix.Name{CommonName: m.domains[0]},
+			DNSNames: m.domains,
+		}, key)
+	if err != nil {
+		return err
+	}
+	chain, _, err := m.client.CreateOrderCert(ctx, order.FinalizeURL, csr,
+		true)
+	if err != nil {
+		return err
+	}
+	return m.install(chain, key)
+}
+
+// authorize answers the TLS-ALPN-01 challenge of the authorization with the
+// passed URL, unless the domain is already authorized.
+func (m *rpcACMEManager) authorize(ctx context.Context, url string) error {
+	authz, err := m.client.GetAuthorization(ctx, url)
+	if err != nil {
+		return err
+	}
+	if authz.Status == acme.StatusValid {
+		return nil
+	}
+	var challenge *acme.Challenge
+	for _, c := range authz.Challenges {
+		if c.Type == "tls-alpn-01" {
+			challenge = c
+			break
+		}
+	}
+	if challenge == nil {
+		return fmt.Errorf("no TLS-ALPN-01 challenge offered for %s",
+			authz.Identifier.Value)
+	}
+
+	domain := authz.Identifier.Value
+	cert, err := m.client.TLSALPN01ChallengeCert(challenge.Token, domain)
+	if err != nil {
+		return err
+	}
+	m.mtx.Lock()
+	m.challenges[domain] = &cert
+	m.mtx.Unlock()
+	defer func() {
+		m.mtx.Lock()
+		delete(m.challenges, domain)
+		m.mtx.Unlock()
+	}()
+
+	if _, err := m.client.Accept(ctx, challenge); err != nil {
+		return err
+	}
+	_, err = m.client.WaitAuthorization(ctx, authz.URI)
+	return err
+}
+
+// challengeCert returns the certificate answering the pending TLS-ALPN-01
+// challenge for the passed domain.
+func (m *rpcACMEManager) challengeCert(domain string) (*tls.Certificate, error) {
+	m.mtx.Lock()
+	cert, ok := m.challenges[domain]
+	m.mtx.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("no ACME challenge pending for %q", domain)
+	}
+	return cert, nil
+}
+
+// install writes the passed certificate chain and key to the RPC certificate
+// and key files and serves it.
+func (m *rpcACMEManager) install(chain [][]byte, key *ecdsa.PrivateKey) error {
+	if len(chain) == 0 {
+		return errors.New("no certificate issued")
+	}
+	var certPEM []byte
+	for _, der := range chain {
+		certPEM = append(certPEM, pem.EncodeToMemory(&pem.Block{
+			Type:  "CERTIFICATE",
+			Bytes: der,
+		})...)
+	}
+	keyPEM, err := marshalECKey(key)
+	if err != nil {
+		return err
+	}
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return err
+	}
+	cert.Leaf, err = x509.ParseCertificate(chain[0])
+	if err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(m.keyPair.keyFile, keyPEM, 0600); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(m.keyPair.certFile, certPEM, 0666); err != nil {
+		return err
+	}
+	m.keyPair.Set(&cert)
+	rpcsLog.Infof("Installed the RPC certificate for %v, valid until %v",
+		m.domains, cert.Leaf.NotAfter)
+	return nil
+}
+
+// writeFileAtomic writes the passed data to a temporary file renamed to the
+// passed name, so the file is never seen partially written.
+func writeFileAtomic(name string, data []byte, perm os.FileMode) error {
+	tmp := name + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, perm); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, name); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
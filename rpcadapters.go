@@ -5,8 +5,10 @@
 package main
 
 import (
+	"errors"
 	"sync/atomic"
 
+	"github.com/pkt-cash/pktd/addrmgr"
 	"github.com/pkt-cash/pktd/blockchain"
 	"github.com/pkt-cash/pktd/chaincfg/chainhash"
 	"github.com/pkt-cash/pktd/mempool"
@@ -61,6 +63,14 @@ func (p *rpcPeer) FeeFilter() int64 {
 	return atomic.LoadInt64(&(*serverPeer)(p).feeFilter)
 }
 
+// Permissions returns the names of the permissions granted to the peer.
+//
+// This function is safe for concurrent access and is part of the rpcserverPeer
+// interface implementation.
+func (p *rpcPeer) Permissions() []string {
+	return (*serverPeer)(p).permissions.names()
+}
+
 // rpcConnManager provides a connection manager for use with the RPC server and
 // implements the rpcserverConnManager interface.
 type rpcConnManager struct {
@@ -218,9 +228,94 @@ func (cm *rpcConnManager) AddRebroadcastInventory(iv *wire.InvVect, data interfa
 }
 
 // RelayTransactions generates and relays inventory vectors for all of the
-// passed transactions to all connected peers.
+// passed transactions to all connected peers.  The transactions are relayed as
+// locally submitted ones.
 func (cm *rpcConnManager) RelayTransactions(txns []*mempool.TxDesc) {
-	cm.server.relayTransactions(txns)
+	cm.server.relayLocalTransactions(txns)
+}
+
+// NodeAddresses returns up to count randomly chosen addresses known to the
+// address manager which are not considered bad, or all of them when count is
+// zero.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverConnManager interface implementation.
+func (cm *rpcConnManager) NodeAddresses(count int) []*wire.NetAddress {
+	return cm.server.addrManager.GoodAddresses(count)
+}
+
+// AddrManagerStats returns statistics about the addresses known to the address
+// manager.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverConnManager interface implementation.
+func (cm *rpcConnManager) AddrManagerStats() *addrmgr.Stats {
+	return cm.server.addrManager.Stats()
+}
+
+// AddPeerAddress adds the passed address to the address manager, marking it
+// good so it is moved to a tried bucket when tried is set.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverConnManager interface implementation.
+func (cm *rpcConnManager) AddPeerAddress(na *wire.NetAddress, tried bool) error {
+	if !addrmgr.IsRoutable(na) {
+		return errors.New("address is not routable")
+	}
+	cm.server.addrManager.AddAddress(na, na)
+	if tried {
+		cm.server.addrManager.Good(na)
+	}
+	return nil
+}
+
+// LocalAddresses returns the local addresses advertised to peers.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverConnManager interface implementation.
+func (cm *rpcConnManager) LocalAddresses() []addrmgr.LocalAddress {
+	return cm.server.addrManager.LocalAddresses()
+}
+
+// AddListener starts listening for peer connections at the passed listen
+// address, which may be a wildcard or name a network interface.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverConnManager interface implementation.
+func (cm *rpcConnManager) AddListener(listenAddr string) error {
+	_, err := cm.server.AddListener(listenAddr)
+	return err
+}
+
+// RemoveListener stops listening for peer connections at the passed listen
+// address or bound address.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverConnManager interface implementation.
+func (cm *rpcConnManager) RemoveListener(addr string) error {
+	_, err := cm.server.RemoveListener(addr)
+	return err
+}
+
+// Listeners returns the listeners accepting peer connections.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverConnManager interface implementation.
+func (cm *rpcConnManager) Listeners() []*p2pListener {
+	listeners := cm.server.connManager.Listeners()
+	p2pListeners := make([]*p2pListener, 0, len(listeners))
+	for _, l := range listeners {
+		p2pListeners = append(p2pListeners, l.(*p2pListener))
+	}
+	return p2pListeners
+}
+
+// LocalServices returns the services advertised to peers.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverConnManager interface implementation.
+func (cm *rpcConnManager) LocalServices() wire.ServiceFlag {
+	return cm.server.services
 }
 
 // rpcSyncMgr provides a block manager for use with the RPC server and
@@ -268,6 +363,23 @@ func (b *rpcSyncMgr) SyncPeerID() int32 {
 	return b.syncMgr.SyncPeerID()
 }
 
+// RejectedTxns returns the transactions relayed by peers which were recently
+// rejected, most recent first.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverSyncManager interface implementation.
+func (b *rpcSyncMgr) RejectedTxns() []netsync.RejectedTx {
+	return b.syncMgr.RejectedTxns()
+}
+
+// SyncStatus returns the status of the sync with the chain of the network.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverSyncManager interface implementation.
+func (b *rpcSyncMgr) SyncStatus() *netsync.SyncStatus {
+	return b.syncMgr.SyncStatus()
+}
+
 // LocateBlocks returns the hashes of the blocks after the first known block in
 // the provided locators until the provided stop hash or the current tip is
 // reached, up to a max of wire.MaxBlockHeadersPerMsg hashes.
@@ -0,0 +1,105 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/difficulty"
+	"github.com/pkt-cash/pktd/btcjson"
+)
+
+// maxAnnAgingSchedule is the maximum number of ages returned by
+// getannagingschedule.
+const maxAnnAgingSchedule = 1000
+
+// handleGetAnnAgingSchedule implements the getannagingschedule command.
+func handleGetAnnAgingSchedule(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetAnnAgingScheduleCmd)
+
+	maxAge := int32(20)
+	if c.MaxAge != nil {
+		maxAge = *c.MaxAge
+	}
+	if maxAge < 0 || maxAge > maxAnnAgingSchedule {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCInvalidParameter,
+			Message: fmt.Sprintf("Max age must be between 0 and %d",
+				maxAnnAgingSchedule),
+		}
+	}
+
+	// The minimum announcement target of the best block is what the
+	// announcements are compared with, and the default target.
+	best := s.cfg.Chain.BestSnapshot()
+	block, err := s.cfg.Chain.BlockByHeight(best.Height)
+	if err != nil {
+		context := "Failed to fetch block"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	work := packetcrypt.ExtractBlockWork(block.MsgBlock())
+	minTarget := uint32(difficulty.MaxAgedAnnTarget)
+	if work.AnnCount > 0 {
+		minTarget = work.AnnMinTarget
+	}
+
+	target := minTarget
+	if c.Target != nil {
+		t, err := strconv.ParseUint(*c.Target, 16, 32)
+		if err != nil || !difficulty.IsAnnMinDiffOk(uint32(t)) {
+			return nil, &btcjson.RPCError{
+				Code: btcjson.ErrRPCInvalidParameter,
+				Message: fmt.Sprintf("Invalid announcement target %q",
+					*c.Target),
+			}
+		}
+		target = uint32(t)
+	}
+
+	height := best.Height + 1
+	result := &btcjson.GetAnnAgingScheduleResult{
+		Height:        height,
+		Target:        strconv.FormatInt(int64(target), 16),
+		MaxAgedBits:   strconv.FormatInt(difficulty.MaxAgedAnnTarget, 16),
+		WaitPeriod:    difficulty.AnnWaitPeriod,
+		ExpiryAge:     -1,
+		AcceptableAge: -1,
+		Schedule:      make([]btcjson.AnnAgeResult, 0, maxAge+1),
+	}
+	if work.AnnCount > 0 {
+		result.TipAnnMinBits = strconv.FormatInt(int64(minTarget), 16)
+	}
+	if age, ok := difficulty.MaxAnnAge(target, difficulty.MaxAgedAnnTarget); ok {
+		result.ExpiryAge = int64(age)
+	}
+	if age, ok := difficulty.MaxAnnAge(target, minTarget); ok {
+		result.AcceptableAge = int64(age)
+	}
+
+	// Announcements can't have a parent below the genesis block.
+	for age := int32(0); age <= maxAge && age <= height; age++ {
+		entry := btcjson.AnnAgeResult{
+			Age:          age,
+			ParentHeight: height - age,
+		}
+		aged := difficulty.GetAgedAnnTarget(target, uint32(age))
+		switch {
+		case age < difficulty.AnnWaitPeriod:
+			entry.Status = "waiting"
+		case aged > difficulty.MaxAgedAnnTarget:
+			entry.Status = "expired"
+		default:
+			entry.Status = "valid"
+			entry.Bits = strconv.FormatInt(int64(aged), 16)
+			entry.Difficulty = getDifficultyRatio(aged,
+				s.cfg.ChainParams)
+			entry.Acceptable = aged <= minTarget
+		}
+		result.Schedule = append(result.Schedule, entry)
+	}
+	return result, nil
+}
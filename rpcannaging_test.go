@@ -0,0 +1,92 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/difficulty"
+	"github.com/pkt-cash/pktd/btcjson"
+)
+
+// TestGetAnnAgingSchedule ensures getannagingschedule validates its parameters
+// and returns the aged targets of announcements by age.
+func TestGetAnnAgingSchedule(t *testing.T) {
+	tc, teardown := newTestChain(t, "annaging", nil)
+	defer teardown()
+	params, chain, g := tc.params, tc.chain, tc.gen
+
+	s := &rpcServer{cfg: rpcserverConfig{Chain: chain, ChainParams: params}}
+	if _, err := g.generate(params.GenesisHash, 5, nil); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+
+	getSchedule := func(target *string, maxAge *int32) (*btcjson.GetAnnAgingScheduleResult, error) {
+		cmd := btcjson.NewGetAnnAgingScheduleCmd(target, maxAge)
+		result, err := handleGetAnnAgingSchedule(s, cmd, nil)
+		if err != nil {
+			return nil, err
+		}
+		return result.(*btcjson.GetAnnAgingScheduleResult), nil
+	}
+
+	// Invalid targets and ages are rejected.
+	for _, target := range []string{"zz", "0", "21000001"} {
+		if _, err := getSchedule(&target, nil); err == nil {
+			t.Errorf("getannagingschedule %s: unexpected success", target)
+		}
+	}
+	for _, maxAge := range []int32{-1, maxAnnAgingSchedule + 1} {
+		if _, err := getSchedule(nil, &maxAge); err == nil {
+			t.Errorf("getannagingschedule %d: unexpected success", maxAge)
+		}
+	}
+
+	// Without an announcement commitment in the best block, the default
+	// target is the highest aged target, which expires as soon as it
+	// ages.
+	schedule, err := getSchedule(nil, nil)
+	if err != nil {
+		t.Fatalf("getannagingschedule: %v", err)
+	}
+	if schedule.Height != 6 || schedule.Target != "207fffff" ||
+		schedule.TipAnnMinBits != "" ||
+		schedule.WaitPeriod != difficulty.AnnWaitPeriod ||
+		schedule.ExpiryAge != schedule.AcceptableAge ||
+		len(schedule.Schedule) != 7 {
+
+		t.Fatalf("unexpected schedule %+v", schedule)
+	}
+	for _, entry := range schedule.Schedule {
+		want := "valid"
+		switch {
+		case entry.Age < difficulty.AnnWaitPeriod:
+			want = "waiting"
+		case int64(entry.Age) > schedule.ExpiryAge:
+			want = "expired"
+		}
+		if entry.Status != want || entry.ParentHeight != 6-entry.Age ||
+			entry.Acceptable != (want == "valid") {
+
+			t.Fatalf("unexpected entry %+v", entry)
+		}
+	}
+
+	// An announcement with more work stays valid longer.
+	target, maxAge := "2000ffff", int32(5)
+	schedule, err = getSchedule(&target, &maxAge)
+	if err != nil {
+		t.Fatalf("getannagingschedule: %v", err)
+	}
+	if schedule.Target != target || schedule.ExpiryAge <= int64(maxAge) ||
+		len(schedule.Schedule) != 6 {
+
+		t.Fatalf("unexpected schedule %+v", schedule)
+	}
+	fresh := schedule.Schedule[difficulty.AnnWaitPeriod]
+	if fresh.Status != "valid" || fresh.Bits != target || !fresh.Acceptable {
+		t.Fatalf("unexpected fresh entry %+v", fresh)
+	}
+}
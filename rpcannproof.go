@@ -0,0 +1,121 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/pcutil"
+	"github.com/pkt-cash/pktd/btcjson"
+	"github.com/pkt-cash/pktd/btcutil/merkle"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/spv"
+)
+
+// newAnnProof returns a proof that the announcement at the passed position,
+// from 0 to 3, of the PacketCrypt proof of the passed block is included in the
+// announcement tree committed to by its coinbase.  The block is expected to be
+// valid, the proof is not verified.
+func newAnnProof(block *btcutil.Block, position int) (*spv.AnnProof, error) {
+	mb := block.MsgBlock()
+	if len(mb.Transactions) == 0 {
+		return nil, errors.New("missing coinbase")
+	}
+	if position < 0 || position > 3 {
+		return nil, fmt.Errorf("announcement position %d is out of range",
+			position)
+	}
+	annIndexes, err := packetcrypt.AnnIndexes(mb)
+	if err != nil {
+		return nil, err
+	}
+	coinbase := block.Transactions()[0]
+	merkleBlock, err := merkle.NewMerkleBlock(block,
+		[]*chainhash.Hash{coinbase.Hash()})
+	if err != nil {
+		return nil, err
+	}
+
+	p := &spv.AnnProof{
+		Block:        *merkleBlock,
+		Coinbase:     *coinbase.MsgTx(),
+		Ann:          mb.Pcp.Announcements[position],
+		Position:     uint8(position),
+		AnnIndexes:   annIndexes,
+		AnnTreeProof: mb.Pcp.AnnProof,
+	}
+	for i := range mb.Pcp.Announcements {
+		pcutil.HashCompress(p.AnnHashes[i][:],
+			mb.Pcp.Announcements[i].Header[:])
+	}
+	return p, nil
+}
+
+// handleGetAnnProof implements the getannproof command.
+func handleGetAnnProof(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetAnnProofCmd)
+
+	hash, err := chainhash.NewHashFromStr(c.BlockHash)
+	if err != nil {
+		return nil, rpcDecodeHexError(c.BlockHash)
+	}
+	if c.Position < 0 || c.Position > 3 {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Position must be between 0 and 3",
+		}
+	}
+	block, err := s.cfg.Chain.BlockByHash(hash)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCBlockNotFound,
+			Message: "Block not found in the main chain",
+		}
+	}
+	proof, err := newAnnProof(block, int(c.Position))
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Unable to prove the announcement: " + err.Error(),
+		}
+	}
+
+	// The proof is checked so the fields returned are the ones a verifier
+	// gets from it.
+	inclusion, err := proof.Verify()
+	if err != nil {
+		context := "Failed to verify the announcement proof"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	var buf bytes.Buffer
+	if err := proof.Serialize(&buf); err != nil {
+		context := "Failed to serialize the announcement proof"
+		return nil, internalRPCError(err.Error(), context)
+	}
+
+	ann := &proof.Ann
+	result := &btcjson.GetAnnProofResult{
+		Hex:          hex.EncodeToString(buf.Bytes()),
+		BlockHash:    inclusion.BlockHash.String(),
+		Height:       inclusion.BlockHeight,
+		Position:     c.Position,
+		AnnHash:      hex.EncodeToString(inclusion.AnnHash[:]),
+		ParentHeight: ann.GetParentBlockHeight(),
+		WorkBits:     strconv.FormatInt(int64(ann.GetWorkTarget()), 16),
+		AgedBits:     strconv.FormatInt(int64(inclusion.AnnTarget), 16),
+		AnnMinBits:   strconv.FormatInt(int64(inclusion.AnnMinTarget), 16),
+		AnnCount:     inclusion.AnnCount,
+	}
+	if ann.HasSigningKey() {
+		result.SigningKey = hex.EncodeToString(ann.GetSigningKey())
+	}
+	return result, nil
+}
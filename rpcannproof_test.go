@@ -0,0 +1,253 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/block/proof"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/pcutil"
+	"github.com/pkt-cash/pktd/btcjson"
+	"github.com/pkt-cash/pktd/spv"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// TestGetAnnProof ensures getannproof rejects invalid positions, blocks
+// outside of the main chain and blocks without a PacketCrypt proof.
+func TestGetAnnProof(t *testing.T) {
+	tc, teardown := newTestChain(t, "annproof", nil)
+	defer teardown()
+	params, chain, g := tc.params, tc.chain, tc.gen
+	s := &rpcServer{cfg: rpcserverConfig{Chain: chain}}
+
+	hashes, err := g.generate(params.GenesisHash, 1, nil)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	tests := []struct {
+		name      string
+		blockHash string
+		position  int32
+		code      btcjson.RPCErrorCode
+	}{
+		{"negative position", hashes[0].String(), -1,
+			btcjson.ErrRPCInvalidParameter},
+		{"position too high", hashes[0].String(), 4,
+			btcjson.ErrRPCInvalidParameter},
+		{"unknown block", "00", 0, btcjson.ErrRPCBlockNotFound},
+		{"no packetcrypt proof", hashes[0].String(), 0,
+			btcjson.ErrRPCInvalidParameter},
+	}
+	for _, test := range tests {
+		cmd := btcjson.NewGetAnnProofCmd(test.blockHash, test.position)
+		_, err := handleGetAnnProof(s, cmd, nil)
+		if rpcErr, ok := err.(*btcjson.RPCError); !ok ||
+			rpcErr.Code != test.code {
+
+			t.Fatalf("%s: unexpected error %v", test.name, err)
+		}
+	}
+}
+
+// annTestHeight is the height of the blocks built by annProofBlock, the
+// announcements being mined 5 blocks earlier.
+const annTestHeight = 1000
+
+// annProofBlock returns a block at annTestHeight committing to a tree of 7
+// announcements with the passed minimum announcement target, whose PacketCrypt
+// proof samples the first 4 of them.  Only the parts of the block needed to
+// prove the announcements are filled in.
+func annProofBlock(t *testing.T, annMinTarget uint32) *btcutil.Block {
+	// The announcements are sorted by hash, which is the order of the
+	// leaves of the tree, following the zero entry.
+	var anns [4]wire.PacketCryptAnn
+	var hashes [4][32]byte
+	for i := range anns {
+		binary.LittleEndian.PutUint32(anns[i].Header[8:12], 0x200fffff)
+		binary.LittleEndian.PutUint32(anns[i].Header[12:16],
+			annTestHeight-5)
+		anns[i].Header[100] = byte(i)
+	}
+	start := func(ann *wire.PacketCryptAnn) uint64 {
+		var hash [32]byte
+		pcutil.HashCompress(hash[:], ann.Header[:])
+		return binary.LittleEndian.Uint64(hash[:8])
+	}
+	sort.Slice(anns[:], func(i, j int) bool {
+		return start(&anns[i]) < start(&anns[j])
+	})
+	for i := range anns {
+		pcutil.HashCompress(hashes[i][:], anns[i].Header[:])
+	}
+
+	// The tree has 8 leaves: the zero entry, the announcements and the 3
+	// other announcements of the block, which are left out of the
+	// PacketCrypt proof.  The proof holds the hash of the zero entry, the
+	// ranges of the proven announcements on the right side, which end
+	// where the next one starts, and the hashes and ranges of the leaf 5
+	// and of the node above the leaves 6 and 7, the last one ending the
+	// tree.
+	var treeProof bytes.Buffer
+	writeRange := func(r uint64) {
+		var b [8]byte
+		binary.LittleEndian.PutUint64(b[:], r)
+		treeProof.Write(b[:])
+	}
+	treeProof.Write(make([]byte, 32))
+	writeRange(start(&anns[1]) - start(&anns[0]))
+	writeRange(start(&anns[3]) - start(&anns[2]))
+	leaf5Start := start(&anns[3]) + (^start(&anns[3]))/2
+	var leaf5Hash [32]byte
+	binary.LittleEndian.PutUint64(leaf5Hash[:8], leaf5Start)
+	leaf5Hash[31] = 5
+	writeRange(1)
+	treeProof.Write(leaf5Hash[:])
+	writeRange(^(leaf5Start + 1))
+	treeProof.Write(bytes.Repeat([]byte{6}, 32))
+
+	pcp := &wire.PacketCryptProof{Announcements: anns,
+		AnnProof: treeProof.Bytes()}
+	wantIndexes := [4]uint64{0, 1, 2, 3}
+	root, err := proof.PcpHash(&hashes, 7, &wantIndexes, pcp)
+	if err != nil {
+		t.Fatalf("PcpHash: %v", err)
+	}
+
+	commit := wire.NewPcCoinbaseCommit()
+	binary.LittleEndian.PutUint32(commit.Bytes[4:8], annMinTarget)
+	copy(commit.Bytes[8:40], root[:])
+	binary.LittleEndian.PutUint64(commit.Bytes[40:48], 7)
+	coinbase := wire.NewMsgTx(1)
+	coinbase.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Index: wire.MaxPrevOutIndex},
+		SignatureScript:  []byte{0x02, 0xe8, 0x03, 0x00},
+	})
+	coinbase.AddTxOut(wire.NewTxOut(0, []byte{0x51}))
+	packetcrypt.InsertCoinbaseCommit(coinbase, commit)
+
+	mb := &wire.MsgBlock{
+		Header: wire.BlockHeader{
+			Version:    1,
+			MerkleRoot: coinbase.TxHash(),
+			Timestamp:  time.Unix(1600000000, 0),
+			Bits:       0x207fffff,
+		},
+		Transactions: []*wire.MsgTx{coinbase},
+		Pcp:          pcp,
+	}
+
+	// Find nonces sampling each announcement at its position in the tree.
+	// The first announcement is selected by the header, so its nonce is
+	// changed along with the one of the PacketCrypt proof.
+	for nonce := uint32(0); nonce < 100000; nonce++ {
+		mb.Header.Nonce = nonce
+		pcp.Nonce = nonce
+		indexes, err := packetcrypt.AnnIndexes(mb)
+		if err != nil {
+			t.Fatalf("AnnIndexes: %v", err)
+		}
+		for i := range indexes {
+			indexes[i] %= 7
+		}
+		if indexes == wantIndexes {
+			return btcutil.NewBlock(mb)
+		}
+	}
+	t.Fatalf("no nonce samples the announcements in order")
+	return nil
+}
+
+// TestNewAnnProof ensures the proofs of the announcements of a block survive
+// serialization and verify against its commitment, while altered proofs fail.
+func TestNewAnnProof(t *testing.T) {
+	blk := annProofBlock(t, 0x207fffff)
+	mb := blk.MsgBlock()
+
+	for position := 0; position < 4; position++ {
+		p, err := newAnnProof(blk, position)
+		if err != nil {
+			t.Fatalf("newAnnProof(%d): %v", position, err)
+		}
+		var buf bytes.Buffer
+		if err := p.Serialize(&buf); err != nil {
+			t.Fatalf("Serialize: %v", err)
+		}
+		var got spv.AnnProof
+		if err := got.Deserialize(bytes.NewReader(buf.Bytes())); err != nil {
+			t.Fatalf("Deserialize: %v", err)
+		}
+		if !reflect.DeepEqual(&got, p) {
+			t.Fatalf("deserialized proof %d differs", position)
+		}
+
+		inclusion, err := got.Verify()
+		if err != nil {
+			t.Fatalf("Verify(%d): %v", position, err)
+		}
+		if inclusion.BlockHash != mb.Header.BlockHash() ||
+			inclusion.BlockHeight != annTestHeight ||
+			inclusion.AnnHash != p.AnnHashes[position] ||
+			inclusion.AnnCount != 7 ||
+			inclusion.AnnMinTarget != 0x207fffff ||
+			inclusion.AnnTarget <= 0x200fffff ||
+			inclusion.AnnTarget > inclusion.AnnMinTarget {
+
+			t.Fatalf("unexpected inclusion %+v", inclusion)
+		}
+	}
+
+	if _, err := newAnnProof(blk, 4); err == nil {
+		t.Fatalf("newAnnProof succeeded with an out of range position")
+	}
+
+	// Altered announcements and tree positions do not match the
+	// commitment.
+	p, err := newAnnProof(blk, 0)
+	if err != nil {
+		t.Fatalf("newAnnProof: %v", err)
+	}
+	p.AnnHashes[1][31] ^= 1
+	if _, err := p.Verify(); err != spv.ErrAnnRootMismatch {
+		t.Fatalf("Verify with an altered announcement hash: got %v, "+
+			"want %v", err, spv.ErrAnnRootMismatch)
+	}
+	p, _ = newAnnProof(blk, 0)
+	p.AnnIndexes[0], p.AnnIndexes[1] = p.AnnIndexes[1], p.AnnIndexes[0]
+	if _, err := p.Verify(); err == nil {
+		t.Fatalf("Verify succeeded with altered announcement indexes")
+	}
+	p, _ = newAnnProof(blk, 0)
+	p.Ann = mb.Pcp.Announcements[1]
+	if _, err := p.Verify(); err == nil {
+		t.Fatalf("Verify succeeded with another announcement")
+	}
+
+	// The coinbase must be the one proven by the merkle block.
+	p, _ = newAnnProof(blk, 0)
+	p.Coinbase.LockTime++
+	if _, err := p.Verify(); err != spv.ErrCoinbaseNotProven {
+		t.Fatalf("Verify with an altered coinbase: got %v, want %v", err,
+			spv.ErrCoinbaseNotProven)
+	}
+
+	// The announcements are too old for a block committing to their work
+	// target.
+	blk = annProofBlock(t, 0x200fffff)
+	p, err = newAnnProof(blk, 0)
+	if err != nil {
+		t.Fatalf("newAnnProof: %v", err)
+	}
+	if _, err := p.Verify(); err != spv.ErrAnnTargetTooHigh {
+		t.Fatalf("Verify with an aged announcement: got %v, want %v", err,
+			spv.ErrAnnTargetTooHigh)
+	}
+}
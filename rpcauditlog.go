@@ -0,0 +1,312 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkt-cash/pktd/btcjson"
+)
+
+const (
+	// auditLogFilename is the name of the file, relative to the data
+	// directory, which holds the RPC audit log.
+	auditLogFilename = "rpcaudit.log"
+
+	// maxAuditLogRecent is the number of most recent audit log entries
+	// which are kept in memory in order to service the getauditlog RPC.
+	maxAuditLogRecent = 1000
+
+	// maxAuditParamsSize is the maximum size of the JSON-encoded
+	// parameters recorded in an audit log entry.  Larger parameters, such
+	// as the ones of submitblock, are replaced by their size and hash.
+	maxAuditParamsSize = 64 * 1024
+)
+
+// zeroAuditHash is the previous hash committed to by the first entry of an
+// audit log.
+var zeroAuditHash = hex.EncodeToString(make([]byte, sha256.Size))
+
+// rpcAudited is the set of state-changing commands which are recorded in the
+// RPC audit log when it is enabled.  Wallet commands are included since a
+// rejected attempt to move funds through the node is still worth recording.
+var rpcAudited = map[string]struct{}{
+	"addnode":                {},
+	"addpeeraddress":         {},
+	"capturemessages":        {},
+	"captureprofile":         {},
+	"configureminingpayouts": {},
+	"createdepositaddresses": {},
+	"debuglevel":             {},
+	"generate":               {},
+	"generatefork":           {},
+	"invalidateblock":        {},
+	"listeners":              {},
+	"minttoken":              {},
+	"node":                   {},
+	"preciousblock":          {},
+	"reconsiderblock":        {},
+	"sendfrom":               {},
+	"sendmany":               {},
+	"sendmanybatch":          {},
+	"sendrawtransaction":     {},
+	"sendtoaddress":          {},
+	"setban":                 {},
+	"setblocklimits":         {},
+	"setgenerate":            {},
+	"simulatereorg":          {},
+	"stop":                   {},
+	"submitblock":            {},
+	"triggergc":              {},
+}
+
+// isAuditedCmd returns whether the passed command is one which changes the
+// state of the server and is therefore recorded in the audit log.  The
+// getcoldspends command only does when it acknowledges the alarm.
+func isAuditedCmd(cmd *parsedRPCCmd) bool {
+	if c, ok := cmd.cmd.(*btcjson.GetColdSpendsCmd); ok {
+		return c.Acknowledge != nil && *c.Acknowledge
+	}
+	_, ok := rpcAudited[cmd.method]
+	return ok
+}
+
+// rpcAuditLog is an append-only log of state-changing RPC commands.  Every
+// entry commits to the hash of the entry before it, so any modification,
+// reordering or removal of entries in the file is detected the next time the
+// log is opened.
+type rpcAuditLog struct {
+	mtx      sync.Mutex
+	file     *os.File
+	syslog   io.Writer
+	nextSeq  int64
+	lastHash string
+	recent   []btcjson.AuditLogEntry
+}
+
+// auditEntryHash returns the hex-encoded hash of the passed entry, which is
+// the sha256 of its JSON serialization with the hash field left empty.
+func auditEntryHash(entry *btcjson.AuditLogEntry) (string, error) {
+	e := *entry
+	e.Hash = ""
+	serialized, err := json.Marshal(&e)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(serialized)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// openRPCAuditLog opens the audit log at the passed path, creating it if it
+// does not exist, and verifies the hash chain of all existing entries.  When
+// syslogWriter is not nil, every new entry is also written to it.
+func openRPCAuditLog(path string, syslogWriter io.Writer) (*rpcAuditLog, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &rpcAuditLog{
+		file:     file,
+		syslog:   syslogWriter,
+		lastHash: zeroAuditHash,
+	}
+	reader := bufio.NewReader(file)
+	var offset int64
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			if len(line) != 0 {
+				err = l.recoverLastEntry(line, offset)
+			} else {
+				err = nil
+			}
+			if err != nil {
+				file.Close()
+				return nil, err
+			}
+			break
+		}
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		if err := l.readEntry(line); err != nil {
+			file.Close()
+			return nil, err
+		}
+		offset += int64(len(line))
+	}
+
+	return l, nil
+}
+
+// readEntry parses the passed line of the audit log and verifies it extends
+// the hash chain.
+//
+// This function MUST be called before the log is shared.
+func (l *rpcAuditLog) readEntry(line []byte) error {
+	var entry btcjson.AuditLogEntry
+	if err := json.Unmarshal(line, &entry); err != nil {
+		return fmt.Errorf("malformed audit log entry %d: %v",
+			l.nextSeq, err)
+	}
+	if err := l.verifyNext(&entry); err != nil {
+		return err
+	}
+	l.appendRecent(entry)
+	return nil
+}
+
+// recoverLastEntry handles a last line of the audit log without a newline at
+// the passed offset, which is left by a crash while the entry was written.
+// The newline is added when the entry is complete, otherwise the line is
+// truncated from the file.
+//
+// This function MUST be called before the log is shared.
+func (l *rpcAuditLog) recoverLastEntry(line []byte, offset int64) error {
+	err := l.readEntry(line)
+	if err == nil {
+		_, err = l.file.Write([]byte{'\n'})
+		return err
+	}
+	rpcsLog.Warnf("Discarding the incomplete last entry %d of the audit "+
+		"log: %v", l.nextSeq, err)
+	return l.file.Truncate(offset)
+}
+
+// verifyNext ensures the passed entry correctly extends the hash chain and
+// advances the chain state past it.
+//
+// This function MUST be called with the log mutex held or before the log is
+// shared.
+func (l *rpcAuditLog) verifyNext(entry *btcjson.AuditLogEntry) error {
+	if entry.Seq != l.nextSeq {
+		return fmt.Errorf("audit log entry %d has unexpected sequence "+
+			"number %d", l.nextSeq, entry.Seq)
+	}
+	if entry.PrevHash != l.lastHash {
+		return fmt.Errorf("audit log entry %d does not commit to the "+
+			"previous entry", entry.Seq)
+	}
+	hash, err := auditEntryHash(entry)
+	if err != nil {
+		return err
+	}
+	if entry.Hash != hash {
+		return fmt.Errorf("audit log entry %d has been modified",
+			entry.Seq)
+	}
+	l.nextSeq++
+	l.lastHash = hash
+	return nil
+}
+
+// appendRecent adds the passed entry to the in-memory list of recent entries,
+// evicting the oldest one when it is full.
+//
+// This function MUST be called with the log mutex held or before the log is
+// shared.
+func (l *rpcAuditLog) appendRecent(entry btcjson.AuditLogEntry) {
+	if len(l.recent) == maxAuditLogRecent {
+		copy(l.recent, l.recent[1:])
+		l.recent = l.recent[:len(l.recent)-1]
+	}
+	l.recent = append(l.recent, entry)
+}
+
+// Record appends an entry for the passed command to the audit log.  Failures
+// to write the log are logged but otherwise do not affect the command.
+//
+// This function is safe for concurrent access.
+func (l *rpcAuditLog) Record(user, remoteAddr, method string, cmd interface{}, cmdErr error) {
+	params, err := json.Marshal(cmd)
+	if err != nil {
+		params = []byte("null")
+	}
+	if len(params) > maxAuditParamsSize {
+		sum := sha256.Sum256(params)
+		params = []byte(fmt.Sprintf(`{"size":%d,"sha256":"%x"}`,
+			len(params), sum))
+	}
+
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	entry := btcjson.AuditLogEntry{
+		Seq:      l.nextSeq,
+		Time:     time.Now().Unix(),
+		User:     user,
+		Remote:   remoteAddr,
+		Method:   method,
+		Params:   string(params),
+		PrevHash: l.lastHash,
+	}
+	if cmdErr != nil {
+		entry.Error = cmdErr.Error()
+	}
+	entry.Hash, err = auditEntryHash(&entry)
+	if err != nil {
+		rpcsLog.Errorf("Unable to hash audit log entry: %v", err)
+		return
+	}
+	serialized, err := json.Marshal(&entry)
+	if err != nil {
+		rpcsLog.Errorf("Unable to serialize audit log entry: %v", err)
+		return
+	}
+	serialized = append(serialized, '\n')
+	if _, err := l.file.Write(serialized); err != nil {
+		rpcsLog.Errorf("Unable to write audit log entry: %v", err)
+		return
+	}
+	if err := l.file.Sync(); err != nil {
+		rpcsLog.Errorf("Unable to sync audit log: %v", err)
+	}
+	if l.syslog != nil {
+		if _, err := l.syslog.Write(serialized); err != nil {
+			rpcsLog.Warnf("Unable to export audit log entry to "+
+				"syslog: %v", err)
+		}
+	}
+
+	l.nextSeq++
+	l.lastHash = entry.Hash
+	l.appendRecent(entry)
+}
+
+// Entries returns up to count of the most recently recorded entries, oldest
+// first.
+//
+// This function is safe for concurrent access.
+func (l *rpcAuditLog) Entries(count int) []btcjson.AuditLogEntry {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	if count < 0 || count > len(l.recent) {
+		count = len(l.recent)
+	}
+	entries := make([]btcjson.AuditLogEntry, count)
+	copy(entries, l.recent[len(l.recent)-count:])
+	return entries
+}
+
+// Close closes the underlying audit log file.
+//
+// This function is safe for concurrent access.
+func (l *rpcAuditLog) Close() error {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	return l.file.Close()
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pkt-cash/pktd/btcjson"
+)
+
+// TestRPCAuditLog ensures audit log entries are chained, survive reopening the
+// log and that tampering with the file is detected.
+func TestRPCAuditLog(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "pktdaudit")
+	if err != nil {
+		t.Fatalf("Failed creating a temporary directory: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	path := filepath.Join(tmpDir, auditLogFilename)
+
+	var exported bytes.Buffer
+	l, err := openRPCAuditLog(path, &exported)
+	if err != nil {
+		t.Fatalf("openRPCAuditLog: %v", err)
+	}
+	l.Record("admin", "127.0.0.1:1234", "stop", &btcjson.StopCmd{}, nil)
+	l.Record("admin", "127.0.0.1:1234", "node",
+		btcjson.NewNodeCmd(btcjson.NConnect, "1.2.3.4", nil),
+		errors.New("boom"))
+	entries := l.Entries(10)
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	if entries[0].PrevHash != zeroAuditHash ||
+		entries[1].PrevHash != entries[0].Hash {
+		t.Fatalf("entries are not chained: %+v", entries)
+	}
+	if entries[1].Error != "boom" {
+		t.Fatalf("got error %q, want %q", entries[1].Error, "boom")
+	}
+	if bytes.Count(exported.Bytes(), []byte("\n")) != 2 {
+		t.Fatalf("entries were not exported: %q", exported.String())
+	}
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// Reopening the log must recover the chain so new entries extend it.
+	l, err = openRPCAuditLog(path, nil)
+	if err != nil {
+		t.Fatalf("openRPCAuditLog: %v", err)
+	}
+	l.Record("admin", "127.0.0.1:1234", "generate",
+		btcjson.NewGenerateCmd(1), nil)
+	entries = l.Entries(1)
+	if len(entries) != 1 || entries[0].Seq != 2 ||
+		entries[0].PrevHash == zeroAuditHash {
+		t.Fatalf("unexpected entry after reopen: %+v", entries)
+	}
+	l.Close()
+
+	// Modifying an entry must be detected.
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	contents = bytes.Replace(contents, []byte(`"generate"`),
+		[]byte(`"stop"`), 1)
+	if err := ioutil.WriteFile(path, contents, 0600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if _, err := openRPCAuditLog(path, nil); err == nil {
+		t.Fatalf("openRPCAuditLog did not detect a modified entry")
+	}
+}
+
+// TestRPCAuditLogRecovery ensures large parameters are replaced by their hash
+// and that a last entry torn by a crash does not prevent opening the log.
+func TestRPCAuditLogRecovery(t *testing.T) {
+	setLogLevels("off")
+	defer setLogLevels(defaultLogLevel)
+
+	tmpDir, err := ioutil.TempDir("", "pktdaudit")
+	if err != nil {
+		t.Fatalf("Failed creating a temporary directory: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	path := filepath.Join(tmpDir, auditLogFilename)
+
+	l, err := openRPCAuditLog(path, nil)
+	if err != nil {
+		t.Fatalf("openRPCAuditLog: %v", err)
+	}
+	hexBlock := strings.Repeat("00", 4*1024*1024)
+	l.Record("admin", "127.0.0.1:1234", "submitblock",
+		btcjson.NewSubmitBlockCmd(hexBlock, nil), nil)
+	l.Record("admin", "127.0.0.1:1234", "stop", &btcjson.StopCmd{}, nil)
+	entries := l.Entries(2)
+	if len(entries) != 2 || len(entries[0].Params) > maxAuditParamsSize ||
+		!strings.Contains(entries[0].Params, `"sha256":"`) {
+
+		t.Fatalf("large parameters were not replaced: %.100q",
+			entries[0].Params)
+	}
+	l.Close()
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		contents []byte
+		want     int
+	}{
+		{"torn entry", append(append([]byte(nil), contents...),
+			contents[:20]...), 2},
+		{"missing newline", contents[:len(contents)-1], 2},
+		{"torn second entry", contents[:len(contents)-20], 1},
+	}
+	for _, test := range tests {
+		if err := ioutil.WriteFile(path, test.contents, 0600); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+		l, err := openRPCAuditLog(path, nil)
+		if err != nil {
+			t.Fatalf("%s: openRPCAuditLog: %v", test.name, err)
+		}
+		if got := len(l.Entries(10)); got != test.want {
+			t.Fatalf("%s: got %d entries, want %d", test.name, got,
+				test.want)
+		}
+
+		// New entries extend the recovered log.
+		l.Record("admin", "127.0.0.1:1234", "stop", &btcjson.StopCmd{},
+			nil)
+		l.Close()
+		l, err = openRPCAuditLog(path, nil)
+		if err != nil {
+			t.Fatalf("%s: openRPCAuditLog after recording: %v",
+				test.name, err)
+		}
+		if got := len(l.Entries(10)); got != test.want+1 {
+			t.Fatalf("%s: got %d entries after recording, want %d",
+				test.name, got, test.want+1)
+		}
+		l.Close()
+	}
+}
@@ -0,0 +1,18 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// +build darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package main
+
+import (
+	"io"
+	"log/syslog"
+)
+
+// newAuditSyslog returns a writer which exports RPC audit log entries to the
+// local syslog daemon.
+func newAuditSyslog() (io.Writer, error) {
+	return syslog.New(syslog.LOG_NOTICE|syslog.LOG_AUTH, "pktd-audit")
+}
@@ -0,0 +1,18 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package main
+
+import (
+	"errors"
+	"io"
+)
+
+// newAuditSyslog returns an error since syslog is not available on this
+// platform.
+func newAuditSyslog() (io.Writer, error) {
+	return nil, errors.New("syslog is not supported on this platform")
+}
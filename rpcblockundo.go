@@ -0,0 +1,46 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/btcjson"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+)
+
+// blockUndoResult returns the JSON result describing the passed undo data.
+func (s *rpcServer) blockUndoResult(undo *blockchain.BlockUndo) *btcjson.BlockUndoResult {
+	return &btcjson.BlockUndoResult{
+		Hash:         undo.Hash.String(),
+		PreviousHash: undo.PrevHash.String(),
+		Height:       undo.Height,
+		Created:      s.chainEventUtxos(undo.Created),
+		Spent:        s.chainEventUtxos(undo.Spent),
+	}
+}
+
+// handleGetBlockUndo implements the getblockundo command.
+func handleGetBlockUndo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetBlockUndoCmd)
+
+	hash, err := chainhash.NewHashFromStr(c.Hash)
+	if err != nil {
+		return nil, rpcDecodeHexError(c.Hash)
+	}
+
+	// The undo data of a block is only kept while it is in the main chain.
+	if !s.cfg.Chain.MainChainHasBlock(hash) {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCBlockNotFound,
+			Message: "Block not found in the main chain",
+		}
+	}
+	undo, err := s.cfg.Chain.FetchBlockUndo(hash)
+	if err != nil {
+		context := "Failed to fetch the undo data"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	return s.blockUndoResult(undo), nil
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/btcjson"
+)
+
+// TestGetBlockUndo ensures getblockundo returns the undo data of main chain
+// blocks only, and that the undo data of disconnected blocks is sent with
+// their notification.
+func TestGetBlockUndo(t *testing.T) {
+	tc, teardown := newTestChain(t, "blockundo", nil)
+	defer teardown()
+	params, chain, g := tc.params, tc.chain, tc.gen
+
+	var disconnected []*blockchain.BlockUndo
+	chain.Subscribe(func(n *blockchain.Notification) {
+		if n.Type == blockchain.NTBlockDisconnected {
+			disconnected = append(disconnected, n.Undo)
+		}
+	})
+
+	s := &rpcServer{cfg: rpcserverConfig{Chain: chain, ChainParams: params}}
+	hashes, err := g.generate(params.GenesisHash, 2, nil)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+
+	getBlockUndo := func(hash string) (*btcjson.BlockUndoResult, error) {
+		result, err := handleGetBlockUndo(s, btcjson.NewGetBlockUndoCmd(hash), nil)
+		if err != nil {
+			return nil, err
+		}
+		return result.(*btcjson.BlockUndoResult), nil
+	}
+
+	if _, err := getBlockUndo("nothex"); err == nil {
+		t.Fatalf("getblockundo: unexpected success with an invalid hash")
+	}
+	undo, err := getBlockUndo(params.GenesisHash.String())
+	if err != nil {
+		t.Fatalf("getblockundo: %v", err)
+	}
+	if len(undo.Created) != 0 || len(undo.Spent) != 0 {
+		t.Fatalf("unexpected genesis undo data %+v", undo)
+	}
+	undo, err = getBlockUndo(hashes[1].String())
+	if err != nil {
+		t.Fatalf("getblockundo: %v", err)
+	}
+	if undo.Hash != hashes[1].String() ||
+		undo.PreviousHash != hashes[0].String() || undo.Height != 2 ||
+		len(undo.Created) == 0 || len(undo.Spent) != 0 {
+
+		t.Fatalf("unexpected undo data %+v", undo)
+	}
+
+	// A longer fork disconnects both blocks, which are notified with their
+	// undo data, tip first, and no longer have undo data available.
+	if _, err := g.generate(params.GenesisHash, 3, nil); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if len(disconnected) != 2 {
+		t.Fatalf("got %d disconnected blocks, want 2", len(disconnected))
+	}
+	for i, u := range disconnected {
+		hash := hashes[len(hashes)-1-i]
+		if u == nil || u.Hash != *hash || len(u.Created) == 0 {
+			t.Fatalf("unexpected undo data %+v of disconnected "+
+				"block %v", u, hash)
+		}
+	}
+	if _, err := getBlockUndo(hashes[1].String()); err == nil {
+		t.Fatalf("getblockundo: unexpected success with a " +
+			"disconnected block")
+	}
+}
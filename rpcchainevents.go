@@ -0,0 +1,121 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/btcjson"
+	"github.com/pkt-cash/pktd/txscript"
+)
+
+// chainEventUtxos returns the JSON results of the passed outputs of a chain
+// event.
+func (s *rpcServer) chainEventUtxos(deltas []blockchain.UtxoDelta) []btcjson.ChainEventUtxo {
+	utxos := make([]btcjson.ChainEventUtxo, 0, len(deltas))
+	for i := range deltas {
+		delta := &deltas[i]
+		utxo := btcjson.ChainEventUtxo{
+			TxID:         delta.OutPoint.Hash.String(),
+			Vout:         delta.OutPoint.Index,
+			Amount:       btcutil.Amount(delta.Amount).ToBTC(),
+			ScriptPubKey: hex.EncodeToString(delta.PkScript),
+			Height:       delta.Height,
+			Coinbase:     delta.IsCoinBase,
+		}
+		_, addrs, _, _ := txscript.ExtractPkScriptAddrs(delta.PkScript,
+			s.cfg.ChainParams)
+		if len(addrs) == 1 {
+			utxo.Address = addrs[0].EncodeAddress()
+		}
+		utxos = append(utxos, utxo)
+	}
+	return utxos
+}
+
+// chainEventResult returns the JSON result of the passed chain event.
+func (s *rpcServer) chainEventResult(event *blockchain.ChainEvent) *btcjson.ChainEventResult {
+	return &btcjson.ChainEventResult{
+		Sequence:      event.Sequence,
+		Type:          event.Type.String(),
+		Hash:          event.Hash.String(),
+		PreviousHash:  event.PrevHash.String(),
+		Height:        event.Height,
+		Time:          event.Timestamp.Unix(),
+		Bits:          strconv.FormatInt(int64(event.Bits), 16),
+		ChainWork:     fmt.Sprintf("%064x", event.ChainWork),
+		Created:       s.chainEventUtxos(event.Created),
+		Spent:         s.chainEventUtxos(event.Spent),
+		FinalityDepth: event.FinalityDepth,
+		FinalHeight:   event.FinalHeight,
+	}
+}
+
+// handleChainEvents streams the blocks connected to and disconnected from the
+// main chain, along with the outputs they create and spend, to the client as
+// server-sent events until it disconnects or the server shuts down.  The events
+// are named after their type and identified by their sequence number.  When the
+// client falls too far behind, an overflow event is sent and the stream ends.
+func (s *rpcServer) handleChainEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "500 Streaming unsupported.",
+			http.StatusInternalServerError)
+		return
+	}
+	sub := s.cfg.Chain.SubscribeChainEvents(blockchain.DefaultChainEventsBuffer)
+	defer sub.Unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(tipEventsKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case event, ok := <-sub.C:
+			if !ok {
+				fmt.Fprintf(w, "event: overflow\ndata: %q\n\n",
+					sub.Err())
+				flusher.Flush()
+				return
+			}
+			data, err := json.Marshal(s.chainEventResult(event))
+			if err != nil {
+				rpcsLog.Errorf("Failed to marshal chain event: %v",
+					err)
+				return
+			}
+			_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n",
+				event.Sequence, event.Type, data)
+			if err != nil {
+				return
+			}
+			flusher.Flush()
+
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+
+		case <-r.Context().Done():
+			return
+
+		case <-s.quit:
+			return
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pkt-cash/pktd/btcjson"
+)
+
+// TestChainEvents ensures the chain event stream reports the connected and
+// disconnected blocks in order, with the outputs they create.
+func TestChainEvents(t *testing.T) {
+	tc, teardown := newTestChain(t, "chainevents", nil)
+	defer teardown()
+	params, chain, g := tc.params, tc.chain, tc.gen
+
+	s := &rpcServer{
+		cfg:  rpcserverConfig{Chain: chain, ChainParams: params},
+		quit: make(chan int),
+	}
+	defer close(s.quit)
+
+	httpServer := httptest.NewServer(http.HandlerFunc(s.handleChainEvents))
+	defer httpServer.Close()
+	resp, err := http.Get(httpServer.URL)
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	defer resp.Body.Close()
+	events := bufio.NewReader(resp.Body)
+	readEvent := func() *btcjson.ChainEventResult {
+		var name string
+		for {
+			line, err := events.ReadString('\n')
+			if err != nil {
+				t.Fatalf("ReadString: %v", err)
+			}
+			if strings.HasPrefix(line, "event: ") {
+				name = strings.TrimSpace(line[len("event: "):])
+			}
+			if !strings.HasPrefix(line, "data: ") {
+				continue
+			}
+			var event btcjson.ChainEventResult
+			err = json.Unmarshal([]byte(line[len("data: "):]), &event)
+			if err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if event.Type != name {
+				t.Fatalf("event %q has type %q", name, event.Type)
+			}
+			return &event
+		}
+	}
+
+	// The connected blocks are reported in order with their coinbase
+	// outputs.
+	hashes, err := g.generate(params.GenesisHash, 2, nil)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	for i, hash := range hashes {
+		event := readEvent()
+		if event.Type != "connected" || event.Hash != hash.String() ||
+			event.Height != int32(i+1) || event.Sequence == 0 ||
+			len(event.Created) == 0 || len(event.Spent) != 0 {
+
+			t.Fatalf("unexpected event %+v", event)
+		}
+		for _, utxo := range event.Created {
+			if !utxo.Coinbase || utxo.Height != event.Height {
+				t.Fatalf("unexpected created output %+v", utxo)
+			}
+		}
+		if event.FinalityDepth != int32(params.CoinbaseMaturity) ||
+			event.FinalHeight != 0 {
+
+			t.Fatalf("unexpected finality %+v", event)
+		}
+	}
+
+	// A reorganization disconnects the replaced block before connecting
+	// the new ones.
+	if _, err := g.simulateReorg(1, 2, nil); err != nil {
+		t.Fatalf("simulateReorg: %v", err)
+	}
+	event := readEvent()
+	if event.Type != "disconnected" || event.Hash != hashes[1].String() {
+		t.Fatalf("unexpected event %+v", event)
+	}
+	sequence := event.Sequence
+	for height := int32(2); height <= 3; height++ {
+		event := readEvent()
+		if event.Type != "connected" || event.Height != height ||
+			event.Sequence != sequence+1 {
+
+			t.Fatalf("unexpected event %+v", event)
+		}
+		sequence = event.Sequence
+	}
+	if event.PreviousHash != hashes[0].String() {
+		t.Fatalf("unexpected previous hash %v", event.PreviousHash)
+	}
+}
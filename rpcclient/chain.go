@@ -11,6 +11,7 @@ import (
 	"encoding/json"
 
 	"github.com/pkt-cash/pktd/btcjson"
+	"github.com/pkt-cash/pktd/btcutil/merkle"
 	"github.com/pkt-cash/pktd/chaincfg/chainhash"
 	"github.com/pkt-cash/pktd/wire"
 )
@@ -927,3 +928,166 @@ func (c *Client) GetCFilterHeader(blockHash *chainhash.Hash,
 	filterType wire.FilterType) (*wire.MsgCFHeaders, error) {
 	return c.GetCFilterHeaderAsync(blockHash, filterType).Receive()
 }
+
+// FutureWaitForBlockResult is a future promise to deliver the result of a
+// WaitForNewBlockAsync or WaitForBlockHeightAsync RPC invocation (or an
+// applicable error).
+type FutureWaitForBlockResult chan *response
+
+// Receive waits for the response promised by the future and returns the best
+// block along with the best block when the request was received.
+func (r FutureWaitForBlockResult) Receive() (*btcjson.WaitForBlockResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a waitforblock result object.
+	var result btcjson.WaitForBlockResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// WaitForNewBlockAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function
+// on the returned instance.
+//
+// See WaitForNewBlock for the blocking version and more details.
+func (c *Client) WaitForNewBlockAsync(timeout int64) FutureWaitForBlockResult {
+	cmd := btcjson.NewWaitForNewBlockCmd(&timeout)
+	return c.sendCmd(cmd)
+}
+
+// WaitForNewBlock waits until the best block changes or the timeout in
+// milliseconds expires and returns the best block.  A timeout of zero waits
+// without limit.
+func (c *Client) WaitForNewBlock(timeout int64) (*btcjson.WaitForBlockResult, error) {
+	return c.WaitForNewBlockAsync(timeout).Receive()
+}
+
+// WaitForBlockHeightAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See WaitForBlockHeight for the blocking version and more details.
+func (c *Client) WaitForBlockHeightAsync(height int32, timeout int64) FutureWaitForBlockResult {
+	cmd := btcjson.NewWaitForBlockHeightCmd(height, &timeout)
+	return c.sendCmd(cmd)
+}
+
+// WaitForBlockHeight waits until the best block reaches the passed height or
+// the timeout in milliseconds expires and returns the best block.  A timeout
+// of zero waits without limit.
+func (c *Client) WaitForBlockHeight(height int32, timeout int64) (*btcjson.WaitForBlockResult, error) {
+	return c.WaitForBlockHeightAsync(height, timeout).Receive()
+}
+
+// FutureGetTxOutProofResult is a future promise to deliver the result of a
+// GetTxOutProofAsync RPC invocation (or an applicable error).
+type FutureGetTxOutProofResult chan *response
+
+// Receive waits for the response promised by the future and returns the proof
+// that the requested transactions are included in a block.
+func (r FutureGetTxOutProofResult) Receive() (*wire.MsgMerkleBlock, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a string.
+	var proofHex string
+	err = json.Unmarshal(res, &proofHex)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decode the serialized proof hex to raw bytes.
+	serialized, err := hex.DecodeString(proofHex)
+	if err != nil {
+		return nil, err
+	}
+
+	return merkle.Deserialize(serialized)
+}
+
+// GetTxOutProofAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetTxOutProof for the blocking version and more details.
+func (c *Client) GetTxOutProofAsync(txHashes []*chainhash.Hash, blockHash *chainhash.Hash) FutureGetTxOutProofResult {
+	txIDs := make([]string, 0, len(txHashes))
+	for _, txHash := range txHashes {
+		txIDs = append(txIDs, txHash.String())
+	}
+	var hash *string
+	if blockHash != nil {
+		hash = btcjson.String(blockHash.String())
+	}
+	cmd := btcjson.NewGetTxOutProofCmd(txIDs, hash)
+	return c.sendCmd(cmd)
+}
+
+// GetTxOutProof returns a proof that the passed transactions are included in
+// the block with the passed hash.  The block is found with the transaction
+// index of the server when the block hash is nil.  The proof can be verified
+// without the server with merkle.ExtractMatches.
+func (c *Client) GetTxOutProof(txHashes []*chainhash.Hash, blockHash *chainhash.Hash) (*wire.MsgMerkleBlock, error) {
+	return c.GetTxOutProofAsync(txHashes, blockHash).Receive()
+}
+
+// FutureVerifyTxOutProofResult is a future promise to deliver the result of a
+// VerifyTxOutProofAsync RPC invocation (or an applicable error).
+type FutureVerifyTxOutProofResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// hashes of the transactions the proof proves.
+func (r FutureVerifyTxOutProofResult) Receive() ([]*chainhash.Hash, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as an array of strings.
+	var txIDs []string
+	err = json.Unmarshal(res, &txIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	txHashes := make([]*chainhash.Hash, 0, len(txIDs))
+	for _, txID := range txIDs {
+		txHash, err := chainhash.NewHashFromStr(txID)
+		if err != nil {
+			return nil, err
+		}
+		txHashes = append(txHashes, txHash)
+	}
+	return txHashes, nil
+}
+
+// VerifyTxOutProofAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function
+// on the returned instance.
+//
+// See VerifyTxOutProof for the blocking version and more details.
+func (c *Client) VerifyTxOutProofAsync(proof *wire.MsgMerkleBlock) FutureVerifyTxOutProofResult {
+	serialized, err := merkle.Serialize(proof)
+	if err != nil {
+		return newFutureError(err)
+	}
+	cmd := btcjson.NewVerifyTxOutProofCmd(hex.EncodeToString(serialized))
+	return c.sendCmd(cmd)
+}
+
+// VerifyTxOutProof asks the server to verify the passed proof and returns the
+// hashes of the transactions it proves.  No hashes are returned when the proof
+// does not match its block header, and an error when the block is not in the
+// main chain of the server.
+func (c *Client) VerifyTxOutProof(proof *wire.MsgMerkleBlock) ([]*chainhash.Hash, error) {
+	return c.VerifyTxOutProofAsync(proof).Receive()
+}
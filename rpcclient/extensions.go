@@ -471,3 +471,1683 @@ func (c *Client) VersionAsync() FutureVersionResult {
 func (c *Client) Version() (map[string]btcjson.VersionResult, error) {
 	return c.VersionAsync().Receive()
 }
+
+// FutureGetAuditLogResult is a future promise to deliver the result of a
+// GetAuditLogAsync RPC invocation (or an applicable error).
+type FutureGetAuditLogResult chan *response
+
+// Receive waits for the response promised by the future and returns the audit
+// log entries.
+func (r FutureGetAuditLogResult) Receive() ([]btcjson.AuditLogEntry, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as an array of audit log entries.
+	var entries []btcjson.AuditLogEntry
+	err = json.Unmarshal(res, &entries)
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// GetAuditLogAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetAuditLog for the blocking version and more details.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetAuditLogAsync(count int32) FutureGetAuditLogResult {
+	cmd := btcjson.NewGetAuditLogCmd(&count)
+	return c.sendCmd(cmd)
+}
+
+// GetAuditLog returns up to count of the most recent entries of the server's
+// RPC audit log, oldest first.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetAuditLog(count int32) ([]btcjson.AuditLogEntry, error) {
+	return c.GetAuditLogAsync(count).Receive()
+}
+
+// FutureVerifyAddressOwnershipResult is a future promise to deliver the result
+// of a VerifyAddressOwnershipAsync RPC invocation (or an applicable error).
+type FutureVerifyAddressOwnershipResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// result of verifying the proof.
+func (r FutureVerifyAddressOwnershipResult) Receive() (*btcjson.VerifyAddressOwnershipResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result btcjson.VerifyAddressOwnershipResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// VerifyAddressOwnershipAsync returns an instance of a type that can be used
+// to get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See VerifyAddressOwnership for the blocking version and more details.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) VerifyAddressOwnershipAsync(proof *wire.MsgTx, message string,
+	height *int32) FutureVerifyAddressOwnershipResult {
+
+	proofHex := ""
+	if proof != nil {
+		// Serialize the proof and convert to hex string.
+		buf := bytes.NewBuffer(make([]byte, 0, proof.SerializeSize()))
+		if err := proof.Serialize(buf); err != nil {
+			return newFutureError(err)
+		}
+		proofHex = hex.EncodeToString(buf.Bytes())
+	}
+
+	cmd := btcjson.NewVerifyAddressOwnershipCmd(proofHex, message, height)
+	return c.sendCmd(cmd)
+}
+
+// VerifyAddressOwnership verifies a proof, as created by
+// ProveAddressOwnership, that the owner of the outputs it spends can spend
+// them and that it commits to message.  The outputs must have been unspent at
+// height, or at the best block height when it is nil.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) VerifyAddressOwnership(proof *wire.MsgTx, message string,
+	height *int32) (*btcjson.VerifyAddressOwnershipResult, error) {
+
+	return c.VerifyAddressOwnershipAsync(proof, message, height).Receive()
+}
+
+// FutureGetClockSkewResult is a future promise to deliver the result of a
+// GetClockSkewAsync RPC invocation (or an applicable error).
+type FutureGetClockSkewResult chan *response
+
+// Receive waits for the response promised by the future and returns how the
+// local clock of the server compares with the median time of its peers.
+func (r FutureGetClockSkewResult) Receive() (*btcjson.GetClockSkewResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result btcjson.GetClockSkewResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetClockSkewAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See GetClockSkew for the blocking version and more details.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetClockSkewAsync() FutureGetClockSkewResult {
+	cmd := btcjson.NewGetClockSkewCmd()
+	return c.sendCmd(cmd)
+}
+
+// GetClockSkew returns how the local clock of the server compares with the
+// median time of its peers and the adjustment applied to it.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetClockSkew() (*btcjson.GetClockSkewResult, error) {
+	return c.GetClockSkewAsync().Receive()
+}
+
+// FutureGetPartitionStatusResult is a future promise to deliver the result of
+// a GetPartitionStatusAsync RPC invocation (or an applicable error).
+type FutureGetPartitionStatusResult chan *response
+
+// Receive waits for the response promised by the future and returns the state
+// of the network partition detector.
+func (r FutureGetPartitionStatusResult) Receive() (*btcjson.GetPartitionStatusResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result btcjson.GetPartitionStatusResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetPartitionStatusAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See GetPartitionStatus for the blocking version and more details.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetPartitionStatusAsync() FutureGetPartitionStatusResult {
+	cmd := btcjson.NewGetPartitionStatusCmd()
+	return c.sendCmd(cmd)
+}
+
+// GetPartitionStatus returns the state of the network partition detector of
+// the server, which compares its chain with the chains announced by its peers.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetPartitionStatus() (*btcjson.GetPartitionStatusResult, error) {
+	return c.GetPartitionStatusAsync().Receive()
+}
+
+// FutureGetMemoryInfoResult is a future promise to deliver the result of a
+// GetMemoryInfoAsync RPC invocation (or an applicable error).
+type FutureGetMemoryInfoResult chan *response
+
+// Receive waits for the response promised by the future and returns the memory
+// statistics of the server.
+func (r FutureGetMemoryInfoResult) Receive() (*btcjson.GetMemoryInfoResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result btcjson.GetMemoryInfoResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetMemoryInfoAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetMemoryInfo for the blocking version and more details.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetMemoryInfoAsync() FutureGetMemoryInfoResult {
+	cmd := btcjson.NewGetMemoryInfoCmd()
+	return c.sendCmd(cmd)
+}
+
+// GetMemoryInfo returns the Go runtime memory statistics of the server and the
+// estimated memory used by its caches and pools.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetMemoryInfo() (*btcjson.GetMemoryInfoResult, error) {
+	return c.GetMemoryInfoAsync().Receive()
+}
+
+// FutureGetDataCarrierInfoResult is a future promise to deliver the result of
+// a GetDataCarrierInfoAsync RPC invocation (or an applicable error).
+type FutureGetDataCarrierInfoResult chan *response
+
+// Receive waits for the response promised by the future and returns the data
+// carrier policy and statistics of the server.
+func (r FutureGetDataCarrierInfoResult) Receive() (*btcjson.GetDataCarrierInfoResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result btcjson.GetDataCarrierInfoResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetDataCarrierInfoAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See GetDataCarrierInfo for the blocking version and more details.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetDataCarrierInfoAsync() FutureGetDataCarrierInfoResult {
+	cmd := btcjson.NewGetDataCarrierInfoCmd()
+	return c.sendCmd(cmd)
+}
+
+// GetDataCarrierInfo returns the relay and mining policy of the transactions
+// carrying data in OP_RETURN outputs and the counts of those processed by the
+// mempool of the server by payload size class.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetDataCarrierInfo() (*btcjson.GetDataCarrierInfoResult, error) {
+	return c.GetDataCarrierInfoAsync().Receive()
+}
+
+// FutureCaptureMessagesResult is a future promise to deliver the result of a
+// CaptureMessagesAsync RPC invocation (or an applicable error).
+type FutureCaptureMessagesResult chan *response
+
+// Receive waits for the response promised by the future and returns the state
+// of the message capture.
+func (r FutureCaptureMessagesResult) Receive() (*btcjson.CaptureMessagesResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result btcjson.CaptureMessagesResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// CaptureMessagesAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function
+// on the returned instance.
+//
+// See CaptureMessages for the blocking version and more details.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) CaptureMessagesAsync(subCmd btcjson.CaptureMessagesSubCmd,
+	redact []string) FutureCaptureMessagesResult {
+
+	var redactPtr *[]string
+	if redact != nil {
+		redactPtr = &redact
+	}
+	cmd := btcjson.NewCaptureMessagesCmd(subCmd, redactPtr)
+	return c.sendCmd(cmd)
+}
+
+// CaptureMessages starts, stops or reports the capture of the P2P messages
+// exchanged by the server with its peers.  A started capture leaves out what
+// the passed redaction options name, or what the server is configured to leave
+// out when redact is nil.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) CaptureMessages(subCmd btcjson.CaptureMessagesSubCmd,
+	redact []string) (*btcjson.CaptureMessagesResult, error) {
+
+	return c.CaptureMessagesAsync(subCmd, redact).Receive()
+}
+
+// FutureListenersResult is a future promise to deliver the result of a
+// ListenersAsync RPC invocation (or an applicable error).
+type FutureListenersResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// listeners accepting peer connections.
+func (r FutureListenersResult) Receive() ([]btcjson.ListenerResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []btcjson.ListenerResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ListenersAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See Listeners for the blocking version and more details.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) ListenersAsync(subCmd btcjson.ListenersSubCmd,
+	addr string) FutureListenersResult {
+
+	var addrPtr *string
+	if addr != "" {
+		addrPtr = &addr
+	}
+	cmd := btcjson.NewListenersCmd(subCmd, addrPtr)
+	return c.sendCmd(cmd)
+}
+
+// Listeners adds or removes a listener accepting peer connections at the passed
+// address on the server, and returns its listeners.  The address is ignored
+// when listing the listeners.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) Listeners(subCmd btcjson.ListenersSubCmd,
+	addr string) ([]btcjson.ListenerResult, error) {
+
+	return c.ListenersAsync(subCmd, addr).Receive()
+}
+
+// FutureCaptureProfileResult is a future promise to deliver the result of a
+// CaptureProfileAsync RPC invocation (or an applicable error).
+type FutureCaptureProfileResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// captured profile.
+func (r FutureCaptureProfileResult) Receive() (*btcjson.CaptureProfileResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result btcjson.CaptureProfileResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// CaptureProfileAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function
+// on the returned instance.
+//
+// See CaptureProfile for the blocking version and more details.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) CaptureProfileAsync(profile string, seconds int32,
+	file string) FutureCaptureProfileResult {
+
+	var filePtr *string
+	if file != "" {
+		filePtr = &file
+	}
+	cmd := btcjson.NewCaptureProfileCmd(profile, &seconds, filePtr)
+	return c.sendCmd(cmd)
+}
+
+// CaptureProfile captures the passed runtime profile of the server, recording
+// it for the passed number of seconds for the cpu profile.  The profile is
+// written to the passed file of the profiles directory of the server, or
+// returned base64-encoded when file is empty.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) CaptureProfile(profile string, seconds int32,
+	file string) (*btcjson.CaptureProfileResult, error) {
+
+	return c.CaptureProfileAsync(profile, seconds, file).Receive()
+}
+
+// FutureTriggerGCResult is a future promise to deliver the result of a
+// TriggerGCAsync RPC invocation (or an applicable error).
+type FutureTriggerGCResult chan *response
+
+// Receive waits for the response promised by the future and returns the heap
+// statistics of the garbage collection.
+func (r FutureTriggerGCResult) Receive() (*btcjson.TriggerGCResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result btcjson.TriggerGCResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// TriggerGCAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See TriggerGC for the blocking version and more details.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) TriggerGCAsync(freeOSMemory bool) FutureTriggerGCResult {
+	cmd := btcjson.NewTriggerGCCmd(&freeOSMemory)
+	return c.sendCmd(cmd)
+}
+
+// TriggerGC runs a garbage collection on the server, also returning as much
+// memory as possible to the operating system when freeOSMemory is set.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) TriggerGC(freeOSMemory bool) (*btcjson.TriggerGCResult, error) {
+	return c.TriggerGCAsync(freeOSMemory).Receive()
+}
+
+// FutureGetBlockCostResult is a future promise to deliver the result of a
+// GetBlockCostAsync RPC invocation (or an applicable error).
+type FutureGetBlockCostResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// consensus accounting of the block.
+func (r FutureGetBlockCostResult) Receive() (*btcjson.GetBlockCostResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result btcjson.GetBlockCostResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetBlockCostAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetBlockCost for the blocking version and more details.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetBlockCostAsync(block *string) FutureGetBlockCostResult {
+	cmd := btcjson.NewGetBlockCostCmd(block)
+	return c.sendCmd(cmd)
+}
+
+// GetBlockCost returns the sizes, weight and signature operation cost of a
+// block and of each of its transactions.  The block is either the hash of a
+// main chain block or a hex-encoded serialized block extending the best block,
+// and the current block template of the server is accounted for when it is
+// nil.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetBlockCost(block *string) (*btcjson.GetBlockCostResult, error) {
+	return c.GetBlockCostAsync(block).Receive()
+}
+
+// FutureGetBlockTemplateLightResult is a future promise to deliver the result
+// of a GetBlockTemplateLightAsync RPC invocation (or an applicable error).
+type FutureGetBlockTemplateLightResult chan *response
+
+// Receive waits for the response promised by the future and returns the block
+// template.
+func (r FutureGetBlockTemplateLightResult) Receive() (*btcjson.GetBlockTemplateLightResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result btcjson.GetBlockTemplateLightResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetBlockTemplateLightAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See GetBlockTemplateLight for the blocking version and more details.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetBlockTemplateLightAsync(longPollID *string) FutureGetBlockTemplateLightResult {
+	cmd := btcjson.NewGetBlockTemplateLightCmd(longPollID)
+	return c.sendCmd(cmd)
+}
+
+// GetBlockTemplateLight returns the block template of the server without the
+// data of the transactions which are already known.  When the long poll ID of
+// a previous block template is passed, it waits for that template to become
+// stale and only returns the transactions which were not in it.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetBlockTemplateLight(longPollID *string) (*btcjson.GetBlockTemplateLightResult, error) {
+	return c.GetBlockTemplateLightAsync(longPollID).Receive()
+}
+
+// FutureGetTxFeeResult is a future promise to deliver the result of a
+// GetTxFeeAsync RPC invocation (or an applicable error).
+type FutureGetTxFeeResult chan *response
+
+// Receive waits for the response promised by the future and returns the fee
+// paid by the transaction.
+func (r FutureGetTxFeeResult) Receive() (*btcjson.GetTxFeeResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result btcjson.GetTxFeeResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetTxFeeAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetTxFee for the blocking version and more details.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetTxFeeAsync(txHash *chainhash.Hash) FutureGetTxFeeResult {
+	hash := ""
+	if txHash != nil {
+		hash = txHash.String()
+	}
+
+	cmd := btcjson.NewGetTxFeeCmd(hash)
+	return c.sendCmd(cmd)
+}
+
+// GetTxFee returns the exact fee paid by a transaction of the memory pool or
+// of the main chain, including transactions spending outputs the caller does
+// not know about.  Confirmed transactions require the server to have the
+// transaction index enabled.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetTxFee(txHash *chainhash.Hash) (*btcjson.GetTxFeeResult, error) {
+	return c.GetTxFeeAsync(txHash).Receive()
+}
+
+// FutureGetUtxoDeltasResult is a future promise to deliver the result of a
+// GetUtxoDeltasAsync RPC invocation (or an applicable error).
+type FutureGetUtxoDeltasResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// outputs created and spent by each block.
+func (r FutureGetUtxoDeltasResult) Receive() ([]btcjson.UtxoDeltasResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []btcjson.UtxoDeltasResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetUtxoDeltasAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetUtxoDeltas for the blocking version and more details.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetUtxoDeltasAsync(startHeight int32, count *int32) FutureGetUtxoDeltasResult {
+	cmd := btcjson.NewGetUtxoDeltasCmd(startHeight, count)
+	return c.sendCmd(cmd)
+}
+
+// GetUtxoDeltas returns the outputs created and spent by up to count blocks of
+// the main chain from the passed height, 100 when count is nil.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetUtxoDeltas(startHeight int32, count *int32) ([]btcjson.UtxoDeltasResult, error) {
+	return c.GetUtxoDeltasAsync(startHeight, count).Receive()
+}
+
+// decodeBlockHeaders deserializes the passed hex-encoded concatenated block
+// headers.
+func decodeBlockHeaders(headersHex string) ([]wire.BlockHeader, error) {
+	serialized, err := hex.DecodeString(headersHex)
+	if err != nil {
+		return nil, err
+	}
+	if len(serialized)%wire.MaxBlockHeaderPayload != 0 {
+		return nil, fmt.Errorf("serialized block headers of %d bytes "+
+			"are not a multiple of %d bytes", len(serialized),
+			wire.MaxBlockHeaderPayload)
+	}
+
+	headers := make([]wire.BlockHeader, len(serialized)/wire.MaxBlockHeaderPayload)
+	r := bytes.NewReader(serialized)
+	for i := range headers {
+		if err := headers[i].Deserialize(r); err != nil {
+			return nil, err
+		}
+	}
+	return headers, nil
+}
+
+// FutureGetBlockHeadersResult is a future promise to deliver the result of a
+// GetBlockHeadersAsync RPC invocation (or an applicable error).
+type FutureGetBlockHeadersResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// block headers.
+func (r FutureGetBlockHeadersResult) Receive() ([]wire.BlockHeader, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result btcjson.BlockHeadersResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeBlockHeaders(result.Hex)
+}
+
+// GetBlockHeadersAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function
+// on the returned instance.
+//
+// See GetBlockHeaders for the blocking version and more details.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetBlockHeadersAsync(startHeight int32, count *int32) FutureGetBlockHeadersResult {
+	cmd := btcjson.NewGetBlockHeadersCmd(startHeight, count, btcjson.Bool(false))
+	return c.sendCmd(cmd)
+}
+
+// GetBlockHeaders returns up to count contiguous headers of the main chain
+// from the passed height, 2000 when count is nil.
+//
+// See GetBlockHeadersVerbose to retrieve data structures with information
+// about the headers instead.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetBlockHeaders(startHeight int32, count *int32) ([]wire.BlockHeader, error) {
+	return c.GetBlockHeadersAsync(startHeight, count).Receive()
+}
+
+// FutureGetBlockHeadersVerboseResult is a future promise to deliver the
+// result of a GetBlockHeadersVerboseAsync RPC invocation (or an applicable
+// error).
+type FutureGetBlockHeadersVerboseResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// data structures describing the block headers.
+func (r FutureGetBlockHeadersVerboseResult) Receive() (*btcjson.BlockHeadersResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result btcjson.BlockHeadersResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetBlockHeadersVerboseAsync returns an instance of a type that can be used
+// to get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See GetBlockHeadersVerbose for the blocking version and more details.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetBlockHeadersVerboseAsync(startHeight int32, count *int32) FutureGetBlockHeadersVerboseResult {
+	cmd := btcjson.NewGetBlockHeadersCmd(startHeight, count, btcjson.Bool(true))
+	return c.sendCmd(cmd)
+}
+
+// GetBlockHeadersVerbose returns data structures describing up to count
+// contiguous headers of the main chain from the passed height, 2000 when
+// count is nil.
+//
+// See GetBlockHeaders to retrieve the headers instead.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetBlockHeadersVerbose(startHeight int32, count *int32) (*btcjson.BlockHeadersResult, error) {
+	return c.GetBlockHeadersVerboseAsync(startHeight, count).Receive()
+}
+
+// FutureGetNetworkHashrateResult is a future promise to deliver the result of
+// a GetNetworkHashrateAsync RPC invocation (or an applicable error).
+type FutureGetNetworkHashrateResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// estimated hash rates of the network.
+func (r FutureGetNetworkHashrateResult) Receive() (*btcjson.GetNetworkHashrateResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result btcjson.GetNetworkHashrateResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetNetworkHashrateAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See GetNetworkHashrate for the blocking version and more details.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetNetworkHashrateAsync(blocks, height *int32) FutureGetNetworkHashrateResult {
+	cmd := btcjson.NewGetNetworkHashrateCmd(blocks, height)
+	return c.sendCmd(cmd)
+}
+
+// GetNetworkHashrate returns the PacketCrypt block mining and announcement
+// hash rates of the network estimated over the passed number of blocks ending
+// at the passed height.  Nil uses 120 blocks ending at the best block.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetNetworkHashrate(blocks, height *int32) (*btcjson.GetNetworkHashrateResult, error) {
+	return c.GetNetworkHashrateAsync(blocks, height).Receive()
+}
+
+// FutureGetAnnAgingScheduleResult is a future promise to deliver the result
+// of a GetAnnAgingScheduleAsync RPC invocation (or an applicable error).
+type FutureGetAnnAgingScheduleResult chan *response
+
+// Receive waits for the response promised by the future and returns the aging
+// schedule of the announcement.
+func (r FutureGetAnnAgingScheduleResult) Receive() (*btcjson.GetAnnAgingScheduleResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result btcjson.GetAnnAgingScheduleResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetAnnAgingScheduleAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See GetAnnAgingSchedule for the blocking version and more details.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetAnnAgingScheduleAsync(target *string, maxAge *int32) FutureGetAnnAgingScheduleResult {
+	cmd := btcjson.NewGetAnnAgingScheduleCmd(target, maxAge)
+	return c.sendCmd(cmd)
+}
+
+// GetAnnAgingSchedule returns the aged targets for each age up to maxAge of an
+// announcement mined at the passed compact target in hex, when mined in the
+// block following the best block.  Nil uses the minimum announcement target of
+// the best block and ages up to 20.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetAnnAgingSchedule(target *string, maxAge *int32) (*btcjson.GetAnnAgingScheduleResult, error) {
+	return c.GetAnnAgingScheduleAsync(target, maxAge).Receive()
+}
+
+// FutureGetAnnProofResult is a future promise to deliver the result of a
+// GetAnnProofAsync RPC invocation (or an applicable error).
+type FutureGetAnnProofResult chan *response
+
+// Receive waits for the response promised by the future and returns the proof
+// of inclusion of the announcement.
+func (r FutureGetAnnProofResult) Receive() (*btcjson.GetAnnProofResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result btcjson.GetAnnProofResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetAnnProofAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See GetAnnProof for the blocking version and more details.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetAnnProofAsync(blockHash *chainhash.Hash, position int32) FutureGetAnnProofResult {
+	hash := ""
+	if blockHash != nil {
+		hash = blockHash.String()
+	}
+
+	cmd := btcjson.NewGetAnnProofCmd(hash, position)
+	return c.sendCmd(cmd)
+}
+
+// GetAnnProof returns a proof that the announcement at the passed position,
+// from 0 to 3, of the PacketCrypt proof of the passed main chain block is
+// included in the announcement tree committed to by the block.  The proof can
+// be verified with the AnnProof type of the spv package.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetAnnProof(blockHash *chainhash.Hash, position int32) (*btcjson.GetAnnProofResult, error) {
+	return c.GetAnnProofAsync(blockHash, position).Receive()
+}
+
+// FutureGetConsensusRulesResult is a future promise to deliver the result of a
+// GetConsensusRulesAsync RPC invocation (or an applicable error).
+type FutureGetConsensusRulesResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// consensus rules in effect at the height.
+func (r FutureGetConsensusRulesResult) Receive() (*btcjson.GetConsensusRulesResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result btcjson.GetConsensusRulesResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetConsensusRulesAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetConsensusRules for the blocking version and more details.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetConsensusRulesAsync(height *int32) FutureGetConsensusRulesResult {
+	cmd := btcjson.NewGetConsensusRulesCmd(height)
+	return c.sendCmd(cmd)
+}
+
+// GetConsensusRules returns the consensus rules in effect for the main chain
+// block at the passed height.  Nil returns the rules of the block following
+// the best block.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetConsensusRules(height *int32) (*btcjson.GetConsensusRulesResult, error) {
+	return c.GetConsensusRulesAsync(height).Receive()
+}
+
+// FutureGetMiningAnalyticsResult is a future promise to deliver the result of
+// a GetMiningAnalyticsAsync RPC invocation (or an applicable error).
+type FutureGetMiningAnalyticsResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// summary of the work of the blocks.
+func (r FutureGetMiningAnalyticsResult) Receive() (*btcjson.GetMiningAnalyticsResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result btcjson.GetMiningAnalyticsResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetMiningAnalyticsAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See GetMiningAnalytics for the blocking version and more details.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetMiningAnalyticsAsync(blocks, height *int32) FutureGetMiningAnalyticsResult {
+	cmd := btcjson.NewGetMiningAnalyticsCmd(blocks, height)
+	return c.sendCmd(cmd)
+}
+
+// GetMiningAnalytics returns how the announcement and block mining work
+// composed the effective targets of the passed number of blocks ending at the
+// passed height.  Nil uses 120 blocks ending at the best block.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetMiningAnalytics(blocks, height *int32) (*btcjson.GetMiningAnalyticsResult, error) {
+	return c.GetMiningAnalyticsAsync(blocks, height).Receive()
+}
+
+// FutureGetDifficultyHistoryResult is a future promise to deliver the result
+// of a GetDifficultyHistoryAsync RPC invocation (or an applicable error).
+type FutureGetDifficultyHistoryResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// difficulties of the blocks in height order.
+func (r FutureGetDifficultyHistoryResult) Receive() ([]btcjson.DifficultyHistoryResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []btcjson.DifficultyHistoryResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetDifficultyHistoryAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See GetDifficultyHistory for the blocking version and more details.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetDifficultyHistoryAsync(count, height, interval *int32) FutureGetDifficultyHistoryResult {
+	cmd := btcjson.NewGetDifficultyHistoryCmd(count, height, interval)
+	return c.sendCmd(cmd)
+}
+
+// GetDifficultyHistory returns the block and announcement difficulties of up
+// to count blocks, every interval blocks back from the passed height.  Nil
+// uses 100 consecutive blocks ending at the best block.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetDifficultyHistory(count, height, interval *int32) ([]btcjson.DifficultyHistoryResult, error) {
+	return c.GetDifficultyHistoryAsync(count, height, interval).Receive()
+}
+
+// FutureGetUtxoStatsResult is a future promise to deliver the result of a
+// GetUtxoStatsAsync RPC invocation (or an applicable error).
+type FutureGetUtxoStatsResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// statistics of the unspent transaction outputs.
+func (r FutureGetUtxoStatsResult) Receive() (*btcjson.GetUtxoStatsResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result btcjson.GetUtxoStatsResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetUtxoStatsAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetUtxoStats for the blocking version and more details.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetUtxoStatsAsync(height *int32) FutureGetUtxoStatsResult {
+	cmd := btcjson.NewGetUtxoStatsCmd(height)
+	return c.sendCmd(cmd)
+}
+
+// GetUtxoStats returns the statistics of the unspent transaction outputs by
+// script class at the best block, or at the latest snapshot at or below the
+// passed height when it is not nil.  The server must maintain the utxo
+// statistics index.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetUtxoStats(height *int32) (*btcjson.GetUtxoStatsResult, error) {
+	return c.GetUtxoStatsAsync(height).Receive()
+}
+
+// FutureGetFeeHistoryResult is a future promise to deliver the result of a
+// GetFeeHistoryAsync RPC invocation (or an applicable error).
+type FutureGetFeeHistoryResult chan *response
+
+// Receive waits for the response promised by the future and returns the fee
+// statistics of the blocks.
+func (r FutureGetFeeHistoryResult) Receive() (*btcjson.GetFeeHistoryResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result btcjson.GetFeeHistoryResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetFeeHistoryAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function
+// on the returned instance.
+//
+// See GetFeeHistory for the blocking version and more details.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetFeeHistoryAsync(start int64, end *int64, byTime bool) FutureGetFeeHistoryResult {
+	cmd := btcjson.NewGetFeeHistoryCmd(start, end, &byTime)
+	return c.sendCmd(cmd)
+}
+
+// GetFeeHistory returns the fees paid by the transactions of the main chain
+// blocks from the start height to the end height, or from the start timestamp
+// to the end timestamp when byTime is set.  The range ends at the best block
+// when end is nil.  The server must maintain the fee statistics index.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetFeeHistory(start int64, end *int64, byTime bool) (*btcjson.GetFeeHistoryResult, error) {
+	return c.GetFeeHistoryAsync(start, end, byTime).Receive()
+}
+
+// FutureGetOrphanBlocksResult is a future promise to deliver the result of a
+// GetOrphanBlocksAsync RPC invocation (or an applicable error).
+type FutureGetOrphanBlocksResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// descriptions of the recently seen blocks outside of the main chain.
+func (r FutureGetOrphanBlocksResult) Receive() ([]btcjson.OrphanBlockResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []btcjson.OrphanBlockResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetOrphanBlocksAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function
+// on the returned instance.
+//
+// See GetOrphanBlocks for the blocking version and more details.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetOrphanBlocksAsync() FutureGetOrphanBlocksResult {
+	cmd := btcjson.NewGetOrphanBlocksCmd()
+	return c.sendCmd(cmd)
+}
+
+// GetOrphanBlocks returns the recently seen blocks which are not part of the
+// main chain, newest first, such as the orphans and the blocks of losing side
+// chains, along with their fork points and the blocks building on them.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetOrphanBlocks() ([]btcjson.OrphanBlockResult, error) {
+	return c.GetOrphanBlocksAsync().Receive()
+}
+
+// FutureGetOrphanTxsResult is a future promise to deliver the result of a
+// GetOrphanTxsAsync RPC invocation (or an applicable error).
+type FutureGetOrphanTxsResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// hashes of the transactions in the orphan pool.
+func (r FutureGetOrphanTxsResult) Receive() ([]*chainhash.Hash, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var txHashStrs []string
+	err = json.Unmarshal(res, &txHashStrs)
+	if err != nil {
+		return nil, err
+	}
+
+	txHashes := make([]*chainhash.Hash, 0, len(txHashStrs))
+	for _, hashStr := range txHashStrs {
+		txHash, err := chainhash.NewHashFromStr(hashStr)
+		if err != nil {
+			return nil, err
+		}
+		txHashes = append(txHashes, txHash)
+	}
+
+	return txHashes, nil
+}
+
+// GetOrphanTxsAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetOrphanTxs for the blocking version and more details.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetOrphanTxsAsync() FutureGetOrphanTxsResult {
+	cmd := btcjson.NewGetOrphanTxsCmd(btcjson.Int(0))
+	return c.sendCmd(cmd)
+}
+
+// GetOrphanTxs returns the hashes of the transactions in the orphan pool,
+// ordered by expiration.
+//
+// See GetOrphanTxsVerbose to retrieve descriptions of the orphans instead.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetOrphanTxs() ([]*chainhash.Hash, error) {
+	return c.GetOrphanTxsAsync().Receive()
+}
+
+// FutureGetOrphanTxsVerboseResult is a future promise to deliver the result of
+// a GetOrphanTxsVerboseAsync RPC invocation (or an applicable error).
+type FutureGetOrphanTxsVerboseResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// descriptions of the transactions in the orphan pool.
+func (r FutureGetOrphanTxsVerboseResult) Receive() ([]btcjson.OrphanTxResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []btcjson.OrphanTxResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetOrphanTxsVerboseAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See GetOrphanTxsVerbose for the blocking version and more details.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetOrphanTxsVerboseAsync(includeHex bool) FutureGetOrphanTxsVerboseResult {
+	verbosity := 1
+	if includeHex {
+		verbosity = 2
+	}
+	cmd := btcjson.NewGetOrphanTxsCmd(&verbosity)
+	return c.sendCmd(cmd)
+}
+
+// GetOrphanTxsVerbose returns descriptions of the transactions in the orphan
+// pool, ordered by expiration, including the serialized transactions when
+// includeHex is set.
+//
+// See GetOrphanTxs to retrieve only the transaction hashes instead.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetOrphanTxsVerbose(includeHex bool) ([]btcjson.OrphanTxResult, error) {
+	return c.GetOrphanTxsVerboseAsync(includeHex).Receive()
+}
+
+// FutureGetRejectedTxsResult is a future promise to deliver the result of a
+// GetRejectedTxsAsync RPC invocation (or an applicable error).
+type FutureGetRejectedTxsResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// recently rejected transactions.
+func (r FutureGetRejectedTxsResult) Receive() ([]btcjson.RejectedTxResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []btcjson.RejectedTxResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetRejectedTxsAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function
+// on the returned instance.
+//
+// See GetRejectedTxs for the blocking version and more details.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetRejectedTxsAsync() FutureGetRejectedTxsResult {
+	cmd := btcjson.NewGetRejectedTxsCmd()
+	return c.sendCmd(cmd)
+}
+
+// GetRejectedTxs returns the transactions relayed by peers which were recently
+// rejected, most recent first, with the reasons they were rejected.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetRejectedTxs() ([]btcjson.RejectedTxResult, error) {
+	return c.GetRejectedTxsAsync().Receive()
+}
+
+// FutureGetMempoolDeltaResult is a future promise to deliver the result of a
+// GetMempoolDeltaAsync RPC invocation (or an applicable error).
+type FutureGetMempoolDeltaResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// changes of the mempool.
+func (r FutureGetMempoolDeltaResult) Receive() (*btcjson.GetMempoolDeltaResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result btcjson.GetMempoolDeltaResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetMempoolDeltaAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function
+// on the returned instance.
+//
+// See GetMempoolDelta for the blocking version and more details.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetMempoolDeltaAsync(sequence uint64) FutureGetMempoolDeltaResult {
+	cmd := btcjson.NewGetMempoolDeltaCmd(&sequence)
+	return c.sendCmd(cmd)
+}
+
+// GetMempoolDelta returns the transactions added to and removed from the
+// mempool since the state with the passed sequence number, as returned by the
+// previous call.  The whole mempool is returned, with Reset set, when the
+// sequence number is 0 or the changes since it are no longer known.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetMempoolDelta(sequence uint64) (*btcjson.GetMempoolDeltaResult, error) {
+	return c.GetMempoolDeltaAsync(sequence).Receive()
+}
+
+// FutureCreateDepositAddressesResult is a future promise to deliver the result
+// of a CreateDepositAddressesAsync RPC invocation (or an applicable error).
+type FutureCreateDepositAddressesResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// deposit addresses bound to the reference IDs.
+func (r FutureCreateDepositAddressesResult) Receive() ([]btcjson.DepositAddressResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []btcjson.DepositAddressResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// CreateDepositAddressesAsync returns an instance of a type that can be used
+// to get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See CreateDepositAddresses for the blocking version and more details.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) CreateDepositAddressesAsync(refs []string) FutureCreateDepositAddressesResult {
+	cmd := btcjson.NewCreateDepositAddressesCmd(refs)
+	return c.sendCmd(cmd)
+}
+
+// CreateDepositAddresses returns the deposit addresses bound to the passed
+// reference IDs, in the same order, binding the next unused addresses of the
+// deposit account to the new ones.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) CreateDepositAddresses(refs []string) ([]btcjson.DepositAddressResult, error) {
+	return c.CreateDepositAddressesAsync(refs).Receive()
+}
+
+// FutureListDepositsResult is a future promise to deliver the result of a
+// ListDepositsAsync RPC invocation (or an applicable error).
+type FutureListDepositsResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// deposits.
+func (r FutureListDepositsResult) Receive() ([]btcjson.DepositResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []btcjson.DepositResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ListDepositsAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See ListDeposits for the blocking version and more details.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) ListDepositsAsync(ref string) FutureListDepositsResult {
+	var refPtr *string
+	if ref != "" {
+		refPtr = &ref
+	}
+	cmd := btcjson.NewListDepositsCmd(refPtr)
+	return c.sendCmd(cmd)
+}
+
+// ListDeposits returns the deposits to the address bound to the passed
+// reference ID, or to every deposit address when it is empty, in the order
+// they were mined.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) ListDeposits(ref string) ([]btcjson.DepositResult, error) {
+	return c.ListDepositsAsync(ref).Receive()
+}
+
+// FutureGetColdSpendsResult is a future promise to deliver the result of a
+// GetColdSpendsAsync RPC invocation (or an applicable error).
+type FutureGetColdSpendsResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// spends from the watched cold storage addresses.
+func (r FutureGetColdSpendsResult) Receive() (*btcjson.GetColdSpendsResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result btcjson.GetColdSpendsResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetColdSpendsAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetColdSpends for the blocking version and more details.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetColdSpendsAsync(acknowledge bool) FutureGetColdSpendsResult {
+	cmd := btcjson.NewGetColdSpendsCmd(&acknowledge)
+	return c.sendCmd(cmd)
+}
+
+// GetColdSpends returns the transactions seen spending from the cold storage
+// addresses watched by the node and whether the alarm they raised is still
+// up, clearing it when acknowledge is true.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetColdSpends(acknowledge bool) (*btcjson.GetColdSpendsResult, error) {
+	return c.GetColdSpendsAsync(acknowledge).Receive()
+}
+
+// FutureGetSyncStatusResult is a future promise to deliver the result of a
+// GetSyncStatusAsync RPC invocation (or an applicable error).
+type FutureGetSyncStatusResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// progress of the sync with the network.
+func (r FutureGetSyncStatusResult) Receive() (*btcjson.GetSyncStatusResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result btcjson.GetSyncStatusResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetSyncStatusAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetSyncStatus for the blocking version and more details.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetSyncStatusAsync() FutureGetSyncStatusResult {
+	cmd := btcjson.NewGetSyncStatusCmd()
+	return c.sendCmd(cmd)
+}
+
+// GetSyncStatus returns the progress of the sync with the network, with an
+// estimate of the time remaining, the time spent in each stage and the
+// bandwidth used.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetSyncStatus() (*btcjson.GetSyncStatusResult, error) {
+	return c.GetSyncStatusAsync().Receive()
+}
+
+// FutureGetBlockUndoResult is a future promise to deliver the result of a
+// GetBlockUndoAsync RPC invocation (or an applicable error).
+type FutureGetBlockUndoResult chan *response
+
+// Receive waits for the response promised by the future and returns the undo
+// data of the block.
+func (r FutureGetBlockUndoResult) Receive() (*btcjson.BlockUndoResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result btcjson.BlockUndoResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetBlockUndoAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetBlockUndo for the blocking version and more details.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetBlockUndoAsync(blockHash *chainhash.Hash) FutureGetBlockUndoResult {
+	hash := ""
+	if blockHash != nil {
+		hash = blockHash.String()
+	}
+
+	cmd := btcjson.NewGetBlockUndoCmd(hash)
+	return c.sendCmd(cmd)
+}
+
+// GetBlockUndo returns the undo data of a block of the main chain, the outputs
+// to remove from and restore to the UTXO set to disconnect it.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetBlockUndo(blockHash *chainhash.Hash) (*btcjson.BlockUndoResult, error) {
+	return c.GetBlockUndoAsync(blockHash).Receive()
+}
+
+// FutureGetDiskStatusResult is a future promise to deliver the result of a
+// GetDiskStatusAsync RPC invocation (or an applicable error).
+type FutureGetDiskStatusResult chan *response
+
+// Receive waits for the response promised by the future and returns the free
+// space on the data directory of the node.
+func (r FutureGetDiskStatusResult) Receive() (*btcjson.GetDiskStatusResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result btcjson.GetDiskStatusResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetDiskStatusAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetDiskStatus for the blocking version and more details.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetDiskStatusAsync() FutureGetDiskStatusResult {
+	cmd := btcjson.NewGetDiskStatusCmd()
+	return c.sendCmd(cmd)
+}
+
+// GetDiskStatus returns the free space on the data directory of the node and
+// whether it is in safe mode because it runs out of it.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) GetDiskStatus() (*btcjson.GetDiskStatusResult, error) {
+	return c.GetDiskStatusAsync().Receive()
+}
+
+// FutureMintTokenResult is a future promise to deliver the result of a
+// MintTokenAsync RPC invocation (or an applicable error).
+type FutureMintTokenResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// minted token.
+func (r FutureMintTokenResult) Receive() (*btcjson.MintTokenResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a minttoken result object.
+	var result btcjson.MintTokenResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// MintTokenAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See MintToken for the blocking version and more details.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) MintTokenAsync(scope string, expiry int64, maxSend *float64,
+	methods []string) FutureMintTokenResult {
+
+	var methodsParam *[]string
+	if methods != nil {
+		methodsParam = &methods
+	}
+	cmd := btcjson.NewMintTokenCmd(scope, &expiry, maxSend, methodsParam)
+	return c.sendCmd(cmd)
+}
+
+// MintToken mints a capability token with the passed scope, valid for expiry
+// seconds or forever when it is 0, which may be used as the Token of the
+// connection configuration of another client.  The maximum total output value
+// of the transactions it may relay and the commands it allows may also be
+// restricted, or left unrestricted by passing nil.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) MintToken(scope string, expiry int64, maxSend *float64,
+	methods []string) (*btcjson.MintTokenResult, error) {
+
+	return c.MintTokenAsync(scope, expiry, maxSend, methods).Receive()
+}
+
+// FutureSetBlockLimitsResult is a future promise to deliver the result of a
+// SetBlockLimitsAsync RPC invocation (or an applicable error).
+type FutureSetBlockLimitsResult chan *response
+
+// Receive waits for the response promised by the future and returns the limits
+// used to assemble block templates after the change.
+func (r FutureSetBlockLimitsResult) Receive() (*btcjson.BlockLimitsResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result btcjson.BlockLimitsResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// SetBlockLimitsAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function
+// on the returned instance.
+//
+// See SetBlockLimits for the blocking version and more details.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) SetBlockLimitsAsync(maxWeight *uint32, maxSigOpCost *int64,
+	reservedWeight, prioritySize *uint32) FutureSetBlockLimitsResult {
+
+	cmd := btcjson.NewSetBlockLimitsCmd(&btcjson.BlockLimits{
+		MaxWeight:      maxWeight,
+		MaxSigOpCost:   maxSigOpCost,
+		ReservedWeight: reservedWeight,
+		PrioritySize:   prioritySize,
+	})
+	return c.sendCmd(cmd)
+}
+
+// SetBlockLimits changes the limits the server uses to assemble block
+// templates.  The limits passed as nil are left unchanged, being omitted from
+// the limits object sent to the server.
+//
+// NOTE: This is a pktd extension.
+func (c *Client) SetBlockLimits(maxWeight *uint32, maxSigOpCost *int64,
+	reservedWeight, prioritySize *uint32) (*btcjson.BlockLimitsResult, error) {
+
+	return c.SetBlockLimitsAsync(maxWeight, maxSigOpCost, reservedWeight,
+		prioritySize).Receive()
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rpcclient
+
+import (
+	"testing"
+
+	"github.com/pkt-cash/pktd/btcjson"
+)
+
+// TestSetBlockLimits ensures the limits passed are sent whichever of the
+// limits preceding them are left nil.
+func TestSetBlockLimits(t *testing.T) {
+	tests := []struct {
+		name           string
+		maxWeight      *uint32
+		maxSigOpCost   *int64
+		reservedWeight *uint32
+		prioritySize   *uint32
+		want           string
+	}{
+		{
+			name: "no limits",
+			want: `[{}]`,
+		},
+		{
+			name:         "nil max weight",
+			prioritySize: btcjson.Uint32(0),
+			want:         `[{"prioritysize":0}]`,
+		},
+		{
+			name:           "all limits",
+			maxWeight:      btcjson.Uint32(2000000),
+			maxSigOpCost:   btcjson.Int64(40000),
+			reservedWeight: btcjson.Uint32(100000),
+			prioritySize:   btcjson.Uint32(50000),
+			want: `[{"maxweight":2000000,"maxsigopcost":40000,` +
+				`"reservedweight":100000,"prioritysize":50000}]`,
+		},
+	}
+	for _, test := range tests {
+		params := sentParams(t, func(c *Client) {
+			c.SetBlockLimitsAsync(test.maxWeight, test.maxSigOpCost,
+				test.reservedWeight, test.prioritySize)
+		})
+		if params != test.want {
+			t.Errorf("%s: sent %s, want %s", test.name, params,
+				test.want)
+		}
+	}
+}
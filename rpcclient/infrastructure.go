@@ -241,6 +241,9 @@ func (c *Client) trackRegisteredNtfns(cmd interface{}) {
 	case *btcjson.NotifyBlocksCmd:
 		c.ntfnState.notifyBlocks = true
 
+	case *btcjson.NotifyUtxoDeltasCmd:
+		c.ntfnState.notifyUtxoDeltas = true
+
 	case *btcjson.NotifyNewTransactionsCmd:
 		if bcmd.Verbose != nil && *bcmd.Verbose {
 			c.ntfnState.notifyNewTxVerbose = true
@@ -258,6 +261,9 @@ func (c *Client) trackRegisteredNtfns(cmd interface{}) {
 		for _, addr := range bcmd.Addresses {
 			c.ntfnState.notifyReceived[addr] = struct{}{}
 		}
+
+	case *btcjson.NotifyConfirmationsCmd:
+		c.ntfnState.notifyConfs = bcmd.Thresholds
 	}
 }
 
@@ -513,6 +519,14 @@ func (c *Client) reregisterNtfns() error {
 		}
 	}
 
+	// Reregister notifyutxodeltas if needed.
+	if stateCopy.notifyUtxoDeltas {
+		log.Debugf("Reregistering [notifyutxodeltas]")
+		if err := c.NotifyUtxoDeltas(); err != nil {
+			return err
+		}
+	}
+
 	// Reregister notifynewtransactions if needed.
 	if stateCopy.notifyNewTx || stateCopy.notifyNewTxVerbose {
 		log.Debugf("Reregistering [notifynewtransactions] (verbose=%v)",
@@ -551,6 +565,15 @@ func (c *Client) reregisterNtfns() error {
 		}
 	}
 
+	// Reregister notifyconfirmations if needed.
+	if len(stateCopy.notifyConfs) > 0 {
+		log.Debugf("Reregistering [notifyconfirmations] thresholds: %v",
+			stateCopy.notifyConfs)
+		if err := c.NotifyConfirmations(stateCopy.notifyConfs); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -819,8 +842,9 @@ func (c *Client) sendPost(jReq *jsonRequest) {
 	httpReq.Close = true
 	httpReq.Header.Set("Content-Type", "application/json")
 
-	// Configure basic access authorization.
-	httpReq.SetBasicAuth(c.config.User, c.config.Pass)
+	// Configure basic access authorization, or bearer authorization with
+	// a token.
+	httpReq.Header.Set("Authorization", authHeader(c.config))
 
 	log.Tracef("Sending command [%s] with id %d", jReq.method, jReq.id)
 	c.sendPostRequest(httpReq, jReq)
@@ -1065,6 +1089,11 @@ type ConnConfig struct {
 	// Pass is the passphrase to use to authenticate to the RPC server.
 	Pass string
 
+	// Token is a capability token minted by the RPC server with the
+	// minttoken command.  When set, it is used to authenticate to the RPC
+	// server instead of the User and Pass parameters.
+	Token string
+
 	// DisableTLS specifies whether transport layer security should be
 	// disabled.  It is recommended to always use TLS if the RPC server
 	// supports it as otherwise your username and password is sent across
@@ -1113,6 +1142,16 @@ type ConnConfig struct {
 	EnableBCInfoHacks bool
 }
 
+// authHeader returns the value of the Authorization header of the requests to
+// the RPC server, the token when there is one, or the username and password.
+func authHeader(config *ConnConfig) string {
+	if config.Token != "" {
+		return "Bearer " + config.Token
+	}
+	login := config.User + ":" + config.Pass
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(login))
+}
+
 // newHTTPClient returns a new http client that is configured according to the
 // proxy and TLS settings in the associated connection configuration.
 func newHTTPClient(config *ConnConfig) (*http.Client, error) {
@@ -1180,12 +1219,10 @@ func dial(config *ConnConfig) (*websocket.Conn, error) {
 		dialer.NetDial = proxy.Dial
 	}
 
-	// The RPC server requires basic authorization, so create a custom
-	// request header with the Authorization header set.
-	login := config.User + ":" + config.Pass
-	auth := "Basic " + base64.StdEncoding.EncodeToString([]byte(login))
+	// The RPC server requires authorization, so create a custom request
+	// header with the Authorization header set.
 	requestHeader := make(http.Header)
-	requestHeader.Add("Authorization", auth)
+	requestHeader.Add("Authorization", authHeader(config))
 
 	// Dial the connection.
 	url := fmt.Sprintf("%s://%s/%s", scheme, config.Host, config.Endpoint)
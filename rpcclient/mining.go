@@ -61,6 +61,72 @@ func (c *Client) Generate(numBlocks uint32) ([]*chainhash.Hash, error) {
 	return c.GenerateAsync(numBlocks).Receive()
 }
 
+// GenerateForkAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See GenerateFork for the blocking version and more details.
+func (c *Client) GenerateForkAsync(forkHash *chainhash.Hash, numBlocks uint32, address btcutil.Address) FutureGenerateResult {
+	var addr *string
+	if address != nil {
+		addr = btcjson.String(address.EncodeAddress())
+	}
+	cmd := btcjson.NewGenerateForkCmd(forkHash.String(), numBlocks, addr)
+	return c.sendCmd(cmd)
+}
+
+// GenerateFork generates numBlocks blocks on top of the passed main chain block
+// and returns their hashes.  The blocks pay the passed address, or the mining
+// address of the server when it is nil.  This is only supported on networks
+// allowing CPU mining.
+func (c *Client) GenerateFork(forkHash *chainhash.Hash, numBlocks uint32, address btcutil.Address) ([]*chainhash.Hash, error) {
+	return c.GenerateForkAsync(forkHash, numBlocks, address).Receive()
+}
+
+// FutureSimulateReorgResult is a future promise to deliver the result of a
+// SimulateReorgAsync RPC invocation (or an applicable error).
+type FutureSimulateReorgResult chan *response
+
+// Receive waits for the response promised by the future and returns the blocks
+// disconnected and connected by the chain reorganization.
+func (r FutureSimulateReorgResult) Receive() (*btcjson.SimulateReorgResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a simulatereorg result object.
+	var result btcjson.SimulateReorgResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// SimulateReorgAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See SimulateReorg for the blocking version and more details.
+func (c *Client) SimulateReorgAsync(depth, numBlocks uint32, address btcutil.Address) FutureSimulateReorgResult {
+	var addr *string
+	if address != nil {
+		addr = btcjson.String(address.EncodeAddress())
+	}
+	cmd := btcjson.NewSimulateReorgCmd(depth, &numBlocks, addr)
+	return c.sendCmd(cmd)
+}
+
+// SimulateReorg replaces the last depth blocks of the main chain of the server
+// with numBlocks generated blocks, which must be more than depth.  The blocks
+// pay the passed address, or the mining address of the server when it is nil.
+// This is only supported on networks allowing CPU mining.
+func (c *Client) SimulateReorg(depth, numBlocks uint32, address btcutil.Address) (*btcjson.SimulateReorgResult, error) {
+	return c.SimulateReorgAsync(depth, numBlocks, address).Receive()
+}
+
 // FutureGetGenerateResult is a future promise to deliver the result of a
 // GetGenerateAsync RPC invocation (or an applicable error).
 type FutureGetGenerateResult chan *response
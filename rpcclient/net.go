@@ -317,3 +317,196 @@ func (c *Client) GetNetTotalsAsync() FutureGetNetTotalsResult {
 func (c *Client) GetNetTotals() (*btcjson.GetNetTotalsResult, error) {
 	return c.GetNetTotalsAsync().Receive()
 }
+
+// FutureGetNetworkInfoResult is a future promise to deliver the result of a
+// GetNetworkInfoAsync RPC invocation (or an applicable error).
+type FutureGetNetworkInfoResult chan *response
+
+// Receive waits for the response promised by the future and returns
+// information about the peer-to-peer networking of the server.
+func (r FutureGetNetworkInfoResult) Receive() (*btcjson.GetNetworkInfoResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a getnetworkinfo result object.
+	var info btcjson.GetNetworkInfoResult
+	err = json.Unmarshal(res, &info)
+	if err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+// GetNetworkInfoAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function
+// on the returned instance.
+//
+// See GetNetworkInfo for the blocking version and more details.
+func (c *Client) GetNetworkInfoAsync() FutureGetNetworkInfoResult {
+	cmd := btcjson.NewGetNetworkInfoCmd()
+	return c.sendCmd(cmd)
+}
+
+// GetNetworkInfo returns information about the peer-to-peer networking of
+// the server.
+func (c *Client) GetNetworkInfo() (*btcjson.GetNetworkInfoResult, error) {
+	return c.GetNetworkInfoAsync().Receive()
+}
+
+// FutureGetRPCInfoResult is a future promise to deliver the result of a
+// GetRPCInfoAsync RPC invocation (or an applicable error).
+type FutureGetRPCInfoResult chan *response
+
+// Receive waits for the response promised by the future and returns
+// information about the RPC server.
+func (r FutureGetRPCInfoResult) Receive() (*btcjson.GetRPCInfoResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a getrpcinfo result object.
+	var info btcjson.GetRPCInfoResult
+	err = json.Unmarshal(res, &info)
+	if err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+// GetRPCInfoAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetRPCInfo for the blocking version and more details.
+func (c *Client) GetRPCInfoAsync() FutureGetRPCInfoResult {
+	cmd := btcjson.NewGetRPCInfoCmd()
+	return c.sendCmd(cmd)
+}
+
+// GetRPCInfo returns information about the RPC server, such as the commands
+// it is currently executing.
+func (c *Client) GetRPCInfo() (*btcjson.GetRPCInfoResult, error) {
+	return c.GetRPCInfoAsync().Receive()
+}
+
+// FutureGetNodeAddressesResult is a future promise to deliver the result of a
+// GetNodeAddressesAsync RPC invocation (or an applicable error).
+type FutureGetNodeAddressesResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// addresses known to the server.
+func (r FutureGetNodeAddressesResult) Receive() ([]btcjson.GetNodeAddressesResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as an array of getnodeaddresses result objects.
+	var addrs []btcjson.GetNodeAddressesResult
+	err = json.Unmarshal(res, &addrs)
+	if err != nil {
+		return nil, err
+	}
+
+	return addrs, nil
+}
+
+// GetNodeAddressesAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetNodeAddresses for the blocking version and more details.
+func (c *Client) GetNodeAddressesAsync(count *int32) FutureGetNodeAddressesResult {
+	cmd := btcjson.NewGetNodeAddressesCmd(count)
+	return c.sendCmd(cmd)
+}
+
+// GetNodeAddresses returns up to count randomly chosen addresses known to the
+// address manager of the server which are not considered bad, or all of them
+// when count is zero.  A nil count returns a single address.
+func (c *Client) GetNodeAddresses(count *int32) ([]btcjson.GetNodeAddressesResult, error) {
+	return c.GetNodeAddressesAsync(count).Receive()
+}
+
+// FutureGetAddrManInfoResult is a future promise to deliver the result of a
+// GetAddrManInfoAsync RPC invocation (or an applicable error).
+type FutureGetAddrManInfoResult chan *response
+
+// Receive waits for the response promised by the future and returns statistics
+// about the address manager.
+func (r FutureGetAddrManInfoResult) Receive() (*btcjson.GetAddrManInfoResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a getaddrmaninfo result object.
+	var info btcjson.GetAddrManInfoResult
+	err = json.Unmarshal(res, &info)
+	if err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+// GetAddrManInfoAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See GetAddrManInfo for the blocking version and more details.
+func (c *Client) GetAddrManInfoAsync() FutureGetAddrManInfoResult {
+	cmd := btcjson.NewGetAddrManInfoCmd()
+	return c.sendCmd(cmd)
+}
+
+// GetAddrManInfo returns statistics about the new and tried buckets of the
+// address manager of the server.
+func (c *Client) GetAddrManInfo() (*btcjson.GetAddrManInfoResult, error) {
+	return c.GetAddrManInfoAsync().Receive()
+}
+
+// FutureAddPeerAddressResult is a future promise to deliver the result of an
+// AddPeerAddressAsync RPC invocation (or an applicable error).
+type FutureAddPeerAddressResult chan *response
+
+// Receive waits for the response promised by the future and returns whether
+// the address was added.
+func (r FutureAddPeerAddressResult) Receive() (bool, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return false, err
+	}
+
+	// Unmarshal result as an addpeeraddress result object.
+	var result btcjson.AddPeerAddressResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return false, err
+	}
+
+	return result.Success, nil
+}
+
+// AddPeerAddressAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See AddPeerAddress for the blocking version and more details.
+func (c *Client) AddPeerAddressAsync(address string, port uint16, tried bool) FutureAddPeerAddressResult {
+	cmd := btcjson.NewAddPeerAddressCmd(address, port, &tried)
+	return c.sendCmd(cmd)
+}
+
+// AddPeerAddress adds the passed IP address and port to the address manager of
+// the server, moving it to a tried bucket when tried is set.  It returns false
+// when the address was not added, such as for an address which is not
+// routable.
+func (c *Client) AddPeerAddress(address string, port uint16, tried bool) (bool, error) {
+	return c.AddPeerAddressAsync(address, port, tried).Receive()
+}
@@ -34,16 +34,19 @@ var (
 // reconnect.
 type notificationState struct {
 	notifyBlocks       bool
+	notifyUtxoDeltas   bool
 	notifyNewTx        bool
 	notifyNewTxVerbose bool
 	notifyReceived     map[string]struct{}
 	notifySpent        map[btcjson.OutPoint]struct{}
+	notifyConfs        []int32
 }
 
 // Copy returns a deep copy of the receiver.
 func (s *notificationState) Copy() *notificationState {
 	var stateCopy notificationState
 	stateCopy.notifyBlocks = s.notifyBlocks
+	stateCopy.notifyUtxoDeltas = s.notifyUtxoDeltas
 	stateCopy.notifyNewTx = s.notifyNewTx
 	stateCopy.notifyNewTxVerbose = s.notifyNewTxVerbose
 	stateCopy.notifyReceived = make(map[string]struct{})
@@ -54,6 +57,7 @@ func (s *notificationState) Copy() *notificationState {
 	for op := range s.notifySpent {
 		stateCopy.notifySpent[op] = struct{}{}
 	}
+	stateCopy.notifyConfs = append([]int32(nil), s.notifyConfs...)
 
 	return &stateCopy
 }
@@ -152,6 +156,30 @@ type NotificationHandlers struct {
 	// github.com/decred/dcrrpcclient.
 	OnRelevantTxAccepted func(transaction []byte)
 
+	// OnWatchedTx is invoked when a mempool or newly mined transaction pays
+	// to or spends from a script on the watch list loaded with
+	// LoadWatchList.  The details are nil for a mempool transaction.
+	//
+	// NOTE: This is a pktd extension.
+	OnWatchedTx func(transaction *btcutil.Tx, matches []btcjson.WatchedTxMatch,
+		details *btcjson.BlockDetails)
+
+	// OnUtxoDeltas is invoked when a block is connected to or disconnected
+	// from the main chain with the outputs it creates and spends.  It will
+	// only be invoked if a preceding call to NotifyUtxoDeltas has been made
+	// to register for the notification and the function is non-nil.
+	//
+	// NOTE: This is a pktd extension.
+	OnUtxoDeltas func(deltas *btcjson.UtxoDeltasResult)
+
+	// OnBlockHeaders is invoked with each batch of main chain headers sent
+	// as a result of a call to StreamBlockHeaders.  The headers are decoded
+	// from the result, and nil when the verbose headers were requested.
+	//
+	// NOTE: This is a pktd extension.
+	OnBlockHeaders func(result *btcjson.BlockHeadersResult,
+		headers []wire.BlockHeader)
+
 	// OnRescanFinished is invoked after a rescan finishes due to a previous
 	// call to Rescan or RescanEndHeight.  Finished rescans should be
 	// signaled on this notification, rather than relying on the return
@@ -199,6 +227,34 @@ type NotificationHandlers struct {
 	// server such as pktwallet.
 	OnWalletLockState func(locked bool)
 
+	// OnNewTx is invoked when a transaction relevant to the wallet is
+	// added to the wallet.
+	//
+	// This will only be available when client is connected to a wallet
+	// server such as pktwallet.
+	OnNewTx func(account string, details *btcjson.ListTransactionsResult)
+
+	// OnTxConfirmed is invoked when a wallet transaction reaches one of the
+	// numbers of confirmations registered with NotifyConfirmations.
+	//
+	// This will only be available when client is connected to a wallet
+	// server such as pktwallet.
+	OnTxConfirmed func(txHash, blockHash *chainhash.Hash, confirmations int32)
+
+	// OnWalletRescanProgress is invoked periodically while the wallet
+	// rescans the chain, including during recovery.
+	//
+	// This will only be available when client is connected to a wallet
+	// server such as pktwallet.
+	OnWalletRescanProgress func(height, targetHeight int32)
+
+	// OnFeeBump is invoked when a wallet transaction is replaced by one
+	// paying the passed higher fee.
+	//
+	// This will only be available when client is connected to a wallet
+	// server such as pktwallet.
+	OnFeeBump func(origTxHash, txHash *chainhash.Hash, fee btcutil.Amount)
+
 	// OnUnknownNotification is invoked when an unrecognized notification
 	// is received.  This typically means the notification handling code
 	// for this package needs to be updated for a new notification type or
@@ -342,6 +398,77 @@ func (c *Client) handleNotification(ntfn *rawNotification) {
 
 		c.ntfnHandlers.OnRelevantTxAccepted(transaction)
 
+	// OnWatchedTx
+	case btcjson.WatchedTxNtfnMethod:
+		// Ignore the notification if the client is not interested in
+		// it.
+		if c.ntfnHandlers.OnWatchedTx == nil {
+			return
+		}
+
+		tx, matches, block, err := parseWatchedTxNtfnParams(ntfn.Params)
+		if err != nil {
+			log.Warnf("Received invalid watchedtx notification: %v",
+				err)
+			return
+		}
+
+		c.ntfnHandlers.OnWatchedTx(tx, matches, block)
+
+	// OnUtxoDeltas
+	case btcjson.UtxoDeltasNtfnMethod:
+		// Ignore the notification if the client is not interested in
+		// it.
+		if c.ntfnHandlers.OnUtxoDeltas == nil {
+			return
+		}
+
+		if len(ntfn.Params) != 1 {
+			log.Warnf("Received invalid utxodeltas notification: %v",
+				wrongNumParams(len(ntfn.Params)))
+			return
+		}
+		var deltas btcjson.UtxoDeltasResult
+		if err := json.Unmarshal(ntfn.Params[0], &deltas); err != nil {
+			log.Warnf("Received invalid utxodeltas notification: %v",
+				err)
+			return
+		}
+
+		c.ntfnHandlers.OnUtxoDeltas(&deltas)
+
+	// OnBlockHeaders
+	case btcjson.BlockHeadersNtfnMethod:
+		// Ignore the notification if the client is not interested in
+		// it.
+		if c.ntfnHandlers.OnBlockHeaders == nil {
+			return
+		}
+
+		if len(ntfn.Params) != 1 {
+			log.Warnf("Received invalid blockheaders notification: %v",
+				wrongNumParams(len(ntfn.Params)))
+			return
+		}
+		var result btcjson.BlockHeadersResult
+		if err := json.Unmarshal(ntfn.Params[0], &result); err != nil {
+			log.Warnf("Received invalid blockheaders notification: %v",
+				err)
+			return
+		}
+		var headers []wire.BlockHeader
+		if result.Hex != "" {
+			var err error
+			headers, err = decodeBlockHeaders(result.Hex)
+			if err != nil {
+				log.Warnf("Received invalid blockheaders "+
+					"notification: %v", err)
+				return
+			}
+		}
+
+		c.ntfnHandlers.OnBlockHeaders(&result, headers)
+
 	// OnRescanFinished
 	case btcjson.RescanFinishedNtfnMethod:
 		// Ignore the notification if the client is not interested in
@@ -463,6 +590,74 @@ func (c *Client) handleNotification(ntfn *rawNotification) {
 
 		c.ntfnHandlers.OnWalletLockState(locked)
 
+	// OnNewTx
+	case btcjson.NewTxNtfnMethod:
+		// Ignore the notification if the client is not interested in
+		// it.
+		if c.ntfnHandlers.OnNewTx == nil {
+			return
+		}
+
+		account, details, err := parseNewTxNtfnParams(ntfn.Params)
+		if err != nil {
+			log.Warnf("Received invalid new tx notification: %v",
+				err)
+			return
+		}
+
+		c.ntfnHandlers.OnNewTx(account, details)
+
+	// OnTxConfirmed
+	case btcjson.TxConfirmedNtfnMethod:
+		// Ignore the notification if the client is not interested in
+		// it.
+		if c.ntfnHandlers.OnTxConfirmed == nil {
+			return
+		}
+
+		txHash, blockHash, confs, err := parseTxConfirmedNtfnParams(ntfn.Params)
+		if err != nil {
+			log.Warnf("Received invalid tx confirmed "+
+				"notification: %v", err)
+			return
+		}
+
+		c.ntfnHandlers.OnTxConfirmed(txHash, blockHash, confs)
+
+	// OnWalletRescanProgress
+	case btcjson.WalletRescanProgressNtfnMethod:
+		// Ignore the notification if the client is not interested in
+		// it.
+		if c.ntfnHandlers.OnWalletRescanProgress == nil {
+			return
+		}
+
+		height, target, err := parseWalletRescanProgressNtfnParams(ntfn.Params)
+		if err != nil {
+			log.Warnf("Received invalid wallet rescan progress "+
+				"notification: %v", err)
+			return
+		}
+
+		c.ntfnHandlers.OnWalletRescanProgress(height, target)
+
+	// OnFeeBump
+	case btcjson.FeeBumpNtfnMethod:
+		// Ignore the notification if the client is not interested in
+		// it.
+		if c.ntfnHandlers.OnFeeBump == nil {
+			return
+		}
+
+		origTxHash, txHash, fee, err := parseFeeBumpNtfnParams(ntfn.Params)
+		if err != nil {
+			log.Warnf("Received invalid fee bump notification: %v",
+				err)
+			return
+		}
+
+		c.ntfnHandlers.OnFeeBump(origTxHash, txHash, fee)
+
 	// OnUnknownNotification
 	default:
 		if c.ntfnHandlers.OnUnknownNotification == nil {
@@ -679,6 +874,41 @@ func parseChainTxNtfnParams(params []json.RawMessage) (*btcutil.Tx,
 	return btcutil.NewTx(&msgTx), block, nil
 }
 
+// parseWatchedTxNtfnParams parses out the transaction, the matching inputs and
+// outputs and optional details about the block it's mined in from the
+// parameters of a watchedtx notification.
+func parseWatchedTxNtfnParams(params []json.RawMessage) (*btcutil.Tx,
+	[]btcjson.WatchedTxMatch, *btcjson.BlockDetails, error) {
+
+	if len(params) < 2 || len(params) > 3 {
+		return nil, nil, nil, wrongNumParams(len(params))
+	}
+
+	serializedTx, err := parseHexParam(params[0])
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var msgTx wire.MsgTx
+	err = msgTx.Deserialize(bytes.NewReader(serializedTx))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var matches []btcjson.WatchedTxMatch
+	if err := json.Unmarshal(params[1], &matches); err != nil {
+		return nil, nil, nil, err
+	}
+
+	var block *btcjson.BlockDetails
+	if len(params) > 2 {
+		if err := json.Unmarshal(params[2], &block); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	return btcutil.NewTx(&msgTx), matches, block, nil
+}
+
 // parseRescanProgressParams parses out the height of the last rescanned block
 // from the parameters of rescanfinished and rescanprogress notifications.
 func parseRescanProgressParams(params []json.RawMessage) (*chainhash.Hash, int32, time.Time, error) {
@@ -855,6 +1085,150 @@ func parseWalletLockStateNtfnParams(params []json.RawMessage) (account string,
 	return account, locked, nil
 }
 
+// parseNewTxNtfnParams parses out the account name and transaction details
+// from the parameters of a newtx notification.
+func parseNewTxNtfnParams(params []json.RawMessage) (string,
+	*btcjson.ListTransactionsResult, error) {
+
+	if len(params) != 2 {
+		return "", nil, wrongNumParams(len(params))
+	}
+
+	// Unmarshal first parameter as a string.
+	var account string
+	err := json.Unmarshal(params[0], &account)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Unmarshal second parameter as a transaction listing.
+	var details btcjson.ListTransactionsResult
+	err = json.Unmarshal(params[1], &details)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return account, &details, nil
+}
+
+// parseTxConfirmedNtfnParams parses out the transaction hash, block hash and
+// number of confirmations from the parameters of a txconfirmed notification.
+func parseTxConfirmedNtfnParams(params []json.RawMessage) (*chainhash.Hash,
+	*chainhash.Hash, int32, error) {
+
+	if len(params) != 3 {
+		return nil, nil, 0, wrongNumParams(len(params))
+	}
+
+	// Unmarshal first parameter as a string.
+	var txHashStr string
+	err := json.Unmarshal(params[0], &txHashStr)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	// Unmarshal second parameter as a string.
+	var blockHashStr string
+	err = json.Unmarshal(params[1], &blockHashStr)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	// Unmarshal third parameter as an integer.
+	var confs int32
+	err = json.Unmarshal(params[2], &confs)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	// Create hashes from the hash strings.
+	txHash, err := chainhash.NewHashFromStr(txHashStr)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	blockHash, err := chainhash.NewHashFromStr(blockHashStr)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	return txHash, blockHash, confs, nil
+}
+
+// parseWalletRescanProgressNtfnParams parses out the height reached and the
+// target height from the parameters of a walletrescanprogress notification.
+func parseWalletRescanProgressNtfnParams(params []json.RawMessage) (int32,
+	int32, error) {
+
+	if len(params) != 2 {
+		return 0, 0, wrongNumParams(len(params))
+	}
+
+	// Unmarshal first parameter as an integer.
+	var height int32
+	err := json.Unmarshal(params[0], &height)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// Unmarshal second parameter as an integer.
+	var targetHeight int32
+	err = json.Unmarshal(params[1], &targetHeight)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return height, targetHeight, nil
+}
+
+// parseFeeBumpNtfnParams parses out the hashes of the replaced and replacing
+// transactions and the new fee from the parameters of a feebump notification.
+func parseFeeBumpNtfnParams(params []json.RawMessage) (*chainhash.Hash,
+	*chainhash.Hash, btcutil.Amount, error) {
+
+	if len(params) != 3 {
+		return nil, nil, 0, wrongNumParams(len(params))
+	}
+
+	// Unmarshal first parameter as a string.
+	var origTxHashStr string
+	err := json.Unmarshal(params[0], &origTxHashStr)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	// Unmarshal second parameter as a string.
+	var txHashStr string
+	err = json.Unmarshal(params[1], &txHashStr)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	// Unmarshal third parameter as a floating point number.
+	var ffee float64
+	err = json.Unmarshal(params[2], &ffee)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	// Create hashes from the hash strings.
+	origTxHash, err := chainhash.NewHashFromStr(origTxHashStr)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	txHash, err := chainhash.NewHashFromStr(txHashStr)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	// Bounds check amount.
+	fee, err := globalcfg.NewAmount(ffee)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	return origTxHash, txHash, btcutil.Amount(fee), nil
+}
+
 // FutureNotifyBlocksResult is a future promise to deliver the result of a
 // NotifyBlocksAsync RPC invocation (or an applicable error).
 type FutureNotifyBlocksResult chan *response
@@ -903,6 +1277,109 @@ func (c *Client) NotifyBlocks() error {
 	return c.NotifyBlocksAsync().Receive()
 }
 
+// FutureNotifyUtxoDeltasResult is a future promise to deliver the result of a
+// NotifyUtxoDeltasAsync RPC invocation (or an applicable error).
+type FutureNotifyUtxoDeltasResult chan *response
+
+// Receive waits for the response promised by the future and returns an error
+// if the registration was not successful.
+func (r FutureNotifyUtxoDeltasResult) Receive() error {
+	_, err := receiveFuture(r)
+	return err
+}
+
+// NotifyUtxoDeltasAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See NotifyUtxoDeltas for the blocking version and more details.
+//
+// NOTE: This is a pktd extension and requires a websocket connection.
+func (c *Client) NotifyUtxoDeltasAsync() FutureNotifyUtxoDeltasResult {
+	// Not supported in HTTP POST mode.
+	if c.config.HTTPPostMode {
+		return newFutureError(ErrWebsocketsRequired)
+	}
+
+	// Ignore the notification if the client is not interested in
+	// notifications.
+	if c.ntfnHandlers == nil {
+		return newNilFutureResult()
+	}
+
+	cmd := btcjson.NewNotifyUtxoDeltasCmd()
+	return c.sendCmd(cmd)
+}
+
+// NotifyUtxoDeltas registers the client to receive notifications of the
+// outputs created and spent by the blocks connected to and disconnected from
+// the main chain.  The notifications are delivered to the notification
+// handlers associated with the client.  Calling this function has no effect if
+// there are no notification handlers and will result in an error if the client
+// is configured to run in HTTP POST mode.
+//
+// The notifications delivered as a result of this call will be via
+// OnUtxoDeltas.  The blocks missed while disconnected can be fetched with
+// GetUtxoDeltas.
+//
+// NOTE: This is a pktd extension and requires a websocket connection.
+func (c *Client) NotifyUtxoDeltas() error {
+	return c.NotifyUtxoDeltasAsync().Receive()
+}
+
+// FutureStreamBlockHeadersResult is a future promise to deliver the result of
+// a StreamBlockHeadersAsync RPC invocation (or an applicable error).
+type FutureStreamBlockHeadersResult chan *response
+
+// Receive waits for the response promised by the future and returns the last
+// header sent once every header was sent.
+func (r FutureStreamBlockHeadersResult) Receive() (*btcjson.StreamBlockHeadersResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result btcjson.StreamBlockHeadersResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// StreamBlockHeadersAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See StreamBlockHeaders for the blocking version and more details.
+//
+// NOTE: This is a pktd extension and requires a websocket connection.
+func (c *Client) StreamBlockHeadersAsync(startHeight int32, endHeight *int32,
+	verbose bool) FutureStreamBlockHeadersResult {
+
+	// Not supported in HTTP POST mode.
+	if c.config.HTTPPostMode {
+		return newFutureError(ErrWebsocketsRequired)
+	}
+
+	cmd := btcjson.NewStreamBlockHeadersCmd(startHeight, endHeight, &verbose)
+	return c.sendCmd(cmd)
+}
+
+// StreamBlockHeaders requests the headers of the main chain from the passed
+// height through endHeight, or through the best block when endHeight is nil.
+// The headers are delivered in batches of up to 2000 headers to the
+// OnBlockHeaders notification handler, before this call returns the last
+// header sent.
+//
+// NOTE: This is a pktd extension and requires a websocket connection.
+func (c *Client) StreamBlockHeaders(startHeight int32, endHeight *int32,
+	verbose bool) (*btcjson.StreamBlockHeadersResult, error) {
+
+	return c.StreamBlockHeadersAsync(startHeight, endHeight, verbose).Receive()
+}
+
 // FutureNotifySpentResult is a future promise to deliver the result of a
 // NotifySpentAsync RPC invocation (or an applicable error).
 //
@@ -1038,6 +1515,53 @@ func (c *Client) NotifyNewTransactions(verbose bool) error {
 	return c.NotifyNewTransactionsAsync(verbose).Receive()
 }
 
+// FutureNotifyConfirmationsResult is a future promise to deliver the result
+// of a NotifyConfirmationsAsync RPC invocation (or an applicable error).
+type FutureNotifyConfirmationsResult chan *response
+
+// Receive waits for the response promised by the future and returns an error
+// if the registration was not successful.
+func (r FutureNotifyConfirmationsResult) Receive() error {
+	_, err := receiveFuture(r)
+	return err
+}
+
+// NotifyConfirmationsAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See NotifyConfirmations for the blocking version and more details.
+//
+// NOTE: This is a pktwallet extension and requires a websocket connection.
+func (c *Client) NotifyConfirmationsAsync(thresholds []int32) FutureNotifyConfirmationsResult {
+	// Not supported in HTTP POST mode.
+	if c.config.HTTPPostMode {
+		return newFutureError(ErrWebsocketsRequired)
+	}
+
+	// Ignore the notification if the client is not interested in
+	// notifications.
+	if c.ntfnHandlers == nil {
+		return newNilFutureResult()
+	}
+
+	cmd := btcjson.NewNotifyConfirmationsCmd(thresholds)
+	return c.sendCmd(cmd)
+}
+
+// NotifyConfirmations registers the client to receive a notification each
+// time a wallet transaction reaches one of the passed numbers of
+// confirmations, replacing any previously registered numbers.  The
+// notifications are delivered to the OnTxConfirmed notification handler.
+// Calling this function has no effect if there are no notification handlers
+// and will result in an error if the client is configured to run in HTTP POST
+// mode.
+//
+// NOTE: This is a pktwallet extension and requires a websocket connection.
+func (c *Client) NotifyConfirmations(thresholds []int32) error {
+	return c.NotifyConfirmationsAsync(thresholds).Receive()
+}
+
 // FutureNotifyReceivedResult is a future promise to deliver the result of a
 // NotifyReceivedAsync RPC invocation (or an applicable error).
 //
@@ -1356,3 +1880,89 @@ func (c *Client) LoadTxFilterAsync(reload bool, addresses []btcutil.Address,
 func (c *Client) LoadTxFilter(reload bool, addresses []btcutil.Address, outPoints []wire.OutPoint) error {
 	return c.LoadTxFilterAsync(reload, addresses, outPoints).Receive()
 }
+
+// FutureLoadWatchListResult is a future promise to deliver the result of a
+// LoadWatchListAsync or RemoveWatchListAsync RPC invocation (or an applicable
+// error).
+//
+// NOTE: This is a pktd extension and requires a websocket connection.
+type FutureLoadWatchListResult chan *response
+
+// Receive waits for the response promised by the future and returns an error
+// if the watch list was not updated.
+//
+// NOTE: This is a pktd extension and requires a websocket connection.
+func (r FutureLoadWatchListResult) Receive() error {
+	_, err := receiveFuture(r)
+	return err
+}
+
+// LoadWatchListAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See LoadWatchList for the blocking version and more details.
+//
+// NOTE: This is a pktd extension and requires a websocket connection.
+func (c *Client) LoadWatchListAsync(reload bool, addresses []btcutil.Address,
+	scripts [][]byte) FutureLoadWatchListResult {
+
+	cmd := btcjson.NewLoadWatchListCmd(reload, encodeAddresses(addresses),
+		encodeScripts(scripts))
+	return c.sendCmd(cmd)
+}
+
+// LoadWatchList adds addresses and output scripts to the watch list of the
+// websocket client, replacing the list when reload is true.  Every mempool or
+// newly mined transaction paying to a watched script, or spending an output
+// which paid to one, is delivered to the OnWatchedTx notification handler.
+//
+// NOTE: This is a pktd extension and requires a websocket connection.
+func (c *Client) LoadWatchList(reload bool, addresses []btcutil.Address, scripts [][]byte) error {
+	return c.LoadWatchListAsync(reload, addresses, scripts).Receive()
+}
+
+// RemoveWatchListAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function
+// on the returned instance.
+//
+// See RemoveWatchList for the blocking version and more details.
+//
+// NOTE: This is a pktd extension and requires a websocket connection.
+func (c *Client) RemoveWatchListAsync(addresses []btcutil.Address,
+	scripts [][]byte) FutureLoadWatchListResult {
+
+	cmd := btcjson.NewRemoveWatchListCmd(encodeAddresses(addresses),
+		encodeScripts(scripts))
+	return c.sendCmd(cmd)
+}
+
+// RemoveWatchList removes addresses and output scripts from the watch list of
+// the websocket client.
+//
+// NOTE: This is a pktd extension and requires a websocket connection.
+func (c *Client) RemoveWatchList(addresses []btcutil.Address, scripts [][]byte) error {
+	return c.RemoveWatchListAsync(addresses, scripts).Receive()
+}
+
+// encodeAddresses returns the string encodings of the passed addresses.
+func encodeAddresses(addresses []btcutil.Address) []string {
+	addrStrs := make([]string, len(addresses))
+	for i, a := range addresses {
+		addrStrs[i] = a.EncodeAddress()
+	}
+	return addrStrs
+}
+
+// encodeScripts returns the hex encodings of the passed scripts, or nil when
+// there are none.
+func encodeScripts(scripts [][]byte) *[]string {
+	if len(scripts) == 0 {
+		return nil
+	}
+	hexScripts := make([]string, len(scripts))
+	for i, script := range scripts {
+		hexScripts[i] = hex.EncodeToString(script)
+	}
+	return &hexScripts
+}
@@ -505,6 +505,73 @@ func (c *Client) SignRawTransaction4(tx *wire.MsgTx,
 		hashType).Receive()
 }
 
+// FutureFundRawTransactionResult is a future promise to deliver the result
+// of a FundRawTransactionAsync RPC invocation (or an applicable error).
+type FutureFundRawTransactionResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// funded transaction along with the fee paid and the index of the change
+// output, which is -1 when no change output was added.
+func (r FutureFundRawTransactionResult) Receive() (*wire.MsgTx, *btcjson.FundRawTransactionResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Unmarshal as a fundrawtransaction result.
+	var fundRawTxResult btcjson.FundRawTransactionResult
+	err = json.Unmarshal(res, &fundRawTxResult)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Decode the serialized transaction hex to raw bytes.
+	serializedTx, err := hex.DecodeString(fundRawTxResult.Hex)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Deserialize the transaction and return it.
+	var msgTx wire.MsgTx
+	if err := msgTx.Deserialize(bytes.NewReader(serializedTx)); err != nil {
+		return nil, nil, err
+	}
+
+	return &msgTx, &fundRawTxResult, nil
+}
+
+// FundRawTransactionAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See FundRawTransaction for the blocking version and more details.
+func (c *Client) FundRawTransactionAsync(tx *wire.MsgTx, minConfirms int,
+	change *btcjson.ChangeOptions) FutureFundRawTransactionResult {
+
+	txHex := ""
+	if tx != nil {
+		// Serialize the transaction and convert to hex string.
+		buf := bytes.NewBuffer(make([]byte, 0, tx.SerializeSize()))
+		if err := tx.Serialize(buf); err != nil {
+			return newFutureError(err)
+		}
+		txHex = hex.EncodeToString(buf.Bytes())
+	}
+
+	cmd := btcjson.NewFundRawTransactionCmd(txHex, &minConfirms, change)
+	return c.sendCmd(cmd)
+}
+
+// FundRawTransaction adds inputs from the wallet to the passed transaction
+// until it pays for its outputs and fee, adding change as directed by the
+// passed options.  A nil change uses the wallet defaults.  The inputs are
+// not signed.
+func (c *Client) FundRawTransaction(tx *wire.MsgTx, minConfirms int,
+	change *btcjson.ChangeOptions) (*wire.MsgTx, *btcjson.FundRawTransactionResult, error) {
+
+	return c.FundRawTransactionAsync(tx, minConfirms, change).Receive()
+}
+
 // FutureSearchRawTransactionsResult is a future promise to deliver the result
 // of the SearchRawTransactionsAsync RPC invocation (or an applicable error).
 type FutureSearchRawTransactionsResult chan *response
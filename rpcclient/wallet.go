@@ -314,6 +314,169 @@ func (c *Client) ListSinceBlockMinConf(blockHash *chainhash.Hash, minConfirms in
 	return c.ListSinceBlockMinConfAsync(blockHash, minConfirms).Receive()
 }
 
+// ListSinceBlockPageAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See ListSinceBlockPage for the blocking version and more details.
+func (c *Client) ListSinceBlockPageAsync(blockHash *chainhash.Hash,
+	minConfirms int, cursor string, count int) FutureListSinceBlockResult {
+
+	// The optional parameters are positional, so the ones preceding the
+	// count are all given explicit defaults for the count to be sent.
+	var hash string
+	if blockHash != nil {
+		hash = blockHash.String()
+	}
+
+	cmd := btcjson.NewListSinceBlockCmd(&hash, &minConfirms,
+		btcjson.Bool(false))
+	cmd.IncludeRemoved = btcjson.Bool(true)
+	cmd.IncludeChange = btcjson.Bool(false)
+	cmd.Cursor = &cursor
+	cmd.Count = &count
+	return c.sendCmd(cmd)
+}
+
+// ListSinceBlockPage returns up to count transactions added in blocks since
+// the specified block hash, along with the transactions removed by
+// reorganizations since then.  The first page is requested with an empty
+// cursor and the following ones by passing the NextCursor of the previous
+// result, which is empty once there are no more transactions.
+//
+// See ListSinceBlockMinConf to list all transactions at once.
+func (c *Client) ListSinceBlockPage(blockHash *chainhash.Hash, minConfirms int,
+	cursor string, count int) (*btcjson.ListSinceBlockResult, error) {
+
+	return c.ListSinceBlockPageAsync(blockHash, minConfirms, cursor,
+		count).Receive()
+}
+
+// FutureSetTxMemoResult is a future promise to deliver the result of a
+// SetTxMemoAsync RPC invocation (or an applicable error).
+type FutureSetTxMemoResult chan *response
+
+// Receive waits for the response promised by the future and returns the result
+// of setting the memo of a transaction.
+func (r FutureSetTxMemoResult) Receive() error {
+	_, err := receiveFuture(r)
+	return err
+}
+
+// SetTxMemoAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See SetTxMemo for the blocking version and more details.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) SetTxMemoAsync(txHash *chainhash.Hash, memo string,
+	metadata map[string]string) FutureSetTxMemoResult {
+
+	var meta *map[string]string
+	if metadata != nil {
+		meta = &metadata
+	}
+	cmd := btcjson.NewSetTxMemoCmd(txHash.String(), memo, meta)
+	return c.sendCmd(cmd)
+}
+
+// SetTxMemo replaces the memo and payment metadata of the passed wallet
+// transaction, which are returned by GetTransaction and ListTransactions.  An
+// empty memo with nil metadata removes the memo.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) SetTxMemo(txHash *chainhash.Hash, memo string,
+	metadata map[string]string) error {
+
+	return c.SetTxMemoAsync(txHash, memo, metadata).Receive()
+}
+
+// FutureExportTxMemosResult is a future promise to deliver the result of an
+// ExportTxMemosAsync RPC invocation (or an applicable error).
+type FutureExportTxMemosResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// encrypted export of the memos.
+func (r FutureExportTxMemosResult) Receive() (string, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return "", err
+	}
+
+	var data string
+	err = json.Unmarshal(res, &data)
+	if err != nil {
+		return "", err
+	}
+	return data, nil
+}
+
+// ExportTxMemosAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See ExportTxMemos for the blocking version and more details.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) ExportTxMemosAsync(passphrase string) FutureExportTxMemosResult {
+	cmd := btcjson.NewExportTxMemosCmd(passphrase)
+	return c.sendCmd(cmd)
+}
+
+// ExportTxMemos returns the memos of every transaction of the wallet, base64
+// encoded and encrypted with the passphrase, to be imported by ImportTxMemos.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) ExportTxMemos(passphrase string) (string, error) {
+	return c.ExportTxMemosAsync(passphrase).Receive()
+}
+
+// FutureImportTxMemosResult is a future promise to deliver the result of an
+// ImportTxMemosAsync RPC invocation (or an applicable error).
+type FutureImportTxMemosResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// number of memos imported and skipped.
+func (r FutureImportTxMemosResult) Receive() (*btcjson.ImportTxMemosResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result btcjson.ImportTxMemosResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ImportTxMemosAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See ImportTxMemos for the blocking version and more details.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) ImportTxMemosAsync(data, passphrase string,
+	overwrite bool) FutureImportTxMemosResult {
+
+	cmd := btcjson.NewImportTxMemosCmd(data, passphrase, &overwrite)
+	return c.sendCmd(cmd)
+}
+
+// ImportTxMemos imports the memos of an export made by ExportTxMemos.  The
+// memos of transactions which already have one are skipped unless overwrite
+// is true.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) ImportTxMemos(data, passphrase string,
+	overwrite bool) (*btcjson.ImportTxMemosResult, error) {
+
+	return c.ImportTxMemosAsync(data, passphrase, overwrite).Receive()
+}
+
 // **************************
 // Transaction Send Functions
 // **************************
@@ -358,6 +521,7 @@ func (c *Client) LockUnspentAsync(unlock bool, ops []*wire.OutPoint) FutureLockU
 //
 // The locked or unlocked state of outputs are not written to disk and after
 // restarting a wallet process, this data will be reset (every output unlocked).
+// See LockUnspentPersistent to keep the state across restarts.
 //
 // NOTE: While this method would be a bit more readable if the unlock bool was
 // reversed (that is, LockUnspent(true, ...) locked the outputs), it has been
@@ -367,6 +531,31 @@ func (c *Client) LockUnspent(unlock bool, ops []*wire.OutPoint) error {
 	return c.LockUnspentAsync(unlock, ops).Receive()
 }
 
+// LockUnspentPersistentAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See LockUnspentPersistent for the blocking version and more details.
+func (c *Client) LockUnspentPersistentAsync(unlock bool, ops []*wire.OutPoint) FutureLockUnspentResult {
+	outputs := make([]btcjson.TransactionInput, len(ops))
+	for i, op := range ops {
+		outputs[i] = btcjson.TransactionInput{
+			Txid: op.Hash.String(),
+			Vout: op.Index,
+		}
+	}
+	cmd := btcjson.NewLockUnspentCmd(unlock, outputs)
+	cmd.Persistent = btcjson.Bool(true)
+	return c.sendCmd(cmd)
+}
+
+// LockUnspentPersistent marks outputs as locked or unlocked in the same way
+// as LockUnspent, except that the state is written to the wallet database and
+// is kept after the wallet process is restarted.
+func (c *Client) LockUnspentPersistent(unlock bool, ops []*wire.OutPoint) error {
+	return c.LockUnspentPersistentAsync(unlock, ops).Receive()
+}
+
 // FutureListLockUnspentResult is a future promise to deliver the result of a
 // ListLockUnspentAsync RPC invocation (or an applicable error).
 type FutureListLockUnspentResult chan *response
@@ -521,6 +710,48 @@ func (c *Client) SendToAddressComment(address btcutil.Address, amount btcutil.Am
 		commentTo).Receive()
 }
 
+// SendToAddressInputsAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See SendToAddressInputs for the blocking version and more details.
+func (c *Client) SendToAddressInputsAsync(address btcutil.Address,
+	amount btcutil.Amount, ops []*wire.OutPoint,
+	change *btcjson.ChangeOptions) FutureSendToAddressResult {
+
+	inputs := make([]btcjson.TransactionInput, len(ops))
+	for i, op := range ops {
+		inputs[i] = btcjson.TransactionInput{
+			Txid: op.Hash.String(),
+			Vout: op.Index,
+		}
+	}
+	// The optional parameters are positional, so the ones preceding the
+	// inputs are given their defaults for the inputs to be sent.
+	if change == nil {
+		change = &btcjson.ChangeOptions{}
+	}
+	addr := address.EncodeAddress()
+	cmd := btcjson.NewSendToAddressCmd(addr, amount.ToBTC(),
+		btcjson.String(""), btcjson.String(""))
+	cmd.Change = change
+	cmd.Inputs = &inputs
+	return c.sendCmd(cmd)
+}
+
+// SendToAddressInputs sends the passed amount to the given address spending
+// exactly the passed outpoints, which may be locked, and handling change as
+// directed by the passed options.  A nil change uses the wallet defaults.
+//
+// NOTE: This function requires to the wallet to be unlocked.  See the
+// WalletPassphrase function for more details.
+func (c *Client) SendToAddressInputs(address btcutil.Address,
+	amount btcutil.Amount, ops []*wire.OutPoint,
+	change *btcjson.ChangeOptions) (*chainhash.Hash, error) {
+
+	return c.SendToAddressInputsAsync(address, amount, ops, change).Receive()
+}
+
 // FutureSendFromResult is a future promise to deliver the result of a
 // SendFromAsync, SendFromMinConfAsync, or SendFromCommentAsync RPC invocation
 // (or an applicable error).
@@ -749,1185 +980,2500 @@ func (c *Client) SendManyComment(fromAccount string,
 		comment).Receive()
 }
 
-// *************************
-// Address/Account Functions
-// *************************
+// SendManyChangeAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See SendManyChange for the blocking version and more details.
+func (c *Client) SendManyChangeAsync(fromAccount string,
+	amounts map[btcutil.Address]btcutil.Amount, minConfirms int,
+	change *btcjson.ChangeOptions) FutureSendManyResult {
 
-// FutureAddMultisigAddressResult is a future promise to deliver the result of a
-// AddMultisigAddressAsync RPC invocation (or an applicable error).
-type FutureAddMultisigAddressResult chan *response
+	convertedAmounts := make(map[string]float64, len(amounts))
+	for addr, amount := range amounts {
+		convertedAmounts[addr.EncodeAddress()] = amount.ToBTC()
+	}
+	// The comment precedes the change options, so an empty one is sent for
+	// the change options to be sent.
+	cmd := btcjson.NewSendManyCmd(fromAccount, convertedAmounts,
+		&minConfirms, btcjson.String(""))
+	cmd.Change = change
+	return c.sendCmd(cmd)
+}
+
+// SendManyChange sends multiple amounts to multiple addresses using the
+// provided account as a source of funds in a single transaction, handling
+// change as directed by the passed options.  This allows paying change to an
+// explicit address, choosing the type of the change address, or avoiding
+// change entirely by subtracting the fee from the outputs.
+//
+// See SendMany and SendManyMinConf to use defaults.
+//
+// NOTE: This function requires to the wallet to be unlocked.  See the
+// WalletPassphrase function for more details.
+func (c *Client) SendManyChange(fromAccount string,
+	amounts map[btcutil.Address]btcutil.Amount, minConfirms int,
+	change *btcjson.ChangeOptions) (*chainhash.Hash, error) {
+
+	return c.SendManyChangeAsync(fromAccount, amounts, minConfirms,
+		change).Receive()
+}
+
+// FutureProveAddressOwnershipResult is a future promise to deliver the result
+// of a ProveAddressOwnershipAsync RPC invocation (or an applicable error).
+type FutureProveAddressOwnershipResult chan *response
 
 // Receive waits for the response promised by the future and returns the
-// multisignature address that requires the specified number of signatures for
-// the provided addresses.
-func (r FutureAddMultisigAddressResult) Receive() (btcutil.Address, error) {
+// ownership proof created by the wallet.
+func (r FutureProveAddressOwnershipResult) Receive() (*btcjson.AddressOwnershipProofResult, error) {
 	res, err := receiveFuture(r)
 	if err != nil {
 		return nil, err
 	}
 
-	// Unmarshal result as a string.
-	var addr string
-	err = json.Unmarshal(res, &addr)
+	var result btcjson.AddressOwnershipProofResult
+	err = json.Unmarshal(res, &result)
 	if err != nil {
 		return nil, err
 	}
-
-	return btcutil.DecodeAddress(addr, &chaincfg.MainNetParams)
+	return &result, nil
 }
 
-// AddMultisigAddressAsync returns an instance of a type that can be used to get
-// the result of the RPC at some future time by invoking the Receive function on
-// the returned instance.
+// ProveAddressOwnershipAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
 //
-// See AddMultisigAddress for the blocking version and more details.
-func (c *Client) AddMultisigAddressAsync(requiredSigs int, addresses []btcutil.Address, account string) FutureAddMultisigAddressResult {
+// See ProveAddressOwnership for the blocking version and more details.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) ProveAddressOwnershipAsync(addresses []btcutil.Address,
+	message string, minConfirms int) FutureProveAddressOwnershipResult {
+
 	addrs := make([]string, 0, len(addresses))
 	for _, addr := range addresses {
-		addrs = append(addrs, addr.String())
+		addrs = append(addrs, addr.EncodeAddress())
 	}
-
-	cmd := btcjson.NewAddMultisigAddressCmd(requiredSigs, addrs, &account)
+	cmd := btcjson.NewProveAddressOwnershipCmd(addrs, message, &minConfirms)
 	return c.sendCmd(cmd)
 }
 
-// AddMultisigAddress adds a multisignature address that requires the specified
-// number of signatures for the provided addresses to the wallet.
-func (c *Client) AddMultisigAddress(requiredSigs int, addresses []btcutil.Address, account string) (btcutil.Address, error) {
-	return c.AddMultisigAddressAsync(requiredSigs, addresses,
-		account).Receive()
+// ProveAddressOwnership creates a proof that the wallet controls every
+// unspent output paying to the passed addresses with at least minConfirms
+// confirmations, without spending them.  The proof commits to message and
+// may be checked against any node with VerifyAddressOwnership.
+//
+// NOTE: This function requires to the wallet to be unlocked.  See the
+// WalletPassphrase function for more details.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) ProveAddressOwnership(addresses []btcutil.Address,
+	message string, minConfirms int) (*btcjson.AddressOwnershipProofResult, error) {
+
+	return c.ProveAddressOwnershipAsync(addresses, message,
+		minConfirms).Receive()
 }
 
-// FutureCreateMultisigResult is a future promise to deliver the result of a
-// CreateMultisigAsync RPC invocation (or an applicable error).
-type FutureCreateMultisigResult chan *response
+// FutureSendManyBatchResult is a future promise to deliver the result of a
+// SendManyBatchAsync RPC invocation (or an applicable error).
+type FutureSendManyBatchResult chan *response
 
 // Receive waits for the response promised by the future and returns the
-// multisignature address and script needed to redeem it.
-func (r FutureCreateMultisigResult) Receive() (*btcjson.CreateMultiSigResult, error) {
+// breakdown of the transaction which was sent.
+func (r FutureSendManyBatchResult) Receive() (*btcjson.SendManyBatchResult, error) {
 	res, err := receiveFuture(r)
 	if err != nil {
 		return nil, err
 	}
 
-	// Unmarshal result as a createmultisig result object.
-	var multisigRes btcjson.CreateMultiSigResult
-	err = json.Unmarshal(res, &multisigRes)
+	var result btcjson.SendManyBatchResult
+	err = json.Unmarshal(res, &result)
 	if err != nil {
 		return nil, err
 	}
-
-	return &multisigRes, nil
+	return &result, nil
 }
 
-// CreateMultisigAsync returns an instance of a type that can be used to get
+// SendManyBatchAsync returns an instance of a type that can be used to get
 // the result of the RPC at some future time by invoking the Receive function on
 // the returned instance.
 //
-// See CreateMultisig for the blocking version and more details.
-func (c *Client) CreateMultisigAsync(requiredSigs int, addresses []btcutil.Address) FutureCreateMultisigResult {
-	addrs := make([]string, 0, len(addresses))
-	for _, addr := range addresses {
-		addrs = append(addrs, addr.String())
-	}
+// See SendManyBatch for the blocking version and more details.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) SendManyBatchAsync(fromAccount string,
+	outputs []btcjson.BatchOutput, minConfirms int,
+	fee *btcjson.BatchFeeOptions,
+	change *btcjson.ChangeOptions) FutureSendManyBatchResult {
 
-	cmd := btcjson.NewCreateMultisigCmd(requiredSigs, addrs)
+	cmd := btcjson.NewSendManyBatchCmd(fromAccount, outputs, &minConfirms,
+		fee, change)
 	return c.sendCmd(cmd)
 }
 
-// CreateMultisig creates a multisignature address that requires the specified
-// number of signatures for the provided addresses and returns the
-// multisignature address and script needed to redeem it.
-func (c *Client) CreateMultisig(requiredSigs int, addresses []btcutil.Address) (*btcjson.CreateMultiSigResult, error) {
-	return c.CreateMultisigAsync(requiredSigs, addresses).Receive()
+// SendManyBatch pays all of the passed outputs using the provided account as a
+// source of funds in a single transaction and returns the inputs, outputs, fee
+// and change of the transaction.  Payments to the same address are merged.
+// See btcjson.SendManyBatchCmd for more details.
+//
+// NOTE: This function requires to the wallet to be unlocked.  See the
+// WalletPassphrase function for more details.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) SendManyBatch(fromAccount string,
+	outputs []btcjson.BatchOutput, minConfirms int,
+	fee *btcjson.BatchFeeOptions,
+	change *btcjson.ChangeOptions) (*btcjson.SendManyBatchResult, error) {
+
+	return c.SendManyBatchAsync(fromAccount, outputs, minConfirms, fee,
+		change).Receive()
 }
 
-// FutureCreateNewAccountResult is a future promise to deliver the result of a
-// CreateNewAccountAsync RPC invocation (or an applicable error).
-type FutureCreateNewAccountResult chan *response
+// FutureSendPayjoinResult is a future promise to deliver the result of a
+// SendPayjoinAsync RPC invocation (or an applicable error).
+type FutureSendPayjoinResult chan *response
 
-// Receive waits for the response promised by the future and returns the
-// result of creating new account.
-func (r FutureCreateNewAccountResult) Receive() error {
-	_, err := receiveFuture(r)
-	return err
+// Receive waits for the response promised by the future and returns the hash
+// of the transaction which was sent and whether it is a payjoin.
+func (r FutureSendPayjoinResult) Receive() (*btcjson.SendPayjoinResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result btcjson.SendPayjoinResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
 }
 
-// CreateNewAccountAsync returns an instance of a type that can be used to get the
+// SendPayjoinAsync returns an instance of a type that can be used to get the
 // result of the RPC at some future time by invoking the Receive function on the
 // returned instance.
 //
-// See CreateNewAccount for the blocking version and more details.
-func (c *Client) CreateNewAccountAsync(account string) FutureCreateNewAccountResult {
-	cmd := btcjson.NewCreateNewAccountCmd(account)
+// See SendPayjoin for the blocking version and more details.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) SendPayjoinAsync(uri string, amounts map[string]btcutil.Amount,
+	fromAccount string, minConfirms int,
+	maxFeeContribution btcutil.Amount) FutureSendPayjoinResult {
+
+	var convertedAmounts *map[string]float64
+	if len(amounts) != 0 {
+		m := make(map[string]float64, len(amounts))
+		for addr, amount := range amounts {
+			m[addr] = amount.ToBTC()
+		}
+		convertedAmounts = &m
+	}
+	cmd := btcjson.NewSendPayjoinCmd(uri, convertedAmounts, &fromAccount,
+		&minConfirms, btcjson.Float64(maxFeeContribution.ToBTC()), nil)
 	return c.sendCmd(cmd)
 }
 
-// CreateNewAccount creates a new wallet account.
-func (c *Client) CreateNewAccount(account string) error {
-	return c.CreateNewAccountAsync(account).Receive()
+// SendPayjoin pays the passed payment URI, along with the additional amounts,
+// using the provided account as a source of funds.  When the URI advertises a
+// payjoin (BIP 0078) endpoint, the receiver may add one of its inputs to the
+// transaction and up to maxFeeContribution of the change pays for its fee.
+// See btcjson.SendPayjoinCmd for more details.
+//
+// NOTE: This function requires to the wallet to be unlocked.  See the
+// WalletPassphrase function for more details.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) SendPayjoin(uri string, amounts map[string]btcutil.Amount,
+	fromAccount string, minConfirms int,
+	maxFeeContribution btcutil.Amount) (*btcjson.SendPayjoinResult, error) {
+
+	return c.SendPayjoinAsync(uri, amounts, fromAccount, minConfirms,
+		maxFeeContribution).Receive()
 }
 
-// FutureGetNewAddressResult is a future promise to deliver the result of a
-// GetNewAddressAsync RPC invocation (or an applicable error).
-type FutureGetNewAddressResult chan *response
+// FutureGetSignerPolicyResult is a future promise to deliver the result of a
+// GetSignerPolicyAsync RPC invocation (or an applicable error).
+type FutureGetSignerPolicyResult chan *response
 
-// Receive waits for the response promised by the future and returns a new
-// address.
-func (r FutureGetNewAddressResult) Receive() (btcutil.Address, error) {
+// Receive waits for the response promised by the future and returns the signer
+// policy of the wallet.
+func (r FutureGetSignerPolicyResult) Receive() (*btcjson.SignerPolicyResult, error) {
 	res, err := receiveFuture(r)
 	if err != nil {
 		return nil, err
 	}
 
-	// Unmarshal result as a string.
-	var addr string
-	err = json.Unmarshal(res, &addr)
+	var policy btcjson.SignerPolicyResult
+	err = json.Unmarshal(res, &policy)
 	if err != nil {
 		return nil, err
 	}
 
-	return btcutil.DecodeAddress(addr, &chaincfg.MainNetParams)
+	return &policy, nil
 }
 
-// GetNewAddressAsync returns an instance of a type that can be used to get the
-// result of the RPC at some future time by invoking the Receive function on the
-// returned instance.
+// GetSignerPolicyAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
 //
-// See GetNewAddress for the blocking version and more details.
-func (c *Client) GetNewAddressAsync(account string) FutureGetNewAddressResult {
-	cmd := btcjson.NewGetNewAddressCmd(&account)
+// See GetSignerPolicy for the blocking version and more details.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) GetSignerPolicyAsync() FutureGetSignerPolicyResult {
+	cmd := btcjson.NewGetSignerPolicyCmd()
 	return c.sendCmd(cmd)
 }
 
-// GetNewAddress returns a new address.
-func (c *Client) GetNewAddress(account string) (btcutil.Address, error) {
-	return c.GetNewAddressAsync(account).Receive()
+// GetSignerPolicy returns the rules every send of the wallet must satisfy
+// before it is signed, and whether a second factor is configured.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) GetSignerPolicy() (*btcjson.SignerPolicyResult, error) {
+	return c.GetSignerPolicyAsync().Receive()
 }
 
-// FutureGetRawChangeAddressResult is a future promise to deliver the result of
-// a GetRawChangeAddressAsync RPC invocation (or an applicable error).
-type FutureGetRawChangeAddressResult chan *response
+// FutureSetSignerPolicyResult is a future promise to deliver the result of a
+// SetSignerPolicyAsync RPC invocation (or an applicable error).
+type FutureSetSignerPolicyResult chan *response
 
-// Receive waits for the response promised by the future and returns a new
-// address for receiving change that will be associated with the provided
-// account.  Note that this is only for raw transactions and NOT for normal use.
-func (r FutureGetRawChangeAddressResult) Receive() (btcutil.Address, error) {
+// Receive waits for the response promised by the future and returns the result
+// of replacing the signer policy.
+func (r FutureSetSignerPolicyResult) Receive() error {
+	_, err := receiveFuture(r)
+	return err
+}
+
+// SetSignerPolicyAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See SetSignerPolicy for the blocking version and more details.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) SetSignerPolicyAsync(rules []btcjson.SignerPolicyRule,
+	secondFactorSecret, code *string) FutureSetSignerPolicyResult {
+
+	cmd := btcjson.NewSetSignerPolicyCmd(rules, secondFactorSecret, code)
+	return c.sendCmd(cmd)
+}
+
+// SetSignerPolicy replaces the signer policy of the wallet.  A nil second
+// factor secret keeps the current one and an empty one removes it.  Once a
+// second factor is configured, code must be one of its one-time passwords.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) SetSignerPolicy(rules []btcjson.SignerPolicyRule,
+	secondFactorSecret, code *string) error {
+
+	return c.SetSignerPolicyAsync(rules, secondFactorSecret, code).Receive()
+}
+
+// FutureListPendingSendsResult is a future promise to deliver the result of a
+// ListPendingSendsAsync RPC invocation (or an applicable error).
+type FutureListPendingSendsResult chan *response
+
+// Receive waits for the response promised by the future and returns the sends
+// held by the signer policy.
+func (r FutureListPendingSendsResult) Receive() ([]btcjson.PendingSendResult, error) {
 	res, err := receiveFuture(r)
 	if err != nil {
 		return nil, err
 	}
 
-	// Unmarshal result as a string.
-	var addr string
-	err = json.Unmarshal(res, &addr)
+	var sends []btcjson.PendingSendResult
+	err = json.Unmarshal(res, &sends)
 	if err != nil {
 		return nil, err
 	}
 
-	return btcutil.DecodeAddress(addr, &chaincfg.MainNetParams)
+	return sends, nil
 }
 
-// GetRawChangeAddressAsync returns an instance of a type that can be used to
-// get the result of the RPC at some future time by invoking the Receive
-// function on the returned instance.
+// ListPendingSendsAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
 //
-// See GetRawChangeAddress for the blocking version and more details.
-func (c *Client) GetRawChangeAddressAsync(account string) FutureGetRawChangeAddressResult {
-	cmd := btcjson.NewGetRawChangeAddressCmd(&account)
+// See ListPendingSends for the blocking version and more details.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) ListPendingSendsAsync() FutureListPendingSendsResult {
+	cmd := btcjson.NewListPendingSendsCmd()
 	return c.sendCmd(cmd)
 }
 
-// GetRawChangeAddress returns a new address for receiving change that will be
-// associated with the provided account.  Note that this is only for raw
-// transactions and NOT for normal use.
-func (c *Client) GetRawChangeAddress(account string) (btcutil.Address, error) {
-	return c.GetRawChangeAddressAsync(account).Receive()
+// ListPendingSends returns the sends held by the signer policy, waiting for
+// their approval or the end of their delay.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) ListPendingSends() ([]btcjson.PendingSendResult, error) {
+	return c.ListPendingSendsAsync().Receive()
 }
 
-// FutureAddWitnessAddressResult is a future promise to deliver the result of
-// a AddWitnessAddressAsync RPC invocation (or an applicable error).
-type FutureAddWitnessAddressResult chan *response
+// FutureApprovePendingSendResult is a future promise to deliver the result of
+// an ApprovePendingSendAsync RPC invocation (or an applicable error).
+type FutureApprovePendingSendResult chan *response
 
-// Receive waits for the response promised by the future and returns the new
-// address.
-func (r FutureAddWitnessAddressResult) Receive() (btcutil.Address, error) {
+// Receive waits for the response promised by the future and returns the
+// approved send.
+func (r FutureApprovePendingSendResult) Receive() (*btcjson.PendingSendResult, error) {
 	res, err := receiveFuture(r)
 	if err != nil {
 		return nil, err
 	}
 
-	// Unmarshal result as a string.
-	var addr string
-	err = json.Unmarshal(res, &addr)
+	var send btcjson.PendingSendResult
+	err = json.Unmarshal(res, &send)
 	if err != nil {
 		return nil, err
 	}
 
-	return btcutil.DecodeAddress(addr, &chaincfg.MainNetParams)
+	return &send, nil
 }
 
-// AddWitnessAddressAsync returns an instance of a type that can be used to get
+// ApprovePendingSendAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See ApprovePendingSend for the blocking version and more details.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) ApprovePendingSendAsync(id uint64, code string) FutureApprovePendingSendResult {
+	cmd := btcjson.NewApprovePendingSendCmd(id, code)
+	return c.sendCmd(cmd)
+}
+
+// ApprovePendingSend approves the send held by the signer policy with the
+// passed ID with a one-time password of the second factor.  The TxID of the
+// returned send is set when it was signed and broadcast right away.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) ApprovePendingSend(id uint64, code string) (*btcjson.PendingSendResult, error) {
+	return c.ApprovePendingSendAsync(id, code).Receive()
+}
+
+// FutureCancelPendingSendResult is a future promise to deliver the result of a
+// CancelPendingSendAsync RPC invocation (or an applicable error).
+type FutureCancelPendingSendResult chan *response
+
+// Receive waits for the response promised by the future and returns the result
+// of cancelling the send.
+func (r FutureCancelPendingSendResult) Receive() error {
+	_, err := receiveFuture(r)
+	return err
+}
+
+// CancelPendingSendAsync returns an instance of a type that can be used to get
 // the result of the RPC at some future time by invoking the Receive function on
 // the returned instance.
 //
-// See AddWitnessAddress for the blocking version and more details.
-func (c *Client) AddWitnessAddressAsync(address string) FutureAddWitnessAddressResult {
-	cmd := btcjson.NewAddWitnessAddressCmd(address)
+// See CancelPendingSend for the blocking version and more details.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) CancelPendingSendAsync(id uint64) FutureCancelPendingSendResult {
+	cmd := btcjson.NewCancelPendingSendCmd(id)
 	return c.sendCmd(cmd)
 }
 
-// AddWitnessAddress adds a witness address for a script and returns the new
-// address (P2SH of the witness script).
-func (c *Client) AddWitnessAddress(address string) (btcutil.Address, error) {
-	return c.AddWitnessAddressAsync(address).Receive()
+// CancelPendingSend drops the send held by the signer policy with the passed
+// ID, so it is never signed.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) CancelPendingSend(id uint64) error {
+	return c.CancelPendingSendAsync(id).Receive()
 }
 
-// FutureGetAccountAddressResult is a future promise to deliver the result of a
-// GetAccountAddressAsync RPC invocation (or an applicable error).
-type FutureGetAccountAddressResult chan *response
+// FutureScheduleSendResult is a future promise to deliver the result of a
+// ScheduleSendAsync RPC invocation (or an applicable error).
+type FutureScheduleSendResult chan *response
 
-// Receive waits for the response promised by the future and returns the current
-// Bitcoin address for receiving payments to the specified account.
-func (r FutureGetAccountAddressResult) Receive() (btcutil.Address, error) {
+// Receive waits for the response promised by the future and returns the
+// scheduled send.
+func (r FutureScheduleSendResult) Receive() (*btcjson.ScheduledSendResult, error) {
 	res, err := receiveFuture(r)
 	if err != nil {
 		return nil, err
 	}
 
-	// Unmarshal result as a string.
-	var addr string
-	err = json.Unmarshal(res, &addr)
+	var send btcjson.ScheduledSendResult
+	err = json.Unmarshal(res, &send)
 	if err != nil {
 		return nil, err
 	}
 
-	return btcutil.DecodeAddress(addr, &chaincfg.MainNetParams)
+	return &send, nil
 }
 
-// GetAccountAddressAsync returns an instance of a type that can be used to get
-// the result of the RPC at some future time by invoking the Receive function on
+// ScheduleSendAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on
 // the returned instance.
 //
-// See GetAccountAddress for the blocking version and more details.
-func (c *Client) GetAccountAddressAsync(account string) FutureGetAccountAddressResult {
-	cmd := btcjson.NewGetAccountAddressCmd(account)
+// See ScheduleSend for the blocking version and more details.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) ScheduleSendAsync(fromAccount string,
+	amounts map[btcutil.Address]btcutil.Amount, at int64, mode *string,
+	every *int64, count *int) FutureScheduleSendResult {
+
+	convertedAmounts := make(map[string]float64, len(amounts))
+	for addr, amount := range amounts {
+		convertedAmounts[addr.EncodeAddress()] = amount.ToBTC()
+	}
+	cmd := btcjson.NewScheduleSendCmd(fromAccount, convertedAmounts, at,
+		mode, every, count)
 	return c.sendCmd(cmd)
 }
 
-// GetAccountAddress returns the current Bitcoin address for receiving payments
-// to the specified account.
-func (c *Client) GetAccountAddress(account string) (btcutil.Address, error) {
-	return c.GetAccountAddressAsync(account).Receive()
+// ScheduleSend schedules paying the passed amounts from the passed account at
+// a block height, when at is below 500000000, or a Unix time otherwise.  The
+// mode is btcjson.ScheduleModeHold or btcjson.ScheduleModeLockTime, and the
+// send is repeated every so many blocks or seconds for count payments in
+// total, or until cancelled when count is 0.  Nil options make a single
+// payment in hold mode.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) ScheduleSend(fromAccount string,
+	amounts map[btcutil.Address]btcutil.Amount, at int64, mode *string,
+	every *int64, count *int) (*btcjson.ScheduledSendResult, error) {
+
+	return c.ScheduleSendAsync(fromAccount, amounts, at, mode, every,
+		count).Receive()
 }
 
-// FutureGetAccountResult is a future promise to deliver the result of a
-// GetAccountAsync RPC invocation (or an applicable error).
-type FutureGetAccountResult chan *response
+// FutureListScheduledResult is a future promise to deliver the result of a
+// ListScheduledAsync RPC invocation (or an applicable error).
+type FutureListScheduledResult chan *response
 
-// Receive waits for the response promised by the future and returns the account
-// associated with the passed address.
-func (r FutureGetAccountResult) Receive() (string, error) {
+// Receive waits for the response promised by the future and returns the
+// scheduled sends.
+func (r FutureListScheduledResult) Receive() ([]btcjson.ScheduledSendResult, error) {
 	res, err := receiveFuture(r)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	// Unmarshal result as a string.
-	var account string
-	err = json.Unmarshal(res, &account)
+	var sends []btcjson.ScheduledSendResult
+	err = json.Unmarshal(res, &sends)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return account, nil
+	return sends, nil
 }
 
-// GetAccountAsync returns an instance of a type that can be used to get the
-// result of the RPC at some future time by invoking the Receive function on the
-// returned instance.
+// ListScheduledAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
 //
-// See GetAccount for the blocking version and more details.
-func (c *Client) GetAccountAsync(address btcutil.Address) FutureGetAccountResult {
-	addr := address.EncodeAddress()
-	cmd := btcjson.NewGetAccountCmd(addr)
+// See ListScheduled for the blocking version and more details.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) ListScheduledAsync() FutureListScheduledResult {
+	cmd := btcjson.NewListScheduledCmd()
 	return c.sendCmd(cmd)
 }
 
-// GetAccount returns the account associated with the passed address.
-func (c *Client) GetAccount(address btcutil.Address) (string, error) {
-	return c.GetAccountAsync(address).Receive()
+// ListScheduled returns the sends scheduled by ScheduleSend.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) ListScheduled() ([]btcjson.ScheduledSendResult, error) {
+	return c.ListScheduledAsync().Receive()
 }
 
-// FutureSetAccountResult is a future promise to deliver the result of a
-// SetAccountAsync RPC invocation (or an applicable error).
-type FutureSetAccountResult chan *response
+// FutureCancelScheduledResult is a future promise to deliver the result of a
+// CancelScheduledAsync RPC invocation (or an applicable error).
+type FutureCancelScheduledResult chan *response
 
 // Receive waits for the response promised by the future and returns the result
-// of setting the account to be associated with the passed address.
-func (r FutureSetAccountResult) Receive() error {
+// of cancelling the send.
+func (r FutureCancelScheduledResult) Receive() error {
 	_, err := receiveFuture(r)
 	return err
 }
 
-// SetAccountAsync returns an instance of a type that can be used to get the
-// result of the RPC at some future time by invoking the Receive function on the
-// returned instance.
+// CancelScheduledAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function
+// on the returned instance.
 //
-// See SetAccount for the blocking version and more details.
-func (c *Client) SetAccountAsync(address btcutil.Address, account string) FutureSetAccountResult {
-	addr := address.EncodeAddress()
-	cmd := btcjson.NewSetAccountCmd(addr, account)
+// See CancelScheduled for the blocking version and more details.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) CancelScheduledAsync(id uint64) FutureCancelScheduledResult {
+	cmd := btcjson.NewCancelScheduledCmd(id)
 	return c.sendCmd(cmd)
 }
 
-// SetAccount sets the account associated with the passed address.
-func (c *Client) SetAccount(address btcutil.Address, account string) error {
-	return c.SetAccountAsync(address, account).Receive()
+// CancelScheduled unschedules the send with the passed ID.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) CancelScheduled(id uint64) error {
+	return c.CancelScheduledAsync(id).Receive()
 }
 
-// FutureGetAddressesByAccountResult is a future promise to deliver the result
-// of a GetAddressesByAccountAsync RPC invocation (or an applicable error).
-type FutureGetAddressesByAccountResult chan *response
+// FutureSetPaymentTemplateResult is a future promise to deliver the result of a
+// SetPaymentTemplateAsync RPC invocation (or an applicable error).
+type FutureSetPaymentTemplateResult chan *response
 
-// Receive waits for the response promised by the future and returns the list of
-// addresses associated with the passed account.
-func (r FutureGetAddressesByAccountResult) Receive() ([]btcutil.Address, error) {
+// Receive waits for the response promised by the future and returns the result
+// of setting the payment template.
+func (r FutureSetPaymentTemplateResult) Receive() error {
+	_, err := receiveFuture(r)
+	return err
+}
+
+// SetPaymentTemplateAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See SetPaymentTemplate for the blocking version and more details.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) SetPaymentTemplateAsync(template *btcjson.PaymentTemplate) FutureSetPaymentTemplateResult {
+	cmd := btcjson.NewSetPaymentTemplateCmd(*template)
+	return c.sendCmd(cmd)
+}
+
+// SetPaymentTemplate adds the passed payment template to the wallet, or
+// replaces the template with the same name.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) SetPaymentTemplate(template *btcjson.PaymentTemplate) error {
+	return c.SetPaymentTemplateAsync(template).Receive()
+}
+
+// FutureRemovePaymentTemplateResult is a future promise to deliver the result
+// of a RemovePaymentTemplateAsync RPC invocation (or an applicable error).
+type FutureRemovePaymentTemplateResult chan *response
+
+// Receive waits for the response promised by the future and returns the result
+// of removing the payment template.
+func (r FutureRemovePaymentTemplateResult) Receive() error {
+	_, err := receiveFuture(r)
+	return err
+}
+
+// RemovePaymentTemplateAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See RemovePaymentTemplate for the blocking version and more details.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) RemovePaymentTemplateAsync(name string) FutureRemovePaymentTemplateResult {
+	cmd := btcjson.NewRemovePaymentTemplateCmd(name)
+	return c.sendCmd(cmd)
+}
+
+// RemovePaymentTemplate stops the runs of the payment template with the passed
+// name.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) RemovePaymentTemplate(name string) error {
+	return c.RemovePaymentTemplateAsync(name).Receive()
+}
+
+// FutureListPaymentTemplatesResult is a future promise to deliver the result of
+// a ListPaymentTemplatesAsync RPC invocation (or an applicable error).
+type FutureListPaymentTemplatesResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// payment templates.
+func (r FutureListPaymentTemplatesResult) Receive() ([]btcjson.PaymentTemplateResult, error) {
 	res, err := receiveFuture(r)
 	if err != nil {
 		return nil, err
 	}
 
-	// Unmashal result as an array of string.
-	var addrStrings []string
-	err = json.Unmarshal(res, &addrStrings)
+	var templates []btcjson.PaymentTemplateResult
+	err = json.Unmarshal(res, &templates)
 	if err != nil {
 		return nil, err
 	}
 
-	addrs := make([]btcutil.Address, 0, len(addrStrings))
-	for _, addrStr := range addrStrings {
-		addr, err := btcutil.DecodeAddress(addrStr,
-			&chaincfg.MainNetParams)
-		if err != nil {
-			return nil, err
-		}
-		addrs = append(addrs, addr)
-	}
-
-	return addrs, nil
+	return templates, nil
 }
 
-// GetAddressesByAccountAsync returns an instance of a type that can be used to
+// ListPaymentTemplatesAsync returns an instance of a type that can be used to
 // get the result of the RPC at some future time by invoking the Receive
 // function on the returned instance.
 //
-// See GetAddressesByAccount for the blocking version and more details.
-func (c *Client) GetAddressesByAccountAsync(account string) FutureGetAddressesByAccountResult {
-	cmd := btcjson.NewGetAddressesByAccountCmd(account)
+// See ListPaymentTemplates for the blocking version and more details.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) ListPaymentTemplatesAsync() FutureListPaymentTemplatesResult {
+	cmd := btcjson.NewListPaymentTemplatesCmd()
 	return c.sendCmd(cmd)
 }
 
-// GetAddressesByAccount returns the list of addresses associated with the
-// passed account.
-func (c *Client) GetAddressesByAccount(account string) ([]btcutil.Address, error) {
-	return c.GetAddressesByAccountAsync(account).Receive()
+// ListPaymentTemplates returns the payment templates of the wallet and the
+// state of their current runs.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) ListPaymentTemplates() ([]btcjson.PaymentTemplateResult, error) {
+	return c.ListPaymentTemplatesAsync().Receive()
 }
 
-// FutureMoveResult is a future promise to deliver the result of a MoveAsync,
-// MoveMinConfAsync, or MoveCommentAsync RPC invocation (or an applicable
-// error).
-type FutureMoveResult chan *response
+// FutureGetPaymentReportResult is a future promise to deliver the result of a
+// GetPaymentReportAsync RPC invocation (or an applicable error).
+type FutureGetPaymentReportResult chan *response
 
-// Receive waits for the response promised by the future and returns the result
-// of the move operation.
-func (r FutureMoveResult) Receive() (bool, error) {
+// Receive waits for the response promised by the future and returns the
+// completed payment runs.
+func (r FutureGetPaymentReportResult) Receive() ([]btcjson.PaymentRunResult, error) {
 	res, err := receiveFuture(r)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
-	// Unmarshal result as a boolean.
-	var moveResult bool
-	err = json.Unmarshal(res, &moveResult)
+	var runs []btcjson.PaymentRunResult
+	err = json.Unmarshal(res, &runs)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
-	return moveResult, nil
+	return runs, nil
 }
 
-// MoveAsync returns an instance of a type that can be used to get the result of
-// the RPC at some future time by invoking the Receive function on the returned
-// instance.
+// GetPaymentReportAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function
+// on the returned instance.
 //
-// See Move for the blocking version and more details.
-func (c *Client) MoveAsync(fromAccount, toAccount string, amount btcutil.Amount) FutureMoveResult {
-	cmd := btcjson.NewMoveCmd(fromAccount, toAccount, amount.ToBTC(), nil,
-		nil)
+// See GetPaymentReport for the blocking version and more details.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) GetPaymentReportAsync(name *string, count *int) FutureGetPaymentReportResult {
+	cmd := btcjson.NewGetPaymentReportCmd(name, count)
 	return c.sendCmd(cmd)
 }
 
-// Move moves specified amount from one account in your wallet to another.  Only
-// funds with the default number of minimum confirmations will be used.
+// GetPaymentReport returns the last count completed runs of the payment
+// template with the passed name, or of every template when it is nil, most
+// recent first.  Nil options report the last 50 runs of every template.
 //
-// See MoveMinConf and MoveComment for different options.
-func (c *Client) Move(fromAccount, toAccount string, amount btcutil.Amount) (bool, error) {
-	return c.MoveAsync(fromAccount, toAccount, amount).Receive()
+// NOTE: This is a pktwallet extension.
+func (c *Client) GetPaymentReport(name *string, count *int) ([]btcjson.PaymentRunResult, error) {
+	return c.GetPaymentReportAsync(name, count).Receive()
 }
 
-// MoveMinConfAsync returns an instance of a type that can be used to get the
-// result of the RPC at some future time by invoking the Receive function on the
-// returned instance.
-//
-// See MoveMinConf for the blocking version and more details.
-func (c *Client) MoveMinConfAsync(fromAccount, toAccount string,
-	amount btcutil.Amount, minConfirms int) FutureMoveResult {
+// *************************
+// Address/Account Functions
+// *************************
 
-	cmd := btcjson.NewMoveCmd(fromAccount, toAccount, amount.ToBTC(),
-		&minConfirms, nil)
-	return c.sendCmd(cmd)
-}
+// FutureAddMultisigAddressResult is a future promise to deliver the result of a
+// AddMultisigAddressAsync RPC invocation (or an applicable error).
+type FutureAddMultisigAddressResult chan *response
 
-// MoveMinConf moves specified amount from one account in your wallet to
-// another.  Only funds with the passed number of minimum confirmations will be
-// used.
-//
-// See Move to use the default number of minimum confirmations and MoveComment
-// for additional options.
-func (c *Client) MoveMinConf(fromAccount, toAccount string, amount btcutil.Amount, minConf int) (bool, error) {
-	return c.MoveMinConfAsync(fromAccount, toAccount, amount, minConf).Receive()
-}
+// Receive waits for the response promised by the future and returns the
+// multisignature address that requires the specified number of signatures for
+// the provided addresses.
+func (r FutureAddMultisigAddressResult) Receive() (btcutil.Address, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
 
-// MoveCommentAsync returns an instance of a type that can be used to get the
-// result of the RPC at some future time by invoking the Receive function on the
-// returned instance.
-//
-// See MoveComment for the blocking version and more details.
-func (c *Client) MoveCommentAsync(fromAccount, toAccount string,
-	amount btcutil.Amount, minConfirms int, comment string) FutureMoveResult {
+	// Unmarshal result as a string.
+	var addr string
+	err = json.Unmarshal(res, &addr)
+	if err != nil {
+		return nil, err
+	}
 
-	cmd := btcjson.NewMoveCmd(fromAccount, toAccount, amount.ToBTC(),
-		&minConfirms, &comment)
-	return c.sendCmd(cmd)
+	return btcutil.DecodeAddress(addr, &chaincfg.MainNetParams)
 }
 
-// MoveComment moves specified amount from one account in your wallet to
-// another and stores the provided comment in the wallet.  The comment
-// parameter is only available in the wallet.  Only funds with the passed number
-// of minimum confirmations will be used.
+// AddMultisigAddressAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
 //
-// See Move and MoveMinConf to use defaults.
-func (c *Client) MoveComment(fromAccount, toAccount string, amount btcutil.Amount,
-	minConf int, comment string) (bool, error) {
-
-	return c.MoveCommentAsync(fromAccount, toAccount, amount, minConf,
-		comment).Receive()
-}
-
-// FutureRenameAccountResult is a future promise to deliver the result of a
-// RenameAccountAsync RPC invocation (or an applicable error).
-type FutureRenameAccountResult chan *response
-
-// Receive waits for the response promised by the future and returns the
-// result of creating new account.
-func (r FutureRenameAccountResult) Receive() error {
-	_, err := receiveFuture(r)
-	return err
-}
+// See AddMultisigAddress for the blocking version and more details.
+func (c *Client) AddMultisigAddressAsync(requiredSigs int, addresses []btcutil.Address, account string) FutureAddMultisigAddressResult {
+	addrs := make([]string, 0, len(addresses))
+	for _, addr := range addresses {
+		addrs = append(addrs, addr.String())
+	}
 
-// RenameAccountAsync returns an instance of a type that can be used to get the
-// result of the RPC at some future time by invoking the Receive function on the
-// returned instance.
-//
-// See RenameAccount for the blocking version and more details.
-func (c *Client) RenameAccountAsync(oldAccount, newAccount string) FutureRenameAccountResult {
-	cmd := btcjson.NewRenameAccountCmd(oldAccount, newAccount)
+	cmd := btcjson.NewAddMultisigAddressCmd(requiredSigs, addrs, &account)
 	return c.sendCmd(cmd)
 }
 
-// RenameAccount creates a new wallet account.
-func (c *Client) RenameAccount(oldAccount, newAccount string) error {
-	return c.RenameAccountAsync(oldAccount, newAccount).Receive()
+// AddMultisigAddress adds a multisignature address that requires the specified
+// number of signatures for the provided addresses to the wallet.
+func (c *Client) AddMultisigAddress(requiredSigs int, addresses []btcutil.Address, account string) (btcutil.Address, error) {
+	return c.AddMultisigAddressAsync(requiredSigs, addresses,
+		account).Receive()
 }
 
-// FutureValidateAddressResult is a future promise to deliver the result of a
-// ValidateAddressAsync RPC invocation (or an applicable error).
-type FutureValidateAddressResult chan *response
+// FutureCreateMultisigResult is a future promise to deliver the result of a
+// CreateMultisigAsync RPC invocation (or an applicable error).
+type FutureCreateMultisigResult chan *response
 
-// Receive waits for the response promised by the future and returns information
-// about the given bitcoin address.
-func (r FutureValidateAddressResult) Receive() (*btcjson.ValidateAddressWalletResult, error) {
+// Receive waits for the response promised by the future and returns the
+// multisignature address and script needed to redeem it.
+func (r FutureCreateMultisigResult) Receive() (*btcjson.CreateMultiSigResult, error) {
 	res, err := receiveFuture(r)
 	if err != nil {
 		return nil, err
 	}
 
-	// Unmarshal result as a validateaddress result object.
-	var addrResult btcjson.ValidateAddressWalletResult
-	err = json.Unmarshal(res, &addrResult)
+	// Unmarshal result as a createmultisig result object.
+	var multisigRes btcjson.CreateMultiSigResult
+	err = json.Unmarshal(res, &multisigRes)
 	if err != nil {
 		return nil, err
 	}
 
-	return &addrResult, nil
+	return &multisigRes, nil
 }
 
-// ValidateAddressAsync returns an instance of a type that can be used to get
+// CreateMultisigAsync returns an instance of a type that can be used to get
 // the result of the RPC at some future time by invoking the Receive function on
 // the returned instance.
 //
-// See ValidateAddress for the blocking version and more details.
-func (c *Client) ValidateAddressAsync(address btcutil.Address) FutureValidateAddressResult {
-	addr := address.EncodeAddress()
-	cmd := btcjson.NewValidateAddressCmd(addr)
+// See CreateMultisig for the blocking version and more details.
+func (c *Client) CreateMultisigAsync(requiredSigs int, addresses []btcutil.Address) FutureCreateMultisigResult {
+	addrs := make([]string, 0, len(addresses))
+	for _, addr := range addresses {
+		addrs = append(addrs, addr.String())
+	}
+
+	cmd := btcjson.NewCreateMultisigCmd(requiredSigs, addrs)
 	return c.sendCmd(cmd)
 }
 
-// ValidateAddress returns information about the given bitcoin address.
-func (c *Client) ValidateAddress(address btcutil.Address) (*btcjson.ValidateAddressWalletResult, error) {
-	return c.ValidateAddressAsync(address).Receive()
+// CreateMultisig creates a multisignature address that requires the specified
+// number of signatures for the provided addresses and returns the
+// multisignature address and script needed to redeem it.
+func (c *Client) CreateMultisig(requiredSigs int, addresses []btcutil.Address) (*btcjson.CreateMultiSigResult, error) {
+	return c.CreateMultisigAsync(requiredSigs, addresses).Receive()
 }
 
-// FutureKeyPoolRefillResult is a future promise to deliver the result of a
-// KeyPoolRefillAsync RPC invocation (or an applicable error).
-type FutureKeyPoolRefillResult chan *response
+// FutureCreateNewAccountResult is a future promise to deliver the result of a
+// CreateNewAccountAsync RPC invocation (or an applicable error).
+type FutureCreateNewAccountResult chan *response
 
-// Receive waits for the response promised by the future and returns the result
-// of refilling the key pool.
-func (r FutureKeyPoolRefillResult) Receive() error {
+// Receive waits for the response promised by the future and returns the
+// result of creating new account.
+func (r FutureCreateNewAccountResult) Receive() error {
 	_, err := receiveFuture(r)
 	return err
 }
 
-// KeyPoolRefillAsync returns an instance of a type that can be used to get the
+// CreateNewAccountAsync returns an instance of a type that can be used to get the
 // result of the RPC at some future time by invoking the Receive function on the
 // returned instance.
 //
-// See KeyPoolRefill for the blocking version and more details.
-func (c *Client) KeyPoolRefillAsync() FutureKeyPoolRefillResult {
-	cmd := btcjson.NewKeyPoolRefillCmd(nil)
+// See CreateNewAccount for the blocking version and more details.
+func (c *Client) CreateNewAccountAsync(account string) FutureCreateNewAccountResult {
+	cmd := btcjson.NewCreateNewAccountCmd(account)
 	return c.sendCmd(cmd)
 }
 
-// KeyPoolRefill fills the key pool as necessary to reach the default size.
-//
-// See KeyPoolRefillSize to override the size of the key pool.
-func (c *Client) KeyPoolRefill() error {
-	return c.KeyPoolRefillAsync().Receive()
+// CreateNewAccount creates a new wallet account.
+func (c *Client) CreateNewAccount(account string) error {
+	return c.CreateNewAccountAsync(account).Receive()
 }
 
-// KeyPoolRefillSizeAsync returns an instance of a type that can be used to get
+// CreateSubAccountAsync returns an instance of a type that can be used to get
 // the result of the RPC at some future time by invoking the Receive function on
 // the returned instance.
 //
-// See KeyPoolRefillSize for the blocking version and more details.
-func (c *Client) KeyPoolRefillSizeAsync(newSize uint) FutureKeyPoolRefillResult {
-	cmd := btcjson.NewKeyPoolRefillCmd(&newSize)
+// See CreateSubAccount for the blocking version and more details.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) CreateSubAccountAsync(account, parent, label string) FutureCreateNewAccountResult {
+	cmd := btcjson.NewCreateSubAccountCmd(account, parent, &label)
 	return c.sendCmd(cmd)
 }
 
-// KeyPoolRefillSize fills the key pool as necessary to reach the specified
-// size.
-func (c *Client) KeyPoolRefillSize(newSize uint) error {
-	return c.KeyPoolRefillSizeAsync(newSize).Receive()
+// CreateSubAccount creates a new wallet account nested under parent, which is
+// named "parent/account" and bound by the policy of parent.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) CreateSubAccount(account, parent, label string) error {
+	return c.CreateSubAccountAsync(account, parent, label).Receive()
 }
 
-// ************************
-// Amount/Balance Functions
-// ************************
-
-// FutureListAccountsResult is a future promise to deliver the result of a
-// ListAccountsAsync or ListAccountsMinConfAsync RPC invocation (or an
-// applicable error).
-type FutureListAccountsResult chan *response
+// FutureGetAccountPolicyResult is a future promise to deliver the result of a
+// GetAccountPolicyAsync RPC invocation (or an applicable error).
+type FutureGetAccountPolicyResult chan *response
 
-// Receive waits for the response promised by the future and returns returns a
-// map of account names and their associated balances.
-func (r FutureListAccountsResult) Receive() (map[string]btcutil.Amount, error) {
+// Receive waits for the response promised by the future and returns the
+// policy of the account along with its place in the account hierarchy.
+func (r FutureGetAccountPolicyResult) Receive() (*btcjson.AccountPolicyResult, error) {
 	res, err := receiveFuture(r)
 	if err != nil {
 		return nil, err
 	}
 
-	// Unmarshal result as a json object.
-	var accounts map[string]float64
-	err = json.Unmarshal(res, &accounts)
+	var result btcjson.AccountPolicyResult
+	err = json.Unmarshal(res, &result)
 	if err != nil {
 		return nil, err
 	}
+	return &result, nil
+}
 
-	accountsMap := make(map[string]btcutil.Amount)
-	for k, v := range accounts {
-		amount, err := globalcfg.NewAmount(v)
-		if err != nil {
-			return nil, err
-		}
+// GetAccountPolicyAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetAccountPolicy for the blocking version and more details.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) GetAccountPolicyAsync(account string) FutureGetAccountPolicyResult {
+	cmd := btcjson.NewGetAccountPolicyCmd(account)
+	return c.sendCmd(cmd)
+}
 
-		accountsMap[k] = btcutil.Amount(amount)
-	}
+// GetAccountPolicy returns the spending policy of the account, how much of its
+// withdrawal limit has been used and its parent and sub-accounts.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) GetAccountPolicy(account string) (*btcjson.AccountPolicyResult, error) {
+	return c.GetAccountPolicyAsync(account).Receive()
+}
 
-	return accountsMap, nil
+// FutureSetAccountPolicyResult is a future promise to deliver the result of a
+// SetAccountPolicyAsync or SetAccountCredentialsAsync RPC invocation (or an
+// applicable error).
+type FutureSetAccountPolicyResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// result of updating the account.
+func (r FutureSetAccountPolicyResult) Receive() error {
+	_, err := receiveFuture(r)
+	return err
 }
 
-// ListAccountsAsync returns an instance of a type that can be used to get the
-// result of the RPC at some future time by invoking the Receive function on the
-// returned instance.
+// SetAccountPolicyAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
 //
-// See ListAccounts for the blocking version and more details.
-func (c *Client) ListAccountsAsync() FutureListAccountsResult {
-	cmd := btcjson.NewListAccountsCmd(nil)
+// See SetAccountPolicy for the blocking version and more details.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) SetAccountPolicyAsync(account string, policy btcjson.AccountPolicy) FutureSetAccountPolicyResult {
+	cmd := btcjson.NewSetAccountPolicyCmd(account, policy)
 	return c.sendCmd(cmd)
 }
 
-// ListAccounts returns a map of account names and their associated balances
-// using the default number of minimum confirmations.
+// SetAccountPolicy replaces the spending policy of the account.
 //
-// See ListAccountsMinConf to override the minimum number of confirmations.
-func (c *Client) ListAccounts() (map[string]btcutil.Amount, error) {
-	return c.ListAccountsAsync().Receive()
+// NOTE: This is a pktwallet extension.
+func (c *Client) SetAccountPolicy(account string, policy btcjson.AccountPolicy) error {
+	return c.SetAccountPolicyAsync(account, policy).Receive()
 }
 
-// ListAccountsMinConfAsync returns an instance of a type that can be used to
+// SetAccountCredentialsAsync returns an instance of a type that can be used to
 // get the result of the RPC at some future time by invoking the Receive
 // function on the returned instance.
 //
-// See ListAccountsMinConf for the blocking version and more details.
-func (c *Client) ListAccountsMinConfAsync(minConfirms int) FutureListAccountsResult {
-	cmd := btcjson.NewListAccountsCmd(&minConfirms)
+// See SetAccountCredentials for the blocking version and more details.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) SetAccountCredentialsAsync(account, username string,
+	password *string) FutureSetAccountPolicyResult {
+
+	cmd := btcjson.NewSetAccountCredentialsCmd(account, username, password)
 	return c.sendCmd(cmd)
 }
 
-// ListAccountsMinConf returns a map of account names and their associated
-// balances using the specified number of minimum confirmations.
+// SetAccountCredentials sets RPC credentials which only give access to the
+// account and its sub-accounts.  Passing a nil password removes them.
 //
-// See ListAccounts to use the default minimum number of confirmations.
-func (c *Client) ListAccountsMinConf(minConfirms int) (map[string]btcutil.Amount, error) {
-	return c.ListAccountsMinConfAsync(minConfirms).Receive()
+// NOTE: This is a pktwallet extension.
+func (c *Client) SetAccountCredentials(account, username string, password *string) error {
+	return c.SetAccountCredentialsAsync(account, username, password).Receive()
 }
 
-// FutureGetBalanceResult is a future promise to deliver the result of a
-// GetBalanceAsync or GetBalanceMinConfAsync RPC invocation (or an applicable
-// error).
-type FutureGetBalanceResult chan *response
+// FutureAccountXPubResult is a future promise to deliver the result of an
+// ExportAccountXPubAsync or ImportAccountXPubAsync RPC invocation (or an
+// applicable error).
+type FutureAccountXPubResult chan *response
 
 // Receive waits for the response promised by the future and returns the
-// available balance from the server for the specified account.
-func (r FutureGetBalanceResult) Receive() (btcutil.Amount, error) {
+// extended public key and descriptors of the account.
+func (r FutureAccountXPubResult) Receive() (*btcjson.AccountXPubResult, error) {
 	res, err := receiveFuture(r)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	// Unmarshal result as a floating point number.
-	var balance float64
-	err = json.Unmarshal(res, &balance)
+	var result btcjson.AccountXPubResult
+	err = json.Unmarshal(res, &result)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
+	return &result, nil
+}
 
-	amount, err := globalcfg.NewAmount(balance)
-	if err != nil {
-		return 0, err
+// ExportAccountXPubAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See ExportAccountXPub for the blocking version and more details.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) ExportAccountXPubAsync(account string) FutureAccountXPubResult {
+	cmd := btcjson.NewExportAccountXPubCmd(account)
+	return c.sendCmd(cmd)
+}
+
+// ExportAccountXPub returns the extended public key and descriptors of the
+// account, whose bundle may be passed to ImportAccountXPub of a watch-only
+// wallet.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) ExportAccountXPub(account string) (*btcjson.AccountXPubResult, error) {
+	return c.ExportAccountXPubAsync(account).Receive()
+}
+
+// ImportAccountXPubAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See ImportAccountXPub for the blocking version and more details.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) ImportAccountXPubAsync(bundle btcjson.AccountXPubBundle,
+	account string, rescan bool) FutureAccountXPubResult {
+
+	var name *string
+	if account != "" {
+		name = &account
 	}
+	cmd := btcjson.NewImportAccountXPubCmd(bundle, name, &rescan)
+	return c.sendCmd(cmd)
+}
 
-	return btcutil.Amount(amount), nil
+// ImportAccountXPub creates a watch-only account tracking the addresses of an
+// account exported by ExportAccountXPub, named after it when account is
+// empty.  When rescan is true the chain is rescanned from the birth height of
+// the bundle.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) ImportAccountXPub(bundle btcjson.AccountXPubBundle,
+	account string, rescan bool) (*btcjson.AccountXPubResult, error) {
+
+	return c.ImportAccountXPubAsync(bundle, account, rescan).Receive()
 }
 
-// FutureGetBalanceParseResult is same as FutureGetBalanceResult except
-// that the result is expected to be a string which is then parsed into
-// a float64 value
-// This is required for compatibility with servers like blockchain.info
-type FutureGetBalanceParseResult chan *response
+// FutureGetNewAddressResult is a future promise to deliver the result of a
+// GetNewAddressAsync RPC invocation (or an applicable error).
+type FutureGetNewAddressResult chan *response
 
-// Receive waits for the response promised by the future and returns the
-// available balance from the server for the specified account.
-func (r FutureGetBalanceParseResult) Receive() (btcutil.Amount, error) {
+// Receive waits for the response promised by the future and returns a new
+// address.
+func (r FutureGetNewAddressResult) Receive() (btcutil.Address, error) {
 	res, err := receiveFuture(r)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	// Unmarshal result as a string
-	var balanceString string
-	err = json.Unmarshal(res, &balanceString)
+	// Unmarshal result as a string.
+	var addr string
+	err = json.Unmarshal(res, &addr)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	balance, err := strconv.ParseFloat(balanceString, 64)
+	return btcutil.DecodeAddress(addr, &chaincfg.MainNetParams)
+}
+
+// GetNewAddressAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See GetNewAddress for the blocking version and more details.
+func (c *Client) GetNewAddressAsync(account string) FutureGetNewAddressResult {
+	cmd := btcjson.NewGetNewAddressCmd(&account)
+	return c.sendCmd(cmd)
+}
+
+// GetNewAddress returns a new address.
+func (c *Client) GetNewAddress(account string) (btcutil.Address, error) {
+	return c.GetNewAddressAsync(account).Receive()
+}
+
+// FutureGetRawChangeAddressResult is a future promise to deliver the result of
+// a GetRawChangeAddressAsync RPC invocation (or an applicable error).
+type FutureGetRawChangeAddressResult chan *response
+
+// Receive waits for the response promised by the future and returns a new
+// address for receiving change that will be associated with the provided
+// account.  Note that this is only for raw transactions and NOT for normal use.
+func (r FutureGetRawChangeAddressResult) Receive() (btcutil.Address, error) {
+	res, err := receiveFuture(r)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-	amount, err := globalcfg.NewAmount(balance)
+
+	// Unmarshal result as a string.
+	var addr string
+	err = json.Unmarshal(res, &addr)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	return btcutil.Amount(amount), nil
+	return btcutil.DecodeAddress(addr, &chaincfg.MainNetParams)
 }
 
-// GetBalanceAsync returns an instance of a type that can be used to get the
-// result of the RPC at some future time by invoking the Receive function on the
-// returned instance.
+// GetRawChangeAddressAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
 //
-// See GetBalance for the blocking version and more details.
-func (c *Client) GetBalanceAsync(account string) FutureGetBalanceResult {
-	cmd := btcjson.NewGetBalanceCmd(&account, nil)
+// See GetRawChangeAddress for the blocking version and more details.
+func (c *Client) GetRawChangeAddressAsync(account string) FutureGetRawChangeAddressResult {
+	cmd := btcjson.NewGetRawChangeAddressCmd(&account)
 	return c.sendCmd(cmd)
 }
 
-// GetBalance returns the available balance from the server for the specified
-// account using the default number of minimum confirmations.  The account may
-// be "*" for all accounts.
-//
-// See GetBalanceMinConf to override the minimum number of confirmations.
-func (c *Client) GetBalance(account string) (btcutil.Amount, error) {
-	return c.GetBalanceAsync(account).Receive()
+// GetRawChangeAddress returns a new address for receiving change that will be
+// associated with the provided account.  Note that this is only for raw
+// transactions and NOT for normal use.
+func (c *Client) GetRawChangeAddress(account string) (btcutil.Address, error) {
+	return c.GetRawChangeAddressAsync(account).Receive()
 }
 
-// GetBalanceMinConfAsync returns an instance of a type that can be used to get
+// FutureAddWitnessAddressResult is a future promise to deliver the result of
+// a AddWitnessAddressAsync RPC invocation (or an applicable error).
+type FutureAddWitnessAddressResult chan *response
+
+// Receive waits for the response promised by the future and returns the new
+// address.
+func (r FutureAddWitnessAddressResult) Receive() (btcutil.Address, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a string.
+	var addr string
+	err = json.Unmarshal(res, &addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return btcutil.DecodeAddress(addr, &chaincfg.MainNetParams)
+}
+
+// AddWitnessAddressAsync returns an instance of a type that can be used to get
 // the result of the RPC at some future time by invoking the Receive function on
 // the returned instance.
 //
-// See GetBalanceMinConf for the blocking version and more details.
-func (c *Client) GetBalanceMinConfAsync(account string, minConfirms int) FutureGetBalanceResult {
-	cmd := btcjson.NewGetBalanceCmd(&account, &minConfirms)
+// See AddWitnessAddress for the blocking version and more details.
+func (c *Client) AddWitnessAddressAsync(address string) FutureAddWitnessAddressResult {
+	cmd := btcjson.NewAddWitnessAddressCmd(address)
 	return c.sendCmd(cmd)
 }
 
-// GetBalanceMinConf returns the available balance from the server for the
-// specified account using the specified number of minimum confirmations.  The
-// account may be "*" for all accounts.
+// AddWitnessAddress adds a witness address for a script and returns the new
+// address (P2SH of the witness script).
+func (c *Client) AddWitnessAddress(address string) (btcutil.Address, error) {
+	return c.AddWitnessAddressAsync(address).Receive()
+}
+
+// FutureGetAccountAddressResult is a future promise to deliver the result of a
+// GetAccountAddressAsync RPC invocation (or an applicable error).
+type FutureGetAccountAddressResult chan *response
+
+// Receive waits for the response promised by the future and returns the current
+// Bitcoin address for receiving payments to the specified account.
+func (r FutureGetAccountAddressResult) Receive() (btcutil.Address, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a string.
+	var addr string
+	err = json.Unmarshal(res, &addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return btcutil.DecodeAddress(addr, &chaincfg.MainNetParams)
+}
+
+// GetAccountAddressAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
 //
-// See GetBalance to use the default minimum number of confirmations.
-func (c *Client) GetBalanceMinConf(account string, minConfirms int) (btcutil.Amount, error) {
-	if c.config.EnableBCInfoHacks {
-		response := c.GetBalanceMinConfAsync(account, minConfirms)
-		return FutureGetBalanceParseResult(response).Receive()
+// See GetAccountAddress for the blocking version and more details.
+func (c *Client) GetAccountAddressAsync(account string) FutureGetAccountAddressResult {
+	cmd := btcjson.NewGetAccountAddressCmd(account)
+	return c.sendCmd(cmd)
+}
+
+// GetAccountAddress returns the current Bitcoin address for receiving payments
+// to the specified account.
+func (c *Client) GetAccountAddress(account string) (btcutil.Address, error) {
+	return c.GetAccountAddressAsync(account).Receive()
+}
+
+// FutureGetAccountResult is a future promise to deliver the result of a
+// GetAccountAsync RPC invocation (or an applicable error).
+type FutureGetAccountResult chan *response
+
+// Receive waits for the response promised by the future and returns the account
+// associated with the passed address.
+func (r FutureGetAccountResult) Receive() (string, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return "", err
 	}
-	return c.GetBalanceMinConfAsync(account, minConfirms).Receive()
+
+	// Unmarshal result as a string.
+	var account string
+	err = json.Unmarshal(res, &account)
+	if err != nil {
+		return "", err
+	}
+
+	return account, nil
+}
+
+// GetAccountAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See GetAccount for the blocking version and more details.
+func (c *Client) GetAccountAsync(address btcutil.Address) FutureGetAccountResult {
+	addr := address.EncodeAddress()
+	cmd := btcjson.NewGetAccountCmd(addr)
+	return c.sendCmd(cmd)
+}
+
+// GetAccount returns the account associated with the passed address.
+func (c *Client) GetAccount(address btcutil.Address) (string, error) {
+	return c.GetAccountAsync(address).Receive()
+}
+
+// FutureSetAccountResult is a future promise to deliver the result of a
+// SetAccountAsync RPC invocation (or an applicable error).
+type FutureSetAccountResult chan *response
+
+// Receive waits for the response promised by the future and returns the result
+// of setting the account to be associated with the passed address.
+func (r FutureSetAccountResult) Receive() error {
+	_, err := receiveFuture(r)
+	return err
+}
+
+// SetAccountAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See SetAccount for the blocking version and more details.
+func (c *Client) SetAccountAsync(address btcutil.Address, account string) FutureSetAccountResult {
+	addr := address.EncodeAddress()
+	cmd := btcjson.NewSetAccountCmd(addr, account)
+	return c.sendCmd(cmd)
+}
+
+// SetAccount sets the account associated with the passed address.
+func (c *Client) SetAccount(address btcutil.Address, account string) error {
+	return c.SetAccountAsync(address, account).Receive()
+}
+
+// FutureGetAddressesByAccountResult is a future promise to deliver the result
+// of a GetAddressesByAccountAsync RPC invocation (or an applicable error).
+type FutureGetAddressesByAccountResult chan *response
+
+// Receive waits for the response promised by the future and returns the list of
+// addresses associated with the passed account.
+func (r FutureGetAddressesByAccountResult) Receive() ([]btcutil.Address, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmashal result as an array of string.
+	var addrStrings []string
+	err = json.Unmarshal(res, &addrStrings)
+	if err != nil {
+		return nil, err
+	}
+
+	addrs := make([]btcutil.Address, 0, len(addrStrings))
+	for _, addrStr := range addrStrings {
+		addr, err := btcutil.DecodeAddress(addrStr,
+			&chaincfg.MainNetParams)
+		if err != nil {
+			return nil, err
+		}
+		addrs = append(addrs, addr)
+	}
+
+	return addrs, nil
+}
+
+// GetAddressesByAccountAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See GetAddressesByAccount for the blocking version and more details.
+func (c *Client) GetAddressesByAccountAsync(account string) FutureGetAddressesByAccountResult {
+	cmd := btcjson.NewGetAddressesByAccountCmd(account)
+	return c.sendCmd(cmd)
+}
+
+// GetAddressesByAccount returns the list of addresses associated with the
+// passed account.
+func (c *Client) GetAddressesByAccount(account string) ([]btcutil.Address, error) {
+	return c.GetAddressesByAccountAsync(account).Receive()
+}
+
+// FutureMoveResult is a future promise to deliver the result of a MoveAsync,
+// MoveMinConfAsync, or MoveCommentAsync RPC invocation (or an applicable
+// error).
+type FutureMoveResult chan *response
+
+// Receive waits for the response promised by the future and returns the result
+// of the move operation.
+func (r FutureMoveResult) Receive() (bool, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return false, err
+	}
+
+	// Unmarshal result as a boolean.
+	var moveResult bool
+	err = json.Unmarshal(res, &moveResult)
+	if err != nil {
+		return false, err
+	}
+
+	return moveResult, nil
+}
+
+// MoveAsync returns an instance of a type that can be used to get the result of
+// the RPC at some future time by invoking the Receive function on the returned
+// instance.
+//
+// See Move for the blocking version and more details.
+func (c *Client) MoveAsync(fromAccount, toAccount string, amount btcutil.Amount) FutureMoveResult {
+	cmd := btcjson.NewMoveCmd(fromAccount, toAccount, amount.ToBTC(), nil,
+		nil)
+	return c.sendCmd(cmd)
+}
+
+// Move moves specified amount from one account in your wallet to another.  Only
+// funds with the default number of minimum confirmations will be used.
+//
+// See MoveMinConf and MoveComment for different options.
+func (c *Client) Move(fromAccount, toAccount string, amount btcutil.Amount) (bool, error) {
+	return c.MoveAsync(fromAccount, toAccount, amount).Receive()
+}
+
+// MoveMinConfAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See MoveMinConf for the blocking version and more details.
+func (c *Client) MoveMinConfAsync(fromAccount, toAccount string,
+	amount btcutil.Amount, minConfirms int) FutureMoveResult {
+
+	cmd := btcjson.NewMoveCmd(fromAccount, toAccount, amount.ToBTC(),
+		&minConfirms, nil)
+	return c.sendCmd(cmd)
+}
+
+// MoveMinConf moves specified amount from one account in your wallet to
+// another.  Only funds with the passed number of minimum confirmations will be
+// used.
+//
+// See Move to use the default number of minimum confirmations and MoveComment
+// for additional options.
+func (c *Client) MoveMinConf(fromAccount, toAccount string, amount btcutil.Amount, minConf int) (bool, error) {
+	return c.MoveMinConfAsync(fromAccount, toAccount, amount, minConf).Receive()
+}
+
+// MoveCommentAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See MoveComment for the blocking version and more details.
+func (c *Client) MoveCommentAsync(fromAccount, toAccount string,
+	amount btcutil.Amount, minConfirms int, comment string) FutureMoveResult {
+
+	cmd := btcjson.NewMoveCmd(fromAccount, toAccount, amount.ToBTC(),
+		&minConfirms, &comment)
+	return c.sendCmd(cmd)
+}
+
+// MoveComment moves specified amount from one account in your wallet to
+// another and stores the provided comment in the wallet.  The comment
+// parameter is only available in the wallet.  Only funds with the passed number
+// of minimum confirmations will be used.
+//
+// See Move and MoveMinConf to use defaults.
+func (c *Client) MoveComment(fromAccount, toAccount string, amount btcutil.Amount,
+	minConf int, comment string) (bool, error) {
+
+	return c.MoveCommentAsync(fromAccount, toAccount, amount, minConf,
+		comment).Receive()
+}
+
+// FutureRenameAccountResult is a future promise to deliver the result of a
+// RenameAccountAsync RPC invocation (or an applicable error).
+type FutureRenameAccountResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// result of creating new account.
+func (r FutureRenameAccountResult) Receive() error {
+	_, err := receiveFuture(r)
+	return err
+}
+
+// RenameAccountAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See RenameAccount for the blocking version and more details.
+func (c *Client) RenameAccountAsync(oldAccount, newAccount string) FutureRenameAccountResult {
+	cmd := btcjson.NewRenameAccountCmd(oldAccount, newAccount)
+	return c.sendCmd(cmd)
+}
+
+// RenameAccount creates a new wallet account.
+func (c *Client) RenameAccount(oldAccount, newAccount string) error {
+	return c.RenameAccountAsync(oldAccount, newAccount).Receive()
+}
+
+// FutureValidateAddressResult is a future promise to deliver the result of a
+// ValidateAddressAsync RPC invocation (or an applicable error).
+type FutureValidateAddressResult chan *response
+
+// Receive waits for the response promised by the future and returns information
+// about the given bitcoin address.
+func (r FutureValidateAddressResult) Receive() (*btcjson.ValidateAddressWalletResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a validateaddress result object.
+	var addrResult btcjson.ValidateAddressWalletResult
+	err = json.Unmarshal(res, &addrResult)
+	if err != nil {
+		return nil, err
+	}
+
+	return &addrResult, nil
+}
+
+// ValidateAddressAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See ValidateAddress for the blocking version and more details.
+func (c *Client) ValidateAddressAsync(address btcutil.Address) FutureValidateAddressResult {
+	addr := address.EncodeAddress()
+	cmd := btcjson.NewValidateAddressCmd(addr)
+	return c.sendCmd(cmd)
+}
+
+// ValidateAddress returns information about the given bitcoin address.
+func (c *Client) ValidateAddress(address btcutil.Address) (*btcjson.ValidateAddressWalletResult, error) {
+	return c.ValidateAddressAsync(address).Receive()
+}
+
+// FutureGetAddressInfoResult is a future promise to deliver the result of a
+// GetAddressInfoAsync RPC invocation (or an applicable error).
+type FutureGetAddressInfoResult chan *response
+
+// Receive waits for the response promised by the future and returns what the
+// wallet knows about the address.
+func (r FutureGetAddressInfoResult) Receive() (*btcjson.GetAddressInfoResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result btcjson.GetAddressInfoResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetAddressInfoAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetAddressInfo for the blocking version and more details.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) GetAddressInfoAsync(address btcutil.Address) FutureGetAddressInfoResult {
+	cmd := btcjson.NewGetAddressInfoCmd(address.EncodeAddress())
+	return c.sendCmd(cmd)
+}
+
+// GetAddressInfo returns everything the wallet knows about the passed address:
+// its ownership, script, derivation path, labels and whether it has been used.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) GetAddressInfo(address btcutil.Address) (*btcjson.GetAddressInfoResult, error) {
+	return c.GetAddressInfoAsync(address).Receive()
+}
+
+// FutureKeyPoolRefillResult is a future promise to deliver the result of a
+// KeyPoolRefillAsync RPC invocation (or an applicable error).
+type FutureKeyPoolRefillResult chan *response
+
+// Receive waits for the response promised by the future and returns the result
+// of refilling the key pool.
+func (r FutureKeyPoolRefillResult) Receive() error {
+	_, err := receiveFuture(r)
+	return err
+}
+
+// KeyPoolRefillAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See KeyPoolRefill for the blocking version and more details.
+func (c *Client) KeyPoolRefillAsync() FutureKeyPoolRefillResult {
+	cmd := btcjson.NewKeyPoolRefillCmd(nil)
+	return c.sendCmd(cmd)
+}
+
+// KeyPoolRefill fills the key pool as necessary to reach the default size.
+//
+// See KeyPoolRefillSize to override the size of the key pool.
+func (c *Client) KeyPoolRefill() error {
+	return c.KeyPoolRefillAsync().Receive()
+}
+
+// KeyPoolRefillSizeAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See KeyPoolRefillSize for the blocking version and more details.
+func (c *Client) KeyPoolRefillSizeAsync(newSize uint) FutureKeyPoolRefillResult {
+	cmd := btcjson.NewKeyPoolRefillCmd(&newSize)
+	return c.sendCmd(cmd)
+}
+
+// KeyPoolRefillSize fills the key pool as necessary to reach the specified
+// size.
+func (c *Client) KeyPoolRefillSize(newSize uint) error {
+	return c.KeyPoolRefillSizeAsync(newSize).Receive()
+}
+
+// ************************
+// Amount/Balance Functions
+// ************************
+
+// FutureListAccountsResult is a future promise to deliver the result of a
+// ListAccountsAsync or ListAccountsMinConfAsync RPC invocation (or an
+// applicable error).
+type FutureListAccountsResult chan *response
+
+// Receive waits for the response promised by the future and returns returns a
+// map of account names and their associated balances.
+func (r FutureListAccountsResult) Receive() (map[string]btcutil.Amount, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a json object.
+	var accounts map[string]float64
+	err = json.Unmarshal(res, &accounts)
+	if err != nil {
+		return nil, err
+	}
+
+	accountsMap := make(map[string]btcutil.Amount)
+	for k, v := range accounts {
+		amount, err := globalcfg.NewAmount(v)
+		if err != nil {
+			return nil, err
+		}
+
+		accountsMap[k] = btcutil.Amount(amount)
+	}
+
+	return accountsMap, nil
+}
+
+// ListAccountsAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See ListAccounts for the blocking version and more details.
+func (c *Client) ListAccountsAsync() FutureListAccountsResult {
+	cmd := btcjson.NewListAccountsCmd(nil)
+	return c.sendCmd(cmd)
+}
+
+// ListAccounts returns a map of account names and their associated balances
+// using the default number of minimum confirmations.
+//
+// See ListAccountsMinConf to override the minimum number of confirmations.
+func (c *Client) ListAccounts() (map[string]btcutil.Amount, error) {
+	return c.ListAccountsAsync().Receive()
+}
+
+// ListAccountsMinConfAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See ListAccountsMinConf for the blocking version and more details.
+func (c *Client) ListAccountsMinConfAsync(minConfirms int) FutureListAccountsResult {
+	cmd := btcjson.NewListAccountsCmd(&minConfirms)
+	return c.sendCmd(cmd)
+}
+
+// ListAccountsMinConf returns a map of account names and their associated
+// balances using the specified number of minimum confirmations.
+//
+// See ListAccounts to use the default minimum number of confirmations.
+func (c *Client) ListAccountsMinConf(minConfirms int) (map[string]btcutil.Amount, error) {
+	return c.ListAccountsMinConfAsync(minConfirms).Receive()
+}
+
+// FutureGetBalanceResult is a future promise to deliver the result of a
+// GetBalanceAsync or GetBalanceMinConfAsync RPC invocation (or an applicable
+// error).
+type FutureGetBalanceResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// available balance from the server for the specified account.
+func (r FutureGetBalanceResult) Receive() (btcutil.Amount, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return 0, err
+	}
+
+	// Unmarshal result as a floating point number.
+	var balance float64
+	err = json.Unmarshal(res, &balance)
+	if err != nil {
+		return 0, err
+	}
+
+	amount, err := globalcfg.NewAmount(balance)
+	if err != nil {
+		return 0, err
+	}
+
+	return btcutil.Amount(amount), nil
+}
+
+// FutureGetBalanceParseResult is same as FutureGetBalanceResult except
+// that the result is expected to be a string which is then parsed into
+// a float64 value
+// This is required for compatibility with servers like blockchain.info
+type FutureGetBalanceParseResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// available balance from the server for the specified account.
+func (r FutureGetBalanceParseResult) Receive() (btcutil.Amount, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return 0, err
+	}
+
+	// Unmarshal result as a string
+	var balanceString string
+	err = json.Unmarshal(res, &balanceString)
+	if err != nil {
+		return 0, err
+	}
+
+	balance, err := strconv.ParseFloat(balanceString, 64)
+	if err != nil {
+		return 0, err
+	}
+	amount, err := globalcfg.NewAmount(balance)
+	if err != nil {
+		return 0, err
+	}
+
+	return btcutil.Amount(amount), nil
+}
+
+// GetBalanceAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See GetBalance for the blocking version and more details.
+func (c *Client) GetBalanceAsync(account string) FutureGetBalanceResult {
+	cmd := btcjson.NewGetBalanceCmd(&account, nil)
+	return c.sendCmd(cmd)
+}
+
+// GetBalance returns the available balance from the server for the specified
+// account using the default number of minimum confirmations.  The account may
+// be "*" for all accounts.
+//
+// See GetBalanceMinConf to override the minimum number of confirmations.
+func (c *Client) GetBalance(account string) (btcutil.Amount, error) {
+	return c.GetBalanceAsync(account).Receive()
+}
+
+// GetBalanceMinConfAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetBalanceMinConf for the blocking version and more details.
+func (c *Client) GetBalanceMinConfAsync(account string, minConfirms int) FutureGetBalanceResult {
+	cmd := btcjson.NewGetBalanceCmd(&account, &minConfirms)
+	return c.sendCmd(cmd)
+}
+
+// GetBalanceMinConf returns the available balance from the server for the
+// specified account using the specified number of minimum confirmations.  The
+// account may be "*" for all accounts.
+//
+// See GetBalance to use the default minimum number of confirmations.
+func (c *Client) GetBalanceMinConf(account string, minConfirms int) (btcutil.Amount, error) {
+	if c.config.EnableBCInfoHacks {
+		response := c.GetBalanceMinConfAsync(account, minConfirms)
+		return FutureGetBalanceParseResult(response).Receive()
+	}
+	return c.GetBalanceMinConfAsync(account, minConfirms).Receive()
+}
+
+// FutureGetReceivedByAccountResult is a future promise to deliver the result of
+// a GetReceivedByAccountAsync or GetReceivedByAccountMinConfAsync RPC
+// invocation (or an applicable error).
+type FutureGetReceivedByAccountResult chan *response
+
+// Receive waits for the response promised by the future and returns the total
+// amount received with the specified account.
+func (r FutureGetReceivedByAccountResult) Receive() (btcutil.Amount, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return 0, err
+	}
+
+	// Unmarshal result as a floating point number.
+	var balance float64
+	err = json.Unmarshal(res, &balance)
+	if err != nil {
+		return 0, err
+	}
+
+	amount, err := globalcfg.NewAmount(balance)
+	if err != nil {
+		return 0, err
+	}
+
+	return btcutil.Amount(amount), nil
+}
+
+// GetReceivedByAccountAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See GetReceivedByAccount for the blocking version and more details.
+func (c *Client) GetReceivedByAccountAsync(account string) FutureGetReceivedByAccountResult {
+	cmd := btcjson.NewGetReceivedByAccountCmd(account, nil)
+	return c.sendCmd(cmd)
+}
+
+// GetReceivedByAccount returns the total amount received with the specified
+// account with at least the default number of minimum confirmations.
+//
+// See GetReceivedByAccountMinConf to override the minimum number of
+// confirmations.
+func (c *Client) GetReceivedByAccount(account string) (btcutil.Amount, error) {
+	return c.GetReceivedByAccountAsync(account).Receive()
+}
+
+// GetReceivedByAccountMinConfAsync returns an instance of a type that can be
+// used to get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See GetReceivedByAccountMinConf for the blocking version and more details.
+func (c *Client) GetReceivedByAccountMinConfAsync(account string, minConfirms int) FutureGetReceivedByAccountResult {
+	cmd := btcjson.NewGetReceivedByAccountCmd(account, &minConfirms)
+	return c.sendCmd(cmd)
+}
+
+// GetReceivedByAccountMinConf returns the total amount received with the
+// specified account with at least the specified number of minimum
+// confirmations.
+//
+// See GetReceivedByAccount to use the default minimum number of confirmations.
+func (c *Client) GetReceivedByAccountMinConf(account string, minConfirms int) (btcutil.Amount, error) {
+	return c.GetReceivedByAccountMinConfAsync(account, minConfirms).Receive()
+}
+
+// FutureGetUnconfirmedBalanceResult is a future promise to deliver the result
+// of a GetUnconfirmedBalanceAsync RPC invocation (or an applicable error).
+type FutureGetUnconfirmedBalanceResult chan *response
+
+// Receive waits for the response promised by the future and returns returns the
+// unconfirmed balance from the server for the specified account.
+func (r FutureGetUnconfirmedBalanceResult) Receive() (btcutil.Amount, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return 0, err
+	}
+
+	// Unmarshal result as a floating point number.
+	var balance float64
+	err = json.Unmarshal(res, &balance)
+	if err != nil {
+		return 0, err
+	}
+
+	amount, err := globalcfg.NewAmount(balance)
+	if err != nil {
+		return 0, err
+	}
+
+	return btcutil.Amount(amount), nil
+}
+
+// GetUnconfirmedBalanceAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See GetUnconfirmedBalance for the blocking version and more details.
+func (c *Client) GetUnconfirmedBalanceAsync(account string) FutureGetUnconfirmedBalanceResult {
+	cmd := btcjson.NewGetUnconfirmedBalanceCmd(&account)
+	return c.sendCmd(cmd)
+}
+
+// GetUnconfirmedBalance returns the unconfirmed balance from the server for
+// the specified account.
+func (c *Client) GetUnconfirmedBalance(account string) (btcutil.Amount, error) {
+	return c.GetUnconfirmedBalanceAsync(account).Receive()
+}
+
+// FutureGetReceivedByAddressResult is a future promise to deliver the result of
+// a GetReceivedByAddressAsync or GetReceivedByAddressMinConfAsync RPC
+// invocation (or an applicable error).
+type FutureGetReceivedByAddressResult chan *response
+
+// Receive waits for the response promised by the future and returns the total
+// amount received by the specified address.
+func (r FutureGetReceivedByAddressResult) Receive() (btcutil.Amount, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return 0, err
+	}
+
+	// Unmarshal result as a floating point number.
+	var balance float64
+	err = json.Unmarshal(res, &balance)
+	if err != nil {
+		return 0, err
+	}
+
+	amount, err := globalcfg.NewAmount(balance)
+	if err != nil {
+		return 0, err
+	}
+
+	return btcutil.Amount(amount), nil
+}
+
+// GetReceivedByAddressAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See GetReceivedByAddress for the blocking version and more details.
+func (c *Client) GetReceivedByAddressAsync(address btcutil.Address) FutureGetReceivedByAddressResult {
+	addr := address.EncodeAddress()
+	cmd := btcjson.NewGetReceivedByAddressCmd(addr, nil)
+	return c.sendCmd(cmd)
+
+}
+
+// GetReceivedByAddress returns the total amount received by the specified
+// address with at least the default number of minimum confirmations.
+//
+// See GetReceivedByAddressMinConf to override the minimum number of
+// confirmations.
+func (c *Client) GetReceivedByAddress(address btcutil.Address) (btcutil.Amount, error) {
+	return c.GetReceivedByAddressAsync(address).Receive()
+}
+
+// GetReceivedByAddressMinConfAsync returns an instance of a type that can be
+// used to get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See GetReceivedByAddressMinConf for the blocking version and more details.
+func (c *Client) GetReceivedByAddressMinConfAsync(address btcutil.Address, minConfirms int) FutureGetReceivedByAddressResult {
+	addr := address.EncodeAddress()
+	cmd := btcjson.NewGetReceivedByAddressCmd(addr, &minConfirms)
+	return c.sendCmd(cmd)
+}
+
+// GetReceivedByAddressMinConf returns the total amount received by the specified
+// address with at least the specified number of minimum confirmations.
+//
+// See GetReceivedByAddress to use the default minimum number of confirmations.
+func (c *Client) GetReceivedByAddressMinConf(address btcutil.Address, minConfirms int) (btcutil.Amount, error) {
+	return c.GetReceivedByAddressMinConfAsync(address, minConfirms).Receive()
+}
+
+// FutureListReceivedByAccountResult is a future promise to deliver the result
+// of a ListReceivedByAccountAsync, ListReceivedByAccountMinConfAsync, or
+// ListReceivedByAccountIncludeEmptyAsync RPC invocation (or an applicable
+// error).
+type FutureListReceivedByAccountResult chan *response
+
+// Receive waits for the response promised by the future and returns a list of
+// balances by account.
+func (r FutureListReceivedByAccountResult) Receive() ([]btcjson.ListReceivedByAccountResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal as an array of listreceivedbyaccount result objects.
+	var received []btcjson.ListReceivedByAccountResult
+	err = json.Unmarshal(res, &received)
+	if err != nil {
+		return nil, err
+	}
+
+	return received, nil
+}
+
+// ListReceivedByAccountAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See ListReceivedByAccount for the blocking version and more details.
+func (c *Client) ListReceivedByAccountAsync() FutureListReceivedByAccountResult {
+	cmd := btcjson.NewListReceivedByAccountCmd(nil, nil, nil)
+	return c.sendCmd(cmd)
+}
+
+// ListReceivedByAccount lists balances by account using the default number
+// of minimum confirmations and including accounts that haven't received any
+// payments.
+//
+// See ListReceivedByAccountMinConf to override the minimum number of
+// confirmations and ListReceivedByAccountIncludeEmpty to filter accounts that
+// haven't received any payments from the results.
+func (c *Client) ListReceivedByAccount() ([]btcjson.ListReceivedByAccountResult, error) {
+	return c.ListReceivedByAccountAsync().Receive()
+}
+
+// ListReceivedByAccountMinConfAsync returns an instance of a type that can be
+// used to get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See ListReceivedByAccountMinConf for the blocking version and more details.
+func (c *Client) ListReceivedByAccountMinConfAsync(minConfirms int) FutureListReceivedByAccountResult {
+	cmd := btcjson.NewListReceivedByAccountCmd(&minConfirms, nil, nil)
+	return c.sendCmd(cmd)
+}
+
+// ListReceivedByAccountMinConf lists balances by account using the specified
+// number of minimum confirmations not including accounts that haven't received
+// any payments.
+//
+// See ListReceivedByAccount to use the default minimum number of confirmations
+// and ListReceivedByAccountIncludeEmpty to also include accounts that haven't
+// received any payments in the results.
+func (c *Client) ListReceivedByAccountMinConf(minConfirms int) ([]btcjson.ListReceivedByAccountResult, error) {
+	return c.ListReceivedByAccountMinConfAsync(minConfirms).Receive()
+}
+
+// ListReceivedByAccountIncludeEmptyAsync returns an instance of a type that can
+// be used to get the result of the RPC at some future time by invoking the
+// Receive function on the returned instance.
+//
+// See ListReceivedByAccountIncludeEmpty for the blocking version and more details.
+func (c *Client) ListReceivedByAccountIncludeEmptyAsync(minConfirms int, includeEmpty bool) FutureListReceivedByAccountResult {
+	cmd := btcjson.NewListReceivedByAccountCmd(&minConfirms, &includeEmpty,
+		nil)
+	return c.sendCmd(cmd)
+}
+
+// ListReceivedByAccountIncludeEmpty lists balances by account using the
+// specified number of minimum confirmations and including accounts that
+// haven't received any payments depending on specified flag.
+//
+// See ListReceivedByAccount and ListReceivedByAccountMinConf to use defaults.
+func (c *Client) ListReceivedByAccountIncludeEmpty(minConfirms int, includeEmpty bool) ([]btcjson.ListReceivedByAccountResult, error) {
+	return c.ListReceivedByAccountIncludeEmptyAsync(minConfirms,
+		includeEmpty).Receive()
+}
+
+// FutureListReceivedByAddressResult is a future promise to deliver the result
+// of a ListReceivedByAddressAsync, ListReceivedByAddressMinConfAsync, or
+// ListReceivedByAddressIncludeEmptyAsync RPC invocation (or an applicable
+// error).
+type FutureListReceivedByAddressResult chan *response
+
+// Receive waits for the response promised by the future and returns a list of
+// balances by address.
+func (r FutureListReceivedByAddressResult) Receive() ([]btcjson.ListReceivedByAddressResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal as an array of listreceivedbyaddress result objects.
+	var received []btcjson.ListReceivedByAddressResult
+	err = json.Unmarshal(res, &received)
+	if err != nil {
+		return nil, err
+	}
+
+	return received, nil
+}
+
+// ListReceivedByAddressAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See ListReceivedByAddress for the blocking version and more details.
+func (c *Client) ListReceivedByAddressAsync() FutureListReceivedByAddressResult {
+	cmd := btcjson.NewListReceivedByAddressCmd(nil, nil, nil)
+	return c.sendCmd(cmd)
+}
+
+// ListReceivedByAddress lists balances by address using the default number
+// of minimum confirmations not including addresses that haven't received any
+// payments or watching only addresses.
+//
+// See ListReceivedByAddressMinConf to override the minimum number of
+// confirmations and ListReceivedByAddressIncludeEmpty to also include addresses
+// that haven't received any payments in the results.
+func (c *Client) ListReceivedByAddress() ([]btcjson.ListReceivedByAddressResult, error) {
+	return c.ListReceivedByAddressAsync().Receive()
+}
+
+// ListReceivedByAddressMinConfAsync returns an instance of a type that can be
+// used to get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See ListReceivedByAddressMinConf for the blocking version and more details.
+func (c *Client) ListReceivedByAddressMinConfAsync(minConfirms int) FutureListReceivedByAddressResult {
+	cmd := btcjson.NewListReceivedByAddressCmd(&minConfirms, nil, nil)
+	return c.sendCmd(cmd)
+}
+
+// ListReceivedByAddressMinConf lists balances by address using the specified
+// number of minimum confirmations not including addresses that haven't received
+// any payments.
+//
+// See ListReceivedByAddress to use the default minimum number of confirmations
+// and ListReceivedByAddressIncludeEmpty to also include addresses that haven't
+// received any payments in the results.
+func (c *Client) ListReceivedByAddressMinConf(minConfirms int) ([]btcjson.ListReceivedByAddressResult, error) {
+	return c.ListReceivedByAddressMinConfAsync(minConfirms).Receive()
+}
+
+// ListReceivedByAddressIncludeEmptyAsync returns an instance of a type that can
+// be used to get the result of the RPC at some future time by invoking the
+// Receive function on the returned instance.
+//
+// See ListReceivedByAccountIncludeEmpty for the blocking version and more details.
+func (c *Client) ListReceivedByAddressIncludeEmptyAsync(minConfirms int, includeEmpty bool) FutureListReceivedByAddressResult {
+	cmd := btcjson.NewListReceivedByAddressCmd(&minConfirms, &includeEmpty,
+		nil)
+	return c.sendCmd(cmd)
+}
+
+// ListReceivedByAddressIncludeEmpty lists balances by address using the
+// specified number of minimum confirmations and including addresses that
+// haven't received any payments depending on specified flag.
+//
+// See ListReceivedByAddress and ListReceivedByAddressMinConf to use defaults.
+func (c *Client) ListReceivedByAddressIncludeEmpty(minConfirms int, includeEmpty bool) ([]btcjson.ListReceivedByAddressResult, error) {
+	return c.ListReceivedByAddressIncludeEmptyAsync(minConfirms,
+		includeEmpty).Receive()
+}
+
+// ************************
+// Wallet Locking Functions
+// ************************
+
+// FutureWalletLockResult is a future promise to deliver the result of a
+// WalletLockAsync RPC invocation (or an applicable error).
+type FutureWalletLockResult chan *response
+
+// Receive waits for the response promised by the future and returns the result
+// of locking the wallet.
+func (r FutureWalletLockResult) Receive() error {
+	_, err := receiveFuture(r)
+	return err
+}
+
+// WalletLockAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See WalletLock for the blocking version and more details.
+func (c *Client) WalletLockAsync() FutureWalletLockResult {
+	cmd := btcjson.NewWalletLockCmd()
+	return c.sendCmd(cmd)
+}
+
+// WalletLock locks the wallet by removing the encryption key from memory.
+//
+// After calling this function, the WalletPassphrase function must be used to
+// unlock the wallet prior to calling any other function which requires the
+// wallet to be unlocked.
+func (c *Client) WalletLock() error {
+	return c.WalletLockAsync().Receive()
 }
 
-// FutureGetReceivedByAccountResult is a future promise to deliver the result of
-// a GetReceivedByAccountAsync or GetReceivedByAccountMinConfAsync RPC
-// invocation (or an applicable error).
-type FutureGetReceivedByAccountResult chan *response
+// WalletPassphrase unlocks the wallet by using the passphrase to derive the
+// decryption key which is then stored in memory for the specified timeout
+// (in seconds).
+func (c *Client) WalletPassphrase(passphrase string, timeoutSecs int64) error {
+	cmd := btcjson.NewWalletPassphraseCmd(passphrase, timeoutSecs)
+	_, err := c.sendCmdAndWait(cmd)
+	return err
+}
 
-// Receive waits for the response promised by the future and returns the total
-// amount received with the specified account.
-func (r FutureGetReceivedByAccountResult) Receive() (btcutil.Amount, error) {
-	res, err := receiveFuture(r)
-	if err != nil {
-		return 0, err
-	}
+// WalletPassphraseScoped unlocks the wallet like WalletPassphrase, but only
+// for the operations allowed by the passed scope.
+func (c *Client) WalletPassphraseScoped(passphrase string, timeoutSecs int64,
+	scope btcjson.WalletUnlockScope) error {
 
-	// Unmarshal result as a floating point number.
-	var balance float64
-	err = json.Unmarshal(res, &balance)
-	if err != nil {
-		return 0, err
-	}
+	cmd := btcjson.NewWalletPassphraseScopedCmd(passphrase, timeoutSecs, scope)
+	_, err := c.sendCmdAndWait(cmd)
+	return err
+}
 
-	amount, err := globalcfg.NewAmount(balance)
-	if err != nil {
-		return 0, err
-	}
+// FutureWalletPassphraseChangeResult is a future promise to deliver the result
+// of a WalletPassphraseChangeAsync RPC invocation (or an applicable error).
+type FutureWalletPassphraseChangeResult chan *response
 
-	return btcutil.Amount(amount), nil
+// Receive waits for the response promised by the future and returns the result
+// of changing the wallet passphrase.
+func (r FutureWalletPassphraseChangeResult) Receive() error {
+	_, err := receiveFuture(r)
+	return err
 }
 
-// GetReceivedByAccountAsync returns an instance of a type that can be used to
+// WalletPassphraseChangeAsync returns an instance of a type that can be used to
 // get the result of the RPC at some future time by invoking the Receive
 // function on the returned instance.
 //
-// See GetReceivedByAccount for the blocking version and more details.
-func (c *Client) GetReceivedByAccountAsync(account string) FutureGetReceivedByAccountResult {
-	cmd := btcjson.NewGetReceivedByAccountCmd(account, nil)
+// See WalletPassphraseChange for the blocking version and more details.
+func (c *Client) WalletPassphraseChangeAsync(old, new string) FutureWalletPassphraseChangeResult {
+	cmd := btcjson.NewWalletPassphraseChangeCmd(old, new)
 	return c.sendCmd(cmd)
 }
 
-// GetReceivedByAccount returns the total amount received with the specified
-// account with at least the default number of minimum confirmations.
-//
-// See GetReceivedByAccountMinConf to override the minimum number of
-// confirmations.
-func (c *Client) GetReceivedByAccount(account string) (btcutil.Amount, error) {
-	return c.GetReceivedByAccountAsync(account).Receive()
+// WalletPassphraseChange changes the wallet passphrase from the specified old
+// to new passphrase.
+func (c *Client) WalletPassphraseChange(old, new string) error {
+	return c.WalletPassphraseChangeAsync(old, new).Receive()
 }
 
-// GetReceivedByAccountMinConfAsync returns an instance of a type that can be
-// used to get the result of the RPC at some future time by invoking the Receive
-// function on the returned instance.
+// FutureWalletPubPassphraseChangeResult is a future promise to deliver the
+// result of a WalletPubPassphraseChangeAsync RPC invocation (or an applicable
+// error).
+type FutureWalletPubPassphraseChangeResult chan *response
+
+// Receive waits for the response promised by the future and returns the result
+// of changing the wallet viewing passphrase.
+func (r FutureWalletPubPassphraseChangeResult) Receive() error {
+	_, err := receiveFuture(r)
+	return err
+}
+
+// WalletPubPassphraseChangeAsync returns an instance of a type that can be
+// used to get the result of the RPC at some future time by invoking the
+// Receive function on the returned instance.
 //
-// See GetReceivedByAccountMinConf for the blocking version and more details.
-func (c *Client) GetReceivedByAccountMinConfAsync(account string, minConfirms int) FutureGetReceivedByAccountResult {
-	cmd := btcjson.NewGetReceivedByAccountCmd(account, &minConfirms)
+// See WalletPubPassphraseChange for the blocking version and more details.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) WalletPubPassphraseChangeAsync(old, new string) FutureWalletPubPassphraseChangeResult {
+	cmd := btcjson.NewWalletPubPassphraseChangeCmd(old, new)
 	return c.sendCmd(cmd)
 }
 
-// GetReceivedByAccountMinConf returns the total amount received with the
-// specified account with at least the specified number of minimum
-// confirmations.
+// WalletPubPassphraseChange changes the viewing passphrase, which protects the
+// public wallet data, from the specified old to new passphrase.
 //
-// See GetReceivedByAccount to use the default minimum number of confirmations.
-func (c *Client) GetReceivedByAccountMinConf(account string, minConfirms int) (btcutil.Amount, error) {
-	return c.GetReceivedByAccountMinConfAsync(account, minConfirms).Receive()
+// NOTE: This is a pktwallet extension.
+func (c *Client) WalletPubPassphraseChange(old, new string) error {
+	return c.WalletPubPassphraseChangeAsync(old, new).Receive()
 }
 
-// FutureGetUnconfirmedBalanceResult is a future promise to deliver the result
-// of a GetUnconfirmedBalanceAsync RPC invocation (or an applicable error).
-type FutureGetUnconfirmedBalanceResult chan *response
+// FutureCreateTimeLockAddressResult is a future promise to deliver the result
+// of a CreateTimeLockAddressAsync RPC invocation (or an applicable error).
+type FutureCreateTimeLockAddressResult chan *response
 
-// Receive waits for the response promised by the future and returns returns the
-// unconfirmed balance from the server for the specified account.
-func (r FutureGetUnconfirmedBalanceResult) Receive() (btcutil.Amount, error) {
+// Receive waits for the response promised by the future and returns the time
+// locked address along with its redeem script.
+func (r FutureCreateTimeLockAddressResult) Receive() (*btcjson.TimeLockAddressResult, error) {
 	res, err := receiveFuture(r)
 	if err != nil {
-		return 0, err
-	}
-
-	// Unmarshal result as a floating point number.
-	var balance float64
-	err = json.Unmarshal(res, &balance)
-	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	amount, err := globalcfg.NewAmount(balance)
+	var result btcjson.TimeLockAddressResult
+	err = json.Unmarshal(res, &result)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-
-	return btcutil.Amount(amount), nil
+	return &result, nil
 }
 
-// GetUnconfirmedBalanceAsync returns an instance of a type that can be used to
+// CreateTimeLockAddressAsync returns an instance of a type that can be used to
 // get the result of the RPC at some future time by invoking the Receive
 // function on the returned instance.
 //
-// See GetUnconfirmedBalance for the blocking version and more details.
-func (c *Client) GetUnconfirmedBalanceAsync(account string) FutureGetUnconfirmedBalanceResult {
-	cmd := btcjson.NewGetUnconfirmedBalanceCmd(&account)
+// See CreateTimeLockAddress for the blocking version and more details.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) CreateTimeLockAddressAsync(account string, lockTime int64,
+	relative bool) FutureCreateTimeLockAddressResult {
+
+	cmd := btcjson.NewCreateTimeLockAddressCmd(lockTime, &relative, &account)
 	return c.sendCmd(cmd)
 }
 
-// GetUnconfirmedBalance returns the unconfirmed balance from the server for
-// the specified account.
-func (c *Client) GetUnconfirmedBalance(account string) (btcutil.Amount, error) {
-	return c.GetUnconfirmedBalanceAsync(account).Receive()
+// CreateTimeLockAddress creates an address of the passed account whose outputs
+// can not be spent before the lock time.  When relative is set, the lock time
+// is a number of blocks, or a BIP 68 time based sequence, counted from the
+// confirmation of each output.  Otherwise it is a block height or unix time.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) CreateTimeLockAddress(account string, lockTime int64,
+	relative bool) (*btcjson.TimeLockAddressResult, error) {
+
+	return c.CreateTimeLockAddressAsync(account, lockTime, relative).Receive()
 }
 
-// FutureGetReceivedByAddressResult is a future promise to deliver the result of
-// a GetReceivedByAddressAsync or GetReceivedByAddressMinConfAsync RPC
-// invocation (or an applicable error).
-type FutureGetReceivedByAddressResult chan *response
+// FutureCreatePaymentURIResult is a future promise to deliver the result of a
+// CreatePaymentURIAsync RPC invocation (or an applicable error).
+type FutureCreatePaymentURIResult chan *response
 
-// Receive waits for the response promised by the future and returns the total
-// amount received by the specified address.
-func (r FutureGetReceivedByAddressResult) Receive() (btcutil.Amount, error) {
+// Receive waits for the response promised by the future and returns the
+// payment URI.
+func (r FutureCreatePaymentURIResult) Receive() (*btcjson.PaymentURIResult, error) {
 	res, err := receiveFuture(r)
 	if err != nil {
-		return 0, err
-	}
-
-	// Unmarshal result as a floating point number.
-	var balance float64
-	err = json.Unmarshal(res, &balance)
-	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	amount, err := globalcfg.NewAmount(balance)
+	var uri btcjson.PaymentURIResult
+	err = json.Unmarshal(res, &uri)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-
-	return btcutil.Amount(amount), nil
+	return &uri, nil
 }
 
-// GetReceivedByAddressAsync returns an instance of a type that can be used to
-// get the result of the RPC at some future time by invoking the Receive
-// function on the returned instance.
+// CreatePaymentURIAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
 //
-// See GetReceivedByAddress for the blocking version and more details.
-func (c *Client) GetReceivedByAddressAsync(address btcutil.Address) FutureGetReceivedByAddressResult {
-	addr := address.EncodeAddress()
-	cmd := btcjson.NewGetReceivedByAddressCmd(addr, nil)
-	return c.sendCmd(cmd)
-
-}
-
-// GetReceivedByAddress returns the total amount received by the specified
-// address with at least the default number of minimum confirmations.
+// See CreatePaymentURI for the blocking version and more details.
 //
-// See GetReceivedByAddressMinConf to override the minimum number of
-// confirmations.
-func (c *Client) GetReceivedByAddress(address btcutil.Address) (btcutil.Amount, error) {
-	return c.GetReceivedByAddressAsync(address).Receive()
-}
+// NOTE: This is a pktwallet extension.
+func (c *Client) CreatePaymentURIAsync(amount btcutil.Amount, label,
+	message string) FutureCreatePaymentURIResult {
 
-// GetReceivedByAddressMinConfAsync returns an instance of a type that can be
-// used to get the result of the RPC at some future time by invoking the Receive
-// function on the returned instance.
-//
-// See GetReceivedByAddressMinConf for the blocking version and more details.
-func (c *Client) GetReceivedByAddressMinConfAsync(address btcutil.Address, minConfirms int) FutureGetReceivedByAddressResult {
-	addr := address.EncodeAddress()
-	cmd := btcjson.NewGetReceivedByAddressCmd(addr, &minConfirms)
+	// The amount is always sent since the label and message follow it, a
+	// zero amount leaving it to the payer.
+	cmd := btcjson.NewCreatePaymentURICmd(btcjson.Float64(amount.ToBTC()),
+		&label, &message, nil)
 	return c.sendCmd(cmd)
 }
 
-// GetReceivedByAddressMinConf returns the total amount received by the specified
-// address with at least the specified number of minimum confirmations.
+// CreatePaymentURI returns a pkt: payment URI requesting the passed amount to a
+// new receiving address of the wallet.  An amount of zero leaves the amount to
+// the payer.
 //
-// See GetReceivedByAddress to use the default minimum number of confirmations.
-func (c *Client) GetReceivedByAddressMinConf(address btcutil.Address, minConfirms int) (btcutil.Amount, error) {
-	return c.GetReceivedByAddressMinConfAsync(address, minConfirms).Receive()
+// NOTE: This is a pktwallet extension.
+func (c *Client) CreatePaymentURI(amount btcutil.Amount, label,
+	message string) (*btcjson.PaymentURIResult, error) {
+
+	return c.CreatePaymentURIAsync(amount, label, message).Receive()
 }
 
-// FutureListReceivedByAccountResult is a future promise to deliver the result
-// of a ListReceivedByAccountAsync, ListReceivedByAccountMinConfAsync, or
-// ListReceivedByAccountIncludeEmptyAsync RPC invocation (or an applicable
-// error).
-type FutureListReceivedByAccountResult chan *response
+// FutureSigningPackageResult is a future promise to deliver the result of a
+// CreateSigningPackageAsync or SignSigningPackageAsync RPC invocation (or an
+// applicable error).
+type FutureSigningPackageResult chan *response
 
-// Receive waits for the response promised by the future and returns a list of
-// balances by account.
-func (r FutureListReceivedByAccountResult) Receive() ([]btcjson.ListReceivedByAccountResult, error) {
+// Receive waits for the response promised by the future and returns the
+// signing package along with whether all of its inputs are signed.
+func (r FutureSigningPackageResult) Receive() (*btcjson.SigningPackageResult, error) {
 	res, err := receiveFuture(r)
 	if err != nil {
 		return nil, err
 	}
 
-	// Unmarshal as an array of listreceivedbyaccount result objects.
-	var received []btcjson.ListReceivedByAccountResult
-	err = json.Unmarshal(res, &received)
+	var pkg btcjson.SigningPackageResult
+	err = json.Unmarshal(res, &pkg)
 	if err != nil {
 		return nil, err
 	}
-
-	return received, nil
+	return &pkg, nil
 }
 
-// ListReceivedByAccountAsync returns an instance of a type that can be used to
+// CreateSigningPackageAsync returns an instance of a type that can be used to
 // get the result of the RPC at some future time by invoking the Receive
 // function on the returned instance.
 //
-// See ListReceivedByAccount for the blocking version and more details.
-func (c *Client) ListReceivedByAccountAsync() FutureListReceivedByAccountResult {
-	cmd := btcjson.NewListReceivedByAccountCmd(nil, nil, nil)
+// See CreateSigningPackage for the blocking version and more details.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) CreateSigningPackageAsync(hexTx, format string) FutureSigningPackageResult {
+	cmd := btcjson.NewCreateSigningPackageCmd(hexTx, &format)
 	return c.sendCmd(cmd)
 }
 
-// ListReceivedByAccount lists balances by account using the default number
-// of minimum confirmations and including accounts that haven't received any
-// payments.
+// CreateSigningPackage exports the passed unsigned transaction together with
+// the scripts, amounts and derivation paths of the outputs it spends, so that
+// it can be signed by a wallet which has no access to the chain.  The format
+// is either btcjson.SigningPackageFormatPSBT or
+// btcjson.SigningPackageFormatJSON.
 //
-// See ListReceivedByAccountMinConf to override the minimum number of
-// confirmations and ListReceivedByAccountIncludeEmpty to filter accounts that
-// haven't received any payments from the results.
-func (c *Client) ListReceivedByAccount() ([]btcjson.ListReceivedByAccountResult, error) {
-	return c.ListReceivedByAccountAsync().Receive()
+// NOTE: This is a pktwallet extension.
+func (c *Client) CreateSigningPackage(hexTx, format string) (*btcjson.SigningPackageResult, error) {
+	return c.CreateSigningPackageAsync(hexTx, format).Receive()
 }
 
-// ListReceivedByAccountMinConfAsync returns an instance of a type that can be
-// used to get the result of the RPC at some future time by invoking the Receive
+// SignSigningPackageAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
 // function on the returned instance.
 //
-// See ListReceivedByAccountMinConf for the blocking version and more details.
-func (c *Client) ListReceivedByAccountMinConfAsync(minConfirms int) FutureListReceivedByAccountResult {
-	cmd := btcjson.NewListReceivedByAccountCmd(&minConfirms, nil, nil)
+// See SignSigningPackage for the blocking version and more details.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) SignSigningPackageAsync(pkg string) FutureSigningPackageResult {
+	cmd := btcjson.NewSignSigningPackageCmd(pkg)
 	return c.sendCmd(cmd)
 }
 
-// ListReceivedByAccountMinConf lists balances by account using the specified
-// number of minimum confirmations not including accounts that haven't received
-// any payments.
+// SignSigningPackage signs every input of the passed package that the wallet
+// holds keys for and returns the package with the signatures added.
 //
-// See ListReceivedByAccount to use the default minimum number of confirmations
-// and ListReceivedByAccountIncludeEmpty to also include accounts that haven't
-// received any payments in the results.
-func (c *Client) ListReceivedByAccountMinConf(minConfirms int) ([]btcjson.ListReceivedByAccountResult, error) {
-	return c.ListReceivedByAccountMinConfAsync(minConfirms).Receive()
+// NOTE: This function requires to the wallet to be unlocked.  See the
+// WalletPassphrase function for more details.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) SignSigningPackage(pkg string) (*btcjson.SigningPackageResult, error) {
+	return c.SignSigningPackageAsync(pkg).Receive()
 }
 
-// ListReceivedByAccountIncludeEmptyAsync returns an instance of a type that can
-// be used to get the result of the RPC at some future time by invoking the
-// Receive function on the returned instance.
+// ImportSignaturesAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
 //
-// See ListReceivedByAccountIncludeEmpty for the blocking version and more details.
-func (c *Client) ListReceivedByAccountIncludeEmptyAsync(minConfirms int, includeEmpty bool) FutureListReceivedByAccountResult {
-	cmd := btcjson.NewListReceivedByAccountCmd(&minConfirms, &includeEmpty,
-		nil)
+// See ImportSignatures for the blocking version and more details.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) ImportSignaturesAsync(pkg string) FutureSignRawTransactionResult {
+	cmd := btcjson.NewImportSignaturesCmd(pkg)
 	return c.sendCmd(cmd)
 }
 
-// ListReceivedByAccountIncludeEmpty lists balances by account using the
-// specified number of minimum confirmations and including accounts that
-// haven't received any payments depending on specified flag.
+// ImportSignatures merges the signatures of a package signed offline into its
+// transaction and returns the transaction along with whether all of its
+// inputs are now signed.
 //
-// See ListReceivedByAccount and ListReceivedByAccountMinConf to use defaults.
-func (c *Client) ListReceivedByAccountIncludeEmpty(minConfirms int, includeEmpty bool) ([]btcjson.ListReceivedByAccountResult, error) {
-	return c.ListReceivedByAccountIncludeEmptyAsync(minConfirms,
-		includeEmpty).Receive()
+// NOTE: This is a pktwallet extension.
+func (c *Client) ImportSignatures(pkg string) (*wire.MsgTx, bool, error) {
+	return c.ImportSignaturesAsync(pkg).Receive()
 }
 
-// FutureListReceivedByAddressResult is a future promise to deliver the result
-// of a ListReceivedByAddressAsync, ListReceivedByAddressMinConfAsync, or
-// ListReceivedByAddressIncludeEmptyAsync RPC invocation (or an applicable
-// error).
-type FutureListReceivedByAddressResult chan *response
+// FutureEnumerateSignersResult is a future promise to deliver the result of an
+// EnumerateSignersAsync RPC invocation (or an applicable error).
+type FutureEnumerateSignersResult chan *response
 
-// Receive waits for the response promised by the future and returns a list of
-// balances by address.
-func (r FutureListReceivedByAddressResult) Receive() ([]btcjson.ListReceivedByAddressResult, error) {
+// Receive waits for the response promised by the future and returns the
+// devices available through the external signer.
+func (r FutureEnumerateSignersResult) Receive() ([]btcjson.ExternalSigner, error) {
 	res, err := receiveFuture(r)
 	if err != nil {
 		return nil, err
 	}
 
-	// Unmarshal as an array of listreceivedbyaddress result objects.
-	var received []btcjson.ListReceivedByAddressResult
-	err = json.Unmarshal(res, &received)
-	if err != nil {
-		return nil, err
-	}
-
-	return received, nil
+	var result btcjson.EnumerateSignersResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+	return result.Signers, nil
 }
 
-// ListReceivedByAddressAsync returns an instance of a type that can be used to
-// get the result of the RPC at some future time by invoking the Receive
-// function on the returned instance.
+// EnumerateSignersAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
 //
-// See ListReceivedByAddress for the blocking version and more details.
-func (c *Client) ListReceivedByAddressAsync() FutureListReceivedByAddressResult {
-	cmd := btcjson.NewListReceivedByAddressCmd(nil, nil, nil)
+// See EnumerateSigners for the blocking version and more details.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) EnumerateSignersAsync() FutureEnumerateSignersResult {
+	cmd := btcjson.NewEnumerateSignersCmd()
 	return c.sendCmd(cmd)
 }
 
-// ListReceivedByAddress lists balances by address using the default number
-// of minimum confirmations not including addresses that haven't received any
-// payments or watching only addresses.
+// EnumerateSigners returns the devices available through the external signer
+// command configured in the wallet.
 //
-// See ListReceivedByAddressMinConf to override the minimum number of
-// confirmations and ListReceivedByAddressIncludeEmpty to also include addresses
-// that haven't received any payments in the results.
-func (c *Client) ListReceivedByAddress() ([]btcjson.ListReceivedByAddressResult, error) {
-	return c.ListReceivedByAddressAsync().Receive()
+// NOTE: This is a pktwallet extension.
+func (c *Client) EnumerateSigners() ([]btcjson.ExternalSigner, error) {
+	return c.EnumerateSignersAsync().Receive()
 }
 
-// ListReceivedByAddressMinConfAsync returns an instance of a type that can be
-// used to get the result of the RPC at some future time by invoking the Receive
-// function on the returned instance.
+// FutureStartRecoveryResult is a future promise to deliver the result of a
+// StartRecoveryAsync RPC invocation (or an applicable error).
+type FutureStartRecoveryResult chan *response
+
+// Receive waits for the response promised by the future and returns an error
+// if the recovery scan could not be started.
+func (r FutureStartRecoveryResult) Receive() error {
+	_, err := receiveFuture(r)
+	return err
+}
+
+// StartRecoveryAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
 //
-// See ListReceivedByAddressMinConf for the blocking version and more details.
-func (c *Client) ListReceivedByAddressMinConfAsync(minConfirms int) FutureListReceivedByAddressResult {
-	cmd := btcjson.NewListReceivedByAddressCmd(&minConfirms, nil, nil)
+// See StartRecovery for the blocking version and more details.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) StartRecoveryAsync(gapLimit, startHeight int32) FutureStartRecoveryResult {
+	cmd := btcjson.NewStartRecoveryCmd(&gapLimit, &startHeight)
 	return c.sendCmd(cmd)
 }
 
-// ListReceivedByAddressMinConf lists balances by address using the specified
-// number of minimum confirmations not including addresses that haven't received
-// any payments.
+// StartRecovery starts a recovery scan of the chain from the passed height
+// which looks for used addresses in every account, extending the address
+// lookahead window past gapLimit whenever one is found.  The scan runs in the
+// background, see GetRecoveryInfo to follow its progress.
 //
-// See ListReceivedByAddress to use the default minimum number of confirmations
-// and ListReceivedByAddressIncludeEmpty to also include addresses that haven't
-// received any payments in the results.
-func (c *Client) ListReceivedByAddressMinConf(minConfirms int) ([]btcjson.ListReceivedByAddressResult, error) {
-	return c.ListReceivedByAddressMinConfAsync(minConfirms).Receive()
+// NOTE: This is a pktwallet extension.
+func (c *Client) StartRecovery(gapLimit, startHeight int32) error {
+	return c.StartRecoveryAsync(gapLimit, startHeight).Receive()
 }
 
-// ListReceivedByAddressIncludeEmptyAsync returns an instance of a type that can
-// be used to get the result of the RPC at some future time by invoking the
-// Receive function on the returned instance.
+// FutureGetRecoveryInfoResult is a future promise to deliver the result of a
+// GetRecoveryInfoAsync RPC invocation (or an applicable error).
+type FutureGetRecoveryInfoResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// progress of the recovery scan.
+func (r FutureGetRecoveryInfoResult) Receive() (*btcjson.RecoveryInfoResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var info btcjson.RecoveryInfoResult
+	err = json.Unmarshal(res, &info)
+	if err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// GetRecoveryInfoAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
 //
-// See ListReceivedByAccountIncludeEmpty for the blocking version and more details.
-func (c *Client) ListReceivedByAddressIncludeEmptyAsync(minConfirms int, includeEmpty bool) FutureListReceivedByAddressResult {
-	cmd := btcjson.NewListReceivedByAddressCmd(&minConfirms, &includeEmpty,
-		nil)
+// See GetRecoveryInfo for the blocking version and more details.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) GetRecoveryInfoAsync() FutureGetRecoveryInfoResult {
+	cmd := btcjson.NewGetRecoveryInfoCmd()
 	return c.sendCmd(cmd)
 }
 
-// ListReceivedByAddressIncludeEmpty lists balances by address using the
-// specified number of minimum confirmations and including addresses that
-// haven't received any payments depending on specified flag.
+// GetRecoveryInfo returns the progress of the current or last recovery scan.
 //
-// See ListReceivedByAddress and ListReceivedByAddressMinConf to use defaults.
-func (c *Client) ListReceivedByAddressIncludeEmpty(minConfirms int, includeEmpty bool) ([]btcjson.ListReceivedByAddressResult, error) {
-	return c.ListReceivedByAddressIncludeEmptyAsync(minConfirms,
-		includeEmpty).Receive()
+// NOTE: This is a pktwallet extension.
+func (c *Client) GetRecoveryInfo() (*btcjson.RecoveryInfoResult, error) {
+	return c.GetRecoveryInfoAsync().Receive()
 }
 
-// ************************
-// Wallet Locking Functions
-// ************************
+// FutureSetWalletFlagResult is a future promise to deliver the result of a
+// SetWalletFlagAsync RPC invocation (or an applicable error).
+type FutureSetWalletFlagResult chan *response
 
-// FutureWalletLockResult is a future promise to deliver the result of a
-// WalletLockAsync RPC invocation (or an applicable error).
-type FutureWalletLockResult chan *response
+// Receive waits for the response promised by the future and returns the new
+// state of the wallet flag.
+func (r FutureSetWalletFlagResult) Receive() (*btcjson.SetWalletFlagResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
 
-// Receive waits for the response promised by the future and returns the result
-// of locking the wallet.
-func (r FutureWalletLockResult) Receive() error {
-	_, err := receiveFuture(r)
-	return err
+	var result btcjson.SetWalletFlagResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
 }
 
-// WalletLockAsync returns an instance of a type that can be used to get the
+// SetWalletFlagAsync returns an instance of a type that can be used to get the
 // result of the RPC at some future time by invoking the Receive function on the
 // returned instance.
 //
-// See WalletLock for the blocking version and more details.
-func (c *Client) WalletLockAsync() FutureWalletLockResult {
-	cmd := btcjson.NewWalletLockCmd()
+// See SetWalletFlag for the blocking version and more details.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) SetWalletFlagAsync(flag string, value bool) FutureSetWalletFlagResult {
+	cmd := btcjson.NewSetWalletFlagCmd(flag, &value)
 	return c.sendCmd(cmd)
 }
 
-// WalletLock locks the wallet by removing the encryption key from memory.
+// SetWalletFlag sets or clears a wallet flag such as
+// btcjson.WalletFlagAvoidReuse, which keeps outputs paying to reused addresses
+// from being spent together with fresh outputs unless it is cleared.
 //
-// After calling this function, the WalletPassphrase function must be used to
-// unlock the wallet prior to calling any other function which requires the
-// wallet to be unlocked.
-func (c *Client) WalletLock() error {
-	return c.WalletLockAsync().Receive()
+// NOTE: This is a pktwallet extension.
+func (c *Client) SetWalletFlag(flag string, value bool) (*btcjson.SetWalletFlagResult, error) {
+	return c.SetWalletFlagAsync(flag, value).Receive()
 }
 
-// WalletPassphrase unlocks the wallet by using the passphrase to derive the
-// decryption key which is then stored in memory for the specified timeout
-// (in seconds).
-func (c *Client) WalletPassphrase(passphrase string, timeoutSecs int64) error {
-	cmd := btcjson.NewWalletPassphraseCmd(passphrase, timeoutSecs)
-	_, err := c.sendCmdAndWait(cmd)
-	return err
-}
+// FutureGetWalletLockStateResult is a future promise to deliver the result of
+// a GetWalletLockStateAsync RPC invocation (or an applicable error).
+type FutureGetWalletLockStateResult chan *response
 
-// FutureWalletPassphraseChangeResult is a future promise to deliver the result
-// of a WalletPassphraseChangeAsync RPC invocation (or an applicable error).
-type FutureWalletPassphraseChangeResult chan *response
+// Receive waits for the response promised by the future and returns the lock
+// state of the wallet.
+func (r FutureGetWalletLockStateResult) Receive() (*btcjson.WalletLockStateResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
 
-// Receive waits for the response promised by the future and returns the result
-// of changing the wallet passphrase.
-func (r FutureWalletPassphraseChangeResult) Receive() error {
-	_, err := receiveFuture(r)
-	return err
+	var state btcjson.WalletLockStateResult
+	err = json.Unmarshal(res, &state)
+	if err != nil {
+		return nil, err
+	}
+	return &state, nil
 }
 
-// WalletPassphraseChangeAsync returns an instance of a type that can be used to
+// GetWalletLockStateAsync returns an instance of a type that can be used to
 // get the result of the RPC at some future time by invoking the Receive
 // function on the returned instance.
 //
-// See WalletPassphraseChange for the blocking version and more details.
-func (c *Client) WalletPassphraseChangeAsync(old, new string) FutureWalletPassphraseChangeResult {
-	cmd := btcjson.NewWalletPassphraseChangeCmd(old, new)
+// See GetWalletLockState for the blocking version and more details.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) GetWalletLockStateAsync() FutureGetWalletLockStateResult {
+	cmd := btcjson.NewGetWalletLockStateCmd()
 	return c.sendCmd(cmd)
 }
 
-// WalletPassphraseChange changes the wallet passphrase from the specified old
-// to new passphrase.
-func (c *Client) WalletPassphraseChange(old, new string) error {
-	return c.WalletPassphraseChangeAsync(old, new).Receive()
+// GetWalletLockState returns whether the wallet is locked, the scope it is
+// unlocked for and when it will lock itself again.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) GetWalletLockState() (*btcjson.WalletLockStateResult, error) {
+	return c.GetWalletLockStateAsync().Receive()
 }
 
 // *************************
@@ -2181,6 +3727,84 @@ func (c *Client) ImportPrivKeyRescan(privKeyWIF *btcutil.WIF, label string, resc
 	return c.ImportPrivKeyRescanAsync(privKeyWIF, label, rescan).Receive()
 }
 
+// FutureImportCoreWalletResult is a future promise to deliver the result of an
+// ImportCoreWalletAsync RPC invocation (or an applicable error).
+type FutureImportCoreWalletResult chan *response
+
+// Receive waits for the response promised by the future and returns what was
+// imported from the wallet export.
+func (r FutureImportCoreWalletResult) Receive() (*btcjson.ImportCoreWalletResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result btcjson.ImportCoreWalletResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ImportCoreWalletAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See ImportCoreWallet for the blocking version and more details.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) ImportCoreWalletAsync(filename, format, account string,
+	rescan bool) FutureImportCoreWalletResult {
+
+	cmd := btcjson.NewImportCoreWalletCmd(filename, &format, &account,
+		&rescan)
+	return c.sendCmd(cmd)
+}
+
+// ImportCoreWallet imports the keys, scripts and labels of a wallet export of
+// the reference client into the account.  The format is either
+// btcjson.CoreWalletFormatDump or btcjson.CoreWalletFormatDescriptors.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) ImportCoreWallet(filename, format, account string,
+	rescan bool) (*btcjson.ImportCoreWalletResult, error) {
+
+	return c.ImportCoreWalletAsync(filename, format, account,
+		rescan).Receive()
+}
+
+// ImportPrivKeyOriginAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See ImportPrivKeyOrigin for the blocking version and more details.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) ImportPrivKeyOriginAsync(privKeyWIF *btcutil.WIF, label string,
+	rescan bool, keyOrigin string) FutureImportPrivKeyResult {
+
+	wif := ""
+	if privKeyWIF != nil {
+		wif = privKeyWIF.String()
+	}
+
+	cmd := btcjson.NewImportPrivKeyCmd(wif, &label, &rescan)
+	cmd.KeyOrigin = &keyOrigin
+	return c.sendCmd(cmd)
+}
+
+// ImportPrivKeyOrigin imports the passed private key like ImportPrivKeyRescan
+// and records its origin, in the [fingerprint/path] form of descriptors.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) ImportPrivKeyOrigin(privKeyWIF *btcutil.WIF, label string,
+	rescan bool, keyOrigin string) error {
+
+	return c.ImportPrivKeyOriginAsync(privKeyWIF, label, rescan,
+		keyOrigin).Receive()
+}
+
 // FutureImportPubKeyResult is a future promise to deliver the result of an
 // ImportPubKeyAsync RPC invocation (or an applicable error).
 type FutureImportPubKeyResult chan *response
@@ -2223,6 +3847,159 @@ func (c *Client) ImportPubKeyRescan(pubKey string, rescan bool) error {
 	return c.ImportPubKeyRescanAsync(pubKey, rescan).Receive()
 }
 
+// ImportPubKeyOriginAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See ImportPubKeyOrigin for the blocking version and more details.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) ImportPubKeyOriginAsync(pubKey string, rescan bool,
+	keyOrigin string) FutureImportPubKeyResult {
+
+	cmd := btcjson.NewImportPubKeyCmd(pubKey, &rescan)
+	cmd.KeyOrigin = &keyOrigin
+	return c.sendCmd(cmd)
+}
+
+// ImportPubKeyOrigin imports the passed public key like ImportPubKeyRescan and
+// records its origin, in the [fingerprint/path] form of descriptors.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) ImportPubKeyOrigin(pubKey string, rescan bool, keyOrigin string) error {
+	return c.ImportPubKeyOriginAsync(pubKey, rescan, keyOrigin).Receive()
+}
+
+// FutureListKeyOriginsResult is a future promise to deliver the result of a
+// ListKeyOriginsAsync RPC invocation (or an applicable error).
+type FutureListKeyOriginsResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// origins of the keys of the wallet.
+func (r FutureListKeyOriginsResult) Receive() ([]btcjson.KeyOriginResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var origins []btcjson.KeyOriginResult
+	err = json.Unmarshal(res, &origins)
+	if err != nil {
+		return nil, err
+	}
+	return origins, nil
+}
+
+// ListKeyOriginsAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See ListKeyOrigins for the blocking version and more details.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) ListKeyOriginsAsync(account *string) FutureListKeyOriginsResult {
+	cmd := btcjson.NewListKeyOriginsCmd(account)
+	return c.sendCmd(cmd)
+}
+
+// ListKeyOrigins returns the origin of every key of the wallet, or of the
+// passed account when it is not nil.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) ListKeyOrigins(account *string) ([]btcjson.KeyOriginResult, error) {
+	return c.ListKeyOriginsAsync(account).Receive()
+}
+
+// FutureBackupWalletResult is a future promise to deliver the result of a
+// BackupWalletAsync or RestoreWalletAsync RPC invocation (or an applicable
+// error).
+type FutureBackupWalletResult chan *response
+
+// Receive waits for the response promised by the future and returns the result
+// of backing up or restoring the wallet.
+func (r FutureBackupWalletResult) Receive() error {
+	_, err := receiveFuture(r)
+	return err
+}
+
+// BackupWalletAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See BackupWallet for the blocking version and more details.
+func (c *Client) BackupWalletAsync(destination string) FutureBackupWalletResult {
+	cmd := btcjson.NewBackupWalletCmd(destination)
+	return c.sendCmd(cmd)
+}
+
+// BackupWallet writes an encrypted copy of the wallet database to the passed
+// destination on the wallet server.
+func (c *Client) BackupWallet(destination string) error {
+	return c.BackupWalletAsync(destination).Receive()
+}
+
+// RestoreWalletAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See RestoreWallet for the blocking version and more details.
+func (c *Client) RestoreWalletAsync(backupFile string) FutureBackupWalletResult {
+	cmd := btcjson.NewRestoreWalletCmd(backupFile)
+	return c.sendCmd(cmd)
+}
+
+// RestoreWallet replaces the wallet with the passed backup file on the wallet
+// server after checking that the backup can be decrypted.
+func (c *Client) RestoreWallet(backupFile string) error {
+	return c.RestoreWalletAsync(backupFile).Receive()
+}
+
+// FutureVerifyWalletBackupResult is a future promise to deliver the result of
+// a VerifyWalletBackupAsync RPC invocation (or an applicable error).
+type FutureVerifyWalletBackupResult chan *response
+
+// Receive waits for the response promised by the future and returns the result
+// of checking the backup.
+func (r FutureVerifyWalletBackupResult) Receive() (*btcjson.VerifyWalletBackupResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result btcjson.VerifyWalletBackupResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// VerifyWalletBackupAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See VerifyWalletBackup for the blocking version and more details.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) VerifyWalletBackupAsync(path string) FutureVerifyWalletBackupResult {
+	var cmd *btcjson.VerifyWalletBackupCmd
+	if path == "" {
+		cmd = btcjson.NewVerifyWalletBackupCmd(nil)
+	} else {
+		cmd = btcjson.NewVerifyWalletBackupCmd(&path)
+	}
+	return c.sendCmd(cmd)
+}
+
+// VerifyWalletBackup checks that the backup at the passed path on the wallet
+// server can be decrypted.  An empty path checks the most recent automatic
+// backup.
+//
+// NOTE: This is a pktwallet extension.
+func (c *Client) VerifyWalletBackup(path string) (*btcjson.VerifyWalletBackupResult, error) {
+	return c.VerifyWalletBackupAsync(path).Receive()
+}
+
 // ***********************
 // Miscellaneous Functions
 // ***********************
@@ -0,0 +1,189 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rpcclient
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/btcjson"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// sentParams returns the JSON encoded parameters of the request sent by the
+// passed function to the server of the client it is given.
+func sentParams(t *testing.T, send func(c *Client)) string {
+	t.Helper()
+
+	requests := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests <- body
+		w.Write([]byte(`{"result":null,"error":{"code":-1,"message":"test"},"id":1}`))
+	}))
+	defer server.Close()
+
+	client, err := New(&ConnConfig{
+		Host:         strings.TrimPrefix(server.URL, "http://"),
+		User:         "user",
+		Pass:         "pass",
+		DisableTLS:   true,
+		HTTPPostMode: true,
+	}, nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer client.Shutdown()
+
+	send(client)
+	select {
+	case body := <-requests:
+		var request btcjson.Request
+		if err := json.Unmarshal(body, &request); err != nil {
+			t.Fatalf("unable to decode request %s: %v", body, err)
+		}
+		params, err := json.Marshal(request.Params)
+		if err != nil {
+			t.Fatalf("unable to encode params: %v", err)
+		}
+		return string(params)
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no request sent")
+	}
+	return ""
+}
+
+// testAddress returns an address to pay in the tests.
+func testAddress(t *testing.T) btcutil.Address {
+	t.Helper()
+
+	addr, err := btcutil.NewAddressPubKeyHash(make([]byte, 20),
+		&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("NewAddressPubKeyHash: %v", err)
+	}
+	return addr
+}
+
+// TestSendToAddressInputs ensures the inputs and change options are sent even
+// though the comments preceding them are not set.
+func TestSendToAddressInputs(t *testing.T) {
+	addr := testAddress(t)
+	op := wire.OutPoint{Hash: chainhash.Hash{1}, Index: 2}
+	tests := []struct {
+		name   string
+		change *btcjson.ChangeOptions
+		want   string
+	}{
+		{
+			name:   "change",
+			change: &btcjson.ChangeOptions{Address: btcjson.String("change")},
+			want: `["` + addr.EncodeAddress() + `",1,"","",` +
+				`{"address":"change"},[{"txid":"` + op.Hash.String() +
+				`","vout":2}]]`,
+		},
+		{
+			name: "default change",
+			want: `["` + addr.EncodeAddress() + `",1,"","",{},` +
+				`[{"txid":"` + op.Hash.String() + `","vout":2}]]`,
+		},
+	}
+	for _, test := range tests {
+		params := sentParams(t, func(c *Client) {
+			c.SendToAddressInputsAsync(addr, btcutil.Amount(1e8),
+				[]*wire.OutPoint{&op}, test.change)
+		})
+		if params != test.want {
+			t.Errorf("%s: sent %s, want %s", test.name, params,
+				test.want)
+		}
+	}
+}
+
+// TestSendManyChange ensures the change options are sent even though the
+// comment preceding them is not set.
+func TestSendManyChange(t *testing.T) {
+	addr := testAddress(t)
+	change := &btcjson.ChangeOptions{Type: btcjson.String("p2wpkh")}
+	params := sentParams(t, func(c *Client) {
+		amounts := map[btcutil.Address]btcutil.Amount{addr: 1e8}
+		c.SendManyChangeAsync("acct", amounts, 6, change)
+	})
+	want := `["acct",{"` + addr.EncodeAddress() + `":1},6,"",` +
+		`{"type":"p2wpkh"}]`
+	if params != want {
+		t.Errorf("sent %s, want %s", params, want)
+	}
+}
+
+// TestListSinceBlockPage ensures the cursor and count are sent along with the
+// optional parameters preceding them.
+func TestListSinceBlockPage(t *testing.T) {
+	hash := chainhash.Hash{3}
+	tests := []struct {
+		name      string
+		blockHash *chainhash.Hash
+		cursor    string
+		want      string
+	}{
+		{
+			name: "first page of all transactions",
+			want: `["",1,false,true,false,"",50]`,
+		},
+		{
+			name:      "next page",
+			blockHash: &hash,
+			cursor:    "c1",
+			want: `["` + hash.String() + `",1,false,true,false,` +
+				`"c1",50]`,
+		},
+	}
+	for _, test := range tests {
+		params := sentParams(t, func(c *Client) {
+			c.ListSinceBlockPageAsync(test.blockHash, 1, test.cursor,
+				50)
+		})
+		if params != test.want {
+			t.Errorf("%s: sent %s, want %s", test.name, params,
+				test.want)
+		}
+	}
+}
+
+// TestCreatePaymentURI ensures the label and message are sent when no amount
+// is requested.
+func TestCreatePaymentURI(t *testing.T) {
+	params := sentParams(t, func(c *Client) {
+		c.CreatePaymentURIAsync(0, "Alice", "Order 1")
+	})
+	want := `[0,"Alice","Order 1"]`
+	if params != want {
+		t.Errorf("sent %s, want %s", params, want)
+	}
+}
+
+// TestScheduleSend ensures the interval and count of a recurring send are sent
+// when the mode is left to its default.
+func TestScheduleSend(t *testing.T) {
+	addr := testAddress(t)
+	params := sentParams(t, func(c *Client) {
+		amounts := map[btcutil.Address]btcutil.Amount{addr: 1e8}
+		c.ScheduleSendAsync("payouts", amounts, 700000, nil,
+			btcjson.Int64(1008), btcjson.Int(4))
+	})
+	want := `["payouts",{"` + addr.EncodeAddress() + `":1},700000,` +
+		`"hold",1008,4]`
+	if params != want {
+		t.Errorf("sent %s, want %s", params, want)
+	}
+}
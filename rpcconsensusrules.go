@@ -0,0 +1,62 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/pkt-cash/pktd/btcjson"
+)
+
+// handleGetConsensusRules implements the getconsensusrules command.
+func handleGetConsensusRules(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetConsensusRulesCmd)
+
+	// The rules default to those of the block following the best block.
+	best := s.cfg.Chain.BestSnapshot()
+	height := best.Height + 1
+	if c.Height != nil && *c.Height != -1 {
+		height = *c.Height
+	}
+	if height < 0 || height > best.Height+1 {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCOutOfRange,
+			Message: fmt.Sprintf("Height %d is out of range [0, %d]",
+				height, best.Height+1),
+		}
+	}
+
+	rules, err := s.cfg.Chain.RulesAtHeight(height)
+	if err != nil {
+		context := "Failed to obtain consensus rules"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	result := &btcjson.GetConsensusRulesResult{
+		Height:               rules.Height,
+		MinBlockVersion:      rules.MinBlockVersion,
+		SerializedHeight:     rules.SerializedHeight,
+		ScriptFlags:          rules.ScriptFlags.String(),
+		Deployments:          make(map[string]string),
+		SubsidyEpoch:         rules.Subsidy.Epoch,
+		NextSubsidyHeight:    rules.Subsidy.NextEpochHeight,
+		Subsidy:              rules.Subsidy.Subsidy,
+		NetworkStewardPayout: rules.Subsidy.NetworkStewardPayout,
+		PacketCrypt:          rules.PacketCrypt.Enabled,
+		AnnAging:             rules.PacketCrypt.AnnAging,
+		AnnWaitPeriod:        rules.PacketCrypt.AnnWaitPeriod,
+	}
+	for deployment, state := range rules.Deployments {
+		name, err := softForkName(deployment)
+		if err != nil {
+			return nil, internalRPCError(err.Error(), "")
+		}
+		status, err := softForkStatus(state)
+		if err != nil {
+			return nil, internalRPCError(err.Error(), "")
+		}
+		result.Deployments[name] = status
+	}
+	return result, nil
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/btcjson"
+)
+
+// TestConsensusRules ensures getconsensusrules validates the height and
+// reports the rules of the block at it.
+func TestConsensusRules(t *testing.T) {
+	tc, teardown := newTestChain(t, "consensusrules", nil)
+	defer teardown()
+	params, chain, g := tc.params, tc.chain, tc.gen
+
+	s := &rpcServer{cfg: rpcserverConfig{Chain: chain, ChainParams: params}}
+	if _, err := g.generate(params.GenesisHash, 3, nil); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+
+	// Only the heights of the main chain and of the next block have rules.
+	for _, height := range []int32{-2, 5} {
+		cmd := btcjson.NewGetConsensusRulesCmd(&height)
+		if _, err := handleGetConsensusRules(s, cmd, nil); err == nil {
+			t.Errorf("getconsensusrules %d: unexpected success", height)
+		}
+	}
+
+	// The rules default to those of the next block.
+	result, err := handleGetConsensusRules(s,
+		btcjson.NewGetConsensusRulesCmd(nil), nil)
+	if err != nil {
+		t.Fatalf("getconsensusrules: %v", err)
+	}
+	rules := result.(*btcjson.GetConsensusRulesResult)
+	if rules.Height != 4 || rules.MinBlockVersion != 1 ||
+		rules.SerializedHeight || rules.ScriptFlags != "P2SH" ||
+		rules.SubsidyEpoch != 0 ||
+		rules.NextSubsidyHeight != params.SubsidyReductionInterval ||
+		rules.Subsidy != blockchain.CalcBlockSubsidy(4, params) ||
+		rules.NetworkStewardPayout != 0 || rules.PacketCrypt ||
+		!rules.AnnAging {
+
+		t.Fatalf("unexpected rules %+v", rules)
+	}
+	for _, name := range []string{"dummy", "csv", "segwit"} {
+		if rules.Deployments[name] != "defined" {
+			t.Fatalf("unexpected deployments %v", rules.Deployments)
+		}
+	}
+
+	// The genesis block predates BIP0016.
+	height := int32(0)
+	result, err = handleGetConsensusRules(s,
+		btcjson.NewGetConsensusRulesCmd(&height), nil)
+	if err != nil {
+		t.Fatalf("getconsensusrules 0: %v", err)
+	}
+	rules = result.(*btcjson.GetConsensusRulesResult)
+	if rules.Height != 0 || rules.ScriptFlags != "NONE" || rules.AnnAging {
+		t.Fatalf("unexpected genesis rules %+v", rules)
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/pkt-cash/pktd/btcjson"
+	"github.com/pkt-cash/pktd/deposits"
+)
+
+// maxDepositRefs is the maximum number of reference IDs a single
+// createdepositaddresses request may bind.
+const maxDepositRefs = 10000
+
+// errNoDepositTracker is returned by the deposit commands when the deposit
+// tracker is not enabled.
+var errNoDepositTracker = &btcjson.RPCError{
+	Code: btcjson.ErrRPCNoDepositTracker,
+	Message: "The deposit tracker must be enabled for this command " +
+		"(specify --depositbundle)",
+}
+
+// handleCreateDepositAddresses implements the createdepositaddresses command.
+func handleCreateDepositAddresses(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	if s.cfg.Deposits == nil {
+		return nil, errNoDepositTracker
+	}
+	c := cmd.(*btcjson.CreateDepositAddressesCmd)
+	if len(c.Refs) > maxDepositRefs {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCInvalidParameter,
+			Message: fmt.Sprintf("At most %d reference IDs may be "+
+				"passed", maxDepositRefs),
+		}
+	}
+
+	for _, ref := range c.Refs {
+		if err := deposits.CheckRef(ref); err != nil {
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCInvalidParameter,
+				Message: err.Error(),
+			}
+		}
+	}
+
+	addrs, err := s.cfg.Deposits.NewAddresses(c.Refs)
+	if err != nil {
+		context := "Failed to bind deposit addresses"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	return depositAddressResults(addrs), nil
+}
+
+// depositAddressResults returns the result of createdepositaddresses for the
+// passed addresses.
+func depositAddressResults(addrs []deposits.Address) []btcjson.DepositAddressResult {
+	results := make([]btcjson.DepositAddressResult, len(addrs))
+	for i, a := range addrs {
+		results[i] = btcjson.DepositAddressResult{
+			Ref:     a.Ref,
+			Address: a.Address,
+			Index:   a.Index,
+		}
+	}
+	return results
+}
+
+// handleListDeposits implements the listdeposits command.
+func handleListDeposits(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	if s.cfg.Deposits == nil {
+		return nil, errNoDepositTracker
+	}
+	c := cmd.(*btcjson.ListDepositsCmd)
+
+	var ref string
+	if c.Ref != nil {
+		ref = *c.Ref
+	}
+	return depositResults(s.cfg.Deposits.Deposits(ref)), nil
+}
+
+// depositResults returns the result of listdeposits for the passed deposits.
+func depositResults(list []deposits.Deposit) []btcjson.DepositResult {
+	results := make([]btcjson.DepositResult, len(list))
+	for i := range list {
+		d := &list[i]
+		results[i] = btcjson.DepositResult{
+			Ref:           d.Ref,
+			Address:       d.Address,
+			TxID:          d.TxID.String(),
+			Vout:          d.Vout,
+			Amount:        d.Amount.ToBTC(),
+			BlockHash:     d.BlockHash.String(),
+			Height:        d.Height,
+			Confirmations: d.Confirmations,
+			Reported:      d.Reported,
+		}
+	}
+	return results
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/pkt-cash/pktd/btcjson"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/deposits"
+)
+
+// TestDepositResults ensures deposits are returned in order with their
+// amounts in coins and their hashes as strings.
+func TestDepositResults(t *testing.T) {
+	list := []deposits.Deposit{
+		{
+			Ref:           "alice",
+			Address:       "addr1",
+			TxID:          chainhash.Hash{1},
+			Vout:          2,
+			Amount:        150000000,
+			BlockHash:     chainhash.Hash{3},
+			Height:        100,
+			Confirmations: 4,
+			Reported:      1,
+		},
+		{Ref: "bob", Address: "addr2", Height: 103, Confirmations: 1},
+	}
+	results := depositResults(list)
+	want := btcjson.DepositResult{
+		Ref:           "alice",
+		Address:       "addr1",
+		TxID:          list[0].TxID.String(),
+		Vout:          2,
+		Amount:        1.5,
+		BlockHash:     list[0].BlockHash.String(),
+		Height:        100,
+		Confirmations: 4,
+		Reported:      1,
+	}
+	if len(results) != 2 || results[0] != want || results[1].Ref != "bob" ||
+		results[1].Reported != 0 {
+
+		t.Fatalf("depositResults: got %+v", results)
+	}
+
+	addrs := depositAddressResults([]deposits.Address{
+		{Ref: "alice", Address: "addr1", Index: 7},
+	})
+	if len(addrs) != 1 || addrs[0] != (btcjson.DepositAddressResult{
+		Ref: "alice", Address: "addr1", Index: 7}) {
+
+		t.Fatalf("depositAddressResults: got %+v", addrs)
+	}
+}
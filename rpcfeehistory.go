@@ -0,0 +1,93 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/blockchain/indexers"
+	"github.com/pkt-cash/pktd/btcjson"
+)
+
+// maxFeeHistoryBlocks is the maximum number of blocks returned by
+// getfeehistory.
+const maxFeeHistoryBlocks = 2016
+
+// handleGetFeeHistory implements the getfeehistory command.
+func handleGetFeeHistory(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	if s.cfg.FeeStatsIndex == nil {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCNoFeeStatsIndex,
+			Message: "The fee statistics index must be enabled " +
+				"for this command (specify --feestatsindex)",
+		}
+	}
+
+	c := cmd.(*btcjson.GetFeeHistoryCmd)
+	end := int64(math.MaxInt64)
+	if c.End != nil {
+		end = *c.End
+	}
+	if c.Start < 0 || end < c.Start {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "The range may not be negative nor end before it starts",
+		}
+	}
+
+	// One more block than returned is requested to tell whether the
+	// result is truncated.
+	var stats []*indexers.FeeStats
+	var err error
+	if c.ByTime != nil && *c.ByTime {
+		stats, err = s.cfg.FeeStatsIndex.FeeStatsByTime(c.Start, end,
+			maxFeeHistoryBlocks+1)
+	} else {
+		if c.Start > math.MaxInt32 {
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCOutOfRange,
+				Message: "Start height is out of range",
+			}
+		}
+		if end > math.MaxInt32 {
+			end = math.MaxInt32
+		}
+		stats, err = s.cfg.FeeStatsIndex.FeeStatsByHeight(int32(c.Start),
+			int32(end), maxFeeHistoryBlocks+1)
+	}
+	if err != nil {
+		context := "Failed to load fee statistics"
+		return nil, internalRPCError(err.Error(), context)
+	}
+
+	result := &btcjson.GetFeeHistoryResult{
+		Percentiles: indexers.FeeStatsPercentiles[:],
+		Blocks:      make([]btcjson.FeeHistoryBlock, 0, len(stats)),
+	}
+	if len(stats) > maxFeeHistoryBlocks {
+		result.Truncated = true
+		stats = stats[:maxFeeHistoryBlocks]
+	}
+	// The fee rates are converted from units per 1000 virtual bytes to
+	// BTC/KB.
+	for _, blockStats := range stats {
+		percentiles := make([]float64, len(blockStats.FeeRatePercentiles))
+		for i, feeRate := range blockStats.FeeRatePercentiles {
+			percentiles[i] = btcutil.Amount(feeRate).ToBTC()
+		}
+		result.Blocks = append(result.Blocks, btcjson.FeeHistoryBlock{
+			Height:             blockStats.Height,
+			Time:               blockStats.Time,
+			TxCount:            blockStats.TxCount,
+			VSize:              blockStats.VSize,
+			TotalFee:           btcutil.Amount(blockStats.Fees).ToBTC(),
+			MinFeeRate:         btcutil.Amount(blockStats.MinFeeRate).ToBTC(),
+			MaxFeeRate:         btcutil.Amount(blockStats.MaxFeeRate).ToBTC(),
+			FeeRatePercentiles: percentiles,
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,300 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/difficulty"
+	"github.com/pkt-cash/pktd/btcjson"
+)
+
+const (
+	// defaultHashrateBlocks is the default number of blocks the hash rates
+	// of getnetworkhashrate are estimated over.
+	defaultHashrateBlocks = 120
+
+	// maxHashrateBlocks is the maximum number of blocks the hash rates of
+	// getnetworkhashrate are estimated over, a week of blocks.
+	maxHashrateBlocks = 10080
+
+	// defaultDifficultyHistory is the default number of blocks returned by
+	// getdifficultyhistory.
+	defaultDifficultyHistory = 100
+
+	// maxDifficultyHistory is the maximum number of blocks returned by
+	// getdifficultyhistory.
+	maxDifficultyHistory = 1000
+
+	// defaultAnalyticsBlocks is the default number of blocks summarized by
+	// getmininganalytics.
+	defaultAnalyticsBlocks = 120
+)
+
+// workRatio returns a divided by b as a float, as works may not fit in 64
+// bits.
+func workRatio(a, b *big.Int) float64 {
+	if b.Sign() == 0 {
+		return 0
+	}
+	r, _ := new(big.Rat).SetFrac(a, b).Float64()
+	return r
+}
+
+// packetCryptWorkResult returns the composition of the effective target of a
+// block mined with announcements, or nil for a block without an announcement
+// commitment.
+func (s *rpcServer) packetCryptWorkResult(work *difficulty.BlockWork) *btcjson.PacketCryptWorkResult {
+	if work.AnnCount == 0 {
+		return nil
+	}
+	effective := work.EffectiveTarget()
+	return &btcjson.PacketCryptWorkResult{
+		AnnCount:      work.AnnCount,
+		AnnMinBits:    strconv.FormatInt(int64(work.AnnMinTarget), 16),
+		AnnDifficulty: getDifficultyRatio(work.AnnMinTarget, s.cfg.ChainParams),
+		HeaderWork:    fmt.Sprintf("%064x", difficulty.Work(work.Target)),
+		AnnMinWork:    fmt.Sprintf("%064x", difficulty.Work(work.AnnMinTarget)),
+		AnnWork:       fmt.Sprintf("%064x", work.Announcements()),
+		EffectiveBits: strconv.FormatInt(int64(effective), 16),
+		EffectiveDifficulty: getDifficultyRatio(effective,
+			s.cfg.ChainParams),
+		EffectiveWork: fmt.Sprintf("%064x", work.Effective()),
+	}
+}
+
+// rpcEndHeight returns the height ending the window of a command, the best
+// height when it is nil or -1.
+func (s *rpcServer) rpcEndHeight(height *int32) (int32, error) {
+	best := s.cfg.Chain.BestSnapshot()
+	if height == nil || *height == -1 {
+		return best.Height, nil
+	}
+	if *height < 0 || *height > best.Height {
+		return 0, &btcjson.RPCError{
+			Code: btcjson.ErrRPCOutOfRange,
+			Message: fmt.Sprintf("Height %d is out of range [0, %d]",
+				*height, best.Height),
+		}
+	}
+	return *height, nil
+}
+
+// handleGetNetworkHashrate implements the getnetworkhashrate command.
+func handleGetNetworkHashrate(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetNetworkHashrateCmd)
+
+	blocks := int32(defaultHashrateBlocks)
+	if c.Blocks != nil {
+		blocks = *c.Blocks
+	}
+	if blocks <= 0 || blocks > maxHashrateBlocks {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCInvalidParameter,
+			Message: fmt.Sprintf("Blocks must be between 1 and %d",
+				maxHashrateBlocks),
+		}
+	}
+	endHeight, err := s.rpcEndHeight(c.Height)
+	if err != nil {
+		return nil, err
+	}
+	startHeight := endHeight - blocks
+	if startHeight < 0 {
+		startHeight = 0
+	}
+
+	works := make([]difficulty.BlockWork, 0, endHeight-startHeight+1)
+	for height := startHeight; height <= endHeight; height++ {
+		select {
+		case <-closeChan:
+			return nil, ErrClientQuit
+		default:
+		}
+
+		block, err := s.cfg.Chain.BlockByHeight(height)
+		if err != nil {
+			context := "Failed to fetch block"
+			return nil, internalRPCError(err.Error(), context)
+		}
+		works = append(works, packetcrypt.ExtractBlockWork(block.MsgBlock()))
+	}
+
+	hashrate := difficulty.EstimateHashrate(works)
+	return &btcjson.GetNetworkHashrateResult{
+		StartHeight:   startHeight,
+		EndHeight:     endHeight,
+		TimeSpan:      hashrate.Seconds,
+		Announcements: hashrate.AnnCount,
+		BlockWork:     fmt.Sprintf("%064x", hashrate.BlockWork),
+		AnnWork:       fmt.Sprintf("%064x", hashrate.AnnWork),
+		BlockHashrate: hashrate.BlockRate,
+		AnnHashrate:   hashrate.AnnRate,
+	}, nil
+}
+
+// handleGetDifficultyHistory implements the getdifficultyhistory command.
+func handleGetDifficultyHistory(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetDifficultyHistoryCmd)
+
+	count := int32(defaultDifficultyHistory)
+	if c.Count != nil {
+		count = *c.Count
+	}
+	if count <= 0 || count > maxDifficultyHistory {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCInvalidParameter,
+			Message: fmt.Sprintf("Count must be between 1 and %d",
+				maxDifficultyHistory),
+		}
+	}
+	interval := int32(1)
+	if c.Interval != nil {
+		interval = *c.Interval
+	}
+	if interval <= 0 {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Interval must be positive",
+		}
+	}
+	endHeight, err := s.rpcEndHeight(c.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	// The blocks are walked back from the end height and returned in
+	// height order.
+	results := make([]btcjson.DifficultyHistoryResult, 0, count)
+	for height := endHeight; height >= 0 && len(results) < int(count); height -= interval {
+		select {
+		case <-closeChan:
+			return nil, ErrClientQuit
+		default:
+		}
+
+		block, err := s.cfg.Chain.BlockByHeight(height)
+		if err != nil {
+			context := "Failed to fetch block"
+			return nil, internalRPCError(err.Error(), context)
+		}
+		work := packetcrypt.ExtractBlockWork(block.MsgBlock())
+		effective := work.EffectiveTarget()
+		result := btcjson.DifficultyHistoryResult{
+			Height:        height,
+			Hash:          block.Hash().String(),
+			Time:          work.Timestamp.Unix(),
+			Bits:          strconv.FormatInt(int64(work.Target), 16),
+			Difficulty:    getDifficultyRatio(work.Target, s.cfg.ChainParams),
+			AnnCount:      work.AnnCount,
+			EffectiveBits: strconv.FormatInt(int64(effective), 16),
+			EffectiveDifficulty: getDifficultyRatio(effective,
+				s.cfg.ChainParams),
+		}
+		if work.AnnCount > 0 {
+			result.AnnMinBits = strconv.FormatInt(
+				int64(work.AnnMinTarget), 16)
+			result.AnnDifficulty = getDifficultyRatio(
+				work.AnnMinTarget, s.cfg.ChainParams)
+		}
+		results = append(results, result)
+	}
+	for i, j := 0, len(results)-1; i < j; i, j = i+1, j-1 {
+		results[i], results[j] = results[j], results[i]
+	}
+	return results, nil
+}
+
+// handleGetMiningAnalytics implements the getmininganalytics command.
+func handleGetMiningAnalytics(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetMiningAnalyticsCmd)
+
+	blocks := int32(defaultAnalyticsBlocks)
+	if c.Blocks != nil {
+		blocks = *c.Blocks
+	}
+	if blocks <= 0 || blocks > maxHashrateBlocks {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCInvalidParameter,
+			Message: fmt.Sprintf("Blocks must be between 1 and %d",
+				maxHashrateBlocks),
+		}
+	}
+	endHeight, err := s.rpcEndHeight(c.Height)
+	if err != nil {
+		return nil, err
+	}
+	startHeight := endHeight - blocks + 1
+	if startHeight < 0 {
+		startHeight = 0
+	}
+
+	result := &btcjson.GetMiningAnalyticsResult{
+		StartHeight: startHeight,
+		EndHeight:   endHeight,
+	}
+	headerWork := new(big.Int)
+	annWork := new(big.Int)
+	effectiveWork := new(big.Int)
+	var difficulties, annDifficulties, effectiveDifficulties float64
+	var reductions float64
+	for height := startHeight; height <= endHeight; height++ {
+		select {
+		case <-closeChan:
+			return nil, ErrClientQuit
+		default:
+		}
+
+		block, err := s.cfg.Chain.BlockByHeight(height)
+		if err != nil {
+			context := "Failed to fetch block"
+			return nil, internalRPCError(err.Error(), context)
+		}
+		work := packetcrypt.ExtractBlockWork(block.MsgBlock())
+		params := s.cfg.ChainParams
+		header := difficulty.Work(work.Target)
+		effective := work.Effective()
+		headerWork.Add(headerWork, header)
+		annWork.Add(annWork, work.Announcements())
+		effectiveWork.Add(effectiveWork, effective)
+		difficulties += getDifficultyRatio(work.Target, params)
+		effectiveDifficulties += getDifficultyRatio(
+			work.EffectiveTarget(), params)
+		result.Blocks++
+		if work.AnnCount == 0 {
+			continue
+		}
+
+		if result.AnnBlocks == 0 || work.AnnCount < result.MinAnnCount {
+			result.MinAnnCount = work.AnnCount
+		}
+		if work.AnnCount > result.MaxAnnCount {
+			result.MaxAnnCount = work.AnnCount
+		}
+		result.AnnBlocks++
+		result.AnnCount += work.AnnCount
+		annDifficulties += getDifficultyRatio(work.AnnMinTarget, params)
+		reductions += workRatio(header, effective)
+	}
+
+	result.AvgDifficulty = difficulties / float64(result.Blocks)
+	result.AvgEffectiveDifficulty = effectiveDifficulties /
+		float64(result.Blocks)
+	if result.AnnBlocks > 0 {
+		n := float64(result.AnnBlocks)
+		result.AvgAnnCount = float64(result.AnnCount) / n
+		result.AvgAnnDifficulty = annDifficulties / n
+		result.WorkReduction = reductions / n
+	}
+	result.HeaderWork = fmt.Sprintf("%064x", headerWork)
+	result.AnnWork = fmt.Sprintf("%064x", annWork)
+	result.EffectiveWork = fmt.Sprintf("%064x", effectiveWork)
+	result.AnnWorkShare = workRatio(annWork,
+		new(big.Int).Add(annWork, effectiveWork))
+	return result, nil
+}
@@ -0,0 +1,159 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"testing"
+
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/difficulty"
+	"github.com/pkt-cash/pktd/btcjson"
+)
+
+// TestHashrateHistory ensures getnetworkhashrate, getdifficultyhistory and
+// getmininganalytics validate their windows and report the work and
+// difficulty of the requested blocks.
+func TestHashrateHistory(t *testing.T) {
+	tc, teardown := newTestChain(t, "hashrate", nil)
+	defer teardown()
+	params, chain, g := tc.params, tc.chain, tc.gen
+
+	s := &rpcServer{cfg: rpcserverConfig{Chain: chain, ChainParams: params}}
+	hashes, err := g.generate(params.GenesisHash, 3, nil)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+
+	// Out of range windows are rejected.
+	for _, test := range []struct {
+		blocks int32
+		height int32
+	}{
+		{0, -1},
+		{maxHashrateBlocks + 1, -1},
+		{10, 4},
+		{10, -2},
+	} {
+		blocks, height := test.blocks, test.height
+		cmd := btcjson.NewGetNetworkHashrateCmd(&blocks, &height)
+		if _, err := handleGetNetworkHashrate(s, cmd, nil); err == nil {
+			t.Errorf("getnetworkhashrate %d %d: unexpected success",
+				blocks, height)
+		}
+	}
+	for _, test := range []struct {
+		count    int32
+		height   int32
+		interval int32
+	}{
+		{0, -1, 1},
+		{maxDifficultyHistory + 1, -1, 1},
+		{10, 4, 1},
+		{10, -1, 0},
+	} {
+		count, height, interval := test.count, test.height, test.interval
+		cmd := btcjson.NewGetDifficultyHistoryCmd(&count, &height, &interval)
+		if _, err := handleGetDifficultyHistory(s, cmd, nil); err == nil {
+			t.Errorf("getdifficultyhistory %d %d %d: unexpected success",
+				count, height, interval)
+		}
+	}
+
+	// The generated blocks have no announcement commitment, so their work
+	// is the work of their header target.  The work of the genesis block,
+	// which starts the window, is not counted.
+	cmd := btcjson.NewGetNetworkHashrateCmd(nil, nil)
+	result, err := handleGetNetworkHashrate(s, cmd, nil)
+	if err != nil {
+		t.Fatalf("getnetworkhashrate: %v", err)
+	}
+	hashrate := result.(*btcjson.GetNetworkHashrateResult)
+	wantWork := new(big.Int)
+	for _, hash := range hashes {
+		header, err := chain.HeaderByHash(hash)
+		if err != nil {
+			t.Fatalf("HeaderByHash: %v", err)
+		}
+		wantWork.Add(wantWork, difficulty.Work(header.Bits))
+	}
+	if hashrate.StartHeight != 0 || hashrate.EndHeight != 3 ||
+		hashrate.Announcements != 0 ||
+		hashrate.BlockWork != fmt.Sprintf("%064x", wantWork) ||
+		hashrate.AnnWork != fmt.Sprintf("%064x", 0) ||
+		hashrate.TimeSpan <= 0 || hashrate.BlockHashrate <= 0 ||
+		hashrate.AnnHashrate != 0 {
+
+		t.Fatalf("unexpected hash rate %+v", hashrate)
+	}
+
+	// The history walks back from the height by interval and is returned
+	// in height order.
+	count, interval := int32(5), int32(2)
+	historyCmd := btcjson.NewGetDifficultyHistoryCmd(&count, nil, &interval)
+	result, err = handleGetDifficultyHistory(s, historyCmd, nil)
+	if err != nil {
+		t.Fatalf("getdifficultyhistory: %v", err)
+	}
+	history := result.([]btcjson.DifficultyHistoryResult)
+	if len(history) != 2 || history[0].Hash != hashes[0].String() ||
+		history[1].Hash != hashes[2].String() {
+
+		t.Fatalf("unexpected history %+v", history)
+	}
+	for _, h := range history {
+		header, err := chain.HeaderByHash(hashes[h.Height-1])
+		if err != nil {
+			t.Fatalf("HeaderByHash: %v", err)
+		}
+		bits := strconv.FormatInt(int64(header.Bits), 16)
+		if h.Bits != bits || h.EffectiveBits != bits ||
+			h.Difficulty != h.EffectiveDifficulty || h.AnnCount != 0 ||
+			h.AnnMinBits != "" || h.Time != header.Timestamp.Unix() {
+
+			t.Fatalf("unexpected history %+v", h)
+		}
+	}
+
+	// Without announcements the effective work is the header work.
+	blocks := int32(2)
+	analyticsCmd := btcjson.NewGetMiningAnalyticsCmd(&blocks, nil)
+	result, err = handleGetMiningAnalytics(s, analyticsCmd, nil)
+	if err != nil {
+		t.Fatalf("getmininganalytics: %v", err)
+	}
+	analytics := result.(*btcjson.GetMiningAnalyticsResult)
+	if analytics.StartHeight != 2 || analytics.EndHeight != 3 ||
+		analytics.Blocks != 2 || analytics.AnnBlocks != 0 ||
+		analytics.HeaderWork != analytics.EffectiveWork ||
+		analytics.AnnWorkShare != 0 || analytics.AvgDifficulty <= 0 {
+
+		t.Fatalf("unexpected analytics %+v", analytics)
+	}
+
+	// The breakdown of a block mined with announcements adds up to its
+	// effective target, and blocks without announcements have none.
+	work := &difficulty.BlockWork{Target: 0x1d00ffff}
+	if r := s.packetCryptWorkResult(work); r != nil {
+		t.Fatalf("unexpected breakdown %+v without announcements", r)
+	}
+	work.AnnMinTarget = 0x2000ffff
+	work.AnnCount = 1000
+	breakdown := s.packetCryptWorkResult(work)
+	effective := difficulty.GetEffectiveTarget(work.Target,
+		work.AnnMinTarget, work.AnnCount)
+	wantAnnWork := new(big.Int).Mul(difficulty.Work(work.AnnMinTarget),
+		big.NewInt(1000))
+	if breakdown == nil || breakdown.AnnCount != 1000 ||
+		breakdown.AnnMinBits != "2000ffff" ||
+		breakdown.EffectiveBits != strconv.FormatInt(int64(effective), 16) ||
+		breakdown.AnnWork != fmt.Sprintf("%064x", wantAnnWork) ||
+		breakdown.EffectiveWork != fmt.Sprintf("%064x",
+			difficulty.Work(effective)) {
+
+		t.Fatalf("unexpected breakdown %+v", breakdown)
+	}
+}
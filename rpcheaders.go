@@ -0,0 +1,199 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/pkt-cash/pktd/btcjson"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// maxBlockHeadersPerRequest is the maximum number of headers returned by
+// getblockheaders, and the number of headers of each blockheaders
+// notification sent by streamblockheaders.
+const maxBlockHeadersPerRequest = wire.MaxBlockHeadersPerMsg
+
+// blockHeadersResult returns the JSON result describing the passed main chain
+// headers starting at the passed height, either as a single hex string of the
+// serialized headers or as verbose objects.
+func (s *rpcServer) blockHeadersResult(startHeight int32,
+	headers []wire.BlockHeader, verbose bool,
+	bestHeight int32) (*btcjson.BlockHeadersResult, error) {
+
+	result := &btcjson.BlockHeadersResult{
+		StartHeight: startHeight,
+		Count:       int32(len(headers)),
+	}
+	if !verbose {
+		var buf bytes.Buffer
+		buf.Grow(len(headers) * wire.MaxBlockHeaderPayload)
+		for i := range headers {
+			if err := headers[i].Serialize(&buf); err != nil {
+				context := "Failed to serialize block header"
+				return nil, internalRPCError(err.Error(), context)
+			}
+		}
+		result.Hex = hex.EncodeToString(buf.Bytes())
+		return result, nil
+	}
+
+	result.Headers = make([]btcjson.GetBlockHeaderVerboseResult, len(headers))
+	hash := headers[0].BlockHash()
+	for i := range headers {
+		height := startHeight + int32(i)
+
+		// The hash of the next block is the one of the next header,
+		// except for the last header which may have been followed by
+		// blocks outside of the range.
+		var nextHash string
+		if i+1 < len(headers) {
+			next := headers[i+1].BlockHash()
+			nextHash = next.String()
+			result.Headers[i] = blockHeaderVerboseResult(&headers[i],
+				hash.String(), height, bestHeight, nextHash,
+				s.cfg.ChainParams)
+			hash = next
+			continue
+		}
+		if height < bestHeight {
+			next, err := s.cfg.Chain.BlockHashByHeight(height + 1)
+			if err != nil {
+				context := "No next block"
+				return nil, internalRPCError(err.Error(), context)
+			}
+			nextHash = next.String()
+		}
+		result.Headers[i] = blockHeaderVerboseResult(&headers[i],
+			hash.String(), height, bestHeight, nextHash,
+			s.cfg.ChainParams)
+	}
+	return result, nil
+}
+
+// handleGetBlockHeaders implements the getblockheaders command.
+func handleGetBlockHeaders(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetBlockHeadersCmd)
+
+	count := int32(maxBlockHeadersPerRequest)
+	if c.Count != nil {
+		count = *c.Count
+	}
+	if count <= 0 || count > maxBlockHeadersPerRequest {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCInvalidParameter,
+			Message: fmt.Sprintf("Count must be between 1 and %d",
+				maxBlockHeadersPerRequest),
+		}
+	}
+	best := s.cfg.Chain.BestSnapshot()
+	if c.StartHeight < 0 || c.StartHeight > best.Height {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCOutOfRange,
+			Message: fmt.Sprintf("Start height %d is out of range "+
+				"[0, %d]", c.StartHeight, best.Height),
+		}
+	}
+
+	headers, err := s.cfg.Chain.HeadersByHeight(c.StartHeight,
+		c.StartHeight+count)
+	if err != nil {
+		context := "Failed to fetch block headers"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	if len(headers) == 0 {
+		// The chain was reorganized to a lower height since the best
+		// snapshot was taken.
+		return &btcjson.BlockHeadersResult{StartHeight: c.StartHeight}, nil
+	}
+	verbose := c.Verbose != nil && *c.Verbose
+	return s.blockHeadersResult(c.StartHeight, headers, verbose, best.Height)
+}
+
+// handleStreamBlockHeaders implements the streamblockheaders command extension
+// for websocket connections.  The headers are sent as blockheaders
+// notifications of up to maxBlockHeadersPerRequest headers, and the reply
+// describes the last header sent.
+func handleStreamBlockHeaders(wsc *wsClient, icmd interface{}) (interface{}, error) {
+	cmd, ok := icmd.(*btcjson.StreamBlockHeadersCmd)
+	if !ok {
+		return nil, btcjson.ErrRPCInternal
+	}
+	chain := wsc.server.cfg.Chain
+
+	best := chain.BestSnapshot()
+	endHeight := best.Height
+	if cmd.EndHeight != nil && *cmd.EndHeight < endHeight {
+		endHeight = *cmd.EndHeight
+	}
+	if cmd.StartHeight < 0 || cmd.StartHeight > best.Height {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCOutOfRange,
+			Message: fmt.Sprintf("Start height %d is out of range "+
+				"[0, %d]", cmd.StartHeight, best.Height),
+		}
+	}
+	if endHeight < cmd.StartHeight {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "The end height may not be below the start height",
+		}
+	}
+	verbose := cmd.Verbose != nil && *cmd.Verbose
+
+	result := &btcjson.StreamBlockHeadersResult{Height: cmd.StartHeight - 1}
+	done := make(chan bool, 1)
+	for height := cmd.StartHeight; height <= endHeight; {
+		batchEnd := height + maxBlockHeadersPerRequest
+		if batchEnd > endHeight+1 {
+			batchEnd = endHeight + 1
+		}
+		headers, err := chain.HeadersByHeight(height, batchEnd)
+		if err != nil {
+			context := "Failed to fetch block headers"
+			return nil, internalRPCError(err.Error(), context)
+		}
+		if len(headers) == 0 {
+			// The chain was reorganized to a lower height since
+			// the stream started.
+			break
+		}
+		headersResult, err := wsc.server.blockHeadersResult(height,
+			headers, verbose, chain.BestSnapshot().Height)
+		if err != nil {
+			return nil, err
+		}
+		marshalledJSON, err := btcjson.MarshalCmd(nil,
+			btcjson.NewBlockHeadersNtfn(*headersResult))
+		if err != nil {
+			context := "Failed to marshal blockheaders notification"
+			return nil, internalRPCError(err.Error(), context)
+		}
+
+		// Each batch is sent as a message rather than queued as a
+		// notification, and the next one is only fetched once it is
+		// written, so a slow client throttles the stream instead of
+		// growing the notification queue, and every batch precedes the
+		// reply.
+		wsc.SendMessage(marshalledJSON, done)
+		select {
+		case sent := <-done:
+			if !sent {
+				return nil, ErrClientQuit
+			}
+		case <-wsc.quit:
+			return nil, ErrClientQuit
+		}
+
+		last := headers[len(headers)-1].BlockHash()
+		result.Hash = last.String()
+		result.Height = height + int32(len(headers)) - 1
+		result.Count += int32(len(headers))
+		height += int32(len(headers))
+	}
+	return result, nil
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/pkt-cash/pktd/btcjson"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// TestGetBlockHeaders ensures getblockheaders validates its range and returns
+// the requested main chain headers in order, serialized or verbose.
+func TestGetBlockHeaders(t *testing.T) {
+	tc, teardown := newTestChain(t, "blockheaders", nil)
+	defer teardown()
+	params, chain, g := tc.params, tc.chain, tc.gen
+
+	s := &rpcServer{cfg: rpcserverConfig{Chain: chain, ChainParams: params}}
+	generated, err := g.generate(params.GenesisHash, 4, nil)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	hashes := []string{params.GenesisHash.String()}
+	for _, hash := range generated {
+		hashes = append(hashes, hash.String())
+	}
+
+	getBlockHeaders := func(start int32, count *int32,
+		verbose bool) (*btcjson.BlockHeadersResult, error) {
+
+		cmd := btcjson.NewGetBlockHeadersCmd(start, count, &verbose)
+		result, err := handleGetBlockHeaders(s, cmd, nil)
+		if err != nil {
+			return nil, err
+		}
+		return result.(*btcjson.BlockHeadersResult), nil
+	}
+
+	// Out of range requests are rejected.
+	for _, test := range []struct {
+		start int32
+		count int32
+	}{
+		{-1, 1},
+		{5, 1},
+		{0, 0},
+		{0, maxBlockHeadersPerRequest + 1},
+	} {
+		count := test.count
+		if _, err := getBlockHeaders(test.start, &count, false); err == nil {
+			t.Errorf("getblockheaders %d %d: unexpected success",
+				test.start, test.count)
+		}
+	}
+
+	// The serialized headers are returned up to the best block.
+	result, err := getBlockHeaders(1, nil, false)
+	if err != nil {
+		t.Fatalf("getblockheaders: %v", err)
+	}
+	if result.StartHeight != 1 || result.Count != 4 || result.Headers != nil {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	serialized, err := hex.DecodeString(result.Hex)
+	if err != nil {
+		t.Fatalf("DecodeString: %v", err)
+	}
+	if len(serialized) != 4*wire.MaxBlockHeaderPayload {
+		t.Fatalf("got %d bytes of headers, want %d", len(serialized),
+			4*wire.MaxBlockHeaderPayload)
+	}
+	r := bytes.NewReader(serialized)
+	for i := 1; i < len(hashes); i++ {
+		var header wire.BlockHeader
+		if err := header.Deserialize(r); err != nil {
+			t.Fatalf("Deserialize: %v", err)
+		}
+		if hash := header.BlockHash(); hash.String() != hashes[i] {
+			t.Fatalf("header %d has hash %v, want %v", i, hash,
+				hashes[i])
+		}
+	}
+
+	// Each verbose header links to the next block, including the last
+	// header of the range, except for the best block.
+	count := int32(2)
+	result, err = getBlockHeaders(0, &count, true)
+	if err != nil {
+		t.Fatalf("getblockheaders: %v", err)
+	}
+	if result.Count != 2 || len(result.Headers) != 2 || result.Hex != "" {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	for i, header := range result.Headers {
+		if header.Hash != hashes[i] || header.Height != int32(i) ||
+			header.NextHash != hashes[i+1] ||
+			header.Confirmations != int64(len(hashes)-i) {
+
+			t.Fatalf("unexpected header %d %+v", i, header)
+		}
+	}
+	result, err = getBlockHeaders(4, nil, true)
+	if err != nil {
+		t.Fatalf("getblockheaders: %v", err)
+	}
+	if len(result.Headers) != 1 || result.Headers[0].Hash != hashes[4] ||
+		result.Headers[0].NextHash != "" ||
+		result.Headers[0].PreviousHash != hashes[3] {
+
+		t.Fatalf("unexpected result %+v", result)
+	}
+}
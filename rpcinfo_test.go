@@ -0,0 +1,49 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/pkt-cash/pktd/btcjson"
+)
+
+// TestGetRPCInfo ensures getrpcinfo reports the commands being executed,
+// oldest first, including itself, and that finished commands are dropped.
+func TestGetRPCInfo(t *testing.T) {
+	defer func(c *config) { cfg = c }(cfg)
+	cfg = &config{LogDir: "logs"}
+
+	s := &rpcServer{}
+	id := s.startCmd("getblock")
+
+	cmd := &parsedRPCCmd{
+		method: "getrpcinfo",
+		cmd:    btcjson.NewGetRPCInfoCmd(),
+	}
+	result, err := s.standardCmdResult(cmd, nil)
+	if err != nil {
+		t.Fatalf("getrpcinfo: %v", err)
+	}
+	info := result.(*btcjson.GetRPCInfoResult)
+	if len(info.ActiveCommands) != 2 ||
+		info.ActiveCommands[0].Method != "getblock" ||
+		info.ActiveCommands[1].Method != "getrpcinfo" {
+
+		t.Fatalf("unexpected active commands %+v", info.ActiveCommands)
+	}
+	if info.ActiveCommands[0].Duration < info.ActiveCommands[1].Duration {
+		t.Errorf("unexpected durations %+v", info.ActiveCommands)
+	}
+	if want := filepath.Join("logs", defaultLogFilename); info.LogPath != want {
+		t.Errorf("unexpected log path %q, want %q", info.LogPath, want)
+	}
+
+	s.finishCmd(id)
+	if cmds := s.activeCommands(); len(cmds) != 0 {
+		t.Errorf("unexpected active commands %+v", cmds)
+	}
+}
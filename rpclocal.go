@@ -0,0 +1,107 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net"
+)
+
+// defaultRPCLocalMode is the default permissions of the RPC Unix domain
+// sockets, which only let the user running pktd connect.
+const defaultRPCLocalMode = 0600
+
+// rpcLocalAddr is the address of an RPC listener on a Unix domain socket or a
+// Windows named pipe.  The permissions of the socket authenticate the
+// connections it accepts, as the admin user or as the limited user.
+type rpcLocalAddr struct {
+	name  string
+	admin bool
+}
+
+// Network returns the network of the address.  It is part of the net.Addr
+// interface.
+func (a *rpcLocalAddr) Network() string {
+	return localNetwork
+}
+
+// String returns the path of the socket or the name of the pipe.  It is part
+// of the net.Addr interface.
+func (a *rpcLocalAddr) String() string {
+	return a.name
+}
+
+// rpcLocalListener accepts the RPC connections of a Unix domain socket or a
+// Windows named pipe, which report the address of the listener as their
+// local address so the RPC server can tell they are authenticated.
+type rpcLocalListener struct {
+	net.Listener
+	addr *rpcLocalAddr
+}
+
+// Accept waits for and returns the next connection.  It is part of the
+// net.Listener interface.
+func (l *rpcLocalListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &rpcLocalConn{Conn: conn, addr: l.addr}, nil
+}
+
+// Addr returns the address of the listener.  It is part of the net.Listener
+// interface.
+func (l *rpcLocalListener) Addr() net.Addr {
+	return l.addr
+}
+
+// rpcLocalConn is a connection accepted by an rpcLocalListener.
+type rpcLocalConn struct {
+	net.Conn
+	addr *rpcLocalAddr
+}
+
+// LocalAddr returns the address of the listener which accepted the connection.
+// It is part of the net.Conn interface.
+func (c *rpcLocalConn) LocalAddr() net.Addr {
+	return c.addr
+}
+
+// RemoteAddr returns the address of the listener which accepted the
+// connection too, since the clients of local sockets have no address.  It is
+// part of the net.Conn interface.
+func (c *rpcLocalConn) RemoteAddr() net.Addr {
+	return c.addr
+}
+
+// setupRPCLocalListeners returns the listeners of the configured Unix domain
+// sockets, or named pipes on Windows, whose connections are authenticated as
+// the admin user or the limited user.
+func setupRPCLocalListeners() ([]net.Listener, error) {
+	var listeners []net.Listener
+	add := func(names []string, admin bool) error {
+		for _, name := range names {
+			l, err := listenLocal(name, cfg.RPCLocalMode)
+			if err != nil {
+				return err
+			}
+			listeners = append(listeners, &rpcLocalListener{
+				Listener: l,
+				addr:     &rpcLocalAddr{name: name, admin: admin},
+			})
+		}
+		return nil
+	}
+	err := add(cfg.RPCLocal, true)
+	if err == nil {
+		err = add(cfg.RPCLimitLocal, false)
+	}
+	if err != nil {
+		for _, l := range listeners {
+			l.Close()
+		}
+		return nil, err
+	}
+	return listeners, nil
+}
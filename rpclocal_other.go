@@ -0,0 +1,46 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// +build !windows
+
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+)
+
+// localNetwork is the network of the RPC listeners authenticated by the
+// permissions of their socket.
+const localNetwork = "unix"
+
+// listenLocal listens on the Unix domain socket with the passed path, setting
+// its permissions to the passed mode.  A socket left behind by a previous run
+// is replaced, unless a process still listens on it.
+func listenLocal(path string, mode uint32) (net.Listener, error) {
+	if fi, err := os.Lstat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("RPC socket %s is already in use",
+				path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+
+	// The socket is created with the permissions already restricted to
+	// the passed mode, since every connection to it is trusted and setting
+	// them afterwards would leave a window during which anyone could
+	// connect.
+	oldMask := syscall.Umask(int(^mode & 0777))
+	l, err := net.Listen("unix", path)
+	syscall.Umask(oldMask)
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
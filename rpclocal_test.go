@@ -0,0 +1,113 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// +build !windows
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+// TestRPCLocalListeners ensures the connections to the RPC Unix domain sockets
+// are authenticated as the user of their socket, and that the sockets get the
+// configured permissions.
+func TestRPCLocalListeners(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "pktdrpclocal")
+	if err != nil {
+		t.Fatalf("Failed creating a temporary directory: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	adminPath := filepath.Join(tmpDir, "admin.sock")
+	limitedPath := filepath.Join(tmpDir, "limited.sock")
+
+	// A socket left behind by a previous run is replaced.
+	stale, err := net.Listen("unix", adminPath)
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	oldMask := syscall.Umask(022)
+	defer syscall.Umask(oldMask)
+	oldCfg := cfg
+	defer func() { cfg = oldCfg }()
+	cfg = &config{
+		RPCLocal:      []string{adminPath},
+		RPCLimitLocal: []string{limitedPath},
+		RPCLocalMode:  0660,
+	}
+	listeners, err := setupRPCLocalListeners()
+	if err != nil {
+		t.Fatalf("setupRPCLocalListeners: %v", err)
+	}
+	for _, l := range listeners {
+		defer l.Close()
+	}
+	fi, err := os.Stat(adminPath)
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if perm := fi.Mode().Perm(); perm != 0660 {
+		t.Fatalf("got socket permissions %o, want 660", perm)
+	}
+	if mask := syscall.Umask(022); mask != 022 {
+		t.Fatalf("got umask %o after creating the sockets, want 22",
+			mask)
+	}
+
+	// A socket in use is not replaced.
+	if _, err := listenLocal(adminPath, 0600); err == nil {
+		t.Fatal("listenLocal replaced a socket in use")
+	}
+
+	// Serve the result of the authentication of the requests.
+	s := &rpcServer{}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authenticated, isAdmin, _, err := s.checkAuth(r, true)
+		fmt.Fprintf(w, "%v %v %v", authenticated, isAdmin, err)
+	})
+	for _, l := range listeners {
+		go http.Serve(l, handler)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{adminPath, "true true <nil>"},
+		{limitedPath, "true false <nil>"},
+	}
+	for _, test := range tests {
+		client := &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var d net.Dialer
+					return d.DialContext(ctx, "unix", test.path)
+				},
+			},
+		}
+		resp, err := client.Get("http://pktd/")
+		if err != nil {
+			t.Fatalf("%s: Get: %v", test.path, err)
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatalf("%s: ReadAll: %v", test.path, err)
+		}
+		if string(body) != test.want {
+			t.Errorf("%s: got %q, want %q", test.path, body, test.want)
+		}
+	}
+}
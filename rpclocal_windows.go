@@ -0,0 +1,224 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+// localNetwork is the network of the RPC listeners authenticated by the
+// permissions of their named pipe.
+const localNetwork = "pipe"
+
+// pipeBufferSize is the size of the input and output buffers of the named
+// pipes.
+const pipeBufferSize = 4096
+
+// errPipeClosed is returned when using a closed named pipe listener or
+// connection.
+var errPipeClosed = errors.New("use of closed named pipe")
+
+// waitOverlapped starts an overlapped operation on the passed handle and waits
+// for it to complete, returning the number of bytes transferred.  Overlapped
+// operations let a connection be read from and written to concurrently, and be
+// cancelled when it is closed.
+func waitOverlapped(handle windows.Handle,
+	op func(*windows.Overlapped) error) (uint32, error) {
+
+	event, err := windows.CreateEvent(nil, 1, 0, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer windows.CloseHandle(event)
+
+	overlapped := windows.Overlapped{HEvent: event}
+	err = op(&overlapped)
+	if err != nil && err != windows.ERROR_IO_PENDING {
+		return 0, err
+	}
+	var n uint32
+	err = windows.GetOverlappedResult(handle, &overlapped, &n, true)
+	return n, err
+}
+
+// pipeListener accepts the connections to a Windows named pipe.  The pipe has
+// the default security descriptor, which only lets the user running pktd,
+// the administrators and the system open it for writing, and remote clients
+// are rejected.
+type pipeListener struct {
+	name string
+
+	mtx    sync.Mutex
+	handle windows.Handle
+	closed bool
+}
+
+// createPipe creates an instance of the named pipe with the passed name.
+func createPipe(name string, first bool) (windows.Handle, error) {
+	path, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return windows.InvalidHandle, err
+	}
+	flags := uint32(windows.PIPE_ACCESS_DUPLEX | windows.FILE_FLAG_OVERLAPPED)
+	if first {
+		flags |= windows.FILE_FLAG_FIRST_PIPE_INSTANCE
+	}
+	return windows.CreateNamedPipe(path, flags, windows.PIPE_TYPE_BYTE|
+		windows.PIPE_READMODE_BYTE|windows.PIPE_WAIT|
+		windows.PIPE_REJECT_REMOTE_CLIENTS,
+		windows.PIPE_UNLIMITED_INSTANCES, pipeBufferSize,
+		pipeBufferSize, 0, nil)
+}
+
+// listenLocal listens on the named pipe with the passed name, such as
+// \\.\pipe\pktd.  The mode only applies to Unix domain sockets and is ignored.
+func listenLocal(name string, mode uint32) (net.Listener, error) {
+	handle, err := createPipe(name, true)
+	if err != nil {
+		return nil, &os.PathError{Op: "listen", Path: name, Err: err}
+	}
+	return &pipeListener{name: name, handle: handle}, nil
+}
+
+// Accept waits for a client to open the pipe and returns the connection.  It
+// is part of the net.Listener interface.
+func (l *pipeListener) Accept() (net.Conn, error) {
+	l.mtx.Lock()
+	handle, closed := l.handle, l.closed
+	l.mtx.Unlock()
+	if closed {
+		return nil, errPipeClosed
+	}
+
+	_, err := waitOverlapped(handle, func(o *windows.Overlapped) error {
+		err := windows.ConnectNamedPipe(handle, o)
+		if err == windows.ERROR_PIPE_CONNECTED {
+			// The client connected before the wait, so signal
+			// the completion.
+			err = windows.SetEvent(o.HEvent)
+		}
+		return err
+	})
+
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if l.closed {
+		windows.CloseHandle(handle)
+		return nil, errPipeClosed
+	}
+	if err != nil {
+		return nil, &os.PathError{Op: "accept", Path: l.name, Err: err}
+	}
+
+	// Create the instance the next client connects to.
+	next, err := createPipe(l.name, false)
+	if err != nil {
+		windows.CloseHandle(handle)
+		return nil, &os.PathError{Op: "accept", Path: l.name, Err: err}
+	}
+	l.handle = next
+	return &pipeConn{handle: handle}, nil
+}
+
+// Close stops listening on the pipe, cancelling a pending Accept, which closes
+// the instance of the pipe.  It is part of the net.Listener interface.
+func (l *pipeListener) Close() error {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if l.closed {
+		return errPipeClosed
+	}
+	l.closed = true
+	windows.CancelIoEx(l.handle, nil)
+	return nil
+}
+
+// Addr returns the address of the listener.  It is part of the net.Listener
+// interface.
+func (l *pipeListener) Addr() net.Addr {
+	return &rpcLocalAddr{name: l.name}
+}
+
+// pipeConn is a connection to a named pipe.  The local and remote addresses
+// are provided by the rpcLocalConn wrapping it, and deadlines are not
+// supported.
+type pipeConn struct {
+	handle windows.Handle
+
+	mtx    sync.Mutex
+	closed bool
+}
+
+// Read reads from the pipe.  It is part of the net.Conn interface.
+func (c *pipeConn) Read(b []byte) (int, error) {
+	n, err := waitOverlapped(c.handle, func(o *windows.Overlapped) error {
+		return windows.ReadFile(c.handle, b, nil, o)
+	})
+	switch {
+	case err == windows.ERROR_BROKEN_PIPE || err == windows.ERROR_NO_DATA:
+		return 0, io.EOF
+	case err == windows.ERROR_OPERATION_ABORTED:
+		return 0, errPipeClosed
+	case err != nil:
+		return 0, err
+	}
+	return int(n), nil
+}
+
+// Write writes to the pipe.  It is part of the net.Conn interface.
+func (c *pipeConn) Write(b []byte) (int, error) {
+	n, err := waitOverlapped(c.handle, func(o *windows.Overlapped) error {
+		return windows.WriteFile(c.handle, b, nil, o)
+	})
+	if err == windows.ERROR_OPERATION_ABORTED {
+		err = errPipeClosed
+	}
+	return int(n), err
+}
+
+// Close cancels the pending reads and writes and closes the instance of the
+// pipe, which disconnects the client.  It is part of the net.Conn interface.
+func (c *pipeConn) Close() error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.closed {
+		return errPipeClosed
+	}
+	c.closed = true
+	windows.CancelIoEx(c.handle, nil)
+	return windows.CloseHandle(c.handle)
+}
+
+// LocalAddr is part of the net.Conn interface.
+func (c *pipeConn) LocalAddr() net.Addr {
+	return nil
+}
+
+// RemoteAddr is part of the net.Conn interface.
+func (c *pipeConn) RemoteAddr() net.Addr {
+	return nil
+}
+
+// SetDeadline is part of the net.Conn interface.
+func (c *pipeConn) SetDeadline(t time.Time) error {
+	return nil
+}
+
+// SetReadDeadline is part of the net.Conn interface.
+func (c *pipeConn) SetReadDeadline(t time.Time) error {
+	return nil
+}
+
+// SetWriteDeadline is part of the net.Conn interface.
+func (c *pipeConn) SetWriteDeadline(t time.Time) error {
+	return nil
+}
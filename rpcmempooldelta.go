@@ -0,0 +1,42 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"github.com/pkt-cash/pktd/btcjson"
+	"github.com/pkt-cash/pktd/mempool"
+)
+
+// handleGetMempoolDelta implements the getmempooldelta command.
+func handleGetMempoolDelta(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetMempoolDeltaCmd)
+
+	var since uint64
+	if c.Sequence != nil {
+		since = *c.Sequence
+	}
+	return mempoolDeltaResult(s.cfg.TxMemPool.Delta(since)), nil
+}
+
+// mempoolDeltaResult returns the result of getmempooldelta for the passed
+// changes of the mempool.
+func mempoolDeltaResult(delta *mempool.TxPoolDelta) *btcjson.GetMempoolDeltaResult {
+	result := &btcjson.GetMempoolDeltaResult{
+		Sequence: delta.Sequence,
+		Reset:    delta.Reset,
+		Added:    make([]string, len(delta.Added)),
+		Removed:  make([]btcjson.MempoolRemovedResult, len(delta.Removed)),
+	}
+	for i := range delta.Added {
+		result.Added[i] = delta.Added[i].String()
+	}
+	for i := range delta.Removed {
+		result.Removed[i] = btcjson.MempoolRemovedResult{
+			TxID:   delta.Removed[i].Hash.String(),
+			Reason: delta.Removed[i].Reason.String(),
+		}
+	}
+	return result
+}
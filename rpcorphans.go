@@ -0,0 +1,147 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"sort"
+
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/btcjson"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/mempool"
+	"github.com/pkt-cash/pktd/netsync"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// handleGetOrphanBlocks implements the getorphanblocks command.
+func handleGetOrphanBlocks(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	return staleBlockResults(s.cfg.Chain.StaleBlocks())
+}
+
+// staleBlockResults returns the result of getorphanblocks for the passed
+// blocks.
+func staleBlockResults(blocks []blockchain.StaleBlock) ([]btcjson.OrphanBlockResult, error) {
+	results := make([]btcjson.OrphanBlockResult, len(blocks))
+	for i := range blocks {
+		b := &blocks[i]
+		var buf bytes.Buffer
+		buf.Grow(wire.MaxBlockHeaderPayload)
+		if err := b.Header.Serialize(&buf); err != nil {
+			context := "Failed to serialize block header"
+			return nil, internalRPCError(err.Error(), context)
+		}
+
+		children := make([]string, len(b.Children))
+		for j := range b.Children {
+			children[j] = b.Children[j].String()
+		}
+		results[i] = btcjson.OrphanBlockResult{
+			Hash:              b.Hash.String(),
+			Height:            b.Height,
+			Header:            hex.EncodeToString(buf.Bytes()),
+			PreviousBlockHash: b.Header.PrevBlock.String(),
+			Time:              b.Header.Timestamp.Unix(),
+			Seen:              b.Seen.Unix(),
+			Reason:            b.Reason.String(),
+			Status:            b.Status.String(),
+			ForkHeight:        b.ForkHeight,
+			Children:          children,
+		}
+		if b.ForkHeight >= 0 {
+			results[i].ForkHash = b.ForkHash.String()
+		}
+		if b.MainChainHash != (chainhash.Hash{}) {
+			results[i].MainChainHash = b.MainChainHash.String()
+		}
+	}
+	return results, nil
+}
+
+// handleGetOrphanTxs implements the getorphantxs command.
+func handleGetOrphanTxs(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetOrphanTxsCmd)
+
+	verbosity := 0
+	if c.Verbosity != nil {
+		verbosity = *c.Verbosity
+	}
+	if verbosity < 0 || verbosity > 2 {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Verbosity must be 0, 1 or 2",
+		}
+	}
+
+	return orphanTxResults(s.cfg.TxMemPool.OrphanTxDescs(), verbosity)
+}
+
+// orphanTxResults returns the result of getorphantxs for the passed orphans
+// with the passed verbosity, ordered by expiration so the orphans to be removed
+// first come first.
+func orphanTxResults(descs []*mempool.OrphanTxDesc, verbosity int) (interface{}, error) {
+	sort.Slice(descs, func(i, j int) bool {
+		return descs[i].Expiration.Before(descs[j].Expiration)
+	})
+
+	if verbosity == 0 {
+		hashes := make([]string, len(descs))
+		for i, desc := range descs {
+			hashes[i] = desc.Tx.Hash().String()
+		}
+		return hashes, nil
+	}
+
+	results := make([]btcjson.OrphanTxResult, len(descs))
+	for i, desc := range descs {
+		mtx := desc.Tx.MsgTx()
+		results[i] = btcjson.OrphanTxResult{
+			TxID:       desc.Tx.Hash().String(),
+			WTxID:      mtx.WitnessHash().String(),
+			Bytes:      int64(mtx.SerializeSize()),
+			VSize:      mempool.GetTxVirtualSize(desc.Tx),
+			Weight:     blockchain.GetTransactionWeight(desc.Tx),
+			From:       uint64(desc.Tag),
+			Expiration: desc.Expiration.Unix(),
+		}
+		if verbosity < 2 {
+			continue
+		}
+		var buf bytes.Buffer
+		buf.Grow(mtx.SerializeSize())
+		if err := mtx.Serialize(&buf); err != nil {
+			context := "Failed to serialize transaction"
+			return nil, internalRPCError(err.Error(), context)
+		}
+		results[i].Hex = hex.EncodeToString(buf.Bytes())
+	}
+	return results, nil
+}
+
+// handleGetRejectedTxs implements the getrejectedtxs command.
+func handleGetRejectedTxs(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	return rejectedTxResults(s.cfg.SyncMgr.RejectedTxns()), nil
+}
+
+// rejectedTxResults returns the result of getrejectedtxs for the passed
+// rejected transactions.
+func rejectedTxResults(rejects []netsync.RejectedTx) []btcjson.RejectedTxResult {
+	results := make([]btcjson.RejectedTxResult, len(rejects))
+	for i := range rejects {
+		r := &rejects[i]
+		results[i] = btcjson.RejectedTxResult{
+			TxID:     r.Hash.String(),
+			PeerID:   r.PeerID,
+			Peer:     r.PeerAddr,
+			Time:     r.Time.Unix(),
+			Height:   r.Height,
+			Code:     r.Code.String(),
+			Reason:   r.Reason,
+			Filtered: r.Filtered,
+		}
+	}
+	return results
+}
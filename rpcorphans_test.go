@@ -0,0 +1,206 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/btcjson"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/mempool"
+	"github.com/pkt-cash/pktd/netsync"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// TestOrphanTxResults ensures the orphans are returned by expiration and
+// described according to the verbosity.
+func TestOrphanTxResults(t *testing.T) {
+	newOrphan := func(value int64, tag mempool.Tag, expiration int64) *mempool.OrphanTxDesc {
+		tx := wire.NewMsgTx(wire.TxVersion)
+		tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Index: 1}, nil, nil))
+		tx.AddTxOut(wire.NewTxOut(value, nil))
+		return &mempool.OrphanTxDesc{
+			Tx:         btcutil.NewTx(tx),
+			Tag:        tag,
+			Expiration: time.Unix(expiration, 0),
+		}
+	}
+	late := newOrphan(1, 7, 2000)
+	early := newOrphan(2, 0, 1000)
+
+	res, err := orphanTxResults([]*mempool.OrphanTxDesc{late, early}, 0)
+	if err != nil {
+		t.Fatalf("orphanTxResults: %v", err)
+	}
+	hashes := res.([]string)
+	if len(hashes) != 2 || hashes[0] != early.Tx.Hash().String() ||
+		hashes[1] != late.Tx.Hash().String() {
+
+		t.Fatalf("orphanTxResults: got hashes %v", hashes)
+	}
+
+	for verbosity := 1; verbosity <= 2; verbosity++ {
+		res, err := orphanTxResults([]*mempool.OrphanTxDesc{late, early},
+			verbosity)
+		if err != nil {
+			t.Fatalf("orphanTxResults: %v", err)
+		}
+		results := res.([]btcjson.OrphanTxResult)
+		if len(results) != 2 {
+			t.Fatalf("orphanTxResults: got %d results, want 2",
+				len(results))
+		}
+		r := results[1]
+		size := int64(late.Tx.MsgTx().SerializeSize())
+		if r.TxID != late.Tx.Hash().String() || r.From != 7 ||
+			r.Expiration != 2000 || r.Bytes != size ||
+			r.VSize != size || r.Weight != 4*size {
+
+			t.Fatalf("orphanTxResults: unexpected result %+v", r)
+		}
+		if (r.Hex != "") != (verbosity == 2) {
+			t.Fatalf("orphanTxResults: verbosity %d has hex %q",
+				verbosity, r.Hex)
+		}
+	}
+}
+
+// TestRejectedTxResults ensures the rejected transactions are described with
+// their reject codes.
+func TestRejectedTxResults(t *testing.T) {
+	rejects := []netsync.RejectedTx{{
+		Hash:     chainhash.Hash{1},
+		PeerID:   3,
+		PeerAddr: "10.0.0.1:64764",
+		Time:     time.Unix(1000, 0),
+		Height:   42,
+		Code:     wire.RejectInsufficientFee,
+		Reason:   "insufficient fee",
+		Filtered: true,
+	}}
+	results := rejectedTxResults(rejects)
+	want := btcjson.RejectedTxResult{
+		TxID:     rejects[0].Hash.String(),
+		PeerID:   3,
+		Peer:     "10.0.0.1:64764",
+		Time:     1000,
+		Height:   42,
+		Code:     "REJECT_INSUFFICIENTFEE",
+		Reason:   "insufficient fee",
+		Filtered: true,
+	}
+	if len(results) != 1 || results[0] != want {
+		t.Fatalf("rejectedTxResults: got %+v, want %+v", results, want)
+	}
+}
+
+// TestGetOrphanBlocks ensures getorphanblocks reports the orphans, the blocks
+// of side chains and the blocks disconnected by reorganizations along with
+// their fork points and children.
+func TestGetOrphanBlocks(t *testing.T) {
+	tc, teardown := newTestChain(t, "orphanblocks", nil)
+	defer teardown()
+	params, chain, g := tc.params, tc.chain, tc.gen
+	s := &rpcServer{cfg: rpcserverConfig{Chain: chain}}
+	getOrphanBlocks := func() []btcjson.OrphanBlockResult {
+		result, err := handleGetOrphanBlocks(s,
+			btcjson.NewGetOrphanBlocksCmd(), nil)
+		if err != nil {
+			t.Fatalf("getorphanblocks: %v", err)
+		}
+		return result.([]btcjson.OrphanBlockResult)
+	}
+
+	hashes, err := g.generate(params.GenesisHash, 3, nil)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if blocks := getOrphanBlocks(); len(blocks) != 0 {
+		t.Fatalf("unexpected blocks %+v", blocks)
+	}
+
+	// A shorter fork stays on a side chain.
+	side, err := g.generate(hashes[0], 1, nil)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+
+	// An orphan at height 4 and a child of it, whose parents are unknown.
+	submitOrphan := func(msgBlock *wire.MsgBlock, prevHash *chainhash.Hash) *btcutil.Block {
+		msgBlock.Header.PrevBlock = *prevHash
+		if !solveHeader(&msgBlock.Header) {
+			t.Fatalf("unable to solve block")
+		}
+		block := btcutil.NewBlock(msgBlock)
+		_, isOrphan, err := chain.ProcessBlock(block, blockchain.BFNone)
+		if err != nil || !isOrphan {
+			t.Fatalf("ProcessBlock: orphan %v, err %v", isOrphan, err)
+		}
+		return block
+	}
+	template, err := g.newBlock(hashes[2], 4, nil)
+	if err != nil {
+		t.Fatalf("newBlock: %v", err)
+	}
+	orphan := submitOrphan(template.MsgBlock(), &chainhash.Hash{1})
+	childBlock := *template.MsgBlock()
+	child := submitOrphan(&childBlock, orphan.Hash())
+
+	// The last main chain block is disconnected by a reorganization.
+	reorg, err := g.simulateReorg(1, 2, nil)
+	if err != nil {
+		t.Fatalf("simulateReorg: %v", err)
+	}
+	mainHash := func(height int32) string {
+		hash, err := chain.BlockHashByHeight(height)
+		if err != nil {
+			t.Fatalf("BlockHashByHeight: %v", err)
+		}
+		return hash.String()
+	}
+
+	blocks := getOrphanBlocks()
+	if len(blocks) != 4 {
+		t.Fatalf("got %d blocks, want 4: %+v", len(blocks), blocks)
+	}
+	check := func(b *btcjson.OrphanBlockResult, hash *chainhash.Hash,
+		height int32, reason, status string, forkHeight int32,
+		children ...*chainhash.Hash) {
+
+		mainChainHash := ""
+		if height >= 0 && height <= chain.BestSnapshot().Height {
+			mainChainHash = mainHash(height)
+		}
+		forkHash := ""
+		if forkHeight >= 0 {
+			forkHash = mainHash(forkHeight)
+		}
+		if b.Hash != hash.String() || b.Height != height ||
+			b.Reason != reason || b.Status != status ||
+			b.ForkHeight != forkHeight || b.ForkHash != forkHash ||
+			b.MainChainHash != mainChainHash ||
+			len(b.Children) != len(children) ||
+			len(b.Header) != 2*wire.MaxBlockHeaderPayload {
+
+			t.Fatalf("unexpected block %+v", b)
+		}
+		for i, child := range children {
+			if b.Children[i] != child.String() {
+				t.Fatalf("unexpected children %v", b.Children)
+			}
+		}
+	}
+	check(&blocks[0], hashes[2], 3, "reorganized", "sidechain", 2)
+	check(&blocks[1], child.Hash(), 4, "orphan", "orphan", -1)
+	check(&blocks[2], orphan.Hash(), 4, "orphan", "orphan", -1, child.Hash())
+	check(&blocks[3], side[0], 2, "sidechain", "sidechain", 1)
+	if blocks[0].MainChainHash != reorg.Connected[0] {
+		t.Fatalf("unexpected main chain block %s, want %s",
+			blocks[0].MainChainHash, reorg.Connected[0])
+	}
+}
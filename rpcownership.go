@@ -0,0 +1,167 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/btcjson"
+	"github.com/pkt-cash/pktd/reserveproof"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// ownershipOutputs returns the outputs spent by the passed proof, but for its
+// commitment, as they were at the passed height of the main chain ending with
+// the best block.  The outputs still unspent are taken from the utxo set and
+// the ones spent since the height from the spend journals of the blocks after
+// it.  The outputs spent at or below the height, or which never existed, are
+// not returned.  The outputs created after the height are, so they can be
+// told apart.
+func (s *rpcServer) ownershipOutputs(tx *wire.MsgTx, height int32,
+	best *blockchain.BestState,
+	closeChan <-chan struct{}) (map[wire.OutPoint]blockchain.UtxoDelta, error) {
+
+	outputs := make(map[wire.OutPoint]blockchain.UtxoDelta, len(tx.TxIn))
+	missing := make(map[wire.OutPoint]struct{})
+	for i, txIn := range tx.TxIn {
+		if i == 0 {
+			continue
+		}
+		op := txIn.PreviousOutPoint
+		entry, err := s.cfg.Chain.FetchUtxoEntry(op)
+		if err != nil {
+			context := "Failed to fetch utxo"
+			return nil, internalRPCError(err.Error(), context)
+		}
+		if entry == nil || entry.IsSpent() {
+			missing[op] = struct{}{}
+			continue
+		}
+		outputs[op] = blockchain.UtxoDelta{
+			OutPoint:   op,
+			Amount:     entry.Amount(),
+			PkScript:   entry.PkScript(),
+			Height:     entry.BlockHeight(),
+			IsCoinBase: entry.IsCoinBase(),
+		}
+	}
+
+	// Walk back the main chain down to the block after the height, looking
+	// for the blocks which spent the missing outputs.
+	hash := best.Hash
+	for blockHeight := best.Height; blockHeight > height &&
+		len(missing) > 0; blockHeight-- {
+
+		select {
+		case <-closeChan:
+			return nil, ErrClientQuit
+		default:
+		}
+
+		block, err := s.cfg.Chain.BlockByHash(&hash)
+		if err != nil {
+			context := "Failed to fetch block"
+			return nil, internalRPCError(err.Error(), context)
+		}
+		stxos, err := s.cfg.Chain.FetchSpendJournal(block)
+		if err != nil {
+			context := "Failed to fetch spend journal"
+			return nil, internalRPCError(err.Error(), context)
+		}
+		_, spent := blockchain.BlockUtxoDeltas(block, blockHeight, stxos)
+		for _, out := range spent {
+			if _, ok := missing[out.OutPoint]; ok {
+				outputs[out.OutPoint] = out
+				delete(missing, out.OutPoint)
+			}
+		}
+		hash = block.MsgBlock().Header.PrevBlock
+	}
+	return outputs, nil
+}
+
+// handleVerifyAddressOwnership implements the verifyaddressownership command.
+func handleVerifyAddressOwnership(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.VerifyAddressOwnershipCmd)
+
+	// Deserialize the proof transaction.
+	hexStr := c.Proof
+	if len(hexStr)%2 != 0 {
+		hexStr = "0" + hexStr
+	}
+	serializedTx, err := hex.DecodeString(hexStr)
+	if err != nil {
+		return nil, rpcDecodeHexError(hexStr)
+	}
+	var mtx wire.MsgTx
+	err = mtx.Deserialize(bytes.NewReader(serializedTx))
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCDeserialization,
+			Message: "Proof decode failed: " + err.Error(),
+		}
+	}
+
+	best := s.cfg.Chain.BestSnapshot()
+	height := best.Height
+	if c.Height != nil {
+		height = *c.Height
+	}
+	if height < 0 || height > best.Height {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Block height out of range",
+		}
+	}
+
+	// Only outputs which were unspent at the requested height, and so were
+	// already confirmed at it, may be proven.
+	outputs, err := s.ownershipOutputs(&mtx, height, best, closeChan)
+	if err != nil {
+		return nil, err
+	}
+	fetch := func(op wire.OutPoint) (*wire.TxOut, error) {
+		out, ok := outputs[op]
+		if !ok {
+			return nil, fmt.Errorf("output %v did not exist or had "+
+				"been spent at height %d", op, height)
+		}
+		if out.Height > height {
+			return nil, fmt.Errorf("output %v was not confirmed at "+
+				"height %d", op, height)
+		}
+		return wire.NewTxOut(out.Amount, out.PkScript), nil
+	}
+
+	result := &btcjson.VerifyAddressOwnershipResult{Height: height}
+	prevOuts, total, err := reserveproof.Verify(&mtx, c.Message, fetch)
+	if err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+	result.Valid = true
+	result.Amount = total.ToBTC()
+
+	// Ignore the error here since an error means the script couldn't parse
+	// and there is no additional information about it anyways.
+	seen := make(map[string]struct{})
+	for _, prevOut := range prevOuts {
+		_, addrs, _, _ := txscript.ExtractPkScriptAddrs(prevOut.PkScript,
+			s.cfg.ChainParams)
+		for _, addr := range addrs {
+			encoded := addr.EncodeAddress()
+			if _, ok := seen[encoded]; ok {
+				continue
+			}
+			seen[encoded] = struct{}{}
+			result.Addresses = append(result.Addresses, encoded)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,188 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/btcec"
+	"github.com/pkt-cash/pktd/btcjson"
+	"github.com/pkt-cash/pktd/reserveproof"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// TestVerifyAddressOwnership ensures the outputs of a proof are resolved as
+// of the requested height, including the ones spent since.
+func TestVerifyAddressOwnership(t *testing.T) {
+	const message = "reserves"
+
+	tc, teardown := newTestChain(t, "ownership", nil)
+	defer teardown()
+	params, chain, g := tc.params, tc.chain, tc.gen
+	s := &rpcServer{cfg: rpcserverConfig{Chain: chain, ChainParams: params}}
+
+	key, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatalf("unable to generate key: %v", err)
+	}
+	addr, err := btcutil.NewAddressPubKeyHash(
+		btcutil.Hash160(key.PubKey().SerializeCompressed()), params)
+	if err != nil {
+		t.Fatalf("NewAddressPubKeyHash: %v", err)
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatalf("PayToAddrScript: %v", err)
+	}
+
+	// Pay the subsidy of the first two blocks to the key, and mine enough
+	// blocks on top for the first one to mature.
+	hashes, err := g.generate(params.GenesisHash, 2, addr)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	more, err := g.generate(hashes[1], uint32(params.CoinbaseMaturity), nil)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	hashes = append(hashes, more...)
+
+	// coinbaseOutput returns the output of the coinbase of the passed block
+	// paying the key.
+	coinbaseOutput := func(index int) (wire.OutPoint, int64) {
+		block, err := chain.BlockByHash(hashes[index])
+		if err != nil {
+			t.Fatalf("BlockByHash: %v", err)
+		}
+		coinbase := block.Transactions()[0]
+		for i, txOut := range coinbase.MsgTx().TxOut {
+			if bytes.Equal(txOut.PkScript, pkScript) {
+				return wire.OutPoint{Hash: *coinbase.Hash(),
+					Index: uint32(i)}, txOut.Value
+			}
+		}
+		t.Fatalf("block %d does not pay the key", index+1)
+		return wire.OutPoint{}, 0
+	}
+	spentOut, spentValue := coinbaseOutput(0)
+	unspentOut, unspentValue := coinbaseOutput(1)
+
+	// Spend the first output in a new block.
+	spendHeight := int32(len(hashes)) + 1
+	block, err := g.newBlock(hashes[len(hashes)-1], spendHeight, nil)
+	if err != nil {
+		t.Fatalf("newBlock: %v", err)
+	}
+	spend := wire.NewMsgTx(wire.TxVersion)
+	spend.AddTxIn(wire.NewTxIn(&spentOut, nil, nil))
+	spend.AddTxOut(wire.NewTxOut(spentValue, []byte{txscript.OP_TRUE}))
+	spend.TxIn[0].SignatureScript, err = txscript.SignatureScript(spend, 0,
+		pkScript, txscript.SigHashAll, key, true)
+	if err != nil {
+		t.Fatalf("SignatureScript: %v", err)
+	}
+	msgBlock := block.MsgBlock()
+	msgBlock.AddTransaction(spend)
+	merkles := blockchain.BuildMerkleTreeStore(
+		btcutil.NewBlock(msgBlock).Transactions(), false)
+	msgBlock.Header.MerkleRoot = *merkles[len(merkles)-1]
+	if !solveHeader(&msgBlock.Header) {
+		t.Fatalf("unable to solve block")
+	}
+	if err := g.submit(btcutil.NewBlock(msgBlock)); err != nil {
+		t.Fatalf("spending block rejected: %v", err)
+	}
+
+	// proof returns the hex-encoded proof of the passed output.
+	proof := func(op wire.OutPoint, value int64) string {
+		tx := reserveproof.New(message, []wire.OutPoint{op},
+			btcutil.Amount(value))
+		tx.TxIn[1].SignatureScript, err = txscript.SignatureScript(tx, 1,
+			pkScript, txscript.SigHashAll, key, true)
+		if err != nil {
+			t.Fatalf("SignatureScript: %v", err)
+		}
+		var buf bytes.Buffer
+		if err := tx.Serialize(&buf); err != nil {
+			t.Fatalf("Serialize: %v", err)
+		}
+		return hex.EncodeToString(buf.Bytes())
+	}
+
+	tests := []struct {
+		name   string
+		proof  string
+		height *int32
+		amount int64
+		err    string
+	}{
+		{
+			name:   "unspent output at the best height",
+			proof:  proof(unspentOut, unspentValue),
+			amount: unspentValue,
+		},
+		{
+			name:   "unspent output before it was confirmed",
+			proof:  proof(unspentOut, unspentValue),
+			height: btcjson.Int32(1),
+			err:    "was not confirmed",
+		},
+		{
+			name:   "output spent after the height",
+			proof:  proof(spentOut, spentValue),
+			height: btcjson.Int32(spendHeight - 1),
+			amount: spentValue,
+		},
+		{
+			name:  "output spent at the best height",
+			proof: proof(spentOut, spentValue),
+			err:   "had been spent",
+		},
+		{
+			name:   "output spent after the height before it was confirmed",
+			proof:  proof(spentOut, spentValue),
+			height: btcjson.Int32(0),
+			err:    "was not confirmed",
+		},
+	}
+	for _, test := range tests {
+		cmd := btcjson.NewVerifyAddressOwnershipCmd(test.proof, message,
+			test.height)
+		reply, err := handleVerifyAddressOwnership(s, cmd, nil)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		result := reply.(*btcjson.VerifyAddressOwnershipResult)
+		if test.err != "" {
+			if result.Valid || !strings.Contains(result.Error, test.err) {
+				t.Errorf("%s: got valid %v with error %q, want "+
+					"error containing %q", test.name,
+					result.Valid, result.Error, test.err)
+			}
+			continue
+		}
+		if !result.Valid {
+			t.Errorf("%s: unexpected error %q", test.name,
+				result.Error)
+			continue
+		}
+		if result.Amount != btcutil.Amount(test.amount).ToBTC() ||
+			len(result.Addresses) != 1 ||
+			result.Addresses[0] != addr.EncodeAddress() {
+
+			t.Errorf("%s: got amount %v of %v, want %v of %v",
+				test.name, result.Amount, result.Addresses,
+				btcutil.Amount(test.amount).ToBTC(),
+				addr.EncodeAddress())
+		}
+	}
+}
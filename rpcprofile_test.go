@@ -0,0 +1,65 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/pkt-cash/pktd/btcjson"
+	"github.com/pkt-cash/pktd/txscript"
+)
+
+// TestProfileRPCs ensures the captureprofile, getmemoryinfo and triggergc
+// handlers return profiles and memory statistics and reject invalid profiles
+// and file names.
+func TestProfileRPCs(t *testing.T) {
+	s := &rpcServer{cfg: rpcserverConfig{SigCache: txscript.NewSigCache(10)}}
+
+	cmd := btcjson.NewCaptureProfileCmd("heap", nil, nil)
+	result, err := handleCaptureProfile(s, cmd, nil)
+	if err != nil {
+		t.Fatalf("captureprofile: %v", err)
+	}
+	profile := result.(*btcjson.CaptureProfileResult)
+	data, err := base64.StdEncoding.DecodeString(profile.Data)
+	if err != nil || len(data) == 0 || int64(len(data)) != profile.Bytes {
+		t.Fatalf("unexpected profile of %d bytes: %v", profile.Bytes, err)
+	}
+
+	invalid := []*btcjson.CaptureProfileCmd{
+		btcjson.NewCaptureProfileCmd("nosuchprofile", nil, nil),
+		btcjson.NewCaptureProfileCmd("cpu", btcjson.Int32(0), nil),
+		btcjson.NewCaptureProfileCmd("heap", nil, btcjson.String("../heap")),
+		btcjson.NewCaptureProfileCmd("heap", nil, btcjson.String("")),
+	}
+	for _, cmd := range invalid {
+		_, err := handleCaptureProfile(s, cmd, nil)
+		if rpcErr, ok := err.(*btcjson.RPCError); !ok ||
+			rpcErr.Code != btcjson.ErrRPCInvalidParameter {
+			t.Errorf("captureprofile %+v: unexpected error %v", cmd, err)
+		}
+	}
+
+	result, err = handleGetMemoryInfo(s, btcjson.NewGetMemoryInfoCmd(), nil)
+	if err != nil {
+		t.Fatalf("getmemoryinfo: %v", err)
+	}
+	info := result.(*btcjson.GetMemoryInfoResult)
+	if info.Runtime.HeapAlloc == 0 || info.Runtime.Goroutines == 0 {
+		t.Errorf("unexpected runtime statistics %+v", info.Runtime)
+	}
+	if len(info.Subsystems) != 1 || info.Subsystems[0].Name != "sigcache" {
+		t.Errorf("unexpected subsystems %+v", info.Subsystems)
+	}
+
+	result, err = handleTriggerGC(s, btcjson.NewTriggerGCCmd(nil), nil)
+	if err != nil {
+		t.Fatalf("triggergc: %v", err)
+	}
+	if result.(*btcjson.TriggerGCResult).HeapAllocAfter == 0 {
+		t.Errorf("unexpected garbage collection result %+v", result)
+	}
+}
@@ -17,11 +17,17 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math"
 	"math/big"
 	"math/rand"
 	"net"
 	"net/http"
 	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"runtime/pprof"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -30,11 +36,13 @@ import (
 
 	"github.com/btcsuite/websocket"
 	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/addrmgr"
 	"github.com/pkt-cash/pktd/blockchain"
 	"github.com/pkt-cash/pktd/blockchain/indexers"
 	"github.com/pkt-cash/pktd/blockchain/packetcrypt"
 	"github.com/pkt-cash/pktd/btcec"
 	"github.com/pkt-cash/pktd/btcjson"
+	"github.com/pkt-cash/pktd/btcutil/merkle"
 	"github.com/pkt-cash/pktd/chaincfg"
 	"github.com/pkt-cash/pktd/chaincfg/chainhash"
 	"github.com/pkt-cash/pktd/chaincfg/globalcfg"
@@ -42,6 +50,7 @@ import (
 	"github.com/pkt-cash/pktd/mempool"
 	"github.com/pkt-cash/pktd/mining"
 	"github.com/pkt-cash/pktd/mining/cpuminer"
+	"github.com/pkt-cash/pktd/netsync"
 	"github.com/pkt-cash/pktd/peer"
 	"github.com/pkt-cash/pktd/txscript"
 	"github.com/pkt-cash/pktd/wire"
@@ -76,8 +85,28 @@ const (
 	// in the memory pool.
 	gbtRegenerateSeconds = 60
 
+	// gbtTemplateHistorySize is the number of the most recent block
+	// templates whose transactions are remembered to reply to the
+	// getblocktemplatelight RPC with the differences from them.
+	gbtTemplateHistorySize = 16
+
 	// maxProtocolVersion is the max protocol version the server supports.
 	maxProtocolVersion = 70002
+
+	// maxProfileSeconds is the longest CPU profile the captureprofile RPC
+	// records.
+	maxProfileSeconds = 600
+
+	// profileDirName is the directory of the data directory which the
+	// captureprofile RPC writes profile files to.
+	profileDirName = "profiles"
+
+	// sigCacheEntryBytes and hashCacheEntryBytes are the estimated memory
+	// used by an entry of the signature cache, holding a signature and a
+	// public key, and of the hash cache, holding three hashes, including
+	// the map overhead.  They are reported by the getmemoryinfo RPC.
+	sigCacheEntryBytes  = 350
+	hashCacheEntryBytes = 160
 )
 
 var (
@@ -121,6 +150,13 @@ var (
 		Code:    btcjson.ErrRPCNoWallet,
 		Message: "This implementation does not implement wallet commands",
 	}
+
+	// ErrRPCReplica is an error returned to RPC clients when the provided
+	// command is not supported by a read-only replica.
+	ErrRPCReplica = &btcjson.RPCError{
+		Code:    btcjson.ErrRPCMisc,
+		Message: "Command not supported by a read-only replica, send it to the primary",
+	}
 )
 
 type commandHandler func(*rpcServer, interface{}, <-chan struct{}) (interface{}, error)
@@ -131,113 +167,217 @@ type commandHandler func(*rpcServer, interface{}, <-chan struct{}) (interface{},
 var rpcHandlers map[string]commandHandler
 var rpcHandlersBeforeInit = map[string]commandHandler{
 	"addnode":                handleAddNode,
+	"addpeeraddress":         handleAddPeerAddress,
+	"capturemessages":        handleCaptureMessages,
+	"captureprofile":         handleCaptureProfile,
 	"configureminingpayouts": handleConfigureMiningPayouts,
+	"createdepositaddresses": handleCreateDepositAddresses,
 	"createrawtransaction":   handleCreateRawTransaction,
 	"debuglevel":             handleDebugLevel,
 	"decoderawtransaction":   handleDecodeRawTransaction,
 	"decodescript":           handleDecodeScript,
 	"estimatefee":            handleEstimateFee,
 	"generate":               handleGenerate,
+	"generatefork":           handleGenerateFork,
+	"getauditlog":            handleGetAuditLog,
 	"getaddednodeinfo":       handleGetAddedNodeInfo,
+	"getaddrmaninfo":         handleGetAddrManInfo,
 	"getbestblock":           handleGetBestBlock,
 	"getbestblockhash":       handleGetBestBlockHash,
 	"getblock":               handleGetBlock,
 	"getblockchaininfo":      handleGetBlockChainInfo,
+	"getblockcost":           handleGetBlockCost,
+	"getannagingschedule":    handleGetAnnAgingSchedule,
+	"getannproof":            handleGetAnnProof,
 	"getblockcount":          handleGetBlockCount,
 	"getblockhash":           handleGetBlockHash,
 	"getblockheader":         handleGetBlockHeader,
+	"getblockheaders":        handleGetBlockHeaders,
 	"getblocktemplate":       handleGetBlockTemplate,
+	"getblockundo":           handleGetBlockUndo,
+	"getblocktemplatelight":  handleGetBlockTemplateLight,
 	"getcfilter":             handleGetCFilter,
 	"getcfilterheader":       handleGetCFilterHeader,
 	"getconnectioncount":     handleGetConnectionCount,
+	"getclockskew":           handleGetClockSkew,
+	"getcoldspends":          handleGetColdSpends,
+	"getconsensusrules":      handleGetConsensusRules,
 	"getcurrentnet":          handleGetCurrentNet,
+	"getdatacarrierinfo":     handleGetDataCarrierInfo,
 	"getdifficulty":          handleGetDifficulty,
+	"getdiskstatus":          handleGetDiskStatus,
+	"getdifficultyhistory":   handleGetDifficultyHistory,
 	"getgenerate":            handleGetGenerate,
 	"gethashespersec":        handleGetHashesPerSec,
+	"getfeehistory":          handleGetFeeHistory,
 	"getheaders":             handleGetHeaders,
 	"getinfo":                handleGetInfo,
+	"getmemoryinfo":          handleGetMemoryInfo,
+	"getmininganalytics":     handleGetMiningAnalytics,
+	"getpartitionstatus":     handleGetPartitionStatus,
+	"getmempooldelta":        handleGetMempoolDelta,
+	"getmempoolentry":        handleGetMempoolEntry,
 	"getmempoolinfo":         handleGetMempoolInfo,
 	"getmininginfo":          handleGetMiningInfo,
 	"getminingpayouts":       handleGetMiningPayouts,
 	"getnettotals":           handleGetNetTotals,
 	"getnetworkhashps":       handleGetNetworkHashPS,
+	"getnetworkhashrate":     handleGetNetworkHashrate,
+	"getnetworkinfo":         handleGetNetworkInfo,
 	"getnetworksteward":      handleGetNetworkSteward,
+	"getnodeaddresses":       handleGetNodeAddresses,
+	"getorphanblocks":        handleGetOrphanBlocks,
+	"getorphantxs":           handleGetOrphanTxs,
 	"getpeerinfo":            handleGetPeerInfo,
 	"getrawmempool":          handleGetRawMempool,
 	"getrawblocktemplate":    handleGetRawBlockTemplate,
 	"checkpcshare":           handleCheckPcShare,
 	"getrawtransaction":      handleGetRawTransaction,
+	"getrejectedtxs":         handleGetRejectedTxs,
+	"getsyncstatus":          handleGetSyncStatus,
+	"getrpcinfo":             handleGetRPCInfo,
+	"gettxfee":               handleGetTxFee,
 	"gettxout":               handleGetTxOut,
+	"gettxoutproof":          handleGetTxOutProof,
+	"getutxodeltas":          handleGetUtxoDeltas,
+	"getutxostats":           handleGetUtxoStats,
 	"help":                   handleHelp,
+	"listdeposits":           handleListDeposits,
+	"listeners":              handleListeners,
+	"minttoken":              handleMintToken,
 	"node":                   handleNode,
 	"ping":                   handlePing,
 	"searchrawtransactions":  handleSearchRawTransactions,
 	"sendrawtransaction":     handleSendRawTransaction,
+	"setblocklimits":         handleSetBlockLimits,
 	"setgenerate":            handleSetGenerate,
+	"simulatereorg":          handleSimulateReorg,
 	"stop":                   handleStop,
 	"submitblock":            handleSubmitBlock,
+	"triggergc":              handleTriggerGC,
 	"uptime":                 handleUptime,
 	"validateaddress":        handleValidateAddress,
+	"verifyaddressownership": handleVerifyAddressOwnership,
 	"verifychain":            handleVerifyChain,
 	"verifymessage":          handleVerifyMessage,
+	"verifytxoutproof":       handleVerifyTxOutProof,
 	"version":                handleVersion,
+	"waitforblockheight":     handleWaitForBlockHeight,
+	"waitfornewblock":        handleWaitForNewBlock,
 }
 
 // list of commands that we recognize, but for which pktd has no support because
 // it lacks support for wallet functionality. For these commands the user
 // should ask a connected instance of pktwallet.
 var rpcAskWallet = map[string]struct{}{
-	"addmultisigaddress":     {},
-	"addp2shscript":          {},
-	"backupwallet":           {},
-	"createencryptedwallet":  {},
-	"createmultisig":         {},
-	"dumpprivkey":            {},
-	"dumpwallet":             {},
-	"encryptwallet":          {},
-	"getaccount":             {},
-	"getaccountaddress":      {},
-	"getaddressesbyaccount":  {},
-	"getbalance":             {},
-	"getnewaddress":          {},
-	"getrawchangeaddress":    {},
-	"getreceivedbyaccount":   {},
-	"getreceivedbyaddress":   {},
-	"gettransaction":         {},
-	"gettxoutsetinfo":        {},
-	"getunconfirmedbalance":  {},
-	"getwalletinfo":          {},
-	"importprivkey":          {},
-	"importwallet":           {},
-	"keypoolrefill":          {},
-	"listaccounts":           {},
-	"listaddressgroupings":   {},
-	"listlockunspent":        {},
-	"listreceivedbyaccount":  {},
-	"listreceivedbyaddress":  {},
-	"listsinceblock":         {},
-	"listtransactions":       {},
-	"listunspent":            {},
-	"lockunspent":            {},
-	"move":                   {},
-	"sendfrom":               {},
-	"sendmany":               {},
-	"sendtoaddress":          {},
-	"setaccount":             {},
-	"settxfee":               {},
-	"signmessage":            {},
-	"signrawtransaction":     {},
-	"walletlock":             {},
-	"walletpassphrase":       {},
-	"walletpassphrasechange": {},
+	"addmultisigaddress":        {},
+	"addp2shscript":             {},
+	"approvependingsend":        {},
+	"backupwallet":              {},
+	"cancelpendingsend":         {},
+	"cancelscheduled":           {},
+	"createencryptedwallet":     {},
+	"createmultisig":            {},
+	"createpaymenturi":          {},
+	"createsigningpackage":      {},
+	"createtimelockaddress":     {},
+	"dumpprivkey":               {},
+	"dumpwallet":                {},
+	"encryptwallet":             {},
+	"enumeratesigners":          {},
+	"exportaccountxpub":         {},
+	"exporttxmemos":             {},
+	"fundrawtransaction":        {},
+	"getaccount":                {},
+	"getaccountaddress":         {},
+	"getaccountpolicy":          {},
+	"getaddressesbyaccount":     {},
+	"getaddressinfo":            {},
+	"getbalance":                {},
+	"getnewaddress":             {},
+	"getrawchangeaddress":       {},
+	"getreceivedbyaccount":      {},
+	"getreceivedbyaddress":      {},
+	"getpaymentreport":          {},
+	"getrecoveryinfo":           {},
+	"getsignerpolicy":           {},
+	"gettransaction":            {},
+	"gettxoutsetinfo":           {},
+	"getunconfirmedbalance":     {},
+	"getwalletinfo":             {},
+	"getwalletlockstate":        {},
+	"importaccountxpub":         {},
+	"importcorewallet":          {},
+	"importprivkey":             {},
+	"importsignatures":          {},
+	"importtxmemos":             {},
+	"importwallet":              {},
+	"keypoolrefill":             {},
+	"listaccounts":              {},
+	"listaddressgroupings":      {},
+	"listkeyorigins":            {},
+	"listpaymenttemplates":      {},
+	"listlockunspent":           {},
+	"listpendingsends":          {},
+	"listscheduled":             {},
+	"listreceivedbyaccount":     {},
+	"listreceivedbyaddress":     {},
+	"listsinceblock":            {},
+	"listtransactions":          {},
+	"listunspent":               {},
+	"lockunspent":               {},
+	"move":                      {},
+	"proveaddressownership":     {},
+	"removepaymenttemplate":     {},
+	"restorewallet":             {},
+	"schedulesend":              {},
+	"sendfrom":                  {},
+	"sendmany":                  {},
+	"sendmanybatch":             {},
+	"sendpayjoin":               {},
+	"sendtoaddress":             {},
+	"setaccount":                {},
+	"setaccountcredentials":     {},
+	"setaccountpolicy":          {},
+	"setpaymenttemplate":        {},
+	"setsignerpolicy":           {},
+	"settxfee":                  {},
+	"settxmemo":                 {},
+	"setwalletflag":             {},
+	"signmessage":               {},
+	"signrawtransaction":        {},
+	"signsigningpackage":        {},
+	"startrecovery":             {},
+	"verifywalletbackup":        {},
+	"walletlock":                {},
+	"walletpassphrase":          {},
+	"walletpassphrasechange":    {},
+	"walletpubpassphrasechange": {},
+}
+
+// rpcReplicaUnsupported is the set of commands which either modify the chain
+// or mempool, or need the network, and so are refused when running as a
+// read-only replica (--replica).
+var rpcReplicaUnsupported = map[string]struct{}{
+	"addnode":                {},
+	"checkpcshare":           {},
+	"configureminingpayouts": {},
+	"generate":               {},
+	"generatefork":           {},
+	"getblocktemplate":       {},
+	"getrawblocktemplate":    {},
+	"node":                   {},
+	"ping":                   {},
+	"sendrawtransaction":     {},
+	"setgenerate":            {},
+	"simulatereorg":          {},
+	"submitblock":            {},
 }
 
 // Commands that are currently unimplemented, but should ultimately be.
 var rpcUnimplemented = map[string]struct{}{
 	"estimatepriority": {},
 	"getchaintips":     {},
-	"getmempoolentry":  {},
-	"getnetworkinfo":   {},
 	"getwork":          {},
 	"invalidateblock":  {},
 	"preciousblock":    {},
@@ -248,46 +388,72 @@ var rpcUnimplemented = map[string]struct{}{
 var rpcLimited = map[string]struct{}{
 	// Websockets commands
 	"loadtxfilter":          {},
+	"loadwatchlist":         {},
 	"notifyblocks":          {},
 	"notifynewtransactions": {},
 	"notifyreceived":        {},
 	"notifyspent":           {},
+	"removewatchlist":       {},
 	"rescan":                {},
 	"rescanblocks":          {},
 	"session":               {},
+	"streamblockheaders":    {},
 
 	// Websockets AND HTTP/S commands
 	"help": {},
 
 	// HTTP/S-only commands
-	"createrawtransaction":  {},
-	"decoderawtransaction":  {},
-	"decodescript":          {},
-	"estimatefee":           {},
-	"getbestblock":          {},
-	"getbestblockhash":      {},
-	"getblock":              {},
-	"getblockcount":         {},
-	"getblockhash":          {},
-	"getblockheader":        {},
-	"getcfilter":            {},
-	"getcfilterheader":      {},
-	"getcurrentnet":         {},
-	"getdifficulty":         {},
-	"getheaders":            {},
-	"getinfo":               {},
-	"getnettotals":          {},
-	"getnetworkhashps":      {},
-	"getrawmempool":         {},
-	"getrawtransaction":     {},
-	"gettxout":              {},
-	"searchrawtransactions": {},
-	"sendrawtransaction":    {},
-	"submitblock":           {},
-	"uptime":                {},
-	"validateaddress":       {},
-	"verifymessage":         {},
-	"version":               {},
+	"createrawtransaction":   {},
+	"decoderawtransaction":   {},
+	"decodescript":           {},
+	"estimatefee":            {},
+	"getbestblock":           {},
+	"getbestblockhash":       {},
+	"getblock":               {},
+	"getannagingschedule":    {},
+	"getannproof":            {},
+	"getblockcount":          {},
+	"getblockhash":           {},
+	"getblockheader":         {},
+	"getblockheaders":        {},
+	"getblockundo":           {},
+	"getcfilter":             {},
+	"getcfilterheader":       {},
+	"getconsensusrules":      {},
+	"getcurrentnet":          {},
+	"getdatacarrierinfo":     {},
+	"getdifficulty":          {},
+	"getdifficultyhistory":   {},
+	"getheaders":             {},
+	"getfeehistory":          {},
+	"getinfo":                {},
+	"getmininganalytics":     {},
+	"getnettotals":           {},
+	"getnetworkhashps":       {},
+	"getnetworkhashrate":     {},
+	"getnetworkinfo":         {},
+	"getmempooldelta":        {},
+	"getmempoolentry":        {},
+	"getorphanblocks":        {},
+	"getorphantxs":           {},
+	"getrawmempool":          {},
+	"getrawtransaction":      {},
+	"gettxfee":               {},
+	"gettxout":               {},
+	"gettxoutproof":          {},
+	"getutxodeltas":          {},
+	"getutxostats":           {},
+	"searchrawtransactions":  {},
+	"sendrawtransaction":     {},
+	"submitblock":            {},
+	"uptime":                 {},
+	"validateaddress":        {},
+	"verifyaddressownership": {},
+	"verifymessage":          {},
+	"verifytxoutproof":       {},
+	"version":                {},
+	"waitforblockheight":     {},
+	"waitfornewblock":        {},
 }
 
 // builderScript is a convenience function which is used for hard-coded scripts
@@ -333,6 +499,20 @@ func rpcNoTxInfoError(txHash *chainhash.Hash) *btcjson.RPCError {
 			txHash))
 }
 
+// txIndexMissError returns the error for a transaction which is not in the
+// transaction index.  When the index only covers the blocks from a height
+// onwards, the error says the transaction may be in an earlier block.
+func (s *rpcServer) txIndexMissError(txHash *chainhash.Hash) *btcjson.RPCError {
+	startHeight := s.cfg.TxIndex.StartHeight()
+	if startHeight == 0 {
+		return rpcNoTxInfoError(txHash)
+	}
+	return btcjson.NewRPCError(btcjson.ErrRPCNoTxInfo,
+		fmt.Sprintf("No information available about transaction %v: "+
+			"blocks below height %d are not indexed", txHash,
+			startHeight))
+}
+
 // gbtWorkState houses state that is used in between multiple RPC invocations to
 // getblocktemplate.
 type gbtWorkState struct {
@@ -345,6 +525,40 @@ type gbtWorkState struct {
 	notifyMap        map[chainhash.Hash]map[int64]chan struct{}
 	timeSource       blockchain.MedianTimeSource
 	withPayAddresses bool
+	recentTemplates  []*gbtTemplateTxs
+
+	// lastRequest is when a block template was last requested, and
+	// longPolls the number of long poll requests waiting for the block
+	// template to change.  They tell whether the block template is still
+	// being used.
+	lastRequest time.Time
+	longPolls   int
+}
+
+// gbtTemplateTxs houses the transactions of a block template generated by the
+// work state, along with the merkle branch of its coinbase, which do not
+// depend on the coinbase.
+type gbtTemplateTxs struct {
+	id             string
+	txHashes       []chainhash.Hash
+	coinbaseBranch []*chainhash.Hash
+}
+
+// noteRequest records that a block template was requested.
+//
+// This function MUST be called with the state locked.
+func (state *gbtWorkState) noteRequest() {
+	state.lastRequest = time.Now()
+}
+
+// isIdle returns whether no block template was requested within gbtIdleTimeout
+// of the passed time and no long poll is waiting, so there is no one to keep
+// the block template up to date for.
+//
+// This function MUST be called with the state locked.
+func (state *gbtWorkState) isIdle(now time.Time) bool {
+	return state.longPolls == 0 &&
+		now.Sub(state.lastRequest) > gbtIdleTimeout
 }
 
 // newGbtWorkState returns a new instance of a gbtWorkState with all internal
@@ -362,6 +576,12 @@ func handleUnimplemented(s *rpcServer, cmd interface{}, closeChan <-chan struct{
 	return nil, ErrRPCUnimplemented
 }
 
+// handleReplicaUnsupported is the handler for commands that are recognized
+// and implemented, but are not supported by a read-only replica.
+func handleReplicaUnsupported(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	return nil, ErrRPCReplica
+}
+
 // handleAskWallet is the handler for commands that are recognized as valid, but
 // are unable to answer correctly since it involves wallet state.
 // These commands will be implemented in pktwallet.
@@ -518,6 +738,143 @@ func messageToHex(msg wire.Message) (string, error) {
 	return hex.EncodeToString(buf.Bytes()), nil
 }
 
+// handleCaptureMessages implements the capturemessages command.
+func handleCaptureMessages(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.CaptureMessagesCmd)
+
+	if s.cfg.MsgCapture == nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCMisc,
+			Message: "Messages can't be captured without peers",
+		}
+	}
+
+	switch c.SubCmd {
+	case btcjson.CaptureStart:
+		var redact *msgCaptureRedaction
+		if c.Redact != nil {
+			var err error
+			redact, err = parseMsgCaptureRedaction(*c.Redact)
+			if err != nil {
+				return nil, &btcjson.RPCError{
+					Code:    btcjson.ErrRPCInvalidParameter,
+					Message: err.Error(),
+				}
+			}
+		}
+		if err := s.cfg.MsgCapture.Start(redact); err != nil {
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCMisc,
+				Message: "Unable to start capture: " + err.Error(),
+			}
+		}
+
+	case btcjson.CaptureStop:
+		s.cfg.MsgCapture.Stop()
+
+	case btcjson.CaptureStatus:
+
+	default:
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "invalid subcommand for capturemessages",
+		}
+	}
+
+	return s.cfg.MsgCapture.Status(), nil
+}
+
+// handleCaptureProfile implements the captureprofile command.
+func handleCaptureProfile(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.CaptureProfileCmd)
+
+	// Profiles are only written to the profile directory, so the command
+	// can't be used to overwrite arbitrary files.
+	var file string
+	if c.File != nil {
+		if *c.File == "" || filepath.Base(*c.File) != *c.File {
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCInvalidParameter,
+				Message: "The file must be a file name without directory",
+			}
+		}
+		file = filepath.Join(cfg.DataDir, profileDirName, *c.File)
+	}
+
+	var buf bytes.Buffer
+	if c.Profile == "cpu" {
+		seconds := int32(30)
+		if c.Seconds != nil {
+			seconds = *c.Seconds
+		}
+		if seconds < 1 || seconds > maxProfileSeconds {
+			return nil, &btcjson.RPCError{
+				Code: btcjson.ErrRPCInvalidParameter,
+				Message: fmt.Sprintf("The CPU profile duration must "+
+					"be between 1 and %d seconds", maxProfileSeconds),
+			}
+		}
+		if err := pprof.StartCPUProfile(&buf); err != nil {
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCMisc,
+				Message: "Unable to start CPU profile: " + err.Error(),
+			}
+		}
+		select {
+		case <-time.After(time.Duration(seconds) * time.Second):
+		case <-closeChan:
+			pprof.StopCPUProfile()
+			return nil, ErrClientQuit
+		}
+		pprof.StopCPUProfile()
+	} else {
+		profile := pprof.Lookup(c.Profile)
+		if profile == nil {
+			return nil, &btcjson.RPCError{
+				Code: btcjson.ErrRPCInvalidParameter,
+				Message: fmt.Sprintf("Unknown profile %q, expected "+
+					"cpu, %s", c.Profile, strings.Join(
+					profileNames(), ", ")),
+			}
+		}
+		if err := profile.WriteTo(&buf, 0); err != nil {
+			context := "Failed to write profile"
+			return nil, internalRPCError(err.Error(), context)
+		}
+	}
+
+	result := &btcjson.CaptureProfileResult{
+		Profile: c.Profile,
+		Bytes:   int64(buf.Len()),
+	}
+	if file == "" {
+		result.Data = base64.StdEncoding.EncodeToString(buf.Bytes())
+		return result, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		context := "Failed to create profile directory"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	if err := ioutil.WriteFile(file, buf.Bytes(), 0600); err != nil {
+		context := "Failed to write profile file"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	result.File = file
+	return result, nil
+}
+
+// profileNames returns the sorted names of the runtime profiles which can be
+// captured besides the CPU profile.
+func profileNames() []string {
+	profiles := pprof.Profiles()
+	names := make([]string, 0, len(profiles))
+	for _, profile := range profiles {
+		names = append(names, profile.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
 // handleCreateRawTransaction handles createrawtransaction commands.
 func handleCreateRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*btcjson.CreateRawTransactionCmd)
@@ -940,6 +1297,96 @@ func handleGenerate(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (i
 	return reply, nil
 }
 
+// newRPCForkGenerator returns a generator of alternative chains submitting the
+// blocks through the sync manager along with the address paid by the blocks,
+// which is nil when none is passed and no mining address is configured.  It
+// responds with an error on networks which do not allow CPU mining.
+func newRPCForkGenerator(s *rpcServer, address *string) (*forkGenerator, btcutil.Address, error) {
+	params := s.cfg.ChainParams
+	if !params.GenerateSupported {
+		return nil, nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCDifficulty,
+			Message: fmt.Sprintf("No support for generating forks on "+
+				"the current network, %s, as it's unlikely to "+
+				"be possible to mine a block with the CPU.",
+				params.Net),
+		}
+	}
+
+	var addr btcutil.Address
+	if address != nil {
+		var err error
+		addr, err = btcutil.DecodeAddress(*address, params)
+		if err != nil || !addr.IsForNet(params) {
+			return nil, nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCInvalidAddressOrKey,
+				Message: "Invalid address: " + *address,
+			}
+		}
+	} else {
+		for a := range cfg.miningAddrs {
+			addr = a
+			break
+		}
+	}
+
+	g := &forkGenerator{
+		chain:  s.cfg.Chain,
+		params: params,
+		submit: func(block *btcutil.Block) error {
+			isOrphan, err := s.cfg.SyncMgr.SubmitBlock(block,
+				blockchain.BFNone)
+			if err == nil && isOrphan {
+				err = fmt.Errorf("block is an orphan")
+			}
+			return err
+		},
+	}
+	return g, addr, nil
+}
+
+// handleGenerateFork implements the generatefork command.
+func handleGenerateFork(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GenerateForkCmd)
+
+	g, addr, err := newRPCForkGenerator(s, c.Address)
+	if err != nil {
+		return nil, err
+	}
+	forkHash, err := chainhash.NewHashFromStr(c.ForkHash)
+	if err != nil {
+		return nil, rpcDecodeHexError(c.ForkHash)
+	}
+	if !s.cfg.Chain.MainChainHasBlock(forkHash) {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCBlockNotFound,
+			Message: "Block not found in the main chain: " + c.ForkHash,
+		}
+	}
+	if c.NumBlocks == 0 {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Please request a nonzero number of blocks to generate.",
+		}
+	}
+
+	hashes, err := g.generate(forkHash, c.NumBlocks, addr)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInternal.Code,
+			Message: err.Error(),
+		}
+	}
+	reply := make([]string, 0, len(hashes))
+	for _, hash := range hashes {
+		reply = append(reply, hash.String())
+	}
+
+	rpcsLog.Infof("Generated %d blocks on top of block %v via generatefork",
+		len(hashes), forkHash)
+	return reply, nil
+}
+
 // handleGetAddedNodeInfo handles getaddednodeinfo commands.
 func handleGetAddedNodeInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*btcjson.GetAddedNodeInfoCmd)
@@ -1030,6 +1477,24 @@ func handleGetAddedNodeInfo(s *rpcServer, cmd interface{}, closeChan <-chan stru
 	return results, nil
 }
 
+// handleGetAuditLog implements the getauditlog command.
+func handleGetAuditLog(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetAuditLogCmd)
+
+	if s.auditLog == nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCMisc,
+			Message: "The RPC audit log is not enabled (--rpcauditlog)",
+		}
+	}
+
+	count := int32(100)
+	if c.Count != nil {
+		count = *c.Count
+	}
+	return s.auditLog.Entries(int(count)), nil
+}
+
 // handleGetBestBlock implements the getbestblock command.
 func handleGetBestBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	// All other "get block" commands give either the height, the
@@ -1145,6 +1610,8 @@ func handleGetBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (i
 		Difficulty:    getDifficultyRatio(blockHeader.Bits, params),
 		NextHash:      nextHashString,
 	}
+	work := packetcrypt.ExtractBlockWork(blk.MsgBlock())
+	blockReply.PacketCrypt = s.packetCryptWorkResult(&work)
 
 	if c.VerboseTx == nil || !*c.VerboseTx {
 		transactions := blk.Transactions()
@@ -1191,29 +1658,58 @@ func softForkStatus(state blockchain.ThresholdState) (string, error) {
 	}
 }
 
+// softForkName maps a version bits deployment ID into a human readable
+// fork-name.
+func softForkName(deployment int) (string, error) {
+	switch deployment {
+	case chaincfg.DeploymentTestDummy:
+		return "dummy", nil
+	case chaincfg.DeploymentCSV:
+		return "csv", nil
+	case chaincfg.DeploymentSegwit:
+		return "segwit", nil
+	default:
+		return "", fmt.Errorf("unknown deployment %v", deployment)
+	}
+}
+
 // handleGetBlockChainInfo implements the getblockchaininfo command.
 func handleGetBlockChainInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
-	// Obtain a snapshot of the current best known blockchain state. We'll
-	// populate the response to this call primarily from this snapshot.
+	// Obtain a snapshot of the current best known blockchain state along
+	// with the progress of the sync. We'll populate the response to this
+	// call primarily from this snapshot.
 	params := s.cfg.ChainParams
 	chain := s.cfg.Chain
-	chainSnapshot := chain.BestSnapshot()
+	estimate, err := s.estimateSync()
+	if err != nil {
+		context := "Failed to estimate the progress of the sync"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	chainSnapshot := estimate.best
 
 	chainInfo := &btcjson.GetBlockChainInfoResult{
-		Chain:         params.Name,
-		Blocks:        chainSnapshot.Height,
-		Headers:       chainSnapshot.Height,
-		BestBlockHash: chainSnapshot.Hash.String(),
-		Difficulty:    getDifficultyRatio(chainSnapshot.Bits, params),
-		MedianTime:    chainSnapshot.MedianTime.Unix(),
-		Pruned:        false,
-		Bip9SoftForks: make(map[string]*btcjson.Bip9SoftForkDescription),
+		Chain:                  params.Name,
+		Blocks:                 chainSnapshot.Height,
+		Headers:                estimate.status.HeaderHeight,
+		BestBlockHash:          chainSnapshot.Hash.String(),
+		Difficulty:             getDifficultyRatio(chainSnapshot.Bits, params),
+		MedianTime:             chainSnapshot.MedianTime.Unix(),
+		VerificationProgress:   estimate.progress,
+		Pruned:                 false,
+		Bip9SoftForks:          make(map[string]*btcjson.Bip9SoftForkDescription),
+		InitialBlockDownload:   !estimate.status.Current,
+		EstimatedTimeRemaining: estimate.remaining,
 	}
 
 	// Next, populate the response with information describing the current
 	// status of soft-forks deployed via the super-majority block
-	// signalling mechanism.
-	height := chainSnapshot.Height
+	// signalling mechanism.  Each rejects the block versions below its
+	// own once the rules of the best block require it.
+	rules, err := chain.RulesAtHeight(chainSnapshot.Height)
+	if err != nil {
+		context := "Failed to obtain consensus rules"
+		return nil, internalRPCError(err.Error(), context)
+	}
 	chainInfo.SoftForks = []*btcjson.SoftForkDescription{
 		{
 			ID:      "bip34",
@@ -1221,7 +1717,7 @@ func handleGetBlockChainInfo(s *rpcServer, cmd interface{}, closeChan <-chan str
 			Reject: struct {
 				Status bool `json:"status"`
 			}{
-				Status: height >= params.BIP0034Height,
+				Status: rules.SerializedHeight,
 			},
 		},
 		{
@@ -1230,7 +1726,7 @@ func handleGetBlockChainInfo(s *rpcServer, cmd interface{}, closeChan <-chan str
 			Reject: struct {
 				Status bool `json:"status"`
 			}{
-				Status: height >= params.BIP0066Height,
+				Status: rules.MinBlockVersion >= 3,
 			},
 		},
 		{
@@ -1239,7 +1735,7 @@ func handleGetBlockChainInfo(s *rpcServer, cmd interface{}, closeChan <-chan str
 			Reject: struct {
 				Status bool `json:"status"`
 			}{
-				Status: height >= params.BIP0065Height,
+				Status: rules.MinBlockVersion >= 4,
 			},
 		},
 	}
@@ -1249,18 +1745,8 @@ func handleGetBlockChainInfo(s *rpcServer, cmd interface{}, closeChan <-chan str
 	for deployment, deploymentDetails := range params.Deployments {
 		// Map the integer deployment ID into a human readable
 		// fork-name.
-		var forkName string
-		switch deployment {
-		case chaincfg.DeploymentTestDummy:
-			forkName = "dummy"
-
-		case chaincfg.DeploymentCSV:
-			forkName = "csv"
-
-		case chaincfg.DeploymentSegwit:
-			forkName = "segwit"
-
-		default:
+		forkName, err := softForkName(deployment)
+		if err != nil {
 			return nil, &btcjson.RPCError{
 				Code: btcjson.ErrRPCInternal.Code,
 				Message: fmt.Sprintf("Unknown deployment %v "+
@@ -1301,6 +1787,96 @@ func handleGetBlockChainInfo(s *rpcServer, cmd interface{}, closeChan <-chan str
 	return chainInfo, nil
 }
 
+// handleGetBlockCost implements the getblockcost command.
+func handleGetBlockCost(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetBlockCostCmd)
+
+	var block *btcutil.Block
+	switch {
+	// Account for the current block template when no block is given.
+	case c.Block == nil:
+		state := s.gbtWorkState
+		state.Lock()
+		defer state.Unlock()
+		err := state.updateBlockTemplate(s, !state.withPayAddresses)
+		if err != nil {
+			return nil, err
+		}
+		block = btcutil.NewBlock(state.template.Block)
+
+	case len(*c.Block) == chainhash.MaxHashStringSize:
+		hash, err := chainhash.NewHashFromStr(*c.Block)
+		if err != nil {
+			return nil, rpcDecodeHexError(*c.Block)
+		}
+		block, err = s.cfg.Chain.BlockByHash(hash)
+		if err != nil {
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCBlockNotFound,
+				Message: "Block not found",
+			}
+		}
+
+	default:
+		hexStr := *c.Block
+		if len(hexStr)%2 != 0 {
+			hexStr = "0" + hexStr
+		}
+		serializedBlock, err := hex.DecodeString(hexStr)
+		if err != nil {
+			return nil, rpcDecodeHexError(hexStr)
+		}
+		block, err = btcutil.NewBlockFromBytes(serializedBlock)
+		if err != nil {
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCDeserialization,
+				Message: "Block decode failed: " + err.Error(),
+			}
+		}
+	}
+
+	cost, err := s.cfg.Chain.BlockCost(block)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCVerify,
+			Message: "Unable to account for the block: " + err.Error(),
+		}
+	}
+
+	txns := make([]btcjson.TxCostResult, 0, len(cost.Txs))
+	for i, tx := range block.Transactions() {
+		txCost := &cost.Txs[i]
+		txns = append(txns, btcjson.TxCostResult{
+			TxID:          tx.Hash().String(),
+			Hash:          tx.WitnessHash().String(),
+			Size:          txCost.Size,
+			StrippedSize:  txCost.BaseSize,
+			WitnessSize:   txCost.WitnessSize,
+			Weight:        txCost.Weight,
+			LegacySigOps:  txCost.LegacySigOps,
+			P2SHSigOps:    txCost.P2SHSigOps,
+			WitnessSigOps: txCost.WitnessSigOps,
+			SigOpCost:     txCost.SigOpCost,
+		})
+	}
+	return &btcjson.GetBlockCostResult{
+		Hash:            block.Hash().String(),
+		Height:          block.Height(),
+		Size:            cost.Size,
+		StrippedSize:    cost.BaseSize,
+		WitnessSize:     cost.WitnessSize,
+		Weight:          cost.Weight,
+		SigOpCost:       cost.SigOpCost,
+		MaxStrippedSize: blockchain.MaxBlockBaseSize,
+		MaxWeight:       blockchain.MaxBlockWeight,
+		MaxSigOpCost:    blockchain.MaxBlockSigOpsCost,
+		HeaderSize:      cost.HeaderSize,
+		TxCountSize:     cost.TxCountSize,
+		ProofSize:       cost.ProofSize,
+		Transactions:    txns,
+	}, nil
+}
+
 // handleGetBlockCount implements the getblockcount command.
 func handleGetBlockCount(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	best := s.cfg.Chain.BestSnapshot()
@@ -1371,22 +1947,31 @@ func handleGetBlockHeader(s *rpcServer, cmd interface{}, closeChan <-chan struct
 		nextHashString = nextHash.String()
 	}
 
-	params := s.cfg.ChainParams
-	blockHeaderReply := btcjson.GetBlockHeaderVerboseResult{
-		Hash:          c.Hash,
-		Confirmations: int64(1 + best.Height - blockHeight),
-		Height:        blockHeight,
+	return blockHeaderVerboseResult(&blockHeader, c.Hash, blockHeight,
+		best.Height, nextHashString, s.cfg.ChainParams), nil
+}
+
+// blockHeaderVerboseResult returns the verbose description of the passed main
+// chain header at the passed height, given the best height and the hash of the
+// next block, which is empty for the best block.
+func blockHeaderVerboseResult(blockHeader *wire.BlockHeader, hash string,
+	height, bestHeight int32, nextHash string,
+	params *chaincfg.Params) btcjson.GetBlockHeaderVerboseResult {
+
+	return btcjson.GetBlockHeaderVerboseResult{
+		Hash:          hash,
+		Confirmations: int64(1 + bestHeight - height),
+		Height:        height,
 		Version:       blockHeader.Version,
 		VersionHex:    fmt.Sprintf("%08x", blockHeader.Version),
 		MerkleRoot:    blockHeader.MerkleRoot.String(),
-		NextHash:      nextHashString,
+		NextHash:      nextHash,
 		PreviousHash:  blockHeader.PrevBlock.String(),
 		Nonce:         uint64(blockHeader.Nonce),
 		Time:          blockHeader.Timestamp.Unix(),
 		Bits:          strconv.FormatInt(int64(blockHeader.Bits), 16),
 		Difficulty:    getDifficultyRatio(blockHeader.Bits, params),
 	}
-	return blockHeaderReply, nil
 }
 
 // encodeTemplateID encodes the passed details into an ID that can be used to
@@ -1529,6 +2114,80 @@ func (state *gbtWorkState) templateUpdateChan(prevHash *chainhash.Hash, lastGene
 	return c
 }
 
+// lastTxSourceUpdate returns the last time the transactions available to the
+// block templates were updated, or now when it is not known.
+func (state *gbtWorkState) lastTxSourceUpdate(s *rpcServer) time.Time {
+	lastTxUpdate := s.cfg.Generator.TxSource().LastUpdated()
+	if lastTxUpdate.IsZero() {
+		lastTxUpdate = time.Now()
+	}
+	return lastTxUpdate
+}
+
+// isStale returns whether a new block template must be generated for the work
+// state, which is the case when there is none with the requested kind of
+// coinbase, the current best block has changed or the transactions in the
+// memory pool have been updated and it has been at least
+// gbtRegenerateSeconds since the last template was generated.
+//
+// This function MUST be called with the state locked.
+func (state *gbtWorkState) isStale(s *rpcServer, useCoinbaseValue bool) bool {
+	latestHash := &s.cfg.Chain.BestSnapshot().Hash
+	return state.template == nil || state.prevHash == nil ||
+		state.withPayAddresses != !useCoinbaseValue ||
+		!state.prevHash.IsEqual(latestHash) ||
+		(state.lastTxUpdate != state.lastTxSourceUpdate(s) &&
+			time.Now().After(state.lastGenerated.Add(time.Second*
+				gbtRegenerateSeconds)))
+}
+
+// recordTemplate remembers the transactions of the current block template so
+// getblocktemplatelight can reply with the differences from it, forgetting
+// the oldest template remembered when there are more than
+// gbtTemplateHistorySize of them.
+//
+// This function MUST be called with the state locked.
+func (state *gbtWorkState) recordTemplate() {
+	block := btcutil.NewBlock(state.template.Block)
+	merkles := blockchain.BuildMerkleTreeStore(block.Transactions(), false)
+	txHashes := make([]chainhash.Hash, 0, len(block.Transactions())-1)
+	for _, tx := range block.Transactions()[1:] {
+		txHashes = append(txHashes, *tx.Hash())
+	}
+	record := &gbtTemplateTxs{
+		id:             encodeTemplateID(state.prevHash, state.lastGenerated),
+		txHashes:       txHashes,
+		coinbaseBranch: blockchain.GetMerkleBranch(0, merkles),
+	}
+
+	// Templates generated within the same second share their ID, so the
+	// newest replaces the older one.
+	templates := state.recentTemplates[:0]
+	for _, t := range state.recentTemplates {
+		if t.id != record.id {
+			templates = append(templates, t)
+		}
+	}
+	templates = append(templates, record)
+	if len(templates) > gbtTemplateHistorySize {
+		templates = templates[len(templates)-gbtTemplateHistorySize:]
+	}
+	state.recentTemplates = templates
+}
+
+// recentTemplate returns the transactions of the remembered block template
+// with the passed ID, or nil when it is not remembered.
+//
+// This function MUST be called with the state locked.
+func (state *gbtWorkState) recentTemplate(id string) *gbtTemplateTxs {
+	for _, t := range state.recentTemplates {
+		if t.id == id {
+			return t
+		}
+	}
+	return nil
+}
+
 // updateBlockTemplate creates or updates a block template for the work state.
 // A new block template will be generated when the current best block has
 // changed or the transactions in the memory pool have been updated and it has
@@ -1543,10 +2202,7 @@ func (state *gbtWorkState) templateUpdateChan(prevHash *chainhash.Hash, lastGene
 // This function MUST be called with the state locked.
 func (state *gbtWorkState) updateBlockTemplate(s *rpcServer, useCoinbaseValue bool) error {
 	generator := s.cfg.Generator
-	lastTxUpdate := generator.TxSource().LastUpdated()
-	if lastTxUpdate.IsZero() {
-		lastTxUpdate = time.Now()
-	}
+	lastTxUpdate := state.lastTxSourceUpdate(s)
 
 	// Generate a new block template when the current best block has
 	// changed or the transactions in the memory pool have been updated and
@@ -1556,12 +2212,7 @@ func (state *gbtWorkState) updateBlockTemplate(s *rpcServer, useCoinbaseValue bo
 	var targetDifficulty string
 	latestHash := &s.cfg.Chain.BestSnapshot().Hash
 	template := state.template
-	if template == nil || state.prevHash == nil ||
-		state.withPayAddresses != !useCoinbaseValue ||
-		!state.prevHash.IsEqual(latestHash) ||
-		(state.lastTxUpdate != lastTxUpdate &&
-			time.Now().After(state.lastGenerated.Add(time.Second*
-				gbtRegenerateSeconds))) {
+	if state.isStale(s, useCoinbaseValue) {
 
 		// Reset the previous best hash the block template was generated
 		// against so any errors below cause the next invocation to try
@@ -1602,6 +2253,7 @@ func (state *gbtWorkState) updateBlockTemplate(s *rpcServer, useCoinbaseValue bo
 		state.prevHash = latestHash
 		state.minTimestamp = minTimestamp
 		state.withPayAddresses = len(payAddrs) > 0
+		state.recordTemplate()
 
 		rpcsLog.Debugf("Generated block template (timestamp %v, "+
 			"target %s, merkle root %s)",
@@ -1637,34 +2289,12 @@ func (state *gbtWorkState) updateBlockTemplate(s *rpcServer, useCoinbaseValue bo
 	return nil
 }
 
-// blockTemplateResult returns the current block template associated with the
-// state as a btcjson.GetBlockTemplateResult that is ready to be encoded to JSON
-// and returned to the caller.
-//
-// This function MUST be called with the state locked.
-func (state *gbtWorkState) blockTemplateResult(useCoinbaseValue bool, submitOld *bool) (*btcjson.GetBlockTemplateResult, error) {
-	// Ensure the timestamps are still in valid range for the template.
-	// This should really only ever happen if the local clock is changed
-	// after the template is generated, but it's important to avoid serving
-	// invalid block templates.
-	template := state.template
+// templateResultTxs converts the transactions of the passed block template to
+// template result transactions.  The result does not include the coinbase, so
+// notice the adjustments to the various lengths and indices.  When include is
+// not nil, only the transactions it returns true for are converted.
+func templateResultTxs(template *mining.BlockTemplate, include func(txHash *chainhash.Hash) bool) ([]btcjson.GetBlockTemplateResultTx, error) {
 	msgBlock := template.Block
-	header := &msgBlock.Header
-	adjustedTime := state.timeSource.AdjustedTime()
-	maxTime := adjustedTime.Add(time.Second * globalcfg.GetMaxTimeOffsetSeconds())
-	if header.Timestamp.After(maxTime) {
-		return nil, &btcjson.RPCError{
-			Code: btcjson.ErrRPCOutOfRange,
-			Message: fmt.Sprintf("The template time is after the "+
-				"maximum allowed time for a block - template "+
-				"time %v, maximum time %v", adjustedTime,
-				maxTime),
-		}
-	}
-
-	// Convert each transaction in the block template to a template result
-	// transaction.  The result does not include the coinbase, so notice
-	// the adjustments to the various lengths and indices.
 	numTx := len(msgBlock.Transactions)
 	transactions := make([]btcjson.GetBlockTemplateResultTx, 0, numTx-1)
 	txIndex := make(map[chainhash.Hash]int64, numTx)
@@ -1673,7 +2303,7 @@ func (state *gbtWorkState) blockTemplateResult(useCoinbaseValue bool, submitOld
 		txIndex[txHash] = int64(i)
 
 		// Skip the coinbase transaction.
-		if i == 0 {
+		if i == 0 || (include != nil && !include(&txHash)) {
 			continue
 		}
 
@@ -1713,6 +2343,51 @@ func (state *gbtWorkState) blockTemplateResult(useCoinbaseValue bool, submitOld
 		transactions = append(transactions, resultTx)
 	}
 
+	return transactions, nil
+}
+
+// templateMaxTime returns the maximum time allowed for the current block
+// template.  It ensures the timestamps are still in valid range for the
+// template.  This should really only ever happen if the local clock is changed
+// after the template is generated, but it's important to avoid serving invalid
+// block templates.
+//
+// This function MUST be called with the state locked.
+func (state *gbtWorkState) templateMaxTime() (time.Time, error) {
+	header := &state.template.Block.Header
+	adjustedTime := state.timeSource.AdjustedTime()
+	maxTime := adjustedTime.Add(time.Second * globalcfg.GetMaxTimeOffsetSeconds())
+	if header.Timestamp.After(maxTime) {
+		return maxTime, &btcjson.RPCError{
+			Code: btcjson.ErrRPCOutOfRange,
+			Message: fmt.Sprintf("The template time is after the "+
+				"maximum allowed time for a block - template "+
+				"time %v, maximum time %v", adjustedTime,
+				maxTime),
+		}
+	}
+	return maxTime, nil
+}
+
+// blockTemplateResult returns the current block template associated with the
+// state as a btcjson.GetBlockTemplateResult that is ready to be encoded to JSON
+// and returned to the caller.
+//
+// This function MUST be called with the state locked.
+func (state *gbtWorkState) blockTemplateResult(useCoinbaseValue bool, submitOld *bool) (*btcjson.GetBlockTemplateResult, error) {
+	template := state.template
+	msgBlock := template.Block
+	header := &msgBlock.Header
+	maxTime, err := state.templateMaxTime()
+	if err != nil {
+		return nil, err
+	}
+
+	transactions, err := templateResultTxs(template, nil)
+	if err != nil {
+		return nil, err
+	}
+
 	// Generate the block template reply.  Note that following mutations are
 	// implied by the included or omission of fields:
 	//  Including MinTime -> time/decrement
@@ -1782,6 +2457,94 @@ func (state *gbtWorkState) blockTemplateResult(useCoinbaseValue bool, submitOld
 	return &reply, nil
 }
 
+// blockTemplateLightResult returns the current block template associated with
+// the state as a btcjson.GetBlockTemplateLightResult that is ready to be
+// encoded to JSON and returned to the caller.  It holds the transactions which
+// are not in the remembered block template with the passed base ID, or all of
+// them when the base ID is empty or the template is no longer remembered.
+//
+// This function MUST be called with the state locked.
+func (state *gbtWorkState) blockTemplateLightResult(baseID string, submitOld *bool) (*btcjson.GetBlockTemplateLightResult, error) {
+	template := state.template
+	msgBlock := template.Block
+	header := &msgBlock.Header
+	maxTime, err := state.templateMaxTime()
+	if err != nil {
+		return nil, err
+	}
+
+	// The current block template is always remembered since it is the
+	// last one generated.
+	templateID := encodeTemplateID(state.prevHash, state.lastGenerated)
+	current := state.recentTemplate(templateID)
+	if current == nil {
+		return nil, internalRPCError("Block template "+templateID+
+			" is not remembered", "")
+	}
+	txHashes := make([]string, 0, len(current.txHashes))
+	for i := range current.txHashes {
+		txHashes = append(txHashes, current.txHashes[i].String())
+	}
+	merkle := make([]string, 0, len(current.coinbaseBranch))
+	for _, hash := range current.coinbaseBranch {
+		merkle = append(merkle, hex.EncodeToString(hash[:]))
+	}
+
+	// Only include the transactions which are not in the base template
+	// and list the ones which are no longer in the current template.
+	var include func(txHash *chainhash.Hash) bool
+	removed := []string{}
+	base := state.recentTemplate(baseID)
+	if base != nil {
+		inBase := make(map[chainhash.Hash]struct{}, len(base.txHashes))
+		for _, txHash := range base.txHashes {
+			inBase[txHash] = struct{}{}
+		}
+		inCurrent := make(map[chainhash.Hash]struct{}, len(current.txHashes))
+		for _, txHash := range current.txHashes {
+			inCurrent[txHash] = struct{}{}
+		}
+		for i := range base.txHashes {
+			if _, ok := inCurrent[base.txHashes[i]]; !ok {
+				removed = append(removed, base.txHashes[i].String())
+			}
+		}
+		include = func(txHash *chainhash.Hash) bool {
+			_, ok := inBase[*txHash]
+			return !ok
+		}
+	} else {
+		baseID = ""
+	}
+	transactions, err := templateResultTxs(template, include)
+	if err != nil {
+		return nil, err
+	}
+
+	reply := btcjson.GetBlockTemplateLightResult{
+		Bits:           strconv.FormatInt(int64(header.Bits), 16),
+		CurTime:        header.Timestamp.Unix(),
+		Height:         int64(template.Height),
+		PreviousHash:   header.PrevBlock.String(),
+		Version:        header.Version,
+		Target:         fmt.Sprintf("%064x", blockchain.CompactToBig(header.Bits)),
+		MinTime:        state.minTimestamp.Unix(),
+		MaxTime:        maxTime.Unix(),
+		LongPollID:     templateID,
+		SubmitOld:      submitOld,
+		CoinbaseValue:  msgBlock.Transactions[0].TxOut[0].Value,
+		Merkle:         merkle,
+		TxHashes:       txHashes,
+		BaseLongPollID: baseID,
+		Transactions:   transactions,
+		Removed:        removed,
+	}
+	if template.WitnessCommitment != nil {
+		reply.DefaultWitnessCommitment = hex.EncodeToString(template.WitnessCommitment)
+	}
+	return &reply, nil
+}
+
 // handleGetBlockTemplateLongPoll is a helper for handleGetBlockTemplateRequest
 // which deals with handling long polling for block templates.  When a caller
 // sends a request with a long poll ID that was previously returned, a response
@@ -1794,11 +2557,30 @@ func (state *gbtWorkState) blockTemplateResult(useCoinbaseValue bool, submitOld
 // See https://en.bitcoin.it/wiki/BIP_0022 for more details.
 func handleGetBlockTemplateLongPoll(s *rpcServer, longPollID string, useCoinbaseValue bool, closeChan <-chan struct{}) (interface{}, error) {
 	state := s.gbtWorkState
+	submitOld, err := state.waitForStaleTemplate(s, longPollID,
+		useCoinbaseValue, closeChan)
+	if err != nil {
+		return nil, err
+	}
+	defer state.Unlock()
+
+	return state.blockTemplateResult(useCoinbaseValue, submitOld)
+}
+
+// waitForStaleTemplate is a helper for the long poll requests which waits until
+// the block template identified by the passed long poll ID is stale and the
+// caller should stop working on it in favor of the current one.  Unless an
+// error is returned, it returns with the state locked and the current block
+// template up to date, along with whether or not it is valid to submit work
+// against the old block template.  That is nil when the long poll ID is
+// invalid, in which case it does not wait.
+func (state *gbtWorkState) waitForStaleTemplate(s *rpcServer, longPollID string, useCoinbaseValue bool, closeChan <-chan struct{}) (*bool, error) {
 	state.Lock()
 	// The state unlock is intentionally not deferred here since it needs to
 	// be manually unlocked before waiting for a notification about block
 	// template changes.
 
+	state.noteRequest()
 	if err := state.updateBlockTemplate(s, useCoinbaseValue); err != nil {
 		state.Unlock()
 		return nil, err
@@ -1808,14 +2590,7 @@ func handleGetBlockTemplateLongPoll(s *rpcServer, longPollID string, useCoinbase
 	// the caller is invalid.
 	prevHash, lastGenerated, err := decodeTemplateID(longPollID)
 	if err != nil {
-		result, err := state.blockTemplateResult(useCoinbaseValue, nil)
-		if err != nil {
-			state.Unlock()
-			return nil, err
-		}
-
-		state.Unlock()
-		return result, nil
+		return nil, nil
 	}
 
 	// Return the block template now if the specific block template
@@ -1829,15 +2604,7 @@ func handleGetBlockTemplateLongPoll(s *rpcServer, longPollID string, useCoinbase
 		// old block template depending on whether or not a solution has
 		// already been found and added to the block chain.
 		submitOld := prevHash.IsEqual(prevTemplateHash)
-		result, err := state.blockTemplateResult(useCoinbaseValue,
-			&submitOld)
-		if err != nil {
-			state.Unlock()
-			return nil, err
-		}
-
-		state.Unlock()
-		return result, nil
+		return &submitOld, nil
 	}
 
 	// Register the previous hash and last generated time for notifications
@@ -1845,12 +2612,16 @@ func handleGetBlockTemplateLongPoll(s *rpcServer, longPollID string, useCoinbase
 	// the provided ID is stale and a new block template should be returned to
 	// the caller.
 	longPollChan := state.templateUpdateChan(prevHash, lastGenerated)
+	state.longPolls++
 	state.Unlock()
 
 	select {
 	// When the client closes before it's time to send a reply, just return
 	// now so the goroutine doesn't hang around.
 	case <-closeChan:
+		state.Lock()
+		state.longPolls--
+		state.Unlock()
 		return nil, ErrClientQuit
 
 	// Wait until signal received to send the reply.
@@ -1860,9 +2631,10 @@ func handleGetBlockTemplateLongPoll(s *rpcServer, longPollID string, useCoinbase
 
 	// Get the lastest block template
 	state.Lock()
-	defer state.Unlock()
-
+	state.longPolls--
+	state.noteRequest()
 	if err := state.updateBlockTemplate(s, useCoinbaseValue); err != nil {
+		state.Unlock()
 		return nil, err
 	}
 
@@ -1870,12 +2642,7 @@ func handleGetBlockTemplateLongPoll(s *rpcServer, longPollID string, useCoinbase
 	// block template depending on whether or not a solution has already
 	// been found and added to the block chain.
 	submitOld := prevHash.IsEqual(&state.template.Block.Header.PrevBlock)
-	result, err := state.blockTemplateResult(useCoinbaseValue, &submitOld)
-	if err != nil {
-		return nil, err
-	}
-
-	return result, nil
+	return &submitOld, nil
 }
 
 // handleGetBlockTemplateRequest is a helper for handleGetBlockTemplate which
@@ -1951,6 +2718,7 @@ func handleGetBlockTemplateRequest(s *rpcServer, request *btcjson.TemplateReques
 	state := s.gbtWorkState
 	state.Lock()
 	defer state.Unlock()
+	state.noteRequest()
 
 	// Get and return a block template.  A new block template will be
 	// generated when the current best block has changed or the transactions
@@ -2312,6 +3080,46 @@ func handleGetBlockTemplate(s *rpcServer, cmd interface{}, closeChan <-chan stru
 	}
 }
 
+// handleGetBlockTemplateLight implements the getblocktemplatelight command.
+func handleGetBlockTemplateLight(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetBlockTemplateLightCmd)
+
+	// No point in generating work before the chain is synced.
+	currentHeight := s.cfg.Chain.BestSnapshot().Height
+	if currentHeight != 0 && !s.cfg.SyncMgr.IsCurrent() {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCClientInInitialDownload,
+			Message: "Bitcoin is downloading blocks...",
+		}
+	}
+
+	// When a long poll ID was provided, wait for the block template it
+	// identifies to be stale and reply with the differences from it.
+	// Otherwise, reply with the current block template right away.
+	state := s.gbtWorkState
+	var baseID string
+	var submitOld *bool
+	if c.LongPollID != nil && *c.LongPollID != "" {
+		baseID = *c.LongPollID
+		var err error
+		submitOld, err = state.waitForStaleTemplate(s, baseID, true,
+			closeChan)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		state.Lock()
+		state.noteRequest()
+		if err := state.updateBlockTemplate(s, true); err != nil {
+			state.Unlock()
+			return nil, err
+		}
+	}
+	defer state.Unlock()
+
+	return state.blockTemplateLightResult(baseID, submitOld)
+}
+
 // handleGetCFilter implements the getcfilter command.
 func handleGetCFilter(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	if s.cfg.CfIndex == nil {
@@ -2453,11 +3261,253 @@ func handleGetInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (in
 		Difficulty:      getDifficultyRatio(best.Bits, s.cfg.ChainParams),
 		TestNet:         cfg.TestNet3,
 		RelayFee:        cfg.minRelayTxFee.ToBTC(),
+		Errors:          s.warnings(),
+		Build:           readBuildInfo().result(cfg.BuildManifest != ""),
 	}
 
 	return ret, nil
 }
 
+// warnings returns the warnings reported by getinfo and getnetworkinfo, which
+// are the clock skew warning, the cold storage spend alarm and the low disk
+// space warning.
+func (s *rpcServer) warnings() string {
+	var warnings []string
+	if w := clockSkewWarning(s.cfg.TimeSource.Status()); w != "" {
+		warnings = append(warnings, w)
+	}
+	if s.cfg.ColdWatch != nil && s.cfg.ColdWatch.Alarm() {
+		warnings = append(warnings, coldSpendWarning)
+	}
+	if s.cfg.DiskMonitor != nil {
+		if w := s.cfg.DiskMonitor.Warning(); w != "" {
+			warnings = append(warnings, w)
+		}
+	}
+	return strings.Join(warnings, "; ")
+}
+
+// clockSkewWarning returns the warning reported by the RPC server when the
+// local clock deviates significantly from the median time of the peers.
+func clockSkewWarning(status blockchain.MedianTimeStatus) string {
+	if !status.Skewed {
+		return ""
+	}
+	return fmt.Sprintf("The local clock is %v off the median time of the "+
+		"peers, please check your date and time are correct",
+		-status.NetworkOffset)
+}
+
+// handleListeners implements the listeners command.
+func handleListeners(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.ListenersCmd)
+
+	switch c.SubCmd {
+	case btcjson.ListenerAdd, btcjson.ListenerRemove:
+		if c.Addr == nil || *c.Addr == "" {
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCInvalidParameter,
+				Message: "an address is required to add or remove a listener",
+			}
+		}
+		var err error
+		if c.SubCmd == btcjson.ListenerAdd {
+			err = s.cfg.ConnMgr.AddListener(*c.Addr)
+		} else {
+			err = s.cfg.ConnMgr.RemoveListener(*c.Addr)
+		}
+		if err != nil {
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCMisc,
+				Message: err.Error(),
+			}
+		}
+
+	case btcjson.ListenerList:
+
+	default:
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "invalid subcommand for listeners",
+		}
+	}
+
+	listeners := s.cfg.ConnMgr.Listeners()
+	result := make([]btcjson.ListenerResult, 0, len(listeners))
+	for _, l := range listeners {
+		network := "ipv4"
+		if tcpAddr, ok := l.Addr().(*net.TCPAddr); ok && tcpAddr.IP.To4() == nil {
+			network = "ipv6"
+		}
+		result = append(result, btcjson.ListenerResult{
+			Listen:      l.listenAddr,
+			Address:     l.Addr().String(),
+			Network:     network,
+			Runtime:     l.runtime,
+			Permissions: l.perms.names(),
+		})
+	}
+	return result, nil
+}
+
+// handleGetClockSkew implements the getclockskew command.
+func handleGetClockSkew(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	status := s.cfg.TimeSource.Status()
+	now := time.Now()
+	return &btcjson.GetClockSkewResult{
+		LocalTime:     now.Unix(),
+		AdjustedTime:  now.Add(status.Offset).Unix(),
+		Offset:        int64(status.Offset / time.Second),
+		NetworkOffset: int64(status.NetworkOffset / time.Second),
+		MaxOffset:     int64(status.MaxOffset / time.Second),
+		Samples:       status.Samples,
+		Outliers:      status.Outliers,
+		Clamped:       status.Clamped(),
+		Skewed:        status.Skewed,
+		Warning:       clockSkewWarning(status),
+	}, nil
+}
+
+// handleGetPartitionStatus implements the getpartitionstatus command.
+func handleGetPartitionStatus(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	if s.cfg.Partitions != nil {
+		return s.cfg.Partitions.Status(), nil
+	}
+
+	// Only the tip is reported when the detector is disabled.
+	best := s.cfg.Chain.BestSnapshot()
+	_, tipWork, _ := s.cfg.Chain.BlockWork(&best.Hash)
+	return &btcjson.GetPartitionStatusResult{
+		TipHash:   best.Hash.String(),
+		TipHeight: best.Height,
+		TipWork:   fmt.Sprintf("%064x", tipWork),
+	}, nil
+}
+
+// handleGetColdSpends implements the getcoldspends command.
+func handleGetColdSpends(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetColdSpendsCmd)
+	if s.cfg.ColdWatch == nil {
+		return &btcjson.GetColdSpendsResult{
+			Spends: []btcjson.ColdSpendResult{},
+		}, nil
+	}
+	return s.cfg.ColdWatch.Status(c.Acknowledge != nil && *c.Acknowledge), nil
+}
+
+// handleGetDiskStatus implements the getdiskstatus command.
+func handleGetDiskStatus(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	if s.cfg.DiskMonitor == nil {
+		return &btcjson.GetDiskStatusResult{
+			Status:   diskSpaceOK,
+			SafeMode: s.cfg.Chain.SafeMode(),
+		}, nil
+	}
+	return s.cfg.DiskMonitor.Status(), nil
+}
+
+// handleGetMemoryInfo implements the getmemoryinfo command.
+func handleGetMemoryInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+
+	var lastGC int64
+	if stats.LastGC != 0 {
+		lastGC = time.Unix(0, int64(stats.LastGC)).Unix()
+	}
+	return &btcjson.GetMemoryInfoResult{
+		Runtime: btcjson.RuntimeMemoryStats{
+			Alloc:        stats.Alloc,
+			TotalAlloc:   stats.TotalAlloc,
+			Sys:          stats.Sys,
+			Mallocs:      stats.Mallocs,
+			Frees:        stats.Frees,
+			HeapAlloc:    stats.HeapAlloc,
+			HeapSys:      stats.HeapSys,
+			HeapIdle:     stats.HeapIdle,
+			HeapInuse:    stats.HeapInuse,
+			HeapReleased: stats.HeapReleased,
+			HeapObjects:  stats.HeapObjects,
+			StackSys:     stats.StackSys,
+			NumGC:        stats.NumGC,
+			LastGC:       lastGC,
+			PauseTotalNs: stats.PauseTotalNs,
+			Goroutines:   runtime.NumGoroutine(),
+		},
+		Subsystems: subsystemMemoryUsage(s),
+	}, nil
+}
+
+// subsystemMemoryUsage returns the estimated memory used by the caches and
+// pools of the node.  The sizes of the signature and hash caches are estimated
+// from their number of entries.
+func subsystemMemoryUsage(s *rpcServer) []btcjson.SubsystemMemoryUsage {
+	var usage []btcjson.SubsystemMemoryUsage
+	if s.cfg.TxMemPool != nil {
+		txDescs := s.cfg.TxMemPool.TxDescs()
+		var numBytes int64
+		for _, txD := range txDescs {
+			numBytes += int64(txD.Tx.MsgTx().SerializeSize())
+		}
+		usage = append(usage, btcjson.SubsystemMemoryUsage{
+			Name:    "mempool",
+			Entries: int64(len(txDescs)),
+			Bytes:   numBytes,
+		})
+	}
+	if sizer, ok := s.cfg.DB.(database.CacheSizer); ok {
+		entries, numBytes := sizer.CacheSize()
+		usage = append(usage, btcjson.SubsystemMemoryUsage{
+			Name:    "dbcache",
+			Entries: int64(entries),
+			Bytes:   int64(numBytes),
+		})
+	}
+	if s.cfg.SigCache != nil {
+		entries := int64(s.cfg.SigCache.Len())
+		usage = append(usage, btcjson.SubsystemMemoryUsage{
+			Name:    "sigcache",
+			Entries: entries,
+			Bytes:   entries * sigCacheEntryBytes,
+		})
+	}
+	if s.cfg.HashCache != nil {
+		entries := int64(s.cfg.HashCache.Len())
+		usage = append(usage, btcjson.SubsystemMemoryUsage{
+			Name:    "hashcache",
+			Entries: entries,
+			Bytes:   entries * hashCacheEntryBytes,
+		})
+	}
+	if s.cfg.ServedBlocks != nil {
+		entries, numBytes := s.cfg.ServedBlocks.Size()
+		usage = append(usage, btcjson.SubsystemMemoryUsage{
+			Name:    "servedblocks",
+			Entries: int64(entries),
+			Bytes:   numBytes,
+		})
+	}
+	return usage
+}
+
+// handleGetMempoolEntry implements the getmempoolentry command.
+func handleGetMempoolEntry(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetMempoolEntryCmd)
+
+	txHash, err := chainhash.NewHashFromStr(c.TxID)
+	if err != nil {
+		return nil, rpcDecodeHexError(c.TxID)
+	}
+	entry, err := s.cfg.TxMemPool.MempoolEntry(txHash)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCNoTxInfo,
+			Message: "Transaction not in mempool",
+		}
+	}
+	return entry, nil
+}
+
 // handleGetMempoolInfo implements the getmempoolinfo command.
 func handleGetMempoolInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	mempoolTxns := s.cfg.TxMemPool.TxDescs()
@@ -2475,6 +3525,43 @@ func handleGetMempoolInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct
 	return ret, nil
 }
 
+// handleGetDataCarrierInfo implements the getdatacarrierinfo command.
+func handleGetDataCarrierInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	stats := s.cfg.TxMemPool.DataCarrierStats()
+	result := &btcjson.GetDataCarrierInfoResult{
+		Relay:        !cfg.NoDataCarrier,
+		MaxSize:      cfg.DataCarrierSize,
+		Rejected:     stats.Rejected,
+		MempoolBytes: stats.PooledBytes,
+		Classes: make([]btcjson.DataCarrierClassResult, 0,
+			mempool.NumDataCarrierSizeClasses),
+	}
+	result.BlockMaxWeight = s.cfg.Generator.Policy().BlockMaxDataCarrierWeight
+
+	min := 0
+	for i := 0; i < mempool.NumDataCarrierSizeClasses; i++ {
+		var size string
+		switch {
+		case i == len(mempool.DataCarrierSizeClasses):
+			size = fmt.Sprintf("%d+", min)
+		case min == mempool.DataCarrierSizeClasses[i]:
+			size = strconv.Itoa(min)
+		default:
+			size = fmt.Sprintf("%d-%d", min,
+				mempool.DataCarrierSizeClasses[i])
+		}
+		result.Classes = append(result.Classes, btcjson.DataCarrierClassResult{
+			Size:     size,
+			Accepted: stats.Accepted[i],
+			Mempool:  stats.Pooled[i],
+		})
+		if i < len(mempool.DataCarrierSizeClasses) {
+			min = mempool.DataCarrierSizeClasses[i] + 1
+		}
+	}
+	return result, nil
+}
+
 // handleGetMiningInfo implements the getmininginfo command. We only return the
 // fields that are not related to wallet functionality.
 func handleGetMiningInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
@@ -2507,7 +3594,10 @@ func handleGetMiningInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{
 		NetworkHashPS:      networkHashesPerSec,
 		PooledTx:           uint64(s.cfg.TxMemPool.Count()),
 		TestNet:            cfg.TestNet3,
+		Chain:              s.cfg.ChainParams.Name,
 	}
+	policy := s.cfg.Generator.Policy()
+	result.BlockLimits = blockLimitsResult(&policy)
 	return &result, nil
 }
 
@@ -2587,6 +3677,86 @@ func handleGetNetTotals(s *rpcServer, cmd interface{}, closeChan <-chan struct{}
 	return reply, nil
 }
 
+// handleGetNodeAddresses implements the getnodeaddresses command.
+func handleGetNodeAddresses(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetNodeAddressesCmd)
+
+	count := int32(1)
+	if c.Count != nil {
+		count = *c.Count
+	}
+	if count < 0 {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Address count out of range",
+		}
+	}
+
+	addrs := s.cfg.ConnMgr.NodeAddresses(int(count))
+	reply := make([]btcjson.GetNodeAddressesResult, 0, len(addrs))
+	for _, na := range addrs {
+		host, _, err := net.SplitHostPort(addrmgr.NetAddressKey(na))
+		if err != nil {
+			continue
+		}
+		reply = append(reply, btcjson.GetNodeAddressesResult{
+			Time:     na.Timestamp.Unix(),
+			Services: uint64(na.Services),
+			Address:  host,
+			Port:     na.Port,
+		})
+	}
+	return reply, nil
+}
+
+// handleGetAddrManInfo implements the getaddrmaninfo command.
+func handleGetAddrManInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	stats := s.cfg.ConnMgr.AddrManagerStats()
+	return &btcjson.GetAddrManInfoResult{
+		Total: stats.New + stats.Tried,
+		New: btcjson.AddrManBucketsResult{
+			Addresses:  stats.New,
+			Buckets:    stats.NewBuckets,
+			Used:       stats.NewBucketsUsed,
+			BucketSize: stats.NewBucketSize,
+			MaxBucket:  stats.NewBucketMax,
+		},
+		Tried: btcjson.AddrManBucketsResult{
+			Addresses:  stats.Tried,
+			Buckets:    stats.TriedBuckets,
+			Used:       stats.TriedBucketsUsed,
+			BucketSize: stats.TriedBucketSize,
+			MaxBucket:  stats.TriedBucketMax,
+		},
+		Bad:   stats.Bad,
+		IPv4:  stats.IPv4,
+		IPv6:  stats.IPv6,
+		Onion: stats.Onion,
+	}, nil
+}
+
+// handleAddPeerAddress implements the addpeeraddress command.
+func handleAddPeerAddress(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.AddPeerAddressCmd)
+
+	ip := net.ParseIP(c.Address)
+	if ip == nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: fmt.Sprintf("Invalid IP address: %v", c.Address),
+		}
+	}
+	tried := c.Tried != nil && *c.Tried
+
+	na := wire.NewNetAddressIPPort(ip, c.Port, 0)
+	if err := s.cfg.ConnMgr.AddPeerAddress(na, tried); err != nil {
+		rpcsLog.Debugf("Unable to add peer address %v: %v",
+			addrmgr.NetAddressKey(na), err)
+		return &btcjson.AddPeerAddressResult{Success: false}, nil
+	}
+	return &btcjson.AddPeerAddressResult{Success: true}, nil
+}
+
 // handleGetNetworkHashPS implements the getnetworkhashps command.
 func handleGetNetworkHashPS(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	// Note: All valid error return paths should return an int64.
@@ -2681,6 +3851,65 @@ func handleGetNetworkHashPS(s *rpcServer, cmd interface{}, closeChan <-chan stru
 	return hashesPerSec.Int64(), nil
 }
 
+// handleGetNetworkInfo implements the getnetworkinfo command.
+func handleGetNetworkInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	// Build the user agent the same way it is advertised to peers.
+	msg := wire.MsgVersion{UserAgent: wire.DefaultUserAgent}
+	err := msg.AddUserAgent(userAgentName, userAgentVersion,
+		cfg.UserAgentComments...)
+	if err != nil {
+		return nil, internalRPCError(err.Error(), "Could not build user agent")
+	}
+
+	onionProxy := cfg.OnionProxy
+	if onionProxy == "" {
+		onionProxy = cfg.Proxy
+	}
+	networks := []btcjson.NetworksResult{
+		{Name: "ipv4", Reachable: true, Proxy: cfg.Proxy},
+		{Name: "ipv6", Reachable: true, Proxy: cfg.Proxy},
+		{
+			Name:                      "onion",
+			Limited:                   cfg.NoOnion,
+			Reachable:                 !cfg.NoOnion && onionProxy != "",
+			Proxy:                     onionProxy,
+			ProxyRandomizeCredentials: cfg.TorIsolation,
+		},
+	}
+
+	localAddrs := s.cfg.ConnMgr.LocalAddresses()
+	addrs := make([]btcjson.LocalAddressesResult, 0, len(localAddrs))
+	for _, la := range localAddrs {
+		host, _, err := net.SplitHostPort(addrmgr.NetAddressKey(la.NetAddress))
+		if err != nil {
+			return nil, internalRPCError(err.Error(), "Invalid local address")
+		}
+		addrs = append(addrs, btcjson.LocalAddressesResult{
+			Address: host,
+			Port:    la.NetAddress.Port,
+			Score:   int32(la.Score),
+		})
+	}
+
+	relayFee := cfg.minRelayTxFee.ToBTC()
+	return &btcjson.GetNetworkInfoResult{
+		Version:         int32(1000000*appMajor + 10000*appMinor + 100*appPatch),
+		SubVersion:      msg.UserAgent,
+		ProtocolVersion: int32(maxProtocolVersion),
+		LocalServices:   fmt.Sprintf("%016x", uint64(s.cfg.ConnMgr.LocalServices())),
+		LocalRelay:      !cfg.BlocksOnly,
+		TimeOffset:      int64(s.cfg.TimeSource.Offset().Seconds()),
+		Connections:     s.cfg.ConnMgr.ConnectedCount(),
+		NetworkActive:   !cfg.Replica,
+		Networks:        networks,
+		RelayFee:        relayFee,
+		IncrementalFee:  relayFee,
+		LocalAddresses:  addrs,
+		Warnings:        s.warnings(),
+		Build:           readBuildInfo().result(cfg.BuildManifest != ""),
+	}, nil
+}
+
 func handleGetNetworkSteward(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	best := s.cfg.Chain.BestSnapshot()
 	return &btcjson.GetNetworkStewardResult{
@@ -2718,6 +3947,9 @@ func handleGetPeerInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{})
 			BanScore:       int32(p.BanScore()),
 			FeeFilter:      p.FeeFilter(),
 			SyncNode:       statsSnap.ID == syncPeerID,
+			Permissions:    p.Permissions(),
+			InvQueued:      statsSnap.InvQueued,
+			InvAnnounced:   statsSnap.InvAnnounced,
 		}
 		if p.ToPeer().LastPingNonce() != 0 {
 			wait := float64(time.Since(statsSnap.LastPingTime).Nanoseconds())
@@ -2787,7 +4019,7 @@ func handleGetRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan str
 			return nil, internalRPCError(err.Error(), context)
 		}
 		if blockRegion == nil {
-			return nil, rpcNoTxInfoError(txHash)
+			return nil, s.txIndexMissError(txHash)
 		}
 
 		// Load the raw transaction bytes from the database.
@@ -2865,9 +4097,32 @@ func handleGetRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan str
 	if err != nil {
 		return nil, err
 	}
+
+	// The fee is only reported at the highest verbosity since computing it
+	// for a confirmed transaction requires loading its block.
+	if *c.Verbose >= 2 {
+		if blkHash != nil {
+			fee, err := s.confirmedTxFee(txHash, blkHash)
+			if err != nil {
+				context := "Failed to compute transaction fee"
+				return nil, internalRPCError(err.Error(), context)
+			}
+			rawTxn.Fee = btcjson.Float64(btcutil.Amount(fee).ToBTC())
+		} else if txD, err := s.cfg.TxMemPool.FetchTxDesc(txHash); err == nil {
+			rawTxn.Fee = btcjson.Float64(btcutil.Amount(txD.Fee).ToBTC())
+		}
+	}
 	return *rawTxn, nil
 }
 
+// handleGetRPCInfo implements the getrpcinfo command.
+func handleGetRPCInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	return &btcjson.GetRPCInfoResult{
+		ActiveCommands: s.activeCommands(),
+		LogPath:        filepath.Join(cfg.LogDir, defaultLogFilename),
+	}, nil
+}
+
 // handleGetTxOut handles gettxout commands.
 func handleGetTxOut(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*btcjson.GetTxOutCmd)
@@ -2948,30 +4203,209 @@ func handleGetTxOut(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (i
 	// doesn't fully parse, so ignore the error here.
 	disbuf, _ := txscript.DisasmString(pkScript)
 
-	// Get further info about the script.
-	// Ignore the error here since an error means the script couldn't parse
-	// and there is no additional information about it anyways.
-	scriptClass, addrs, reqSigs, _ := txscript.ExtractPkScriptAddrs(pkScript,
-		s.cfg.ChainParams)
-	addresses := make([]string, len(addrs))
-	for i, addr := range addrs {
-		addresses[i] = addr.EncodeAddress()
+	// Get further info about the script.
+	// Ignore the error here since an error means the script couldn't parse
+	// and there is no additional information about it anyways.
+	scriptClass, addrs, reqSigs, _ := txscript.ExtractPkScriptAddrs(pkScript,
+		s.cfg.ChainParams)
+	addresses := make([]string, len(addrs))
+	for i, addr := range addrs {
+		addresses[i] = addr.EncodeAddress()
+	}
+
+	txOutReply := &btcjson.GetTxOutResult{
+		BestBlock:     bestBlockHash,
+		Confirmations: int64(confirmations),
+		Value:         btcutil.Amount(value).ToBTC(),
+		ScriptPubKey: btcjson.ScriptPubKeyResult{
+			Asm:       disbuf,
+			Hex:       hex.EncodeToString(pkScript),
+			ReqSigs:   int32(reqSigs),
+			Type:      scriptClass.String(),
+			Addresses: addresses,
+		},
+		Coinbase: isCoinbase,
+	}
+	return txOutReply, nil
+}
+
+// handleGetTxOutProof implements the gettxoutproof command.
+func handleGetTxOutProof(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetTxOutProofCmd)
+	if len(c.TxIDs) == 0 {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "At least one transaction id is required",
+		}
+	}
+	txHashes := make([]*chainhash.Hash, 0, len(c.TxIDs))
+	seen := make(map[chainhash.Hash]struct{}, len(c.TxIDs))
+	for _, txID := range c.TxIDs {
+		txHash, err := chainhash.NewHashFromStr(txID)
+		if err != nil {
+			return nil, rpcDecodeHexError(txID)
+		}
+		if _, ok := seen[*txHash]; ok {
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCInvalidParameter,
+				Message: "Duplicated transaction id " + txID,
+			}
+		}
+		seen[*txHash] = struct{}{}
+		txHashes = append(txHashes, txHash)
+	}
+
+	// Look up the block of the first transaction in the transaction index
+	// when no block is specified.
+	var blockHash *chainhash.Hash
+	if c.BlockHash != nil {
+		var err error
+		blockHash, err = chainhash.NewHashFromStr(*c.BlockHash)
+		if err != nil {
+			return nil, rpcDecodeHexError(*c.BlockHash)
+		}
+	} else {
+		if s.cfg.TxIndex == nil {
+			return nil, &btcjson.RPCError{
+				Code: btcjson.ErrRPCNoTxInfo,
+				Message: "The transaction index must be " +
+					"enabled to find the block of the " +
+					"transactions (specify --txindex) or " +
+					"the block hash must be specified",
+			}
+		}
+		blockRegion, err := s.cfg.TxIndex.TxBlockRegion(txHashes[0])
+		if err != nil {
+			context := "Failed to retrieve transaction location"
+			return nil, internalRPCError(err.Error(), context)
+		}
+		if blockRegion == nil {
+			return nil, s.txIndexMissError(txHashes[0])
+		}
+		blockHash = blockRegion.Hash
+	}
+
+	block, err := s.cfg.Chain.BlockByHash(blockHash)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCBlockNotFound,
+			Message: "Block not found in the main chain",
+		}
+	}
+	proof, err := merkle.NewMerkleBlock(block, txHashes)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidAddressOrKey,
+			Message: err.Error(),
+		}
+	}
+	serialized, err := merkle.Serialize(proof)
+	if err != nil {
+		context := "Failed to serialize merkle proof"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	return hex.EncodeToString(serialized), nil
+}
+
+// utxoClassStatsResult converts the statistics of the unspent outputs of a
+// script class to the form returned by the getutxostats command.  Only the
+// non-empty buckets of the histogram are returned.
+func utxoClassStatsResult(class string, stats *indexers.UtxoClassStats) btcjson.UtxoClassStatsResult {
+	result := btcjson.UtxoClassStatsResult{
+		Class:      class,
+		Count:      stats.Count,
+		Amount:     btcutil.Amount(stats.Value).ToBTC(),
+		DustCount:  stats.DustCount,
+		DustAmount: btcutil.Amount(stats.DustValue).ToBTC(),
+		Histogram:  []btcjson.UtxoHistogramBucket{},
+	}
+	var minValue int64
+	for i, count := range stats.Histogram {
+		maxValue := minValue*10 - 1
+		if i == 0 {
+			maxValue = 0
+		} else if i == len(stats.Histogram)-1 {
+			maxValue = math.MaxInt64
+		}
+		if count > 0 {
+			result.Histogram = append(result.Histogram,
+				btcjson.UtxoHistogramBucket{
+					MinValue: minValue,
+					MaxValue: maxValue,
+					Count:    count,
+				})
+		}
+		if minValue == 0 {
+			minValue = 1
+		} else {
+			minValue *= 10
+		}
+	}
+	return result
+}
+
+// handleGetUtxoStats implements the getutxostats command.
+func handleGetUtxoStats(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	if s.cfg.UtxoStatsIndex == nil {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCNoUtxoStatsIndex,
+			Message: "The utxo statistics index must be enabled " +
+				"for this command (specify --utxostatsindex)",
+		}
+	}
+
+	c := cmd.(*btcjson.GetUtxoStatsCmd)
+	height := int32(-1)
+	if c.Height != nil {
+		height = *c.Height
+	}
+	stats, err := s.cfg.UtxoStatsIndex.UtxoStats(height)
+	if err != nil {
+		context := "Failed to load utxo statistics"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	if stats == nil {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCOutOfRange,
+			Message: fmt.Sprintf("No utxo statistics snapshot "+
+				"at or below height %d", height),
+		}
+	}
+	hash, err := s.cfg.Chain.BlockHashByHeight(stats.Height)
+	if err != nil {
+		context := "Failed to fetch block hash"
+		return nil, internalRPCError(err.Error(), context)
 	}
 
-	txOutReply := &btcjson.GetTxOutResult{
-		BestBlock:     bestBlockHash,
-		Confirmations: int64(confirmations),
-		Value:         btcutil.Amount(value).ToBTC(),
-		ScriptPubKey: btcjson.ScriptPubKeyResult{
-			Asm:       disbuf,
-			Hex:       hex.EncodeToString(pkScript),
-			ReqSigs:   int32(reqSigs),
-			Type:      scriptClass.String(),
-			Addresses: addresses,
-		},
-		Coinbase: isCoinbase,
+	classes := make([]txscript.ScriptClass, 0, len(stats.Classes))
+	for class := range stats.Classes {
+		classes = append(classes, class)
 	}
-	return txOutReply, nil
+	sort.Slice(classes, func(i, j int) bool {
+		return classes[i] < classes[j]
+	})
+
+	var total indexers.UtxoClassStats
+	result := &btcjson.GetUtxoStatsResult{
+		Height:       stats.Height,
+		Hash:         hash.String(),
+		DustRelayFee: indexers.UtxoStatsDustRelayFee.ToBTC(),
+		Classes:      make([]btcjson.UtxoClassStatsResult, 0, len(classes)),
+	}
+	for _, class := range classes {
+		classStats := stats.Classes[class]
+		total.Count += classStats.Count
+		total.Value += classStats.Value
+		total.DustCount += classStats.DustCount
+		total.DustValue += classStats.DustValue
+		for i, count := range classStats.Histogram {
+			total.Histogram[i] += count
+		}
+		result.Classes = append(result.Classes,
+			utxoClassStatsResult(class.String(), classStats))
+	}
+	result.Total = utxoClassStatsResult("total", &total)
+	return result, nil
 }
 
 // handleHelp implements the help command.
@@ -3070,7 +4504,7 @@ func fetchInputTxos(s *rpcServer, tx *wire.MsgTx) (map[wire.OutPoint]wire.TxOut,
 			return nil, internalRPCError(err.Error(), context)
 		}
 		if blockRegion == nil {
-			return nil, rpcNoTxInfoError(&origin.Hash)
+			return nil, s.txIndexMissError(&origin.Hash)
 		}
 
 		// Load the raw transaction bytes from the database.
@@ -3534,6 +4968,7 @@ func handleSendRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan st
 
 	// Use 0 for the tag to represent local node.
 	tx := btcutil.NewTx(&msgTx)
+	s.cfg.TxMemPool.NoteSubmission(tx.Hash())
 	acceptedTxs, err := s.cfg.TxMemPool.ProcessTransaction(tx, false, false, 0)
 	if err != nil {
 		// When the error is a rule error, it means the transaction was
@@ -3588,7 +5023,8 @@ func handleSendRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan st
 	// Also, since an error is being returned to the caller, ensure the
 	// transaction is removed from the memory pool.
 	if len(acceptedTxs) == 0 || !acceptedTxs[0].Tx.Hash().IsEqual(tx.Hash()) {
-		s.cfg.TxMemPool.RemoveTransaction(tx, true)
+		s.cfg.TxMemPool.RemoveTransaction(tx, true,
+			mempool.RemovalEvicted)
 
 		errStr := fmt.Sprintf("transaction %v is not in accepted list",
 			tx.Hash())
@@ -3603,6 +5039,12 @@ func handleSendRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan st
 	// Notify both websocket and getblocktemplate long poll clients of all
 	// newly accepted transactions.
 	s.NotifyNewTransactions(acceptedTxs)
+	if s.cfg.Webhooks != nil {
+		s.cfg.Webhooks.NotifyNewTransactions(acceptedTxs)
+	}
+	if s.cfg.ColdWatch != nil {
+		s.cfg.ColdWatch.NotifyNewTransactions(acceptedTxs)
+	}
 
 	// Keep track of all the sendrawtransaction request txns so that they
 	// can be rebroadcast if they don't make their way into a block.
@@ -3613,6 +5055,84 @@ func handleSendRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan st
 	return tx.Hash().String(), nil
 }
 
+// blockLimitsResult returns the block assembly limits of the passed mining
+// policy.
+func blockLimitsResult(policy *mining.Policy) *btcjson.BlockLimitsResult {
+	return &btcjson.BlockLimitsResult{
+		MaxWeight:            policy.BlockMaxWeight,
+		MaxSigOpCost:         policy.BlockMaxSigOpCost,
+		ReservedWeight:       policy.BlockReservedWeight,
+		PrioritySize:         policy.BlockPrioritySize,
+		MaxDataCarrierWeight: policy.BlockMaxDataCarrierWeight,
+	}
+}
+
+// handleSetBlockLimits implements the setblocklimits command.
+func handleSetBlockLimits(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.SetBlockLimitsCmd)
+
+	invalidParameter := func(format string, args ...interface{}) error {
+		return &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: fmt.Sprintf(format, args...),
+		}
+	}
+
+	limits := c.Limits
+	if limits == nil {
+		limits = &btcjson.BlockLimits{}
+	}
+	policy := s.cfg.Generator.Policy()
+	if limits.MaxWeight != nil {
+		if *limits.MaxWeight < blockMaxWeightMin ||
+			*limits.MaxWeight > blockMaxWeightMax {
+
+			return nil, invalidParameter("maxweight must be in "+
+				"between %d and %d", blockMaxWeightMin,
+				blockMaxWeightMax)
+		}
+		policy.BlockMaxWeight = *limits.MaxWeight
+		policy.BlockMinWeight = minUint32(policy.BlockMinWeight,
+			policy.BlockMaxWeight)
+		policy.BlockMaxDataCarrierWeight = uint32(
+			float64(policy.BlockMaxWeight) * cfg.DataCarrierShare / 100)
+	}
+	if limits.MaxSigOpCost != nil {
+		if *limits.MaxSigOpCost < 1 ||
+			*limits.MaxSigOpCost > blockchain.MaxBlockSigOpsCost {
+
+			return nil, invalidParameter("maxsigopcost must be in "+
+				"between 1 and %d", blockchain.MaxBlockSigOpsCost)
+		}
+		policy.BlockMaxSigOpCost = *limits.MaxSigOpCost
+	}
+	if limits.ReservedWeight != nil {
+		policy.BlockReservedWeight = *limits.ReservedWeight
+	}
+	if limits.PrioritySize != nil {
+		policy.BlockPrioritySize = *limits.PrioritySize
+	}
+
+	// The reserved weight and the priority size are parts of the block.
+	if policy.BlockReservedWeight > policy.BlockMaxWeight {
+		return nil, invalidParameter("reservedweight %d is more than "+
+			"the max block weight %d", policy.BlockReservedWeight,
+			policy.BlockMaxWeight)
+	}
+	if policy.BlockPrioritySize > policy.BlockMaxWeight {
+		return nil, invalidParameter("prioritysize %d is more than "+
+			"the max block weight %d", policy.BlockPrioritySize,
+			policy.BlockMaxWeight)
+	}
+
+	s.cfg.Generator.SetPolicy(policy)
+	rpcsLog.Infof("Block limits changed via setblocklimits: max weight "+
+		"%d, max sigop cost %d, reserved weight %d, priority size %d",
+		policy.BlockMaxWeight, policy.BlockMaxSigOpCost,
+		policy.BlockReservedWeight, policy.BlockPrioritySize)
+	return blockLimitsResult(&policy), nil
+}
+
 // handleSetGenerate implements the setgenerate command.
 func handleSetGenerate(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*btcjson.SetGenerateCmd)
@@ -3649,6 +5169,32 @@ func handleSetGenerate(s *rpcServer, cmd interface{}, closeChan <-chan struct{})
 	return nil, nil
 }
 
+// handleSimulateReorg implements the simulatereorg command.
+func handleSimulateReorg(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.SimulateReorgCmd)
+
+	g, addr, err := newRPCForkGenerator(s, c.Address)
+	if err != nil {
+		return nil, err
+	}
+	numBlocks := c.Depth + 1
+	if c.NumBlocks != nil {
+		numBlocks = *c.NumBlocks
+	}
+
+	result, err := g.simulateReorg(c.Depth, numBlocks, addr)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: err.Error(),
+		}
+	}
+
+	rpcsLog.Infof("Reorganized the chain from block %s to block %s via "+
+		"simulatereorg", result.OldTip, result.NewTip)
+	return result, nil
+}
+
 // handleStop implements the stop command.
 func handleStop(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	select {
@@ -3680,15 +5226,122 @@ func handleSubmitBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{})
 		}
 	}
 
-	// Process this block using the same rules as blocks coming from other
-	// nodes.  This will in turn relay it to the network like normal.
-	_, err = s.cfg.SyncMgr.SubmitBlock(block, blockchain.BFNone)
+	// Blocks which are already known are classified rather than processed
+	// again.
+	chain := s.cfg.Chain
+	hash := block.Hash()
+	result := &btcjson.SubmitBlockResult{Hash: hash.String()}
+	known, err := chain.HaveBlock(hash)
 	if err != nil {
-		return fmt.Sprintf("rejected: %s", err.Error()), nil
+		context := "Failed to look up block"
+		return nil, internalRPCError(err.Error(), context)
 	}
+	if known {
+		result.Status = knownBlockStatus(chain, hash)
+	} else {
+		// Process this block using the same rules as blocks coming from
+		// other nodes.  This will in turn relay it to the network like
+		// normal.
+		isOrphan, err := s.cfg.SyncMgr.SubmitBlock(block, blockchain.BFNone)
+		switch {
+		case err != nil:
+			setSubmitBlockRejection(result, err)
+			if result.Reason == btcjson.SubmitBlockDuplicate {
+				result.Status = knownBlockStatus(chain, hash)
+			}
 
-	rpcsLog.Infof("Accepted block %s via submitblock", block.Hash())
-	return nil, nil
+		// Orphans and side chain blocks are not fully validated.
+		case isOrphan || !chain.MainChainHasBlock(hash):
+			result.Status = btcjson.SubmitBlockInconclusive
+
+		default:
+			result.Status = btcjson.SubmitBlockAccepted
+		}
+		if err == nil {
+			rpcsLog.Infof("Accepted block %s via submitblock", hash)
+		}
+	}
+
+	if c.Options != nil && c.Options.Verbose {
+		return result, nil
+	}
+	switch result.Status {
+	case btcjson.SubmitBlockAccepted:
+		return nil, nil
+	case btcjson.SubmitBlockRejected:
+		return fmt.Sprintf("rejected: %s", result.Message), nil
+	}
+	return result.Status, nil
+}
+
+// knownBlockStatus returns the submitblock status of the known block with the
+// passed hash.
+func knownBlockStatus(chain *blockchain.BlockChain, hash *chainhash.Hash) string {
+	if chain.IsKnownOrphan(hash) {
+		return btcjson.SubmitBlockDuplicateInconclusive
+	}
+	knownValid, knownInvalid := chain.BlockValidity(hash)
+	switch {
+	case knownInvalid:
+		return btcjson.SubmitBlockDuplicateInvalid
+	case knownValid:
+		return btcjson.SubmitBlockDuplicate
+	}
+	return btcjson.SubmitBlockDuplicateInconclusive
+}
+
+// setSubmitBlockRejection sets the result of submitblock to the rejection of
+// the block with the passed error, along with the details of the violated rule
+// when they are known.
+func setSubmitBlockRejection(result *btcjson.SubmitBlockResult, err error) {
+	result.Status = btcjson.SubmitBlockRejected
+	result.Message = err.Error()
+	ruleErr, ok := err.(blockchain.RuleError)
+	if !ok {
+		return
+	}
+	result.Rule = ruleErr.ErrorCode.String()
+	if reason := chainErrToGBTErrString(err); !strings.HasPrefix(reason, "rejected") {
+		result.Reason = reason
+	}
+	details := ruleErr.Details
+	if details == nil {
+		return
+	}
+	index := func(i int) *int {
+		if i < 0 {
+			return nil
+		}
+		return &i
+	}
+	result.TxIndex = index(details.TxIndex)
+	result.InputIndex = index(details.InputIndex)
+	result.AnnIndex = index(details.AnnIndex)
+	result.Expected = details.Expected
+	result.Actual = details.Actual
+}
+
+// handleTriggerGC implements the triggergc command.
+func handleTriggerGC(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.TriggerGCCmd)
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	if c.FreeOSMemory != nil && *c.FreeOSMemory {
+		debug.FreeOSMemory()
+	} else {
+		runtime.GC()
+	}
+	duration := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	return &btcjson.TriggerGCResult{
+		HeapAllocBefore: before.HeapAlloc,
+		HeapAllocAfter:  after.HeapAlloc,
+		HeapReleased:    after.HeapReleased,
+		DurationMs:      int64(duration / time.Millisecond),
+	}, nil
 }
 
 // handleUptime implements the uptime command.
@@ -3713,16 +5366,30 @@ func handleValidateAddress(s *rpcServer, cmd interface{}, closeChan <-chan struc
 	return result, nil
 }
 
-func verifyChain(s *rpcServer, level, depth int32) error {
+// verifyChain re-validates the most recent depth blocks of the main chain at
+// the given check level, logging progress periodically.  A depth which is not
+// positive, or is larger than the chain, verifies every block.  The
+// verification is abandoned early if the close channel is signalled.
+func verifyChain(s *rpcServer, level, depth int32, closeChan <-chan struct{}) error {
 	best := s.cfg.Chain.BestSnapshot()
 	finishHeight := best.Height - depth
-	if finishHeight < 0 {
+	if depth <= 0 || finishHeight < 0 {
 		finishHeight = 0
 	}
-	rpcsLog.Infof("Verifying chain for %d blocks at level %d",
-		best.Height-finishHeight, level)
+	total := best.Height - finishHeight
+	rpcsLog.Infof("Verifying chain for %d blocks at level %d", total, level)
 
+	var verified int32
+	lastLogTime := time.Now()
 	for height := best.Height; height > finishHeight; height-- {
+		select {
+		case <-closeChan:
+			rpcsLog.Infof("Chain verify aborted after %d of %d blocks",
+				verified, total)
+			return ErrClientQuit
+		default:
+		}
+
 		// Level 0 just looks up the block.
 		block, err := s.cfg.Chain.BlockByHeight(height)
 		if err != nil {
@@ -3742,6 +5409,36 @@ func verifyChain(s *rpcServer, level, depth int32) error {
 				return err
 			}
 		}
+
+		// Level 2 checks the block in the context of its parent.
+		if level > 1 {
+			err := s.cfg.Chain.CheckBlockContext(block)
+			if err != nil {
+				rpcsLog.Errorf("Verify found block at hash %v "+
+					"height %d does not fit the chain: %v",
+					block.Hash(), height, err)
+				return err
+			}
+		}
+
+		// Level 3 re-validates the PacketCrypt proof.
+		if level > 2 {
+			err := s.cfg.Chain.CheckPacketCryptProof(block)
+			if err != nil {
+				rpcsLog.Errorf("Verify is unable to validate "+
+					"PacketCrypt proof of block at hash %v "+
+					"height %d: %v", block.Hash(), height, err)
+				return err
+			}
+		}
+
+		verified++
+		if now := time.Now(); now.Sub(lastLogTime) >= 10*time.Second {
+			rpcsLog.Infof("Verified %d of %d blocks (height %d, %s)",
+				verified, total, height,
+				block.MsgBlock().Header.Timestamp)
+			lastLogTime = now
+		}
 	}
 	rpcsLog.Infof("Chain verify completed successfully")
 
@@ -3760,7 +5457,10 @@ func handleVerifyChain(s *rpcServer, cmd interface{}, closeChan <-chan struct{})
 		checkDepth = *c.CheckDepth
 	}
 
-	err := verifyChain(s, checkLevel, checkDepth)
+	err := verifyChain(s, checkLevel, checkDepth, closeChan)
+	if err == ErrClientQuit {
+		return nil, err
+	}
 	return err == nil, nil
 }
 
@@ -3827,6 +5527,41 @@ func handleVerifyMessage(s *rpcServer, cmd interface{}, closeChan <-chan struct{
 	return address.EncodeAddress() == c.Address, nil
 }
 
+// handleVerifyTxOutProof implements the verifytxoutproof command.
+func handleVerifyTxOutProof(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.VerifyTxOutProofCmd)
+	serialized, err := hex.DecodeString(c.Proof)
+	if err != nil {
+		return nil, rpcDecodeHexError(c.Proof)
+	}
+	proof, err := merkle.Deserialize(serialized)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCDeserialization,
+			Message: "Proof decode failed: " + err.Error(),
+		}
+	}
+
+	// A proof which does not commit to its header proves nothing.
+	txHashes, _, err := merkle.ExtractMatches(proof)
+	if err != nil {
+		return []string{}, nil
+	}
+	blockHash := proof.Header.BlockHash()
+	if !s.cfg.Chain.MainChainHasBlock(&blockHash) {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCBlockNotFound,
+			Message: "Block not found in the main chain",
+		}
+	}
+
+	txIDs := make([]string, 0, len(txHashes))
+	for _, txHash := range txHashes {
+		txIDs = append(txIDs, txHash.String())
+	}
+	return txIDs, nil
+}
+
 // handleVersion implements the version command.
 //
 // NOTE: This is a btcsuite extension ported from github.com/decred/dcrd.
@@ -3842,6 +5577,22 @@ func handleVersion(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (in
 	return result, nil
 }
 
+// handleWaitForBlockHeight implements the waitforblockheight command.
+func handleWaitForBlockHeight(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.WaitForBlockHeightCmd)
+	return s.waitForTip(c.Timeout, func(prev, best *blockchain.BestState) bool {
+		return best.Height >= c.Height
+	}, closeChan)
+}
+
+// handleWaitForNewBlock implements the waitfornewblock command.
+func handleWaitForNewBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.WaitForNewBlockCmd)
+	return s.waitForTip(c.Timeout, func(prev, best *blockchain.BestState) bool {
+		return best.Hash != prev.Hash
+	}, closeChan)
+}
+
 // rpcServer provides a concurrent safe RPC server to a chain server.
 type rpcServer struct {
 	started                int32
@@ -3855,7 +5606,16 @@ type rpcServer struct {
 	statusLock             sync.RWMutex
 	wg                     sync.WaitGroup
 	gbtWorkState           *gbtWorkState
+	gbtTemplateMgr         *gbtTemplateManager
 	helpCacher             *helpCacher
+	auditLog               *rpcAuditLog
+	tokenAuth              *rpcTokenAuth
+	txFees                 *txFeeCache
+	activeCmds             map[uint64]activeRPCCmd
+	activeCmdsLock         sync.Mutex
+	nextActiveCmd          uint64
+	tipChanged             chan struct{}
+	tipLock                sync.Mutex
 	requestProcessShutdown chan struct{}
 	quit                   chan int
 }
@@ -3933,6 +5693,14 @@ func (s *rpcServer) Stop() error {
 	s.ntfnMgr.WaitForShutdown()
 	close(s.quit)
 	s.wg.Wait()
+	if s.cfg.ACME != nil {
+		s.cfg.ACME.Stop()
+	}
+	if s.auditLog != nil {
+		if err := s.auditLog.Close(); err != nil {
+			rpcsLog.Errorf("Unable to close RPC audit log: %v", err)
+		}
+	}
 	rpcsLog.Infof("RPC server shutdown complete")
 	return nil
 }
@@ -3950,7 +5718,8 @@ func (s *rpcServer) RequestedProcessShutdown() <-chan struct{} {
 func (s *rpcServer) NotifyNewTransactions(txns []*mempool.TxDesc) {
 	for _, txD := range txns {
 		// Notify websocket clients about mempool transactions.
-		s.ntfnMgr.NotifyMempoolTx(txD.Tx, true)
+		s.ntfnMgr.NotifyMempoolTx(txD.Tx,
+			s.cfg.TxMemPool.TxProvenance(txD.Tx.Hash()), true)
 
 		// Potentially notify any getblocktemplate long poll clients
 		// about stale block templates due to the new transaction.
@@ -3995,24 +5764,56 @@ func (s *rpcServer) decrementClients() {
 // checkAuth checks the HTTP Basic authentication supplied by a wallet
 // or RPC client in the HTTP request r.  If the supplied authentication
 // does not match the username and password expected, a non-nil error is
-// returned.
+// returned.  A valid client certificate, or a connection to a Unix domain
+// socket or named pipe listener, authenticates the request without a username
+// and password.
 //
 // This check is time-constant.
 //
 // The first bool return value signifies auth success (true if successful) and
 // the second bool return value specifies whether the user can change the state
 // of the server (true) or whether the user is limited (false). The second is
-// always false if the first is.
-func (s *rpcServer) checkAuth(r *http.Request, require bool) (bool, bool, error) {
+// always false if the first is.  The grant is only returned when the request
+// was authenticated by an RPC token, whose caveats further restrict the
+// commands it may run.
+func (s *rpcServer) checkAuth(r *http.Request, require bool) (bool, bool, *rpcTokenGrant, error) {
+	// The permissions of the Unix domain sockets and named pipes
+	// authenticate their connections.
+	localAddr := r.Context().Value(http.LocalAddrContextKey)
+	if addr, ok := localAddr.(*rpcLocalAddr); ok {
+		return true, addr.admin, nil, nil
+	}
+
+	// A valid client certificate authenticates the connection.
+	if s.cfg.ClientCAs != nil && r.TLS != nil {
+		isAdmin, err := s.cfg.ClientCAs.Authenticate(r.TLS)
+		if err == nil {
+			return true, isAdmin, nil, nil
+		}
+	}
+
 	authhdr := r.Header["Authorization"]
 	if len(authhdr) <= 0 {
 		if require {
 			rpcsLog.Warnf("RPC authentication failure from %s",
 				r.RemoteAddr)
-			return false, false, errors.New("auth failure")
+			return false, false, nil, errors.New("auth failure")
 		}
 
-		return false, false, nil
+		return false, false, nil, nil
+	}
+
+	// A valid RPC token authenticates the request with the access its
+	// caveats allow.
+	if s.tokenAuth != nil && strings.HasPrefix(authhdr[0], "Bearer ") {
+		encoded := strings.TrimPrefix(authhdr[0], "Bearer ")
+		grant, err := s.tokenAuth.verify(encoded, time.Now())
+		if err != nil {
+			rpcsLog.Warnf("RPC authentication failure from %s: %v",
+				r.RemoteAddr, err)
+			return false, false, nil, errors.New("auth failure")
+		}
+		return true, grant.isAdmin(), grant, nil
 	}
 
 	authsha := sha256.Sum256([]byte(authhdr[0]))
@@ -4021,18 +5822,18 @@ func (s *rpcServer) checkAuth(r *http.Request, require bool) (bool, bool, error)
 	// are probably expected to have a higher volume of calls
 	limitcmp := subtle.ConstantTimeCompare(authsha[:], s.limitauthsha[:])
 	if limitcmp == 1 {
-		return true, false, nil
+		return true, false, nil, nil
 	}
 
 	// Check for admin-level auth
 	cmp := subtle.ConstantTimeCompare(authsha[:], s.authsha[:])
 	if cmp == 1 {
-		return true, true, nil
+		return true, true, nil, nil
 	}
 
 	// Request's auth doesn't match either user
 	rpcsLog.Warnf("RPC authentication failure from %s", r.RemoteAddr)
-	return false, false, errors.New("auth failure")
+	return false, false, nil, errors.New("auth failure")
 }
 
 // parsedRPCCmd represents a JSON-RPC request object that has been parsed into
@@ -4050,6 +5851,11 @@ type parsedRPCCmd struct {
 // commands which are not recognized or not implemented will return an error
 // suitable for use in replies.
 func (s *rpcServer) standardCmdResult(cmd *parsedRPCCmd, closeChan <-chan struct{}) (interface{}, error) {
+	if cfg.Replica {
+		if _, ok := rpcReplicaUnsupported[cmd.method]; ok {
+			return handleReplicaUnsupported(s, cmd.cmd, closeChan)
+		}
+	}
 	handler, ok := rpcHandlers[cmd.method]
 	if ok {
 		goto handled
@@ -4067,9 +5873,88 @@ func (s *rpcServer) standardCmdResult(cmd *parsedRPCCmd, closeChan <-chan struct
 	return nil, btcjson.ErrRPCMethodNotFound
 handled:
 
+	id := s.startCmd(cmd.method)
+	defer s.finishCmd(id)
 	return handler(s, cmd.cmd, closeChan)
 }
 
+// activeRPCCmd describes a command which is being executed by the server.
+type activeRPCCmd struct {
+	method string
+	start  time.Time
+}
+
+// startCmd records the passed method as being executed and returns the
+// identifier to pass to finishCmd once it completes.
+func (s *rpcServer) startCmd(method string) uint64 {
+	s.activeCmdsLock.Lock()
+	defer s.activeCmdsLock.Unlock()
+
+	if s.activeCmds == nil {
+		s.activeCmds = make(map[uint64]activeRPCCmd)
+	}
+	s.nextActiveCmd++
+	s.activeCmds[s.nextActiveCmd] = activeRPCCmd{
+		method: method,
+		start:  time.Now(),
+	}
+	return s.nextActiveCmd
+}
+
+// finishCmd removes the command with the passed identifier from the commands
+// being executed.
+func (s *rpcServer) finishCmd(id uint64) {
+	s.activeCmdsLock.Lock()
+	delete(s.activeCmds, id)
+	s.activeCmdsLock.Unlock()
+}
+
+// activeCommands returns the commands being executed by the server, oldest
+// first, along with how long they have been running in microseconds.
+func (s *rpcServer) activeCommands() []btcjson.RPCCommandInfo {
+	s.activeCmdsLock.Lock()
+	cmds := make([]activeRPCCmd, 0, len(s.activeCmds))
+	for _, c := range s.activeCmds {
+		cmds = append(cmds, c)
+	}
+	s.activeCmdsLock.Unlock()
+
+	sort.Slice(cmds, func(i, j int) bool {
+		return cmds[i].start.Before(cmds[j].start)
+	})
+	now := time.Now()
+	result := make([]btcjson.RPCCommandInfo, 0, len(cmds))
+	for _, c := range cmds {
+		result = append(result, btcjson.RPCCommandInfo{
+			Method:   c.method,
+			Duration: int64(now.Sub(c.start) / time.Microsecond),
+		})
+	}
+	return result
+}
+
+// auditCmd records the passed command in the audit log when the log is
+// enabled and the command is one which changes the state of the server.
+func (s *rpcServer) auditCmd(cmd *parsedRPCCmd, isAdmin bool, grant *rpcTokenGrant,
+	remoteAddr string, err error) {
+
+	if s.auditLog == nil {
+		return
+	}
+	if !isAuditedCmd(cmd) {
+		return
+	}
+
+	user := cfg.RPCLimitUser
+	switch {
+	case grant != nil:
+		user = grant.user()
+	case isAdmin:
+		user = cfg.RPCUser
+	}
+	s.auditLog.Record(user, remoteAddr, cmd.method, cmd.cmd, err)
+}
+
 // parseCmd parses a JSON-RPC request object into known concrete command.  The
 // err field of the returned parsedRPCCmd struct will contain an RPC error that
 // is suitable for use in replies if the command is invalid in some way such as
@@ -4118,7 +6003,9 @@ func createMarshalledReply(id, result interface{}, replyErr error) ([]byte, erro
 }
 
 // jsonRPCRead handles reading and responding to RPC messages.
-func (s *rpcServer) jsonRPCRead(w http.ResponseWriter, r *http.Request, isAdmin bool) {
+func (s *rpcServer) jsonRPCRead(w http.ResponseWriter, r *http.Request, isAdmin bool,
+	grant *rpcTokenGrant) {
+
 	if atomic.LoadInt32(&s.shutdown) != 0 {
 		return
 	}
@@ -4222,8 +6109,13 @@ func (s *rpcServer) jsonRPCRead(w http.ResponseWriter, r *http.Request, isAdmin
 			parsedCmd := parseCmd(&request)
 			if parsedCmd.err != nil {
 				jsonErr = parsedCmd.err
-			} else {
+			} else if grant != nil {
+				jsonErr = grant.authorize(parsedCmd, time.Now())
+			}
+			if jsonErr == nil {
 				result, jsonErr = s.standardCmdResult(parsedCmd, closeChan)
+				s.auditCmd(parsedCmd, isAdmin, grant, r.RemoteAddr,
+					jsonErr)
 			}
 		}
 	}
@@ -4285,19 +6177,19 @@ func (s *rpcServer) Start() {
 		// Keep track of the number of connected clients.
 		s.incrementClients()
 		defer s.decrementClients()
-		_, isAdmin, err := s.checkAuth(r, true)
+		_, isAdmin, grant, err := s.checkAuth(r, true)
 		if err != nil {
 			jsonAuthFail(w)
 			return
 		}
 
 		// Read and respond to the request.
-		s.jsonRPCRead(w, r, isAdmin)
+		s.jsonRPCRead(w, r, isAdmin, grant)
 	})
 
 	// Websocket endpoint.
 	rpcServeMux.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
-		authenticated, isAdmin, err := s.checkAuth(r, false)
+		authenticated, isAdmin, grant, err := s.checkAuth(r, false)
 		if err != nil {
 			jsonAuthFail(w)
 			return
@@ -4314,7 +6206,39 @@ func (s *rpcServer) Start() {
 			http.Error(w, "400 Bad Request.", http.StatusBadRequest)
 			return
 		}
-		s.WebsocketHandler(ws, r.RemoteAddr, authenticated, isAdmin)
+		s.WebsocketHandler(ws, r.RemoteAddr, authenticated, isAdmin,
+			grant)
+	})
+
+	// Server-sent events endpoint streaming the changes of the best block
+	// to clients which cannot use websockets.
+	rpcServeMux.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
+		if s.limitConnections(w, r.RemoteAddr) {
+			return
+		}
+		s.incrementClients()
+		defer s.decrementClients()
+		if _, _, _, err := s.checkAuth(r, true); err != nil {
+			jsonAuthFail(w)
+			return
+		}
+		s.handleTipEvents(w, r)
+	})
+
+	// Server-sent events endpoint streaming the blocks connected to and
+	// disconnected from the main chain with the outputs they create and
+	// spend, for the services following the chain.
+	rpcServeMux.HandleFunc("/chainevents", func(w http.ResponseWriter, r *http.Request) {
+		if s.limitConnections(w, r.RemoteAddr) {
+			return
+		}
+		s.incrementClients()
+		defer s.decrementClients()
+		if _, _, _, err := s.checkAuth(r, true); err != nil {
+			jsonAuthFail(w)
+			return
+		}
+		s.handleChainEvents(w, r)
 	})
 
 	for _, listener := range s.cfg.Listeners {
@@ -4328,6 +6252,10 @@ func (s *rpcServer) Start() {
 	}
 
 	s.ntfnMgr.Start()
+	s.gbtTemplateMgr.Start()
+	if s.cfg.ACME != nil {
+		s.cfg.ACME.Start()
+	}
 }
 
 // genCertPair generates a key/cert pair to the paths provided.
@@ -4373,6 +6301,10 @@ type rpcserverPeer interface {
 	// FeeFilter returns the requested current minimum fee rate for which
 	// transactions should be announced.
 	FeeFilter() int64
+
+	// Permissions returns the names of the permissions granted to the
+	// peer.
+	Permissions() []string
 }
 
 // rpcserverConnManager represents a connection manager for use with the RPC
@@ -4434,6 +6366,36 @@ type rpcserverConnManager interface {
 	// RelayTransactions generates and relays inventory vectors for all of
 	// the passed transactions to all connected peers.
 	RelayTransactions(txns []*mempool.TxDesc)
+
+	// NodeAddresses returns up to count randomly chosen addresses known
+	// to the address manager which are not considered bad, or all of them
+	// when count is zero.
+	NodeAddresses(count int) []*wire.NetAddress
+
+	// AddrManagerStats returns statistics about the addresses known to
+	// the address manager.
+	AddrManagerStats() *addrmgr.Stats
+
+	// AddPeerAddress adds the passed address to the address manager,
+	// marking it good so it is moved to a tried bucket when tried is set.
+	AddPeerAddress(na *wire.NetAddress, tried bool) error
+
+	// LocalAddresses returns the local addresses advertised to peers.
+	LocalAddresses() []addrmgr.LocalAddress
+
+	// LocalServices returns the services advertised to peers.
+	LocalServices() wire.ServiceFlag
+
+	// AddListener starts listening for peer connections at the passed
+	// listen address, which may be a wildcard or name a network interface.
+	AddListener(listenAddr string) error
+
+	// RemoveListener stops listening for peer connections at the passed
+	// listen address or bound address.
+	RemoveListener(addr string) error
+
+	// Listeners returns the listeners accepting peer connections.
+	Listeners() []*p2pListener
 }
 
 // rpcserverSyncManager represents a sync manager for use with the RPC server.
@@ -4456,6 +6418,14 @@ type rpcserverSyncManager interface {
 	// used to sync from or 0 if there is none.
 	SyncPeerID() int32
 
+	// RejectedTxns returns the transactions relayed by peers which were
+	// recently rejected, most recent first.
+	RejectedTxns() []netsync.RejectedTx
+
+	// SyncStatus returns the status of the sync with the chain of the
+	// network.
+	SyncStatus() *netsync.SyncStatus
+
 	// LocateHeaders returns the headers of the blocks after the first known
 	// block in the provided locators until the provided stop hash or the
 	// current tip is reached, up to a max of wire.MaxBlockHeadersPerMsg
@@ -4504,13 +6474,54 @@ type rpcserverConfig struct {
 
 	// These fields define any optional indexes the RPC server can make use
 	// of to provide additional data when queried.
-	TxIndex   *indexers.TxIndex
-	AddrIndex *indexers.AddrIndex
-	CfIndex   *indexers.CfIndex
+	TxIndex        *indexers.TxIndex
+	AddrIndex      *indexers.AddrIndex
+	CfIndex        *indexers.CfIndex
+	UtxoStatsIndex *indexers.UtxoStatsIndex
+	FeeStatsIndex  *indexers.FeeStatsIndex
 
 	// The fee estimator keeps track of how long transactions are left in
 	// the mempool before they are mined into blocks.
 	FeeEstimator *mempool.FeeEstimator
+
+	// SigCache, HashCache and ServedBlocks are the caches whose memory use
+	// is reported by the getmemoryinfo RPC.  They may be nil.
+	SigCache     *txscript.SigCache
+	HashCache    *txscript.HashCache
+	ServedBlocks *servedBlockCache
+
+	// Webhooks sends events to the configured webhooks.  It is nil when
+	// no webhooks are configured.
+	Webhooks *webhookManager
+
+	// MsgCapture records the messages exchanged with the peers for the
+	// capturemessages RPC.  It is nil when there are no peers.
+	MsgCapture *msgCapture
+
+	// Partitions watches the chains announced by the peers for the
+	// getpartitionstatus RPC.  It is nil when the detector is disabled.
+	Partitions *partitionMonitor
+
+	// Deposits tracks the deposits to the addresses bound to reference IDs
+	// for the deposit commands.  It is nil when the tracker is disabled.
+	Deposits *depositTracker
+
+	// ColdWatch raises an alarm on spends from cold storage addresses for
+	// the getcoldspends RPC.  It is nil when no addresses are watched.
+	ColdWatch *coldWatcher
+
+	// DiskMonitor watches the free space on the data directory for the
+	// getdiskstatus RPC.  It is nil when disabled.
+	DiskMonitor *diskMonitor
+
+	// ClientCAs holds the certificate authorities whose client
+	// certificates authenticate connections.  It is nil when client
+	// certificates are not used.
+	ClientCAs *rpcClientCAs
+
+	// ACME obtains and renews the certificate of the listeners from an
+	// ACME certificate authority.  It is nil when disabled.
+	ACME *rpcACMEManager
 }
 
 // newRPCServer returns a new instance of the rpcServer struct.
@@ -4520,6 +6531,7 @@ func newRPCServer(config *rpcserverConfig) (*rpcServer, error) {
 		statusLines:            make(map[int]string),
 		gbtWorkState:           newGbtWorkState(config.TimeSource),
 		helpCacher:             newHelpCacher(),
+		txFees:                 newTxFeeCache(txFeeCacheLimit),
 		requestProcessShutdown: make(chan struct{}),
 		quit:                   make(chan int),
 	}
@@ -4533,7 +6545,35 @@ func newRPCServer(config *rpcserverConfig) (*rpcServer, error) {
 		auth := "Basic " + base64.StdEncoding.EncodeToString([]byte(login))
 		rpc.limitauthsha = sha256.Sum256([]byte(auth))
 	}
+	if cfg.RPCAuditLog {
+		var syslogWriter io.Writer
+		if cfg.RPCAuditSyslog {
+			w, err := newAuditSyslog()
+			if err != nil {
+				return nil, fmt.Errorf("unable to export RPC audit "+
+					"log to syslog: %v", err)
+			}
+			syslogWriter = w
+		}
+		auditLogPath := filepath.Join(cfg.DataDir, auditLogFilename)
+		auditLog, err := openRPCAuditLog(auditLogPath, syslogWriter)
+		if err != nil {
+			return nil, fmt.Errorf("unable to open RPC audit log: %v",
+				err)
+		}
+		rpc.auditLog = auditLog
+	}
+	if cfg.RPCTokens {
+		keyFile := filepath.Join(cfg.DataDir, rpcTokenKeyFilename)
+		tokenAuth, err := newRPCTokenAuth(keyFile, cfg.RPCRevokeTokens)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load RPC token root "+
+				"key: %v", err)
+		}
+		rpc.tokenAuth = tokenAuth
+	}
 	rpc.ntfnMgr = newWsNotificationManager(&rpc)
+	rpc.gbtTemplateMgr = newGbtTemplateManager(&rpc)
 	rpc.cfg.Chain.Subscribe(rpc.handleBlockchainNotification)
 
 	return &rpc, nil
@@ -4565,6 +6605,13 @@ func (s *rpcServer) handleBlockchainNotification(notification *blockchain.Notifi
 		// Notify registered websocket clients of incoming block.
 		s.ntfnMgr.NotifyBlockConnected(block)
 
+		// Have the block template built on top of the new best block
+		// right away.
+		s.gbtTemplateMgr.NotifyBlockConnected()
+
+		// Wake the clients waiting for the best block to change.
+		s.notifyTipChanged()
+
 	case blockchain.NTBlockDisconnected:
 		block, ok := notification.Data.(*btcutil.Block)
 		if !ok {
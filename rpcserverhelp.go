@@ -35,6 +35,41 @@ var helpDescsEnUS = map[string]string{
 	"addnode-addr":      "IP address and port of the peer to operate on",
 	"addnode-subcmd":    "'add' to add a persistent peer, 'remove' to remove a persistent peer, or 'onetry' to try a single connection to a peer",
 
+	// AddPeerAddressCmd help.
+	"addpeeraddress--synopsis":     "Adds an address to the address manager, which is where the peers to connect to are chosen from.",
+	"addpeeraddress-address":       "IP address of the peer",
+	"addpeeraddress-port":          "Port of the peer",
+	"addpeeraddress-tried":         "Mark the address as good and move it to a tried bucket",
+	"addpeeraddressresult-success": "Whether the address was added, which fails for addresses which are not routable",
+
+	// CaptureMessagesCmd help.
+	"capturemessages--synopsis": "Starts, stops or reports the capture of the P2P messages exchanged with the peers.\n" +
+		"The messages of every connection are written to a pcapng file in the capture directory, as TCP packets readable by Wireshark.\n" +
+		"Only the messages starting while the capture is running are recorded.",
+	"capturemessages-subcmd": "'start' to start the capture, or restart it with new files, 'stop' to stop it and close the files, or 'status' to only report its state",
+	"capturemessages-redact": "What to leave out of a started capture: 'peers' to replace the peer addresses with placeholders, or a message command such as 'tx' or 'addr' to record those messages with an empty payload (default: the --msgcaptureredact options)",
+
+	// CaptureMessagesResult help.
+	"capturemessagesresult-enabled":  "Whether the capture is running",
+	"capturemessagesresult-dir":      "The directory the capture files are written to",
+	"capturemessagesresult-redact":   "The redaction options of the capture",
+	"capturemessagesresult-files":    "The capture files currently open, one per connection",
+	"capturemessagesresult-messages": "The number of messages captured since the capture was started",
+	"capturemessagesresult-bytes":    "The number of bytes of messages captured since the capture was started",
+
+	// CaptureProfileCmd help.
+	"captureprofile--synopsis": "Captures a runtime profile of pktd, in the format read by go tool pprof.\n" +
+		"The profile is returned base64-encoded, or written to a file of the profiles directory of the data directory when a file name is given.",
+	"captureprofile-profile": "The profile to capture: 'cpu', or one of the runtime profiles such as 'heap', 'allocs', 'goroutine', 'block', 'mutex' or 'threadcreate'",
+	"captureprofile-seconds": "The number of seconds to record a CPU profile for, up to 600",
+	"captureprofile-file":    "The name of the file to write the profile to",
+
+	// CaptureProfileResult help.
+	"captureprofileresult-profile": "The captured profile",
+	"captureprofileresult-bytes":   "The size of the profile in bytes",
+	"captureprofileresult-file":    "The path of the file the profile was written to",
+	"captureprofileresult-data":    "The base64-encoded profile when no file was given",
+
 	// NodeCmd help.
 	"node--synopsis":     "Attempts to add or remove a peer.",
 	"node-subcmd":        "'disconnect' to remove all matching non-persistent peers, 'remove' to remove a persistent peer, or 'connect' to connect to a peer",
@@ -131,6 +166,15 @@ var helpDescsEnUS = map[string]string{
 	"generate-numblocks": "Number of blocks to generate",
 	"generate--result0":  "The hashes, in order, of blocks generated by the call",
 
+	// GenerateForkCmd help
+	"generatefork--synopsis": "Generates a set number of blocks on top of a block of the main chain (simnet or regtest only)\n" +
+		"and returns a JSON array of their hashes.\n" +
+		"The blocks only hold a coinbase transaction, and cause a chain reorganization once they have more work than the main chain.",
+	"generatefork-forkhash":  "The hash of the main chain block to build the blocks on top of",
+	"generatefork-numblocks": "Number of blocks to generate",
+	"generatefork-address":   "The address paid by the blocks (default: a --miningaddr address, or anyone when none is configured)",
+	"generatefork--result0":  "The hashes, in order, of blocks generated by the call",
+
 	// GetAddedNodeInfoResultAddr help.
 	"getaddednodeinforesultaddr-address":   "The ip address for this DNS entry",
 	"getaddednodeinforesultaddr-connected": "The connection 'direction' (inbound/outbound/false)",
@@ -148,6 +192,24 @@ var helpDescsEnUS = map[string]string{
 	"getaddednodeinfo--condition1": "dns=true",
 	"getaddednodeinfo--result0":    "List of added peers",
 
+	// GetAuditLogCmd help.
+	"getauditlog--synopsis": "Returns the most recent entries of the RPC audit log.\n" +
+		"The audit log records every state-changing RPC command along with the authenticated user and must be enabled with --rpcauditlog.\n" +
+		"Each entry commits to the hash of the entry before it, so tampering with the log file is detected when it is opened.",
+	"getauditlog-count":    "The maximum number of entries to return, starting from the most recent",
+	"getauditlog--result0": "The audit log entries, oldest first",
+
+	// AuditLogEntry help.
+	"auditlogentry-seq":      "The sequence number of the entry",
+	"auditlogentry-time":     "The time the command was executed in seconds since 1 Jan 1970 GMT",
+	"auditlogentry-user":     "The RPC user which issued the command",
+	"auditlogentry-remote":   "The remote address of the RPC client",
+	"auditlogentry-method":   "The RPC method",
+	"auditlogentry-params":   "The JSON-encoded parameters of the command, or their size and sha256 when larger than 64 KiB",
+	"auditlogentry-error":    "The error returned by the command, if any",
+	"auditlogentry-prevhash": "The hash of the previous entry",
+	"auditlogentry-hash":     "The sha256 of this entry serialized with an empty hash",
+
 	// GetBestBlockResult help.
 	"getbestblockresult-hash":   "Hex-encoded bytes of the best block hash",
 	"getbestblockresult-height": "Height of the best block",
@@ -179,7 +241,7 @@ var helpDescsEnUS = map[string]string{
 	"getblockchaininforesult-bestblockhash":         "The block hash for the latest block in the main chain",
 	"getblockchaininforesult-difficulty":            "The current chain difficulty",
 	"getblockchaininforesult-mediantime":            "The median time from the PoV of the best block in the chain",
-	"getblockchaininforesult-verificationprogress":  "An estimate for how much of the best chain we've verified",
+	"getblockchaininforesult-verificationprogress":  "An estimate for how much of the best chain we've verified, between 0 and 1",
 	"getblockchaininforesult-pruned":                "A bool that indicates if the node is pruned or not",
 	"getblockchaininforesult-pruneheight":           "The lowest block retained in the current pruned chain",
 	"getblockchaininforesult-chainwork":             "The total cumulative work in the best chain",
@@ -189,6 +251,10 @@ var helpDescsEnUS = map[string]string{
 	"getblockchaininforesult-bip9_softforks--value": "An object describing a particular BIP009 deployment",
 	"getblockchaininforesult-bip9_softforks--desc":  "The status of any defined BIP0009 soft-fork deployments",
 
+	// GetBlockChainInfoResult help for the pktd extensions.
+	"getblockchaininforesult-initialblockdownload":   "Whether the node is still syncing with the network",
+	"getblockchaininforesult-estimatedtimeremaining": "The estimated number of seconds left to sync, -1 when no block is being connected (see getsyncstatus)",
+
 	// SoftForkDescription help.
 	"softforkdescription-reject":  "The current activation status of the softfork",
 	"softforkdescription-version": "The block version that signals enforcement of this softfork",
@@ -202,6 +268,7 @@ var helpDescsEnUS = map[string]string{
 	"txrawresult-locktime":      "The transaction lock time",
 	"txrawresult-vin":           "The transaction inputs as JSON objects",
 	"txrawresult-vout":          "The transaction outputs as JSON objects",
+	"txrawresult-fee":           "The fee paid by the transaction in coins (only with a verbosity of 2)",
 	"txrawresult-blockhash":     "Hash of the block the transaction is part of",
 	"txrawresult-confirmations": "Number of confirmations of the block",
 	"txrawresult-time":          "Transaction time in seconds since 1 Jan 1970 GMT",
@@ -209,6 +276,12 @@ var helpDescsEnUS = map[string]string{
 	"txrawresult-size":          "The size of the transaction in bytes",
 	"txrawresult-vsize":         "The virtual size of the transaction in bytes",
 	"txrawresult-hash":          "The wtxid of the transaction",
+	"txrawresult-provenance":    "When and how the transaction reached the node (only in txacceptedverbose notifications)",
+
+	// TxProvenanceResult help.
+	"txprovenanceresult-firstseen":  "The time the transaction was first announced to the node or received by it in seconds since 1 Jan 1970 GMT",
+	"txprovenanceresult-origin":     "The kind of peer the transaction was first received from (local, inbound, outbound or whitelisted)",
+	"txprovenanceresult-announcers": "The number of peers which announced or relayed the transaction",
 
 	// SearchRawTransactionsResult help.
 	"searchrawtransactionsresult-hex":           "Hex-encoded transaction",
@@ -243,6 +316,18 @@ var helpDescsEnUS = map[string]string{
 	"getblockverboseresult-nextblockhash":     "The hash of the next block (only if there is one)",
 	"getblockverboseresult-strippedsize":      "The size of the block without witness data",
 	"getblockverboseresult-weight":            "The weight of the block",
+	"getblockverboseresult-packetcrypt":       "The composition of the effective target the block was mined at (omitted for blocks without an announcement commitment)",
+
+	// PacketCryptWorkResult help.
+	"packetcryptworkresult-anncount":            "The number of announcements the block was mined with",
+	"packetcryptworkresult-annminbits":          "The target of the announcement with the least work in compact form",
+	"packetcryptworkresult-anndifficulty":       "The difficulty of the announcement with the least work",
+	"packetcryptworkresult-headerwork":          "The hex-encoded work of the header target",
+	"packetcryptworkresult-annminwork":          "The hex-encoded work of the announcement with the least work",
+	"packetcryptworkresult-annwork":             "The hex-encoded work of all the announcements, valued at the least work of any of them",
+	"packetcryptworkresult-effectivebits":       "The target the block was mined at given its announcements in compact form",
+	"packetcryptworkresult-effectivedifficulty": "The difficulty of the effective target",
+	"packetcryptworkresult-effectivework":       "The hex-encoded work of the effective target, the cube of the header work divided by the minimum announcement work and the announcement count",
 
 	// GetBlockCountCmd help.
 	"getblockcount--synopsis": "Returns the number of blocks in the longest block chain.",
@@ -376,6 +461,20 @@ var helpDescsEnUS = map[string]string{
 	"infochainresult-testnet":         "Whether or not server is using testnet",
 	"infochainresult-relayfee":        "The minimum relay fee for non-free transactions in BTC/KB",
 	"infochainresult-errors":          "Any current errors",
+	"infochainresult-build":           "The build information of the server",
+
+	// BuildInfoResult help.
+	"buildinforesult-commit":           "The commit the server was built from (omitted when not set at build time)",
+	"buildinforesult-goversion":        "The Go version the server was built with",
+	"buildinforesult-flags":            "The build flags set at build time (omitted when not set)",
+	"buildinforesult-consensusmodules": "The consensus-critical modules the server was built with",
+	"buildinforesult-manifestverified": "Whether the consensus-critical modules were verified against --buildmanifest at startup",
+
+	// BuildModuleResult help.
+	"buildmoduleresult-path":     "The module path",
+	"buildmoduleresult-version":  "The module version",
+	"buildmoduleresult-sum":      "The go.sum hash of the module (omitted when replaced with a local directory)",
+	"buildmoduleresult-replaced": "Whether the module was replaced",
 
 	// InfoWalletResult help.
 	"infowalletresult-version":         "The version of the server",
@@ -404,6 +503,184 @@ var helpDescsEnUS = map[string]string{
 	// GetInfoCmd help.
 	"getinfo--synopsis": "Returns a JSON object containing various state info.",
 
+	// GetDataCarrierInfoCmd help.
+	"getdatacarrierinfo--synopsis": "Returns the relay and mining policy of the transactions carrying data in OP_RETURN outputs along with the counts of those processed by the mempool by payload size class.",
+
+	// GetDataCarrierInfoResult help.
+	"getdatacarrierinforesult-relay":          "Whether transactions carrying data are relayed",
+	"getdatacarrierinforesult-maxsize":        "The maximum number of bytes of data a relayed transaction may carry in its OP_RETURN outputs",
+	"getdatacarrierinforesult-blockmaxweight": "The maximum total weight of the transactions carrying data in a generated block template",
+	"getdatacarrierinforesult-rejected":       "The number of transactions rejected by the data carrier policy since startup",
+	"getdatacarrierinforesult-mempoolbytes":   "The total payload of the transactions carrying data in the mempool",
+	"getdatacarrierinforesult-classes":        "The counts of the transactions carrying data by payload size class",
+
+	// DataCarrierClassResult help.
+	"datacarrierclassresult-size":     "The range of payload sizes of the class in bytes",
+	"datacarrierclassresult-accepted": "The number of transactions of the class accepted into the mempool since startup",
+	"datacarrierclassresult-mempool":  "The number of transactions of the class in the mempool",
+
+	// GetDiskStatusCmd help.
+	"getdiskstatus--synopsis": "Returns the free space on the data directory and whether the node is in safe mode because it runs out of it.\n" +
+		"In safe mode blocks are not accepted, so the database is not corrupted when the disk gets full, while queries keep being served until space is freed.",
+
+	// GetDiskStatusResult help.
+	"getdiskstatusresult-enabled":       "Whether the free space is monitored (--diskwarnspace, --disksafemodespace)",
+	"getdiskstatusresult-datadir":       "The data directory whose free space is monitored",
+	"getdiskstatusresult-status":        "The state of the free space (ok, low, safemode)",
+	"getdiskstatusresult-safemode":      "Whether blocks are refused because the disk is almost full",
+	"getdiskstatusresult-free":          "The free space on the data directory in bytes",
+	"getdiskstatusresult-total":         "The size of the filesystem of the data directory in bytes",
+	"getdiskstatusresult-warnspace":     "The free space in bytes below which the status is low, 0 when disabled",
+	"getdiskstatusresult-safemodespace": "The free space in bytes below which the safe mode is entered, 0 when disabled",
+	"getdiskstatusresult-since":         "The time the status last changed in seconds since 1 Jan 1970 GMT",
+	"getdiskstatusresult-checked":       "The time of the last check in seconds since 1 Jan 1970 GMT",
+	"getdiskstatusresult-error":         "The error of the last check, if it failed",
+
+	// GetMemoryInfoCmd help.
+	"getmemoryinfo--synopsis": "Returns the Go runtime memory statistics of pktd and the estimated memory used by its caches and pools.",
+
+	// GetMemoryInfoResult help.
+	"getmemoryinforesult-runtime":    "The Go runtime memory statistics",
+	"getmemoryinforesult-subsystems": "The estimated memory used by the caches and pools",
+
+	// RuntimeMemoryStats help.
+	"runtimememorystats-alloc":        "Bytes of allocated heap objects",
+	"runtimememorystats-totalalloc":   "Cumulative bytes allocated for heap objects",
+	"runtimememorystats-sys":          "Total bytes of memory obtained from the operating system",
+	"runtimememorystats-mallocs":      "Cumulative count of heap objects allocated",
+	"runtimememorystats-frees":        "Cumulative count of heap objects freed",
+	"runtimememorystats-heapalloc":    "Bytes of allocated heap objects",
+	"runtimememorystats-heapsys":      "Bytes of heap memory obtained from the operating system",
+	"runtimememorystats-heapidle":     "Bytes in idle heap spans",
+	"runtimememorystats-heapinuse":    "Bytes in in-use heap spans",
+	"runtimememorystats-heapreleased": "Bytes of heap memory returned to the operating system",
+	"runtimememorystats-heapobjects":  "Number of allocated heap objects",
+	"runtimememorystats-stacksys":     "Bytes of stack memory obtained from the operating system",
+	"runtimememorystats-numgc":        "Number of completed garbage collection cycles",
+	"runtimememorystats-lastgc":       "Time of the last garbage collection in seconds since 1 Jan 1970 GMT, 0 when none completed",
+	"runtimememorystats-pausetotalns": "Cumulative nanoseconds spent in garbage collection pauses",
+	"runtimememorystats-goroutines":   "Number of goroutines",
+
+	// SubsystemMemoryUsage help.
+	"subsystemmemoryusage-name":    "The subsystem: 'mempool', 'dbcache' (block database updates, including utxo set changes, not yet written to disk), 'sigcache', 'hashcache' or 'servedblocks'",
+	"subsystemmemoryusage-entries": "The number of entries of the subsystem",
+	"subsystemmemoryusage-bytes":   "The estimated memory used by the entries in bytes",
+
+	// ListenersCmd help.
+	"listeners--synopsis": "Adds, removes or lists the listeners accepting peer connections.\n" +
+		"The bound addresses of an added listener are advertised like those of --listen, and the addresses no remaining listener is bound to stop being advertised when one is removed.",
+	"listeners-subcmd":   "'add' to listen at an address, 'remove' to stop listening at an address, or 'list' to only list the listeners",
+	"listeners-addr":     "The address to add or remove: an IP address, an empty host for all of them or the name of a network interface such as eth0 for all its addresses, with an optional port; a listener can also be removed by the address it is bound to. An added address may be prefixed with the permissions granted to the peers connecting to it, such as noban,relay@eth0",
+	"listeners--result0": "The listeners accepting peer connections",
+
+	// ListenerResult help.
+	"listenerresult-listen":      "The listen address the listener was created for",
+	"listenerresult-address":     "The address the listener is bound to",
+	"listenerresult-network":     "The address family of the listener: 'ipv4' or 'ipv6'",
+	"listenerresult-runtime":     "Whether the listener was added with the listeners command",
+	"listenerresult-permissions": "The permissions granted to the peers connecting to the listener",
+
+	// GetClockSkewCmd help.
+	"getclockskew--synopsis": "Returns how the local clock compares with the median time of the peers, as sampled from their version messages.\n" +
+		"The samples far from the others are rejected as outliers and the local clock is adjusted by the median offset of the rest, clamped to --maxtimeadjustment.",
+
+	// GetClockSkewResult help.
+	"getclockskewresult-localtime":     "The local time in seconds since 1 Jan 1970 GMT",
+	"getclockskewresult-adjustedtime":  "The local time adjusted by the offset, as used to check block timestamps",
+	"getclockskewresult-offset":        "The adjustment in seconds applied to the local clock",
+	"getclockskewresult-networkoffset": "The median offset in seconds of the peer time samples which are not outliers",
+	"getclockskewresult-maxoffset":     "The maximum adjustment in seconds of the local clock (--maxtimeadjustment)",
+	"getclockskewresult-samples":       "The number of peer time samples considered",
+	"getclockskewresult-outliers":      "The number of peer time samples rejected for being far from the others",
+	"getclockskewresult-clamped":       "Whether the network offset exceeds the maximum adjustment",
+	"getclockskewresult-skewed":        "Whether the local clock deviates significantly from the median time of the peers",
+	"getclockskewresult-warning":       "A warning when the local clock is skewed",
+
+	// GetColdSpendsCmd help.
+	"getcoldspends--synopsis": "Returns the transactions seen spending from the cold storage addresses watched with the coldaddress option, most recent first, and whether the alarm they raised is still up.\n" +
+		"A spend raises the alarm as soon as it is seen in the mempool and again when it is mined.",
+	"getcoldspends-acknowledge": "Clear the alarm after returning it",
+
+	// GetColdSpendsResult help.
+	"getcoldspendsresult-alarm":     "Whether a spend was seen since the alarm was last acknowledged",
+	"getcoldspendsresult-addresses": "The number of cold storage addresses watched",
+	"getcoldspendsresult-spends":    "The transactions spending from the cold storage addresses, most recent first",
+
+	// ColdSpendResult help.
+	"coldspendresult-txid":      "The hash of the transaction",
+	"coldspendresult-addresses": "The cold storage addresses spent from",
+	"coldspendresult-amount":    "The total amount spent from the cold storage addresses",
+	"coldspendresult-firstseen": "The time in seconds since 1 Jan 1970 GMT the transaction was first seen",
+	"coldspendresult-blockhash": "The hash of the block the transaction was mined in, if it is mined",
+	"coldspendresult-height":    "The height of the block the transaction was mined in, if it is mined",
+
+	// GetConsensusRulesCmd help.
+	"getconsensusrules--synopsis": "Returns the consensus rules in effect for a block of the main chain: the block versions, script flags, deployment states, subsidy and PacketCrypt rules at its height.",
+	"getconsensusrules-height":    "The height of the block (default: the height of the block following the best block)",
+
+	// GetConsensusRulesResult help.
+	"getconsensusrulesresult-height":               "The height of the block the rules apply to",
+	"getconsensusrulesresult-minblockversion":      "The lowest block version accepted, as raised by BIP0034, BIP0066 and BIP0065",
+	"getconsensusrulesresult-serializedheight":     "Whether the coinbase must start with the height of the block (BIP0034)",
+	"getconsensusrulesresult-scriptflags":          "The script flags enforced for the transactions of every valid block, separated by commas",
+	"getconsensusrulesresult-deployments":          "JSON object mapping each version bits deployment to its state",
+	"getconsensusrulesresult-deployments--key":     "deployment",
+	"getconsensusrulesresult-deployments--value":   "The state of the deployment (defined, started, lockedin, active, failed)",
+	"getconsensusrulesresult-deployments--desc":    "The state of each version bits deployment for the block",
+	"getconsensusrulesresult-subsidyepoch":         "The number of times the subsidy changed before the block",
+	"getconsensusrulesresult-nextsubsidyheight":    "The height at which the subsidy next changes, or -1 when it never does",
+	"getconsensusrulesresult-subsidy":              "The new money created by the block in the smallest unit of the chain",
+	"getconsensusrulesresult-networkstewardpayout": "The part of the subsidy paid to the network steward in the smallest unit of the chain",
+	"getconsensusrulesresult-packetcrypt":          "Whether the block must carry a PacketCrypt proof",
+	"getconsensusrulesresult-annaging":             "Whether the targets of the announcements of the block are aged",
+	"getconsensusrulesresult-annwaitperiod":        "The age at which an announcement can first be mined",
+
+	// GetPartitionStatusCmd help.
+	"getpartitionstatus--synopsis": "Returns the state of the network partition detector, which compares the chain of the node with the chains announced by its peers.\n" +
+		"An alert is raised when the node stays behind the most work announced by a peer, or a large share of the peers stays on another chain, for the configured alert time.",
+
+	// GetPartitionStatusResult help.
+	"getpartitionstatusresult-enabled":           "Whether the detector is enabled (--partitionalerttime); only the tip is reported otherwise",
+	"getpartitionstatusresult-alerttime":         "The number of seconds a condition must hold before an alert is raised",
+	"getpartitionstatusresult-tiphash":           "The hash of the best block of the node",
+	"getpartitionstatusresult-tipheight":         "The height of the best block of the node",
+	"getpartitionstatusresult-tipwork":           "The hex-encoded total work of the main chain",
+	"getpartitionstatusresult-bestpeer":          "The address of the peer announcing the most work",
+	"getpartitionstatusresult-bestpeerheight":    "The height announced by the best peer",
+	"getpartitionstatusresult-bestpeerwork":      "The hex-encoded total work of the chain announced by the best peer",
+	"getpartitionstatusresult-bestpeerestimated": "Whether the work of the best peer is estimated from its height since its chain is unknown",
+	"getpartitionstatusresult-peers":             "The number of peers compared",
+	"getpartitionstatusresult-peerssamechain":    "The number of peers whose latest block is in the main chain or extends its tip",
+	"getpartitionstatusresult-peersotherchain":   "The number of peers on another chain",
+	"getpartitionstatusresult-behind":            "Whether the node is behind the most work announced by a peer",
+	"getpartitionstatusresult-fork":              "Whether the share of the peers on another chain reaches --partitionforkratio",
+
+	// PartitionConditionResult help.
+	"partitionconditionresult-holding": "Whether the condition holds",
+	"partitionconditionresult-since":   "The time the condition started to hold in seconds since 1 Jan 1970 GMT",
+	"partitionconditionresult-alert":   "Whether an alert is raised since the condition held for the alert time",
+
+	// GetMempoolEntryCmd help.
+	"getmempoolentry--synopsis": "Returns information about a transaction in the memory pool.",
+	"getmempoolentry-txid":      "The hash of the transaction",
+
+	// GetMempoolEntryResult help.
+	"getmempoolentryresult-size":             "The virtual size of the transaction",
+	"getmempoolentryresult-fee":              "Transaction fee in bitcoins",
+	"getmempoolentryresult-modifiedfee":      "Transaction fee in bitcoins used for mining priority (the same as the fee)",
+	"getmempoolentryresult-time":             "Local time transaction entered pool in seconds since 1 Jan 1970 GMT",
+	"getmempoolentryresult-height":           "Block height when transaction entered the pool",
+	"getmempoolentryresult-startingpriority": "Priority when transaction entered the pool",
+	"getmempoolentryresult-currentpriority":  "Current priority",
+	"getmempoolentryresult-descendantcount":  "Number of in-pool descendant transactions, including this one",
+	"getmempoolentryresult-descendantsize":   "Virtual size of the in-pool descendants, including this one",
+	"getmempoolentryresult-descendantfees":   "Fees of the in-pool descendants in atoms, including this one",
+	"getmempoolentryresult-ancestorcount":    "Number of in-pool ancestor transactions, including this one",
+	"getmempoolentryresult-ancestorsize":     "Virtual size of the in-pool ancestors, including this one",
+	"getmempoolentryresult-ancestorfees":     "Fees of the in-pool ancestors in atoms, including this one",
+	"getmempoolentryresult-depends":          "Unconfirmed transactions used as inputs for this transaction",
+	"getmempoolentryresult-provenance":       "When and how the transaction reached the node",
+
 	// GetMempoolInfoCmd help.
 	"getmempoolinfo--synopsis": "Returns memory pool information",
 
@@ -424,12 +701,53 @@ var helpDescsEnUS = map[string]string{
 	"getmininginforesult-networkhashps":      "Estimated network hashes per second for the most recent blocks",
 	"getmininginforesult-pooledtx":           "Number of transactions in the memory pool",
 	"getmininginforesult-testnet":            "Whether or not server is using testnet",
+	"getmininginforesult-chain":              "The name of the chain the server is on",
+	"getmininginforesult-warnings":           "Any network and blockchain warnings",
+	"getmininginforesult-blocklimits":        "The limits used to assemble block templates",
+
+	// BlockLimitsResult help.
+	"blocklimitsresult-maxweight":            "The maximum weight of the block templates",
+	"blocklimitsresult-maxsigopcost":         "The maximum signature operation cost of the block templates",
+	"blocklimitsresult-reservedweight":       "The part of the maximum weight only used by the transactions submitted to this node",
+	"blocklimitsresult-prioritysize":         "The part of the block filled with high-priority transactions regardless of their fees",
+	"blocklimitsresult-maxdatacarrierweight": "The maximum weight of the transactions carrying data in OP_RETURN outputs",
 
 	// GetMiningInfoCmd help.
 	"getmininginfo--synopsis": "Returns a JSON object containing mining-related information.",
 
 	"getnetworksteward--synopsis": "Returns information about the network steward, if using a chain with one",
 
+	// GetNetworkInfoCmd help.
+	"getnetworkinfo--synopsis": "Returns a JSON object containing information about the peer-to-peer networking of the server.",
+
+	// GetNetworkInfoResult help.
+	"getnetworkinforesult-version":         "The version of the server",
+	"getnetworkinforesult-subversion":      "The user agent advertised to peers",
+	"getnetworkinforesult-protocolversion": "The latest supported protocol version",
+	"getnetworkinforesult-localservices":   "The services advertised to peers, as a hex string",
+	"getnetworkinforesult-localrelay":      "Whether or not transactions are relayed to and requested from peers",
+	"getnetworkinforesult-timeoffset":      "The time offset in seconds",
+	"getnetworkinforesult-connections":     "The number of connected peers",
+	"getnetworkinforesult-networkactive":   "Whether or not the server is connected to the network",
+	"getnetworkinforesult-networks":        "Information about each network the server can connect through",
+	"getnetworkinforesult-relayfee":        "The minimum relay fee for non-free transactions in BTC/KB",
+	"getnetworkinforesult-incrementalfee":  "The minimum fee increment for replacement transactions in BTC/KB",
+	"getnetworkinforesult-localaddresses":  "The local addresses advertised to peers",
+	"getnetworkinforesult-warnings":        "Any network and blockchain warnings",
+	"getnetworkinforesult-build":           "The build information of the server",
+
+	// NetworksResult help.
+	"networksresult-name":                        "The name of the network (ipv4, ipv6 or onion)",
+	"networksresult-limited":                     "Whether or not connections through the network are disabled",
+	"networksresult-reachable":                   "Whether or not peers are reachable through the network",
+	"networksresult-proxy":                       "The proxy used to connect through the network, if any",
+	"networksresult-proxy_randomize_credentials": "Whether or not proxy credentials are randomized for each connection",
+
+	// LocalAddressesResult help.
+	"localaddressesresult-address": "The local IP address or onion host",
+	"localaddressesresult-port":    "The port the address is advertised with",
+	"localaddressesresult-score":   "The priority of the address",
+
 	// GetNetworkHashPSCmd help.
 	"getnetworkhashps--synopsis": "Returns the estimated network hashes per second for the block heights provided by the parameters.",
 	"getnetworkhashps-blocks":    "The number of blocks, or -1 for blocks since last difficulty change",
@@ -444,6 +762,35 @@ var helpDescsEnUS = map[string]string{
 	"getnettotalsresult-totalbytessent": "Total bytes sent",
 	"getnettotalsresult-timemillis":     "Number of milliseconds since 1 Jan 1970 GMT",
 
+	// GetNodeAddressesCmd help.
+	"getnodeaddresses--synopsis": "Returns randomly chosen addresses known to the address manager which are not considered bad, which can be used to find peers.",
+	"getnodeaddresses-count":     "The maximum number of addresses to return, or 0 for all of them",
+
+	// GetNodeAddressesResult help.
+	"getnodeaddressesresult-time":     "The time in seconds since 1 Jan 1970 GMT the address was last seen",
+	"getnodeaddressesresult-services": "The services offered by the node",
+	"getnodeaddressesresult-address":  "The IP address or onion host of the node",
+	"getnodeaddressesresult-port":     "The port of the node",
+
+	// GetAddrManInfoCmd help.
+	"getaddrmaninfo--synopsis": "Returns statistics about the new and tried buckets of the address manager.",
+
+	// AddrManBucketsResult help.
+	"addrmanbucketsresult-addresses":  "The number of addresses in the buckets",
+	"addrmanbucketsresult-buckets":    "The number of buckets",
+	"addrmanbucketsresult-used":       "The number of buckets holding at least one address",
+	"addrmanbucketsresult-bucketsize": "The maximum number of addresses in a bucket",
+	"addrmanbucketsresult-maxbucket":  "The number of addresses in the fullest bucket",
+
+	// GetAddrManInfoResult help.
+	"getaddrmaninforesult-total": "The number of known addresses",
+	"getaddrmaninforesult-new":   "Statistics about the new buckets, which hold addresses which were never connected to",
+	"getaddrmaninforesult-tried": "Statistics about the tried buckets, which hold addresses which were successfully connected to",
+	"getaddrmaninforesult-bad":   "The number of addresses which are considered bad and are about to be evicted",
+	"getaddrmaninforesult-ipv4":  "The number of IPv4 addresses",
+	"getaddrmaninforesult-ipv6":  "The number of IPv6 addresses",
+	"getaddrmaninforesult-onion": "The number of Tor onion addresses",
+
 	// GetPeerInfoResult help.
 	"getpeerinforesult-id":             "A unique node ID",
 	"getpeerinforesult-addr":           "The ip address and port of the peer",
@@ -466,6 +813,9 @@ var helpDescsEnUS = map[string]string{
 	"getpeerinforesult-banscore":       "The ban score",
 	"getpeerinforesult-feefilter":      "The requested minimum fee a transaction must have to be announced to the peer",
 	"getpeerinforesult-syncnode":       "Whether or not the peer is the sync peer",
+	"getpeerinforesult-permissions":    "The permissions granted to the peer: noban, relay, forcerelay, addr or download",
+	"getpeerinforesult-invqueued":      "The number of inventory items waiting to be trickled to the peer",
+	"getpeerinforesult-invannounced":   "The number of inventory items trickled to the peer since the connection was made",
 
 	// GetPeerInfoCmd help.
 	"getpeerinfo--synopsis": "Returns data about each connected network peer as an array of json objects.",
@@ -500,11 +850,162 @@ var helpDescsEnUS = map[string]string{
 	// GetRawTransactionCmd help.
 	"getrawtransaction--synopsis":   "Returns information about a transaction given its hash.",
 	"getrawtransaction-txid":        "The hash of the transaction",
-	"getrawtransaction-verbose":     "Specifies the transaction is returned as a JSON object instead of a hex-encoded string",
+	"getrawtransaction-verbose":     "Specifies the transaction is returned as a JSON object instead of a hex-encoded string, including its fee when set to 2",
 	"getrawtransaction--condition0": "verbose=false",
 	"getrawtransaction--condition1": "verbose=true",
 	"getrawtransaction--result0":    "Hex-encoded bytes of the serialized transaction",
 
+	// GetOrphanBlocksCmd help.
+	"getorphanblocks--synopsis": "Returns the recently seen blocks with a valid proof of work which are not part of the main chain, newest first.\n" +
+		"They are the orphans received before their parents, the blocks of side chains with less work and the blocks disconnected by reorganizations.",
+
+	// OrphanBlockResult help.
+	"orphanblockresult-hash":              "The hash of the block",
+	"orphanblockresult-height":            "The height of the block, taken from the coinbase of orphans, or -1 when it is unknown",
+	"orphanblockresult-header":            "The serialized, hex-encoded block header",
+	"orphanblockresult-previousblockhash": "The hash of the parent block",
+	"orphanblockresult-time":              "The block timestamp in seconds since 1 Jan 1970 GMT",
+	"orphanblockresult-seen":              "The time the block was first left out of the main chain in seconds since 1 Jan 1970 GMT",
+	"orphanblockresult-reason":            "Why the block was last left out of the main chain (orphan, sidechain or reorganized)",
+	"orphanblockresult-status":            "The current state of the block (orphan while its parent is missing, sidechain, invalid, or dropped when the orphan expired)",
+	"orphanblockresult-forkheight":        "The height of the last main chain block the block descends from, or -1 when it does not connect to the known blocks",
+	"orphanblockresult-forkhash":          "The hash of the last main chain block the block descends from",
+	"orphanblockresult-mainchainhash":     "The hash of the main chain block at the height of the block",
+	"orphanblockresult-children":          "The hashes of the known blocks outside of the main chain building on the block",
+
+	// GetOrphanTxsCmd help.
+	"getorphantxs--synopsis":   "Returns the transactions of the orphan pool, which spend outputs of unknown transactions, ordered by expiration.",
+	"getorphantxs-verbosity":   "0 for an array of transaction hashes, 1 for objects describing the orphans and 2 to add the serialized transactions",
+	"getorphantxs--condition0": "verbosity=0",
+	"getorphantxs--condition1": "verbosity=1 or verbosity=2",
+	"getorphantxs--result0":    "Array of transaction hashes",
+
+	// OrphanTxResult help.
+	"orphantxresult-txid":       "The hash of the transaction",
+	"orphantxresult-wtxid":      "The witness hash of the transaction",
+	"orphantxresult-bytes":      "The serialized size of the transaction",
+	"orphantxresult-vsize":      "The virtual size of the transaction",
+	"orphantxresult-weight":     "The weight of the transaction",
+	"orphantxresult-from":       "The ID of the peer which relayed the transaction, 0 when it was submitted locally",
+	"orphantxresult-expiration": "The time in seconds since 1 Jan 1970 GMT when the orphan is removed if its parents are still unknown",
+	"orphantxresult-hex":        "Hex-encoded bytes of the serialized transaction (only with verbosity=2)",
+
+	// GetRejectedTxsCmd help.
+	"getrejectedtxs--synopsis": "Returns the transactions relayed by peers which were recently rejected, most recent first, with the reasons they were rejected.\n" +
+		"The number of transactions remembered is set with the maxrejectedtx option.",
+
+	// RejectedTxResult help.
+	"rejectedtxresult-txid":     "The hash of the transaction",
+	"rejectedtxresult-peerid":   "The ID of the peer which relayed the transaction",
+	"rejectedtxresult-peer":     "The IP address and port of the peer which relayed the transaction",
+	"rejectedtxresult-time":     "The time in seconds since 1 Jan 1970 GMT the transaction was rejected",
+	"rejectedtxresult-height":   "The height of the best block when the transaction was rejected",
+	"rejectedtxresult-code":     "The reject code sent to the peer",
+	"rejectedtxresult-reason":   "The reason the transaction was rejected",
+	"rejectedtxresult-filtered": "Whether the transaction was rejected since the last block, so it is not requested again when announced",
+
+	// GetMempoolDeltaCmd help.
+	"getmempooldelta--synopsis": "Returns the transactions added to and removed from the memory pool since the state with the passed sequence number, so monitoring tools can follow the memory pool without downloading it.\n" +
+		"The changes are netted out: a transaction both added and removed since then is left out.\n" +
+		"The whole memory pool is returned instead when the sequence number is 0 or older than the events remembered, whose number is set with the mempoolevents option.",
+	"getmempooldelta-sequence": "The sequence number returned by the previous call, or 0 for the whole memory pool",
+
+	// GetMempoolDeltaResult help.
+	"getmempooldeltaresult-sequence": "The sequence number of the current state of the memory pool, to pass to the next call",
+	"getmempooldeltaresult-reset":    "Whether the changes since the sequence number are not known, in which case added lists every transaction of the memory pool",
+	"getmempooldeltaresult-added":    "The hashes of the transactions added to the memory pool",
+	"getmempooldeltaresult-removed":  "The transactions removed from the memory pool",
+
+	// MempoolRemovedResult help.
+	"mempoolremovedresult-txid":   "The hash of the transaction",
+	"mempoolremovedresult-reason": "Why the transaction was removed (mined, conflict, replaced, reorg, evicted)",
+
+	// GetSyncStatusCmd help.
+	"getsyncstatus--synopsis": "Returns the progress of the sync with the chain of the network, with an estimate of the time remaining, the time spent in each stage and the bandwidth used.\n" +
+		"The height of the chain of the network is estimated from the headers, the peers and, while syncing, the time elapsed since the best block.",
+
+	// GetSyncStatusResult help.
+	"getsyncstatusresult-blocks":                 "The height of the best block",
+	"getsyncstatusresult-headers":                "The height of the latest header known to be part of the chain being synced",
+	"getsyncstatusresult-bestpeerheight":         "The height of the latest block announced by the peers",
+	"getsyncstatusresult-estimatedheight":        "The estimated height of the chain of the network",
+	"getsyncstatusresult-syncpeer":               "The ID of the peer the chain is synced from, 0 when there is none",
+	"getsyncstatusresult-headersfirst":           "Whether the headers are downloaded before the blocks up to the next checkpoint",
+	"getsyncstatusresult-presyncphase":           "The phase of the headers pre-synchronization (presync or redownload), only while it runs",
+	"getsyncstatusresult-initialblockdownload":   "Whether the node is still syncing with the network",
+	"getsyncstatusresult-verificationprogress":   "The fraction of the estimated height of the chain of the network which was verified, between 0 and 1",
+	"getsyncstatusresult-blockspersecond":        "The number of blocks per second connected over the last 5 minutes",
+	"getsyncstatusresult-estimatedtimeremaining": "The estimated number of seconds left to sync, -1 when no block is being connected",
+	"getsyncstatusresult-stages":                 "The time spent in each stage of the sync since the node started",
+	"getsyncstatusresult-bandwidth":              "The bandwidth used since the node started",
+
+	// SyncStagesResult help.
+	"syncstagesresult-headers": "The processing of the headers downloaded in the headers-first mode",
+	"syncstagesresult-blocks":  "The processing of the blocks received from peers, including the checks of their proofs and scripts",
+	"syncstagesresult-proofs":  "The checks of the PacketCrypt proofs of the blocks",
+	"syncstagesresult-scripts": "The checks of the scripts of the transactions of the blocks",
+
+	// SyncStageResult help.
+	"syncstageresult-count":   "The number of headers or blocks which went through the stage",
+	"syncstageresult-seconds": "The total time spent in the stage in seconds",
+
+	// SyncBandwidthResult help.
+	"syncbandwidthresult-totalbytesrecv": "Total bytes received",
+	"syncbandwidthresult-totalbytessent": "Total bytes sent",
+	"syncbandwidthresult-recvrate":       "The bytes received per second since the node started",
+	"syncbandwidthresult-sentrate":       "The bytes sent per second since the node started",
+
+	// CreateDepositAddressesCmd help.
+	"createdepositaddresses--synopsis": "Returns the deposit addresses bound to the passed reference IDs, in the same order, binding the next unused addresses of the deposit account to the new ones.\n" +
+		"The deposit tracker must be enabled with the depositbundle option.",
+	"createdepositaddresses-refs": "The reference IDs, such as the customer account numbers, at most 10000",
+
+	// DepositAddressResult help.
+	"depositaddressresult-ref":     "The reference ID",
+	"depositaddressresult-address": "The deposit address bound to the reference ID",
+	"depositaddressresult-index":   "The index of the address on the receive branch of the deposit account",
+
+	// ListDepositsCmd help.
+	"listdeposits--synopsis": "Returns the deposits to the deposit addresses which are in the main chain, in the order they were mined.\n" +
+		"The deposit tracker must be enabled with the depositbundle option.",
+	"listdeposits-ref": "Only return the deposits to the address bound to this reference ID",
+
+	// DepositResult help.
+	"depositresult-ref":           "The reference ID of the address paid",
+	"depositresult-address":       "The address paid",
+	"depositresult-txid":          "The hash of the transaction of the deposit",
+	"depositresult-vout":          "The index of the output of the deposit",
+	"depositresult-amount":        "The amount of the deposit",
+	"depositresult-blockhash":     "The hash of the block the deposit was mined in",
+	"depositresult-height":        "The height of the block the deposit was mined in",
+	"depositresult-confirmations": "The number of confirmations of the deposit",
+	"depositresult-reported":      "The highest confirmation count the deposit was sent to the webhooks at, 0 before the first",
+
+	// MintTokenCmd help.
+	"minttoken--synopsis": "Mints a capability token which authenticates RPC clients in place of a username and password, restricted to a scope and optionally to an expiration, a maximum send amount and a list of commands.\n" +
+		"Clients send the token in the Authorization header as a bearer token.  Its holder may restrict it further without contacting the node, but never extend it.\n" +
+		"RPC tokens must be enabled with the rpctokens option, and a token is revoked by passing its identifier to the rpcrevoketoken option.",
+	"minttoken-scope":   "The commands allowed by the token: readonly for the commands of the limited user which don't change the state of the node, send for the commands of the limited user, or admin for every command",
+	"minttoken-expiry":  "The number of seconds the token is valid for, or 0 for a token which never expires",
+	"minttoken-maxsend": "The maximum total output value in PKT of each transaction relayed with sendrawtransaction",
+	"minttoken-methods": "Restrict the token to these commands",
+
+	// MintTokenResult help.
+	"minttokenresult-token":   "The token",
+	"minttokenresult-id":      "The identifier of the token, which names it in the logs and to the rpcrevoketoken option",
+	"minttokenresult-expires": "The time the token expires in seconds since 1 Jan 1970 GMT, omitted when it never expires",
+
+	// GetRPCInfoCmd help.
+	"getrpcinfo--synopsis": "Returns a JSON object containing information about the RPC server.",
+
+	// GetRPCInfoResult help.
+	"getrpcinforesult-active_commands": "The commands being executed by the server",
+	"getrpcinforesult-logpath":         "The path of the debug log",
+
+	// RPCCommandInfo help.
+	"rpccommandinfo-method":   "The name of the command",
+	"rpccommandinfo-duration": "How long the command has been running in microseconds",
+
 	// GetTxOutResult help.
 	"gettxoutresult-bestblock":     "The block hash that contains the transaction output",
 	"gettxoutresult-confirmations": "The number of confirmations",
@@ -519,6 +1020,280 @@ var helpDescsEnUS = map[string]string{
 	"gettxout-vout":           "The index of the output",
 	"gettxout-includemempool": "Include the mempool when true",
 
+	// GetTxOutProofCmd help.
+	"gettxoutproof--synopsis": "Returns a hex-encoded proof that the passed transactions are included in a block.\n" +
+		"The proof is a serialized merkleblock message, which can be checked with verifytxoutproof.\n" +
+		"The block is found with the transaction index (--txindex) when its hash is not specified.",
+	"gettxoutproof-txids":     "The hashes of the transactions to prove, all of which must be in the same block",
+	"gettxoutproof-blockhash": "The hash of the block holding the transactions",
+	"gettxoutproof--result0":  "The hex-encoded proof",
+
+	// GetBlockCostCmd help.
+	"getblockcost--synopsis": "Returns the consensus accounting of a block, the sizes, weight and signature operation cost checked against the limits of the chain, for the block and each of its transactions.\n" +
+		"The block must be in the main chain or extend the best block, as a block template does.",
+	"getblockcost-block": "The hash of a main chain block or a hex-encoded serialized block extending the best block (default: the current block template)",
+
+	// GetBlockCostResult help.
+	"getblockcostresult-hash":            "The hash of the block",
+	"getblockcostresult-height":          "The height of the block",
+	"getblockcostresult-size":            "The serialized size of the block including the witness data",
+	"getblockcostresult-strippedsize":    "The serialized size of the block without the witness data",
+	"getblockcostresult-witnesssize":     "The size of the witness data of the block",
+	"getblockcostresult-weight":          "The weight of the block as defined in BIP0141",
+	"getblockcostresult-sigopcost":       "The total signature operation cost of the transactions",
+	"getblockcostresult-maxstrippedsize": "The maximum serialized size of a block without the witness data",
+	"getblockcostresult-maxweight":       "The maximum weight of a block",
+	"getblockcostresult-maxsigopcost":    "The maximum signature operation cost of a block",
+	"getblockcostresult-headersize":      "The size of the block header",
+	"getblockcostresult-txcountsize":     "The size of the number of transactions",
+	"getblockcostresult-proofsize":       "The size of the PacketCrypt proof of the block, if any",
+	"getblockcostresult-transactions":    "The accounting of each transaction of the block",
+
+	// TxCostResult help.
+	"txcostresult-txid":          "The hash of the transaction",
+	"txcostresult-hash":          "The witness hash of the transaction",
+	"txcostresult-size":          "The serialized size of the transaction including the witness data",
+	"txcostresult-strippedsize":  "The serialized size of the transaction without the witness data",
+	"txcostresult-witnesssize":   "The size of the witness data of the transaction",
+	"txcostresult-weight":        "The weight of the transaction as defined in BIP0141",
+	"txcostresult-legacysigops":  "The number of signature operations in the input and output scripts",
+	"txcostresult-p2shsigops":    "The number of signature operations in the redeem scripts of the pay-to-script-hash inputs",
+	"txcostresult-witnesssigops": "The number of signature operations in the witness programs of the inputs",
+	"txcostresult-sigopcost":     "The signature operation cost of the transaction, the legacy and pay-to-script-hash signature operations times the witness scale factor plus the witness signature operations",
+
+	// GetBlockTemplateLightCmd help.
+	"getblocktemplatelight--synopsis": "Returns the block template getblocktemplate returns with a coinbase value, without the data of the transactions which are already known.\n" +
+		"The block template is kept up to date by the server, so new work is available as soon as the best block changes.",
+	"getblocktemplatelight-longpollid": "The long poll ID of a previous block template; the reply waits for it to become stale and only holds the transactions which were not in it",
+
+	// GetBlockTemplateLightResult help.
+	"getblocktemplatelightresult-bits":                       "Hex-encoded compressed difficulty",
+	"getblocktemplatelightresult-curtime":                    "Current time as seen by the server (recommended for block time); must fall within mintime/maxtime rules",
+	"getblocktemplatelightresult-height":                     "Height of the block to be solved",
+	"getblocktemplatelightresult-previousblockhash":          "Hex-encoded big-endian hash of the previous block",
+	"getblocktemplatelightresult-version":                    "The block version",
+	"getblocktemplatelightresult-target":                     "Hex-encoded big-endian number which valid results must be less than",
+	"getblocktemplatelightresult-mintime":                    "Minimum allowed time",
+	"getblocktemplatelightresult-maxtime":                    "Maximum allowed time",
+	"getblocktemplatelightresult-longpollid":                 "Identifier of the block template for long poll requests and later deltas",
+	"getblocktemplatelightresult-submitold":                  "Whether work on the block template identified by the long poll ID can still be submitted (only for long poll requests)",
+	"getblocktemplatelightresult-coinbasevalue":              "Total amount available for the coinbase in Satoshi",
+	"getblocktemplatelightresult-default_witness_commitment": "The witness commitment itself. Will be populated if the block has witness data",
+	"getblocktemplatelightresult-merkle":                     "The hex-encoded hashes of the merkle branch of the coinbase, from the leaves up",
+	"getblocktemplatelightresult-txhashes":                   "The hashes of the transactions of the block template other than the coinbase, in order",
+	"getblocktemplatelightresult-baselongpollid":             "The long poll ID of the block template the transactions are the differences from, if it is still remembered",
+	"getblocktemplatelightresult-transactions":               "The transactions which are not in the base block template, or all of them without one",
+	"getblocktemplatelightresult-removed":                    "The hashes of the transactions of the base block template which are no longer included",
+
+	// GetTxFeeCmd help.
+	"gettxfee--synopsis": "Returns the exact fee paid by a transaction of the memory pool or of the main chain, computed from the outputs it spends, so the fees of transactions with foreign inputs can be reported.\n" +
+		"Confirmed transactions require the transaction index to be enabled.",
+	"gettxfee-txid": "The hash of the transaction",
+
+	// GetTxFeeResult help.
+	"gettxfeeresult-txid":      "The hash of the transaction",
+	"gettxfeeresult-fee":       "The fee paid by the transaction in coins",
+	"gettxfeeresult-vsize":     "The virtual size of the transaction in bytes",
+	"gettxfeeresult-feerate":   "The fee rate of the transaction in coins per kilobyte of virtual size",
+	"gettxfeeresult-blockhash": "The hash of the block containing the transaction (omitted when it is in the memory pool)",
+
+	// GetNetworkHashrateCmd help.
+	"getnetworkhashrate--synopsis": "Returns the PacketCrypt block mining and announcement hash rates of the network estimated from the work of the blocks of a window.\n" +
+		"Unlike getnetworkhashps, the block work is the work of the effective target each block was mined at given its announcements.",
+	"getnetworkhashrate-blocks": "The number of blocks of the window, up to 10080",
+	"getnetworkhashrate-height": "The height of the last block of the window or -1 for the best block",
+
+	// GetNetworkHashrateResult help.
+	"getnetworkhashrateresult-startheight":   "The height of the first block of the window, whose work is not counted",
+	"getnetworkhashrateresult-endheight":     "The height of the last block of the window",
+	"getnetworkhashrateresult-timespan":      "The number of seconds between the earliest and the latest block timestamps of the window",
+	"getnetworkhashrateresult-announcements": "The number of announcements the blocks were mined with",
+	"getnetworkhashrateresult-blockwork":     "The hex-encoded block mining work of the blocks",
+	"getnetworkhashrateresult-annwork":       "The hex-encoded work of the announcements of the blocks",
+	"getnetworkhashrateresult-blockhashrate": "The estimated block mining hashes per second",
+	"getnetworkhashrateresult-annhashrate":   "The estimated announcement hashes per second",
+
+	// GetAnnAgingScheduleCmd help.
+	"getannagingschedule--synopsis": "Returns the aged targets of an announcement for each age when mined in the block following the best block, and the last ages at which it meets the minimum announcement target of the best block and can be mined at all.\n" +
+		"An announcement can be mined once its parent block is the wait period deep, and its work is then divided by its age past the wait period.",
+	"getannagingschedule-target": "The compact target in hex the announcement was mined at (default: the minimum announcement target of the best block)",
+	"getannagingschedule-maxage": "The greatest age of the schedule, up to 1000",
+
+	// GetAnnAgingScheduleResult help.
+	"getannagingscheduleresult-height":        "The height of the block following the best block, which the ages are relative to",
+	"getannagingscheduleresult-target":        "The compact target the announcement was mined at",
+	"getannagingscheduleresult-tipannminbits": "The minimum announcement target of the best block (omitted without an announcement commitment)",
+	"getannagingscheduleresult-maxagedbits":   "The highest aged target an announcement can be mined at",
+	"getannagingscheduleresult-waitperiod":    "The age at which an announcement can first be mined",
+	"getannagingscheduleresult-expiryage":     "The last age at which the announcement can be mined, or -1 when it never can",
+	"getannagingscheduleresult-acceptableage": "The last age at which the announcement meets the minimum announcement target of the best block, or -1 when it never does",
+	"getannagingscheduleresult-schedule":      "The aged target of the announcement for each age",
+
+	// AnnAgeResult help.
+	"annageresult-age":          "The age of the announcement in blocks",
+	"annageresult-parentheight": "The height of the parent block of an announcement of this age",
+	"annageresult-status":       "Whether the announcement is waiting to be mined, valid or expired (waiting, valid, expired)",
+	"annageresult-bits":         "The aged target in compact form (only when valid)",
+	"annageresult-difficulty":   "The difficulty of the aged target (only when valid)",
+	"annageresult-acceptable":   "Whether the aged target meets the minimum announcement target of the best block",
+
+	// GetAnnProofCmd help.
+	"getannproof--synopsis": "Returns a proof that an announcement of the PacketCrypt proof of a main chain block is included in the announcement tree committed to by the block, along with what it proves.\n" +
+		"Only the 4 announcements sampled by the PacketCrypt proof can be proven. The proof holds a merkle proof of the coinbase, the announcement, the hashes of the other sampled announcements and the announcement tree branches, and can be verified without the chain besides the block header.",
+	"getannproof-blockhash": "The hash of the block",
+	"getannproof-position":  "The position of the announcement in the PacketCrypt proof of the block, from 0 to 3",
+
+	// GetAnnProofResult help.
+	"getannproofresult-hex":          "The serialized, hex-encoded proof",
+	"getannproofresult-blockhash":    "The hash of the block",
+	"getannproofresult-height":       "The height of the block, as committed to by its coinbase",
+	"getannproofresult-position":     "The position of the announcement in the PacketCrypt proof of the block",
+	"getannproofresult-annhash":      "The hex-encoded hash of the announcement in the announcement tree",
+	"getannproofresult-parentheight": "The height of the parent block of the announcement",
+	"getannproofresult-workbits":     "The compact target the announcement was mined at",
+	"getannproofresult-agedbits":     "The compact target of the announcement aged to the height of the block",
+	"getannproofresult-annminbits":   "The minimum announcement target committed to by the block",
+	"getannproofresult-anncount":     "The number of announcements committed to by the block",
+	"getannproofresult-signingkey":   "The hex-encoded signing key of the announcement (omitted when it is not signed)",
+
+	// GetMiningAnalyticsCmd help.
+	"getmininganalytics--synopsis": "Summarizes how the announcement and block mining work composed the effective targets of the blocks of a window, so the ratio of announcement to block mining can be tuned.",
+	"getmininganalytics-blocks":    "The number of blocks of the window, up to 10080",
+	"getmininganalytics-height":    "The height of the last block of the window or -1 for the best block",
+
+	// GetMiningAnalyticsResult help.
+	"getmininganalyticsresult-startheight":            "The height of the first block of the window",
+	"getmininganalyticsresult-endheight":              "The height of the last block of the window",
+	"getmininganalyticsresult-blocks":                 "The number of blocks of the window",
+	"getmininganalyticsresult-annblocks":              "The number of blocks with an announcement commitment",
+	"getmininganalyticsresult-anncount":               "The number of announcements the blocks were mined with",
+	"getmininganalyticsresult-minanncount":            "The least announcements a block with an announcement commitment was mined with",
+	"getmininganalyticsresult-maxanncount":            "The most announcements a block was mined with",
+	"getmininganalyticsresult-avganncount":            "The average number of announcements of the blocks with an announcement commitment",
+	"getmininganalyticsresult-avgdifficulty":          "The average difficulty of the header targets",
+	"getmininganalyticsresult-avganndifficulty":       "The average difficulty of the announcements with the least work of the blocks with an announcement commitment",
+	"getmininganalyticsresult-avgeffectivedifficulty": "The average difficulty of the effective targets",
+	"getmininganalyticsresult-headerwork":             "The hex-encoded work of the header targets",
+	"getmininganalyticsresult-annwork":                "The hex-encoded work of the announcements",
+	"getmininganalyticsresult-effectivework":          "The hex-encoded work of the effective targets",
+	"getmininganalyticsresult-annworkshare":           "The share of the announcement work in the total announcement and block mining work",
+	"getmininganalyticsresult-workreduction":          "The average ratio of the header work to the effective work of the blocks with an announcement commitment",
+
+	// GetDifficultyHistoryCmd help.
+	"getdifficultyhistory--synopsis": "Returns the block and announcement difficulties of blocks of the main chain, in height order.",
+	"getdifficultyhistory-count":     "The maximum number of blocks, up to 1000",
+	"getdifficultyhistory-height":    "The height of the last block or -1 for the best block",
+	"getdifficultyhistory-interval":  "The number of blocks between two blocks of the series",
+	"getdifficultyhistory--result0":  "The difficulties of each block",
+
+	// DifficultyHistoryResult help.
+	"difficultyhistoryresult-height":              "The height of the block",
+	"difficultyhistoryresult-hash":                "The hash of the block",
+	"difficultyhistoryresult-time":                "The block time in seconds since 1 Jan 1970 GMT",
+	"difficultyhistoryresult-bits":                "The header target of the block in compact form",
+	"difficultyhistoryresult-difficulty":          "The difficulty of the header target",
+	"difficultyhistoryresult-anncount":            "The number of announcements the block was mined with (0 without an announcement commitment)",
+	"difficultyhistoryresult-annminbits":          "The target of the announcement with the least work in compact form",
+	"difficultyhistoryresult-anndifficulty":       "The difficulty of the announcement with the least work",
+	"difficultyhistoryresult-effectivebits":       "The target the block was mined at given its announcements in compact form",
+	"difficultyhistoryresult-effectivedifficulty": "The difficulty of the effective target",
+
+	// GetBlockUndoCmd help.
+	"getblockundo--synopsis": "Returns the undo data of a block of the main chain, the outputs to remove from and restore to the UTXO set to bring it back to its state before the block was connected.\n" +
+		"The undo data of a block is no longer available once it is disconnected from the main chain.",
+	"getblockundo-hash": "The hash of the block",
+
+	// BlockUndoResult help.
+	"blockundoresult-hash":         "The hash of the block",
+	"blockundoresult-previoushash": "The hash of the previous block",
+	"blockundoresult-height":       "The height of the block",
+	"blockundoresult-created":      "The outputs created by the block, removed from the UTXO set when it is disconnected",
+	"blockundoresult-spent":        "The outputs spent by the block in the order of the inputs spending them, restored to the UTXO set when it is disconnected",
+
+	// GetUtxoDeltasCmd help.
+	"getutxodeltas--synopsis": "Returns the unspent transaction outputs created and spent by blocks of the main chain, so indexers can follow the UTXO set without fetching the spent outputs.\n" +
+		"Websocket clients can receive the changes of the following blocks as utxodeltas notifications with notifyutxodeltas.",
+	"getutxodeltas-startheight": "The height of the first block",
+	"getutxodeltas-count":       "The maximum number of blocks, up to 1000",
+	"getutxodeltas--result0":    "The outputs created and spent by each block",
+
+	// UtxoDeltasResult help.
+	"utxodeltasresult-type":         "Whether the block was connected to or disconnected from the main chain (connected, disconnected)",
+	"utxodeltasresult-hash":         "The hash of the block",
+	"utxodeltasresult-previoushash": "The hash of the previous block",
+	"utxodeltasresult-height":       "The height of the block",
+	"utxodeltasresult-created":      "The outputs created by the block",
+	"utxodeltasresult-spent":        "The outputs spent by the block",
+
+	// GetBlockHeadersCmd help.
+	"getblockheaders--synopsis": "Returns contiguous headers of the main chain in one call, either concatenated in their serialized form or as JSON objects.\n" +
+		"Websocket clients can receive longer ranges as blockheaders notifications with streamblockheaders.",
+	"getblockheaders-startheight": "The height of the first header",
+	"getblockheaders-count":       "The maximum number of headers, up to 2000",
+	"getblockheaders-verbose":     "Specifies the headers are returned as JSON objects instead of a hex-encoded string",
+
+	// BlockHeadersResult help.
+	"blockheadersresult-startheight": "The height of the first header",
+	"blockheadersresult-count":       "The number of headers",
+	"blockheadersresult-hex":         "The hex-encoded serialized headers, 80 bytes each (only when verbose=false)",
+	"blockheadersresult-headers":     "The headers (only when verbose=true)",
+
+	// ChainEventUtxo help.
+	"chaineventutxo-txid":         "The hash of the transaction which created the output",
+	"chaineventutxo-vout":         "The index of the output",
+	"chaineventutxo-amount":       "The value of the output in BTC",
+	"chaineventutxo-scriptpubkey": "The hex-encoded output script",
+	"chaineventutxo-address":      "The address the output pays to, if any",
+	"chaineventutxo-height":       "The height of the block which created the output",
+	"chaineventutxo-coinbase":     "Whether the output was created by a coinbase transaction",
+
+	// GetUtxoStatsCmd help.
+	"getutxostats--synopsis": "Returns the number and value of the unspent transaction outputs by script class, along with the outputs considered dust and histograms of their values.\n" +
+		"The statistics are kept by the utxo statistics index (--utxostatsindex), which records a snapshot of them every --utxostatsinterval blocks.",
+	"getutxostats-height": "Return the latest snapshot at or below this height instead of the statistics at the best block",
+
+	// GetUtxoStatsResult help.
+	"getutxostatsresult-height":       "The height of the block the statistics were recorded at",
+	"getutxostatsresult-hash":         "The hash of the block the statistics were recorded at",
+	"getutxostatsresult-dustrelayfee": "The minimum relay fee in BTC/KB used to tell whether an output is dust",
+	"getutxostatsresult-total":        "The statistics of all unspent outputs",
+	"getutxostatsresult-classes":      "The statistics of the unspent outputs of each script class",
+
+	// UtxoClassStatsResult help.
+	"utxoclassstatsresult-class":      "The script class of the outputs",
+	"utxoclassstatsresult-count":      "The number of unspent outputs",
+	"utxoclassstatsresult-amount":     "The total value of the unspent outputs in BTC",
+	"utxoclassstatsresult-dustcount":  "The number of unspent outputs which are dust",
+	"utxoclassstatsresult-dustamount": "The total value of the unspent outputs which are dust in BTC",
+	"utxoclassstatsresult-histogram":  "The non-empty buckets of the histogram of the output values",
+
+	// UtxoHistogramBucket help.
+	"utxohistogrambucket-minvalue": "The lowest output value of the bucket in atomic units",
+	"utxohistogrambucket-maxvalue": "The highest output value of the bucket in atomic units",
+	"utxohistogrambucket-count":    "The number of unspent outputs whose value is in the bucket",
+
+	// GetFeeHistoryCmd help.
+	"getfeehistory--synopsis": "Returns the fees paid by the transactions of the main chain blocks in a range of heights or timestamps, leaving out the coinbases.\n" +
+		"The statistics are kept by the fee statistics index (--feestatsindex).  At most 2016 blocks are returned, in order of height, or of timestamp when the range is of timestamps.",
+	"getfeehistory-start":  "The first height of the range, or its first timestamp in seconds since 1 Jan 1970 GMT",
+	"getfeehistory-end":    "The last height or timestamp of the range, included (default: the best block)",
+	"getfeehistory-bytime": "Whether the range is of timestamps rather than heights",
+
+	// GetFeeHistoryResult help.
+	"getfeehistoryresult-percentiles": "The percentiles of the fee rates returned for each block",
+	"getfeehistoryresult-truncated":   "Whether more blocks are in the range than returned",
+	"getfeehistoryresult-blocks":      "The fee statistics of the blocks",
+
+	// FeeHistoryBlock help.
+	"feehistoryblock-height":             "The height of the block",
+	"feehistoryblock-time":               "The timestamp of the block in seconds since 1 Jan 1970 GMT",
+	"feehistoryblock-txcount":            "The number of transactions of the block, leaving out the coinbase",
+	"feehistoryblock-vsize":              "The total virtual size of the transactions",
+	"feehistoryblock-totalfee":           "The total fee paid by the transactions in BTC",
+	"feehistoryblock-minfeerate":         "The lowest fee rate paid by a transaction of the block in BTC/KB",
+	"feehistoryblock-maxfeerate":         "The highest fee rate paid by a transaction of the block in BTC/KB",
+	"feehistoryblock-feeratepercentiles": "The fee rates in BTC/KB at the percentiles, weighted by virtual size",
+
 	// HelpCmd help.
 	"help--synopsis":   "Returns a list of all commands or help for a specified command.",
 	"help-command":     "The command to retrieve help for",
@@ -554,42 +1329,111 @@ var helpDescsEnUS = map[string]string{
 	"sendrawtransaction-allowhighfees": "Whether or not to allow insanely high fees (pktd does not yet implement this parameter, so it has no effect)",
 	"sendrawtransaction--result0":      "The hash of the transaction",
 
+	// SetBlockLimitsCmd help.
+	"setblocklimits--synopsis": "Changes the limits used to assemble block templates until the server is restarted.\n" +
+		"The limits which are omitted from the object are left unchanged.",
+	"setblocklimits-limits":      "The limits to change, the ones omitted being left unchanged",
+	"blocklimits-maxweight":      "The maximum weight of the block templates",
+	"blocklimits-maxsigopcost":   "The maximum signature operation cost of the block templates",
+	"blocklimits-reservedweight": "The part of the maximum weight only used by the transactions submitted to this node with sendrawtransaction",
+	"blocklimits-prioritysize":   "The part of the block filled with high-priority transactions regardless of their fees",
+
 	// SetGenerateCmd help.
 	"setgenerate--synopsis":    "Set the server to generate coins (mine) or not.",
 	"setgenerate-generate":     "Use true to enable generation, false to disable it",
 	"setgenerate-genproclimit": "The number of processors (cores) to limit generation to or -1 for default",
 
+	// SimulateReorgCmd help.
+	"simulatereorg--synopsis": "Replaces the last blocks of the main chain with generated blocks (simnet or regtest only),\n" +
+		"causing a chain reorganization.",
+	"simulatereorg-depth":     "The number of main chain blocks to disconnect",
+	"simulatereorg-numblocks": "Number of blocks to generate from the fork point, which must exceed depth (default: depth+1)",
+	"simulatereorg-address":   "The address paid by the blocks (default: a --miningaddr address, or anyone when none is configured)",
+
+	// SimulateReorgResult help.
+	"simulatereorgresult-forkhash":     "The hash of the block the generated blocks are built on top of",
+	"simulatereorgresult-forkheight":   "The height of the fork block",
+	"simulatereorgresult-oldtip":       "The hash of the best block before the reorganization",
+	"simulatereorgresult-newtip":       "The hash of the best block after the reorganization",
+	"simulatereorgresult-newheight":    "The height of the best block after the reorganization",
+	"simulatereorgresult-disconnected": "The hashes, in order, of the blocks removed from the main chain",
+	"simulatereorgresult-connected":    "The hashes, in order, of the generated blocks",
+
 	// StopCmd help.
 	"stop--synopsis": "Shutdown pktd.",
 	"stop--result0":  "The string 'pktd stopping.'",
 
 	// SubmitBlockOptions help.
-	"submitblockoptions-workid": "This parameter is currently ignored",
+	"submitblockoptions-workid":  "This parameter is currently ignored",
+	"submitblockoptions-verbose": "Reply with an object detailing the outcome rather than a string (pktd extension)",
 
 	// SubmitBlockCmd help.
 	"submitblock--synopsis":   "Attempts to submit a new serialized, hex-encoded block to the network.",
 	"submitblock-hexblock":    "Serialized, hex-encoded block",
-	"submitblock-options":     "This parameter is currently ignored",
+	"submitblock-options":     "Options of the submission",
 	"submitblock--condition0": "Block successfully submitted",
-	"submitblock--condition1": "Block rejected",
-	"submitblock--result1":    "The reason the block was rejected",
+	"submitblock--condition1": "Block rejected, already known or not fully validated",
+	"submitblock--condition2": "verbose=true",
+	"submitblock--result1":    "The reason the block was rejected, prefixed with 'rejected: ', or one of 'inconclusive', 'duplicate', 'duplicate-invalid' and 'duplicate-inconclusive'",
+
+	// SubmitBlockResult help.
+	"submitblockresult-hash":       "The hash of the block",
+	"submitblockresult-status":     "One of 'accepted', 'rejected', 'inconclusive', 'duplicate', 'duplicate-invalid' and 'duplicate-inconclusive'",
+	"submitblockresult-reason":     "The BIP0022 reason of the rejection, if there is one",
+	"submitblockresult-rule":       "The name of the consensus rule the block violates, if known",
+	"submitblockresult-message":    "The description of the rejection",
+	"submitblockresult-txindex":    "The index of the transaction of the block the rejection is about, if any",
+	"submitblockresult-inputindex": "The index of the input of the transaction the rejection is about, if any",
+	"submitblockresult-annindex":   "The index of the PacketCrypt announcement of the block the rejection is about, if any",
+	"submitblockresult-expected":   "The value, or the limit, the violated rule requires, if known",
+	"submitblockresult-actual":     "The value of the block, if known",
 
 	// ValidateAddressResult help.
 	"validateaddresschainresult-isvalid": "Whether or not the address is valid",
 	"validateaddresschainresult-address": "The bitcoin address (only when isvalid is true)",
 
+	// TriggerGCCmd help.
+	"triggergc--synopsis":    "Runs a garbage collection.",
+	"triggergc-freeosmemory": "Also return as much memory as possible to the operating system",
+
+	// TriggerGCResult help.
+	"triggergcresult-heapallocbefore": "Bytes of allocated heap objects before the garbage collection",
+	"triggergcresult-heapallocafter":  "Bytes of allocated heap objects after the garbage collection",
+	"triggergcresult-heapreleased":    "Bytes of heap memory returned to the operating system",
+	"triggergcresult-durationms":      "The duration of the garbage collection in milliseconds",
+
 	// ValidateAddressCmd help.
 	"validateaddress--synopsis": "Verify an address is valid.",
 	"validateaddress-address":   "Bitcoin address to validate",
 
+	// VerifyAddressOwnershipCmd help.
+	"verifyaddressownership--synopsis": "Verifies a proof, created by the proveaddressownership wallet command, that the owner of a set of unspent outputs can spend them.\n" +
+		"The proof is a transaction which can never be mined whose signatures commit to the message, see BIP 127.\n" +
+		"Every output it spends must have been confirmed and unspent at the block height.\n" +
+		"The outputs spent since that height are looked up in the spend journals of the blocks after it, so older heights take longer to verify.",
+	"verifyaddressownership-proof":    "The hex-encoded proof transaction",
+	"verifyaddressownership-message":  "The message the proof must commit to",
+	"verifyaddressownership-height":   "The block height the outputs must have been unspent at, defaults to the best block height",
+	"verifyaddressownership--result0": "The result of the verification",
+
+	// VerifyAddressOwnershipResult help.
+	"verifyaddressownershipresult-valid":     "Whether or not the proof is valid",
+	"verifyaddressownershipresult-height":    "The block height the proof was verified at",
+	"verifyaddressownershipresult-amount":    "The total amount of the outputs spent by the proof in BTC",
+	"verifyaddressownershipresult-addresses": "The addresses paid by the outputs spent by the proof",
+	"verifyaddressownershipresult-error":     "The reason the proof is not valid",
+
 	// VerifyChainCmd help.
 	"verifychain--synopsis": "Verifies the block chain database.\n" +
 		"The actual checks performed by the checklevel parameter are implementation specific.\n" +
 		"For pktd this is:\n" +
 		"checklevel=0 - Look up each block and ensure it can be loaded from the database.\n" +
-		"checklevel=1 - Perform basic context-free sanity checks on each block.",
+		"checklevel=1 - Perform basic context-free sanity checks on each block.\n" +
+		"checklevel=2 - Additionally check each block in the context of its parent (difficulty, timestamps, checkpoints, finality).\n" +
+		"checklevel=3 - Additionally re-validate the PacketCrypt proof of each block.\n" +
+		"Progress is reported in the log while the verification is running.",
 	"verifychain-checklevel": "How thorough the block verification is",
-	"verifychain-checkdepth": "The number of blocks to check",
+	"verifychain-checkdepth": "The number of blocks to check, 0 to check the entire chain",
 	"verifychain--result0":   "Whether or not the chain verified",
 
 	// VerifyMessageCmd help.
@@ -599,6 +1443,12 @@ var helpDescsEnUS = map[string]string{
 	"verifymessage-message":   "The signed message",
 	"verifymessage--result0":  "Whether or not the signature verified",
 
+	// VerifyTxOutProofCmd help.
+	"verifytxoutproof--synopsis": "Verifies a proof created by gettxoutproof and returns the hashes of the transactions it proves.\n" +
+		"An empty list is returned when the proof does not match its block header, and an error when the block is not in the main chain.",
+	"verifytxoutproof-proof":    "The hex-encoded proof",
+	"verifytxoutproof--result0": "The hashes of the proven transactions",
+
 	// -------- Websocket-specific help --------
 
 	// Session help.
@@ -608,6 +1458,12 @@ var helpDescsEnUS = map[string]string{
 	// NotifyBlocksCmd help.
 	"notifyblocks--synopsis": "Request notifications for whenever a block is connected or disconnected from the main (best) chain.",
 
+	// NotifyUtxoDeltasCmd help.
+	"notifyutxodeltas--synopsis": "Send a utxodeltas notification with the outputs created and spent by every block connected to or disconnected from the main (best) chain.",
+
+	// StopNotifyUtxoDeltasCmd help.
+	"stopnotifyutxodeltas--synopsis": "Cancel registered utxodeltas notifications.",
+
 	// StopNotifyBlocksCmd help.
 	"stopnotifyblocks--synopsis": "Cancel registered notifications for whenever a block is connected or disconnected from the main (best) chain.",
 
@@ -645,6 +1501,18 @@ var helpDescsEnUS = map[string]string{
 	"loadtxfilter-addresses": "Array of addresses to add to the transaction filter",
 	"loadtxfilter-outpoints": "Array of outpoints to add to the transaction filter",
 
+	// LoadWatchListCmd help.
+	"loadwatchlist--synopsis": "Add addresses and output scripts to the watch list of the websocket client, or replace the list when reload is set.\n" +
+		"A watchedtx notification is sent for every mempool or newly mined transaction paying to a watched script or spending an output which paid to one.",
+	"loadwatchlist-reload":    "Replace the watch list instead of adding to it",
+	"loadwatchlist-addresses": "Array of addresses to watch",
+	"loadwatchlist-scripts":   "Array of hex-encoded output scripts to watch",
+
+	// RemoveWatchListCmd help.
+	"removewatchlist--synopsis": "Remove addresses and output scripts from the watch list of the websocket client.",
+	"removewatchlist-addresses": "Array of addresses to stop watching",
+	"removewatchlist-scripts":   "Array of hex-encoded output scripts to stop watching",
+
 	// Rescan help.
 	"rescan--synopsis": "Rescan block chain for transactions to addresses.\n" +
 		"When the endblock parameter is omitted, the rescan continues through the best block in the main chain.\n" +
@@ -660,6 +1528,19 @@ var helpDescsEnUS = map[string]string{
 	"rescanblocks-blockhashes": "List of hashes to rescan.  Each next block must be a child of the previous.",
 	"rescanblocks--result0":    "List of matching blocks.",
 
+	// StreamBlockHeaders help.
+	"streamblockheaders--synopsis": "Sends headers of the main chain as blockheaders notifications of up to 2000 headers.\n" +
+		"When the endheight parameter is omitted, the headers are sent through the best block in the main chain.\n" +
+		"This call returns once every header was sent.",
+	"streamblockheaders-startheight": "The height of the first header",
+	"streamblockheaders-endheight":   "The height of the last header",
+	"streamblockheaders-verbose":     "Specifies the headers are sent as JSON objects instead of a hex-encoded string",
+
+	// StreamBlockHeadersResult help.
+	"streamblockheadersresult-hash":   "The hash of the last header sent",
+	"streamblockheadersresult-height": "The height of the last header sent",
+	"streamblockheadersresult-count":  "The number of headers sent",
+
 	// RescannedBlock help.
 	"rescannedblock-hash":         "Hash of the matching block.",
 	"rescannedblock-transactions": "List of matching transactions, serialized and hex-encoded.",
@@ -681,6 +1562,22 @@ var helpDescsEnUS = map[string]string{
 	"versionresult-patch":         "The patch component of the JSON-RPC API version",
 	"versionresult-prerelease":    "Prerelease info about the current build",
 	"versionresult-buildmetadata": "Metadata about the current build",
+
+	// WaitForBlockHeightCmd help.
+	"waitforblockheight--synopsis": "Waits until the best block reaches a height or the timeout expires and returns the best block.",
+	"waitforblockheight-height":    "The height to wait for",
+	"waitforblockheight-timeout":   "The time to wait in milliseconds, or 0 to wait without limit",
+
+	// WaitForNewBlockCmd help.
+	"waitfornewblock--synopsis": "Waits until the best block changes or the timeout expires and returns the best block.",
+	"waitfornewblock-timeout":   "The time to wait in milliseconds, or 0 to wait without limit",
+
+	// WaitForBlockResult help.
+	"waitforblockresult-hash":           "The hash of the best block",
+	"waitforblockresult-height":         "The height of the best block",
+	"waitforblockresult-previoushash":   "The hash of the best block when the command was received",
+	"waitforblockresult-previousheight": "The height of the best block when the command was received",
+	"waitforblockresult-reorg":          "Whether or not the previous best block was removed from the main chain by a reorganization",
 }
 
 // rpcResultTypes specifies the result types that each RPC command can return.
@@ -688,62 +1585,113 @@ var helpDescsEnUS = map[string]string{
 // pointer to the type (or nil to indicate no return value).
 var rpcResultTypes = map[string][]interface{}{
 	"addnode":                nil,
+	"addpeeraddress":         {(*btcjson.AddPeerAddressResult)(nil)},
+	"capturemessages":        {(*btcjson.CaptureMessagesResult)(nil)},
+	"captureprofile":         {(*btcjson.CaptureProfileResult)(nil)},
 	"configureminingpayouts": nil,
+	"createdepositaddresses": {(*[]btcjson.DepositAddressResult)(nil)},
 	"createrawtransaction":   {(*string)(nil)},
 	"debuglevel":             {(*string)(nil), (*string)(nil)},
 	"decoderawtransaction":   {(*btcjson.TxRawDecodeResult)(nil)},
 	"decodescript":           {(*btcjson.DecodeScriptResult)(nil)},
 	"estimatefee":            {(*float64)(nil)},
 	"generate":               {(*[]string)(nil)},
+	"generatefork":           {(*[]string)(nil)},
+	"getauditlog":            {(*[]btcjson.AuditLogEntry)(nil)},
 	"getaddednodeinfo":       {(*[]string)(nil), (*[]btcjson.GetAddedNodeInfoResult)(nil)},
+	"getaddrmaninfo":         {(*btcjson.GetAddrManInfoResult)(nil)},
 	"getbestblock":           {(*btcjson.GetBestBlockResult)(nil)},
 	"getbestblockhash":       {(*string)(nil)},
 	"getblock":               {(*string)(nil), (*btcjson.GetBlockVerboseResult)(nil)},
+	"getblockcost":           {(*btcjson.GetBlockCostResult)(nil)},
+	"getblocktemplatelight":  {(*btcjson.GetBlockTemplateLightResult)(nil)},
+	"getannagingschedule":    {(*btcjson.GetAnnAgingScheduleResult)(nil)},
+	"getannproof":            {(*btcjson.GetAnnProofResult)(nil)},
 	"getblockcount":          {(*int64)(nil)},
 	"getblockhash":           {(*string)(nil)},
 	"getblockheader":         {(*string)(nil), (*btcjson.GetBlockHeaderVerboseResult)(nil)},
+	"getblockheaders":        {(*btcjson.BlockHeadersResult)(nil)},
 	"getblocktemplate":       {(*btcjson.GetBlockTemplateResult)(nil), (*string)(nil), nil},
+	"getblockundo":           {(*btcjson.BlockUndoResult)(nil)},
 	"getblockchaininfo":      {(*btcjson.GetBlockChainInfoResult)(nil)},
 	"getcfilter":             {(*string)(nil)},
 	"getcfilterheader":       {(*string)(nil)},
 	"getconnectioncount":     {(*int32)(nil)},
+	"getclockskew":           {(*btcjson.GetClockSkewResult)(nil)},
+	"getcoldspends":          {(*btcjson.GetColdSpendsResult)(nil)},
+	"getconsensusrules":      {(*btcjson.GetConsensusRulesResult)(nil)},
 	"getcurrentnet":          {(*uint32)(nil)},
+	"getdatacarrierinfo":     {(*btcjson.GetDataCarrierInfoResult)(nil)},
 	"getdifficulty":          {(*float64)(nil)},
+	"getdifficultyhistory":   {(*[]btcjson.DifficultyHistoryResult)(nil)},
+	"getdiskstatus":          {(*btcjson.GetDiskStatusResult)(nil)},
 	"getgenerate":            {(*bool)(nil)},
 	"gethashespersec":        {(*float64)(nil)},
 	"getheaders":             {(*[]string)(nil)},
 	"getinfo":                {(*btcjson.InfoChainResult)(nil)},
+	"getmemoryinfo":          {(*btcjson.GetMemoryInfoResult)(nil)},
+	"getmininganalytics":     {(*btcjson.GetMiningAnalyticsResult)(nil)},
+	"getpartitionstatus":     {(*btcjson.GetPartitionStatusResult)(nil)},
+	"getmempooldelta":        {(*btcjson.GetMempoolDeltaResult)(nil)},
+	"getmempoolentry":        {(*btcjson.GetMempoolEntryResult)(nil)},
 	"getmempoolinfo":         {(*btcjson.GetMempoolInfoResult)(nil)},
 	"getmininginfo":          {(*btcjson.GetMiningInfoResult)(nil)},
 	"getminingpayouts":       {(*btcjson.GetMiningPayoutsResult)(nil)},
 	"getnettotals":           {(*btcjson.GetNetTotalsResult)(nil)},
+	"getnodeaddresses":       {(*[]btcjson.GetNodeAddressesResult)(nil)},
+	"getnetworkinfo":         {(*btcjson.GetNetworkInfoResult)(nil)},
 	"getnetworksteward":      {(*btcjson.GetNetworkStewardResult)(nil)},
 	"getnetworkhashps":       {(*int64)(nil)},
+	"getnetworkhashrate":     {(*btcjson.GetNetworkHashrateResult)(nil)},
 	"getpeerinfo":            {(*[]btcjson.GetPeerInfoResult)(nil)},
 	"getrawblocktemplate":    {(*string)(nil)},
 	"checkpcshare":           {(*string)(nil)},
 	"getrawmempool":          {(*[]string)(nil), (*btcjson.GetRawMempoolVerboseResult)(nil)},
 	"getrawtransaction":      {(*string)(nil), (*btcjson.TxRawResult)(nil)},
+	"getorphanblocks":        {(*[]btcjson.OrphanBlockResult)(nil)},
+	"getorphantxs":           {(*[]string)(nil), (*[]btcjson.OrphanTxResult)(nil)},
+	"getrejectedtxs":         {(*[]btcjson.RejectedTxResult)(nil)},
+	"getsyncstatus":          {(*btcjson.GetSyncStatusResult)(nil)},
+	"getrpcinfo":             {(*btcjson.GetRPCInfoResult)(nil)},
+	"gettxfee":               {(*btcjson.GetTxFeeResult)(nil)},
 	"gettxout":               {(*btcjson.GetTxOutResult)(nil)},
+	"gettxoutproof":          {(*string)(nil)},
+	"getutxodeltas":          {(*[]btcjson.UtxoDeltasResult)(nil)},
+	"getutxostats":           {(*btcjson.GetUtxoStatsResult)(nil)},
+	"getfeehistory":          {(*btcjson.GetFeeHistoryResult)(nil)},
+	"listdeposits":           {(*[]btcjson.DepositResult)(nil)},
+	"listeners":              {(*[]btcjson.ListenerResult)(nil)},
+	"minttoken":              {(*btcjson.MintTokenResult)(nil)},
 	"node":                   nil,
 	"help":                   {(*string)(nil), (*string)(nil)},
 	"ping":                   nil,
 	"searchrawtransactions":  {(*string)(nil), (*[]btcjson.SearchRawTransactionsResult)(nil)},
 	"sendrawtransaction":     {(*string)(nil)},
+	"setblocklimits":         {(*btcjson.BlockLimitsResult)(nil)},
 	"setgenerate":            nil,
+	"simulatereorg":          {(*btcjson.SimulateReorgResult)(nil)},
 	"stop":                   {(*string)(nil)},
-	"submitblock":            {nil, (*string)(nil)},
+	"submitblock":            {nil, (*string)(nil), (*btcjson.SubmitBlockResult)(nil)},
+	"triggergc":              {(*btcjson.TriggerGCResult)(nil)},
 	"uptime":                 {(*int64)(nil)},
 	"validateaddress":        {(*btcjson.ValidateAddressChainResult)(nil)},
+	"verifyaddressownership": {(*btcjson.VerifyAddressOwnershipResult)(nil)},
 	"verifychain":            {(*bool)(nil)},
 	"verifymessage":          {(*bool)(nil)},
+	"verifytxoutproof":       {(*[]string)(nil)},
 	"version":                {(*map[string]btcjson.VersionResult)(nil)},
+	"waitforblockheight":     {(*btcjson.WaitForBlockResult)(nil)},
+	"waitfornewblock":        {(*btcjson.WaitForBlockResult)(nil)},
 
 	// Websocket commands.
 	"loadtxfilter":              nil,
+	"loadwatchlist":             nil,
+	"removewatchlist":           nil,
 	"session":                   {(*btcjson.SessionResult)(nil)},
 	"notifyblocks":              nil,
 	"stopnotifyblocks":          nil,
+	"notifyutxodeltas":          nil,
+	"stopnotifyutxodeltas":      nil,
 	"notifynewtransactions":     nil,
 	"stopnotifynewtransactions": nil,
 	"notifyreceived":            nil,
@@ -752,6 +1700,7 @@ var rpcResultTypes = map[string][]interface{}{
 	"stopnotifyspent":           nil,
 	"rescan":                    nil,
 	"rescanblocks":              {(*[]btcjson.RescannedBlock)(nil)},
+	"streamblockheaders":        {(*btcjson.StreamBlockHeadersResult)(nil)},
 }
 
 // helpCacher provides a concurrent safe type that provides help and usage for
@@ -0,0 +1,152 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"time"
+
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/btcjson"
+	"github.com/pkt-cash/pktd/netsync"
+)
+
+// syncEstimate is the estimated progress of the sync with the chain of the
+// network.
+type syncEstimate struct {
+	status *netsync.SyncStatus
+	best   *blockchain.BestState
+
+	// height is the estimated height of the chain of the network,
+	// progress the fraction of it which was verified and remaining the
+	// estimated number of seconds left, or -1 when unknown.
+	height    int32
+	progress  float64
+	remaining int64
+}
+
+// estimateSync returns the estimated progress of the sync.
+func (s *rpcServer) estimateSync() (*syncEstimate, error) {
+	status := s.cfg.SyncMgr.SyncStatus()
+	best := s.cfg.Chain.BestSnapshot()
+	header, err := s.cfg.Chain.HeaderByHash(&best.Hash)
+	if err != nil {
+		return nil, err
+	}
+	height := estimateNetworkHeight(best.Height, header.Timestamp,
+		status.HeaderHeight, status.BestPeerHeight, status.Current,
+		time.Now(), s.cfg.ChainParams.TargetTimePerBlock)
+	return &syncEstimate{
+		status:    status,
+		best:      best,
+		height:    height,
+		progress:  syncProgress(best.Height, height),
+		remaining: syncTimeRemaining(best.Height, height, status.BlockRate),
+	}, nil
+}
+
+// estimateNetworkHeight returns the estimated height of the chain of the
+// network, which is the highest of the latest known header, the latest block
+// announced by the peers and the height the chain would have if blocks had
+// kept being mined at the target rate since the best block.  The latter is
+// what tells how far behind the node is before any peer is connected, but it
+// is not used once the node is current since the time between blocks varies.
+func estimateNetworkHeight(bestHeight int32, bestTime time.Time, headerHeight,
+	peerHeight int32, current bool, now time.Time,
+	targetTimePerBlock time.Duration) int32 {
+
+	height := bestHeight
+	if headerHeight > height {
+		height = headerHeight
+	}
+	if peerHeight > height {
+		height = peerHeight
+	}
+	if !current && targetTimePerBlock > 0 && now.After(bestTime) {
+		missed := int64(now.Sub(bestTime) / targetTimePerBlock)
+		if projected := int64(bestHeight) + missed; projected > int64(height) &&
+			projected <= math.MaxInt32 {
+
+			height = int32(projected)
+		}
+	}
+	return height
+}
+
+// syncProgress returns the fraction of the estimated height of the chain of
+// the network which was verified, between 0 and 1.
+func syncProgress(bestHeight, height int32) float64 {
+	if height <= 0 || bestHeight >= height {
+		return 1
+	}
+	if bestHeight < 0 {
+		return 0
+	}
+	return float64(bestHeight) / float64(height)
+}
+
+// syncTimeRemaining returns the estimated number of seconds left to reach the
+// estimated height of the chain of the network at the passed number of blocks
+// per second, or -1 when no block is being connected.
+func syncTimeRemaining(bestHeight, height int32, rate float64) int64 {
+	if bestHeight >= height {
+		return 0
+	}
+	if rate <= 0 {
+		return -1
+	}
+	return int64(math.Ceil(float64(height-bestHeight) / rate))
+}
+
+// syncStageResult returns the result of a stage of the sync.
+func syncStageResult(stage blockchain.StageStats) btcjson.SyncStageResult {
+	return btcjson.SyncStageResult{
+		Count:   stage.Count,
+		Seconds: stage.Duration.Seconds(),
+	}
+}
+
+// handleGetSyncStatus implements the getsyncstatus command.
+func handleGetSyncStatus(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	estimate, err := s.estimateSync()
+	if err != nil {
+		context := "Failed to estimate the progress of the sync"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	status := estimate.status
+	validation := s.cfg.Chain.ValidationStats()
+
+	recv, sent := s.cfg.ConnMgr.NetTotals()
+	bandwidth := btcjson.SyncBandwidthResult{
+		TotalBytesRecv: recv,
+		TotalBytesSent: sent,
+	}
+	uptime := time.Now().Unix() - s.cfg.StartupTime
+	if uptime > 0 {
+		bandwidth.RecvRate = float64(recv) / float64(uptime)
+		bandwidth.SentRate = float64(sent) / float64(uptime)
+	}
+
+	return &btcjson.GetSyncStatusResult{
+		Blocks:                 estimate.best.Height,
+		Headers:                status.HeaderHeight,
+		BestPeerHeight:         status.BestPeerHeight,
+		EstimatedHeight:        estimate.height,
+		SyncPeer:               status.SyncPeerID,
+		HeadersFirst:           status.HeadersFirstMode,
+		PresyncPhase:           status.PresyncPhase,
+		InitialBlockDownload:   !status.Current,
+		VerificationProgress:   estimate.progress,
+		BlocksPerSecond:        status.BlockRate,
+		EstimatedTimeRemaining: estimate.remaining,
+		Stages: btcjson.SyncStagesResult{
+			Headers: syncStageResult(status.Headers),
+			Blocks:  syncStageResult(status.Blocks),
+			Proofs:  syncStageResult(validation.Proofs),
+			Scripts: syncStageResult(validation.Scripts),
+		},
+		Bandwidth: bandwidth,
+	}, nil
+}
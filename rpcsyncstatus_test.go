@@ -0,0 +1,88 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// TestSyncEstimate ensures the height of the chain of the network, the
+// verification progress and the time remaining are estimated as expected.
+func TestSyncEstimate(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	target := time.Minute
+
+	tests := []struct {
+		name         string
+		bestHeight   int32
+		bestAge      time.Duration
+		headerHeight int32
+		peerHeight   int32
+		current      bool
+		rate         float64
+		height       int32
+		progress     float64
+		remaining    int64
+	}{
+		{
+			name:       "synced",
+			bestHeight: 1000,
+			bestAge:    10 * time.Minute,
+			peerHeight: 1000,
+			current:    true,
+			height:     1000,
+			progress:   1,
+			remaining:  0,
+		},
+		{
+			name:       "no peers",
+			bestHeight: 1000,
+			bestAge:    1000 * time.Minute,
+			height:     2000,
+			progress:   0.5,
+			remaining:  -1,
+		},
+		{
+			name:         "headers ahead",
+			bestHeight:   500,
+			bestAge:      1000 * time.Minute,
+			headerHeight: 2000,
+			peerHeight:   1800,
+			rate:         10,
+			height:       2000,
+			progress:     0.25,
+			remaining:    150,
+		},
+		{
+			name:       "peers ahead",
+			bestHeight: 999,
+			peerHeight: 1003,
+			rate:       3,
+			height:     1003,
+			progress:   999.0 / 1003,
+			remaining:  2,
+		},
+	}
+	for _, test := range tests {
+		height := estimateNetworkHeight(test.bestHeight,
+			now.Add(-test.bestAge), test.headerHeight,
+			test.peerHeight, test.current, now, target)
+		if height != test.height {
+			t.Errorf("%s: estimated height %d, want %d", test.name,
+				height, test.height)
+			continue
+		}
+		if p := syncProgress(test.bestHeight, height); p != test.progress {
+			t.Errorf("%s: progress %v, want %v", test.name, p,
+				test.progress)
+		}
+		remaining := syncTimeRemaining(test.bestHeight, height, test.rate)
+		if remaining != test.remaining {
+			t.Errorf("%s: time remaining %d, want %d", test.name,
+				remaining, test.remaining)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/btcjson"
+)
+
+// tipEventsKeepAlive is the interval at which a comment is written to tip
+// event streams while the best block does not change, so idle connections are
+// not closed by proxies and clients can detect dead servers.
+const tipEventsKeepAlive = 30 * time.Second
+
+// tipChangeChan returns a channel which is closed the next time a block is
+// connected to the main chain.
+func (s *rpcServer) tipChangeChan() <-chan struct{} {
+	s.tipLock.Lock()
+	defer s.tipLock.Unlock()
+
+	if s.tipChanged == nil {
+		s.tipChanged = make(chan struct{})
+	}
+	return s.tipChanged
+}
+
+// notifyTipChanged wakes everything waiting on a channel returned by
+// tipChangeChan.
+func (s *rpcServer) notifyTipChanged() {
+	s.tipLock.Lock()
+	if s.tipChanged != nil {
+		close(s.tipChanged)
+		s.tipChanged = nil
+	}
+	s.tipLock.Unlock()
+}
+
+// tipResult returns the result describing the change of the best block from
+// prev to best.  The change is a reorganization when prev is no longer part of
+// the main chain.
+func (s *rpcServer) tipResult(prev, best *blockchain.BestState) *btcjson.WaitForBlockResult {
+	return &btcjson.WaitForBlockResult{
+		Hash:           best.Hash.String(),
+		Height:         best.Height,
+		PreviousHash:   prev.Hash.String(),
+		PreviousHeight: prev.Height,
+		Reorg:          !s.cfg.Chain.MainChainHasBlock(&prev.Hash),
+	}
+}
+
+// waitForTip waits until done returns true for the best block when the call
+// started and the current best block, the timeout in milliseconds expires or
+// the server shuts down, and returns the change of the best block.  A nil or
+// zero timeout waits without limit.
+func (s *rpcServer) waitForTip(timeoutMillis *int64, done func(prev, best *blockchain.BestState) bool,
+	closeChan <-chan struct{}) (interface{}, error) {
+
+	var timeout int64
+	if timeoutMillis != nil {
+		timeout = *timeoutMillis
+	}
+	if timeout < 0 {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Timeout must not be negative",
+		}
+	}
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(time.Duration(timeout) * time.Millisecond)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	prev := s.cfg.Chain.BestSnapshot()
+	for {
+		// Fetch the channel before the best block so a block connected
+		// in between is not missed.
+		changed := s.tipChangeChan()
+		best := s.cfg.Chain.BestSnapshot()
+		if done(prev, best) {
+			return s.tipResult(prev, best), nil
+		}
+
+		select {
+		case <-changed:
+		case <-expired:
+			return s.tipResult(prev, best), nil
+		case <-s.quit:
+			return s.tipResult(prev, best), nil
+		case <-closeChan:
+			return nil, ErrClientQuit
+		}
+	}
+}
+
+// writeTipEvent writes the change of the best block from prev to best to a
+// tip event stream.
+func (s *rpcServer) writeTipEvent(w http.ResponseWriter, prev, best *blockchain.BestState) error {
+	data, err := json.Marshal(s.tipResult(prev, best))
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: tip\ndata: %s\n\n", data)
+	return err
+}
+
+// handleTipEvents streams the changes of the best block to the client as
+// server-sent events until it disconnects or the server shuts down.  The first
+// event describes the best block when the stream is opened, with the previous
+// block set to the same block.
+func (s *rpcServer) handleTipEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "500 Streaming unsupported.",
+			http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	keepAlive := time.NewTicker(tipEventsKeepAlive)
+	defer keepAlive.Stop()
+
+	prev := s.cfg.Chain.BestSnapshot()
+	if err := s.writeTipEvent(w, prev, prev); err != nil {
+		return
+	}
+	flusher.Flush()
+	for {
+		changed := s.tipChangeChan()
+		best := s.cfg.Chain.BestSnapshot()
+		if best.Hash != prev.Hash {
+			if err := s.writeTipEvent(w, prev, best); err != nil {
+				return
+			}
+			flusher.Flush()
+			prev = best
+			continue
+		}
+
+		select {
+		case <-changed:
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		case <-s.quit:
+			return
+		}
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/btcjson"
+)
+
+// TestTipWaiters ensures waitfornewblock, waitforblockheight and the tip event
+// stream return when the best block changes and report reorganizations.
+func TestTipWaiters(t *testing.T) {
+	tc, teardown := newTestChain(t, "tipevents", nil)
+	defer teardown()
+	params, chain, g := tc.params, tc.chain, tc.gen
+
+	s := &rpcServer{
+		cfg:  rpcserverConfig{Chain: chain},
+		quit: make(chan int),
+	}
+	defer close(s.quit)
+	chain.Subscribe(func(n *blockchain.Notification) {
+		if n.Type == blockchain.NTBlockConnected {
+			s.notifyTipChanged()
+		}
+	})
+
+	// A timeout returns the unchanged best block.
+	cmd := btcjson.NewWaitForNewBlockCmd(btcjson.Int64(10))
+	result, err := handleWaitForNewBlock(s, cmd, nil)
+	if err != nil {
+		t.Fatalf("waitfornewblock: %v", err)
+	}
+	tip := result.(*btcjson.WaitForBlockResult)
+	if tip.Height != 0 || tip.Hash != params.GenesisHash.String() ||
+		tip.PreviousHash != tip.Hash || tip.Reorg {
+		t.Fatalf("unexpected result %+v", tip)
+	}
+	cmd = btcjson.NewWaitForNewBlockCmd(btcjson.Int64(-1))
+	if _, err := handleWaitForNewBlock(s, cmd, nil); err == nil {
+		t.Fatal("negative timeout accepted")
+	}
+
+	// Open a tip event stream and read the event of the current tip.
+	httpServer := httptest.NewServer(http.HandlerFunc(s.handleTipEvents))
+	defer httpServer.Close()
+	resp, err := http.Get(httpServer.URL)
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	defer resp.Body.Close()
+	events := bufio.NewReader(resp.Body)
+	readEvent := func() *btcjson.WaitForBlockResult {
+		for {
+			line, err := events.ReadString('\n')
+			if err != nil {
+				t.Fatalf("ReadString: %v", err)
+			}
+			if !strings.HasPrefix(line, "data: ") {
+				continue
+			}
+			var event btcjson.WaitForBlockResult
+			err = json.Unmarshal([]byte(line[len("data: "):]), &event)
+			if err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			return &event
+		}
+	}
+	if event := readEvent(); event.Height != 0 || event.PreviousHeight != 0 {
+		t.Fatalf("unexpected first event %+v", event)
+	}
+
+	// Waiting for a height returns once the chain reaches it.
+	results := make(chan interface{}, 1)
+	go func() {
+		cmd := btcjson.NewWaitForBlockHeightCmd(2, nil)
+		result, err := handleWaitForBlockHeight(s, cmd, nil)
+		if err != nil {
+			result = err
+		}
+		results <- result
+	}()
+	hashes, err := g.generate(params.GenesisHash, 2, nil)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	select {
+	case result := <-results:
+		tip, ok := result.(*btcjson.WaitForBlockResult)
+		if !ok || tip.Height != 2 || tip.Hash != hashes[1].String() ||
+			tip.Reorg {
+			t.Fatalf("unexpected waitforblockheight result %v", result)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("waitforblockheight did not return")
+	}
+	for {
+		event := readEvent()
+		if event.Reorg {
+			t.Fatalf("unexpected event %+v", event)
+		}
+		if event.Height == 2 {
+			break
+		}
+	}
+
+	// Waiting stops when the client goes away.
+	closeChan := make(chan struct{})
+	close(closeChan)
+	_, err = handleWaitForNewBlock(s, btcjson.NewWaitForNewBlockCmd(nil),
+		closeChan)
+	if err != ErrClientQuit {
+		t.Fatalf("waitfornewblock: unexpected error %v", err)
+	}
+
+	// A reorganization is reported along with the previous best block.
+	if _, err := g.simulateReorg(1, 2, nil); err != nil {
+		t.Fatalf("simulateReorg: %v", err)
+	}
+	var reorged bool
+	for {
+		event := readEvent()
+		if event.Reorg && event.PreviousHash == hashes[1].String() {
+			reorged = true
+		}
+		if event.Hash == chain.BestSnapshot().Hash.String() {
+			break
+		}
+	}
+	if !reorged {
+		t.Fatal("reorganization not reported")
+	}
+}
@@ -0,0 +1,256 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/acme"
+)
+
+// keyPairCheckInterval is the minimum time between two checks of the RPC
+// certificate and key files for changes.
+const keyPairCheckInterval = 10 * time.Second
+
+// rpcKeyPair serves the RPC TLS certificate and reloads it when its files
+// change, so certificates can be rotated without restarting the node.  The
+// files are checked on TLS handshakes, at most every keyPairCheckInterval.
+type rpcKeyPair struct {
+	certFile string
+	keyFile  string
+
+	mtx     sync.Mutex
+	cert    *tls.Certificate
+	certMod time.Time
+	keyMod  time.Time
+	checked time.Time
+
+	// challenge, when not nil, returns the certificate answering an ACME
+	// TLS-ALPN-01 challenge for the passed server name.
+	challenge func(serverName string) (*tls.Certificate, error)
+}
+
+// loadRPCKeyPair loads the RPC certificate and key from the passed files.
+func loadRPCKeyPair(certFile, keyFile string) (*rpcKeyPair, error) {
+	k := &rpcKeyPair{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+	if err := k.load(time.Now()); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// modTimes returns the modification times of the certificate and key files.
+func (k *rpcKeyPair) modTimes() (time.Time, time.Time, error) {
+	certInfo, err := os.Stat(k.certFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	keyInfo, err := os.Stat(k.keyFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return certInfo.ModTime(), keyInfo.ModTime(), nil
+}
+
+// load reads the certificate and key files.  The current certificate is kept
+// when they can't be loaded.
+//
+// This function MUST be called with the key pair lock held (for writes) or
+// before the key pair is shared.
+func (k *rpcKeyPair) load(now time.Time) error {
+	k.checked = now
+	certMod, keyMod, err := k.modTimes()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(k.certFile, k.keyFile)
+	if err != nil {
+		return err
+	}
+	cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return err
+	}
+	k.cert = &cert
+	k.certMod = certMod
+	k.keyMod = keyMod
+	return nil
+}
+
+// Certificate returns the current certificate, after reloading it when its
+// files changed since they were last loaded.
+func (k *rpcKeyPair) Certificate(now time.Time) *tls.Certificate {
+	k.mtx.Lock()
+	defer k.mtx.Unlock()
+
+	if now.Sub(k.checked) < keyPairCheckInterval {
+		return k.cert
+	}
+	k.checked = now
+	certMod, keyMod, err := k.modTimes()
+	if err != nil {
+		rpcsLog.Warnf("Unable to check the RPC certificate files: %v",
+			err)
+		return k.cert
+	}
+	if certMod.Equal(k.certMod) && keyMod.Equal(k.keyMod) {
+		return k.cert
+	}
+
+	// The files may be caught between the writes of the certificate and
+	// of the key, in which case the reload is attempted again with the
+	// next check.
+	if err := k.load(now); err != nil {
+		rpcsLog.Warnf("Unable to reload the RPC certificate, keeping "+
+			"the current one: %v", err)
+		return k.cert
+	}
+	rpcsLog.Infof("Reloaded the RPC certificate, valid until %v",
+		k.cert.Leaf.NotAfter)
+	return k.cert
+}
+
+// Set replaces the current certificate, once it has been written to the
+// certificate and key files.
+func (k *rpcKeyPair) Set(cert *tls.Certificate) {
+	k.mtx.Lock()
+	k.cert = cert
+	k.certMod, k.keyMod, _ = k.modTimes()
+	k.checked = time.Now()
+	k.mtx.Unlock()
+}
+
+// GetCertificate returns the certificate to present to a TLS client.  It
+// answers the ACME TLS-ALPN-01 challenges when certificates are obtained from
+// an ACME certificate authority.  It is suitable for tls.Config.
+func (k *rpcKeyPair) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	if k.challenge != nil && len(hello.SupportedProtos) == 1 &&
+		hello.SupportedProtos[0] == acme.ALPNProto {
+
+		return k.challenge(hello.ServerName)
+	}
+	return k.Certificate(time.Now()), nil
+}
+
+// rpcTLSConfig returns the TLS configuration of the RPC listeners serving the
+// certificate of the passed key pair.  Client certificates are requested and
+// verified when client certificate authorities are passed, and required when
+// requireClientCert is set.
+func rpcTLSConfig(keyPair *rpcKeyPair, clientCAs *rpcClientCAs,
+	requireClientCert bool) *tls.Config {
+
+	tlsConfig := &tls.Config{
+		GetCertificate: keyPair.GetCertificate,
+		MinVersion:     tls.VersionTLS12,
+	}
+	if keyPair.challenge != nil {
+		tlsConfig.NextProtos = []string{"http/1.1", acme.ALPNProto}
+	}
+	if clientCAs != nil {
+		tlsConfig.ClientCAs = clientCAs.all
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		if requireClientCert {
+			tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	}
+	return tlsConfig
+}
+
+// rpcClientCAs holds the certificate authorities whose client certificates
+// authenticate RPC connections.  Admin access is granted to the certificates
+// of the admin authorities and limited access to the certificates of the
+// limited authorities.
+type rpcClientCAs struct {
+	admin   *x509.CertPool
+	limited *x509.CertPool
+
+	// all holds both the admin and the limited authorities, for the TLS
+	// handshake.
+	all *x509.CertPool
+}
+
+// loadRPCClientCAs loads the admin and limited client certificate authorities
+// from the passed PEM files.  It returns nil when neither file is passed.
+func loadRPCClientCAs(adminFile, limitedFile string) (*rpcClientCAs, error) {
+	if adminFile == "" && limitedFile == "" {
+		return nil, nil
+	}
+	c := &rpcClientCAs{all: x509.NewCertPool()}
+	var err error
+	if c.admin, err = c.loadPool(adminFile); err != nil {
+		return nil, err
+	}
+	if c.limited, err = c.loadPool(limitedFile); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// loadPool returns the pool of the certificates of the passed PEM file, or nil
+// when no file is passed, and adds them to the pool of all the authorities.
+func (c *rpcClientCAs) loadPool(file string) (*x509.CertPool, error) {
+	if file == "" {
+		return nil, nil
+	}
+	pem, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificate found in %s", file)
+	}
+	c.all.AppendCertsFromPEM(pem)
+	return pool, nil
+}
+
+// errNoClientCert is returned when a connection has no verified client
+// certificate.
+var errNoClientCert = errors.New("no verified client certificate")
+
+// Authenticate returns whether the verified client certificate of the passed
+// TLS connection grants admin access rather than limited access.  An error is
+// returned when the connection has no client certificate issued by one of the
+// authorities.
+func (c *rpcClientCAs) Authenticate(state *tls.ConnectionState) (bool, error) {
+	if state == nil || len(state.PeerCertificates) == 0 ||
+		len(state.VerifiedChains) == 0 {
+
+		return false, errNoClientCert
+	}
+	leaf := state.PeerCertificates[0]
+	intermediates := x509.NewCertPool()
+	for _, cert := range state.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	verifies := func(roots *x509.CertPool) bool {
+		if roots == nil {
+			return false
+		}
+		_, err := leaf.Verify(x509.VerifyOptions{
+			Roots:         roots,
+			Intermediates: intermediates,
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		})
+		return err == nil
+	}
+	switch {
+	case verifies(c.admin):
+		return true, nil
+	case verifies(c.limited):
+		return false, nil
+	}
+	return false, errNoClientCert
+}
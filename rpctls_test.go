@@ -0,0 +1,317 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/acme"
+)
+
+// testCertificate returns a certificate for the passed name signed by the
+// passed parent, or self-signed when parent is nil, along with its key.
+func testCertificate(t *testing.T, name string, isCA bool,
+	usage x509.ExtKeyUsage, notAfter time.Time, parent *x509.Certificate,
+	parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		t.Fatalf("rand.Int: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: name},
+		DNSNames:              []string{name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{usage},
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	if isCA {
+		template.KeyUsage |= x509.KeyUsageCertSign
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent,
+		&key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("CreateCertificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("ParseCertificate: %v", err)
+	}
+	return cert, key
+}
+
+// writeTestKeyPair writes the passed certificate and key to PEM files.
+func writeTestKeyPair(t *testing.T, certFile, keyFile string,
+	cert *x509.Certificate, key *ecdsa.PrivateKey) {
+
+	certPEM := pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: cert.Raw,
+	})
+	keyPEM, err := marshalECKey(key)
+	if err != nil {
+		t.Fatalf("marshalECKey: %v", err)
+	}
+	if err := writeFileAtomic(certFile, certPEM, 0600); err != nil {
+		t.Fatalf("writeFileAtomic: %v", err)
+	}
+	if err := writeFileAtomic(keyFile, keyPEM, 0600); err != nil {
+		t.Fatalf("writeFileAtomic: %v", err)
+	}
+}
+
+// TestRPCKeyPairReload ensures the RPC certificate is reloaded when its files
+// change, and kept when they can't be loaded.
+func TestRPCKeyPairReload(t *testing.T) {
+	setLogLevels("off")
+	defer setLogLevels(defaultLogLevel)
+
+	tmpDir, err := ioutil.TempDir("", "pktdrpctls")
+	if err != nil {
+		t.Fatalf("Failed creating a temporary directory: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	certFile := filepath.Join(tmpDir, "rpc.cert")
+	keyFile := filepath.Join(tmpDir, "rpc.key")
+
+	notAfter := time.Now().Add(24 * time.Hour)
+	first, firstKey := testCertificate(t, "first", false,
+		x509.ExtKeyUsageServerAuth, notAfter, nil, nil)
+	writeTestKeyPair(t, certFile, keyFile, first, firstKey)
+	keyPair, err := loadRPCKeyPair(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("loadRPCKeyPair: %v", err)
+	}
+	now := time.Now()
+	if cert := keyPair.Certificate(now); cert.Leaf.Subject.CommonName != "first" {
+		t.Fatalf("got certificate %q, want first",
+			cert.Leaf.Subject.CommonName)
+	}
+
+	// Replace the files with a new certificate, moving their modification
+	// times forward since the filesystem may not tell the writes apart.
+	second, secondKey := testCertificate(t, "second", false,
+		x509.ExtKeyUsageServerAuth, notAfter, nil, nil)
+	writeTestKeyPair(t, certFile, keyFile, second, secondKey)
+	later := now.Add(time.Minute)
+	os.Chtimes(certFile, later, later)
+	os.Chtimes(keyFile, later, later)
+
+	// The files are not checked again before keyPairCheckInterval.
+	cert := keyPair.Certificate(now.Add(keyPairCheckInterval / 2))
+	if cert.Leaf.Subject.CommonName != "first" {
+		t.Fatalf("got certificate %q before the check interval, want "+
+			"first", cert.Leaf.Subject.CommonName)
+	}
+	now = now.Add(keyPairCheckInterval)
+	if cert := keyPair.Certificate(now); cert.Leaf.Subject.CommonName != "second" {
+		t.Fatalf("got certificate %q, want second",
+			cert.Leaf.Subject.CommonName)
+	}
+
+	// A key which doesn't match the certificate keeps the current one.
+	writeTestKeyPair(t, certFile, keyFile, first, secondKey)
+	later = later.Add(time.Minute)
+	os.Chtimes(certFile, later, later)
+	os.Chtimes(keyFile, later, later)
+	now = now.Add(keyPairCheckInterval)
+	if cert := keyPair.Certificate(now); cert.Leaf.Subject.CommonName != "second" {
+		t.Fatalf("got certificate %q after an invalid change, want "+
+			"second", cert.Leaf.Subject.CommonName)
+	}
+
+	// ACME challenges are answered with the challenge certificate.
+	keyPair.challenge = func(serverName string) (*tls.Certificate, error) {
+		return &tls.Certificate{Leaf: first}, nil
+	}
+	cert, err = keyPair.GetCertificate(&tls.ClientHelloInfo{
+		ServerName:      "first",
+		SupportedProtos: []string{acme.ALPNProto},
+	})
+	if err != nil || cert.Leaf != first {
+		t.Fatalf("GetCertificate did not return the challenge "+
+			"certificate: %v", err)
+	}
+}
+
+// TestRPCClientCAs ensures client certificates authenticate TLS connections as
+// the user of the authority which issued them.
+func TestRPCClientCAs(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "pktdrpctls")
+	if err != nil {
+		t.Fatalf("Failed creating a temporary directory: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	notAfter := time.Now().Add(24 * time.Hour)
+	certFile := filepath.Join(tmpDir, "rpc.cert")
+	keyFile := filepath.Join(tmpDir, "rpc.key")
+	serverCert, serverKey := testCertificate(t, "localhost", false,
+		x509.ExtKeyUsageServerAuth, notAfter, nil, nil)
+	writeTestKeyPair(t, certFile, keyFile, serverCert, serverKey)
+	keyPair, err := loadRPCKeyPair(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("loadRPCKeyPair: %v", err)
+	}
+
+	adminCA, adminCAKey := testCertificate(t, "admin ca", true,
+		x509.ExtKeyUsageClientAuth, notAfter, nil, nil)
+	limitedCA, limitedCAKey := testCertificate(t, "limited ca", true,
+		x509.ExtKeyUsageClientAuth, notAfter, nil, nil)
+	otherCA, otherCAKey := testCertificate(t, "other ca", true,
+		x509.ExtKeyUsageClientAuth, notAfter, nil, nil)
+	adminCAFile := filepath.Join(tmpDir, "admin.pem")
+	limitedCAFile := filepath.Join(tmpDir, "limited.pem")
+	for file, cert := range map[string]*x509.Certificate{
+		adminCAFile:   adminCA,
+		limitedCAFile: limitedCA,
+	} {
+		pemCert := pem.EncodeToMemory(&pem.Block{
+			Type:  "CERTIFICATE",
+			Bytes: cert.Raw,
+		})
+		if err := ioutil.WriteFile(file, pemCert, 0600); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+	clientCAs, err := loadRPCClientCAs(adminCAFile, limitedCAFile)
+	if err != nil {
+		t.Fatalf("loadRPCClientCAs: %v", err)
+	}
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0",
+		rpcTLSConfig(keyPair, clientCAs, false))
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	defer listener.Close()
+
+	// handshake connects with the passed client certificate, which may be
+	// nil, and returns the result of authenticating the server side of the
+	// connection.
+	handshake := func(cert *x509.Certificate, key *ecdsa.PrivateKey) (bool, error) {
+		clientConfig := &tls.Config{InsecureSkipVerify: true}
+		if cert != nil {
+			clientConfig.Certificates = []tls.Certificate{{
+				Certificate: [][]byte{cert.Raw},
+				PrivateKey:  key,
+			}}
+		}
+		accepted := make(chan *tls.Conn, 1)
+		go func() {
+			conn, err := listener.Accept()
+			if err != nil {
+				accepted <- nil
+				return
+			}
+			tlsConn := conn.(*tls.Conn)
+			tlsConn.Handshake()
+			accepted <- tlsConn
+		}()
+		conn, err := tls.Dial("tcp", listener.Addr().String(),
+			clientConfig)
+		if err != nil {
+			t.Fatalf("Dial: %v", err)
+		}
+		defer conn.Close()
+		serverConn := <-accepted
+		if serverConn == nil {
+			t.Fatal("Accept failed")
+		}
+		defer serverConn.Close()
+		state := serverConn.ConnectionState()
+		return clientCAs.Authenticate(&state)
+	}
+
+	tests := []struct {
+		name    string
+		ca      *x509.Certificate
+		caKey   *ecdsa.PrivateKey
+		isAdmin bool
+		valid   bool
+	}{
+		{"admin", adminCA, adminCAKey, true, true},
+		{"limited", limitedCA, limitedCAKey, false, true},
+		{"no certificate", nil, nil, false, false},
+	}
+	for _, test := range tests {
+		var cert *x509.Certificate
+		var key *ecdsa.PrivateKey
+		if test.ca != nil {
+			cert, key = testCertificate(t, test.name, false,
+				x509.ExtKeyUsageClientAuth, notAfter, test.ca,
+				test.caKey)
+		}
+		isAdmin, err := handshake(cert, key)
+		if (err == nil) != test.valid || isAdmin != test.isAdmin {
+			t.Errorf("%s: got admin %v and error %v", test.name,
+				isAdmin, err)
+		}
+	}
+
+	// A certificate of another authority fails the handshake, so it is
+	// tested on a connection state directly.
+	cert, _ := testCertificate(t, "other", false, x509.ExtKeyUsageClientAuth,
+		notAfter, otherCA, otherCAKey)
+	state := &tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{cert},
+		VerifiedChains:   [][]*x509.Certificate{{cert, otherCA}},
+	}
+	if _, err := clientCAs.Authenticate(state); err == nil {
+		t.Error("certificate of another authority authenticated")
+	}
+}
+
+// TestCertNeedsRenewal ensures ACME certificates are renewed when they don't
+// cover the domains or expire soon.
+func TestCertNeedsRenewal(t *testing.T) {
+	now := time.Now()
+	fresh, _ := testCertificate(t, "node.example.com", false,
+		x509.ExtKeyUsageServerAuth, now.Add(60*24*time.Hour), nil, nil)
+	expiring, _ := testCertificate(t, "node.example.com", false,
+		x509.ExtKeyUsageServerAuth, now.Add(7*24*time.Hour), nil, nil)
+
+	tests := []struct {
+		name    string
+		leaf    *x509.Certificate
+		domains []string
+		want    bool
+	}{
+		{"fresh", fresh, []string{"node.example.com"}, false},
+		{"expiring", expiring, []string{"node.example.com"}, true},
+		{"other domain", fresh, []string{"node.example.com",
+			"rpc.example.com"}, true},
+		{"no certificate", nil, []string{"node.example.com"}, true},
+	}
+	for _, test := range tests {
+		got := certNeedsRenewal(test.leaf, test.domains, now)
+		if got != test.want {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
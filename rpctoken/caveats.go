@@ -0,0 +1,179 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rpctoken
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Scope is the set of commands a token allows.  The scopes are ordered from
+// the narrowest to the broadest.
+type Scope uint8
+
+const (
+	// ScopeReadOnly allows the commands of the limited RPC user which do
+	// not change the state of the node.
+	ScopeReadOnly Scope = iota
+
+	// ScopeSend allows the commands of the limited RPC user, which include
+	// relaying transactions.
+	ScopeSend
+
+	// ScopeAdmin allows every command.
+	ScopeAdmin
+)
+
+// scopeNames maps the scopes to their names in caveats.
+var scopeNames = map[Scope]string{
+	ScopeReadOnly: "readonly",
+	ScopeSend:     "send",
+	ScopeAdmin:    "admin",
+}
+
+// String returns the name of the scope.
+func (s Scope) String() string {
+	if name, ok := scopeNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("Unknown Scope (%d)", uint8(s))
+}
+
+// ParseScope returns the scope with the passed name.
+func ParseScope(name string) (Scope, error) {
+	for scope, n := range scopeNames {
+		if n == name {
+			return scope, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown RPC token scope %q", name)
+}
+
+// The kinds of caveats, the keys of their key=value strings.
+const (
+	caveatScope   = "scope"
+	caveatExpires = "expires"
+	caveatMaxSend = "maxsend"
+	caveatMethods = "methods"
+)
+
+// ScopeCaveat returns the caveat restricting a token to the passed scope.
+func ScopeCaveat(scope Scope) string {
+	return caveatScope + "=" + scope.String()
+}
+
+// ExpiresCaveat returns the caveat making a token expire at the passed time.
+func ExpiresCaveat(expires time.Time) string {
+	return caveatExpires + "=" + strconv.FormatInt(expires.Unix(), 10)
+}
+
+// MaxSendCaveat returns the caveat limiting the total value paid by each
+// transaction relayed with a token to the passed number of atoms.
+func MaxSendCaveat(atoms int64) string {
+	return caveatMaxSend + "=" + strconv.FormatInt(atoms, 10)
+}
+
+// MethodsCaveat returns the caveat restricting a token to the passed
+// commands.
+func MethodsCaveat(methods ...string) string {
+	return caveatMethods + "=" + strings.Join(methods, ",")
+}
+
+// Restrictions are the restrictions of all the caveats of a token combined.
+type Restrictions struct {
+	// Scope is the narrowest scope of the token, ScopeAdmin when it has no
+	// scope caveat.
+	Scope Scope
+
+	// Expires is the earliest expiration of the token, the zero time when
+	// it never expires.
+	Expires time.Time
+
+	// MaxSend is the lowest limit in atoms of the value paid by each
+	// transaction relayed with the token, negative when unlimited.
+	MaxSend int64
+
+	// Methods is the set of commands allowed by every methods caveat of
+	// the token, nil when it has none.
+	Methods map[string]struct{}
+}
+
+// Restrictions combines the caveats of the token.  It returns an error when a
+// caveat is malformed or of an unknown kind, since its restriction could not
+// be enforced.
+func (t *Token) Restrictions() (*Restrictions, error) {
+	r := &Restrictions{Scope: ScopeAdmin, MaxSend: -1}
+	for _, caveat := range t.Caveats {
+		parts := strings.SplitN(caveat, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("malformed RPC token caveat %q",
+				caveat)
+		}
+		key, value := parts[0], parts[1]
+		switch key {
+		case caveatScope:
+			scope, err := ParseScope(value)
+			if err != nil {
+				return nil, err
+			}
+			if scope < r.Scope {
+				r.Scope = scope
+			}
+
+		case caveatExpires:
+			unix, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("malformed RPC token "+
+					"caveat %q", caveat)
+			}
+			expires := time.Unix(unix, 0)
+			if r.Expires.IsZero() || expires.Before(r.Expires) {
+				r.Expires = expires
+			}
+
+		case caveatMaxSend:
+			atoms, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || atoms < 0 {
+				return nil, fmt.Errorf("malformed RPC token "+
+					"caveat %q", caveat)
+			}
+			if r.MaxSend < 0 || atoms < r.MaxSend {
+				r.MaxSend = atoms
+			}
+
+		case caveatMethods:
+			methods := make(map[string]struct{})
+			for _, method := range strings.Split(value, ",") {
+				if _, ok := r.Methods[method]; ok || r.Methods == nil {
+					methods[method] = struct{}{}
+				}
+			}
+			r.Methods = methods
+
+		default:
+			return nil, fmt.Errorf("unknown RPC token caveat %q",
+				caveat)
+		}
+	}
+	return r, nil
+}
+
+// Expired returns whether the token has expired at the passed time.
+func (r *Restrictions) Expired(now time.Time) bool {
+	return !r.Expires.IsZero() && !now.Before(r.Expires)
+}
+
+// AllowsMethod returns whether the methods caveats of the token allow the
+// passed command.  The scope of the token is checked by the node, which knows
+// what the commands do.
+func (r *Restrictions) AllowsMethod(method string) bool {
+	if r.Methods == nil {
+		return true
+	}
+	_, ok := r.Methods[method]
+	return ok
+}
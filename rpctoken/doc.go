@@ -0,0 +1,32 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package rpctoken implements the capability tokens which authenticate RPC
+clients in place of a fixed username and password, so a service given a token
+can only do what the token allows, and only until it expires.
+
+A token is a random identifier followed by a list of caveats, each restricting
+what the token allows, authenticated by a chain of HMAC-SHA256: the signature
+of the identifier is keyed with a root key only the node knows, and the
+signature of each caveat is keyed with the signature before it.  Anybody
+holding a token can therefore attenuate it by appending a caveat, and hand the
+weaker token to a less trusted service, but nobody can remove a caveat without
+the root key.  This is the construction of macaroons, restricted to first
+party caveats verified by the node.
+
+The caveats are key=value strings:
+
+	scope=readonly      only commands which do not change the state of the node
+	scope=send          the commands of the limited RPC user, which may relay transactions
+	scope=admin         every command
+	expires=1600000000  the token is refused after this unix time
+	maxsend=100000000   relayed transactions may not pay more than this many atoms in total
+	methods=getinfo,... only the listed commands
+
+A token with several caveats of the same kind is bound by all of them, such as
+the narrowest scope and the earliest expiration.  A caveat of an unknown kind
+makes the token invalid, since its restriction could not be enforced.
+*/
+package rpctoken
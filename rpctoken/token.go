@@ -0,0 +1,198 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rpctoken
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+)
+
+const (
+	// RootKeySize is the size of the root keys tokens are signed with.
+	RootKeySize = 32
+
+	// IDSize is the size of the random identifier of a token.
+	IDSize = 16
+
+	// MaxCaveats is the maximum number of caveats of a token.
+	MaxCaveats = 32
+
+	// MaxCaveatSize is the maximum length of a caveat.
+	MaxCaveatSize = 255
+
+	// tokenVersion is the version of the token format.
+	tokenVersion = 1
+)
+
+var (
+	// ErrMalformedToken is returned when decoding a string which is not a
+	// token.
+	ErrMalformedToken = errors.New("malformed RPC token")
+
+	// ErrBadSignature is returned when verifying a token which was not
+	// minted with the root key, or which was altered.
+	ErrBadSignature = errors.New("invalid RPC token signature")
+
+	// ErrTooManyCaveats is returned when adding a caveat to a token which
+	// already has MaxCaveats caveats.
+	ErrTooManyCaveats = errors.New("too many RPC token caveats")
+
+	// ErrCaveatTooLarge is returned when adding a caveat longer than
+	// MaxCaveatSize.
+	ErrCaveatTooLarge = errors.New("RPC token caveat too large")
+)
+
+// Token is a capability token: an identifier and the caveats restricting what
+// it allows, along with the signature chaining them to the root key.
+type Token struct {
+	// ID is the random identifier of the token, shared by the tokens
+	// attenuated from it.
+	ID [IDSize]byte
+
+	// Caveats are the restrictions of the token, in the order they were
+	// added.
+	Caveats []string
+
+	signature [sha256.Size]byte
+}
+
+// NewRootKey returns a new random root key.
+func NewRootKey() ([]byte, error) {
+	key := make([]byte, RootKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// sign returns the HMAC-SHA256 of the message keyed with the passed key.
+func sign(key []byte, message []byte) [sha256.Size]byte {
+	var sig [sha256.Size]byte
+	mac := hmac.New(sha256.New, key)
+	mac.Write(message)
+	copy(sig[:], mac.Sum(nil))
+	return sig
+}
+
+// New mints a token with a random identifier and the passed caveats, signed
+// with the root key.
+func New(rootKey []byte, caveats ...string) (*Token, error) {
+	t := &Token{}
+	if _, err := rand.Read(t.ID[:]); err != nil {
+		return nil, err
+	}
+	t.signature = sign(rootKey, t.ID[:])
+	for _, caveat := range caveats {
+		if err := t.addCaveat(caveat); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// addCaveat appends the passed caveat to the token and chains its signature.
+func (t *Token) addCaveat(caveat string) error {
+	if len(t.Caveats) >= MaxCaveats {
+		return ErrTooManyCaveats
+	}
+	if len(caveat) > MaxCaveatSize {
+		return ErrCaveatTooLarge
+	}
+	t.Caveats = append(t.Caveats, caveat)
+	t.signature = sign(t.signature[:], []byte(caveat))
+	return nil
+}
+
+// Attenuate returns a copy of the token restricted by the passed caveats.  It
+// does not need the root key, so any holder of a token can derive a weaker one
+// from it, while the original token is left unchanged.
+func (t *Token) Attenuate(caveats ...string) (*Token, error) {
+	attenuated := &Token{
+		ID:        t.ID,
+		Caveats:   append([]string(nil), t.Caveats...),
+		signature: t.signature,
+	}
+	for _, caveat := range caveats {
+		if err := attenuated.addCaveat(caveat); err != nil {
+			return nil, err
+		}
+	}
+	return attenuated, nil
+}
+
+// Verify returns ErrBadSignature unless the token was minted with the passed
+// root key, and its caveats were only appended to since.  It does not check
+// what the caveats allow, see Restrictions.
+func (t *Token) Verify(rootKey []byte) error {
+	sig := sign(rootKey, t.ID[:])
+	for _, caveat := range t.Caveats {
+		sig = sign(sig[:], []byte(caveat))
+	}
+	if !hmac.Equal(sig[:], t.signature[:]) {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+// IDString returns the hex encoded identifier of the token, which names it in
+// logs and revocation lists without revealing the token.
+func (t *Token) IDString() string {
+	return hex.EncodeToString(t.ID[:])
+}
+
+// Encode returns the token encoded as an unpadded URL-safe base64 string,
+// suitable for an HTTP Authorization header.
+func (t *Token) Encode() string {
+	size := 1 + IDSize + 1 + sha256.Size
+	for _, caveat := range t.Caveats {
+		size += 1 + len(caveat)
+	}
+	b := make([]byte, 0, size)
+	b = append(b, tokenVersion)
+	b = append(b, t.ID[:]...)
+	b = append(b, byte(len(t.Caveats)))
+	for _, caveat := range t.Caveats {
+		b = append(b, byte(len(caveat)))
+		b = append(b, caveat...)
+	}
+	b = append(b, t.signature[:]...)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Decode decodes a token encoded by Encode.  The token still has to be
+// verified.
+func Decode(s string) (*Token, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+	if len(b) < 1+IDSize+1+sha256.Size || b[0] != tokenVersion {
+		return nil, ErrMalformedToken
+	}
+	t := &Token{}
+	copy(t.ID[:], b[1:])
+	b = b[1+IDSize:]
+	count := int(b[0])
+	if count > MaxCaveats {
+		return nil, ErrMalformedToken
+	}
+	b = b[1:]
+	for i := 0; i < count; i++ {
+		if len(b) < 1 || len(b) < 1+int(b[0]) {
+			return nil, ErrMalformedToken
+		}
+		t.Caveats = append(t.Caveats, string(b[1:1+int(b[0])]))
+		b = b[1+int(b[0]):]
+	}
+	if len(b) != sha256.Size {
+		return nil, ErrMalformedToken
+	}
+	copy(t.signature[:], b)
+	return t, nil
+}
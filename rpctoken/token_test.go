@@ -0,0 +1,191 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rpctoken
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestTokenVerify ensures tokens verify with their root key only, survive
+// being encoded, and can be attenuated but not stripped of their caveats.
+func TestTokenVerify(t *testing.T) {
+	rootKey, err := NewRootKey()
+	if err != nil {
+		t.Fatalf("NewRootKey: %v", err)
+	}
+	otherKey, err := NewRootKey()
+	if err != nil {
+		t.Fatalf("NewRootKey: %v", err)
+	}
+
+	token, err := New(rootKey, ScopeCaveat(ScopeSend))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := token.Verify(rootKey); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if err := token.Verify(otherKey); err != ErrBadSignature {
+		t.Fatalf("Verify with another root key: %v", err)
+	}
+
+	decoded, err := Decode(token.Encode())
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, token) {
+		t.Fatalf("decoded %+v, want %+v", decoded, token)
+	}
+
+	// Attenuating a token does not need the root key and leaves the
+	// original token unchanged.
+	attenuated, err := token.Attenuate(MaxSendCaveat(1000))
+	if err != nil {
+		t.Fatalf("Attenuate: %v", err)
+	}
+	if err := attenuated.Verify(rootKey); err != nil {
+		t.Fatalf("Verify attenuated token: %v", err)
+	}
+	if len(token.Caveats) != 1 || attenuated.ID != token.ID {
+		t.Fatalf("Attenuate changed the original token")
+	}
+
+	// Removing or altering a caveat invalidates the signature.
+	stripped := *attenuated
+	stripped.Caveats = stripped.Caveats[:1]
+	if err := stripped.Verify(rootKey); err != ErrBadSignature {
+		t.Fatalf("Verify of a token stripped of a caveat: %v", err)
+	}
+	altered := *attenuated
+	altered.Caveats = []string{ScopeCaveat(ScopeAdmin),
+		MaxSendCaveat(1000)}
+	if err := altered.Verify(rootKey); err != ErrBadSignature {
+		t.Fatalf("Verify of a token with an altered caveat: %v", err)
+	}
+
+	encoded := token.Encode()
+	for _, s := range []string{"", "not a token!", encoded[:len(encoded)-2]} {
+		if _, err := Decode(s); err != ErrMalformedToken {
+			t.Errorf("Decode(%q): %v", s, err)
+		}
+	}
+
+	if _, err := token.Attenuate(strings.Repeat("x", MaxCaveatSize+1)); err != ErrCaveatTooLarge {
+		t.Errorf("Attenuate with an oversized caveat: %v", err)
+	}
+	caveats := make([]string, MaxCaveats)
+	for i := range caveats {
+		caveats[i] = ScopeCaveat(ScopeSend)
+	}
+	if _, err := token.Attenuate(caveats...); err != ErrTooManyCaveats {
+		t.Errorf("Attenuate with too many caveats: %v", err)
+	}
+}
+
+// TestRestrictions ensures the caveats of a token combine into the narrowest
+// restrictions, and that unknown caveats are refused.
+func TestRestrictions(t *testing.T) {
+	early := time.Unix(1600000000, 0)
+	late := early.Add(time.Hour)
+
+	tests := []struct {
+		name    string
+		caveats []string
+		want    *Restrictions
+		invalid bool
+	}{
+		{
+			name: "no caveats",
+			want: &Restrictions{Scope: ScopeAdmin, MaxSend: -1},
+		},
+		{
+			name: "narrowest",
+			caveats: []string{
+				ScopeCaveat(ScopeSend),
+				ExpiresCaveat(late),
+				MaxSendCaveat(500),
+				ScopeCaveat(ScopeReadOnly),
+				ExpiresCaveat(early),
+				MaxSendCaveat(1000),
+				ScopeCaveat(ScopeAdmin),
+			},
+			want: &Restrictions{
+				Scope:   ScopeReadOnly,
+				Expires: early,
+				MaxSend: 500,
+			},
+		},
+		{
+			name: "methods",
+			caveats: []string{
+				MethodsCaveat("getinfo", "getblock", "sendrawtransaction"),
+				MethodsCaveat("getblock", "sendrawtransaction", "stop"),
+			},
+			want: &Restrictions{
+				Scope:   ScopeAdmin,
+				MaxSend: -1,
+				Methods: map[string]struct{}{
+					"getblock":           {},
+					"sendrawtransaction": {},
+				},
+			},
+		},
+		{
+			name:    "unknown caveat",
+			caveats: []string{"ipaddr=127.0.0.1"},
+			invalid: true,
+		},
+		{
+			name:    "unknown scope",
+			caveats: []string{"scope=root"},
+			invalid: true,
+		},
+		{
+			name:    "malformed",
+			caveats: []string{"expires"},
+			invalid: true,
+		},
+		{
+			name:    "negative max send",
+			caveats: []string{"maxsend=-1"},
+			invalid: true,
+		},
+	}
+	for _, test := range tests {
+		token := &Token{Caveats: test.caveats}
+		r, err := token.Restrictions()
+		if test.invalid {
+			if err == nil {
+				t.Errorf("%s: no error", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if !r.Expires.Equal(test.want.Expires) {
+			t.Errorf("%s: expires %v, want %v", test.name,
+				r.Expires, test.want.Expires)
+		}
+		r.Expires = test.want.Expires
+		if !reflect.DeepEqual(r, test.want) {
+			t.Errorf("%s: got %+v, want %+v", test.name, r, test.want)
+		}
+	}
+
+	r := &Restrictions{Expires: early, Methods: map[string]struct{}{
+		"getinfo": {},
+	}}
+	if r.Expired(early.Add(-time.Second)) || !r.Expired(early) {
+		t.Errorf("Expired does not expire the token at %v", early)
+	}
+	if !r.AllowsMethod("getinfo") || r.AllowsMethod("stop") {
+		t.Errorf("AllowsMethod does not follow the methods caveat")
+	}
+}
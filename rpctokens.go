@@ -0,0 +1,258 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/pkt-cash/pktd/btcjson"
+	"github.com/pkt-cash/pktd/chaincfg/globalcfg"
+	"github.com/pkt-cash/pktd/rpctoken"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// rpcTokenKeyFilename is the name of the file in the data directory holding
+// the root key the RPC tokens are minted with.  Deleting it revokes every
+// token.
+const rpcTokenKeyFilename = "rpctoken.key"
+
+var (
+	// errNoRPCTokens is returned by minttoken when the RPC tokens are not
+	// enabled.
+	errNoRPCTokens = &btcjson.RPCError{
+		Code:    btcjson.ErrRPCMisc,
+		Message: "RPC tokens are not enabled (specify --rpctokens)",
+	}
+
+	// errRPCTokenRevoked is returned when authenticating with a token
+	// whose identifier was revoked.
+	errRPCTokenRevoked = errors.New("RPC token revoked")
+
+	// errRPCTokenExpired is returned when authenticating with an expired
+	// token.
+	errRPCTokenExpired = errors.New("RPC token expired")
+)
+
+// loadRPCTokenKey reads the root key of the RPC tokens from the passed file,
+// generating it when the file does not exist yet.
+func loadRPCTokenKey(path string) ([]byte, error) {
+	key, err := ioutil.ReadFile(path)
+	if err == nil {
+		if len(key) != rpctoken.RootKeySize {
+			return nil, fmt.Errorf("%s is not an RPC token root key",
+				path)
+		}
+		return key, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	key, err = rpctoken.NewRootKey()
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFileAtomic(path, key, 0600); err != nil {
+		return nil, err
+	}
+	rpcsLog.Infof("Generated RPC token root key %s", path)
+	return key, nil
+}
+
+// rpcTokenAuth mints the capability tokens authenticating RPC clients and
+// verifies them.
+type rpcTokenAuth struct {
+	rootKey []byte
+	revoked map[string]struct{}
+}
+
+// newRPCTokenAuth returns the token authenticator using the root key of the
+// passed file, which refuses the tokens with the passed hex encoded
+// identifiers.
+func newRPCTokenAuth(keyFile string, revoked []string) (*rpcTokenAuth, error) {
+	rootKey, err := loadRPCTokenKey(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	a := &rpcTokenAuth{
+		rootKey: rootKey,
+		revoked: make(map[string]struct{}, len(revoked)),
+	}
+	for _, id := range revoked {
+		a.revoked[id] = struct{}{}
+	}
+	return a, nil
+}
+
+// mint returns a new token restricted by the passed caveats.
+func (a *rpcTokenAuth) mint(caveats ...string) (*rpctoken.Token, error) {
+	return rpctoken.New(a.rootKey, caveats...)
+}
+
+// verify returns the access granted by the passed encoded token at the passed
+// time, or an error when it is not a valid token.
+func (a *rpcTokenAuth) verify(encoded string, now time.Time) (*rpcTokenGrant, error) {
+	token, err := rpctoken.Decode(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if err := token.Verify(a.rootKey); err != nil {
+		return nil, err
+	}
+	id := token.IDString()
+	if _, ok := a.revoked[id]; ok {
+		return nil, errRPCTokenRevoked
+	}
+	restrictions, err := token.Restrictions()
+	if err != nil {
+		return nil, err
+	}
+	if restrictions.Expired(now) {
+		return nil, errRPCTokenExpired
+	}
+	return &rpcTokenGrant{id: id, Restrictions: restrictions}, nil
+}
+
+// rpcTokenGrant is the access granted to an RPC client by the token it
+// authenticated with.
+type rpcTokenGrant struct {
+	*rpctoken.Restrictions
+
+	// id is the hex encoded identifier of the token.
+	id string
+}
+
+// isAdmin returns whether the token grants the commands of the admin user.
+// The other scopes are bound by the commands of the limited user.
+func (g *rpcTokenGrant) isAdmin() bool {
+	return g.Scope == rpctoken.ScopeAdmin
+}
+
+// user returns the name of the client in the RPC audit log.
+func (g *rpcTokenGrant) user() string {
+	return "token:" + g.id
+}
+
+// txOutputValue returns the total value of the outputs of the passed hex
+// encoded transaction.
+func txOutputValue(hexTx string) (int64, error) {
+	if len(hexTx)%2 != 0 {
+		hexTx = "0" + hexTx
+	}
+	serializedTx, err := hex.DecodeString(hexTx)
+	if err != nil {
+		return 0, err
+	}
+	var msgTx wire.MsgTx
+	if err := msgTx.Deserialize(bytes.NewReader(serializedTx)); err != nil {
+		return 0, err
+	}
+	var value int64
+	for _, txOut := range msgTx.TxOut {
+		value += txOut.Value
+	}
+	return value, nil
+}
+
+// authorize returns an error unless the caveats of the token allow the passed
+// command at the passed time.  The tokens are also checked when the clients
+// authenticate, but a websocket client may outlive its token.
+func (g *rpcTokenGrant) authorize(cmd *parsedRPCCmd, now time.Time) *btcjson.RPCError {
+	unauthorized := func(message string) *btcjson.RPCError {
+		return &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParams.Code,
+			Message: message,
+		}
+	}
+	if g.Expired(now) {
+		return unauthorized(errRPCTokenExpired.Error())
+	}
+	if !g.AllowsMethod(cmd.method) {
+		return unauthorized("RPC token not authorized for this method")
+	}
+	if g.Scope == rpctoken.ScopeReadOnly {
+		if isAuditedCmd(cmd) {
+			return unauthorized("read-only RPC token not " +
+				"authorized for this method")
+		}
+	}
+
+	// Transactions which can't be decoded are left for the command to
+	// reject.
+	if c, ok := cmd.cmd.(*btcjson.SendRawTransactionCmd); ok && g.MaxSend >= 0 {
+		value, err := txOutputValue(c.HexTx)
+		if err == nil && value > g.MaxSend {
+			return unauthorized(fmt.Sprintf("transaction outputs "+
+				"total %d atoms, more than the %d allowed by "+
+				"the RPC token", value, g.MaxSend))
+		}
+	}
+	return nil
+}
+
+// handleMintToken implements the minttoken command.
+func handleMintToken(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	if s.tokenAuth == nil {
+		return nil, errNoRPCTokens
+	}
+	c := cmd.(*btcjson.MintTokenCmd)
+	invalidParameter := func(message string) *btcjson.RPCError {
+		return &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: message,
+		}
+	}
+
+	scope, err := rpctoken.ParseScope(c.Scope)
+	if err != nil {
+		return nil, invalidParameter(err.Error())
+	}
+	caveats := []string{rpctoken.ScopeCaveat(scope)}
+
+	var expires int64
+	if c.Expiry != nil && *c.Expiry != 0 {
+		if *c.Expiry < 0 {
+			return nil, invalidParameter("The expiry may not be " +
+				"negative")
+		}
+		t := time.Now().Add(time.Duration(*c.Expiry) * time.Second)
+		caveats = append(caveats, rpctoken.ExpiresCaveat(t))
+		expires = t.Unix()
+	}
+	if c.MaxSend != nil {
+		atoms, err := globalcfg.NewAmount(*c.MaxSend)
+		if err != nil || atoms < 0 {
+			return nil, invalidParameter("Invalid maximum send " +
+				"amount")
+		}
+		caveats = append(caveats, rpctoken.MaxSendCaveat(int64(atoms)))
+	}
+	if c.Methods != nil {
+		if len(*c.Methods) == 0 {
+			return nil, invalidParameter("At least one method must " +
+				"be allowed")
+		}
+		caveats = append(caveats, rpctoken.MethodsCaveat(*c.Methods...))
+	}
+
+	token, err := s.tokenAuth.mint(caveats...)
+	if err == rpctoken.ErrCaveatTooLarge {
+		return nil, invalidParameter("Too many methods")
+	}
+	if err != nil {
+		return nil, internalRPCError(err.Error(), "Failed to mint token")
+	}
+	return &btcjson.MintTokenResult{
+		Token:   token.Encode(),
+		ID:      token.IDString(),
+		Expires: expires,
+	}, nil
+}
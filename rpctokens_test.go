@@ -0,0 +1,206 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkt-cash/pktd/btcjson"
+	"github.com/pkt-cash/pktd/rpctoken"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// TestRPCTokenAuth ensures RPC tokens authenticate requests with the access
+// allowed by their caveats, and that revoked, expired and forged tokens are
+// refused.
+func TestRPCTokenAuth(t *testing.T) {
+	setLogLevels("off")
+	defer setLogLevels(defaultLogLevel)
+
+	tmpDir, err := ioutil.TempDir("", "pktdrpctokens")
+	if err != nil {
+		t.Fatalf("Failed creating a temporary directory: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	keyFile := filepath.Join(tmpDir, rpcTokenKeyFilename)
+
+	tokenAuth, err := newRPCTokenAuth(keyFile, nil)
+	if err != nil {
+		t.Fatalf("newRPCTokenAuth: %v", err)
+	}
+	mint := func(caveats ...string) string {
+		token, err := tokenAuth.mint(caveats...)
+		if err != nil {
+			t.Fatalf("mint: %v", err)
+		}
+		return token.Encode()
+	}
+	admin := mint(rpctoken.ScopeCaveat(rpctoken.ScopeAdmin))
+	readOnly := mint(rpctoken.ScopeCaveat(rpctoken.ScopeReadOnly))
+	expired := mint(rpctoken.ScopeCaveat(rpctoken.ScopeAdmin),
+		rpctoken.ExpiresCaveat(time.Now().Add(-time.Minute)))
+	revokedToken, err := tokenAuth.mint(rpctoken.ScopeCaveat(rpctoken.ScopeSend))
+	if err != nil {
+		t.Fatalf("mint: %v", err)
+	}
+
+	// The root key is kept, so the tokens survive a restart, except the
+	// revoked one.
+	tokenAuth, err = newRPCTokenAuth(keyFile,
+		[]string{revokedToken.IDString()})
+	if err != nil {
+		t.Fatalf("newRPCTokenAuth: %v", err)
+	}
+	other, err := rpctoken.New(make([]byte, rpctoken.RootKeySize),
+		rpctoken.ScopeCaveat(rpctoken.ScopeAdmin))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	s := &rpcServer{tokenAuth: tokenAuth}
+	tests := []struct {
+		name    string
+		token   string
+		isAdmin bool
+		valid   bool
+	}{
+		{"admin", admin, true, true},
+		{"read-only", readOnly, false, true},
+		{"expired", expired, false, false},
+		{"revoked", revokedToken.Encode(), false, false},
+		{"other root key", other.Encode(), false, false},
+		{"malformed", "token", false, false},
+	}
+	for _, test := range tests {
+		r, err := http.NewRequest("POST", "http://127.0.0.1/", nil)
+		if err != nil {
+			t.Fatalf("NewRequest: %v", err)
+		}
+		r.Header.Set("Authorization", "Bearer "+test.token)
+		authenticated, isAdmin, grant, err := s.checkAuth(r, true)
+		if authenticated != test.valid || (err == nil) != test.valid ||
+			(grant != nil) != test.valid || isAdmin != test.isAdmin {
+
+			t.Errorf("%s: got authenticated %v, admin %v, grant %v "+
+				"and error %v", test.name, authenticated, isAdmin,
+				grant, err)
+		}
+	}
+}
+
+// TestRPCTokenAuthorize ensures the caveats of the RPC tokens restrict the
+// commands they may run.
+func TestRPCTokenAuthorize(t *testing.T) {
+	msgTx := wire.NewMsgTx(wire.TxVersion)
+	msgTx.AddTxIn(wire.NewTxIn(&wire.OutPoint{}, nil, nil))
+	msgTx.AddTxOut(wire.NewTxOut(600, nil))
+	msgTx.AddTxOut(wire.NewTxOut(400, nil))
+	var buf bytes.Buffer
+	if err := msgTx.Serialize(&buf); err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+	send := &parsedRPCCmd{
+		method: "sendrawtransaction",
+		cmd: btcjson.NewSendRawTransactionCmd(
+			hex.EncodeToString(buf.Bytes()), nil),
+	}
+	getInfo := &parsedRPCCmd{method: "getinfo", cmd: &btcjson.GetInfoCmd{}}
+	getColdSpends := &parsedRPCCmd{
+		method: "getcoldspends",
+		cmd:    btcjson.NewGetColdSpendsCmd(nil),
+	}
+	acknowledge := &parsedRPCCmd{
+		method: "getcoldspends",
+		cmd:    btcjson.NewGetColdSpendsCmd(btcjson.Bool(true)),
+	}
+
+	now := time.Now()
+	tests := []struct {
+		name       string
+		caveats    []string
+		cmd        *parsedRPCCmd
+		authorized bool
+	}{
+		{
+			name:       "send",
+			caveats:    []string{rpctoken.ScopeCaveat(rpctoken.ScopeSend)},
+			cmd:        send,
+			authorized: true,
+		},
+		{
+			name:       "read-only send",
+			caveats:    []string{rpctoken.ScopeCaveat(rpctoken.ScopeReadOnly)},
+			cmd:        send,
+			authorized: false,
+		},
+		{
+			name:       "read-only getinfo",
+			caveats:    []string{rpctoken.ScopeCaveat(rpctoken.ScopeReadOnly)},
+			cmd:        getInfo,
+			authorized: true,
+		},
+		{
+			name:       "read-only getcoldspends",
+			caveats:    []string{rpctoken.ScopeCaveat(rpctoken.ScopeReadOnly)},
+			cmd:        getColdSpends,
+			authorized: true,
+		},
+		{
+			name:       "read-only getcoldspends acknowledge",
+			caveats:    []string{rpctoken.ScopeCaveat(rpctoken.ScopeReadOnly)},
+			cmd:        acknowledge,
+			authorized: false,
+		},
+		{
+			name:       "max send",
+			caveats:    []string{rpctoken.MaxSendCaveat(1000)},
+			cmd:        send,
+			authorized: true,
+		},
+		{
+			name:       "max send exceeded",
+			caveats:    []string{rpctoken.MaxSendCaveat(999)},
+			cmd:        send,
+			authorized: false,
+		},
+		{
+			name:       "methods",
+			caveats:    []string{rpctoken.MethodsCaveat("getinfo")},
+			cmd:        getInfo,
+			authorized: true,
+		},
+		{
+			name:       "other method",
+			caveats:    []string{rpctoken.MethodsCaveat("getinfo")},
+			cmd:        send,
+			authorized: false,
+		},
+		{
+			name:       "expired since authenticating",
+			caveats:    []string{rpctoken.ExpiresCaveat(now)},
+			cmd:        getInfo,
+			authorized: false,
+		},
+	}
+	for _, test := range tests {
+		token := &rpctoken.Token{Caveats: test.caveats}
+		restrictions, err := token.Restrictions()
+		if err != nil {
+			t.Fatalf("%s: Restrictions: %v", test.name, err)
+		}
+		grant := &rpcTokenGrant{Restrictions: restrictions}
+		jsonErr := grant.authorize(test.cmd, now)
+		if (jsonErr == nil) != test.authorized {
+			t.Errorf("%s: got error %v", test.name, jsonErr)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"container/list"
+	"fmt"
+	"sync"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/btcjson"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/database"
+	"github.com/pkt-cash/pktd/mempool"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// txFeeCacheLimit is the number of confirmed transaction fees kept by the RPC
+// server.
+const txFeeCacheLimit = 10000
+
+// txFee is an entry of the transaction fee cache.
+type txFee struct {
+	hash chainhash.Hash
+	fee  int64
+}
+
+// txFeeCache keeps the fees most recently computed for confirmed transactions.
+// Computing the fee of a confirmed transaction requires loading the block and
+// the spend journal holding it, while the fee of a transaction never changes,
+// so wallets repeatedly reporting the same history only pay that cost once.
+type txFeeCache struct {
+	mtx   sync.Mutex
+	fees  map[chainhash.Hash]*list.Element
+	lru   *list.List // Most recently used first, contains *txFee.
+	limit int
+}
+
+// newTxFeeCache returns a cache of at most limit transaction fees.
+func newTxFeeCache(limit int) *txFeeCache {
+	return &txFeeCache{
+		fees:  make(map[chainhash.Hash]*list.Element),
+		lru:   list.New(),
+		limit: limit,
+	}
+}
+
+// Lookup returns the cached fee of the transaction with the passed hash and
+// whether it is cached.  A nil cache never holds any fee.
+//
+// This function is safe for concurrent access.
+func (c *txFeeCache) Lookup(hash *chainhash.Hash) (int64, bool) {
+	if c == nil {
+		return 0, false
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	elem, ok := c.fees[*hash]
+	if !ok {
+		return 0, false
+	}
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*txFee).fee, true
+}
+
+// Add caches the fee of the transaction with the passed hash, evicting the
+// least recently used fee when the cache is full.
+//
+// This function is safe for concurrent access.
+func (c *txFeeCache) Add(hash *chainhash.Hash, fee int64) {
+	if c == nil || c.limit <= 0 {
+		return
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if elem, ok := c.fees[*hash]; ok {
+		c.lru.MoveToFront(elem)
+		return
+	}
+	if c.lru.Len() >= c.limit {
+		oldest := c.lru.Remove(c.lru.Back()).(*txFee)
+		delete(c.fees, oldest.hash)
+	}
+	c.fees[*hash] = c.lru.PushFront(&txFee{hash: *hash, fee: fee})
+}
+
+// blockTxFee returns the fee paid by the transaction with the passed hash in
+// the passed block, given the spend journal entries of the block, which hold
+// the outputs spent by its transactions in order.
+func blockTxFee(block *btcutil.Block, stxos []blockchain.SpentTxOut,
+	txHash *chainhash.Hash) (int64, error) {
+
+	offset := 0
+	for i, tx := range block.Transactions() {
+		if i == 0 {
+			if tx.Hash().IsEqual(txHash) {
+				return 0, nil
+			}
+			continue
+		}
+
+		numIns := len(tx.MsgTx().TxIn)
+		if !tx.Hash().IsEqual(txHash) {
+			offset += numIns
+			continue
+		}
+		if offset+numIns > len(stxos) {
+			return 0, fmt.Errorf("spend journal of block %v is "+
+				"missing entries", block.Hash())
+		}
+
+		var fee int64
+		for _, stxo := range stxos[offset : offset+numIns] {
+			fee += stxo.Amount
+		}
+		for _, txOut := range tx.MsgTx().TxOut {
+			fee -= txOut.Value
+		}
+		return fee, nil
+	}
+	return 0, fmt.Errorf("transaction %v is not in block %v", txHash,
+		block.Hash())
+}
+
+// confirmedTxFee returns the fee paid by the transaction with the passed hash
+// in the main chain block with the passed hash.  The fees are cached, so the
+// block and its spend journal are only loaded the first time.
+func (s *rpcServer) confirmedTxFee(txHash, blkHash *chainhash.Hash) (int64, error) {
+	if fee, ok := s.txFees.Lookup(txHash); ok {
+		return fee, nil
+	}
+
+	block, err := s.cfg.Chain.BlockByHash(blkHash)
+	if err != nil {
+		return 0, err
+	}
+	stxos, err := s.cfg.Chain.FetchSpendJournal(block)
+	if err != nil {
+		return 0, err
+	}
+	fee, err := blockTxFee(block, stxos, txHash)
+	if err != nil {
+		return 0, err
+	}
+	s.txFees.Add(txHash, fee)
+	return fee, nil
+}
+
+// handleGetTxFee implements the gettxfee command.
+func handleGetTxFee(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetTxFeeCmd)
+
+	txHash, err := chainhash.NewHashFromStr(c.TxID)
+	if err != nil {
+		return nil, rpcDecodeHexError(c.TxID)
+	}
+
+	// The fee of the transactions of the memory pool is known, otherwise
+	// it is computed from the spend journal of the block holding the
+	// transaction.
+	result := &btcjson.GetTxFeeResult{TxID: c.TxID}
+	var mtx *wire.MsgTx
+	var fee int64
+	if txD, err := s.cfg.TxMemPool.FetchTxDesc(txHash); err == nil {
+		mtx = txD.Tx.MsgTx()
+		fee = txD.Fee
+	} else {
+		if s.cfg.TxIndex == nil {
+			return nil, &btcjson.RPCError{
+				Code: btcjson.ErrRPCNoTxInfo,
+				Message: "The transaction index must be " +
+					"enabled to query the blockchain " +
+					"(specify --txindex)",
+			}
+		}
+		blockRegion, err := s.cfg.TxIndex.TxBlockRegion(txHash)
+		if err != nil {
+			context := "Failed to retrieve transaction location"
+			return nil, internalRPCError(err.Error(), context)
+		}
+		if blockRegion == nil {
+			return nil, s.txIndexMissError(txHash)
+		}
+		var txBytes []byte
+		err = s.cfg.DB.View(func(dbTx database.Tx) error {
+			var err error
+			txBytes, err = dbTx.FetchBlockRegion(blockRegion)
+			return err
+		})
+		if err != nil {
+			return nil, rpcNoTxInfoError(txHash)
+		}
+		var msgTx wire.MsgTx
+		err = msgTx.Deserialize(bytes.NewReader(txBytes))
+		if err != nil {
+			context := "Failed to deserialize transaction"
+			return nil, internalRPCError(err.Error(), context)
+		}
+		fee, err = s.confirmedTxFee(txHash, blockRegion.Hash)
+		if err != nil {
+			context := "Failed to compute transaction fee"
+			return nil, internalRPCError(err.Error(), context)
+		}
+		mtx = &msgTx
+		result.BlockHash = blockRegion.Hash.String()
+	}
+
+	vsize := mempool.GetTxVirtualSize(btcutil.NewTx(mtx))
+	result.Fee = btcutil.Amount(fee).ToBTC()
+	result.VSize = int32(vsize)
+	if vsize > 0 {
+		result.FeeRate = btcutil.Amount(fee * 1000 / vsize).ToBTC()
+	}
+	return result, nil
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// TestBlockTxFee ensures the fee of a transaction is computed from the spend
+// journal entries of its own inputs.
+func TestBlockTxFee(t *testing.T) {
+	newTx := func(numIns int, outValues ...int64) *wire.MsgTx {
+		tx := wire.NewMsgTx(wire.TxVersion)
+		for i := 0; i < numIns; i++ {
+			prevOut := wire.OutPoint{Index: uint32(i)}
+			tx.AddTxIn(wire.NewTxIn(&prevOut, nil, nil))
+		}
+		for _, value := range outValues {
+			tx.AddTxOut(wire.NewTxOut(value, nil))
+		}
+		return tx
+	}
+	coinbase := newTx(1, 5000)
+	tx1 := newTx(2, 1500)
+	tx2 := newTx(1, 700, 200)
+	block := btcutil.NewBlock(&wire.MsgBlock{
+		Transactions: []*wire.MsgTx{coinbase, tx1, tx2},
+	})
+	stxos := []blockchain.SpentTxOut{
+		{Amount: 1000}, {Amount: 600}, {Amount: 1000},
+	}
+
+	tests := []struct {
+		name string
+		hash chainhash.Hash
+		fee  int64
+	}{
+		{"coinbase", coinbase.TxHash(), 0},
+		{"first", tx1.TxHash(), 100},
+		{"second", tx2.TxHash(), 100},
+	}
+	for _, test := range tests {
+		fee, err := blockTxFee(block, stxos, &test.hash)
+		if err != nil {
+			t.Errorf("%s: blockTxFee: %v", test.name, err)
+			continue
+		}
+		if fee != test.fee {
+			t.Errorf("%s: got fee %d, want %d", test.name, fee, test.fee)
+		}
+	}
+
+	// A transaction of another block and a truncated spend journal are
+	// rejected.
+	other := newTx(1, 1).TxHash()
+	if _, err := blockTxFee(block, stxos, &other); err == nil {
+		t.Error("blockTxFee: unexpected success for a foreign transaction")
+	}
+	hash := tx2.TxHash()
+	if _, err := blockTxFee(block, stxos[:2], &hash); err == nil {
+		t.Error("blockTxFee: unexpected success for a truncated journal")
+	}
+}
+
+// TestTxFeeCache ensures the transaction fee cache evicts the least recently
+// used fee and that a nil cache holds nothing.
+func TestTxFeeCache(t *testing.T) {
+	hashes := []chainhash.Hash{{1}, {2}, {3}}
+
+	c := newTxFeeCache(2)
+	c.Add(&hashes[0], 10)
+	c.Add(&hashes[1], 20)
+	if fee, ok := c.Lookup(&hashes[0]); !ok || fee != 10 {
+		t.Fatalf("got cached fee %d (%v), want 10", fee, ok)
+	}
+	c.Add(&hashes[2], 30)
+	if _, ok := c.Lookup(&hashes[1]); ok {
+		t.Fatal("least recently used fee not evicted")
+	}
+	if fee, ok := c.Lookup(&hashes[2]); !ok || fee != 30 {
+		t.Fatalf("got cached fee %d (%v), want 30", fee, ok)
+	}
+
+	c = nil
+	c.Add(&hashes[0], 10)
+	if _, ok := c.Lookup(&hashes[0]); ok {
+		t.Fatal("fee cached by a nil cache")
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/btcjson"
+)
+
+const (
+	// defaultUtxoDeltasBlocks is the default number of blocks returned by
+	// getutxodeltas.
+	defaultUtxoDeltasBlocks = 100
+
+	// maxUtxoDeltasBlocks is the maximum number of blocks returned by
+	// getutxodeltas.
+	maxUtxoDeltasBlocks = 1000
+)
+
+// utxoDeltasResult returns the JSON result describing the passed outputs
+// created and spent by the passed block.
+func (s *rpcServer) utxoDeltasResult(typ blockchain.ChainEventType,
+	block *btcutil.Block, height int32, created,
+	spent []blockchain.UtxoDelta) btcjson.UtxoDeltasResult {
+
+	return btcjson.UtxoDeltasResult{
+		Type:         typ.String(),
+		Hash:         block.Hash().String(),
+		PreviousHash: block.MsgBlock().Header.PrevBlock.String(),
+		Height:       height,
+		Created:      s.chainEventUtxos(created),
+		Spent:        s.chainEventUtxos(spent),
+	}
+}
+
+// handleGetUtxoDeltas implements the getutxodeltas command.
+func handleGetUtxoDeltas(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetUtxoDeltasCmd)
+
+	count := int32(defaultUtxoDeltasBlocks)
+	if c.Count != nil {
+		count = *c.Count
+	}
+	if count <= 0 || count > maxUtxoDeltasBlocks {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCInvalidParameter,
+			Message: fmt.Sprintf("Count must be between 1 and %d",
+				maxUtxoDeltasBlocks),
+		}
+	}
+	best := s.cfg.Chain.BestSnapshot()
+	if c.StartHeight < 0 || c.StartHeight > best.Height {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCOutOfRange,
+			Message: fmt.Sprintf("Start height %d is out of range "+
+				"[0, %d]", c.StartHeight, best.Height),
+		}
+	}
+
+	results := make([]btcjson.UtxoDeltasResult, 0, count)
+	for height := c.StartHeight; height <= best.Height &&
+		len(results) < int(count); height++ {
+
+		select {
+		case <-closeChan:
+			return nil, ErrClientQuit
+		default:
+		}
+
+		block, err := s.cfg.Chain.BlockByHeight(height)
+		if err != nil {
+			context := "Failed to fetch block"
+			return nil, internalRPCError(err.Error(), context)
+		}
+		stxos, err := s.cfg.Chain.FetchSpendJournal(block)
+		if err != nil {
+			context := "Failed to fetch spend journal"
+			return nil, internalRPCError(err.Error(), context)
+		}
+		created, spent := blockchain.BlockUtxoDeltas(block, height, stxos)
+
+		// The outputs of the genesis block are not part of the UTXO
+		// set.
+		if height == 0 {
+			created = nil
+		}
+		results = append(results, s.utxoDeltasResult(
+			blockchain.ChainEventConnected, block, height, created,
+			spent))
+	}
+	return results, nil
+}
+
+// handleNotifyUtxoDeltas implements the notifyutxodeltas command extension for
+// websocket connections.
+func handleNotifyUtxoDeltas(wsc *wsClient, icmd interface{}) (interface{}, error) {
+	wsc.server.ntfnMgr.RegisterUtxoDeltas(wsc)
+	return nil, nil
+}
+
+// handleStopNotifyUtxoDeltas implements the stopnotifyutxodeltas command
+// extension for websocket connections.
+func handleStopNotifyUtxoDeltas(wsc *wsClient, icmd interface{}) (interface{}, error) {
+	wsc.server.ntfnMgr.UnregisterUtxoDeltas(wsc)
+	return nil, nil
+}
+
+// Notification types and control requests of the UTXO delta notifications.
+type notificationUtxoDeltas struct {
+	sub   *blockchain.ChainEventSubscription
+	event *blockchain.ChainEvent
+}
+type notificationUtxoDeltasEnded blockchain.ChainEventSubscription
+type notificationRegisterUtxoDeltas wsClient
+type notificationUnregisterUtxoDeltas wsClient
+
+// RegisterUtxoDeltas requests UTXO delta notifications to the passed websocket
+// client.
+func (m *wsNotificationManager) RegisterUtxoDeltas(wsc *wsClient) {
+	m.queueNotification <- (*notificationRegisterUtxoDeltas)(wsc)
+}
+
+// UnregisterUtxoDeltas removes UTXO delta notifications for the passed
+// websocket client.
+func (m *wsNotificationManager) UnregisterUtxoDeltas(wsc *wsClient) {
+	m.queueNotification <- (*notificationUnregisterUtxoDeltas)(wsc)
+}
+
+// subscribeUtxoDeltas subscribes to the chain events and forwards them to the
+// notification handler until the subscription ends.  The chain events are
+// only subscribed to while clients are registered for UTXO delta
+// notifications, so the deltas are not computed otherwise.
+func (m *wsNotificationManager) subscribeUtxoDeltas() *blockchain.ChainEventSubscription {
+	sub := m.server.cfg.Chain.SubscribeChainEvents(
+		blockchain.DefaultChainEventsBuffer)
+	go func() {
+		for event := range sub.C {
+			n := &notificationUtxoDeltas{sub: sub, event: event}
+			select {
+			case m.queueNotification <- n:
+			case <-m.quit:
+				return
+			}
+		}
+		if sub.Err() != nil {
+			select {
+			case m.queueNotification <- (*notificationUtxoDeltasEnded)(sub):
+			case <-m.quit:
+			}
+		}
+	}()
+	return sub
+}
+
+// notifyUtxoDeltas notifies websocket clients that have registered for UTXO
+// delta updates of the outputs created and spent by a block connected to or
+// disconnected from the main chain.
+func (m *wsNotificationManager) notifyUtxoDeltas(clients map[chan struct{}]*wsClient,
+	event *blockchain.ChainEvent) {
+
+	if len(clients) == 0 {
+		return
+	}
+	ntfn := btcjson.NewUtxoDeltasNtfn(m.server.utxoDeltasResult(event.Type,
+		event.Block, event.Height, event.Created, event.Spent))
+	marshalledJSON, err := btcjson.MarshalCmd(nil, ntfn)
+	if err != nil {
+		rpcsLog.Errorf("Failed to marshal utxodeltas notification: %v",
+			err)
+		return
+	}
+	for _, wsc := range clients {
+		wsc.QueueNotification(marshalledJSON)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/pkt-cash/pktd/btcjson"
+)
+
+// TestGetUtxoDeltas ensures getutxodeltas validates its range and returns the
+// outputs created by the requested blocks in order.
+func TestGetUtxoDeltas(t *testing.T) {
+	tc, teardown := newTestChain(t, "utxodeltas", nil)
+	defer teardown()
+	params, chain, g := tc.params, tc.chain, tc.gen
+
+	s := &rpcServer{cfg: rpcserverConfig{Chain: chain, ChainParams: params}}
+	hashes, err := g.generate(params.GenesisHash, 3, nil)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+
+	getUtxoDeltas := func(start int32, count *int32) ([]btcjson.UtxoDeltasResult, error) {
+		cmd := btcjson.NewGetUtxoDeltasCmd(start, count)
+		result, err := handleGetUtxoDeltas(s, cmd, nil)
+		if err != nil {
+			return nil, err
+		}
+		return result.([]btcjson.UtxoDeltasResult), nil
+	}
+
+	// Out of range requests are rejected.
+	for _, test := range []struct {
+		start int32
+		count int32
+	}{
+		{-1, 1},
+		{4, 1},
+		{0, 0},
+		{0, maxUtxoDeltasBlocks + 1},
+	} {
+		count := test.count
+		if _, err := getUtxoDeltas(test.start, &count); err == nil {
+			t.Errorf("getutxodeltas %d %d: unexpected success",
+				test.start, test.count)
+		}
+	}
+
+	// The genesis block creates no spendable outputs and the generated
+	// blocks create their coinbase outputs.
+	deltas, err := getUtxoDeltas(0, nil)
+	if err != nil {
+		t.Fatalf("getutxodeltas: %v", err)
+	}
+	if len(deltas) != 4 {
+		t.Fatalf("got %d blocks, want 4", len(deltas))
+	}
+	if len(deltas[0].Created) != 0 || len(deltas[0].Spent) != 0 {
+		t.Fatalf("unexpected genesis deltas %+v", deltas[0])
+	}
+	for i, hash := range hashes {
+		delta := deltas[i+1]
+		if delta.Type != "connected" || delta.Hash != hash.String() ||
+			delta.Height != int32(i+1) || len(delta.Created) == 0 ||
+			len(delta.Spent) != 0 {
+
+			t.Fatalf("unexpected deltas %+v", delta)
+		}
+		for _, utxo := range delta.Created {
+			if !utxo.Coinbase || utxo.TxID == "" ||
+				utxo.Height != delta.Height {
+
+				t.Fatalf("unexpected created output %+v", utxo)
+			}
+		}
+	}
+
+	// The count limits the number of blocks returned.
+	count := int32(2)
+	deltas, err = getUtxoDeltas(2, &count)
+	if err != nil {
+		t.Fatalf("getutxodeltas: %v", err)
+	}
+	if len(deltas) != 2 || deltas[0].Hash != hashes[1].String() ||
+		deltas[1].Hash != hashes[2].String() {
+
+		t.Fatalf("unexpected deltas %+v", deltas)
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/btcjson"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/globalcfg"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// verifyChainTest describes a verifychain call and whether the chain is
+// expected to verify.
+type verifyChainTest struct {
+	name  string
+	level int32
+	depth int32
+	valid bool
+}
+
+// checkVerifyChain runs the passed verifychain calls against the server.
+func checkVerifyChain(t *testing.T, s *rpcServer, tests []verifyChainTest) {
+	t.Helper()
+
+	for _, test := range tests {
+		cmd := btcjson.NewVerifyChainCmd(btcjson.Int32(test.level),
+			btcjson.Int32(test.depth))
+		reply, err := handleVerifyChain(s, cmd, nil)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if reply.(bool) != test.valid {
+			t.Errorf("%s: got %v, want %v", test.name, reply,
+				test.valid)
+		}
+	}
+}
+
+// TestVerifyChainContext ensures verifychain reports a main chain block which
+// breaks the contextual rules from level 2 on, and only when the block is
+// within the requested depth.
+func TestVerifyChainContext(t *testing.T) {
+	tc, teardown := newTestChain(t, "verifychain", nil)
+	defer teardown()
+	params, chain, g := tc.params, tc.chain, tc.gen
+	s := &rpcServer{cfg: rpcserverConfig{
+		Chain:       chain,
+		ChainParams: params,
+		TimeSource:  blockchain.NewMedianTime(),
+	}}
+
+	hashes, err := g.generate(params.GenesisHash, 2, nil)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+
+	// Add a block claiming a higher difficulty than the required one.  It
+	// is sane on its own, but adding it fast skips the contextual checks,
+	// so it ends up in the main chain as if the database was corrupted.
+	block, err := g.newBlock(hashes[1], 3, nil)
+	if err != nil {
+		t.Fatalf("newBlock: %v", err)
+	}
+	msgBlock := block.MsgBlock()
+	msgBlock.Header.Bits = blockchain.BigToCompact(
+		new(big.Int).Rsh(params.PowLimit, 2))
+	if !solveHeader(&msgBlock.Header) {
+		t.Fatalf("unable to solve block")
+	}
+	block = btcutil.NewBlock(msgBlock)
+	_, _, err = chain.ProcessBlock(block, blockchain.BFFastAdd)
+	if err != nil {
+		t.Fatalf("ProcessBlock: %v", err)
+	}
+	if _, err := g.generate(block.Hash(), 2, nil); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+
+	checkVerifyChain(t, s, []verifyChainTest{
+		{"sanity only", 1, 0, true},
+		{"context above the block", 2, 2, true},
+		{"context down to the block", 2, 3, false},
+		{"context of the whole chain", 2, 0, false},
+		{"proofs of the whole chain", 3, 0, false},
+	})
+}
+
+// TestVerifyChainPacketCrypt ensures verifychain reports a main chain block
+// without a valid PacketCrypt proof from level 3 on.
+func TestVerifyChainPacketCrypt(t *testing.T) {
+	// Use the regression test network with PacketCrypt as the proof of
+	// work.  Its blocks are stored with a proof, so the genesis block is
+	// given a bogus one.
+	params := chaincfg.RegressionNetParams
+	params.GlobalConf.ProofOfWorkAlgorithm = globalcfg.PowPacketCrypt
+	bogusProof := &wire.PacketCryptProof{AnnProof: []byte{0}}
+	genesis := *params.GenesisBlock
+	genesis.Pcp = bogusProof
+	params.GenesisBlock = &genesis
+
+	db, teardown := newTestDB(t, "verifychainpc", &params)
+	defer teardown()
+	chain, err := blockchain.New(&blockchain.Config{
+		DB:          db,
+		ChainParams: &params,
+		TimeSource:  blockchain.NewMedianTime(),
+	})
+	if err != nil {
+		t.Fatalf("blockchain.New: %v", err)
+	}
+	s := &rpcServer{cfg: rpcserverConfig{
+		Chain:       chain,
+		ChainParams: &params,
+		TimeSource:  blockchain.NewMedianTime(),
+	}}
+
+	// The blocks are given the bogus proof too, which is not checked while
+	// adding them.
+	g := newTestGenerator(chain, &params)
+	g.submit = func(block *btcutil.Block) error {
+		block.MsgBlock().Pcp = bogusProof
+		_, _, err := chain.ProcessBlock(block, blockchain.BFNoPoWCheck)
+		return err
+	}
+	if _, err := g.generate(params.GenesisHash, 2, nil); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+
+	checkVerifyChain(t, s, []verifyChainTest{
+		{"sanity", 1, 0, true},
+		{"context", 2, 0, true},
+		{"proofs", 3, 0, false},
+	})
+}
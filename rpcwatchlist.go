@@ -0,0 +1,196 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/btcjson"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// watchMatcher matches transactions against the output scripts watched by
+// websocket clients through the loadwatchlist command.  Scripts are indexed by
+// their serialized bytes, so matching a transaction costs one map lookup per
+// input and output regardless of how many clients and scripts are watched.
+//
+// Outputs paying to a watched script are remembered so that the transactions
+// spending them match as well.
+//
+// A watchMatcher is owned by the notification manager and is not safe for
+// concurrent access.
+type watchMatcher struct {
+	params    *chaincfg.Params
+	scripts   map[string]map[chan struct{}]*wsClient
+	outPoints map[wire.OutPoint]string
+}
+
+// watchedTx is a transaction which matched the watch list of a client along
+// with the inputs and outputs which matched.
+type watchedTx struct {
+	wsc     *wsClient
+	matches []btcjson.WatchedTxMatch
+}
+
+// decodeWatchScripts returns the output scripts paying to the passed addresses
+// followed by the passed hex-encoded scripts.
+func decodeWatchScripts(addresses []string, hexScripts *[]string, params *chaincfg.Params) ([][]byte, error) {
+	var scripts [][]byte
+	for _, a := range addresses {
+		addr, err := btcutil.DecodeAddress(a, params)
+		if err != nil {
+			return nil, &btcjson.RPCError{
+				Code: btcjson.ErrRPCInvalidAddressOrKey,
+				Message: fmt.Sprintf("Invalid address or key: %v",
+					a),
+			}
+		}
+		script, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCInvalidAddressOrKey,
+				Message: err.Error(),
+			}
+		}
+		scripts = append(scripts, script)
+	}
+	if hexScripts != nil {
+		for _, s := range *hexScripts {
+			script, err := hex.DecodeString(s)
+			if err != nil || len(script) == 0 {
+				return nil, rpcDecodeHexError(s)
+			}
+			scripts = append(scripts, script)
+		}
+	}
+	return scripts, nil
+}
+
+// newWatchMatcher returns an empty watch matcher for the passed network.
+func newWatchMatcher(params *chaincfg.Params) *watchMatcher {
+	return &watchMatcher{
+		params:    params,
+		scripts:   make(map[string]map[chan struct{}]*wsClient),
+		outPoints: make(map[wire.OutPoint]string),
+	}
+}
+
+// add adds the passed scripts to the watch list of the websocket client.
+func (w *watchMatcher) add(wsc *wsClient, scripts [][]byte) {
+	for _, script := range scripts {
+		key := string(script)
+
+		// Track the script in the client as well so it can be quickly
+		// removed on disconnect.
+		wsc.watchedScripts[key] = struct{}{}
+
+		cmap, ok := w.scripts[key]
+		if !ok {
+			cmap = make(map[chan struct{}]*wsClient)
+			w.scripts[key] = cmap
+		}
+		cmap[wsc.quit] = wsc
+	}
+}
+
+// remove removes the passed scripts from the watch list of the websocket
+// client.
+func (w *watchMatcher) remove(wsc *wsClient, scripts [][]byte) {
+	for _, script := range scripts {
+		w.removeScript(wsc, string(script))
+	}
+}
+
+// removeClient removes every script watched by the websocket client.
+func (w *watchMatcher) removeClient(wsc *wsClient) {
+	for key := range wsc.watchedScripts {
+		w.removeScript(wsc, key)
+	}
+}
+
+// removeScript removes a single script from the watch list of the websocket
+// client.
+func (w *watchMatcher) removeScript(wsc *wsClient, key string) {
+	delete(wsc.watchedScripts, key)
+
+	cmap, ok := w.scripts[key]
+	if !ok {
+		return
+	}
+	delete(cmap, wsc.quit)
+
+	// Remove the map entry altogether if there are no more clients
+	// interested in it.  Outputs paying to the script are dropped lazily
+	// when they are spent.
+	if len(cmap) == 0 {
+		delete(w.scripts, key)
+	}
+}
+
+// isEmpty returns whether no client is watching any script.
+func (w *watchMatcher) isEmpty() bool {
+	return len(w.scripts) == 0
+}
+
+// match returns the clients which watch a script paid to or spent from by the
+// passed transaction, keyed by their quit channels.  When mined is true the
+// transaction was included in a block and the watched outputs it spends are
+// forgotten.
+func (w *watchMatcher) match(tx *btcutil.Tx, mined bool) map[chan struct{}]*watchedTx {
+	var matched map[chan struct{}]*watchedTx
+	addMatch := func(key string, input bool, index uint32) bool {
+		cmap, ok := w.scripts[key]
+		if !ok {
+			return false
+		}
+		m := btcjson.WatchedTxMatch{
+			Script: hex.EncodeToString([]byte(key)),
+			Input:  input,
+			Index:  index,
+		}
+		_, addrs, _, err := txscript.ExtractPkScriptAddrs([]byte(key),
+			w.params)
+		if err == nil && len(addrs) == 1 {
+			m.Address = addrs[0].EncodeAddress()
+		}
+		if matched == nil {
+			matched = make(map[chan struct{}]*watchedTx)
+		}
+		for quit, wsc := range cmap {
+			wtx, ok := matched[quit]
+			if !ok {
+				wtx = &watchedTx{wsc: wsc}
+				matched[quit] = wtx
+			}
+			wtx.matches = append(wtx.matches, m)
+		}
+		return true
+	}
+
+	msgTx := tx.MsgTx()
+	for i, txIn := range msgTx.TxIn {
+		prevOut := txIn.PreviousOutPoint
+		key, ok := w.outPoints[prevOut]
+		if !ok {
+			continue
+		}
+		if !addMatch(key, true, uint32(i)) || mined {
+			delete(w.outPoints, prevOut)
+		}
+	}
+	for i, txOut := range msgTx.TxOut {
+		key := string(txOut.PkScript)
+		if addMatch(key, false, uint32(i)) {
+			op := wire.OutPoint{Hash: *tx.Hash(), Index: uint32(i)}
+			w.outPoints[op] = key
+		}
+	}
+
+	return matched
+}
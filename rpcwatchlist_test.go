@@ -0,0 +1,117 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// newWatchTestClient returns a websocket client with just the state used by
+// the watch matcher.
+func newWatchTestClient() *wsClient {
+	return &wsClient{
+		quit:           make(chan struct{}),
+		watchedScripts: make(map[string]struct{}),
+	}
+}
+
+// TestWatchMatcher ensures transactions paying to and spending from watched
+// scripts are matched to the clients watching them.
+func TestWatchMatcher(t *testing.T) {
+	params := &chaincfg.RegressionNetParams
+	addr, err := btcutil.NewAddressPubKeyHash(make([]byte, 20), params)
+	if err != nil {
+		t.Fatalf("NewAddressPubKeyHash: %v", err)
+	}
+	scripts, err := decodeWatchScripts([]string{addr.EncodeAddress()},
+		&[]string{"6a"}, params)
+	if err != nil {
+		t.Fatalf("decodeWatchScripts: %v", err)
+	}
+	p2pkh, nullData := scripts[0], scripts[1]
+	if want, _ := txscript.PayToAddrScript(addr); string(p2pkh) != string(want) {
+		t.Fatalf("unexpected address script %x", p2pkh)
+	}
+
+	w := newWatchMatcher(params)
+	c1 := newWatchTestClient()
+	c2 := newWatchTestClient()
+	w.add(c1, [][]byte{p2pkh})
+	w.add(c2, [][]byte{p2pkh, nullData})
+
+	// A transaction paying to the address matches both clients.
+	fund := wire.NewMsgTx(wire.TxVersion)
+	fund.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: chainhash.Hash{1}}, nil, nil))
+	fund.AddTxOut(wire.NewTxOut(1000, []byte{txscript.OP_TRUE}))
+	fund.AddTxOut(wire.NewTxOut(5000, p2pkh))
+	matched := w.match(btcutil.NewTx(fund), false)
+	if len(matched) != 2 {
+		t.Fatalf("unexpected number of matched clients: %d", len(matched))
+	}
+	m := matched[c1.quit].matches
+	if len(m) != 1 || m[0].Input || m[0].Index != 1 ||
+		m[0].Address != addr.EncodeAddress() ||
+		m[0].Script != hex.EncodeToString(p2pkh) {
+
+		t.Fatalf("unexpected matches %+v", m)
+	}
+
+	// Spending the watched output matches as well, along with the null
+	// data output only watched by the second client.
+	fundHash := fund.TxHash()
+	spend := wire.NewMsgTx(wire.TxVersion)
+	spend.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&fundHash, 1), nil, nil))
+	spend.AddTxOut(wire.NewTxOut(0, nullData))
+	matched = w.match(btcutil.NewTx(spend), true)
+	if m := matched[c1.quit].matches; len(m) != 1 || !m[0].Input || m[0].Index != 0 {
+		t.Fatalf("unexpected matches for first client %+v", m)
+	}
+	if m := matched[c2.quit].matches; len(m) != 2 {
+		t.Fatalf("unexpected matches for second client %+v", m)
+	}
+	if _, ok := w.outPoints[*wire.NewOutPoint(&fundHash, 1)]; ok {
+		t.Fatal("mined spend did not forget the watched output")
+	}
+
+	// Removing the scripts of a client stops it from matching, and
+	// removing the last watcher of a script drops the script.
+	w.remove(c1, [][]byte{p2pkh})
+	if len(c1.watchedScripts) != 0 {
+		t.Fatalf("client still tracks %d scripts", len(c1.watchedScripts))
+	}
+	matched = w.match(btcutil.NewTx(fund), false)
+	if _, ok := matched[c1.quit]; ok || len(matched) != 1 {
+		t.Fatalf("unexpected matched clients after removal: %d",
+			len(matched))
+	}
+	w.removeClient(c2)
+	if !w.isEmpty() {
+		t.Fatal("watch matcher not empty after removing every client")
+	}
+	if matched := w.match(btcutil.NewTx(fund), false); len(matched) != 0 {
+		t.Fatalf("unexpected matched clients: %d", len(matched))
+	}
+}
+
+// TestDecodeWatchScripts ensures invalid addresses and scripts are rejected.
+func TestDecodeWatchScripts(t *testing.T) {
+	params := &chaincfg.RegressionNetParams
+	if _, err := decodeWatchScripts([]string{"bogus"}, nil, params); err == nil {
+		t.Error("expected error for invalid address")
+	}
+	for _, s := range []string{"zz", ""} {
+		_, err := decodeWatchScripts(nil, &[]string{s}, params)
+		if err == nil {
+			t.Errorf("expected error for script %q", s)
+		}
+	}
+}
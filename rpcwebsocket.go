@@ -20,14 +20,15 @@ import (
 	"sync"
 	"time"
 
+	"github.com/pkt-cash/btcutil"
 	"github.com/pkt-cash/pktd/blockchain"
 	"github.com/pkt-cash/pktd/btcjson"
 	"github.com/pkt-cash/pktd/chaincfg"
 	"github.com/pkt-cash/pktd/chaincfg/chainhash"
 	"github.com/pkt-cash/pktd/database"
+	"github.com/pkt-cash/pktd/mempool"
 	"github.com/pkt-cash/pktd/txscript"
 	"github.com/pkt-cash/pktd/wire"
-	"github.com/pkt-cash/btcutil"
 
 	"github.com/btcsuite/websocket"
 	"golang.org/x/crypto/ripemd160"
@@ -65,16 +66,21 @@ type wsCommandHandler func(*wsClient, interface{}) (interface{}, error)
 var wsHandlers map[string]wsCommandHandler
 var wsHandlersBeforeInit = map[string]wsCommandHandler{
 	"loadtxfilter":              handleLoadTxFilter,
+	"loadwatchlist":             handleLoadWatchList,
 	"help":                      handleWebsocketHelp,
 	"notifyblocks":              handleNotifyBlocks,
 	"notifynewtransactions":     handleNotifyNewTransactions,
 	"notifyreceived":            handleNotifyReceived,
 	"notifyspent":               handleNotifySpent,
+	"notifyutxodeltas":          handleNotifyUtxoDeltas,
+	"removewatchlist":           handleRemoveWatchList,
 	"session":                   handleSession,
 	"stopnotifyblocks":          handleStopNotifyBlocks,
+	"streamblockheaders":        handleStreamBlockHeaders,
 	"stopnotifynewtransactions": handleStopNotifyNewTransactions,
 	"stopnotifyspent":           handleStopNotifySpent,
 	"stopnotifyreceived":        handleStopNotifyReceived,
+	"stopnotifyutxodeltas":      handleStopNotifyUtxoDeltas,
 	"rescan":                    handleRescan,
 	"rescanblocks":              handleRescanBlocks,
 }
@@ -85,7 +91,7 @@ var wsHandlersBeforeInit = map[string]wsCommandHandler{
 // server handler which runs each new connection in a new goroutine thereby
 // satisfying the requirement.
 func (s *rpcServer) WebsocketHandler(conn *websocket.Conn, remoteAddr string,
-	authenticated bool, isAdmin bool) {
+	authenticated bool, isAdmin bool, grant *rpcTokenGrant) {
 
 	// Clear the read deadline that was set before the websocket hijacked
 	// the connection.
@@ -104,7 +110,8 @@ func (s *rpcServer) WebsocketHandler(conn *websocket.Conn, remoteAddr string,
 	// Create a new websocket client to handle the new websocket connection
 	// and wait for it to shutdown.  Once it has shutdown (and hence
 	// disconnected), remove it and any notifications it registered for.
-	client, err := newWebsocketClient(s, conn, remoteAddr, authenticated, isAdmin)
+	client, err := newWebsocketClient(s, conn, remoteAddr, authenticated,
+		isAdmin, grant)
 	if err != nil {
 		rpcsLog.Errorf("Failed to serve client %s: %v", remoteAddr, err)
 		conn.Close()
@@ -230,11 +237,15 @@ func (m *wsNotificationManager) NotifyBlockDisconnected(block *btcutil.Block) {
 // NotifyMempoolTx passes a transaction accepted by mempool to the
 // notification manager for transaction notification processing.  If
 // isNew is true, the tx is is a new transaction, rather than one
-// added to the mempool during a reorg.
-func (m *wsNotificationManager) NotifyMempoolTx(tx *btcutil.Tx, isNew bool) {
+// added to the mempool during a reorg.  The provenance of the transaction,
+// when known, is included in the verbose notifications.
+func (m *wsNotificationManager) NotifyMempoolTx(tx *btcutil.Tx,
+	provenance *mempool.TxProvenance, isNew bool) {
+
 	n := &notificationTxAcceptedByMempool{
-		isNew: isNew,
-		tx:    tx,
+		isNew:      isNew,
+		tx:         tx,
+		provenance: provenance,
 	}
 
 	// As NotifyMempoolTx will be called by mempool and the RPC server
@@ -449,8 +460,9 @@ func (f *wsClientFilter) removeUnspentOutPoint(op *wire.OutPoint) {
 type notificationBlockConnected btcutil.Block
 type notificationBlockDisconnected btcutil.Block
 type notificationTxAcceptedByMempool struct {
-	isNew bool
-	tx    *btcutil.Tx
+	isNew      bool
+	tx         *btcutil.Tx
+	provenance *mempool.TxProvenance
 }
 
 // Notification control requests
@@ -476,6 +488,15 @@ type notificationUnregisterAddr struct {
 	wsc  *wsClient
 	addr string
 }
+type notificationLoadWatchList struct {
+	wsc     *wsClient
+	reload  bool
+	scripts [][]byte
+}
+type notificationRemoveWatchList struct {
+	wsc     *wsClient
+	scripts [][]byte
+}
 
 // notificationHandler reads notifications and control messages from the queue
 // handler and processes one at a time.
@@ -494,6 +515,18 @@ func (m *wsNotificationManager) notificationHandler() {
 	txNotifications := make(map[chan struct{}]*wsClient)
 	watchedOutPoints := make(map[wire.OutPoint]map[chan struct{}]*wsClient)
 	watchedAddrs := make(map[string]map[chan struct{}]*wsClient)
+	watchList := newWatchMatcher(m.server.cfg.ChainParams)
+
+	// The clients registered for UTXO delta notifications, along with the
+	// subscription to the chain events made while there is any.
+	utxoDeltaNotifications := make(map[chan struct{}]*wsClient)
+	var utxoDeltas *blockchain.ChainEventSubscription
+	stopUtxoDeltas := func() {
+		if len(utxoDeltaNotifications) == 0 && utxoDeltas != nil {
+			utxoDeltas.Unsubscribe()
+			utxoDeltas = nil
+		}
+	}
 
 out:
 	for {
@@ -515,6 +548,11 @@ out:
 							watchedAddrs, tx, block)
 					}
 				}
+				if !watchList.isEmpty() {
+					for _, tx := range block.Transactions() {
+						m.notifyWatchedTx(watchList, tx, block)
+					}
+				}
 
 				if len(blockNotifications) != 0 {
 					m.notifyBlockConnected(blockNotifications,
@@ -535,10 +573,43 @@ out:
 
 			case *notificationTxAcceptedByMempool:
 				if n.isNew && len(txNotifications) != 0 {
-					m.notifyForNewTx(txNotifications, n.tx)
+					m.notifyForNewTx(txNotifications, n.tx,
+						n.provenance)
 				}
 				m.notifyForTx(watchedOutPoints, watchedAddrs, n.tx, nil)
 				m.notifyRelevantTxAccepted(n.tx, clients)
+				if !watchList.isEmpty() {
+					m.notifyWatchedTx(watchList, n.tx, nil)
+				}
+
+			case *notificationUtxoDeltas:
+				// Events of an ended subscription are ignored.
+				if n.sub == utxoDeltas {
+					m.notifyUtxoDeltas(utxoDeltaNotifications,
+						n.event)
+				}
+
+			case *notificationUtxoDeltasEnded:
+				// The subscription fell behind, subscribe again.
+				// The clients see the gap in the heights.
+				sub := (*blockchain.ChainEventSubscription)(n)
+				if sub == utxoDeltas {
+					rpcsLog.Warnf("UTXO delta notifications "+
+						"fell behind: %v", sub.Err())
+					utxoDeltas = m.subscribeUtxoDeltas()
+				}
+
+			case *notificationRegisterUtxoDeltas:
+				wsc := (*wsClient)(n)
+				utxoDeltaNotifications[wsc.quit] = wsc
+				if utxoDeltas == nil {
+					utxoDeltas = m.subscribeUtxoDeltas()
+				}
+
+			case *notificationUnregisterUtxoDeltas:
+				wsc := (*wsClient)(n)
+				delete(utxoDeltaNotifications, wsc.quit)
+				stopUtxoDeltas()
 
 			case *notificationRegisterBlocks:
 				wsc := (*wsClient)(n)
@@ -558,6 +629,8 @@ out:
 				// the client itself.
 				delete(blockNotifications, wsc.quit)
 				delete(txNotifications, wsc.quit)
+				delete(utxoDeltaNotifications, wsc.quit)
+				stopUtxoDeltas()
 				for k := range wsc.spentRequests {
 					op := k
 					m.removeSpentRequest(watchedOutPoints, wsc, &op)
@@ -565,6 +638,7 @@ out:
 				for addr := range wsc.addrRequests {
 					m.removeAddrRequest(watchedAddrs, wsc, addr)
 				}
+				watchList.removeClient(wsc)
 				delete(clients, wsc.quit)
 
 			case *notificationRegisterSpent:
@@ -579,6 +653,15 @@ out:
 			case *notificationUnregisterAddr:
 				m.removeAddrRequest(watchedAddrs, n.wsc, n.addr)
 
+			case *notificationLoadWatchList:
+				if n.reload {
+					watchList.removeClient(n.wsc)
+				}
+				watchList.add(n.wsc, n.scripts)
+
+			case *notificationRemoveWatchList:
+				watchList.remove(n.wsc, n.scripts)
+
 			case *notificationRegisterNewMempoolTxs:
 				wsc := (*wsClient)(n)
 				txNotifications[wsc.quit] = wsc
@@ -599,6 +682,9 @@ out:
 		}
 	}
 
+	if utxoDeltas != nil {
+		utxoDeltas.Unsubscribe()
+	}
 	for _, c := range clients {
 		c.Disconnect()
 	}
@@ -822,7 +908,9 @@ func (m *wsNotificationManager) UnregisterNewMempoolTxsUpdates(wsc *wsClient) {
 
 // notifyForNewTx notifies websocket clients that have registered for updates
 // when a new transaction is added to the memory pool.
-func (m *wsNotificationManager) notifyForNewTx(clients map[chan struct{}]*wsClient, tx *btcutil.Tx) {
+func (m *wsNotificationManager) notifyForNewTx(clients map[chan struct{}]*wsClient,
+	tx *btcutil.Tx, provenance *mempool.TxProvenance) {
+
 	txHashStr := tx.Hash().String()
 	mtx := tx.MsgTx()
 
@@ -853,6 +941,13 @@ func (m *wsNotificationManager) notifyForNewTx(clients map[chan struct{}]*wsClie
 			if err != nil {
 				return
 			}
+			if provenance != nil {
+				rawTx.Provenance = &btcjson.TxProvenanceResult{
+					FirstSeen:  provenance.FirstSeen.Unix(),
+					Origin:     provenance.Origin.String(),
+					Announcers: int32(provenance.Announcers),
+				}
+			}
 
 			verboseNtfn = btcjson.NewTxAcceptedVerboseNtfn(*rawTx)
 			marshalledJSONVerbose, err = btcjson.MarshalCmd(nil,
@@ -1059,6 +1154,31 @@ func (m *wsNotificationManager) notifyRelevantTxAccepted(tx *btcutil.Tx,
 	}
 }
 
+// notifyWatchedTx sends a watchedtx notification to every websocket client
+// watching a script the passed transaction pays to or spends from.  The block
+// is nil for a transaction accepted to the mempool.
+func (m *wsNotificationManager) notifyWatchedTx(watchList *watchMatcher,
+	tx *btcutil.Tx, block *btcutil.Block) {
+
+	matched := watchList.match(tx, block != nil)
+	if len(matched) == 0 {
+		return
+	}
+
+	txHex := txHexString(tx.MsgTx())
+	details := blockDetails(block, tx.Index())
+	for _, wtx := range matched {
+		n := btcjson.NewWatchedTxNtfn(txHex, wtx.matches, details)
+		marshalled, err := btcjson.MarshalCmd(nil, n)
+		if err != nil {
+			rpcsLog.Errorf("Failed to marshal watchedtx "+
+				"notification: %v", err)
+			return
+		}
+		wtx.wsc.QueueNotification(marshalled)
+	}
+}
+
 // notifyForTx examines the inputs and outputs of the passed transaction,
 // notifying websocket clients of outputs spending to a watched address
 // and inputs spending a watched outpoint.
@@ -1177,6 +1297,25 @@ func (*wsNotificationManager) removeAddrRequest(addrs map[string]map[chan struct
 	}
 }
 
+// LoadWatchList adds the passed scripts to the watch list of the websocket
+// client, replacing the existing list when reload is true.
+func (m *wsNotificationManager) LoadWatchList(wsc *wsClient, reload bool, scripts [][]byte) {
+	m.queueNotification <- &notificationLoadWatchList{
+		wsc:     wsc,
+		reload:  reload,
+		scripts: scripts,
+	}
+}
+
+// RemoveWatchList removes the passed scripts from the watch list of the
+// websocket client.
+func (m *wsNotificationManager) RemoveWatchList(wsc *wsClient, scripts [][]byte) {
+	m.queueNotification <- &notificationRemoveWatchList{
+		wsc:     wsc,
+		scripts: scripts,
+	}
+}
+
 // AddClient adds the passed websocket client to the notification manager.
 func (m *wsNotificationManager) AddClient(wsc *wsClient) {
 	m.queueNotification <- (*notificationRegisterClient)(wsc)
@@ -1269,6 +1408,10 @@ type wsClient struct {
 	// false means its access is only to the limited set of RPC calls.
 	isAdmin bool
 
+	// grant is the access granted by the RPC token the client authenticated
+	// with, if any, whose caveats further restrict its commands.
+	grant *rpcTokenGrant
+
 	// sessionID is a random ID generated for each client when connected.
 	// These IDs may be queried by a client using the session RPC.  A change
 	// to the session ID indicates that the client reconnected.
@@ -1288,6 +1431,11 @@ type wsClient struct {
 	// Owned by the notification manager.
 	spentRequests map[wire.OutPoint]struct{}
 
+	// watchedScripts is the set of serialized output scripts on the watch
+	// list of the client, maintained so they can be removed when it
+	// disconnects.  Owned by the notification manager.
+	watchedScripts map[string]struct{}
+
 	// filterData is the new generation transaction filter backported from
 	// github.com/decred/dcrd for the new backported `loadtxfilter` and
 	// `rescanblocks` methods.
@@ -1446,6 +1594,22 @@ out:
 			}
 		}
 
+		// Check the caveats of the RPC token the client authenticated
+		// with, which may have expired since.
+		if c.grant != nil {
+			jsonErr := c.grant.authorize(cmd, time.Now())
+			if jsonErr != nil {
+				reply, err := createMarshalledReply(cmd.id, nil, jsonErr)
+				if err != nil {
+					rpcsLog.Errorf("Failed to marshal token "+
+						"failure reply: %v", err)
+					continue
+				}
+				c.SendMessage(reply, nil)
+				continue
+			}
+		}
+
 		// Asynchronously handle the request.  A semaphore is used to
 		// limit the number of concurrent requests currently being
 		// serviced.  If the semaphore can not be acquired, simply wait
@@ -1495,6 +1659,7 @@ func (c *wsClient) serviceRequest(r *parsedRPCCmd) {
 		result, err = wsHandler(c, r.cmd)
 	} else {
 		result, err = c.server.standardCmdResult(r, nil)
+		c.server.auditCmd(r, c.isAdmin, c.grant, c.addr, err)
 	}
 	reply, err := createMarshalledReply(r.id, result, err)
 	if err != nil {
@@ -1705,12 +1870,13 @@ func (c *wsClient) WaitForShutdown() {
 
 // newWebsocketClient returns a new websocket client given the notification
 // manager, websocket connection, remote address, and whether or not the client
-// has already been authenticated (via HTTP Basic access authentication).  The
-// returned client is ready to start.  Once started, the client will process
-// incoming and outgoing messages in separate goroutines complete with queuing
-// and asynchrous handling for long-running operations.
+// has already been authenticated (via HTTP Basic access authentication or an
+// RPC token).  The returned client is ready to start.  Once started, the
+// client will process incoming and outgoing messages in separate goroutines
+// complete with queuing and asynchrous handling for long-running operations.
 func newWebsocketClient(server *rpcServer, conn *websocket.Conn,
-	remoteAddr string, authenticated bool, isAdmin bool) (*wsClient, error) {
+	remoteAddr string, authenticated bool, isAdmin bool,
+	grant *rpcTokenGrant) (*wsClient, error) {
 
 	sessionID, err := wire.RandomUint64()
 	if err != nil {
@@ -1722,10 +1888,12 @@ func newWebsocketClient(server *rpcServer, conn *websocket.Conn,
 		addr:              remoteAddr,
 		authenticated:     authenticated,
 		isAdmin:           isAdmin,
+		grant:             grant,
 		sessionID:         sessionID,
 		server:            server,
 		addrRequests:      make(map[string]struct{}),
 		spentRequests:     make(map[wire.OutPoint]struct{}),
+		watchedScripts:    make(map[string]struct{}),
 		serviceRequestSem: makeSemaphore(cfg.RPCMaxConcurrentReqs),
 		ntfnChan:          make(chan []byte, 1), // nonblocking sync
 		sendChan:          make(chan wsResponse, websocketSendBufferSize),
@@ -1826,6 +1994,42 @@ func handleLoadTxFilter(wsc *wsClient, icmd interface{}) (interface{}, error) {
 	return nil, nil
 }
 
+// handleLoadWatchList implements the loadwatchlist command extension for
+// websocket connections.
+func handleLoadWatchList(wsc *wsClient, icmd interface{}) (interface{}, error) {
+	cmd, ok := icmd.(*btcjson.LoadWatchListCmd)
+	if !ok {
+		return nil, btcjson.ErrRPCInternal
+	}
+
+	scripts, err := decodeWatchScripts(cmd.Addresses, cmd.Scripts,
+		wsc.server.cfg.ChainParams)
+	if err != nil {
+		return nil, err
+	}
+
+	wsc.server.ntfnMgr.LoadWatchList(wsc, cmd.Reload, scripts)
+	return nil, nil
+}
+
+// handleRemoveWatchList implements the removewatchlist command extension for
+// websocket connections.
+func handleRemoveWatchList(wsc *wsClient, icmd interface{}) (interface{}, error) {
+	cmd, ok := icmd.(*btcjson.RemoveWatchListCmd)
+	if !ok {
+		return nil, btcjson.ErrRPCInternal
+	}
+
+	scripts, err := decodeWatchScripts(cmd.Addresses, cmd.Scripts,
+		wsc.server.cfg.ChainParams)
+	if err != nil {
+		return nil, err
+	}
+
+	wsc.server.ntfnMgr.RemoveWatchList(wsc, scripts)
+	return nil, nil
+}
+
 // handleNotifyBlocks implements the notifyblocks command extension for
 // websocket connections.
 func handleNotifyBlocks(wsc *wsClient, icmd interface{}) (interface{}, error) {
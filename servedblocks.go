@@ -0,0 +1,93 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// servedBlock is an entry of the served block cache.
+type servedBlock struct {
+	hash  chainhash.Hash
+	block *wire.MsgBlock
+}
+
+// servedBlockCache keeps the blocks most recently served to peers, so the
+// blocks near the tip, which every syncing peer requests, are read from the
+// database and deserialized once rather than once per peer.  The cached blocks
+// are shared by the peers and must not be modified.
+type servedBlockCache struct {
+	mtx    sync.Mutex
+	blocks map[chainhash.Hash]*list.Element
+	lru    *list.List // Most recently used first, contains *servedBlock.
+	limit  int
+}
+
+// newServedBlockCache returns a cache of at most limit blocks.  A zero limit
+// disables the cache.
+func newServedBlockCache(limit int) *servedBlockCache {
+	return &servedBlockCache{
+		blocks: make(map[chainhash.Hash]*list.Element),
+		lru:    list.New(),
+		limit:  limit,
+	}
+}
+
+// Lookup returns the cached block with the passed hash, or nil when it is not
+// cached.
+//
+// This function is safe for concurrent access.
+func (c *servedBlockCache) Lookup(hash *chainhash.Hash) *wire.MsgBlock {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	elem, ok := c.blocks[*hash]
+	if !ok {
+		return nil
+	}
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*servedBlock).block
+}
+
+// Add caches the passed block, evicting the least recently served block when
+// the cache is full.
+//
+// This function is safe for concurrent access.
+func (c *servedBlockCache) Add(hash *chainhash.Hash, block *wire.MsgBlock) {
+	if c.limit <= 0 {
+		return
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if elem, ok := c.blocks[*hash]; ok {
+		c.lru.MoveToFront(elem)
+		return
+	}
+	if c.lru.Len() >= c.limit {
+		oldest := c.lru.Remove(c.lru.Back()).(*servedBlock)
+		delete(c.blocks, oldest.hash)
+	}
+	c.blocks[*hash] = c.lru.PushFront(&servedBlock{hash: *hash, block: block})
+}
+
+// Size returns the number of cached blocks and their serialized size in bytes.
+//
+// This function is safe for concurrent access.
+func (c *servedBlockCache) Size() (int, int64) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	var size int64
+	for elem := c.lru.Front(); elem != nil; elem = elem.Next() {
+		size += int64(elem.Value.(*servedBlock).block.SerializeSize())
+	}
+	return c.lru.Len(), size
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// TestServedBlockCache ensures the served block cache evicts the least
+// recently served block and that a zero limit disables it.
+func TestServedBlockCache(t *testing.T) {
+	blocks := make([]*wire.MsgBlock, 3)
+	hashes := make([]chainhash.Hash, 3)
+	for i := range blocks {
+		blocks[i] = &wire.MsgBlock{Header: wire.BlockHeader{Nonce: uint32(i)}}
+		hashes[i] = blocks[i].BlockHash()
+	}
+
+	c := newServedBlockCache(2)
+	c.Add(&hashes[0], blocks[0])
+	c.Add(&hashes[1], blocks[1])
+
+	// Serving the first block again makes the second one the least
+	// recently served.
+	if c.Lookup(&hashes[0]) != blocks[0] {
+		t.Fatal("cached block not found")
+	}
+	c.Add(&hashes[2], blocks[2])
+	if c.Lookup(&hashes[1]) != nil {
+		t.Fatal("least recently served block not evicted")
+	}
+	if c.Lookup(&hashes[0]) != blocks[0] || c.Lookup(&hashes[2]) != blocks[2] {
+		t.Fatal("recently served block evicted")
+	}
+
+	c = newServedBlockCache(0)
+	c.Add(&hashes[0], blocks[0])
+	if c.Lookup(&hashes[0]) != nil {
+		t.Fatal("block cached with a zero limit")
+	}
+}
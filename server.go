@@ -14,6 +14,7 @@ import (
 	"fmt"
 	"math"
 	"net"
+	"path/filepath"
 	"runtime"
 	"sort"
 	"strconv"
@@ -27,11 +28,13 @@ import (
 	"github.com/pkt-cash/pktd/addrmgr"
 	"github.com/pkt-cash/pktd/blockchain"
 	"github.com/pkt-cash/pktd/blockchain/indexers"
+	"github.com/pkt-cash/pktd/blockchain/utreexo"
 	"github.com/pkt-cash/pktd/chaincfg"
 	"github.com/pkt-cash/pktd/chaincfg/chainhash"
 	"github.com/pkt-cash/pktd/chaincfg/globalcfg"
 	"github.com/pkt-cash/pktd/connmgr"
 	"github.com/pkt-cash/pktd/database"
+	"github.com/pkt-cash/pktd/hooks"
 	"github.com/pkt-cash/pktd/mempool"
 	"github.com/pkt-cash/pktd/mining"
 	"github.com/pkt-cash/pktd/mining/cpuminer"
@@ -117,6 +120,27 @@ func (a simpleAddr) Network() string {
 // Ensure simpleAddr implements the net.Addr interface.
 var _ net.Addr = simpleAddr{}
 
+// p2pListener is a listener accepting peer connections along with the listen
+// address it was created for, which may be a wildcard or name a network
+// interface, the permissions it grants the peers connecting to it and whether
+// it was added with the listeners RPC.
+type p2pListener struct {
+	net.Listener
+	listenAddr string
+	perms      peerPermissions
+	runtime    bool
+}
+
+// Accept waits for and returns the next peer connection, along with the
+// permissions of the listener when it grants any.
+func (l *p2pListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil || l.perms == 0 {
+		return conn, err
+	}
+	return &permissionedConn{Conn: conn, perms: l.perms}, nil
+}
+
 // broadcastMsg provides the ability to house a bitcoin message to be broadcast
 // to all connected peers except specified excluded peers.
 type broadcastMsg struct {
@@ -133,10 +157,12 @@ type broadcastInventoryAdd relayMsg
 type broadcastInventoryDel *wire.InvVect
 
 // relayMsg packages an inventory vector along with the newly discovered
-// inventory so the relay has access to that information.
+// inventory so the relay has access to that information.  Local is set for
+// transactions submitted locally, which are trickled in the local lane.
 type relayMsg struct {
 	invVect *wire.InvVect
 	data    interface{}
+	local   bool
 }
 
 // updatePeerHeightsMsg is a message sent from the blockmanager to the server
@@ -231,18 +257,68 @@ type server struct {
 	timeSource           blockchain.MedianTimeSource
 	services             wire.ServiceFlag
 
+	// externalNets holds the networks, ipv4, ipv6 or onion, of the
+	// --externalip addresses.  The bound addresses of the listeners in
+	// these networks are not advertised.  listenerMtx serializes the
+	// addition and removal of listeners with the listeners RPC.
+	externalNets map[string]struct{}
+	listenerMtx  sync.Mutex
+
 	// The following fields are used for optional indexes.  They will be nil
 	// if the associated index is not enabled.  These fields are set during
 	// initial creation of the server and never changed afterwards, so they
 	// do not need to be protected for concurrent access.
-	txIndex   *indexers.TxIndex
-	addrIndex *indexers.AddrIndex
-	cfIndex   *indexers.CfIndex
+	txIndex        *indexers.TxIndex
+	addrIndex      *indexers.AddrIndex
+	cfIndex        *indexers.CfIndex
+	utxoStatsIndex *indexers.UtxoStatsIndex
+	feeStatsIndex  *indexers.FeeStatsIndex
+	utreexoIndex   *indexers.UtreexoIndex
 
 	// The fee estimator keeps track of how long transactions are left in
 	// the mempool before they are mined into blocks.
 	feeEstimator *mempool.FeeEstimator
 
+	// webhooks sends block and transaction events to the configured
+	// webhooks.  It is nil when no webhooks are configured.
+	webhooks *webhookManager
+
+	// deposits tracks the deposits to the addresses bound to reference
+	// IDs.  It is nil unless an account bundle is configured.
+	deposits *depositTracker
+
+	// coldWatch raises an alarm on spends from cold storage addresses.  It
+	// is nil when no addresses are watched.
+	coldWatch *coldWatcher
+
+	// hooks are the operator hooks notified of the blocks and able to
+	// reject transactions from the mempool.  It is nil when none are
+	// configured.
+	hooks *hooks.Manager
+
+	// consensusRecorder records the blocks processed by the chain when
+	// --recordconsensus is set.  It is nil otherwise.
+	consensusRecorder *consensusRecorder
+
+	// servedBlocks caches the blocks recently served to peers.
+	servedBlocks *servedBlockCache
+
+	// msgCapture records the messages exchanged with the peers while it
+	// is running.
+	msgCapture *msgCapture
+
+	// partitionMonitor raises alerts when the node appears to be cut off
+	// from the network.  It is nil when disabled.
+	partitionMonitor *partitionMonitor
+
+	// diskMonitor enters the safe mode when the disk of the data directory
+	// is about to be full.  It is nil when disabled.
+	diskMonitor *diskMonitor
+
+	// explorer serves the read-only block explorer.  It is nil when no
+	// explorer listeners are configured.
+	explorer *explorer
+
 	// cfCheckptCaches stores a cached slice of filter headers for cfcheckpt
 	// messages for each filter type.
 	cfCheckptCaches    map[wire.FilterType][]cfHeaderKV
@@ -255,13 +331,19 @@ type server struct {
 	// agentWhitelist is a list of whitelisted user agent substrings, no
 	// whitelisting will be applied if the list is empty or nil.
 	agentWhitelist []string
+
+	// netGroupKey is the random key used to hash the network groups of
+	// inbound peers when choosing which ones are protected from eviction.
+	netGroupKey [32]byte
 }
 
 // serverPeer extends the peer to maintain state shared by the server and
 // the blockmanager.
 type serverPeer struct {
 	// The following variables must only be used atomically
-	feeFilter int64
+	feeFilter     int64
+	lastTxTime    int64
+	lastBlockTime int64
 
 	*peer.Peer
 
@@ -272,7 +354,7 @@ type serverPeer struct {
 	relayMtx       sync.Mutex
 	disableRelayTx bool
 	sentAddrs      bool
-	isWhitelisted  bool
+	permissions    peerPermissions
 	filter         *bloom.Filter
 	knownAddresses map[string]struct{}
 	banScore       connmgr.DynamicBanScore
@@ -317,6 +399,11 @@ func (sp *serverPeer) addressKnown(na *wire.NetAddress) bool {
 	return exists
 }
 
+// hasPermission returns whether the peer was granted the passed permission.
+func (sp *serverPeer) hasPermission(perm peerPermissions) bool {
+	return sp.permissions&perm != 0
+}
+
 // setDisableRelayTx toggles relaying of transactions for the given peer.
 // It is safe for concurrent access.
 func (sp *serverPeer) setDisableRelayTx(disable bool) {
@@ -365,7 +452,7 @@ func (sp *serverPeer) addBanScore(persistent, transient uint32, reason string) {
 	if cfg.DisableBanning {
 		return
 	}
-	if sp.isWhitelisted {
+	if sp.hasPermission(permNoBan) {
 		peerLog.Debugf("Misbehaving whitelisted peer %s: %s", sp, reason)
 		return
 	}
@@ -509,8 +596,9 @@ func (sp *serverPeer) OnVersion(_ *peer.Peer, msg *wire.MsgVersion) *wire.MsgRej
 // bloom filter loaded, the contents are filtered accordingly.
 func (sp *serverPeer) OnMemPool(_ *peer.Peer, msg *wire.MsgMemPool) {
 	// Only allow mempool requests if the server has bloom filtering
-	// enabled.
-	if sp.server.services&wire.SFNodeBloom != wire.SFNodeBloom {
+	// enabled, unless the peer is allowed to download the mempool.
+	download := sp.hasPermission(permDownload)
+	if sp.server.services&wire.SFNodeBloom != wire.SFNodeBloom && !download {
 		peerLog.Debugf("peer %v sent mempool request with bloom "+
 			"filtering disabled -- disconnecting", sp)
 		sp.Disconnect()
@@ -521,7 +609,9 @@ func (sp *serverPeer) OnMemPool(_ *peer.Peer, msg *wire.MsgMemPool) {
 	// The ban score accumulates and passes the ban threshold if a burst of
 	// mempool messages comes from a peer. The score decays each minute to
 	// half of its value.
-	sp.addBanScore(0, 33, "mempool")
+	if !download {
+		sp.addBanScore(0, 33, "mempool")
+	}
 
 	// Generate inventory message with the available transactions in the
 	// transaction memory pool.  Limit it to the max allowed inventory
@@ -574,8 +664,15 @@ func (sp *serverPeer) OnTx(_ *peer.Peer, msg *wire.MsgTx) {
 	// processed and known good or bad.  This helps prevent a malicious peer
 	// from queuing up a bunch of bad transactions before disconnecting (or
 	// being disconnected) and wasting memory.
-	sp.server.syncManager.QueueTx(tx, sp.Peer, sp.txProcessed)
+	sp.server.syncManager.QueueTx(tx, sp.Peer,
+		sp.permissions.txRelayFlags(), sp.txProcessed)
 	<-sp.txProcessed
+
+	// Remember when the peer last relayed a transaction which was accepted
+	// so inbound peers relaying new transactions are not evicted.
+	if sp.server.txMemPool.HaveTransaction(tx.Hash()) {
+		atomic.StoreInt64(&sp.lastTxTime, time.Now().Unix())
+	}
 }
 
 // OnBlock is invoked when a peer receives a block bitcoin message.  It
@@ -602,6 +699,12 @@ func (sp *serverPeer) OnBlock(_ *peer.Peer, msg *wire.MsgBlock, buf []byte) {
 	// the bitcoin block has been fully processed.
 	sp.server.syncManager.QueueBlock(block, sp.Peer, sp.blockProcessed)
 	<-sp.blockProcessed
+
+	// Remember when the peer last relayed a block which was accepted so
+	// inbound peers relaying new blocks are not evicted.
+	if have, err := sp.server.chain.HaveBlock(block.Hash()); err == nil && have {
+		atomic.StoreInt64(&sp.lastBlockTime, time.Now().Unix())
+	}
 }
 
 // OnInv is invoked when a peer receives an inv bitcoin message and is
@@ -644,6 +747,9 @@ func (sp *serverPeer) OnInv(_ *peer.Peer, msg *wire.MsgInv) {
 // OnHeaders is invoked when a peer receives a headers bitcoin
 // message.  The message is passed down to the sync manager.
 func (sp *serverPeer) OnHeaders(_ *peer.Peer, msg *wire.MsgHeaders) {
+	if sp.server.partitionMonitor != nil {
+		sp.server.partitionMonitor.ObserveHeaders(sp.ID(), msg.Headers)
+	}
 	sp.server.syncManager.QueueHeaders(msg, sp.Peer)
 }
 
@@ -661,7 +767,11 @@ func (sp *serverPeer) OnGetData(_ *peer.Peer, msg *wire.MsgGetData) {
 	// bursts of small requests are not penalized as that would potentially ban
 	// peers performing IBD.
 	// This incremental score decays each minute to half of its value.
-	sp.addBanScore(0, uint32(length)*99/wire.MaxInvPerMsg, "getdata")
+	// Peers allowed to download are not penalized.
+	if !sp.hasPermission(permDownload) {
+		sp.addBanScore(0, uint32(length)*99/wire.MaxInvPerMsg,
+			"getdata")
+	}
 
 	// We wait on this wait channel periodically to prevent queuing
 	// far more data than we can send in a reasonable time, wasting memory.
@@ -1154,6 +1264,54 @@ func (sp *serverPeer) enforceNodeBloomFlag(cmd string) bool {
 	return true
 }
 
+// OnGetUtreexoProof is invoked when a peer receives a getuproof bitcoin
+// message.  It replies with the roots of the utreexo accumulator and the proofs
+// of the requested outputs which are unspent.
+func (sp *serverPeer) OnGetUtreexoProof(_ *peer.Peer, msg *wire.MsgGetUtreexoProof) {
+	// Ignore getuproof requests if the accumulator is not maintained or
+	// not in sync.
+	if sp.server.utreexoIndex == nil || !sp.server.syncManager.IsCurrent() {
+		return
+	}
+
+	leaves := make([]utreexo.LeafData, 0, len(msg.OutPoints))
+	for _, outPoint := range msg.OutPoints {
+		entry, err := sp.server.chain.FetchUtxoEntry(outPoint)
+		if err != nil || entry == nil || entry.IsSpent() {
+			continue
+		}
+		leaves = append(leaves, utreexo.LeafData{
+			OutPoint:   outPoint,
+			Height:     entry.BlockHeight(),
+			IsCoinBase: entry.IsCoinBase(),
+			Amount:     entry.Amount(),
+			PkScript:   entry.PkScript(),
+		})
+	}
+
+	// The outputs may have been spent since they were looked up, so only
+	// the ones still in the accumulator are proven.
+	tipHash, stump, proofs := sp.server.utreexoIndex.Prove(leaves)
+	proofMsg := wire.NewMsgUtreexoProof(tipHash, stump.NumLeaves, stump.Roots)
+	for i, proof := range proofs {
+		if proof == nil {
+			continue
+		}
+		leaf := &leaves[i]
+		proofMsg.AddProof(&wire.UtreexoProof{
+			OutPoint:   leaf.OutPoint,
+			Height:     leaf.Height,
+			IsCoinBase: leaf.IsCoinBase,
+			Amount:     leaf.Amount,
+			PkScript:   leaf.PkScript,
+			Position:   proof.Position,
+			Siblings:   proof.Siblings,
+		})
+	}
+
+	sp.QueueMessage(proofMsg, nil)
+}
+
 // OnFeeFilter is invoked when a peer receives a feefilter bitcoin message and
 // is used by remote peers to request that no transactions which have a fee rate
 // lower than provided value are inventoried to them.  The peer will be
@@ -1242,8 +1400,10 @@ func (sp *serverPeer) OnGetAddr(_ *peer.Peer, msg *wire.MsgGetAddr) {
 	}
 
 	// Do not accept getaddr requests from outbound peers.  This reduces
-	// fingerprinting attacks.
-	if !sp.Inbound() {
+	// fingerprinting attacks.  Peers allowed to relay addresses are always
+	// answered.
+	addr := sp.hasPermission(permAddr)
+	if !sp.Inbound() && !addr {
 		peerLog.Debugf("Ignoring getaddr request from outbound peer ",
 			"%v", sp)
 		return
@@ -1251,7 +1411,7 @@ func (sp *serverPeer) OnGetAddr(_ *peer.Peer, msg *wire.MsgGetAddr) {
 
 	// Only allow one getaddr request per connection to discourage
 	// address stamping of inv announcements.
-	if sp.sentAddrs {
+	if sp.sentAddrs && !addr {
 		peerLog.Debugf("Ignoring repeated getaddr request from peer ",
 			"%v", sp)
 		return
@@ -1376,6 +1536,16 @@ func (s *server) relayTransactions(txns []*mempool.TxDesc) {
 	}
 }
 
+// relayLocalTransactions is like relayTransactions for transactions which
+// were submitted locally, or accepted because of one that was.  They are
+// trickled to peers in the local lane.
+func (s *server) relayLocalTransactions(txns []*mempool.TxDesc) {
+	for _, txD := range txns {
+		iv := wire.NewInvVect(wire.InvTypeTx, txD.Tx.Hash())
+		s.relayInv <- relayMsg{invVect: iv, data: txD, local: true}
+	}
+}
+
 // AnnounceNewTransactions generates and relays inventory vectors and notifies
 // both websocket and getblocktemplate long poll clients of the passed
 // transactions.  This function should be called whenever new transactions
@@ -1390,6 +1560,31 @@ func (s *server) AnnounceNewTransactions(txns []*mempool.TxDesc) {
 	if s.rpcServer != nil {
 		s.rpcServer.NotifyNewTransactions(txns)
 	}
+
+	if s.webhooks != nil {
+		s.webhooks.NotifyNewTransactions(txns)
+	}
+
+	if s.coldWatch != nil {
+		s.coldWatch.NotifyNewTransactions(txns)
+	}
+}
+
+// fetchPrevOut returns the output spent by the passed outpoint from the
+// mempool or the utxo set, or nil when it is unknown or already spent.
+func (s *server) fetchPrevOut(op wire.OutPoint) *wire.TxOut {
+	tx, err := s.txMemPool.FetchTransaction(&op.Hash)
+	if err == nil {
+		if int(op.Index) < len(tx.MsgTx().TxOut) {
+			return tx.MsgTx().TxOut[op.Index]
+		}
+		return nil
+	}
+	entry, err := s.chain.FetchUtxoEntry(op)
+	if err != nil || entry == nil || entry.IsSpent() {
+		return nil
+	}
+	return wire.NewTxOut(entry.Amount(), entry.PkScript())
 }
 
 // Transaction has one confirmation on the main chain. Now we can mark it as no
@@ -1433,34 +1628,44 @@ func (s *server) pushTxMsg(sp *serverPeer, hash *chainhash.Hash, doneChan chan<-
 	return nil
 }
 
-// pushBlockMsg sends a block message for the provided block hash to the
-// connected peer.  An error is returned if the block hash is not known.
-func (s *server) pushBlockMsg(sp *serverPeer, hash *chainhash.Hash, doneChan chan<- struct{},
-	waitChan <-chan struct{}, encoding wire.MessageEncoding) error {
+// fetchServedBlock returns the block with the passed hash to serve it to a
+// peer, from the served block cache or else from the database.  The returned
+// block must not be modified.
+func (s *server) fetchServedBlock(hash *chainhash.Hash) (*wire.MsgBlock, error) {
+	if msgBlock := s.servedBlocks.Lookup(hash); msgBlock != nil {
+		return msgBlock, nil
+	}
 
 	// Fetch the raw block bytes from the database.
 	var blockBytes []byte
-	err := sp.server.db.View(func(dbTx database.Tx) error {
+	err := s.db.View(func(dbTx database.Tx) error {
 		var err error
 		blockBytes, err = dbTx.FetchBlock(hash)
 		return err
 	})
 	if err != nil {
-		peerLog.Tracef("Unable to fetch requested block hash %v: %v",
-			hash, err)
-
-		if doneChan != nil {
-			doneChan <- struct{}{}
-		}
-		return err
+		return nil, err
 	}
 
 	// Deserialize the block.
 	var msgBlock wire.MsgBlock
 	err = msgBlock.Deserialize(bytes.NewReader(blockBytes))
 	if err != nil {
-		peerLog.Tracef("Unable to deserialize requested block hash "+
-			"%v: %v", hash, err)
+		return nil, fmt.Errorf("unable to deserialize block: %v", err)
+	}
+	s.servedBlocks.Add(hash, &msgBlock)
+	return &msgBlock, nil
+}
+
+// pushBlockMsg sends a block message for the provided block hash to the
+// connected peer.  An error is returned if the block hash is not known.
+func (s *server) pushBlockMsg(sp *serverPeer, hash *chainhash.Hash, doneChan chan<- struct{},
+	waitChan <-chan struct{}, encoding wire.MessageEncoding) error {
+
+	msgBlock, err := s.fetchServedBlock(hash)
+	if err != nil {
+		peerLog.Tracef("Unable to fetch requested block hash %v: %v",
+			hash, err)
 
 		if doneChan != nil {
 			doneChan <- struct{}{}
@@ -1481,7 +1686,7 @@ func (s *server) pushBlockMsg(sp *serverPeer, hash *chainhash.Hash, doneChan cha
 	if !sendInv {
 		dc = doneChan
 	}
-	sp.QueueMessageWithEncoding(&msgBlock, dc, encoding)
+	sp.QueueMessageWithEncoding(msgBlock, dc, encoding)
 
 	// When the peer requests the final block that was advertised in
 	// response to a getblocks message which requested more blocks than
@@ -1514,8 +1719,8 @@ func (s *server) pushMerkleBlockMsg(sp *serverPeer, hash *chainhash.Hash,
 		return nil
 	}
 
-	// Fetch the raw block bytes from the database.
-	blk, err := sp.server.chain.BlockByHash(hash)
+	// Fetch the block from the database.
+	msgBlock, err := s.fetchServedBlock(hash)
 	if err != nil {
 		peerLog.Tracef("Unable to fetch requested block hash %v: %v",
 			hash, err)
@@ -1525,6 +1730,7 @@ func (s *server) pushMerkleBlockMsg(sp *serverPeer, hash *chainhash.Hash,
 		}
 		return err
 	}
+	blk := btcutil.NewBlock(msgBlock)
 
 	// Generate a merkle block by filtering the requested block according
 	// to the filter for the peer.
@@ -1629,8 +1835,10 @@ func (s *server) handleAddPeerMsg(state *peerState, sp *serverPeer) bool {
 
 	// TODO: Check for max peers from a single IP.
 
-	// Limit max number of total peers.
-	if state.Count() >= cfg.MaxPeers {
+	// Limit max number of total peers.  When all slots are taken, a new
+	// inbound peer may take the slot of an existing inbound peer chosen by
+	// the eviction policy.
+	if state.Count() >= cfg.MaxPeers && !(sp.Inbound() && s.evictInboundPeer(state, sp)) {
 		srvrLog.Infof("Max peers reached [%d] - disconnecting peer %s",
 			cfg.MaxPeers, sp)
 		sp.Disconnect()
@@ -1655,6 +1863,39 @@ func (s *server) handleAddPeerMsg(state *peerState, sp *serverPeer) bool {
 	return true
 }
 
+// evictInboundPeer disconnects an inbound peer chosen by the eviction policy to
+// make room for the passed new inbound peer.  It returns false when every
+// inbound peer is protected from eviction.  It is invoked from the peerHandler
+// goroutine.
+func (s *server) evictInboundPeer(state *peerState, newPeer *serverPeer) bool {
+	candidates := make([]*evictionCandidate, 0, len(state.inboundPeers))
+	for _, sp := range state.inboundPeers {
+		if sp.hasPermission(permNoBan) || !sp.Connected() || sp.NA() == nil {
+			continue
+		}
+		netGroup := addrmgr.GroupKey(sp.NA())
+		candidates = append(candidates, &evictionCandidate{
+			sp:            sp,
+			connected:     sp.TimeConnected(),
+			pingMicros:    sp.LastPingMicros(),
+			lastTx:        atomic.LoadInt64(&sp.lastTxTime),
+			lastBlock:     atomic.LoadInt64(&sp.lastBlockTime),
+			netGroup:      netGroup,
+			keyedNetGroup: keyedNetGroup(s.netGroupKey[:], netGroup),
+		})
+	}
+
+	evict := selectEvictionCandidate(candidates)
+	if evict == nil {
+		return false
+	}
+	srvrLog.Infof("Max peers reached [%d] - evicting inbound peer %s "+
+		"for %s", cfg.MaxPeers, evict.sp, newPeer)
+	delete(state.inboundPeers, evict.sp.ID())
+	evict.sp.Disconnect()
+	return true
+}
+
 // handleDonePeerMsg deals with peers that have signalled they are done.  It is
 // invoked from the peerHandler goroutine.
 func (s *server) handleDonePeerMsg(state *peerState, sp *serverPeer) {
@@ -1763,6 +2004,18 @@ func (s *server) handleRelayInvMsg(state *peerState, msg relayMsg) {
 					return
 				}
 			}
+
+			// Queue the transaction to be relayed by fee rate, or
+			// ahead of the relayed ones when it was submitted
+			// locally.
+			// It will be ignored if the peer is already known to
+			// have the inventory.
+			lane := peer.InvLaneRelay
+			if msg.local {
+				lane = peer.InvLaneLocal
+			}
+			sp.QueueTxInventory(msg.invVect, txD.FeePerKB, lane)
+			return
 		}
 
 		// Queue the inventory to be relayed with the next batch.
@@ -1963,26 +2216,27 @@ func disconnectPeer(peerList map[int32]*serverPeer, compareFunc func(*serverPeer
 func newPeerConfig(sp *serverPeer) *peer.Config {
 	return &peer.Config{
 		Listeners: peer.MessageListeners{
-			OnVersion:      sp.OnVersion,
-			OnMemPool:      sp.OnMemPool,
-			OnTx:           sp.OnTx,
-			OnBlock:        sp.OnBlock,
-			OnInv:          sp.OnInv,
-			OnHeaders:      sp.OnHeaders,
-			OnGetData:      sp.OnGetData,
-			OnGetBlocks:    sp.OnGetBlocks,
-			OnGetHeaders:   sp.OnGetHeaders,
-			OnGetCFilters:  sp.OnGetCFilters,
-			OnGetCFHeaders: sp.OnGetCFHeaders,
-			OnGetCFCheckpt: sp.OnGetCFCheckpt,
-			OnFeeFilter:    sp.OnFeeFilter,
-			OnFilterAdd:    sp.OnFilterAdd,
-			OnFilterClear:  sp.OnFilterClear,
-			OnFilterLoad:   sp.OnFilterLoad,
-			OnGetAddr:      sp.OnGetAddr,
-			OnAddr:         sp.OnAddr,
-			OnRead:         sp.OnRead,
-			OnWrite:        sp.OnWrite,
+			OnVersion:         sp.OnVersion,
+			OnMemPool:         sp.OnMemPool,
+			OnTx:              sp.OnTx,
+			OnBlock:           sp.OnBlock,
+			OnInv:             sp.OnInv,
+			OnHeaders:         sp.OnHeaders,
+			OnGetData:         sp.OnGetData,
+			OnGetBlocks:       sp.OnGetBlocks,
+			OnGetHeaders:      sp.OnGetHeaders,
+			OnGetCFilters:     sp.OnGetCFilters,
+			OnGetCFHeaders:    sp.OnGetCFHeaders,
+			OnGetCFCheckpt:    sp.OnGetCFCheckpt,
+			OnGetUtreexoProof: sp.OnGetUtreexoProof,
+			OnFeeFilter:       sp.OnFeeFilter,
+			OnFilterAdd:       sp.OnFilterAdd,
+			OnFilterClear:     sp.OnFilterClear,
+			OnFilterLoad:      sp.OnFilterLoad,
+			OnGetAddr:         sp.OnGetAddr,
+			OnAddr:            sp.OnAddr,
+			OnRead:            sp.OnRead,
+			OnWrite:           sp.OnWrite,
 
 			// Note: The reference client currently bans peers that send alerts
 			// not signed with its key.  We could verify against their key, but
@@ -2001,6 +2255,7 @@ func newPeerConfig(sp *serverPeer) *peer.Config {
 		DisableRelayTx:    cfg.BlocksOnly,
 		ProtocolVersion:   peer.MaxProtocolVersion,
 		TrickleInterval:   cfg.TrickleInterval,
+		TrickleBatchSize:  cfg.TrickleBatchSize,
 	}
 }
 
@@ -2010,9 +2265,9 @@ func newPeerConfig(sp *serverPeer) *peer.Config {
 // for disconnection.
 func (s *server) inboundPeerConnected(conn net.Conn) {
 	sp := newServerPeer(s, false)
-	sp.isWhitelisted = isWhitelisted(conn.RemoteAddr())
+	sp.permissions = connPermissions(conn)
 	sp.Peer = peer.NewInboundPeer(newPeerConfig(sp))
-	sp.AssociateConnection(conn)
+	sp.AssociateConnection(s.msgCapture.WrapConn(conn, true))
 	go s.peerDoneHandler(sp)
 }
 
@@ -2030,8 +2285,8 @@ func (s *server) outboundPeerConnected(c *connmgr.ConnReq, conn net.Conn) {
 	}
 	sp.Peer = p
 	sp.connReq = c
-	sp.isWhitelisted = isWhitelisted(conn.RemoteAddr())
-	sp.AssociateConnection(conn)
+	sp.permissions = whitelistPermissions(conn.RemoteAddr())
+	sp.AssociateConnection(s.msgCapture.WrapConn(conn, false))
 	go s.peerDoneHandler(sp)
 	s.addrManager.Attempt(sp.NA())
 }
@@ -2138,6 +2393,11 @@ out:
 	s.syncManager.Stop()
 	s.addrManager.Stop()
 
+	// No more blocks are processed once the sync manager is stopped.
+	if s.consensusRecorder != nil {
+		s.consensusRecorder.Close()
+	}
+
 	// Drain channels before exiting so nothing is left waiting around
 	// to send.
 cleanup:
@@ -2191,6 +2451,75 @@ func (s *server) ConnectedCount() int32 {
 	return <-replyChan
 }
 
+// partitionPeers returns the tips announced by the connected peers whose
+// version is known, for the partition monitor.
+func (s *server) partitionPeers() []partitionPeer {
+	replyChan := make(chan []*serverPeer)
+	select {
+	case s.query <- getPeersMsg{reply: replyChan}:
+	case <-s.quit:
+		return nil
+	}
+	serverPeers := <-replyChan
+
+	peers := make([]partitionPeer, 0, len(serverPeers))
+	for _, sp := range serverPeers {
+		if !sp.VersionKnown() {
+			continue
+		}
+		peers = append(peers, partitionPeer{
+			ID:     sp.ID(),
+			Addr:   sp.Addr(),
+			Height: sp.LastBlock(),
+			Hash:   sp.LastAnnouncedBlock(),
+		})
+	}
+	return peers
+}
+
+// explorerPeers returns the connected peers shown by the block explorer.
+func (s *server) explorerPeers() []*peer.Peer {
+	replyChan := make(chan []*serverPeer)
+	select {
+	case s.query <- getPeersMsg{reply: replyChan}:
+	case <-s.quit:
+		return nil
+	}
+	serverPeers := <-replyChan
+
+	peers := make([]*peer.Peer, 0, len(serverPeers))
+	for _, sp := range serverPeers {
+		peers = append(peers, sp.Peer)
+	}
+	return peers
+}
+
+// explorerFetchTx returns a confirmed transaction from the transaction index
+// and the hash of the block it is in.
+func (s *server) explorerFetchTx(hash *chainhash.Hash) (*wire.MsgTx, *chainhash.Hash, error) {
+	blockRegion, err := s.txIndex.TxBlockRegion(hash)
+	if err != nil {
+		return nil, nil, err
+	}
+	if blockRegion == nil {
+		return nil, nil, errExplorerNotFound
+	}
+	var txBytes []byte
+	err = s.db.View(func(dbTx database.Tx) error {
+		var err error
+		txBytes, err = dbTx.FetchBlockRegion(blockRegion)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	var msgTx wire.MsgTx
+	if err := msgTx.Deserialize(bytes.NewReader(txBytes)); err != nil {
+		return nil, nil, err
+	}
+	return &msgTx, blockRegion.Hash, nil
+}
+
 // OutboundGroupCount returns the number of peers connected to the given
 // outbound group key.
 func (s *server) OutboundGroupCount(key string) int {
@@ -2260,7 +2589,8 @@ out:
 			// yet. We periodically resubmit them until they have.
 			for iv, data := range pendingInvs {
 				ivCopy := iv
-				s.RelayInventory(&ivCopy, data)
+				s.relayInv <- relayMsg{invVect: &ivCopy, data: data,
+					local: true}
 			}
 
 			// Process at a random time up to 30mins (in seconds)
@@ -2300,6 +2630,11 @@ func (s *server) Start() {
 	// Server startup time. Used for the uptime command for uptime calculation.
 	s.startupTime = time.Now().Unix()
 
+	// Check the free space before any block is accepted.
+	if s.diskMonitor != nil {
+		s.diskMonitor.Start()
+	}
+
 	// Start the peer handler which in turn starts the address and block
 	// managers.
 	s.wg.Add(1)
@@ -2324,6 +2659,22 @@ func (s *server) Start() {
 	if cfg.Generate {
 		s.cpuMiner.Start()
 	}
+
+	if s.webhooks != nil {
+		s.webhooks.Start()
+	}
+
+	if s.coldWatch != nil {
+		s.coldWatch.Start()
+	}
+
+	if s.partitionMonitor != nil {
+		s.partitionMonitor.Start()
+	}
+
+	if s.explorer != nil {
+		s.explorer.Start()
+	}
 }
 
 // Stop gracefully shuts down the server by stopping and disconnecting all
@@ -2345,6 +2696,33 @@ func (s *server) Stop() error {
 		s.rpcServer.Stop()
 	}
 
+	if s.explorer != nil {
+		s.explorer.Stop()
+	}
+
+	if s.partitionMonitor != nil {
+		s.partitionMonitor.Stop()
+	}
+
+	if s.diskMonitor != nil {
+		s.diskMonitor.Stop()
+	}
+
+	if s.coldWatch != nil {
+		s.coldWatch.Stop()
+	}
+
+	if s.hooks != nil {
+		s.hooks.Close()
+	}
+
+	if s.webhooks != nil {
+		s.webhooks.Stop()
+	}
+
+	// Close the message capture files.
+	s.msgCapture.Stop()
+
 	// Save fee estimator state in the database.
 	s.db.Update(func(tx database.Tx) error {
 		metadata := tx.Metadata()
@@ -2406,11 +2784,13 @@ func (s *server) ScheduleShutdown(duration time.Duration) {
 // parseListeners determines whether each listen address is IPv4 and IPv6 and
 // returns a slice of appropriate net.Addrs to listen on with TCP. It also
 // properly detects addresses which apply to "all interfaces" and adds the
-// address as both IPv4 and IPv6.
-func parseListeners(addrs []string) ([]net.Addr, error) {
+// address as both IPv4 and IPv6, and resolves the names of network interfaces
+// to all their addresses.  The ipv4 and ipv6 flags select the address families
+// of the wildcard and interface addresses, IP addresses are always listened on.
+func parseListeners(addrs []string, ipv4, ipv6 bool) ([]net.Addr, error) {
 	netAddrs := make([]net.Addr, 0, len(addrs)*2)
 	for _, addr := range addrs {
-		host, _, err := net.SplitHostPort(addr)
+		host, port, err := net.SplitHostPort(addr)
 		if err != nil {
 			// Shouldn't happen due to already being normalized.
 			return nil, err
@@ -2418,8 +2798,12 @@ func parseListeners(addrs []string) ([]net.Addr, error) {
 
 		// Empty host or host of * on plan9 is both IPv4 and IPv6.
 		if host == "" || (host == "*" && runtime.GOOS == "plan9") {
-			netAddrs = append(netAddrs, simpleAddr{net: "tcp4", addr: addr})
-			netAddrs = append(netAddrs, simpleAddr{net: "tcp6", addr: addr})
+			if ipv4 {
+				netAddrs = append(netAddrs, simpleAddr{net: "tcp4", addr: addr})
+			}
+			if ipv6 {
+				netAddrs = append(netAddrs, simpleAddr{net: "tcp6", addr: addr})
+			}
 			continue
 		}
 
@@ -2430,10 +2814,16 @@ func parseListeners(addrs []string) ([]net.Addr, error) {
 			host = host[:zoneIndex]
 		}
 
-		// Parse the IP.
+		// Parse the IP, or listen on all the addresses of the network
+		// interface it names otherwise.
 		ip := net.ParseIP(host)
 		if ip == nil {
-			return nil, fmt.Errorf("'%s' is not a valid IP address", host)
+			ifaceAddrs, err := interfaceListenAddrs(host, port, ipv4, ipv6)
+			if err != nil {
+				return nil, err
+			}
+			netAddrs = append(netAddrs, ifaceAddrs...)
+			continue
 		}
 
 		// To4 returns nil when the IP is not an IPv4 address, so use
@@ -2447,6 +2837,49 @@ func parseListeners(addrs []string) ([]net.Addr, error) {
 	return netAddrs, nil
 }
 
+// interfaceListenAddrs returns the addresses to listen on with TCP at the passed
+// port for the addresses of the named network interface in the selected address
+// families.  IPv6 link-local addresses are scoped to the interface.
+func interfaceListenAddrs(name, port string, ipv4, ipv6 bool) ([]net.Addr, error) {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return nil, fmt.Errorf("'%s' is not a valid IP address or "+
+			"network interface", name)
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, err
+	}
+
+	var netAddrs []net.Addr
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok {
+			continue
+		}
+		host := ipNet.IP.String()
+		switch {
+		case ipNet.IP.To4() != nil:
+			if ipv4 {
+				netAddrs = append(netAddrs, simpleAddr{net: "tcp4",
+					addr: net.JoinHostPort(host, port)})
+			}
+
+		case ipv6:
+			if ipNet.IP.IsLinkLocalUnicast() {
+				host += "%" + iface.Name
+			}
+			netAddrs = append(netAddrs, simpleAddr{net: "tcp6",
+				addr: net.JoinHostPort(host, port)})
+		}
+	}
+	if len(netAddrs) == 0 {
+		srvrLog.Warnf("Network interface %s has no address to listen on",
+			name)
+	}
+	return netAddrs, nil
+}
+
 func (s *server) upnpUpdateThread() {
 	// Go off immediately to prevent code duplication, thereafter we renew
 	// lease every 15 minutes.
@@ -2501,38 +2934,60 @@ out:
 	s.wg.Done()
 }
 
-// setupRPCListeners returns a slice of listeners that are configured for use
-// with the RPC server depending on the configuration settings for listen
-// addresses and TLS.
-func setupRPCListeners() ([]net.Listener, error) {
-	// Setup TLS if not disabled.
-	listenFunc := net.Listen
-	if !cfg.DisableTLS {
-		// Generate the TLS cert and key file if both don't already
-		// exist.
-		if !fileExists(cfg.RPCKey) && !fileExists(cfg.RPCCert) {
-			err := genCertPair(cfg.RPCCert, cfg.RPCKey)
-			if err != nil {
-				return nil, err
-			}
-		}
-		keypair, err := tls.LoadX509KeyPair(cfg.RPCCert, cfg.RPCKey)
+// setupRPCTLS returns the TLS configuration of the RPC listeners, the
+// authorities of the client certificates, which are nil when disabled, and
+// the manager of the ACME certificate, which is nil when certificates aren't
+// obtained from an ACME certificate authority.
+func setupRPCTLS() (*tls.Config, *rpcClientCAs, *rpcACMEManager, error) {
+	// Generate the TLS cert and key file if both don't already exist.
+	// ACME certificates replace it once obtained.
+	if !fileExists(cfg.RPCKey) && !fileExists(cfg.RPCCert) {
+		err := genCertPair(cfg.RPCCert, cfg.RPCKey)
 		if err != nil {
-			return nil, err
+			return nil, nil, nil, err
 		}
+	}
+	keyPair, err := loadRPCKeyPair(cfg.RPCCert, cfg.RPCKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
 
-		tlsConfig := tls.Config{
-			Certificates: []tls.Certificate{keypair},
-			MinVersion:   tls.VersionTLS12,
+	var acmeManager *rpcACMEManager
+	if len(cfg.RPCACMEDomains) > 0 {
+		accountKeyFile := filepath.Join(filepath.Dir(cfg.RPCKey),
+			acmeAccountKeyFilename)
+		acmeManager, err = newRPCACMEManager(cfg.RPCACMEDirectory,
+			cfg.RPCACMEEmail, cfg.RPCACMEDomains, accountKeyFile,
+			keyPair)
+		if err != nil {
+			return nil, nil, nil, err
 		}
+	}
 
+	clientCAs, err := loadRPCClientCAs(cfg.RPCClientCA, cfg.RPCLimitClientCA)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("unable to load the RPC client "+
+			"certificate authorities: %v", err)
+	}
+
+	tlsConfig := rpcTLSConfig(keyPair, clientCAs, cfg.RPCRequireClientCert)
+	return tlsConfig, clientCAs, acmeManager, nil
+}
+
+// setupRPCListeners returns a slice of listeners that are configured for use
+// with the RPC server depending on the configuration settings for listen
+// addresses and the passed TLS configuration, which is nil when TLS is
+// disabled.
+func setupRPCListeners(tlsConfig *tls.Config) ([]net.Listener, error) {
+	listenFunc := net.Listen
+	if tlsConfig != nil {
 		// Change the standard net.Listen function to the tls one.
 		listenFunc = func(net string, laddr string) (net.Listener, error) {
-			return tls.Listen(net, laddr, &tlsConfig)
+			return tls.Listen(net, laddr, tlsConfig)
 		}
 	}
 
-	netAddrs, err := parseListeners(cfg.RPCListeners)
+	netAddrs, err := parseListeners(cfg.RPCListeners, true, true)
 	if err != nil {
 		return nil, err
 	}
@@ -2550,6 +3005,37 @@ func setupRPCListeners() ([]net.Listener, error) {
 	return listeners, nil
 }
 
+// setupExplorerListeners returns the listeners the block explorer is served
+// on.
+func setupExplorerListeners() ([]net.Listener, error) {
+	netAddrs, err := parseListeners(cfg.ExplorerListeners, true, true)
+	if err != nil {
+		return nil, err
+	}
+
+	listeners := make([]net.Listener, 0, len(netAddrs))
+	for _, addr := range netAddrs {
+		listener, err := net.Listen(addr.Network(), addr.String())
+		if err != nil {
+			explLog.Warnf("Can't listen on %s: %v", addr, err)
+			continue
+		}
+		listeners = append(listeners, listener)
+	}
+
+	return listeners, nil
+}
+
+// newIndexManager returns the manager of the passed indexes.  The database of a
+// replica is read-only, so its indexes are only checked to be caught up rather
+// than created and caught up.
+func newIndexManager(db database.DB, indexes []indexers.Indexer) *indexers.Manager {
+	if cfg.Replica {
+		return indexers.NewReadOnlyManager(db, indexes)
+	}
+	return indexers.NewManager(db, indexes)
+}
+
 // newServer returns a new pktd server configured to listen on addr for the
 // bitcoin network type specified by chainParams.  Use start to begin accepting
 // connections from peers.
@@ -2564,14 +3050,19 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 	if cfg.NoCFilters {
 		services &^= wire.SFNodeCF
 	}
+	if cfg.Utreexo {
+		services |= wire.SFNodeUtreexo
+	}
 
 	amgr := addrmgr.New(cfg.DataDir, pktdLookup)
 
 	var listeners []net.Listener
 	var nat NAT
+	var externalNets map[string]struct{}
 	if !cfg.DisableListen {
 		var err error
-		listeners, nat, err = initListeners(amgr, listenAddrs, services)
+		listeners, nat, externalNets, err = initListeners(amgr,
+			listenAddrs, services)
 		if err != nil {
 			return nil, err
 		}
@@ -2600,15 +3091,28 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 		modifyRebroadcastInv: make(chan interface{}),
 		peerHeightsUpdate:    make(chan updatePeerHeightsMsg),
 		nat:                  nat,
+		externalNets:         externalNets,
 		db:                   db,
-		timeSource:           blockchain.NewMedianTime(),
+		timeSource:           blockchain.NewMedianTimeWithMaxAdjustment(cfg.MaxTimeAdjustment),
 		services:             services,
 		sigCache:             txscript.NewSigCache(cfg.SigCacheMaxSize),
 		hashCache:            txscript.NewHashCache(cfg.SigCacheMaxSize),
+		servedBlocks:         newServedBlockCache(int(cfg.ServedBlockCache)),
 		cfCheckptCaches:      make(map[wire.FilterType][]cfHeaderKV),
 		agentBlacklist:       agentBlacklist,
 		agentWhitelist:       agentWhitelist,
 	}
+	if _, err := rand.Read(s.netGroupKey[:]); err != nil {
+		return nil, err
+	}
+
+	// Capture the peer messages right away if requested.
+	s.msgCapture = newMsgCapture(cfg.MsgCaptureDir, cfg.msgCaptureRedact)
+	if cfg.MsgCapture {
+		if err := s.msgCapture.Start(nil); err != nil {
+			return nil, err
+		}
+	}
 
 	// Create the transaction and address indexes if needed.
 	//
@@ -2628,7 +3132,11 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 			indxLog.Info("Transaction index is enabled")
 		}
 
-		s.txIndex = indexers.NewTxIndex(db)
+		if cfg.TxIndexStart > 0 {
+			indxLog.Infof("Transaction index only covers blocks "+
+				"from height %d", cfg.TxIndexStart)
+		}
+		s.txIndex = indexers.NewTxIndexFromHeight(db, cfg.TxIndexStart)
 		indexes = append(indexes, s.txIndex)
 	}
 	if cfg.AddrIndex {
@@ -2641,11 +3149,27 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 		s.cfIndex = indexers.NewCfIndex(db, chainParams)
 		indexes = append(indexes, s.cfIndex)
 	}
+	if cfg.UtxoStatsIndex {
+		indxLog.Info("Utxo statistics index is enabled")
+		s.utxoStatsIndex = indexers.NewUtxoStatsIndex(db,
+			cfg.UtxoStatsInterval)
+		indexes = append(indexes, s.utxoStatsIndex)
+	}
+	if cfg.FeeStatsIndex {
+		indxLog.Info("Fee statistics index is enabled")
+		s.feeStatsIndex = indexers.NewFeeStatsIndex(db)
+		indexes = append(indexes, s.feeStatsIndex)
+	}
+	if cfg.Utreexo {
+		indxLog.Info("Utreexo accumulator is enabled")
+		s.utreexoIndex = indexers.NewUtreexoIndex(db)
+		indexes = append(indexes, s.utreexoIndex)
+	}
 
 	// Create an index manager if any of the optional indexes are enabled.
 	var indexManager blockchain.IndexManager
 	if len(indexes) > 0 {
-		indexManager = indexers.NewManager(db, indexes)
+		indexManager = newIndexManager(db, indexes)
 	}
 
 	// Merge given checkpoints with the default ones unless they are disabled.
@@ -2654,21 +3178,44 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 		checkpoints = mergeCheckpoints(s.chainParams.Checkpoints, cfg.addCheckpoints)
 	}
 
-	// Create a new block chain instance with the appropriate configuration.
+	// Record the blocks processed by the chain if requested.
 	var err error
+	var blockRecorder blockchain.BlockRecorder
+	if cfg.RecordConsensus != "" {
+		s.consensusRecorder, err = newConsensusRecorder(
+			cfg.RecordConsensus, s.chainParams.Net)
+		if err != nil {
+			return nil, err
+		}
+		blockRecorder = s.consensusRecorder
+		srvrLog.Infof("Recording consensus inputs to %s",
+			cfg.RecordConsensus)
+	}
+
+	// Create a new block chain instance with the appropriate configuration.
 	s.chain, err = blockchain.New(&blockchain.Config{
-		DB:           s.db,
-		Interrupt:    interrupt,
-		ChainParams:  s.chainParams,
-		Checkpoints:  checkpoints,
-		TimeSource:   s.timeSource,
-		SigCache:     s.sigCache,
-		IndexManager: indexManager,
-		HashCache:    s.hashCache,
+		DB:            s.db,
+		Interrupt:     interrupt,
+		ChainParams:   s.chainParams,
+		Checkpoints:   checkpoints,
+		TimeSource:    s.timeSource,
+		SigCache:      s.sigCache,
+		IndexManager:  indexManager,
+		HashCache:     s.hashCache,
+		BlockRecorder: blockRecorder,
+		ReadOnly:      cfg.Replica,
 	})
 	if err != nil {
+		if s.consensusRecorder != nil {
+			s.consensusRecorder.Close()
+		}
 		return nil, err
 	}
+	if s.consensusRecorder != nil && s.chain.BestSnapshot().Height > 0 {
+		srvrLog.Warnf("Recording consensus inputs on top of an existing " +
+			"chain, the recording can only be replayed from the " +
+			"genesis block")
+	}
 
 	// Search for a FeeEstimator state in the database. If none can be found
 	// or if it cannot be loaded, create a new one.
@@ -2711,6 +3258,8 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 			MinRelayTxFee:        cfg.minRelayTxFee,
 			MaxTxVersion:         2,
 			RejectReplacement:    cfg.RejectReplacement,
+			RejectDataCarrier:    cfg.NoDataCarrier,
+			MaxDataCarrierSize:   cfg.DataCarrierSize,
 		},
 		ChainParams:    chainParams,
 		FetchUtxoView:  s.chain.FetchUtxoView,
@@ -2720,13 +3269,84 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 			return s.chain.CalcSequenceLock(tx, view, true)
 		},
 		IsDeploymentActive: s.chain.IsDeploymentActive,
-		SigCache:           s.sigCache,
-		HashCache:          s.hashCache,
-		AddrIndex:          s.addrIndex,
-		FeeEstimator:       s.feeEstimator,
+		ScriptFlags: func() (txscript.ScriptFlags, error) {
+			version, err := s.chain.CalcNextBlockVersion()
+			if err != nil {
+				return 0, err
+			}
+			return s.chain.ConsensusScriptFlags(&wire.BlockHeader{
+				Version:   version,
+				PrevBlock: s.chain.BestSnapshot().Hash,
+				Timestamp: time.Now(),
+			})
+		},
+		SigCache:     s.sigCache,
+		HashCache:    s.hashCache,
+		AddrIndex:    s.addrIndex,
+		FeeEstimator: s.feeEstimator,
+		MaxTxEvents:  cfg.MempoolEvents,
+	}
+	if len(cfg.Hooks) > 0 {
+		s.hooks, err = hooks.New(&hooks.Config{
+			Hooks:   cfg.Hooks,
+			Timeout: cfg.HookTimeout,
+		})
+		if err != nil {
+			return nil, err
+		}
+		txC.PolicyHook = s.hooks.AcceptTx
+		s.chain.Subscribe(s.hooks.HandleBlockchainNotification)
 	}
 	s.txMemPool = mempool.New(&txC)
 
+	if len(cfg.Webhooks) > 0 {
+		s.webhooks, err = newWebhookManager(&webhookConfig{
+			Hooks:             cfg.Webhooks,
+			Secret:            cfg.WebhookSecret,
+			Watch:             cfg.WebhookWatch,
+			Confirmations:     cfg.WebhookConfs,
+			ChainParams:       chainParams,
+			FetchSpendJournal: s.chain.FetchSpendJournal,
+			FetchPrevOut:      s.fetchPrevOut,
+		})
+		if err != nil {
+			return nil, err
+		}
+		s.chain.Subscribe(s.webhooks.handleBlockchainNotification)
+	}
+
+	if len(cfg.ColdAddresses) > 0 {
+		coldCfg := &coldWatchConfig{
+			Addresses:         cfg.ColdAddresses,
+			ChainParams:       chainParams,
+			FetchSpendJournal: s.chain.FetchSpendJournal,
+			FetchPrevOut:      s.fetchPrevOut,
+		}
+		if s.webhooks != nil {
+			coldCfg.Notify = s.webhooks.NotifyColdSpend
+		}
+		s.coldWatch, err = newColdWatcher(coldCfg)
+		if err != nil {
+			return nil, err
+		}
+		s.chain.Subscribe(s.coldWatch.handleBlockchainNotification)
+	}
+
+	if cfg.DepositBundle != "" {
+		s.deposits, err = newDepositTracker(&depositTrackerConfig{
+			BundleFile:    cfg.DepositBundle,
+			StateFile:     filepath.Join(cfg.DataDir, depositsFilename),
+			Confirmations: cfg.DepositConfs,
+			Chain:         s.chain,
+			ChainParams:   chainParams,
+			Webhooks:      s.webhooks,
+		})
+		if err != nil {
+			return nil, err
+		}
+		s.chain.Subscribe(s.deposits.handleBlockchainNotification)
+	}
+
 	s.syncManager, err = netsync.New(&netsync.Config{
 		PeerNotifier:       &s,
 		Chain:              s.chain,
@@ -2734,12 +3354,67 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 		ChainParams:        s.chainParams,
 		DisableCheckpoints: cfg.DisableCheckpoints,
 		MaxPeers:           cfg.MaxPeers,
+		HeaderProbePeers:   cfg.HeaderProbePeers,
+		MaxRejectedTxns:    cfg.MaxRejectedTxs,
 		FeeEstimator:       s.feeEstimator,
 	})
 	if err != nil {
 		return nil, err
 	}
 
+	// Watch the chains announced by the peers for network partitions
+	// unless disabled.
+	if cfg.PartitionAlertTime > 0 {
+		monitorCfg := &partitionMonitorConfig{
+			Chain:     s.chain,
+			Peers:     s.partitionPeers,
+			IsCurrent: s.syncManager.IsCurrent,
+			AlertTime: cfg.PartitionAlertTime,
+			ForkRatio: cfg.PartitionForkRatio,
+		}
+		if s.webhooks != nil {
+			monitorCfg.Notify = s.webhooks.NotifyPartitionAlert
+		}
+		s.partitionMonitor = newPartitionMonitor(monitorCfg)
+	}
+
+	// Watch the free space on the data directory unless disabled.
+	if cfg.DiskWarnSpace > 0 || cfg.DiskSafeModeSpace > 0 {
+		diskCfg := &diskMonitorConfig{
+			Dir:           cfg.DataDir,
+			WarnSpace:     uint64(cfg.DiskWarnSpace) << 20,
+			SafeModeSpace: uint64(cfg.DiskSafeModeSpace) << 20,
+			DiskSpace:     diskSpace,
+			SetSafeMode:   s.chain.SetSafeMode,
+		}
+		if s.webhooks != nil {
+			diskCfg.Notify = s.webhooks.NotifyDiskSpace
+		}
+		s.diskMonitor = newDiskMonitor(diskCfg)
+	}
+
+	// Serve the block explorer on the configured listeners.
+	if len(cfg.ExplorerListeners) > 0 {
+		listeners, err := setupExplorerListeners()
+		if err != nil {
+			return nil, err
+		}
+		explorerCfg := &explorerConfig{
+			Listeners:     listeners,
+			ChainParams:   chainParams,
+			BestSnapshot:  s.chain.BestSnapshot,
+			BlockByHeight: s.chain.BlockByHeight,
+			BlockByHash:   s.chain.BlockByHash,
+			MempoolTxs:    s.txMemPool.TxDescs,
+			Peers:         s.explorerPeers,
+			ListPeers:     cfg.ExplorerPeers,
+		}
+		if s.txIndex != nil {
+			explorerCfg.FetchTx = s.explorerFetchTx
+		}
+		s.explorer = newExplorer(explorerCfg)
+	}
+
 	// Create the mining policy and block template generator based on the
 	// configuration options.
 	//
@@ -2752,6 +3427,10 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 		BlockMaxSize:      cfg.BlockMaxSize,
 		BlockPrioritySize: cfg.BlockPrioritySize,
 		TxMinFreeFee:      cfg.minRelayTxFee,
+		BlockMaxDataCarrierWeight: uint32(float64(cfg.BlockMaxWeight) *
+			cfg.DataCarrierShare / 100),
+		BlockMaxSigOpCost:   cfg.BlockMaxSigOpCost,
+		BlockReservedWeight: cfg.BlockReservedWeight,
 	}
 	blockTemplateGenerator := mining.NewBlkTmplGenerator(&policy,
 		s.chainParams, s.txMemPool, s.chain, s.timeSource,
@@ -2850,30 +3529,63 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 	if !cfg.DisableRPC {
 		// Setup listeners for the configured RPC listen addresses and
 		// TLS settings.
-		rpcListeners, err := setupRPCListeners()
+		var tlsConfig *tls.Config
+		var clientCAs *rpcClientCAs
+		var acmeManager *rpcACMEManager
+		if !cfg.DisableTLS && len(cfg.RPCListeners) != 0 {
+			tlsConfig, clientCAs, acmeManager, err = setupRPCTLS()
+			if err != nil {
+				return nil, err
+			}
+		}
+		rpcListeners, err := setupRPCListeners(tlsConfig)
 		if err != nil {
 			return nil, err
 		}
+
+		// Add the listeners of the Unix domain sockets and named
+		// pipes, whose permissions authenticate their connections.
+		localListeners, err := setupRPCLocalListeners()
+		if err != nil {
+			for _, l := range rpcListeners {
+				l.Close()
+			}
+			return nil, err
+		}
+		rpcListeners = append(rpcListeners, localListeners...)
 		if len(rpcListeners) == 0 {
 			return nil, errors.New("RPCS: No valid listen address")
 		}
 
 		s.rpcServer, err = newRPCServer(&rpcserverConfig{
-			Listeners:    rpcListeners,
-			StartupTime:  s.startupTime,
-			ConnMgr:      &rpcConnManager{&s},
-			SyncMgr:      &rpcSyncMgr{&s, s.syncManager},
-			TimeSource:   s.timeSource,
-			Chain:        s.chain,
-			ChainParams:  chainParams,
-			DB:           db,
-			TxMemPool:    s.txMemPool,
-			Generator:    blockTemplateGenerator,
-			CPUMiner:     s.cpuMiner,
-			TxIndex:      s.txIndex,
-			AddrIndex:    s.addrIndex,
-			CfIndex:      s.cfIndex,
-			FeeEstimator: s.feeEstimator,
+			Listeners:      rpcListeners,
+			StartupTime:    s.startupTime,
+			ConnMgr:        &rpcConnManager{&s},
+			SyncMgr:        &rpcSyncMgr{&s, s.syncManager},
+			TimeSource:     s.timeSource,
+			Chain:          s.chain,
+			ChainParams:    chainParams,
+			DB:             db,
+			TxMemPool:      s.txMemPool,
+			Generator:      blockTemplateGenerator,
+			CPUMiner:       s.cpuMiner,
+			TxIndex:        s.txIndex,
+			AddrIndex:      s.addrIndex,
+			CfIndex:        s.cfIndex,
+			UtxoStatsIndex: s.utxoStatsIndex,
+			FeeStatsIndex:  s.feeStatsIndex,
+			FeeEstimator:   s.feeEstimator,
+			SigCache:       s.sigCache,
+			HashCache:      s.hashCache,
+			ServedBlocks:   s.servedBlocks,
+			Webhooks:       s.webhooks,
+			MsgCapture:     s.msgCapture,
+			Partitions:     s.partitionMonitor,
+			Deposits:       s.deposits,
+			ColdWatch:      s.coldWatch,
+			DiskMonitor:    s.diskMonitor,
+			ClientCAs:      clientCAs,
+			ACME:           acmeManager,
 		})
 		if err != nil {
 			return nil, err
@@ -2890,32 +3602,38 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 }
 
 // initListeners initializes the configured net listeners and adds any bound
-// addresses to the address manager. Returns the listeners and a NAT interface,
-// which is non-nil if UPnP is in use.
-func initListeners(amgr *addrmgr.AddrManager, listenAddrs []string, services wire.ServiceFlag) ([]net.Listener, NAT, error) {
-	// Listen for TCP connections at the configured addresses
-	netAddrs, err := parseListeners(listenAddrs)
-	if err != nil {
-		return nil, nil, err
-	}
+// addresses to the address manager. Returns the listeners, a NAT interface,
+// which is non-nil if UPnP is in use, and the networks of the external IP
+// addresses.
+func initListeners(amgr *addrmgr.AddrManager, listenAddrs []string,
+	services wire.ServiceFlag) ([]net.Listener, NAT, map[string]struct{}, error) {
 
-	listeners := make([]net.Listener, 0, len(netAddrs))
-	for _, addr := range netAddrs {
-		listener, err := net.Listen(addr.Network(), addr.String())
+	// Listen for TCP connections at the configured addresses, granting the
+	// permissions of the whitebind listeners to their peers.
+	var listeners []net.Listener
+	for _, listenAddr := range listenAddrs {
+		l, err := listenPeers(listenAddr, 0, false)
 		if err != nil {
-			srvrLog.Warnf("Can't listen on %s: %v", addr, err)
-			continue
+			return nil, nil, nil, err
 		}
-		listeners = append(listeners, listener)
+		listeners = append(listeners, l...)
+	}
+	for _, wb := range cfg.whitebinds {
+		l, err := listenPeers(wb.listenAddr, wb.perms, false)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		listeners = append(listeners, l...)
 	}
 
 	var nat NAT
+	externalNets := make(map[string]struct{})
 	if len(cfg.ExternalIPs) != 0 {
 		defaultPort, err := strconv.ParseUint(activeNetParams.DefaultPort, 10, 16)
 		if err != nil {
 			srvrLog.Errorf("Can not parse default port %s for active chain: %v",
 				activeNetParams.DefaultPort, err)
-			return nil, nil, err
+			return nil, nil, nil, err
 		}
 
 		for _, sip := range cfg.ExternalIPs {
@@ -2942,29 +3660,197 @@ func initListeners(amgr *addrmgr.AddrManager, listenAddrs []string, services wir
 			err = amgr.AddLocalAddress(na, addrmgr.ManualPrio)
 			if err != nil {
 				amgrLog.Warnf("Skipping specified external IP: %v", err)
+				continue
 			}
+			externalNets[localAddrNetwork(na)] = struct{}{}
 		}
-	} else {
-		if cfg.Upnp {
-			var err error
-			nat, err = Discover()
-			if err != nil {
-				srvrLog.Warnf("Can't discover upnp: %v", err)
-			}
-			// nil nat here is fine, just means no upnp on network.
+	} else if cfg.Upnp {
+		var err error
+		nat, err = Discover()
+		if err != nil {
+			srvrLog.Warnf("Can't discover upnp: %v", err)
 		}
+		// nil nat here is fine, just means no upnp on network.
+	}
 
-		// Add bound addresses to address manager to be advertised to peers.
-		for _, listener := range listeners {
-			addr := listener.Addr().String()
-			err := addLocalAddress(amgr, addr, services)
-			if err != nil {
-				amgrLog.Warnf("Skipping bound address %s: %v", addr, err)
+	// Add bound addresses to address manager to be advertised to peers,
+	// unless external IP addresses were specified for their network.
+	for _, listener := range listeners {
+		advertiseListener(amgr, listener, externalNets, services)
+	}
+
+	return listeners, nat, externalNets, nil
+}
+
+// listenPeers listens for peer connections at the passed listen address, which
+// may be a wildcard or name a network interface, skipping the addresses which
+// can't be listened on.  The peers connecting are granted the passed
+// permissions.
+func listenPeers(listenAddr string, perms peerPermissions,
+	runtime bool) ([]net.Listener, error) {
+
+	netAddrs, err := parseListeners([]string{listenAddr}, !cfg.NoListenIPv4,
+		!cfg.NoListenIPv6)
+	if err != nil {
+		return nil, err
+	}
+
+	listeners := make([]net.Listener, 0, len(netAddrs))
+	for _, addr := range netAddrs {
+		listener, err := net.Listen(addr.Network(), addr.String())
+		if err != nil {
+			srvrLog.Warnf("Can't listen on %s: %v", addr, err)
+			continue
+		}
+		listeners = append(listeners, &p2pListener{
+			Listener:   listener,
+			listenAddr: listenAddr,
+			perms:      perms,
+			runtime:    runtime,
+		})
+	}
+	return listeners, nil
+}
+
+// localAddrNetwork returns the network an advertised address is reachable
+// from: ipv4, ipv6 or onion.
+func localAddrNetwork(na *wire.NetAddress) string {
+	switch {
+	case addrmgr.IsIPv4(na):
+		return "ipv4"
+	case addrmgr.IsOnionCatTor(na):
+		return "onion"
+	default:
+		return "ipv6"
+	}
+}
+
+// advertiseListener adds the bound addresses of the passed listener to the
+// address manager, unless external IP addresses were specified for their
+// network.
+func advertiseListener(amgr *addrmgr.AddrManager, listener net.Listener,
+	externalNets map[string]struct{}, services wire.ServiceFlag) {
+
+	tcpAddr, ok := listener.Addr().(*net.TCPAddr)
+	if !ok {
+		return
+	}
+	na := wire.NewNetAddressIPPort(tcpAddr.IP, uint16(tcpAddr.Port), services)
+	if _, ok := externalNets[localAddrNetwork(na)]; ok {
+		return
+	}
+
+	// The zone of link-local addresses is left out, they are not
+	// advertised anyway.
+	addr := net.JoinHostPort(tcpAddr.IP.String(), strconv.Itoa(tcpAddr.Port))
+	err := addLocalAddress(amgr, addr, services)
+	if err != nil {
+		amgrLog.Warnf("Skipping bound address %s: %v", addr, err)
+	}
+}
+
+// listenerBinds returns whether the passed listener is bound to the passed
+// local address, directly or through a wildcard address of its family.
+func listenerBinds(listener net.Listener, na *wire.NetAddress) bool {
+	tcpAddr, ok := listener.Addr().(*net.TCPAddr)
+	if !ok || tcpAddr.Port != int(na.Port) {
+		return false
+	}
+	if tcpAddr.IP.IsUnspecified() {
+		return (tcpAddr.IP.To4() == nil) == (na.IP.To4() == nil)
+	}
+	return tcpAddr.IP.Equal(na.IP)
+}
+
+// AddListener starts listening for peer connections at the passed listen
+// address, which may be a wildcard or name a network interface like the
+// --listen addresses, and advertises the bound addresses.  The address may be
+// prefixed with the permissions granted to the peers like the --whitebind
+// addresses.
+func (s *server) AddListener(listenAddr string) ([]net.Listener, error) {
+	if cfg.DisableListen {
+		return nil, errors.New("listening for peers is disabled")
+	}
+	perms, listenAddr, err := parsePeerPermissions(listenAddr, 0)
+	if err != nil {
+		return nil, err
+	}
+	listenAddr = normalizeAddress(listenAddr, s.chainParams.DefaultPort)
+
+	s.listenerMtx.Lock()
+	defer s.listenerMtx.Unlock()
+
+	for _, l := range s.connManager.Listeners() {
+		if l.(*p2pListener).listenAddr == listenAddr {
+			return nil, fmt.Errorf("already listening on %s", listenAddr)
+		}
+	}
+	listeners, err := listenPeers(listenAddr, perms, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(listeners) == 0 {
+		return nil, fmt.Errorf("can't listen on %s", listenAddr)
+	}
+	for i, listener := range listeners {
+		if err := s.connManager.AddListener(listener); err != nil {
+			for _, l := range listeners[i:] {
+				l.Close()
 			}
+			return nil, err
+		}
+		advertiseListener(s.addrManager, listener, s.externalNets,
+			s.services)
+	}
+	srvrLog.Infof("Added listener for %s", listenAddr)
+	return listeners, nil
+}
+
+// RemoveListener stops listening for peer connections at the passed listen
+// address or bound address, and stops advertising the addresses no remaining
+// listener is bound to.  Connected peers are left alone.
+func (s *server) RemoveListener(addr string) ([]net.Listener, error) {
+	listenAddr := normalizeAddress(addr, s.chainParams.DefaultPort)
+
+	s.listenerMtx.Lock()
+	defer s.listenerMtx.Unlock()
+
+	var removed []net.Listener
+	for _, l := range s.connManager.Listeners() {
+		bound := l.Addr().String()
+		if l.(*p2pListener).listenAddr != listenAddr && bound != addr &&
+			bound != listenAddr {
+
+			continue
 		}
+		if err := s.connManager.RemoveListener(bound); err != nil {
+			return removed, err
+		}
+		removed = append(removed, l)
+	}
+	if len(removed) == 0 {
+		return nil, fmt.Errorf("not listening on %s", addr)
 	}
 
-	return listeners, nat, nil
+	remaining := s.connManager.Listeners()
+	for _, la := range s.addrManager.LocalAddresses() {
+		if la.Score >= addrmgr.ManualPrio {
+			continue
+		}
+		na := la.NetAddress
+		unbound := false
+		for _, l := range removed {
+			unbound = unbound || listenerBinds(l, na)
+		}
+		for _, l := range remaining {
+			unbound = unbound && !listenerBinds(l, na)
+		}
+		if unbound {
+			s.addrManager.RemoveLocalAddress(na)
+		}
+	}
+	srvrLog.Infof("Removed listener for %s", addr)
+	return removed, nil
 }
 
 // addrStringToNetAddr takes an address in the form of 'host:port' and returns
@@ -3083,32 +3969,6 @@ func dynamicTickDuration(remaining time.Duration) time.Duration {
 	return time.Hour
 }
 
-// isWhitelisted returns whether the IP address is included in the whitelisted
-// networks and IPs.
-func isWhitelisted(addr net.Addr) bool {
-	if len(cfg.whitelists) == 0 {
-		return false
-	}
-
-	host, _, err := net.SplitHostPort(addr.String())
-	if err != nil {
-		srvrLog.Warnf("Unable to SplitHostPort on '%s': %v", addr, err)
-		return false
-	}
-	ip := net.ParseIP(host)
-	if ip == nil {
-		srvrLog.Warnf("Unable to parse IP '%s'", addr)
-		return false
-	}
-
-	for _, ipnet := range cfg.whitelists {
-		if ipnet.Contains(ip) {
-			return true
-		}
-	}
-	return false
-}
-
 // checkpointSorter implements sort.Interface to allow a slice of checkpoints to
 // be sorted.
 type checkpointSorter []chaincfg.Checkpoint
@@ -0,0 +1,26 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package signpolicy enforces the spending policy of a wallet before it signs a
+send, for custodial and treasury deployments where a stolen RPC password or
+a compromised operator must not be enough to empty the wallet.
+
+A policy is a list of rules, each applying to the sends above a threshold.
+The threshold of a rule with a window is a spending limit: it is compared to
+the total authorized within the window, the send included, rather than to the
+send alone.  A rule can deny the sends it applies to, require a second
+authentication factor, or hold them for a delay during which they can be
+cancelled.  When several rules apply, the send must satisfy all of them.
+
+The second factor is a time-based one-time password (RFC 6238) as generated by
+common authenticator apps, from a secret shared when the policy is set up.
+Once a second factor is configured, changing the policy requires it as well.
+
+The Engine keeps the sends held by the policy and the history of authorized
+sends the spending limits are computed from.  The wallet submits every send
+to it before signing, signs the sends it authorizes right away and releases
+the held sends once they are approved and their delay has elapsed.
+*/
+package signpolicy
@@ -0,0 +1,280 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package signpolicy
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkt-cash/btcutil"
+)
+
+var (
+	// ErrDenied is returned when a rule of the policy refuses a send.
+	ErrDenied = errors.New("send denied by the signer policy")
+
+	// ErrNoSecondFactor is returned when a second factor is required but
+	// none is configured.
+	ErrNoSecondFactor = errors.New("no second factor configured")
+
+	// ErrUnknownSend is returned for the ID of a send which is not held.
+	ErrUnknownSend = errors.New("unknown pending send")
+
+	// ErrNotReady is returned when releasing a send which still waits for
+	// its approval or the end of its delay.
+	ErrNotReady = errors.New("pending send is not ready")
+)
+
+// PendingSend is a send held by the policy.
+type PendingSend struct {
+	// ID identifies the send.
+	ID uint64
+
+	// Amount is the amount sent, excluding the fee.
+	Amount btcutil.Amount
+
+	// Created is the time the send was submitted.
+	Created time.Time
+
+	// NotBefore is the time the delay of the send ends, zero when it has
+	// none.
+	NotBefore time.Time
+
+	// SecondFactor is set when the send must be approved with a one-time
+	// password.
+	SecondFactor bool
+
+	// Approved is set once the send was approved.
+	Approved bool
+
+	// Rules are the names of the rules holding the send.
+	Rules []string
+}
+
+// Ready returns whether the send can be released at the passed time.
+func (p *PendingSend) Ready(now time.Time) bool {
+	return (!p.SecondFactor || p.Approved) && !now.Before(p.NotBefore)
+}
+
+// Engine enforces a policy on the sends of a wallet.
+//
+// It is safe for concurrent access.
+type Engine struct {
+	mtx     sync.Mutex
+	policy  Policy
+	totp    *TOTP
+	history []Spend
+	pending map[uint64]*PendingSend
+	nextID  uint64
+
+	// now returns the current time.  It is replaced by the tests.
+	now func() time.Time
+}
+
+// NewEngine returns an engine enforcing the passed policy, verifying the
+// second factor with totp, which may be nil when no rule requires it.
+func NewEngine(policy *Policy, totp *TOTP) (*Engine, error) {
+	if err := checkPolicy(policy, totp); err != nil {
+		return nil, err
+	}
+	return &Engine{
+		policy:  *policy,
+		totp:    totp,
+		pending: make(map[uint64]*PendingSend),
+		nextID:  1,
+		now:     time.Now,
+	}, nil
+}
+
+// checkPolicy validates the passed policy and ensures a second factor is
+// configured when it requires one.
+func checkPolicy(policy *Policy, totp *TOTP) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	if totp == nil && policy.NeedsSecondFactor() {
+		return ErrNoSecondFactor
+	}
+	return nil
+}
+
+// Policy returns the enforced policy and whether a second factor is
+// configured.
+func (e *Engine) Policy() (Policy, bool) {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+
+	rules := make([]Rule, len(e.policy.Rules))
+	copy(rules, e.policy.Rules)
+	return Policy{Rules: rules}, e.totp != nil
+}
+
+// SetPolicy replaces the enforced policy and second factor.  When a second
+// factor is configured, the change must be authorized with a one-time password
+// of the current one.  The sends already held keep their requirements.
+func (e *Engine) SetPolicy(policy *Policy, totp *TOTP, code string) error {
+	if err := checkPolicy(policy, totp); err != nil {
+		return err
+	}
+
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+
+	if e.totp != nil {
+		if err := e.totp.Verify(code, e.now()); err != nil {
+			return err
+		}
+	}
+	e.policy = *policy
+	e.totp = totp
+	return nil
+}
+
+// record adds an authorized send to the history and forgets the sends too old
+// to matter to the policy.
+//
+// This function MUST be called with the engine lock held.
+func (e *Engine) record(amount btcutil.Amount, now time.Time) {
+	since := now.Add(-e.policy.Window())
+	history := e.history[:0]
+	for _, spend := range e.history {
+		if spend.Time.After(since) {
+			history = append(history, spend)
+		}
+	}
+	e.history = append(history, Spend{Amount: amount, Time: now})
+}
+
+// Submit evaluates sending amount against the policy.  It returns nil when the
+// send is authorized and can be signed right away, and the held send
+// otherwise.  ErrDenied is returned when a rule refuses the send.
+//
+// An authorized send counts towards the spending limits from then on, so the
+// wallet must sign it or call Refund.
+func (e *Engine) Submit(amount btcutil.Amount) (*PendingSend, error) {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+
+	now := e.now()
+	d := e.policy.Evaluate(amount, e.history, now)
+	if d.Denied {
+		return nil, fmt.Errorf("%v (%v)", ErrDenied, d.Rules)
+	}
+	if !d.Held() {
+		e.record(amount, now)
+		return nil, nil
+	}
+
+	p := &PendingSend{
+		ID:           e.nextID,
+		Amount:       amount,
+		Created:      now,
+		SecondFactor: d.SecondFactor,
+		Rules:        d.Rules,
+	}
+	if d.Delay > 0 {
+		p.NotBefore = now.Add(d.Delay)
+	}
+	e.nextID++
+	e.pending[p.ID] = p
+	result := *p
+	return &result, nil
+}
+
+// Refund removes an authorized send which was not signed from the history.
+func (e *Engine) Refund(amount btcutil.Amount) {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+
+	for i := len(e.history) - 1; i >= 0; i-- {
+		if e.history[i].Amount == amount {
+			e.history = append(e.history[:i], e.history[i+1:]...)
+			return
+		}
+	}
+}
+
+// Approve approves the held send with the passed ID with a one-time password.
+func (e *Engine) Approve(id uint64, code string) (*PendingSend, error) {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+
+	p, ok := e.pending[id]
+	if !ok {
+		return nil, ErrUnknownSend
+	}
+	if p.SecondFactor && !p.Approved {
+		if e.totp == nil {
+			return nil, ErrNoSecondFactor
+		}
+		if err := e.totp.Verify(code, e.now()); err != nil {
+			return nil, err
+		}
+		p.Approved = true
+	}
+	result := *p
+	return &result, nil
+}
+
+// Cancel drops the held send with the passed ID.
+func (e *Engine) Cancel(id uint64) error {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+
+	if _, ok := e.pending[id]; !ok {
+		return ErrUnknownSend
+	}
+	delete(e.pending, id)
+	return nil
+}
+
+// Release authorizes the held send with the passed ID, which must be ready,
+// and stops holding it.  The wallet signs the send once it is released.
+func (e *Engine) Release(id uint64) error {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+
+	p, ok := e.pending[id]
+	if !ok {
+		return ErrUnknownSend
+	}
+	now := e.now()
+	if !p.Ready(now) {
+		return ErrNotReady
+	}
+	delete(e.pending, id)
+	e.record(p.Amount, now)
+	return nil
+}
+
+// Pending returns the held sends ordered by ID.
+func (e *Engine) Pending() []PendingSend {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+
+	sends := make([]PendingSend, 0, len(e.pending))
+	for _, p := range e.pending {
+		sends = append(sends, *p)
+	}
+	sort.Slice(sends, func(i, j int) bool {
+		return sends[i].ID < sends[j].ID
+	})
+	return sends
+}
+
+// Ready returns the IDs of the held sends which can be released, in order.
+func (e *Engine) Ready() []uint64 {
+	now := e.now()
+	var ids []uint64
+	for _, p := range e.Pending() {
+		if p.Ready(now) {
+			ids = append(ids, p.ID)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package signpolicy
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/pkt-cash/btcutil"
+)
+
+// Action is what a rule requires of the sends it applies to.
+type Action string
+
+const (
+	// ActionDeny refuses the sends.
+	ActionDeny Action = "deny"
+
+	// ActionSecondFactor holds the sends until they are approved with a
+	// one-time password.
+	ActionSecondFactor Action = "secondfactor"
+
+	// ActionDelay holds the sends for the delay of the rule, during which
+	// they can be cancelled.
+	ActionDelay Action = "delay"
+)
+
+// ErrInvalidPolicy is returned when a policy has an invalid rule.
+var ErrInvalidPolicy = errors.New("invalid signer policy")
+
+// Rule is a rule of a policy.
+type Rule struct {
+	// Name identifies the rule in the decisions.
+	Name string
+
+	// Threshold is the amount above which the rule applies.
+	Threshold btcutil.Amount
+
+	// Window makes the rule a spending limit when it is not zero.  The
+	// threshold is then compared to the total authorized within the
+	// window, including the send.
+	Window time.Duration
+
+	// Action is what the rule requires.
+	Action Action
+
+	// Delay is the time the sends are held for by a delay rule.
+	Delay time.Duration
+}
+
+// Policy is the spending policy of a wallet.  The zero value authorizes every
+// send.
+type Policy struct {
+	Rules []Rule
+}
+
+// Validate checks the rules of the policy have a known action, non negative
+// amounts and durations, and that delay rules have a delay.
+func (p *Policy) Validate() error {
+	for i := range p.Rules {
+		r := &p.Rules[i]
+		switch r.Action {
+		case ActionDeny, ActionSecondFactor:
+		case ActionDelay:
+			if r.Delay <= 0 {
+				return fmt.Errorf("%v: rule %q has no delay",
+					ErrInvalidPolicy, r.Name)
+			}
+		default:
+			return fmt.Errorf("%v: rule %q has unknown action %q",
+				ErrInvalidPolicy, r.Name, r.Action)
+		}
+		if r.Threshold < 0 || r.Window < 0 || r.Delay < 0 {
+			return fmt.Errorf("%v: rule %q has a negative value",
+				ErrInvalidPolicy, r.Name)
+		}
+	}
+	return nil
+}
+
+// NeedsSecondFactor returns whether a rule of the policy requires a second
+// factor.
+func (p *Policy) NeedsSecondFactor() bool {
+	for i := range p.Rules {
+		if p.Rules[i].Action == ActionSecondFactor {
+			return true
+		}
+	}
+	return false
+}
+
+// Spend is a send authorized by a policy.
+type Spend struct {
+	Amount btcutil.Amount
+	Time   time.Time
+}
+
+// Decision is the outcome of evaluating a send against a policy.
+type Decision struct {
+	// Denied is set when a rule refuses the send.
+	Denied bool
+
+	// SecondFactor is set when the send must be approved with a one-time
+	// password.
+	SecondFactor bool
+
+	// Delay is the longest delay of the rules holding the send.
+	Delay time.Duration
+
+	// Rules are the names of the rules applying to the send.
+	Rules []string
+}
+
+// Held returns whether the send can't be signed right away.
+func (d *Decision) Held() bool {
+	return d.Denied || d.SecondFactor || d.Delay > 0
+}
+
+// Evaluate returns the decision of the policy on sending amount at the passed
+// time, given the sends previously authorized.
+func (p *Policy) Evaluate(amount btcutil.Amount, history []Spend,
+	now time.Time) *Decision {
+
+	d := &Decision{}
+	for i := range p.Rules {
+		r := &p.Rules[i]
+		total := amount
+		if r.Window > 0 {
+			since := now.Add(-r.Window)
+			for _, spend := range history {
+				if spend.Time.After(since) {
+					total += spend.Amount
+				}
+			}
+		}
+		if total <= r.Threshold {
+			continue
+		}
+
+		d.Rules = append(d.Rules, r.Name)
+		switch r.Action {
+		case ActionDeny:
+			d.Denied = true
+		case ActionSecondFactor:
+			d.SecondFactor = true
+		case ActionDelay:
+			if r.Delay > d.Delay {
+				d.Delay = r.Delay
+			}
+		}
+	}
+	return d
+}
+
+// Window returns the longest window of the rules of the policy, the age of the
+// oldest sends which can matter to a decision.
+func (p *Policy) Window() time.Duration {
+	var window time.Duration
+	for i := range p.Rules {
+		if p.Rules[i].Window > window {
+			window = p.Rules[i].Window
+		}
+	}
+	return window
+}
@@ -0,0 +1,215 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package signpolicy
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+
+	"github.com/pkt-cash/btcutil"
+)
+
+// TestTOTP ensures the one-time passwords match the SHA1 test vectors of RFC
+// 6238, truncated to 6 digits, and are only accepted once.
+func TestTOTP(t *testing.T) {
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	totp, err := ParseSecret(secret)
+	if err != nil {
+		t.Fatalf("ParseSecret: %v", err)
+	}
+
+	tests := []struct {
+		time int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+	for _, test := range tests {
+		if code := totp.Code(time.Unix(test.time, 0)); code != test.code {
+			t.Errorf("Code(%d): got %s, want %s", test.time, code,
+				test.code)
+		}
+	}
+
+	// The password of the previous step is accepted once, and then no
+	// older password is.
+	now := time.Unix(1111111111, 0)
+	prev := totp.Code(now.Add(-totpStep * time.Second))
+	if err := totp.Verify(prev, now); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if err := totp.Verify(prev, now); err != ErrBadCode {
+		t.Fatalf("Verify replay: got %v, want %v", err, ErrBadCode)
+	}
+	if err := totp.Verify("000000", now); err != ErrBadCode {
+		t.Fatalf("Verify wrong code: got %v, want %v", err, ErrBadCode)
+	}
+	if err := totp.Verify(totp.Code(now), now); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+
+	if _, err := ParseSecret("JBSWY3DP"); err != ErrBadSecret {
+		t.Fatalf("ParseSecret short: got %v, want %v", err, ErrBadSecret)
+	}
+	if _, err := ParseSecret("not base32!"); err != ErrBadSecret {
+		t.Fatalf("ParseSecret invalid: got %v, want %v", err,
+			ErrBadSecret)
+	}
+}
+
+// TestEvaluate ensures the rules apply above their thresholds, to the total of
+// their window for spending limits, and combine their requirements.
+func TestEvaluate(t *testing.T) {
+	policy := &Policy{Rules: []Rule{
+		{Name: "large", Threshold: 100, Action: ActionSecondFactor},
+		{Name: "daily", Threshold: 150, Window: 24 * time.Hour,
+			Action: ActionDelay, Delay: time.Hour},
+		{Name: "max", Threshold: 1000, Action: ActionDeny},
+	}}
+	if err := policy.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	now := time.Unix(1000000, 0)
+	history := []Spend{
+		{Amount: 80, Time: now.Add(-25 * time.Hour)},
+		{Amount: 60, Time: now.Add(-time.Hour)},
+	}
+
+	tests := []struct {
+		amount       btcutil.Amount
+		denied       bool
+		secondFactor bool
+		delay        time.Duration
+		rules        int
+	}{
+		{50, false, false, 0, 0},
+		{90, false, false, 0, 0},
+		{91, false, false, time.Hour, 1},
+		{120, false, true, time.Hour, 2},
+		{1001, true, true, time.Hour, 3},
+	}
+	for _, test := range tests {
+		d := policy.Evaluate(test.amount, history, now)
+		if d.Denied != test.denied || d.SecondFactor != test.secondFactor ||
+			d.Delay != test.delay || len(d.Rules) != test.rules {
+
+			t.Errorf("Evaluate(%d): unexpected decision %+v",
+				test.amount, d)
+		}
+	}
+
+	invalid := []Rule{
+		{Name: "action", Action: "approve"},
+		{Name: "delay", Action: ActionDelay},
+		{Name: "negative", Threshold: -1, Action: ActionDeny},
+	}
+	for _, rule := range invalid {
+		p := &Policy{Rules: []Rule{rule}}
+		if err := p.Validate(); err == nil {
+			t.Errorf("Validate %s: unexpected success", rule.Name)
+		}
+	}
+}
+
+// TestEngine ensures held sends are released once approved and past their
+// delay, count towards the spending limits, and that the policy can only be
+// changed with the second factor.
+func TestEngine(t *testing.T) {
+	totp, err := ParseSecret("GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ")
+	if err != nil {
+		t.Fatalf("ParseSecret: %v", err)
+	}
+	policy := &Policy{Rules: []Rule{
+		{Name: "large", Threshold: 100, Action: ActionSecondFactor},
+		{Name: "daily", Threshold: 150, Window: 24 * time.Hour,
+			Action: ActionDelay, Delay: time.Hour},
+	}}
+	if _, err := NewEngine(policy, nil); err != ErrNoSecondFactor {
+		t.Fatalf("NewEngine: got %v, want %v", err, ErrNoSecondFactor)
+	}
+	e, err := NewEngine(policy, totp)
+	if err != nil {
+		t.Fatalf("NewEngine: %v", err)
+	}
+	now := time.Unix(1000000, 0)
+	e.now = func() time.Time { return now }
+
+	// Small sends are authorized until the daily limit is reached.
+	if p, err := e.Submit(100); p != nil || err != nil {
+		t.Fatalf("Submit: got %+v, %v", p, err)
+	}
+	delayed, err := e.Submit(60)
+	if err != nil || delayed == nil || delayed.SecondFactor ||
+		!delayed.NotBefore.Equal(now.Add(time.Hour)) {
+
+		t.Fatalf("Submit: got %+v, %v", delayed, err)
+	}
+	if err := e.Release(delayed.ID); err != ErrNotReady {
+		t.Fatalf("Release: got %v, want %v", err, ErrNotReady)
+	}
+
+	// A large send needs the second factor.
+	large, err := e.Submit(500)
+	if err != nil || large == nil || !large.SecondFactor {
+		t.Fatalf("Submit: got %+v, %v", large, err)
+	}
+	if _, err := e.Approve(large.ID, "000000"); err != ErrBadCode {
+		t.Fatalf("Approve: got %v, want %v", err, ErrBadCode)
+	}
+	if _, err := e.Approve(large.ID, totp.Code(now)); err != nil {
+		t.Fatalf("Approve: %v", err)
+	}
+
+	// Cancelled sends are dropped and the others are released after
+	// their delay.
+	if err := e.Cancel(delayed.ID); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	if err := e.Cancel(delayed.ID); err != ErrUnknownSend {
+		t.Fatalf("Cancel: got %v, want %v", err, ErrUnknownSend)
+	}
+	if ready := e.Ready(); len(ready) != 0 {
+		t.Fatalf("unexpected ready sends %v", ready)
+	}
+	now = now.Add(time.Hour)
+	if ready := e.Ready(); len(ready) != 1 || ready[0] != large.ID {
+		t.Fatalf("unexpected ready sends %v", ready)
+	}
+	if err := e.Release(large.ID); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if pending := e.Pending(); len(pending) != 0 {
+		t.Fatalf("unexpected pending sends %+v", pending)
+	}
+
+	// The released send counts towards the daily limit, until it is
+	// older than the window.
+	if p, err := e.Submit(1); p == nil || err != nil {
+		t.Fatalf("Submit: got %+v, %v", p, err)
+	}
+	now = now.Add(24 * time.Hour)
+	if p, err := e.Submit(1); p != nil || err != nil {
+		t.Fatalf("Submit: got %+v, %v", p, err)
+	}
+
+	// Changing the policy requires the second factor.
+	if err := e.SetPolicy(&Policy{}, nil, "000000"); err != ErrBadCode {
+		t.Fatalf("SetPolicy: got %v, want %v", err, ErrBadCode)
+	}
+	if err := e.SetPolicy(&Policy{}, nil, totp.Code(now)); err != nil {
+		t.Fatalf("SetPolicy: %v", err)
+	}
+	if p, err := e.Submit(10000); p != nil || err != nil {
+		t.Fatalf("Submit: got %+v, %v", p, err)
+	}
+	if _, hasTOTP := e.Policy(); hasTOTP {
+		t.Fatal("second factor still configured")
+	}
+}
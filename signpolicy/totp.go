@@ -0,0 +1,110 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package signpolicy
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// totpStep is the time step of the one-time passwords.
+	totpStep = 30
+
+	// totpDigits is the number of digits of the one-time passwords.
+	totpDigits = 6
+
+	// totpSkew is the number of time steps before and after the current
+	// one whose passwords are accepted, for clock differences.
+	totpSkew = 1
+
+	// minSecretSize is the minimum size in bytes of a shared secret.
+	minSecretSize = 10
+)
+
+var (
+	// ErrBadSecret is returned when a shared secret is not valid base32 or
+	// is too short.
+	ErrBadSecret = errors.New("invalid second factor secret")
+
+	// ErrBadCode is returned when a one-time password is wrong, expired or
+	// was already used.
+	ErrBadCode = errors.New("invalid second factor code")
+)
+
+// TOTP verifies the time-based one-time passwords (RFC 6238) generated from a
+// shared secret, with the parameters used by authenticator apps: HMAC-SHA1,
+// 30 second steps and 6 digits.  A password is only accepted once.
+type TOTP struct {
+	secret   []byte
+	lastUsed uint64
+}
+
+// ParseSecret returns the TOTP of the passed base32 encoded secret, as shown
+// by authenticator apps.  Spaces and padding are ignored.
+func ParseSecret(s string) (*TOTP, error) {
+	s = strings.ToUpper(strings.Replace(s, " ", "", -1))
+	s = strings.TrimRight(s, "=")
+	secret, err := base32.StdEncoding.WithPadding(base32.NoPadding).
+		DecodeString(s)
+	if err != nil || len(secret) < minSecretSize {
+		return nil, ErrBadSecret
+	}
+	return &TOTP{secret: secret}, nil
+}
+
+// Secret returns the base32 encoding of the shared secret.
+func (t *TOTP) Secret() string {
+	return base32.StdEncoding.WithPadding(base32.NoPadding).
+		EncodeToString(t.secret)
+}
+
+// code returns the password of the passed time step.
+func (t *TOTP) code(counter uint64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+	mac := hmac.New(sha1.New, t.secret)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:]) & 0x7fffffff
+	mod := uint32(1)
+	for i := 0; i < totpDigits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", totpDigits, value%mod)
+}
+
+// Code returns the password at the passed time.
+func (t *TOTP) Code(now time.Time) string {
+	return t.code(uint64(now.Unix()) / totpStep)
+}
+
+// Verify checks the passed password against the ones of the time steps around
+// the passed time, and that it is newer than the last accepted one so it can't
+// be replayed.
+func (t *TOTP) Verify(code string, now time.Time) error {
+	counter := uint64(now.Unix()) / totpStep
+	for i := -totpSkew; i <= totpSkew; i++ {
+		c := counter + uint64(i)
+		if c <= t.lastUsed {
+			continue
+		}
+		expected := t.code(c)
+		if subtle.ConstantTimeCompare([]byte(code), []byte(expected)) == 1 {
+			t.lastUsed = c
+			return nil
+		}
+	}
+	return ErrBadCode
+}
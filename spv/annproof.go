@@ -0,0 +1,284 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package spv
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/block/proof"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/difficulty"
+	"github.com/pkt-cash/pktd/blockchain/packetcrypt/pcutil"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+var (
+	// ErrCoinbaseNotProven is returned when the merkle proof of an
+	// announcement proof does not prove exactly the coinbase of its block.
+	ErrCoinbaseNotProven = errors.New("merkle proof does not prove the " +
+		"coinbase of the block")
+
+	// ErrBadCoinbaseHeight is returned when the coinbase of an announcement
+	// proof does not start with the height of its block.
+	ErrBadCoinbaseHeight = errors.New("coinbase does not commit to the " +
+		"height of the block")
+
+	// ErrNoAnnCommitment is returned when the coinbase of an announcement
+	// proof holds no valid announcement commitment.
+	ErrNoAnnCommitment = errors.New("coinbase holds no valid announcement " +
+		"commitment")
+
+	// ErrAnnRootMismatch is returned when the root of the announcement tree
+	// computed from a proof differs from the one committed to by the
+	// coinbase.
+	ErrAnnRootMismatch = errors.New("announcement tree root of the proof " +
+		"does not match the coinbase commitment")
+
+	// ErrAnnTargetTooHigh is returned when the aged target of a proven
+	// announcement is higher than the minimum target committed to by the
+	// coinbase, so the block could not have included it.
+	ErrAnnTargetTooHigh = errors.New("aged target of the announcement is " +
+		"higher than the minimum announcement target of the block")
+)
+
+// pcCoinbasePrefix is the start of the coinbase output script holding the
+// announcement commitment: an OP_RETURN pushing 48 bytes starting with the
+// commitment magic.
+var pcCoinbasePrefix = [...]byte{0x6a, 0x30, 0x09, 0xf9, 0x11, 0x02}
+
+// AnnProof is a proof that a PacketCrypt announcement is included in the
+// announcement tree committed to by the coinbase of a block.
+//
+// A block only carries the four announcements sampled by its PacketCrypt
+// proof, along with the branches of the announcement tree leading to them, so
+// only these announcements can be proven.  Position is the index of the proven
+// announcement among them and AnnHashes are the hashes of the four
+// announcements, AnnHashes[Position] being the hash of Ann.  AnnIndexes are
+// the numbers selecting the announcements, which give their positions in the
+// tree, and AnnTreeProof is the announcement proof of the block.
+type AnnProof struct {
+	Block        wire.MsgMerkleBlock
+	Coinbase     wire.MsgTx
+	Ann          wire.PacketCryptAnn
+	Position     uint8
+	AnnHashes    [4][32]byte
+	AnnIndexes   [4]uint64
+	AnnTreeProof []byte
+}
+
+// AnnInclusion is the result of the verification of an announcement proof.
+type AnnInclusion struct {
+	// BlockHash and BlockHeight identify the block including the
+	// announcement.
+	BlockHash   chainhash.Hash
+	BlockHeight int32
+
+	// AnnHash is the hash of the announcement in the announcement tree.
+	AnnHash [32]byte
+
+	// AnnCount and AnnMinTarget are the number of announcements and the
+	// highest announcement target committed to by the coinbase.
+	AnnCount     uint64
+	AnnMinTarget uint32
+
+	// AnnTarget is the target of the announcement once aged to the height
+	// of the block, which is the target its work counted for.
+	AnnTarget uint32
+}
+
+// Serialize writes the proof to w: the merkle block proving the coinbase, the
+// coinbase without its witness, the announcement, the position, the hashes of
+// the other announcements, the selecting numbers and the announcement proof.
+func (p *AnnProof) Serialize(w io.Writer) error {
+	err := p.Block.BtcEncode(w, wire.ProtocolVersion, wire.BaseEncoding)
+	if err != nil {
+		return err
+	}
+	if err := p.Coinbase.SerializeNoWitness(w); err != nil {
+		return err
+	}
+	if _, err := w.Write(p.Ann.Header[:]); err != nil {
+		return err
+	}
+	if _, err := w.Write([]byte{p.Position}); err != nil {
+		return err
+	}
+	for i := range p.AnnHashes {
+		if i == int(p.Position) {
+			continue
+		}
+		if _, err := w.Write(p.AnnHashes[i][:]); err != nil {
+			return err
+		}
+	}
+	var buf [8]byte
+	for _, index := range p.AnnIndexes {
+		binary.LittleEndian.PutUint64(buf[:], index)
+		if _, err := w.Write(buf[:]); err != nil {
+			return err
+		}
+	}
+	return wire.WriteVarBytes(w, wire.ProtocolVersion, p.AnnTreeProof)
+}
+
+// Deserialize reads a proof written by Serialize from r.  The hash of the
+// proven announcement is computed from it.
+func (p *AnnProof) Deserialize(r io.Reader) error {
+	err := p.Block.BtcDecode(r, wire.ProtocolVersion, wire.BaseEncoding)
+	if err != nil {
+		return err
+	}
+	if err := p.Coinbase.DeserializeNoWitness(r); err != nil {
+		return err
+	}
+	if _, err := io.ReadFull(r, p.Ann.Header[:]); err != nil {
+		return err
+	}
+	var buf [8]byte
+	if _, err := io.ReadFull(r, buf[:1]); err != nil {
+		return err
+	}
+	p.Position = buf[0]
+	if p.Position >= 4 {
+		return fmt.Errorf("announcement position %d is out of range",
+			p.Position)
+	}
+	for i := range p.AnnHashes {
+		if i == int(p.Position) {
+			pcutil.HashCompress(p.AnnHashes[i][:], p.Ann.Header[:])
+			continue
+		}
+		if _, err := io.ReadFull(r, p.AnnHashes[i][:]); err != nil {
+			return err
+		}
+	}
+	for i := range p.AnnIndexes {
+		if _, err := io.ReadFull(r, buf[:]); err != nil {
+			return err
+		}
+		p.AnnIndexes[i] = binary.LittleEndian.Uint64(buf[:])
+	}
+	p.AnnTreeProof, err = wire.ReadVarBytes(r, wire.ProtocolVersion,
+		wire.MaxBlockPayload, "AnnTreeProof")
+	return err
+}
+
+// coinbaseHeight returns the block height the passed coinbase starts its
+// signature script with, as required by BIP 34.
+func coinbaseHeight(coinbase *wire.MsgTx) (int32, error) {
+	if len(coinbase.TxIn) != 1 || len(coinbase.TxIn[0].SignatureScript) < 1 {
+		return 0, ErrBadCoinbaseHeight
+	}
+	prevOut := &coinbase.TxIn[0].PreviousOutPoint
+	if prevOut.Index != wire.MaxPrevOutIndex || prevOut.Hash != (chainhash.Hash{}) {
+		return 0, ErrBadCoinbaseHeight
+	}
+
+	// The height is either a small integer pushed by OP_0 or OP_1 through
+	// OP_16, or pushed as a little-endian number.
+	sigScript := coinbase.TxIn[0].SignatureScript
+	switch opcode := sigScript[0]; {
+	case opcode == 0x00:
+		return 0, nil
+	case opcode >= 0x51 && opcode <= 0x60:
+		return int32(opcode - 0x50), nil
+	}
+	serializedLen := int(sigScript[0])
+	if serializedLen > 8 || len(sigScript[1:]) < serializedLen {
+		return 0, ErrBadCoinbaseHeight
+	}
+	var serializedHeight [8]byte
+	copy(serializedHeight[:], sigScript[1:serializedLen+1])
+	return int32(binary.LittleEndian.Uint64(serializedHeight[:])), nil
+}
+
+// coinbaseCommit returns the announcement commitment of the passed coinbase,
+// or nil when it has none.
+func coinbaseCommit(coinbase *wire.MsgTx) *wire.PcCoinbaseCommit {
+	for _, txOut := range coinbase.TxOut {
+		if len(txOut.PkScript) > len(pcCoinbasePrefix) &&
+			bytes.Equal(txOut.PkScript[:len(pcCoinbasePrefix)],
+				pcCoinbasePrefix[:]) {
+
+			var commit wire.PcCoinbaseCommit
+			copy(commit.Bytes[:], txOut.PkScript[2:])
+			return &commit
+		}
+	}
+	return nil
+}
+
+// Verify checks the proof, returning what it proves about the announcement.
+// The merkle block must prove the coinbase, which must commit to the height of
+// the block and to an announcement tree whose root is computed from the
+// announcement hashes and proof, and the announcement must be recent enough
+// for the block to include it.  The caller must still check the header belongs
+// to the best chain.
+//
+// The work of the announcement itself is not checked: a block including an
+// announcement without the work it claims is invalid, and the work can be
+// checked with the ValidatePcAnn function of the packetcrypt package given the
+// hash of the block at the parent height of the announcement.
+func (p *AnnProof) Verify() (*AnnInclusion, error) {
+	if p.Position >= 4 {
+		return nil, fmt.Errorf("announcement position %d is out of range",
+			p.Position)
+	}
+	var annHash [32]byte
+	pcutil.HashCompress(annHash[:], p.Ann.Header[:])
+	if annHash != p.AnnHashes[p.Position] {
+		return nil, errors.New("announcement does not match its hash")
+	}
+
+	txHashes, indices, err := ExtractMatches(&p.Block)
+	if err != nil {
+		return nil, err
+	}
+	if len(txHashes) != 1 || indices[0] != 0 ||
+		*txHashes[0] != p.Coinbase.TxHash() {
+
+		return nil, ErrCoinbaseNotProven
+	}
+	height, err := coinbaseHeight(&p.Coinbase)
+	if err != nil {
+		return nil, err
+	}
+	commit := coinbaseCommit(&p.Coinbase)
+	if commit == nil || commit.Magic() != wire.PcCoinbaseCommitMagic ||
+		!difficulty.IsAnnMinDiffOk(commit.AnnMinDifficulty()) ||
+		commit.AnnCount() == 0 {
+
+		return nil, ErrNoAnnCommitment
+	}
+
+	pcp := wire.PacketCryptProof{AnnProof: p.AnnTreeProof}
+	root, err := proof.PcpHash(&p.AnnHashes, commit.AnnCount(),
+		&p.AnnIndexes, &pcp)
+	if err != nil {
+		return nil, fmt.Errorf("malformed announcement proof: %v", err)
+	}
+	if !bytes.Equal(root[:], commit.MerkleRoot()) {
+		return nil, ErrAnnRootMismatch
+	}
+
+	annTarget := difficulty.GetBlockAnnTarget(p.Ann.GetWorkTarget(),
+		p.Ann.GetParentBlockHeight(), height)
+	if annTarget > commit.AnnMinDifficulty() {
+		return nil, ErrAnnTargetTooHigh
+	}
+
+	return &AnnInclusion{
+		BlockHash:    p.Block.Header.BlockHash(),
+		BlockHeight:  height,
+		AnnHash:      annHash,
+		AnnCount:     commit.AnnCount(),
+		AnnMinTarget: commit.AnnMinDifficulty(),
+		AnnTarget:    annTarget,
+	}, nil
+}
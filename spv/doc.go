@@ -0,0 +1,44 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package spv holds the consensus code needed by light clients to verify PKT
+simplified payment verification (SPV) proofs: the difficulty math, the checks
+of chains of block headers and the verification of merkle proofs.
+
+The node uses this package itself, so light clients verify proofs with the
+same code as the node.  It only depends on the chainhash, wire and chaincfg
+packages and the PacketCrypt difficulty math, not on the database, script
+engine or networking, so it can be built for WebAssembly and mobile platforms:
+
+	GOOS=js GOARCH=wasm go build -o pktspv.wasm ./cmd/pktspv
+	gomobile bind -target=android ./spv/pktspv
+
+The pktspv package wraps this package with an API limited to the types
+gomobile supports, and the pktspv command exposes it to JavaScript.
+
+A HeaderChain starts from a trusted header, such as a checkpoint, and checks
+each header added to it connects to the previous one and has a difficulty the
+network permits given the difficulty of the previous header.  The retarget
+rules are only checked within the limits of the adjustment factor, since
+computing the exact difficulty needs the timestamps of the headers of a whole
+retarget period.  The PacketCrypt proof of work of a block is not part of its
+header, so it is not checked either: a light client trusts the headers with
+the most work announced by the nodes it follows.
+
+A proof that transactions are included in a block is a merkle block as defined
+by BIP 37, in the format of the merkleblock wire message and of the
+gettxoutproof RPC.  ExtractMatches verifies it against the merkle root of its
+header and returns the proven transactions.  The header must then be checked to
+belong to the best chain.
+
+An AnnProof, as returned by the getannproof RPC, proves a PacketCrypt
+announcement is included in a block: it holds a merkle block proving the
+coinbase, the coinbase, the announcement and the branches of the announcement
+tree committed to by the coinbase.  Verify recomputes the root of the tree and
+checks the announcement aged to the height of the block meets the minimum
+announcement target of the block, so announcement miners can prove their
+contribution to pools which only follow the headers.
+*/
+package spv
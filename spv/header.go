@@ -0,0 +1,91 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package spv
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// CheckHeader ensures the passed header of the block at the passed height
+// connects to the previous header, with the passed hash and difficulty bits,
+// and has a valid difficulty for the network, returning its hash.
+//
+// The proof of work is not checked: the PacketCrypt proof of a block is not
+// part of its header.
+func CheckHeader(params *chaincfg.Params, header *wire.BlockHeader,
+	height int32, prevHash *chainhash.Hash, prevBits uint32) (chainhash.Hash, error) {
+
+	hash := header.BlockHash()
+	if header.PrevBlock != *prevHash {
+		return hash, fmt.Errorf("header %v at height %d does not "+
+			"connect to the previous header", hash, height)
+	}
+	target := CompactToBig(header.Bits)
+	if target.Sign() <= 0 || target.Cmp(powLimit(params)) > 0 {
+		return hash, fmt.Errorf("header %v at height %d has an "+
+			"invalid difficulty of %08x", hash, height, header.Bits)
+	}
+	if !PermittedDifficultyTransition(params, height, prevBits,
+		header.Bits) {
+
+		return hash, fmt.Errorf("header %v at height %d has a "+
+			"difficulty of %08x which can't follow %08x", hash,
+			height, header.Bits, prevBits)
+	}
+	return hash, nil
+}
+
+// HeaderChain follows a chain of headers from a trusted header, such as a
+// checkpoint, checking each header added to it.
+type HeaderChain struct {
+	params *chaincfg.Params
+	hash   chainhash.Hash
+	height int32
+	bits   uint32
+	work   *big.Int
+}
+
+// NewHeaderChain returns a chain of headers of the passed network starting at
+// the trusted header with the passed hash, height and difficulty bits.
+func NewHeaderChain(params *chaincfg.Params, hash *chainhash.Hash,
+	height int32, bits uint32) *HeaderChain {
+
+	return &HeaderChain{
+		params: params,
+		hash:   *hash,
+		height: height,
+		bits:   bits,
+		work:   new(big.Int),
+	}
+}
+
+// Add checks the passed header follows the tip of the chain and makes it the
+// new tip.  The chain is left unchanged when an error is returned.
+func (c *HeaderChain) Add(header *wire.BlockHeader) error {
+	hash, err := CheckHeader(c.params, header, c.height+1, &c.hash, c.bits)
+	if err != nil {
+		return err
+	}
+	c.hash = hash
+	c.height++
+	c.bits = header.Bits
+	c.work.Add(c.work, CalcWork(header.Bits))
+	return nil
+}
+
+// Tip returns the hash and height of the tip of the chain.
+func (c *HeaderChain) Tip() (chainhash.Hash, int32) {
+	return c.hash, c.height
+}
+
+// Work returns the work of the headers added to the chain.
+func (c *HeaderChain) Work() *big.Int {
+	return new(big.Int).Set(c.work)
+}
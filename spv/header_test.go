@@ -0,0 +1,64 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package spv
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// TestHeaderChain ensures headers are only added to a header chain when they
+// connect to its tip with a permitted difficulty, and that their work is
+// accumulated.
+func TestHeaderChain(t *testing.T) {
+	params := &chaincfg.MainNetParams
+	start := chainhash.Hash{1}
+	bits := params.PowLimitBits
+	chain := NewHeaderChain(params, &start, 1000, bits)
+
+	header := func(prev chainhash.Hash, bits uint32) *wire.BlockHeader {
+		return wire.NewBlockHeader(1, &prev, &chainhash.Hash{}, bits, 0)
+	}
+	tests := []struct {
+		name   string
+		header *wire.BlockHeader
+	}{
+		{"not connecting", header(chainhash.Hash{2}, bits)},
+		{"difficulty change", header(start, bits-1)},
+		{"zero target", header(start, 0)},
+		{"target above limit", header(start, 0x1e00ffff)},
+	}
+	for _, test := range tests {
+		if err := chain.Add(test.header); err == nil {
+			t.Fatalf("Add: unexpected success with %s", test.name)
+		}
+		if hash, height := chain.Tip(); hash != start || height != 1000 {
+			t.Fatalf("Add: tip moved to %v at height %d with %s",
+				hash, height, test.name)
+		}
+	}
+
+	first := header(start, bits)
+	first.Timestamp = time.Unix(1, 0)
+	if err := chain.Add(first); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	second := header(first.BlockHash(), bits)
+	if err := chain.Add(second); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if hash, height := chain.Tip(); hash != second.BlockHash() || height != 1002 {
+		t.Fatalf("Tip: got %v at height %d", hash, height)
+	}
+	want := new(big.Int).Mul(CalcWork(bits), big.NewInt(2))
+	if work := chain.Work(); work.Cmp(want) != 0 {
+		t.Fatalf("Work: got %v, want %v", work, want)
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package spv
+
+import (
+	"errors"
+
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// maxTransactions is the maximum number of transactions a proof may claim the
+// block holds.  It matches the largest number of transactions which fit in a
+// block.
+const maxTransactions = wire.MaxBlockPayload / 10
+
+var (
+	// ErrBadProof is returned when a proof is malformed, so the partial
+	// merkle tree can not be traversed.
+	ErrBadProof = errors.New("malformed merkle proof")
+
+	// ErrMerkleRootMismatch is returned when the merkle root computed from
+	// a proof differs from the one committed to by its header.
+	ErrMerkleRootMismatch = errors.New("merkle root of the proof does not " +
+		"match the block header")
+)
+
+// HashMerkleBranches takes two hashes, treated as the left and right tree
+// nodes, and returns the hash of their concatenation.  This is a helper
+// function used to aid in the generation of a merkle tree.
+func HashMerkleBranches(left *chainhash.Hash, right *chainhash.Hash) *chainhash.Hash {
+	// Concatenate the left and right nodes.
+	var hash [chainhash.HashSize * 2]byte
+	copy(hash[:chainhash.HashSize], left[:])
+	copy(hash[chainhash.HashSize:], right[:])
+
+	newHash := chainhash.DoubleHashH(hash[:])
+	return &newHash
+}
+
+// TreeWidth returns the number of nodes at the passed height of the merkle
+// tree of a block holding numTx transactions, where the leaves are at height
+// zero.
+func TreeWidth(numTx uint32, height uint) uint32 {
+	return uint32((uint64(numTx) + (1 << height) - 1) >> height)
+}
+
+// TreeHeight returns the height of the root of the merkle tree of a block
+// holding numTx transactions.
+func TreeHeight(numTx uint32) uint {
+	var height uint
+	for TreeWidth(numTx, height) > 1 {
+		height++
+	}
+	return height
+}
+
+// extractor is used to house the state of the traversal of a partial merkle
+// tree.
+type extractor struct {
+	msg      *wire.MsgMerkleBlock
+	bitsUsed int
+	hashUsed int
+	matches  []*chainhash.Hash
+	indices  []uint32
+}
+
+// extract traverses the partial merkle tree depth-first from the node at the
+// passed height and position, recording the matched transactions, and returns
+// the hash of the node.
+func (e *extractor) extract(height uint, pos uint32) (*chainhash.Hash, error) {
+	if e.bitsUsed >= len(e.msg.Flags)*8 {
+		return nil, ErrBadProof
+	}
+	isParent := e.msg.Flags[e.bitsUsed/8]&(1<<uint(e.bitsUsed%8)) != 0
+	e.bitsUsed++
+
+	if height == 0 || !isParent {
+		if e.hashUsed >= len(e.msg.Hashes) {
+			return nil, ErrBadProof
+		}
+		hash := e.msg.Hashes[e.hashUsed]
+		e.hashUsed++
+		if height == 0 && isParent {
+			e.matches = append(e.matches, hash)
+			e.indices = append(e.indices, pos)
+		}
+		return hash, nil
+	}
+
+	left, err := e.extract(height-1, pos*2)
+	if err != nil {
+		return nil, err
+	}
+	right := left
+	if pos*2+1 < TreeWidth(e.msg.Transactions, height-1) {
+		right, err = e.extract(height-1, pos*2+1)
+		if err != nil {
+			return nil, err
+		}
+
+		// A right branch equal to the left one would let a different
+		// list of transactions have the same merkle root (CVE-2012-2459).
+		if right.IsEqual(left) {
+			return nil, ErrBadProof
+		}
+	}
+	return HashMerkleBranches(left, right), nil
+}
+
+// ExtractMatches verifies the passed proof, a merkle block as defined by BIP
+// 37, against the merkle root of its header and returns the hashes of the
+// proven transactions along with their indices in the block.  ErrBadProof is
+// returned when the proof is malformed and ErrMerkleRootMismatch when it does
+// not match its header.
+func ExtractMatches(msg *wire.MsgMerkleBlock) ([]*chainhash.Hash, []uint32, error) {
+	if msg.Transactions == 0 || msg.Transactions > maxTransactions ||
+		uint32(len(msg.Hashes)) > msg.Transactions ||
+		len(msg.Flags)*8 < len(msg.Hashes) {
+
+		return nil, nil, ErrBadProof
+	}
+
+	e := extractor{msg: msg}
+	root, err := e.extract(TreeHeight(msg.Transactions), 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Every hash and every flag byte must have been used.
+	if e.hashUsed != len(msg.Hashes) || (e.bitsUsed+7)/8 != len(msg.Flags) {
+		return nil, nil, ErrBadProof
+	}
+	if !root.IsEqual(&msg.Header.MerkleRoot) {
+		return nil, nil, ErrMerkleRootMismatch
+	}
+	return e.matches, e.indices, nil
+}
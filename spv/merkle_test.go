@@ -0,0 +1,77 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package spv
+
+import (
+	"testing"
+
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// TestExtractMatches ensures the transactions proven by a merkle proof are
+// extracted and that malformed or mismatching proofs are rejected.
+func TestExtractMatches(t *testing.T) {
+	// A block of three transactions, the last one being paired with
+	// itself.
+	txns := []*chainhash.Hash{{1}, {2}, {3}}
+	left := HashMerkleBranches(txns[0], txns[1])
+	right := HashMerkleBranches(txns[2], txns[2])
+	root := HashMerkleBranches(left, right)
+
+	// The proof of the third transaction holds the hash of the left
+	// branch, then descends the right branch to the transaction.
+	proof := func() *wire.MsgMerkleBlock {
+		msg := wire.NewMsgMerkleBlock(wire.NewBlockHeader(1,
+			&chainhash.Hash{}, root, 0, 0))
+		msg.Transactions = 3
+		msg.Hashes = []*chainhash.Hash{left, txns[2]}
+		msg.Flags = []byte{0x0d} // 1 (root), 0 (left), 1, 1 (third)
+		return msg
+	}
+
+	matches, indices, err := ExtractMatches(proof())
+	if err != nil {
+		t.Fatalf("ExtractMatches: %v", err)
+	}
+	if len(matches) != 1 || *matches[0] != *txns[2] ||
+		len(indices) != 1 || indices[0] != 2 {
+
+		t.Fatalf("ExtractMatches: got %v at %v", matches, indices)
+	}
+
+	mismatch := proof()
+	mismatch.Header.MerkleRoot = *left
+	if _, _, err := ExtractMatches(mismatch); err != ErrMerkleRootMismatch {
+		t.Fatalf("ExtractMatches: unexpected error %v with another "+
+			"merkle root", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*wire.MsgMerkleBlock)
+	}{
+		{"no transactions", func(msg *wire.MsgMerkleBlock) {
+			msg.Transactions = 0
+		}},
+		{"missing hash", func(msg *wire.MsgMerkleBlock) {
+			msg.Hashes = msg.Hashes[:1]
+		}},
+		{"extra flags", func(msg *wire.MsgMerkleBlock) {
+			msg.Flags = append(msg.Flags, 0)
+		}},
+		{"missing flags", func(msg *wire.MsgMerkleBlock) {
+			msg.Flags = nil
+		}},
+	}
+	for _, test := range tests {
+		msg := proof()
+		test.modify(msg)
+		if _, _, err := ExtractMatches(msg); err != ErrBadProof {
+			t.Fatalf("ExtractMatches: unexpected error %v with %s",
+				err, test.name)
+		}
+	}
+}
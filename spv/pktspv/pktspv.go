@@ -0,0 +1,252 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package pktspv exposes the spv package to mobile applications.  Its API is
+// limited to the types supported by gomobile: hashes, headers and proofs are
+// passed as hex strings and big numbers as decimal strings.
+package pktspv
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/spv"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// networks are the networks which can be selected by name.
+var networks = []*chaincfg.Params{
+	&chaincfg.PktMainNetParams,
+	&chaincfg.PktTestNetParams,
+	&chaincfg.MainNetParams,
+	&chaincfg.TestNet3Params,
+	&chaincfg.RegressionNetParams,
+	&chaincfg.SimNetParams,
+}
+
+// network returns the parameters of the network with the passed name.
+func network(name string) (*chaincfg.Params, error) {
+	for _, params := range networks {
+		if params.Name == name {
+			return params, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown network %q", name)
+}
+
+// Target returns the target encoded by the passed difficulty bits as a
+// 64-character hex string.
+func Target(bits int64) string {
+	return fmt.Sprintf("%064x", spv.CompactToBig(uint32(bits)))
+}
+
+// Work returns the work of a block with the passed difficulty bits in decimal.
+func Work(bits int64) string {
+	return spv.CalcWork(uint32(bits)).String()
+}
+
+// EffectiveTarget returns the difficulty bits of the target the PacketCrypt
+// proof of a block must beat given the difficulty bits of its header, the
+// difficulty bits of the highest target of its announcements and their number.
+func EffectiveTarget(bits, minAnnBits, annCount int64) int64 {
+	return int64(spv.GetEffectiveTarget(uint32(bits), uint32(minAnnBits),
+		uint64(annCount)))
+}
+
+// HeaderChain follows a chain of headers from a trusted header.
+type HeaderChain struct {
+	chain *spv.HeaderChain
+}
+
+// NewHeaderChain returns a chain of headers of the network with the passed
+// name, such as pkt or pkttest, starting at the trusted header with the passed
+// hash, height and difficulty bits.
+func NewHeaderChain(networkName, hash string, height int32, bits int64) (*HeaderChain, error) {
+	params, err := network(networkName)
+	if err != nil {
+		return nil, err
+	}
+	h, err := chainhash.NewHashFromStr(hash)
+	if err != nil {
+		return nil, err
+	}
+	return &HeaderChain{
+		chain: spv.NewHeaderChain(params, h, height, uint32(bits)),
+	}, nil
+}
+
+// AddHeader checks the passed hex-encoded header follows the tip of the chain
+// and makes it the new tip.
+func (c *HeaderChain) AddHeader(header string) error {
+	serialized, err := hex.DecodeString(header)
+	if err != nil {
+		return err
+	}
+	var h wire.BlockHeader
+	r := bytes.NewReader(serialized)
+	if err := h.Deserialize(r); err != nil {
+		return err
+	}
+	if r.Len() != 0 {
+		return fmt.Errorf("%d trailing bytes after the header", r.Len())
+	}
+	return c.chain.Add(&h)
+}
+
+// TipHash returns the hash of the tip of the chain.
+func (c *HeaderChain) TipHash() string {
+	hash, _ := c.chain.Tip()
+	return hash.String()
+}
+
+// TipHeight returns the height of the tip of the chain.
+func (c *HeaderChain) TipHeight() int32 {
+	_, height := c.chain.Tip()
+	return height
+}
+
+// Work returns the work of the headers added to the chain in decimal.
+func (c *HeaderChain) Work() string {
+	return c.chain.Work().String()
+}
+
+// TxOutProof is a verified proof that transactions are included in a block.
+type TxOutProof struct {
+	// BlockHash, PrevBlockHash and MerkleRoot are the hashes of the block
+	// header, Time its timestamp and Bits its difficulty bits.
+	BlockHash     string
+	PrevBlockHash string
+	MerkleRoot    string
+	Time          int64
+	Bits          int64
+
+	txIDs   []string
+	indices []int64
+}
+
+// NumTx returns the number of transactions proven.
+func (p *TxOutProof) NumTx() int {
+	return len(p.txIDs)
+}
+
+// TxID returns the ID of the proven transaction with the passed index.
+func (p *TxOutProof) TxID(i int) string {
+	return p.txIDs[i]
+}
+
+// TxIndex returns the index in the block of the proven transaction with the
+// passed index.
+func (p *TxOutProof) TxIndex(i int) int64 {
+	return p.indices[i]
+}
+
+// VerifyTxOutProof verifies the passed hex-encoded proof, as returned by the
+// gettxoutproof RPC, against the merkle root of its header.  The caller must
+// still check the header belongs to the best chain.
+func VerifyTxOutProof(proof string) (*TxOutProof, error) {
+	serialized, err := hex.DecodeString(proof)
+	if err != nil {
+		return nil, err
+	}
+	var msg wire.MsgMerkleBlock
+	r := bytes.NewReader(serialized)
+	err = msg.BtcDecode(r, wire.ProtocolVersion, wire.BaseEncoding)
+	if err != nil {
+		return nil, err
+	}
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("%d trailing bytes after the merkle proof",
+			r.Len())
+	}
+	txHashes, indices, err := spv.ExtractMatches(&msg)
+	if err != nil {
+		return nil, err
+	}
+
+	header := &msg.Header
+	result := &TxOutProof{
+		BlockHash:     header.BlockHash().String(),
+		PrevBlockHash: header.PrevBlock.String(),
+		MerkleRoot:    header.MerkleRoot.String(),
+		Time:          header.Timestamp.Unix(),
+		Bits:          int64(header.Bits),
+		txIDs:         make([]string, 0, len(txHashes)),
+		indices:       make([]int64, 0, len(indices)),
+	}
+	for i, hash := range txHashes {
+		result.txIDs = append(result.txIDs, hash.String())
+		result.indices = append(result.indices, int64(indices[i]))
+	}
+	return result, nil
+}
+
+// AnnProof is a verified proof that a PacketCrypt announcement is included in
+// a block.
+type AnnProof struct {
+	// BlockHash and BlockHeight identify the block including the
+	// announcement.
+	BlockHash   string
+	BlockHeight int32
+
+	// Ann is the hex-encoded announcement and AnnHash its hash in the
+	// announcement tree of the block.  ParentHeight is the height of the
+	// parent block of the announcement, WorkBits the difficulty bits it
+	// was mined at and SigningKey its hex-encoded signing key, empty when
+	// it is not signed.
+	Ann          string
+	AnnHash      string
+	ParentHeight int64
+	WorkBits     int64
+	SigningKey   string
+
+	// AgedBits are the difficulty bits of the announcement aged to the
+	// height of the block, and AnnMinBits and AnnCount the highest
+	// announcement target and the number of announcements committed to by
+	// the block.
+	AgedBits   int64
+	AnnMinBits int64
+	AnnCount   int64
+}
+
+// VerifyAnnProof verifies the passed hex-encoded announcement proof, as
+// returned by the getannproof RPC.  The caller must still check the block
+// header belongs to the best chain.
+func VerifyAnnProof(proof string) (*AnnProof, error) {
+	serialized, err := hex.DecodeString(proof)
+	if err != nil {
+		return nil, err
+	}
+	var p spv.AnnProof
+	r := bytes.NewReader(serialized)
+	if err := p.Deserialize(r); err != nil {
+		return nil, err
+	}
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("%d trailing bytes after the announcement "+
+			"proof", r.Len())
+	}
+	inclusion, err := p.Verify()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AnnProof{
+		BlockHash:    inclusion.BlockHash.String(),
+		BlockHeight:  inclusion.BlockHeight,
+		Ann:          hex.EncodeToString(p.Ann.Header[:]),
+		AnnHash:      hex.EncodeToString(inclusion.AnnHash[:]),
+		ParentHeight: int64(p.Ann.GetParentBlockHeight()),
+		WorkBits:     int64(p.Ann.GetWorkTarget()),
+		AgedBits:     int64(inclusion.AnnTarget),
+		AnnMinBits:   int64(inclusion.AnnMinTarget),
+		AnnCount:     int64(inclusion.AnnCount),
+	}
+	if p.Ann.HasSigningKey() {
+		result.SigningKey = hex.EncodeToString(p.Ann.GetSigningKey())
+	}
+	return result, nil
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package pktspv
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/btcutil/merkle"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// TestVerifyTxOutProof ensures a proof created by the node is verified and its
+// transactions returned.
+func TestVerifyTxOutProof(t *testing.T) {
+	msgBlock := wire.NewMsgBlock(wire.NewBlockHeader(1, &chainhash.Hash{},
+		&chainhash.Hash{}, 0x1f0fffff, 0))
+	for i := 0; i < 5; i++ {
+		tx := wire.NewMsgTx(wire.TxVersion)
+		tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Index: uint32(i)}, nil, nil))
+		tx.AddTxOut(wire.NewTxOut(int64(i), nil))
+		msgBlock.AddTransaction(tx)
+	}
+	block := btcutil.NewBlock(msgBlock)
+	merkles := blockchain.BuildMerkleTreeStore(block.Transactions(), false)
+	msgBlock.Header.MerkleRoot = *merkles[len(merkles)-1]
+	block = btcutil.NewBlock(msgBlock)
+
+	proven := block.Transactions()[3].Hash()
+	msg, err := merkle.NewMerkleBlock(block, []*chainhash.Hash{proven})
+	if err != nil {
+		t.Fatalf("NewMerkleBlock: %v", err)
+	}
+	serialized, err := merkle.Serialize(msg)
+	if err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+
+	proof, err := VerifyTxOutProof(hex.EncodeToString(serialized))
+	if err != nil {
+		t.Fatalf("VerifyTxOutProof: %v", err)
+	}
+	if proof.BlockHash != block.Hash().String() || proof.Bits != 0x1f0fffff ||
+		proof.NumTx() != 1 || proof.TxID(0) != proven.String() ||
+		proof.TxIndex(0) != 3 {
+
+		t.Fatalf("VerifyTxOutProof: unexpected proof %+v", proof)
+	}
+
+	msg.Header.Nonce++
+	serialized, _ = merkle.Serialize(msg)
+	if _, err := VerifyTxOutProof(hex.EncodeToString(serialized) + "00"); err == nil {
+		t.Fatalf("VerifyTxOutProof: unexpected success with trailing bytes")
+	}
+}
+
+// TestHeaderChain ensures headers are added to a header chain from their hex
+// encoding.
+func TestHeaderChain(t *testing.T) {
+	params := &chaincfg.PktMainNetParams
+	if _, err := NewHeaderChain("nonet", params.GenesisHash.String(), 0,
+		int64(params.PowLimitBits)); err == nil {
+
+		t.Fatalf("NewHeaderChain: unexpected success with an unknown " +
+			"network")
+	}
+	c, err := NewHeaderChain("pkt", params.GenesisHash.String(), 0,
+		int64(params.PowLimitBits))
+	if err != nil {
+		t.Fatalf("NewHeaderChain: %v", err)
+	}
+
+	header := wire.NewBlockHeader(1, params.GenesisHash, &chainhash.Hash{},
+		params.PowLimitBits, 0)
+	var buf bytes.Buffer
+	if err := header.Serialize(&buf); err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+	if err := c.AddHeader(hex.EncodeToString(buf.Bytes())); err != nil {
+		t.Fatalf("AddHeader: %v", err)
+	}
+	if c.TipHash() != header.BlockHash().String() || c.TipHeight() != 1 ||
+		c.Work() != Work(int64(params.PowLimitBits)) {
+
+		t.Fatalf("unexpected tip %s at height %d with work %s",
+			c.TipHash(), c.TipHeight(), c.Work())
+	}
+	if err := c.AddHeader(hex.EncodeToString(buf.Bytes())); err == nil {
+		t.Fatalf("AddHeader: unexpected success adding a header twice")
+	}
+}
+
+// TestVerifyAnnProof ensures malformed announcement proofs are rejected.
+func TestVerifyAnnProof(t *testing.T) {
+	if _, err := VerifyAnnProof("zz"); err == nil {
+		t.Fatalf("VerifyAnnProof: unexpected success with invalid hex")
+	}
+
+	// A proof cut after its merkle block is rejected.
+	msg := wire.NewMsgMerkleBlock(wire.NewBlockHeader(1, &chainhash.Hash{},
+		&chainhash.Hash{}, 0x1f0fffff, 0))
+	serialized, err := merkle.Serialize(msg)
+	if err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+	if _, err := VerifyAnnProof(hex.EncodeToString(serialized)); err == nil {
+		t.Fatalf("VerifyAnnProof: unexpected success with a truncated " +
+			"proof")
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/btcjson"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+)
+
+// submitBlockSyncMgr is a sync manager which only processes the submitted
+// blocks with the chain.
+type submitBlockSyncMgr struct {
+	rpcserverSyncManager
+	chain *blockchain.BlockChain
+}
+
+// SubmitBlock processes the passed block with the chain.
+func (m *submitBlockSyncMgr) SubmitBlock(block *btcutil.Block, flags blockchain.BehaviorFlags) (bool, error) {
+	_, isOrphan, err := m.chain.ProcessBlock(block, flags)
+	return isOrphan, err
+}
+
+// TestSubmitBlock ensures submitblock classifies the submitted blocks and
+// details the rejections.
+func TestSubmitBlock(t *testing.T) {
+	tc, teardown := newTestChain(t, "submitblock", nil)
+	defer teardown()
+	params, chain, g := tc.params, tc.chain, tc.gen
+	s := &rpcServer{cfg: rpcserverConfig{
+		Chain:   chain,
+		SyncMgr: &submitBlockSyncMgr{chain: chain},
+	}}
+
+	if _, err := g.generate(params.GenesisHash, 2, nil); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+
+	// submit submits the block and returns the result of both the
+	// BIP0022 and the verbose modes.
+	serialize := func(block *btcutil.Block) string {
+		var buf bytes.Buffer
+		if err := block.MsgBlock().Serialize(&buf); err != nil {
+			t.Fatalf("Serialize: %v", err)
+		}
+		return hex.EncodeToString(buf.Bytes())
+	}
+	submit := func(block *btcutil.Block, verbose bool) interface{} {
+		t.Helper()
+		cmd := btcjson.NewSubmitBlockCmd(serialize(block),
+			&btcjson.SubmitBlockOptions{Verbose: verbose})
+		result, err := handleSubmitBlock(s, cmd, nil)
+		if err != nil {
+			t.Fatalf("submitblock: %v", err)
+		}
+		return result
+	}
+	check := func(block *btcutil.Block, want interface{},
+		wantStatus string) *btcjson.SubmitBlockResult {
+
+		t.Helper()
+		result := submit(block, false)
+		if result != want {
+			t.Fatalf("got result %v, want %v", result, want)
+		}
+		verbose := submit(block, true).(*btcjson.SubmitBlockResult)
+		if verbose.Hash != block.Hash().String() ||
+			verbose.Status != wantStatus {
+
+			t.Fatalf("got block %s with status %q, want %v with %q",
+				verbose.Hash, verbose.Status, block.Hash(),
+				wantStatus)
+		}
+		return verbose
+	}
+	resolve := func(block *btcutil.Block) *btcutil.Block {
+		t.Helper()
+		msgBlock := block.MsgBlock()
+		if !solveHeader(&msgBlock.Header) {
+			t.Fatal("unable to solve block")
+		}
+		return btcutil.NewBlock(msgBlock)
+	}
+
+	// A block extending the best block is accepted, once.
+	best := chain.BestSnapshot()
+	next, err := g.newBlock(&best.Hash, best.Height+1, nil)
+	if err != nil {
+		t.Fatalf("newBlock: %v", err)
+	}
+	if result := submit(next, false); result != nil {
+		t.Fatalf("got result %v, want nil", result)
+	}
+	check(next, btcjson.SubmitBlockDuplicate, btcjson.SubmitBlockDuplicate)
+	next, err = g.newBlock(next.Hash(), best.Height+2, nil)
+	if err != nil {
+		t.Fatalf("newBlock: %v", err)
+	}
+	if result := submit(next, true).(*btcjson.SubmitBlockResult); result.Status !=
+		btcjson.SubmitBlockAccepted {
+
+		t.Fatalf("got status %q, want accepted", result.Status)
+	}
+
+	// Side chain blocks are not fully validated.
+	side, err := g.newBlock(params.GenesisHash, 1, nil)
+	if err != nil {
+		t.Fatalf("newBlock: %v", err)
+	}
+	check(side, btcjson.SubmitBlockInconclusive,
+		btcjson.SubmitBlockDuplicateInconclusive)
+	check(side, btcjson.SubmitBlockDuplicateInconclusive,
+		btcjson.SubmitBlockDuplicateInconclusive)
+
+	// Neither are orphans.
+	best = chain.BestSnapshot()
+	orphan, err := g.newBlock(&best.Hash, best.Height+1, nil)
+	if err != nil {
+		t.Fatalf("newBlock: %v", err)
+	}
+	orphan.MsgBlock().Header.PrevBlock = chainhash.Hash{0x01}
+	orphan = resolve(orphan)
+	check(orphan, btcjson.SubmitBlockInconclusive,
+		btcjson.SubmitBlockDuplicateInconclusive)
+
+	// The rejections detail the violated rule.
+	bad, err := g.newBlock(&best.Hash, best.Height+1, nil)
+	if err != nil {
+		t.Fatalf("newBlock: %v", err)
+	}
+	wantRoot := bad.MsgBlock().Header.MerkleRoot
+	bad.MsgBlock().Header.MerkleRoot = chainhash.Hash{0x02}
+	bad = resolve(bad)
+	result := check(bad, "rejected: "+submit(bad, true).(*btcjson.SubmitBlockResult).Message,
+		btcjson.SubmitBlockRejected)
+	if result.Rule != "ErrBadMerkleRoot" || result.Reason != "bad-txnmrklroot" ||
+		result.Expected != wantRoot.String() ||
+		result.Actual != bad.MsgBlock().Header.MerkleRoot.String() ||
+		result.TxIndex != nil || result.InputIndex != nil {
+
+		t.Fatalf("unexpected rejection %+v", result)
+	}
+
+	// Blocks which failed validation are known to be invalid.
+	bad, err = g.newBlock(&best.Hash, best.Height+1, nil)
+	if err != nil {
+		t.Fatalf("newBlock: %v", err)
+	}
+	msgBlock := bad.MsgBlock()
+	msgBlock.Transactions[0].TxOut[0].Value++
+	msgBlock.Header.MerkleRoot = msgBlock.Transactions[0].TxHash()
+	bad = resolve(bad)
+	result = submit(bad, true).(*btcjson.SubmitBlockResult)
+	if result.Status != btcjson.SubmitBlockRejected ||
+		result.Rule != "ErrBadCoinbaseValue" ||
+		result.Reason != "bad-cb-value" ||
+		result.Expected == "" || result.Actual == "" {
+
+		t.Fatalf("unexpected rejection %+v", result)
+	}
+	check(bad, btcjson.SubmitBlockDuplicateInvalid,
+		btcjson.SubmitBlockDuplicateInvalid)
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/globalcfg"
+	"github.com/pkt-cash/pktd/database"
+)
+
+// testChain is a chain of the regression test network held in a temporary
+// database, along with a generator of blocks extending it.
+type testChain struct {
+	params *chaincfg.Params
+	db     database.DB
+	chain  *blockchain.BlockChain
+	gen    *forkGenerator
+}
+
+// newTestDB selects the passed network and creates an empty database for it in
+// a temporary directory named after the passed name.  Logging is disabled since
+// the log rotator is not initialized in tests.  The returned function closes
+// and removes the database and restores the logging and the network, and must
+// be called once the test is done.
+func newTestDB(t *testing.T, name string, params *chaincfg.Params) (database.DB, func()) {
+	t.Helper()
+
+	var teardowns []func()
+	teardown := func() {
+		for i := len(teardowns) - 1; i >= 0; i-- {
+			teardowns[i]()
+		}
+	}
+
+	setLogLevels("off")
+	teardowns = append(teardowns, func() { setLogLevels(defaultLogLevel) })
+
+	if !globalcfg.SelectConfig(params.GlobalConf) {
+		teardown()
+		t.Fatal("globalcfg.SelectConfig() called twice")
+	}
+	teardowns = append(teardowns, func() { globalcfg.RemoveConfig() })
+
+	dir, err := ioutil.TempDir("", "pktd-"+name)
+	if err != nil {
+		teardown()
+		t.Fatalf("TempDir: %v", err)
+	}
+	teardowns = append(teardowns, func() { os.RemoveAll(dir) })
+
+	db, err := database.Create("ffldb", filepath.Join(dir, "db"), params.Net)
+	if err != nil {
+		teardown()
+		t.Fatalf("database.Create: %v", err)
+	}
+	teardowns = append(teardowns, func() { db.Close() })
+
+	return db, teardown
+}
+
+// newTestGenerator returns a generator of blocks which processes them with the
+// passed chain, rejecting the ones which end up orphaned.
+func newTestGenerator(chain *blockchain.BlockChain,
+	params *chaincfg.Params) *forkGenerator {
+
+	return &forkGenerator{
+		chain:  chain,
+		params: params,
+		submit: func(block *btcutil.Block) error {
+			_, isOrphan, err := chain.ProcessBlock(block, blockchain.BFNone)
+			if err == nil && isOrphan {
+				err = errors.New("orphan block")
+			}
+			return err
+		},
+	}
+}
+
+// newTestChain returns a chain of the regression test network holding only
+// the genesis block, in a database created by newTestDB.  The configure
+// function, when not nil, is called with the configuration of the chain before
+// it is created, so indexes or a block recorder can be set up.  The returned
+// function tears down the database and must be called once the test is done.
+func newTestChain(t *testing.T, name string,
+	configure func(*blockchain.Config)) (*testChain, func()) {
+
+	t.Helper()
+
+	params := &chaincfg.RegressionNetParams
+	db, teardown := newTestDB(t, name, params)
+	config := &blockchain.Config{
+		DB:          db,
+		ChainParams: params,
+		TimeSource:  blockchain.NewMedianTime(),
+	}
+	if configure != nil {
+		configure(config)
+	}
+	chain, err := blockchain.New(config)
+	if err != nil {
+		teardown()
+		t.Fatalf("blockchain.New: %v", err)
+	}
+	return &testChain{
+		params: params,
+		db:     db,
+		chain:  chain,
+		gen:    newTestGenerator(chain, params),
+	}, teardown
+}
@@ -0,0 +1,308 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txauthor
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+var (
+	// ErrNoOutputs is returned when a transaction is built without any
+	// output to pay.
+	ErrNoOutputs = errors.New("transaction has no outputs")
+
+	// ErrUnsupportedInput is returned when the size of the signature of an
+	// input cannot be estimated from its script.
+	ErrUnsupportedInput = errors.New("unsupported input script")
+)
+
+// InsufficientFundsError is returned when the inputs do not cover the outputs
+// and the fee of a transaction.
+type InsufficientFundsError struct {
+	// Needed is the amount of the outputs and the fee.
+	Needed btcutil.Amount
+
+	// Available is the amount of the inputs.
+	Available btcutil.Amount
+}
+
+// Error returns a description of the missing funds.
+func (e *InsufficientFundsError) Error() string {
+	return fmt.Sprintf("insufficient funds: %v available, %v needed",
+		e.Available, e.Needed)
+}
+
+// Input is an output which may be spent by a built transaction.
+type Input struct {
+	// OutPoint is the output being spent.
+	OutPoint wire.OutPoint
+
+	// Amount is the value of the output.
+	Amount btcutil.Amount
+
+	// PkScript is the script the output pays to.
+	PkScript []byte
+
+	// RedeemScript is the redeem script of a pay-to-script-hash output or
+	// the witness script of a pay-to-witness-script-hash output.
+	RedeemScript []byte
+}
+
+// InputSource returns inputs whose total amount is at least the passed target,
+// along with that total.  It may return less when no more inputs are
+// available.  The inputs returned for a larger target must include those
+// returned for a smaller one.
+type InputSource func(target btcutil.Amount) (btcutil.Amount, []*Input, error)
+
+// SelectInputs returns an InputSource selecting the passed inputs in order
+// until they cover the target.
+func SelectInputs(inputs []*Input) InputSource {
+	return func(target btcutil.Amount) (btcutil.Amount, []*Input, error) {
+		var total btcutil.Amount
+		for i, input := range inputs {
+			if total >= target {
+				return total, inputs[:i], nil
+			}
+			total += input.Amount
+		}
+		return total, inputs, nil
+	}
+}
+
+// Builder builds unsigned transactions paying a set of outputs.
+type Builder struct {
+	// Outputs are the outputs to pay.
+	Outputs []*wire.TxOut
+
+	// FeeRate is the fee paid per kilobyte of virtual size.
+	FeeRate btcutil.Amount
+
+	// DustRelayFee is the relay fee used to tell whether the change is
+	// dust, the fee rate when zero.
+	DustRelayFee btcutil.Amount
+
+	// ChangeScript is the script the change is paid to.  The change is
+	// folded into the fee when it is nil.
+	ChangeScript []byte
+
+	// SortBIP69 sorts the inputs and outputs as described by BIP 0069
+	// instead of keeping the order of the outputs.
+	SortBIP69 bool
+
+	// Version is the version of the transaction, wire.TxVersion when zero.
+	Version int32
+
+	// LockTime is the lock time of the transaction.  It overrides the
+	// lock time set to discourage fee sniping when non-zero.
+	LockTime uint32
+
+	// BestHeight is the height of the best chain.  When set and LockTime
+	// is zero, the lock time is set to this height so the transaction can
+	// only be mined on top of the best chain, which discourages miners
+	// from reorganizing it to take its fee.  The lock time is moved back
+	// to a random earlier height one time out of ten so the transactions
+	// delayed before being broadcast do not stand out.
+	BestHeight int32
+
+	// Replaceable signals that the transaction may be replaced as
+	// described by BIP 0125.
+	Replaceable bool
+}
+
+// AuthoredTx is an unsigned transaction built by a Builder.
+type AuthoredTx struct {
+	// Tx is the unsigned transaction.
+	Tx *wire.MsgTx
+
+	// Inputs are the inputs spent by the transaction, in the order of its
+	// inputs.
+	Inputs []*Input
+
+	// ChangeIndex is the index of the change output, -1 when there is no
+	// change.
+	ChangeIndex int
+
+	// Fee is the fee paid by the transaction.
+	Fee btcutil.Amount
+
+	// VirtualSize is the estimated virtual size of the signed transaction.
+	VirtualSize int
+}
+
+// randIntn returns a uniform random number in [0, n).  It is a variable so
+// the tests can make it deterministic.
+var randIntn = func(n int) int {
+	r, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0
+	}
+	return int(r.Int64())
+}
+
+// lockTime returns the lock time of the transactions built by the builder.
+func (b *Builder) lockTime() uint32 {
+	if b.LockTime != 0 || b.BestHeight <= 0 {
+		return b.LockTime
+	}
+	height := b.BestHeight
+	if randIntn(10) == 0 {
+		height -= int32(randIntn(100))
+		if height < 0 {
+			height = 0
+		}
+	}
+	return uint32(height)
+}
+
+// sequence returns the sequence number of the inputs of a transaction with the
+// passed lock time.
+func (b *Builder) sequence(lockTime uint32) uint32 {
+	switch {
+	case b.Replaceable:
+		return wire.MaxTxInSequenceNum - 2
+
+	// The lock time is only enforced when an input is not final.
+	case lockTime != 0:
+		return wire.MaxTxInSequenceNum - 1
+	}
+	return wire.MaxTxInSequenceNum
+}
+
+// feeForSize returns the fee of a transaction of the passed virtual size.
+func feeForSize(feeRate btcutil.Amount, vsize int) btcutil.Amount {
+	return (feeRate*btcutil.Amount(vsize) + 999) / 1000
+}
+
+// isDust returns whether the passed output is dust at the passed relay fee,
+// that is whether spending it costs more than a third of its value.  This
+// matches the relay policy of the mempool.
+func isDust(txOut *wire.TxOut, relayFee btcutil.Amount) bool {
+	if txscript.IsUnspendable(txOut.PkScript) {
+		return true
+	}
+	totalSize := txOut.SerializeSize() + 41
+	if txscript.IsWitnessProgram(txOut.PkScript) {
+		totalSize += 107 / blockchain.WitnessScaleFactor
+	} else {
+		totalSize += 107
+	}
+	return txOut.Value*1000/(3*int64(totalSize)) < int64(relayFee)
+}
+
+// unsignedTx returns the unsigned transaction spending the passed inputs and
+// paying the outputs of the builder with the passed lock time, along with the
+// estimated virtual size of the signed transaction.
+func (b *Builder) unsignedTx(lockTime uint32, inputs []*Input,
+	sizes []InputSize, change *wire.TxOut) (*wire.MsgTx, int) {
+
+	version := b.Version
+	if version == 0 {
+		version = wire.TxVersion
+	}
+	tx := wire.NewMsgTx(version)
+	tx.LockTime = lockTime
+	sequence := b.sequence(lockTime)
+	for _, input := range inputs {
+		tx.AddTxIn(&wire.TxIn{
+			PreviousOutPoint: input.OutPoint,
+			Sequence:         sequence,
+		})
+	}
+	for _, txOut := range b.Outputs {
+		tx.AddTxOut(txOut)
+	}
+	if change != nil {
+		tx.AddTxOut(change)
+	}
+	return tx, EstimateVirtualSize(tx, sizes)
+}
+
+// Build returns an unsigned transaction paying the outputs of the builder from
+// inputs fetched from the passed source, and the change, if any, to the change
+// script.  The fee paid is computed from the estimated virtual size of the
+// signed transaction, along with the change when it would be dust.
+func (b *Builder) Build(fetchInputs InputSource) (*AuthoredTx, error) {
+	if len(b.Outputs) == 0 {
+		return nil, ErrNoOutputs
+	}
+	var target btcutil.Amount
+	for _, txOut := range b.Outputs {
+		target += btcutil.Amount(txOut.Value)
+	}
+	dustRelayFee := b.DustRelayFee
+	if dustRelayFee == 0 {
+		dustRelayFee = b.FeeRate
+	}
+	lockTime := b.lockTime()
+
+	// Fetch inputs until they cover the fee of the transaction spending
+	// them, which grows with each input.
+	_, vsize := b.unsignedTx(lockTime, nil, nil, nil)
+	fee := feeForSize(b.FeeRate, vsize)
+	for {
+		total, inputs, err := fetchInputs(target + fee)
+		if err != nil {
+			return nil, err
+		}
+		if total < target+fee {
+			return nil, &InsufficientFundsError{
+				Needed:    target + fee,
+				Available: total,
+			}
+		}
+
+		sizes := make([]InputSize, len(inputs))
+		for i, input := range inputs {
+			sizes[i], err = EstimateInputSize(input.PkScript,
+				input.RedeemScript)
+			if err != nil {
+				return nil, err
+			}
+		}
+		tx, vsize := b.unsignedTx(lockTime, inputs, sizes, nil)
+		fee = feeForSize(b.FeeRate, vsize)
+		if total < target+fee {
+			continue
+		}
+
+		// Add the change output when it is worth spending, otherwise
+		// fold the change into the fee.
+		changeIndex := -1
+		if b.ChangeScript != nil {
+			change := &wire.TxOut{PkScript: b.ChangeScript}
+			changeTx, changeVSize := b.unsignedTx(lockTime, inputs,
+				sizes, change)
+			changeFee := feeForSize(b.FeeRate, changeVSize)
+			change.Value = int64(total - target - changeFee)
+			if change.Value > 0 && !isDust(change, dustRelayFee) {
+				tx, vsize, fee = changeTx, changeVSize, changeFee
+				changeIndex = len(tx.TxOut) - 1
+			}
+		}
+		if changeIndex < 0 {
+			fee = total - target
+		}
+
+		authored := &AuthoredTx{
+			Tx:          tx,
+			Inputs:      append([]*Input(nil), inputs...),
+			ChangeIndex: changeIndex,
+			Fee:         fee,
+			VirtualSize: vsize,
+		}
+		if b.SortBIP69 {
+			authored.sortBIP69()
+		}
+		return authored, nil
+	}
+}
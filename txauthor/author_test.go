@@ -0,0 +1,290 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txauthor
+
+import (
+	"testing"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// testP2WPKH returns a pay-to-witness-pubkey-hash script paying to a hash made
+// of the passed byte.
+func testP2WPKH(b byte) []byte {
+	script := []byte{0x00, 0x14}
+	for i := 0; i < 20; i++ {
+		script = append(script, b)
+	}
+	return script
+}
+
+// testInputs returns pay-to-witness-pubkey-hash inputs of the passed amounts.
+func testInputs(amounts ...btcutil.Amount) []*Input {
+	inputs := make([]*Input, len(amounts))
+	for i, amount := range amounts {
+		inputs[i] = &Input{
+			OutPoint: wire.OutPoint{
+				Hash:  chainhash.Hash{byte(len(amounts) - i)},
+				Index: uint32(i),
+			},
+			Amount:   amount,
+			PkScript: testP2WPKH(0xaa),
+		}
+	}
+	return inputs
+}
+
+// TestBuild ensures transactions pay the outputs and exactly the fee of their
+// estimated size, adding change only when it is not dust.
+func TestBuild(t *testing.T) {
+	const feeRate = 1000
+	change := testP2WPKH(0xcc)
+
+	tests := []struct {
+		name      string
+		outputs   []btcutil.Amount
+		inputs    []*Input
+		numInputs int
+		hasChange bool
+		err       bool
+	}{
+		{
+			name:      "single input with change",
+			outputs:   []btcutil.Amount{50000},
+			inputs:    testInputs(100000, 100000),
+			numInputs: 1,
+			hasChange: true,
+		},
+		{
+			name:      "second input needed for the fee",
+			outputs:   []btcutil.Amount{99950},
+			inputs:    testInputs(100000, 100000),
+			numInputs: 2,
+			hasChange: true,
+		},
+		{
+			name:      "dust change folded into the fee",
+			outputs:   []btcutil.Amount{99700},
+			inputs:    testInputs(100000),
+			numInputs: 1,
+			hasChange: false,
+		},
+		{
+			name:    "insufficient funds",
+			outputs: []btcutil.Amount{150000},
+			inputs:  testInputs(100000),
+			err:     true,
+		},
+	}
+	for _, test := range tests {
+		b := &Builder{FeeRate: feeRate, ChangeScript: change}
+		var target btcutil.Amount
+		for i, amount := range test.outputs {
+			b.Outputs = append(b.Outputs,
+				wire.NewTxOut(int64(amount), testP2WPKH(byte(i))))
+			target += amount
+		}
+
+		authored, err := b.Build(SelectInputs(test.inputs))
+		if test.err {
+			if _, ok := err.(*InsufficientFundsError); !ok {
+				t.Errorf("%s: unexpected error %v", test.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: Build: %v", test.name, err)
+			continue
+		}
+		tx := authored.Tx
+		if len(tx.TxIn) != test.numInputs ||
+			len(authored.Inputs) != test.numInputs {
+
+			t.Errorf("%s: got %d inputs, want %d", test.name,
+				len(tx.TxIn), test.numInputs)
+			continue
+		}
+		if (authored.ChangeIndex >= 0) != test.hasChange {
+			t.Errorf("%s: unexpected change index %d", test.name,
+				authored.ChangeIndex)
+			continue
+		}
+
+		// The fee is the difference of the inputs and the outputs, and
+		// pays the fee rate when there is change.
+		var in, out btcutil.Amount
+		for _, input := range authored.Inputs {
+			in += input.Amount
+		}
+		for _, txOut := range tx.TxOut {
+			out += btcutil.Amount(txOut.Value)
+		}
+		if in-out != authored.Fee {
+			t.Errorf("%s: fee %v, inputs %v, outputs %v", test.name,
+				authored.Fee, in, out)
+		}
+		minFee := feeForSize(feeRate, authored.VirtualSize)
+		if authored.Fee < minFee ||
+			(test.hasChange && authored.Fee != minFee) {
+
+			t.Errorf("%s: fee %v for virtual size %d", test.name,
+				authored.Fee, authored.VirtualSize)
+		}
+	}
+}
+
+// TestBuildBIP69 ensures sorted transactions keep their inputs and change
+// index in line with the sorted order, and unsorted ones keep the order of the
+// outputs with the change last.
+func TestBuildBIP69(t *testing.T) {
+	b := &Builder{
+		Outputs: []*wire.TxOut{
+			wire.NewTxOut(30000, testP2WPKH(2)),
+			wire.NewTxOut(10000, testP2WPKH(3)),
+			wire.NewTxOut(10000, testP2WPKH(1)),
+		},
+		FeeRate:      1000,
+		ChangeScript: testP2WPKH(0xcc),
+	}
+	inputs := testInputs(50000, 50000, 50000)
+
+	authored, err := b.Build(SelectInputs(inputs))
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	for i, txOut := range b.Outputs {
+		if authored.Tx.TxOut[i] != txOut {
+			t.Fatalf("output %d was reordered", i)
+		}
+	}
+	if authored.ChangeIndex != len(b.Outputs) {
+		t.Fatalf("unexpected change index %d", authored.ChangeIndex)
+	}
+
+	b.SortBIP69 = true
+	authored, err = b.Build(SelectInputs(inputs))
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	tx := authored.Tx
+	for i := 1; i < len(tx.TxIn); i++ {
+		if lessOutPoint(&tx.TxIn[i].PreviousOutPoint,
+			&tx.TxIn[i-1].PreviousOutPoint) {
+
+			t.Fatalf("inputs are not sorted")
+		}
+	}
+	for i, txIn := range tx.TxIn {
+		if authored.Inputs[i].OutPoint != txIn.PreviousOutPoint {
+			t.Fatalf("input %d does not match its transaction input", i)
+		}
+	}
+	if inputs[0].OutPoint.Index != 0 {
+		t.Fatalf("the inputs of the source were reordered")
+	}
+	for i := 1; i < len(tx.TxOut); i++ {
+		if lessTxOut(tx.TxOut[i], tx.TxOut[i-1]) {
+			t.Fatalf("outputs are not sorted")
+		}
+	}
+	if tx.TxOut[0].PkScript[2] != 1 || tx.TxOut[1].PkScript[2] != 3 {
+		t.Fatalf("outputs of equal amounts are not sorted by script")
+	}
+	changeOut := tx.TxOut[authored.ChangeIndex]
+	if changeOut.PkScript[2] != 0xcc {
+		t.Fatalf("change index %d does not point to the change",
+			authored.ChangeIndex)
+	}
+}
+
+// TestBuildLockTime ensures the lock time discourages fee sniping unless it is
+// overridden, and the sequence numbers enforce it and signal replaceability.
+func TestBuildLockTime(t *testing.T) {
+	defer func(f func(int) int) { randIntn = f }(randIntn)
+
+	tests := []struct {
+		name        string
+		lockTime    uint32
+		bestHeight  int32
+		replaceable bool
+		rand        []int
+		wantLock    uint32
+		wantSeq     uint32
+	}{
+		{
+			name:     "no height",
+			wantLock: 0,
+			wantSeq:  wire.MaxTxInSequenceNum,
+		},
+		{
+			name:       "best height",
+			bestHeight: 1000,
+			rand:       []int{1},
+			wantLock:   1000,
+			wantSeq:    wire.MaxTxInSequenceNum - 1,
+		},
+		{
+			name:       "random earlier height",
+			bestHeight: 1000,
+			rand:       []int{0, 42},
+			wantLock:   958,
+			wantSeq:    wire.MaxTxInSequenceNum - 1,
+		},
+		{
+			name:       "earlier height not below zero",
+			bestHeight: 10,
+			rand:       []int{0, 42},
+			wantLock:   0,
+			wantSeq:    wire.MaxTxInSequenceNum,
+		},
+		{
+			name:       "explicit lock time",
+			lockTime:   500,
+			bestHeight: 1000,
+			wantLock:   500,
+			wantSeq:    wire.MaxTxInSequenceNum - 1,
+		},
+		{
+			name:        "replaceable",
+			replaceable: true,
+			wantLock:    0,
+			wantSeq:     wire.MaxTxInSequenceNum - 2,
+		},
+	}
+	for _, test := range tests {
+		rnd := test.rand
+		randIntn = func(n int) int {
+			if len(rnd) == 0 {
+				t.Fatalf("%s: unexpected random number", test.name)
+			}
+			r := rnd[0]
+			rnd = rnd[1:]
+			return r
+		}
+		b := &Builder{
+			Outputs:     []*wire.TxOut{wire.NewTxOut(10000, testP2WPKH(1))},
+			FeeRate:     1000,
+			LockTime:    test.lockTime,
+			BestHeight:  test.bestHeight,
+			Replaceable: test.replaceable,
+		}
+		authored, err := b.Build(SelectInputs(testInputs(50000, 50000)))
+		if err != nil {
+			t.Fatalf("%s: Build: %v", test.name, err)
+		}
+		if authored.Tx.LockTime != test.wantLock {
+			t.Errorf("%s: lock time %d, want %d", test.name,
+				authored.Tx.LockTime, test.wantLock)
+		}
+		for _, txIn := range authored.Tx.TxIn {
+			if txIn.Sequence != test.wantSeq {
+				t.Errorf("%s: sequence %x, want %x", test.name,
+					txIn.Sequence, test.wantSeq)
+			}
+		}
+	}
+}
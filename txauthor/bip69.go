@@ -0,0 +1,72 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txauthor
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// lessOutPoint returns whether the passed outpoint sorts before the other one
+// as described by BIP 0069: by transaction hash in its displayed byte order,
+// then by output index.
+func lessOutPoint(a, b *wire.OutPoint) bool {
+	if a.Hash != b.Hash {
+		for i := chainhash.HashSize - 1; i >= 0; i-- {
+			if a.Hash[i] != b.Hash[i] {
+				return a.Hash[i] < b.Hash[i]
+			}
+		}
+	}
+	return a.Index < b.Index
+}
+
+// lessTxOut returns whether the passed output sorts before the other one as
+// described by BIP 0069: by amount, then by script.
+func lessTxOut(a, b *wire.TxOut) bool {
+	if a.Value != b.Value {
+		return a.Value < b.Value
+	}
+	return bytes.Compare(a.PkScript, b.PkScript) < 0
+}
+
+// SortBIP69 sorts the inputs and outputs of the passed transaction as described
+// by BIP 0069.
+func SortBIP69(tx *wire.MsgTx) {
+	sort.SliceStable(tx.TxIn, func(i, j int) bool {
+		return lessOutPoint(&tx.TxIn[i].PreviousOutPoint,
+			&tx.TxIn[j].PreviousOutPoint)
+	})
+	sort.SliceStable(tx.TxOut, func(i, j int) bool {
+		return lessTxOut(tx.TxOut[i], tx.TxOut[j])
+	})
+}
+
+// sortBIP69 sorts the transaction as described by BIP 0069 while keeping the
+// inputs and the change index in line with it.
+func (a *AuthoredTx) sortBIP69() {
+	var change *wire.TxOut
+	if a.ChangeIndex >= 0 {
+		change = a.Tx.TxOut[a.ChangeIndex]
+	}
+	inputs := make(map[wire.OutPoint]*Input, len(a.Inputs))
+	for _, input := range a.Inputs {
+		inputs[input.OutPoint] = input
+	}
+
+	SortBIP69(a.Tx)
+
+	for i, txIn := range a.Tx.TxIn {
+		a.Inputs[i] = inputs[txIn.PreviousOutPoint]
+	}
+	for i, txOut := range a.Tx.TxOut {
+		if txOut == change {
+			a.ChangeIndex = i
+		}
+	}
+}
@@ -0,0 +1,32 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package txauthor builds unsigned transactions paying a set of outputs from the
+inputs of a wallet, paying exactly the fee of the signed transaction.
+
+A Builder is given the outputs to pay, a fee rate and a change script, and asks
+an InputSource for inputs until they cover the outputs and the fee.  The fee is
+computed from the virtual size the transaction will have once signed, which is
+estimated from the script of each input: pay-to-pubkey, pay-to-pubkey-hash,
+bare multisig, pay-to-witness-pubkey-hash and pay-to-script-hash or
+pay-to-witness-script-hash wrapping a multisig or witness key hash script may
+be mixed in a transaction.  Signatures are assumed to have their maximum
+length, so the fee paid is never lower than the fee rate.
+
+As more inputs raise the fee, the inputs are fetched again until they cover it.
+A change output is only added when it is not dust, otherwise the change is
+folded into the fee.  The outputs are kept in the order they are given, with
+the change last, unless the transaction is sorted as described by BIP 0069.
+
+Given the height of the best chain, the lock time of the transaction is set to
+that height, occasionally moved back by a random number of blocks, so it cannot
+be mined in a reorganization of the best chain, as done by other wallets.  An
+explicit lock time overrides it.  The inputs signal replaceability as described
+by BIP 0125 when requested.
+
+The package does not depend on a wallet or a node so it can be used by both and
+by tools building transactions offline.
+*/
+package txauthor
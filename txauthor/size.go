@@ -0,0 +1,154 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txauthor
+
+import (
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+const (
+	// sigPushSize is the size of the push of a signature of the maximum
+	// length: the push opcode, a 72 bytes DER signature and the hash type.
+	sigPushSize = 1 + 72 + 1
+
+	// pubKeyPushSize is the size of the push of a compressed public key.
+	pubKeyPushSize = 1 + 33
+
+	// witnessPubKeyHashSize is the size of the witness of a
+	// pay-to-witness-pubkey-hash input: the number of items, the
+	// signature and the public key.
+	witnessPubKeyHashSize = 1 + sigPushSize + pubKeyPushSize
+)
+
+// InputSize describes the size of the signature script and witness of a
+// signed input.
+type InputSize struct {
+	// SigScript is the size of the signature script.
+	SigScript int
+
+	// Witness is the serialized size of the witness, including the number
+	// of items, or zero when the input has no witness.
+	Witness int
+}
+
+// pushSize returns the size of a canonical push of data of the passed length.
+func pushSize(n int) int {
+	switch {
+	case n < txscript.OP_PUSHDATA1:
+		return 1 + n
+	case n <= 0xff:
+		return 2 + n
+	case n <= 0xffff:
+		return 3 + n
+	}
+	return 5 + n
+}
+
+// multiSigSize returns the size of the signatures of a multisig script,
+// including the extra item consumed by OP_CHECKMULTISIG.
+func multiSigSize(script []byte) (int, int, error) {
+	_, numSigs, err := txscript.CalcMultiSigStats(script)
+	if err != nil {
+		return 0, 0, err
+	}
+	return 1 + numSigs*sigPushSize, numSigs, nil
+}
+
+// EstimateInputSize returns the largest sizes of the signature script and
+// witness of an input spending an output paying to the passed script.  The
+// redeem script of a pay-to-script-hash output, or the witness script of a
+// pay-to-witness-script-hash output, must be passed as it tells how the output
+// is spent.  Public keys are assumed to be compressed.
+func EstimateInputSize(pkScript, redeemScript []byte) (InputSize, error) {
+	switch txscript.GetScriptClass(pkScript) {
+	case txscript.PubKeyTy:
+		return InputSize{SigScript: sigPushSize}, nil
+
+	case txscript.PubKeyHashTy:
+		return InputSize{SigScript: sigPushSize + pubKeyPushSize}, nil
+
+	case txscript.MultiSigTy:
+		size, _, err := multiSigSize(pkScript)
+		if err != nil {
+			return InputSize{}, err
+		}
+		return InputSize{SigScript: size}, nil
+
+	case txscript.WitnessV0PubKeyHashTy:
+		return InputSize{Witness: witnessPubKeyHashSize}, nil
+
+	case txscript.ScriptHashTy:
+		switch txscript.GetScriptClass(redeemScript) {
+		case txscript.WitnessV0PubKeyHashTy:
+			return InputSize{
+				SigScript: pushSize(len(redeemScript)),
+				Witness:   witnessPubKeyHashSize,
+			}, nil
+
+		case txscript.MultiSigTy:
+			size, _, err := multiSigSize(redeemScript)
+			if err != nil {
+				return InputSize{}, err
+			}
+			return InputSize{
+				SigScript: size + pushSize(len(redeemScript)),
+			}, nil
+		}
+
+	case txscript.WitnessV0ScriptHashTy:
+		if txscript.GetScriptClass(redeemScript) == txscript.MultiSigTy {
+			size, numSigs, err := multiSigSize(redeemScript)
+			if err != nil {
+				return InputSize{}, err
+			}
+
+			// The signatures and the dummy item are pushed as witness
+			// items, followed by the witness script.
+			numItems := uint64(numSigs + 2)
+			witnessScriptSize := wire.VarIntSerializeSize(
+				uint64(len(redeemScript))) + len(redeemScript)
+			return InputSize{
+				Witness: wire.VarIntSerializeSize(numItems) + size +
+					witnessScriptSize,
+			}, nil
+		}
+
+	}
+
+	return InputSize{}, ErrUnsupportedInput
+}
+
+// EstimateVirtualSize returns the virtual size of the passed unsigned
+// transaction once its inputs are signed with the passed sizes.
+func EstimateVirtualSize(tx *wire.MsgTx, sizes []InputSize) int {
+	// Replace the size of the signature scripts of the unsigned inputs
+	// with the size they will have once signed.
+	baseSize := tx.SerializeSizeStripped()
+	witnessSize := 0
+	hasWitness := false
+	for i, size := range sizes {
+		sigScript := tx.TxIn[i].SignatureScript
+		baseSize += wire.VarIntSerializeSize(uint64(size.SigScript)) +
+			size.SigScript - wire.VarIntSerializeSize(
+			uint64(len(sigScript))) - len(sigScript)
+		if size.Witness == 0 {
+			// An input without witness serializes an empty stack.
+			witnessSize++
+			continue
+		}
+		hasWitness = true
+		witnessSize += size.Witness
+	}
+
+	weight := baseSize * blockchain.WitnessScaleFactor
+	if hasWitness {
+		// The marker and flag bytes and the witnesses are not scaled.
+		weight += 2 + witnessSize
+	}
+	return (weight + blockchain.WitnessScaleFactor - 1) /
+		blockchain.WitnessScaleFactor
+}
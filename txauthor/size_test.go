@@ -0,0 +1,186 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txauthor
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/btcec"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// mustScript returns the script built by the passed builder.
+func mustScript(t *testing.T, builder *txscript.ScriptBuilder) []byte {
+	t.Helper()
+	script, err := builder.Script()
+	if err != nil {
+		t.Fatalf("Script: %v", err)
+	}
+	return script
+}
+
+// TestEstimateVirtualSize ensures the estimated virtual size of transactions
+// spending mixed input types is not lower than, and close to, their virtual
+// size once signed.
+func TestEstimateVirtualSize(t *testing.T) {
+	key, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatalf("NewPrivateKey: %v", err)
+	}
+	pubKey := key.PubKey().SerializeCompressed()
+	keyHash := btcutil.Hash160(pubKey)
+
+	p2pk := mustScript(t, txscript.NewScriptBuilder().AddData(pubKey).
+		AddOp(txscript.OP_CHECKSIG))
+	p2pkh := mustScript(t, txscript.NewScriptBuilder().
+		AddOp(txscript.OP_DUP).AddOp(txscript.OP_HASH160).
+		AddData(keyHash).AddOp(txscript.OP_EQUALVERIFY).
+		AddOp(txscript.OP_CHECKSIG))
+	p2wpkh := mustScript(t, txscript.NewScriptBuilder().
+		AddOp(txscript.OP_0).AddData(keyHash))
+	p2shP2wpkh := mustScript(t, txscript.NewScriptBuilder().
+		AddOp(txscript.OP_HASH160).AddData(btcutil.Hash160(p2wpkh)).
+		AddOp(txscript.OP_EQUAL))
+
+	inputs := []*Input{
+		{PkScript: p2pk, Amount: 1e6},
+		{PkScript: p2pkh, Amount: 2e6},
+		{PkScript: p2wpkh, Amount: 3e6},
+		{PkScript: p2shP2wpkh, RedeemScript: p2wpkh, Amount: 4e6},
+	}
+	tests := []struct {
+		name   string
+		inputs []*Input
+	}{
+		{"pubkey", inputs[:1]},
+		{"pubkey hash", inputs[1:2]},
+		{"witness pubkey hash", inputs[2:3]},
+		{"nested witness pubkey hash", inputs[3:4]},
+		{"mixed", inputs},
+	}
+	for _, test := range tests {
+		tx := wire.NewMsgTx(wire.TxVersion)
+		sizes := make([]InputSize, len(test.inputs))
+		for i, input := range test.inputs {
+			tx.AddTxIn(&wire.TxIn{
+				PreviousOutPoint: wire.OutPoint{Index: uint32(i)},
+				Sequence:         wire.MaxTxInSequenceNum,
+			})
+			sizes[i], err = EstimateInputSize(input.PkScript,
+				input.RedeemScript)
+			if err != nil {
+				t.Fatalf("%s: EstimateInputSize: %v", test.name, err)
+			}
+		}
+		tx.AddTxOut(wire.NewTxOut(1e6, p2wpkh))
+		estimate := EstimateVirtualSize(tx, sizes)
+
+		// Sign the inputs and measure the signed transaction.
+		sigHashes := txscript.NewTxSigHashes(tx)
+		for i, input := range test.inputs {
+			txIn := tx.TxIn[i]
+			switch {
+			case input.RedeemScript != nil:
+				txIn.Witness, err = txscript.WitnessSignature(tx,
+					sigHashes, i, int64(input.Amount),
+					p2pkh, txscript.SigHashAll, key, true)
+				txIn.SignatureScript = mustScript(t,
+					txscript.NewScriptBuilder().
+						AddData(input.RedeemScript))
+			case bytes.Equal(input.PkScript, p2wpkh):
+				txIn.Witness, err = txscript.WitnessSignature(tx,
+					sigHashes, i, int64(input.Amount),
+					p2pkh, txscript.SigHashAll, key, true)
+			case bytes.Equal(input.PkScript, p2pk):
+				var sig []byte
+				sig, err = txscript.RawTxInSignature(tx, i,
+					input.PkScript, txscript.SigHashAll, key)
+				txIn.SignatureScript = mustScript(t,
+					txscript.NewScriptBuilder().AddData(sig))
+			default:
+				txIn.SignatureScript, err = txscript.SignatureScript(
+					tx, i, input.PkScript, txscript.SigHashAll,
+					key, true)
+			}
+			if err != nil {
+				t.Fatalf("%s: signing input %d: %v", test.name, i, err)
+			}
+		}
+		weight := tx.SerializeSizeStripped()*3 + tx.SerializeSize()
+		actual := (weight + 3) / 4
+
+		// Signatures may be up to two bytes shorter than the maximum.
+		if estimate < actual || estimate > actual+2*len(test.inputs) {
+			t.Errorf("%s: estimated virtual size %d, signed virtual "+
+				"size %d", test.name, estimate, actual)
+		}
+	}
+}
+
+// TestEstimateInputSize ensures the sizes of inputs spending multisig scripts
+// are estimated as expected and unsupported scripts are rejected.
+func TestEstimateInputSize(t *testing.T) {
+	pubKey := make([]byte, 33)
+	pubKey[0] = 0x02
+	multiSig := mustScript(t, txscript.NewScriptBuilder().
+		AddOp(txscript.OP_2).AddData(pubKey).AddData(pubKey).
+		AddData(pubKey).AddOp(txscript.OP_3).
+		AddOp(txscript.OP_CHECKMULTISIG))
+	p2sh := mustScript(t, txscript.NewScriptBuilder().
+		AddOp(txscript.OP_HASH160).AddData(make([]byte, 20)).
+		AddOp(txscript.OP_EQUAL))
+	p2wsh := mustScript(t, txscript.NewScriptBuilder().
+		AddOp(txscript.OP_0).AddData(make([]byte, 32)))
+
+	tests := []struct {
+		name         string
+		pkScript     []byte
+		redeemScript []byte
+		size         InputSize
+		err          error
+	}{
+		{
+			name:     "bare multisig",
+			pkScript: multiSig,
+			size:     InputSize{SigScript: 1 + 2*sigPushSize},
+		},
+		{
+			name:         "multisig script hash",
+			pkScript:     p2sh,
+			redeemScript: multiSig,
+			size: InputSize{
+				SigScript: 1 + 2*sigPushSize + 2 + len(multiSig),
+			},
+		},
+		{
+			name:         "multisig witness script hash",
+			pkScript:     p2wsh,
+			redeemScript: multiSig,
+			size: InputSize{
+				Witness: 1 + 1 + 2*sigPushSize + 1 + len(multiSig),
+			},
+		},
+		{
+			name:     "script hash without redeem script",
+			pkScript: p2sh,
+			err:      ErrUnsupportedInput,
+		},
+		{
+			name:     "nulldata",
+			pkScript: []byte{txscript.OP_RETURN},
+			err:      ErrUnsupportedInput,
+		},
+	}
+	for _, test := range tests {
+		size, err := EstimateInputSize(test.pkScript, test.redeemScript)
+		if err != test.err || size != test.size {
+			t.Errorf("%s: got %+v (%v), want %+v (%v)", test.name,
+				size, err, test.size, test.err)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/blockchain/indexers"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+)
+
+// TestPartialTxIndex ensures a transaction index with a start height only
+// indexes the blocks from that height, both while catching up and while
+// blocks are connected, and that its start height cannot be changed.
+func TestPartialTxIndex(t *testing.T) {
+	params := &chaincfg.RegressionNetParams
+	db, teardown := newTestDB(t, "txindex", params)
+	defer teardown()
+
+	newChain := func(txIndex *indexers.TxIndex) (*blockchain.BlockChain, error) {
+		var indexManager blockchain.IndexManager
+		if txIndex != nil {
+			indexManager = indexers.NewManager(db,
+				[]indexers.Indexer{txIndex})
+		}
+		return blockchain.New(&blockchain.Config{
+			DB:           db,
+			ChainParams:  params,
+			TimeSource:   blockchain.NewMedianTime(),
+			IndexManager: indexManager,
+		})
+	}
+	generate := func(chain *blockchain.BlockChain, numBlocks uint32) {
+		best := chain.BestSnapshot()
+		g := newTestGenerator(chain, params)
+		if _, err := g.generate(&best.Hash, numBlocks, nil); err != nil {
+			t.Fatalf("generate: %v", err)
+		}
+	}
+	checkIndexed := func(chain *blockchain.BlockChain, txIndex *indexers.TxIndex) {
+		best := chain.BestSnapshot()
+		for height := int32(1); height <= best.Height; height++ {
+			block, err := chain.BlockByHeight(height)
+			if err != nil {
+				t.Fatalf("BlockByHeight: %v", err)
+			}
+			region, err := txIndex.TxBlockRegion(block.Transactions()[0].Hash())
+			if err != nil {
+				t.Fatalf("TxBlockRegion: %v", err)
+			}
+			if indexed := region != nil; indexed != (height >= 3) {
+				t.Fatalf("coinbase of block %d indexed: %v",
+					height, indexed)
+			}
+		}
+	}
+
+	// Catching up skips the blocks below the start height.
+	chain, err := newChain(nil)
+	if err != nil {
+		t.Fatalf("blockchain.New: %v", err)
+	}
+	generate(chain, 4)
+	txIndex := indexers.NewTxIndexFromHeight(db, 3)
+	chain, err = newChain(txIndex)
+	if err != nil {
+		t.Fatalf("blockchain.New: %v", err)
+	}
+	checkIndexed(chain, txIndex)
+
+	// Connected blocks are indexed.
+	generate(chain, 2)
+	checkIndexed(chain, txIndex)
+
+	// Missing transactions are reported as not indexed.
+	s := &rpcServer{cfg: rpcserverConfig{TxIndex: txIndex}}
+	rpcErr := s.txIndexMissError(&chainhash.Hash{})
+	if !strings.Contains(rpcErr.Message, "below height 3 are not indexed") {
+		t.Errorf("unexpected error %v", rpcErr)
+	}
+
+	// The start height of the index cannot be changed.
+	if _, err := newChain(indexers.NewTxIndexFromHeight(db, 2)); err == nil {
+		t.Fatal("changed start height accepted")
+	}
+	if _, err := newChain(indexers.NewTxIndex(db)); err == nil {
+		t.Fatal("full index accepted over a partial index")
+	}
+	txIndex = indexers.NewTxIndexFromHeight(db, 3)
+	chain, err = newChain(txIndex)
+	if err != nil {
+		t.Fatalf("blockchain.New: %v", err)
+	}
+	checkIndexed(chain, txIndex)
+}
@@ -0,0 +1,20 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package txmemo defines the memos a wallet attaches to its transactions and the
+encrypted format they are exported in, so the notes of a user survive moving
+to another wallet or rebuilding a wallet from its seed.
+
+A memo is a free form note along with arbitrary key/value payment metadata,
+such as an invoice number or a counterparty, stored by the wallet alongside
+the transaction it describes and reported by the commands listing
+transactions.
+
+An export holds every memo of a wallet encrypted with ChaCha20-Poly1305 under
+a key derived from a passphrase with scrypt.  The scrypt cost and salt are
+stored in the clear in the header of the export, so it can be imported by a
+wallet which knows nothing but the passphrase.
+*/
+package txmemo
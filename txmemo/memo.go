@@ -0,0 +1,202 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txmemo
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// MaxMemoSize is the maximum length in bytes of the note of a memo.
+	MaxMemoSize = 1024
+
+	// MaxMetadataSize is the maximum total length in bytes of the keys and
+	// values of the metadata of a memo.
+	MaxMetadataSize = 4096
+
+	// exportMagic starts every export.
+	exportMagic = "pktmemo"
+
+	// exportVersion is the version of the export format.
+	exportVersion = 1
+
+	// DefaultScryptLogN is the base 2 logarithm of the scrypt cost used to
+	// derive the key of an export.
+	DefaultScryptLogN = 15
+
+	// maxScryptLogN bounds the scrypt cost accepted from an export so a
+	// crafted one can not exhaust the memory of the importer.
+	maxScryptLogN = 20
+
+	// scryptR and scryptP are the scrypt block size and parallelization
+	// parameters.
+	scryptR = 8
+	scryptP = 1
+
+	// saltSize is the size of the scrypt salt of an export.
+	saltSize = 16
+
+	// headerSize is the size of the header of an export: the magic, the
+	// version, the scrypt cost, the salt and the nonce.
+	headerSize = len(exportMagic) + 2 + saltSize +
+		chacha20poly1305.NonceSize
+)
+
+var (
+	// ErrMemoTooLarge is returned when the note or the metadata of a memo
+	// exceed their maximum size.
+	ErrMemoTooLarge = errors.New("memo too large")
+
+	// ErrBadExport is returned when the data imported is not an export.
+	ErrBadExport = errors.New("not a memo export")
+
+	// ErrBadPassphrase is returned when an export can not be decrypted,
+	// either because the passphrase is wrong or because it was altered.
+	ErrBadPassphrase = errors.New("wrong passphrase or corrupted export")
+)
+
+// Memo is the note and metadata a wallet attaches to one of its transactions.
+type Memo struct {
+	// TxID is the hash of the transaction described by the memo.
+	TxID chainhash.Hash
+
+	// Memo is the free form note of the user.
+	Memo string
+
+	// Metadata is the payment metadata attached to the transaction.
+	Metadata map[string]string
+}
+
+// Validate returns ErrMemoTooLarge when the memo exceeds the maximum sizes.
+func (m *Memo) Validate() error {
+	if len(m.Memo) > MaxMemoSize {
+		return ErrMemoTooLarge
+	}
+	size := 0
+	for k, v := range m.Metadata {
+		size += len(k) + len(v)
+	}
+	if size > MaxMetadataSize {
+		return ErrMemoTooLarge
+	}
+	return nil
+}
+
+// exportedMemo is the JSON encoding of a memo in an export.
+type exportedMemo struct {
+	TxID     string            `json:"txid"`
+	Memo     string            `json:"memo,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// deriveKey returns the key encrypting an export.
+func deriveKey(passphrase, salt []byte, logN uint8) ([]byte, error) {
+	return scrypt.Key(passphrase, salt, 1<<logN, scryptR, scryptP,
+		chacha20poly1305.KeySize)
+}
+
+// Export returns the passed memos encrypted with a key derived from the passed
+// passphrase with a scrypt cost of 2^logN.
+func Export(memos []Memo, passphrase []byte, logN uint8) ([]byte, error) {
+	if logN == 0 || logN > maxScryptLogN {
+		return nil, fmt.Errorf("scrypt cost 2^%d out of range", logN)
+	}
+	exported := make([]exportedMemo, len(memos))
+	for i := range memos {
+		m := &memos[i]
+		if err := m.Validate(); err != nil {
+			return nil, err
+		}
+		exported[i] = exportedMemo{
+			TxID:     m.TxID.String(),
+			Memo:     m.Memo,
+			Metadata: m.Metadata,
+		}
+	}
+	plaintext, err := json.Marshal(exported)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, headerSize)
+	copy(header, exportMagic)
+	header[len(exportMagic)] = exportVersion
+	header[len(exportMagic)+1] = logN
+	if _, err := rand.Read(header[len(exportMagic)+2:]); err != nil {
+		return nil, err
+	}
+	salt := header[len(exportMagic)+2 : len(exportMagic)+2+saltSize]
+	nonce := header[len(exportMagic)+2+saltSize:]
+
+	key, err := deriveKey(passphrase, salt, logN)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, err
+	}
+
+	// The header is authenticated so its cost and salt can not be
+	// altered without being detected.
+	return aead.Seal(header, nonce, plaintext, header), nil
+}
+
+// Import returns the memos of an export made by Export with the passed
+// passphrase.
+func Import(data, passphrase []byte) ([]Memo, error) {
+	if len(data) < headerSize || !bytes.HasPrefix(data, []byte(exportMagic)) {
+		return nil, ErrBadExport
+	}
+	if version := data[len(exportMagic)]; version != exportVersion {
+		return nil, fmt.Errorf("unsupported memo export version %d",
+			version)
+	}
+	logN := data[len(exportMagic)+1]
+	if logN == 0 || logN > maxScryptLogN {
+		return nil, ErrBadExport
+	}
+	header := data[:headerSize]
+	salt := header[len(exportMagic)+2 : len(exportMagic)+2+saltSize]
+	nonce := header[len(exportMagic)+2+saltSize:]
+
+	key, err := deriveKey(passphrase, salt, logN)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, nonce, data[headerSize:], header)
+	if err != nil {
+		return nil, ErrBadPassphrase
+	}
+
+	var exported []exportedMemo
+	if err := json.Unmarshal(plaintext, &exported); err != nil {
+		return nil, ErrBadExport
+	}
+	memos := make([]Memo, len(exported))
+	for i, e := range exported {
+		txid, err := chainhash.NewHashFromStr(e.TxID)
+		if err != nil {
+			return nil, ErrBadExport
+		}
+		memos[i] = Memo{TxID: *txid, Memo: e.Memo, Metadata: e.Metadata}
+		if err := memos[i].Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return memos, nil
+}
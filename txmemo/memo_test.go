@@ -0,0 +1,106 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txmemo
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+)
+
+// testLogN is a low scrypt cost keeping the tests fast.
+const testLogN = 4
+
+// TestExportImport ensures memos survive an export and an import, and that an
+// export can only be imported with its passphrase and unaltered.
+func TestExportImport(t *testing.T) {
+	memos := []Memo{
+		{
+			TxID: chainhash.Hash{1},
+			Memo: "rent for march",
+			Metadata: map[string]string{
+				"invoice": "2020-031",
+				"payee":   "landlord",
+			},
+		},
+		{TxID: chainhash.Hash{2}, Memo: "coffee"},
+	}
+	passphrase := []byte("correct horse battery staple")
+
+	data, err := Export(memos, passphrase, testLogN)
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if strings.Contains(string(data), "landlord") {
+		t.Fatalf("export is not encrypted")
+	}
+	imported, err := Import(data, passphrase)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if !reflect.DeepEqual(imported, memos) {
+		t.Fatalf("imported %+v, want %+v", imported, memos)
+	}
+
+	if _, err := Import(data, []byte("wrong")); err != ErrBadPassphrase {
+		t.Fatalf("Import with wrong passphrase: %v", err)
+	}
+	for _, i := range []int{len(exportMagic) + 2, len(data) - 1} {
+		altered := append([]byte(nil), data...)
+		altered[i] ^= 1
+		if _, err := Import(altered, passphrase); err != ErrBadPassphrase {
+			t.Fatalf("Import of export altered at %d: %v", i, err)
+		}
+	}
+	if _, err := Import([]byte("not an export"), passphrase); err != ErrBadExport {
+		t.Fatalf("Import of garbage: %v", err)
+	}
+
+	again, err := Export(memos, passphrase, testLogN)
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if reflect.DeepEqual(again, data) {
+		t.Fatalf("exports reuse their salt and nonce")
+	}
+}
+
+// TestMemoValidate ensures oversized memos are rejected.
+func TestMemoValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		memo Memo
+		err  error
+	}{
+		{
+			name: "max memo",
+			memo: Memo{Memo: strings.Repeat("a", MaxMemoSize)},
+		},
+		{
+			name: "memo too large",
+			memo: Memo{Memo: strings.Repeat("a", MaxMemoSize+1)},
+			err:  ErrMemoTooLarge,
+		},
+		{
+			name: "metadata too large",
+			memo: Memo{Metadata: map[string]string{
+				"k": strings.Repeat("v", MaxMetadataSize),
+			}},
+			err: ErrMemoTooLarge,
+		},
+	}
+	for _, test := range tests {
+		if err := test.memo.Validate(); err != test.err {
+			t.Errorf("%s: got %v, want %v", test.name, err, test.err)
+		}
+		_, err := Export([]Memo{test.memo}, []byte("p"), testLogN)
+		if err != test.err {
+			t.Errorf("%s: Export got %v, want %v", test.name, err,
+				test.err)
+		}
+	}
+}
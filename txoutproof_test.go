@@ -0,0 +1,81 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/pkt-cash/pktd/btcjson"
+)
+
+// TestTxOutProof ensures gettxoutproof proves the inclusion of transactions
+// which verifytxoutproof accepts while their block is in the main chain.
+func TestTxOutProof(t *testing.T) {
+	tc, teardown := newTestChain(t, "txoutproof", nil)
+	defer teardown()
+	params, chain, g := tc.params, tc.chain, tc.gen
+	hashes, err := g.generate(params.GenesisHash, 3, nil)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	block, err := chain.BlockByHash(hashes[2])
+	if err != nil {
+		t.Fatalf("BlockByHash: %v", err)
+	}
+	txID := block.Transactions()[0].Hash().String()
+	s := &rpcServer{cfg: rpcserverConfig{Chain: chain}}
+
+	// The block must be specified without a transaction index and hold
+	// the transactions.
+	cmd := btcjson.NewGetTxOutProofCmd([]string{txID}, nil)
+	if _, err := handleGetTxOutProof(s, cmd, nil); err == nil {
+		t.Fatal("gettxoutproof without block hash or txindex succeeded")
+	}
+	cmd = btcjson.NewGetTxOutProofCmd([]string{txID},
+		btcjson.String(hashes[1].String()))
+	if _, err := handleGetTxOutProof(s, cmd, nil); err == nil {
+		t.Fatal("gettxoutproof with the wrong block succeeded")
+	}
+	cmd = btcjson.NewGetTxOutProofCmd([]string{txID, txID},
+		btcjson.String(hashes[2].String()))
+	if _, err := handleGetTxOutProof(s, cmd, nil); err == nil {
+		t.Fatal("gettxoutproof with duplicated transactions succeeded")
+	}
+
+	cmd = btcjson.NewGetTxOutProofCmd([]string{txID},
+		btcjson.String(hashes[2].String()))
+	result, err := handleGetTxOutProof(s, cmd, nil)
+	if err != nil {
+		t.Fatalf("gettxoutproof: %v", err)
+	}
+	proof := result.(string)
+	result, err = handleVerifyTxOutProof(s,
+		btcjson.NewVerifyTxOutProofCmd(proof), nil)
+	if err != nil {
+		t.Fatalf("verifytxoutproof: %v", err)
+	}
+	if txIDs := result.([]string); len(txIDs) != 1 || txIDs[0] != txID {
+		t.Fatalf("verifytxoutproof returned %v, want [%v]", txIDs, txID)
+	}
+
+	// Malformed proofs are rejected.
+	_, err = handleVerifyTxOutProof(s,
+		btcjson.NewVerifyTxOutProofCmd(proof[:len(proof)-2]), nil)
+	if err == nil {
+		t.Fatal("verifytxoutproof of a truncated proof succeeded")
+	}
+
+	// The proof no longer verifies once its block leaves the main chain.
+	if _, err := g.simulateReorg(1, 2, nil); err != nil {
+		t.Fatalf("simulateReorg: %v", err)
+	}
+	_, err = handleVerifyTxOutProof(s, btcjson.NewVerifyTxOutProofCmd(proof),
+		nil)
+	if rpcErr, ok := err.(*btcjson.RPCError); !ok ||
+		rpcErr.Code != btcjson.ErrRPCBlockNotFound {
+		t.Fatalf("verifytxoutproof of a stale block: unexpected error %v",
+			err)
+	}
+}
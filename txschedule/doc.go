@@ -0,0 +1,39 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package txschedule keeps the sends a wallet makes at a later block height or
+time, such as recurring payouts.
+
+The time of a send is expressed like a transaction lock time: a block height
+below txscript.LockTimeThreshold and a Unix time otherwise.  A recurring send
+is repeated every so many blocks or seconds, for a number of payments or until
+it is cancelled.
+
+A send is made in one of two modes.  In hold mode the wallet keeps the send
+and creates, signs and broadcasts its transaction once the best block reaches
+the height, or the clock reaches the time, of the send.  The coins are only
+selected then, so the send fails if the balance no longer covers it.
+
+In lock time mode the wallet signs the transaction right away with the time of
+the send as its lock time, which reserves its inputs, and broadcasts it as soon
+as it can be mined.  A transaction whose lock time has already passed is
+broadcast immediately.  The signed transaction can also be handed to the
+recipient, who can broadcast it without the wallet.
+
+The Scheduler keeps the sends.  The wallet asks it for the due sends whenever a
+block is connected and periodically, acts on them and reports the outcome, and
+the Scheduler then moves recurring sends to their next payment.
+
+The PaymentEngine runs payroll-style disbursements, such as the payouts of a
+mining pool or the payments of the contributors of a team.  A PaymentTemplate
+pays a list of recipients with a single transaction, like sendmany, at a
+regular interval.  The amount of each recipient is an expression evaluated at
+each run, such as "250", "5% * balance" or "(balance - 1000) / 4", where
+balance is the spendable balance of the account paying.  The template also
+sets the fee policy of its transactions, and how many times and how often a
+failed run is attempted again before it is given up until the next interval.
+The engine keeps the history of the runs for reporting.
+*/
+package txschedule
@@ -0,0 +1,253 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txschedule
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkt-cash/btcutil"
+)
+
+// VarBalance is the variable of amount expressions holding the spendable
+// balance of the account paying.
+const VarBalance = "balance"
+
+// ErrInvalidExpr is returned when an amount expression cannot be parsed or
+// evaluated.
+var ErrInvalidExpr = errors.New("invalid amount expression")
+
+// Vars are the values of the variables of amount expressions.
+type Vars map[string]btcutil.Amount
+
+// exprNode is a node of a parsed amount expression.  Values are in coins.
+type exprNode interface {
+	eval(vars Vars) (float64, error)
+}
+
+// numberNode is a constant.
+type numberNode float64
+
+func (n numberNode) eval(Vars) (float64, error) {
+	return float64(n), nil
+}
+
+// varNode is a variable.
+type varNode string
+
+func (n varNode) eval(vars Vars) (float64, error) {
+	v, ok := vars[string(n)]
+	if !ok {
+		return 0, fmt.Errorf("%v: unknown variable %q", ErrInvalidExpr,
+			string(n))
+	}
+	return v.ToBTC(), nil
+}
+
+// negNode is a negation.
+type negNode struct {
+	x exprNode
+}
+
+func (n negNode) eval(vars Vars) (float64, error) {
+	x, err := n.x.eval(vars)
+	return -x, err
+}
+
+// binaryNode is an arithmetic operation.
+type binaryNode struct {
+	op   byte
+	x, y exprNode
+}
+
+func (n binaryNode) eval(vars Vars) (float64, error) {
+	x, err := n.x.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	y, err := n.y.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	switch n.op {
+	case '+':
+		return x + y, nil
+	case '-':
+		return x - y, nil
+	case '*':
+		return x * y, nil
+	}
+	if y == 0 {
+		return 0, fmt.Errorf("%v: division by zero", ErrInvalidExpr)
+	}
+	return x / y, nil
+}
+
+// AmountExpr is a parsed amount expression.  An amount expression is an
+// arithmetic expression of amounts in coins, such as "12.5", "5% * balance" or
+// "(balance - 100) / 4", made of numbers, percentages, variables, the + - * /
+// operators and parentheses.
+type AmountExpr struct {
+	src  string
+	root exprNode
+}
+
+// ParseAmountExpr parses the passed amount expression.
+func ParseAmountExpr(src string) (*AmountExpr, error) {
+	p := exprParser{src: src}
+	root, err := p.parseSum()
+	if err == nil {
+		p.skipSpace()
+		if p.pos < len(p.src) {
+			err = p.errorf("unexpected %q", p.src[p.pos:])
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &AmountExpr{src: src, root: root}, nil
+}
+
+// String returns the source of the expression.
+func (e *AmountExpr) String() string {
+	return e.src
+}
+
+// Eval returns the amount the expression evaluates to with the passed
+// variables, which must be positive.
+func (e *AmountExpr) Eval(vars Vars) (btcutil.Amount, error) {
+	v, err := e.root.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	amount, err := btcutil.NewAmount(v)
+	if err != nil {
+		return 0, fmt.Errorf("%v: %q: %v", ErrInvalidExpr, e.src, err)
+	}
+	if amount <= 0 {
+		return 0, fmt.Errorf("%v: %q evaluates to %v", ErrInvalidExpr,
+			e.src, amount)
+	}
+	return amount, nil
+}
+
+// exprParser is a recursive descent parser of amount expressions.
+type exprParser struct {
+	src string
+	pos int
+}
+
+// errorf returns an error at the current position of the parser.
+func (p *exprParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%v: %q at offset %d: %s", ErrInvalidExpr, p.src,
+		p.pos, fmt.Sprintf(format, args...))
+}
+
+// skipSpace advances past any white space.
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.src) && unicode.IsSpace(rune(p.src[p.pos])) {
+		p.pos++
+	}
+}
+
+// peek returns the next character after any white space, or 0 at the end.
+func (p *exprParser) peek() byte {
+	p.skipSpace()
+	if p.pos == len(p.src) {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+// parseSum parses terms separated by + and -.
+func (p *exprParser) parseSum() (exprNode, error) {
+	x, err := p.parseProduct()
+	for err == nil {
+		op := p.peek()
+		if op != '+' && op != '-' {
+			break
+		}
+		p.pos++
+		var y exprNode
+		y, err = p.parseProduct()
+		x = binaryNode{op: op, x: x, y: y}
+	}
+	return x, err
+}
+
+// parseProduct parses factors separated by * and /.
+func (p *exprParser) parseProduct() (exprNode, error) {
+	x, err := p.parseFactor()
+	for err == nil {
+		op := p.peek()
+		if op != '*' && op != '/' {
+			break
+		}
+		p.pos++
+		var y exprNode
+		y, err = p.parseFactor()
+		x = binaryNode{op: op, x: x, y: y}
+	}
+	return x, err
+}
+
+// parseFactor parses a number, a percentage, a variable, a negation or an
+// expression in parentheses.
+func (p *exprParser) parseFactor() (exprNode, error) {
+	c := p.peek()
+	switch {
+	case c == '-':
+		p.pos++
+		x, err := p.parseFactor()
+		return negNode{x: x}, err
+
+	case c == '(':
+		p.pos++
+		x, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ')' {
+			return nil, p.errorf("missing )")
+		}
+		p.pos++
+		return x, nil
+
+	case c == '.' || (c >= '0' && c <= '9'):
+		start := p.pos
+		for p.pos < len(p.src) && (p.src[p.pos] == '.' ||
+			(p.src[p.pos] >= '0' && p.src[p.pos] <= '9')) {
+
+			p.pos++
+		}
+		v, err := strconv.ParseFloat(p.src[start:p.pos], 64)
+		if err != nil {
+			p.pos = start
+			return nil, p.errorf("bad number")
+		}
+		if p.peek() == '%' {
+			p.pos++
+			v /= 100
+		}
+		return numberNode(v), nil
+
+	case c == '_' || unicode.IsLetter(rune(c)):
+		start := p.pos
+		for p.pos < len(p.src) && (p.src[p.pos] == '_' ||
+			unicode.IsLetter(rune(p.src[p.pos])) ||
+			unicode.IsDigit(rune(p.src[p.pos]))) {
+
+			p.pos++
+		}
+		return varNode(strings.ToLower(p.src[start:p.pos])), nil
+
+	case c == 0:
+		return nil, p.errorf("unexpected end")
+	}
+	return nil, p.errorf("unexpected %q", c)
+}
@@ -0,0 +1,388 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txschedule
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+)
+
+var (
+	// ErrInvalidTemplate is returned when setting an invalid payment
+	// template.
+	ErrInvalidTemplate = errors.New("invalid payment template")
+
+	// ErrUnknownTemplate is returned for the name of a payment template
+	// which does not exist.
+	ErrUnknownTemplate = errors.New("unknown payment template")
+
+	// ErrFeeTooHigh is returned when the fee of a payment run exceeds the
+	// maximum of its fee policy.
+	ErrFeeTooHigh = errors.New("fee exceeds the maximum of the fee policy")
+)
+
+// DefaultMaxPaymentRuns is the default number of payment runs kept in the
+// history of a payment engine.
+const DefaultMaxPaymentRuns = 1000
+
+// Recipient is a recipient of the payments of a template.
+type Recipient struct {
+	// Address is the address paid.
+	Address string
+
+	// Amount is the amount paid, evaluated at each run.
+	Amount *AmountExpr
+
+	// Label is an optional caller reference reported with the runs.
+	Label string
+}
+
+// FeePolicy is the fee policy of the payments of a template.
+type FeePolicy struct {
+	// ConfTarget is the number of blocks the transaction should confirm
+	// within, used when FeeRate is zero.
+	ConfTarget int32
+
+	// FeeRate is the fee rate paid per kilobyte.
+	FeeRate btcutil.Amount
+
+	// MaxFee fails a run whose fee would exceed it, when not zero.
+	MaxFee btcutil.Amount
+
+	// SubtractFee subtracts the fee from the amounts paid, in proportion
+	// to them, instead of adding it.
+	SubtractFee bool
+}
+
+// CheckFee returns ErrFeeTooHigh when the passed fee exceeds the maximum fee
+// of the policy.
+func (f *FeePolicy) CheckFee(fee btcutil.Amount) error {
+	if f.MaxFee != 0 && fee > f.MaxFee {
+		return fmt.Errorf("%v: %v > %v", ErrFeeTooHigh, fee, f.MaxFee)
+	}
+	return nil
+}
+
+// PaymentTemplate describes payments made to a list of recipients by a single
+// transaction at a regular interval, like sendmany.
+type PaymentTemplate struct {
+	// Name identifies the template.
+	Name string
+
+	// Account is the account paying.
+	Account string
+
+	// Recipients are the recipients paid at each run.  Payments to the
+	// same address are merged.
+	Recipients []Recipient
+
+	// Interval is the time between the runs.
+	Interval time.Duration
+
+	// Start is the time of the first run, now when zero.
+	Start time.Time
+
+	// MinConf is the number of confirmations of the outputs spent.
+	MinConf int32
+
+	// Fee is the fee policy of the runs.
+	Fee FeePolicy
+
+	// MaxRetries is the number of times a failed run is attempted again
+	// before it is given up until the next interval.
+	MaxRetries int
+
+	// RetryDelay is the time between the attempts of a failed run.
+	RetryDelay time.Duration
+}
+
+// validate checks the template is named, pays a recipient and has positive
+// intervals.
+func (t *PaymentTemplate) validate() error {
+	if t.Name == "" {
+		return fmt.Errorf("%v: no name", ErrInvalidTemplate)
+	}
+	if len(t.Recipients) == 0 {
+		return fmt.Errorf("%v: no recipients", ErrInvalidTemplate)
+	}
+	for i := range t.Recipients {
+		r := &t.Recipients[i]
+		if r.Address == "" || r.Amount == nil {
+			return fmt.Errorf("%v: recipient %d has no address or "+
+				"amount", ErrInvalidTemplate, i)
+		}
+	}
+	if t.Interval <= 0 {
+		return fmt.Errorf("%v: no interval", ErrInvalidTemplate)
+	}
+	if t.MinConf < 0 || t.MaxRetries < 0 || t.RetryDelay < 0 ||
+		t.Fee.ConfTarget < 0 || t.Fee.FeeRate < 0 || t.Fee.MaxFee < 0 {
+
+		return fmt.Errorf("%v: negative value", ErrInvalidTemplate)
+	}
+	if t.MaxRetries > 0 && t.RetryDelay == 0 {
+		return fmt.Errorf("%v: retries without a delay",
+			ErrInvalidTemplate)
+	}
+	return nil
+}
+
+// Outputs evaluates the amounts paid by the template with the passed
+// variables, and returns them by address.
+func (t *PaymentTemplate) Outputs(vars Vars) (map[string]btcutil.Amount, error) {
+	outputs := make(map[string]btcutil.Amount, len(t.Recipients))
+	for i := range t.Recipients {
+		r := &t.Recipients[i]
+		amount, err := r.Amount.Eval(vars)
+		if err != nil {
+			return nil, fmt.Errorf("recipient %s: %v", r.Address, err)
+		}
+		outputs[r.Address] += amount
+	}
+	return outputs, nil
+}
+
+// PaymentState is a payment template and the state of its runs.
+type PaymentState struct {
+	PaymentTemplate
+
+	// Scheduled is the time of the current run.
+	Scheduled time.Time
+
+	// NextAttempt is the time of the next attempt of the current run.
+	NextAttempt time.Time
+
+	// Attempts is the number of failed attempts of the current run.
+	Attempts int
+
+	// LastError is why the last attempt failed, empty once a run succeeds.
+	LastError string
+}
+
+// PaymentRun is a completed run of a payment template, which made its
+// payments or was given up.
+type PaymentRun struct {
+	// Template is the name of the template.
+	Template string
+
+	// Scheduled is the time the run was scheduled for.
+	Scheduled time.Time
+
+	// Time is the time the run completed.
+	Time time.Time
+
+	// Attempts is the number of attempts of the run.
+	Attempts int
+
+	// TxID is the hash of the transaction which made the payments, nil
+	// when the run was given up.
+	TxID *chainhash.Hash
+
+	// Outputs are the amounts paid by address.
+	Outputs map[string]btcutil.Amount
+
+	// Fee is the fee of the transaction.
+	Fee btcutil.Amount
+
+	// Error is why the last attempt of a run which was given up failed.
+	Error string
+}
+
+// PaymentEngine runs payment templates on a cadence, retries their failed
+// runs and keeps the history of their runs.
+//
+// It is safe for concurrent access.
+type PaymentEngine struct {
+	mtx        sync.Mutex
+	templates  map[string]*PaymentState
+	history    []PaymentRun
+	maxHistory int
+
+	// now returns the current time.  It is replaced by the tests.
+	now func() time.Time
+}
+
+// NewPaymentEngine returns a payment engine without templates which keeps the
+// last maxHistory runs, DefaultMaxPaymentRuns when it is zero.
+func NewPaymentEngine(maxHistory int) *PaymentEngine {
+	if maxHistory <= 0 {
+		maxHistory = DefaultMaxPaymentRuns
+	}
+	return &PaymentEngine{
+		templates:  make(map[string]*PaymentState),
+		maxHistory: maxHistory,
+		now:        time.Now,
+	}
+}
+
+// Set adds the passed template, or replaces the template with the same name,
+// whose current run is then rescheduled.
+func (e *PaymentEngine) Set(t *PaymentTemplate) error {
+	if err := t.validate(); err != nil {
+		return err
+	}
+
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+
+	start := t.Start
+	if start.IsZero() {
+		start = e.now()
+	}
+	tc := *t
+	tc.Recipients = append([]Recipient(nil), t.Recipients...)
+	e.templates[t.Name] = &PaymentState{
+		PaymentTemplate: tc,
+		Scheduled:       start,
+		NextAttempt:     start,
+	}
+	return nil
+}
+
+// Remove removes the template with the passed name.  Its history is kept.
+func (e *PaymentEngine) Remove(name string) error {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+
+	if _, ok := e.templates[name]; !ok {
+		return ErrUnknownTemplate
+	}
+	delete(e.templates, name)
+	return nil
+}
+
+// Templates returns the templates and the state of their runs ordered by name.
+func (e *PaymentEngine) Templates() []PaymentState {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+
+	states := make([]PaymentState, 0, len(e.templates))
+	for _, s := range e.templates {
+		states = append(states, *s)
+	}
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].Name < states[j].Name
+	})
+	return states
+}
+
+// Due returns the templates whose current run must be attempted, ordered by
+// name.  The wallet makes the payments of each, with the amounts returned by
+// Outputs, and reports the outcome with Succeeded or Failed.
+func (e *PaymentEngine) Due() []PaymentState {
+	now := e.now()
+	var due []PaymentState
+	for _, s := range e.Templates() {
+		if !now.Before(s.NextAttempt) {
+			due = append(due, s)
+		}
+	}
+	return due
+}
+
+// record adds a completed run to the history and schedules the next run of
+// the template after the current time, so the runs missed while the wallet
+// was not running are not made in a burst.
+//
+// This function MUST be called with the engine lock held.
+func (e *PaymentEngine) record(s *PaymentState, run *PaymentRun) {
+	if len(e.history) >= e.maxHistory {
+		e.history = append(e.history[:0], e.history[1:]...)
+	}
+	e.history = append(e.history, *run)
+
+	next := s.Scheduled.Add(s.Interval)
+	if now := run.Time; !next.After(now) {
+		missed := now.Sub(next)/s.Interval + 1
+		next = next.Add(missed * s.Interval)
+	}
+	s.Scheduled = next
+	s.NextAttempt = next
+	s.Attempts = 0
+}
+
+// Succeeded records that the current run of the template with the passed name
+// made the passed payments with the passed transaction.
+func (e *PaymentEngine) Succeeded(name string, txHash *chainhash.Hash,
+	outputs map[string]btcutil.Amount, fee btcutil.Amount) error {
+
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+
+	s, ok := e.templates[name]
+	if !ok {
+		return ErrUnknownTemplate
+	}
+	hash := *txHash
+	run := PaymentRun{
+		Template:  name,
+		Scheduled: s.Scheduled,
+		Time:      e.now(),
+		Attempts:  s.Attempts + 1,
+		TxID:      &hash,
+		Outputs:   make(map[string]btcutil.Amount, len(outputs)),
+		Fee:       fee,
+	}
+	for addr, amount := range outputs {
+		run.Outputs[addr] = amount
+	}
+	s.LastError = ""
+	e.record(s, &run)
+	return nil
+}
+
+// Failed records that the current attempt of the template with the passed
+// name failed.  The run is attempted again after the retry delay of the
+// template, or given up until the next interval once it ran out of retries.
+// It returns whether the run was given up.
+func (e *PaymentEngine) Failed(name string, err error) (bool, error) {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+
+	s, ok := e.templates[name]
+	if !ok {
+		return false, ErrUnknownTemplate
+	}
+	now := e.now()
+	s.Attempts++
+	s.LastError = err.Error()
+	if s.Attempts <= s.MaxRetries {
+		s.NextAttempt = now.Add(s.RetryDelay)
+		return false, nil
+	}
+	e.record(s, &PaymentRun{
+		Template:  name,
+		Scheduled: s.Scheduled,
+		Time:      now,
+		Attempts:  s.Attempts,
+		Error:     s.LastError,
+	})
+	return true, nil
+}
+
+// Report returns up to count completed runs of the template with the passed
+// name, or of every template when it is empty, most recent first.  All the
+// runs are returned when count is zero.
+func (e *PaymentEngine) Report(name string, count int) []PaymentRun {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+
+	var runs []PaymentRun
+	for i := len(e.history) - 1; i >= 0; i-- {
+		if count > 0 && len(runs) == count {
+			break
+		}
+		if name == "" || e.history[i].Template == name {
+			runs = append(runs, e.history[i])
+		}
+	}
+	return runs
+}
@@ -0,0 +1,176 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txschedule
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+)
+
+// TestAmountExpr ensures amount expressions are parsed and evaluated with the
+// usual precedence, and invalid ones are rejected.
+func TestAmountExpr(t *testing.T) {
+	vars := Vars{VarBalance: 1000 * btcutil.SatoshiPerBitcoin}
+	tests := []struct {
+		expr string
+		want float64
+	}{
+		{"250", 250},
+		{"0.125", 0.125},
+		{"5% * balance", 50},
+		{"Balance * 5%", 50},
+		{"(balance - 200) / 4", 200},
+		{"balance - 200 / 4", 950},
+		{"-(-3) + 2 * 3", 9},
+	}
+	for _, test := range tests {
+		e, err := ParseAmountExpr(test.expr)
+		if err != nil {
+			t.Errorf("ParseAmountExpr(%q): %v", test.expr, err)
+			continue
+		}
+		want, _ := btcutil.NewAmount(test.want)
+		if got, err := e.Eval(vars); err != nil || got != want {
+			t.Errorf("Eval(%q): got %v (%v), want %v", test.expr, got,
+				err, want)
+		}
+	}
+
+	for _, expr := range []string{"", "1 +", "(1", "1 2", "2 $", "1..2"} {
+		if _, err := ParseAmountExpr(expr); err == nil {
+			t.Errorf("ParseAmountExpr(%q): unexpected success", expr)
+		}
+	}
+	for _, expr := range []string{"1 / 0", "0", "10 - balance", "fees"} {
+		e, err := ParseAmountExpr(expr)
+		if err != nil {
+			t.Fatalf("ParseAmountExpr(%q): %v", expr, err)
+		}
+		if _, err := e.Eval(vars); err == nil {
+			t.Errorf("Eval(%q): unexpected success", expr)
+		}
+	}
+}
+
+// TestPaymentEngine ensures templates are run on their cadence, failed runs
+// are retried and then given up, and the runs are reported.
+func TestPaymentEngine(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	e := NewPaymentEngine(0)
+	e.now = func() time.Time { return now }
+
+	mustParse := func(expr string) *AmountExpr {
+		e, err := ParseAmountExpr(expr)
+		if err != nil {
+			t.Fatalf("ParseAmountExpr(%q): %v", expr, err)
+		}
+		return e
+	}
+	tmpl := PaymentTemplate{
+		Name:    "payroll",
+		Account: "team",
+		Recipients: []Recipient{
+			{Address: "a", Amount: mustParse("10")},
+			{Address: "b", Amount: mustParse("1% * balance")},
+			{Address: "a", Amount: mustParse("5")},
+		},
+		Interval:   24 * time.Hour,
+		MaxRetries: 1,
+		RetryDelay: time.Hour,
+	}
+	if err := e.Set(&PaymentTemplate{Name: "empty",
+		Interval: time.Hour}); err == nil {
+
+		t.Fatalf("Set: unexpected success without recipients")
+	}
+	if err := e.Set(&tmpl); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	// The amounts of the recipients are evaluated and merged by address.
+	outputs, err := tmpl.Outputs(Vars{VarBalance: 500 * btcutil.SatoshiPerBitcoin})
+	if err != nil {
+		t.Fatalf("Outputs: %v", err)
+	}
+	if len(outputs) != 2 || outputs["a"] != 15*btcutil.SatoshiPerBitcoin ||
+		outputs["b"] != 5*btcutil.SatoshiPerBitcoin {
+
+		t.Fatalf("Outputs: got %v", outputs)
+	}
+
+	// The first run is due right away.  It fails once, is retried after
+	// the delay and succeeds.
+	if due := e.Due(); len(due) != 1 || due[0].Name != "payroll" {
+		t.Fatalf("Due: got %+v, want payroll", due)
+	}
+	if given, err := e.Failed("payroll", errors.New("locked")); err != nil || given {
+		t.Fatalf("Failed: got %v, %v, want a retry", given, err)
+	}
+	if due := e.Due(); len(due) != 0 {
+		t.Fatalf("Due: run is retried before its delay")
+	}
+	now = now.Add(time.Hour)
+	hash := chainhash.Hash{1}
+	if err := e.Succeeded("payroll", &hash, outputs, 1000); err != nil {
+		t.Fatalf("Succeeded: %v", err)
+	}
+
+	// The next run is a day after the first one was scheduled.  It is given
+	// up after its retry and the run after it is not made in a burst after
+	// a long downtime.
+	state := e.Templates()[0]
+	if !state.Scheduled.Equal(time.Unix(1600000000, 0).Add(24*time.Hour)) ||
+		state.Attempts != 0 || state.LastError != "" {
+
+		t.Fatalf("Templates: unexpected state %+v", state)
+	}
+	now = state.Scheduled.Add(time.Minute)
+	e.Failed("payroll", errors.New("insufficient funds"))
+	now = now.Add(3 * 24 * time.Hour)
+	if given, _ := e.Failed("payroll", errors.New("insufficient funds")); !given {
+		t.Fatalf("Failed: run is not given up after its retries")
+	}
+	state = e.Templates()[0]
+	if !state.Scheduled.After(now) || state.Scheduled.Sub(now) > 24*time.Hour {
+		t.Fatalf("Templates: next run at %v is not the next after %v",
+			state.Scheduled, now)
+	}
+
+	report := e.Report("payroll", 0)
+	if len(report) != 2 || report[0].TxID != nil || report[0].Attempts != 2 ||
+		report[0].Error != "insufficient funds" || *report[1].TxID != hash ||
+		report[1].Attempts != 2 || report[1].Fee != 1000 {
+
+		t.Fatalf("Report: got %+v", report)
+	}
+	if len(e.Report("other", 0)) != 0 || len(e.Report("", 1)) != 1 {
+		t.Fatalf("Report: unexpected filtering")
+	}
+
+	if err := e.Remove("payroll"); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if err := e.Remove("payroll"); err != ErrUnknownTemplate {
+		t.Fatalf("Remove: got %v, want %v", err, ErrUnknownTemplate)
+	}
+}
+
+// TestFeePolicy ensures the maximum fee of a fee policy is enforced when set.
+func TestFeePolicy(t *testing.T) {
+	if err := (&FeePolicy{}).CheckFee(1e8); err != nil {
+		t.Fatalf("CheckFee: unexpected error without maximum: %v", err)
+	}
+	f := FeePolicy{MaxFee: 1000}
+	if err := f.CheckFee(1000); err != nil {
+		t.Fatalf("CheckFee: %v", err)
+	}
+	if err := f.CheckFee(1001); err == nil {
+		t.Fatalf("CheckFee: unexpected success above the maximum")
+	}
+}
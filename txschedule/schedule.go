@@ -0,0 +1,375 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txschedule
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+var (
+	// ErrInvalidSend is returned when scheduling an invalid send.
+	ErrInvalidSend = errors.New("invalid scheduled send")
+
+	// ErrUnknownSend is returned for the ID of a send which is not
+	// scheduled.
+	ErrUnknownSend = errors.New("unknown scheduled send")
+)
+
+// Mode is how a scheduled send is made.
+type Mode string
+
+const (
+	// ModeHold creates and signs the transaction of the send once it is
+	// due.
+	ModeHold Mode = "hold"
+
+	// ModeLockTime signs the transaction of the send right away with the
+	// time of the send as its lock time, and broadcasts it once it is
+	// final.
+	ModeLockTime Mode = "locktime"
+)
+
+// Action is what the wallet must do for a due send.
+type Action int
+
+const (
+	// ActionSign creates and signs the transaction of the send.  In hold
+	// mode, the transaction is then broadcast and reported with Sent.  In
+	// lock time mode, it is signed with the lock time of the send and
+	// reported with Signed.
+	ActionSign Action = iota
+
+	// ActionBroadcast broadcasts the signed transaction of a send in lock
+	// time mode, which is then reported with Sent.
+	ActionBroadcast
+)
+
+// String returns the action in human-readable form.
+func (a Action) String() string {
+	switch a {
+	case ActionSign:
+		return "sign"
+	case ActionBroadcast:
+		return "broadcast"
+	}
+	return fmt.Sprintf("Action(%d)", int(a))
+}
+
+// ChainState is the state of the main chain the due sends are determined
+// from.
+type ChainState struct {
+	// Height is the height of the best block.
+	Height int32
+
+	// MedianTime is the median time of the best block, which the lock
+	// time of a transaction in the next block is compared to.
+	MedianTime time.Time
+}
+
+// Send is a scheduled send.
+type Send struct {
+	// ID identifies the send.
+	ID uint64
+
+	// Account is the account the send spends from.
+	Account string
+
+	// Outputs are the amounts paid to each address.
+	Outputs map[string]btcutil.Amount
+
+	// MinConf is the number of confirmations of the outputs spent.
+	MinConf int32
+
+	// Comment is stored with the transactions of the send.
+	Comment string
+
+	// Mode is how the send is made.
+	Mode Mode
+
+	// At is the height or Unix time of the next payment, as a lock time.
+	At uint32
+
+	// Every is the number of blocks or seconds between the payments of a
+	// recurring send, zero for a single payment.
+	Every uint32
+
+	// Remaining is the number of payments left, zero when a recurring send
+	// repeats until cancelled.
+	Remaining int
+
+	// Created is the time the send was scheduled.
+	Created time.Time
+
+	// Tx is the signed transaction of the next payment in lock time mode,
+	// nil until it is signed.
+	Tx *wire.MsgTx
+
+	// Sent is the number of payments made.
+	Sent int
+
+	// LastTxID is the hash of the transaction of the last payment.
+	LastTxID *chainhash.Hash
+
+	// LastError is why the last attempt to make a payment failed, empty
+	// once a payment succeeds.
+	LastError string
+}
+
+// IsHeight returns whether the time of the send is a block height.
+func (s *Send) IsHeight() bool {
+	return s.At < txscript.LockTimeThreshold
+}
+
+// validate checks the send has outputs, a known mode and a consistent
+// recurrence.
+func (s *Send) validate() error {
+	if len(s.Outputs) == 0 {
+		return fmt.Errorf("%v: no outputs", ErrInvalidSend)
+	}
+	for addr, amount := range s.Outputs {
+		if amount <= 0 {
+			return fmt.Errorf("%v: amount paid to %s is not "+
+				"positive", ErrInvalidSend, addr)
+		}
+	}
+	if s.Mode != ModeHold && s.Mode != ModeLockTime {
+		return fmt.Errorf("%v: unknown mode %q", ErrInvalidSend, s.Mode)
+	}
+	if s.MinConf < 0 || s.Remaining < 0 {
+		return fmt.Errorf("%v: negative value", ErrInvalidSend)
+	}
+	if s.Every == 0 && s.Remaining != 1 {
+		return fmt.Errorf("%v: repeated send without an interval",
+			ErrInvalidSend)
+	}
+	return nil
+}
+
+// action returns what must be done for the send and whether it is due.
+func (s *Send) action(chain *ChainState, now time.Time) (Action, bool) {
+	switch s.Mode {
+	case ModeHold:
+		if s.IsHeight() {
+			return ActionSign, chain.Height >= int32(s.At)
+		}
+		return ActionSign, now.Unix() >= int64(s.At)
+
+	case ModeLockTime:
+		if s.Tx == nil {
+			return ActionSign, true
+		}
+
+		// The transaction can be mined in the next block once its lock
+		// time is below the height or median time of that block.
+		if s.IsHeight() {
+			return ActionBroadcast, chain.Height+1 > int32(s.At)
+		}
+		return ActionBroadcast, chain.MedianTime.Unix() > int64(s.At)
+	}
+	return 0, false
+}
+
+// clone returns a copy of the send which shares nothing with it.
+func (s *Send) clone() Send {
+	c := *s
+	c.Outputs = make(map[string]btcutil.Amount, len(s.Outputs))
+	for addr, amount := range s.Outputs {
+		c.Outputs[addr] = amount
+	}
+	if s.Tx != nil {
+		c.Tx = s.Tx.Copy()
+	}
+	if s.LastTxID != nil {
+		hash := *s.LastTxID
+		c.LastTxID = &hash
+	}
+	return c
+}
+
+// Task is a due send and what the wallet must do for it.
+type Task struct {
+	Send
+	Action Action
+}
+
+// Scheduler keeps the scheduled sends of a wallet.
+//
+// It is safe for concurrent access.
+type Scheduler struct {
+	mtx    sync.Mutex
+	sends  map[uint64]*Send
+	nextID uint64
+
+	// now returns the current time.  It is replaced by the tests.
+	now func() time.Time
+}
+
+// NewScheduler returns a scheduler without any send.
+func NewScheduler() *Scheduler {
+	return &Scheduler{
+		sends:  make(map[uint64]*Send),
+		nextID: 1,
+		now:    time.Now,
+	}
+}
+
+// Add schedules the passed send and returns it with its ID and creation time
+// set.  A send made once must have Remaining set to 1.
+func (s *Scheduler) Add(send *Send) (*Send, error) {
+	if err := send.validate(); err != nil {
+		return nil, err
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	c := send.clone()
+	c.ID = s.nextID
+	c.Created = s.now()
+	c.Tx = nil
+	c.Sent = 0
+	c.LastTxID = nil
+	c.LastError = ""
+	s.nextID++
+	s.sends[c.ID] = &c
+	result := c.clone()
+	return &result, nil
+}
+
+// Cancel unschedules the send with the passed ID and returns it, so the
+// wallet can release the inputs of its signed transaction, if any.
+func (s *Scheduler) Cancel(id uint64) (*Send, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	send, ok := s.sends[id]
+	if !ok {
+		return nil, ErrUnknownSend
+	}
+	delete(s.sends, id)
+	result := send.clone()
+	return &result, nil
+}
+
+// Scheduled returns the scheduled sends ordered by ID.
+func (s *Scheduler) Scheduled() []Send {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	sends := make([]Send, 0, len(s.sends))
+	for _, send := range s.sends {
+		sends = append(sends, send.clone())
+	}
+	sort.Slice(sends, func(i, j int) bool {
+		return sends[i].ID < sends[j].ID
+	})
+	return sends
+}
+
+// Due returns the sends the wallet must act on given the passed chain state,
+// ordered by ID.
+func (s *Scheduler) Due(chain *ChainState) []Task {
+	now := s.now()
+	var tasks []Task
+	for _, send := range s.Scheduled() {
+		if action, due := send.action(chain, now); due {
+			tasks = append(tasks, Task{Send: send, Action: action})
+		}
+	}
+	return tasks
+}
+
+// Signed stores the signed transaction of the next payment of the send in lock
+// time mode with the passed ID.  The transaction must have the time of the
+// send as its lock time, and an input with a sequence number below the maximum
+// so the lock time is enforced.
+func (s *Scheduler) Signed(id uint64, tx *wire.MsgTx) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	send, ok := s.sends[id]
+	if !ok {
+		return ErrUnknownSend
+	}
+	if send.Mode != ModeLockTime {
+		return fmt.Errorf("%v: send %d is not in lock time mode",
+			ErrInvalidSend, id)
+	}
+	if tx.LockTime != send.At {
+		return fmt.Errorf("%v: lock time %d of the transaction is not "+
+			"%d", ErrInvalidSend, tx.LockTime, send.At)
+	}
+	enforced := false
+	for _, txIn := range tx.TxIn {
+		if txIn.Sequence != wire.MaxTxInSequenceNum {
+			enforced = true
+			break
+		}
+	}
+	if !enforced {
+		return fmt.Errorf("%v: lock time of the transaction is not "+
+			"enforced", ErrInvalidSend)
+	}
+	send.Tx = tx.Copy()
+	return nil
+}
+
+// Sent records the transaction which made the next payment of the send with
+// the passed ID.  A recurring send moves to its next payment and any other
+// send is unscheduled.
+func (s *Scheduler) Sent(id uint64, txHash *chainhash.Hash) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	send, ok := s.sends[id]
+	if !ok {
+		return ErrUnknownSend
+	}
+	hash := *txHash
+	send.LastTxID = &hash
+	send.LastError = ""
+	send.Sent++
+	send.Tx = nil
+
+	// The send ends with its last payment, or when the next payment would
+	// overflow its time or turn a height into a time.
+	next := uint64(send.At) + uint64(send.Every)
+	if send.Remaining == 1 || next > math.MaxUint32 ||
+		(send.IsHeight() && next >= txscript.LockTimeThreshold) {
+
+		delete(s.sends, id)
+		return nil
+	}
+	if send.Remaining > 1 {
+		send.Remaining--
+	}
+	send.At = uint32(next)
+	return nil
+}
+
+// Failed records why the last attempt to make the next payment of the send
+// with the passed ID failed.  The payment is attempted again the next time the
+// send is due.
+func (s *Scheduler) Failed(id uint64, err error) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	send, ok := s.sends[id]
+	if !ok {
+		return ErrUnknownSend
+	}
+	send.LastError = err.Error()
+	return nil
+}
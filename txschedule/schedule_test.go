@@ -0,0 +1,173 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txschedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// testOutputs are the outputs of the test sends.
+var testOutputs = map[string]btcutil.Amount{"addr": 1e8}
+
+// TestSendValidate ensures invalid sends are not scheduled.
+func TestSendValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		send Send
+	}{
+		{"no outputs", Send{Mode: ModeHold, Remaining: 1}},
+		{"zero amount", Send{
+			Outputs:   map[string]btcutil.Amount{"addr": 0},
+			Mode:      ModeHold,
+			Remaining: 1,
+		}},
+		{"unknown mode", Send{Outputs: testOutputs, Mode: "later",
+			Remaining: 1}},
+		{"repeated without interval", Send{Outputs: testOutputs,
+			Mode: ModeHold, Remaining: 2}},
+		{"negative remaining", Send{Outputs: testOutputs,
+			Mode: ModeHold, Every: 10, Remaining: -1}},
+	}
+	s := NewScheduler()
+	for _, test := range tests {
+		if _, err := s.Add(&test.send); err == nil {
+			t.Errorf("%s: Add: unexpected success", test.name)
+		}
+	}
+	if len(s.Scheduled()) != 0 {
+		t.Fatalf("Scheduled: invalid sends were scheduled")
+	}
+}
+
+// TestHoldSend ensures a recurring send in hold mode is due once its height is
+// reached and then moves to its next payment until its last one.
+func TestHoldSend(t *testing.T) {
+	s := NewScheduler()
+	send, err := s.Add(&Send{
+		Outputs:   testOutputs,
+		Mode:      ModeHold,
+		At:        100,
+		Every:     10,
+		Remaining: 2,
+	})
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	if tasks := s.Due(&ChainState{Height: 99}); len(tasks) != 0 {
+		t.Fatalf("Due: send is due before its height")
+	}
+	tasks := s.Due(&ChainState{Height: 100})
+	if len(tasks) != 1 || tasks[0].ID != send.ID ||
+		tasks[0].Action != ActionSign {
+
+		t.Fatalf("Due: got %+v, want send %d to sign", tasks, send.ID)
+	}
+
+	// A failed payment is attempted again and successful ones move the
+	// send to its next payment.
+	if err := s.Failed(send.ID, ErrInvalidSend); err != nil {
+		t.Fatalf("Failed: %v", err)
+	}
+	if tasks := s.Due(&ChainState{Height: 101}); len(tasks) != 1 ||
+		tasks[0].LastError == "" {
+
+		t.Fatalf("Due: failed send is not due with its error")
+	}
+	hash := chainhash.Hash{1}
+	if err := s.Sent(send.ID, &hash); err != nil {
+		t.Fatalf("Sent: %v", err)
+	}
+	scheduled := s.Scheduled()
+	if len(scheduled) != 1 || scheduled[0].At != 110 ||
+		scheduled[0].Remaining != 1 || scheduled[0].Sent != 1 ||
+		scheduled[0].LastError != "" || *scheduled[0].LastTxID != hash {
+
+		t.Fatalf("Scheduled: unexpected send %+v", scheduled)
+	}
+	if tasks := s.Due(&ChainState{Height: 109}); len(tasks) != 0 {
+		t.Fatalf("Due: send is due before its next height")
+	}
+
+	// The last payment unschedules the send.
+	if err := s.Sent(send.ID, &hash); err != nil {
+		t.Fatalf("Sent: %v", err)
+	}
+	if len(s.Scheduled()) != 0 {
+		t.Fatalf("Scheduled: send remains after its last payment")
+	}
+	if err := s.Sent(send.ID, &hash); err != ErrUnknownSend {
+		t.Fatalf("Sent: got %v, want %v", err, ErrUnknownSend)
+	}
+}
+
+// TestLockTimeSend ensures a send in lock time mode is signed right away with
+// its lock time and broadcast once its transaction is final.
+func TestLockTimeSend(t *testing.T) {
+	const at = 1600000000
+	s := NewScheduler()
+	s.now = func() time.Time { return time.Unix(at+3600, 0) }
+	send, err := s.Add(&Send{
+		Outputs:   testOutputs,
+		Mode:      ModeLockTime,
+		At:        at,
+		Remaining: 1,
+	})
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	chain := &ChainState{Height: 5, MedianTime: time.Unix(at, 0)}
+	tasks := s.Due(chain)
+	if len(tasks) != 1 || tasks[0].Action != ActionSign {
+		t.Fatalf("Due: got %+v, want the send to sign", tasks)
+	}
+
+	// The transaction must have the lock time of the send, and enforce
+	// it.
+	tx := wire.NewMsgTx(wire.TxVersion)
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{}, nil, nil))
+	tx.LockTime = at - 1
+	if err := s.Signed(send.ID, tx); err == nil {
+		t.Fatalf("Signed: unexpected success with another lock time")
+	}
+	tx.LockTime = at
+	if err := s.Signed(send.ID, tx); err == nil {
+		t.Fatalf("Signed: unexpected success with final inputs")
+	}
+	tx.TxIn[0].Sequence = wire.MaxTxInSequenceNum - 1
+	if err := s.Signed(send.ID, tx); err != nil {
+		t.Fatalf("Signed: %v", err)
+	}
+
+	// The transaction is only broadcast once the median time passed its
+	// lock time, regardless of the clock.
+	if tasks := s.Due(chain); len(tasks) != 0 {
+		t.Fatalf("Due: send is due before its lock time")
+	}
+	chain.MedianTime = time.Unix(at+1, 0)
+	tasks = s.Due(chain)
+	if len(tasks) != 1 || tasks[0].Action != ActionBroadcast ||
+		tasks[0].Tx.TxHash() != tx.TxHash() {
+
+		t.Fatalf("Due: got %+v, want the send to broadcast", tasks)
+	}
+
+	cancelled, err := s.Cancel(send.ID)
+	if err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	if cancelled.Tx == nil {
+		t.Fatalf("Cancel: the signed transaction is not returned")
+	}
+	if _, err := s.Cancel(send.ID); err != ErrUnknownSend {
+		t.Fatalf("Cancel: got %v, want %v", err, ErrUnknownSend)
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txscript
+
+import (
+	"fmt"
+
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// AlternateEngine checks the input at index txIdx of the passed transaction,
+// which spends an output with the passed public key script and amount, under
+// the passed flags with an implementation of the script engine other than the
+// one of this package.  It returns nil when the script succeeds.
+type AlternateEngine func(pkScript []byte, tx *wire.MsgTx, txIdx int,
+	amount int64, flags ScriptFlags) error
+
+// alternateEngine is the engine every script is checked with when the package
+// is built with the scriptdiff build tag.
+var alternateEngine AlternateEngine = uncachedEngine
+
+// RegisterAlternateEngine sets the engine every script is checked with when
+// the package is built with the scriptdiff build tag, such as an engine from
+// an earlier version of this package or from upstream btcd.  By default the
+// scripts are executed again by this package without the signature and hash
+// caches.  Passing nil disables the check.
+//
+// This function is NOT safe for concurrent access with the execution of
+// scripts, so it must be called during initialization.
+func RegisterAlternateEngine(engine AlternateEngine) {
+	alternateEngine = engine
+}
+
+// uncachedEngine is the default alternate engine.  It executes the script with
+// a new engine of this package which does not use any cache, so optimizations
+// relying on the signature and hash caches are checked.
+func uncachedEngine(pkScript []byte, tx *wire.MsgTx, txIdx int, amount int64,
+	flags ScriptFlags) error {
+
+	vm, err := newEngine(pkScript, tx, txIdx, flags, nil, nil, amount)
+	if err != nil {
+		return err
+	}
+	return vm.execute()
+}
+
+// divergence returns a description of the difference between err, the result
+// of the engine of this package, and altErr, the result of the alternate
+// engine, or an empty string when they agree.  They agree when both succeed,
+// or when both fail with the same error code.  Errors other than script errors
+// match any error, since alternate engines may not return script errors.
+func divergence(err, altErr error) string {
+	switch {
+	case err == nil && altErr == nil:
+		return ""
+	case err == nil:
+		return fmt.Sprintf("the script succeeded but the alternate "+
+			"engine failed with: %v", altErr)
+	case altErr == nil:
+		return fmt.Sprintf("the script failed with: %v, but the "+
+			"alternate engine succeeded", err)
+	}
+
+	serr, ok := err.(Error)
+	altSerr, altOk := altErr.(Error)
+	if ok && altOk && serr.ErrorCode != altSerr.ErrorCode {
+		return fmt.Sprintf("the script failed with: %v, but the "+
+			"alternate engine failed with: %v", err, altErr)
+	}
+	return ""
+}
+
+// checkAlternate checks the passed script with the alternate engine and panics
+// when its result differs from err, the result of the engine of this package.
+// The panic message holds everything needed to reproduce the divergence.
+func checkAlternate(pkScript []byte, tx *wire.MsgTx, txIdx int, amount int64,
+	flags ScriptFlags, err error) {
+
+	if alternateEngine == nil {
+		return
+	}
+	altErr := alternateEngine(pkScript, tx, txIdx, amount, flags)
+	if msg := divergence(err, altErr); msg != "" {
+		panic(fmt.Sprintf("script engine divergence: %s (input %d of "+
+			"transaction %x spending %d with public key script %x "+
+			"and flags %v)", msg, txIdx, tx.AppendTo(nil), amount,
+			pkScript, flags))
+	}
+}
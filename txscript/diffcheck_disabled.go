@@ -0,0 +1,11 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// +build !scriptdiff
+
+package txscript
+
+// diffCheckEnabled is set when every script is also checked with the
+// alternate engine.
+const diffCheckEnabled = false
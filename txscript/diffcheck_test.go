@@ -0,0 +1,91 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txscript
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// TestDivergence ensures the results of the engine and of an alternate engine
+// are compared as expected.
+func TestDivergence(t *testing.T) {
+	t.Parallel()
+
+	evalFalse := scriptError(ErrEvalFalse, "false stack entry at end of "+
+		"script execution")
+	equalVerify := scriptError(ErrEqualVerify, "OP_EQUALVERIFY failed")
+	other := errors.New("alternate engine error")
+
+	tests := []struct {
+		name    string
+		err     error
+		altErr  error
+		diverge bool
+	}{
+		{"both succeed", nil, nil, false},
+		{"same error code", evalFalse, evalFalse, false},
+		{"alternate fails", nil, evalFalse, true},
+		{"alternate succeeds", evalFalse, nil, true},
+		{"different error codes", evalFalse, equalVerify, true},
+		{"not a script error", evalFalse, other, false},
+	}
+	for _, test := range tests {
+		msg := divergence(test.err, test.altErr)
+		if (msg != "") != test.diverge {
+			t.Errorf("%s: unexpected divergence %q", test.name, msg)
+		}
+	}
+}
+
+// TestCheckAlternate ensures checking a script with an alternate engine panics
+// when the results differ, and only then.
+func TestCheckAlternate(t *testing.T) {
+	// Not parallel since it replaces the alternate engine.
+	defer RegisterAlternateEngine(alternateEngine)
+
+	tx := &wire.MsgTx{
+		Version: 1,
+		TxIn: []*wire.TxIn{{
+			PreviousOutPoint: wire.OutPoint{Index: 1},
+			Sequence:         wire.MaxTxInSequenceNum,
+		}},
+	}
+	pkScript := []byte{OP_TRUE}
+
+	// The default alternate engine agrees with the engine.
+	vm, err := NewEngine(pkScript, tx, 0, 0, nil, nil, 1000)
+	if err != nil {
+		t.Fatalf("NewEngine: unexpected error: %v", err)
+	}
+	if err := vm.Execute(); err != nil {
+		t.Fatalf("Execute: unexpected error: %v", err)
+	}
+	checkAlternate(pkScript, tx, 0, 1000, 0, nil)
+
+	RegisterAlternateEngine(func([]byte, *wire.MsgTx, int, int64,
+		ScriptFlags) error {
+
+		return scriptError(ErrEvalFalse, "always false")
+	})
+	func() {
+		defer func() {
+			msg, _ := recover().(string)
+			if !strings.Contains(msg, "script engine divergence") ||
+				!strings.Contains(msg, "always false") {
+
+				t.Errorf("unexpected panic: %q", msg)
+			}
+		}()
+		checkAlternate(pkScript, tx, 0, 1000, 0, nil)
+	}()
+
+	// No check is made once the alternate engine is unregistered.
+	RegisterAlternateEngine(nil)
+	checkAlternate(pkScript, tx, 0, 1000, 0, nil)
+}
@@ -30,6 +30,21 @@ is used to prove the the spender is authorized to perform the transaction.
 One benefit of using a scripting language is added flexibility in specifying
 what conditions must be met in order to spend bitcoins.
 
+Differential Checking
+
+When built with the scriptdiff build tag, every script executed by the engine
+is also checked with an alternate engine, and the package panics with the
+script, transaction and flags when the results differ.  By default the
+alternate engine is the engine of this package without the signature and hash
+caches, and RegisterAlternateEngine replaces it, for instance with the engine
+of upstream btcd.  Running the reference tests, or syncing a node, built with
+the tag catches changes of consensus behavior:
+
+	go test -tags scriptdiff ./txscript ./blockchain
+	go build -tags scriptdiff
+
+The check is compiled out without the tag.
+
 Errors
 
 Errors returned by this package are of type txscript.Error.  This allows the
@@ -10,6 +10,7 @@ import (
 	"crypto/sha256"
 	"fmt"
 	"math/big"
+	"strings"
 
 	"github.com/pkt-cash/pktd/btcec"
 	"github.com/pkt-cash/pktd/wire"
@@ -118,10 +119,20 @@ var halfOrder = new(big.Int).Rsh(btcec.S256().N, 1)
 
 // Engine is the virtual machine that executes scripts.
 type Engine struct {
-	scripts         [][]parsedOpcode
+	// scripts houses the raw scripts executed by the engine: the signature
+	// script, the public key script and, for pay-to-script-hash and witness
+	// programs, the script they commit to.  They are tokenized one opcode
+	// at a time as they are executed, so they are never parsed into an
+	// intermediate form.
+	//
+	// opcodeIdx is the number of the opcode within the current script and
+	// is only used for disassembly, while lastCodeSep is the byte offset
+	// in the current script following the last OP_CODESEPARATOR.
+	scripts         [][]byte
 	scriptIdx       int
-	scriptOff       int
+	opcodeIdx       int
 	lastCodeSep     int
+	tokenizer       ScriptTokenizer
 	dstack          stack // data stack
 	astack          stack // alt stack
 	tx              wire.MsgTx
@@ -157,23 +168,23 @@ func (vm *Engine) isBranchExecuting() bool {
 // executeOpcode peforms execution on the passed opcode.  It takes into account
 // whether or not it is hidden by conditionals, but some rules still must be
 // tested in this case.
-func (vm *Engine) executeOpcode(pop *parsedOpcode) error {
+func (vm *Engine) executeOpcode(op *opcode, data []byte) error {
 	// Disabled opcodes are fail on program counter.
-	if pop.isDisabled() {
+	if isOpcodeDisabled(op.value) {
 		str := fmt.Sprintf("attempt to execute disabled opcode %s",
-			pop.opcode.name)
+			op.name)
 		return scriptError(ErrDisabledOpcode, str)
 	}
 
 	// Always-illegal opcodes are fail on program counter.
-	if pop.alwaysIllegal() {
+	if isOpcodeAlwaysIllegal(op.value) {
 		str := fmt.Sprintf("attempt to execute reserved opcode %s",
-			pop.opcode.name)
+			op.name)
 		return scriptError(ErrReservedOpcode, str)
 	}
 
 	// Note that this includes OP_RESERVED which counts as a push operation.
-	if pop.opcode.value > OP_16 {
+	if op.value > OP_16 {
 		vm.numOps++
 		if vm.numOps > MaxOpsPerScript {
 			str := fmt.Sprintf("exceeded max operation limit of %d",
@@ -181,65 +192,49 @@ func (vm *Engine) executeOpcode(pop *parsedOpcode) error {
 			return scriptError(ErrTooManyOperations, str)
 		}
 
-	} else if len(pop.data) > MaxScriptElementSize {
+	} else if len(data) > MaxScriptElementSize {
 		str := fmt.Sprintf("element size %d exceeds max allowed size %d",
-			len(pop.data), MaxScriptElementSize)
+			len(data), MaxScriptElementSize)
 		return scriptError(ErrElementTooBig, str)
 	}
 
 	// Nothing left to do when this is not a conditional opcode and it is
 	// not in an executing branch.
-	if !vm.isBranchExecuting() && !pop.isConditional() {
+	if !vm.isBranchExecuting() && !isOpcodeConditional(op.value) {
 		return nil
 	}
 
 	// Ensure all executed data push opcodes use the minimal encoding when
 	// the minimal data verification flag is set.
 	if vm.dstack.verifyMinimalData && vm.isBranchExecuting() &&
-		pop.opcode.value >= 0 && pop.opcode.value <= OP_PUSHDATA4 {
+		op.value <= OP_PUSHDATA4 {
 
-		if err := pop.checkMinimalDataPush(); err != nil {
+		if err := checkMinimalDataPush(op, data); err != nil {
 			return err
 		}
 	}
 
-	return pop.opcode.opfunc(pop, vm)
-}
-
-// disasm is a helper function to produce the output for DisasmPC and
-// DisasmScript.  It produces the opcode prefixed by the program counter at the
-// provided position in the script.  It does no error checking and leaves that
-// to the caller to provide a valid offset.
-func (vm *Engine) disasm(scriptIdx int, scriptOff int) string {
-	return fmt.Sprintf("%02x:%04x: %s", scriptIdx, scriptOff,
-		vm.scripts[scriptIdx][scriptOff].print(false))
+	return op.opfunc(op, data, vm)
 }
 
 // validPC returns an error if the current script position is valid for
-// execution, nil otherwise.
+// execution, nil otherwise.  Positions past the end of the current script are
+// detected by its tokenizer instead.
 func (vm *Engine) validPC() error {
 	if vm.scriptIdx >= len(vm.scripts) {
 		str := fmt.Sprintf("past input scripts %v:%v %v:xxxx",
-			vm.scriptIdx, vm.scriptOff, len(vm.scripts))
-		return scriptError(ErrInvalidProgramCounter, str)
-	}
-	if vm.scriptOff >= len(vm.scripts[vm.scriptIdx]) {
-		str := fmt.Sprintf("past input scripts %v:%v %v:%04d",
-			vm.scriptIdx, vm.scriptOff, vm.scriptIdx,
-			len(vm.scripts[vm.scriptIdx]))
+			vm.scriptIdx, vm.opcodeIdx, len(vm.scripts))
 		return scriptError(ErrInvalidProgramCounter, str)
 	}
 	return nil
 }
 
-// curPC returns either the current script and offset, or an error if the
-// position isn't valid.
-func (vm *Engine) curPC() (script int, off int, err error) {
-	err = vm.validPC()
-	if err != nil {
-		return 0, 0, err
-	}
-	return vm.scriptIdx, vm.scriptOff, nil
+// pastScriptEnd returns the error for an attempt to execute or disassemble an
+// opcode past the end of the current script.
+func (vm *Engine) pastScriptEnd() error {
+	str := fmt.Sprintf("past input scripts %v:%v %v:%04d", vm.scriptIdx,
+		vm.opcodeIdx, vm.scriptIdx, len(vm.scripts[vm.scriptIdx]))
+	return scriptError(ErrInvalidProgramCounter, str)
 }
 
 // isWitnessVersionActive returns true if a witness program was extracted
@@ -249,6 +244,18 @@ func (vm *Engine) isWitnessVersionActive(version uint) bool {
 	return vm.witnessProgram != nil && uint(vm.witnessVersion) == version
 }
 
+// sigHashes returns the BIP0143 sighash midstate of the transaction.  When no
+// midstate was passed to NewEngine, it is computed on first use and kept so
+// every signature check of the input shares it.  Callers validating several
+// inputs of a transaction should pass the same midstate to each engine, as it
+// takes time linear in the size of the transaction to compute.
+func (vm *Engine) sigHashes() *TxSigHashes {
+	if vm.hashCache == nil {
+		vm.hashCache = NewTxSigHashes(&vm.tx)
+	}
+	return vm.hashCache
+}
+
 // verifyWitnessProgram validates the stored witness program using the passed
 // witness as input.
 func (vm *Engine) verifyWitnessProgram(witness [][]byte) error {
@@ -269,15 +276,11 @@ func (vm *Engine) verifyWitnessProgram(witness [][]byte) error {
 			if err != nil {
 				return err
 			}
-			pops, err := parseScript(pkScript)
-			if err != nil {
-				return err
-			}
 
 			// Set the stack to the provided witness stack, then
 			// append the pkScript generated above as the next
 			// script to execute.
-			vm.scripts = append(vm.scripts, pops)
+			vm.scripts = append(vm.scripts, pkScript)
 			vm.SetStack(witness)
 
 		case payToWitnessScriptHashDataSize: // P2WSH
@@ -307,18 +310,17 @@ func (vm *Engine) verifyWitnessProgram(witness [][]byte) error {
 					"witness program hash mismatch")
 			}
 
-			// With all the validity checks passed, parse the
-			// script into individual op-codes so w can execute it
-			// as the next script.
-			pops, err := parseScript(witnessScript)
-			if err != nil {
+			// With all the validity checks passed, ensure the
+			// script parses so it can be executed as the next
+			// script.
+			if err := checkScriptParses(witnessScript); err != nil {
 				return err
 			}
 
 			// The hash matched successfully, so use the witness as
 			// the stack, and set the witnessScript to be the next
 			// script executed.
-			vm.scripts = append(vm.scripts, pops)
+			vm.scripts = append(vm.scripts, witnessScript)
 			vm.SetStack(witness[:len(witness)-1])
 
 		default:
@@ -361,11 +363,26 @@ func (vm *Engine) verifyWitnessProgram(witness [][]byte) error {
 // DisasmPC returns the string for the disassembly of the opcode that will be
 // next to execute when Step() is called.
 func (vm *Engine) DisasmPC() (string, error) {
-	scriptIdx, scriptOff, err := vm.curPC()
-	if err != nil {
+	if err := vm.validPC(); err != nil {
 		return "", err
 	}
-	return vm.disasm(scriptIdx, scriptOff), nil
+
+	// Parse the next opcode with a copy of the tokenizer so the program
+	// counter is left as is.
+	tokenizer := vm.tokenizer
+	if !tokenizer.Next() {
+		// All the scripts were checked to parse before they are
+		// executed, so this can only be the end of the script.
+		if err := tokenizer.Err(); err != nil {
+			return "", err
+		}
+		return "", vm.pastScriptEnd()
+	}
+
+	var buf strings.Builder
+	disasmOpcode(&buf, tokenizer.op, tokenizer.Data(), false)
+	return fmt.Sprintf("%02x:%04x: %s", vm.scriptIdx, vm.opcodeIdx,
+		buf.String()), nil
 }
 
 // DisasmScript returns the disassembly string for the script at the requested
@@ -378,11 +395,14 @@ func (vm *Engine) DisasmScript(idx int) (string, error) {
 		return "", scriptError(ErrInvalidIndex, str)
 	}
 
-	var disstr string
-	for i := range vm.scripts[idx] {
-		disstr = disstr + vm.disasm(idx, i) + "\n"
+	var disbuf strings.Builder
+	tokenizer := MakeScriptTokenizer(0, vm.scripts[idx])
+	for opcodeIdx := 0; tokenizer.Next(); opcodeIdx++ {
+		disbuf.WriteString(fmt.Sprintf("%02x:%04x: ", idx, opcodeIdx))
+		disasmOpcode(&disbuf, tokenizer.op, tokenizer.Data(), false)
+		disbuf.WriteByte('\n')
 	}
-	return disstr, nil
+	return disbuf.String(), nil
 }
 
 // CheckErrorCondition returns nil if the running script has ended and was
@@ -445,13 +465,21 @@ func (vm *Engine) Step() (done bool, err error) {
 	if err != nil {
 		return true, err
 	}
-	opcode := &vm.scripts[vm.scriptIdx][vm.scriptOff]
-	vm.scriptOff++
+
+	// Parse the next opcode of the current script.  All the scripts were
+	// checked to parse before they are executed, so this can only fail
+	// when the program counter is past the end of the script.
+	if !vm.tokenizer.Next() {
+		if err := vm.tokenizer.Err(); err != nil {
+			return true, err
+		}
+		return true, vm.pastScriptEnd()
+	}
 
 	// Execute the opcode while taking into account several things such as
 	// disabled opcodes, illegal opcodes, maximum allowed operations per
 	// script, maximum script element sizes, and conditionals.
-	err = vm.executeOpcode(opcode)
+	err = vm.executeOpcode(vm.tokenizer.op, vm.tokenizer.Data())
 	if err != nil {
 		return true, err
 	}
@@ -466,7 +494,8 @@ func (vm *Engine) Step() (done bool, err error) {
 	}
 
 	// Prepare for next instruction.
-	if vm.scriptOff >= len(vm.scripts[vm.scriptIdx]) {
+	vm.opcodeIdx++
+	if vm.tokenizer.Done() {
 		// Illegal to have an `if' that straddles two scripts.
 		if err == nil && len(vm.condStack) != 0 {
 			return false, scriptError(ErrUnbalancedConditional,
@@ -477,7 +506,7 @@ func (vm *Engine) Step() (done bool, err error) {
 		_ = vm.astack.DropN(vm.astack.Depth())
 
 		vm.numOps = 0 // number of ops is per script.
-		vm.scriptOff = 0
+		vm.opcodeIdx = 0
 		if vm.scriptIdx == 0 && vm.bip16 {
 			vm.scriptIdx++
 			vm.savedFirstStack = vm.GetStack()
@@ -492,11 +521,10 @@ func (vm *Engine) Step() (done bool, err error) {
 			}
 
 			script := vm.savedFirstStack[len(vm.savedFirstStack)-1]
-			pops, err := parseScript(script)
-			if err != nil {
+			if err := checkScriptParses(script); err != nil {
 				return false, err
 			}
-			vm.scripts = append(vm.scripts, pops)
+			vm.scripts = append(vm.scripts, script)
 
 			// Set stack to be the stack from first script minus the
 			// script itself
@@ -514,20 +542,36 @@ func (vm *Engine) Step() (done bool, err error) {
 			vm.scriptIdx++
 		}
 		// there are zero length scripts in the wild
-		if vm.scriptIdx < len(vm.scripts) && vm.scriptOff >= len(vm.scripts[vm.scriptIdx]) {
+		if vm.scriptIdx < len(vm.scripts) && len(vm.scripts[vm.scriptIdx]) == 0 {
 			vm.scriptIdx++
 		}
 		vm.lastCodeSep = 0
 		if vm.scriptIdx >= len(vm.scripts) {
 			return true, nil
 		}
+
+		// Tokenize the next script from its start.
+		vm.tokenizer = MakeScriptTokenizer(0, vm.scripts[vm.scriptIdx])
 	}
 	return false, nil
 }
 
 // Execute will execute all scripts in the script engine and return either nil
 // for successful validation or an error if one occurred.
-func (vm *Engine) Execute() (err error) {
+//
+// When built with the scriptdiff build tag, the script is also checked with
+// the alternate engine, and Execute panics when their results differ.
+func (vm *Engine) Execute() error {
+	err := vm.execute()
+	if diffCheckEnabled {
+		checkAlternate(vm.scripts[1], &vm.tx, vm.txIdx, vm.inputAmount,
+			vm.flags, err)
+	}
+	return err
+}
+
+// execute executes all scripts in the script engine.  See Execute.
+func (vm *Engine) execute() (err error) {
 	done := false
 	for !done {
 		log.Tracef("%v", newLogClosure(func() string {
@@ -561,7 +605,7 @@ func (vm *Engine) Execute() (err error) {
 }
 
 // subScript returns the script since the last OP_CODESEPARATOR.
-func (vm *Engine) subScript() []parsedOpcode {
+func (vm *Engine) subScript() []byte {
 	return vm.scripts[vm.scriptIdx][vm.lastCodeSep:]
 }
 
@@ -852,9 +896,25 @@ func (vm *Engine) SetAltStack(data [][]byte) {
 // NewEngine returns a new script engine for the provided public key script,
 // transaction, and input index.  The flags modify the behavior of the script
 // engine according to the description provided by each flag.
+//
+// When built with the scriptdiff build tag, a script which is rejected before
+// it is executed is also checked with the alternate engine, and NewEngine
+// panics when their results differ.
 func NewEngine(scriptPubKey []byte, tx *wire.MsgTx, txIdx int, flags ScriptFlags,
 	sigCache *SigCache, hashCache *TxSigHashes, inputAmount int64) (*Engine, error) {
 
+	vm, err := newEngine(scriptPubKey, tx, txIdx, flags, sigCache,
+		hashCache, inputAmount)
+	if diffCheckEnabled && err != nil {
+		checkAlternate(scriptPubKey, tx, txIdx, inputAmount, flags, err)
+	}
+	return vm, err
+}
+
+// newEngine returns a new script engine.  See NewEngine.
+func newEngine(scriptPubKey []byte, tx *wire.MsgTx, txIdx int, flags ScriptFlags,
+	sigCache *SigCache, hashCache *TxSigHashes, inputAmount int64) (*Engine, error) {
+
 	// The provided transaction input index must refer to a valid input.
 	if txIdx < 0 || txIdx >= len(tx.TxIn) {
 		str := fmt.Sprintf("transaction input index %d is negative or "+
@@ -897,24 +957,23 @@ func NewEngine(scriptPubKey []byte, tx *wire.MsgTx, txIdx int, flags ScriptFlags
 			"signature script is not push only")
 	}
 
-	// The engine stores the scripts in parsed form using a slice.  This
-	// allows multiple scripts to be executed in sequence.  For example,
-	// with a pay-to-script-hash transaction, there will be ultimately be
-	// a third script to execute.
+	// The engine stores the scripts using a slice.  This allows multiple
+	// scripts to be executed in sequence.  For example, with a
+	// pay-to-script-hash transaction, there will be ultimately be a third
+	// script to execute.  The scripts are tokenized as they are executed,
+	// so they are only checked to parse here.
 	scripts := [][]byte{scriptSig, scriptPubKey}
-	vm.scripts = make([][]parsedOpcode, len(scripts))
-	for i, scr := range scripts {
+	for _, scr := range scripts {
 		if len(scr) > MaxScriptSize {
 			str := fmt.Sprintf("script size %d is larger than max "+
 				"allowed size %d", len(scr), MaxScriptSize)
 			return nil, scriptError(ErrScriptTooBig, str)
 		}
-		var err error
-		vm.scripts[i], err = parseScript(scr)
-		if err != nil {
+		if err := checkScriptParses(scr); err != nil {
 			return nil, err
 		}
 	}
+	vm.scripts = scripts
 
 	// Advance the program counter to the public key script if the signature
 	// script is empty since there is nothing to execute for it in that
@@ -966,11 +1025,10 @@ func NewEngine(scriptPubKey []byte, tx *wire.MsgTx, txIdx int, flags ScriptFlags
 			// The sigScript MUST be *exactly* a single canonical
 			// data push of the witness program, otherwise we
 			// reintroduce malleability.
-			sigPops := vm.scripts[0]
-			if len(sigPops) == 1 && canonicalPush(sigPops[0]) &&
-				IsWitnessProgram(sigPops[0].data) {
+			if data, ok := singleCanonicalPush(scriptSig); ok &&
+				IsWitnessProgram(data) {
 
-				witProgram = sigPops[0].data
+				witProgram = data
 			} else {
 				errStr := "signature script for witness " +
 					"nested p2sh is not canonical"
@@ -1000,5 +1058,8 @@ func NewEngine(scriptPubKey []byte, tx *wire.MsgTx, txIdx int, flags ScriptFlags
 	vm.tx = *tx
 	vm.txIdx = txIdx
 
+	// Start tokenizing the first script to execute.
+	vm.tokenizer = MakeScriptTokenizer(0, vm.scripts[vm.scriptIdx])
+
 	return &vm, nil
 }
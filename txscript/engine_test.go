@@ -61,7 +61,7 @@ func TestBadPC(t *testing.T) {
 
 		// set to after all scripts
 		vm.scriptIdx = test.script
-		vm.scriptOff = test.off
+		vm.tokenizer.offset = int32(test.off)
 
 		_, err = vm.Step()
 		if err == nil {
@@ -0,0 +1,135 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txscript
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkt-cash/pktd/wire"
+)
+
+const (
+	// ConsensusVerifyFlags are all the script flags which are enforced by
+	// the consensus rules once the rule changes introducing them are
+	// active.  Which of them apply to a given block depends on the chain
+	// parameters and the state of the deployments, so they are derived by
+	// the blockchain package rather than used as is.
+	ConsensusVerifyFlags = ScriptBip16 |
+		ScriptVerifyDERSignatures |
+		ScriptVerifyCheckLockTimeVerify |
+		ScriptVerifyCheckSequenceVerify |
+		ScriptVerifyWitness |
+		ScriptStrictMultiSig
+
+	// PolicyVerifyFlags are the script flags which are never enforced by
+	// the consensus rules but only required for transactions to be
+	// considered standard.  These checks help reduce issues related to
+	// transaction malleability and keep the upgrade paths of the script
+	// system open.
+	//
+	// ScriptVerifySigPushOnly is not part of the set since the policy
+	// checks the signature scripts are push only before they are run.
+	PolicyVerifyFlags = ScriptVerifyStrictEncoding |
+		ScriptVerifyMinimalData |
+		ScriptDiscourageUpgradableNops |
+		ScriptVerifyCleanStack |
+		ScriptVerifyNullFail |
+		ScriptVerifyLowS |
+		ScriptVerifyDiscourageUpgradeableWitnessProgram |
+		ScriptVerifyMinimalIf |
+		ScriptVerifyWitnessPubKeyType
+
+	// StandardVerifyFlags are the script flags which are used when
+	// executing transaction scripts to enforce additional checks which
+	// are required for the script to be considered standard.  They are
+	// all the consensus flags along with the policy ones, so they are
+	// stricter than what any block requires.
+	StandardVerifyFlags = ConsensusVerifyFlags | PolicyVerifyFlags
+)
+
+// scriptFlagNames maps the script flags to the names used by the reference
+// tests, in the order the flags are defined.
+var scriptFlagNames = []struct {
+	flag ScriptFlags
+	name string
+}{
+	{ScriptBip16, "P2SH"},
+	{ScriptStrictMultiSig, "NULLDUMMY"},
+	{ScriptDiscourageUpgradableNops, "DISCOURAGE_UPGRADABLE_NOPS"},
+	{ScriptVerifyCheckLockTimeVerify, "CHECKLOCKTIMEVERIFY"},
+	{ScriptVerifyCheckSequenceVerify, "CHECKSEQUENCEVERIFY"},
+	{ScriptVerifyCleanStack, "CLEANSTACK"},
+	{ScriptVerifyDERSignatures, "DERSIG"},
+	{ScriptVerifyLowS, "LOW_S"},
+	{ScriptVerifyMinimalData, "MINIMALDATA"},
+	{ScriptVerifyNullFail, "NULLFAIL"},
+	{ScriptVerifySigPushOnly, "SIGPUSHONLY"},
+	{ScriptVerifyStrictEncoding, "STRICTENC"},
+	{ScriptVerifyWitness, "WITNESS"},
+	{ScriptVerifyDiscourageUpgradeableWitnessProgram,
+		"DISCOURAGE_UPGRADABLE_WITNESS_PROGRAM"},
+	{ScriptVerifyMinimalIf, "MINIMALIF"},
+	{ScriptVerifyWitnessPubKeyType, "WITNESS_PUBKEYTYPE"},
+}
+
+// String returns the names of the flags separated by commas, or NONE when no
+// flag is set.  Unknown flags are shown in hexadecimal.
+func (flags ScriptFlags) String() string {
+	if flags == 0 {
+		return "NONE"
+	}
+	var names []string
+	for _, f := range scriptFlagNames {
+		if flags&f.flag == f.flag {
+			names = append(names, f.name)
+			flags &^= f.flag
+		}
+	}
+	if flags != 0 {
+		names = append(names, fmt.Sprintf("0x%x", uint32(flags)))
+	}
+	return strings.Join(names, ",")
+}
+
+// ParseScriptFlags parses flags separated by commas, as returned by String,
+// into the script flags they name.
+func ParseScriptFlags(flagStr string) (ScriptFlags, error) {
+	var flags ScriptFlags
+	for _, name := range strings.Split(flagStr, ",") {
+		if name == "" || name == "NONE" {
+			continue
+		}
+		found := false
+		for _, f := range scriptFlagNames {
+			if f.name == name {
+				flags |= f.flag
+				found = true
+				break
+			}
+		}
+		if !found {
+			return flags, fmt.Errorf("invalid flag: %s", name)
+		}
+	}
+	return flags, nil
+}
+
+// VerifyScript executes the signature script and the witness of the input at
+// index txIdx of the transaction against the public key script and the amount
+// of the output it spends, under the passed flags.  It returns nil when the
+// script succeeds.
+//
+// The signature and hash caches are optional.
+func VerifyScript(pkScript []byte, tx *wire.MsgTx, txIdx int, amount int64,
+	flags ScriptFlags, sigCache *SigCache, hashCache *TxSigHashes) error {
+
+	vm, err := NewEngine(pkScript, tx, txIdx, flags, sigCache, hashCache,
+		amount)
+	if err != nil {
+		return err
+	}
+	return vm.Execute()
+}
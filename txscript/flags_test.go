@@ -0,0 +1,61 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txscript
+
+import "testing"
+
+// TestScriptFlagSets ensures the consensus and policy flag sets are disjoint
+// and make up the standard flags.
+func TestScriptFlagSets(t *testing.T) {
+	if ConsensusVerifyFlags&PolicyVerifyFlags != 0 {
+		t.Fatalf("flags %v are both consensus and policy flags",
+			ConsensusVerifyFlags&PolicyVerifyFlags)
+	}
+	if StandardVerifyFlags != ConsensusVerifyFlags|PolicyVerifyFlags {
+		t.Fatalf("standard flags %v are not the consensus and policy "+
+			"flags", StandardVerifyFlags)
+	}
+}
+
+// TestScriptFlagsString ensures script flags are named and parsed back.
+func TestScriptFlagsString(t *testing.T) {
+	tests := []struct {
+		flags ScriptFlags
+		str   string
+	}{
+		{0, "NONE"},
+		{ScriptBip16, "P2SH"},
+		{ScriptBip16 | ScriptVerifyWitness | ScriptStrictMultiSig,
+			"P2SH,NULLDUMMY,WITNESS"},
+		{ScriptVerifyWitnessPubKeyType, "WITNESS_PUBKEYTYPE"},
+	}
+	for _, test := range tests {
+		if str := test.flags.String(); str != test.str {
+			t.Errorf("String: got %q, want %q", str, test.str)
+		}
+		flags, err := ParseScriptFlags(test.str)
+		if err != nil {
+			t.Errorf("ParseScriptFlags(%q): %v", test.str, err)
+			continue
+		}
+		if flags != test.flags {
+			t.Errorf("ParseScriptFlags(%q): got %v, want %v",
+				test.str, flags, test.flags)
+		}
+	}
+
+	// Every flag up to the last one defined has a name.
+	for flag := ScriptBip16; flag <= ScriptVerifyWitnessPubKeyType; flag <<= 1 {
+		if _, err := ParseScriptFlags(flag.String()); err != nil {
+			t.Errorf("flag 0x%x: %v", uint32(flag), err)
+		}
+	}
+	if str := (ScriptVerifyWitnessPubKeyType << 1).String(); str != "0x10000" {
+		t.Errorf("unknown flag named %q", str)
+	}
+	if _, err := ParseScriptFlags("P2SH,BOGUS"); err == nil {
+		t.Error("ParseScriptFlags accepted an unknown flag")
+	}
+}
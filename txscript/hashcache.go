@@ -87,3 +87,12 @@ func (h *HashCache) PurgeSigHashes(txid *chainhash.Hash) {
 	delete(h.sigHashes, *txid)
 	h.Unlock()
 }
+
+// Len returns the number of transactions with partial sighashes in the
+// HashCache.
+func (h *HashCache) Len() int {
+	h.RLock()
+	defer h.RUnlock()
+
+	return len(h.sigHashes)
+}
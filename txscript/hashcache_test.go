@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/pkt-cash/pktd/btcec"
 	"github.com/pkt-cash/pktd/wire"
 	"github.com/davecgh/go-spew/spew"
 )
@@ -180,3 +181,71 @@ func TestHashCachePurge(t *testing.T) {
 		}
 	}
 }
+
+// TestEngineSigHashes ensures the engine computes the sighash midstate of a
+// witness input once when none is passed to it, and otherwise uses the one it
+// is passed.
+func TestEngineSigHashes(t *testing.T) {
+	t.Parallel()
+
+	key, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatalf("NewPrivateKey: %v", err)
+	}
+	pubKeyHash := hash160((*btcec.PublicKey)(&key.PublicKey).SerializeCompressed())
+	pkScript, err := NewScriptBuilder().AddOp(OP_0).AddData(pubKeyHash).Script()
+	if err != nil {
+		t.Fatalf("Script: %v", err)
+	}
+	subScript, err := NewScriptBuilder().AddOp(OP_DUP).AddOp(OP_HASH160).
+		AddData(pubKeyHash).AddOp(OP_EQUALVERIFY).AddOp(OP_CHECKSIG).
+		Script()
+	if err != nil {
+		t.Fatalf("Script: %v", err)
+	}
+
+	// Every input of the transaction spends the same amount to the key.
+	const amount = 1e8
+	tx := wire.NewMsgTx(2)
+	for i := 0; i < 3; i++ {
+		tx.AddTxIn(&wire.TxIn{
+			PreviousOutPoint: wire.OutPoint{Index: uint32(i)},
+		})
+	}
+	tx.AddTxOut(wire.NewTxOut(amount, pkScript))
+	sigHashes := NewTxSigHashes(tx)
+	for i, txIn := range tx.TxIn {
+		txIn.Witness, err = WitnessSignature(tx, sigHashes, i, amount,
+			subScript, SigHashAll, key, true)
+		if err != nil {
+			t.Fatalf("WitnessSignature: %v", err)
+		}
+	}
+
+	for i := range tx.TxIn {
+		vm, err := NewEngine(pkScript, tx, i, StandardVerifyFlags, nil,
+			nil, amount)
+		if err != nil {
+			t.Fatalf("NewEngine: %v", err)
+		}
+		if err := vm.Execute(); err != nil {
+			t.Fatalf("input %d: Execute: %v", i, err)
+		}
+		if vm.hashCache == nil || *vm.hashCache != *sigHashes {
+			t.Fatalf("input %d: unexpected midstate %v", i,
+				spew.Sdump(vm.hashCache))
+		}
+
+		vm, err = NewEngine(pkScript, tx, i, StandardVerifyFlags, nil,
+			sigHashes, amount)
+		if err != nil {
+			t.Fatalf("NewEngine: %v", err)
+		}
+		if err := vm.Execute(); err != nil {
+			t.Fatalf("input %d: Execute: %v", i, err)
+		}
+		if vm.hashCache != sigHashes {
+			t.Fatalf("input %d: the passed midstate was not used", i)
+		}
+	}
+}
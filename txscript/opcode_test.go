@@ -23,8 +23,7 @@ func TestOpcodeDisabled(t *testing.T) {
 		OP_LSHIFT, OP_RSHIFT,
 	}
 	for _, opcodeVal := range tests {
-		pop := parsedOpcode{opcode: &opcodeArray[opcodeVal], data: nil}
-		err := opcodeDisabled(&pop, nil)
+		err := opcodeDisabled(&opcodeArray[opcodeVal], nil, nil)
 		if !IsErrorCode(err, ErrDisabledOpcode) {
 			t.Errorf("opcodeDisabled: unexpected error - got %v, "+
 				"want %v", err, ErrDisabledOpcode)
@@ -33,8 +32,8 @@ func TestOpcodeDisabled(t *testing.T) {
 	}
 }
 
-// TestOpcodeDisasm tests the print function for all opcodes in both the oneline
-// and full modes to ensure it provides the expected disassembly.
+// TestOpcodeDisasm tests the disasmOpcode function for all opcodes in both the
+// compact and full modes to ensure it provides the expected disassembly.
 func TestOpcodeDisasm(t *testing.T) {
 	t.Parallel()
 
@@ -127,10 +126,11 @@ func TestOpcodeDisasm(t *testing.T) {
 			expectedStr = "OP_UNKNOWN" + strconv.Itoa(int(opcodeVal))
 		}
 
-		pop := parsedOpcode{opcode: &opcodeArray[opcodeVal], data: data}
-		gotStr := pop.print(true)
+		var buf strings.Builder
+		disasmOpcode(&buf, &opcodeArray[opcodeVal], data, true)
+		gotStr := buf.String()
 		if gotStr != expectedStr {
-			t.Errorf("pop.print (opcode %x): Unexpected disasm "+
+			t.Errorf("disasmOpcode (opcode %x): Unexpected disasm "+
 				"string - got %v, want %v", opcodeVal, gotStr,
 				expectedStr)
 			continue
@@ -193,10 +193,11 @@ func TestOpcodeDisasm(t *testing.T) {
 			expectedStr = "OP_UNKNOWN" + strconv.Itoa(int(opcodeVal))
 		}
 
-		pop := parsedOpcode{opcode: &opcodeArray[opcodeVal], data: data}
-		gotStr := pop.print(false)
+		var buf strings.Builder
+		disasmOpcode(&buf, &opcodeArray[opcodeVal], data, false)
+		gotStr := buf.String()
 		if gotStr != expectedStr {
-			t.Errorf("pop.print (opcode %x): Unexpected disasm "+
+			t.Errorf("disasmOpcode (opcode %x): Unexpected disasm "+
 				"string - got %v, want %v", opcodeVal, gotStr,
 				expectedStr)
 			continue
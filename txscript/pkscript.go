@@ -209,13 +209,11 @@ func computeNonWitnessPkScript(sigScript []byte) (PkScript, error) {
 	// can assume it's a P2SH signature script.
 	default:
 		// The redeem script will always be the last data push of the
-		// signature script, so we'll parse the script into opcodes to
-		// obtain it.
-		parsedOpcodes, err := parseScript(sigScript)
-		if err != nil {
+		// signature script, so we'll tokenize the script to obtain it.
+		if err := checkScriptParses(sigScript); err != nil {
 			return PkScript{}, err
 		}
-		redeemScript := parsedOpcodes[len(parsedOpcodes)-1].data
+		redeemScript := finalOpcodeData(sigScript)
 
 		scriptHash := hash160(redeemScript)
 		script, err := payToScriptHashScript(scriptHash)
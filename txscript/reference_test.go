@@ -150,57 +150,6 @@ func parseShortForm(script string) ([]byte, error) {
 	return builder.Script()
 }
 
-// parseScriptFlags parses the provided flags string from the format used in the
-// reference tests into ScriptFlags suitable for use in the script engine.
-func parseScriptFlags(flagStr string) (ScriptFlags, error) {
-	var flags ScriptFlags
-
-	sFlags := strings.Split(flagStr, ",")
-	for _, flag := range sFlags {
-		switch flag {
-		case "":
-			// Nothing.
-		case "CHECKLOCKTIMEVERIFY":
-			flags |= ScriptVerifyCheckLockTimeVerify
-		case "CHECKSEQUENCEVERIFY":
-			flags |= ScriptVerifyCheckSequenceVerify
-		case "CLEANSTACK":
-			flags |= ScriptVerifyCleanStack
-		case "DERSIG":
-			flags |= ScriptVerifyDERSignatures
-		case "DISCOURAGE_UPGRADABLE_NOPS":
-			flags |= ScriptDiscourageUpgradableNops
-		case "LOW_S":
-			flags |= ScriptVerifyLowS
-		case "MINIMALDATA":
-			flags |= ScriptVerifyMinimalData
-		case "NONE":
-			// Nothing.
-		case "NULLDUMMY":
-			flags |= ScriptStrictMultiSig
-		case "NULLFAIL":
-			flags |= ScriptVerifyNullFail
-		case "P2SH":
-			flags |= ScriptBip16
-		case "SIGPUSHONLY":
-			flags |= ScriptVerifySigPushOnly
-		case "STRICTENC":
-			flags |= ScriptVerifyStrictEncoding
-		case "WITNESS":
-			flags |= ScriptVerifyWitness
-		case "DISCOURAGE_UPGRADABLE_WITNESS_PROGRAM":
-			flags |= ScriptVerifyDiscourageUpgradeableWitnessProgram
-		case "MINIMALIF":
-			flags |= ScriptVerifyMinimalIf
-		case "WITNESS_PUBKEYTYPE":
-			flags |= ScriptVerifyWitnessPubKeyType
-		default:
-			return flags, fmt.Errorf("invalid flag: %s", flag)
-		}
-	}
-	return flags, nil
-}
-
 // parseExpectedResult parses the provided expected result string into allowed
 // script error codes.  An error is returned if the expected result string is
 // not supported.
@@ -416,7 +365,7 @@ func testScripts(t *testing.T, tests [][]interface{}, useSigCache bool) {
 			t.Errorf("%s: flags field is not a string", name)
 			continue
 		}
-		flags, err := parseScriptFlags(flagsStr)
+		flags, err := ParseScriptFlags(flagsStr)
 		if err != nil {
 			t.Errorf("%s: %v", name, err)
 			continue
@@ -568,7 +517,7 @@ testloop:
 			continue
 		}
 
-		flags, err := parseScriptFlags(verifyFlags)
+		flags, err := ParseScriptFlags(verifyFlags)
 		if err != nil {
 			t.Errorf("bad test %d: %v", i, err)
 			continue
@@ -723,7 +672,7 @@ testloop:
 			continue
 		}
 
-		flags, err := parseScriptFlags(verifyFlags)
+		flags, err := ParseScriptFlags(verifyFlags)
 		if err != nil {
 			t.Errorf("bad test %d: %v", i, err)
 			continue
@@ -857,15 +806,14 @@ func TestCalcSignatureHash(t *testing.T) {
 		}
 
 		subScript, _ := hex.DecodeString(test[1].(string))
-		parsedScript, err := parseScript(subScript)
-		if err != nil {
+		if err := checkScriptParses(subScript); err != nil {
 			t.Errorf("TestCalcSignatureHash failed test #%d: "+
 				"Failed to parse sub-script: %v", i, err)
 			continue
 		}
 
 		hashType := SigHashType(testVecF64ToUint32(test[3].(float64)))
-		hash := calcSignatureHash(parsedScript, hashType, &tx,
+		hash := calcSignatureHash(subScript, hashType, &tx,
 			int(test[2].(float64)))
 
 		expectedHash, _ := chainhash.NewHashFromStr(test[4].(string))
@@ -9,6 +9,7 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/pkt-cash/pktd/chaincfg/chainhash"
@@ -45,69 +46,81 @@ const (
 
 // isSmallInt returns whether or not the opcode is considered a small integer,
 // which is an OP_0, or OP_1 through OP_16.
-func isSmallInt(op *opcode) bool {
-	if op.value == OP_0 || (op.value >= OP_1 && op.value <= OP_16) {
-		return true
-	}
-	return false
+func isSmallInt(op byte) bool {
+	return op == OP_0 || (op >= OP_1 && op <= OP_16)
 }
 
 // isScriptHash returns true if the script passed is a pay-to-script-hash
 // transaction, false otherwise.
-func isScriptHash(pops []parsedOpcode) bool {
-	return len(pops) == 3 &&
-		pops[0].opcode.value == OP_HASH160 &&
-		pops[1].opcode.value == OP_DATA_20 &&
-		pops[2].opcode.value == OP_EQUAL
+func isScriptHash(script []byte) bool {
+	// A pay-to-script-hash script is of the form:
+	//  OP_HASH160 <20-byte hash> OP_EQUAL
+	return len(script) == 23 &&
+		script[0] == OP_HASH160 &&
+		script[1] == OP_DATA_20 &&
+		script[22] == OP_EQUAL
 }
 
 // IsPayToScriptHash returns true if the script is in the standard
 // pay-to-script-hash (P2SH) format, false otherwise.
 func IsPayToScriptHash(script []byte) bool {
-	pops, err := parseScript(script)
-	if err != nil {
-		return false
-	}
-	return isScriptHash(pops)
+	return isScriptHash(script)
 }
 
 // isWitnessScriptHash returns true if the passed script is a
 // pay-to-witness-script-hash transaction, false otherwise.
-func isWitnessScriptHash(pops []parsedOpcode) bool {
-	return len(pops) == 2 &&
-		pops[0].opcode.value == OP_0 &&
-		pops[1].opcode.value == OP_DATA_32
+func isWitnessScriptHash(script []byte) bool {
+	// A pay-to-witness-script-hash script is of the form:
+	//  OP_0 <32-byte hash>
+	return len(script) == 34 &&
+		script[0] == OP_0 &&
+		script[1] == OP_DATA_32
 }
 
 // ElectionGetVotesForAgainst gets the candidates who are voted for and voted against
 // by the provided pkScript
 func ElectionGetVotesForAgainst(pkScript []byte) (voteFor []byte, voteAgainst []byte) {
-	pops, err := parseScript(pkScript)
-	if err != nil {
-		return nil, nil
-	}
-	for i, op := range pops {
-		if op.opcode.value != OP_VOTE {
-			continue
-		}
-		if i < 2 {
-			// invalid, too short
-			continue
+	// votePush returns the candidate pushed by a vote opcode argument and
+	// whether it is a valid argument.  OP_0 is valid and votes for no
+	// candidate.
+	votePush := func(op byte, data []byte) ([]byte, bool) {
+		if op == OP_0 {
+			return nil, true
 		}
-		if pops[i-1].opcode.value == OP_0 {
-		} else if canonicalPush(pops[i-1]) && len(pops[i-1].data) > 0 && len(pops[i-1].data) < 80 {
-			voteAgainst = pops[i-1].data
-		} else {
-			continue
+		if isCanonicalPush(op, data) && len(data) > 0 && len(data) < 80 {
+			return data, true
 		}
-		if pops[i-2].opcode.value == OP_0 {
-		} else if canonicalPush(pops[i-2]) && len(pops[i-2].data) > 0 && len(pops[i-2].data) < 80 {
-			voteFor = pops[i-2].data
-		} else {
-			voteAgainst = nil
-			continue
+		return nil, false
+	}
+
+	// The arguments of a vote are the two opcodes preceding it, so keep
+	// track of them while tokenizing.
+	var (
+		numOps              int
+		prevOp, prev2Op     byte
+		prevData, prev2Data []byte
+		found               bool
+	)
+	tokenizer := MakeScriptTokenizer(0, pkScript)
+	for tokenizer.Next() {
+		op, data := tokenizer.Opcode(), tokenizer.Data()
+		if !found && op == OP_VOTE && numOps >= 2 {
+			against, ok := votePush(prevOp, prevData)
+			if ok {
+				var vFor []byte
+				vFor, ok = votePush(prev2Op, prev2Data)
+				if ok {
+					voteFor, voteAgainst = vFor, against
+					found = true
+				}
+			}
 		}
-		break
+		numOps++
+		prev2Op, prev2Data = prevOp, prevData
+		prevOp, prevData = op, data
+	}
+	if tokenizer.Err() != nil {
+		return nil, nil
 	}
 	return
 }
@@ -115,29 +128,23 @@ func ElectionGetVotesForAgainst(pkScript []byte) (voteFor []byte, voteAgainst []
 // IsPayToWitnessScriptHash returns true if the is in the standard
 // pay-to-witness-script-hash (P2WSH) format, false otherwise.
 func IsPayToWitnessScriptHash(script []byte) bool {
-	pops, err := parseScript(script)
-	if err != nil {
-		return false
-	}
-	return isWitnessScriptHash(pops)
+	return isWitnessScriptHash(script)
 }
 
 // IsPayToWitnessPubKeyHash returns true if the is in the standard
 // pay-to-witness-pubkey-hash (P2WKH) format, false otherwise.
 func IsPayToWitnessPubKeyHash(script []byte) bool {
-	pops, err := parseScript(script)
-	if err != nil {
-		return false
-	}
-	return isWitnessPubKeyHash(pops)
+	return isWitnessPubKeyHash(script)
 }
 
 // isWitnessPubKeyHash returns true if the passed script is a
 // pay-to-witness-pubkey-hash, and false otherwise.
-func isWitnessPubKeyHash(pops []parsedOpcode) bool {
-	return len(pops) == 2 &&
-		pops[0].opcode.value == OP_0 &&
-		pops[1].opcode.value == OP_DATA_20
+func isWitnessPubKeyHash(script []byte) bool {
+	// A pay-to-witness-pubkey-hash script is of the form:
+	//  OP_0 <20-byte hash>
+	return len(script) == 22 &&
+		script[0] == OP_0 &&
+		script[1] == OP_DATA_20
 }
 
 // IsWitnessProgram returns true if the passed script is a valid witness
@@ -145,6 +152,16 @@ func isWitnessPubKeyHash(pops []parsedOpcode) bool {
 // witness program must be a small integer (from 0-16), followed by 2-40 bytes
 // of pushed data.
 func IsWitnessProgram(script []byte) bool {
+	return isWitnessProgram(script)
+}
+
+// isWitnessProgram returns true if the passed script is a witness program, and
+// false otherwise. A witness program MUST adhere to the following constraints:
+// there must be exactly two opcodes (program version and the program itself),
+// the first opcode MUST be a small integer (0-16), the push data MUST be
+// canonical, and finally the size of the push data must be between 2 and 40
+// bytes.
+func isWitnessProgram(script []byte) bool {
 	// The length of the script must be between 4 and 42 bytes. The
 	// smallest program is the witness version, followed by a data push of
 	// 2 bytes.  The largest allowed witness program has a data push of
@@ -153,183 +170,94 @@ func IsWitnessProgram(script []byte) bool {
 		return false
 	}
 
-	pops, err := parseScript(script)
-	if err != nil {
-		return false
-	}
-
-	return isWitnessProgram(pops)
-}
-
-// isWitnessProgram returns true if the passed script is a witness program, and
-// false otherwise. A witness program MUST adhere to the following constraints:
-// there must be exactly two pops (program version and the program itself), the
-// first opcode MUST be a small integer (0-16), the push data MUST be
-// canonical, and finally the size of the push data must be between 2 and 40
-// bytes.
-func isWitnessProgram(pops []parsedOpcode) bool {
-	return len(pops) == 2 &&
-		isSmallInt(pops[0].opcode) &&
-		canonicalPush(pops[1]) &&
-		(len(pops[1].data) >= 2 && len(pops[1].data) <= 40)
+	// The only canonical pushes of 2 to 40 bytes are OP_DATA_2 through
+	// OP_DATA_40, so the program must be one of them spanning the rest of
+	// the script.
+	return isSmallInt(script[0]) &&
+		script[1] >= OP_DATA_2 && script[1] <= OP_DATA_40 &&
+		int(script[1]) == len(script)-2
 }
 
 // ExtractWitnessProgramInfo attempts to extract the witness program version,
 // as well as the witness program itself from the passed script.
 func ExtractWitnessProgramInfo(script []byte) (int, []byte, error) {
-	pops, err := parseScript(script)
-	if err != nil {
-		return 0, nil, err
-	}
-
 	// If at this point, the scripts doesn't resemble a witness program,
 	// then we'll exit early as there isn't a valid version or program to
 	// extract.
-	if !isWitnessProgram(pops) {
+	if !isWitnessProgram(script) {
 		return 0, nil, fmt.Errorf("script is not a witness program, " +
 			"unable to extract version or witness program")
 	}
 
-	witnessVersion := asSmallInt(pops[0].opcode)
-	witnessProgram := pops[1].data
+	witnessVersion := asSmallInt(script[0])
+	witnessProgram := script[2:]
 
 	return witnessVersion, witnessProgram, nil
 }
 
 // isPushOnly returns true if the script only pushes data, false otherwise.
-func isPushOnly(pops []parsedOpcode) bool {
-	// NOTE: This function does NOT verify opcodes directly since it is
-	// internal and is only called with parsed opcodes for scripts that did
-	// not have any parse errors.  Thus, consensus is properly maintained.
-
-	for _, pop := range pops {
+// Scripts which do not parse are not push only.
+func isPushOnly(script []byte) bool {
+	tokenizer := MakeScriptTokenizer(0, script)
+	for tokenizer.Next() {
 		// All opcodes up to OP_16 are data push instructions.
 		// NOTE: This does consider OP_RESERVED to be a data push
 		// instruction, but execution of OP_RESERVED will fail anyways
 		// and matches the behavior required by consensus.
-		if pop.opcode.value > OP_16 {
+		if tokenizer.Opcode() > OP_16 {
 			return false
 		}
 	}
-	return true
+	return tokenizer.Err() == nil
 }
 
 // IsPushOnlyScript returns whether or not the passed script only pushes data.
 //
 // False will be returned when the script does not parse.
 func IsPushOnlyScript(script []byte) bool {
-	pops, err := parseScript(script)
-	if err != nil {
-		return false
-	}
-	return isPushOnly(pops)
+	return isPushOnly(script)
 }
 
-// parseScriptTemplate is the same as parseScript but allows the passing of the
-// template list for testing purposes.  When there are parse errors, it returns
-// the list of parsed opcodes up to the point of failure along with the error.
-func parseScriptTemplate(script []byte, opcodes *[256]opcode) ([]parsedOpcode, error) {
-	retScript := make([]parsedOpcode, 0, len(script))
-	for i := 0; i < len(script); {
-		instr := script[i]
-		op := &opcodes[instr]
-		pop := parsedOpcode{opcode: op}
-
-		// Parse data out of instruction.
-		switch {
-		// No additional data.  Note that some of the opcodes, notably
-		// OP_1NEGATE, OP_0, and OP_[1-16] represent the data
-		// themselves.
-		case op.length == 1:
-			i++
-
-		// Data pushes of specific lengths -- OP_DATA_[1-75].
-		case op.length > 1:
-			if len(script[i:]) < op.length {
-				str := fmt.Sprintf("opcode %s requires %d "+
-					"bytes, but script only has %d remaining",
-					op.name, op.length, len(script[i:]))
-				return retScript, scriptError(ErrMalformedPush,
-					str)
-			}
-
-			// Slice out the data.
-			pop.data = script[i+1 : i+op.length]
-			i += op.length
-
-		// Data pushes with parsed lengths -- OP_PUSHDATAP{1,2,4}.
-		case op.length < 0:
-			var l uint
-			off := i + 1
-
-			if len(script[off:]) < -op.length {
-				str := fmt.Sprintf("opcode %s requires %d "+
-					"bytes, but script only has %d remaining",
-					op.name, -op.length, len(script[off:]))
-				return retScript, scriptError(ErrMalformedPush,
-					str)
-			}
-
-			// Next -length bytes are little endian length of data.
-			switch op.length {
-			case -1:
-				l = uint(script[off])
-			case -2:
-				l = ((uint(script[off+1]) << 8) |
-					uint(script[off]))
-			case -4:
-				l = ((uint(script[off+3]) << 24) |
-					(uint(script[off+2]) << 16) |
-					(uint(script[off+1]) << 8) |
-					uint(script[off]))
-			default:
-				str := fmt.Sprintf("invalid opcode length %d",
-					op.length)
-				return retScript, scriptError(ErrMalformedPush,
-					str)
-			}
-
-			// Move offset to beginning of the data.
-			off += -op.length
-
-			// Disallow entries that do not fit script or were
-			// sign extended.
-			if int(l) > len(script[off:]) || int(l) < 0 {
-				str := fmt.Sprintf("opcode %s pushes %d bytes, "+
-					"but script only has %d remaining",
-					op.name, int(l), len(script[off:]))
-				return retScript, scriptError(ErrMalformedPush,
-					str)
-			}
-
-			pop.data = script[off : off+int(l)]
-			i += 1 - op.length + int(l)
-		}
-
-		retScript = append(retScript, pop)
+// checkScriptParses returns an error if the provided script fails to parse.
+func checkScriptParses(script []byte) error {
+	tokenizer := MakeScriptTokenizer(0, script)
+	for tokenizer.Next() {
+		// Nothing to do.
 	}
-
-	return retScript, nil
+	return tokenizer.Err()
 }
 
-// parseScript preparses the script in bytes into a list of parsedOpcodes while
-// applying a number of sanity checks.
-func parseScript(script []byte) ([]parsedOpcode, error) {
-	return parseScriptTemplate(script, &opcodeArray)
+// finalOpcodeData returns the data associated with the final opcode in the
+// script.  It will return nil if the script fails to parse.
+func finalOpcodeData(script []byte) []byte {
+	// Avoid unnecessary work.
+	if len(script) == 0 {
+		return nil
+	}
+
+	var data []byte
+	tokenizer := MakeScriptTokenizer(0, script)
+	for tokenizer.Next() {
+		data = tokenizer.Data()
+	}
+	if tokenizer.Err() != nil {
+		return nil
+	}
+	return data
 }
 
-// unparseScript reversed the action of parseScript and returns the
-// parsedOpcodes as a list of bytes
-func unparseScript(pops []parsedOpcode) ([]byte, error) {
-	script := make([]byte, 0, len(pops))
-	for _, pop := range pops {
-		b, err := pop.bytes()
-		if err != nil {
-			return nil, err
-		}
-		script = append(script, b...)
+// singleCanonicalPush returns the data pushed by the script and true when the
+// script is exactly one canonical data push.
+func singleCanonicalPush(script []byte) ([]byte, bool) {
+	tokenizer := MakeScriptTokenizer(0, script)
+	if !tokenizer.Next() {
+		return nil, false
+	}
+	op, data := tokenizer.Opcode(), tokenizer.Data()
+	if !tokenizer.Done() || !isCanonicalPush(op, data) {
+		return nil, false
 	}
-	return script, nil
+	return data, true
 }
 
 // DisasmString formats a disassembled script for one line printing.  When the
@@ -337,41 +265,57 @@ func unparseScript(pops []parsedOpcode) ([]byte, error) {
 // script up to the point the failure occurred along with the string '[error]'
 // appended.  In addition, the reason the script failed to parse is returned
 // if the caller wants more information about the failure.
-func DisasmString(buf []byte) (string, error) {
-	var disbuf bytes.Buffer
-	opcodes, err := parseScript(buf)
-	for _, pop := range opcodes {
-		disbuf.WriteString(pop.print(true))
-		disbuf.WriteByte(' ')
-	}
-	if disbuf.Len() > 0 {
-		disbuf.Truncate(disbuf.Len() - 1)
+func DisasmString(script []byte) (string, error) {
+	var disbuf strings.Builder
+	tokenizer := MakeScriptTokenizer(0, script)
+	for tokenizer.Next() {
+		if disbuf.Len() > 0 {
+			disbuf.WriteByte(' ')
+		}
+		disasmOpcode(&disbuf, tokenizer.op, tokenizer.Data(), true)
 	}
-	if err != nil {
+	if err := tokenizer.Err(); err != nil {
 		disbuf.WriteString("[error]")
+		return disbuf.String(), err
 	}
-	return disbuf.String(), err
+	return disbuf.String(), nil
 }
 
-// removeOpcode will remove any opcode matching ``opcode'' from the opcode
-// stream in pkscript
-func removeOpcode(pkscript []parsedOpcode, opcode byte) []parsedOpcode {
-	retScript := make([]parsedOpcode, 0, len(pkscript))
-	for _, pop := range pkscript {
-		if pop.opcode.value != opcode {
-			retScript = append(retScript, pop)
+// removeOpcode returns the script minus any opcodes matching the passed
+// opcode.  The script is returned as is, without allocating, when it has no
+// such opcode.  The script MUST already be known to parse.
+func removeOpcode(script []byte, opcode byte) []byte {
+	// Avoid work when possible.
+	if len(script) == 0 {
+		return script
+	}
+
+	// Only allocate a new script once an opcode to remove is found.
+	var result []byte
+	var prevOffset int32
+	tokenizer := MakeScriptTokenizer(0, script)
+	for tokenizer.Next() {
+		if tokenizer.Opcode() == opcode {
+			if result == nil {
+				result = make([]byte, 0, len(script))
+				result = append(result, script[:prevOffset]...)
+			}
+		} else if result != nil {
+			result = append(result, script[prevOffset:tokenizer.ByteIndex()]...)
 		}
+		prevOffset = tokenizer.ByteIndex()
 	}
-	return retScript
+	if result == nil {
+		return script
+	}
+	return result
 }
 
-// canonicalPush returns true if the object is either not a push instruction
-// or the push instruction contained wherein is matches the canonical form
-// or using the smallest instruction to do the job. False otherwise.
-func canonicalPush(pop parsedOpcode) bool {
-	opcode := pop.opcode.value
-	data := pop.data
-	dataLen := len(pop.data)
+// isCanonicalPush returns true if the opcode is either not a push instruction
+// or the data it pushes is pushed in the canonical form, using the smallest
+// instruction to do the job.  False otherwise.
+func isCanonicalPush(opcode byte, data []byte) bool {
+	dataLen := len(data)
 	if opcode > OP_16 {
 		return true
 	}
@@ -392,16 +336,36 @@ func canonicalPush(pop parsedOpcode) bool {
 }
 
 // removeOpcodeByData will return the script minus any opcodes that would push
-// the passed data to the stack.
-func removeOpcodeByData(pkscript []parsedOpcode, data []byte) []parsedOpcode {
-	retScript := make([]parsedOpcode, 0, len(pkscript))
-	for _, pop := range pkscript {
-		if !canonicalPush(pop) || !bytes.Contains(pop.data, data) {
-			retScript = append(retScript, pop)
+// the passed data to the stack.  The script is returned as is, without
+// allocating, when it has no such push, which is by far the common case as
+// this is used to remove signatures from the scripts they sign.  The script
+// MUST already be known to parse.
+func removeOpcodeByData(script []byte, data []byte) []byte {
+	// Avoid work when possible.
+	if len(script) == 0 {
+		return script
+	}
+
+	// Only allocate a new script once a push to remove is found.
+	var result []byte
+	var prevOffset int32
+	tokenizer := MakeScriptTokenizer(0, script)
+	for tokenizer.Next() {
+		op, pushed := tokenizer.Opcode(), tokenizer.Data()
+		if isCanonicalPush(op, pushed) && bytes.Contains(pushed, data) {
+			if result == nil {
+				result = make([]byte, 0, len(script))
+				result = append(result, script[:prevOffset]...)
+			}
+		} else if result != nil {
+			result = append(result, script[prevOffset:tokenizer.ByteIndex()]...)
 		}
+		prevOffset = tokenizer.ByteIndex()
 	}
-	return retScript
-
+	if result == nil {
+		return script
+	}
+	return result
 }
 
 // calcHashPrevOuts calculates a single hash of all the previous outputs
@@ -469,7 +433,7 @@ func calcHashOutputs(tx *wire.MsgTx) chainhash.Hash {
 // being spent, in addition to the final transaction fee. In the case the
 // wallet if fed an invalid input amount, the real sighash will differ causing
 // the produced signature to be invalid.
-func calcWitnessSignatureHash(subScript []parsedOpcode, sigHashes *TxSigHashes,
+func calcWitnessSignatureHash(subScript []byte, sigHashes *TxSigHashes,
 	hashType SigHashType, tx *wire.MsgTx, idx int, amt int64) ([]byte, error) {
 
 	// As a sanity check, ensure the passed input index for the transaction
@@ -527,15 +491,14 @@ func calcWitnessSignatureHash(subScript []parsedOpcode, sigHashes *TxSigHashes,
 		sigHash.Write([]byte{OP_DUP})
 		sigHash.Write([]byte{OP_HASH160})
 		sigHash.Write([]byte{OP_DATA_20})
-		sigHash.Write(subScript[1].data)
+		sigHash.Write(subScript[2:22])
 		sigHash.Write([]byte{OP_EQUALVERIFY})
 		sigHash.Write([]byte{OP_CHECKSIG})
 	} else {
 		// For p2wsh outputs, and future outputs, the script code is
 		// the original script, with all code separators removed,
 		// serialized with a var int length prefix.
-		rawScript, _ := unparseScript(subScript)
-		wire.WriteVarBytes(&sigHash, 0, rawScript)
+		wire.WriteVarBytes(&sigHash, 0, subScript)
 	}
 
 	// Next, add the input amount, and sequence number of the input being
@@ -579,13 +542,11 @@ func calcWitnessSignatureHash(subScript []parsedOpcode, sigHashes *TxSigHashes,
 func CalcWitnessSigHash(script []byte, sigHashes *TxSigHashes, hType SigHashType,
 	tx *wire.MsgTx, idx int, amt int64) ([]byte, error) {
 
-	parsedScript, err := parseScript(script)
-	if err != nil {
+	if err := checkScriptParses(script); err != nil {
 		return nil, fmt.Errorf("cannot parse output script: %v", err)
 	}
 
-	return calcWitnessSignatureHash(parsedScript, sigHashes, hType, tx, idx,
-		amt)
+	return calcWitnessSignatureHash(script, sigHashes, hType, tx, idx, amt)
 }
 
 // shallowCopyTx creates a shallow copy of the transaction for use when
@@ -620,17 +581,16 @@ func shallowCopyTx(tx *wire.MsgTx) wire.MsgTx {
 // engine instance, calculate the signature hash to be used for signing and
 // verification.
 func CalcSignatureHash(script []byte, hashType SigHashType, tx *wire.MsgTx, idx int) ([]byte, error) {
-	parsedScript, err := parseScript(script)
-	if err != nil {
+	if err := checkScriptParses(script); err != nil {
 		return nil, fmt.Errorf("cannot parse output script: %v", err)
 	}
-	return calcSignatureHash(parsedScript, hashType, tx, idx), nil
+	return calcSignatureHash(script, hashType, tx, idx), nil
 }
 
 // calcSignatureHash will, given a script and hash type for the current script
 // engine instance, calculate the signature hash to be used for signing and
-// verification.
-func calcSignatureHash(script []parsedOpcode, hashType SigHashType, tx *wire.MsgTx, idx int) []byte {
+// verification.  The script MUST already be known to parse.
+func calcSignatureHash(script []byte, hashType SigHashType, tx *wire.MsgTx, idx int) []byte {
 	// The SigHashSingle signature type signs only the corresponding input
 	// and output (the output with the same index number as the input).
 	//
@@ -665,10 +625,7 @@ func calcSignatureHash(script []parsedOpcode, hashType SigHashType, tx *wire.Msg
 	txCopy := shallowCopyTx(tx)
 	for i := range txCopy.TxIn {
 		if i == idx {
-			// UnparseScript cannot fail here because removeOpcode
-			// above only returns a valid script.
-			sigScript, _ := unparseScript(script)
-			txCopy.TxIn[idx].SignatureScript = sigScript
+			txCopy.TxIn[idx].SignatureScript = script
 		} else {
 			txCopy.TxIn[i].SignatureScript = nil
 		}
@@ -724,22 +681,25 @@ func calcSignatureHash(script []parsedOpcode, hashType SigHashType, tx *wire.Msg
 
 // asSmallInt returns the passed opcode, which must be true according to
 // isSmallInt(), as an integer.
-func asSmallInt(op *opcode) int {
-	if op.value == OP_0 {
+func asSmallInt(op byte) int {
+	if op == OP_0 {
 		return 0
 	}
 
-	return int(op.value - (OP_1 - 1))
+	return int(op - (OP_1 - 1))
 }
 
 // getSigOpCount is the implementation function for counting the number of
-// signature operations in the script provided by pops. If precise mode is
-// requested then we attempt to count the number of operations for a multisig
-// op. Otherwise we use the maximum.
-func getSigOpCount(pops []parsedOpcode, precise bool) int {
+// signature operations in the script provided. If precise mode is requested
+// then we attempt to count the number of operations for a multisig op.
+// Otherwise we use the maximum.  If the script fails to parse, then the count
+// up to the point of failure is returned.
+func getSigOpCount(script []byte, precise bool) int {
 	nSigs := 0
-	for i, pop := range pops {
-		switch pop.opcode.value {
+	prevOp := byte(OP_INVALIDOPCODE)
+	tokenizer := MakeScriptTokenizer(0, script)
+	for tokenizer.Next() {
+		switch tokenizer.Opcode() {
 		case OP_CHECKSIG:
 			fallthrough
 		case OP_CHECKSIGVERIFY:
@@ -751,16 +711,15 @@ func getSigOpCount(pops []parsedOpcode, precise bool) int {
 			// patterns for multisig, for now all we recognize is
 			// OP_1 - OP_16 to signify the number of pubkeys.
 			// Otherwise, we use the max of 20.
-			if precise && i > 0 &&
-				pops[i-1].opcode.value >= OP_1 &&
-				pops[i-1].opcode.value <= OP_16 {
-				nSigs += asSmallInt(pops[i-1].opcode)
+			if precise && prevOp >= OP_1 && prevOp <= OP_16 {
+				nSigs += asSmallInt(prevOp)
 			} else {
 				nSigs += MaxPubKeysPerMultiSig
 			}
 		default:
 			// Not a sigop.
 		}
+		prevOp = tokenizer.Opcode()
 	}
 
 	return nSigs
@@ -771,10 +730,7 @@ func getSigOpCount(pops []parsedOpcode, precise bool) int {
 // If the script fails to parse, then the count up to the point of failure is
 // returned.
 func GetSigOpCount(script []byte) int {
-	// Don't check error since parseScript returns the parsed-up-to-error
-	// list of pops.
-	pops, _ := parseScript(script)
-	return getSigOpCount(pops, false)
+	return getSigOpCount(script, false)
 }
 
 // GetPreciseSigOpCount returns the number of signature operations in
@@ -783,43 +739,29 @@ func GetSigOpCount(script []byte) int {
 // operations in the transaction.  If the script fails to parse, then the count
 // up to the point of failure is returned.
 func GetPreciseSigOpCount(scriptSig, scriptPubKey []byte, bip16 bool) int {
-	// Don't check error since parseScript returns the parsed-up-to-error
-	// list of pops.
-	pops, _ := parseScript(scriptPubKey)
-
 	// Treat non P2SH transactions as normal.
-	if !(bip16 && isScriptHash(pops)) {
-		return getSigOpCount(pops, true)
-	}
-
-	// The public key script is a pay-to-script-hash, so parse the signature
-	// script to get the final item.  Scripts that fail to fully parse count
-	// as 0 signature operations.
-	sigPops, err := parseScript(scriptSig)
-	if err != nil {
-		return 0
+	if !(bip16 && isScriptHash(scriptPubKey)) {
+		return getSigOpCount(scriptPubKey, true)
 	}
 
 	// The signature script must only push data to the stack for P2SH to be
 	// a valid pair, so the signature operation count is 0 when that is not
-	// the case.
-	if !isPushOnly(sigPops) || len(sigPops) == 0 {
+	// the case.  Scripts that fail to fully parse are not push only and
+	// so count as 0 signature operations.
+	if len(scriptSig) == 0 || !isPushOnly(scriptSig) {
 		return 0
 	}
 
 	// The P2SH script is the last item the signature script pushes to the
 	// stack.  When the script is empty, there are no signature operations.
-	shScript := sigPops[len(sigPops)-1].data
+	shScript := finalOpcodeData(scriptSig)
 	if len(shScript) == 0 {
 		return 0
 	}
 
-	// Parse the P2SH script and don't check the error since parseScript
-	// returns the parsed-up-to-error list of pops and the consensus rules
-	// dictate signature operations are counted up to the first parse
-	// failure.
-	shPops, _ := parseScript(shScript)
-	return getSigOpCount(shPops, true)
+	// The consensus rules dictate signature operations are counted up to
+	// the first parse failure of the P2SH script.
+	return getSigOpCount(shScript, true)
 }
 
 // GetWitnessSigOpCount returns the number of signature operations generated by
@@ -838,11 +780,7 @@ func GetWitnessSigOpCount(sigScript, pkScript []byte, witness wire.TxWitness) in
 	// Next, we'll check the sigScript to see if this is a nested p2sh
 	// witness program. This is a case wherein the sigScript is actually a
 	// datapush of a p2wsh witness program.
-	sigPops, err := parseScript(sigScript)
-	if err != nil {
-		return 0
-	}
-	if IsPayToScriptHash(pkScript) && isPushOnly(sigPops) &&
+	if IsPayToScriptHash(pkScript) && isPushOnly(sigScript) &&
 		IsWitnessProgram(sigScript[1:]) {
 		return getWitnessSigOps(sigScript[1:], witness)
 	}
@@ -873,8 +811,7 @@ func getWitnessSigOps(pkScript []byte, witness wire.TxWitness) int {
 			len(witness) > 0:
 
 			witnessScript := witness[len(witness)-1]
-			pops, _ := parseScript(witnessScript)
-			return getSigOpCount(pops, true)
+			return getSigOpCount(witnessScript, true)
 		}
 	}
 
@@ -885,10 +822,8 @@ func getWitnessSigOps(pkScript []byte, witness wire.TxWitness) int {
 // guaranteed to fail at execution.  This allows inputs to be pruned instantly
 // when entering the UTXO set.
 func IsUnspendable(pkScript []byte) bool {
-	pops, err := parseScript(pkScript)
-	if err != nil {
-		return true
-	}
-
-	return len(pops) > 0 && pops[0].opcode.value == OP_RETURN
+	// The script is unspendable when it starts with OP_RETURN or does not
+	// parse.
+	return (len(pkScript) > 0 && pkScript[0] == OP_RETURN) ||
+		checkScriptParses(pkScript) != nil
 }
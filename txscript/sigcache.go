@@ -97,3 +97,13 @@ func (s *SigCache) Add(sigHash chainhash.Hash, sig *btcec.Signature, pubKey *btc
 	}
 	s.validSigs[sigHash] = sigCacheEntry{sig, pubKey}
 }
+
+// Len returns the number of entries in the SigCache.
+//
+// NOTE: This function is safe for concurrent access.
+func (s *SigCache) Len() int {
+	s.RLock()
+	defer s.RUnlock()
+
+	return len(s.validSigs)
+}
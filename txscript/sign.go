@@ -17,17 +17,18 @@ import (
 // RawTxInWitnessSignature returns the serialized ECDA signature for the input
 // idx of the given transaction, with the hashType appended to it. This
 // function is identical to RawTxInSignature, however the signature generated
-// signs a new sighash digest defined in BIP0143.
+// signs a new sighash digest defined in BIP0143.  The sighash midstate should
+// be computed once with NewTxSigHashes and shared by all the inputs signed, so
+// signing a transaction takes time linear in its size.
 func RawTxInWitnessSignature(tx *wire.MsgTx, sigHashes *TxSigHashes, idx int,
 	amt int64, subScript []byte, hashType SigHashType,
 	key *btcec.PrivateKey) ([]byte, error) {
 
-	parsedScript, err := parseScript(subScript)
-	if err != nil {
+	if err := checkScriptParses(subScript); err != nil {
 		return nil, fmt.Errorf("cannot parse output script: %v", err)
 	}
 
-	hash, err := calcWitnessSignatureHash(parsedScript, sigHashes, hashType, tx,
+	hash, err := calcWitnessSignatureHash(subScript, sigHashes, hashType, tx,
 		idx, amt)
 	if err != nil {
 		return nil, err
@@ -230,27 +231,21 @@ func mergeScripts(chainParams *chaincfg.Params, tx *wire.MsgTx, idx int,
 	case ScriptHashTy:
 		// Remove the last push in the script and then recurse.
 		// this could be a lot less inefficient.
-		sigPops, err := parseScript(sigScript)
-		if err != nil || len(sigPops) == 0 {
+		if len(sigScript) == 0 || checkScriptParses(sigScript) != nil {
 			return prevScript
 		}
-		prevPops, err := parseScript(prevScript)
-		if err != nil || len(prevPops) == 0 {
+		if len(prevScript) == 0 || checkScriptParses(prevScript) != nil {
 			return sigScript
 		}
 
-		// assume that script in sigPops is the correct one, we just
+		// assume that script in sigScript is the correct one, we just
 		// made it.
-		script := sigPops[len(sigPops)-1].data
+		script := finalOpcodeData(sigScript)
 
 		// We already know this information somewhere up the stack.
 		class, addresses, nrequired, _ :=
 			ExtractPkScriptAddrs(script, chainParams)
 
-		// regenerate scripts.
-		sigScript, _ := unparseScript(sigPops)
-		prevScript, _ := unparseScript(prevPops)
-
 		// Merge
 		mergedScript := mergeScripts(chainParams, tx, idx, script,
 			class, addresses, nrequired, sigScript, prevScript)
@@ -288,34 +283,29 @@ func mergeScripts(chainParams *chaincfg.Params, tx *wire.MsgTx, idx int,
 func mergeMultiSig(tx *wire.MsgTx, idx int, addresses []btcutil.Address,
 	nRequired int, pkScript, sigScript, prevScript []byte) []byte {
 
-	// This is an internal only function and we already parsed this script
-	// as ok for multisig (this is how we got here), so if this fails then
-	// all assumptions are broken and who knows which way is up?
-	pkPops, _ := parseScript(pkScript)
-
-	sigPops, err := parseScript(sigScript)
-	if err != nil || len(sigPops) == 0 {
+	// This is an internal only function and we already parsed pkScript
+	// as ok for multisig (this is how we got here).
+	if len(sigScript) == 0 || checkScriptParses(sigScript) != nil {
 		return prevScript
 	}
-
-	prevPops, err := parseScript(prevScript)
-	if err != nil || len(prevPops) == 0 {
+	if len(prevScript) == 0 || checkScriptParses(prevScript) != nil {
 		return sigScript
 	}
 
 	// Convenience function to avoid duplication.
-	extractSigs := func(pops []parsedOpcode, sigs [][]byte) [][]byte {
-		for _, pop := range pops {
-			if len(pop.data) != 0 {
-				sigs = append(sigs, pop.data)
+	extractSigs := func(script []byte, sigs [][]byte) [][]byte {
+		tokenizer := MakeScriptTokenizer(0, script)
+		for tokenizer.Next() {
+			if data := tokenizer.Data(); len(data) != 0 {
+				sigs = append(sigs, data)
 			}
 		}
 		return sigs
 	}
 
-	possibleSigs := make([][]byte, 0, len(sigPops)+len(prevPops))
-	possibleSigs = extractSigs(sigPops, possibleSigs)
-	possibleSigs = extractSigs(prevPops, possibleSigs)
+	var possibleSigs [][]byte
+	possibleSigs = extractSigs(sigScript, possibleSigs)
+	possibleSigs = extractSigs(prevScript, possibleSigs)
 
 	// Now we need to match the signatures to pubkeys, the only real way to
 	// do that is to try to verify them all and match it to the pubkey
@@ -345,7 +335,7 @@ sigLoop:
 		// however, assume no sigs etc are in the script since that
 		// would make the transaction nonstandard and thus not
 		// MultiSigTy, so we just need to hash the full thing.
-		hash := calcSignatureHash(pkPops, hashType, tx, idx)
+		hash := calcSignatureHash(pkScript, hashType, tx, idx)
 
 		for _, addr := range addresses {
 			// All multisig addresses should be pubkey addresses
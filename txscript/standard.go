@@ -7,6 +7,7 @@ package txscript
 
 import (
 	"fmt"
+	"math"
 
 	"github.com/pkt-cash/btcutil"
 	"github.com/pkt-cash/pktd/chaincfg"
@@ -17,33 +18,6 @@ const (
 	// MaxDataCarrierSize is the maximum number of bytes allowed in pushed
 	// data to be considered a nulldata transaction
 	MaxDataCarrierSize = 80
-
-	// StandardVerifyFlags are the script flags which are used when
-	// executing transaction scripts to enforce additional checks which
-	// are required for the script to be considered standard.  These checks
-	// help reduce issues related to transaction malleability as well as
-	// allow pay-to-script hash transactions.  Note these flags are
-	// different than what is required for the consensus rules in that they
-	// are more strict.
-	//
-	// TODO: This definition does not belong here.  It belongs in a policy
-	// package.
-	StandardVerifyFlags = ScriptBip16 |
-		ScriptVerifyDERSignatures |
-		ScriptVerifyStrictEncoding |
-		ScriptVerifyMinimalData |
-		ScriptStrictMultiSig |
-		ScriptDiscourageUpgradableNops |
-		ScriptVerifyCleanStack |
-		ScriptVerifyNullFail |
-		ScriptVerifyCheckLockTimeVerify |
-		ScriptVerifyCheckSequenceVerify |
-		ScriptVerifyLowS |
-		ScriptStrictMultiSig |
-		ScriptVerifyWitness |
-		ScriptVerifyDiscourageUpgradeableWitnessProgram |
-		ScriptVerifyMinimalIf |
-		ScriptVerifyWitnessPubKeyType
 )
 
 // ScriptClass is an enumeration for the list of standard types of script.
@@ -84,95 +58,159 @@ func (t ScriptClass) String() string {
 	return scriptClassToName[t]
 }
 
+// extractPubKey extracts the public key from the passed script if it is a
+// standard pay-to-pubkey script.  It will return nil otherwise.
+func extractPubKey(script []byte) []byte {
+	// A pay-to-pubkey script is of the form:
+	//  <pubkey> OP_CHECKSIG
+	// where valid pubkeys are either 33 or 65 bytes.
+	tokenizer := MakeScriptTokenizer(0, script)
+	if !tokenizer.Next() {
+		return nil
+	}
+	pubKey := tokenizer.Data()
+	if len(pubKey) != 33 && len(pubKey) != 65 {
+		return nil
+	}
+	if !tokenizer.Next() || tokenizer.Opcode() != OP_CHECKSIG ||
+		!tokenizer.Done() {
+
+		return nil
+	}
+	return pubKey
+}
+
 // isPubkey returns true if the script passed is a pay-to-pubkey transaction,
 // false otherwise.
-func isPubkey(pops []parsedOpcode) bool {
-	// Valid pubkeys are either 33 or 65 bytes.
-	return len(pops) == 2 &&
-		(len(pops[0].data) == 33 || len(pops[0].data) == 65) &&
-		pops[1].opcode.value == OP_CHECKSIG
+func isPubkey(script []byte) bool {
+	return extractPubKey(script) != nil
 }
 
 // isPubkeyHash returns true if the script passed is a pay-to-pubkey-hash
 // transaction, false otherwise.
-func isPubkeyHash(pops []parsedOpcode) bool {
-	return len(pops) == 5 &&
-		pops[0].opcode.value == OP_DUP &&
-		pops[1].opcode.value == OP_HASH160 &&
-		pops[2].opcode.value == OP_DATA_20 &&
-		pops[3].opcode.value == OP_EQUALVERIFY &&
-		pops[4].opcode.value == OP_CHECKSIG
-
-}
-
-// isMultiSig returns true if the passed script is a multisig transaction, false
-// otherwise.
-func isMultiSig(pops []parsedOpcode) bool {
+func isPubkeyHash(script []byte) bool {
+	// A pay-to-pubkey-hash script is of the form:
+	//  OP_DUP OP_HASH160 <20-byte hash> OP_EQUALVERIFY OP_CHECKSIG
+	return len(script) == 25 &&
+		script[0] == OP_DUP &&
+		script[1] == OP_HASH160 &&
+		script[2] == OP_DATA_20 &&
+		script[23] == OP_EQUALVERIFY &&
+		script[24] == OP_CHECKSIG
+}
+
+// multiSigDetails houses details extracted from a standard multisig script.
+type multiSigDetails struct {
+	requiredSigs int
+	numPubKeys   int
+	pubKeys      [][]byte
+	valid        bool
+}
+
+// extractMultiSigDetails attempts to extract details from the passed script if
+// it is a standard multisig script.  The returned details struct will have the
+// valid flag set to false otherwise.
+//
+// The extract pubkeys flag indicates whether or not the pubkeys themselves
+// should also be extracted and is provided because extracting them results in
+// an allocation that the caller might wish to avoid.  The pubKeys member of
+// the returned details struct will be nil when the flag is false.
+func extractMultiSigDetails(script []byte, extractPubKeys bool) multiSigDetails {
+	// A multi-signature script is of the form:
+	//  NUM_SIGS PUBKEY PUBKEY PUBKEY ... NUM_PUBKEYS OP_CHECKMULTISIG
+	//
 	// The absolute minimum is 1 pubkey:
-	// OP_0/OP_1-16 <pubkey> OP_1 OP_CHECKMULTISIG
-	l := len(pops)
-	if l < 4 {
-		return false
-	}
-	if !isSmallInt(pops[0].opcode) {
-		return false
+	//  OP_0/OP_1-16 <pubkey> OP_1 OP_CHECKMULTISIG
+	tokenizer := MakeScriptTokenizer(0, script)
+	if !tokenizer.Next() || !isSmallInt(tokenizer.Opcode()) {
+		return multiSigDetails{}
 	}
-	if !isSmallInt(pops[l-2].opcode) {
-		return false
+	requiredSigs := asSmallInt(tokenizer.Opcode())
+
+	// Valid pubkeys are either 33 or 65 bytes.
+	var pubKeys [][]byte
+	numPubKeys := 0
+	for tokenizer.Next() {
+		data := tokenizer.Data()
+		if len(data) != 33 && len(data) != 65 {
+			break
+		}
+		numPubKeys++
+		if extractPubKeys {
+			pubKeys = append(pubKeys, data)
+		}
 	}
-	if pops[l-1].opcode.value != OP_CHECKMULTISIG {
-		return false
+	if tokenizer.Err() != nil || numPubKeys == 0 {
+		return multiSigDetails{}
 	}
 
 	// Verify the number of pubkeys specified matches the actual number
 	// of pubkeys provided.
-	if l-2-1 != asSmallInt(pops[l-2].opcode) {
-		return false
+	op := tokenizer.Opcode()
+	if !isSmallInt(op) || asSmallInt(op) != numPubKeys {
+		return multiSigDetails{}
 	}
+	if !tokenizer.Next() || tokenizer.Opcode() != OP_CHECKMULTISIG ||
+		!tokenizer.Done() {
 
-	for _, pop := range pops[1 : l-2] {
-		// Valid pubkeys are either 33 or 65 bytes.
-		if len(pop.data) != 33 && len(pop.data) != 65 {
-			return false
-		}
+		return multiSigDetails{}
+	}
+
+	return multiSigDetails{
+		requiredSigs: requiredSigs,
+		numPubKeys:   numPubKeys,
+		pubKeys:      pubKeys,
+		valid:        true,
 	}
-	return true
+}
+
+// isMultiSig returns true if the passed script is a multisig transaction, false
+// otherwise.
+func isMultiSig(script []byte) bool {
+	// Since this is only checking the form of the script, don't extract the
+	// public keys to avoid the allocation.
+	return extractMultiSigDetails(script, false).valid
 }
 
 // isNullData returns true if the passed script is a null data transaction,
 // false otherwise.
-func isNullData(pops []parsedOpcode) bool {
+func isNullData(script []byte) bool {
 	// A nulldata transaction is either a single OP_RETURN or an
 	// OP_RETURN SMALLDATA (where SMALLDATA is a data push up to
 	// MaxDataCarrierSize bytes).
-	l := len(pops)
-	if l == 1 && pops[0].opcode.value == OP_RETURN {
+	if len(script) == 0 || script[0] != OP_RETURN {
+		return false
+	}
+	if len(script) == 1 {
 		return true
 	}
 
-	return l == 2 &&
-		pops[0].opcode.value == OP_RETURN &&
-		(isSmallInt(pops[1].opcode) || pops[1].opcode.value <=
-			OP_PUSHDATA4) &&
-		len(pops[1].data) <= MaxDataCarrierSize
+	tokenizer := MakeScriptTokenizer(0, script[1:])
+	if !tokenizer.Next() {
+		return false
+	}
+	op := tokenizer.Opcode()
+	return (isSmallInt(op) || op <= OP_PUSHDATA4) &&
+		len(tokenizer.Data()) <= MaxDataCarrierSize &&
+		tokenizer.Done() && tokenizer.Err() == nil
 }
 
 // scriptType returns the type of the script being inspected from the known
 // standard types.
-func typeOfScript(pops []parsedOpcode) ScriptClass {
-	if isPubkey(pops) {
+func typeOfScript(script []byte) ScriptClass {
+	if isPubkey(script) {
 		return PubKeyTy
-	} else if isPubkeyHash(pops) {
+	} else if isPubkeyHash(script) {
 		return PubKeyHashTy
-	} else if isWitnessPubKeyHash(pops) {
+	} else if isWitnessPubKeyHash(script) {
 		return WitnessV0PubKeyHashTy
-	} else if isScriptHash(pops) {
+	} else if isScriptHash(script) {
 		return ScriptHashTy
-	} else if isWitnessScriptHash(pops) {
+	} else if isWitnessScriptHash(script) {
 		return WitnessV0ScriptHashTy
-	} else if isMultiSig(pops) {
+	} else if isMultiSig(script) {
 		return MultiSigTy
-	} else if isNullData(pops) {
+	} else if isNullData(script) {
 		return NullDataTy
 	}
 	return NonStandardTy
@@ -182,20 +220,18 @@ func typeOfScript(pops []parsedOpcode) ScriptClass {
 //
 // NonStandardTy will be returned when the script does not parse.
 func GetScriptClass(script []byte) ScriptClass {
-	pops, err := parseScript(script)
-	if err != nil {
+	if checkScriptParses(script) != nil {
 		return NonStandardTy
 	}
-	pops = stripVote(pops)
-	return typeOfScript(pops)
+	return typeOfScript(stripVote(script))
 }
 
 // expectedInputs returns the number of arguments required by a script.
 // If the script is of unknown type such that the number can not be determined
 // then -1 is returned. We are an internal function and thus assume that class
-// is the real class of pops (and we can thus assume things that were determined
-// while finding out the type).
-func expectedInputs(pops []parsedOpcode, class ScriptClass) int {
+// is the real class of the script (and we can thus assume things that were
+// determined while finding out the type).
+func expectedInputs(script []byte, class ScriptClass) int {
 	switch class {
 	case PubKeyTy:
 		return 1
@@ -222,7 +258,7 @@ func expectedInputs(pops []parsedOpcode, class ScriptClass) int {
 		// the original bitcoind bug where OP_CHECKMULTISIG pops an
 		// additional item from the stack, add an extra expected input
 		// for the extra push that is required to compensate.
-		return asSmallInt(pops[0].opcode) + 1
+		return asSmallInt(script[0]) + 1
 
 	case NullDataTy:
 		fallthrough
@@ -257,50 +293,55 @@ type ScriptInfo struct {
 func CalcScriptInfo(sigScript, pkScript []byte, witness wire.TxWitness,
 	bip16, segwit bool) (*ScriptInfo, error) {
 
-	sigPops, err := parseScript(sigScript)
-	if err != nil {
+	// Count the number of opcodes in the signature script while also
+	// ensuring it parses.  Since the script is checked to be push only
+	// below, this equates to the number of inputs to the public key script.
+	var numInputs int
+	tokenizer := MakeScriptTokenizer(0, sigScript)
+	for tokenizer.Next() {
+		numInputs++
+	}
+	if err := tokenizer.Err(); err != nil {
 		return nil, err
 	}
 
-	pkPops, err := parseScript(pkScript)
-	if err != nil {
+	if err := checkScriptParses(pkScript); err != nil {
 		return nil, err
 	}
 
 	// Push only sigScript makes little sense.
 	si := new(ScriptInfo)
-	si.PkScriptClass = typeOfScript(pkPops)
+	si.PkScriptClass = typeOfScript(pkScript)
 
 	// Can't have a signature script that doesn't just push data.
-	if !isPushOnly(sigPops) {
+	if !isPushOnly(sigScript) {
 		return nil, scriptError(ErrNotPushOnly,
 			"signature script is not push only")
 	}
 
-	si.ExpectedInputs = expectedInputs(pkPops, si.PkScriptClass)
+	si.ExpectedInputs = expectedInputs(pkScript, si.PkScriptClass)
 
 	switch {
 	// Count sigops taking into account pay-to-script-hash.
 	case si.PkScriptClass == ScriptHashTy && bip16 && !segwit:
 		// The pay-to-hash-script is the final data push of the
 		// signature script.
-		script := sigPops[len(sigPops)-1].data
-		shPops, err := parseScript(script)
-		if err != nil {
+		script := finalOpcodeData(sigScript)
+		if err := checkScriptParses(script); err != nil {
 			return nil, err
 		}
 
-		shInputs := expectedInputs(shPops, typeOfScript(shPops))
+		shInputs := expectedInputs(script, typeOfScript(script))
 		if shInputs == -1 {
 			si.ExpectedInputs = -1
 		} else {
 			si.ExpectedInputs += shInputs
 		}
-		si.SigOps = getSigOpCount(shPops, true)
+		si.SigOps = getSigOpCount(script, true)
 
 		// All entries pushed to stack (or are OP_RESERVED and exec
 		// will fail).
-		si.NumInputs = len(sigPops)
+		si.NumInputs = numInputs
 
 	// If segwit is active, and this is a regular p2wkh output, then we'll
 	// treat the script as a p2pkh output in essence.
@@ -316,8 +357,9 @@ func CalcScriptInfo(sigScript, pkScript []byte, witness wire.TxWitness,
 
 		// Extract the pushed witness program from the sigScript so we
 		// can determine the number of expected inputs.
-		pkPops, _ := parseScript(sigScript[1:])
-		shInputs := expectedInputs(pkPops, typeOfScript(pkPops))
+		witnessProgram := sigScript[1:]
+		shInputs := expectedInputs(witnessProgram,
+			typeOfScript(witnessProgram))
 		if shInputs == -1 {
 			si.ExpectedInputs = -1
 		} else {
@@ -327,7 +369,7 @@ func CalcScriptInfo(sigScript, pkScript []byte, witness wire.TxWitness,
 		si.SigOps = GetWitnessSigOpCount(sigScript, pkScript, witness)
 
 		si.NumInputs = len(witness)
-		si.NumInputs += len(sigPops)
+		si.NumInputs += numInputs
 
 	// If segwit is active, and this is a p2wsh output, then we'll need to
 	// examine the witness script to generate accurate script info.
@@ -335,9 +377,9 @@ func CalcScriptInfo(sigScript, pkScript []byte, witness wire.TxWitness,
 		// The witness script is the final element of the witness
 		// stack.
 		witnessScript := witness[len(witness)-1]
-		pops, _ := parseScript(witnessScript)
 
-		shInputs := expectedInputs(pops, typeOfScript(pops))
+		shInputs := expectedInputs(witnessScript,
+			typeOfScript(witnessScript))
 		if shInputs == -1 {
 			si.ExpectedInputs = -1
 		} else {
@@ -348,11 +390,11 @@ func CalcScriptInfo(sigScript, pkScript []byte, witness wire.TxWitness,
 		si.NumInputs = len(witness)
 
 	default:
-		si.SigOps = getSigOpCount(pkPops, true)
+		si.SigOps = getSigOpCount(pkScript, true)
 
 		// All entries pushed to stack (or are OP_RESERVED and exec
 		// will fail).
-		si.NumInputs = len(sigPops)
+		si.NumInputs = numInputs
 	}
 
 	return si, nil
@@ -362,11 +404,6 @@ func CalcScriptInfo(sigScript, pkScript []byte, witness wire.TxWitness,
 // a multi-signature transaction script.  The passed script MUST already be
 // known to be a multi-signature script.
 func CalcMultiSigStats(script []byte) (int, int, error) {
-	pops, err := parseScript(script)
-	if err != nil {
-		return 0, 0, err
-	}
-
 	// A multi-signature script is of the pattern:
 	//  NUM_SIGS PUBKEY PUBKEY PUBKEY... NUM_PUBKEYS OP_CHECKMULTISIG
 	// Therefore the number of signatures is the oldest item on the stack
@@ -374,13 +411,26 @@ func CalcMultiSigStats(script []byte) (int, int, error) {
 	// minimum for a multi-signature script is 1 pubkey, so at least 4
 	// items must be on the stack per:
 	//  OP_1 PUBKEY OP_1 OP_CHECKMULTISIG
-	if len(pops) < 4 {
+	var numOps int
+	var firstOp, secondLastOp, lastOp byte
+	tokenizer := MakeScriptTokenizer(0, script)
+	for tokenizer.Next() {
+		if numOps == 0 {
+			firstOp = tokenizer.Opcode()
+		}
+		secondLastOp, lastOp = lastOp, tokenizer.Opcode()
+		numOps++
+	}
+	if err := tokenizer.Err(); err != nil {
+		return 0, 0, err
+	}
+	if numOps < 4 {
 		str := fmt.Sprintf("script %x is not a multisig script", script)
 		return 0, 0, scriptError(ErrNotMultisigScript, str)
 	}
 
-	numSigs := asSmallInt(pops[0].opcode)
-	numPubKeys := asSmallInt(pops[len(pops)-2].opcode)
+	numSigs := asSmallInt(firstOp)
+	numPubKeys := asSmallInt(secondLastOp)
 	return numPubKeys, numSigs, nil
 }
 
@@ -494,27 +544,49 @@ func PayToAddrScript(addr btcutil.Address) ([]byte, error) {
 }
 
 // stripVote removes any votes from a script so that it will appear as a cannonical
-// transaction.
-func stripVote(pops []parsedOpcode) []parsedOpcode {
-	out := make([]parsedOpcode, 0, len(pops))
-	for _, pop := range pops {
-		out = append(out, pop)
-		if pop.opcode.value != OP_VOTE {
-			continue
-		}
-		if len(out) < 3 {
-			continue
+// transaction.  The script is returned as is, without allocating, when it has
+// no vote.  The script MUST already be known to parse.
+func stripVote(script []byte) []byte {
+	hasVote := false
+	tokenizer := MakeScriptTokenizer(0, script)
+	for tokenizer.Next() {
+		if tokenizer.Opcode() == OP_VOTE {
+			hasVote = true
+			break
 		}
-		last := out[len(out)-2]
-		secondLast := out[len(out)-3]
-		if last.opcode.value != OP_0 && !canonicalPush(last) {
+	}
+	if !hasVote {
+		return script
+	}
+
+	// keptOp is an opcode kept in the stripped script along with its
+	// offset there and whether it may be an argument of a vote.
+	type keptOp struct {
+		offset  int
+		voteArg bool
+	}
+	out := make([]byte, 0, len(script))
+	kept := make([]keptOp, 0, len(script))
+	var prevOffset int32
+	tokenizer = MakeScriptTokenizer(0, script)
+	for tokenizer.Next() {
+		op, data := tokenizer.Opcode(), tokenizer.Data()
+		kept = append(kept, keptOp{
+			offset:  len(out),
+			voteArg: op == OP_0 || isCanonicalPush(op, data),
+		})
+		out = append(out, script[prevOffset:tokenizer.ByteIndex()]...)
+		prevOffset = tokenizer.ByteIndex()
+
+		if op != OP_VOTE || len(kept) < 3 {
 			continue
 		}
-		if secondLast.opcode.value != OP_0 && !canonicalPush(secondLast) {
+		if !kept[len(kept)-2].voteArg || !kept[len(kept)-3].voteArg {
 			continue
 		}
 		// pop second op, last op, vote
-		out = out[:len(out)-3]
+		out = out[:kept[len(kept)-3].offset]
+		kept = kept[:len(kept)-3]
 	}
 	return out
 }
@@ -532,6 +604,30 @@ func NullDataScript(data []byte) ([]byte, error) {
 	return NewScriptBuilder().AddOp(OP_RETURN).AddData(data).Script()
 }
 
+// NullDataPayloadSize returns the total number of bytes pushed by the passed
+// script and true when it is a data carrier script, that is an OP_RETURN
+// followed only by data pushes.  Unlike the nulldata script class, the script
+// may push any amount of data, in any number of pushes, so the caller can
+// apply its own limit.
+func NullDataPayloadSize(script []byte) (int, bool) {
+	if len(script) == 0 || script[0] != OP_RETURN {
+		return 0, false
+	}
+
+	size := 0
+	tokenizer := MakeScriptTokenizer(0, script[1:])
+	for tokenizer.Next() {
+		if tokenizer.Opcode() > OP_16 {
+			return 0, false
+		}
+		size += len(tokenizer.Data())
+	}
+	if tokenizer.Err() != nil {
+		return 0, false
+	}
+	return size, true
+}
+
 // MultiSigScript returns a valid script for a multisignature redemption where
 // nrequired of the keys in pubkeys are required to have signed the transaction
 // for success.  An Error with the error code ErrTooManyRequiredSigs will be
@@ -557,19 +653,18 @@ func MultiSigScript(pubkeys []*btcutil.AddressPubKey, nrequired int) ([]byte, er
 // PushedData returns an array of byte slices containing any pushed data found
 // in the passed script.  This includes OP_0, but not OP_1 - OP_16.
 func PushedData(script []byte) ([][]byte, error) {
-	pops, err := parseScript(script)
-	if err != nil {
-		return nil, err
-	}
-
 	var data [][]byte
-	for _, pop := range pops {
-		if pop.data != nil {
-			data = append(data, pop.data)
-		} else if pop.opcode.value == OP_0 {
+	tokenizer := MakeScriptTokenizer(0, script)
+	for tokenizer.Next() {
+		if tokenizer.Data() != nil {
+			data = append(data, tokenizer.Data())
+		} else if tokenizer.Opcode() == OP_0 {
 			data = append(data, nil)
 		}
 	}
+	if err := tokenizer.Err(); err != nil {
+		return nil, err
+	}
 	return data, nil
 }
 
@@ -583,14 +678,13 @@ func ExtractPkScriptAddrs(pkScript []byte, chainParams *chaincfg.Params) (Script
 
 	// No valid addresses or required signatures if the script doesn't
 	// parse.
-	pops, err := parseScript(pkScript)
-	if err != nil {
+	if err := checkScriptParses(pkScript); err != nil {
 		return NonStandardTy, nil, 0, err
 	}
 
-	pops = stripVote(pops)
+	pkScript = stripVote(pkScript)
 
-	scriptClass := typeOfScript(pops)
+	scriptClass := typeOfScript(pkScript)
 	switch scriptClass {
 	case PubKeyHashTy:
 		// A pay-to-pubkey-hash script is of the form:
@@ -598,7 +692,7 @@ func ExtractPkScriptAddrs(pkScript []byte, chainParams *chaincfg.Params) (Script
 		// Therefore the pubkey hash is the 3rd item on the stack.
 		// Skip the pubkey hash if it's invalid for some reason.
 		requiredSigs = 1
-		addr, err := btcutil.NewAddressPubKeyHash(pops[2].data,
+		addr, err := btcutil.NewAddressPubKeyHash(pkScript[3:23],
 			chainParams)
 		if err == nil {
 			addrs = append(addrs, addr)
@@ -610,7 +704,7 @@ func ExtractPkScriptAddrs(pkScript []byte, chainParams *chaincfg.Params) (Script
 		// Therefore, the pubkey hash is the second item on the stack.
 		// Skip the pubkey hash if it's invalid for some reason.
 		requiredSigs = 1
-		addr, err := btcutil.NewAddressWitnessPubKeyHash(pops[1].data,
+		addr, err := btcutil.NewAddressWitnessPubKeyHash(pkScript[2:22],
 			chainParams)
 		if err == nil {
 			addrs = append(addrs, addr)
@@ -622,7 +716,8 @@ func ExtractPkScriptAddrs(pkScript []byte, chainParams *chaincfg.Params) (Script
 		// Therefore the pubkey is the first item on the stack.
 		// Skip the pubkey if it's invalid for some reason.
 		requiredSigs = 1
-		addr, err := btcutil.NewAddressPubKey(pops[0].data, chainParams)
+		addr, err := btcutil.NewAddressPubKey(extractPubKey(pkScript),
+			chainParams)
 		if err == nil {
 			addrs = append(addrs, addr)
 		}
@@ -633,7 +728,7 @@ func ExtractPkScriptAddrs(pkScript []byte, chainParams *chaincfg.Params) (Script
 		// Therefore the script hash is the 2nd item on the stack.
 		// Skip the script hash if it's invalid for some reason.
 		requiredSigs = 1
-		addr, err := btcutil.NewAddressScriptHashFromHash(pops[1].data,
+		addr, err := btcutil.NewAddressScriptHashFromHash(pkScript[2:22],
 			chainParams)
 		if err == nil {
 			addrs = append(addrs, addr)
@@ -645,7 +740,7 @@ func ExtractPkScriptAddrs(pkScript []byte, chainParams *chaincfg.Params) (Script
 		// Therefore, the script hash is the second item on the stack.
 		// Skip the script hash if it's invalid for some reason.
 		requiredSigs = 1
-		addr, err := btcutil.NewAddressWitnessScriptHash(pops[1].data,
+		addr, err := btcutil.NewAddressWitnessScriptHash(pkScript[2:34],
 			chainParams)
 		if err == nil {
 			addrs = append(addrs, addr)
@@ -657,14 +752,13 @@ func ExtractPkScriptAddrs(pkScript []byte, chainParams *chaincfg.Params) (Script
 		// Therefore the number of required signatures is the 1st item
 		// on the stack and the number of public keys is the 2nd to last
 		// item on the stack.
-		requiredSigs = asSmallInt(pops[0].opcode)
-		numPubKeys := asSmallInt(pops[len(pops)-2].opcode)
+		details := extractMultiSigDetails(pkScript, true)
+		requiredSigs = details.requiredSigs
 
 		// Extract the public keys while skipping any that are invalid.
-		addrs = make([]btcutil.Address, 0, numPubKeys)
-		for i := 0; i < numPubKeys; i++ {
-			addr, err := btcutil.NewAddressPubKey(pops[i+1].data,
-				chainParams)
+		addrs = make([]btcutil.Address, 0, details.numPubKeys)
+		for _, pubKey := range details.pubKeys {
+			addr, err := btcutil.NewAddressPubKey(pubKey, chainParams)
 			if err == nil {
 				addrs = append(addrs, addr)
 			}
@@ -703,63 +797,187 @@ type AtomicSwapDataPushes struct {
 // This function is only defined in the txscript package due to API limitations
 // which prevent callers using txscript to parse nonstandard scripts.
 func ExtractAtomicSwapDataPushes(version uint16, pkScript []byte) (*AtomicSwapDataPushes, error) {
-	pops, err := parseScript(pkScript)
-	if err != nil {
+	// An atomic swap is of the form:
+	//  IF
+	//   SIZE <secret size> EQUALVERIFY SHA256 <32-byte secret> EQUALVERIFY DUP
+	//   HASH160 <20-byte recipient hash>
+	//  ELSE
+	//   <locktime> CHECKLOCKTIMEVERIFY DROP DUP HASH160 <20-byte refund hash>
+	//  ENDIF
+	//  EQUALVERIFY CHECKSIG
+	//
+	// The integers are matched by canonicalIntPush rather than by opcode.
+	type templateMatch struct {
+		expectInt     bool
+		opcode        byte
+		extractedInt  int64
+		extractedData []byte
+	}
+	var template = [20]templateMatch{
+		{opcode: OP_IF},
+		{opcode: OP_SIZE},
+		{expectInt: true},
+		{opcode: OP_EQUALVERIFY},
+		{opcode: OP_SHA256},
+		{opcode: OP_DATA_32},
+		{opcode: OP_EQUALVERIFY},
+		{opcode: OP_DUP},
+		{opcode: OP_HASH160},
+		{opcode: OP_DATA_20},
+		{opcode: OP_ELSE},
+		{expectInt: true},
+		{opcode: OP_CHECKLOCKTIMEVERIFY},
+		{opcode: OP_DROP},
+		{opcode: OP_DUP},
+		{opcode: OP_HASH160},
+		{opcode: OP_DATA_20},
+		{opcode: OP_ENDIF},
+		{opcode: OP_EQUALVERIFY},
+		{opcode: OP_CHECKSIG},
+	}
+
+	var templateOffset int
+	isAtomicSwap := true
+	tokenizer := MakeScriptTokenizer(version, pkScript)
+	for tokenizer.Next() {
+		// Not an atomic swap script if it has more opcodes than
+		// expected in the template.  The rest of the script is still
+		// parsed to report parse errors.
+		if !isAtomicSwap || templateOffset >= len(template) {
+			isAtomicSwap = false
+			continue
+		}
+
+		op, data := tokenizer.Opcode(), tokenizer.Data()
+		tplEntry := &template[templateOffset]
+		if tplEntry.expectInt {
+			n, ok := canonicalIntPush(op, data)
+			if !ok {
+				isAtomicSwap = false
+				continue
+			}
+			tplEntry.extractedInt = n
+		} else {
+			if op != tplEntry.opcode {
+				isAtomicSwap = false
+				continue
+			}
+			tplEntry.extractedData = data
+		}
+
+		templateOffset++
+	}
+	if err := tokenizer.Err(); err != nil {
 		return nil, err
 	}
-
-	if len(pops) != 20 {
+	if !isAtomicSwap || templateOffset != len(template) {
 		return nil, nil
 	}
-	isAtomicSwap := pops[0].opcode.value == OP_IF &&
-		pops[1].opcode.value == OP_SIZE &&
-		canonicalPush(pops[2]) &&
-		pops[3].opcode.value == OP_EQUALVERIFY &&
-		pops[4].opcode.value == OP_SHA256 &&
-		pops[5].opcode.value == OP_DATA_32 &&
-		pops[6].opcode.value == OP_EQUALVERIFY &&
-		pops[7].opcode.value == OP_DUP &&
-		pops[8].opcode.value == OP_HASH160 &&
-		pops[9].opcode.value == OP_DATA_20 &&
-		pops[10].opcode.value == OP_ELSE &&
-		canonicalPush(pops[11]) &&
-		pops[12].opcode.value == OP_CHECKLOCKTIMEVERIFY &&
-		pops[13].opcode.value == OP_DROP &&
-		pops[14].opcode.value == OP_DUP &&
-		pops[15].opcode.value == OP_HASH160 &&
-		pops[16].opcode.value == OP_DATA_20 &&
-		pops[17].opcode.value == OP_ENDIF &&
-		pops[18].opcode.value == OP_EQUALVERIFY &&
-		pops[19].opcode.value == OP_CHECKSIG
-	if !isAtomicSwap {
-		return nil, nil
+
+	pushes := &AtomicSwapDataPushes{
+		SecretSize: template[2].extractedInt,
+		LockTime:   template[11].extractedInt,
 	}
+	copy(pushes.SecretHash[:], template[5].extractedData)
+	copy(pushes.RecipientHash160[:], template[9].extractedData)
+	copy(pushes.RefundHash160[:], template[16].extractedData)
+	return pushes, nil
+}
 
-	pushes := new(AtomicSwapDataPushes)
-	copy(pushes.SecretHash[:], pops[5].data)
-	copy(pushes.RecipientHash160[:], pops[9].data)
-	copy(pushes.RefundHash160[:], pops[16].data)
-	if pops[2].data != nil {
-		locktime, err := makeScriptNum(pops[2].data, true, 5)
+// canonicalIntPush returns the integer pushed by the passed opcode and data and
+// true when they are a canonical push of a script number of up to 5 bytes, as
+// used for lock times.
+func canonicalIntPush(op byte, data []byte) (int64, bool) {
+	if !isCanonicalPush(op, data) {
+		return 0, false
+	}
+	if data != nil {
+		n, err := makeScriptNum(data, true, 5)
 		if err != nil {
-			return nil, nil
+			return 0, false
 		}
-		pushes.SecretSize = int64(locktime)
-	} else if op := pops[2].opcode; isSmallInt(op) {
-		pushes.SecretSize = int64(asSmallInt(op))
-	} else {
+		return int64(n), true
+	}
+	if isSmallInt(op) {
+		return int64(asSmallInt(op)), true
+	}
+	return 0, false
+}
+
+// TimeLockScript returns a script which pays to the passed address once the
+// passed lock time is reached.  The lock time is checked with
+// OP_CHECKSEQUENCEVERIFY when relative is set, in which case it is a sequence
+// number relative to the confirmation of the output, and with
+// OP_CHECKLOCKTIMEVERIFY otherwise, in which case it is a block height or,
+// from LockTimeThreshold on, a unix time.
+//
+// The script is not standard on its own and is meant to be wrapped in P2SH or
+// P2WSH.
+func TimeLockScript(addr *btcutil.AddressPubKeyHash, lockTime int64, relative bool) ([]byte, error) {
+	if lockTime < 0 {
+		str := fmt.Sprintf("negative lock time %d", lockTime)
+		return nil, scriptError(ErrNegativeLockTime, str)
+	}
+	if lockTime > math.MaxUint32 {
+		str := fmt.Sprintf("lock time %d is larger than max allowed "+
+			"lock time %d", lockTime, uint32(math.MaxUint32))
+		return nil, scriptError(ErrNumberTooBig, str)
+	}
+
+	lockOp := byte(OP_CHECKLOCKTIMEVERIFY)
+	if relative {
+		lockOp = OP_CHECKSEQUENCEVERIFY
+	}
+	return NewScriptBuilder().AddInt64(lockTime).AddOp(lockOp).
+		AddOp(OP_DROP).AddOp(OP_DUP).AddOp(OP_HASH160).
+		AddData(addr.ScriptAddress()).AddOp(OP_EQUALVERIFY).
+		AddOp(OP_CHECKSIG).Script()
+}
+
+// TimeLockDataPushes houses the data pushes found in the scripts created by
+// TimeLockScript.
+type TimeLockDataPushes struct {
+	PubKeyHash160 [20]byte
+	LockTime      int64
+	Relative      bool
+}
+
+// ExtractTimeLockDataPushes returns the data pushes from a script created by
+// TimeLockScript.  If the script is not such a script, it returns (nil, nil).
+// Non-nil errors are returned for unparsable scripts.
+func ExtractTimeLockDataPushes(script []byte) (*TimeLockDataPushes, error) {
+	if err := checkScriptParses(script); err != nil {
+		return nil, err
+	}
+
+	// A time lock script is of the form:
+	//  <locktime> CHECKLOCKTIMEVERIFY/CHECKSEQUENCEVERIFY DROP DUP HASH160
+	//  <20-byte hash> EQUALVERIFY CHECKSIG
+	tokenizer := MakeScriptTokenizer(0, script)
+	if !tokenizer.Next() {
 		return nil, nil
 	}
-	if pops[11].data != nil {
-		locktime, err := makeScriptNum(pops[11].data, true, 5)
-		if err != nil {
-			return nil, nil
-		}
-		pushes.LockTime = int64(locktime)
-	} else if op := pops[11].opcode; isSmallInt(op) {
-		pushes.LockTime = int64(asSmallInt(op))
-	} else {
+	lockTime, ok := canonicalIntPush(tokenizer.Opcode(), tokenizer.Data())
+	if !ok || lockTime < 0 || !tokenizer.Next() {
+		return nil, nil
+	}
+	lockOp := tokenizer.Opcode()
+	if lockOp != OP_CHECKLOCKTIMEVERIFY && lockOp != OP_CHECKSEQUENCEVERIFY {
+		return nil, nil
+	}
+
+	// The rest of the script drops the lock time and is a
+	// pay-to-pubkey-hash script.
+	rest := script[tokenizer.ByteIndex():]
+	if len(rest) == 0 || rest[0] != OP_DROP || !isPubkeyHash(rest[1:]) {
 		return nil, nil
 	}
+	p2pkh := rest[1:]
+
+	pushes := &TimeLockDataPushes{
+		LockTime: lockTime,
+		Relative: lockOp == OP_CHECKSEQUENCEVERIFY,
+	}
+	copy(pushes.PubKeyHash160[:], p2pkh[3:23])
 	return pushes, nil
 }
@@ -1213,3 +1213,179 @@ func TestNullDataScript(t *testing.T) {
 		}
 	}
 }
+
+// TestNullDataPayloadSize ensures the payload of data carrier scripts is
+// measured regardless of its size and that other scripts are not treated as
+// data carriers.
+func TestNullDataPayloadSize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		script []byte
+		size   int
+		ok     bool
+	}{
+		{
+			name:   "bare OP_RETURN",
+			script: mustParseShortForm("RETURN"),
+			size:   0,
+			ok:     true,
+		},
+		{
+			name:   "small int",
+			script: mustParseShortForm("RETURN 1"),
+			size:   0,
+			ok:     true,
+		},
+		{
+			name:   "single push",
+			script: mustParseShortForm("RETURN DATA_4 0x01020304"),
+			size:   4,
+			ok:     true,
+		},
+		{
+			name: "several pushes",
+			script: mustParseShortForm("RETURN DATA_2 0x0102 " +
+				"DATA_3 0x010203"),
+			size: 5,
+			ok:   true,
+		},
+		{
+			name: "larger than MaxDataCarrierSize",
+			script: append(mustParseShortForm("RETURN PUSHDATA1 0xc8"),
+				make([]byte, 200)...),
+			size: 200,
+			ok:   true,
+		},
+		{
+			name:   "not push only",
+			script: mustParseShortForm("RETURN DATA_1 0x01 DUP"),
+			ok:     false,
+		},
+		{
+			name:   "no OP_RETURN",
+			script: mustParseShortForm("DATA_1 0x01"),
+			ok:     false,
+		},
+		{
+			name:   "truncated push",
+			script: mustParseShortForm("RETURN DATA_4 0x0102"),
+			ok:     false,
+		},
+		{
+			name:   "empty",
+			script: nil,
+			ok:     false,
+		},
+	}
+
+	for _, test := range tests {
+		size, ok := NullDataPayloadSize(test.script)
+		if ok != test.ok || size != test.size {
+			t.Errorf("%s: got (%d, %v), want (%d, %v)", test.name,
+				size, ok, test.size, test.ok)
+		}
+	}
+}
+
+// TestTimeLockScript ensures time locked scripts are created as expected, that
+// their data pushes are extracted back and that invalid lock times are
+// rejected.
+func TestTimeLockScript(t *testing.T) {
+	t.Parallel()
+
+	pkh := hexToBytes("e34cce70c86373273efcc54ce7d2a491bb4a0e84")
+	addr, err := btcutil.NewAddressPubKeyHash(pkh, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("Unable to create address: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		lockTime int64
+		relative bool
+		expected []byte
+		err      error
+	}{
+		{
+			name:     "absolute height",
+			lockTime: 500000,
+			expected: mustParseShortForm("DATA_3 0x20a107 " +
+				"CHECKLOCKTIMEVERIFY DROP DUP HASH160 DATA_20 " +
+				"0xe34cce70c86373273efcc54ce7d2a491bb4a0e84 " +
+				"EQUALVERIFY CHECKSIG"),
+		},
+		{
+			name:     "relative blocks",
+			lockTime: 144,
+			relative: true,
+			expected: mustParseShortForm("DATA_2 0x9000 " +
+				"CHECKSEQUENCEVERIFY DROP DUP HASH160 DATA_20 " +
+				"0xe34cce70c86373273efcc54ce7d2a491bb4a0e84 " +
+				"EQUALVERIFY CHECKSIG"),
+		},
+		{
+			name:     "small lock time",
+			lockTime: 16,
+			expected: mustParseShortForm("16 CHECKLOCKTIMEVERIFY " +
+				"DROP DUP HASH160 DATA_20 " +
+				"0xe34cce70c86373273efcc54ce7d2a491bb4a0e84 " +
+				"EQUALVERIFY CHECKSIG"),
+		},
+		{
+			name:     "negative lock time",
+			lockTime: -1,
+			err:      scriptError(ErrNegativeLockTime, ""),
+		},
+		{
+			name:     "lock time too big",
+			lockTime: 1 << 32,
+			err:      scriptError(ErrNumberTooBig, ""),
+		},
+	}
+
+	for i, test := range tests {
+		script, err := TimeLockScript(addr, test.lockTime, test.relative)
+		if e := tstCheckScriptError(err, test.err); e != nil {
+			t.Errorf("TimeLockScript: #%d (%s): %v", i, test.name, e)
+			continue
+		}
+		if test.err != nil {
+			continue
+		}
+		if !bytes.Equal(script, test.expected) {
+			t.Errorf("TimeLockScript: #%d (%s) wrong result\n"+
+				"got: %x\nwant: %x", i, test.name, script,
+				test.expected)
+			continue
+		}
+
+		pushes, err := ExtractTimeLockDataPushes(script)
+		if err != nil {
+			t.Errorf("ExtractTimeLockDataPushes: #%d (%s) unexpected "+
+				"error: %v", i, test.name, err)
+			continue
+		}
+		want := &TimeLockDataPushes{
+			LockTime: test.lockTime,
+			Relative: test.relative,
+		}
+		copy(want.PubKeyHash160[:], pkh)
+		if !reflect.DeepEqual(pushes, want) {
+			t.Errorf("ExtractTimeLockDataPushes: #%d (%s) got %+v, "+
+				"want %+v", i, test.name, pushes, want)
+		}
+	}
+
+	// Scripts which are not time locked must not be detected as such.
+	p2pkh, err := PayToAddrScript(addr)
+	if err != nil {
+		t.Fatalf("Unable to create script: %v", err)
+	}
+	pushes, err := ExtractTimeLockDataPushes(p2pkh)
+	if err != nil || pushes != nil {
+		t.Fatalf("ExtractTimeLockDataPushes: unexpected result for "+
+			"p2pkh: %v, %v", pushes, err)
+	}
+}
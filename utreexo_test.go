@@ -0,0 +1,115 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/blockchain/indexers"
+	"github.com/pkt-cash/pktd/blockchain/utreexo"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// TestUtreexoIndex ensures the utreexo accumulator follows the unspent outputs
+// of the chain across reorganizations, that the proofs it creates verify
+// against its roots and that it is rebuilt from the database.
+func TestUtreexoIndex(t *testing.T) {
+	var utreexoIndex *indexers.UtreexoIndex
+	tc, teardown := newTestChain(t, "utreexo",
+		func(config *blockchain.Config) {
+			utreexoIndex = indexers.NewUtreexoIndex(config.DB)
+			config.IndexManager = indexers.NewManager(config.DB,
+				[]indexers.Indexer{utreexoIndex})
+		})
+	defer teardown()
+	params, chain, g := tc.params, tc.chain, tc.gen
+
+	// leaves returns the spendable outputs of the coinbase of the main
+	// chain block at the passed height.
+	leaves := func(height int32) []utreexo.LeafData {
+		block, err := chain.BlockByHeight(height)
+		if err != nil {
+			t.Fatalf("BlockByHeight: %v", err)
+		}
+		tx := block.Transactions()[0]
+		var leaves []utreexo.LeafData
+		for i, txOut := range tx.MsgTx().TxOut {
+			if txscript.IsUnspendable(txOut.PkScript) {
+				continue
+			}
+			leaves = append(leaves, utreexo.LeafData{
+				OutPoint:   *wire.NewOutPoint(tx.Hash(), uint32(i)),
+				Height:     height,
+				IsCoinBase: true,
+				Amount:     txOut.Value,
+				PkScript:   txOut.PkScript,
+			})
+		}
+		return leaves
+	}
+
+	// check ensures the outputs of the main chain blocks are proven
+	// against the roots at the tip and returns the roots.
+	check := func() *utreexo.Stump {
+		best := chain.BestSnapshot()
+		var all []utreexo.LeafData
+		for height := int32(1); height <= best.Height; height++ {
+			all = append(all, leaves(height)...)
+		}
+		tipHash, stump, proofs := utreexoIndex.Prove(all)
+		if *tipHash != best.Hash {
+			t.Fatalf("proofs at %v, want %v", tipHash, best.Hash)
+		}
+		if stump.NumLeaves != uint64(len(all)) {
+			t.Fatalf("got %d leaves, want %d", stump.NumLeaves,
+				len(all))
+		}
+		for i, proof := range proofs {
+			if proof == nil {
+				t.Fatalf("output %v not proven", all[i].OutPoint)
+			}
+			if err := stump.Verify(all[i].Hash(), proof); err != nil {
+				t.Fatalf("output %v: %v", all[i].OutPoint, err)
+			}
+		}
+		return stump
+	}
+
+	if _, err := g.generate(params.GenesisHash, 5, nil); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	check()
+	stale := leaves(5)
+
+	// The outputs of the disconnected blocks are removed.
+	if _, err := g.simulateReorg(2, 3, nil); err != nil {
+		t.Fatalf("simulateReorg: %v", err)
+	}
+	stump := check()
+	_, _, proofs := utreexoIndex.Prove(stale)
+	for i, proof := range proofs {
+		if proof != nil {
+			t.Fatalf("disconnected output %v proven", stale[i].OutPoint)
+		}
+	}
+
+	// The forest is rebuilt from the leaves stored in the database.
+	reloaded := indexers.NewUtreexoIndex(tc.db)
+	if err := reloaded.Init(); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	tipHash, reloadedStump, _ := reloaded.Prove(nil)
+	if *tipHash != chain.BestSnapshot().Hash {
+		t.Fatalf("reloaded index at %v, want %v", tipHash,
+			chain.BestSnapshot().Hash)
+	}
+	if !reflect.DeepEqual(reloadedStump, stump) {
+		t.Fatalf("reloaded roots %v, want %v", reloadedStump.Roots,
+			stump.Roots)
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/blockchain/indexers"
+	"github.com/pkt-cash/pktd/btcjson"
+	"github.com/pkt-cash/pktd/txscript"
+)
+
+// TestUtxoStatsIndex ensures the utxo statistics index follows the unspent
+// outputs of the chain across reorganizations, keeps snapshots at the
+// configured interval and that getutxostats reports them.
+func TestUtxoStatsIndex(t *testing.T) {
+	var statsIndex *indexers.UtxoStatsIndex
+	tc, teardown := newTestChain(t, "utxostats",
+		func(config *blockchain.Config) {
+			statsIndex = indexers.NewUtxoStatsIndex(config.DB, 2)
+			config.IndexManager = indexers.NewManager(config.DB,
+				[]indexers.Indexer{statsIndex})
+		})
+	defer teardown()
+	params, chain, g := tc.params, tc.chain, tc.gen
+	s := &rpcServer{cfg: rpcserverConfig{Chain: chain}}
+
+	// The command needs the index.
+	cmd := btcjson.NewGetUtxoStatsCmd(nil)
+	_, err := handleGetUtxoStats(s, cmd, nil)
+	if rpcErr, ok := err.(*btcjson.RPCError); !ok ||
+		rpcErr.Code != btcjson.ErrRPCNoUtxoStatsIndex {
+		t.Fatalf("getutxostats without index: unexpected error %v", err)
+	}
+	s.cfg.UtxoStatsIndex = statsIndex
+
+	// check compares the result of getutxostats at the passed height with
+	// the outputs of the main chain blocks up to the expected height.  The
+	// generated blocks only hold a coinbase, so none of them is spent.
+	check := func(height *int32, wantHeight int32) {
+		result, err := handleGetUtxoStats(s, btcjson.NewGetUtxoStatsCmd(height), nil)
+		if err != nil {
+			t.Fatalf("getutxostats: %v", err)
+		}
+		stats := result.(*btcjson.GetUtxoStatsResult)
+		if stats.Height != wantHeight {
+			t.Fatalf("got statistics at height %d, want %d",
+				stats.Height, wantHeight)
+		}
+		hash, err := chain.BlockHashByHeight(wantHeight)
+		if err != nil {
+			t.Fatalf("BlockHashByHeight: %v", err)
+		}
+		if stats.Hash != hash.String() {
+			t.Fatalf("got hash %v, want %v", stats.Hash, hash)
+		}
+
+		var count uint64
+		var value btcutil.Amount
+		for h := int32(1); h <= wantHeight; h++ {
+			block, err := chain.BlockByHeight(h)
+			if err != nil {
+				t.Fatalf("BlockByHeight: %v", err)
+			}
+			for _, txOut := range block.MsgBlock().Transactions[0].TxOut {
+				if txscript.IsUnspendable(txOut.PkScript) {
+					continue
+				}
+				count++
+				value += btcutil.Amount(txOut.Value)
+			}
+		}
+		if stats.Total.Count != count || stats.Total.Amount != value.ToBTC() {
+			t.Fatalf("got %d outputs of %v BTC, want %d of %v",
+				stats.Total.Count, stats.Total.Amount, count,
+				value.ToBTC())
+		}
+		var classCount, histogramCount uint64
+		for _, class := range stats.Classes {
+			classCount += class.Count
+		}
+		for _, bucket := range stats.Total.Histogram {
+			if bucket.MinValue > bucket.MaxValue {
+				t.Fatalf("invalid histogram bucket %+v", bucket)
+			}
+			histogramCount += bucket.Count
+		}
+		if classCount != count || histogramCount != count {
+			t.Fatalf("classes count %d and histogram count %d "+
+				"outputs, want %d", classCount, histogramCount,
+				count)
+		}
+	}
+
+	if _, err := g.generate(params.GenesisHash, 5, nil); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	check(nil, 5)
+	check(btcjson.Int32(3), 2)
+	check(btcjson.Int32(4), 4)
+
+	// The genesis block is indexed, so its empty snapshot is the earliest.
+	check(btcjson.Int32(1), 0)
+
+	// Disconnected blocks are removed from the statistics and the
+	// snapshots.
+	if _, err := g.simulateReorg(2, 3, nil); err != nil {
+		t.Fatalf("simulateReorg: %v", err)
+	}
+	check(nil, 6)
+	check(btcjson.Int32(5), 4)
+	check(btcjson.Int32(2), 2)
+}
@@ -0,0 +1,648 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkt-cash/btcutil"
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/mempool"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// Events which may be sent to webhooks.
+const (
+	webhookBlockConnected    = "blockconnected"
+	webhookBlockDisconnected = "blockdisconnected"
+	webhookAddressActivity   = "addressactivity"
+	webhookTxConfirmed       = "txconfirmed"
+	webhookPartitionAlert    = "partitionalert"
+	webhookDeposit           = "deposit"
+	webhookColdSpend         = "coldspend"
+	webhookDiskSpace         = "diskspace"
+)
+
+const (
+	// maxWebhookQueue is the number of payloads which may be waiting to be
+	// delivered to a single webhook.  Further payloads are dropped until
+	// the endpoint catches up.
+	maxWebhookQueue = 1000
+
+	// maxWebhookRetries is the number of times the delivery of a payload
+	// is retried before it is dropped.
+	maxWebhookRetries = 8
+
+	// defaultWebhookRetryDelay is the delay before the first retry of a
+	// failed delivery.  It doubles after every further failure.
+	defaultWebhookRetryDelay = time.Second
+
+	// maxWebhookRetryDelay caps the delay between retries.
+	maxWebhookRetryDelay = 5 * time.Minute
+
+	// webhookTimeout is the time allowed for a single delivery attempt.
+	webhookTimeout = 10 * time.Second
+)
+
+// webhookEvents is the set of events which may be selected for a webhook.
+var webhookEvents = map[string]struct{}{
+	webhookBlockConnected:    {},
+	webhookBlockDisconnected: {},
+	webhookAddressActivity:   {},
+	webhookTxConfirmed:       {},
+	webhookPartitionAlert:    {},
+	webhookDeposit:           {},
+	webhookColdSpend:         {},
+	webhookDiskSpace:         {},
+}
+
+// defaultWebhookConfirmations are the confirmation counts at which
+// transactions of watched addresses are sent to webhooks when none are
+// configured.
+var defaultWebhookConfirmations = []int32{1, 6}
+
+// webhookPayload is the JSON body of every webhook request.  ID increases by
+// one for every payload so receivers can detect gaps and duplicates.
+type webhookPayload struct {
+	ID    uint64      `json:"id"`
+	Event string      `json:"event"`
+	Time  int64       `json:"time"`
+	Data  interface{} `json:"data"`
+}
+
+// webhookBlock is the data of the blockconnected and blockdisconnected events.
+type webhookBlock struct {
+	Hash     string `json:"hash"`
+	Height   int32  `json:"height"`
+	PrevHash string `json:"previousblockhash"`
+	Time     int64  `json:"time"`
+}
+
+// webhookActivity is the data of the addressactivity event.  BlockHash and
+// Height are only set once the transaction is mined.
+type webhookActivity struct {
+	TxID      string   `json:"txid"`
+	Addresses []string `json:"addresses"`
+	BlockHash string   `json:"blockhash,omitempty"`
+	Height    int32    `json:"height,omitempty"`
+}
+
+// webhookConfirmed is the data of the txconfirmed event.
+type webhookConfirmed struct {
+	TxID          string `json:"txid"`
+	BlockHash     string `json:"blockhash"`
+	Height        int32  `json:"height"`
+	Confirmations int32  `json:"confirmations"`
+}
+
+// webhookPartition is the data of the partitionalert event, sent when a
+// network partition alert is raised or cleared.  Since is the Unix time the
+// condition started to hold.
+type webhookPartition struct {
+	Condition       string `json:"condition"`
+	Status          string `json:"status"`
+	Since           int64  `json:"since"`
+	TipHash         string `json:"tiphash"`
+	TipHeight       int32  `json:"tipheight"`
+	BestPeerHeight  int32  `json:"bestpeerheight"`
+	PeersSameChain  int    `json:"peerssamechain"`
+	PeersOtherChain int    `json:"peersotherchain"`
+}
+
+// webhookDepositInfo is the data of the deposit event, sent when a deposit to an
+// address bound to a reference ID reaches a confirmation count, with status
+// confirmed, or when the block of a deposit which was already sent is
+// disconnected, with status reorged and the last confirmation count sent.
+type webhookDepositInfo struct {
+	Ref           string  `json:"ref"`
+	Address       string  `json:"address"`
+	TxID          string  `json:"txid"`
+	Vout          uint32  `json:"vout"`
+	Amount        float64 `json:"amount"`
+	BlockHash     string  `json:"blockhash"`
+	Height        int32   `json:"height"`
+	Confirmations int32   `json:"confirmations"`
+	Status        string  `json:"status"`
+}
+
+// webhookColdSpendInfo is the data of the coldspend event, sent when a transaction
+// spending from cold storage addresses is seen in the mempool, with status
+// mempool, and when it is mined, with status mined.  Amount is the total
+// spent from the cold storage addresses.
+type webhookColdSpendInfo struct {
+	TxID      string   `json:"txid"`
+	Addresses []string `json:"addresses"`
+	Amount    float64  `json:"amount"`
+	Status    string   `json:"status"`
+	BlockHash string   `json:"blockhash,omitempty"`
+	Height    int32    `json:"height,omitempty"`
+}
+
+// webhookDiskSpaceInfo is the data of the diskspace event, sent when the state
+// of the free space on the data directory changes from Previous to Status (ok,
+// low or safemode).  The sizes are in bytes.
+type webhookDiskSpaceInfo struct {
+	Status   string `json:"status"`
+	Previous string `json:"previous"`
+	Free     uint64 `json:"free"`
+	Total    uint64 `json:"total"`
+}
+
+// webhookConfig holds the configuration of the webhook manager.
+type webhookConfig struct {
+	// Hooks are the --webhook URLs, optionally followed by a fragment
+	// listing the events to send to the URL.
+	Hooks []string
+
+	// Secret is the key used to sign every payload with HMAC-SHA256.  No
+	// signature is sent when it is empty.
+	Secret string
+
+	// Watch are the addresses whose activity is sent to webhooks.
+	Watch []string
+
+	// Confirmations are the confirmation counts at which transactions of
+	// watched addresses are sent to webhooks.
+	Confirmations []int32
+
+	ChainParams *chaincfg.Params
+
+	// FetchSpendJournal returns the outputs spent by a connected block and
+	// FetchPrevOut returns the output spent by a mempool transaction, or
+	// nil when it is unknown.  They are used to find transactions spending
+	// from watched addresses.
+	FetchSpendJournal func(*btcutil.Block) ([]blockchain.SpentTxOut, error)
+	FetchPrevOut      func(wire.OutPoint) *wire.TxOut
+}
+
+// webhook is a single endpoint which events are POSTed to.
+type webhook struct {
+	url    string
+	events map[string]struct{} // nil for every event
+	queue  chan []byte
+}
+
+// webhookTx is a mined transaction of a watched address which is waiting to
+// reach the configured confirmation counts.
+type webhookTx struct {
+	blockHash chainhash.Hash
+	height    int32
+	next      int // Index of the next confirmation count to notify.
+}
+
+// webhookManager sends chain and mempool events as signed JSON to webhooks,
+// retrying failed deliveries with exponential backoff.
+type webhookManager struct {
+	cfg        webhookConfig
+	hooks      []*webhook
+	watched    map[string]struct{}
+	retryDelay time.Duration
+	client     *http.Client
+	nextID     uint64 // Accessed atomically.
+
+	// Notifications are passed through an unbounded queue so the chain
+	// callback never blocks.
+	queueNotification chan interface{}
+	notifications     chan interface{}
+
+	// pending is only accessed by the notification handler.
+	pending map[chainhash.Hash]*webhookTx
+
+	wg   sync.WaitGroup
+	quit chan struct{}
+}
+
+// parseWebhook parses a --webhook value, which is a URL optionally followed by
+// a fragment listing the events to send to it, for example
+// https://example.com/hook#blockconnected,txconfirmed.
+func parseWebhook(s string) (*webhook, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("webhook %q is not an http or https URL", s)
+	}
+
+	h := &webhook{queue: make(chan []byte, maxWebhookQueue)}
+	if u.Fragment != "" {
+		h.events = make(map[string]struct{})
+		for _, event := range strings.Split(u.Fragment, ",") {
+			if _, ok := webhookEvents[event]; !ok {
+				return nil, fmt.Errorf("webhook %q has unknown "+
+					"event %q", s, event)
+			}
+			h.events[event] = struct{}{}
+		}
+		u.Fragment = ""
+	}
+	h.url = u.String()
+	return h, nil
+}
+
+// newWebhookManager returns a webhook manager for the passed configuration.
+func newWebhookManager(cfg *webhookConfig) (*webhookManager, error) {
+	m := &webhookManager{
+		cfg:               *cfg,
+		watched:           make(map[string]struct{}, len(cfg.Watch)),
+		retryDelay:        defaultWebhookRetryDelay,
+		client:            &http.Client{Timeout: webhookTimeout},
+		queueNotification: make(chan interface{}),
+		notifications:     make(chan interface{}),
+		pending:           make(map[chainhash.Hash]*webhookTx),
+		quit:              make(chan struct{}),
+	}
+	for _, s := range cfg.Hooks {
+		h, err := parseWebhook(s)
+		if err != nil {
+			return nil, err
+		}
+		m.hooks = append(m.hooks, h)
+	}
+	for _, s := range cfg.Watch {
+		addr, err := btcutil.DecodeAddress(s, cfg.ChainParams)
+		if err != nil {
+			return nil, fmt.Errorf("invalid watched address %q: %v",
+				s, err)
+		}
+		m.watched[addr.EncodeAddress()] = struct{}{}
+	}
+	if len(m.cfg.Confirmations) == 0 {
+		m.cfg.Confirmations = defaultWebhookConfirmations
+	}
+	confs := make([]int32, len(m.cfg.Confirmations))
+	copy(confs, m.cfg.Confirmations)
+	sort.Slice(confs, func(i, j int) bool { return confs[i] < confs[j] })
+	m.cfg.Confirmations = confs
+	return m, nil
+}
+
+// Start starts delivering events to the webhooks.
+func (m *webhookManager) Start() {
+	m.wg.Add(2 + len(m.hooks))
+	go func() {
+		queueHandler(m.queueNotification, m.notifications, m.quit)
+		m.wg.Done()
+	}()
+	go m.notificationHandler()
+	for _, h := range m.hooks {
+		go m.deliveryHandler(h)
+	}
+}
+
+// Stop stops the webhook manager, dropping any undelivered events, and waits
+// for it to finish.
+func (m *webhookManager) Stop() {
+	close(m.quit)
+	m.wg.Wait()
+}
+
+// handleBlockchainNotification queues block connected and disconnected
+// notifications from the chain.
+func (m *webhookManager) handleBlockchainNotification(notification *blockchain.Notification) {
+	switch notification.Type {
+	case blockchain.NTBlockConnected, blockchain.NTBlockDisconnected:
+	default:
+		return
+	}
+	select {
+	case m.queueNotification <- notification:
+	case <-m.quit:
+	}
+}
+
+// NotifyNewTransactions queues transactions newly accepted to the mempool so
+// that activity of watched addresses is sent to the webhooks.
+func (m *webhookManager) NotifyNewTransactions(txns []*mempool.TxDesc) {
+	if len(m.watched) == 0 {
+		return
+	}
+	select {
+	case m.queueNotification <- txns:
+	case <-m.quit:
+	}
+}
+
+// notificationHandler turns queued notifications into webhook payloads.  It
+// must be run as a goroutine.
+func (m *webhookManager) notificationHandler() {
+out:
+	for {
+		select {
+		case n, ok := <-m.notifications:
+			if !ok {
+				break out
+			}
+			switch n := n.(type) {
+			case *blockchain.Notification:
+				block := n.Data.(*btcutil.Block)
+				if n.Type == blockchain.NTBlockConnected {
+					m.blockConnected(block)
+				} else {
+					m.blockDisconnected(block)
+				}
+			case []*mempool.TxDesc:
+				for _, txD := range n {
+					m.txAccepted(txD.Tx)
+				}
+			}
+
+		case <-m.quit:
+			break out
+		}
+	}
+	m.wg.Done()
+}
+
+// NotifyPartitionAlert sends a raised or cleared network partition alert to
+// the webhooks.
+func (m *webhookManager) NotifyPartitionAlert(alert *webhookPartition) {
+	m.send(webhookPartitionAlert, alert)
+}
+
+// NotifyDeposit sends a deposit event to the webhooks.
+func (m *webhookManager) NotifyDeposit(deposit *webhookDepositInfo) {
+	m.send(webhookDeposit, deposit)
+}
+
+// NotifyColdSpend sends a coldspend event to the webhooks.
+func (m *webhookManager) NotifyColdSpend(spend *webhookColdSpendInfo) {
+	m.send(webhookColdSpend, spend)
+}
+
+// NotifyDiskSpace sends a diskspace event to the webhooks.
+func (m *webhookManager) NotifyDiskSpace(event *webhookDiskSpaceInfo) {
+	m.send(webhookDiskSpace, event)
+}
+
+// blockEvent returns the data of a block connected or disconnected event.
+func blockEvent(block *btcutil.Block) *webhookBlock {
+	header := &block.MsgBlock().Header
+	return &webhookBlock{
+		Hash:     block.Hash().String(),
+		Height:   block.Height(),
+		PrevHash: header.PrevBlock.String(),
+		Time:     header.Timestamp.Unix(),
+	}
+}
+
+// blockConnected sends the block and the activity of watched addresses in it
+// to the webhooks, along with transactions reaching a confirmation count.
+func (m *webhookManager) blockConnected(block *btcutil.Block) {
+	m.send(webhookBlockConnected, blockEvent(block))
+	if len(m.watched) == 0 {
+		return
+	}
+
+	// The spend journal holds the outputs spent by the block in the order
+	// of the inputs of every transaction but the coinbase.
+	stxos, err := m.cfg.FetchSpendJournal(block)
+	if err != nil {
+		hookLog.Warnf("Unable to fetch the outputs spent by block %v: %v",
+			block.Hash(), err)
+	}
+	for i, tx := range block.Transactions() {
+		var prevScripts [][]byte
+		if i != 0 && len(stxos) != 0 {
+			for range tx.MsgTx().TxIn {
+				if len(stxos) == 0 {
+					break
+				}
+				prevScripts = append(prevScripts, stxos[0].PkScript)
+				stxos = stxos[1:]
+			}
+		}
+		addrs := m.watchedAddresses(tx.MsgTx(), prevScripts)
+		if len(addrs) == 0 {
+			continue
+		}
+		m.send(webhookAddressActivity, &webhookActivity{
+			TxID:      tx.Hash().String(),
+			Addresses: addrs,
+			BlockHash: block.Hash().String(),
+			Height:    block.Height(),
+		})
+		m.pending[*tx.Hash()] = &webhookTx{
+			blockHash: *block.Hash(),
+			height:    block.Height(),
+		}
+	}
+
+	for hash, ptx := range m.pending {
+		confs := block.Height() - ptx.height + 1
+		for ptx.next < len(m.cfg.Confirmations) &&
+			m.cfg.Confirmations[ptx.next] <= confs {
+
+			m.send(webhookTxConfirmed, &webhookConfirmed{
+				TxID:          hash.String(),
+				BlockHash:     ptx.blockHash.String(),
+				Height:        ptx.height,
+				Confirmations: m.cfg.Confirmations[ptx.next],
+			})
+			ptx.next++
+		}
+		if ptx.next == len(m.cfg.Confirmations) {
+			delete(m.pending, hash)
+		}
+	}
+}
+
+// blockDisconnected sends the block to the webhooks and stops tracking the
+// confirmations of the transactions in it.
+func (m *webhookManager) blockDisconnected(block *btcutil.Block) {
+	m.send(webhookBlockDisconnected, blockEvent(block))
+	for hash, ptx := range m.pending {
+		if ptx.blockHash == *block.Hash() {
+			delete(m.pending, hash)
+		}
+	}
+}
+
+// txAccepted sends the activity of watched addresses in a transaction newly
+// accepted to the mempool to the webhooks.
+func (m *webhookManager) txAccepted(tx *btcutil.Tx) {
+	prevScripts := make([][]byte, 0, len(tx.MsgTx().TxIn))
+	for _, txIn := range tx.MsgTx().TxIn {
+		var pkScript []byte
+		if prevOut := m.cfg.FetchPrevOut(txIn.PreviousOutPoint); prevOut != nil {
+			pkScript = prevOut.PkScript
+		}
+		prevScripts = append(prevScripts, pkScript)
+	}
+	addrs := m.watchedAddresses(tx.MsgTx(), prevScripts)
+	if len(addrs) == 0 {
+		return
+	}
+	m.send(webhookAddressActivity, &webhookActivity{
+		TxID:      tx.Hash().String(),
+		Addresses: addrs,
+	})
+}
+
+// watchedAddresses returns the watched addresses paid by the outputs of the
+// transaction or by the outputs it spends, whose scripts are passed in the
+// order of its inputs.
+func (m *webhookManager) watchedAddresses(tx *wire.MsgTx, prevScripts [][]byte) []string {
+	var found []string
+	seen := make(map[string]struct{})
+	check := func(pkScript []byte) {
+		// Ignore the error here since an error means the script
+		// couldn't parse and so can't pay to any watched address.
+		_, addrs, _, _ := txscript.ExtractPkScriptAddrs(pkScript,
+			m.cfg.ChainParams)
+		for _, addr := range addrs {
+			encoded := addr.EncodeAddress()
+			if _, ok := m.watched[encoded]; !ok {
+				continue
+			}
+			if _, ok := seen[encoded]; ok {
+				continue
+			}
+			seen[encoded] = struct{}{}
+			found = append(found, encoded)
+		}
+	}
+	for _, pkScript := range prevScripts {
+		check(pkScript)
+	}
+	for _, txOut := range tx.TxOut {
+		check(txOut.PkScript)
+	}
+	return found
+}
+
+// send queues the event for every webhook which receives it.
+func (m *webhookManager) send(event string, data interface{}) {
+	payload := webhookPayload{
+		ID:    atomic.AddUint64(&m.nextID, 1),
+		Event: event,
+		Time:  time.Now().Unix(),
+		Data:  data,
+	}
+	body, err := json.Marshal(&payload)
+	if err != nil {
+		hookLog.Errorf("Unable to serialize %s webhook payload: %v",
+			event, err)
+		return
+	}
+	for _, h := range m.hooks {
+		if h.events != nil {
+			if _, ok := h.events[event]; !ok {
+				continue
+			}
+		}
+		select {
+		case h.queue <- body:
+		default:
+			hookLog.Warnf("Dropping %s event %d for webhook %s: "+
+				"too many undelivered events", event, payload.ID,
+				h.url)
+		}
+	}
+}
+
+// deliveryHandler delivers the payloads queued for a webhook in order.  It
+// must be run as a goroutine.
+func (m *webhookManager) deliveryHandler(h *webhook) {
+out:
+	for {
+		select {
+		case body := <-h.queue:
+			if !m.deliver(h, body) {
+				break out
+			}
+		case <-m.quit:
+			break out
+		}
+	}
+	m.wg.Done()
+}
+
+// deliver POSTs the payload to the webhook, retrying with exponential backoff
+// until it is accepted or the retries are exhausted.  It returns false when
+// the manager is stopped while waiting to retry.
+func (m *webhookManager) deliver(h *webhook, body []byte) bool {
+	delay := m.retryDelay
+	for attempt := 0; ; attempt++ {
+		err := m.post(h.url, body)
+		if err == nil {
+			return true
+		}
+		if attempt == maxWebhookRetries {
+			hookLog.Warnf("Giving up delivering to webhook %s: %v",
+				h.url, err)
+			return true
+		}
+		hookLog.Debugf("Delivery to webhook %s failed, retrying in "+
+			"%v: %v", h.url, delay, err)
+
+		select {
+		case <-time.After(delay):
+		case <-m.quit:
+			return false
+		}
+		delay *= 2
+		if delay > maxWebhookRetryDelay {
+			delay = maxWebhookRetryDelay
+		}
+	}
+}
+
+// webhookSignature returns the signature of a payload sent at the passed unix
+// time, which is the hex-encoded HMAC-SHA256 of the time and the body joined
+// by a dot.  The time is included so a captured request can not be replayed
+// later on.
+func webhookSignature(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	io.WriteString(mac, timestamp)
+	mac.Write([]byte{'.'})
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// post makes a single delivery attempt of the payload.  Any response status
+// other than 2xx is a failure.
+func (m *webhookManager) post(hookURL string, body []byte) error {
+	req, err := http.NewRequest("POST", hookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Pktd-Timestamp", timestamp)
+	if m.cfg.Secret != "" {
+		req.Header.Set("X-Pktd-Signature", "sha256="+
+			webhookSignature([]byte(m.cfg.Secret), timestamp, body))
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return err
+	}
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
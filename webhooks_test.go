@@ -0,0 +1,147 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkt-cash/pktd/chaincfg"
+)
+
+// TestParseWebhook ensures webhook URLs and their event selections are parsed
+// as expected.
+func TestParseWebhook(t *testing.T) {
+	tests := []struct {
+		name   string
+		hook   string
+		url    string
+		events []string
+		err    bool
+	}{
+		{
+			name: "all events",
+			hook: "https://example.com/hook",
+			url:  "https://example.com/hook",
+		},
+		{
+			name:   "selected events",
+			hook:   "http://127.0.0.1:8080/hook?x=1#blockconnected,txconfirmed",
+			url:    "http://127.0.0.1:8080/hook?x=1",
+			events: []string{webhookBlockConnected, webhookTxConfirmed},
+		},
+		{
+			name: "unknown event",
+			hook: "https://example.com/hook#newblock",
+			err:  true,
+		},
+		{
+			name: "not http",
+			hook: "ftp://example.com/hook",
+			err:  true,
+		},
+	}
+
+	for _, test := range tests {
+		h, err := parseWebhook(test.hook)
+		if test.err {
+			if err == nil {
+				t.Errorf("%s: expected error", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if h.url != test.url {
+			t.Errorf("%s: unexpected url: got %s, want %s",
+				test.name, h.url, test.url)
+		}
+		if test.events == nil {
+			if h.events != nil {
+				t.Errorf("%s: unexpected events %v", test.name,
+					h.events)
+			}
+			continue
+		}
+		if len(h.events) != len(test.events) {
+			t.Errorf("%s: unexpected events %v", test.name, h.events)
+		}
+		for _, event := range test.events {
+			if _, ok := h.events[event]; !ok {
+				t.Errorf("%s: missing event %s", test.name, event)
+			}
+		}
+	}
+}
+
+// TestWebhookDelivery ensures payloads are signed, filtered by event and
+// retried when the endpoint fails.
+func TestWebhookDelivery(t *testing.T) {
+	const secret = "hooksecret"
+
+	var requests int32
+	received := make(chan webhookPayload, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Fail the first attempt so the payload is retried.
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("unable to read body: %v", err)
+			return
+		}
+		timestamp := r.Header.Get("X-Pktd-Timestamp")
+		want := "sha256=" + webhookSignature([]byte(secret), timestamp, body)
+		if got := r.Header.Get("X-Pktd-Signature"); got != want {
+			t.Errorf("unexpected signature: got %s, want %s", got, want)
+		}
+		var payload webhookPayload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Errorf("unable to decode payload: %v", err)
+			return
+		}
+		received <- payload
+	}))
+	defer srv.Close()
+
+	m, err := newWebhookManager(&webhookConfig{
+		Hooks:       []string{srv.URL + "#" + webhookBlockConnected},
+		Secret:      secret,
+		ChainParams: &chaincfg.RegressionNetParams,
+	})
+	if err != nil {
+		t.Fatalf("newWebhookManager: %v", err)
+	}
+	m.retryDelay = time.Millisecond
+	m.Start()
+	defer m.Stop()
+
+	// The disconnected event is not selected by the webhook and must not
+	// be delivered.
+	m.send(webhookBlockDisconnected, &webhookBlock{Height: 1})
+	m.send(webhookBlockConnected, &webhookBlock{Height: 2})
+
+	select {
+	case payload := <-received:
+		if payload.Event != webhookBlockConnected {
+			t.Fatalf("unexpected event %s", payload.Event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for webhook delivery")
+	}
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Fatalf("unexpected number of requests: got %d, want 2", n)
+	}
+}
@@ -5,7 +5,6 @@
 package wire
 
 import (
-	"bytes"
 	"io"
 	"time"
 
@@ -46,14 +45,22 @@ const blockHeaderLen = 80
 
 // BlockHash computes the block identifier hash for the given block header.
 func (h *BlockHeader) BlockHash() chainhash.Hash {
-	// Encode the header and double sha256 everything prior to the number of
-	// transactions.  Ignore the error returns since there is no way the
-	// encode could fail except being out of memory which would cause a
-	// run-time panic.
-	buf := bytes.NewBuffer(make([]byte, 0, MaxBlockHeaderPayload))
-	_ = writeBlockHeader(buf, 0, h)
-
-	return chainhash.DoubleHashH(buf.Bytes())
+	// Encode the header into an array on the stack and double sha256
+	// everything prior to the number of transactions.
+	var buf [blockHeaderLen]byte
+	return chainhash.DoubleHashH(h.AppendTo(buf[:0]))
+}
+
+// AppendTo appends the encoding of the block header, which is the same on the
+// wire and in long-term storage, to b and returns the extended slice.  It does
+// not allocate when b has room for blockHeaderLen more bytes.
+func (h *BlockHeader) AppendTo(b []byte) []byte {
+	b = appendUint32(b, uint32(h.Version))
+	b = append(b, h.PrevBlock[:]...)
+	b = append(b, h.MerkleRoot[:]...)
+	b = appendUint32(b, uint32(h.Timestamp.Unix()))
+	b = appendUint32(b, h.Bits)
+	return appendUint32(b, h.Nonce)
 }
 
 // BtcDecode decodes r using the bitcoin protocol encoding into the receiver.
@@ -122,7 +129,7 @@ func readBlockHeader(r io.Reader, pver uint32, bh *BlockHeader) error {
 // encoding block headers to be stored to disk, such as in a database, as
 // opposed to encoding for the wire.
 func writeBlockHeader(w io.Writer, pver uint32, bh *BlockHeader) error {
-	sec := uint32(bh.Timestamp.Unix())
-	return writeElements(w, bh.Version, &bh.PrevBlock, &bh.MerkleRoot,
-		sec, bh.Bits, bh.Nonce)
+	var buf [blockHeaderLen]byte
+	_, err := w.Write(bh.AppendTo(buf[:0]))
+	return err
 }
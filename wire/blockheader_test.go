@@ -259,3 +259,32 @@ func TestBlockHeaderSerialize(t *testing.T) {
 		}
 	}
 }
+
+// TestBlockHeaderAppendTo ensures appending a block header to a byte slice
+// produces the same bytes as Serialize.
+func TestBlockHeaderAppendTo(t *testing.T) {
+	bh := NewBlockHeader(1, &mainNetGenesisHash, &mainNetGenesisMerkleRoot,
+		0x1d00ffff, 123123)
+
+	var buf bytes.Buffer
+	if err := bh.Serialize(&buf); err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+	prefix := []byte{0xde, 0xad}
+	b := bh.AppendTo(append([]byte(nil), prefix...))
+	if !bytes.Equal(b[:len(prefix)], prefix) ||
+		!bytes.Equal(b[len(prefix):], buf.Bytes()) {
+
+		t.Errorf("AppendTo\n got: %s want: %s", spew.Sdump(b),
+			spew.Sdump(buf.Bytes()))
+	}
+
+	// Appending to a slice with enough room must not allocate.
+	b = make([]byte, 0, blockHeaderLen)
+	allocs := testing.AllocsPerRun(10, func() {
+		b = bh.AppendTo(b[:0])
+	})
+	if allocs != 0 {
+		t.Errorf("AppendTo: got %v allocations, want 0", allocs)
+	}
+}
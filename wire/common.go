@@ -5,11 +5,13 @@
 package wire
 
 import (
+	"bytes"
 	"crypto/rand"
 	"encoding/binary"
 	"fmt"
 	"io"
 	"math"
+	"sync"
 	"time"
 
 	"github.com/pkt-cash/pktd/chaincfg/chainhash"
@@ -171,6 +173,35 @@ func (l binaryFreeList) PutUint64(w io.Writer, byteOrder binary.ByteOrder, val u
 // deserializing primitive integer values to and from io.Readers and io.Writers.
 var binarySerializer binaryFreeList = make(chan []byte, binaryFreeListMaxItems)
 
+// bufferPool provides a pool of byte buffers that messages and transactions
+// are serialized into before being written out.  Unlike the free lists above,
+// the buffers are of any size, so it is a sync.Pool which lets the garbage
+// collector reclaim them, while buffers for large blocks are still reused
+// when the same block is relayed to many peers at once.
+var bufferPool = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+// borrowBuffer returns an empty buffer from the pool which can hold at least
+// size bytes without growing.
+func borrowBuffer(size int) *bytes.Buffer {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Grow(size)
+	return buf
+}
+
+// returnBuffer puts the provided buffer back into the pool.  The buffer MUST
+// have been obtained via borrowBuffer and MUST NOT be used afterwards, nor
+// may any slice of its contents be retained.  Buffers larger than the largest
+// possible message are left to the garbage collector.
+func returnBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > MaxMessagePayload {
+		return
+	}
+	buf.Reset()
+	bufferPool.Put(buf)
+}
+
 // errNonCanonicalVarInt is the common format string used for non-canonically
 // encoded variable length integer errors.
 var errNonCanonicalVarInt = "non-canonical varint %x - discriminant %x must " +
@@ -563,6 +594,22 @@ func WriteVarInt(w io.Writer, pver uint32, val uint64) error {
 	return binarySerializer.PutUint64(w, littleEndian, val)
 }
 
+// appendVarInt appends val to b as a variable length integer in the same
+// format WriteVarInt writes and returns the extended slice.
+func appendVarInt(b []byte, val uint64) []byte {
+	switch {
+	case val < 0xfd:
+		return append(b, uint8(val))
+
+	case val <= math.MaxUint16:
+		return append(b, 0xfd, uint8(val), uint8(val>>8))
+
+	case val <= math.MaxUint32:
+		return appendUint32(append(b, 0xfe), uint32(val))
+	}
+	return appendUint64(append(b, 0xff), val)
+}
+
 // VarIntSerializeSize returns the number of bytes it would take to serialize
 // val as a variable length integer.
 func VarIntSerializeSize(val uint64) int {
@@ -672,6 +719,28 @@ func WriteVarBytes(w io.Writer, pver uint32, bytes []byte) error {
 	return err
 }
 
+// appendVarBytes appends bytes to b as a variable length byte array in the
+// same format WriteVarBytes writes and returns the extended slice.
+func appendVarBytes(b []byte, bytes []byte) []byte {
+	b = appendVarInt(b, uint64(len(bytes)))
+	return append(b, bytes...)
+}
+
+// appendUint32 appends the little endian encoding of val to b and returns the
+// extended slice.
+func appendUint32(b []byte, val uint32) []byte {
+	return append(b, uint8(val), uint8(val>>8), uint8(val>>16),
+		uint8(val>>24))
+}
+
+// appendUint64 appends the little endian encoding of val to b and returns the
+// extended slice.
+func appendUint64(b []byte, val uint64) []byte {
+	return append(b, uint8(val), uint8(val>>8), uint8(val>>16),
+		uint8(val>>24), uint8(val>>32), uint8(val>>40), uint8(val>>48),
+		uint8(val>>56))
+}
+
 // randomUint64 returns a cryptographically random uint64 value.  This
 // unexported version takes a reader primarily to ensure the error paths
 // can be properly tested by passing a fake reader in the tests.
@@ -294,6 +294,14 @@ func TestVarIntWire(t *testing.T) {
 			continue
 		}
 
+		// Ensure appending encodes the same way.
+		appended := appendVarInt([]byte{0x01}, test.in)
+		if !bytes.Equal(appended[1:], test.buf) {
+			t.Errorf("appendVarInt #%d\n got: %s want: %s", i,
+				spew.Sdump(appended[1:]), spew.Sdump(test.buf))
+			continue
+		}
+
 		// Decode from wire format.
 		rbuf := bytes.NewReader(test.buf)
 		val, err := ReadVarInt(rbuf, test.pver)
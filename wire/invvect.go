@@ -82,5 +82,29 @@ func readInvVect(r io.Reader, pver uint32, iv *InvVect) error {
 
 // writeInvVect serializes an InvVect to w depending on the protocol version.
 func writeInvVect(w io.Writer, pver uint32, iv *InvVect) error {
-	return writeElements(w, iv.Type, &iv.Hash)
+	var buf [maxInvVectPayload]byte
+	_, err := w.Write(iv.AppendTo(buf[:0]))
+	return err
+}
+
+// AppendTo appends the bitcoin protocol encoding of the inventory vector to b
+// and returns the extended slice.
+func (iv *InvVect) AppendTo(b []byte) []byte {
+	b = appendUint32(b, uint32(iv.Type))
+	return append(b, iv.Hash[:]...)
+}
+
+// writeInvList writes the count and the inventory vectors of an inv, getdata
+// or notfound message to w.  The list is encoded into a buffer from the pool
+// so it is written out with a single call rather than two for every vector.
+func writeInvList(w io.Writer, invList []*InvVect) error {
+	buf := borrowBuffer(MaxVarIntPayload + len(invList)*maxInvVectPayload)
+	defer returnBuffer(buf)
+
+	b := appendVarInt(buf.Bytes(), uint64(len(invList)))
+	for _, iv := range invList {
+		b = iv.AppendTo(b)
+	}
+	_, err := w.Write(b)
+	return err
 }
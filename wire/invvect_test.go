@@ -55,6 +55,22 @@ func TestInvVect(t *testing.T) {
 
 }
 
+// TestInvVectAppendTo ensures appending an inventory vector to a byte slice
+// produces the same bytes as its wire encoding.
+func TestInvVectAppendTo(t *testing.T) {
+	iv := NewInvVect(InvTypeWitnessTx, &mainNetGenesisHash)
+
+	var buf bytes.Buffer
+	if err := writeInvVect(&buf, ProtocolVersion, iv); err != nil {
+		t.Fatalf("writeInvVect: %v", err)
+	}
+	b := iv.AppendTo([]byte{0x01})
+	if !bytes.Equal(b[1:], buf.Bytes()) {
+		t.Errorf("AppendTo\n got: %s want: %s", spew.Sdump(b[1:]),
+			spew.Sdump(buf.Bytes()))
+	}
+}
+
 // TestInvVectWire tests the InvVect wire encode and decode for various
 // protocol versions and supported inventory vector types.
 func TestInvVectWire(t *testing.T) {
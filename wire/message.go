@@ -57,6 +57,10 @@ const (
 	CmdCFilter      = "cfilter"
 	CmdCFHeaders    = "cfheaders"
 	CmdCFCheckpt    = "cfcheckpt"
+
+	// CmdGetUtreexoProof and CmdUtreexoProof are pktd extensions.
+	CmdGetUtreexoProof = "getuproof"
+	CmdUtreexoProof    = "uproof"
 )
 
 // MessageEncoding represents the wire message encoding format to be used.
@@ -188,6 +192,12 @@ func makeEmptyMessage(command string) (Message, error) {
 	case CmdCFCheckpt:
 		msg = &MsgCFCheckpt{}
 
+	case CmdGetUtreexoProof:
+		msg = &MsgGetUtreexoProof{}
+
+	case CmdUtreexoProof:
+		msg = &MsgUtreexoProof{}
+
 	default:
 		return nil, fmt.Errorf("unhandled command [%s]", command)
 	}
@@ -283,9 +293,11 @@ func WriteMessageWithEncodingN(w io.Writer, msg Message, pver uint32,
 	}
 	copy(command[:], []byte(cmd))
 
-	// Encode the message payload.
-	var bw bytes.Buffer
-	err := msg.BtcEncode(&bw, pver, encoding)
+	// Encode the message payload into a buffer from the pool which is
+	// sized up front for messages that know their serialized size.
+	bw := borrowBuffer(payloadSizeHint(msg, encoding))
+	defer returnBuffer(bw)
+	err := msg.BtcEncode(bw, pver, encoding)
 	if err != nil {
 		return totalBytes, err
 	}
@@ -335,6 +347,27 @@ func WriteMessageWithEncodingN(w io.Writer, msg Message, pver uint32,
 	return totalBytes, err
 }
 
+// payloadSizeHint returns the number of bytes the payload of the passed
+// message is expected to take when encoded with the given encoding, or zero
+// when the message does not know its serialized size.  It is only a hint used
+// to size the buffer the payload is encoded into.
+func payloadSizeHint(msg Message, enc MessageEncoding) int {
+	switch m := msg.(type) {
+	case *MsgTx:
+		if enc != WitnessEncoding {
+			return m.SerializeSizeStripped()
+		}
+		return m.SerializeSize()
+
+	case *MsgBlock:
+		if enc&WitnessEncoding == 0 {
+			return m.SerializeSizeStripped()
+		}
+		return m.SerializeSize()
+	}
+	return 0
+}
+
 // ReadMessageWithEncodingN reads, validates, and parses the next bitcoin Message
 // from r for the provided protocol version and bitcoin network.  It returns the
 // number of bytes read in addition to the parsed Message and raw bytes which
@@ -0,0 +1,104 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+)
+
+// MaxUtreexoProofsPerMsg is the maximum number of outputs whose utreexo proofs
+// may be requested in a single getuproof message.
+const MaxUtreexoProofsPerMsg = 500
+
+// MsgGetUtreexoProof implements the Message interface and represents a bitcoin
+// getuproof message.  It is used to request the proofs that unspent outputs
+// are in the utreexo accumulator of a peer advertising SFNodeUtreexo.
+//
+// This message is not part of the Bitcoin protocol.
+type MsgGetUtreexoProof struct {
+	OutPoints []OutPoint
+}
+
+// AddOutPoint adds an outpoint to the message.
+func (msg *MsgGetUtreexoProof) AddOutPoint(op *OutPoint) error {
+	if len(msg.OutPoints)+1 > MaxUtreexoProofsPerMsg {
+		str := fmt.Sprintf("too many outpoints in message [max %v]",
+			MaxUtreexoProofsPerMsg)
+		return messageError("MsgGetUtreexoProof.AddOutPoint", str)
+	}
+
+	msg.OutPoints = append(msg.OutPoints, *op)
+	return nil
+}
+
+// BtcDecode decodes r using the bitcoin protocol encoding into the receiver.
+// This is part of the Message interface implementation.
+func (msg *MsgGetUtreexoProof) BtcDecode(r io.Reader, pver uint32, _ MessageEncoding) error {
+	count, err := ReadVarInt(r, pver)
+	if err != nil {
+		return err
+	}
+	if count > MaxUtreexoProofsPerMsg {
+		str := fmt.Sprintf("too many outpoints in message "+
+			"[count %v, max %v]", count, MaxUtreexoProofsPerMsg)
+		return messageError("MsgGetUtreexoProof.BtcDecode", str)
+	}
+
+	msg.OutPoints = make([]OutPoint, count)
+	for i := range msg.OutPoints {
+		err := readOutPoint(r, pver, 0, &msg.OutPoints[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BtcEncode encodes the receiver to w using the bitcoin protocol encoding.
+// This is part of the Message interface implementation.
+func (msg *MsgGetUtreexoProof) BtcEncode(w io.Writer, pver uint32, _ MessageEncoding) error {
+	count := len(msg.OutPoints)
+	if count > MaxUtreexoProofsPerMsg {
+		str := fmt.Sprintf("too many outpoints in message "+
+			"[count %v, max %v]", count, MaxUtreexoProofsPerMsg)
+		return messageError("MsgGetUtreexoProof.BtcEncode", str)
+	}
+
+	err := WriteVarInt(w, pver, uint64(count))
+	if err != nil {
+		return err
+	}
+	for i := range msg.OutPoints {
+		err := writeOutPoint(w, pver, 0, &msg.OutPoints[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Command returns the protocol command string for the message.  This is part
+// of the Message interface implementation.
+func (msg *MsgGetUtreexoProof) Command() string {
+	return CmdGetUtreexoProof
+}
+
+// MaxPayloadLength returns the maximum length the payload can be for the
+// receiver.  This is part of the Message interface implementation.
+func (msg *MsgGetUtreexoProof) MaxPayloadLength(pver uint32) uint32 {
+	// Num outpoints (varInt) + max allowed outpoints.
+	return MaxVarIntPayload + MaxUtreexoProofsPerMsg*(chainhash.HashSize+4)
+}
+
+// NewMsgGetUtreexoProof returns a new bitcoin getuproof message that conforms
+// to the Message interface.  See MsgGetUtreexoProof for details.
+func NewMsgGetUtreexoProof() *MsgGetUtreexoProof {
+	return &MsgGetUtreexoProof{
+		OutPoints: make([]OutPoint, 0, MaxUtreexoProofsPerMsg),
+	}
+}
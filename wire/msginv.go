@@ -83,19 +83,7 @@ func (msg *MsgInv) BtcEncode(w io.Writer, pver uint32, enc MessageEncoding) erro
 		return messageError("MsgInv.BtcEncode", str)
 	}
 
-	err := WriteVarInt(w, pver, uint64(count))
-	if err != nil {
-		return err
-	}
-
-	for _, iv := range msg.InvList {
-		err := writeInvVect(w, pver, iv)
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return writeInvList(w, msg.InvList)
 }
 
 // Command returns the protocol command string for the message.  This is part
@@ -5,7 +5,6 @@
 package wire
 
 import (
-	"bytes"
 	"fmt"
 	"io"
 	"strconv"
@@ -170,6 +169,13 @@ func (c scriptFreeList) Return(buf []byte) {
 	}
 }
 
+// isPooledScript returns whether the passed script is held by a buffer
+// borrowed from the script free list.  Only those scripts need to be copied
+// out of the free list once deserialized.
+func isPooledScript(script []byte) bool {
+	return cap(script) == freeListMaxScriptSize
+}
+
 // Create the concurrent safe free list to use for script deserialization.  As
 // previously described, this free list is maintained to significantly reduce
 // the number of allocations.
@@ -199,13 +205,20 @@ func (o OutPoint) String() string {
 	// maximum message payload may increase in the future and this
 	// optimization may go unnoticed, so allocate space for 10 decimal
 	// digits, which will fit any uint32.
-	buf := make([]byte, 2*chainhash.HashSize+1, 2*chainhash.HashSize+1+10)
-	copy(buf, o.Hash.String())
-	buf[2*chainhash.HashSize] = ':'
+	buf := make([]byte, 0, 2*chainhash.HashSize+1+10)
+	buf = o.Hash.AppendString(buf)
+	buf = append(buf, ':')
 	buf = strconv.AppendUint(buf, uint64(o.Index), 10)
 	return string(buf)
 }
 
+// AppendTo appends the bitcoin protocol encoding of the outpoint, its hash
+// followed by its index, to b and returns the extended slice.
+func (o *OutPoint) AppendTo(b []byte) []byte {
+	b = append(b, o.Hash[:]...)
+	return appendUint32(b, o.Index)
+}
+
 // TxIn defines a bitcoin transaction input.
 type TxIn struct {
 	PreviousOutPoint OutPoint
@@ -305,13 +318,11 @@ func (msg *MsgTx) AddTxOut(to *TxOut) {
 
 // TxHash generates the Hash for the transaction.
 func (msg *MsgTx) TxHash() chainhash.Hash {
-	// Encode the transaction and calculate double sha256 on the result.
-	// Ignore the error returns since the only way the encode could fail
-	// is being out of memory or due to nil pointers, both of which would
-	// cause a run-time panic.
-	buf := bytes.NewBuffer(make([]byte, 0, msg.SerializeSizeStripped()))
-	_ = msg.SerializeNoWitness(buf)
-	return chainhash.DoubleHashH(buf.Bytes())
+	// Encode the transaction without its witnesses into a buffer from the
+	// pool and calculate double sha256 on the result.
+	buf := borrowBuffer(msg.baseSize())
+	defer returnBuffer(buf)
+	return chainhash.DoubleHashH(msg.appendTo(buf.Bytes(), false))
 }
 
 // WitnessHash generates the hash of the transaction serialized according to
@@ -321,9 +332,9 @@ func (msg *MsgTx) TxHash() chainhash.Hash {
 // is the same as its txid.
 func (msg *MsgTx) WitnessHash() chainhash.Hash {
 	if msg.HasWitness() {
-		buf := bytes.NewBuffer(make([]byte, 0, msg.SerializeSize()))
-		_ = msg.Serialize(buf)
-		return chainhash.DoubleHashH(buf.Bytes())
+		buf := borrowBuffer(msg.SerializeSize())
+		defer returnBuffer(buf)
+		return chainhash.DoubleHashH(msg.appendTo(buf.Bytes(), true))
 	}
 
 	return msg.TxHash()
@@ -497,7 +508,9 @@ func (msg *MsgTx) BtcDecode(r io.Reader, pver uint32, enc MessageEncoding) error
 			returnScriptBuffers()
 			return err
 		}
-		totalScriptSize += uint64(len(ti.SignatureScript))
+		if isPooledScript(ti.SignatureScript) {
+			totalScriptSize += uint64(len(ti.SignatureScript))
+		}
 	}
 
 	count, err = ReadVarInt(r, pver)
@@ -530,7 +543,9 @@ func (msg *MsgTx) BtcDecode(r io.Reader, pver uint32, enc MessageEncoding) error
 			returnScriptBuffers()
 			return err
 		}
-		totalScriptSize += uint64(len(to.PkScript))
+		if isPooledScript(to.PkScript) {
+			totalScriptSize += uint64(len(to.PkScript))
+		}
 	}
 
 	// If the transaction's flag byte isn't 0x00 at this point, then one or
@@ -567,7 +582,9 @@ func (msg *MsgTx) BtcDecode(r io.Reader, pver uint32, enc MessageEncoding) error
 					returnScriptBuffers()
 					return err
 				}
-				totalScriptSize += uint64(len(txin.Witness[j]))
+				if isPooledScript(txin.Witness[j]) {
+					totalScriptSize += uint64(len(txin.Witness[j]))
+				}
 			}
 		}
 	}
@@ -588,6 +605,10 @@ func (msg *MsgTx) BtcDecode(r io.Reader, pver uint32, enc MessageEncoding) error
 	// amount of runtime overhead that would otherwise be needed to keep
 	// track of millions of small allocations.
 	//
+	// Scripts and witness items too large for the pool already have an
+	// allocation of their own, so they are kept as they are rather than
+	// being copied.
+	//
 	// NOTE: It is no longer valid to call the returnScriptBuffers closure
 	// after these blocks of code run because it is already done and the
 	// scripts in the transaction inputs and outputs no longer point to the
@@ -598,14 +619,17 @@ func (msg *MsgTx) BtcDecode(r io.Reader, pver uint32, enc MessageEncoding) error
 		// Copy the signature script into the contiguous buffer at the
 		// appropriate offset.
 		signatureScript := msg.TxIn[i].SignatureScript
-		copy(scripts[offset:], signatureScript)
-
-		// Reset the signature script of the transaction input to the
-		// slice of the contiguous buffer where the script lives.
-		scriptSize := uint64(len(signatureScript))
-		end := offset + scriptSize
-		msg.TxIn[i].SignatureScript = scripts[offset:end:end]
-		offset += scriptSize
+		if isPooledScript(signatureScript) {
+			copy(scripts[offset:], signatureScript)
+
+			// Reset the signature script of the transaction input to
+			// the slice of the contiguous buffer where the script
+			// lives.
+			scriptSize := uint64(len(signatureScript))
+			end := offset + scriptSize
+			msg.TxIn[i].SignatureScript = scripts[offset:end:end]
+			offset += scriptSize
+		}
 
 		// Return the temporary script buffer to the pool.
 		scriptPool.Return(signatureScript)
@@ -615,14 +639,17 @@ func (msg *MsgTx) BtcDecode(r io.Reader, pver uint32, enc MessageEncoding) error
 			// input into the contiguous buffer at the appropriate
 			// offset.
 			witnessElem := msg.TxIn[i].Witness[j]
-			copy(scripts[offset:], witnessElem)
-
-			// Reset the witness item within the stack to the slice
-			// of the contiguous buffer where the witness lives.
-			witnessElemSize := uint64(len(witnessElem))
-			end := offset + witnessElemSize
-			msg.TxIn[i].Witness[j] = scripts[offset:end:end]
-			offset += witnessElemSize
+			if isPooledScript(witnessElem) {
+				copy(scripts[offset:], witnessElem)
+
+				// Reset the witness item within the stack to the
+				// slice of the contiguous buffer where the
+				// witness lives.
+				witnessElemSize := uint64(len(witnessElem))
+				end := offset + witnessElemSize
+				msg.TxIn[i].Witness[j] = scripts[offset:end:end]
+				offset += witnessElemSize
+			}
 
 			// Return the temporary buffer used for the witness stack
 			// item to the pool.
@@ -633,14 +660,17 @@ func (msg *MsgTx) BtcDecode(r io.Reader, pver uint32, enc MessageEncoding) error
 		// Copy the public key script into the contiguous buffer at the
 		// appropriate offset.
 		pkScript := msg.TxOut[i].PkScript
-		copy(scripts[offset:], pkScript)
-
-		// Reset the public key script of the transaction output to the
-		// slice of the contiguous buffer where the script lives.
-		scriptSize := uint64(len(pkScript))
-		end := offset + scriptSize
-		msg.TxOut[i].PkScript = scripts[offset:end:end]
-		offset += scriptSize
+		if isPooledScript(pkScript) {
+			copy(scripts[offset:], pkScript)
+
+			// Reset the public key script of the transaction output
+			// to the slice of the contiguous buffer where the script
+			// lives.
+			scriptSize := uint64(len(pkScript))
+			end := offset + scriptSize
+			msg.TxOut[i].PkScript = scripts[offset:end:end]
+			offset += scriptSize
+		}
 
 		// Return the temporary script buffer to the pool.
 		scriptPool.Return(pkScript)
@@ -679,68 +709,82 @@ func (msg *MsgTx) DeserializeNoWitness(r io.Reader) error {
 // See Serialize for encoding transactions to be stored to disk, such as in a
 // database, as opposed to encoding transactions for the wire.
 func (msg *MsgTx) BtcEncode(w io.Writer, pver uint32, enc MessageEncoding) error {
-	err := binarySerializer.PutUint32(w, littleEndian, uint32(msg.Version))
-	if err != nil {
-		return err
-	}
-
 	// If the encoding version is set to WitnessEncoding, and the Flags
 	// field for the MsgTx aren't 0x00, then this indicates the transaction
 	// is to be encoded using the new witness inclusionary structure
 	// defined in BIP0144.
 	doWitness := enc == WitnessEncoding && msg.HasWitness()
+
+	// Encode the transaction into a buffer from the pool which is sized
+	// from its serialized size so the whole transaction is written out with
+	// a single call rather than one for every field.
+	size := msg.baseSize()
 	if doWitness {
-		// After the txn's Version field, we include two additional
-		// bytes specific to the witness encoding. The first byte is an
-		// always 0x00 marker byte, which allows decoders to
-		// distinguish a serialized transaction with witnesses from a
-		// regular (legacy) one. The second byte is the Flag field,
-		// which at the moment is always 0x01, but may be extended in
-		// the future to accommodate auxiliary non-committed fields.
-		if _, err := w.Write(witessMarkerBytes); err != nil {
-			return err
-		}
+		size = msg.SerializeSize()
 	}
+	buf := borrowBuffer(size)
+	defer returnBuffer(buf)
 
-	count := uint64(len(msg.TxIn))
-	err = WriteVarInt(w, pver, count)
-	if err != nil {
-		return err
-	}
+	_, err := w.Write(msg.appendTo(buf.Bytes(), doWitness))
+	return err
+}
 
-	for _, ti := range msg.TxIn {
-		err = writeTxIn(w, pver, msg.Version, ti)
-		if err != nil {
-			return err
-		}
+// AppendTo appends the encoding of the transaction used by Serialize to b and
+// returns the extended slice.  It does not allocate when b has room for
+// SerializeSize more bytes.
+func (msg *MsgTx) AppendTo(b []byte) []byte {
+	return msg.appendTo(b, msg.HasWitness())
+}
+
+// AppendToNoWitness appends the encoding of the transaction used by
+// SerializeNoWitness to b and returns the extended slice.  It does not allocate
+// when b has room for SerializeSizeStripped more bytes.
+func (msg *MsgTx) AppendToNoWitness(b []byte) []byte {
+	return msg.appendTo(b, false)
+}
+
+// appendTo appends the bitcoin protocol encoding of the transaction to b and
+// returns the extended slice.  The witnesses of the inputs are only included,
+// in the format defined in BIP0144, when doWitness is set.
+func (msg *MsgTx) appendTo(b []byte, doWitness bool) []byte {
+	b = appendUint32(b, uint32(msg.Version))
+
+	// After the txn's Version field, we include two additional bytes
+	// specific to the witness encoding. The first byte is an always 0x00
+	// marker byte, which allows decoders to distinguish a serialized
+	// transaction with witnesses from a regular (legacy) one. The second
+	// byte is the Flag field, which at the moment is always 0x01, but may
+	// be extended in the future to accommodate auxiliary non-committed
+	// fields.
+	if doWitness {
+		b = append(b, witessMarkerBytes...)
 	}
 
-	count = uint64(len(msg.TxOut))
-	err = WriteVarInt(w, pver, count)
-	if err != nil {
-		return err
+	b = appendVarInt(b, uint64(len(msg.TxIn)))
+	for _, ti := range msg.TxIn {
+		b = ti.PreviousOutPoint.AppendTo(b)
+		b = appendVarBytes(b, ti.SignatureScript)
+		b = appendUint32(b, ti.Sequence)
 	}
 
+	b = appendVarInt(b, uint64(len(msg.TxOut)))
 	for _, to := range msg.TxOut {
-		err = WriteTxOut(w, pver, msg.Version, to)
-		if err != nil {
-			return err
-		}
+		b = appendUint64(b, uint64(to.Value))
+		b = appendVarBytes(b, to.PkScript)
 	}
 
-	// If this transaction is a witness transaction, and the witness
-	// encoded is desired, then encode the witness for each of the inputs
-	// within the transaction.
+	// The witness items are appended straight from the inputs, each
+	// witness being a stack of items preceded by the number of items.
 	if doWitness {
 		for _, ti := range msg.TxIn {
-			err = writeTxWitness(w, pver, msg.Version, ti.Witness)
-			if err != nil {
-				return err
+			b = appendVarInt(b, uint64(len(ti.Witness)))
+			for _, item := range ti.Witness {
+				b = appendVarBytes(b, item)
 			}
 		}
 	}
 
-	return binarySerializer.PutUint32(w, littleEndian, msg.LockTime)
+	return appendUint32(b, msg.LockTime)
 }
 
 // HasWitness returns false if none of the inputs within the transaction
@@ -911,12 +955,9 @@ func readOutPoint(r io.Reader, pver uint32, version int32, op *OutPoint) error {
 // writeOutPoint encodes op to the bitcoin protocol encoding for an OutPoint
 // to w.
 func writeOutPoint(w io.Writer, pver uint32, version int32, op *OutPoint) error {
-	_, err := w.Write(op.Hash[:])
-	if err != nil {
-		return err
-	}
-
-	return binarySerializer.PutUint32(w, littleEndian, op.Index)
+	var buf [chainhash.HashSize + 4]byte
+	_, err := w.Write(op.AppendTo(buf[:0]))
+	return err
 }
 
 // readScript reads a variable length byte array that represents a transaction
@@ -259,6 +259,56 @@ func TestWTxSha(t *testing.T) {
 	}
 }
 
+// TestTxAppendTo ensures appending transactions and outpoints to byte slices
+// produces the same bytes as serializing them.
+func TestTxAppendTo(t *testing.T) {
+	tests := []struct {
+		tx        *MsgTx // Transaction to append
+		buf       []byte // Serialized data
+		noWitness []byte // Serialized data without the witnesses
+	}{
+		{multiTx, multiTxEncoded, multiTxEncoded},
+		{multiWitnessTx, multiWitnessTxEncoded, nil},
+	}
+
+	for i, test := range tests {
+		if test.noWitness == nil {
+			var buf bytes.Buffer
+			if err := test.tx.SerializeNoWitness(&buf); err != nil {
+				t.Fatalf("SerializeNoWitness #%d error %v", i, err)
+			}
+			test.noWitness = buf.Bytes()
+		}
+
+		b := test.tx.AppendTo([]byte{0x01})
+		if !bytes.Equal(b[1:], test.buf) {
+			t.Errorf("AppendTo #%d\n got: %s want: %s", i,
+				spew.Sdump(b[1:]), spew.Sdump(test.buf))
+		}
+		b = test.tx.AppendToNoWitness([]byte{0x01})
+		if !bytes.Equal(b[1:], test.noWitness) {
+			t.Errorf("AppendToNoWitness #%d\n got: %s want: %s", i,
+				spew.Sdump(b[1:]), spew.Sdump(test.noWitness))
+		}
+
+		// The outpoints are encoded at the start of each input.
+		for j, txIn := range test.tx.TxIn {
+			var buf bytes.Buffer
+			err := writeOutPoint(&buf, 0, test.tx.Version,
+				&txIn.PreviousOutPoint)
+			if err != nil {
+				t.Fatalf("writeOutPoint #%d.%d error %v", i, j, err)
+			}
+			b := txIn.PreviousOutPoint.AppendTo(nil)
+			if !bytes.Equal(b, buf.Bytes()) {
+				t.Errorf("OutPoint.AppendTo #%d.%d\n got: %s "+
+					"want: %s", i, j, spew.Sdump(b),
+					spew.Sdump(buf.Bytes()))
+			}
+		}
+	}
+}
+
 // TestTxWire tests the MsgTx wire encode and decode for various numbers
 // of transaction inputs and outputs and protocol versions.
 func TestTxWire(t *testing.T) {
@@ -778,6 +828,55 @@ func TestTxWitnessSize(t *testing.T) {
 	}
 }
 
+// TestTxDeserializeScripts ensures the scripts and witness items of a
+// deserialized transaction do not share memory with the script free list,
+// whether they are copied out of it or were too large for it.
+func TestTxDeserializeScripts(t *testing.T) {
+	largeItem := bytes.Repeat([]byte{0x01}, freeListMaxScriptSize+1)
+	tx := &MsgTx{
+		Version: 1,
+		TxIn: []*TxIn{{
+			PreviousOutPoint: OutPoint{Index: 1},
+			SignatureScript:  []byte{},
+			Witness:          TxWitness{{0x02, 0x03}, largeItem},
+			Sequence:         0xffffffff,
+		}},
+		TxOut: []*TxOut{{
+			Value:    1,
+			PkScript: bytes.Repeat([]byte{0x04}, 1000),
+		}},
+	}
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+	if buf.Len() != tx.SerializeSize() {
+		t.Fatalf("Serialize: wrote %d bytes, SerializeSize is %d",
+			buf.Len(), tx.SerializeSize())
+	}
+
+	var decoded MsgTx
+	if err := decoded.Deserialize(&buf); err != nil {
+		t.Fatalf("Deserialize: %v", err)
+	}
+	if !reflect.DeepEqual(&decoded, tx) {
+		t.Fatalf("Deserialize: got %s want %s", spew.Sdump(&decoded),
+			spew.Sdump(tx))
+	}
+
+	// A script with spare capacity could be appended to in place, which
+	// would write into a buffer the free list hands out again.
+	scripts := [][]byte{decoded.TxIn[0].SignatureScript,
+		decoded.TxOut[0].PkScript}
+	scripts = append(scripts, decoded.TxIn[0].Witness...)
+	for i, script := range scripts {
+		if cap(script) != len(script) {
+			t.Errorf("script #%d has len %d, cap %d", i, len(script),
+				cap(script))
+		}
+	}
+}
+
 // multiTx is a MsgTx with an input and output and used in various tests.
 var multiTx = &MsgTx{
 	Version: 1,
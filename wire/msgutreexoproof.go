@@ -0,0 +1,257 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+)
+
+const (
+	// MaxUtreexoRoots is the maximum number of roots of a utreexo
+	// accumulator, one for each bit of the number of leaves.
+	MaxUtreexoRoots = 64
+
+	// maxUtreexoPkScriptSize is the maximum size of the public key script
+	// of an output in a uproof message.  It matches the maximum size of a
+	// script.
+	maxUtreexoPkScriptSize = 10000
+)
+
+// UtreexoProof defines the proof that an unspent output is in a utreexo
+// accumulator.  It holds the data of the output the leaf commits to, the
+// position of the leaf and the hashes of the siblings on its path to the root
+// of its tree.
+type UtreexoProof struct {
+	OutPoint   OutPoint
+	Height     int32
+	IsCoinBase bool
+	Amount     int64
+	PkScript   []byte
+	Position   uint64
+	Siblings   []chainhash.Hash
+}
+
+// readUtreexoProof reads the next sequence of bytes from r as a UtreexoProof.
+func readUtreexoProof(r io.Reader, pver uint32, proof *UtreexoProof) error {
+	err := readOutPoint(r, pver, 0, &proof.OutPoint)
+	if err != nil {
+		return err
+	}
+	err = readElements(r, &proof.Height, &proof.IsCoinBase, &proof.Amount)
+	if err != nil {
+		return err
+	}
+	proof.PkScript, err = ReadVarBytes(r, pver, maxUtreexoPkScriptSize,
+		"utreexo proof pkscript")
+	if err != nil {
+		return err
+	}
+	err = readElement(r, &proof.Position)
+	if err != nil {
+		return err
+	}
+
+	count, err := ReadVarInt(r, pver)
+	if err != nil {
+		return err
+	}
+	if count >= MaxUtreexoRoots {
+		str := fmt.Sprintf("too many siblings in utreexo proof "+
+			"[count %v, max %v]", count, MaxUtreexoRoots-1)
+		return messageError("readUtreexoProof", str)
+	}
+	proof.Siblings = make([]chainhash.Hash, count)
+	for i := range proof.Siblings {
+		err := readElement(r, &proof.Siblings[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeUtreexoProof encodes proof to the bitcoin protocol encoding for a
+// UtreexoProof to w.
+func writeUtreexoProof(w io.Writer, pver uint32, proof *UtreexoProof) error {
+	count := len(proof.Siblings)
+	if count >= MaxUtreexoRoots {
+		str := fmt.Sprintf("too many siblings in utreexo proof "+
+			"[count %v, max %v]", count, MaxUtreexoRoots-1)
+		return messageError("writeUtreexoProof", str)
+	}
+
+	err := writeOutPoint(w, pver, 0, &proof.OutPoint)
+	if err != nil {
+		return err
+	}
+	err = writeElements(w, proof.Height, proof.IsCoinBase, proof.Amount)
+	if err != nil {
+		return err
+	}
+	err = WriteVarBytes(w, pver, proof.PkScript)
+	if err != nil {
+		return err
+	}
+	err = writeElement(w, proof.Position)
+	if err != nil {
+		return err
+	}
+
+	err = WriteVarInt(w, pver, uint64(count))
+	if err != nil {
+		return err
+	}
+	for i := range proof.Siblings {
+		err := writeElement(w, &proof.Siblings[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MsgUtreexoProof implements the Message interface and represents a bitcoin
+// uproof message.  It is used to deliver the proofs of the unspent outputs
+// requested with a getuproof (MsgGetUtreexoProof) message, along with the
+// roots of the accumulator they are proven against.  The outputs which are
+// not in the accumulator are left out.
+//
+// This message is not part of the Bitcoin protocol.
+type MsgUtreexoProof struct {
+	BlockHash chainhash.Hash
+	NumLeaves uint64
+	Roots     []chainhash.Hash
+	Proofs    []*UtreexoProof
+}
+
+// AddProof adds a proof to the message.
+func (msg *MsgUtreexoProof) AddProof(proof *UtreexoProof) error {
+	if len(msg.Proofs)+1 > MaxUtreexoProofsPerMsg {
+		str := fmt.Sprintf("too many proofs in message [max %v]",
+			MaxUtreexoProofsPerMsg)
+		return messageError("MsgUtreexoProof.AddProof", str)
+	}
+
+	msg.Proofs = append(msg.Proofs, proof)
+	return nil
+}
+
+// BtcDecode decodes r using the bitcoin protocol encoding into the receiver.
+// This is part of the Message interface implementation.
+func (msg *MsgUtreexoProof) BtcDecode(r io.Reader, pver uint32, _ MessageEncoding) error {
+	err := readElements(r, &msg.BlockHash, &msg.NumLeaves)
+	if err != nil {
+		return err
+	}
+
+	count, err := ReadVarInt(r, pver)
+	if err != nil {
+		return err
+	}
+	if count > MaxUtreexoRoots {
+		str := fmt.Sprintf("too many roots in message "+
+			"[count %v, max %v]", count, MaxUtreexoRoots)
+		return messageError("MsgUtreexoProof.BtcDecode", str)
+	}
+	msg.Roots = make([]chainhash.Hash, count)
+	for i := range msg.Roots {
+		err := readElement(r, &msg.Roots[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	count, err = ReadVarInt(r, pver)
+	if err != nil {
+		return err
+	}
+	if count > MaxUtreexoProofsPerMsg {
+		str := fmt.Sprintf("too many proofs in message "+
+			"[count %v, max %v]", count, MaxUtreexoProofsPerMsg)
+		return messageError("MsgUtreexoProof.BtcDecode", str)
+	}
+	msg.Proofs = make([]*UtreexoProof, count)
+	for i := range msg.Proofs {
+		proof := &UtreexoProof{}
+		err := readUtreexoProof(r, pver, proof)
+		if err != nil {
+			return err
+		}
+		msg.Proofs[i] = proof
+	}
+	return nil
+}
+
+// BtcEncode encodes the receiver to w using the bitcoin protocol encoding.
+// This is part of the Message interface implementation.
+func (msg *MsgUtreexoProof) BtcEncode(w io.Writer, pver uint32, _ MessageEncoding) error {
+	if len(msg.Roots) > MaxUtreexoRoots {
+		str := fmt.Sprintf("too many roots in message "+
+			"[count %v, max %v]", len(msg.Roots), MaxUtreexoRoots)
+		return messageError("MsgUtreexoProof.BtcEncode", str)
+	}
+	if len(msg.Proofs) > MaxUtreexoProofsPerMsg {
+		str := fmt.Sprintf("too many proofs in message "+
+			"[count %v, max %v]", len(msg.Proofs),
+			MaxUtreexoProofsPerMsg)
+		return messageError("MsgUtreexoProof.BtcEncode", str)
+	}
+
+	err := writeElements(w, &msg.BlockHash, msg.NumLeaves)
+	if err != nil {
+		return err
+	}
+
+	err = WriteVarInt(w, pver, uint64(len(msg.Roots)))
+	if err != nil {
+		return err
+	}
+	for i := range msg.Roots {
+		err := writeElement(w, &msg.Roots[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	err = WriteVarInt(w, pver, uint64(len(msg.Proofs)))
+	if err != nil {
+		return err
+	}
+	for _, proof := range msg.Proofs {
+		err := writeUtreexoProof(w, pver, proof)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Command returns the protocol command string for the message.  This is part
+// of the Message interface implementation.
+func (msg *MsgUtreexoProof) Command() string {
+	return CmdUtreexoProof
+}
+
+// MaxPayloadLength returns the maximum length the payload can be for the
+// receiver.  This is part of the Message interface implementation.
+func (msg *MsgUtreexoProof) MaxPayloadLength(pver uint32) uint32 {
+	return MaxMessagePayload
+}
+
+// NewMsgUtreexoProof returns a new bitcoin uproof message that conforms to the
+// Message interface using the passed parameters.  See MsgUtreexoProof for
+// details.
+func NewMsgUtreexoProof(blockHash *chainhash.Hash, numLeaves uint64,
+	roots []chainhash.Hash) *MsgUtreexoProof {
+
+	return &MsgUtreexoProof{
+		BlockHash: *blockHash,
+		NumLeaves: numLeaves,
+		Roots:     roots,
+	}
+}
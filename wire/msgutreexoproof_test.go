@@ -0,0 +1,90 @@
+// Copyright (c) 2020 The pktd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+)
+
+// TestUtreexoProofWire tests the MsgGetUtreexoProof and MsgUtreexoProof wire
+// encode and decode.
+func TestUtreexoProofWire(t *testing.T) {
+	hash := chainhash.Hash{0x01, 0x02}
+	outPoint := OutPoint{Hash: chainhash.Hash{0x03}, Index: 7}
+
+	getProof := NewMsgGetUtreexoProof()
+	if err := getProof.AddOutPoint(&outPoint); err != nil {
+		t.Fatalf("AddOutPoint: %v", err)
+	}
+	proof := NewMsgUtreexoProof(&hash, 5, []chainhash.Hash{{0x04}, {0x05}})
+	err := proof.AddProof(&UtreexoProof{
+		OutPoint:   outPoint,
+		Height:     12,
+		IsCoinBase: true,
+		Amount:     5000,
+		PkScript:   []byte{0x51},
+		Position:   2,
+		Siblings:   []chainhash.Hash{{0x06}, {0x07}},
+	})
+	if err != nil {
+		t.Fatalf("AddProof: %v", err)
+	}
+
+	tests := []struct {
+		in      Message
+		out     Message
+		command string
+	}{
+		{getProof, &MsgGetUtreexoProof{}, CmdGetUtreexoProof},
+		{NewMsgGetUtreexoProof(), &MsgGetUtreexoProof{}, CmdGetUtreexoProof},
+		{proof, &MsgUtreexoProof{}, CmdUtreexoProof},
+	}
+	for i, test := range tests {
+		if cmd := test.in.Command(); cmd != test.command {
+			t.Errorf("Command #%d: got %v, want %v", i, cmd,
+				test.command)
+		}
+
+		var buf bytes.Buffer
+		err := test.in.BtcEncode(&buf, ProtocolVersion, BaseEncoding)
+		if err != nil {
+			t.Errorf("BtcEncode #%d error %v", i, err)
+			continue
+		}
+		if uint32(buf.Len()) > test.in.MaxPayloadLength(ProtocolVersion) {
+			t.Errorf("BtcEncode #%d: payload of %d bytes exceeds "+
+				"the maximum", i, buf.Len())
+		}
+		err = test.out.BtcDecode(&buf, ProtocolVersion, BaseEncoding)
+		if err != nil {
+			t.Errorf("BtcDecode #%d error %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(test.out, test.in) {
+			t.Errorf("BtcDecode #%d\n got: %s want: %s", i,
+				spew.Sdump(test.out), spew.Sdump(test.in))
+		}
+	}
+
+	// Requests for too many outputs are rejected.
+	for i := len(getProof.OutPoints); i < MaxUtreexoProofsPerMsg; i++ {
+		if err := getProof.AddOutPoint(&outPoint); err != nil {
+			t.Fatalf("AddOutPoint: %v", err)
+		}
+	}
+	if err := getProof.AddOutPoint(&outPoint); err == nil {
+		t.Fatal("AddOutPoint beyond the maximum succeeded")
+	}
+	var buf bytes.Buffer
+	getProof.OutPoints = append(getProof.OutPoints, outPoint)
+	if err := getProof.BtcEncode(&buf, ProtocolVersion, BaseEncoding); err == nil {
+		t.Fatal("BtcEncode of too many outpoints succeeded")
+	}
+}
@@ -89,6 +89,11 @@ const (
 	SFNode2X
 )
 
+// SFNodeUtreexo is a flag used to indicate a peer maintains a utreexo
+// accumulator of the unspent outputs and serves proofs of them with the
+// getuproof and uproof commands.  This is a pktd extension.
+const SFNodeUtreexo ServiceFlag = 1 << 24
+
 // Map of service flags back to their constant names for pretty printing.
 var sfStrings = map[ServiceFlag]string{
 	SFNodeNetwork: "SFNodeNetwork",
@@ -99,6 +104,7 @@ var sfStrings = map[ServiceFlag]string{
 	SFNodeBit5:    "SFNodeBit5",
 	SFNodeCF:      "SFNodeCF",
 	SFNode2X:      "SFNode2X",
+	SFNodeUtreexo: "SFNodeUtreexo",
 }
 
 // orderedSFStrings is an ordered list of service flags from highest to
@@ -112,6 +118,7 @@ var orderedSFStrings = []ServiceFlag{
 	SFNodeBit5,
 	SFNodeCF,
 	SFNode2X,
+	SFNodeUtreexo,
 }
 
 // String returns the ServiceFlag in human-readable form.